@@ -0,0 +1,286 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package a11y checks rendered HTML for common accessibility problems.
+package a11y
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// The rules checked.
+const (
+	RuleImageAlt     = "image-alt"
+	RuleHTMLLang     = "html-lang"
+	RuleDuplicateID  = "duplicate-id"
+	RuleHeadingOrder = "heading-order"
+	RuleLinkText     = "link-text"
+)
+
+var rules = []string{RuleImageAlt, RuleHTMLLang, RuleDuplicateID, RuleHeadingOrder, RuleLinkText}
+
+// The issue severities.
+const (
+	SeverityWarning = "warning"
+	SeverityError   = "error"
+)
+
+var ruleSeverity = map[string]string{
+	RuleImageAlt:     SeverityError,
+	RuleHTMLLang:     SeverityError,
+	RuleDuplicateID:  SeverityError,
+	RuleHeadingOrder: SeverityWarning,
+	RuleLinkText:     SeverityWarning,
+}
+
+// Link texts that say nothing about where it leads.
+var lowInformationLinkTexts = map[string]bool{
+	"click":      true,
+	"click here": true,
+	"continue":   true,
+	"here":       true,
+	"learn more": true,
+	"link":       true,
+	"more":       true,
+	"read more":  true,
+	"this":       true,
+	"this link":  true,
+}
+
+func isRule(s string) bool {
+	_, found := ruleSeverity[s]
+	return found
+}
+
+func severityLevel(s string) int {
+	switch s {
+	case SeverityWarning:
+		return 1
+	case SeverityError:
+		return 2
+	}
+	return 0
+}
+
+// Issue is an accessibility problem found in a page.
+type Issue struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+
+	// The line in the HTML, before any minification.
+	Line int `json:"line"`
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%d: %s: %s (%s)", i.Line, i.Severity, i.Message, i.Rule)
+}
+
+// Checker checks HTML pages and collects the issues found.
+type Checker struct {
+	cfg Config
+
+	mu    sync.Mutex
+	pages map[string][]Issue
+}
+
+// New creates a new Checker, nil if the checks are not enabled.
+func New(cfg Config) *Checker {
+	if !cfg.Enable {
+		return nil
+	}
+	return &Checker{cfg: cfg, pages: make(map[string][]Issue)}
+}
+
+// Check checks the HTML document in r, published to path. Any previous
+// issues for path are replaced.
+func (c *Checker) Check(path string, r io.Reader) error {
+	issues, err := c.check(r)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(issues) == 0 {
+		delete(c.pages, path)
+	} else {
+		c.pages[path] = issues
+	}
+
+	return nil
+}
+
+type link struct {
+	line int
+	text strings.Builder
+}
+
+func (c *Checker) check(r io.Reader) ([]Issue, error) {
+	var (
+		issues       []Issue
+		line         = 1
+		ids          = make(map[string]int)
+		headingLevel int
+		links        []*link
+	)
+
+	add := func(rule string, line int, format string, args ...interface{}) {
+		if c.cfg.disabled(rule) {
+			return
+		}
+		issues = append(issues, Issue{
+			Rule:     rule,
+			Severity: ruleSeverity[rule],
+			Message:  fmt.Sprintf(format, args...),
+			Line:     line,
+		})
+	}
+
+	z := html.NewTokenizer(r)
+
+	for {
+		tt := z.Next()
+		tokenLine := line
+		line += bytes.Count(z.Raw(), []byte("\n"))
+
+		switch tt {
+		case html.ErrorToken:
+			if z.Err() == io.EOF {
+				return issues, nil
+			}
+			return nil, z.Err()
+		case html.TextToken:
+			for _, l := range links {
+				l.text.Write(z.Text())
+			}
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			if atom.Lookup(name) == atom.A && len(links) > 0 {
+				l := links[len(links)-1]
+				links = links[:len(links)-1]
+				text := normalizeLinkText(l.text.String())
+				if text == "" {
+					add(RuleLinkText, l.line, "link has no text")
+				} else if lowInformationLinkTexts[text] {
+					add(RuleLinkText, l.line, "link text %q does not describe where the link leads", text)
+				}
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			attrs := make(map[string]string)
+			for hasAttr {
+				var k, v []byte
+				k, v, hasAttr = z.TagAttr()
+				attrs[string(k)] = string(v)
+			}
+
+			if id, found := attrs["id"]; found && id != "" {
+				if first, found := ids[id]; found {
+					add(RuleDuplicateID, tokenLine, "duplicate id %q, first used on line %d", id, first)
+				} else {
+					ids[id] = tokenLine
+				}
+			}
+
+			a := atom.Lookup(name)
+			switch a {
+			case atom.Html:
+				if strings.TrimSpace(attrs["lang"]) == "" {
+					add(RuleHTMLLang, tokenLine, "html element has no lang attribute")
+				}
+			case atom.Img:
+				alt, found := attrs["alt"]
+				if !found {
+					add(RuleImageAlt, tokenLine, "image %q has no alt attribute", attrs["src"])
+				}
+				for _, l := range links {
+					l.text.WriteString(" " + alt)
+				}
+			case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+				level := int(name[1] - '0')
+				if headingLevel > 0 && level > headingLevel+1 {
+					add(RuleHeadingOrder, tokenLine, "heading level jumps from h%d to h%d", headingLevel, level)
+				}
+				headingLevel = level
+			case atom.A:
+				if _, found := attrs["href"]; !found || tt == html.SelfClosingTagToken {
+					continue
+				}
+				l := &link{line: tokenLine}
+				if label := attrs["aria-label"]; label != "" {
+					l.text.WriteString(label)
+				} else if title := attrs["title"]; title != "" {
+					l.text.WriteString(title)
+				}
+				links = append(links, l)
+			}
+		}
+	}
+}
+
+// normalizeLinkText lower cases s and removes any extra space and trailing
+// punctuation, e.g. "Read more …" becomes "read more".
+func normalizeLinkText(s string) string {
+	s = strings.ToLower(strings.Join(strings.Fields(s), " "))
+	return strings.TrimSpace(strings.TrimRight(s, ".…:»›→>!"))
+}
+
+// Report returns the issues found, sorted by path.
+func (c *Checker) Report() Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var report Report
+	for path, issues := range c.pages {
+		report.Pages = append(report.Pages, PageReport{Path: path, Issues: issues})
+	}
+	sort.Slice(report.Pages, func(i, j int) bool {
+		return report.Pages[i].Path < report.Pages[j].Path
+	})
+
+	return report
+}
+
+// Report holds the issues found per page.
+type Report struct {
+	Pages []PageReport `json:"pages"`
+}
+
+// PageReport holds the issues found in a page.
+type PageReport struct {
+	Path   string  `json:"path"`
+	Issues []Issue `json:"issues"`
+}
+
+// Count returns the number of issues with the given severity or higher.
+func (r Report) Count(severity string) int {
+	min := severityLevel(severity)
+	var n int
+	for _, p := range r.Pages {
+		for _, i := range p.Issues {
+			if severityLevel(i.Severity) >= min {
+				n++
+			}
+		}
+	}
+	return n
+}
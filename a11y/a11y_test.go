@@ -0,0 +1,122 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package a11y
+
+import (
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/config"
+)
+
+func TestDecodeConfig(t *testing.T) {
+	c := qt.New(t)
+
+	ac, err := DecodeConfig(config.New())
+	c.Assert(err, qt.IsNil)
+	c.Assert(ac.Enable, qt.IsFalse)
+	c.Assert(New(ac), qt.IsNil)
+
+	cfg, err := config.FromConfigString(`
+[a11y]
+enable = true
+failOn = "Error"
+disable = ["heading-order"]
+`, "toml")
+	c.Assert(err, qt.IsNil)
+	ac, err = DecodeConfig(cfg)
+	c.Assert(err, qt.IsNil)
+	c.Assert(ac, qt.DeepEquals, Config{Enable: true, FailOn: SeverityError, Disable: []string{RuleHeadingOrder}})
+
+	for _, invalid := range []string{
+		`[a11y]
+failOn = "fatal"`,
+		`[a11y]
+disable = ["color-contrast"]`,
+	} {
+		cfg, err := config.FromConfigString(invalid, "toml")
+		c.Assert(err, qt.IsNil)
+		_, err = DecodeConfig(cfg)
+		c.Assert(err, qt.Not(qt.IsNil))
+	}
+}
+
+func TestCheck(t *testing.T) {
+	c := qt.New(t)
+
+	checker := New(Config{Enable: true})
+
+	c.Assert(checker.Check("bad/index.html", strings.NewReader(`<!DOCTYPE html>
+<html>
+<body>
+<h1 id="title">Title</h1>
+<h3>Sub</h3>
+<img src="a.jpg">
+<img src="b.jpg" alt="">
+<p id="title">Text</p>
+<a href="/a">Click here!</a>
+<a href="/b"><img src="logo.png" alt=""></a>
+<a href="/c" aria-label="Read more about Hugo">Read more</a>
+<a href="/d"><span>Read   more …</span></a>
+<a name="anchor"></a>
+<h4>Sub sub</h4>
+</body>
+</html>
+`)), qt.IsNil)
+
+	c.Assert(checker.Check("good/index.html", strings.NewReader(`<!DOCTYPE html>
+<html lang="en">
+<body>
+<h1>Title</h1>
+<h2>Sub</h2>
+<img src="a.jpg" alt="A cat">
+<a href="/a">Hugo documentation</a>
+<a href="/b"><img src="logo.png" alt="Home"></a>
+</body>
+</html>
+`)), qt.IsNil)
+
+	report := checker.Report()
+	c.Assert(report.Pages, qt.HasLen, 1)
+	c.Assert(report.Pages[0].Path, qt.Equals, "bad/index.html")
+
+	var got []string
+	for _, issue := range report.Pages[0].Issues {
+		got = append(got, issue.String())
+	}
+
+	c.Assert(got, qt.DeepEquals, []string{
+		`2: error: html element has no lang attribute (html-lang)`,
+		`5: warning: heading level jumps from h1 to h3 (heading-order)`,
+		`6: error: image "a.jpg" has no alt attribute (image-alt)`,
+		`8: error: duplicate id "title", first used on line 4 (duplicate-id)`,
+		`9: warning: link text "click here" does not describe where the link leads (link-text)`,
+		`10: warning: link has no text (link-text)`,
+		`12: warning: link text "read more" does not describe where the link leads (link-text)`,
+	})
+
+	c.Assert(report.Count(SeverityWarning), qt.Equals, 7)
+	c.Assert(report.Count(SeverityError), qt.Equals, 3)
+
+	// Fixed issues are removed.
+	c.Assert(checker.Check("bad/index.html", strings.NewReader(`<html lang="en"></html>`)), qt.IsNil)
+	c.Assert(checker.Report().Pages, qt.HasLen, 0)
+
+	c.Run("Disable", func(c *qt.C) {
+		checker := New(Config{Enable: true, Disable: []string{RuleHTMLLang}})
+		c.Assert(checker.Check("index.html", strings.NewReader(`<html><h1>Foo</h1></html>`)), qt.IsNil)
+		c.Assert(checker.Report().Pages, qt.HasLen, 0)
+	})
+}
@@ -0,0 +1,82 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package a11y
+
+import (
+	"strings"
+
+	"github.com/gohugoio/hugo/config"
+	"github.com/gohugoio/hugo/config/configschema"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+)
+
+const a11yConfigKey = "a11y"
+
+// DefaultConfig holds the default accessibility check configuration.
+var DefaultConfig = Config{}
+
+// Config holds the accessibility check configuration.
+type Config struct {
+	// Enable the checks of the HTML pages.
+	Enable bool
+
+	// Fail the build if there are issues with this severity or higher,
+	// "warning" or "error". Never fail the build if not set.
+	FailOn string
+
+	// The IDs of the rules to skip, e.g. "heading-order".
+	Disable []string
+}
+
+func (c Config) disabled(rule string) bool {
+	for _, r := range c.Disable {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// DecodeConfig decodes the a11y section in the site configuration.
+func DecodeConfig(cfg config.Provider) (Config, error) {
+	c := DefaultConfig
+
+	if !cfg.IsSet(a11yConfigKey) {
+		return c, nil
+	}
+
+	if err := mapstructure.WeakDecode(cfg.GetStringMap(a11yConfigKey), &c); err != nil {
+		return c, errors.Wrap(err, "failed to decode a11y config")
+	}
+
+	c.FailOn = strings.ToLower(c.FailOn)
+	if c.FailOn != "" && severityLevel(c.FailOn) == 0 {
+		return c, errors.Errorf("a11y: invalid failOn %q, must be one of warning or error", c.FailOn)
+	}
+
+	for i, r := range c.Disable {
+		r = strings.ToLower(r)
+		if !isRule(r) {
+			return c, errors.Errorf("a11y: invalid rule %q, must be one of %s", r, strings.Join(rules, ", "))
+		}
+		c.Disable[i] = r
+	}
+
+	return c, nil
+}
+
+func init() {
+	configschema.AddSection(a11yConfigKey, DefaultConfig)
+}
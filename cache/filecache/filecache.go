@@ -15,6 +15,7 @@ package filecache
 
 import (
 	"bytes"
+	"compress/gzip"
 	"errors"
 	"io"
 	"io/ioutil"
@@ -25,6 +26,7 @@ import (
 	"time"
 
 	"github.com/gohugoio/hugo/common/hugio"
+	"github.com/gohugoio/hugo/common/loggers"
 
 	"github.com/gohugoio/hugo/helpers"
 
@@ -37,6 +39,9 @@ var ErrFatal = errors.New("fatal filecache error")
 
 const (
 	filecacheRootDirname = "filecache"
+
+	// The suffix of the files holding compressed entries.
+	compressedSuffix = ".gz"
 )
 
 // Cache caches a set of files in a directory. This is usually a file on
@@ -51,6 +56,19 @@ type Cache struct {
 	// When set, we just remove this entire root directory on expiration.
 	pruneAllRootDir string
 
+	// An optional remote store shared between builds.
+	remote *remoteStore
+
+	// Whether the entries are stored gzipped.
+	compress bool
+
+	// If > 0, the least recently used entries are removed by Evict when the
+	// cache is larger than this.
+	maxSize int64
+
+	// The configuration this cache was created from, if any.
+	cfg Config
+
 	nlocker *lockTracker
 }
 
@@ -93,15 +111,20 @@ func NewCache(fs afero.Fs, maxAge time.Duration, pruneAllRootDir string) *Cache
 	}
 }
 
+// Enabled reports whether this cache stores any entries.
+func (c *Cache) Enabled() bool {
+	return c.maxAge != 0
+}
+
 // lockedFile is a file with a lock that is released on Close.
 type lockedFile struct {
-	afero.File
+	io.WriteCloser
 	unlock func()
 }
 
 func (l *lockedFile) Close() error {
 	defer l.unlock()
-	return l.File.Close()
+	return l.WriteCloser.Close()
 }
 
 // WriteCloser returns a transactional writer into the cache.
@@ -112,15 +135,18 @@ func (c *Cache) WriteCloser(id string) (ItemInfo, io.WriteCloser, error) {
 
 	info := ItemInfo{Name: id}
 
-	f, err := helpers.OpenFileForWriting(c.Fs, id)
+	f, err := c.create(id)
 	if err != nil {
 		c.nlocker.Unlock(id)
 		return info, nil, err
 	}
 
 	return info, &lockedFile{
-		File:   f,
-		unlock: func() { c.nlocker.Unlock(id) },
+		WriteCloser: f,
+		unlock: func() {
+			c.putRemoteFile(id)
+			c.nlocker.Unlock(id)
+		},
 	}, nil
 }
 
@@ -150,12 +176,15 @@ func (c *Cache) ReadOrCreate(id string,
 		}
 	}
 
-	f, err := helpers.OpenFileForWriting(c.Fs, id)
+	f, err := c.create(id)
 	if err != nil {
 		return
 	}
 
 	err = create(info, f)
+	if err == nil {
+		c.putRemoteFile(id)
+	}
 
 	return
 }
@@ -186,9 +215,12 @@ func (c *Cache) GetOrCreate(id string, create func() (io.ReadCloser, error)) (It
 	}
 
 	var buff bytes.Buffer
-	return info,
-		hugio.ToReadCloser(&buff),
-		afero.WriteReader(c.Fs, id, io.TeeReader(r, &buff))
+	if err := c.writeReader(id, io.TeeReader(r, &buff)); err != nil {
+		return info, hugio.ToReadCloser(&buff), err
+	}
+	c.putRemote(id, buff.Bytes())
+
+	return info, hugio.ToReadCloser(&buff), nil
 }
 
 // GetOrCreateBytes is the same as GetOrCreate, but produces a byte slice.
@@ -215,9 +247,11 @@ func (c *Cache) GetOrCreateBytes(id string, create func() ([]byte, error)) (Item
 		return info, b, nil
 	}
 
-	if err := afero.WriteReader(c.Fs, id, bytes.NewReader(b)); err != nil {
+	if err := c.writeReader(id, bytes.NewReader(b)); err != nil {
 		return info, nil, err
 	}
+	c.putRemote(id, b)
+
 	return info, b, nil
 }
 
@@ -262,25 +296,101 @@ func (c *Cache) getOrRemove(id string) hugio.ReadSeekCloser {
 	}
 
 	if c.maxAge > 0 {
-		fi, err := c.Fs.Stat(id)
+		fi, err := c.Fs.Stat(c.name(id))
 		if err != nil {
-			return nil
+			return c.getRemote(id)
 		}
 
 		if c.isExpired(fi.ModTime()) {
-			c.Fs.Remove(id)
+			c.Fs.Remove(c.name(id))
 			return nil
 		}
 	}
 
-	f, err := c.Fs.Open(id)
+	f, err := c.open(id)
 	if err != nil {
-		return nil
+		return c.getRemote(id)
 	}
 
 	return f
 }
 
+// name returns the name of the file holding the entry with the given id.
+func (c *Cache) name(id string) string {
+	if c.compress {
+		return id + compressedSuffix
+	}
+	return id
+}
+
+// id is the inverse of name.
+func (c *Cache) id(name string) string {
+	if c.compress {
+		return strings.TrimSuffix(name, compressedSuffix)
+	}
+	return name
+}
+
+// create creates or truncates the entry with the given id for writing.
+func (c *Cache) create(id string) (io.WriteCloser, error) {
+	f, err := helpers.OpenFileForWriting(c.Fs, c.name(id))
+	if err != nil {
+		return nil, err
+	}
+	if !c.compress {
+		return f, nil
+	}
+	return gzipWriteCloser{Writer: gzip.NewWriter(f), f: f}, nil
+}
+
+func (c *Cache) writeReader(id string, r io.Reader) error {
+	w, err := c.create(id)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(w, r); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// open opens the entry with the given id for reading.
+func (c *Cache) open(id string) (hugio.ReadSeekCloser, error) {
+	f, err := c.Fs.Open(c.name(id))
+	if err != nil {
+		return nil, err
+	}
+	if !c.compress {
+		return f, nil
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	b, err := ioutil.ReadAll(zr)
+	if err != nil {
+		return nil, err
+	}
+
+	return hugio.NewReadSeekerNoOpCloser(bytes.NewReader(b)), nil
+}
+
+type gzipWriteCloser struct {
+	*gzip.Writer
+	f io.Closer
+}
+
+func (w gzipWriteCloser) Close() error {
+	err := w.Writer.Close()
+	if cerr := w.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
 func (c *Cache) isExpired(modTime time.Time) bool {
 	if c.maxAge < 0 {
 		return false
@@ -295,7 +405,7 @@ func (c *Cache) getString(id string) string {
 	c.nlocker.Lock(id)
 	defer c.nlocker.Unlock(id)
 
-	f, err := c.Fs.Open(id)
+	f, err := c.open(id)
 	if err != nil {
 		return ""
 	}
@@ -313,6 +423,15 @@ func (f Caches) Get(name string) *Cache {
 	return f[strings.ToLower(name)]
 }
 
+// SetLogger sets the logger used to warn about remote caches failing.
+func (f Caches) SetLogger(logger loggers.Logger) {
+	for _, c := range f {
+		if c.remote != nil {
+			c.remote.logger = logger
+		}
+	}
+}
+
 // NewCaches creates a new set of file caches from the given
 // configuration.
 func NewCaches(p *helpers.PathSpec) (Caches, error) {
@@ -360,7 +479,18 @@ func NewCaches(p *helpers.PathSpec) (Caches, error) {
 			pruneAllRootDir = "pkg"
 		}
 
-		m[k] = NewCache(bfs, v.MaxAge, pruneAllRootDir)
+		c := NewCache(bfs, v.MaxAge, pruneAllRootDir)
+		c.cfg = v
+		c.compress = v.Compression == compressionGzip
+		c.maxSize = v.MaxSize
+		if c.Enabled() {
+			remote, err := newRemoteStore(k, v)
+			if err != nil {
+				return nil, err
+			}
+			c.remote = remote
+		}
+		m[k] = c
 	}
 
 	return m, nil
@@ -16,15 +16,19 @@ package filecache
 import (
 	"path"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"time"
 
 	"github.com/gohugoio/hugo/common/maps"
+	"github.com/gohugoio/hugo/config/configschema"
 
 	"github.com/gohugoio/hugo/config"
 
 	"github.com/gohugoio/hugo/helpers"
+	"github.com/gohugoio/hugo/hugofs"
 
+	"github.com/dustin/go-humanize"
 	"github.com/mitchellh/mapstructure"
 	"github.com/pkg/errors"
 	"github.com/spf13/afero"
@@ -33,6 +37,9 @@ import (
 const (
 	cachesConfigKey = "caches"
 
+	compressionNone = "none"
+	compressionGzip = "gzip"
+
 	resourcesGenDir = ":resourceDir/_gen"
 )
 
@@ -42,11 +49,18 @@ var defaultCacheConfig = Config{
 }
 
 const (
-	cacheKeyGetJSON = "getjson"
-	cacheKeyGetCSV  = "getcsv"
-	cacheKeyImages  = "images"
-	cacheKeyAssets  = "assets"
-	cacheKeyModules = "modules"
+	cacheKeyGetJSON     = "getjson"
+	cacheKeyGetCSV      = "getcsv"
+	cacheKeyImages      = "images"
+	cacheKeyAssets      = "assets"
+	cacheKeyModules     = "modules"
+	cacheKeyRenders     = "renders"
+	cacheKeyRelated     = "related"
+	cacheKeyComments    = "comments"
+	cacheKeyLinkCheck   = "linkcheck"
+	cacheKeyGetResource = "getresource"
+	cacheKeyGetGraphQL  = "getgraphql"
+	cacheKeyGetFeed     = "getfeed"
 )
 
 type Configs map[string]Config
@@ -70,6 +84,28 @@ var defaultCacheConfigs = Configs{
 		MaxAge: -1,
 		Dir:    resourcesGenDir,
 	},
+	// The render cache is disabled by default.
+	cacheKeyRenders: {
+		MaxAge: 0,
+		Dir:    ":cacheDir/:project",
+	},
+	cacheKeyRelated: defaultCacheConfig,
+	// Comments change, so refetch them regularly.
+	cacheKeyComments: {
+		MaxAge: time.Hour,
+		Dir:    ":cacheDir/:project",
+	},
+	// Only valid external links are cached.
+	cacheKeyLinkCheck: {
+		MaxAge: 24 * time.Hour,
+		Dir:    ":cacheDir/:project",
+	},
+	cacheKeyGetResource: {
+		MaxAge: -1,
+		Dir:    ":cacheDir/:project",
+	},
+	cacheKeyGetGraphQL: defaultCacheConfig,
+	cacheKeyGetFeed:    defaultCacheConfig,
 }
 
 type Config struct {
@@ -81,6 +117,24 @@ type Config struct {
 	// The directory where files are stored.
 	Dir string
 
+	// An optional remote store shared between builds, e.g. on CI runners:
+	// a s3://, gs:// or file:// bucket URL, or a http(s):// URL to a cache
+	// service supporting GET and PUT. MaxAge applies to the time the remote
+	// entries were created. Not supported for the modules cache, which is
+	// managed by the Go tool.
+	Remote string
+
+	// Only read from the remote store, never write to it.
+	RemoteReadOnly bool
+
+	// The maximum size of this cache in bytes, e.g. "2GB". When it is
+	// exceeded after a build, the least recently used entries are removed.
+	// 0 means no limit.
+	MaxSize int64
+
+	// Set to "gzip" to store the entries compressed.
+	Compression string
+
 	// Will resources/_gen will get its own composite filesystem that
 	// also checks any theme.
 	isResourceDir bool
@@ -106,6 +160,41 @@ func (f Caches) ModulesCache() *Cache {
 	return f[cacheKeyModules]
 }
 
+// RendersCache gets the file cache for rendered pages.
+func (f Caches) RendersCache() *Cache {
+	return f[cacheKeyRenders]
+}
+
+// RelatedCache gets the file cache for the related content indices.
+func (f Caches) RelatedCache() *Cache {
+	return f[cacheKeyRelated]
+}
+
+// CommentsCache gets the file cache for comments fetched from external sources.
+func (f Caches) CommentsCache() *Cache {
+	return f[cacheKeyComments]
+}
+
+// LinkCheckCache gets the file cache for the external links checked by hugo check links.
+func (f Caches) LinkCheckCache() *Cache {
+	return f[cacheKeyLinkCheck]
+}
+
+// GetResourceCache gets the file cache for remote resources.
+func (f Caches) GetResourceCache() *Cache {
+	return f[cacheKeyGetResource]
+}
+
+// GetGraphQLCache gets the file cache for GraphQL queries.
+func (f Caches) GetGraphQLCache() *Cache {
+	return f[cacheKeyGetGraphQL]
+}
+
+// GetFeedCache gets the file cache for getFeed.
+func (f Caches) GetFeedCache() *Cache {
+	return f[cacheKeyGetFeed]
+}
+
 // AssetsCache gets the file cache for assets (processed resources, SCSS etc.).
 func (f Caches) AssetsCache() *Cache {
 	return f[cacheKeyAssets]
@@ -122,7 +211,7 @@ func DecodeConfig(fs afero.Fs, cfg config.Provider) (Configs, error) {
 
 	m := cfg.GetStringMap(cachesConfigKey)
 
-	_, isOsFs := fs.(*afero.OsFs)
+	isOsFs := hugofs.IsOsFs(fs)
 
 	for k, v := range m {
 		if _, ok := v.(maps.Params); !ok {
@@ -132,7 +221,7 @@ func DecodeConfig(fs afero.Fs, cfg config.Provider) (Configs, error) {
 
 		dc := &mapstructure.DecoderConfig{
 			Result:           &cc,
-			DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
+			DecodeHook:       mapstructure.ComposeDecodeHookFunc(mapstructure.StringToTimeDurationHookFunc(), stringToByteSizeHookFunc),
 			WeaklyTypedInput: true,
 		}
 
@@ -154,6 +243,32 @@ func DecodeConfig(fs afero.Fs, cfg config.Provider) (Configs, error) {
 			return nil, errors.Errorf("%q is not a valid cache name", name)
 		}
 
+		if cc.MaxSize < 0 {
+			return nil, errors.Errorf("maxSize for the %q cache must be >= 0", name)
+		}
+
+		switch strings.ToLower(cc.Compression) {
+		case "", compressionNone:
+			cc.Compression = ""
+		case compressionGzip:
+			if name == cacheKeyImages || name == cacheKeyAssets || name == cacheKeyModules {
+				// These are read directly from disk.
+				return nil, errors.Errorf("compression is not supported for the %q cache", name)
+			}
+			cc.Compression = compressionGzip
+		default:
+			return nil, errors.Errorf("invalid compression %q for the %q cache, must be %q or %q", cc.Compression, name, compressionGzip, compressionNone)
+		}
+
+		if cc.Remote != "" {
+			if name == cacheKeyModules {
+				return nil, errors.New("remote is not supported for the modules cache, use a Go module proxy instead")
+			}
+			if err := validateRemoteURL(cc.Remote); err != nil {
+				return nil, err
+			}
+		}
+
 		c[name] = cc
 	}
 
@@ -217,6 +332,19 @@ func DecodeConfig(fs afero.Fs, cfg config.Provider) (Configs, error) {
 	return c, nil
 }
 
+// stringToByteSizeHookFunc decodes sizes such as "512MB" or "2GiB" into
+// int64 fields.
+func stringToByteSizeHookFunc(f reflect.Type, t reflect.Type, data interface{}) (interface{}, error) {
+	if f.Kind() != reflect.String || t != reflect.TypeOf(int64(0)) {
+		return data, nil
+	}
+	n, err := humanize.ParseBytes(data.(string))
+	if err != nil {
+		return nil, err
+	}
+	return int64(n), nil
+}
+
 // Resolves :resourceDir => /myproject/resources etc., :cacheDir => ...
 func resolveDirPlaceholder(fs afero.Fs, cfg config.Provider, placeholder string) (cacheDir string, isResource bool, err error) {
 	workingDir := cfg.GetString("workingDir")
@@ -233,3 +361,17 @@ func resolveDirPlaceholder(fs afero.Fs, cfg config.Provider, placeholder string)
 
 	return "", false, errors.Errorf("%q is not a valid placeholder (valid values are :cacheDir or :resourceDir)", placeholder)
 }
+
+func init() {
+	s := configschema.FromValue(defaultCacheConfigs)
+	setCacheSchema := func(cs *configschema.Schema) {
+		// Sizes can also be set as strings, e.g. "2GB".
+		cs.Properties["maxSize"].Type = []string{configschema.TypeInteger, configschema.TypeString}
+		cs.Properties["compression"].Enum = []interface{}{"", compressionNone, compressionGzip}
+	}
+	setCacheSchema(s.AdditionalProperties)
+	for _, cs := range s.Properties {
+		setCacheSchema(cs)
+	}
+	configschema.AddSection(cachesConfigKey, s)
+}
@@ -50,6 +50,11 @@ maxAge = "11h"
 dir = "/path/to/c2"
 [caches.images]
 dir = "/path/to/c3"
+maxSize = "2GB"
+[caches.getresource]
+dir = "/path/to/c4"
+maxSize = 1024
+compression = "GZIP"
 
 `
 
@@ -59,7 +64,12 @@ dir = "/path/to/c3"
 	decoded, err := DecodeConfig(fs, cfg)
 	c.Assert(err, qt.IsNil)
 
-	c.Assert(len(decoded), qt.Equals, 5)
+	c.Assert(len(decoded), qt.Equals, 12)
+
+	c.Assert(decoded["images"].MaxSize, qt.Equals, int64(2000000000))
+	c.Assert(decoded["images"].Compression, qt.Equals, "")
+	c.Assert(decoded["getresource"].MaxSize, qt.Equals, int64(1024))
+	c.Assert(decoded["getresource"].Compression, qt.Equals, "gzip")
 
 	c2 := decoded["getcsv"]
 	c.Assert(c2.MaxAge.String(), qt.Equals, "11h0m0s")
@@ -103,7 +113,7 @@ dir = "/path/to/c3"
 	decoded, err := DecodeConfig(fs, cfg)
 	c.Assert(err, qt.IsNil)
 
-	c.Assert(len(decoded), qt.Equals, 5)
+	c.Assert(len(decoded), qt.Equals, 12)
 
 	for _, v := range decoded {
 		c.Assert(v.MaxAge, qt.Equals, time.Duration(0))
@@ -129,7 +139,7 @@ func TestDecodeConfigDefault(t *testing.T) {
 
 	c.Assert(err, qt.IsNil)
 
-	c.Assert(len(decoded), qt.Equals, 5)
+	c.Assert(len(decoded), qt.Equals, 12)
 
 	imgConfig := decoded[cacheKeyImages]
 	jsonConfig := decoded[cacheKeyGetJSON]
@@ -190,3 +200,25 @@ func newTestConfig() config.Provider {
 
 	return cfg
 }
+
+func TestDecodeConfigInvalid(t *testing.T) {
+	t.Parallel()
+
+	c := qt.New(t)
+
+	for _, invalid := range []string{
+		`[caches.getjson]
+maxSize = "2 foos"`,
+		`[caches.getjson]
+maxSize = -1`,
+		`[caches.getjson]
+compression = "zip"`,
+		`[caches.images]
+compression = "gzip"`,
+	} {
+		cfg, err := config.FromConfigString(invalid, "toml")
+		c.Assert(err, qt.IsNil)
+		_, err = DecodeConfig(afero.NewMemMapFs(), cfg)
+		c.Assert(err, qt.Not(qt.IsNil), qt.Commentf(invalid))
+	}
+}
@@ -63,6 +63,10 @@ func (c *Cache) Prune(force bool) (int, error) {
 
 		name = cleanID(name)
 
+		if name == cacheIndexFilename {
+			return nil
+		}
+
 		if info.IsDir() {
 			f, err := c.Fs.Open(name)
 			if err != nil {
@@ -87,7 +91,7 @@ func (c *Cache) Prune(force bool) (int, error) {
 
 		if !shouldRemove && len(c.nlocker.seen) > 0 {
 			// Remove it if it's not been touched/used in the last build.
-			_, seen := c.nlocker.seen[name]
+			_, seen := c.nlocker.seen[c.id(name)]
 			shouldRemove = !seen
 		}
 
@@ -0,0 +1,67 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !nodeploy
+
+package filecache
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"gocloud.dev/blob"
+	_ "gocloud.dev/blob/fileblob" // import
+	_ "gocloud.dev/blob/gcsblob"  // import
+	_ "gocloud.dev/blob/s3blob"   // import
+	"gocloud.dev/gcerrors"
+)
+
+// blobBackend stores the cache entries in a bucket, e.g.
+// s3://my-bucket/hugo?region=us-east-1. For buckets in the cloud, the path
+// in the URL is used as a key prefix.
+type blobBackend struct {
+	bucket *blob.Bucket
+}
+
+func newBlobBackend(u *url.URL) (remoteBackend, error) {
+	var prefix string
+	if u.Scheme != "file" {
+		uu := *u
+		prefix = strings.Trim(uu.Path, "/")
+		uu.Path = ""
+		u = &uu
+	}
+
+	bucket, err := blob.OpenBucket(context.Background(), u.String())
+	if err != nil {
+		return nil, err
+	}
+	if prefix != "" {
+		bucket = blob.PrefixedBucket(bucket, prefix+"/")
+	}
+
+	return &blobBackend{bucket: bucket}, nil
+}
+
+func (b *blobBackend) get(ctx context.Context, key string) ([]byte, error) {
+	d, err := b.bucket.ReadAll(ctx, key)
+	if gcerrors.Code(err) == gcerrors.NotFound {
+		return nil, nil
+	}
+	return d, err
+}
+
+func (b *blobBackend) put(ctx context.Context, key string, d []byte) error {
+	return b.bucket.WriteAll(ctx, key, d, nil)
+}
@@ -0,0 +1,194 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filecache
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/common/hugio"
+	"github.com/gohugoio/hugo/common/loggers"
+	"github.com/gohugoio/hugo/config"
+	"github.com/spf13/afero"
+	jww "github.com/spf13/jwalterweatherman"
+)
+
+func TestRemoteCache(t *testing.T) {
+	t.Parallel()
+	c := qt.New(t)
+
+	var (
+		mu    sync.Mutex
+		store = make(map[string][]byte)
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case "GET":
+			b, found := store[r.URL.Path]
+			if !found {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write(b)
+		case "PUT":
+			b, _ := ioutil.ReadAll(r.Body)
+			store[r.URL.Path] = b
+		}
+	}))
+	defer srv.Close()
+
+	configTemplate := `
+resourceDir = "myresources"
+contentDir = "content"
+dataDir = "data"
+i18nDir = "i18n"
+layoutDir = "layouts"
+assetDir = "assets"
+archetypeDir = "archetypes"
+
+[caches.getresource]
+dir = "/cache/c"
+maxAge = %s
+remote = %q
+remoteReadOnly = %t
+`
+
+	// Every cache has its own local file system, like an ephemeral CI runner.
+	newCacheWithMaxAge := func(c *qt.C, maxAge, remote string, readOnly bool) *Cache {
+		p := newPathsSpec(t, afero.NewMemMapFs(), fmt.Sprintf(configTemplate, maxAge, remote, readOnly))
+		caches, err := NewCaches(p)
+		c.Assert(err, qt.IsNil)
+		return caches.GetResourceCache()
+	}
+	newCache := func(c *qt.C, remote string, readOnly bool) *Cache {
+		return newCacheWithMaxAge(c, "-1", remote, readOnly)
+	}
+
+	for _, remote := range []string{srv.URL + "/hugo", "file://" + t.TempDir()} {
+		c.Run(remote, func(c *qt.C) {
+			created := 0
+			create := func() ([]byte, error) {
+				created++
+				return []byte("data"), nil
+			}
+
+			_, b, err := newCache(c, remote, false).GetOrCreateBytes("a/b.txt", create)
+			c.Assert(err, qt.IsNil)
+			c.Assert(string(b), qt.Equals, "data")
+			c.Assert(created, qt.Equals, 1)
+
+			ca := newCache(c, remote, false)
+			_, b, err = ca.GetOrCreateBytes("a/b.txt", create)
+			c.Assert(err, qt.IsNil)
+			c.Assert(string(b), qt.Equals, "data")
+			c.Assert(created, qt.Equals, 1)
+			// It's now in the local cache.
+			c.Assert(ca.getString("a/b.txt"), qt.Equals, "data")
+
+			_, w, err := newCache(c, remote, false).WriteCloser("c.txt")
+			c.Assert(err, qt.IsNil)
+			w.Write([]byte("written"))
+			c.Assert(w.Close(), qt.IsNil)
+			_, r, err := newCache(c, remote, false).Get("c.txt")
+			c.Assert(err, qt.IsNil)
+			c.Assert(r, qt.Not(qt.IsNil))
+			b, _ = ioutil.ReadAll(r)
+			r.Close()
+			c.Assert(string(b), qt.Equals, "written")
+
+			_, r, err = newCache(c, remote, true).GetOrCreate("d.txt", func() (io.ReadCloser, error) {
+				return hugio.ToReadCloser(strings.NewReader("read only")), nil
+			})
+			c.Assert(err, qt.IsNil)
+			r.Close()
+			_, r, err = newCache(c, remote, false).Get("d.txt")
+			c.Assert(err, qt.IsNil)
+			c.Assert(r, qt.IsNil)
+
+			// maxAge applies to the time the remote entry was created.
+			ca = newCache(c, remote, false)
+			modTime := time.Now().Add(-2 * time.Hour).Truncate(time.Second)
+			ca.remote.put("old.txt", modTime, []byte("old"))
+			_, r, err = newCacheWithMaxAge(c, `"1h"`, remote, false).Get("old.txt")
+			c.Assert(err, qt.IsNil)
+			c.Assert(r, qt.IsNil)
+			ca = newCacheWithMaxAge(c, `"3h"`, remote, false)
+			_, r, err = ca.Get("old.txt")
+			c.Assert(err, qt.IsNil)
+			c.Assert(r, qt.Not(qt.IsNil))
+			r.Close()
+			fi, err := ca.Fs.Stat("old.txt")
+			c.Assert(err, qt.IsNil)
+			c.Assert(fi.ModTime().Equal(modTime), qt.IsTrue)
+		})
+	}
+
+	c.Run("Older entries", func(c *qt.C) {
+		ca := newCache(c, srv.URL+"/hugo", false)
+		mu.Lock()
+		store["/hugo/"+ca.remote.remoteKey("e.txt")] = []byte("no header")
+		mu.Unlock()
+		_, r, err := ca.Get("e.txt")
+		c.Assert(err, qt.IsNil)
+		c.Assert(r, qt.IsNil)
+	})
+
+	c.Run("Failing", func(c *qt.C) {
+		var buf bytes.Buffer
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer srv.Close()
+
+		p := newPathsSpec(t, afero.NewMemMapFs(), fmt.Sprintf(configTemplate, "-1", srv.URL, false))
+		caches, err := NewCaches(p)
+		c.Assert(err, qt.IsNil)
+		caches.SetLogger(loggers.NewBasicLoggerForWriter(jww.LevelWarn, &buf))
+
+		for i := 0; i < 3; i++ {
+			_, b, err := caches.GetResourceCache().GetOrCreateBytes(fmt.Sprintf("f%d", i), func() ([]byte, error) {
+				return []byte("data"), nil
+			})
+			c.Assert(err, qt.IsNil)
+			c.Assert(string(b), qt.Equals, "data")
+		}
+		c.Assert(strings.Count(buf.String(), "disabled the remote"), qt.Equals, 1)
+		c.Assert(buf.String(), qt.Contains, "500 Internal Server Error")
+	})
+
+	c.Run("Invalid config", func(c *qt.C) {
+		for _, invalid := range []string{
+			`[caches.images]
+remote = "ftp://example.org"`,
+			`[caches.modules]
+remote = "https://example.org"`,
+		} {
+			cfg, err := config.FromConfigString(invalid, "toml")
+			c.Assert(err, qt.IsNil)
+			_, err = DecodeConfig(afero.NewMemMapFs(), cfg)
+			c.Assert(err, qt.Not(qt.IsNil))
+		}
+	})
+}
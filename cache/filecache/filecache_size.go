@@ -0,0 +1,245 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filecache
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/gohugoio/hugo/hugofs"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+// cacheIndexFilename is the name of the index stored in the root of caches
+// with a maxSize.
+const cacheIndexFilename = ".hugo_cache_index.json"
+
+// cacheIndex tracks when the entries in a cache were last used and the
+// evictions done.
+type cacheIndex struct {
+	// Maps file names to the Unix time they were last used.
+	LastUsed map[string]int64 `json:"lastUsed"`
+
+	Evicted      int       `json:"evicted"`
+	EvictedBytes int64     `json:"evictedBytes"`
+	LastEviction time.Time `json:"lastEviction"`
+}
+
+func (c *Cache) readIndex() cacheIndex {
+	var idx cacheIndex
+	if b, err := afero.ReadFile(c.Fs, cacheIndexFilename); err == nil {
+		json.Unmarshal(b, &idx)
+	}
+	if idx.LastUsed == nil {
+		idx.LastUsed = make(map[string]int64)
+	}
+	return idx
+}
+
+func (c *Cache) writeIndex(idx cacheIndex) error {
+	b, err := json.Marshal(idx)
+	if err != nil {
+		return err
+	}
+	return afero.WriteFile(c.Fs, cacheIndexFilename, b, 0666)
+}
+
+type cacheEntry struct {
+	name     string
+	size     int64
+	lastUsed int64
+}
+
+// walk calls fn for every entry in the cache.
+func (c *Cache) walk(fn func(name string, info os.FileInfo)) error {
+	err := afero.Walk(c.Fs, "", func(name string, info os.FileInfo, err error) error {
+		if info == nil || info.IsDir() {
+			return nil
+		}
+		name = cleanID(name)
+		if name == cacheIndexFilename {
+			return nil
+		}
+		fn(name, info)
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// Evict removes the least recently used entries from the caches with a
+// maxSize until they are below it, returning the number of entries removed.
+// The entries used since the cache was created count as used now.
+func (f Caches) Evict() (int, error) {
+	counter := 0
+	for k, c := range f {
+		count, err := c.Evict()
+		counter += count
+		if err != nil {
+			return counter, errors.Wrapf(err, "failed to evict entries from cache %q", k)
+		}
+	}
+	return counter, nil
+}
+
+// Evict removes the least recently used entries until the cache is below
+// its maxSize, returning the number of entries removed. The modules cache is
+// removed in full when it is too big.
+func (c *Cache) Evict() (int, error) {
+	if c.maxSize <= 0 {
+		return 0, nil
+	}
+
+	idx := c.readIndex()
+	now := time.Now().Unix()
+
+	c.nlocker.seenMu.RLock()
+	for id := range c.nlocker.seen {
+		idx.LastUsed[c.name(id)] = now
+	}
+	c.nlocker.seenMu.RUnlock()
+
+	var (
+		entries []cacheEntry
+		total   int64
+	)
+	lastUsed := make(map[string]int64)
+	if err := c.walk(func(name string, info os.FileInfo) {
+		e := cacheEntry{name: name, size: info.Size(), lastUsed: info.ModTime().Unix()}
+		if t, found := idx.LastUsed[name]; found && t > e.lastUsed {
+			e.lastUsed = t
+		}
+		lastUsed[name] = e.lastUsed
+		entries = append(entries, e)
+		total += e.size
+	}); err != nil {
+		return 0, err
+	}
+	idx.LastUsed = lastUsed
+
+	if total <= c.maxSize {
+		return 0, c.writeIndex(idx)
+	}
+
+	var (
+		counter int
+		evicted int64
+	)
+
+	if c.pruneAllRootDir != "" {
+		count, err := hugofs.MakeReadableAndRemoveAllModulePkgDir(c.Fs, c.pruneAllRootDir)
+		if err != nil {
+			return count, err
+		}
+		counter, evicted = count, total
+		idx.LastUsed = make(map[string]int64)
+	} else {
+		sort.Slice(entries, func(i, j int) bool {
+			if entries[i].lastUsed == entries[j].lastUsed {
+				return entries[i].name < entries[j].name
+			}
+			return entries[i].lastUsed < entries[j].lastUsed
+		})
+
+		for _, e := range entries {
+			if total <= c.maxSize {
+				break
+			}
+			if err := c.Fs.Remove(e.name); err != nil {
+				if os.IsNotExist(err) {
+					continue
+				}
+				return counter, err
+			}
+			delete(idx.LastUsed, e.name)
+			total -= e.size
+			evicted += e.size
+			counter++
+		}
+	}
+
+	idx.Evicted += counter
+	idx.EvictedBytes += evicted
+	idx.LastEviction = time.Now()
+
+	return counter, c.writeIndex(idx)
+}
+
+// Stats holds statistics about a file cache.
+type Stats struct {
+	Name string
+
+	// The configuration.
+	Dir         string
+	MaxAge      time.Duration
+	MaxSize     int64
+	Compression string
+	Remote      string
+
+	// The number of entries and their total size on disk.
+	Entries int
+	Size    int64
+
+	// The evictions done because of the maxSize, in total.
+	Evicted      int
+	EvictedBytes int64
+	LastEviction time.Time
+}
+
+// Stats returns statistics about all the caches, sorted by name.
+func (f Caches) Stats() ([]Stats, error) {
+	var stats []Stats
+	for k, c := range f {
+		s, err := c.Stats()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read cache %q", k)
+		}
+		s.Name = k
+		stats = append(stats, s)
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Name < stats[j].Name
+	})
+
+	return stats, nil
+}
+
+// Stats returns statistics about this cache.
+func (c *Cache) Stats() (Stats, error) {
+	s := Stats{
+		Dir:         c.cfg.Dir,
+		MaxAge:      c.maxAge,
+		MaxSize:     c.maxSize,
+		Compression: c.cfg.Compression,
+		Remote:      c.cfg.Remote,
+	}
+
+	if err := c.walk(func(name string, info os.FileInfo) {
+		s.Entries++
+		s.Size += info.Size()
+	}); err != nil {
+		return s, err
+	}
+
+	idx := c.readIndex()
+	s.Evicted, s.EvictedBytes, s.LastEviction = idx.Evicted, idx.EvictedBytes, idx.LastEviction
+
+	return s, nil
+}
@@ -0,0 +1,124 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filecache
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/spf13/afero"
+)
+
+func TestEvict(t *testing.T) {
+	t.Parallel()
+	c := qt.New(t)
+
+	newCache := func(fs afero.Fs) *Cache {
+		ca := NewCache(fs, -1, "")
+		ca.maxSize = 25
+		return ca
+	}
+
+	fs := afero.NewMemMapFs()
+	ca := newCache(fs)
+	for i := 0; i < 5; i++ {
+		_, _, err := ca.GetOrCreateBytes(fmt.Sprintf("f%d", i), func() ([]byte, error) {
+			return []byte("0123456789"), nil
+		})
+		c.Assert(err, qt.IsNil)
+		// Make f0 the oldest.
+		mtime := time.Now().Add(time.Duration(i-10) * time.Hour)
+		c.Assert(fs.Chtimes(fmt.Sprintf("f%d", i), mtime, mtime), qt.IsNil)
+	}
+
+	// A new build using f0 and f3 only.
+	ca = newCache(fs)
+	for _, id := range []string{"f0", "f3"} {
+		_, b, err := ca.GetBytes(id)
+		c.Assert(err, qt.IsNil)
+		c.Assert(string(b), qt.Equals, "0123456789")
+	}
+
+	count, err := ca.Evict()
+	c.Assert(err, qt.IsNil)
+	c.Assert(count, qt.Equals, 3)
+
+	for id, exists := range map[string]bool{"f0": true, "f1": false, "f2": false, "f3": true, "f4": false} {
+		_, err := fs.Stat(id)
+		c.Assert(err == nil, qt.Equals, exists, qt.Commentf(id))
+	}
+
+	stats, err := ca.Stats()
+	c.Assert(err, qt.IsNil)
+	c.Assert(stats.Entries, qt.Equals, 2)
+	c.Assert(stats.Size, qt.Equals, int64(20))
+	c.Assert(stats.Evicted, qt.Equals, 3)
+	c.Assert(stats.EvictedBytes, qt.Equals, int64(30))
+	c.Assert(stats.LastEviction.IsZero(), qt.IsFalse)
+
+	// Below the limit.
+	count, err = newCache(fs).Evict()
+	c.Assert(err, qt.IsNil)
+	c.Assert(count, qt.Equals, 0)
+
+	c.Run("Modules", func(c *qt.C) {
+		fs := afero.NewMemMapFs()
+		c.Assert(afero.WriteFile(fs, "pkg/mod/github.com/bep/a@v1.0.0/a.txt", []byte(strings.Repeat("a", 30)), 0444), qt.IsNil)
+		ca := NewCache(fs, -1, "pkg")
+		ca.maxSize = 25
+		count, err := ca.Evict()
+		c.Assert(err, qt.IsNil)
+		c.Assert(count > 0, qt.IsTrue)
+		_, err = fs.Stat("pkg")
+		c.Assert(os.IsNotExist(err), qt.IsTrue)
+	})
+}
+
+func TestFileCacheCompression(t *testing.T) {
+	t.Parallel()
+	c := qt.New(t)
+
+	fs := afero.NewMemMapFs()
+	ca := NewCache(fs, -1, "")
+	ca.compress = true
+
+	content := strings.Repeat("Hugo ", 100)
+	_, _, err := ca.GetOrCreateBytes("a/b.json", func() ([]byte, error) {
+		return []byte(content), nil
+	})
+	c.Assert(err, qt.IsNil)
+
+	fi, err := fs.Stat("a/b.json.gz")
+	c.Assert(err, qt.IsNil)
+	c.Assert(fi.Size() < int64(len(content)), qt.IsTrue)
+
+	_, b, err := ca.GetBytes("a/b.json")
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, content)
+
+	_, w, err := ca.WriteCloser("c.json")
+	c.Assert(err, qt.IsNil)
+	w.Write([]byte(content))
+	c.Assert(w.Close(), qt.IsNil)
+	c.Assert(ca.getString("c.json"), qt.Equals, content)
+
+	// Used in this build, so not pruned.
+	count, err := ca.Prune(false)
+	c.Assert(err, qt.IsNil)
+	c.Assert(count, qt.Equals, 0)
+}
@@ -0,0 +1,123 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/gohugoio/hugo/cache/filecache"
+	"github.com/spf13/cobra"
+)
+
+var _ cmder = (*cacheCmd)(nil)
+
+type cacheCmd struct {
+	*baseBuilderCmd
+}
+
+func (b *commandsBuilder) newCacheCmd() *cacheCmd {
+	c := &cacheCmd{}
+
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Inspect the file caches",
+		Long: `Inspect the file caches.
+
+Cache requires a subcommand, e.g. ` + "`hugo cache stats`.",
+		RunE: nil,
+	}
+
+	var format string
+	statsCmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Print the size and eviction statistics of the file caches",
+		Long: `Print the number of entries, the size and the eviction statistics of the file
+caches for the current project, together with their maxAge, maxSize and compression
+settings.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			com, err := initializeConfig(true, false, &c.hugoBuilderCommon, c, nil)
+			if err != nil {
+				return err
+			}
+
+			stats, err := com.hugo().FileCaches.Stats()
+			if err != nil {
+				return err
+			}
+
+			switch format {
+			case "text":
+				return writeCacheStats(cmd.OutOrStdout(), stats)
+			case "json":
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				return enc.Encode(stats)
+			default:
+				return newUserError(fmt.Sprintf("invalid format %q, must be \"text\" or \"json\"", format))
+			}
+		},
+	}
+	statsCmd.Flags().StringVarP(&format, "format", "", "text", `output format, one of "text" or "json"`)
+
+	cmd.AddCommand(statsCmd)
+
+	c.baseBuilderCmd = b.newBuilderCmd(cmd)
+
+	return c
+}
+
+func writeCacheStats(w io.Writer, stats []filecache.Stats) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tENTRIES\tSIZE\tMAX SIZE\tMAX AGE\tCOMPRESSION\tEVICTED\tLAST EVICTION\tDIR")
+
+	var (
+		entries int
+		size    int64
+	)
+	for _, s := range stats {
+		entries += s.Entries
+		size += s.Size
+
+		maxSize := "-"
+		if s.MaxSize > 0 {
+			maxSize = humanize.Bytes(uint64(s.MaxSize))
+		}
+		maxAge := "forever"
+		switch {
+		case s.MaxAge == 0:
+			maxAge = "disabled"
+		case s.MaxAge > 0:
+			maxAge = s.MaxAge.String()
+		}
+		compression := s.Compression
+		if compression == "" {
+			compression = "none"
+		}
+		evicted, lastEviction := "-", "-"
+		if s.Evicted > 0 {
+			evicted = fmt.Sprintf("%d (%s)", s.Evicted, humanize.Bytes(uint64(s.EvictedBytes)))
+			lastEviction = s.LastEviction.Format(time.RFC3339)
+		}
+
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", s.Name, s.Entries, humanize.Bytes(uint64(s.Size)), maxSize, maxAge, compression, evicted, lastEviction, s.Dir)
+	}
+	fmt.Fprintf(tw, "total\t%d\t%s\t\t\t\t\t\t\n", entries, humanize.Bytes(uint64(size)))
+
+	return tw.Flush()
+}
@@ -0,0 +1,72 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/cache/filecache"
+)
+
+func TestCacheStats(t *testing.T) {
+	c := qt.New(t)
+
+	dir, clean, err := createSimpleTestSite(t, testSiteConfig{configTOML: `
+baseURL = "https://example.org"
+[caches.getjson]
+dir = ":resourceDir/cache"
+maxSize = "1KB"
+compression = "gzip"
+`})
+	c.Assert(err, qt.IsNil)
+	defer clean()
+
+	cacheDir := filepath.Join(dir, "resources", "cache", "filecache", "getjson")
+	writeFile(t, filepath.Join(cacheDir, "a.json.gz"), "aaaa")
+	writeFile(t, filepath.Join(cacheDir, "b.json.gz"), "bbbbbb")
+
+	var buf bytes.Buffer
+	cmd := newCommandsBuilder().addAll().build().getCommand()
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"-s=" + dir, "cache", "stats", "--format", "json"})
+	_, err = cmd.ExecuteC()
+	c.Assert(err, qt.IsNil)
+
+	var stats []filecache.Stats
+	c.Assert(json.Unmarshal(buf.Bytes(), &stats), qt.IsNil)
+	var found bool
+	for _, s := range stats {
+		if s.Name == "getjson" {
+			found = true
+			c.Assert(s.Entries, qt.Equals, 2)
+			c.Assert(s.Size, qt.Equals, int64(10))
+			c.Assert(s.MaxSize, qt.Equals, int64(1000))
+			c.Assert(s.Compression, qt.Equals, "gzip")
+		}
+	}
+	c.Assert(found, qt.IsTrue)
+
+	buf.Reset()
+	cmd = newCommandsBuilder().addAll().build().getCommand()
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"-s=" + dir, "cache", "stats"})
+	_, err = cmd.ExecuteC()
+	c.Assert(err, qt.IsNil)
+	c.Assert(buf.String(), qt.Contains, "NAME")
+	c.Assert(buf.String(), qt.Matches, `(?s).*getjson\s+2\s+10 B\s+1.0 kB\s+forever\s+gzip.*`)
+}
@@ -25,11 +25,15 @@ type checkCmd struct {
 	*baseCmd
 }
 
-func newCheckCmd() *checkCmd {
-	return &checkCmd{baseCmd: &baseCmd{
+func (b *commandsBuilder) newCheckCmd() *checkCmd {
+	cc := &checkCmd{baseCmd: &baseCmd{
 		cmd: &cobra.Command{
 			Use:   "check",
 			Short: "Contains some verification checks",
 		},
 	}}
+
+	cc.cmd.AddCommand(b.newCheckLinksCmd().getCommand())
+
+	return cc
 }
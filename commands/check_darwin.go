@@ -23,7 +23,7 @@ type checkCmd struct {
 	*baseCmd
 }
 
-func newCheckCmd() *checkCmd {
+func (b *commandsBuilder) newCheckCmd() *checkCmd {
 	cc := &checkCmd{baseCmd: &baseCmd{
 		cmd: &cobra.Command{
 			Use:   "check",
@@ -31,7 +31,10 @@ func newCheckCmd() *checkCmd {
 		},
 	}}
 
-	cc.cmd.AddCommand(newLimitCmd().getCommand())
+	cc.cmd.AddCommand(
+		newLimitCmd().getCommand(),
+		b.newCheckLinksCmd().getCommand(),
+	)
 
 	return cc
 }
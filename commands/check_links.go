@@ -0,0 +1,127 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/gohugoio/hugo/hugolib"
+	"github.com/gohugoio/hugo/linkcheck"
+	"github.com/spf13/cobra"
+)
+
+var _ cmder = (*checkLinksCmd)(nil)
+
+type checkLinksCmd struct {
+	*baseBuilderCmd
+
+	external    bool
+	format      string
+	output      string
+	ignore      []string
+	concurrency int
+}
+
+func (b *commandsBuilder) newCheckLinksCmd() *checkLinksCmd {
+	cc := &checkLinksCmd{}
+
+	cmd := &cobra.Command{
+		Use:   "links",
+		Short: "Check the links in the rendered site",
+		Long: `Build the site in memory and check that all links in the HTML files point
+to a published file. With --external the links to other sites are checked too.
+
+Valid external links are cached between runs in the linkcheck file cache.
+The command fails if any broken links are found.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cc.check()
+		},
+	}
+
+	cmd.Flags().BoolVar(&cc.external, "external", false, "also check links to other sites")
+	cmd.Flags().StringVar(&cc.format, "format", linkcheck.FormatText, "the report format, one of text, json or junit")
+	cmd.Flags().StringVarP(&cc.output, "output", "o", "", "write the report to this file instead of stdout")
+	cmd.Flags().StringSliceVar(&cc.ignore, "ignore", nil, "regular expressions matching links that should not be checked")
+	cmd.Flags().IntVar(&cc.concurrency, "concurrency", 0, "the maximum number of external links checked at the same time")
+
+	cc.baseBuilderCmd = b.newBuilderBasicCmd(cmd)
+
+	return cc
+}
+
+func (cc *checkLinksCmd) check() error {
+	cfgInit := func(c *commandeer) error {
+		c.Set("renderToMemory", true)
+		return nil
+	}
+
+	c, err := initializeConfig(true, false, &cc.hugoBuilderCommon, cc, cfgInit)
+	if err != nil {
+		return err
+	}
+
+	lc, err := linkcheck.DecodeConfig(c.Cfg)
+	if err != nil {
+		return err
+	}
+	lc.Ignore = append(lc.Ignore, cc.ignore...)
+	if cc.concurrency > 0 {
+		lc.Concurrency = cc.concurrency
+	}
+
+	sites, err := hugolib.NewHugoSites(*c.DepsCfg)
+	if err != nil {
+		return newSystemError("Error creating sites", err)
+	}
+
+	if err := sites.Build(hugolib.BuildCfg{}); err != nil {
+		return newSystemError("Error building sites", err)
+	}
+
+	checker, err := linkcheck.New(lc, sites.BaseFs.PublishFs, c.Cfg.GetString("baseURL"), sites.FileCaches.LinkCheckCache())
+	if err != nil {
+		return err
+	}
+
+	report, err := checker.Check(context.Background(), cc.external)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if cc.output != "" {
+		f, err := os.Create(cc.output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	} else if cc.quiet {
+		w = ioutil.Discard
+	}
+
+	if err := report.Write(w, cc.format); err != nil {
+		return err
+	}
+
+	if report.Broken > 0 {
+		return newUserError(fmt.Sprintf("found %d broken link(s)", report.Broken))
+	}
+
+	return nil
+}
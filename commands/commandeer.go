@@ -20,6 +20,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strings"
 	"sync"
 	"time"
 
@@ -58,8 +59,9 @@ type commandeerHugoState struct {
 type commandeer struct {
 	*commandeerHugoState
 
-	logger       loggers.Logger
-	serverConfig *config.Server
+	logger        loggers.Logger
+	serverConfig  *config.Server
+	watcherConfig *config.Watcher
 
 	// Currently only set when in "fast render mode". But it seems to
 	// be fast enough that we could maybe just add it for all server modes.
@@ -126,8 +128,19 @@ func (c *commandeer) getErrorWithContext() interface{} {
 	m["Version"] = hugo.BuildVersionString()
 
 	fe := herrors.UnwrapErrorWithFileContext(c.buildErr)
+
+	if stack := c.templateCallStack(c.buildErr); len(stack) > 0 {
+		m["TemplateStack"] = stack
+		// Show the source of the template where the error happened.
+		if inner := stack[len(stack)-1]; inner.File != nil {
+			fe = inner.File
+		}
+	}
+
 	if fe != nil {
 		m["File"] = fe
+		pos := fe.Position()
+		m["EditorURL"] = c.editorURL(pos.Filename, pos.LineNumber, pos.ColumnNumber)
 	}
 
 	if c.h.verbose {
@@ -187,9 +200,24 @@ type fileChangeDetector struct {
 	current map[string]string
 	prev    map[string]string
 
+	// The last file published for every name with any fingerprint removed.
+	published map[string]string
+
 	irrelevantRe *regexp.Regexp
 }
 
+// fingerprintRe matches the fingerprint added by resources.Fingerprint.
+var fingerprintRe = regexp.MustCompile(`\.[0-9a-f]{32,128}(\.[^./\\]+)$`)
+
+// previous returns the file published in an earlier build that name
+// replaces. This is name itself, or, if name is fingerprinted, the file with
+// a different fingerprint. It returns an empty string if there is none.
+func (f *fileChangeDetector) previous(name string) string {
+	f.Lock()
+	defer f.Unlock()
+	return f.published[fingerprintRe.ReplaceAllString(name, "$1")]
+}
+
 func (f *fileChangeDetector) OnFileClose(name, md5sum string) {
 	f.Lock()
 	defer f.Unlock()
@@ -234,9 +262,14 @@ func (f *fileChangeDetector) PrepareNew() {
 	if f.current == nil {
 		f.current = make(map[string]string)
 		f.prev = make(map[string]string)
+		f.published = make(map[string]string)
 		return
 	}
 
+	for k := range f.current {
+		f.published[fingerprintRe.ReplaceAllString(k, "$1")] = k
+	}
+
 	f.prev = make(map[string]string)
 	for k, v := range f.current {
 		f.prev[k] = v
@@ -345,6 +378,27 @@ func (c *commandeer) loadConfig(mustHaveConfigFile, running bool) error {
 	if err != nil {
 		return err
 	}
+	c.watcherConfig, err = hconfig.DecodeWatcher(cfg.Cfg)
+	if err != nil {
+		return err
+	}
+
+	if running && !c.serverConfig.DisableHostConfig {
+		// Emulate the headers and redirects of the host platform.
+		hs, err := hconfig.DecodeHostServer(sourceFs, dir, filepath.Join(dir, config.GetString("staticDir")))
+		if err != nil {
+			return err
+		}
+		for _, warning := range hs.Warnings {
+			logger.Warnln(warning)
+		}
+		if len(hs.Filenames) > 0 {
+			logger.Infoln("Using headers and redirects from", strings.Join(hs.Filenames, ", "))
+		}
+		c.serverConfig.Headers = append(c.serverConfig.Headers, hs.Headers...)
+		c.serverConfig.Redirects = append(c.serverConfig.Redirects, hs.Redirects...)
+		c.configFiles = append(c.configFiles, hs.Filenames...)
+	}
 
 	createMemFs := config.GetBool("renderToMemory")
 
@@ -356,6 +410,16 @@ func (c *commandeer) loadConfig(mustHaveConfigFile, running bool) error {
 	c.fsCreate.Do(func() {
 		fs := hugofs.NewFrom(sourceFs, config)
 
+		var statCacheCfg hugofs.StatCacheConfig
+		statCacheCfg, err = hugofs.DecodeStatCacheConfig(config)
+		if err != nil {
+			close(c.created)
+			return
+		}
+		if statCacheCfg.Enable {
+			fs.Source = hugofs.NewStatCacheFs(fs.Source, statCacheCfg)
+		}
+
 		if c.destinationFs != nil {
 			// Need to reuse the destination on server rebuilds.
 			fs.Destination = c.destinationFs
@@ -364,11 +428,12 @@ func (c *commandeer) loadConfig(mustHaveConfigFile, running bool) error {
 			fs.Destination = new(afero.MemMapFs)
 		}
 
-		if c.fastRenderMode {
-			// For now, fast render mode only. It should, however, be fast enough
-			// for the full variant, too.
+		if c.fastRenderMode || config.GetBool("navigateToChanged") || config.GetBool("navigateToChangedIfAffected") {
+			// The full rebuilds with fast render mode disabled reload all
+			// browsers, so there's no need to hash every published file.
 			changeDetector := &fileChangeDetector{
-				// We use this detector to decide to do a Hot reload of a single path or not.
+				// We use this detector to decide to do a Hot reload of a single path or not,
+				// and to find the pages affected by a change.
 				// We need to filter out source maps and possibly some other to be able
 				// to make that decision.
 				irrelevantRe: regexp.MustCompile(`\.map$`),
@@ -46,15 +46,18 @@ func (b *commandsBuilder) addAll() *commandsBuilder {
 	b.addCommands(
 		b.newServerCmd(),
 		newVersionCmd(),
-		newEnvCmd(),
+		b.newEnvCmd(),
 		b.newConfigCmd(),
-		newCheckCmd(),
+		b.newCheckCmd(),
 		b.newDeployCmd(),
 		b.newConvertCmd(),
 		b.newNewCmd(),
 		b.newListCmd(),
+		b.newLintCmd(),
+		b.newI18nCmd(),
+		b.newCacheCmd(),
 		newImportCmd(),
-		newGenCmd(),
+		b.newGenCmd(),
 		createReleaser(),
 		b.newModCmd(),
 	)
@@ -184,8 +187,10 @@ Complete documentation is available at http://gohugo.io/.`,
 	cc.cmd.PersistentFlags().BoolVar(&cc.logging, "log", false, "enable Logging")
 	cc.cmd.PersistentFlags().StringVar(&cc.logFile, "logFile", "", "log File path (if set, logging enabled automatically)")
 	cc.cmd.PersistentFlags().BoolVar(&cc.verboseLog, "verboseLog", false, "verbose logging")
+	cc.cmd.PersistentFlags().StringVar(&cc.logFormat, "logFormat", "text", "log format, text or json")
 
 	cc.cmd.Flags().BoolVarP(&cc.buildWatch, "watch", "w", false, "watch filesystem for changes and recreate as needed")
+	cc.cmd.Flags().StringVar(&cc.poll, "poll", "", "set this to a poll interval, e.g --poll 700ms, to poll for file system changes when watching; the interval backs off when nothing changes")
 
 	cc.cmd.Flags().Bool("renderToMemory", false, "render to memory (only useful for benchmark testing)")
 
@@ -205,6 +210,13 @@ type hugoBuilderCommon struct {
 
 	buildWatch bool
 
+	// A poll interval, e.g. 700ms, to poll for file changes when watching.
+	poll string
+
+	// Log the watcher backend, the watched paths and the dropped events.
+	// Only set with hugo server.
+	debugWatch bool
+
 	gc bool
 
 	// Profile flags (for debugging of performance problems)
@@ -221,9 +233,10 @@ type hugoBuilderCommon struct {
 	debug      bool
 	quiet      bool
 
-	cfgFile string
-	cfgDir  string
-	logFile string
+	cfgFile   string
+	cfgDir    string
+	logFile   string
+	logFormat string
 }
 
 func (cc *hugoBuilderCommon) timeTrack(start time.Time, name string) {
@@ -231,7 +244,12 @@ func (cc *hugoBuilderCommon) timeTrack(start time.Time, name string) {
 		return
 	}
 	elapsed := time.Since(start)
-	fmt.Printf("%s in %v ms\n", name, int(1000*elapsed.Seconds()))
+	msg := fmt.Sprintf("%s in %v ms", name, int(1000*elapsed.Seconds()))
+	if loggers.IsJSON() {
+		loggers.NewEntry("info", msg).Write(os.Stdout)
+		return
+	}
+	fmt.Println(msg)
 }
 
 func (cc *hugoBuilderCommon) getConfigDir(baseDir string) string {
@@ -290,15 +308,24 @@ func (cc *hugoBuilderCommon) handleFlags(cmd *cobra.Command) {
 	cmd.Flags().StringSliceP("theme", "t", []string{}, "themes to use (located in /themes/THEMENAME/)")
 	cmd.Flags().StringVarP(&cc.baseURL, "baseURL", "b", "", "hostname (and path) to the root, e.g. http://spf13.com/")
 	cmd.Flags().Bool("enableGitInfo", false, "add Git revision, date and author info to the pages")
+	cmd.Flags().Bool("strict-frontmatter", false, "fail the build on front matter not matching the contentTypes schemas")
+	cmd.Flags().Bool("reproducible", false, "make the output the same in every build of the same source, using the time in SOURCE_DATE_EPOCH or of the last Git commit")
 	cmd.Flags().BoolVar(&cc.gc, "gc", false, "enable to run some cleanup tasks (remove unused cache files) after the build")
 
 	cmd.Flags().Bool("templateMetrics", false, "display metrics about template executions")
 	cmd.Flags().Bool("templateMetricsHints", false, "calculate some improvement hints when combined with --templateMetrics")
+	cmd.Flags().Bool("pageMetrics", false, "display render timings for the slowest pages")
+	cmd.Flags().Int("pageMetricsCount", 20, "the number of pages to display with --pageMetrics")
+	cmd.Flags().String("progress", "", "show the progress of the build phases on stderr, text or json")
+	cmd.Flags().String("errorsFile", "", "write the build errors with their codes and positions as JSON to this file, e.g. errors.json")
+	cmd.Flags().String("trace-endpoint", "", "export the build phases, template executions and resource transformations as OpenTelemetry traces to this OTLP/HTTP endpoint, e.g. http://localhost:4318")
 	cmd.Flags().BoolP("forceSyncStatic", "", false, "copy all files when static is changed.")
 	cmd.Flags().BoolP("noTimes", "", false, "don't sync modification time of files")
 	cmd.Flags().BoolP("noChmod", "", false, "don't sync permission mode of files")
+	cmd.Flags().String("staticPublishMode", "", "how to publish static files to disk, one of copy, hardlink or reflink")
 	cmd.Flags().BoolP("i18n-warnings", "", false, "print missing translations")
 	cmd.Flags().BoolP("path-warnings", "", false, "print warnings on duplicate target paths etc.")
+	cmd.Flags().BoolP("debug-memory", "", false, "print a report of the memory usage and the biggest objects retained in memory after the build")
 	cmd.Flags().StringVarP(&cc.cpuprofile, "profile-cpu", "", "", "write cpu profile to `file`")
 	cmd.Flags().StringVarP(&cc.memprofile, "profile-mem", "", "", "write memory profile to `file`")
 	cmd.Flags().BoolVarP(&cc.printm, "print-mem", "", false, "print memory usage to screen at intervals")
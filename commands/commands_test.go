@@ -14,6 +14,7 @@
 package commands
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -28,6 +29,7 @@ import (
 
 	"github.com/gohugoio/hugo/hugofs"
 
+	"github.com/gohugoio/hugo/common/hugo"
 	"github.com/gohugoio/hugo/common/types"
 
 	"github.com/spf13/cobra"
@@ -74,6 +76,85 @@ func TestExecute(t *testing.T) {
 		c.Assert(converted, qt.Equals, "{\n   \"title\": \"P1\",\n   \"weight\": 1\n}\n\nContent\n\n", qt.Commentf(converted))
 	})
 
+	c.Run("convert fields", func(c *qt.C) {
+		dir, clean := createSite(c)
+		defer clean()
+		mapping := filepath.Join(dir, "mapping.toml")
+		writeFile(t, mapping, `
+delete = ["weight"]
+[rename]
+title = "linkTitle"
+[defaults]
+toc = true
+`)
+		p1 := filepath.Join(dir, "content", "p1.md")
+		before := readFileFrom(c, p1)
+		p2 := filepath.Join(dir, "content", "p2.md")
+		writeFile(t, p2, "No front matter.\n")
+
+		out, err := captureStdout(func() error {
+			resp := Execute([]string{"convert", "fields", "-s=" + dir, "--mapping=" + mapping, "--diff"})
+			return resp.Err
+		})
+		c.Assert(err, qt.IsNil)
+		c.Assert(out, qt.Contains, "--- a/content/p1.md\n+++ b/content/p1.md\n")
+		c.Assert(out, qt.Contains, "-title: \"P1\"\n-weight: 1\n+linkTitle: P1\n+toc: true\n")
+		c.Assert(readFileFrom(c, p1), qt.Equals, before)
+
+		resp := Execute([]string{"convert", "fields", "-s=" + dir, "--mapping=" + mapping})
+		c.Assert(resp.Err.Error(), qt.Contains, "Unsafe operation not allowed")
+
+		resp = Execute([]string{"convert", "fields", "-s=" + dir, "--mapping=" + mapping, "--unsafe"})
+		c.Assert(resp.Err, qt.IsNil)
+		c.Assert(readFileFrom(c, p1), qt.Equals, "---\nlinkTitle: P1\ntoc: true\n---\n\nContent\n\n")
+		c.Assert(readFileFrom(c, p2), qt.Equals, "+++\ntoc = true\n\n+++\nNo front matter.\n")
+
+		resp = Execute([]string{"convert", "fields", "-s=" + dir})
+		c.Assert(resp.Err.Error(), qt.Contains, "needs a mapping file")
+	})
+
+	c.Run("convert content", func(c *qt.C) {
+		dir, clean := createSite(c)
+		defer clean()
+		rules := filepath.Join(dir, "rules.toml")
+		writeFile(t, rules, `
+relref = true
+[[shortcodes]]
+name = "old"
+rename = "new"
+`)
+		p2 := filepath.Join(dir, "content", "p2.md")
+		writeFile(t, p2, "---\ntitle: P2\n---\nSee [P1](/p1/).\n\n{{< old >}}\n")
+
+		out, err := captureStdout(func() error {
+			resp := Execute([]string{"convert", "content", "-s=" + dir, "--rules=" + rules, "--unsafe"})
+			return resp.Err
+		})
+		c.Assert(err, qt.IsNil)
+		c.Assert(out, qt.Contains, "content/p2.md: relref (1), shortcode old (1)")
+		c.Assert(readFileFrom(c, p2), qt.Equals, "---\ntitle: P2\n---\nSee [P1]({{< relref \"/p1.md\" >}}).\n\n{{< new >}}\n")
+
+		resp := Execute([]string{"convert", "content", "-s=" + dir, "--unsafe"})
+		c.Assert(resp.Err.Error(), qt.Contains, "needs a rules file")
+	})
+
+	c.Run("env --json", func(c *qt.C) {
+		dir, clean := createSite(c)
+		defer clean()
+		out, err := captureStdout(func() error {
+			return Execute([]string{"env", "--json", "-s=" + dir}).Err
+		})
+		c.Assert(err, qt.IsNil)
+		var env envInfo
+		c.Assert(json.Unmarshal([]byte(out), &env), qt.IsNil)
+		c.Assert(env.Version, qt.Equals, hugo.CurrentVersion.String())
+		c.Assert(env.ConfigError, qt.Equals, "")
+		c.Assert(env.Features["extended"], qt.Equals, hugo.IsExtended)
+		_, found := env.Features["dartSass"]
+		c.Assert(found, qt.IsTrue)
+		c.Assert(env.Caches["images"], qt.Equals, filepath.Join(dir, "resources", "_gen", "images"))
+	})
+
 	c.Run("config, set environment", func(c *qt.C) {
 		dir, clean := createSite(c)
 		defer clean()
@@ -182,6 +263,15 @@ func TestFlags(t *testing.T) {
 				c.Assert(cfg.Get("ignoreVendorPaths"), qt.Equals, "github.com/**")
 			},
 		},
+		{
+			name: "strict-frontmatter",
+			args: []string{"server", "--strict-frontmatter"},
+			check: func(c *qt.C, cmd *serverCmd) {
+				cfg := config.New()
+				cmd.flagsToConfig(cfg)
+				c.Assert(cfg.GetBool("strictFrontMatter"), qt.Equals, true)
+			},
+		},
 		{
 			name: "Persistent flags",
 			args: []string{
@@ -207,6 +297,9 @@ func TestFlags(t *testing.T) {
 				"--renderToDisk",
 				"--source=mysource",
 				"--path-warnings",
+				"--trace-endpoint=http://localhost:4318",
+				"--errorsFile=errors.json",
+				"--progress=json",
 			},
 			check: func(c *qt.C, sc *serverCmd) {
 				c.Assert(sc, qt.Not(qt.IsNil))
@@ -235,6 +328,10 @@ func TestFlags(t *testing.T) {
 
 				// The flag is named i18n-warnings
 				c.Assert(cfg.GetBool("logI18nWarnings"), qt.Equals, true)
+
+				c.Assert(cfg.GetString("traceEndpoint"), qt.Equals, "http://localhost:4318")
+				c.Assert(cfg.GetString("errorsFile"), qt.Equals, "errors.json")
+				c.Assert(cfg.GetString("progress"), qt.Equals, "json")
 			},
 		},
 	}
@@ -285,6 +382,7 @@ func TestCommandsExecute(t *testing.T) {
 		{nil, []string{sourceFlag}, ""},
 		{nil, []string{sourceFlag, "--renderToMemory"}, ""},
 		{[]string{"config"}, []string{sourceFlag}, ""},
+		{[]string{"check", "links"}, []string{sourceFlag, "--format=junit", "-o=" + filepath.Join(dirOut, "links.xml")}, ""},
 		{[]string{"convert", "toTOML"}, []string{sourceFlag, "-o=" + filepath.Join(dirOut, "toml")}, ""},
 		{[]string{"convert", "toYAML"}, []string{sourceFlag, "-o=" + filepath.Join(dirOut, "yaml")}, ""},
 		{[]string{"convert", "toJSON"}, []string{sourceFlag, "-o=" + filepath.Join(dirOut, "json")}, ""},
@@ -295,6 +393,7 @@ func TestCommandsExecute(t *testing.T) {
 		{[]string{"list", "drafts"}, []string{sourceFlag}, ""},
 		{[]string{"list", "expired"}, []string{sourceFlag}, ""},
 		{[]string{"list", "future"}, []string{sourceFlag}, ""},
+		{[]string{"lint", "content"}, []string{sourceFlag}, ""},
 		{[]string{"new", "new-page.md"}, []string{sourceFlag}, ""},
 		{[]string{"new", "site", filepath.Join(dirOut, "new-site")}, nil, ""},
 		{[]string{"unknowncommand"}, nil, "unknown command"},
@@ -16,10 +16,14 @@ package commands
 import (
 	"bytes"
 	"fmt"
+	"io/ioutil"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"time"
 
+	"github.com/gohugoio/hugo/common/text"
+
 	"github.com/gohugoio/hugo/parser/pageparser"
 
 	"github.com/gohugoio/hugo/resources/page"
@@ -43,6 +47,9 @@ var _ cmder = (*convertCmd)(nil)
 type convertCmd struct {
 	outputDir string
 	unsafe    bool
+	mapping   string
+	rules     string
+	diff      bool
 
 	*baseBuilderCmd
 }
@@ -55,7 +62,10 @@ func (b *commandsBuilder) newConvertCmd() *convertCmd {
 		Short: "Convert your content to different formats",
 		Long: `Convert your content (e.g. front matter) to different formats.
 
-See convert's subcommands toJSON, toTOML and toYAML for more information.`,
+See convert's subcommands toJSON, toTOML, toYAML, fields and content for more information.
+
+Front matter fields can be renamed and transformed with a mapping file set
+with --mapping, and --diff shows the changes without writing any files.`,
 		RunE: nil,
 	}
 
@@ -87,18 +97,125 @@ to use YAML for the front matter.`,
 				return cc.convertContents(metadecoders.YAML)
 			},
 		},
+		&cobra.Command{
+			Use:   "fields",
+			Short: "Rename and transform front matter fields",
+			Long: `fields applies the mapping set with --mapping to all front matter in
+the content directory, keeping the front matter format.`,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				if cc.mapping == "" {
+					return newUserError("fields needs a mapping file, set with --mapping")
+				}
+				return cc.convertContents("")
+			},
+		},
+		&cobra.Command{
+			Use:   "content",
+			Short: "Rewrite the content with rules",
+			Long: `content applies the rewrite rules set with --rules to the content,
+after the front matter, of all content files: shortcode renames and parameter
+changes, fixes for Markdown that Blackfriday accepted but Goldmark doesn't,
+links to pages as relref and regular expression replacements.`,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return cc.convertContentBodies()
+			},
+		},
 	)
 
 	cmd.PersistentFlags().StringVarP(&cc.outputDir, "output", "o", "", "filesystem path to write files to")
 	cmd.PersistentFlags().BoolVar(&cc.unsafe, "unsafe", false, "enable less safe operations, please backup first")
+	cmd.PersistentFlags().StringVar(&cc.mapping, "mapping", "", "filesystem path to a TOML, YAML or JSON file with front matter fields to rename, transform, delete or set")
+	cmd.PersistentFlags().StringVar(&cc.rules, "rules", "", "filesystem path to a TOML, YAML or JSON file with the rewrite rules for the content subcommand")
+	cmd.PersistentFlags().BoolVar(&cc.diff, "diff", false, "print the changes as a diff instead of writing the files")
 
 	cc.baseBuilderCmd = b.newBuilderBasicCmd(cmd)
 
 	return cc
 }
 
+// convertedFile is a content file with its converted source.
+type convertedFile struct {
+	// The original filename, and the filename to write the result to.
+	filename    string
+	newFilename string
+
+	source    []byte
+	converted []byte
+
+	// A summary of the changes, if any.
+	report string
+}
+
+// convertFunc returns the converted source of the content file of p, nil if
+// it should be left as is, and an optional summary of the changes.
+type convertFunc func(p page.Page, site *hugolib.Site, source []byte) ([]byte, string, error)
+
+// convertContents converts the front matter in all content files to the
+// given format, or keeps the format if not set.
 func (cc *convertCmd) convertContents(format metadecoders.Format) error {
-	if cc.outputDir == "" && !cc.unsafe {
+	var mapping *fieldMapping
+	if cc.mapping != "" {
+		var err error
+		if mapping, err = loadFieldMapping(hugofs.Os, cc.mapping); err != nil {
+			return err
+		}
+	}
+
+	return cc.convertFiles(func(p page.Page, site *hugolib.Site, source []byte) ([]byte, string, error) {
+		b, err := cc.convertFrontMatter(p, site, source, format, mapping)
+		return b, "", err
+	})
+}
+
+// convertContentBodies applies the rules set with --rules to the content of
+// all content files.
+func (cc *convertCmd) convertContentBodies() error {
+	if cc.rules == "" {
+		return newUserError("content needs a rules file, set with --rules")
+	}
+
+	rules, err := loadContentRules(hugofs.Os, cc.rules)
+	if err != nil {
+		return err
+	}
+
+	var refs map[string]map[string]string
+
+	return cc.convertFiles(func(p page.Page, site *hugolib.Site, source []byte) ([]byte, string, error) {
+		if refs == nil && rules.Relref {
+			refs = pageRefs(site)
+		}
+
+		pf, err := pageparser.ParseFrontMatterAndContent(bytes.NewReader(source))
+		if err != nil {
+			return nil, "", err
+		}
+		content := pf.Content
+		if pf.FrontMatterFormat == "" {
+			content = source
+		}
+		frontMatter := source[:len(source)-len(content)]
+
+		lookupRef := func(dest string) string {
+			return lookupPageRef(refs[p.Language().Lang], site.PathSpec.BaseURL.URL(), dest)
+		}
+
+		newContent, report, err := rules.rewrite(content, lookupRef)
+		if err != nil {
+			return nil, "", errors.Wrapf(err, "failed to convert %q", p.File().Filename())
+		}
+		if bytes.Equal(content, newContent) {
+			return nil, "", nil
+		}
+
+		return append(append([]byte{}, frontMatter...), newContent...), report.String(), nil
+	})
+}
+
+// convertFiles converts all the content files with convert, and writes the
+// results, or prints the changes as a diff.
+func (cc *convertCmd) convertFiles(convert convertFunc) error {
+	if cc.outputDir == "" && !cc.unsafe && !cc.diff {
 		return newUserError("Unsafe operation not allowed, use --unsafe or set a different output path")
 	}
 
@@ -121,18 +238,53 @@ func (cc *convertCmd) convertContents(format metadecoders.Format) error {
 	site := h.Sites[0]
 
 	site.Log.Println("processing", len(site.AllPages()), "content files")
+
+	// Convert all files before writing any, so an invalid mapping doesn't
+	// leave the content half converted.
+	var files []convertedFile
 	for _, p := range site.AllPages() {
-		if err := cc.convertAndSavePage(p, site, format); err != nil {
+		if err := cc.convertPage(p, site, convert, &files); err != nil {
 			return err
 		}
 	}
+
+	relName := func(filename string) string {
+		return filepath.ToSlash(strings.TrimPrefix(filename, c.Cfg.GetString("workingDir")+helpers.FilePathSeparator))
+	}
+
+	if cc.diff {
+		var changed int
+		for _, f := range files {
+			name := relName(f.filename)
+			if d := text.UnifiedDiff("a/"+name, "b/"+name, string(f.source), string(f.converted)); d != "" {
+				if f.report != "" {
+					fmt.Printf("%s: %s\n", name, f.report)
+				}
+				fmt.Print(d)
+				changed++
+			}
+		}
+		site.Log.Println(changed, "content files would change")
+		return nil
+	}
+
+	fs := hugofs.Os
+	for _, f := range files {
+		if err := helpers.WriteToDisk(f.newFilename, bytes.NewReader(f.converted), fs); err != nil {
+			return errors.Wrapf(err, "Failed to save file %q:", f.newFilename)
+		}
+		if f.report != "" {
+			fmt.Printf("%s: %s\n", relName(f.filename), f.report)
+		}
+	}
+
 	return nil
 }
 
-func (cc *convertCmd) convertAndSavePage(p page.Page, site *hugolib.Site, targetFormat metadecoders.Format) error {
+func (cc *convertCmd) convertPage(p page.Page, site *hugolib.Site, convert convertFunc, files *[]convertedFile) error {
 	// The resources are not in .Site.AllPages.
 	for _, r := range p.Resources().ByType("page") {
-		if err := cc.convertAndSavePage(r.(page.Page), site, targetFormat); err != nil {
+		if err := cc.convertPage(r.(page.Page), site, convert, files); err != nil {
 			return err
 		}
 	}
@@ -154,14 +306,86 @@ func (cc *convertCmd) convertAndSavePage(p page.Page, site *hugolib.Site, target
 		return nil
 	}
 
-	pf, err := pageparser.ParseFrontMatterAndContent(file)
+	source, err := ioutil.ReadAll(file)
+	file.Close()
 	if err != nil {
 		site.Log.Errorln(errMsg)
-		file.Close()
 		return err
 	}
 
-	file.Close()
+	converted, report, err := convert(p, site, source)
+	if err != nil {
+		site.Log.Errorln(errMsg)
+		return err
+	}
+	if converted == nil {
+		return nil
+	}
+
+	newFilename := p.File().Filename()
+
+	if cc.outputDir != "" {
+		contentDir := strings.TrimSuffix(newFilename, p.Path())
+		contentDir = filepath.Base(contentDir)
+
+		newFilename = filepath.Join(cc.outputDir, contentDir, p.Path())
+	}
+
+	*files = append(*files, convertedFile{
+		filename:    p.File().Filename(),
+		newFilename: newFilename,
+		source:      source,
+		converted:   converted,
+		report:      report,
+	})
+
+	return nil
+}
+
+func (cc *convertCmd) convertFrontMatter(p page.Page, site *hugolib.Site, source []byte, targetFormat metadecoders.Format, mapping *fieldMapping) ([]byte, error) {
+	errMsg := fmt.Errorf("Error processing file %q", p.Path())
+
+	pf, err := pageparser.ParseFrontMatterAndContent(bytes.NewReader(source))
+	if err != nil {
+		site.Log.Errorln(errMsg)
+		return nil, err
+	}
+
+	format := targetFormat
+	if format == "" {
+		format = pf.FrontMatterFormat
+	}
+
+	if pf.FrontMatterFormat == "" {
+		// No front matter, it's all content.
+		pf.Content = source
+	}
+
+	if mapping != nil {
+		if pf.FrontMatter == nil {
+			pf.FrontMatter = make(map[string]interface{})
+		}
+		if err := mapping.apply(pf.FrontMatter, site.PathSpec.URLize); err != nil {
+			return nil, errors.Wrapf(err, "failed to map the front matter fields of %q", p.File().Filename())
+		}
+	}
+
+	if targetFormat == "" {
+		// Only rewrite the files with changed front matter.
+		orig, _ := pageparser.ParseFrontMatterAndContent(bytes.NewReader(source))
+		if len(orig.FrontMatter) == 0 && len(pf.FrontMatter) == 0 || reflect.DeepEqual(orig.FrontMatter, pf.FrontMatter) {
+			return nil, nil
+		}
+		switch format {
+		case metadecoders.JSON, metadecoders.TOML, metadecoders.YAML:
+		case "":
+			// No front matter.
+			format = metadecoders.TOML
+		default:
+			site.Log.Warnf("%s: front matter in %s is not supported, skipping", p.File().Filename(), format)
+			return nil, nil
+		}
+	}
 
 	// better handling of dates in formats that don't have support for them
 	if pf.FrontMatterFormat == metadecoders.JSON || pf.FrontMatterFormat == metadecoders.YAML || pf.FrontMatterFormat == metadecoders.TOML {
@@ -174,29 +398,15 @@ func (cc *convertCmd) convertAndSavePage(p page.Page, site *hugolib.Site, target
 	}
 
 	var newContent bytes.Buffer
-	err = parser.InterfaceToFrontMatter(pf.FrontMatter, targetFormat, &newContent)
+	err = parser.InterfaceToFrontMatter(pf.FrontMatter, format, &newContent)
 	if err != nil {
 		site.Log.Errorln(errMsg)
-		return err
+		return nil, err
 	}
 
 	newContent.Write(pf.Content)
 
-	newFilename := p.File().Filename()
-
-	if cc.outputDir != "" {
-		contentDir := strings.TrimSuffix(newFilename, p.Path())
-		contentDir = filepath.Base(contentDir)
-
-		newFilename = filepath.Join(cc.outputDir, contentDir, p.Path())
-	}
-
-	fs := hugofs.Os
-	if err := helpers.WriteToDisk(newFilename, &newContent, fs); err != nil {
-		return errors.Wrapf(err, "Failed to save file %q:", newFilename)
-	}
-
-	return nil
+	return newContent.Bytes(), nil
 }
 
 type parsedFile struct {
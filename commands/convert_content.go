@@ -0,0 +1,557 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gohugoio/hugo/hugolib"
+	"github.com/gohugoio/hugo/parser/metadecoders"
+	"github.com/gohugoio/hugo/parser/pageparser"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+// contentRules are the rewrite rules applied to the content of the content
+// files, i.e. what comes after the front matter, by hugo convert content.
+type contentRules struct {
+	// Fixes Markdown that Blackfriday accepted but Goldmark doesn't:
+	// nested lists not indented to the content of the parent item, and
+	// link destinations with spaces.
+	Blackfriday bool
+
+	// Replaces links to pages in the site with relref shortcodes.
+	Relref bool
+
+	Shortcodes []shortcodeRule
+
+	// Regular expression replacements, applied last.
+	Replace []replaceRule
+}
+
+// shortcodeRule changes the name or the parameters of a shortcode.
+type shortcodeRule struct {
+	Name string
+
+	// The new name of the shortcode.
+	Rename string
+
+	// Names for the positional parameters, turning them into named
+	// parameters.
+	Named []string
+
+	// Renames named parameters, old = new. An empty new name deletes the
+	// parameter.
+	Params map[string]string
+}
+
+type replaceRule struct {
+	Pattern     string
+	Replacement string
+
+	re *regexp.Regexp
+}
+
+// loadContentRules loads the rules from the TOML, YAML or JSON file
+// filename.
+func loadContentRules(fs afero.Fs, filename string) (*contentRules, error) {
+	m, err := metadecoders.Default.UnmarshalFileToMap(fs, filename)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load content rules %q", filename)
+	}
+	rules, err := decodeContentRules(m)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid content rules %q", filepath.Base(filename))
+	}
+	return rules, nil
+}
+
+func decodeContentRules(m map[string]interface{}) (*contentRules, error) {
+	rules := &contentRules{}
+	if err := mapstructure.WeakDecode(m, rules); err != nil {
+		return nil, err
+	}
+
+	for _, sc := range rules.Shortcodes {
+		if sc.Name == "" {
+			return nil, errors.New("shortcodes: name must be set")
+		}
+	}
+
+	for i, r := range rules.Replace {
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return nil, errors.Wrapf(err, "replace: invalid pattern %q", r.Pattern)
+		}
+		rules.Replace[i].re = re
+	}
+
+	return rules, nil
+}
+
+// contentReport counts the changes made to a content file by rule.
+type contentReport map[string]int
+
+func (r contentReport) String() string {
+	var keys []string
+	for k := range r {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for i, k := range keys {
+		keys[i] = fmt.Sprintf("%s (%d)", k, r[k])
+	}
+	return strings.Join(keys, ", ")
+}
+
+// rewrite applies the rules to content. The lookupRef func returns the
+// path to use in relref for a link destination, or an empty string if the
+// destination isn't a page in the site.
+func (rules *contentRules) rewrite(content []byte, lookupRef func(dest string) string) ([]byte, contentReport, error) {
+	report := make(contentReport)
+
+	if len(rules.Shortcodes) > 0 {
+		var err error
+		content, err = rules.rewriteShortcodes(content, report)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if rules.Blackfriday || rules.Relref {
+		s := string(content)
+		if rules.Blackfriday {
+			s = fixListIndentation(s, report)
+		}
+		s = mapLinkDestinations(s, func(dest string) string {
+			if rules.Relref && lookupRef != nil {
+				if ref := lookupRef(dest); ref != "" {
+					report["relref"]++
+					return fmt.Sprintf(`{{< relref "%s" >}}`, ref)
+				}
+			}
+			if rules.Blackfriday && !strings.HasPrefix(dest, "<") && !strings.Contains(dest, "{{") && strings.ContainsAny(dest, " \t") {
+				// Goldmark requires destinations with spaces to be in
+				// angle brackets.
+				report["blackfriday link"]++
+				return "<" + dest + ">"
+			}
+			return dest
+		})
+		content = []byte(s)
+	}
+
+	for _, r := range rules.Replace {
+		if n := len(r.re.FindAllIndex(content, -1)); n > 0 {
+			content = r.re.ReplaceAll(content, []byte(r.Replacement))
+			report[fmt.Sprintf("replace %q", r.Pattern)] += n
+		}
+	}
+
+	return content, report, nil
+}
+
+// shortcodeTag is a shortcode tag in the content, opening or closing.
+type shortcodeTag struct {
+	// The position of the tag in the content.
+	start, end int
+
+	markup      bool
+	closing     bool
+	selfClosing bool
+
+	name   string
+	params []shortcodeParam
+}
+
+type shortcodeParam struct {
+	// Empty for positional parameters.
+	key string
+
+	val string
+
+	// The quote used for the value, if any.
+	quote byte
+}
+
+func (t shortcodeTag) String() string {
+	open, close := "{{<", ">}}"
+	if t.markup {
+		open, close = "{{%", "%}}"
+	}
+
+	var sb strings.Builder
+	sb.WriteString(open + " ")
+	if t.closing {
+		sb.WriteString("/")
+	}
+	sb.WriteString(t.name)
+	for _, p := range t.params {
+		sb.WriteString(" ")
+		if p.key != "" {
+			sb.WriteString(p.key + "=")
+		}
+		switch p.quote {
+		case '"':
+			sb.WriteString(`"` + strings.Replace(p.val, `"`, `\"`, -1) + `"`)
+		case '`':
+			sb.WriteString("`" + p.val + "`")
+		default:
+			sb.WriteString(p.val)
+		}
+	}
+	if t.selfClosing {
+		sb.WriteString(" /")
+	}
+	sb.WriteString(" " + close)
+
+	return sb.String()
+}
+
+// parseShortcodeTags returns the shortcode tags in content.
+func parseShortcodeTags(content []byte) ([]shortcodeTag, error) {
+	result, err := pageparser.ParseMain(bytes.NewReader(content), pageparser.Config{})
+	if err != nil {
+		return nil, err
+	}
+
+	input := result.Input()
+	quoteBefore := func(item pageparser.Item) byte {
+		if item.Pos > 0 && (input[item.Pos-1] == '"' || input[item.Pos-1] == '`') {
+			return input[item.Pos-1]
+		}
+		return 0
+	}
+
+	var (
+		tags []shortcodeTag
+		tag  *shortcodeTag
+	)
+
+	iter := result.Iterator()
+	for {
+		item := iter.Next()
+		switch {
+		case item.IsError():
+			return nil, errors.Errorf("failed to parse shortcodes: %s", item.ValStr())
+		case item.IsDone():
+			return tags, nil
+		case item.IsLeftShortcodeDelim():
+			tag = &shortcodeTag{start: item.Pos, markup: item.IsShortcodeMarkupDelimiter()}
+		case tag == nil:
+		case item.IsShortcodeClose():
+			if tag.name == "" {
+				tag.closing = true
+			} else {
+				tag.selfClosing = true
+			}
+		case item.IsShortcodeName(), item.IsInlineShortcodeName():
+			tag.name = item.ValStr()
+		case item.IsShortcodeParam():
+			if next := iter.Peek(); next.IsShortcodeParamVal() {
+				iter.Next()
+				tag.params = append(tag.params, shortcodeParam{key: item.ValStr(), val: next.ValStr(), quote: quoteBefore(next)})
+			} else {
+				tag.params = append(tag.params, shortcodeParam{val: item.ValStr(), quote: quoteBefore(item)})
+			}
+		case item.IsRightShortcodeDelim():
+			tag.end = item.Pos + len(item.Val)
+			tags = append(tags, *tag)
+			tag = nil
+		}
+	}
+}
+
+func (rules *contentRules) rewriteShortcodes(content []byte, report contentReport) ([]byte, error) {
+	tags, err := parseShortcodeTags(content)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		buf  bytes.Buffer
+		prev int
+	)
+
+	for _, tag := range tags {
+		for _, rule := range rules.Shortcodes {
+			if tag.name != rule.Name {
+				continue
+			}
+			if err := rule.apply(&tag); err != nil {
+				return nil, err
+			}
+			buf.Write(content[prev:tag.start])
+			buf.WriteString(tag.String())
+			prev = tag.end
+			report["shortcode "+rule.Name]++
+			break
+		}
+	}
+
+	if prev == 0 {
+		return content, nil
+	}
+	buf.Write(content[prev:])
+
+	return buf.Bytes(), nil
+}
+
+func (rule shortcodeRule) apply(tag *shortcodeTag) error {
+	if rule.Rename != "" {
+		tag.name = rule.Rename
+	}
+
+	if tag.closing {
+		return nil
+	}
+
+	if len(rule.Named) > 0 && len(tag.params) > 0 && tag.params[0].key == "" {
+		if len(tag.params) > len(rule.Named) {
+			return errors.Errorf("shortcode %q has %d positional parameters, but only %d names are set", rule.Name, len(tag.params), len(rule.Named))
+		}
+		for i := range tag.params {
+			tag.params[i].key = rule.Named[i]
+		}
+	}
+
+	var params []shortcodeParam
+Params:
+	for _, p := range tag.params {
+		for oldKey, newKey := range rule.Params {
+			if p.key == "" || !strings.EqualFold(p.key, oldKey) {
+				continue
+			}
+			if newKey == "" {
+				continue Params
+			}
+			p.key = newKey
+			break
+		}
+		params = append(params, p)
+	}
+	tag.params = params
+
+	return nil
+}
+
+var (
+	fenceRe      = regexp.MustCompile("^( {0,3})(```+|~~~+)")
+	listMarkerRe = regexp.MustCompile(`^( *)([-*+]|\d{1,9}[.)])( +)\S`)
+	leadingRe    = regexp.MustCompile(`^ *`)
+)
+
+// fixListIndentation indents nested list items to the content of their
+// parent item, as required by Goldmark, e.g. 3 spaces below "1. ".
+func fixListIndentation(s string, report contentReport) string {
+	type listItem struct {
+		indent  int
+		content int
+		shift   int
+	}
+
+	var (
+		items     []listItem
+		fence     string
+		shift     int
+		prevBlank bool
+	)
+
+	// The shift to apply to a line indented indent below the list items.
+	shiftFor := func(indent int) int {
+		for i := len(items) - 1; i >= 0; i-- {
+			if items[i].indent < indent {
+				return items[i].shift
+			}
+		}
+		return 0
+	}
+
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if fence != "" {
+			// Inside a code block.
+			if strings.HasPrefix(strings.TrimLeft(line, " "), fence) {
+				fence = ""
+			}
+			if shift > 0 && strings.TrimSpace(line) != "" {
+				lines[i] = strings.Repeat(" ", shift) + line
+			}
+			continue
+		}
+
+		if strings.TrimSpace(line) == "" {
+			prevBlank = true
+			continue
+		}
+
+		indent := len(leadingRe.FindString(line))
+		if indent == 0 && prevBlank && !listMarkerRe.MatchString(line) {
+			// The end of the list.
+			items = nil
+		}
+		prevBlank = false
+
+		if m := fenceRe.FindStringSubmatch(strings.TrimLeft(line, " ")); m != nil {
+			fence = m[2]
+			shift = 0
+			if indent > 0 {
+				shift = shiftFor(indent)
+			}
+			if shift > 0 {
+				lines[i] = strings.Repeat(" ", shift) + line
+			}
+			continue
+		}
+
+		if m := listMarkerRe.FindStringSubmatch(line); m != nil {
+			for len(items) > 0 && indent <= items[len(items)-1].indent {
+				items = items[:len(items)-1]
+			}
+
+			item := listItem{indent: indent}
+			newIndent := indent
+			if len(items) > 0 {
+				parent := items[len(items)-1]
+				newIndent = indent + parent.shift
+				if newIndent < parent.content {
+					newIndent = parent.content
+					report["blackfriday list"]++
+				}
+			}
+			item.shift = newIndent - indent
+			item.content = newIndent + len(m[2]) + len(m[3])
+			items = append(items, item)
+
+			if item.shift > 0 {
+				lines[i] = strings.Repeat(" ", item.shift) + line
+			}
+			continue
+		}
+
+		if sh := shiftFor(indent); indent > 0 && sh > 0 {
+			lines[i] = strings.Repeat(" ", sh) + line
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// linkDestRe matches the destination and optional title of inline links
+// and images.
+var linkDestRe = regexp.MustCompile(`\]\(([^)\n]*)\)`)
+
+var linkTitleRe = regexp.MustCompile(`^(.*?)(\s+(?:"[^"]*"|'[^']*'))$`)
+
+// mapLinkDestinations replaces the destinations of the inline links and
+// images in s outside of code blocks and code spans with the result of
+// mapDest.
+func mapLinkDestinations(s string, mapDest func(dest string) string) string {
+	lines := strings.Split(s, "\n")
+	var fence string
+	for i, line := range lines {
+		if fence != "" {
+			if strings.HasPrefix(strings.TrimLeft(line, " "), fence) {
+				fence = ""
+			}
+			continue
+		}
+		if m := fenceRe.FindStringSubmatch(strings.TrimLeft(line, " ")); m != nil {
+			fence = m[2]
+			continue
+		}
+		if !strings.Contains(line, "](") {
+			continue
+		}
+
+		// Leave code spans alone.
+		parts := strings.Split(line, "`")
+		for j := 0; j < len(parts); j += 2 {
+			parts[j] = linkDestRe.ReplaceAllStringFunc(parts[j], func(match string) string {
+				inner := match[2 : len(match)-1]
+				dest, title := strings.TrimSpace(inner), ""
+				if m := linkTitleRe.FindStringSubmatch(dest); m != nil {
+					dest, title = m[1], m[2]
+				}
+				if dest == "" {
+					return match
+				}
+				newDest := mapDest(dest)
+				if newDest == dest {
+					return match
+				}
+				return "](" + newDest + title + ")"
+			})
+		}
+		lines[i] = strings.Join(parts, "`")
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// pageRefs returns, by language, the paths to use in relref for the pages in
+// the site, keyed by their relative permalink.
+func pageRefs(site *hugolib.Site) map[string]map[string]string {
+	refs := make(map[string]map[string]string)
+	for _, p := range site.AllPages() {
+		f := p.File()
+		if f.IsZero() {
+			continue
+		}
+		lang := p.Language().Lang
+		if refs[lang] == nil {
+			refs[lang] = make(map[string]string)
+		}
+		refs[lang][p.RelPermalink()] = path.Join(filepath.ToSlash(f.Dir()), f.TranslationBaseName()+"."+f.Extension())
+	}
+	return refs
+}
+
+// lookupPageRef returns the path to use in relref for the link destination
+// dest if it's the URL of one of the pages in refs, or an empty string if
+// not. Absolute URLs must be on the host of baseURL.
+func lookupPageRef(refs map[string]string, baseURL *url.URL, dest string) string {
+	u, err := url.Parse(dest)
+	if err != nil || u.RawQuery != "" || u.Path == "" {
+		return ""
+	}
+	if u.Scheme != "" || u.Host != "" {
+		if baseURL == nil || !strings.EqualFold(u.Host, baseURL.Host) {
+			return ""
+		}
+	} else if !strings.HasPrefix(u.Path, "/") {
+		return ""
+	}
+
+	ref, found := refs[u.Path]
+	if !found && !strings.HasSuffix(u.Path, "/") {
+		ref, found = refs[u.Path+"/"]
+	}
+	if !found {
+		return ""
+	}
+	if u.Fragment != "" {
+		ref += "#" + u.Fragment
+	}
+
+	return ref
+}
@@ -0,0 +1,170 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"net/url"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestContentRulesShortcodes(t *testing.T) {
+	c := qt.New(t)
+
+	rules, err := decodeContentRules(map[string]interface{}{
+		"shortcodes": []map[string]interface{}{
+			{"name": "yt", "rename": "youtube", "named": []interface{}{"id", "title"}},
+			{"name": "fig", "rename": "figure", "params": map[string]interface{}{"image": "src", "cls": ""}},
+			{"name": "note", "rename": "notice"},
+		},
+	})
+	c.Assert(err, qt.IsNil)
+
+	content := []byte(`Intro.
+
+{{< yt abc123 "My \"video\"" >}}
+
+{{< fig image="/img/a.png" cls=big caption=` + "`A caption`" + ` >}}
+
+{{% note %}}Some **text**.{{% /note %}}
+
+{{</* yt commented */>}}
+
+{{< other 1 2 />}}
+`)
+
+	got, report, err := rules.rewrite(content, nil)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(got), qt.Equals, `Intro.
+
+{{< youtube id=abc123 title="My \"video\"" >}}
+
+{{< figure src="/img/a.png" caption=`+"`A caption`"+` >}}
+
+{{% notice %}}Some **text**.{{% /notice %}}
+
+{{</* yt commented */>}}
+
+{{< other 1 2 />}}
+`)
+	c.Assert(report.String(), qt.Equals, "shortcode fig (1), shortcode note (2), shortcode yt (1)")
+
+	_, _, err = rules.rewrite([]byte(`{{< yt a b c >}}`), nil)
+	c.Assert(err, qt.ErrorMatches, `shortcode "yt" has 3 positional parameters, but only 2 names are set`)
+
+	_, _, err = rules.rewrite([]byte(`{{< yt a b `), nil)
+	c.Assert(err, qt.ErrorMatches, `failed to parse shortcodes.*`)
+}
+
+func TestContentRulesBlackfriday(t *testing.T) {
+	c := qt.New(t)
+
+	rules := &contentRules{Blackfriday: true}
+
+	got, report, err := rules.rewrite([]byte(`1. First
+  - Nested
+  - Nested 2
+    continued
+2. Second
+  1. Nested ordered
+     - Deep
+
+- Bullet
+  - Fine
+
+10. Tenth
+  - Nested
+
+    Paragraph in nested.
+
+        code in nested
+
+Text ![An image](my image.png "Title") and [a link](<already ok.html>).
+
+`+"```"+`
+1. Code
+  - not a list
+![x](in code.png)
+`+"```"+`
+
+Inline `+"`[x](a b)`"+` code.
+`), nil)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(got), qt.Equals, `1. First
+   - Nested
+   - Nested 2
+     continued
+2. Second
+   1. Nested ordered
+      - Deep
+
+- Bullet
+  - Fine
+
+10. Tenth
+    - Nested
+
+      Paragraph in nested.
+
+          code in nested
+
+Text ![An image](<my image.png> "Title") and [a link](<already ok.html>).
+
+`+"```"+`
+1. Code
+  - not a list
+![x](in code.png)
+`+"```"+`
+
+Inline `+"`[x](a b)`"+` code.
+`)
+	c.Assert(report, qt.DeepEquals, contentReport{"blackfriday list": 4, "blackfriday link": 1})
+}
+
+func TestContentRulesRelref(t *testing.T) {
+	c := qt.New(t)
+
+	baseURL, _ := url.Parse("https://example.org/")
+	refs := map[string]string{
+		"/posts/first/": "posts/first.md",
+		"/about/":       "about/index.md",
+	}
+	lookup := func(dest string) string { return lookupPageRef(refs, baseURL, dest) }
+
+	c.Assert(lookup("/posts/first/"), qt.Equals, "posts/first.md")
+	c.Assert(lookup("/posts/first"), qt.Equals, "posts/first.md")
+	c.Assert(lookup("https://example.org/about/#team"), qt.Equals, "about/index.md#team")
+	c.Assert(lookup("https://other.org/about/"), qt.Equals, "")
+	c.Assert(lookup("/about/?q=1"), qt.Equals, "")
+	c.Assert(lookup("about/"), qt.Equals, "")
+	c.Assert(lookup("/nope/"), qt.Equals, "")
+
+	rules, err := decodeContentRules(map[string]interface{}{
+		"relref":  true,
+		"replace": []map[string]interface{}{{"pattern": `(?m)^<!-- ?more ?-->$`, "replacement": "<!--more-->"}},
+	})
+	c.Assert(err, qt.IsNil)
+
+	got, report, err := rules.rewrite([]byte(`See [the first post](https://example.org/posts/first/ "First") and [us](/about/).
+<!-- more -->
+[External](https://gohugo.io/).
+`), lookup)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(got), qt.Equals, `See [the first post]({{< relref "posts/first.md" >}} "First") and [us]({{< relref "about/index.md" >}}).
+<!--more-->
+[External](https://gohugo.io/).
+`)
+	c.Assert(report.String(), qt.Equals, `relref (2), replace "(?m)^<!-- ?more ?-->$" (1)`)
+}
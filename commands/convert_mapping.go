@@ -0,0 +1,351 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gohugoio/hugo/common/maps"
+	"github.com/gohugoio/hugo/parser/metadecoders"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"github.com/spf13/cast"
+)
+
+// fieldMapping describes the changes to make to the front matter fields
+// when converting content. Field names are case insensitive, and nested
+// fields are separated by dots, e.g. params.author.
+type fieldMapping struct {
+	// Maps the old field name to the new one.
+	Rename map[string]string
+
+	// Transforms the field values, applied after the renames.
+	Transform []fieldTransform
+
+	// The fields to delete, applied after the transforms.
+	Delete []string
+
+	// Values for the fields not set, applied last.
+	Defaults map[string]interface{}
+}
+
+// fieldTransform transforms the value of a field. String actions are
+// applied to every string in a list.
+type fieldTransform struct {
+	Field string
+
+	// One of lower, upper, trim, urlize, replace, split, join and
+	// dateFormat.
+	Action string
+
+	// The regular expression and the replacement for replace, see
+	// regexp.ReplaceAllString.
+	Pattern     string
+	Replacement string
+
+	// The separator for split and join. The defaults are "," and ", ".
+	Separator string
+
+	// The Go layout for dateFormat, e.g. 2006-01-02.
+	Layout string
+
+	re *regexp.Regexp
+}
+
+var fieldTransformActions = map[string]bool{
+	"lower":      true,
+	"upper":      true,
+	"trim":       true,
+	"urlize":     true,
+	"replace":    true,
+	"split":      true,
+	"join":       true,
+	"dateFormat": true,
+}
+
+// loadFieldMapping loads the mapping from the TOML, YAML or JSON file
+// filename.
+func loadFieldMapping(fs afero.Fs, filename string) (*fieldMapping, error) {
+	m, err := metadecoders.Default.UnmarshalFileToMap(fs, filename)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load field mapping %q", filename)
+	}
+	fm, err := decodeFieldMapping(m)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid field mapping %q", filepath.Base(filename))
+	}
+	return fm, nil
+}
+
+func decodeFieldMapping(m map[string]interface{}) (*fieldMapping, error) {
+	fm := &fieldMapping{}
+	if err := mapstructure.WeakDecode(m, fm); err != nil {
+		return nil, err
+	}
+
+	for from, to := range fm.Rename {
+		if to == "" {
+			return nil, errors.Errorf("rename: no new name for %q", from)
+		}
+	}
+
+	for i, t := range fm.Transform {
+		if t.Field == "" {
+			return nil, errors.Errorf("transform: field must be set")
+		}
+		if !fieldTransformActions[t.Action] {
+			return nil, errors.Errorf("transform: unknown action %q for %q", t.Action, t.Field)
+		}
+		switch t.Action {
+		case "replace":
+			re, err := regexp.Compile(t.Pattern)
+			if err != nil {
+				return nil, errors.Wrapf(err, "transform: invalid pattern for %q", t.Field)
+			}
+			fm.Transform[i].re = re
+		case "dateFormat":
+			if t.Layout == "" {
+				return nil, errors.Errorf("transform: layout must be set for dateFormat of %q", t.Field)
+			}
+		case "split":
+			if t.Separator == "" {
+				fm.Transform[i].Separator = ","
+			}
+		case "join":
+			if t.Separator == "" {
+				fm.Transform[i].Separator = ", "
+			}
+		}
+	}
+
+	return fm, nil
+}
+
+// apply applies the mapping to the front matter in fm. The urlize function
+// is used for the urlize action.
+func (m *fieldMapping) apply(fm map[string]interface{}, urlize func(string) string) error {
+	var renames []string
+	for from := range m.Rename {
+		renames = append(renames, from)
+	}
+	sort.Strings(renames)
+
+	for _, from := range renames {
+		to := m.Rename[from]
+		v, found := getField(fm, from)
+		if !found {
+			continue
+		}
+		if existing, found := getField(fm, to); found {
+			// Merge lists, e.g. when renaming categories to tags.
+			merged, ok := mergeLists(existing, v)
+			if !ok {
+				return errors.Errorf("cannot rename %q to %q, %q is already set", from, to, to)
+			}
+			v = merged
+		}
+		deleteField(fm, from)
+		setField(fm, to, v)
+	}
+
+	for _, t := range m.Transform {
+		v, found := getField(fm, t.Field)
+		if !found {
+			continue
+		}
+		nv, err := t.apply(v, urlize)
+		if err != nil {
+			return errors.Wrapf(err, "failed to %s %q", t.Action, t.Field)
+		}
+		setField(fm, t.Field, nv)
+	}
+
+	for _, name := range m.Delete {
+		deleteField(fm, name)
+	}
+
+	for name, v := range m.Defaults {
+		if _, found := getField(fm, name); !found {
+			setField(fm, name, v)
+		}
+	}
+
+	return nil
+}
+
+func (t fieldTransform) apply(v interface{}, urlize func(string) string) (interface{}, error) {
+	switch t.Action {
+	case "split":
+		s, err := cast.ToStringE(v)
+		if err != nil {
+			if _, ok := v.([]interface{}); ok {
+				// Already a list.
+				return v, nil
+			}
+			return nil, err
+		}
+		var list []interface{}
+		for _, part := range strings.Split(s, t.Separator) {
+			if part = strings.TrimSpace(part); part != "" {
+				list = append(list, part)
+			}
+		}
+		return list, nil
+	case "join":
+		list, err := cast.ToStringSliceE(v)
+		if err != nil {
+			return nil, err
+		}
+		return strings.Join(list, t.Separator), nil
+	case "dateFormat":
+		d, err := cast.ToTimeE(v)
+		if err != nil {
+			return nil, err
+		}
+		return d.Format(t.Layout), nil
+	}
+
+	var str func(s string) string
+	switch t.Action {
+	case "lower":
+		str = strings.ToLower
+	case "upper":
+		str = strings.ToUpper
+	case "trim":
+		str = strings.TrimSpace
+	case "urlize":
+		str = urlize
+	case "replace":
+		str = func(s string) string { return t.re.ReplaceAllString(s, t.Replacement) }
+	}
+
+	switch vv := v.(type) {
+	case string:
+		return str(vv), nil
+	case []interface{}:
+		list := make([]interface{}, len(vv))
+		for i, e := range vv {
+			s, ok := e.(string)
+			if !ok {
+				return nil, errors.Errorf("unable to transform %T", e)
+			}
+			list[i] = str(s)
+		}
+		return list, nil
+	case []string:
+		list := make([]interface{}, len(vv))
+		for i, s := range vv {
+			list[i] = str(s)
+		}
+		return list, nil
+	default:
+		return nil, errors.Errorf("unable to transform %T", v)
+	}
+}
+
+// mergeLists returns the values in b appended to a, if both are lists.
+func mergeLists(a, b interface{}) (interface{}, bool) {
+	al, ok1 := toList(a)
+	bl, ok2 := toList(b)
+	if !ok1 || !ok2 {
+		return nil, false
+	}
+	merged := append([]interface{}{}, al...)
+	for _, v := range bl {
+		seen := false
+		for _, vv := range merged {
+			// The elements may be maps, e.g. authors = [{name = "x"}].
+			if reflect.DeepEqual(vv, v) {
+				seen = true
+				break
+			}
+		}
+		if !seen {
+			merged = append(merged, v)
+		}
+	}
+	return merged, true
+}
+
+// toList returns v as a []interface{} if it is a slice of any type,
+// e.g. a []map[string]interface{} from TOML.
+func toList(v interface{}) ([]interface{}, bool) {
+	if l, ok := v.([]interface{}); ok {
+		return l, true
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice {
+		return nil, false
+	}
+	l := make([]interface{}, rv.Len())
+	for i := range l {
+		l[i] = rv.Index(i).Interface()
+	}
+	return l, true
+}
+
+// lookupField returns the map holding the field name, and the key of the
+// field in that map, which may differ in case from the name. The map is
+// created if needed and create is set.
+func lookupField(fm map[string]interface{}, name string, create bool) (map[string]interface{}, string) {
+	parts := strings.Split(name, ".")
+	m := fm
+	for i, part := range parts {
+		key := part
+		for k := range m {
+			if strings.EqualFold(k, part) {
+				key = k
+				break
+			}
+		}
+		if i == len(parts)-1 {
+			return m, key
+		}
+		next, err := maps.ToStringMapE(m[key])
+		if err != nil || m[key] == nil {
+			if !create {
+				return nil, ""
+			}
+			next = make(map[string]interface{})
+		}
+		m[key] = next
+		m = next
+	}
+	return nil, ""
+}
+
+func getField(fm map[string]interface{}, name string) (interface{}, bool) {
+	m, key := lookupField(fm, name, false)
+	if m == nil {
+		return nil, false
+	}
+	v, found := m[key]
+	return v, found
+}
+
+func setField(fm map[string]interface{}, name string, v interface{}) {
+	m, key := lookupField(fm, name, true)
+	m[key] = v
+}
+
+func deleteField(fm map[string]interface{}, name string) {
+	if m, key := lookupField(fm, name, false); m != nil {
+		delete(m, key)
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestFieldMapping(t *testing.T) {
+	c := qt.New(t)
+
+	m, err := decodeFieldMapping(map[string]interface{}{
+		"rename": map[string]interface{}{
+			"categories": "topics",
+			"series":     "tags",
+			"author":     "params.author.name",
+		},
+		"transform": []map[string]interface{}{
+			{"field": "topics", "action": "urlize"},
+			{"field": "keywords", "action": "split", "separator": ";"},
+			{"field": "date", "action": "dateFormat", "layout": "2006-01-02"},
+			{"field": "title", "action": "replace", "pattern": `^Draft: `, "replacement": ""},
+		},
+		"delete":   []interface{}{"layout"},
+		"defaults": map[string]interface{}{"toc": true, "title": "Untitled"},
+	})
+	c.Assert(err, qt.IsNil)
+
+	fm := map[string]interface{}{
+		"Title":      "Draft: My Post",
+		"categories": []interface{}{"Go Tips", "Hugo"},
+		"series":     []interface{}{"hugo", "intro"},
+		"tags":       []interface{}{"hugo"},
+		"Author":     "Jane",
+		"keywords":   "one; two;",
+		"date":       time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC),
+		"layout":     "post",
+	}
+
+	c.Assert(m.apply(fm, func(s string) string { return strings.ToLower(strings.Replace(s, " ", "-", -1)) }), qt.IsNil)
+	c.Assert(fm, qt.DeepEquals, map[string]interface{}{
+		"Title":    "My Post",
+		"topics":   []interface{}{"go-tips", "hugo"},
+		"tags":     []interface{}{"hugo", "intro"},
+		"params":   map[string]interface{}{"author": map[string]interface{}{"name": "Jane"}},
+		"keywords": []interface{}{"one", "two"},
+		"date":     "2021-06-01",
+		"toc":      true,
+	})
+
+	// Merging lists of maps.
+	m, err = decodeFieldMapping(map[string]interface{}{"rename": map[string]interface{}{"writers": "authors"}})
+	c.Assert(err, qt.IsNil)
+	fm = map[string]interface{}{
+		"authors": []map[string]interface{}{{"name": "x"}},
+		"writers": []interface{}{map[string]interface{}{"name": "x"}, map[string]interface{}{"name": "y"}},
+	}
+	c.Assert(m.apply(fm, nil), qt.IsNil)
+	c.Assert(fm, qt.DeepEquals, map[string]interface{}{
+		"authors": []interface{}{map[string]interface{}{"name": "x"}, map[string]interface{}{"name": "y"}},
+	})
+
+	// Renaming to a field already set.
+	m, err = decodeFieldMapping(map[string]interface{}{"rename": map[string]interface{}{"foo": "bar"}})
+	c.Assert(err, qt.IsNil)
+	err = m.apply(map[string]interface{}{"foo": "a", "bar": "b"}, nil)
+	c.Assert(err, qt.ErrorMatches, `cannot rename "foo" to "bar", "bar" is already set`)
+
+	// Transforming a value of the wrong type.
+	m, err = decodeFieldMapping(map[string]interface{}{"transform": []map[string]interface{}{{"field": "weight", "action": "lower"}}})
+	c.Assert(err, qt.IsNil)
+	c.Assert(m.apply(map[string]interface{}{"weight": 3}, nil), qt.ErrorMatches, `failed to lower "weight": unable to transform int`)
+
+	for _, test := range []struct {
+		m      map[string]interface{}
+		expect string
+	}{
+		{map[string]interface{}{"rename": map[string]interface{}{"foo": ""}}, `rename: no new name for "foo"`},
+		{map[string]interface{}{"transform": []map[string]interface{}{{"field": "foo", "action": "reverse"}}}, `transform: unknown action "reverse" for "foo"`},
+		{map[string]interface{}{"transform": []map[string]interface{}{{"action": "lower"}}}, `transform: field must be set`},
+		{map[string]interface{}{"transform": []map[string]interface{}{{"field": "foo", "action": "replace", "pattern": "("}}}, `transform: invalid pattern for "foo".*`},
+		{map[string]interface{}{"transform": []map[string]interface{}{{"field": "date", "action": "dateFormat"}}}, `transform: layout must be set for dateFormat of "date"`},
+	} {
+		_, err := decodeFieldMapping(test.m)
+		c.Assert(err, qt.ErrorMatches, test.expect)
+	}
+}
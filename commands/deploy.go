@@ -17,8 +17,10 @@ package commands
 
 import (
 	"context"
+	"time"
 
 	"github.com/gohugoio/hugo/deploy"
+	"github.com/gohugoio/hugo/notify"
 	"github.com/spf13/cobra"
 )
 
@@ -68,7 +70,13 @@ documentation.
 			if err != nil {
 				return err
 			}
-			return deployer.Deploy(context.Background())
+			start := time.Now()
+			err = deployer.Deploy(context.Background())
+			comm.notify(notify.EventDeploy, start, err, func(s *notify.Summary) {
+				s.Target = deployer.Target()
+				s.ChangedFiles = deployer.ChangedFiles()
+			})
+			return err
 		},
 	}
 
@@ -14,9 +14,19 @@
 package commands
 
 import (
+	"encoding/json"
+	"path/filepath"
 	"runtime"
 
+	"github.com/cli/safeexec"
+	"github.com/gohugoio/hugo/cache/filecache"
 	"github.com/gohugoio/hugo/common/hugo"
+	"github.com/gohugoio/hugo/markup/asciidocext"
+	"github.com/gohugoio/hugo/markup/pandoc"
+	"github.com/gohugoio/hugo/markup/rst"
+	"github.com/gohugoio/hugo/modules"
+	"github.com/gohugoio/hugo/resources/resource_transformers/tocss/dartsass"
+	"github.com/gohugoio/hugo/resources/resource_transformers/tocss/scss"
 
 	"github.com/spf13/cobra"
 	jww "github.com/spf13/jwalterweatherman"
@@ -25,36 +35,162 @@ import (
 var _ cmder = (*envCmd)(nil)
 
 type envCmd struct {
-	*baseCmd
+	*baseBuilderCmd
+
+	json bool
 }
 
-func newEnvCmd() *envCmd {
-	return &envCmd{
-		baseCmd: newBaseCmd(&cobra.Command{
-			Use:   "env",
-			Short: "Print Hugo version and environment info",
-			Long: `Print Hugo version and environment info. This is useful in Hugo bug reports.
+func (b *commandsBuilder) newEnvCmd() *envCmd {
+	cc := &envCmd{}
+	cmd := &cobra.Command{
+		Use:   "env",
+		Short: "Print Hugo version and environment info",
+		Long: `Print Hugo version and environment info. This is useful in Hugo bug reports.
 
 If you add the -v flag, you will get a full dependency list.
+
+With --json, the info is printed as JSON, with the optional features available,
+e.g. Dart Sass and Asciidoctor, the full dependency list and, if run in a Hugo
+project, the module versions and the cache locations.
 `,
-			RunE: func(cmd *cobra.Command, args []string) error {
-				printHugoVersion()
-				jww.FEEDBACK.Printf("GOOS=%q\n", runtime.GOOS)
-				jww.FEEDBACK.Printf("GOARCH=%q\n", runtime.GOARCH)
-				jww.FEEDBACK.Printf("GOVERSION=%q\n", runtime.Version())
-
-				isVerbose, _ := cmd.Flags().GetBool("verbose")
-
-				if isVerbose {
-					deps := hugo.GetDependencyList()
-					for _, dep := range deps {
-						jww.FEEDBACK.Printf("%s\n", dep)
-					}
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cc.json {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				return enc.Encode(cc.newEnvInfo())
+			}
+
+			printHugoVersion()
+			jww.FEEDBACK.Printf("GOOS=%q\n", runtime.GOOS)
+			jww.FEEDBACK.Printf("GOARCH=%q\n", runtime.GOARCH)
+			jww.FEEDBACK.Printf("GOVERSION=%q\n", runtime.Version())
+
+			isVerbose, _ := cmd.Flags().GetBool("verbose")
+
+			if isVerbose {
+				deps := hugo.GetDependencyList()
+				for _, dep := range deps {
+					jww.FEEDBACK.Printf("%s\n", dep)
 				}
+			}
 
-				return nil
-			},
-		}),
+			return nil
+		},
 	}
 
+	cmd.Flags().BoolVar(&cc.json, "json", false, "print the environment info as JSON")
+
+	cc.baseBuilderCmd = b.newBuilderBasicCmd(cmd)
+
+	return cc
+}
+
+// envInfo is the environment info printed by hugo env --json.
+type envInfo struct {
+	Version    string `json:"version"`
+	CommitHash string `json:"commitHash"`
+	BuildDate  string `json:"buildDate"`
+	Extended   bool   `json:"extended"`
+	GOOS       string `json:"goos"`
+	GOARCH     string `json:"goarch"`
+	GoVersion  string `json:"goVersion"`
+
+	// Whether the optional features are available, by name, e.g. dartSass.
+	Features map[string]bool `json:"features"`
+
+	// The versions of the dependencies by package.
+	Dependencies map[string]string `json:"dependencies"`
+
+	// The project's modules, set if the config is loaded, and the file
+	// caches by name.
+	Modules  []envModule       `json:"modules,omitempty"`
+	CacheDir string            `json:"cacheDir,omitempty"`
+	Caches   map[string]string `json:"caches,omitempty"`
+
+	// Set if the project config failed to load.
+	ConfigError string `json:"configError,omitempty"`
+}
+
+type envModule struct {
+	Path    string `json:"path"`
+	Version string `json:"version,omitempty"`
+	Dir     string `json:"dir"`
+	Owner   string `json:"owner,omitempty"`
+	Replace string `json:"replace,omitempty"`
+	Vendor  bool   `json:"vendor"`
+}
+
+func (cc *envCmd) newEnvInfo() envInfo {
+	info := hugo.NewInfo("")
+
+	env := envInfo{
+		Version:    hugo.CurrentVersion.String(),
+		CommitHash: info.CommitHash,
+		BuildDate:  info.BuildDate,
+		Extended:   hugo.IsExtended,
+		GOOS:       runtime.GOOS,
+		GOARCH:     runtime.GOARCH,
+		GoVersion:  runtime.Version(),
+		Features: map[string]bool{
+			"extended":    hugo.IsExtended,
+			"libSass":     scss.Supports(),
+			"dartSass":    dartsass.Supports(),
+			"asciidoctor": asciidocext.Supports(),
+			"pandoc":      pandoc.Supports(),
+			"rst":         rst.Supports(),
+			"git":         hasProgram("git"),
+		},
+		Dependencies: hugo.GetDependencies(),
+	}
+
+	c, err := initializeConfig(false, false, &cc.hugoBuilderCommon, cc, nil)
+	if err != nil {
+		env.ConfigError = err.Error()
+		return env
+	}
+
+	if allModules, ok := c.Cfg.Get("allmodules").(modules.Modules); ok {
+		for _, m := range allModules {
+			if m.Owner() == nil {
+				// The project itself.
+				continue
+			}
+			em := envModule{
+				Path:    m.Path(),
+				Version: m.Version(),
+				Dir:     m.Dir(),
+				Owner:   m.Owner().Path(),
+				Vendor:  m.Vendor(),
+			}
+			if m.Replace() != nil {
+				em.Replace = m.Replace().Path()
+			}
+			env.Modules = append(env.Modules, em)
+		}
+	}
+
+	env.CacheDir = c.Cfg.GetString("cacheDir")
+	caches, err := filecache.DecodeConfig(c.Fs.Source, c.Cfg)
+	if err != nil {
+		env.ConfigError = err.Error()
+		return env
+	}
+	resourceDir := c.hugo().PathSpec.AbsPathify(c.Cfg.GetString("resourceDir"))
+	env.Caches = make(map[string]string)
+	for name, cache := range caches {
+		dir := cache.Dir
+		if !filepath.IsAbs(dir) {
+			// The caches in :resourceDir.
+			dir = filepath.Join(resourceDir, dir)
+		}
+		env.Caches[name] = dir
+	}
+
+	return env
+}
+
+// hasProgram reports whether the program name is found in $PATH.
+func hasProgram(name string) bool {
+	p, err := safeexec.LookPath(name)
+	return err == nil && p != ""
 }
@@ -23,7 +23,7 @@ type genCmd struct {
 	*baseCmd
 }
 
-func newGenCmd() *genCmd {
+func (b *commandsBuilder) newGenCmd() *genCmd {
 	cc := &genCmd{}
 	cc.baseCmd = newBaseCmd(&cobra.Command{
 		Use:   "gen",
@@ -35,7 +35,9 @@ func newGenCmd() *genCmd {
 		newGenDocCmd().getCommand(),
 		newGenManCmd().getCommand(),
 		createGenDocsHelper().getCommand(),
-		createGenChromaStyles().getCommand())
+		createGenChromaStyles().getCommand(),
+		b.newGenConfigSchemaCmd().getCommand(),
+		b.newGenThemeDocsCmd().getCommand())
 
 	return cc
 }
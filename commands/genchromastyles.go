@@ -14,7 +14,13 @@
 package commands
 
 import (
-	"os"
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode"
 
 	"github.com/alecthomas/chroma"
 	"github.com/alecthomas/chroma/formatters/html"
@@ -28,6 +34,11 @@ type genChromaStyles struct {
 	style          string
 	highlightStyle string
 	linesStyle     string
+
+	darkStyle          string
+	darkHighlightStyle string
+	darkSelector       string
+	variables          bool
 	*baseCmd
 }
 
@@ -39,34 +50,309 @@ func createGenChromaStyles() *genChromaStyles {
 			Short: "Generate CSS stylesheet for the Chroma code highlighter",
 			Long: `Generate CSS stylesheet for the Chroma code highlighter for a given style. This stylesheet is needed if pygmentsUseClasses is enabled in config.
 
+Use --darkStyle to add a second style used in dark mode. By default it's wrapped in a
+prefers-color-scheme media query; use --darkSelector to scope it to a class instead, e.g.:
+
+    hugo gen chromastyles --style=github --darkStyle=monokai --darkSelector=html.dark
+
+With --variables, the colors and font styles are set with CSS custom properties
+(e.g. --chroma-keyword-color) defined on :root, so they can be changed without
+editing the rules.
+
 See https://help.farbox.com/pygments.html for preview of available styles`,
 		}),
 	}
 
 	g.cmd.RunE = func(cmd *cobra.Command, args []string) error {
-		return g.generate()
+		return g.generate(cmd.OutOrStdout())
 	}
 
 	g.cmd.PersistentFlags().StringVar(&g.style, "style", "friendly", "highlighter style (see https://help.farbox.com/pygments.html)")
 	g.cmd.PersistentFlags().StringVar(&g.highlightStyle, "highlightStyle", "bg:#ffffcc", "style used for highlighting lines (see https://github.com/alecthomas/chroma)")
 	g.cmd.PersistentFlags().StringVar(&g.linesStyle, "linesStyle", "", "style used for line numbers (see https://github.com/alecthomas/chroma)")
+	g.cmd.PersistentFlags().StringVar(&g.darkStyle, "darkStyle", "", "highlighter style used in dark mode")
+	g.cmd.PersistentFlags().StringVar(&g.darkHighlightStyle, "darkHighlightStyle", "", "style used for highlighting lines in dark mode")
+	g.cmd.PersistentFlags().StringVar(&g.darkSelector, "darkSelector", "", `selector for an ancestor enabling dark mode, e.g. "html.dark"; if not set, the prefers-color-scheme media query is used`)
+	g.cmd.PersistentFlags().BoolVar(&g.variables, "variables", false, "set the colors and font styles with CSS custom properties")
 
 	return g
 }
 
-func (g *genChromaStyles) generate() error {
-	builder := styles.Get(g.style).Builder()
-	if g.highlightStyle != "" {
-		builder.Add(chroma.LineHighlight, g.highlightStyle)
+func (g *genChromaStyles) generate(w io.Writer) error {
+	light, err := g.buildStyle(g.style, g.highlightStyle)
+	if err != nil {
+		return err
 	}
-	if g.linesStyle != "" {
-		builder.Add(chroma.LineNumbers, g.linesStyle)
+
+	if g.darkStyle == "" && !g.variables {
+		formatter := html.New(html.WithAllClasses(true))
+		return formatter.WriteCSS(w, light)
 	}
-	style, err := builder.Build()
+
+	lightRules, err := chromaCSSRules(light)
 	if err != nil {
 		return err
 	}
-	formatter := html.New(html.WithAllClasses(true))
-	formatter.WriteCSS(os.Stdout, style)
+
+	var darkRules []cssRule
+	if g.darkStyle != "" {
+		dark, err := g.buildStyle(g.darkStyle, g.darkHighlightStyle)
+		if err != nil {
+			return err
+		}
+		if darkRules, err = chromaCSSRules(dark); err != nil {
+			return err
+		}
+	}
+
+	if g.variables {
+		writeChromaCSSWithVariables(w, lightRules, darkRules, g.darkSelector)
+	} else {
+		writeChromaCSSWithDark(w, lightRules, darkRules, g.darkSelector)
+	}
+
 	return nil
 }
+
+func (g *genChromaStyles) buildStyle(name, highlightStyle string) (*chroma.Style, error) {
+	if styles.Registry[name] == nil {
+		return nil, newUserError(fmt.Sprintf("unknown style %q", name))
+	}
+	builder := styles.Get(name).Builder()
+	if highlightStyle != "" {
+		builder.Add(chroma.LineHighlight, highlightStyle)
+	}
+	if g.linesStyle != "" {
+		builder.Add(chroma.LineNumbers, g.linesStyle)
+	}
+	return builder.Build()
+}
+
+// cssRule is a rule written by the Chroma HTML formatter, e.g.
+// /* Keyword */ .chroma .k { color: #66d9ef }
+type cssRule struct {
+	name     string
+	selector string
+	decls    []cssDecl
+}
+
+type cssDecl struct {
+	prop, value string
+}
+
+func (r cssRule) get(prop string) (string, bool) {
+	for _, d := range r.decls {
+		if d.prop == prop {
+			return d.value, true
+		}
+	}
+	return "", false
+}
+
+func (r cssRule) write(w io.Writer, indent, selectorPrefix string) {
+	decls := make([]string, len(r.decls))
+	for i, d := range r.decls {
+		decls[i] = d.prop + ": " + d.value
+	}
+	fmt.Fprintf(w, "%s/* %s */ %s%s { %s }\n", indent, r.name, selectorPrefix, r.selector, strings.Join(decls, "; "))
+}
+
+var cssRuleRe = regexp.MustCompile(`^/\* (\w+) \*/ (.+?) \{(.*)\}$`)
+
+func chromaCSSRules(style *chroma.Style) ([]cssRule, error) {
+	var buf bytes.Buffer
+	if err := html.New(html.WithAllClasses(true)).WriteCSS(&buf, style); err != nil {
+		return nil, err
+	}
+
+	var rules []cssRule
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		m := cssRuleRe.FindStringSubmatch(strings.TrimSpace(scanner.Text()))
+		if m == nil {
+			continue
+		}
+		r := cssRule{name: m[1], selector: m[2]}
+		for _, decl := range strings.Split(m[3], ";") {
+			parts := strings.SplitN(decl, ":", 2)
+			if len(parts) != 2 {
+				continue
+			}
+			r.decls = append(r.decls, cssDecl{prop: strings.TrimSpace(parts[0]), value: strings.TrimSpace(parts[1])})
+		}
+		rules = append(rules, r)
+	}
+
+	return rules, scanner.Err()
+}
+
+// mergeCSSRules returns the rules in light and dark, in order, with the
+// dark rules matched to the light rules by name.
+func mergeCSSRules(light, dark []cssRule) (names []string, lightByName, darkByName map[string]cssRule) {
+	lightByName = make(map[string]cssRule)
+	darkByName = make(map[string]cssRule)
+	for _, r := range light {
+		names = append(names, r.name)
+		lightByName[r.name] = r
+	}
+	for _, r := range dark {
+		if _, found := lightByName[r.name]; !found {
+			names = append(names, r.name)
+		}
+		darkByName[r.name] = r
+	}
+	return
+}
+
+func writeDarkScope(w io.Writer, darkSelector string, f func(indent, selectorPrefix string)) {
+	if darkSelector != "" {
+		f("", darkSelector+" ")
+		return
+	}
+	fmt.Fprintln(w, "@media (prefers-color-scheme: dark) {")
+	f("  ", "")
+	fmt.Fprintln(w, "}")
+}
+
+// writeChromaCSSWithDark writes the light rules followed by the dark rules
+// scoped to darkSelector or the prefers-color-scheme media query. The
+// properties set in the light style only are unset in the dark rules.
+func writeChromaCSSWithDark(w io.Writer, light, dark []cssRule, darkSelector string) {
+	for _, r := range light {
+		r.write(w, "", "")
+	}
+
+	names, lightByName, darkByName := mergeCSSRules(light, dark)
+
+	writeDarkScope(w, darkSelector, func(indent, selectorPrefix string) {
+		for _, name := range names {
+			dr, found := darkByName[name]
+			if !found {
+				continue
+			}
+			for _, d := range lightByName[name].decls {
+				if _, found := dr.get(d.prop); !found {
+					dr.decls = append(dr.decls, cssDecl{prop: d.prop, value: "unset"})
+				}
+			}
+			dr.write(w, indent, selectorPrefix)
+		}
+	})
+}
+
+// chromaStyleProps are the properties set from the styles, as opposed to
+// the layout of e.g. the line number tables.
+var chromaStyleProps = []string{"color", "background-color", "border", "font-weight", "font-style", "text-decoration"}
+
+func isChromaStyleProp(prop string) bool {
+	for _, p := range chromaStyleProps {
+		if p == prop {
+			return true
+		}
+	}
+	return false
+}
+
+// writeChromaCSSWithVariables writes the rules with the style properties
+// set from CSS custom properties, defined on :root for the light style and
+// in the dark scope for the dark style, if any. A property missing in a
+// style is set to initial, which makes the property it's used in unset.
+// Properties with the same value in both styles are written as is.
+func writeChromaCSSWithVariables(w io.Writer, light, dark []cssRule, darkSelector string) {
+	names, lightByName, darkByName := mergeCSSRules(light, dark)
+
+	type variable struct {
+		name, rule, prop string
+	}
+
+	var (
+		vars  []variable
+		rules []cssRule
+	)
+
+	for _, name := range names {
+		lr, dr := lightByName[name], darkByName[name]
+		rule := lr
+		if rule.selector == "" {
+			rule = dr
+		}
+		rule.decls = nil
+
+		sameValue := func(prop string) bool {
+			if len(dark) == 0 {
+				return false
+			}
+			lv, lfound := lr.get(prop)
+			dv, dfound := dr.get(prop)
+			return lfound && dfound && lv == dv
+		}
+
+		addDecl := func(prop, value string) {
+			if _, found := rule.get(prop); found {
+				return
+			}
+			if isChromaStyleProp(prop) && !sameValue(prop) {
+				v := variable{name: "--chroma-" + kebabCase(name) + "-" + prop, rule: name, prop: prop}
+				vars = append(vars, v)
+				value = "var(" + v.name + ")"
+			}
+			rule.decls = append(rule.decls, cssDecl{prop: prop, value: value})
+		}
+
+		for _, d := range lr.decls {
+			addDecl(d.prop, d.value)
+		}
+		for _, d := range dr.decls {
+			addDecl(d.prop, d.value)
+		}
+		rules = append(rules, rule)
+	}
+
+	writeVars := func(indent, selector string, byName map[string]cssRule) {
+		fmt.Fprintf(w, "%s%s {\n", indent, selector)
+		for _, v := range vars {
+			value, found := byName[v.rule].get(v.prop)
+			if !found {
+				value = "initial"
+			}
+			fmt.Fprintf(w, "%s  %s: %s;\n", indent, v.name, value)
+		}
+		fmt.Fprintf(w, "%s}\n", indent)
+	}
+
+	writeVars("", ":root", lightByName)
+	if len(dark) > 0 {
+		if darkSelector != "" {
+			writeVars("", darkSelector, darkByName)
+		} else {
+			fmt.Fprintln(w, "@media (prefers-color-scheme: dark) {")
+			writeVars("  ", ":root", darkByName)
+			fmt.Fprintln(w, "}")
+		}
+	}
+
+	for _, r := range rules {
+		r.write(w, "", "")
+	}
+}
+
+// kebabCase converts e.g. KeywordConstant to keyword-constant and
+// LineTableTD to line-table-td.
+func kebabCase(s string) string {
+	var (
+		b    strings.Builder
+		prev rune
+	)
+	for _, r := range s {
+		if unicode.IsUpper(r) {
+			if unicode.IsLower(prev) {
+				b.WriteRune('-')
+			}
+			b.WriteRune(unicode.ToLower(r))
+		} else {
+			b.WriteRune(r)
+		}
+		prev = r
+	}
+	return b.String()
+}
@@ -0,0 +1,70 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bytes"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestGenChromaStyles(t *testing.T) {
+	c := qt.New(t)
+
+	gen := func(c *qt.C, args ...string) string {
+		var buf bytes.Buffer
+		cmd := newCommandsBuilder().addAll().build().getCommand()
+		cmd.SetOut(&buf)
+		cmd.SetArgs(append([]string{"gen", "chromastyles", "--style=github"}, args...))
+		_, err := cmd.ExecuteC()
+		c.Assert(err, qt.IsNil)
+		return buf.String()
+	}
+
+	c.Run("Default", func(c *qt.C) {
+		css := gen(c)
+		c.Assert(css, qt.Contains, "/* Keyword */ .chroma .k { color: #000000; font-weight: bold }")
+		c.Assert(css, qt.Not(qt.Contains), "@media")
+	})
+
+	c.Run("Media query", func(c *qt.C) {
+		css := gen(c, "--darkStyle=monokai")
+		c.Assert(css, qt.Contains, "/* Keyword */ .chroma .k { color: #000000; font-weight: bold }\n")
+		c.Assert(css, qt.Contains, "@media (prefers-color-scheme: dark) {\n")
+		c.Assert(css, qt.Contains, "  /* Keyword */ .chroma .k { color: #66d9ef; font-weight: unset }\n")
+	})
+
+	c.Run("Selector", func(c *qt.C) {
+		css := gen(c, "--darkStyle=monokai", "--darkSelector=html.dark")
+		c.Assert(css, qt.Contains, "/* Keyword */ html.dark .chroma .k { color: #66d9ef; font-weight: unset }\n")
+		c.Assert(css, qt.Not(qt.Contains), "@media")
+	})
+
+	c.Run("Variables", func(c *qt.C) {
+		css := gen(c, "--darkStyle=monokai", "--variables")
+		c.Assert(css, qt.Contains, ":root {\n  --chroma-background-background-color: #ffffff;\n")
+		c.Assert(css, qt.Contains, "@media (prefers-color-scheme: dark) {\n  :root {\n")
+		c.Assert(css, qt.Contains, "    --chroma-keyword-color: #66d9ef;\n    --chroma-keyword-font-weight: initial;\n")
+		c.Assert(css, qt.Contains, "/* Keyword */ .chroma .k { color: var(--chroma-keyword-color); font-weight: var(--chroma-keyword-font-weight) }\n")
+		c.Assert(css, qt.Contains, "/* LineTableTD */ .chroma .lntd { vertical-align: top; padding: 0; margin: 0; border: 0 }\n")
+	})
+
+	c.Run("Unknown style", func(c *qt.C) {
+		cmd := newCommandsBuilder().addAll().build().getCommand()
+		cmd.SetArgs([]string{"gen", "chromastyles", "--darkStyle=nosuchstyle"})
+		_, err := cmd.ExecuteC()
+		c.Assert(err, qt.ErrorMatches, `unknown style "nosuchstyle"\s*`)
+	})
+}
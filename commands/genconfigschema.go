@@ -0,0 +1,68 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"encoding/json"
+
+	"github.com/gohugoio/hugo/hugolib"
+	"github.com/gohugoio/hugo/modules"
+	"github.com/spf13/cobra"
+)
+
+var _ cmder = (*genConfigSchemaCmd)(nil)
+
+type genConfigSchemaCmd struct {
+	*baseBuilderCmd
+}
+
+func (b *commandsBuilder) newGenConfigSchemaCmd() *genConfigSchemaCmd {
+	cc := &genConfigSchemaCmd{}
+
+	cmd := &cobra.Command{
+		Use:   "configschema",
+		Short: "Generate a JSON Schema for the site configuration",
+		Long: `Generate a JSON Schema for the site configuration, e.g. to get autocompletion
+in your editor or to validate the config files in CI:
+
+    hugo gen configschema > hugo.schema.json
+
+If run in a Hugo project, the params schemas set in the project and its modules
+and themes (module.paramsSchema) are included.`,
+		RunE: cc.generate,
+	}
+
+	cc.baseBuilderCmd = b.newBuilderBasicCmd(cmd)
+
+	return cc
+}
+
+func (c *genConfigSchemaCmd) generate(cmd *cobra.Command, args []string) error {
+	cfg, err := initializeConfig(false, false, &c.hugoBuilderCommon, c, nil)
+	if err != nil {
+		return err
+	}
+
+	mods, _ := cfg.Cfg.Get("allmodules").(modules.Modules)
+
+	s, err := hugolib.ConfigSchema(cfg.Fs.Source, mods)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(cmd.OutOrStdout())
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(s)
+}
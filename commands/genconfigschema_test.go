@@ -0,0 +1,78 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestGenConfigSchema(t *testing.T) {
+	c := qt.New(t)
+
+	dir, clean, err := createSimpleTestSite(t, testSiteConfig{configTOML: `
+baseURL = "https://example.org"
+theme = "mytheme"
+`})
+	c.Assert(err, qt.IsNil)
+	defer clean()
+
+	writeFile(t, filepath.Join(dir, "themes", "mytheme", "config.toml"), `
+[module]
+paramsSchema = "schema/params.json"
+`)
+	writeFile(t, filepath.Join(dir, "themes", "mytheme", "schema", "params.json"), `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "type": "object",
+  "properties": {
+    "logo": {"type": "string"}
+  }
+}`)
+
+	var buf bytes.Buffer
+	cmd := newCommandsBuilder().addAll().build().getCommand()
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"-s=" + dir, "gen", "configschema"})
+	_, err = cmd.ExecuteC()
+	c.Assert(err, qt.IsNil)
+
+	var schema struct {
+		Schema     string `json:"$schema"`
+		Properties map[string]struct {
+			Type                 interface{}
+			Default              interface{}
+			Properties           map[string]json.RawMessage
+			AdditionalProperties struct {
+				Properties map[string]json.RawMessage
+			}
+			AllOf []map[string]interface{}
+		}
+	}
+	c.Assert(json.Unmarshal(buf.Bytes(), &schema), qt.IsNil)
+
+	props := schema.Properties
+	c.Assert(schema.Schema, qt.Equals, "http://json-schema.org/draft-07/schema#")
+	c.Assert(props["baseURL"].Type, qt.Equals, "string")
+	c.Assert(props["paginate"].Default, qt.Equals, float64(10))
+	c.Assert(props["markup"].Properties["goldmark"], qt.Not(qt.IsNil))
+	c.Assert(props["caches"].Properties["getjson"], qt.Not(qt.IsNil))
+	c.Assert(props["params"].AllOf, qt.HasLen, 1)
+	c.Assert(props["params"].AllOf[0]["$schema"], qt.IsNil)
+	c.Assert(props["params"].AllOf[0]["properties"], qt.DeepEquals, map[string]interface{}{"logo": map[string]interface{}{"type": "string"}})
+	c.Assert(props["languages"].AdditionalProperties.Properties["params"], qt.Not(qt.IsNil))
+}
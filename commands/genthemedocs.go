@@ -0,0 +1,359 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gohugoio/hugo/hugofs/files"
+	"github.com/gohugoio/hugo/modules"
+	"github.com/gohugoio/hugo/tpl"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+var _ cmder = (*genThemeDocsCmd)(nil)
+
+type genThemeDocsCmd struct {
+	module string
+	format string
+
+	*baseBuilderCmd
+}
+
+func (b *commandsBuilder) newGenThemeDocsCmd() *genThemeDocsCmd {
+	cc := &genThemeDocsCmd{}
+
+	cmd := &cobra.Command{
+		Use:   "themedocs",
+		Short: "Generate reference docs for the shortcodes and partials",
+		Long: `Generate reference docs for the shortcodes and partials in the project and
+its modules and themes, in Markdown or JSON.
+
+The docs are extracted from the templates: the doc comment at the top of the
+template, with any examples after a line with "Example:", and for shortcodes,
+the parameters declared in $_hugo_config:
+
+    {{/*
+    Renders an image with a caption.
+
+    Example:
+    {{< figure2 src="sunset.jpg" caption="Sunset" >}}
+    */}}
+
+Use --module to only include the templates in one module, e.g. a theme:
+
+    hugo gen themedocs --module mytheme > docs/content/reference.md`,
+		RunE: cc.generate,
+	}
+
+	cmd.Flags().StringVar(&cc.module, "module", "", "only include the templates in the module with this path, e.g. the theme name")
+	cmd.Flags().StringVar(&cc.format, "format", "markdown", "the output format, markdown or json")
+
+	cc.baseBuilderCmd = b.newBuilderBasicCmd(cmd)
+
+	return cc
+}
+
+// templateDoc is the reference doc for a shortcode or partial.
+type templateDoc struct {
+	Name        string             `json:"name"`
+	Module      string             `json:"module"`
+	File        string             `json:"file"`
+	Description string             `json:"description,omitempty"`
+	Params      []templateDocParam `json:"params,omitempty"`
+	Returns     []templateDocParam `json:"returns,omitempty"`
+	Slots       []string           `json:"slots,omitempty"`
+	Examples    []string           `json:"examples,omitempty"`
+}
+
+type templateDocParam struct {
+	Name        string      `json:"name"`
+	Type        string      `json:"type,omitempty"`
+	Required    bool        `json:"required,omitempty"`
+	Default     interface{} `json:"default,omitempty"`
+	Description string      `json:"description,omitempty"`
+}
+
+type themeDocs struct {
+	Shortcodes []templateDoc `json:"shortcodes"`
+	Partials   []templateDoc `json:"partials"`
+}
+
+func (c *genThemeDocsCmd) generate(cmd *cobra.Command, args []string) error {
+	if c.format != "markdown" && c.format != "json" {
+		return newUserError(fmt.Sprintf("invalid format %q, must be markdown or json", c.format))
+	}
+
+	cfg, err := initializeConfig(false, false, &c.hugoBuilderCommon, c, nil)
+	if err != nil {
+		return err
+	}
+
+	mods, _ := cfg.Cfg.Get("allmodules").(modules.Modules)
+
+	var (
+		docs      themeDocs
+		found     bool
+		seen      = make(map[string]bool)
+		addModule = func(m modules.Module) error {
+			for _, mount := range m.Mounts() {
+				if mount.Target != files.ComponentFolderLayouts {
+					continue
+				}
+				dir := filepath.Join(m.Dir(), mount.Source)
+				shortcodes, err := collectTemplateDocs(cfg.Fs.Source, m.Path(), dir, "shortcodes", seen)
+				if err != nil {
+					return err
+				}
+				partials, err := collectTemplateDocs(cfg.Fs.Source, m.Path(), dir, "partials", seen)
+				if err != nil {
+					return err
+				}
+				docs.Shortcodes = append(docs.Shortcodes, shortcodes...)
+				docs.Partials = append(docs.Partials, partials...)
+			}
+			return nil
+		}
+	)
+
+	// The modules are ordered by precedence, so any template overriding a
+	// template in another module comes first.
+	for _, m := range mods {
+		if c.module != "" && m.Path() != c.module {
+			continue
+		}
+		found = true
+		if err := addModule(m); err != nil {
+			return err
+		}
+	}
+
+	if !found {
+		return newUserError(fmt.Sprintf("module %q not found", c.module))
+	}
+
+	sortTemplateDocs(docs.Shortcodes)
+	sortTemplateDocs(docs.Partials)
+
+	if c.format == "json" {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		return enc.Encode(docs)
+	}
+
+	writeThemeDocsMarkdown(cmd.OutOrStdout(), docs)
+
+	return nil
+}
+
+// collectTemplateDocs collects the docs for the templates in the kind
+// folder, shortcodes or partials, in the layouts folder dir.
+// Templates already in seen are skipped.
+func collectTemplateDocs(fs afero.Fs, module, dir, kind string, seen map[string]bool) ([]templateDoc, error) {
+	root := filepath.Join(dir, kind)
+
+	var filenames []string
+	err := afero.Walk(fs, root, func(filename string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !info.IsDir() {
+			filenames = append(filenames, filename)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if kind == "shortcodes" {
+		// Document the shortcode from the template without variants, e.g.
+		// myshortcode.html before myshortcode.amp.html.
+		sort.SliceStable(filenames, func(i, j int) bool {
+			return strings.Count(filepath.Base(filenames[i]), ".") < strings.Count(filepath.Base(filenames[j]), ".")
+		})
+	}
+
+	var docs []templateDoc
+
+	for _, filename := range filenames {
+		rel, _ := filepath.Rel(root, filename)
+		rel = filepath.ToSlash(rel)
+
+		name := rel
+		if kind == "shortcodes" {
+			base := path.Base(rel)
+			if i := strings.Index(base, "."); i > 0 {
+				base = base[:i]
+			}
+			name = path.Join(path.Dir(rel), base)
+		}
+
+		key := kind + "/" + name
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		b, err := afero.ReadFile(fs, filename)
+		if err != nil {
+			return nil, err
+		}
+
+		doc, err := extractTemplateDoc(string(b))
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s", filename)
+		}
+		doc.Name = name
+		doc.Module = module
+		doc.File = path.Join("layouts", kind, rel)
+
+		docs = append(docs, doc)
+	}
+
+	return docs, nil
+}
+
+var (
+	templateDocConfigRe  = regexp.MustCompile("^\\s*{{-?\\s*\\$_hugo_config\\s*:=\\s*`([^`]*)`\\s*-?}}")
+	templateDocCommentRe = regexp.MustCompile(`^\s*{{-?\s*/\*((?s).*?)\*/\s*-?}}`)
+	templateDocExampleRe = regexp.MustCompile(`(?m)^\s*Examples?:\s*$`)
+	blankLinesRe         = regexp.MustCompile(`\n\s*\n`)
+)
+
+// extractTemplateDoc extracts the doc comment and the declared parameters
+// from the top of a template.
+func extractTemplateDoc(s string) (templateDoc, error) {
+	var doc templateDoc
+
+	if m := templateDocConfigRe.FindStringSubmatch(s); m != nil {
+		var cfg tpl.ParseConfig
+		if err := cfg.Decode(m[1]); err != nil {
+			return doc, errors.Wrap(err, "failed to decode $_hugo_config")
+		}
+		doc.Params = templateDocParams(cfg.Params)
+		doc.Returns = templateDocParams(cfg.Returns)
+		doc.Slots = cfg.Slots
+		s = s[len(m[0]):]
+	}
+
+	m := templateDocCommentRe.FindStringSubmatch(s)
+	if m == nil {
+		return doc, nil
+	}
+	comment := m[1]
+
+	if loc := templateDocExampleRe.FindStringIndex(comment); loc != nil {
+		for _, example := range blankLinesRe.Split(strings.TrimSpace(comment[loc[1]:]), -1) {
+			if example = strings.TrimSpace(example); example != "" {
+				doc.Examples = append(doc.Examples, example)
+			}
+		}
+		comment = comment[:loc[0]]
+	}
+
+	doc.Description = strings.TrimSpace(comment)
+
+	return doc, nil
+}
+
+func templateDocParams(decls map[string]tpl.Param) []templateDocParam {
+	var params []templateDocParam
+	for name, p := range decls {
+		params = append(params, templateDocParam{
+			Name:        name,
+			Type:        p.Type,
+			Required:    p.Required,
+			Default:     p.Default,
+			Description: p.Description,
+		})
+	}
+	sort.Slice(params, func(i, j int) bool {
+		return params[i].Name < params[j].Name
+	})
+	return params
+}
+
+func sortTemplateDocs(docs []templateDoc) {
+	sort.SliceStable(docs, func(i, j int) bool {
+		return docs[i].Name < docs[j].Name
+	})
+}
+
+// shortcodeEscaper escapes the shortcodes in the Markdown docs, so they are
+// not rendered if the docs are added to a Hugo site.
+var shortcodeEscaper = strings.NewReplacer("{{<", "{{</*", ">}}", "*/>}}", "{{%", "{{%/*", "%}}", "*/%}}")
+
+func writeThemeDocsMarkdown(w io.Writer, docs themeDocs) {
+	table := func(title string, params []templateDocParam) {
+		if len(params) == 0 {
+			return
+		}
+		fmt.Fprintf(w, "%s | Type | Required | Default | Description\n--- | --- | --- | --- | ---\n", title)
+		for _, p := range params {
+			var required, def string
+			if p.Required {
+				required = "yes"
+			}
+			if p.Default != nil {
+				def = fmt.Sprintf("`%v`", p.Default)
+			}
+			fmt.Fprintf(w, "`%s` | %s | %s | %s | %s\n", p.Name, p.Type, required, def, p.Description)
+		}
+		fmt.Fprintln(w)
+	}
+
+	section := func(title string, docs []templateDoc, signature func(d templateDoc) string) {
+		if len(docs) == 0 {
+			return
+		}
+		fmt.Fprintf(w, "## %s\n\n", title)
+		for _, d := range docs {
+			fmt.Fprintf(w, "### %s\n\n", d.Name)
+			fmt.Fprintf(w, "`%s`, defined in `%s` in %s.\n\n", shortcodeEscaper.Replace(signature(d)), d.File, d.Module)
+			if d.Description != "" {
+				fmt.Fprintf(w, "%s\n\n", shortcodeEscaper.Replace(d.Description))
+			}
+			table("Parameter", d.Params)
+			table("Return value", d.Returns)
+			if len(d.Slots) > 0 {
+				fmt.Fprintf(w, "Slots: `%s`\n\n", strings.Join(d.Slots, "`, `"))
+			}
+			for _, example := range d.Examples {
+				fmt.Fprintf(w, "```\n%s\n```\n\n", shortcodeEscaper.Replace(example))
+			}
+		}
+	}
+
+	section("Shortcodes", docs.Shortcodes, func(d templateDoc) string {
+		return fmt.Sprintf("{{< %s >}}", d.Name)
+	})
+	section("Partials", docs.Partials, func(d templateDoc) string {
+		return fmt.Sprintf("{{ partial %q . }}", d.Name)
+	})
+}
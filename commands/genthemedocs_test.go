@@ -0,0 +1,96 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestGenThemeDocs(t *testing.T) {
+	c := qt.New(t)
+
+	dir, clean, err := createSimpleTestSite(t, testSiteConfig{configTOML: `
+baseURL = "https://example.org"
+theme = "mytheme"
+`})
+	c.Assert(err, qt.IsNil)
+	defer clean()
+
+	writeFile(t, filepath.Join(dir, "themes", "mytheme", "layouts", "shortcodes", "figure2.html"), "{{ $_hugo_config := `"+`{ "params": {
+  "src": { "type": "string", "required": true, "description": "The image." },
+  "width": { "type": "int", "default": 640 }
+}}`+"` }}"+`
+{{/*
+Renders an image with a caption.
+
+Example:
+{{< figure2 src="sunset.jpg" >}}
+
+{{< figure2 src="sunset.jpg" width=300 >}}
+*/}}
+<img src="{{ .Get "src" }}">`)
+	writeFile(t, filepath.Join(dir, "themes", "mytheme", "layouts", "shortcodes", "figure2.amp.html"), `AMP`)
+	writeFile(t, filepath.Join(dir, "themes", "mytheme", "layouts", "partials", "head", "meta.html"), `{{- /* Renders the meta tags. */ -}}`)
+	writeFile(t, filepath.Join(dir, "themes", "mytheme", "layouts", "partials", "stats.html"), "{{ $_hugo_config := `"+`{ "returns": {
+  "count": { "type": "int", "required": true, "description": "The number of pages." }
+}}`+"` }}"+`
+{{ return "count" (len .Pages) }}`)
+	writeFile(t, filepath.Join(dir, "themes", "mytheme", "layouts", "partials", "components", "card.html"), "{{ $_hugo_config := `"+`{ "slots": ["header", "footer"] }`+"` }}{{ .Children }}")
+	// Overrides the theme's partial.
+	writeFile(t, filepath.Join(dir, "layouts", "partials", "head", "meta.html"), `{{/* My meta tags. */}}`)
+
+	gen := func(args ...string) string {
+		var buf bytes.Buffer
+		cmd := newCommandsBuilder().addAll().build().getCommand()
+		cmd.SetOut(&buf)
+		cmd.SetArgs(append([]string{"-s=" + dir, "gen", "themedocs"}, args...))
+		_, err = cmd.ExecuteC()
+		c.Assert(err, qt.IsNil)
+		return buf.String()
+	}
+
+	var docs themeDocs
+	c.Assert(json.Unmarshal([]byte(gen("--format", "json")), &docs), qt.IsNil)
+	c.Assert(docs.Shortcodes, qt.HasLen, 1)
+	sc := docs.Shortcodes[0]
+	c.Assert(sc.Name, qt.Equals, "figure2")
+	c.Assert(sc.Module, qt.Equals, "mytheme")
+	c.Assert(sc.File, qt.Equals, "layouts/shortcodes/figure2.html")
+	c.Assert(sc.Description, qt.Equals, "Renders an image with a caption.")
+	c.Assert(sc.Examples, qt.DeepEquals, []string{`{{< figure2 src="sunset.jpg" >}}`, `{{< figure2 src="sunset.jpg" width=300 >}}`})
+	c.Assert(sc.Params, qt.DeepEquals, []templateDocParam{
+		{Name: "src", Type: "string", Required: true, Description: "The image."},
+		{Name: "width", Type: "int", Default: float64(640)},
+	})
+	c.Assert(docs.Partials, qt.DeepEquals, []templateDoc{
+		{Name: "components/card.html", Module: "mytheme", File: "layouts/partials/components/card.html", Slots: []string{"header", "footer"}},
+		{Name: "head/meta.html", Module: "project", File: "layouts/partials/head/meta.html", Description: "My meta tags."},
+		{Name: "stats.html", Module: "mytheme", File: "layouts/partials/stats.html", Returns: []templateDocParam{
+			{Name: "count", Type: "int", Required: true, Description: "The number of pages."},
+		}},
+	})
+
+	md := gen("--module", "mytheme")
+	c.Assert(md, qt.Contains, "### figure2\n\n`{{</* figure2 */>}}`, defined in `layouts/shortcodes/figure2.html` in mytheme.\n\nRenders an image with a caption.")
+	c.Assert(md, qt.Contains, "`src` | string | yes |  | The image.\n`width` | int |  | `640` | \n")
+	c.Assert(md, qt.Contains, "```\n{{</* figure2 src=\"sunset.jpg\" width=300 */>}}\n```")
+	c.Assert(md, qt.Contains, "### head/meta.html\n\n`{{ partial \"head/meta.html\" . }}`, defined in `layouts/partials/head/meta.html` in mytheme.\n\nRenders the meta tags.")
+	c.Assert(md, qt.Contains, "Return value | Type | Required | Default | Description\n--- | --- | --- | --- | ---\n`count` | int | yes |  | The number of pages.\n")
+	c.Assert(md, qt.Contains, "Slots: `header`, `footer`\n")
+}
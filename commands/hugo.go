@@ -25,6 +25,7 @@ import (
 	"runtime"
 	"runtime/pprof"
 	"runtime/trace"
+	"sort"
 	"strings"
 	"sync/atomic"
 	"syscall"
@@ -32,8 +33,6 @@ import (
 
 	"github.com/gohugoio/hugo/hugofs"
 
-	"github.com/gohugoio/hugo/resources/page"
-
 	"github.com/pkg/errors"
 
 	"github.com/gohugoio/hugo/common/herrors"
@@ -53,6 +52,8 @@ import (
 	"github.com/gohugoio/hugo/helpers"
 	"github.com/gohugoio/hugo/hugolib"
 	"github.com/gohugoio/hugo/livereload"
+	"github.com/gohugoio/hugo/metrics"
+	"github.com/gohugoio/hugo/notify"
 	"github.com/gohugoio/hugo/watcher"
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
@@ -85,8 +86,15 @@ func Execute(args []string) Response {
 	cmd := hugoCmd.getCommand()
 	cmd.SetArgs(args)
 
+	// We print the error ourselves to support the JSON log format.
+	cmd.SilenceErrors = true
+
 	c, err := cmd.ExecuteC()
 
+	if err != nil && c != nil {
+		printError(c, err)
+	}
+
 	var resp Response
 
 	if c == cmd && hugoCmd.c != nil {
@@ -113,6 +121,14 @@ func Execute(args []string) Response {
 	return resp
 }
 
+func printError(cmd *cobra.Command, err error) {
+	if loggers.IsJSON() {
+		loggers.NewEntry("error", err.Error()).Write(cmd.ErrOrStderr())
+		return
+	}
+	cmd.PrintErrln("Error:", err.Error())
+}
+
 // InitializeConfig initializes a config file with sensible default configuration flags.
 func initializeConfig(mustHaveConfigFile, running bool,
 	h *hugoBuilderCommon,
@@ -139,6 +155,10 @@ func (c *commandeer) createLogger(cfg config.Provider, running bool) (loggers.Lo
 		outHandle = os.Stdout
 	}
 
+	if err := loggers.SetFormat(cfg.GetString("logFormat")); err != nil {
+		return nil, newUserError(err)
+	}
+
 	if c.h.verboseLog || c.h.logging || (c.h.logFile != "") {
 		var err error
 		if logFile != "" {
@@ -170,7 +190,10 @@ func (c *commandeer) createLogger(cfg config.Provider, running bool) (loggers.Lo
 	loggers.InitGlobalLogger(stdoutThreshold, logThreshold, outHandle, logHandle)
 	helpers.InitLoggers()
 
-	return loggers.NewLogger(stdoutThreshold, logThreshold, outHandle, logHandle, running), nil
+	// The logged errors are also written to the errors file, if set.
+	saveErrors := running || cfg.GetString("errorsFile") != ""
+
+	return loggers.NewLogger(stdoutThreshold, logThreshold, outHandle, logHandle, saveErrors), nil
 }
 
 func initializeFlags(cmd *cobra.Command, cfg config.Provider) {
@@ -178,6 +201,7 @@ func initializeFlags(cmd *cobra.Command, cfg config.Provider) {
 		"debug",
 		"verbose",
 		"logFile",
+		"logFormat",
 		// Moved from vars
 	}
 	flagKeys := []string{
@@ -195,10 +219,16 @@ func initializeFlags(cmd *cobra.Command, cfg config.Provider) {
 		"forceSyncStatic",
 		"noTimes",
 		"noChmod",
+		"staticPublishMode",
 		"ignoreVendor",
 		"ignoreVendorPaths",
 		"templateMetrics",
 		"templateMetricsHints",
+		"pageMetrics",
+		"pageMetricsCount",
+		"reproducible",
+		"errorsFile",
+		"progress",
 
 		// Moved from vars.
 		"baseURL",
@@ -217,6 +247,7 @@ func initializeFlags(cmd *cobra.Command, cfg config.Provider) {
 		"invalidateCDN",
 		"layoutDir",
 		"logFile",
+		"logFormat",
 		"maxDeletes",
 		"quiet",
 		"renderToMemory",
@@ -242,6 +273,9 @@ func initializeFlags(cmd *cobra.Command, cfg config.Provider) {
 	setValueFromFlag(cmd.Flags(), "destination", cfg, "publishDir", false)
 	setValueFromFlag(cmd.Flags(), "i18n-warnings", cfg, "logI18nWarnings", false)
 	setValueFromFlag(cmd.Flags(), "path-warnings", cfg, "logPathWarnings", false)
+	setValueFromFlag(cmd.Flags(), "debug-memory", cfg, "debugMemory", false)
+	setValueFromFlag(cmd.Flags(), "trace-endpoint", cfg, "traceEndpoint", false)
+	setValueFromFlag(cmd.Flags(), "strict-frontmatter", cfg, "strictFrontMatter", false)
 }
 
 func setValueFromFlag(flags *flag.FlagSet, key string, cfg config.Provider, targetKey string, force bool) {
@@ -272,6 +306,16 @@ func setValueFromFlag(flags *flag.FlagSet, key string, cfg config.Provider, targ
 	}
 }
 
+func (c *commandeer) printProcessingStats() {
+	if loggers.IsJSON() {
+		c.hugo().PrintProcessingStats(os.Stdout)
+		return
+	}
+	fmt.Println()
+	c.hugo().PrintProcessingStats(os.Stdout)
+	fmt.Println()
+}
+
 func isTerminal() bool {
 	return terminal.IsTerminal(os.Stdout)
 }
@@ -282,7 +326,7 @@ func (c *commandeer) fullBuild() error {
 		langCount map[string]uint64
 	)
 
-	if !c.h.quiet {
+	if !c.h.quiet && !loggers.IsJSON() {
 		fmt.Println("Start building sites … ")
 		fmt.Println(hugo.BuildVersionString())
 		if isTerminal() {
@@ -339,6 +383,14 @@ func (c *commandeer) fullBuild() error {
 		}
 	}
 
+	count, err := c.hugo().FileCaches.Evict()
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		c.logger.Infof("Evicted %d entries from the file caches", count)
+	}
+
 	return nil
 }
 
@@ -420,7 +472,7 @@ func (c *commandeer) initMemTicker() func() {
 	printMem := func() {
 		var m runtime.MemStats
 		runtime.ReadMemStats(&m)
-		fmt.Printf("\n\nAlloc = %v\nTotalAlloc = %v\nSys = %v\nNumGC = %v\n\n", formatByteCount(m.Alloc), formatByteCount(m.TotalAlloc), formatByteCount(m.Sys), m.NumGC)
+		fmt.Printf("\n\nAlloc = %v\nTotalAlloc = %v\nSys = %v\nNumGC = %v\n\n", helpers.FormatByteCount(m.Alloc), helpers.FormatByteCount(m.TotalAlloc), helpers.FormatByteCount(m.Sys), m.NumGC)
 	}
 
 	go func() {
@@ -494,15 +546,29 @@ func (c *commandeer) build() error {
 		}
 	}()
 
-	if err := c.fullBuild(); err != nil {
+	start := time.Now()
+	err = c.fullBuild()
+	c.notify(notify.EventBuild, start, err, func(s *notify.Summary) {
+		if c.hugo() == nil {
+			return
+		}
+		for _, site := range c.hugo().Sites {
+			s.Pages += int(site.ProcessingStats.Pages)
+		}
+	})
+	if err != nil {
 		return err
 	}
 
+	if c.hugo().Reproducible {
+		if err := c.normalizeModTimes(); err != nil {
+			return err
+		}
+	}
+
 	// TODO(bep) Feedback?
 	if !c.h.quiet {
-		fmt.Println()
-		c.hugo().PrintProcessingStats(os.Stdout)
-		fmt.Println()
+		c.printProcessingStats()
 
 		if createCounter, ok := c.destinationFs.(hugofs.DuplicatesReporter); ok {
 			dupes := createCounter.ReportDuplicates()
@@ -550,22 +616,41 @@ func (c *commandeer) serverBuild() error {
 		}
 	}()
 
-	if err := c.fullBuild(); err != nil {
+	start := time.Now()
+	err = c.fullBuild()
+	c.notify(notify.EventBuild, start, err, func(s *notify.Summary) {
+		if c.hugo() == nil {
+			return
+		}
+		for _, site := range c.hugo().Sites {
+			s.Pages += int(site.ProcessingStats.Pages)
+		}
+	})
+	if err != nil {
 		return err
 	}
 
+	if c.hugo().Reproducible {
+		if err := c.normalizeModTimes(); err != nil {
+			return err
+		}
+	}
+
 	// TODO(bep) Feedback?
 	if !c.h.quiet {
-		fmt.Println()
-		c.hugo().PrintProcessingStats(os.Stdout)
-		fmt.Println()
+		c.printProcessingStats()
 	}
 
 	return nil
 }
 
 func (c *commandeer) copyStatic() (map[string]uint64, error) {
-	m, err := c.doWithPublishDirs(c.copyStaticTo)
+	progress := c.hugo().Progress.Start(metrics.ProgressPhaseStatic, 0)
+	defer progress.End()
+
+	m, err := c.doWithPublishDirs(func(sourceFs *filesystems.SourceFilesystem) (uint64, error) {
+		return c.copyStaticTo(sourceFs, progress)
+	})
 	if err == nil || os.IsNotExist(err) {
 		return m, nil
 	}
@@ -604,13 +689,17 @@ func (c *commandeer) doWithPublishDirs(f func(sourceFs *filesystems.SourceFilesy
 type countingStatFs struct {
 	afero.Fs
 	statCounter uint64
+	progress    *metrics.ProgressPhase
 }
 
 func (fs *countingStatFs) Stat(name string) (os.FileInfo, error) {
 	f, err := fs.Fs.Stat(name)
 	if err == nil {
 		if !f.IsDir() {
-			atomic.AddUint64(&fs.statCounter, 1)
+			if n := atomic.AddUint64(&fs.statCounter, 1); n%3 == 0 {
+				// See the note about Stat in copyStaticTo.
+				fs.progress.Incr()
+			}
 		}
 	}
 	return f, err
@@ -626,7 +715,25 @@ func chmodFilter(dst, src os.FileInfo) bool {
 	return src.IsDir()
 }
 
-func (c *commandeer) copyStaticTo(sourceFs *filesystems.SourceFilesystem) (uint64, error) {
+// normalizeModTimes sets the modification time of all files in the publish
+// directory to the time of the build, so they're the same in every
+// reproducible build.
+func (c *commandeer) normalizeModTimes() error {
+	h := c.hugo()
+	fs := h.Fs.Destination
+	mtime := h.Clock.Now()
+	return afero.Walk(fs, h.PathSpec.PublishDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		return fs.Chtimes(path, mtime, mtime)
+	})
+}
+
+func (c *commandeer) copyStaticTo(sourceFs *filesystems.SourceFilesystem, progress *metrics.ProgressPhase) (uint64, error) {
 	publishDir := c.hugo().PathSpec.PublishDir
 	// If root, remove the second '/'
 	if publishDir == "//" {
@@ -637,7 +744,7 @@ func (c *commandeer) copyStaticTo(sourceFs *filesystems.SourceFilesystem) (uint6
 		publishDir = filepath.Join(publishDir, sourceFs.PublishFolder)
 	}
 
-	fs := &countingStatFs{Fs: sourceFs.Fs}
+	fs := &countingStatFs{Fs: sourceFs.Fs, progress: progress}
 
 	syncer := fsync.NewSyncer()
 	syncer.NoTimes = c.Cfg.GetBool("noTimes")
@@ -658,13 +765,23 @@ func (c *commandeer) copyStaticTo(sourceFs *filesystems.SourceFilesystem) (uint6
 	}
 	c.logger.Infoln("syncing static files to", publishDir)
 
+	fileSyncer, err := c.newStaticFileSyncer(fs, syncer)
+	if err != nil {
+		return 0, err
+	}
+
 	// because we are using a baseFs (to get the union right).
 	// set sync src to root
-	err := syncer.Sync(publishDir, helpers.FilePathSeparator)
+	err = fileSyncer.Sync(publishDir, helpers.FilePathSeparator)
 	if err != nil {
 		return 0, err
 	}
 
+	if l, ok := fileSyncer.(*staticLinker); ok {
+		progress.Add(int(l.numFiles))
+		return l.numFiles, nil
+	}
+
 	// Sync runs Stat 3 times for every source file (which sounds much)
 	numFiles := fs.statCounter / 3
 
@@ -695,6 +812,10 @@ func (c *commandeer) getDirList() ([]string, error) {
 				fi.Name() == "node_modules" || fi.Name() == "bower_components" {
 				return filepath.SkipDir
 			}
+			if c.isWatchIgnored(fi.Meta().Filename(), true) {
+				c.debugWatchf("not watching %s: matches watcher.ignore", fi.Meta().Filename())
+				return filepath.SkipDir
+			}
 
 			filenames = append(filenames, fi.Meta().Filename())
 		}
@@ -705,7 +826,9 @@ func (c *commandeer) getDirList() ([]string, error) {
 	watchFiles := c.hugo().PathSpec.BaseFs.WatchDirs()
 	for _, fi := range watchFiles {
 		if !fi.IsDir() {
-			filenames = append(filenames, fi.Meta().Filename())
+			if !c.isWatchIgnored(fi.Meta().Filename(), false) {
+				filenames = append(filenames, fi.Meta().Filename())
+			}
 			continue
 		}
 
@@ -715,11 +838,82 @@ func (c *commandeer) getDirList() ([]string, error) {
 		}
 	}
 
+	for _, filename := range c.extraWatchPaths() {
+		fi, err := c.Fs.Source.Stat(filename)
+		if err != nil {
+			c.logger.Warnf("Failed to watch %q: %s", filename, err)
+			continue
+		}
+		if !fi.IsDir() {
+			filenames = append(filenames, filename)
+			continue
+		}
+		if err := helpers.SymbolicWalk(c.Fs.Source, filename, walkFn); err != nil {
+			c.logger.Errorln("walker: ", err)
+		}
+	}
+
 	filenames = helpers.UniqueStringsSorted(filenames)
 
 	return filenames, nil
 }
 
+// extraWatchPaths returns the absolute paths of the extra directories and
+// files to watch set in the watcher config.
+func (c *commandeer) extraWatchPaths() []string {
+	if c.watcherConfig == nil {
+		return nil
+	}
+	workingDir := c.Cfg.GetString("workingDir")
+	var paths []string
+	for _, p := range c.watcherConfig.Paths {
+		if p == "" {
+			continue
+		}
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(workingDir, p)
+		}
+		paths = append(paths, filepath.Clean(p))
+	}
+	return paths
+}
+
+// isWatchIgnored reports whether the file or directory filename is ignored by
+// the watcher config.
+func (c *commandeer) isWatchIgnored(filename string, isDir bool) bool {
+	if c.watcherConfig == nil {
+		return false
+	}
+	rel, err := filepath.Rel(c.Cfg.GetString("workingDir"), filename)
+	if err != nil {
+		return false
+	}
+	return c.watcherConfig.Ignores(filepath.ToSlash(rel), isDir)
+}
+
+// isExtraWatchFile reports whether filename is in one of the extra watch
+// paths and not in any of the Hugo file systems, which means Hugo can't tell
+// what depends on it.
+func (c *commandeer) isExtraWatchFile(filename string) bool {
+	for _, p := range c.extraWatchPaths() {
+		if !isInDir(filename, p) {
+			continue
+		}
+		for _, fi := range c.hugo().BaseFs.AllDirs() {
+			if isInDir(filename, fi.Meta().Filename()) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// isInDir reports whether filename is dir or inside it.
+func isInDir(filename, dir string) bool {
+	return filename == dir || strings.HasPrefix(filename, dir+string(filepath.Separator))
+}
+
 func (c *commandeer) buildSites() (err error) {
 	return c.hugo().Build(hugolib.BuildCfg{})
 }
@@ -819,6 +1013,41 @@ func (c *commandeer) fullRebuild(changeType string) {
 	}()
 }
 
+// The poll interval used if the file system events can't be watched.
+const defaultPollInterval = 700 * time.Millisecond
+
+// newBatcher creates the file watcher, polling for changes if set with
+// --poll or if the file system events can't be watched.
+func (c *commandeer) newBatcher() (*watcher.Batcher, error) {
+	var pollInterval time.Duration
+	if c.h.poll != "" {
+		var err error
+		pollInterval, err = time.ParseDuration(c.h.poll)
+		if err != nil || pollInterval <= 0 {
+			return nil, newUserError(fmt.Sprintf("invalid poll interval %q, must be a duration, e.g. 700ms", c.h.poll))
+		}
+	}
+
+	b, err := watcher.New(1*time.Second, pollInterval)
+	if err == nil || pollInterval > 0 {
+		return b, err
+	}
+
+	// E.g. the limit of open files or inotify instances is reached.
+	c.logger.Warnf("Failed to watch for file system events, polling for changes instead: %s", err)
+	return watcher.New(1*time.Second, defaultPollInterval)
+}
+
+// debugWatchf logs a watcher diagnostic if enabled with --debug-watch.
+func (c *commandeer) debugWatchf(format string, args ...interface{}) {
+	if c.h.debugWatch {
+		c.logger.Printf("watch: "+format, args...)
+	}
+}
+
+// How often to check for URL mounts to refresh while watching.
+const urlMountsRefreshInterval = 30 * time.Second
+
 // newWatcher creates a new watcher to watch filesystem events.
 func (c *commandeer) newWatcher(dirList ...string) (*watcher.Batcher, error) {
 	if runtime.GOOS == "darwin" {
@@ -830,14 +1059,26 @@ func (c *commandeer) newWatcher(dirList ...string) (*watcher.Batcher, error) {
 		return nil, err
 	}
 
-	watcher, err := watcher.New(1 * time.Second)
+	watcher, err := c.newBatcher()
 	if err != nil {
 		return nil, err
 	}
 
+	c.debugWatchf("using %s", watcher.Backend())
+
+	var failed int
+	addWatch := func(filename string) {
+		if err := watcher.Add(filename); err != nil {
+			failed++
+			c.debugWatchf("failed to watch %s: %s", filename, err)
+			return
+		}
+		c.debugWatchf("watching %s", filename)
+	}
+
 	for _, d := range dirList {
 		if d != "" {
-			_ = watcher.Add(d)
+			addWatch(d)
 		}
 	}
 
@@ -846,20 +1087,34 @@ func (c *commandeer) newWatcher(dirList ...string) (*watcher.Batcher, error) {
 
 	c.logger.Println("Watching for config changes in", strings.Join(c.configFiles, ", "))
 	for _, configFile := range c.configFiles {
-		watcher.Add(configFile)
+		addWatch(configFile)
 		configSet[configFile] = true
 	}
 
+	if failed > 0 && !c.h.debugWatch {
+		c.logger.Warnf("Failed to watch %d paths, changes in these will not trigger a rebuild. Run hugo server with --debug-watch for details.", failed)
+	}
+
+	// Refresh any URL mounts that are due. Changed files are picked up
+	// by the watcher.
+	refreshTicker := time.NewTicker(urlMountsRefreshInterval)
+
 	go func() {
 		for {
 			select {
+			case <-refreshTicker.C:
+				if client := c.hugo().ModulesClient; client != nil {
+					if err := client.RefreshURLMounts(context.Background()); err != nil {
+						c.logger.Warnln(err)
+					}
+				}
 			case evs := <-watcher.Events:
 				c.handleEvents(watcher, staticSyncer, evs, configSet)
 				if c.showErrorInBrowser && c.errCount() > 0 {
 					// Need to reload browser to show the error
 					livereload.ForceRefresh()
 				}
-			case err := <-watcher.Errors:
+			case err := <-watcher.Errors():
 				if err != nil {
 					c.logger.Errorln("Error while watching:", err)
 				}
@@ -885,6 +1140,7 @@ func (c *commandeer) printChangeDetected(typ string) {
 const (
 	configChangeConfig = "config file"
 	configChangeGoMod  = "go.mod file"
+	changeWatchPath    = "watched file"
 )
 
 func (c *commandeer) handleEvents(watcher *watcher.Batcher,
@@ -895,6 +1151,21 @@ func (c *commandeer) handleEvents(watcher *watcher.Batcher,
 		c.wasError = false
 	}()
 
+	if inv, ok := c.Fs.Source.(hugofs.Invalidater); ok {
+		// Make sure we see the changes in the file metadata cache.
+		filenames := make([]string, len(evs))
+		for i, ev := range evs {
+			filenames[i] = ev.Name
+		}
+		inv.Invalidate(filenames...)
+	}
+
+	if c.h.debugWatch {
+		for _, ev := range evs {
+			c.debugWatchf("received %s", ev)
+		}
+	}
+
 	var isHandled bool
 
 	for _, ev := range evs {
@@ -947,12 +1218,14 @@ func (c *commandeer) handleEvents(watcher *watcher.Batcher,
 	if c.paused {
 		// Wait for the server to get into a consistent state before
 		// we continue with processing.
+		c.debugWatchf("dropped %d events: paused until the config is fixed", len(evs))
 		return
 	}
 
 	if len(evs) > 50 {
 		// This is probably a mass edit of the content dir.
 		// Schedule a full rebuild for when it slows down.
+		c.debugWatchf("got %d events, scheduling a full rebuild", len(evs))
 		c.debounce(func() {
 			c.fullRebuild("")
 		})
@@ -963,10 +1236,12 @@ func (c *commandeer) handleEvents(watcher *watcher.Batcher,
 
 	staticEvents := []fsnotify.Event{}
 	dynamicEvents := []fsnotify.Event{}
+	var extraChanged bool
 
 	filtered := []fsnotify.Event{}
 	for _, ev := range evs {
 		if c.hugo().ShouldSkipFileChangeEvent(ev) {
+			c.debugWatchf("dropped %s: written by the build", ev)
 			continue
 		}
 		// Check the most specific first, i.e. files.
@@ -1014,13 +1289,20 @@ func (c *commandeer) handleEvents(watcher *watcher.Batcher,
 			strings.HasPrefix(baseName, ".#") || // emacs
 			strings.HasPrefix(baseName, "#") // emacs
 		if istemp {
+			c.debugWatchf("dropped %s: temporary file", ev)
 			continue
 		}
 		if c.hugo().Deps.SourceSpec.IgnoreFile(ev.Name) {
+			c.debugWatchf("dropped %s: matches ignoreFiles", ev)
+			continue
+		}
+		if c.isWatchIgnored(ev.Name, false) {
+			c.debugWatchf("dropped %s: matches watcher.ignore", ev)
 			continue
 		}
 		// Sometimes during rm -rf operations a '"": REMOVE' is triggered. Just ignore these
 		if ev.Name == "" {
+			c.debugWatchf("dropped %s: no filename", ev)
 			continue
 		}
 
@@ -1036,13 +1318,26 @@ func (c *commandeer) handleEvents(watcher *watcher.Batcher,
 		// could be aggregated with other important events, and we still want
 		// to rebuild on those
 		if ev.Op&(fsnotify.Chmod|fsnotify.Write|fsnotify.Create) == fsnotify.Chmod {
+			c.debugWatchf("dropped %s: only the permissions changed", ev)
 			continue
 		}
 
 		walkAdder := func(path string, f hugofs.FileMetaInfo, err error) error {
+			if c.isWatchIgnored(path, f.IsDir()) {
+				if f.IsDir() {
+					c.debugWatchf("not watching %s: matches watcher.ignore", path)
+					return filepath.SkipDir
+				}
+				return nil
+			}
 			if f.IsDir() {
+				if f.Meta().IsSymlink() && !c.hugo().BaseFs.FollowSymlinkedDirs(path) {
+					// Same rules as in the initial build.
+					return filepath.SkipDir
+				}
 				c.logger.Println("adding created directory to watchlist", path)
 				if err := watcher.Add(path); err != nil {
+					c.debugWatchf("failed to watch %s: %s", path, err)
 					return err
 				}
 			} else if !staticSyncer.isStatic(path) {
@@ -1062,6 +1357,11 @@ func (c *commandeer) handleEvents(watcher *watcher.Batcher,
 			}
 		}
 
+		if c.isExtraWatchFile(ev.Name) {
+			extraChanged = true
+			continue
+		}
+
 		if staticSyncer.isStatic(ev.Name) {
 			staticEvents = append(staticEvents, ev)
 		} else {
@@ -1069,6 +1369,12 @@ func (c *commandeer) handleEvents(watcher *watcher.Batcher,
 		}
 	}
 
+	if extraChanged {
+		// We don't know what depends on these, so rebuild everything.
+		c.fullRebuild(changeWatchPath)
+		return
+	}
+
 	if len(staticEvents) > 0 {
 		c.printChangeDetected("Static files")
 
@@ -1129,34 +1435,150 @@ func (c *commandeer) handleEvents(watcher *watcher.Batcher,
 				} else if len(changed) == 1 {
 					pathToRefresh := c.firstPathSpec().RelURL(helpers.ToSlashTrimLeading(changed[0]), false)
 					livereload.RefreshPath(pathToRefresh)
+				} else if assets := c.swappableAssets(changed); assets != nil {
+					livereload.SwapAssets(assets)
 				} else {
-					livereload.ForceRefresh()
+					c.refreshChanged(changed)
 				}
 			}
 
 			if len(partitionedEvents.ContentEvents) > 0 {
+				navigate := c.Cfg.GetBool("navigateToChanged") || c.Cfg.GetBool("navigateToChangedIfAffected")
 
-				navigate := c.Cfg.GetBool("navigateToChanged")
-				// We have fetched the same page above, but it may have
-				// changed.
-				var p page.Page
-
-				if navigate {
-					if onePageName != "" {
-						p = c.hugo().GetContentPage(onePageName)
-					}
-				}
-
-				if p != nil {
-					livereload.NavigateToPathForPort(p.RelPermalink(), p.Site().ServerPort())
-				} else {
+				if c.wasError {
 					livereload.ForceRefresh()
+				} else if navigate {
+					c.navigateToChanged(onePageName)
+				} else {
+					c.refreshChanged(c.changeDetector.changed())
 				}
 			}
 		}
 	}
 }
 
+// refreshChanged tells the browsers viewing the pages in changed to refresh.
+// If any other files changed, e.g. an image, all browsers are refreshed.
+func (c *commandeer) refreshChanged(changed []string) {
+	if c.changeDetector == nil {
+		livereload.ForceRefresh()
+		return
+	}
+
+	for _, filename := range changed {
+		if !pageFileSuffixes[filepath.Ext(filename)] {
+			livereload.ForceRefresh()
+			return
+		}
+	}
+
+	livereload.RefreshPages(c.pagePaths(changed))
+}
+
+// pageFileSuffixes are the suffixes of the files that are not embedded in,
+// or loaded by, other pages, for the purpose of live reloading.
+var pageFileSuffixes = map[string]bool{
+	".html": true,
+	".xml":  true,
+	".txt":  true,
+}
+
+// swappableAssets returns the stylesheets and scripts in changed, to be
+// swapped in the browser without a reload, if no other files changed. It
+// returns nil if not.
+func (c *commandeer) swappableAssets(changed []string) []livereload.Asset {
+	if c.changeDetector == nil {
+		return nil
+	}
+
+	prevs := make([]string, len(changed))
+	for i, filename := range changed {
+		switch filepath.Ext(filename) {
+		case ".css", ".js":
+		default:
+			// Any other change, e.g. to the pages, needs a reload.
+			return nil
+		}
+
+		prevs[i] = c.changeDetector.previous(filename)
+		if prevs[i] == "" {
+			// A new asset.
+			return nil
+		}
+	}
+
+	var assets []livereload.Asset
+	for i, filename := range changed {
+		asset := livereload.Asset{Path: c.publishURL(filename)}
+		if prevs[i] != filename {
+			asset.OldPath = c.publishURL(prevs[i])
+		}
+		assets = append(assets, asset)
+	}
+
+	return assets
+}
+
+// navigateToChanged tells the browser to navigate to the pages built from
+// filename, which may be a content file or a bundle resource. If filename
+// is not part of any page, e.g. a data file, and exactly one page changed,
+// the browser navigates to that page instead.
+func (c *commandeer) navigateToChanged(filename string) {
+	affected := c.pagePaths(c.changeDetector.changed())
+
+	nav := livereload.Navigation{
+		Affected:       affected,
+		OnlyIfAffected: c.Cfg.GetBool("navigateToChangedIfAffected"),
+	}
+
+	if filename != "" {
+		for _, p := range c.hugo().GetContentPages(filename) {
+			nav.Targets = append(nav.Targets, livereload.NavigationTarget{Path: p.RelPermalink(), Port: p.Site().ServerPort()})
+			nav.Affected = append(nav.Affected, p.RelPermalink())
+		}
+	}
+
+	if len(nav.Targets) == 0 && len(affected) == 1 {
+		nav.Targets = []livereload.NavigationTarget{{Path: affected[0]}}
+	}
+
+	livereload.Navigate(nav)
+}
+
+// pagePaths returns the URL paths of the HTML files in changed.
+func (c *commandeer) pagePaths(changed []string) []string {
+	var paths []string
+	for _, filename := range changed {
+		if !strings.HasSuffix(filename, ".html") {
+			continue
+		}
+		path := c.publishURL(filename)
+		if strings.HasSuffix(path, "/index.html") {
+			path = strings.TrimSuffix(path, "index.html")
+		}
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	return paths
+}
+
+// publishURL returns the relative URL of the published file filename.
+func (c *commandeer) publishURL(filename string) string {
+	publishDir := c.firstPathSpec().AbsPublishDir
+	filename = strings.TrimPrefix(filename, strings.TrimSuffix(publishDir, helpers.FilePathSeparator))
+	path := "/" + helpers.ToSlashTrimLeading(filename)
+	if c.hugo().IsMultihost() {
+		for _, s := range c.hugo().Sites {
+			if prefix := "/" + s.Language().Lang + "/"; strings.HasPrefix(path, prefix) {
+				path = "/" + strings.TrimPrefix(path, prefix)
+				break
+			}
+		}
+	}
+	return c.firstPathSpec().RelURL(path, false)
+}
+
 // dynamicEvents contains events that is considered dynamic, as in "not static".
 // Both of these categories will trigger a new build, but the asset events
 // does not fit into the "navigate to changed" logic.
@@ -1190,17 +1612,3 @@ func pickOneWriteOrCreatePath(events []fsnotify.Event) string {
 
 	return name
 }
-
-func formatByteCount(b uint64) string {
-	const unit = 1000
-	if b < unit {
-		return fmt.Sprintf("%d B", b)
-	}
-	div, exp := int64(unit), 0
-	for n := b / unit; n >= unit; n /= unit {
-		div *= unit
-		exp++
-	}
-	return fmt.Sprintf("%.1f %cB",
-		float64(b)/float64(div), "kMGTPE"[exp])
-}
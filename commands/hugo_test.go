@@ -14,9 +14,18 @@
 package commands
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
 	"testing"
 
 	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/notify"
 )
 
 // Issue #5662
@@ -45,3 +54,120 @@ contentDir = "thisdoesnotexist"
 	_, err = cmd.ExecuteC()
 	c.Assert(err, qt.IsNil)
 }
+
+func TestHugoStaticPublishModeHardlink(t *testing.T) {
+	c := qt.New(t)
+
+	hugoCmd := newCommandsBuilder().addAll().build()
+	cmd := hugoCmd.getCommand()
+
+	cfgStr := `
+
+baseURL = "https://example.org"
+title = "Hugo Commands"
+staticPublishMode = "hardlink"
+
+`
+	dir, clean, err := createSimpleTestSite(t, testSiteConfig{configTOML: cfgStr})
+	c.Assert(err, qt.IsNil)
+	defer clean()
+
+	writeFile(t, filepath.Join(dir, "static", "css", "style.css"), "body {}")
+
+	cmd.SetArgs([]string{"-s=" + dir})
+
+	_, err = cmd.ExecuteC()
+	c.Assert(err, qt.IsNil)
+
+	sfi, err := os.Stat(filepath.Join(dir, "static", "css", "style.css"))
+	c.Assert(err, qt.IsNil)
+	dfi, err := os.Stat(filepath.Join(dir, "public", "css", "style.css"))
+	c.Assert(err, qt.IsNil)
+	c.Assert(os.SameFile(sfi, dfi), qt.IsTrue)
+}
+
+func TestHugoNotifications(t *testing.T) {
+	c := qt.New(t)
+
+	summaries := make(chan notify.Summary, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var s notify.Summary
+		json.NewDecoder(r.Body).Decode(&s)
+		summaries <- s
+	}))
+	defer srv.Close()
+
+	hugoCmd := newCommandsBuilder().addAll().build()
+	cmd := hugoCmd.getCommand()
+
+	cfgStr := `
+
+baseURL = "https://example.org"
+title = "Hugo Commands"
+
+[[notifications.webhooks]]
+url = "` + srv.URL + `"
+events = ["build"]
+
+`
+	dir, clean, err := createSimpleTestSite(t, testSiteConfig{configTOML: cfgStr})
+	c.Assert(err, qt.IsNil)
+	defer clean()
+
+	cmd.SetArgs([]string{"-s=" + dir, "--quiet"})
+
+	_, err = cmd.ExecuteC()
+	c.Assert(err, qt.IsNil)
+
+	s := <-summaries
+	c.Assert(s.Event, qt.Equals, notify.EventBuild)
+	c.Assert(s.Success, qt.IsTrue)
+	c.Assert(s.Pages > 0, qt.IsTrue)
+	c.Assert(s.Errors, qt.HasLen, 0)
+}
+
+func TestFileChangeDetector(t *testing.T) {
+	c := qt.New(t)
+
+	fingerprint := func(s string) string {
+		return strings.Repeat(s, 64)
+	}
+
+	d := &fileChangeDetector{irrelevantRe: regexp.MustCompile(`\.map$`)}
+	d.PrepareNew()
+	d.OnFileClose("/index.html", "a")
+	d.OnFileClose("/css/main."+fingerprint("a")+".css", "a")
+	d.OnFileClose("/js/main.js", "a")
+	d.OnFileClose("/js/main.js.map", "a")
+
+	d.PrepareNew()
+	d.OnFileClose("/index.html", "b")
+	d.OnFileClose("/css/main."+fingerprint("b")+".css", "b")
+	d.OnFileClose("/js/main.js", "b")
+	d.OnFileClose("/js/main.js.map", "b")
+	d.OnFileClose("/js/new.js", "b")
+
+	changed := d.changed()
+	sort.Strings(changed)
+	c.Assert(changed, qt.DeepEquals, []string{"/css/main." + fingerprint("b") + ".css", "/index.html", "/js/main.js", "/js/new.js"})
+	c.Assert(d.previous("/css/main."+fingerprint("b")+".css"), qt.Equals, "/css/main."+fingerprint("a")+".css")
+	c.Assert(d.previous("/js/main.js"), qt.Equals, "/js/main.js")
+	c.Assert(d.previous("/js/new.js"), qt.Equals, "")
+}
+
+func TestSwappableAssets(t *testing.T) {
+	c := qt.New(t)
+
+	d := &fileChangeDetector{irrelevantRe: regexp.MustCompile(`\.map$`)}
+	d.PrepareNew()
+	d.OnFileClose("/index.html", "a")
+	d.OnFileClose("/css/main.css", "a")
+	d.PrepareNew()
+
+	cc := &commandeer{changeDetector: d}
+	// A page changed too, so it must be reloaded.
+	c.Assert(cc.swappableAssets([]string{"/css/main.css", "/index.html"}), qt.IsNil)
+	// A new stylesheet.
+	c.Assert(cc.swappableAssets([]string{"/css/new.css"}), qt.IsNil)
+	c.Assert((&commandeer{}).swappableAssets([]string{"/css/main.css"}), qt.IsNil)
+}
@@ -0,0 +1,427 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/gohugoio/hugo/hugofs"
+	"github.com/gohugoio/hugo/hugolib"
+	"github.com/gohugoio/hugo/langs/i18n"
+	"github.com/gohugoio/hugo/parser"
+	"github.com/gohugoio/hugo/parser/metadecoders"
+	"github.com/gohugoio/hugo/parser/pageparser"
+	"github.com/gohugoio/hugo/resources/page"
+	"github.com/pkg/errors"
+	"github.com/spf13/cast"
+	"github.com/spf13/cobra"
+	jww "github.com/spf13/jwalterweatherman"
+)
+
+var _ cmder = (*i18nCmd)(nil)
+
+type i18nCmd struct {
+	*baseBuilderCmd
+
+	write bool
+
+	format      string
+	targets     []string
+	output      string
+	frontMatter []string
+}
+
+func (ic *i18nCmd) buildSites() (*hugolib.HugoSites, error) {
+	c, err := initializeConfig(true, false, &ic.hugoBuilderCommon, ic, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	sites, err := hugolib.NewHugoSites(*c.DepsCfg)
+	if err != nil {
+		return nil, newSystemError("Error creating sites", err)
+	}
+
+	return sites, nil
+}
+
+func (b *commandsBuilder) newI18nCmd() *i18nCmd {
+	cc := &i18nCmd{}
+
+	cmd := &cobra.Command{
+		Use:   "i18n",
+		Short: "Work with the translation files",
+		Long: `Work with the translation files in the i18n folders of your project, themes and modules.
+
+I18n requires a subcommand, e.g. ` + "`hugo i18n sync`.",
+		RunE: nil,
+	}
+
+	syncCmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Report i18n keys missing from or unused in the translation files",
+		Long: `Scan the templates for i18n (or T) calls and report, per language, the keys
+missing from the translation files and the keys not used in any template.
+
+Only keys given as string literals are detected.
+
+With --write, stubs for the missing keys are added to the project's translation file
+for each language, using the text in the default content language if available.
+Without it, the command fails if any keys are missing.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sites, err := cc.buildSites()
+			if err != nil {
+				return err
+			}
+
+			var langs []string
+			for _, s := range sites.Sites {
+				langs = append(langs, s.Language().Lang)
+			}
+
+			results, err := i18n.Sync(sites.Deps, langs, cc.write)
+			if err != nil {
+				return newSystemError("Error syncing translations", err)
+			}
+
+			var missing int
+			for _, r := range results {
+				if len(r.Missing) > 0 {
+					jww.FEEDBACK.Printf("%s: missing %s\n", r.Lang, strings.Join(r.Missing, ", "))
+				}
+				if len(r.Unused) > 0 {
+					jww.FEEDBACK.Printf("%s: unused %s\n", r.Lang, strings.Join(r.Unused, ", "))
+				}
+				if r.Written != "" {
+					jww.FEEDBACK.Printf("%s: added %d key(s) to %s\n", r.Lang, len(r.Missing),
+						strings.TrimPrefix(r.Written, sites.WorkingDir+string(os.PathSeparator)))
+				}
+				missing += len(r.Missing)
+			}
+
+			if missing > 0 && !cc.write {
+				return newUserError(fmt.Sprintf("found %d missing translation(s)", missing))
+			}
+
+			return nil
+		},
+	}
+
+	syncCmd.Flags().BoolVar(&cc.write, "write", false, "add stubs for the missing keys to the translation files")
+
+	exportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the translations to XLIFF",
+		Long: `Export the i18n messages in the default content language, with any existing
+translations, to one XLIFF 2.0 document per target language.
+
+With --frontmatter, the given front matter fields of the content in the default
+content language are exported as well.
+
+The documents are written to <lang>.xlf in the --output directory, to be
+translated and imported back with ` + "`hugo i18n import`.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cc.format != "xliff" {
+				return newUserError(fmt.Sprintf("unsupported format %q, must be xliff", cc.format))
+			}
+
+			sites, err := cc.buildSites()
+			if err != nil {
+				return err
+			}
+			if len(cc.frontMatter) > 0 {
+				if err := sites.Build(hugolib.BuildCfg{SkipRender: true}); err != nil {
+					return newSystemError("Error Processing Source Content", err)
+				}
+			}
+
+			src := cc.defaultSite(sites)
+			targets := cc.targets
+			if len(targets) == 0 {
+				for _, s := range sites.Sites {
+					if s != src {
+						targets = append(targets, s.Language().Lang)
+					}
+				}
+			}
+
+			for _, target := range targets {
+				doc := i18n.NewXLIFFDocument(src.Language().Lang, target)
+				if err := i18n.ExportXLIFF(sites.Deps, doc); err != nil {
+					return newSystemError("Error exporting translations", err)
+				}
+				if err := cc.exportFrontMatter(src, doc); err != nil {
+					return newSystemError("Error exporting front matter", err)
+				}
+
+				var buf bytes.Buffer
+				if err := doc.Write(&buf); err != nil {
+					return err
+				}
+				filename := filepath.Join(cc.output, target+".xlf")
+				if err := helpers.WriteToDisk(filename, &buf, hugofs.Os); err != nil {
+					return errors.Wrapf(err, "failed to save file %q", filename)
+				}
+				jww.FEEDBACK.Printf("%s: exported to %s\n", target, filename)
+			}
+
+			return nil
+		},
+	}
+
+	exportCmd.Flags().StringVar(&cc.format, "format", "xliff", "the export format, currently only xliff")
+	exportCmd.Flags().StringSliceVar(&cc.targets, "target", nil, "the languages to export, defaults to all but the default content language")
+	exportCmd.Flags().StringVarP(&cc.output, "output", "o", ".", "the directory to write the XLIFF files to")
+	exportCmd.Flags().StringSliceVar(&cc.frontMatter, "frontmatter", nil, "front matter fields to export, e.g. title,description")
+
+	importCmd := &cobra.Command{
+		Use:   "import [path]...",
+		Short: "Import translations from XLIFF",
+		Long: `Import the translated XLIFF 2.0 documents created with ` + "`hugo i18n export`." + `
+
+The i18n messages are merged into the project's translation file for the
+target language. Front matter fields are written to the translated content
+files, which are created from the source content if they do not exist.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sites, err := cc.buildSites()
+			if err != nil {
+				return err
+			}
+			if err := sites.Build(hugolib.BuildCfg{SkipRender: true}); err != nil {
+				return newSystemError("Error Processing Source Content", err)
+			}
+
+			for _, filename := range args {
+				f, err := os.Open(filename)
+				if err != nil {
+					return err
+				}
+				doc, err := i18n.ReadXLIFF(f)
+				f.Close()
+				if err != nil {
+					return newUserError(fmt.Sprintf("%s: %s", filename, err))
+				}
+
+				written, err := i18n.ImportXLIFF(sites.Deps, doc)
+				if err != nil {
+					return newSystemError("Error importing translations", err)
+				}
+				contentWritten, err := cc.importFrontMatter(sites, doc)
+				if err != nil {
+					return newSystemError("Error importing front matter", err)
+				}
+
+				for _, w := range append(written, contentWritten...) {
+					jww.FEEDBACK.Printf("%s: updated %s\n", doc.TrgLang,
+						strings.TrimPrefix(w, sites.WorkingDir+string(os.PathSeparator)))
+				}
+			}
+
+			return nil
+		},
+	}
+
+	cmd.AddCommand(syncCmd, exportCmd, importCmd)
+
+	cc.baseBuilderCmd = b.newBuilderBasicCmd(cmd)
+
+	return cc
+}
+
+// xliffFileContent is the ID of the XLIFF file element holding the front
+// matter fields.
+const xliffFileContent = "content"
+
+func (ic *i18nCmd) defaultSite(sites *hugolib.HugoSites) *hugolib.Site {
+	defaultLang := sites.Deps.Cfg.GetString("defaultContentLanguage")
+	for _, s := range sites.Sites {
+		if s.Language().Lang == defaultLang {
+			return s
+		}
+	}
+	return sites.Sites[0]
+}
+
+// exportFrontMatter adds units for the front matter fields of the regular
+// pages in src to doc, keyed by "path#field".
+func (ic *i18nCmd) exportFrontMatter(src *hugolib.Site, doc *i18n.XLIFFDocument) error {
+	if len(ic.frontMatter) == 0 {
+		return nil
+	}
+
+	for _, p := range src.RegularPages() {
+		if p.File().IsZero() {
+			continue
+		}
+
+		var tp page.Page
+		for _, t := range p.Translations() {
+			if t.Language().Lang == doc.TrgLang {
+				tp = t
+				break
+			}
+		}
+
+		for _, field := range ic.frontMatter {
+			source := cast.ToString(p.Params()[strings.ToLower(field)])
+			if source == "" {
+				continue
+			}
+			var target string
+			if tp != nil {
+				target = cast.ToString(tp.Params()[strings.ToLower(field)])
+			}
+			doc.AddUnit(xliffFileContent, filepath.ToSlash(p.File().Path())+"#"+field, i18n.XLIFFSegment{Source: source, Target: target})
+		}
+	}
+
+	return nil
+}
+
+// importFrontMatter writes the translated front matter fields in doc to the
+// content files of the target language, creating them from the source
+// content if needed. It returns the files written.
+func (ic *i18nCmd) importFrontMatter(sites *hugolib.HugoSites, doc *i18n.XLIFFDocument) ([]string, error) {
+	f := doc.File(xliffFileContent)
+	if f == nil {
+		return nil, nil
+	}
+
+	var src, trg *hugolib.Site
+	for _, s := range sites.Sites {
+		switch s.Language().Lang {
+		case doc.SrcLang:
+			src = s
+		case doc.TrgLang:
+			trg = s
+		}
+	}
+	if src == nil || trg == nil {
+		return nil, errors.Errorf("no site for %q or %q", doc.SrcLang, doc.TrgLang)
+	}
+
+	fields := make(map[string]map[string]string)
+	var paths []string
+	for _, u := range f.Units {
+		key := u.Key()
+		i := strings.LastIndex(key, "#")
+		if i == -1 || len(u.Segments) == 0 || u.Segments[0].Target == "" {
+			continue
+		}
+		path, field := key[:i], key[i+1:]
+		if fields[path] == nil {
+			fields[path] = make(map[string]string)
+			paths = append(paths, path)
+		}
+		fields[path][field] = u.Segments[0].Target
+	}
+
+	var written []string
+	for _, path := range paths {
+		var p page.Page
+		for _, pp := range src.RegularPages() {
+			if !pp.File().IsZero() && filepath.ToSlash(pp.File().Path()) == path {
+				p = pp
+				break
+			}
+		}
+		if p == nil {
+			sites.Log.Warnf("i18n import: no content found for %q", path)
+			continue
+		}
+
+		filename, from := "", p.File().Filename()
+		for _, t := range p.Translations() {
+			if t.Language().Lang == doc.TrgLang {
+				filename = t.File().Filename()
+				from = filename
+				break
+			}
+		}
+		if filename == "" {
+			filename = translationFilename(sites.WorkingDir, p, src, trg)
+		}
+
+		if err := writeFrontMatterFields(from, filename, fields[path]); err != nil {
+			return nil, err
+		}
+		written = append(written, filename)
+	}
+
+	return written, nil
+}
+
+// translationFilename returns the filename for a new translation of p into
+// the language of trg.
+func translationFilename(workingDir string, p page.Page, src, trg *hugolib.Site) string {
+	f := p.File()
+	srcDir, trgDir := src.Language().ContentDir, trg.Language().ContentDir
+	if srcDir != trgDir {
+		if !filepath.IsAbs(trgDir) {
+			trgDir = filepath.Join(workingDir, trgDir)
+		}
+		return filepath.Join(trgDir, f.Path())
+	}
+	return filepath.Join(filepath.Dir(f.Filename()), f.TranslationBaseName()+"."+trg.Language().Lang+"."+f.Ext())
+}
+
+// writeFrontMatterFields sets fields in the front matter of the content file
+// from and writes the result to filename.
+func writeFrontMatterFields(from, filename string, fields map[string]string) error {
+	file, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	pf, err := pageparser.ParseFrontMatterAndContent(file)
+	file.Close()
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse %q", from)
+	}
+
+	if pf.FrontMatter == nil {
+		pf.FrontMatter = make(map[string]interface{})
+	}
+	if pf.FrontMatterFormat == "" {
+		pf.FrontMatterFormat = metadecoders.YAML
+	}
+
+	for field, value := range fields {
+		// Keep the case of any existing field.
+		for k := range pf.FrontMatter {
+			if strings.EqualFold(k, field) {
+				field = k
+				break
+			}
+		}
+		pf.FrontMatter[field] = value
+	}
+
+	var buf bytes.Buffer
+	if err := parser.InterfaceToFrontMatter(pf.FrontMatter, pf.FrontMatterFormat, &buf); err != nil {
+		return err
+	}
+	buf.Write(pf.Content)
+
+	if err := helpers.WriteToDisk(filename, &buf, hugofs.Os); err != nil {
+		return errors.Wrapf(err, "failed to save file %q", filename)
+	}
+
+	return nil
+}
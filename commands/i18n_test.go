@@ -0,0 +1,152 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestI18nSync(t *testing.T) {
+	c := qt.New(t)
+	dir, clean, err := createSimpleTestSite(t, testSiteConfig{configTOML: `
+baseURL = "https://example.org"
+defaultContentLanguage = "en"
+[languages]
+[languages.en]
+weight = 1
+[languages.nn]
+weight = 2
+`})
+	defer clean()
+	c.Assert(err, qt.IsNil)
+
+	defer func() {
+		os.RemoveAll(dir)
+	}()
+
+	writeFile(t, filepath.Join(dir, "layouts", "index.html"), "{{ i18n \"hello\" }}|{{ T `bye` . }}|{{ i18n .Title }}")
+	writeFile(t, filepath.Join(dir, "i18n", "en.toml"), `
+hello = "Hello"
+bye = "Bye"
+unused = "Unused"
+`)
+	writeFile(t, filepath.Join(dir, "i18n", "nn.yaml"), `
+hello: "Hei"
+`)
+
+	sync := func(args ...string) error {
+		cmd := newCommandsBuilder().addAll().build().getCommand()
+		cmd.SetArgs(append([]string{"-s=" + dir, "i18n", "sync"}, args...))
+		_, err := cmd.ExecuteC()
+		return err
+	}
+
+	err = sync()
+	c.Assert(err, qt.Not(qt.IsNil))
+	c.Assert(err.Error(), qt.Contains, "found 1 missing translation(s)")
+
+	c.Assert(sync("--write"), qt.IsNil)
+	b, err := ioutil.ReadFile(filepath.Join(dir, "i18n", "nn.yaml"))
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, `
+hello: "Hei"
+"bye":
+  other: "Bye"
+`)
+
+	c.Assert(sync(), qt.IsNil)
+}
+
+func TestI18nXLIFF(t *testing.T) {
+	c := qt.New(t)
+	dir, clean, err := createSimpleTestSite(t, testSiteConfig{configTOML: `
+baseURL = "https://example.org"
+defaultContentLanguage = "en"
+[languages]
+[languages.en]
+weight = 1
+[languages.nn]
+weight = 2
+`})
+	defer clean()
+	c.Assert(err, qt.IsNil)
+
+	defer func() {
+		os.RemoveAll(dir)
+	}()
+
+	writeFile(t, filepath.Join(dir, "i18n", "en.toml"), `
+hello = "Hello"
+[items]
+one = "One item"
+other = "{{ .Count }} items"
+`)
+	writeFile(t, filepath.Join(dir, "i18n", "nn.toml"), `
+hello = "Hei"
+`)
+	writeFile(t, filepath.Join(dir, "content", "about.md"), `---
+title: "About"
+---
+About us.
+`)
+
+	run := func(args ...string) error {
+		cmd := newCommandsBuilder().addAll().build().getCommand()
+		cmd.SetArgs(append([]string{"-s=" + dir, "i18n"}, args...))
+		_, err := cmd.ExecuteC()
+		return err
+	}
+
+	out := filepath.Join(dir, "xliff")
+	c.Assert(run("export", "-o", out, "--frontmatter", "title"), qt.IsNil)
+
+	xlf := filepath.Join(out, "nn.xlf")
+	b, err := ioutil.ReadFile(xlf)
+	c.Assert(err, qt.IsNil)
+	content := string(b)
+	c.Assert(content, qt.Contains, `<xliff xmlns="urn:oasis:names:tc:xliff:document:2.0" version="2.0" srcLang="en" trgLang="nn">`)
+	c.Assert(content, qt.Contains, "<source>Hello</source>\n        <target>Hei</target>")
+	c.Assert(content, qt.Contains, `<segment id="one">`)
+	c.Assert(content, qt.Contains, `<unit id="about.md_title" name="about.md#title">`)
+
+	content = strings.Replace(content, "<source>About</source>", "<source>About</source><target>Om oss</target>", 1)
+	content = strings.Replace(content, "<source>One item</source>", "<source>One item</source><target>Ein ting</target>", 1)
+	content = strings.Replace(content, "<source>{{ .Count }} items</source>", "<source>{{ .Count }} items</source><target>{{ .Count }} ting</target>", 1)
+	writeFile(t, xlf, content)
+
+	c.Assert(run("import", xlf), qt.IsNil)
+
+	b, err = ioutil.ReadFile(filepath.Join(dir, "i18n", "nn.toml"))
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, `hello = "Hei"
+
+[items]
+  one = "Ein ting"
+  other = "{{ .Count }} ting"
+`)
+
+	b, err = ioutil.ReadFile(filepath.Join(dir, "content", "about.nn.md"))
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, `---
+title: Om oss
+---
+About us.
+`)
+}
@@ -0,0 +1,109 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gohugoio/hugo/hugolib"
+	"github.com/spf13/cobra"
+	jww "github.com/spf13/jwalterweatherman"
+)
+
+var _ cmder = (*lintCmd)(nil)
+
+type lintCmd struct {
+	*baseBuilderCmd
+}
+
+func (lc *lintCmd) buildSites(config map[string]interface{}) (*hugolib.HugoSites, error) {
+	cfgInit := func(c *commandeer) error {
+		for key, value := range config {
+			c.Set(key, value)
+		}
+		return nil
+	}
+
+	c, err := initializeConfig(true, false, &lc.hugoBuilderCommon, lc, cfgInit)
+	if err != nil {
+		return nil, err
+	}
+
+	sites, err := hugolib.NewHugoSites(*c.DepsCfg)
+	if err != nil {
+		return nil, newSystemError("Error creating sites", err)
+	}
+
+	if err := sites.Build(hugolib.BuildCfg{SkipRender: true}); err != nil {
+		return nil, newSystemError("Error Processing Source Content", err)
+	}
+
+	return sites, nil
+}
+
+func (b *commandsBuilder) newLintCmd() *lintCmd {
+	cc := &lintCmd{}
+
+	cmd := &cobra.Command{
+		Use:   "lint",
+		Short: "Check your content for common problems",
+		Long: `Check your content for common problems.
+
+Lint requires a subcommand, e.g. ` + "`hugo lint content`.",
+		RunE: nil,
+	}
+
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "content",
+			Short: "List pages missing front matter fields defined in their archetype",
+			Long: `List all the pages in your content directory, including drafts, future and expired pages,
+missing front matter fields defined in the archetype used when creating them with hugo new.
+
+The command fails if any are found.`,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				sites, err := cc.buildSites(map[string]interface{}{
+					"buildExpired":   true,
+					"buildDrafts":    true,
+					"buildFuture":    true,
+					"lintArchetypes": true,
+				})
+				if err != nil {
+					return newSystemError("Error building sites", err)
+				}
+
+				results := sites.LintArchetypes()
+				for _, r := range results {
+					jww.FEEDBACK.Printf("%s: missing %s (archetype %s)\n",
+						strings.TrimPrefix(r.Filename, sites.WorkingDir+string(os.PathSeparator)),
+						strings.Join(r.Missing, ", "),
+						r.Archetype,
+					)
+				}
+
+				if len(results) > 0 {
+					return newUserError(fmt.Sprintf("found %d page(s) missing front matter fields", len(results)))
+				}
+
+				return nil
+			},
+		},
+	)
+
+	cc.baseBuilderCmd = b.newBuilderBasicCmd(cmd)
+
+	return cc
+}
@@ -15,12 +15,16 @@ package commands
 
 import (
 	"encoding/csv"
+	"encoding/json"
+	"fmt"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gohugoio/hugo/hugolib"
+	"github.com/gohugoio/hugo/resources/page"
 	"github.com/gohugoio/hugo/resources/resource"
 	"github.com/spf13/cobra"
 	jww "github.com/spf13/jwalterweatherman"
@@ -145,6 +149,36 @@ List requires a subcommand, e.g. ` + "`hugo list drafts`.",
 				return nil
 			},
 		},
+		cc.newListScheduledCmd(),
+		&cobra.Command{
+			Use:   "orphans",
+			Short: "List all pages no other page links to",
+			Long: `List all of the regular pages in your content directory that no other page
+links to, found in the rendered content of the pages.`,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				sites, err := cc.buildSites(nil)
+				if err != nil {
+					return newSystemError("Error building sites", err)
+				}
+
+				writer := csv.NewWriter(os.Stdout)
+				defer writer.Flush()
+
+				for _, s := range sites.Sites {
+					for _, p := range s.Info.LinkGraph().Orphans() {
+						var path string
+						if !p.File().IsZero() {
+							path = strings.TrimPrefix(p.File().Filename(), sites.WorkingDir+string(os.PathSeparator))
+						}
+						if err := writer.Write([]string{path, p.Title(), p.Permalink()}); err != nil {
+							return newSystemError("Error writing orphans to stdout", err)
+						}
+					}
+				}
+
+				return nil
+			},
+		},
 		&cobra.Command{
 			Use:   "all",
 			Short: "List all posts",
@@ -200,3 +234,101 @@ List requires a subcommand, e.g. ` + "`hugo list drafts`.",
 
 	return cc
 }
+
+// scheduledPage is a page that will be published or expire, as listed by
+// "hugo list scheduled".
+type scheduledPage struct {
+	Path      string    `json:"path"`
+	Title     string    `json:"title"`
+	Lang      string    `json:"lang"`
+	Permalink string    `json:"permalink"`
+	Event     string    `json:"event"`
+	Date      time.Time `json:"date"`
+}
+
+func (lc *listCmd) newListScheduledCmd() *cobra.Command {
+	var within string
+
+	cmd := &cobra.Command{
+		Use:   "scheduled",
+		Short: "List all posts that will be published or expire",
+		Long: `List all of the posts in your content directory that will be published
+(a publish date in the future) or expire (an expiry date in the future) as JSON,
+ordered by the date of the transition.
+
+Use --within to only list the transitions in the given period from now,
+e.g. --within 7d or --within 12h.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var until time.Time
+			now := time.Now()
+			if within != "" {
+				d, err := parseDays(within)
+				if err != nil {
+					return newUserError(fmt.Sprintf("invalid --within duration %q", within))
+				}
+				until = now.Add(d)
+			}
+
+			sites, err := lc.buildSites(map[string]interface{}{
+				"buildExpired": true,
+				"buildFuture":  true,
+			})
+			if err != nil {
+				return newSystemError("Error building sites", err)
+			}
+
+			scheduled := []scheduledPage{}
+			add := func(p page.Page, event string, date time.Time) {
+				if date.IsZero() || !date.After(now) || (!until.IsZero() && date.After(until)) {
+					return
+				}
+				var path string
+				if !p.File().IsZero() {
+					path = strings.TrimPrefix(p.File().Filename(), sites.WorkingDir+string(os.PathSeparator))
+				}
+				scheduled = append(scheduled, scheduledPage{
+					Path:      path,
+					Title:     p.Title(),
+					Lang:      p.Language().Lang,
+					Permalink: p.Permalink(),
+					Event:     event,
+					Date:      date,
+				})
+			}
+
+			for _, p := range sites.Pages() {
+				add(p, "publish", p.PublishDate())
+				add(p, "expire", p.ExpiryDate())
+			}
+
+			sort.SliceStable(scheduled, func(i, j int) bool {
+				return scheduled[i].Date.Before(scheduled[j].Date)
+			})
+
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(scheduled); err != nil {
+				return newSystemError("Error writing scheduled posts to stdout", err)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&within, "within", "", "only list the transitions within this duration from now, e.g. 7d or 12h")
+
+	return cmd
+}
+
+// parseDays is like time.ParseDuration, but also accepts a number of days,
+// e.g. "7d".
+func parseDays(s string) (time.Duration, error) {
+	if strings.HasSuffix(s, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(s, "d"))
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
@@ -3,11 +3,14 @@ package commands
 import (
 	"bytes"
 	"encoding/csv"
+	"encoding/json"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	qt "github.com/frankban/quicktest"
 )
@@ -69,3 +72,76 @@ func TestListAll(t *testing.T) {
 		"false", "https://example.org/p1/",
 	})
 }
+
+func TestListScheduled(t *testing.T) {
+	c := qt.New(t)
+	dir, clean, err := createSimpleTestSite(t, testSiteConfig{})
+	defer clean()
+
+	c.Assert(err, qt.IsNil)
+
+	now := time.Now()
+	writeFile(t, filepath.Join(dir, "content", "future.md"), fmt.Sprintf("---\ntitle: Future\npublishDate: %s\n---\n", now.Add(48*time.Hour).Format(time.RFC3339)))
+	writeFile(t, filepath.Join(dir, "content", "later.md"), fmt.Sprintf("---\ntitle: Later\npublishDate: %s\n---\n", now.Add(30*24*time.Hour).Format(time.RFC3339)))
+	writeFile(t, filepath.Join(dir, "content", "expiring.md"), fmt.Sprintf("---\ntitle: Expiring\nexpiryDate: %s\n---\n", now.Add(time.Hour).Format(time.RFC3339)))
+	writeFile(t, filepath.Join(dir, "content", "expired.md"), fmt.Sprintf("---\ntitle: Expired\nexpiryDate: %s\n---\n", now.Add(-time.Hour).Format(time.RFC3339)))
+
+	list := func(args ...string) []scheduledPage {
+		hugoCmd := newCommandsBuilder().addAll().build()
+		cmd := hugoCmd.getCommand()
+		cmd.SetArgs(append([]string{"-s=" + dir, "list", "scheduled"}, args...))
+
+		out, err := captureStdout(func() error {
+			_, err := cmd.ExecuteC()
+			return err
+		})
+		c.Assert(err, qt.IsNil)
+
+		var scheduled []scheduledPage
+		c.Assert(json.Unmarshal([]byte(out), &scheduled), qt.IsNil)
+		return scheduled
+	}
+
+	scheduled := list()
+	c.Assert(scheduled, qt.HasLen, 3)
+	c.Assert(scheduled[0].Title, qt.Equals, "Expiring")
+	c.Assert(scheduled[0].Event, qt.Equals, "expire")
+	c.Assert(scheduled[0].Path, qt.Equals, filepath.Join("content", "expiring.md"))
+	c.Assert(scheduled[1].Title, qt.Equals, "Future")
+	c.Assert(scheduled[1].Event, qt.Equals, "publish")
+	c.Assert(scheduled[1].Permalink, qt.Equals, "https://example.org/future/")
+	c.Assert(scheduled[2].Title, qt.Equals, "Later")
+
+	scheduled = list("--within", "7d")
+	c.Assert(scheduled, qt.HasLen, 2)
+	c.Assert(scheduled[1].Title, qt.Equals, "Future")
+
+	c.Assert(list("--within", "30m"), qt.HasLen, 0)
+}
+
+func TestListOrphans(t *testing.T) {
+	c := qt.New(t)
+	dir, clean, err := createSimpleTestSite(t, testSiteConfig{})
+	defer clean()
+
+	c.Assert(err, qt.IsNil)
+
+	writeFile(t, filepath.Join(dir, "content", "p2.md"), "---\ntitle: P2\n---\n[P3](/p3/)")
+	writeFile(t, filepath.Join(dir, "content", "p3.md"), "---\ntitle: P3\n---\n[P2](../p2/)")
+
+	hugoCmd := newCommandsBuilder().addAll().build()
+	cmd := hugoCmd.getCommand()
+	cmd.SetArgs([]string{"-s=" + dir, "list", "orphans"})
+
+	out, err := captureStdout(func() error {
+		_, err := cmd.ExecuteC()
+		return err
+	})
+	c.Assert(err, qt.IsNil)
+
+	records, err := csv.NewReader(strings.NewReader(out)).ReadAll()
+	c.Assert(err, qt.IsNil)
+	c.Assert(records, qt.DeepEquals, [][]string{
+		{filepath.Join("content", "p1.md"), "P1", "https://example.org/p1/"},
+	})
+}
@@ -52,6 +52,32 @@ func (c *modCmd) newVerifyCmd() *cobra.Command {
 	return verifyCmd
 }
 
+func (c *modCmd) newGraphCmd() *cobra.Command {
+	var format string
+
+	graphCmd := &cobra.Command{
+		Use:   "graph",
+		Short: "Print a module dependency graph.",
+		Long: `Print a module dependency graph with information about module status (disabled, vendored).
+Note that for vendored modules, that is the version listed and not the one from go.mod.
+
+Use the --format flag to print the graph in DOT (Graphviz) or JSON format; both include
+version, replacement and mount information for every module, e.g.:
+
+    hugo mod graph --format dot | dot -Tsvg > modules.svg
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.withModsClient(true, func(c *modules.Client) error {
+				return c.GraphFormat(os.Stdout, format)
+			})
+		},
+	}
+
+	graphCmd.Flags().StringVarP(&format, "format", "", modules.GraphFormatText, `output format, one of "text", "dot" or "json"`)
+
+	return graphCmd
+}
+
 var moduleNotFoundRe = regexp.MustCompile("module.*not found")
 
 func (c *modCmd) newCleanCmd() *cobra.Command {
@@ -201,15 +227,20 @@ Run "go help get" for more information. All flags available for "go get" is also
 				})
 			},
 		},
+		c.newGraphCmd(),
 		&cobra.Command{
-			Use:   "graph",
-			Short: "Print a module dependency graph.",
-			Long: `Print a module dependency graph with information about module status (disabled, vendored).
-Note that for vendored modules, that is the version listed and not the one from go.mod.
+			Use:   "licenses",
+			Short: "Print a license report for the module dependency graph.",
+			Long: `Print a license report for all the modules in the dependency graph.
+
+Hugo looks for a license file (e.g. LICENSE, LICENSE.md, COPYING) in the root of every module
+and makes a best effort guess of its SPDX identifier. Modules where the license could not be
+determined are marked as UNKNOWN. Any license declared in the module's configuration
+(e.g. "license" in theme.toml) is listed as well.
 `,
 			RunE: func(cmd *cobra.Command, args []string) error {
 				return c.withModsClient(true, func(c *modules.Client) error {
-					return c.Graph(os.Stdout)
+					return c.Licenses(os.Stdout)
 				})
 			},
 		},
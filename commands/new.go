@@ -32,6 +32,7 @@ var _ cmder = (*newCmd)(nil)
 type newCmd struct {
 	contentEditor string
 	contentType   string
+	fromURL       string
 
 	*baseBuilderCmd
 }
@@ -50,14 +51,35 @@ If archetypes are provided in your theme or site, they will be used.
 Ensure you run this within the root directory of your site.`,
 	}
 
-	cc := &newCmd{baseBuilderCmd: b.newBuilderCmd(cmd)}
+	cc := b.newNewContentCmd(cmd)
 
-	cmd.Flags().StringVarP(&cc.contentType, "kind", "k", "", "content type to create")
-	cmd.Flags().StringVar(&cc.contentEditor, "editor", "", "edit new content with this editor, if provided")
+	cmd.AddCommand(b.newNewContentCmd(&cobra.Command{
+		Use:   "content [path]",
+		Short: "Create new content for your site",
+		Long: `Create a new content file and automatically set the date and title.
+This is the same as ` + "`hugo new [path]`" + `.
 
+With ` + "`--from-url`" + `, the content is imported from a web page: the main
+content of the page is converted to Markdown and the images in it are
+downloaded into a new page bundle, with the title, date, description and
+canonical URL of the page set in front matter. The path defaults to the
+page title.
+
+    hugo new content posts/my-post --from-url https://example.org/my-post/`,
+	}).getCommand())
 	cmd.AddCommand(b.newNewSiteCmd().getCommand())
 	cmd.AddCommand(b.newNewThemeCmd().getCommand())
 
+	return cc
+}
+
+func (b *commandsBuilder) newNewContentCmd(cmd *cobra.Command) *newCmd {
+	cc := &newCmd{baseBuilderCmd: b.newBuilderCmd(cmd)}
+
+	cmd.Flags().StringVarP(&cc.contentType, "kind", "k", "", "content type to create")
+	cmd.Flags().StringVar(&cc.contentEditor, "editor", "", "edit new content with this editor, if provided")
+	cmd.Flags().StringVar(&cc.fromURL, "from-url", "", "import the content from the web page at this URL into a page bundle")
+
 	cmd.RunE = cc.newContent
 
 	return cc
@@ -76,6 +98,14 @@ func (n *newCmd) newContent(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if n.fromURL != "" {
+		var createPath string
+		if len(args) > 0 {
+			createPath, _ = newContentPathSection(c.hugo(), args[0])
+		}
+		return create.NewContentFromURL(c.hugo(), createPath, n.fromURL)
+	}
+
 	if len(args) < 1 {
 		return newUserError("path needs to be provided")
 	}
@@ -118,7 +148,13 @@ func newContentPathSection(h *hugolib.HugoSites, path string) (string, string) {
 
 	if h != nil {
 		for _, dir := range h.BaseFs.Content.Dirs {
-			createpath = strings.TrimPrefix(createpath, dir.Meta().Filename())
+			meta := dir.Meta()
+			if mountRoot := meta.MountRoot(); mountRoot != "" && strings.HasPrefix(createpath, meta.Filename()+helpers.FilePathSeparator) {
+				// A file inside a mount below /content, e.g. content/docs.
+				createpath = filepath.Join(mountRoot, strings.TrimPrefix(createpath, meta.Filename()))
+				break
+			}
+			createpath = strings.TrimPrefix(createpath, meta.Filename())
 		}
 	}
 
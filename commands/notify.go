@@ -0,0 +1,59 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"time"
+
+	"github.com/gohugoio/hugo/common/hugo"
+	"github.com/gohugoio/hugo/config/security"
+	"github.com/gohugoio/hugo/notify"
+)
+
+// notify sends a summary of the event started at start to the webhooks in
+// the notifications config. Any failure is logged as a warning.
+func (c *commandeer) notify(event string, start time.Time, err error, addSummary func(s *notify.Summary)) {
+	nc, nerr := notify.DecodeConfig(c.Cfg)
+	if nerr != nil {
+		c.logger.Warnln(nerr)
+		return
+	}
+	if len(nc.Webhooks) == 0 {
+		return
+	}
+
+	sc, ok := c.Cfg.Get("securityConfig").(security.Config)
+	if !ok {
+		sc = security.DefaultConfig
+	}
+
+	s := notify.Summary{
+		Event:       event,
+		Success:     err == nil,
+		Duration:    int64(time.Since(start) / time.Millisecond),
+		HugoVersion: hugo.CurrentVersion.String(),
+		Date:        start,
+	}
+	if err != nil {
+		s.Errors = []string{err.Error()}
+	}
+	if addSummary != nil {
+		addSummary(&s)
+	}
+
+	if nerr := notify.New(nc, sc, c.logger).Notify(context.Background(), s); nerr != nil {
+		c.logger.Warnln(nerr)
+	}
+}
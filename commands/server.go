@@ -15,6 +15,7 @@ package commands
 
 import (
 	"bytes"
+	"crypto/tls"
 	"fmt"
 	"io"
 	"net"
@@ -37,8 +38,11 @@ import (
 
 	"github.com/gohugoio/hugo/livereload"
 
+	"github.com/gohugoio/hugo/common/loggers"
 	"github.com/gohugoio/hugo/config"
+	"github.com/gohugoio/hugo/config/security"
 	"github.com/gohugoio/hugo/helpers"
+	"github.com/gohugoio/hugo/tunnel"
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
 	jww "github.com/spf13/jwalterweatherman"
@@ -48,18 +52,27 @@ type serverCmd struct {
 	// Can be used to stop the server. Useful in tests
 	stop <-chan bool
 
-	disableLiveReload bool
-	navigateToChanged bool
-	renderToDisk      bool
-	serverAppend      bool
-	serverInterface   string
-	serverPort        int
-	liveReloadPort    int
-	serverWatch       bool
-	noHTTPCache       bool
+	disableLiveReload  bool
+	navigateToChanged  bool
+	navigateIfAffected bool
+	renderToDisk       bool
+	serverAppend       bool
+	serverInterface    string
+	serverPort         int
+	liveReloadPort     int
+	serverWatch        bool
+	noHTTPCache        bool
+	http2              bool
+	compress           bool
+	api                bool
+	tunnelProvider     string
+
+	// The running tunnel, if the server is shared with --tunnel.
+	tunnel *tunnel.Tunnel
 
 	disableFastRender   bool
 	disableBrowserError bool
+	editorURL           string
 
 	*baseBuilderCmd
 }
@@ -94,13 +107,22 @@ of a second, you will be able to save and see your changes nearly instantly.`,
 	cc.cmd.Flags().IntVar(&cc.liveReloadPort, "liveReloadPort", -1, "port for live reloading (i.e. 443 in HTTPS proxy situations)")
 	cc.cmd.Flags().StringVarP(&cc.serverInterface, "bind", "", "127.0.0.1", "interface to which the server will bind")
 	cc.cmd.Flags().BoolVarP(&cc.serverWatch, "watch", "w", true, "watch filesystem for changes and recreate as needed")
+	cc.cmd.Flags().StringVar(&cc.poll, "poll", "", "set this to a poll interval, e.g --poll 700ms, to poll for file system changes when watching; the interval backs off when nothing changes")
+	cc.cmd.Flags().BoolVar(&cc.debugWatch, "debug-watch", false, "log the active watcher backend, the watched paths and the dropped file system events")
 	cc.cmd.Flags().BoolVar(&cc.noHTTPCache, "noHTTPCache", false, "prevent HTTP caching")
+	cc.cmd.Flags().BoolVar(&cc.http2, "http2", false, "serve over HTTPS with HTTP/2, using a self-signed certificate unless server.tlsCertFile and server.tlsKeyFile are set")
+	cc.cmd.Flags().BoolVar(&cc.compress, "compress", false, "compress the responses with gzip and serve precompressed .br and .gz files")
+	cc.cmd.Flags().BoolVar(&cc.api, "api", false, "enable the JSON API returning the rendered pages at /__hugo/api/pages/")
+	cc.cmd.Flags().StringVar(&cc.tunnelProvider, "tunnel", "", "share the server on a public URL through a tunnel, with cloudflared, ngrok, localtunnel or command; the default is the provider in server.tunnel or cloudflared")
+	cc.cmd.Flags().Lookup("tunnel").NoOptDefVal = tunnelDefaultProvider
 	cc.cmd.Flags().BoolVarP(&cc.serverAppend, "appendPort", "", true, "append port to baseURL")
 	cc.cmd.Flags().BoolVar(&cc.disableLiveReload, "disableLiveReload", false, "watch without enabling live browser reload on rebuild")
 	cc.cmd.Flags().BoolVar(&cc.navigateToChanged, "navigateToChanged", false, "navigate to changed content file on live browser reload")
+	cc.cmd.Flags().BoolVar(&cc.navigateIfAffected, "navigateToChangedIfAffected", false, "like navigateToChanged, but only navigate away from the current page if it was affected by the change")
 	cc.cmd.Flags().BoolVar(&cc.renderToDisk, "renderToDisk", false, "render to Destination path (default is render to memory & serve from there)")
 	cc.cmd.Flags().BoolVar(&cc.disableFastRender, "disableFastRender", false, "enables full re-renders on changes")
 	cc.cmd.Flags().BoolVar(&cc.disableBrowserError, "disableBrowserError", false, "do not show build errors in the browser")
+	cc.cmd.Flags().StringVar(&cc.editorURL, "editorURL", "", "open files in this editor from the browser error page, vscode, sublime, idea, textmate or a URL pattern with {file}, {line} and {column}")
 
 	cc.cmd.Flags().String("memstats", "", "log memory usage to this file")
 	cc.cmd.Flags().String("meminterval", "100ms", "interval to poll memory usage (requires --memstats), valid time units are \"ns\", \"us\" (or \"µs\"), \"ms\", \"s\", \"m\", \"h\".")
@@ -144,6 +166,9 @@ func (sc *serverCmd) server(cmd *cobra.Command, args []string) error {
 		if cmd.Flags().Changed("navigateToChanged") {
 			c.Set("navigateToChanged", sc.navigateToChanged)
 		}
+		if cmd.Flags().Changed("navigateToChangedIfAffected") {
+			c.Set("navigateToChangedIfAffected", sc.navigateIfAffected)
+		}
 		if cmd.Flags().Changed("disableLiveReload") {
 			c.Set("disableLiveReload", sc.disableLiveReload)
 		}
@@ -153,6 +178,9 @@ func (sc *serverCmd) server(cmd *cobra.Command, args []string) error {
 		if cmd.Flags().Changed("disableBrowserError") {
 			c.Set("disableBrowserError", sc.disableBrowserError)
 		}
+		if cmd.Flags().Changed("editorURL") {
+			c.Set("editorURL", sc.editorURL)
+		}
 		if sc.serverWatch {
 			c.Set("watch", true)
 		}
@@ -197,6 +225,14 @@ func (sc *serverCmd) server(cmd *cobra.Command, args []string) error {
 
 				currentServerPort = serverPorts[i] + 1
 			}
+
+			if err == nil && cmd.Flags().Changed("tunnel") {
+				if c.languages.IsMultihost() {
+					err = newSystemError("--tunnel not supported in multihost mode")
+					return
+				}
+				err = sc.startTunnel(c, serverPorts[0])
+			}
 		})
 
 		c.serverPorts = serverPorts
@@ -236,6 +272,12 @@ func (sc *serverCmd) server(cmd *cobra.Command, args []string) error {
 		jww.WARN.Println("memstats error:", err)
 	}
 
+	defer func() {
+		if sc.tunnel != nil {
+			sc.tunnel.Close()
+		}
+	}()
+
 	c, err := initializeConfig(true, true, &sc.hugoBuilderCommon, sc, cfgInit)
 	if err != nil {
 		return err
@@ -366,6 +408,8 @@ func (f *fileServer) createEndpoint(i int) (*http.ServeMux, string, string, erro
 			if f.s.noHTTPCache {
 				w.Header().Set("Cache-Control", "no-store, no-cache, must-revalidate, max-age=0")
 				w.Header().Set("Pragma", "no-cache")
+			} else if f.c.serverConfig.CacheControl != "" {
+				w.Header().Set("Cache-Control", f.c.serverConfig.CacheControl)
 			}
 
 			// Ignore any query params for the operations below.
@@ -395,11 +439,15 @@ func (f *fileServer) createEndpoint(i int) (*http.ServeMux, string, string, erro
 				}
 
 				if doRedirect {
-					if redirect.Status == 200 {
+					if redirect.Status == 200 || redirect.Status == 404 {
+						// Serve the content of the target.
 						if r2 := f.rewriteRequest(r, strings.TrimPrefix(redirect.To, u.Path)); r2 != nil {
 							requestURI = redirect.To
 							r = r2
 						}
+						if redirect.Status == 404 {
+							w = &statusResponseWriter{ResponseWriter: w, status: redirect.Status}
+						}
 					} else {
 						w.Header().Set("Content-Type", "")
 						http.Redirect(w, r, redirect.To, redirect.Status)
@@ -431,7 +479,11 @@ func (f *fileServer) createEndpoint(i int) (*http.ServeMux, string, string, erro
 		})
 	}
 
-	fileserver := decorate(http.FileServer(fs))
+	var h http.Handler = http.FileServer(fs)
+	if f.s.useCompression(f.c.serverConfig) {
+		h = compressHandler(fs, h)
+	}
+	fileserver := decorate(h)
 	mu := http.NewServeMux()
 	if u.Path == "" || u.Path == "/" {
 		mu.Handle("/", fileserver)
@@ -492,31 +544,89 @@ func (c *commandeer) serve(s *serverCmd) error {
 		livereload.Initialize()
 	}
 
+	serveLiveReloadJS := livereload.ServeJS
+	if s.tunnel != nil && !s.cmd.Flags().Changed("liveReloadPort") {
+		// The pages are served on another port through the tunnel.
+		serveLiveReloadJS = livereload.ServeJSForPagePort
+	}
+
+	var tlsConfig *tls.Config
+	if s.useHTTP2(c.Cfg) {
+		var hosts []string
+		for _, baseURL := range baseURLs {
+			if u, err := url.Parse(baseURL); err == nil {
+				hosts = append(hosts, u.Hostname())
+			}
+		}
+		if s.serverInterface != "0.0.0.0" {
+			hosts = append(hosts, s.serverInterface)
+		}
+		certFile, keyFile := c.serverConfig.TLSCertFile, c.serverConfig.TLSKeyFile
+		if certFile != "" {
+			certFile = c.hugo().PathSpec.AbsPathify(certFile)
+		}
+		if keyFile != "" {
+			keyFile = c.hugo().PathSpec.AbsPathify(keyFile)
+		}
+		tlsConfig, err = newTLSConfig(certFile, keyFile, hosts)
+		if err != nil {
+			return err
+		}
+	}
+
 	sigs := make(chan os.Signal, 1)
 	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
 
 	for i := range baseURLs {
 		mu, serverURL, endpoint, err := srv.createEndpoint(i)
 
+		var livereloadPaths []string
 		if doLiveReload {
 			u, err := url.Parse(helpers.SanitizeURL(baseURLs[i]))
 			if err != nil {
 				return err
 			}
 
-			mu.HandleFunc(u.Path+"/livereload.js", livereload.ServeJS)
-			mu.HandleFunc(u.Path+"/livereload", livereload.Handler)
+			livereloadPaths = []string{u.Path + "/livereload.js", u.Path + "/livereload"}
+			mu.HandleFunc(livereloadPaths[0], serveLiveReloadJS)
+			mu.HandleFunc(livereloadPaths[1], livereload.Handler)
+		}
+		if s.useAPI(c.serverConfig) {
+			u, err := url.Parse(helpers.SanitizeURL(baseURLs[i]))
+			if err != nil {
+				return err
+			}
+			mu.Handle(strings.TrimSuffix(u.Path, "/")+apiPagesPath, srv.apiHandler(i))
 		}
+		h := srv.accessControl(mu, livereloadPaths...)
 		jww.FEEDBACK.Printf("Web Server is available at %s (bind address %s)\n", serverURL, s.serverInterface)
 		go func() {
-			err = http.ListenAndServe(endpoint, mu)
+			if tlsConfig != nil {
+				// HTTP/2 is enabled by default for TLS in net/http.
+				srv := &http.Server{Addr: endpoint, Handler: h, TLSConfig: tlsConfig}
+				err = srv.ListenAndServeTLS("", "")
+			} else {
+				err = http.ListenAndServe(endpoint, h)
+			}
 			if err != nil {
 				c.logger.Errorf("Error: %s\n", err.Error())
+				if s.tunnel != nil {
+					s.tunnel.Close()
+				}
 				os.Exit(1)
 			}
 		}()
 	}
 
+	if s.tunnel != nil {
+		jww.FEEDBACK.Printf("Tunnel (%s) forwards %s to the server on port %d\n", s.tunnel.Provider, s.tunnel.URL, c.serverPorts[0])
+		if !c.serverConfig.Tunnel.DisableQRCode {
+			if err := tunnel.WriteQRCode(os.Stdout, baseURLs[0]); err != nil {
+				c.logger.Warnln("Failed to print the tunnel URL as a QR code:", err)
+			}
+		}
+	}
+
 	jww.FEEDBACK.Println("Press Ctrl+C to stop")
 
 	if s.stop != nil {
@@ -570,6 +680,18 @@ func (sc *serverCmd) fixURL(cfg config.Provider, s string, port int) (string, er
 		u.Host = "localhost"
 	}
 
+	if sc.tunnel != nil {
+		// Use the public URL so the links work through the tunnel.
+		u.Scheme = sc.tunnel.URL.Scheme
+		u.Host = sc.tunnel.URL.Host
+		return u.String(), nil
+	}
+
+	if sc.useHTTP2(cfg) {
+		// Browsers only support HTTP/2 over TLS.
+		u.Scheme = "https"
+	}
+
 	if sc.serverAppend {
 		if strings.Contains(u.Host, ":") {
 			u.Host, _, err = net.SplitHostPort(u.Host)
@@ -583,6 +705,79 @@ func (sc *serverCmd) fixURL(cfg config.Provider, s string, port int) (string, er
 	return u.String(), nil
 }
 
+// useHTTP2 reports whether to serve over HTTPS with HTTP/2, set with the
+// --http2 flag or in the server config.
+func (sc *serverCmd) useHTTP2(cfg config.Provider) bool {
+	if sc.cmd.Flags().Changed("http2") {
+		return sc.http2
+	}
+	return cfg.GetBool("server.http2")
+}
+
+// useAPI reports whether to enable the JSON API, set with the --api flag or
+// in the server config.
+func (sc *serverCmd) useAPI(serverConfig *config.Server) bool {
+	if sc.cmd.Flags().Changed("api") {
+		return sc.api
+	}
+	return serverConfig.API
+}
+
+// tunnelDefaultProvider is the value of --tunnel without a provider, which
+// uses the provider in the server config.
+const tunnelDefaultProvider = "default"
+
+// startTunnel starts a tunnel to the server on the given port, using the
+// provider set with --tunnel or in the server config.
+func (sc *serverCmd) startTunnel(c *commandeer, port int) error {
+	serverConfig, err := config.DecodeServer(c.Cfg)
+	if err != nil {
+		return err
+	}
+
+	cfg := serverConfig.Tunnel
+	if sc.tunnelProvider != tunnelDefaultProvider {
+		cfg.Provider = sc.tunnelProvider
+	}
+	if cfg.Provider == "" {
+		cfg.Provider = tunnel.DefaultProvider
+	}
+
+	host := sc.serverInterface
+	if ip := net.ParseIP(host); ip != nil && ip.IsUnspecified() {
+		host = "localhost"
+	}
+	local := &url.URL{Scheme: "http", Host: net.JoinHostPort(host, strconv.Itoa(port))}
+	if sc.useHTTP2(c.Cfg) {
+		local.Scheme = "https"
+	}
+
+	securityConfig, ok := c.Cfg.Get("securityConfig").(security.Config)
+	if !ok {
+		securityConfig = security.DefaultConfig
+	}
+
+	logger := loggers.NewWarningLogger()
+	if c.Cfg.GetBool("verbose") {
+		logger = loggers.NewInfoLogger()
+	}
+
+	jww.FEEDBACK.Printf("Starting tunnel with %s ...\n", cfg.Provider)
+
+	sc.tunnel, err = tunnel.Start(cfg, securityConfig, local, logger)
+
+	return err
+}
+
+// useCompression reports whether to compress the responses, set with the
+// --compress flag or in the server config.
+func (sc *serverCmd) useCompression(serverConfig *config.Server) bool {
+	if sc.cmd.Flags().Changed("compress") {
+		return sc.compress
+	}
+	return serverConfig.Compress
+}
+
 func memStats() error {
 	b := newCommandsBuilder()
 	sc := b.newServerCmd().getCommand()
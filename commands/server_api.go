@@ -0,0 +1,199 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gohugoio/hugo/resources/page"
+	"github.com/spf13/cast"
+)
+
+// apiPagesPath is the path, below the baseURL path, of the JSON API
+// returning a page.
+const apiPagesPath = "/__hugo/api/pages/"
+
+// apiPage is a page as returned by the JSON API.
+type apiPage struct {
+	Kind         string `json:"kind"`
+	Lang         string `json:"lang"`
+	Path         string `json:"path,omitempty"`
+	Section      string `json:"section,omitempty"`
+	Type         string `json:"type"`
+	Title        string `json:"title"`
+	LinkTitle    string `json:"linkTitle"`
+	Description  string `json:"description,omitempty"`
+	Permalink    string `json:"permalink"`
+	RelPermalink string `json:"relPermalink"`
+	Draft        bool   `json:"draft"`
+
+	Date        time.Time `json:"date"`
+	Lastmod     time.Time `json:"lastmod"`
+	PublishDate time.Time `json:"publishDate"`
+	ExpiryDate  time.Time `json:"expiryDate"`
+
+	WordCount   int `json:"wordCount"`
+	ReadingTime int `json:"readingTime"`
+
+	// The front matter params.
+	Params map[string]interface{} `json:"params"`
+
+	// The rendered content.
+	Summary         string `json:"summary"`
+	Content         string `json:"content"`
+	TableOfContents string `json:"tableOfContents"`
+
+	Outputs []apiOutput `json:"outputs"`
+}
+
+// apiOutput is an output format of a page as returned by the JSON API.
+type apiOutput struct {
+	Name         string `json:"name"`
+	MediaType    string `json:"mediaType"`
+	Permalink    string `json:"permalink"`
+	RelPermalink string `json:"relPermalink"`
+}
+
+// apiHandler returns the handler for the JSON API of the endpoint i, which
+// looks up the page with the path after apiPagesPath, as in .Site.GetPage. The
+// language is set with the lang query parameter, the default is the language
+// of the endpoint.
+func (f *fileServer) apiHandler(i int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			apiError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+
+		requestURI := strings.TrimSuffix(r.RequestURI, "?"+r.URL.RawQuery)
+		for _, header := range f.c.serverConfig.MatchHeaders(requestURI) {
+			w.Header().Set(header.Key, header.Value)
+		}
+
+		if err := f.c.getErrorWithContext(); err != nil {
+			apiError(w, http.StatusInternalServerError, "build failed: %s", f.c.buildErr)
+			return
+		}
+
+		h := f.c.hugo()
+		// The pages are reset and rendered again on rebuilds.
+		defer h.LockBuild()()
+
+		sites := h.Sites
+		s := sites[0]
+		if h.IsMultihost() {
+			s = sites[i]
+		}
+		if lang := r.URL.Query().Get("lang"); lang != "" {
+			s = nil
+			for _, ss := range sites {
+				if ss.Language().Lang == lang {
+					s = ss
+					break
+				}
+			}
+			if s == nil {
+				apiError(w, http.StatusNotFound, "language %q not found", lang)
+				return
+			}
+		}
+
+		ref := r.URL.Path
+		if idx := strings.Index(ref, apiPagesPath); idx != -1 {
+			ref = ref[idx+len(apiPagesPath):]
+		}
+		ref = "/" + strings.Trim(ref, "/")
+
+		p, err := s.Info.GetPage(ref)
+		if err != nil {
+			apiError(w, http.StatusBadRequest, "%s", err)
+			return
+		}
+		if p == nil || p == page.NilPage {
+			apiError(w, http.StatusNotFound, "page %q not found", ref)
+			return
+		}
+
+		ap, err := f.newAPIPage(p)
+		if err != nil {
+			apiError(w, http.StatusInternalServerError, "failed to render %q: %s", ref, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		// The content changes on every edit.
+		w.Header().Set("Cache-Control", "no-store")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.Encode(ap)
+	})
+}
+
+func (f *fileServer) newAPIPage(p page.Page) (apiPage, error) {
+	content, err := p.Content()
+	if err != nil {
+		return apiPage{}, err
+	}
+
+	ap := apiPage{
+		Kind:            p.Kind(),
+		Lang:            p.Language().Lang,
+		Section:         p.Section(),
+		Type:            p.Type(),
+		Title:           p.Title(),
+		LinkTitle:       p.LinkTitle(),
+		Description:     p.Description(),
+		Permalink:       p.Permalink(),
+		RelPermalink:    p.RelPermalink(),
+		Draft:           p.Draft(),
+		Date:            p.Date(),
+		Lastmod:         p.Lastmod(),
+		PublishDate:     p.PublishDate(),
+		ExpiryDate:      p.ExpiryDate(),
+		WordCount:       p.WordCount(),
+		ReadingTime:     p.ReadingTime(),
+		Params:          p.Params(),
+		Summary:         string(p.Summary()),
+		Content:         cast.ToString(content),
+		TableOfContents: string(p.TableOfContents()),
+		Outputs:         []apiOutput{},
+	}
+
+	if !p.File().IsZero() {
+		ap.Path = filepath.ToSlash(strings.TrimPrefix(p.File().Filename(), f.c.hugo().WorkingDir+string(os.PathSeparator)))
+	}
+
+	for _, o := range p.OutputFormats() {
+		ap.Outputs = append(ap.Outputs, apiOutput{
+			Name:         o.Name(),
+			MediaType:    o.MediaType().Type(),
+			Permalink:    o.Permalink(),
+			RelPermalink: o.RelPermalink(),
+		})
+	}
+
+	return ap, nil
+}
+
+func apiError(w http.ResponseWriter, status int, format string, args ...interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf(format, args...)})
+}
@@ -17,6 +17,11 @@ import (
 	"bytes"
 	"io"
 	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gohugoio/hugo/common/herrors"
 
 	"github.com/gohugoio/hugo/transform"
 	"github.com/gohugoio/hugo/transform/livereloadinject"
@@ -63,6 +68,12 @@ var buildErrorTemplate = `<!doctype html>
 		a:hover {
 			color: #ccc;
 		}
+		.template-stack {
+			color: #ccc;
+		}
+		.template-stack code {
+			color: #fff;
+		}
 		</style>
 	</head>
 	<body>
@@ -73,6 +84,19 @@ var buildErrorTemplate = `<!doctype html>
 			{{ $lexer := .ChromaLexer | default "go-html-template" }}
 			{{  highlight (delimit .Lines "\n") $lexer $params }}
 			{{ end }}
+			{{ with .EditorURL }}
+			<p><a href="{{ . | htmlEscape }}">Open in editor</a></p>
+			{{ end }}
+			{{ with .TemplateStack }}
+			<div class="template-stack">
+			<p>Template call stack:</p>
+			<ol>
+			{{ range . }}
+			<li>{{ with .EditorURL }}<a href="{{ . | htmlEscape }}">{{ end }}{{ .Name }}:{{ .Line }}:{{ .Column }}{{ if .EditorURL }}</a>{{ end }}: <code>{{ .Action | htmlEscape }}</code></li>
+			{{ end }}
+			</ol>
+			</div>
+			{{ end }}
 			{{ with .StackTrace }}
 			{{ highlight . "apl" "noclasses=true,style=paraiso-dark" }}
 			{{ end }}
@@ -83,6 +107,93 @@ var buildErrorTemplate = `<!doctype html>
 </html>
 `
 
+// editorURLPresets are the URL patterns used to open a file in some
+// common editors. The {file} placeholder is the absolute, slash separated
+// filename, always with a leading slash.
+var editorURLPresets = map[string]string{
+	"vscode":   "vscode://file{file}:{line}:{column}",
+	"sublime":  "subl://open?url=file://{file}&line={line}&column={column}",
+	"idea":     "idea://open?file={file}&line={line}&column={column}",
+	"textmate": "txmt://open?url=file://{file}&line={line}&column={column}",
+}
+
+// templateFrame is a template in the template call stack shown in the
+// browser error page.
+type templateFrame struct {
+	herrors.TemplateFrame
+
+	// The template source around the failing action, if found.
+	File *herrors.ErrorWithFileContext
+
+	EditorURL string
+}
+
+// templateCallStack resolves the template call stack in err to files in
+// the layouts filesystem.
+func (c *commandeer) templateCallStack(err error) []templateFrame {
+	stack := herrors.TemplateCallStack(err)
+	if len(stack) == 0 {
+		return nil
+	}
+
+	layouts := c.hugo().BaseFs.Layouts
+
+	frames := make([]templateFrame, len(stack))
+	for i, tf := range stack {
+		frames[i] = templateFrame{TemplateFrame: tf}
+
+		filename := layouts.RealFilename(tf.Name)
+		if !filepath.IsAbs(filename) {
+			// Not in the project, e.g. an embedded template.
+			continue
+		}
+
+		fe := herrors.NewFileError(strings.TrimPrefix(filepath.Ext(tf.Name), "."), -1, tf.Line, tf.Column, err)
+		if ferr, ok := herrors.WithFileContextForFile(fe, filename, tf.Name, layouts.Fs, herrors.SimpleLineMatcher); ok {
+			frames[i].File = herrors.UnwrapErrorWithFileContext(ferr)
+		}
+		frames[i].EditorURL = c.editorURL(filename, tf.Line, tf.Column)
+	}
+
+	return frames
+}
+
+// editorURL creates a link to open filename at the given position in the
+// editor configured in editorURL, either one of the presets in
+// editorURLPresets or a URL pattern with {file}, {line} and {column}
+// placeholders. It returns an empty string if no editor is configured.
+func (c *commandeer) editorURL(filename string, line, column int) string {
+	pattern := c.Cfg.GetString("editorURL")
+	if pattern == "" || filename == "" {
+		return ""
+	}
+	if preset, found := editorURLPresets[strings.ToLower(pattern)]; found {
+		pattern = preset
+	}
+
+	if !filepath.IsAbs(filename) {
+		filename = filepath.Join(c.Cfg.GetString("workingDir"), filename)
+	}
+	if line < 1 {
+		line = 1
+	}
+	if column < 1 {
+		column = 1
+	}
+
+	filename = filepath.ToSlash(filename)
+	if !strings.HasPrefix(filename, "/") {
+		// Windows, e.g. /C:/project/layouts/index.html.
+		filename = "/" + filename
+	}
+
+	return strings.NewReplacer(
+		"{file}", filename,
+		"{line}", strconv.Itoa(line),
+		"{column}", strconv.Itoa(column),
+	).Replace(pattern)
+}
+
 func injectLiveReloadScript(src io.Reader, baseURL url.URL) string {
 	var b bytes.Buffer
 	chain := transform.Chain{livereloadinject.New(baseURL)}
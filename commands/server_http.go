@@ -0,0 +1,321 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"compress/gzip"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// The precompressed files served by compressHandler, in order of preference.
+var precompressed = []struct {
+	encoding string
+	ext      string
+}{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+// compressHandler serves precompressed .br and .gz files from fs if they
+// exist, and compresses the other responses from h with gzip if the client
+// accepts it.
+func compressHandler(fs http.FileSystem, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		accepted := r.Header.Get("Accept-Encoding")
+
+		name := r.URL.Path
+		if strings.HasSuffix(name, "/") {
+			name += "index.html"
+		}
+
+		for _, p := range precompressed {
+			if !acceptsEncoding(accepted, p.encoding) {
+				continue
+			}
+			f, err := fs.Open(name + p.ext)
+			if err != nil {
+				continue
+			}
+			fi, err := f.Stat()
+			f.Close()
+			if err != nil || fi.IsDir() {
+				continue
+			}
+
+			if ctype := mime.TypeByExtension(path.Ext(name)); ctype != "" {
+				w.Header().Set("Content-Type", ctype)
+			}
+			w.Header().Set("Content-Encoding", p.encoding)
+
+			r2 := new(http.Request)
+			*r2 = *r
+			r2.URL = new(url.URL)
+			*r2.URL = *r.URL
+			r2.URL.Path = name + p.ext
+			h.ServeHTTP(w, r2)
+			return
+		}
+
+		if r.Method == http.MethodHead || !acceptsEncoding(accepted, "gzip") {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		gw := &gzipResponseWriter{ResponseWriter: w}
+		defer gw.close()
+		h.ServeHTTP(gw, r)
+	})
+}
+
+func acceptsEncoding(accepted, encoding string) bool {
+	for _, v := range strings.Split(accepted, ",") {
+		v = strings.TrimSpace(v)
+		if i := strings.IndexByte(v, ';'); i != -1 {
+			if strings.Replace(v[i+1:], " ", "", -1) == "q=0" {
+				continue
+			}
+			v = strings.TrimSpace(v[:i])
+		}
+		if strings.EqualFold(v, encoding) {
+			return true
+		}
+	}
+	return false
+}
+
+// isCompressible reports whether content of the given content type should
+// be compressed. Most image, video and font formats are already compressed.
+func isCompressible(ctype string) bool {
+	if i := strings.IndexByte(ctype, ';'); i != -1 {
+		ctype = ctype[:i]
+	}
+	ctype = strings.TrimSpace(strings.ToLower(ctype))
+	if strings.HasPrefix(ctype, "text/") {
+		return true
+	}
+	for _, s := range []string{"javascript", "json", "xml", "wasm"} {
+		if strings.Contains(ctype, s) {
+			return true
+		}
+	}
+	return false
+}
+
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	gz          *gzip.Writer
+	wroteHeader bool
+}
+
+func (w *gzipResponseWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+
+	h := w.Header()
+	if status == http.StatusOK && h.Get("Content-Encoding") == "" && isCompressible(h.Get("Content-Type")) {
+		h.Del("Content-Length")
+		h.Set("Content-Encoding", "gzip")
+		w.gz = gzip.NewWriter(w.ResponseWriter)
+	}
+
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *gzipResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", http.DetectContentType(b))
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.gz != nil {
+		return w.gz.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *gzipResponseWriter) close() error {
+	if w.gz == nil {
+		return nil
+	}
+	return w.gz.Close()
+}
+
+// statusResponseWriter serves a successful response with another status,
+// e.g. the content of /404.html with status 404.
+type statusResponseWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (w *statusResponseWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	if status == http.StatusOK {
+		status = w.status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// newTLSConfig creates the TLS config for the server, loading the
+// certificate from certFile and keyFile if set, else creating a
+// self-signed certificate for the given hosts.
+func newTLSConfig(certFile, keyFile string, hosts []string) (*tls.Config, error) {
+	var (
+		cert tls.Certificate
+		err  error
+	)
+
+	if certFile != "" || keyFile != "" {
+		cert, err = tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to load TLS certificate")
+		}
+	} else {
+		cert, err = selfSignedCertificate(hosts)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create TLS certificate")
+		}
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+func selfSignedCertificate(hosts []string) (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	now := time.Now()
+	template := x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"Hugo development server"}},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(30 * 24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+
+	seen := make(map[string]bool)
+	for _, h := range append(hosts, "localhost", "127.0.0.1", "::1") {
+		if seen[h] {
+			continue
+		}
+		seen[h] = true
+		if ip := net.ParseIP(h); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else if h != "" {
+			template.DNSNames = append(template.DNSNames, h)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
+
+// accessControl restricts the access to h to the clients allowed by the
+// allowIPs and basicAuth server config. The livereload endpoints in public
+// expose no content and are not protected with basic authentication, as not
+// all browsers send the credentials when opening a WebSocket. For requests
+// through the tunnel, the client IP address is read from the headers set by
+// the tunnel provider.
+func (f *fileServer) accessControl(h http.Handler, public ...string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sc := f.c.serverConfig
+
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+		ip := net.ParseIP(host)
+		allowed := sc.AllowsIP
+		if f.s != nil && f.s.tunnel.Forwards(r) && ip.IsLoopback() {
+			// The request comes from the local tunnel client.
+			ip = f.s.tunnel.ClientIP(r)
+			allowed = sc.AllowsForwardedIP
+		}
+		if !allowed(ip) {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+
+		if !sc.BasicAuth.IsZero() && !isPublicPath(r.URL.Path, public) {
+			user, password, ok := r.BasicAuth()
+			if !ok || !secureCompare(user, sc.BasicAuth.User) || !secureCompare(password, sc.BasicAuth.Password) {
+				w.Header().Set("WWW-Authenticate", `Basic realm="Hugo", charset="UTF-8"`)
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
+
+func isPublicPath(p string, public []string) bool {
+	for _, pp := range public {
+		if p == pp {
+			return true
+		}
+	}
+	return false
+}
+
+func secureCompare(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
@@ -14,16 +14,27 @@
 package commands
 
 import (
+	"compress/gzip"
+	"crypto/x509"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
+	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/gohugoio/hugo/config"
+	"github.com/gohugoio/hugo/deps"
 	"github.com/gohugoio/hugo/helpers"
+	"github.com/gohugoio/hugo/tunnel"
+	"github.com/spf13/afero"
 
 	qt "github.com/frankban/quicktest"
 )
@@ -75,6 +86,128 @@ func TestServer(t *testing.T) {
 	stop <- true
 }
 
+func TestServerAPI(t *testing.T) {
+	if isWindowsCI() {
+		// TODO(bep) not sure why server tests have started to fail on the Windows CI server.
+		t.Skip("Skip server test on appveyor")
+	}
+	c := qt.New(t)
+	dir, clean, err := createSimpleTestSite(t, testSiteConfig{})
+	defer clean()
+	c.Assert(err, qt.IsNil)
+
+	port := 1332
+
+	defer func() {
+		os.RemoveAll(dir)
+	}()
+
+	stop := make(chan bool)
+
+	b := newCommandsBuilder()
+	scmd := b.newServerCmdSignaled(stop)
+
+	cmd := scmd.getCommand()
+	cmd.SetArgs([]string{"-s=" + dir, fmt.Sprintf("-p=%d", port), "--api"})
+
+	go func() {
+		_, err = cmd.ExecuteC()
+		c.Assert(err, qt.IsNil)
+	}()
+
+	// There is no way to know exactly when the server is ready for connections.
+	// We could improve by something like https://golang.org/pkg/net/http/httptest/#Server
+	// But for now, let us sleep and pray!
+	time.Sleep(2 * time.Second)
+
+	c.Run("API", func(c *qt.C) {
+		resp, err := http.Get("http://localhost:1332/__hugo/api/pages/p1")
+		c.Assert(err, qt.IsNil)
+		defer resp.Body.Close()
+		c.Assert(resp.StatusCode, qt.Equals, 200)
+		c.Assert(resp.Header.Get("Content-Type"), qt.Equals, "application/json; charset=utf-8")
+
+		var p apiPage
+		c.Assert(json.NewDecoder(resp.Body).Decode(&p), qt.IsNil)
+		c.Assert(p.Kind, qt.Equals, "page")
+		c.Assert(p.Title, qt.Equals, "P1")
+		c.Assert(p.Path, qt.Equals, "content/p1.md")
+		c.Assert(p.Content, qt.Equals, "<p>Content</p>\n")
+		c.Assert(p.Params["weight"], qt.Equals, float64(1))
+		c.Assert(p.Outputs, qt.HasLen, 1)
+		c.Assert(p.Outputs[0].RelPermalink, qt.Equals, "/p1/")
+
+		resp, err = http.Get("http://localhost:1332/__hugo/api/pages/")
+		c.Assert(err, qt.IsNil)
+		resp.Body.Close()
+		c.Assert(resp.StatusCode, qt.Equals, 200)
+
+		for path, status := range map[string]int{
+			"/__hugo/api/pages/nope":       404,
+			"/__hugo/api/pages/p1?lang=fr": 404,
+		} {
+			resp, err := http.Get("http://localhost:1332" + path)
+			c.Assert(err, qt.IsNil)
+			var body map[string]string
+			c.Assert(json.NewDecoder(resp.Body).Decode(&body), qt.IsNil)
+			resp.Body.Close()
+			c.Assert(resp.StatusCode, qt.Equals, status)
+			c.Assert(body["error"], qt.Not(qt.Equals), "")
+		}
+	})
+
+	c.Run("API during rebuild", func(c *qt.C) {
+		// Run with -race to detect reads of the pages while they're rebuilt.
+		getContent := func() string {
+			resp, err := http.Get("http://localhost:1332/__hugo/api/pages/p1")
+			if err != nil {
+				return ""
+			}
+			defer resp.Body.Close()
+			var p apiPage
+			json.NewDecoder(resp.Body).Decode(&p)
+			return p.Content
+		}
+
+		done := make(chan struct{})
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					select {
+					case <-done:
+						return
+					default:
+						getContent()
+					}
+				}
+			}()
+		}
+
+		p1 := filepath.Join(dir, "content", "p1.md")
+		for i := 0; i < 5; i++ {
+			writeFile(t, p1, fmt.Sprintf("---\ntitle: \"P1\"\nweight: 1\n---\n\nContent %d\n", i))
+			time.Sleep(200 * time.Millisecond)
+		}
+		close(done)
+		wg.Wait()
+
+		var content string
+		for i := 0; i < 50; i++ {
+			if content = getContent(); content == "<p>Content 4</p>\n" {
+				break
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+		c.Assert(content, qt.Equals, "<p>Content 4</p>\n")
+	})
+
+	// Stop the server.
+	stop <- true
+}
+
 func TestFixURL(t *testing.T) {
 	type data struct {
 		TestName   string
@@ -115,6 +248,120 @@ func TestFixURL(t *testing.T) {
 			}
 		})
 	}
+
+	t.Run("HTTP/2", func(t *testing.T) {
+		c := qt.New(t)
+		b := newCommandsBuilder()
+		s := b.newServerCmd()
+		v := config.New()
+		v.Set("baseURL", "http://foo.com")
+		v.Set("server", map[string]interface{}{"http2": true})
+		s.serverAppend = true
+		result, err := s.fixURL(v, "", 1313)
+		c.Assert(err, qt.IsNil)
+		c.Assert(result, qt.Equals, "https://localhost:1313/")
+	})
+
+	t.Run("Tunnel", func(t *testing.T) {
+		c := qt.New(t)
+		b := newCommandsBuilder()
+		s := b.newServerCmd()
+		v := config.New()
+		v.Set("baseURL", "http://foo.com/docs/")
+		s.serverAppend = true
+		u, _ := url.Parse("https://brave-lions-sing-loudly.trycloudflare.com")
+		s.tunnel = &tunnel.Tunnel{URL: u}
+		result, err := s.fixURL(v, "", 1313)
+		c.Assert(err, qt.IsNil)
+		c.Assert(result, qt.Equals, "https://brave-lions-sing-loudly.trycloudflare.com/docs/")
+	})
+}
+
+func TestCompressHandler(t *testing.T) {
+	c := qt.New(t)
+
+	fs := afero.NewMemMapFs()
+	html := strings.Repeat("<p>Hello</p>", 100)
+	c.Assert(afero.WriteFile(fs, "/index.html", []byte(html), 0666), qt.IsNil)
+	c.Assert(afero.WriteFile(fs, "/app.js", []byte("var a;"), 0666), qt.IsNil)
+	c.Assert(afero.WriteFile(fs, "/app.js.br", []byte("brotli"), 0666), qt.IsNil)
+	c.Assert(afero.WriteFile(fs, "/image.png", []byte("\x89PNG\r\n\x1a\n"), 0666), qt.IsNil)
+
+	httpFs := afero.NewHttpFs(fs).Dir("/")
+	h := compressHandler(httpFs, http.FileServer(httpFs))
+
+	get := func(method, path, acceptEncoding string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, path, nil)
+		if acceptEncoding != "" {
+			r.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	w := get("GET", "/", "gzip, deflate, br")
+	c.Assert(w.Code, qt.Equals, 200)
+	c.Assert(w.Header().Get("Content-Encoding"), qt.Equals, "gzip")
+	c.Assert(w.Header().Get("Content-Length"), qt.Equals, "")
+	c.Assert(w.Header().Get("Vary"), qt.Equals, "Accept-Encoding")
+	gr, err := gzip.NewReader(w.Body)
+	c.Assert(err, qt.IsNil)
+	b, err := ioutil.ReadAll(gr)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, html)
+
+	w = get("GET", "/", "")
+	c.Assert(w.Header().Get("Content-Encoding"), qt.Equals, "")
+	c.Assert(w.Body.String(), qt.Equals, html)
+
+	w = get("HEAD", "/", "gzip")
+	c.Assert(w.Header().Get("Content-Encoding"), qt.Equals, "")
+
+	w = get("GET", "/app.js", "gzip, br")
+	c.Assert(w.Header().Get("Content-Encoding"), qt.Equals, "br")
+	c.Assert(w.Header().Get("Content-Type"), qt.Contains, "javascript")
+	c.Assert(w.Body.String(), qt.Equals, "brotli")
+
+	w = get("GET", "/app.js", "gzip, br;q=0")
+	c.Assert(w.Header().Get("Content-Encoding"), qt.Equals, "gzip")
+
+	w = get("GET", "/image.png", "gzip")
+	c.Assert(w.Header().Get("Content-Encoding"), qt.Equals, "")
+	c.Assert(w.Header().Get("Content-Type"), qt.Contains, "image/png")
+}
+
+func TestStatusResponseWriter(t *testing.T) {
+	c := qt.New(t)
+
+	fs := afero.NewMemMapFs()
+	c.Assert(afero.WriteFile(fs, "/404.html", []byte("Not found"), 0666), qt.IsNil)
+
+	httpFs := afero.NewHttpFs(fs).Dir("/")
+	h := http.FileServer(httpFs)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(&statusResponseWriter{ResponseWriter: w, status: http.StatusNotFound}, httptest.NewRequest("GET", "/404.html", nil))
+	c.Assert(w.Code, qt.Equals, http.StatusNotFound)
+	c.Assert(w.Body.String(), qt.Equals, "Not found")
+	c.Assert(w.Header().Get("Content-Type"), qt.Contains, "text/html")
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	c := qt.New(t)
+
+	cfg, err := newTLSConfig("", "", []string{"example.local", "localhost", "192.168.1.2"})
+	c.Assert(err, qt.IsNil)
+	c.Assert(cfg.Certificates, qt.HasLen, 1)
+
+	cert, err := x509.ParseCertificate(cfg.Certificates[0].Certificate[0])
+	c.Assert(err, qt.IsNil)
+	c.Assert(cert.DNSNames, qt.DeepEquals, []string{"example.local", "localhost"})
+	c.Assert(cert.IPAddresses, qt.HasLen, 3)
+	c.Assert(cert.VerifyHostname("example.local"), qt.IsNil)
+
+	_, err = newTLSConfig("cert.pem", "key.pem", nil)
+	c.Assert(err, qt.ErrorMatches, "failed to load TLS certificate.*")
 }
 
 func TestRemoveErrorPrefixFromLog(t *testing.T) {
@@ -128,6 +375,91 @@ ERROR 2018/10/07 13:11:12 Rebuild failed: logged 1 error(s)
 	c.Assert(strings.Contains(withoutError, "ERROR"), qt.Equals, false)
 }
 
+func TestEditorURL(t *testing.T) {
+	c := qt.New(t)
+
+	cfg := config.New()
+	cfg.Set("workingDir", "/my/project")
+	cc := &commandeer{commandeerHugoState: &commandeerHugoState{DepsCfg: &deps.DepsCfg{Cfg: cfg}}}
+
+	c.Assert(cc.editorURL("/my/project/layouts/index.html", 3, 4), qt.Equals, "")
+
+	cfg.Set("editorURL", "vscode")
+	c.Assert(cc.editorURL("/my/project/layouts/index.html", 3, 4), qt.Equals, "vscode://file/my/project/layouts/index.html:3:4")
+	c.Assert(cc.editorURL("content/post.md", -1, 0), qt.Equals, "vscode://file/my/project/content/post.md:1:1")
+
+	cfg.Set("editorURL", "myeditor://open?path={file}&l={line}")
+	c.Assert(cc.editorURL("/my/project/layouts/index.html", 3, 4), qt.Equals, "myeditor://open?path=/my/project/layouts/index.html&l=3")
+}
+
 func isWindowsCI() bool {
 	return runtime.GOOS == "windows" && os.Getenv("CI") != ""
 }
+
+func TestAccessControl(t *testing.T) {
+	c := qt.New(t)
+
+	sc := &config.Server{
+		BasicAuth: config.BasicAuth{User: "jo", Password: "secret"},
+		AllowIPs:  []string{"192.168.1.0/24"},
+	}
+	f := &fileServer{c: &commandeer{serverConfig: sc}}
+	h := f.accessControl(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "OK")
+	}), "/livereload.js")
+
+	get := func(remoteAddr, path, user, password string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", path, nil)
+		r.RemoteAddr = remoteAddr
+		if user != "" {
+			r.SetBasicAuth(user, password)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	c.Assert(get("192.168.1.10:1234", "/", "jo", "secret").Body.String(), qt.Equals, "OK")
+	c.Assert(get("127.0.0.1:1234", "/", "jo", "secret").Code, qt.Equals, 200)
+	c.Assert(get("192.168.2.10:1234", "/", "jo", "secret").Code, qt.Equals, 403)
+	c.Assert(get("192.168.2.10:1234", "/livereload.js", "", "").Code, qt.Equals, 403)
+	c.Assert(get("192.168.1.10:1234", "/livereload.js", "", "").Code, qt.Equals, 200)
+
+	w := get("192.168.1.10:1234", "/", "jo", "wrong")
+	c.Assert(w.Code, qt.Equals, 401)
+	c.Assert(w.Header().Get("WWW-Authenticate"), qt.Contains, "Basic")
+	c.Assert(get("192.168.1.10:1234", "/", "", "").Code, qt.Equals, 401)
+	c.Assert(get("192.168.1.10:1234", "/livereload.js/", "", "").Code, qt.Equals, 401)
+
+	c.Run("Tunnel", func(c *qt.C) {
+		u, _ := url.Parse("https://my.example.org")
+		f.s = &serverCmd{tunnel: &tunnel.Tunnel{URL: u}}
+		defer func() { f.s = nil }()
+
+		get := func(remoteAddr, host, forwardedFor string) int {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = remoteAddr
+			r.Host = host
+			if forwardedFor != "" {
+				r.Header.Set("X-Forwarded-For", forwardedFor)
+			}
+			r.SetBasicAuth("jo", "secret")
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			return w.Code
+		}
+
+		c.Assert(get("127.0.0.1:1234", "my.example.org", "192.168.1.10"), qt.Equals, 200)
+		c.Assert(get("127.0.0.1:1234", "my.example.org", "192.168.2.10"), qt.Equals, 403)
+		c.Assert(get("127.0.0.1:1234", "my.example.org", ""), qt.Equals, 403)
+		c.Assert(get("127.0.0.1:1234", "localhost:1313", ""), qt.Equals, 200)
+		// The client controls all but the last entry.
+		c.Assert(get("127.0.0.1:1234", "my.example.org", "192.168.1.10, 192.168.2.10"), qt.Equals, 403)
+		c.Assert(get("127.0.0.1:1234", "my.example.org", "192.168.2.10, 192.168.1.10"), qt.Equals, 200)
+		// Header-derived addresses are never trusted as loopback.
+		c.Assert(get("127.0.0.1:1234", "my.example.org", "127.0.0.1"), qt.Equals, 403)
+		c.Assert(get("127.0.0.1:1234", "my.example.org", "192.168.2.10, 127.0.0.1"), qt.Equals, 403)
+		// Only trust the headers from the local tunnel client.
+		c.Assert(get("192.168.2.10:1234", "my.example.org", "192.168.1.10"), qt.Equals, 403)
+	})
+}
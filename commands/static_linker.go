@@ -0,0 +1,194 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/gohugoio/hugo/common/loggers"
+	"github.com/gohugoio/hugo/hugofs"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"github.com/spf13/fsync"
+)
+
+// staticFileSyncer syncs the static files in src into dst.
+type staticFileSyncer interface {
+	Sync(dst, src string) error
+}
+
+// staticLinker publishes static files as hard links or copy-on-write clones
+// of the source files. Files it cannot link, e.g. because the source
+// and destination are on different devices, are copied.
+type staticLinker struct {
+	mode   string
+	logger loggers.Logger
+
+	srcFs  afero.Fs
+	destFs afero.Fs
+
+	// Used to copy the files that cannot be linked.
+	copier *fsync.Syncer
+
+	noTimes bool
+
+	delete       bool
+	deleteFilter func(f os.FileInfo) bool
+
+	numFiles     uint64
+	fallbackOnce sync.Once
+}
+
+// newStaticFileSyncer creates a syncer for the configured staticPublishMode.
+// Linking is only possible when publishing to the OS filesystem.
+func (c *commandeer) newStaticFileSyncer(srcFs afero.Fs, copier *fsync.Syncer) (staticFileSyncer, error) {
+	mode := strings.ToLower(c.Cfg.GetString("staticPublishMode"))
+	if mode == "" || mode == hugofs.LinkModeCopy {
+		return copier, nil
+	}
+	if err := hugofs.ValidateLinkMode(mode); err != nil {
+		return nil, errors.Wrap(err, "invalid staticPublishMode")
+	}
+
+	if _, ok := c.Fs.Destination.(*afero.OsFs); !ok {
+		c.logger.Infof("staticPublishMode %q is only supported when publishing to disk, copying static files", mode)
+		return copier, nil
+	}
+
+	return &staticLinker{
+		mode:         mode,
+		logger:       c.logger,
+		srcFs:        srcFs,
+		destFs:       c.Fs.Destination,
+		copier:       copier,
+		noTimes:      c.Cfg.GetBool("noTimes"),
+		delete:       copier.Delete,
+		deleteFilter: copier.DeleteFilter,
+	}, nil
+}
+
+// Sync links the files and directories inside src into dst.
+func (l *staticLinker) Sync(dst, src string) error {
+	fi, err := l.srcFs.Stat(src)
+	if err != nil {
+		return err
+	}
+
+	if !fi.IsDir() {
+		return l.syncFile(dst, src, fi)
+	}
+
+	return l.syncDir(dst, src)
+}
+
+func (l *staticLinker) syncDir(dst, src string) error {
+	dfi, err := l.destFs.Stat(dst)
+	if err == nil && !dfi.IsDir() {
+		if err := l.destFs.Remove(dst); err != nil {
+			return err
+		}
+		err = os.ErrNotExist
+	}
+	if os.IsNotExist(err) {
+		if err := l.destFs.MkdirAll(dst, 0755); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+
+	fis, err := afero.ReadDir(l.srcFs, src)
+	if err != nil {
+		return err
+	}
+
+	names := make(map[string]bool, len(fis))
+	for _, fi := range fis {
+		names[fi.Name()] = true
+		dst2, src2 := filepath.Join(dst, fi.Name()), filepath.Join(src, fi.Name())
+		if fi.IsDir() {
+			err = l.syncDir(dst2, src2)
+		} else {
+			err = l.syncFile(dst2, src2, fi)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if l.delete {
+		dfis, err := afero.ReadDir(l.destFs, dst)
+		if err != nil {
+			return err
+		}
+		for _, dfi := range dfis {
+			if !names[dfi.Name()] && !l.deleteFilter(dfi) {
+				if err := l.destFs.RemoveAll(filepath.Join(dst, dfi.Name())); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (l *staticLinker) syncFile(dst, src string, fi os.FileInfo) error {
+	l.numFiles++
+
+	var filename string
+	if fim, ok := fi.(hugofs.FileMetaInfo); ok {
+		filename = fim.Meta().Filename()
+	}
+	if filename == "" {
+		return l.copy(dst, src)
+	}
+
+	if dfi, err := os.Stat(dst); err == nil && !dfi.IsDir() {
+		if sfi, err := os.Stat(filename); err == nil {
+			if os.SameFile(dfi, sfi) {
+				// Already linked.
+				return nil
+			}
+			if l.mode == hugofs.LinkModeReflink && !l.noTimes && dfi.Size() == sfi.Size() && dfi.ModTime().Equal(sfi.ModTime()) {
+				// Cloned in a previous build.
+				return nil
+			}
+		}
+	}
+
+	if err := hugofs.LinkFile(l.mode, dst, filename); err != nil {
+		l.fallbackOnce.Do(func() {
+			l.logger.Infof("Failed to %s %q, copying files that cannot be linked: %s", l.mode, filename, err)
+		})
+		return l.copy(dst, src)
+	}
+
+	if l.mode == hugofs.LinkModeReflink && !l.noTimes {
+		// Hard links share the modification time with the source,
+		// clones need it to be set.
+		if err := os.Chtimes(dst, fi.ModTime(), fi.ModTime()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (l *staticLinker) copy(dst, src string) error {
+	return l.copier.Sync(dst, src)
+}
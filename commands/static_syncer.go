@@ -57,6 +57,11 @@ func (s *staticSyncer) syncsStaticEvents(staticEvents []fsnotify.Event) error {
 		syncer.SrcFs = sourceFs.Fs
 		syncer.DestFs = c.Fs.Destination
 
+		fileSyncer, err := c.newStaticFileSyncer(sourceFs.Fs, syncer)
+		if err != nil {
+			return 0, err
+		}
+
 		// prevent spamming the log on changes
 		logger := helpers.NewDistinctErrorLogger()
 
@@ -106,7 +111,7 @@ func (s *staticSyncer) syncsStaticEvents(staticEvents []fsnotify.Event) error {
 					// If file still exists, sync it
 					logger.Println("Syncing", relPath, "to", publishDir)
 
-					if err := syncer.Sync(filepath.Join(publishDir, relPath), relPath); err != nil {
+					if err := fileSyncer.Sync(filepath.Join(publishDir, relPath), relPath); err != nil {
 						c.logger.Errorln(err)
 					}
 				} else {
@@ -118,7 +123,7 @@ func (s *staticSyncer) syncsStaticEvents(staticEvents []fsnotify.Event) error {
 
 			// For all other event operations Hugo will sync static.
 			logger.Println("Syncing", relPath, "to", publishDir)
-			if err := syncer.Sync(filepath.Join(publishDir, relPath), relPath); err != nil {
+			if err := fileSyncer.Sync(filepath.Join(publishDir, relPath), relPath); err != nil {
 				c.logger.Errorln(err)
 			}
 		}
@@ -0,0 +1,193 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package comments fetches comments and discussions from external services at
+// build time, so they can be rendered statically.
+package comments
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gohugoio/hugo/cache/filecache"
+	"github.com/gohugoio/hugo/config/security"
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/pkg/errors"
+)
+
+// Comment is a comment with its replies.
+type Comment struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+
+	Author Author `json:"author"`
+
+	// The comment body as HTML, as provided by the source.
+	// This is not sanitized by Hugo.
+	Content string `json:"content"`
+
+	Date time.Time `json:"date"`
+
+	Replies []Comment `json:"replies"`
+}
+
+// Author is the author of a comment.
+type Author struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Avatar string `json:"avatar"`
+}
+
+// Client fetches comments from the configured sources.
+type Client struct {
+	sources map[string]SourceConfig
+	cache   *filecache.Cache
+
+	httpClient *http.Client
+	timeout    time.Duration
+	security   security.Config
+}
+
+// New creates a new Client. The comments are cached in cache, if set.
+func New(cfg Config, cache *filecache.Cache, sc security.Config) *Client {
+	sources := make(map[string]SourceConfig)
+	for _, s := range cfg.Sources {
+		sources[s.Name] = s
+	}
+
+	return &Client{
+		sources:    sources,
+		cache:      cache,
+		httpClient: sc.NewHTTPClient(0),
+		timeout:    cfg.Timeout,
+		security:   sc,
+	}
+}
+
+// HasSource reports whether a source with the given name is configured.
+func (c *Client) HasSource(name string) bool {
+	_, found := c.sources[strings.ToLower(name)]
+	return found
+}
+
+// Get gets the comments with the given id, e.g. a GitHub discussion number,
+// from the named source. The top level comments are sorted by date.
+func (c *Client) Get(name, id string) ([]Comment, error) {
+	s, found := c.sources[strings.ToLower(name)]
+	if !found {
+		return nil, errors.Errorf("comments: source %q not found", name)
+	}
+	if id == "" {
+		return nil, errors.Errorf("comments: no id given for source %q", name)
+	}
+
+	fetch := func() ([]byte, error) {
+		comments, err := c.fetch(s, id)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(comments)
+	}
+
+	var (
+		b   []byte
+		err error
+	)
+
+	if c.cache != nil {
+		_, b, err = c.cache.GetOrCreateBytes(s.Name+"_"+helpers.MD5String(id), fetch)
+	} else {
+		b, err = fetch()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var comments []Comment
+	if err := json.Unmarshal(b, &comments); err != nil {
+		return nil, err
+	}
+
+	return comments, nil
+}
+
+func (c *Client) fetch(s SourceConfig, id string) ([]Comment, error) {
+	var (
+		comments []Comment
+		err      error
+	)
+
+	switch s.Type {
+	case TypeGitHub:
+		comments, err = c.fetchGitHub(s, id)
+	case TypeMastodon:
+		comments, err = c.fetchMastodon(s, id)
+	case TypeJSON:
+		comments, err = c.fetchJSON(s, id)
+	default:
+		err = errors.Errorf("unknown type %q", s.Type)
+	}
+
+	if err != nil {
+		if security.IsAccessDenied(err) {
+			return nil, err
+		}
+		return nil, errors.Wrapf(err, "comments: failed to get %q from source %q", id, s.Name)
+	}
+
+	sort.SliceStable(comments, func(i, j int) bool {
+		return comments[i].Date.Before(comments[j].Date)
+	})
+
+	return comments, nil
+}
+
+// do performs req, authorized with the bearer token if set, and decodes the
+// JSON response into v.
+func (c *Client) do(req *http.Request, token string, v interface{}) error {
+	if err := c.security.CheckAllowedHTTP(req.Method, req.URL); err != nil {
+		return err
+	}
+
+	if c.timeout > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+		defer cancel()
+		req = req.WithContext(ctx)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "Hugo Static Site Generator")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		io.Copy(ioutil.Discard, resp.Body)
+		return fmt.Errorf("%s %s: %s", req.Method, req.URL, resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,196 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package comments
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/cache/filecache"
+	"github.com/gohugoio/hugo/config"
+	"github.com/gohugoio/hugo/config/security"
+	"github.com/spf13/afero"
+)
+
+func TestDecodeConfig(t *testing.T) {
+	c := qt.New(t)
+
+	cc, err := DecodeConfig(config.New())
+	c.Assert(err, qt.IsNil)
+	c.Assert(cc.Timeout, qt.Equals, 10*time.Second)
+	c.Assert(cc.Sources, qt.HasLen, 0)
+
+	cfg, err := config.FromConfigString(`
+[comments]
+timeout = "3s"
+[[comments.sources]]
+name = "Discussions"
+type = "GitHub"
+repository = "gohugoio/hugo"
+[[comments.sources]]
+name = "mastodon"
+type = "mastodon"
+[[comments.sources]]
+name = "api"
+type = "json"
+url = "https://example.org/comments/:id.json"
+`, "toml")
+	c.Assert(err, qt.IsNil)
+
+	cc, err = DecodeConfig(cfg)
+	c.Assert(err, qt.IsNil)
+	c.Assert(cc.Timeout, qt.Equals, 3*time.Second)
+	c.Assert(cc.Sources, qt.HasLen, 3)
+	c.Assert(cc.Sources[0], qt.DeepEquals, SourceConfig{Name: "discussions", Type: TypeGitHub, Repository: "gohugoio/hugo", URL: defaultGitHubURL})
+
+	for _, invalid := range []string{
+		`[[comments.sources]]
+type = "json"
+url = "https://example.org/:id"`,
+		`[[comments.sources]]
+name = "a"
+type = "disqus"`,
+		`[[comments.sources]]
+name = "a"
+type = "github"
+repository = "hugo"`,
+		`[[comments.sources]]
+name = "a"
+type = "json"
+url = "https://example.org/comments"`,
+		`[[comments.sources]]
+name = "a"
+type = "json"
+url = "ftp://example.org/:id"`,
+	} {
+		cfg, err := config.FromConfigString(invalid, "toml")
+		c.Assert(err, qt.IsNil)
+		_, err = DecodeConfig(cfg)
+		c.Assert(err, qt.Not(qt.IsNil))
+	}
+}
+
+func TestClient(t *testing.T) {
+	c := qt.New(t)
+
+	var (
+		calls   int32
+		auth    string
+		graphql map[string]interface{}
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		switch {
+		case r.URL.Path == "/graphql":
+			auth = r.Header.Get("Authorization")
+			b, _ := ioutil.ReadAll(r.Body)
+			json.Unmarshal(b, &graphql)
+			w.Write([]byte(`{"data": {"repository": {"discussion": {"comments": {"nodes": [
+{"id": "c2", "bodyHTML": "<p>Second</p>", "createdAt": "2021-06-02T10:00:00Z", "author": {"login": "bep"}},
+{"id": "c1", "bodyHTML": "<p>First</p>", "createdAt": "2021-06-01T10:00:00Z", "author": {"login": "jmooring"},
+ "replies": {"nodes": [{"id": "r1", "bodyHTML": "<p>Reply</p>", "createdAt": "2021-06-03T10:00:00Z", "author": {"login": "bep"}}]}}
+]}}}}}`))
+		case r.URL.Path == "/api/v1/statuses/100/context":
+			w.Write([]byte(`{"ancestors": [], "descendants": [
+{"id": "101", "in_reply_to_id": "100", "content": "<p>Toot</p>", "created_at": "2021-06-01T10:00:00Z", "account": {"acct": "bep@example.org"}},
+{"id": "102", "in_reply_to_id": "101", "content": "<p>Reply</p>", "created_at": "2021-06-01T11:00:00Z", "account": {"display_name": "Bjørn Erik", "acct": "bep"}}
+]}`))
+		case strings.HasPrefix(r.URL.Path, "/comments/"):
+			if r.URL.Path != "/comments/my-post.json" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write([]byte(`[{"id": "1", "content": "Hello", "author": {"name": "Jane"}, "date": "2021-06-01T10:00:00Z"}]`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	cfg := DefaultConfig
+	cfg.Sources = []SourceConfig{
+		{Name: "github", Type: TypeGitHub, Repository: "gohugoio/hugo", URL: srv.URL + "/graphql", Token: "mytoken"},
+		{Name: "mastodon", Type: TypeMastodon},
+		{Name: "api", Type: TypeJSON, URL: srv.URL + "/comments/:id.json"},
+	}
+
+	fs := afero.NewMemMapFs()
+	cache := filecache.NewCache(fs, time.Hour, "")
+
+	client := New(cfg, cache, security.DefaultConfig)
+
+	c.Run("GitHub", func(c *qt.C) {
+		cs, err := client.Get("github", "42")
+		c.Assert(err, qt.IsNil)
+		c.Assert(auth, qt.Equals, "Bearer mytoken")
+		c.Assert(graphql["variables"], qt.DeepEquals, map[string]interface{}{"owner": "gohugoio", "name": "hugo", "number": float64(42)})
+		c.Assert(cs, qt.HasLen, 2)
+		c.Assert(cs[0].ID, qt.Equals, "c1")
+		c.Assert(cs[0].Author.Name, qt.Equals, "jmooring")
+		c.Assert(cs[0].Replies, qt.HasLen, 1)
+		c.Assert(cs[0].Replies[0].Content, qt.Equals, "<p>Reply</p>")
+		c.Assert(cs[1].ID, qt.Equals, "c2")
+
+		_, err = client.Get("github", "foo")
+		c.Assert(err, qt.ErrorMatches, `.*invalid discussion number "foo"`)
+	})
+
+	c.Run("Mastodon", func(c *qt.C) {
+		cs, err := client.Get("mastodon", srv.URL+"/@bep/100")
+		c.Assert(err, qt.IsNil)
+		c.Assert(cs, qt.HasLen, 1)
+		c.Assert(cs[0].Author.Name, qt.Equals, "bep@example.org")
+		c.Assert(cs[0].Replies, qt.HasLen, 1)
+		c.Assert(cs[0].Replies[0].Author.Name, qt.Equals, "Bjørn Erik")
+	})
+
+	c.Run("JSON", func(c *qt.C) {
+		cs, err := client.Get("api", "my-post")
+		c.Assert(err, qt.IsNil)
+		c.Assert(cs, qt.HasLen, 1)
+		c.Assert(cs[0].Author.Name, qt.Equals, "Jane")
+
+		_, err = client.Get("api", "other-post")
+		c.Assert(err, qt.ErrorMatches, `comments: failed to get "other-post" from source "api": GET .*: 404 Not Found`)
+	})
+
+	c.Run("Cache", func(c *qt.C) {
+		before := atomic.LoadInt32(&calls)
+		cs, err := client.Get("api", "my-post")
+		c.Assert(err, qt.IsNil)
+		c.Assert(cs, qt.HasLen, 1)
+		c.Assert(atomic.LoadInt32(&calls), qt.Equals, before)
+	})
+
+	c.Run("Not found", func(c *qt.C) {
+		c.Assert(client.HasSource("API"), qt.IsTrue)
+		_, err := client.Get("disqus", "my-post")
+		c.Assert(err, qt.ErrorMatches, `comments: source "disqus" not found`)
+	})
+
+	c.Run("Access denied", func(c *qt.C) {
+		sc := security.DefaultConfig
+		sc.HTTP.Domains = security.NewWhitelist("none")
+		_, err := New(cfg, nil, sc).Get("api", "my-post")
+		c.Assert(security.IsAccessDenied(err), qt.IsTrue)
+	})
+}
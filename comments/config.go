@@ -0,0 +1,146 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package comments
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gohugoio/hugo/config"
+	"github.com/gohugoio/hugo/config/configschema"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+)
+
+const commentsConfigKey = "comments"
+
+// The supported source types.
+const (
+	// Comments in a GitHub Discussion, identified by the discussion number.
+	TypeGitHub = "github"
+
+	// Replies to a Mastodon status, identified by the status URL.
+	TypeMastodon = "mastodon"
+
+	// A JSON API returning comments in Hugo's format, identified by whatever
+	// the API expects in place of :id in the URL.
+	TypeJSON = "json"
+)
+
+const defaultGitHubURL = "https://api.github.com/graphql"
+
+// DefaultConfig holds the default comments configuration.
+var DefaultConfig = Config{
+	Timeout: 10 * time.Second,
+}
+
+// Config holds the comments configuration.
+type Config struct {
+	// The sources to fetch comments from.
+	Sources []SourceConfig
+
+	// The timeout for each request.
+	Timeout time.Duration
+}
+
+// SourceConfig configures a comment source.
+type SourceConfig struct {
+	// The name used to refer to the source, e.g. in the page front matter.
+	Name string
+
+	// One of github, mastodon or json.
+	Type string
+
+	// The API endpoint. For the json type this must be set and contain
+	// an :id placeholder. For github it defaults to GitHub's GraphQL API.
+	URL string
+
+	// The GitHub repository holding the discussions, e.g. "gohugoio/hugo".
+	Repository string
+
+	// The token sent as a bearer token in the Authorization header.
+	// For github this defaults to the GITHUB_TOKEN environment variable.
+	Token string
+}
+
+// DecodeConfig decodes the comments section in the site configuration.
+func DecodeConfig(cfg config.Provider) (Config, error) {
+	c := DefaultConfig
+
+	if !cfg.IsSet(commentsConfigKey) {
+		return c, nil
+	}
+
+	dec, err := mapstructure.NewDecoder(
+		&mapstructure.DecoderConfig{
+			WeaklyTypedInput: true,
+			Result:           &c,
+			DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
+		},
+	)
+	if err != nil {
+		return c, err
+	}
+
+	if err := dec.Decode(cfg.GetStringMap(commentsConfigKey)); err != nil {
+		return c, errors.Wrap(err, "failed to decode comments config")
+	}
+
+	seen := make(map[string]bool)
+
+	for i, s := range c.Sources {
+		if s.Name == "" {
+			return c, errors.New("comments: all sources must have a name")
+		}
+		s.Name = strings.ToLower(s.Name)
+		if seen[s.Name] {
+			return c, errors.Errorf("comments: duplicate source name %q", s.Name)
+		}
+		seen[s.Name] = true
+
+		s.Type = strings.ToLower(s.Type)
+		switch s.Type {
+		case TypeGitHub:
+			if len(strings.Split(s.Repository, "/")) != 2 {
+				return c, errors.Errorf("comments: source %q must have a repository on the form owner/name", s.Name)
+			}
+			if s.URL == "" {
+				s.URL = defaultGitHubURL
+			}
+		case TypeMastodon:
+		case TypeJSON:
+			if !strings.Contains(s.URL, ":id") {
+				return c, errors.Errorf("comments: the URL of source %q must contain an :id placeholder", s.Name)
+			}
+		default:
+			return c, errors.Errorf("comments: invalid type %q for source %q, must be one of github, mastodon or json", s.Type, s.Name)
+		}
+
+		if s.URL != "" {
+			u, err := url.Parse(s.URL)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+				return c, errors.Errorf("comments: invalid URL %q for source %q", s.URL, s.Name)
+			}
+		}
+
+		c.Sources[i] = s
+	}
+
+	return c, nil
+}
+
+func init() {
+	configschema.AddSection(commentsConfigKey, DefaultConfig)
+}
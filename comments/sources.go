@@ -0,0 +1,242 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package comments
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const githubDiscussionQuery = `query($owner: String!, $name: String!, $number: Int!) {
+  repository(owner: $owner, name: $name) {
+    discussion(number: $number) {
+      comments(first: 100) {
+        nodes {
+          ...comment
+          replies(first: 100) {
+            nodes {
+              ...comment
+            }
+          }
+        }
+      }
+    }
+  }
+}
+
+fragment comment on DiscussionComment {
+  id
+  url
+  bodyHTML
+  createdAt
+  author {
+    login
+    url
+    avatarUrl
+  }
+}`
+
+type githubComment struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	BodyHTML  string    `json:"bodyHTML"`
+	CreatedAt time.Time `json:"createdAt"`
+	Author    struct {
+		Login     string `json:"login"`
+		URL       string `json:"url"`
+		AvatarURL string `json:"avatarUrl"`
+	} `json:"author"`
+	Replies struct {
+		Nodes []githubComment `json:"nodes"`
+	} `json:"replies"`
+}
+
+func (g githubComment) toComment() Comment {
+	c := Comment{
+		ID:      g.ID,
+		URL:     g.URL,
+		Content: g.BodyHTML,
+		Date:    g.CreatedAt,
+		Author: Author{
+			Name:   g.Author.Login,
+			URL:    g.Author.URL,
+			Avatar: g.Author.AvatarURL,
+		},
+	}
+	for _, r := range g.Replies.Nodes {
+		c.Replies = append(c.Replies, r.toComment())
+	}
+	return c
+}
+
+func (c *Client) fetchGitHub(s SourceConfig, id string) ([]Comment, error) {
+	number, err := strconv.Atoi(strings.TrimPrefix(id, "#"))
+	if err != nil {
+		return nil, errors.Errorf("invalid discussion number %q", id)
+	}
+
+	token := s.Token
+	if token == "" {
+		token = os.Getenv("GITHUB_TOKEN")
+	}
+	if token == "" {
+		return nil, errors.New("the GitHub GraphQL API requires a token, set it in the config or in the GITHUB_TOKEN environment variable")
+	}
+
+	parts := strings.Split(s.Repository, "/")
+
+	body, err := json.Marshal(map[string]interface{}{
+		"query": githubDiscussionQuery,
+		"variables": map[string]interface{}{
+			"owner":  parts[0],
+			"name":   parts[1],
+			"number": number,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", s.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var resp struct {
+		Data struct {
+			Repository struct {
+				Discussion *struct {
+					Comments struct {
+						Nodes []githubComment `json:"nodes"`
+					} `json:"comments"`
+				} `json:"discussion"`
+			} `json:"repository"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+
+	if err := c.do(req, token, &resp); err != nil {
+		return nil, err
+	}
+
+	if len(resp.Errors) > 0 {
+		return nil, errors.New(resp.Errors[0].Message)
+	}
+	if resp.Data.Repository.Discussion == nil {
+		return nil, errors.Errorf("discussion %d not found in %s", number, s.Repository)
+	}
+
+	var comments []Comment
+	for _, n := range resp.Data.Repository.Discussion.Comments.Nodes {
+		comments = append(comments, n.toComment())
+	}
+
+	return comments, nil
+}
+
+type mastodonStatus struct {
+	ID          string    `json:"id"`
+	InReplyToID string    `json:"in_reply_to_id"`
+	URL         string    `json:"url"`
+	Content     string    `json:"content"`
+	CreatedAt   time.Time `json:"created_at"`
+	Account     struct {
+		DisplayName string `json:"display_name"`
+		Acct        string `json:"acct"`
+		URL         string `json:"url"`
+		Avatar      string `json:"avatar"`
+	} `json:"account"`
+}
+
+// fetchMastodon fetches the replies to the status with the URL id,
+// e.g. https://mastodon.social/@user/106391081048901234.
+func (c *Client) fetchMastodon(s SourceConfig, id string) ([]Comment, error) {
+	u, err := url.Parse(id)
+	if err != nil || u.Host == "" {
+		return nil, errors.Errorf("invalid status URL %q", id)
+	}
+	statusID := path.Base(u.Path)
+
+	req, err := http.NewRequest("GET", u.Scheme+"://"+u.Host+"/api/v1/statuses/"+url.PathEscape(statusID)+"/context", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Descendants []mastodonStatus `json:"descendants"`
+	}
+
+	if err := c.do(req, s.Token, &resp); err != nil {
+		return nil, err
+	}
+
+	children := make(map[string][]mastodonStatus)
+	for _, st := range resp.Descendants {
+		children[st.InReplyToID] = append(children[st.InReplyToID], st)
+	}
+
+	var build func(parentID string) []Comment
+	build = func(parentID string) []Comment {
+		var comments []Comment
+		for _, st := range children[parentID] {
+			name := st.Account.DisplayName
+			if name == "" {
+				name = st.Account.Acct
+			}
+			comments = append(comments, Comment{
+				ID:      st.ID,
+				URL:     st.URL,
+				Content: st.Content,
+				Date:    st.CreatedAt,
+				Author: Author{
+					Name:   name,
+					URL:    st.Account.URL,
+					Avatar: st.Account.Avatar,
+				},
+				Replies: build(st.ID),
+			})
+		}
+		return comments
+	}
+
+	return build(statusID), nil
+}
+
+// fetchJSON fetches comments in Hugo's format from the source URL, with
+// :id replaced.
+func (c *Client) fetchJSON(s SourceConfig, id string) ([]Comment, error) {
+	req, err := http.NewRequest("GET", strings.Replace(s.URL, ":id", url.PathEscape(id), -1), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var comments []Comment
+	if err := c.do(req, s.Token, &comments); err != nil {
+		return nil, err
+	}
+
+	return comments, nil
+}
@@ -22,4 +22,9 @@ const (
 	// IDs for remote errors in tpl/data.
 	ErrRemoteGetJSON = "error-remote-getjson"
 	ErrRemoteGetCSV  = "error-remote-getcsv"
+	ErrRemoteGraphQL = "error-remote-graphql"
+	ErrRemoteGetFeed = "error-remote-getfeed"
+
+	// ID for remote errors in tpl/comments.
+	ErrRemoteGetComments = "error-remote-getcomments"
 )
@@ -0,0 +1,181 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package herrors
+
+import (
+	"github.com/gohugoio/hugo/common/text"
+)
+
+// The codes identifying the class of a build error. These are stable and
+// meant to be used by editors, CI systems etc., so they must never change.
+const (
+	// The error class could not be determined.
+	CodeUnknown = "UNKNOWN"
+
+	// Failed to load the site configuration.
+	CodeConfig = "CONFIG"
+
+	// Failed to parse a template.
+	CodeTemplateParse = "TEMPLATE_PARSE"
+
+	// Failed to execute a template.
+	CodeTemplateExecute = "TEMPLATE_EXECUTE"
+
+	// Failed to parse the front matter of a content file.
+	CodeFrontMatter = "FRONT_MATTER"
+
+	// Failed to parse a content file.
+	CodeContentParse = "CONTENT_PARSE"
+
+	// Failed to extract or render a shortcode.
+	CodeShortcode = "SHORTCODE"
+
+	// Failed to load a data file.
+	CodeData = "DATA"
+
+	// Failed to load a translation file.
+	CodeI18n = "I18N"
+
+	// Failed to transform a resource, e.g. with PostCSS or ToCSS.
+	CodeResourceTransform = "RESOURCE_TRANSFORM"
+
+	// A ref or relref could not be resolved.
+	CodeRefNotFound = "REF_NOT_FOUND"
+
+	// An error logged with no specific class, e.g. with errorf in a template.
+	CodeLogged = "LOGGED"
+)
+
+// ErrorCoder is implemented by errors with a code identifying their class.
+type ErrorCoder interface {
+	ErrorCode() string
+}
+
+var _ causer = (*codedError)(nil)
+
+type codedError struct {
+	code  string
+	cause error
+}
+
+func (e *codedError) Error() string {
+	return e.cause.Error()
+}
+
+func (e *codedError) Cause() error {
+	return e.cause
+}
+
+func (e *codedError) ErrorCode() string {
+	return e.code
+}
+
+// WithCode adds the given code, e.g. CodeTemplateParse, to err.
+// It returns nil if err is nil.
+func WithCode(code string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &codedError{code: code, cause: err}
+}
+
+var _ causer = (*pageError)(nil)
+
+type pageError struct {
+	path  string
+	cause error
+}
+
+func (e *pageError) Error() string {
+	return e.cause.Error()
+}
+
+func (e *pageError) Cause() error {
+	return e.cause
+}
+
+// WithPage adds the path of the page the error relates to to err.
+// It returns nil if err is nil.
+func WithPage(path string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &pageError{path: path, cause: err}
+}
+
+// ErrorCode returns the code of the given error, CodeUnknown if not set.
+// If there are more codes in the error chain, the innermost, most specific,
+// is returned.
+func ErrorCode(err error) string {
+	code := CodeUnknown
+	walkErr(err, func(err error) {
+		if c, ok := err.(ErrorCoder); ok {
+			code = c.ErrorCode()
+		}
+	})
+	return code
+}
+
+// ErrorInfo is a machine-readable description of a build error.
+type ErrorInfo struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+
+	// The file and position of the error, if known.
+	File   string `json:"file,omitempty"`
+	Line   int    `json:"line,omitempty"`
+	Column int    `json:"column,omitempty"`
+
+	// The path of the page the error relates to, if known.
+	Page string `json:"page,omitempty"`
+}
+
+// NewErrorInfo creates a new ErrorInfo from err.
+func NewErrorInfo(err error) ErrorInfo {
+	info := ErrorInfo{
+		Code:    ErrorCode(err),
+		Message: err.Error(),
+	}
+
+	var pos text.Position
+	if fe := UnwrapErrorWithFileContext(err); fe != nil {
+		pos = fe.Position()
+	} else if fe := UnwrapFileError(err); fe != nil {
+		pos = fe.Position()
+	}
+	info.File, info.Line, info.Column = pos.Filename, pos.LineNumber, pos.ColumnNumber
+
+	walkErr(err, func(err error) {
+		if pe, ok := err.(*pageError); ok && info.Page == "" {
+			info.Page = pe.path
+		}
+	})
+
+	return info
+}
+
+// walkErr calls fn for err and all the errors it wraps.
+func walkErr(err error, fn func(err error)) {
+	for err != nil {
+		fn(err)
+		switch v := err.(type) {
+		case causer:
+			err = v.Cause()
+		case interface{ Unwrap() error }:
+			err = v.Unwrap()
+		default:
+			return
+		}
+	}
+}
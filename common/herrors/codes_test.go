@@ -0,0 +1,48 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package herrors
+
+import (
+	"fmt"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/pkg/errors"
+)
+
+func TestErrorCode(t *testing.T) {
+	c := qt.New(t)
+
+	c.Assert(ErrorCode(errors.New("foo")), qt.Equals, CodeUnknown)
+	c.Assert(WithCode(CodeData, nil), qt.IsNil)
+
+	err := WithCode(CodeTemplateExecute, errors.Wrap(WithCode(CodeResourceTransform, errors.New("foo")), "bar"))
+	c.Assert(err.Error(), qt.Equals, "bar: foo")
+	c.Assert(ErrorCode(err), qt.Equals, CodeResourceTransform)
+	c.Assert(ErrorCode(fmt.Errorf("wrapped: %w", WithCode(CodeConfig, errors.New("foo")))), qt.Equals, CodeConfig)
+}
+
+func TestNewErrorInfo(t *testing.T) {
+	c := qt.New(t)
+
+	fe := NewFileError("md", -1, 3, 5, WithCode(CodeFrontMatter, errors.New("invalid")))
+	info := NewErrorInfo(errors.Wrap(WithPage("post/p1.md", fe), "failed"))
+	c.Assert(info, qt.DeepEquals, ErrorInfo{
+		Code:    CodeFrontMatter,
+		Message: "failed: invalid",
+		Line:    3,
+		Column:  5,
+		Page:    "post/p1.md",
+	})
+}
@@ -0,0 +1,54 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package herrors
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// TemplateFrame is a template in a template call stack.
+type TemplateFrame struct {
+	// The template name, e.g. "partials/header.html".
+	Name string
+
+	// The position of the failing action in the template.
+	Line   int
+	Column int
+
+	// The action being executed, e.g. `partial "header.html" .`.
+	Action string
+}
+
+var templateFrameRe = regexp.MustCompile(`template: ([^:\s]+):(\d+):(\d+): executing "[^"]*" at <(.*?)>:`)
+
+// TemplateCallStack extracts the template call stack from a template
+// execution error, outermost template first. Go's template package does
+// not keep the call stack, but it is recorded in the error message, one
+// "template: name:line:col: executing..." for every call to e.g. partial
+// or template that failed.
+func TemplateCallStack(err error) []TemplateFrame {
+	if err == nil {
+		return nil
+	}
+
+	var frames []TemplateFrame
+	for _, m := range templateFrameRe.FindAllStringSubmatch(err.Error(), -1) {
+		line, _ := strconv.Atoi(m[2])
+		col, _ := strconv.Atoi(m[3])
+		frames = append(frames, TemplateFrame{Name: m[1], Line: line, Column: col, Action: m[4]})
+	}
+
+	return frames
+}
@@ -0,0 +1,35 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package herrors
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/pkg/errors"
+)
+
+func TestTemplateCallStack(t *testing.T) {
+	c := qt.New(t)
+
+	err := errors.Wrap(errors.New(`template: _default/single.html:5:3: executing "main" at <partial "header.html" .>: error calling partial: template: partials/header.html:2:12: executing "partials/header.html" at <.Foo>: can't evaluate field Foo in type *hugolib.pageState`), "render failed")
+
+	c.Assert(TemplateCallStack(err), qt.DeepEquals, []TemplateFrame{
+		{Name: "_default/single.html", Line: 5, Column: 3, Action: `partial "header.html" .`},
+		{Name: "partials/header.html", Line: 2, Column: 12, Action: ".Foo"},
+	})
+
+	c.Assert(TemplateCallStack(errors.New(`template: _default/single.html:1: function "foo" not defined`)), qt.HasLen, 0)
+	c.Assert(TemplateCallStack(nil), qt.IsNil)
+}
@@ -0,0 +1,61 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package htime provides the clock used in a build, which is fixed in
+// reproducible builds.
+package htime
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Clock tells the current time.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock is the clock of the system.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// FixedClock returns a Clock that's always at t.
+func FixedClock(t time.Time) Clock {
+	return fixedClock(t)
+}
+
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time {
+	return time.Time(c)
+}
+
+// ParseSourceDateEpoch parses s, the number of seconds since the Unix epoch
+// as set in the SOURCE_DATE_EPOCH environment variable, see
+// https://reproducible-builds.org/specs/source-date-epoch/. The time is in
+// UTC.
+func ParseSourceDateEpoch(s string) (time.Time, error) {
+	secs, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil || secs < 0 {
+		return time.Time{}, errors.Errorf("invalid SOURCE_DATE_EPOCH %q, must be the number of seconds since 1970-01-01 00:00:00 UTC", s)
+	}
+	return time.Unix(secs, 0).UTC(), nil
+}
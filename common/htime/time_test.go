@@ -0,0 +1,46 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package htime
+
+import (
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestParseSourceDateEpoch(t *testing.T) {
+	c := qt.New(t)
+
+	d, err := ParseSourceDateEpoch("1625097600")
+	c.Assert(err, qt.IsNil)
+	c.Assert(d, qt.Equals, time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC))
+
+	d, err = ParseSourceDateEpoch(" 0\n")
+	c.Assert(err, qt.IsNil)
+	c.Assert(d.Unix(), qt.Equals, int64(0))
+
+	for _, s := range []string{"", "-1", "2021-07-01", "1.5"} {
+		_, err = ParseSourceDateEpoch(s)
+		c.Assert(err, qt.ErrorMatches, "invalid SOURCE_DATE_EPOCH.*")
+	}
+}
+
+func TestClock(t *testing.T) {
+	c := qt.New(t)
+
+	d := time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)
+	c.Assert(FixedClock(d).Now(), qt.Equals, d)
+	c.Assert(SystemClock.Now().After(d), qt.IsTrue)
+}
@@ -115,17 +115,24 @@ func GetExecEnviron(workDir string, cfg config.Provider, fs afero.Fs) []string {
 func GetDependencyList() []string {
 	var deps []string
 
-	formatDep := func(path, version string) string {
-		return fmt.Sprintf("%s=%q", path, version)
+	for path, version := range GetDependencies() {
+		deps = append(deps, fmt.Sprintf("%s=%q", path, version))
 	}
 
+	sort.Strings(deps)
+
+	return deps
+}
+
+// GetDependencies returns the version of the dependencies in
+// GetDependencyList by package.
+func GetDependencies() map[string]string {
+	deps := make(map[string]string)
+
 	if IsExtended {
-		deps = append(
-			deps,
-			// TODO(bep) consider adding a DepsNonGo() method to these upstream projects.
-			formatDep("github.com/sass/libsass", "3.6.5"),
-			formatDep("github.com/webmproject/libwebp", "v1.2.0"),
-		)
+		// TODO(bep) consider adding a DepsNonGo() method to these upstream projects.
+		deps["github.com/sass/libsass"] = "3.6.5"
+		deps["github.com/webmproject/libwebp"] = "v1.2.0"
 	}
 
 	bi, ok := debug.ReadBuildInfo()
@@ -134,11 +141,9 @@ func GetDependencyList() []string {
 	}
 
 	for _, dep := range bi.Deps {
-		deps = append(deps, formatDep(dep.Path, dep.Version))
+		deps[dep.Path] = dep.Version
 	}
 
-	sort.Strings(deps)
-
 	return deps
 }
 
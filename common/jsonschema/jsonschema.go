@@ -0,0 +1,700 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package jsonschema validates values against a JSON Schema.
+//
+// A subset of JSON Schema (draft 2019-09) is supported: the type, enum,
+// const, properties, required, additionalProperties, propertyNames, items,
+// minItems, maxItems, uniqueItems, minLength, maxLength, pattern, format,
+// minimum, maximum, exclusiveMinimum, exclusiveMaximum, multipleOf, allOf,
+// anyOf, oneOf and not keywords, and $ref to definitions in the same
+// schema. The annotation keywords, e.g. title and description, are
+// accepted but not validated. A schema using any other keyword fails to
+// compile, so it never passes data it was meant to reject.
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cast"
+)
+
+// Schema is a compiled JSON Schema.
+type Schema struct {
+	root *node
+}
+
+// ValidationError describes a value not matching its schema.
+type ValidationError struct {
+	// The path to the value, e.g. ["products", "2", "price"].
+	Path []string
+
+	Message string
+}
+
+// Pointer returns the path to the value as a JSON Pointer,
+// e.g. "/products/2/price".
+func (e *ValidationError) Pointer() string {
+	if len(e.Path) == 0 {
+		return "/"
+	}
+	parts := make([]string, len(e.Path))
+	for i, p := range e.Path {
+		parts[i] = strings.NewReplacer("~", "~0", "/", "~1").Replace(p)
+	}
+	return "/" + strings.Join(parts, "/")
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Pointer(), e.Message)
+}
+
+type node struct {
+	// Set for the boolean schemas true and false.
+	always *bool
+
+	types []string
+	enum  []interface{}
+
+	hasConst bool
+	constVal interface{}
+
+	properties           map[string]*node
+	required             []string
+	additionalProperties *node
+	propertyNames        *node
+
+	items                 *node
+	minItems, maxItems    *int
+	uniqueItems           bool
+	minLength, maxLength  *int
+	pattern               *regexp.Regexp
+	format                string
+	minimum, maximum      *float64
+	exclusiveMin, exclMax *float64
+	multipleOf            *float64
+	allOf, anyOf, oneOf   []*node
+	not                   *node
+	ref                   string
+	resolved              *node
+}
+
+var schemaTypes = map[string]bool{
+	"object": true, "array": true, "string": true, "number": true,
+	"integer": true, "boolean": true, "null": true,
+}
+
+// annotations are the keywords that do not affect validation.
+var annotations = map[string]bool{
+	"$schema": true, "$id": true, "$comment": true, "title": true,
+	"description": true, "default": true, "examples": true, "deprecated": true,
+	"readOnly": true, "writeOnly": true, "definitions": true, "$defs": true,
+}
+
+// Compile compiles the JSON Schema in m, e.g. decoded from a JSON file.
+func Compile(m map[string]interface{}) (*Schema, error) {
+	c := &compiler{root: m, refs: make(map[string]*node)}
+	root, err := c.compile(m, "#")
+	if err != nil {
+		return nil, err
+	}
+	if err := c.resolve(); err != nil {
+		return nil, err
+	}
+	return &Schema{root: root}, nil
+}
+
+type compiler struct {
+	root  map[string]interface{}
+	refs  map[string]*node
+	nodes []*node
+}
+
+func (c *compiler) compile(v interface{}, ptr string) (*node, error) {
+	n := &node{}
+	c.nodes = append(c.nodes, n)
+
+	if b, ok := v.(bool); ok {
+		n.always = &b
+		return n, nil
+	}
+
+	m, ok := toStringMap(v)
+	if !ok {
+		return nil, errors.Errorf("%s: schema must be an object or a boolean, got %T", ptr, v)
+	}
+
+	var err error
+
+	for k, vv := range m {
+		kptr := ptr + "/" + k
+		switch k {
+		case "type":
+			switch t := vv.(type) {
+			case string:
+				n.types = []string{t}
+			default:
+				if n.types, err = cast.ToStringSliceE(t); err != nil {
+					return nil, errors.Errorf("%s: must be a string or a list of strings", kptr)
+				}
+			}
+			for _, t := range n.types {
+				if !schemaTypes[t] {
+					return nil, errors.Errorf("%s: invalid type %q", kptr, t)
+				}
+			}
+		case "enum":
+			rv := reflect.ValueOf(vv)
+			if rv.Kind() != reflect.Slice {
+				return nil, errors.Errorf("%s: must be a list", kptr)
+			}
+			for i := 0; i < rv.Len(); i++ {
+				n.enum = append(n.enum, rv.Index(i).Interface())
+			}
+		case "const":
+			n.hasConst = true
+			n.constVal = vv
+		case "properties":
+			pm, ok := toStringMap(vv)
+			if !ok {
+				return nil, errors.Errorf("%s: must be an object", kptr)
+			}
+			n.properties = make(map[string]*node)
+			for name, ps := range pm {
+				if n.properties[name], err = c.compile(ps, kptr+"/"+name); err != nil {
+					return nil, err
+				}
+			}
+		case "required":
+			if n.required, err = cast.ToStringSliceE(vv); err != nil {
+				return nil, errors.Errorf("%s: must be a list of strings", kptr)
+			}
+		case "additionalProperties":
+			if n.additionalProperties, err = c.compile(vv, kptr); err != nil {
+				return nil, err
+			}
+		case "propertyNames":
+			if n.propertyNames, err = c.compile(vv, kptr); err != nil {
+				return nil, err
+			}
+		case "items":
+			if n.items, err = c.compile(vv, kptr); err != nil {
+				return nil, err
+			}
+		case "minItems", "maxItems", "minLength", "maxLength":
+			i, err := cast.ToIntE(vv)
+			if err != nil || i < 0 {
+				return nil, errors.Errorf("%s: must be a non-negative integer", kptr)
+			}
+			switch k {
+			case "minItems":
+				n.minItems = &i
+			case "maxItems":
+				n.maxItems = &i
+			case "minLength":
+				n.minLength = &i
+			case "maxLength":
+				n.maxLength = &i
+			}
+		case "uniqueItems":
+			n.uniqueItems = cast.ToBool(vv)
+		case "pattern":
+			if n.pattern, err = regexp.Compile(cast.ToString(vv)); err != nil {
+				return nil, errors.Wrapf(err, "%s", kptr)
+			}
+		case "format":
+			n.format = cast.ToString(vv)
+		case "minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum", "multipleOf":
+			f, ok := toFloat(vv)
+			if !ok {
+				return nil, errors.Errorf("%s: must be a number", kptr)
+			}
+			switch k {
+			case "minimum":
+				n.minimum = &f
+			case "maximum":
+				n.maximum = &f
+			case "exclusiveMinimum":
+				n.exclusiveMin = &f
+			case "exclusiveMaximum":
+				n.exclMax = &f
+			case "multipleOf":
+				if f <= 0 {
+					return nil, errors.Errorf("%s: must be > 0", kptr)
+				}
+				n.multipleOf = &f
+			}
+		case "allOf", "anyOf", "oneOf":
+			rv := reflect.ValueOf(vv)
+			if rv.Kind() != reflect.Slice || rv.Len() == 0 {
+				return nil, errors.Errorf("%s: must be a non-empty list", kptr)
+			}
+			var nodes []*node
+			for i := 0; i < rv.Len(); i++ {
+				nn, err := c.compile(rv.Index(i).Interface(), kptr+"/"+strconv.Itoa(i))
+				if err != nil {
+					return nil, err
+				}
+				nodes = append(nodes, nn)
+			}
+			switch k {
+			case "allOf":
+				n.allOf = nodes
+			case "anyOf":
+				n.anyOf = nodes
+			case "oneOf":
+				n.oneOf = nodes
+			}
+		case "not":
+			if n.not, err = c.compile(vv, kptr); err != nil {
+				return nil, err
+			}
+		case "$ref":
+			n.ref = cast.ToString(vv)
+			if n.ref != "#" && !strings.HasPrefix(n.ref, "#/") {
+				return nil, errors.Errorf("%s: only references within the schema, e.g. \"#/definitions/product\", are supported, got %q", kptr, n.ref)
+			}
+		default:
+			if !annotations[k] {
+				return nil, errors.Errorf("%s: unsupported keyword %q", kptr, k)
+			}
+		}
+	}
+
+	return n, nil
+}
+
+// resolve resolves the $ref in all nodes.
+func (c *compiler) resolve() error {
+	// Compiling a referenced schema may add more nodes.
+	for i := 0; i < len(c.nodes); i++ {
+		n := c.nodes[i]
+		if n.ref == "" {
+			continue
+		}
+		if r, found := c.refs[n.ref]; found {
+			n.resolved = r
+			continue
+		}
+		v, err := lookupPointer(c.root, n.ref)
+		if err != nil {
+			return err
+		}
+		r, err := c.compile(v, n.ref)
+		if err != nil {
+			return err
+		}
+		c.refs[n.ref] = r
+		n.resolved = r
+	}
+	return nil
+}
+
+func lookupPointer(root map[string]interface{}, ref string) (interface{}, error) {
+	var v interface{} = root
+	for _, part := range strings.Split(strings.TrimPrefix(ref, "#"), "/")[1:] {
+		part = strings.NewReplacer("~1", "/", "~0", "~").Replace(part)
+		switch vv := v.(type) {
+		case []interface{}:
+			i, err := strconv.Atoi(part)
+			if err != nil || i < 0 || i >= len(vv) {
+				return nil, errors.Errorf("$ref %q not found", ref)
+			}
+			v = vv[i]
+		default:
+			m, ok := toStringMap(v)
+			if !ok {
+				return nil, errors.Errorf("$ref %q not found", ref)
+			}
+			if v, ok = m[part]; !ok {
+				return nil, errors.Errorf("$ref %q not found", ref)
+			}
+		}
+	}
+	return v, nil
+}
+
+// Validate validates v against the schema. The errors are sorted by path.
+func (s *Schema) Validate(v interface{}) []*ValidationError {
+	var errs []*ValidationError
+	s.root.validate(v, nil, &errs)
+
+	sort.SliceStable(errs, func(i, j int) bool {
+		return comparePaths(errs[i].Path, errs[j].Path) < 0
+	})
+
+	return errs
+}
+
+func (n *node) validate(v interface{}, path []string, errs *[]*ValidationError) {
+	addErr := func(format string, args ...interface{}) {
+		*errs = append(*errs, &ValidationError{Path: append([]string(nil), path...), Message: fmt.Sprintf(format, args...)})
+	}
+
+	if n.always != nil {
+		if !*n.always {
+			addErr("is not allowed")
+		}
+		return
+	}
+
+	if n.resolved != nil {
+		n.resolved.validate(v, path, errs)
+	}
+
+	typ := typeOf(v)
+
+	if len(n.types) > 0 {
+		var ok bool
+		for _, t := range n.types {
+			if t == typ || (t == "integer" && typ == "number" && isInteger(v)) || (t == "number" && typ == "integer") {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			addErr("must be of type %s, got %s", strings.Join(n.types, " or "), typ)
+			return
+		}
+	}
+
+	if len(n.enum) > 0 {
+		var ok bool
+		for _, e := range n.enum {
+			if equal(v, e) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			addErr("must be one of %s", formatValues(n.enum))
+		}
+	}
+
+	if n.hasConst && !equal(v, n.constVal) {
+		addErr("must be %s", formatValues([]interface{}{n.constVal}))
+	}
+
+	switch typ {
+	case "object":
+		n.validateObject(v, path, errs, addErr)
+	case "array":
+		n.validateArray(v, path, errs, addErr)
+	case "string":
+		n.validateString(v, addErr)
+	case "number", "integer":
+		n.validateNumber(v, addErr)
+	}
+
+	for _, nn := range n.allOf {
+		nn.validate(v, path, errs)
+	}
+
+	if len(n.anyOf) > 0 {
+		var ok bool
+		for _, nn := range n.anyOf {
+			if nn.matches(v, path) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			addErr("must match at least one of the schemas in anyOf")
+		}
+	}
+
+	if len(n.oneOf) > 0 {
+		var count int
+		for _, nn := range n.oneOf {
+			if nn.matches(v, path) {
+				count++
+			}
+		}
+		if count != 1 {
+			addErr("must match exactly one of the schemas in oneOf, matched %d", count)
+		}
+	}
+
+	if n.not != nil && n.not.matches(v, path) {
+		addErr("must not match the schema in not")
+	}
+}
+
+func (n *node) matches(v interface{}, path []string) bool {
+	var errs []*ValidationError
+	n.validate(v, path, &errs)
+	return len(errs) == 0
+}
+
+func (n *node) validateObject(v interface{}, path []string, errs *[]*ValidationError, addErr func(string, ...interface{})) {
+	m, _ := toStringMap(v)
+
+	for _, name := range n.required {
+		if _, found := m[name]; !found {
+			*errs = append(*errs, &ValidationError{Path: appendPath(path, name), Message: "is required"})
+		}
+	}
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		vv := m[k]
+		if n.propertyNames != nil && !n.propertyNames.matches(k, path) {
+			*errs = append(*errs, &ValidationError{Path: appendPath(path, k), Message: "is not an allowed property name"})
+		}
+		if ps, found := n.properties[k]; found {
+			ps.validate(vv, appendPath(path, k), errs)
+			continue
+		}
+		if n.additionalProperties != nil {
+			if a := n.additionalProperties.always; a != nil && !*a {
+				*errs = append(*errs, &ValidationError{Path: appendPath(path, k), Message: "is not allowed"})
+				continue
+			}
+			n.additionalProperties.validate(vv, appendPath(path, k), errs)
+		}
+	}
+}
+
+func (n *node) validateArray(v interface{}, path []string, errs *[]*ValidationError, addErr func(string, ...interface{})) {
+	rv := reflect.ValueOf(v)
+	l := rv.Len()
+
+	if n.minItems != nil && l < *n.minItems {
+		addErr("must have at least %d items, got %d", *n.minItems, l)
+	}
+	if n.maxItems != nil && l > *n.maxItems {
+		addErr("must have at most %d items, got %d", *n.maxItems, l)
+	}
+
+	if n.uniqueItems {
+	outer:
+		for i := 0; i < l; i++ {
+			for j := 0; j < i; j++ {
+				if equal(rv.Index(i).Interface(), rv.Index(j).Interface()) {
+					*errs = append(*errs, &ValidationError{Path: appendPath(path, strconv.Itoa(i)), Message: fmt.Sprintf("is a duplicate of item %d", j)})
+					break outer
+				}
+			}
+		}
+	}
+
+	if n.items != nil {
+		for i := 0; i < l; i++ {
+			n.items.validate(rv.Index(i).Interface(), appendPath(path, strconv.Itoa(i)), errs)
+		}
+	}
+}
+
+func (n *node) validateString(v interface{}, addErr func(string, ...interface{})) {
+	var s string
+	switch vv := v.(type) {
+	case time.Time:
+		s = vv.Format(time.RFC3339)
+	default:
+		s = cast.ToString(v)
+	}
+
+	l := utf8.RuneCountInString(s)
+	if n.minLength != nil && l < *n.minLength {
+		addErr("must be at least %d characters long", *n.minLength)
+	}
+	if n.maxLength != nil && l > *n.maxLength {
+		addErr("must be at most %d characters long", *n.maxLength)
+	}
+	if n.pattern != nil && !n.pattern.MatchString(s) {
+		addErr("must match the pattern %q", n.pattern.String())
+	}
+
+	if n.format != "" && !isFormat(v, s, n.format) {
+		addErr("must be a valid %s, got %q", n.format, s)
+	}
+}
+
+func (n *node) validateNumber(v interface{}, addErr func(string, ...interface{})) {
+	f, _ := toFloat(v)
+
+	if n.minimum != nil && f < *n.minimum {
+		addErr("must be >= %v, got %v", *n.minimum, f)
+	}
+	if n.maximum != nil && f > *n.maximum {
+		addErr("must be <= %v, got %v", *n.maximum, f)
+	}
+	if n.exclusiveMin != nil && f <= *n.exclusiveMin {
+		addErr("must be > %v, got %v", *n.exclusiveMin, f)
+	}
+	if n.exclMax != nil && f >= *n.exclMax {
+		addErr("must be < %v, got %v", *n.exclMax, f)
+	}
+	if n.multipleOf != nil {
+		q := f / *n.multipleOf
+		if math.Abs(q-math.Round(q)) > 1e-9 {
+			addErr("must be a multiple of %v, got %v", *n.multipleOf, f)
+		}
+	}
+}
+
+func isFormat(v interface{}, s, format string) bool {
+	switch format {
+	case "date-time":
+		if _, ok := v.(time.Time); ok {
+			return true
+		}
+		_, err := time.Parse(time.RFC3339, s)
+		return err == nil
+	case "date":
+		if _, ok := v.(time.Time); ok {
+			return true
+		}
+		_, err := time.Parse("2006-01-02", s)
+		return err == nil
+	case "email":
+		_, err := mail.ParseAddress(s)
+		return err == nil
+	case "uri":
+		u, err := url.Parse(s)
+		return err == nil && u.Scheme != ""
+	case "uri-reference":
+		_, err := url.Parse(s)
+		return err == nil
+	}
+	// Unknown formats are not validated.
+	return true
+}
+
+func typeOf(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string, time.Time:
+		return "string"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return "integer"
+	case float32, float64:
+		return "number"
+	}
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Map:
+		return "object"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func isInteger(v interface{}) bool {
+	f, ok := toFloat(v)
+	return ok && f == math.Trunc(f)
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch v.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return cast.ToFloat64(v), true
+	}
+	return 0, false
+}
+
+func toStringMap(v interface{}) (map[string]interface{}, bool) {
+	switch vv := v.(type) {
+	case map[string]interface{}:
+		return vv, true
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(vv))
+		for k, v := range vv {
+			m[cast.ToString(k)] = v
+		}
+		return m, true
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Map {
+		return nil, false
+	}
+	m := make(map[string]interface{}, rv.Len())
+	for _, k := range rv.MapKeys() {
+		m[cast.ToString(k.Interface())] = rv.MapIndex(k).Interface()
+	}
+	return m, true
+}
+
+// equal reports whether a and b are equal as JSON values, so 1 equals 1.0.
+func equal(a, b interface{}) bool {
+	if fa, ok := toFloat(a); ok {
+		fb, ok := toFloat(b)
+		return ok && fa == fb
+	}
+	ja, err1 := json.Marshal(a)
+	jb, err2 := json.Marshal(b)
+	return err1 == nil && err2 == nil && string(ja) == string(jb)
+}
+
+func formatValues(values []interface{}) string {
+	s := make([]string, len(values))
+	for i, v := range values {
+		b, err := json.Marshal(v)
+		if err != nil {
+			s[i] = fmt.Sprint(v)
+		} else {
+			s[i] = string(b)
+		}
+	}
+	return strings.Join(s, ", ")
+}
+
+func appendPath(path []string, s string) []string {
+	p := make([]string, len(path)+1)
+	copy(p, path)
+	p[len(path)] = s
+	return p
+}
+
+// comparePaths orders paths by their elements, with array indices in
+// numeric order.
+func comparePaths(a, b []string) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] == b[i] {
+			continue
+		}
+		ia, err1 := strconv.Atoi(a[i])
+		ib, err2 := strconv.Atoi(b[i])
+		if err1 == nil && err2 == nil {
+			if ia < ib {
+				return -1
+			}
+			return 1
+		}
+		if a[i] < b[i] {
+			return -1
+		}
+		return 1
+	}
+	return len(a) - len(b)
+}
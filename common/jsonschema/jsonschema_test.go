@@ -0,0 +1,167 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonschema
+
+import (
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/parser/metadecoders"
+)
+
+func TestValidate(t *testing.T) {
+	c := qt.New(t)
+
+	compile := func(c *qt.C, schema string) *Schema {
+		m, err := metadecoders.Default.UnmarshalToMap([]byte(schema), metadecoders.JSON)
+		c.Assert(err, qt.IsNil)
+		s, err := Compile(m)
+		c.Assert(err, qt.IsNil)
+		return s
+	}
+
+	errorStrings := func(errs []*ValidationError) []string {
+		var s []string
+		for _, err := range errs {
+			s = append(s, err.Error())
+		}
+		return s
+	}
+
+	s := compile(c, `{
+  "type": "object",
+  "required": ["products"],
+  "properties": {
+    "products": {
+      "type": "array",
+      "items": {"$ref": "#/definitions/product"}
+    }
+  },
+  "definitions": {
+    "product": {
+      "type": "object",
+      "required": ["name", "price"],
+      "additionalProperties": false,
+      "properties": {
+        "name": {"type": "string", "minLength": 1},
+        "price": {"type": "number", "minimum": 0},
+        "stock": {"type": "integer"},
+        "color": {"enum": ["red", "blue"]},
+        "released": {"type": "string", "format": "date"},
+        "tags": {"type": "array", "uniqueItems": true, "items": {"type": "string"}}
+      }
+    }
+  }
+}`)
+
+	c.Assert(s.Validate(map[string]interface{}{
+		"products": []interface{}{
+			map[string]interface{}{"name": "Shirt", "price": 32.5, "stock": 3, "color": "red", "tags": []interface{}{"a", "b"}},
+			map[string]interface{}{"name": "Hat", "price": 10, "released": time.Now()},
+		},
+	}), qt.HasLen, 0)
+
+	c.Assert(errorStrings(s.Validate(map[string]interface{}{})), qt.DeepEquals, []string{
+		"/products: is required",
+	})
+
+	c.Assert(errorStrings(s.Validate(map[string]interface{}{
+		"products": []interface{}{
+			map[string]interface{}{"name": "Shirt", "price": "32"},
+			map[string]interface{}{"name": "", "price": -1, "stock": 1.5, "size": "L"},
+			map[string]interface{}{"price": 1, "color": "green", "released": "yesterday", "tags": []interface{}{"a", "a"}},
+		},
+	})), qt.DeepEquals, []string{
+		"/products/0/price: must be of type number, got string",
+		"/products/1/name: must be at least 1 characters long",
+		"/products/1/price: must be >= 0, got -1",
+		"/products/1/size: is not allowed",
+		"/products/1/stock: must be of type integer, got number",
+		"/products/2/color: must be one of \"red\", \"blue\"",
+		"/products/2/name: is required",
+		"/products/2/released: must be a valid date, got \"yesterday\"",
+		"/products/2/tags/1: is a duplicate of item 0",
+	})
+
+	c.Run("Combinators", func(c *qt.C) {
+		s := compile(c, `{
+  "anyOf": [{"type": "string"}, {"type": "integer"}],
+  "not": {"const": 3}
+}`)
+		c.Assert(s.Validate("foo"), qt.HasLen, 0)
+		c.Assert(s.Validate(2), qt.HasLen, 0)
+		c.Assert(errorStrings(s.Validate(3)), qt.DeepEquals, []string{"/: must not match the schema in not"})
+		c.Assert(errorStrings(s.Validate(true)), qt.DeepEquals, []string{"/: must match at least one of the schemas in anyOf"})
+
+		s = compile(c, `{"oneOf": [{"type": "number"}, {"type": "integer"}]}`)
+		c.Assert(s.Validate(1.5), qt.HasLen, 0)
+		c.Assert(errorStrings(s.Validate(1)), qt.DeepEquals, []string{"/: must match exactly one of the schemas in oneOf, matched 2"})
+	})
+
+	c.Run("Recursive ref", func(c *qt.C) {
+		s := compile(c, `{
+  "type": "object",
+  "properties": {
+    "name": {"type": "string"},
+    "children": {"type": "array", "items": {"$ref": "#"}}
+  }
+}`)
+		c.Assert(errorStrings(s.Validate(map[string]interface{}{
+			"name": "a",
+			"children": []interface{}{
+				map[string]interface{}{"name": "b", "children": []interface{}{map[string]interface{}{"name": 32}}},
+			},
+		})), qt.DeepEquals, []string{"/children/0/children/0/name: must be of type string, got integer"})
+	})
+
+	c.Run("CSV", func(c *qt.C) {
+		s := compile(c, `{"type": "array", "items": {"type": "array", "minItems": 2, "items": {"type": "string", "pattern": "^[a-z]+$"}}}`)
+		c.Assert(errorStrings(s.Validate([][]string{{"a", "b"}, {"c"}, {"d", "E"}})), qt.DeepEquals, []string{
+			"/1: must have at least 2 items, got 1",
+			"/2/1: must match the pattern \"^[a-z]+$\"",
+		})
+	})
+
+	c.Run("Annotations", func(c *qt.C) {
+		s := compile(c, `{
+  "$schema": "https://json-schema.org/draft/2019-09/schema",
+  "title": "Product",
+  "description": "A product.",
+  "type": "object",
+  "properties": {"name": {"type": "string", "default": "", "examples": ["Shirt"]}}
+}`)
+		c.Assert(s.Validate(map[string]interface{}{"name": "Hat"}), qt.HasLen, 0)
+	})
+
+	c.Run("Invalid schema", func(c *qt.C) {
+		for _, invalid := range []string{
+			`{"type": "foo"}`,
+			`{"properties": []}`,
+			`{"pattern": "["}`,
+			`{"$ref": "other.json"}`,
+			`{"$ref": "#/definitions/missing"}`,
+			`{"type": "object", "minProperties": 1}`,
+			`{"if": {"type": "string"}, "then": {"minLength": 1}}`,
+			`{"properties": {"tags": {"contains": {"const": "a"}}}}`,
+			`{"patternProperties": {"^x-": {"type": "string"}}}`,
+			`{"definitions": {"product": {"dependentRequired": {"a": ["b"]}}}, "$ref": "#/definitions/product"}`,
+		} {
+			m, err := metadecoders.Default.UnmarshalToMap([]byte(invalid), metadecoders.JSON)
+			c.Assert(err, qt.IsNil)
+			_, err = Compile(m)
+			c.Assert(err, qt.Not(qt.IsNil), qt.Commentf(invalid))
+		}
+	})
+}
@@ -0,0 +1,199 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loggers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// The log formats.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+var (
+	formatMu  sync.RWMutex
+	logFormat = FormatText
+)
+
+// SetFormat sets the format, text or json, used by the loggers created
+// after this call, including the global loggers.
+func SetFormat(format string) error {
+	format = strings.ToLower(format)
+	switch format {
+	case "":
+		format = FormatText
+	case FormatText, FormatJSON:
+	default:
+		return errors.Errorf("invalid log format %q, must be %s or %s", format, FormatText, FormatJSON)
+	}
+
+	formatMu.Lock()
+	logFormat = format
+	formatMu.Unlock()
+
+	return nil
+}
+
+// IsJSON reports whether the logs are written as JSON.
+func IsJSON() bool {
+	formatMu.RLock()
+	defer formatMu.RUnlock()
+	return logFormat == FormatJSON
+}
+
+// Entry is a log line written in the JSON format.
+type Entry struct {
+	Time  string `json:"time"`
+	Level string `json:"level"`
+
+	// E.g. the external tool or the part of Hugo logging, if known.
+	Component string `json:"component,omitempty"`
+
+	// The language of the site logging, if known.
+	Lang string `json:"lang,omitempty"`
+
+	// The file, line and column the message refers to, if any.
+	File   string `json:"file,omitempty"`
+	Line   int    `json:"line,omitempty"`
+	Column int    `json:"column,omitempty"`
+
+	// The path of the page the message refers to, if any.
+	Page string `json:"page,omitempty"`
+
+	// The duration in milliseconds for timing messages.
+	Duration int `json:"durationMs,omitempty"`
+
+	Message string `json:"msg"`
+
+	// Any additional fields, e.g. the build stats.
+	Fields map[string]interface{} `json:"fields,omitempty"`
+}
+
+var (
+	logLineRe      = regexp.MustCompile(`^(?:(TRACE|DEBUG|INFO|WARN|ERROR|CRITICAL|FATAL) )?(?:\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2} )?`)
+	langRe         = regexp.MustCompile(`^\[([a-zA-Z][\w-]*)\] `)
+	componentRe    = regexp.MustCompile(`^([a-z][a-z0-9_.-]*): `)
+	fileRe         = regexp.MustCompile(`"([^"]+?):(\d+):(\d+)"`)
+	pageRe         = regexp.MustCompile(`page "([^"]+)"`)
+	durationRe     = regexp.MustCompile(` in (\d+) ms$`)
+	deprecationStr = " is deprecated and will be removed"
+	errorLineRe    = regexp.MustCompile(`(?m)^ERROR (?:\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2} )?`)
+)
+
+// NewEntry creates an Entry for msg logged with the given level, e.g. "warn".
+// The language, component, file position, page and duration are extracted
+// from the message on the forms used in Hugo's log messages, e.g.
+//     [en] REF_NOT_FOUND: Ref "foo": "/site/content/post.md:12:3": page not found
+func NewEntry(level, msg string) Entry {
+	e := Entry{
+		Time:    time.Now().Format(time.RFC3339),
+		Level:   strings.ToLower(level),
+		Message: msg,
+	}
+
+	rest := msg
+	if m := langRe.FindStringSubmatch(rest); m != nil {
+		e.Lang = m[1]
+		rest = rest[len(m[0]):]
+	}
+	if m := componentRe.FindStringSubmatch(rest); m != nil {
+		e.Component = m[1]
+	} else if strings.Contains(rest, deprecationStr) {
+		e.Component = "deprecation"
+	}
+	if m := fileRe.FindStringSubmatch(rest); m != nil {
+		e.File = m[1]
+		e.Line, _ = strconv.Atoi(m[2])
+		e.Column, _ = strconv.Atoi(m[3])
+	}
+	if m := pageRe.FindStringSubmatch(rest); m != nil {
+		e.Page = m[1]
+	}
+	if m := durationRe.FindStringSubmatch(rest); m != nil {
+		e.Duration, _ = strconv.Atoi(m[1])
+	}
+
+	return e
+}
+
+// Write writes e as a JSON line to w.
+func (e Entry) Write(w io.Writer) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+// jsonWriter converts the log lines written by the loggers to JSON.
+type jsonWriter struct {
+	w io.Writer
+}
+
+func (a jsonWriter) Write(p []byte) (n int, err error) {
+	line := ansiColorRe.ReplaceAll(p, nil)
+	m := logLineRe.FindSubmatch(line)
+	msg := string(bytes.TrimSpace(line[len(m[0]):]))
+	if msg == "" {
+		// E.g. an empty line written to separate the output.
+		return len(p), nil
+	}
+
+	level := "info"
+	if len(m[1]) > 0 {
+		level = string(m[1])
+	}
+
+	e := NewEntry(level, msg)
+	if len(m[1]) == 0 && e.Component == "deprecation" {
+		// Deprecations are printed without a level.
+		e.Level = "warn"
+	}
+
+	if err := e.Write(a.w); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// ErrorMessages splits the errors returned from Logger.Errors into messages,
+// one per logged error.
+func ErrorMessages(errs string) []string {
+	errs = ansiColorRe.ReplaceAllString(errs, "")
+	locs := errorLineRe.FindAllStringIndex(errs, -1)
+	var msgs []string
+	for i, loc := range locs {
+		end := len(errs)
+		if i < len(locs)-1 {
+			end = locs[i+1][0]
+		}
+		if msg := strings.TrimSpace(errs[loc[1]:end]); msg != "" {
+			msgs = append(msgs, msg)
+		}
+	}
+	return msgs
+}
@@ -0,0 +1,88 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loggers
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	jww "github.com/spf13/jwalterweatherman"
+)
+
+func TestNewEntry(t *testing.T) {
+	c := qt.New(t)
+
+	e := NewEntry("ERROR", `[en] REF_NOT_FOUND: Ref "foo.md": "/site/content/post.md:12:3": page not found`)
+	c.Assert(e.Level, qt.Equals, "error")
+	c.Assert(e.Lang, qt.Equals, "en")
+	c.Assert(e.File, qt.Equals, "/site/content/post.md")
+	c.Assert(e.Line, qt.Equals, 12)
+	c.Assert(e.Column, qt.Equals, 3)
+
+	e = NewEntry("warn", `postcss: failed to transform page "/blog/post.md"`)
+	c.Assert(e.Component, qt.Equals, "postcss")
+	c.Assert(e.Page, qt.Equals, "/blog/post.md")
+
+	e = NewEntry("warn", "Page.URL is deprecated and will be removed in a future release. Use .Permalink.")
+	c.Assert(e.Component, qt.Equals, "deprecation")
+
+	e = NewEntry("info", "Total in 123 ms")
+	c.Assert(e.Duration, qt.Equals, 123)
+}
+
+func TestJSONFormat(t *testing.T) {
+	c := qt.New(t)
+
+	c.Assert(SetFormat("xml"), qt.Not(qt.IsNil))
+	c.Assert(SetFormat("JSON"), qt.IsNil)
+	defer SetFormat(FormatText)
+	c.Assert(IsJSON(), qt.IsTrue)
+
+	var b bytes.Buffer
+	l := NewBasicLoggerForWriter(jww.LevelInfo, &b)
+	l.Warnf(`"content/post.md:1:2": some warning`)
+	l.Println("")
+	l.Println("Some feedback")
+	l.Println("Page.Dir is deprecated and will be removed in a future release.")
+
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	c.Assert(lines, qt.HasLen, 3)
+
+	var e Entry
+	c.Assert(json.Unmarshal([]byte(lines[0]), &e), qt.IsNil)
+	c.Assert(e.Level, qt.Equals, "warn")
+	c.Assert(e.File, qt.Equals, "content/post.md")
+	c.Assert(e.Line, qt.Equals, 1)
+	c.Assert(e.Message, qt.Equals, `"content/post.md:1:2": some warning`)
+	c.Assert(e.Time, qt.Not(qt.Equals), "")
+
+	c.Assert(json.Unmarshal([]byte(lines[1]), &e), qt.IsNil)
+	c.Assert(e.Level, qt.Equals, "info")
+	c.Assert(e.Message, qt.Equals, "Some feedback")
+
+	e = Entry{}
+	c.Assert(json.Unmarshal([]byte(lines[2]), &e), qt.IsNil)
+	c.Assert(e.Level, qt.Equals, "warn")
+	c.Assert(e.Component, qt.Equals, "deprecation")
+}
+
+func TestErrorMessages(t *testing.T) {
+	c := qt.New(t)
+
+	c.Assert(ErrorMessages(""), qt.HasLen, 0)
+	c.Assert(ErrorMessages("ERROR 2021/06/10 09:01:03 First\nsecond line\nERROR 2021/06/10 09:01:04 Second\n"), qt.DeepEquals, []string{"First\nsecond line", "Second"})
+}
@@ -274,6 +274,16 @@ func InitGlobalLogger(stdoutThreshold, logThreshold jww.Threshold, outHandle, lo
 }
 
 func getLogWriters(outHandle, logHandle io.Writer) (io.Writer, io.Writer) {
+	if IsJSON() {
+		if logHandle != ioutil.Discard {
+			logHandle = jsonWriter{w: logHandle}
+		}
+		if outHandle != ioutil.Discard {
+			outHandle = jsonWriter{w: outHandle}
+		}
+		return outHandle, logHandle
+	}
+
 	isTerm := terminal.IsTerminal(os.Stdout)
 	if logHandle != ioutil.Discard && isTerm {
 		// Remove any Ansi coloring from log output
@@ -0,0 +1,70 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text
+
+import (
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	c := qt.New(t)
+
+	c.Assert(UnifiedDiff("a", "b", "same\n", "same\n"), qt.Equals, "")
+
+	c.Assert(UnifiedDiff("a/p1.md", "b/p1.md", "+++\ncategories = [\"a\"]\ntitle = \"P1\"\n+++\n\nContent\n", "+++\ntitle = \"P1\"\ntopics = [\"a\"]\n+++\n\nContent\n"), qt.Equals, `--- a/p1.md
++++ b/p1.md
+@@ -1,6 +1,6 @@
+ +++
+-categories = ["a"]
+ title = "P1"
++topics = ["a"]
+ +++
+ 
+ Content
+`)
+
+	var lines []string
+	for i := 0; i < 20; i++ {
+		lines = append(lines, string(rune('a'+i)))
+	}
+	a := strings.Join(lines, "\n") + "\n"
+	lines[1] = "B"
+	lines[18] = "S"
+	b := strings.Join(lines, "\n") + "\n"
+
+	// Two hunks.
+	c.Assert(UnifiedDiff("a", "b", a, b), qt.Equals, `--- a
++++ b
+@@ -1,5 +1,5 @@
+ a
+-b
++B
+ c
+ d
+ e
+@@ -16,5 +16,5 @@
+ p
+ q
+ r
+-s
++S
+ t
+`)
+
+	c.Assert(UnifiedDiff("a", "b", "", "new\n"), qt.Equals, "--- a\n+++ b\n@@ -0,0 +1 @@\n+new\n")
+	c.Assert(UnifiedDiff("a", "b", "x\ny\n", "x\n"), qt.Equals, "--- a\n+++ b\n@@ -1,2 +1 @@\n x\n-y\n")
+}
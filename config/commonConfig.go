@@ -14,13 +14,17 @@
 package config
 
 import (
+	"net"
+	"regexp"
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 
 	"github.com/gohugoio/hugo/common/types"
+	hglob "github.com/gohugoio/hugo/hugofs/glob"
 
 	"github.com/gobwas/glob"
 	"github.com/gohugoio/hugo/common/herrors"
@@ -32,6 +36,9 @@ import (
 var DefaultBuild = Build{
 	UseResourceCacheWhen: "fallback",
 	WriteStats:           false,
+	Workers: Workers{
+		Images: 1,
+	},
 }
 
 // Build holds some build related configuration.
@@ -45,6 +52,28 @@ type Build struct {
 	// Can be used to toggle off writing of the intellinsense /assets/jsconfig.js
 	// file.
 	NoJSConfigInAssets bool
+
+	// Limits the number of concurrent heavy resource transformations.
+	Workers Workers
+}
+
+// Workers configures the worker pools for heavy resource transformations.
+// A value of 0 or less means no limit.
+type Workers struct {
+	// The number of images processed concurrently. Default is 1, as the
+	// imaging library spins up its own set of Go routines.
+	Images int
+
+	// The number of concurrent Sass/SCSS transformations (LibSass and Dart Sass).
+	Sass int
+
+	// The number of concurrent js.Build transformations.
+	JSBuild int
+
+	// When running the server, limit each pool to half of the logical CPUs
+	// and process images on a single Go routine, so big imports don't block
+	// the server and live reload for too long.
+	LowPriority bool
 }
 
 func (b Build) UseResourceCache(err error) bool {
@@ -80,13 +109,106 @@ func DecodeBuild(cfg Provider) Build {
 	return b
 }
 
+// Memory holds the in-memory budgets for some of the bigger subsystems.
+// A budget is the maximum number of entries kept in memory; when exceeded,
+// the least recently used entries are evicted and recreated (or re-read from the file
+// cache) if needed again. A value of 0 or less means no limit.
+type Memory struct {
+	// The number of pages that keep their rendered content in memory between
+	// render passes (one pass per language and output format).
+	Pages int
+
+	// The number of processed images kept in memory.
+	Images int
+
+	// The number of entries (e.g. transformed resources) in the resource cache.
+	Resources int
+}
+
+func DecodeMemory(cfg Provider) Memory {
+	var m Memory
+	if err := mapstructure.WeakDecode(cfg.GetStringMap("memory"), &m); err != nil {
+		return Memory{}
+	}
+	return m
+}
+
+// Watcher configures the file watcher used by hugo server and hugo --watch.
+type Watcher struct {
+	// Extra directories or files to watch for changes, relative to the
+	// project directory, e.g. the dist folder of a sibling package. A change
+	// in these triggers a full rebuild, unless it's in one of the mounted
+	// Hugo file systems.
+	Paths []string
+
+	// Glob patterns for the files and directories to ignore, relative to the
+	// project directory, e.g. "**/*.tmp" or "assets/vendor". Ignored
+	// directories are not watched.
+	Ignore []string
+
+	ignoreInit   sync.Once
+	ignoreFilter *hglob.FilenameFilter
+}
+
+// Ignores reports whether the file or directory filename, a path relative to
+// the project directory, should be ignored by the watcher.
+func (w *Watcher) Ignores(filename string, isDir bool) bool {
+	if len(w.Ignore) == 0 {
+		return false
+	}
+	w.ignoreInit.Do(func() {
+		// Validated in DecodeWatcher.
+		w.ignoreFilter, _ = hglob.NewFilenameFilter(nil, w.Ignore)
+	})
+	return !w.ignoreFilter.Match(filename, isDir)
+}
+
+func DecodeWatcher(cfg Provider) (*Watcher, error) {
+	w := &Watcher{}
+	m := cfg.GetStringMap("watcher")
+	if m == nil {
+		return w, nil
+	}
+
+	if err := mapstructure.WeakDecode(m, w); err != nil {
+		return nil, errors.Wrap(err, "failed to decode watcher config")
+	}
+
+	if _, err := hglob.NewFilenameFilter(nil, w.Ignore); err != nil {
+		return nil, errors.Wrap(err, "invalid ignore pattern in watcher config")
+	}
+
+	return w, nil
+}
+
 // Sitemap configures the sitemap to be generated.
 type Sitemap struct {
 	ChangeFreq string
 	Priority   float64
 	Filename   string
+
+	// Whether to leave the page out of the sitemap.
+	Disable bool
+
+	// Where to get the lastmod date of the page from, "lastmod" (the default)
+	// for the page's .Lastmod or "git" for the author date of the last
+	// Git commit of the page's file.
+	LastmodSource string
+
+	// Whether to use the last change of the page's bundled resources as the
+	// lastmod date if it is more recent.
+	LastmodResources bool
+
+	// The lastmod date of the page in the sitemap, resolved from the above.
+	Lastmod time.Time
 }
 
+// The lastmod sources for the sitemap.
+const (
+	SitemapLastmodSourceLastmod = "lastmod"
+	SitemapLastmodSourceGit     = "git"
+)
+
 func DecodeSitemap(prototype Sitemap, input map[string]interface{}) Sitemap {
 	for key, value := range input {
 		switch key {
@@ -96,6 +218,14 @@ func DecodeSitemap(prototype Sitemap, input map[string]interface{}) Sitemap {
 			prototype.Priority = cast.ToFloat64(value)
 		case "filename":
 			prototype.Filename = cast.ToString(value)
+		case "disable":
+			prototype.Disable = cast.ToBool(value)
+		case "lastmodsource":
+			prototype.LastmodSource = strings.ToLower(cast.ToString(value))
+		case "lastmodresources":
+			prototype.LastmodResources = cast.ToBool(value)
+		case "rules":
+			// Decoded with the site.
 		default:
 			jww.WARN.Printf("Unknown Sitemap field: %s\n", key)
 		}
@@ -109,9 +239,81 @@ type Server struct {
 	Headers   []Headers
 	Redirects []Redirect
 
+	// Disable reading headers and redirects from the host platform config
+	// files, e.g. netlify.toml, in the project.
+	DisableHostConfig bool
+
+	// Enable to serve over HTTPS with HTTP/2.
+	HTTP2 bool
+
+	// The TLS certificate and key files to use with HTTP2. If not set, a
+	// self-signed certificate is created.
+	TLSCertFile string
+	TLSKeyFile  string
+
+	// Enable to compress the responses with gzip. Precompressed .br and .gz
+	// files next to the requested file are served if the client accepts them.
+	Compress bool
+
+	// The Cache-Control header to set on all responses. Use Headers to set
+	// it for some paths only.
+	CacheControl string
+
+	// If set, the server requires HTTP basic authentication with these
+	// credentials.
+	BasicAuth BasicAuth
+
+	// If set, only clients with these IP addresses or in these CIDR ranges,
+	// e.g. 192.168.1.0/24, can access the server. Loopback addresses are
+	// always allowed.
+	AllowIPs []string
+
+	// Configures the public preview URL set up with hugo server --tunnel.
+	Tunnel Tunnel
+
+	// Enable the JSON API returning the rendered pages at /__hugo/api/pages/,
+	// e.g. for the preview pane in a CMS.
+	API bool
+
 	compiledInit      sync.Once
 	compiledHeaders   []glob.Glob
 	compiledRedirects []glob.Glob
+	compiledAllowIPs  []*net.IPNet
+}
+
+// BasicAuth holds the credentials for HTTP basic authentication.
+type BasicAuth struct {
+	User     string
+	Password string
+}
+
+// IsZero reports whether no credentials are set.
+func (b BasicAuth) IsZero() bool {
+	return b.User == "" && b.Password == ""
+}
+
+// Tunnel configures the tunnel to the server started with hugo server
+// --tunnel.
+type Tunnel struct {
+	// The tunnel provider, one of cloudflared (default), ngrok, localtunnel
+	// or command.
+	Provider string
+
+	// The command to run with the command provider.
+	Command string
+
+	// The arguments to the command. With the command provider, {port} and
+	// {url} are replaced with the port and URL of the local server. With the
+	// other providers, these are added to the default arguments.
+	Args []string
+
+	// With the command provider, a regular expression matching the public
+	// URL in the output of the command. The default matches the first
+	// https URL.
+	URLPattern string
+
+	// Disable printing the public URL as a QR code.
+	DisableQRCode bool
 }
 
 func (s *Server) init() {
@@ -122,9 +324,63 @@ func (s *Server) init() {
 		for _, r := range s.Redirects {
 			s.compiledRedirects = append(s.compiledRedirects, glob.MustCompile(r.From))
 		}
+		for _, ip := range s.AllowIPs {
+			// Validated in DecodeServer.
+			n, _ := parseIPNet(ip)
+			if n != nil {
+				s.compiledAllowIPs = append(s.compiledAllowIPs, n)
+			}
+		}
 	})
 }
 
+// AllowsIP reports whether a client with the given IP address can access
+// the server.
+func (s *Server) AllowsIP(ip net.IP) bool {
+	return s.allowsIP(ip, true)
+}
+
+// AllowsForwardedIP is like AllowsIP, but for an IP address read from the
+// request headers, e.g. through a tunnel. These are never trusted as
+// loopback addresses.
+func (s *Server) AllowsForwardedIP(ip net.IP) bool {
+	return s.allowsIP(ip, false)
+}
+
+func (s *Server) allowsIP(ip net.IP, allowLoopback bool) bool {
+	s.init()
+
+	if len(s.AllowIPs) == 0 || (allowLoopback && ip.IsLoopback()) {
+		return true
+	}
+
+	for _, n := range s.compiledAllowIPs {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseIPNet parses s as an IP address or a CIDR range.
+func parseIPNet(s string) (*net.IPNet, error) {
+	s = strings.TrimSpace(s)
+	if strings.Contains(s, "/") {
+		_, n, err := net.ParseCIDR(s)
+		return n, err
+	}
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil, errors.Errorf("invalid IP address %q", s)
+	}
+	if v4 := ip.To4(); v4 != nil {
+		ip = v4
+	}
+	bits := 8 * len(ip)
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+}
+
 func (s *Server) MatchHeaders(pattern string) []types.KeyValueStr {
 	s.init()
 
@@ -168,6 +424,17 @@ func (s *Server) MatchRedirect(pattern string) Redirect {
 		}
 
 		if g.Match(pattern) {
+			if strings.Contains(redir.To, ":splat") {
+				// Replace :splat with the part of the path matching the wildcard.
+				var splat string
+				if i := strings.Index(redir.From, "*"); i != -1 {
+					splat = strings.TrimPrefix(pattern, redir.From[:i])
+				}
+				redir.To = strings.Replace(redir.To, ":splat", splat, -1)
+				if redir.To == pattern {
+					return Redirect{}
+				}
+			}
 			return redir
 		}
 	}
@@ -191,6 +458,19 @@ func (r Redirect) IsZero() bool {
 	return r.From == ""
 }
 
+// normalizeRedirect gets r in line with the Hugo server. It returns an
+// error if the target is not supported.
+func normalizeRedirect(r Redirect) (Redirect, error) {
+	r.To = strings.TrimSuffix(r.To, "index.html")
+	if r.Status == 404 || strings.HasPrefix(r.To, "http://") || strings.HasPrefix(r.To, "https://") || strings.HasSuffix(r.To, "/") || strings.HasSuffix(r.To, ":splat") {
+		return r, nil
+	}
+	// There are some tricky infinite loop situations when dealing
+	// when the target does not have a trailing slash.
+	// This can certainly be handled better, but not time for that now.
+	return r, errors.Errorf("unsupported redirect to value %q; currently this must be either a remote destination or a local folder, e.g. \"/blog/\" or \"/blog/index.html\"", r.To)
+}
+
 func DecodeServer(cfg Provider) (*Server, error) {
 	m := cfg.GetStringMap("server")
 	s := &Server{}
@@ -201,16 +481,28 @@ func DecodeServer(cfg Provider) (*Server, error) {
 	_ = mapstructure.WeakDecode(m, s)
 
 	for i, redir := range s.Redirects {
-		// Get it in line with the Hugo server.
-		redir.To = strings.TrimSuffix(redir.To, "index.html")
-		if !strings.HasPrefix(redir.To, "https") && !strings.HasSuffix(redir.To, "/") {
-			// There are some tricky infinite loop situations when dealing
-			// when the target does not have a trailing slash.
-			// This can certainly be handled better, but not time for that now.
-			return nil, errors.Errorf("unsupported redirect to value %q in server config; currently this must be either a remote destination or a local folder, e.g. \"/blog/\" or \"/blog/index.html\"", redir.To)
+		redir, err := normalizeRedirect(redir)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid redirect in server config")
 		}
 		s.Redirects[i] = redir
 	}
 
+	if (s.BasicAuth.User == "") != (s.BasicAuth.Password == "") {
+		return nil, errors.New("both user and password must be set in basicAuth in server config")
+	}
+
+	for _, ip := range s.AllowIPs {
+		if _, err := parseIPNet(ip); err != nil {
+			return nil, errors.Wrap(err, "invalid allowIPs in server config")
+		}
+	}
+
+	if s.Tunnel.URLPattern != "" {
+		if _, err := regexp.Compile(s.Tunnel.URLPattern); err != nil {
+			return nil, errors.Wrap(err, "invalid tunnel.urlPattern in server config")
+		}
+	}
+
 	return s, nil
 }
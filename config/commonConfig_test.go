@@ -15,6 +15,7 @@ package config
 
 import (
 	"errors"
+	"net"
 	"testing"
 
 	"github.com/gohugoio/hugo/common/herrors"
@@ -55,6 +56,68 @@ func TestBuild(t *testing.T) {
 	c.Assert(b.UseResourceCache(herrors.ErrFeatureNotAvailable), qt.Equals, false)
 	c.Assert(b.UseResourceCache(errors.New("err")), qt.Equals, false)
 	c.Assert(b.UseResourceCache(nil), qt.Equals, false)
+
+	c.Assert(b.Workers, qt.DeepEquals, Workers{Images: 1})
+
+	v.Set("build", map[string]interface{}{
+		"workers": map[string]interface{}{
+			"images":      4,
+			"sass":        "2",
+			"jsBuild":     3,
+			"lowPriority": true,
+		},
+	})
+
+	b = DecodeBuild(v)
+
+	c.Assert(b.Workers, qt.DeepEquals, Workers{Images: 4, Sass: 2, JSBuild: 3, LowPriority: true})
+}
+
+func TestMemory(t *testing.T) {
+	c := qt.New(t)
+
+	v := New()
+	c.Assert(DecodeMemory(v), qt.Equals, Memory{})
+
+	v.Set("memory", map[string]interface{}{
+		"pages":     10000,
+		"images":    "500",
+		"resources": 200,
+	})
+
+	c.Assert(DecodeMemory(v), qt.Equals, Memory{Pages: 10000, Images: 500, Resources: 200})
+}
+
+func TestWatcher(t *testing.T) {
+	c := qt.New(t)
+
+	w, err := DecodeWatcher(New())
+	c.Assert(err, qt.IsNil)
+	c.Assert(w.Ignores("content/post.md", false), qt.IsFalse)
+
+	cfg, err := FromConfigString(`[watcher]
+paths = ["../mylib/dist"]
+ignore = ["**/*.tmp", "assets/vendor", "**/*~"]
+`, "toml")
+	c.Assert(err, qt.IsNil)
+
+	w, err = DecodeWatcher(cfg)
+	c.Assert(err, qt.IsNil)
+	c.Assert(w.Paths, qt.DeepEquals, []string{"../mylib/dist"})
+	c.Assert(w.Ignores("file.tmp", false), qt.IsTrue)
+	c.Assert(w.Ignores("content/posts/file.TMP", false), qt.IsTrue)
+	c.Assert(w.Ignores("content/posts/file.md~", false), qt.IsTrue)
+	c.Assert(w.Ignores("assets/vendor", true), qt.IsTrue)
+	c.Assert(w.Ignores("assets/vendor/lib.js", false), qt.IsTrue)
+	c.Assert(w.Ignores("assets/vendored.js", false), qt.IsFalse)
+	c.Assert(w.Ignores("content/posts/file.md", false), qt.IsFalse)
+
+	cfg, err = FromConfigString(`[watcher]
+ignore = ["assets/[vendor"]
+`, "toml")
+	c.Assert(err, qt.IsNil)
+	_, err = DecodeWatcher(cfg)
+	c.Assert(err, qt.ErrorMatches, "invalid ignore pattern in watcher config.*")
 }
 
 func TestServer(t *testing.T) {
@@ -138,3 +201,111 @@ status = 301`,
 
 	}
 }
+
+func TestServerHTTP(t *testing.T) {
+	c := qt.New(t)
+
+	cfg, err := FromConfigString(`[server]
+http2 = true
+compress = true
+cacheControl = "public, max-age=3600"
+tlsCertFile = "cert.pem"
+tlsKeyFile = "key.pem"
+`, "toml")
+	c.Assert(err, qt.IsNil)
+
+	s, err := DecodeServer(cfg)
+	c.Assert(err, qt.IsNil)
+	c.Assert(s.HTTP2, qt.IsTrue)
+	c.Assert(s.Compress, qt.IsTrue)
+	c.Assert(s.CacheControl, qt.Equals, "public, max-age=3600")
+	c.Assert(s.TLSCertFile, qt.Equals, "cert.pem")
+	c.Assert(s.TLSKeyFile, qt.Equals, "key.pem")
+
+	s, err = DecodeServer(New())
+	c.Assert(err, qt.IsNil)
+	c.Assert(s.HTTP2, qt.IsFalse)
+	c.Assert(s.Compress, qt.IsFalse)
+	c.Assert(s.CacheControl, qt.Equals, "")
+}
+
+func TestServerAPI(t *testing.T) {
+	c := qt.New(t)
+
+	cfg, err := FromConfigString(`[server]
+api = true
+`, "toml")
+	c.Assert(err, qt.IsNil)
+
+	s, err := DecodeServer(cfg)
+	c.Assert(err, qt.IsNil)
+	c.Assert(s.API, qt.IsTrue)
+
+	s, err = DecodeServer(New())
+	c.Assert(err, qt.IsNil)
+	c.Assert(s.API, qt.IsFalse)
+}
+
+func TestServerTunnel(t *testing.T) {
+	c := qt.New(t)
+
+	cfg, err := FromConfigString(`[server]
+allowIPs = ["10.0.0.1"]
+
+[server.tunnel]
+provider = "ngrok"
+args = ["--region", "eu"]
+`, "toml")
+	c.Assert(err, qt.IsNil)
+
+	s, err := DecodeServer(cfg)
+	c.Assert(err, qt.IsNil)
+	c.Assert(s.Tunnel.Provider, qt.Equals, "ngrok")
+	c.Assert(s.Tunnel.Args, qt.DeepEquals, []string{"--region", "eu"})
+	c.Assert(s.AllowsForwardedIP(net.ParseIP("127.0.0.1")), qt.IsFalse)
+	c.Assert(s.AllowsForwardedIP(net.ParseIP("10.0.0.1")), qt.IsTrue)
+	c.Assert(s.AllowsForwardedIP(nil), qt.IsFalse)
+
+	cfg, err = FromConfigString(`[server.tunnel]
+urlPattern = "https://(.*"`, "toml")
+	c.Assert(err, qt.IsNil)
+	_, err = DecodeServer(cfg)
+	c.Assert(err, qt.Not(qt.IsNil))
+}
+
+func TestServerAccess(t *testing.T) {
+	c := qt.New(t)
+
+	cfg, err := FromConfigString(`[server]
+allowIPs = ["192.168.1.0/24", "10.0.0.1", "fd00::/8"]
+
+[server.basicAuth]
+user = "jo"
+password = "secret"
+`, "toml")
+	c.Assert(err, qt.IsNil)
+
+	s, err := DecodeServer(cfg)
+	c.Assert(err, qt.IsNil)
+	c.Assert(s.BasicAuth, qt.Equals, BasicAuth{User: "jo", Password: "secret"})
+	c.Assert(s.AllowsIP(net.ParseIP("127.0.0.1")), qt.IsTrue)
+	c.Assert(s.AllowsIP(net.ParseIP("::1")), qt.IsTrue)
+	c.Assert(s.AllowsIP(net.ParseIP("192.168.1.12")), qt.IsTrue)
+	c.Assert(s.AllowsIP(net.ParseIP("192.168.2.12")), qt.IsFalse)
+	c.Assert(s.AllowsIP(net.ParseIP("10.0.0.1")), qt.IsTrue)
+	c.Assert(s.AllowsIP(net.ParseIP("10.0.0.2")), qt.IsFalse)
+	c.Assert(s.AllowsIP(net.ParseIP("fd00::1")), qt.IsTrue)
+	c.Assert((&Server{}).AllowsIP(net.ParseIP("10.0.0.2")), qt.IsTrue)
+
+	for _, invalid := range []string{
+		`[server.basicAuth]
+user = "jo"`,
+		`[server]
+allowIPs = ["192.168.1"]`,
+	} {
+		cfg, err := FromConfigString(invalid, "toml")
+		c.Assert(err, qt.IsNil)
+		_, err = DecodeServer(cfg)
+		c.Assert(err, qt.Not(qt.IsNil))
+	}
+}
@@ -0,0 +1,286 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package configschema builds a JSON Schema for the site configuration,
+// used for editor autocompletion and validation of config files.
+package configschema
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SchemaURI is the JSON Schema version used.
+const SchemaURI = "http://json-schema.org/draft-07/schema#"
+
+// The JSON Schema types.
+const (
+	TypeObject  = "object"
+	TypeArray   = "array"
+	TypeString  = "string"
+	TypeInteger = "integer"
+	TypeNumber  = "number"
+	TypeBoolean = "boolean"
+)
+
+// Schema is a JSON Schema. Only the keywords needed to describe the site
+// configuration are supported; any other schema can be added in AllOf.
+type Schema struct {
+	Schema      string `json:"$schema,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+
+	// The type(s), a string or a slice of strings.
+	Type interface{} `json:"type,omitempty"`
+
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Default              interface{}        `json:"default,omitempty"`
+
+	// Any schemas, e.g. decoded from JSON, that must also match.
+	AllOf []interface{} `json:"allOf,omitempty"`
+}
+
+// Schemaer is implemented by types that don't map to a schema by their
+// Go type, e.g. types decoded from a string.
+type Schemaer interface {
+	ConfigSchema() *Schema
+}
+
+// Set sets the schema of the property key to v, which must be a *Schema
+// or a value as documented in FromValue.
+func (s *Schema) Set(key string, v interface{}) {
+	if s.Properties == nil {
+		s.Properties = make(map[string]*Schema)
+	}
+	s.Properties[key] = toSchema(v)
+}
+
+// Keys returns the sorted property keys.
+func (s *Schema) Keys() []string {
+	var keys []string
+	for k := range s.Properties {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Object creates an object schema with the given properties, see Set.
+func Object(properties map[string]interface{}) *Schema {
+	s := &Schema{Type: TypeObject}
+	for k, v := range properties {
+		s.Set(k, v)
+	}
+	return s
+}
+
+var (
+	sectionsMu sync.Mutex
+	sections   = make(map[string]interface{})
+)
+
+// AddSection registers the schema for the root config key, typically
+// with the default config for that section. v must be a *Schema or a
+// value as documented in FromValue.
+func AddSection(key string, v interface{}) {
+	sectionsMu.Lock()
+	defer sectionsMu.Unlock()
+	sections[key] = v
+}
+
+// New creates a new root schema with the sections registered with
+// AddSection.
+func New() *Schema {
+	sectionsMu.Lock()
+	defer sectionsMu.Unlock()
+
+	s := &Schema{
+		Schema: SchemaURI,
+		Title:  "Hugo site configuration",
+		Type:   TypeObject,
+	}
+	for k, v := range sections {
+		s.Set(k, v)
+	}
+	return s
+}
+
+func toSchema(v interface{}) *Schema {
+	if s, ok := v.(*Schema); ok {
+		return s
+	}
+	return FromValue(v)
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// FromValue creates a schema from the Go value v, typically a config
+// struct with its default values set. The property names are the field
+// names in lower camel case, e.g. baseURL, or the mapstructure tag
+// names, if set. The scalar values, and the keys of a map, are used as
+// defaults.
+func FromValue(v interface{}) *Schema {
+	if v == nil {
+		return &Schema{}
+	}
+	return fromValue(reflect.ValueOf(v), make(map[reflect.Type]bool))
+}
+
+func fromValue(v reflect.Value, seen map[reflect.Type]bool) *Schema {
+	if v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return &Schema{}
+		}
+		v = v.Elem()
+	}
+
+	t := v.Type()
+
+	if v.CanInterface() {
+		if sc, ok := v.Interface().(Schemaer); ok {
+			return sc.ConfigSchema()
+		}
+	}
+
+	if t == durationType {
+		s := &Schema{Type: []string{TypeString, TypeInteger}}
+		if d := v.Interface().(time.Duration); d < 0 {
+			// Usually means forever.
+			s.Default = int64(d)
+		} else if d > 0 {
+			s.Default = d.String()
+		}
+		return s
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return fromValue(reflect.Zero(t.Elem()), seen)
+		}
+		return fromValue(v.Elem(), seen)
+	case reflect.Bool:
+		return &Schema{Type: TypeBoolean, Default: v.Bool()}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: TypeInteger, Default: v.Int()}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: TypeInteger, Default: v.Uint()}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: TypeNumber, Default: v.Float()}
+	case reflect.String:
+		s := &Schema{Type: TypeString}
+		if v.String() != "" {
+			s.Default = v.String()
+		}
+		return s
+	case reflect.Slice, reflect.Array:
+		// The first element, if any, provides the defaults for the items.
+		first := reflect.Zero(t.Elem())
+		if v.Len() > 0 {
+			first = v.Index(0)
+		}
+		items := fromValue(first, seen)
+		items.Default = nil
+		s := &Schema{Type: TypeArray, Items: items}
+		if typ, ok := items.Type.(string); ok && typ != TypeObject && typ != TypeArray {
+			// A single value is accepted for a slice of scalars.
+			s.Type = []string{TypeArray, typ}
+			if v.Len() > 0 && v.CanInterface() {
+				s.Default = v.Interface()
+			}
+		}
+		return s
+	case reflect.Map:
+		s := &Schema{Type: TypeObject}
+		if t.Elem().Kind() != reflect.Interface {
+			s.AdditionalProperties = fromValue(reflect.Zero(t.Elem()), seen)
+			s.AdditionalProperties.Default = nil
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			if iter.Key().Kind() != reflect.String {
+				break
+			}
+			s.Set(iter.Key().String(), fromValue(iter.Value(), seen))
+		}
+		return s
+	case reflect.Struct:
+		s := &Schema{Type: TypeObject}
+		if seen[t] {
+			// Recursive type.
+			return s
+		}
+		seen[t] = true
+		defer delete(seen, t)
+		addStructFields(s, v, seen)
+		return s
+	}
+
+	return &Schema{}
+}
+
+func addStructFields(s *Schema, v reflect.Value, seen map[reflect.Type]bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			// Unexported.
+			continue
+		}
+		switch f.Type.Kind() {
+		case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+			continue
+		}
+
+		name, squash := f.Name, false
+		if tag, found := f.Tag.Lookup("mapstructure"); found {
+			parts := strings.Split(tag, ",")
+			if parts[0] == "-" {
+				continue
+			}
+			if parts[0] != "" {
+				name = parts[0]
+			}
+			for _, p := range parts[1:] {
+				squash = squash || p == "squash"
+			}
+		} else {
+			name = lowerCamel(name)
+		}
+
+		if squash && f.Type.Kind() == reflect.Struct {
+			addStructFields(s, v.Field(i), seen)
+			continue
+		}
+
+		s.Set(name, fromValue(v.Field(i), seen))
+	}
+}
+
+// lowerCamel converts a field name to the form used in the docs, e.g.
+// BaseURL to baseURL and JSON to json.
+func lowerCamel(s string) string {
+	if s == strings.ToUpper(s) {
+		return strings.ToLower(s)
+	}
+	r, width := utf8.DecodeRuneInString(s)
+	return string(unicode.ToLower(r)) + s[width:]
+}
@@ -0,0 +1,91 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configschema
+
+import (
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+type testConfig struct {
+	BaseURL  string
+	Timeout  time.Duration
+	MaxAge   time.Duration
+	Weight   int
+	Draft    bool
+	Tags     []string
+	Params   map[string]interface{}
+	Targets  []testTarget
+	Renamed  string `mapstructure:"custom"`
+	Skipped  string `mapstructure:"-"`
+	Children []*testConfig
+	HTML     testTarget
+
+	unexported string
+}
+
+type testTarget struct {
+	URL string
+}
+
+type testString struct{}
+
+func (testString) ConfigSchema() *Schema {
+	return &Schema{Type: TypeString}
+}
+
+func TestFromValue(t *testing.T) {
+	s := FromValue(testConfig{
+		BaseURL: "https://example.org",
+		Timeout: 30 * time.Second,
+		MaxAge:  -1,
+		Tags:    []string{"a", "b"},
+	})
+
+	c := qt.New(t)
+	c.Assert(s.Type, qt.Equals, TypeObject)
+	c.Assert(s.Keys(), qt.DeepEquals, []string{"baseURL", "children", "custom", "draft", "html", "maxAge", "params", "tags", "targets", "timeout", "weight"})
+	c.Assert(s.Properties["baseURL"], qt.DeepEquals, &Schema{Type: TypeString, Default: "https://example.org"})
+	c.Assert(s.Properties["timeout"], qt.DeepEquals, &Schema{Type: []string{TypeString, TypeInteger}, Default: "30s"})
+	c.Assert(s.Properties["maxAge"].Default, qt.Equals, int64(-1))
+	c.Assert(s.Properties["weight"], qt.DeepEquals, &Schema{Type: TypeInteger, Default: int64(0)})
+	c.Assert(s.Properties["draft"], qt.DeepEquals, &Schema{Type: TypeBoolean, Default: false})
+	c.Assert(s.Properties["tags"], qt.DeepEquals, &Schema{Type: []string{TypeArray, TypeString}, Items: &Schema{Type: TypeString}, Default: []string{"a", "b"}})
+	c.Assert(s.Properties["params"], qt.DeepEquals, &Schema{Type: TypeObject})
+	c.Assert(s.Properties["targets"].Items.Properties["url"].Type, qt.Equals, TypeString)
+	c.Assert(s.Properties["html"].Properties["url"].Type, qt.Equals, TypeString)
+	// Recursive type.
+	c.Assert(s.Properties["children"].Items, qt.DeepEquals, &Schema{Type: TypeObject})
+
+	m := FromValue(map[string]testString{"a": {}})
+	c.Assert(m.AdditionalProperties, qt.DeepEquals, &Schema{Type: TypeString})
+	c.Assert(m.Properties["a"], qt.DeepEquals, &Schema{Type: TypeString})
+}
+
+func TestNew(t *testing.T) {
+	c := qt.New(t)
+
+	AddSection("testSection", struct{ Enable bool }{true})
+	defer func() {
+		sectionsMu.Lock()
+		delete(sections, "testSection")
+		sectionsMu.Unlock()
+	}()
+
+	s := New()
+	c.Assert(s.Schema, qt.Equals, SchemaURI)
+	c.Assert(s.Properties["testSection"].Properties["enable"].Default, qt.Equals, true)
+}
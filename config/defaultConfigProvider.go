@@ -87,13 +87,12 @@ func (c *defaultConfigProvider) Get(k string) interface{} {
 		return c.root
 	}
 	c.mu.RLock()
+	defer c.mu.RUnlock()
 	key, m := c.getNestedKeyAndMap(strings.ToLower(k), false)
 	if m == nil {
 		return nil
 	}
-	v := m[key]
-	c.mu.RUnlock()
-	return v
+	return m[key]
 }
 
 func (c *defaultConfigProvider) GetBool(k string) bool {
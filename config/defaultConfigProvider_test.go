@@ -295,6 +295,15 @@ func TestDefaultConfigProvider(t *testing.T) {
 		c.Assert(cfg.IsSet("z"), qt.IsFalse)
 	})
 
+	c.Run("Get missing nested", func(c *qt.C) {
+		cfg := New()
+
+		c.Assert(cfg.Get("a.b"), qt.IsNil)
+		// This used to deadlock.
+		cfg.Set("a", "av")
+		c.Assert(cfg.Get("a"), qt.Equals, "av")
+	})
+
 	c.Run("Para", func(c *qt.C) {
 		cfg := New()
 		p := para.New(4)
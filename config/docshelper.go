@@ -15,6 +15,7 @@ package config
 
 import (
 	"github.com/gohugoio/hugo/common/maps"
+	"github.com/gohugoio/hugo/config/configschema"
 	"github.com/gohugoio/hugo/docshelper"
 )
 
@@ -42,4 +43,7 @@ func init() {
 	}
 
 	docshelper.AddDocProviderFunc(docsProvider)
+
+	configschema.AddSection("build", DefaultBuild)
+	configschema.AddSection("server", Server{})
 }
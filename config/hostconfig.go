@@ -0,0 +1,363 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gohugoio/hugo/parser/metadecoders"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+// HostConfigFiles are the host platform config files read by
+// DecodeHostServer, in the order their rules are applied.
+var HostConfigFiles = []string{"_headers", "_redirects", "netlify.toml", "vercel.json"}
+
+// HostServer holds the headers and redirects read from the host platform
+// config files.
+type HostServer struct {
+	Headers   []Headers
+	Redirects []Redirect
+
+	// The files read.
+	Filenames []string
+
+	// Rules that could not be emulated and were skipped.
+	Warnings []string
+}
+
+// DecodeHostServer reads the headers, redirects and rewrites in the
+// Netlify (netlify.toml, _headers, _redirects) and Vercel (vercel.json)
+// config files found in dirs, so the dev server can emulate them.
+func DecodeHostServer(fs afero.Fs, dirs ...string) (HostServer, error) {
+	var hs HostServer
+
+	for _, dir := range dirs {
+		for _, name := range HostConfigFiles {
+			filename := filepath.Join(dir, name)
+			b, err := afero.ReadFile(fs, filename)
+			if err != nil {
+				if os.IsNotExist(err) {
+					continue
+				}
+				return hs, err
+			}
+
+			d := &hostDecoder{filename: filename, hs: &hs}
+			switch name {
+			case "_headers":
+				d.decodeHeadersFile(b)
+			case "_redirects":
+				d.decodeRedirectsFile(b)
+			case "netlify.toml":
+				err = d.decodeNetlify(b)
+			case "vercel.json":
+				err = d.decodeVercel(b)
+			}
+			if err != nil {
+				return hs, errors.Wrapf(err, "failed to decode %s", filename)
+			}
+
+			hs.Filenames = append(hs.Filenames, filename)
+		}
+	}
+
+	return hs, nil
+}
+
+type hostDecoder struct {
+	filename string
+	hs       *HostServer
+}
+
+func (d *hostDecoder) warnf(format string, args ...interface{}) {
+	d.hs.Warnings = append(d.hs.Warnings, fmt.Sprintf("%s: %s", d.filename, fmt.Sprintf(format, args...)))
+}
+
+func (d *hostDecoder) addHeaders(h Headers) {
+	if h.For == "" || len(h.Values) == 0 {
+		return
+	}
+	pattern, _, ok := d.pattern(h.For)
+	if !ok {
+		d.warnf("unsupported headers path %q", h.For)
+		return
+	}
+	h.For = pattern
+	d.hs.Headers = append(d.hs.Headers, h)
+}
+
+func (d *hostDecoder) addRedirect(r Redirect) {
+	if r.From == "" || r.To == "" {
+		return
+	}
+	if r.Status == 0 {
+		r.Status = 301
+	}
+
+	from, placeholders, ok := d.pattern(r.From)
+	if !ok {
+		d.warnf("unsupported redirect from %q", r.From)
+		return
+	}
+
+	to := r.To
+	for i, p := range placeholders {
+		refs := []string{"$" + strconv.Itoa(i+1)}
+		if p != "" {
+			refs = append(refs, ":"+p+"*", ":"+p+"+", ":"+p+"?", ":"+p)
+		}
+		for _, ref := range refs {
+			if !strings.Contains(to, ref) {
+				continue
+			}
+			// Only a single placeholder, matching the rest of the path,
+			// can be used in the target.
+			if len(placeholders) != 1 || !strings.HasSuffix(from, "*") {
+				d.warnf("unsupported redirect from %q to %q, only a single trailing placeholder in from can be used in to", r.From, r.To)
+				return
+			}
+			to = strings.Replace(to, ref, ":splat", -1)
+		}
+	}
+
+	remote := strings.HasPrefix(to, "http://") || strings.HasPrefix(to, "https://")
+
+	switch {
+	case r.Status == 200 || r.Status == 404:
+		// Rewrites, and e.g. a custom 404 page, are served with the
+		// content of the target.
+		if remote {
+			d.warnf("proxying %q to %q is not supported", r.From, r.To)
+			return
+		}
+		if r.Status == 200 && !strings.HasSuffix(to, "/") && path.Ext(to) == "" && !strings.Contains(to, ":splat") {
+			// Avoid a redirect to the directory with a trailing slash.
+			to += "/"
+		}
+	case r.Status < 300 || r.Status > 399:
+		d.warnf("unsupported status %d for redirect from %q to %q", r.Status, r.From, r.To)
+		return
+	}
+
+	r.From = from
+	r.To = to
+	r, err := normalizeRedirect(r)
+	if err != nil {
+		d.warnf("%s", err)
+		return
+	}
+	d.hs.Redirects = append(d.hs.Redirects, r)
+}
+
+var placeholderRe = regexp.MustCompile(`:[a-zA-Z_][a-zA-Z0-9_]*[*+?]?|\(\.\*\)|\*`)
+
+// pattern converts a Netlify or Vercel path pattern, with
+// placeholders (e.g. :slug or :path*), wildcards (*) and (.*), to a glob.
+// It returns the placeholder names, "splat" for wildcards and "" for (.*).
+func (d *hostDecoder) pattern(s string) (string, []string, bool) {
+	var placeholders []string
+
+	glob := placeholderRe.ReplaceAllStringFunc(s, func(m string) string {
+		name := strings.TrimRight(strings.TrimPrefix(m, ":"), "*+?")
+		if m == "*" {
+			name = "splat"
+		} else if m == "(.*)" {
+			name = ""
+		}
+		placeholders = append(placeholders, name)
+		return "*"
+	})
+
+	if strings.ContainsAny(glob, "()[]{}?\\") {
+		// Regular expressions are not supported.
+		return "", nil, false
+	}
+
+	return glob, placeholders, true
+}
+
+// decodeHeadersFile decodes a _headers file, e.g.:
+//
+//  /*
+//    X-Frame-Options: DENY
+func (d *hostDecoder) decodeHeadersFile(b []byte) {
+	var current *Headers
+
+	flush := func() {
+		if current != nil {
+			d.addHeaders(*current)
+		}
+		current = nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		if trimmed == line {
+			flush()
+			current = &Headers{For: trimmed, Values: make(map[string]interface{})}
+			continue
+		}
+
+		if current == nil {
+			continue
+		}
+
+		parts := strings.SplitN(trimmed, ":", 2)
+		if len(parts) != 2 {
+			d.warnf("invalid header %q", trimmed)
+			continue
+		}
+		key, value := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		if existing, found := current.Values[key]; found {
+			value = existing.(string) + ", " + value
+		}
+		current.Values[key] = value
+	}
+
+	flush()
+}
+
+// decodeRedirectsFile decodes a _redirects file, e.g.:
+//
+//  /blog/*  /news/:splat  301!
+func (d *hostDecoder) decodeRedirectsFile(b []byte) {
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(line, "#"); i != -1 && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t') {
+			line = strings.TrimSpace(line[:i])
+		}
+		if line == "" {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 || len(fields) > 3 {
+			d.warnf("unsupported redirect %q", line)
+			continue
+		}
+
+		r := Redirect{From: fields[0], To: fields[1]}
+		if len(fields) == 3 {
+			status := fields[2]
+			if strings.HasSuffix(status, "!") {
+				r.Force = true
+				status = strings.TrimSuffix(status, "!")
+			}
+			var err error
+			r.Status, err = strconv.Atoi(status)
+			if err != nil {
+				d.warnf("unsupported redirect %q", line)
+				continue
+			}
+		}
+
+		d.addRedirect(r)
+	}
+}
+
+func (d *hostDecoder) decodeNetlify(b []byte) error {
+	m, err := metadecoders.Default.UnmarshalToMap(b, metadecoders.TOML)
+	if err != nil {
+		return err
+	}
+
+	var nc struct {
+		Headers   []Headers
+		Redirects []Redirect
+	}
+
+	if err := mapstructure.WeakDecode(m, &nc); err != nil {
+		return err
+	}
+
+	for _, h := range nc.Headers {
+		d.addHeaders(h)
+	}
+	for _, r := range nc.Redirects {
+		d.addRedirect(r)
+	}
+
+	return nil
+}
+
+func (d *hostDecoder) decodeVercel(b []byte) error {
+	var vc struct {
+		Headers []struct {
+			Source  string
+			Headers []struct {
+				Key   string
+				Value string
+			}
+		}
+		Redirects []struct {
+			Source      string
+			Destination string
+			Permanent   *bool
+			StatusCode  int
+		}
+		Rewrites []struct {
+			Source      string
+			Destination string
+		}
+	}
+
+	if err := json.Unmarshal(b, &vc); err != nil {
+		return err
+	}
+
+	for _, h := range vc.Headers {
+		values := make(map[string]interface{})
+		for _, kv := range h.Headers {
+			values[kv.Key] = kv.Value
+		}
+		d.addHeaders(Headers{For: h.Source, Values: values})
+	}
+
+	for _, r := range vc.Redirects {
+		status := r.StatusCode
+		if status == 0 {
+			status = 308
+			if r.Permanent != nil && !*r.Permanent {
+				status = 307
+			}
+		}
+		// Vercel's redirects always apply.
+		d.addRedirect(Redirect{From: r.Source, To: r.Destination, Status: status, Force: true})
+	}
+
+	for _, r := range vc.Rewrites {
+		d.addRedirect(Redirect{From: r.Source, To: r.Destination, Status: 200})
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/common/types"
+	"github.com/spf13/afero"
+)
+
+func TestDecodeHostServer(t *testing.T) {
+	c := qt.New(t)
+
+	fs := afero.NewMemMapFs()
+	for filename, content := range map[string]string{
+		"/site/netlify.toml": `
+[build]
+command = "hugo"
+
+[[redirects]]
+from = "/old/"
+to = "/new/"
+
+[[redirects]]
+from = "/api/*"
+to = "https://api.example.org/:splat"
+status = 200
+
+[[headers]]
+for = "/*"
+[headers.values]
+X-Frame-Options = "DENY"
+`,
+		"/site/static/_redirects": `
+# Comment
+/blog/*     /news/:splat    301!
+/docs/:section/:page  /documentation/:page  302
+/shop/*     /shop/index.html 200
+/app/*      /spa     200
+/geo  /us  302  Country=us
+/gone  /  410
+/old.html  /new.html  301
+/*  /404.html  404
+`,
+		"/site/static/_headers": `
+/*.jpg
+  Cache-Control: public
+  X-Custom: a
+  X-Custom: b
+`,
+		"/site/vercel.json": `{
+  "redirects": [
+    { "source": "/guides/:slug*", "destination": "/tutorials/:slug*" },
+    { "source": "/temp", "destination": "/other/", "permanent": false },
+    { "source": "/(.*)\\.php", "destination": "/" }
+  ],
+  "rewrites": [
+    { "source": "/dashboard/(.*)", "destination": "/dashboard/" }
+  ],
+  "headers": [
+    { "source": "/assets/(.*)", "headers": [{ "key": "X-Vercel", "value": "yes" }] }
+  ]
+}`,
+	} {
+		c.Assert(afero.WriteFile(fs, filename, []byte(content), 0666), qt.IsNil)
+	}
+
+	hs, err := DecodeHostServer(fs, "/site", "/site/static", "/site/nonexisting")
+	c.Assert(err, qt.IsNil)
+
+	c.Assert(hs.Filenames, qt.DeepEquals, []string{"/site/netlify.toml", "/site/vercel.json", "/site/static/_headers", "/site/static/_redirects"})
+
+	c.Assert(hs.Redirects, qt.DeepEquals, []Redirect{
+		{From: "/old/", To: "/new/", Status: 301},
+		{From: "/guides/*", To: "/tutorials/:splat", Status: 308, Force: true},
+		{From: "/temp", To: "/other/", Status: 307, Force: true},
+		{From: "/dashboard/*", To: "/dashboard/", Status: 200},
+		{From: "/blog/*", To: "/news/:splat", Status: 301, Force: true},
+		{From: "/shop/*", To: "/shop/", Status: 200},
+		{From: "/app/*", To: "/spa/", Status: 200},
+		{From: "/*", To: "/404.html", Status: 404},
+	})
+
+	c.Assert(hs.Headers, qt.DeepEquals, []Headers{
+		{For: "/*", Values: map[string]interface{}{"X-Frame-Options": "DENY"}},
+		{For: "/assets/*", Values: map[string]interface{}{"X-Vercel": "yes"}},
+		{For: "/*.jpg", Values: map[string]interface{}{"Cache-Control": "public", "X-Custom": "a, b"}},
+	})
+
+	c.Assert(hs.Warnings, qt.DeepEquals, []string{
+		`/site/netlify.toml: proxying "/api/*" to "https://api.example.org/:splat" is not supported`,
+		`/site/vercel.json: unsupported redirect from "/(.*)\\.php"`,
+		`/site/static/_redirects: unsupported redirect from "/docs/:section/:page" to "/documentation/:page", only a single trailing placeholder in from can be used in to`,
+		`/site/static/_redirects: unsupported redirect "/geo  /us  302  Country=us"`,
+		`/site/static/_redirects: unsupported status 410 for redirect from "/gone" to "/"`,
+		`/site/static/_redirects: unsupported redirect to value "/new.html"; currently this must be either a remote destination or a local folder, e.g. "/blog/" or "/blog/index.html"`,
+	})
+
+	s := &Server{Headers: hs.Headers, Redirects: hs.Redirects}
+
+	c.Assert(s.MatchRedirect("/blog/2021/hello/"), qt.DeepEquals, Redirect{From: "/blog/*", To: "/news/2021/hello/", Status: 301, Force: true})
+	c.Assert(s.MatchRedirect("/guides/intro/index.html"), qt.DeepEquals, Redirect{From: "/guides/*", To: "/tutorials/intro/", Status: 308, Force: true})
+	c.Assert(s.MatchRedirect("/nope/"), qt.DeepEquals, Redirect{From: "/*", To: "/404.html", Status: 404})
+	c.Assert(s.MatchHeaders("/images/cat.jpg"), qt.DeepEquals, []types.KeyValueStr{
+		{Key: "Cache-Control", Value: "public"},
+		{Key: "X-Custom", Value: "a, b"},
+		{Key: "X-Frame-Options", Value: "DENY"},
+	})
+}
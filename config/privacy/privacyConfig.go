@@ -15,6 +15,7 @@ package privacy
 
 import (
 	"github.com/gohugoio/hugo/config"
+	"github.com/gohugoio/hugo/config/configschema"
 	"github.com/mitchellh/mapstructure"
 )
 
@@ -112,3 +113,7 @@ func DecodeConfig(cfg config.Provider) (pc Config, err error) {
 
 	return
 }
+
+func init() {
+	configschema.AddSection(privacyConfigKey, Config{})
+}
@@ -0,0 +1,273 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package security contains the security policy used when Hugo runs
+// external commands or makes HTTP requests on behalf of the site and its
+// modules.
+package security
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gohugoio/hugo/config"
+	"github.com/gohugoio/hugo/config/configschema"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+	"github.com/spf13/cast"
+)
+
+const securityConfigKey = "security"
+
+// The maximum number of redirects followed, as in the net/http default.
+const maxRedirects = 10
+
+// DefaultConfig holds the default security policy.
+var DefaultConfig = Config{
+	Exec: Exec{
+		Allow: NewWhitelist(
+			"^dart-sass-embedded$",
+			"^git$", // for remote mounts
+			"^go$",  // for Go Modules
+			"^postcss$",
+		),
+		// These have been tested to work with Hugo's external programs
+		// on Windows, Linux and MacOS.
+		OsEnv: NewWhitelist("(?i)^(PATH|PATHEXT|APPDATA|TMP|TEMP|TERM)$"),
+	},
+	HTTP: HTTP{
+		Domains: NewWhitelist(".*"),
+		Methods: NewWhitelist("(?i)^(GET|HEAD|POST)$"),
+	},
+}
+
+// Config is the top level security config.
+type Config struct {
+	// Restricts access to os.Exec.
+	Exec Exec
+
+	// Restricts access to remote resources over HTTP(S).
+	HTTP HTTP
+}
+
+// Exec holds os/exec policies.
+type Exec struct {
+	// The commands that are allowed to run, matched on the command name.
+	Allow Whitelist
+
+	// The OS environment variables passed on to the commands.
+	OsEnv Whitelist
+
+	// Further restrictions for individual commands, keyed by the lower
+	// case command name.
+	Commands map[string]ExecCommand
+}
+
+// ExecCommand holds the os/exec policies for one command.
+type ExecCommand struct {
+	// If set, every argument passed to the command must match.
+	Args Whitelist
+
+	// If set, this replaces security.exec.osEnv for the command.
+	OsEnv Whitelist
+}
+
+// HTTP holds the policies for HTTP requests, e.g. in getJSON and getCSV.
+type HTTP struct {
+	// The host names allowed, matched without the port.
+	Domains Whitelist
+
+	// The HTTP methods allowed.
+	Methods Whitelist
+}
+
+// CheckAllowedExec returns an error if the given command, or any of the
+// given arguments, is not allowed by the security policy.
+func (c Config) CheckAllowedExec(name string, args ...string) error {
+	if !c.Exec.Allow.Accept(name) {
+		return &AccessDeniedError{
+			Name:     name,
+			Path:     "security.exec.allow",
+			policies: c.Exec.Allow.String(),
+		}
+	}
+
+	cmd, found := c.Exec.Commands[strings.ToLower(name)]
+	if !found || !cmd.Args.IsSet() {
+		return nil
+	}
+
+	for _, arg := range args {
+		if !cmd.Args.Accept(arg) {
+			return &AccessDeniedError{
+				Name:     arg,
+				Path:     "security.exec.commands." + strings.ToLower(name) + ".args",
+				policies: cmd.Args.String(),
+			}
+		}
+	}
+
+	return nil
+}
+
+// NewHTTPClient returns an HTTP client with the given timeout, zero for
+// none, that checks every redirect with CheckAllowedHTTP. Callers must still
+// check the first request.
+func (c Config) NewHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout:       timeout,
+		CheckRedirect: c.checkRedirect,
+	}
+}
+
+func (c Config) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return errors.Errorf("stopped after %d redirects", maxRedirects)
+	}
+	return c.CheckAllowedHTTP(req.Method, req.URL)
+}
+
+// CheckAllowedHTTP returns an error if a request with the given method to
+// the given URL is not allowed by the security policy.
+func (c Config) CheckAllowedHTTP(method string, u *url.URL) error {
+	if host := u.Hostname(); !c.HTTP.Domains.Accept(host) {
+		return &AccessDeniedError{
+			Name:     host,
+			Path:     "security.http.domains",
+			policies: c.HTTP.Domains.String(),
+		}
+	}
+	if !c.HTTP.Methods.Accept(method) {
+		return &AccessDeniedError{
+			Name:     method,
+			Path:     "security.http.methods",
+			policies: c.HTTP.Methods.String(),
+		}
+	}
+	return nil
+}
+
+// FilterOsEnv returns the OS environment variables in environ allowed by the
+// security policy.
+func (c Config) FilterOsEnv(environ []string) []string {
+	return filterOsEnv(c.Exec.OsEnv, environ)
+}
+
+// FilterOsEnvFor returns the OS environment variables in environ allowed by
+// the security policy for the given command.
+func (c Config) FilterOsEnvFor(name string, environ []string) []string {
+	if cmd, found := c.Exec.Commands[strings.ToLower(name)]; found && cmd.OsEnv.IsSet() {
+		return filterOsEnv(cmd.OsEnv, environ)
+	}
+	return c.FilterOsEnv(environ)
+}
+
+func filterOsEnv(w Whitelist, environ []string) []string {
+	var filtered []string
+	for _, v := range environ {
+		k, _ := config.SplitEnvVar(v)
+		if w.Accept(k) {
+			filtered = append(filtered, v)
+		}
+	}
+	return filtered
+}
+
+// AccessDeniedError is returned when an operation is denied by the
+// security policy.
+type AccessDeniedError struct {
+	// The command, argument, host name or HTTP method denied.
+	Name string
+
+	// The policy denying it, e.g. "security.http.domains".
+	Path string
+
+	policies string
+}
+
+func (e *AccessDeniedError) Error() string {
+	return fmt.Sprintf("access denied: %q is not whitelisted in policy %q; the current security configuration is: %s", e.Name, e.Path, e.policies)
+}
+
+// IsAccessDenied reports whether err is an AccessDeniedError.
+func IsAccessDenied(err error) bool {
+	_, ok := errors.Cause(err).(*AccessDeniedError)
+	return ok
+}
+
+// DecodeConfig creates a security Config from a given Hugo configuration.
+// Any value not set will use the default.
+func DecodeConfig(cfg config.Provider) (Config, error) {
+	sc := DefaultConfig
+	if !cfg.IsSet(securityConfigKey) {
+		return sc, nil
+	}
+
+	m := cfg.GetStringMap(securityConfigKey)
+
+	dec, err := mapstructure.NewDecoder(
+		&mapstructure.DecoderConfig{
+			WeaklyTypedInput: true,
+			Result:           &sc,
+			DecodeHook:       stringSliceToWhitelistHook(),
+		},
+	)
+	if err != nil {
+		return sc, err
+	}
+
+	if err := dec.Decode(m); err != nil {
+		return sc, errors.Wrap(err, "failed to decode security config")
+	}
+
+	commands := make(map[string]ExecCommand)
+	for name, cmd := range sc.Exec.Commands {
+		commands[strings.ToLower(name)] = cmd
+	}
+	sc.Exec.Commands = commands
+
+	return sc, nil
+}
+
+func stringSliceToWhitelistHook() mapstructure.DecodeHookFuncType {
+	return func(
+		f reflect.Type,
+		t reflect.Type,
+		data interface{}) (interface{}, error) {
+		if t != reflect.TypeOf(Whitelist{}) {
+			return data, nil
+		}
+
+		patterns, err := cast.ToStringSliceE(data)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, p := range patterns {
+			if _, err := regexp.Compile(p); err != nil {
+				return nil, errors.Wrapf(err, "invalid security pattern %q", p)
+			}
+		}
+
+		return NewWhitelist(patterns...), nil
+	}
+}
+
+func init() {
+	configschema.AddSection(securityConfigKey, DefaultConfig)
+}
@@ -0,0 +1,166 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package security
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/config"
+	"github.com/pkg/errors"
+)
+
+func TestDecodeConfig(t *testing.T) {
+	c := qt.New(t)
+
+	c.Run("Default", func(c *qt.C) {
+		pc, err := DecodeConfig(config.New())
+		c.Assert(err, qt.IsNil)
+		c.Assert(pc.CheckAllowedExec("postcss"), qt.IsNil)
+		c.Assert(pc.CheckAllowedExec("npx"), qt.Not(qt.IsNil))
+		c.Assert(pc.CheckAllowedExec("rm"), qt.Not(qt.IsNil))
+		c.Assert(IsAccessDenied(pc.CheckAllowedExec("rm")), qt.IsTrue)
+		c.Assert(pc.FilterOsEnv([]string{"PATH=/bin", "SECRET=foo"}), qt.DeepEquals, []string{"PATH=/bin"})
+	})
+
+	c.Run("Slice", func(c *qt.C) {
+		cfg, err := config.FromConfigString(`
+[security]
+[security.exec]
+allow=["a", "b"]
+osEnv=["^FOO$"]
+`, "toml")
+		c.Assert(err, qt.IsNil)
+
+		pc, err := DecodeConfig(cfg)
+		c.Assert(err, qt.IsNil)
+		c.Assert(pc.Exec.Allow.Accept("a"), qt.IsTrue)
+		c.Assert(pc.Exec.Allow.Accept("npx"), qt.IsFalse)
+		c.Assert(pc.Exec.OsEnv.Accept("FOO"), qt.IsTrue)
+		c.Assert(pc.Exec.OsEnv.Accept("PATH"), qt.IsFalse)
+	})
+
+	c.Run("String", func(c *qt.C) {
+		cfg, err := config.FromConfigString(`
+[security]
+[security.exec]
+allow="none"
+`, "toml")
+		c.Assert(err, qt.IsNil)
+
+		pc, err := DecodeConfig(cfg)
+		c.Assert(err, qt.IsNil)
+		c.Assert(pc.Exec.Allow.Accept("npx"), qt.IsFalse)
+		c.Assert(pc.Exec.Allow.String(), qt.Equals, "none")
+	})
+
+	c.Run("Commands", func(c *qt.C) {
+		cfg, err := config.FromConfigString(`
+[security.exec]
+allow=["^git$", "^go$"]
+[security.exec.commands.git]
+args=["^(fetch|checkout|-q)$"]
+osEnv=["^HOME$"]
+`, "toml")
+		c.Assert(err, qt.IsNil)
+
+		pc, err := DecodeConfig(cfg)
+		c.Assert(err, qt.IsNil)
+		c.Assert(pc.CheckAllowedExec("git", "fetch", "-q"), qt.IsNil)
+		err = pc.CheckAllowedExec("git", "fetch", "--upload-pack=evil")
+		c.Assert(IsAccessDenied(err), qt.IsTrue)
+		ade := err.(*AccessDeniedError)
+		c.Assert(ade.Name, qt.Equals, "--upload-pack=evil")
+		c.Assert(ade.Path, qt.Equals, "security.exec.commands.git.args")
+		c.Assert(pc.CheckAllowedExec("go", "anything"), qt.IsNil)
+		c.Assert(pc.FilterOsEnvFor("git", []string{"PATH=/bin", "HOME=/home"}), qt.DeepEquals, []string{"HOME=/home"})
+		c.Assert(pc.FilterOsEnvFor("go", []string{"PATH=/bin", "HOME=/home"}), qt.DeepEquals, []string{"PATH=/bin"})
+	})
+
+	c.Run("HTTP", func(c *qt.C) {
+		u, _ := url.Parse("https://example.org:8080/data.json")
+
+		pc, err := DecodeConfig(config.New())
+		c.Assert(err, qt.IsNil)
+		c.Assert(pc.CheckAllowedHTTP("GET", u), qt.IsNil)
+		c.Assert(IsAccessDenied(pc.CheckAllowedHTTP("DELETE", u)), qt.IsTrue)
+
+		cfg, err := config.FromConfigString(`
+[security.http]
+domains=["^example\\.com$"]
+`, "toml")
+		c.Assert(err, qt.IsNil)
+
+		pc, err = DecodeConfig(cfg)
+		c.Assert(err, qt.IsNil)
+		err = pc.CheckAllowedHTTP("GET", u)
+		c.Assert(IsAccessDenied(err), qt.IsTrue)
+		c.Assert(err.(*AccessDeniedError).Name, qt.Equals, "example.org")
+		c.Assert(err.(*AccessDeniedError).Path, qt.Equals, "security.http.domains")
+		u, _ = url.Parse("https://example.com/data.json")
+		c.Assert(pc.CheckAllowedHTTP("GET", u), qt.IsNil)
+	})
+
+	c.Run("Invalid", func(c *qt.C) {
+		cfg, err := config.FromConfigString(`
+[security.exec]
+allow="(a"
+`, "toml")
+		c.Assert(err, qt.IsNil)
+
+		_, err = DecodeConfig(cfg)
+		c.Assert(err, qt.Not(qt.IsNil))
+	})
+}
+
+func TestWhitelist(t *testing.T) {
+	c := qt.New(t)
+
+	c.Assert(NewWhitelist().Accept("foo"), qt.IsFalse)
+	c.Assert(NewWhitelist("none").Accept("foo"), qt.IsFalse)
+	c.Assert(NewWhitelist("^foo$", "none").Accept("foo"), qt.IsTrue)
+	c.Assert(NewWhitelist("^foo$").Accept("foobar"), qt.IsFalse)
+	c.Assert(NewWhitelist("^foo$", "^bar").String(), qt.Equals, "[^foo$ ^bar]")
+	c.Assert(NewWhitelist("none").IsSet(), qt.IsTrue)
+	c.Assert(Whitelist{}.IsSet(), qt.IsFalse)
+}
+
+func TestNewHTTPClient(t *testing.T) {
+	c := qt.New(t)
+
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer target.Close()
+	redirect := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, strings.Replace(target.URL, "127.0.0.1", "localhost", 1), http.StatusFound)
+	}))
+	defer redirect.Close()
+
+	sc := DefaultConfig
+	sc.HTTP.Domains = NewWhitelist(`^127\.0\.0\.1$`)
+	_, err := sc.NewHTTPClient(0).Get(redirect.URL)
+	c.Assert(err, qt.Not(qt.IsNil))
+	c.Assert(IsAccessDenied(errors.Cause(err.(*url.Error).Err)), qt.IsTrue)
+
+	sc.HTTP.Domains = NewWhitelist(`^127\.0\.0\.1$`, "^localhost$")
+	res, err := sc.NewHTTPClient(0).Get(redirect.URL)
+	c.Assert(err, qt.IsNil)
+	res.Body.Close()
+	c.Assert(res.StatusCode, qt.Equals, http.StatusOK)
+}
@@ -0,0 +1,110 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package security
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gohugoio/hugo/config/configschema"
+)
+
+const acceptNoneKeyword = "none"
+
+// Whitelist holds a whitelist of regular expressions.
+type Whitelist struct {
+	acceptNone bool
+	patterns   []*regexp.Regexp
+
+	// Store this for debugging/error reporting
+	patternsStrings []string
+}
+
+// ConfigSchema returns the schema for a Whitelist, one or more patterns
+// or "none".
+func (w Whitelist) ConfigSchema() *configschema.Schema {
+	return &configschema.Schema{
+		Type:  []string{configschema.TypeArray, configschema.TypeString},
+		Items: &configschema.Schema{Type: configschema.TypeString},
+	}
+}
+
+// NewWhitelist creates a new Whitelist from zero or more patterns.
+// An empty patterns list or a pattern with the value 'none' will create
+// a whitelist that will Accept noone.
+func NewWhitelist(patterns ...string) Whitelist {
+	if len(patterns) == 0 {
+		return Whitelist{acceptNone: true}
+	}
+
+	var acceptSome bool
+	var patternsStrings []string
+
+	for _, p := range patterns {
+		if p == acceptNoneKeyword {
+			continue
+		}
+
+		if p != "" {
+			acceptSome = true
+			patternsStrings = append(patternsStrings, p)
+		}
+	}
+
+	if !acceptSome {
+		return Whitelist{
+			acceptNone: true,
+		}
+	}
+
+	var patternsr []*regexp.Regexp
+
+	for i := 0; i < len(patterns); i++ {
+		p := strings.TrimSpace(patterns[i])
+		if p == "" || p == acceptNoneKeyword {
+			continue
+		}
+		patternsr = append(patternsr, regexp.MustCompile(p))
+	}
+
+	return Whitelist{patterns: patternsr, patternsStrings: patternsStrings}
+}
+
+// Accept reports whether name is whitelisted.
+func (w Whitelist) Accept(name string) bool {
+	if w.acceptNone {
+		return false
+	}
+
+	for _, p := range w.patterns {
+		if p.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSet reports whether w was created with NewWhitelist, i.e. is not the
+// zero value.
+func (w Whitelist) IsSet() bool {
+	return w.acceptNone || len(w.patterns) > 0
+}
+
+func (w Whitelist) String() string {
+	if w.acceptNone {
+		return acceptNoneKeyword
+	}
+	return fmt.Sprint(w.patternsStrings)
+}
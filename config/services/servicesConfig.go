@@ -14,8 +14,14 @@
 package services
 
 import (
+	"strings"
+
+	"github.com/gohugoio/hugo/common/maps"
 	"github.com/gohugoio/hugo/config"
+	"github.com/gohugoio/hugo/config/configschema"
 	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+	"github.com/spf13/cast"
 )
 
 const (
@@ -70,8 +76,106 @@ type Twitter struct {
 
 // RSS holds the functional configuration settings related to the RSS feeds.
 type RSS struct {
+	// The settings for all feeds.
+	RSSFeed `mapstructure:",squash"`
+
+	// The settings for the feeds of the given page kinds, e.g. "home" or
+	// "term", on top of the settings above.
+	Kinds map[string]RSSFeed `mapstructure:"-"`
+
+	// The settings for the feeds of the given sections, on top of the
+	// settings for the "section" kind.
+	Sections map[string]RSSFeed `mapstructure:"-"`
+}
+
+// RSSFeed holds the settings for a single RSS feed.
+type RSSFeed struct {
 	// Limit the number of pages.
 	Limit int
+
+	// Use the full content instead of the summary in the item descriptions.
+	FullContent bool
+
+	// Include the pages in nested sections in a section's feed.
+	IncludeSubpages bool
+
+	// Exclude the pages in these sections from the feed.
+	ExcludeSections []string
+}
+
+// Feed returns the settings for the feed of a page with the given kind in
+// the given section.
+func (r RSS) Feed(kind, section string) RSSFeed {
+	if kind == "section" {
+		if f, found := r.Sections[section]; found {
+			return f
+		}
+	}
+	if f, found := r.Kinds[kind]; found {
+		return f
+	}
+	return r.RSSFeed
+}
+
+func decodeRSS(cfg config.Provider, m map[string]interface{}, r *RSS) error {
+	base := make(map[string]interface{})
+	var kinds, sections map[string]interface{}
+	for k, v := range m {
+		switch strings.ToLower(k) {
+		case "kinds":
+			kinds = maps.ToStringMap(v)
+		case "sections":
+			sections = maps.ToStringMap(v)
+		default:
+			base[strings.ToLower(k)] = v
+		}
+	}
+
+	if cast.ToInt(base["limit"]) == 0 {
+		// Keep backwards compatibility.
+		base["limit"] = cfg.GetInt(rssLimitKey)
+	}
+
+	merge := func(ms ...map[string]interface{}) map[string]interface{} {
+		merged := make(map[string]interface{})
+		for _, m := range ms {
+			for k, v := range m {
+				merged[strings.ToLower(k)] = v
+			}
+		}
+		return merged
+	}
+
+	decode := func(m map[string]interface{}) (f RSSFeed, err error) {
+		err = mapstructure.WeakDecode(m, &f)
+		return
+	}
+
+	var err error
+	if r.RSSFeed, err = decode(base); err != nil {
+		return err
+	}
+
+	if len(kinds) > 0 {
+		r.Kinds = make(map[string]RSSFeed)
+		for kind, v := range kinds {
+			if r.Kinds[strings.ToLower(kind)], err = decode(merge(base, maps.ToStringMap(v))); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(sections) > 0 {
+		sectionBase := merge(base, maps.ToStringMap(kinds["section"]))
+		r.Sections = make(map[string]RSSFeed)
+		for section, v := range sections {
+			if r.Sections[section], err = decode(merge(sectionBase, maps.ToStringMap(v))); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
 }
 
 // DecodeConfig creates a services Config from a given Hugo configuration.
@@ -89,9 +193,18 @@ func DecodeConfig(cfg config.Provider) (c Config, err error) {
 		c.Disqus.Shortname = cfg.GetString(disqusShortnameKey)
 	}
 
-	if c.RSS.Limit == 0 {
-		c.RSS.Limit = cfg.GetInt(rssLimitKey)
+	if err != nil {
+		return
+	}
+
+	err = decodeRSS(cfg, maps.ToStringMap(m["rss"]), &c.RSS)
+	if err != nil {
+		err = errors.Wrap(err, "failed to decode services.rss config")
 	}
 
 	return
 }
+
+func init() {
+	configschema.AddSection(servicesConfigKey, Config{})
+}
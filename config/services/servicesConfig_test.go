@@ -66,3 +66,32 @@ func TestUseSettingsFromRootIfSet(t *testing.T) {
 	c.Assert(config.Disqus.Shortname, qt.Equals, "root_short")
 	c.Assert(config.GoogleAnalytics.ID, qt.Equals, "ga_root")
 }
+
+func TestDecodeRSSConfig(t *testing.T) {
+	c := qt.New(t)
+
+	cfg, err := config.FromConfigString(`
+rssLimit = 5
+[services.rss]
+excludeSections = ["legal"]
+[services.rss.kinds.section]
+fullContent = true
+[services.rss.kinds.term]
+limit = 3
+[services.rss.sections.blog]
+includeSubpages = true
+`, "toml")
+	c.Assert(err, qt.IsNil)
+
+	config, err := DecodeConfig(cfg)
+	c.Assert(err, qt.IsNil)
+
+	rss := config.RSS
+	c.Assert(rss.Limit, qt.Equals, 5)
+	c.Assert(rss.Feed("home", ""), qt.DeepEquals, RSSFeed{Limit: 5, ExcludeSections: []string{"legal"}})
+	c.Assert(rss.Feed("term", "tags"), qt.DeepEquals, RSSFeed{Limit: 3, ExcludeSections: []string{"legal"}})
+	c.Assert(rss.Feed("section", "docs"), qt.DeepEquals, RSSFeed{Limit: 5, FullContent: true, ExcludeSections: []string{"legal"}})
+	c.Assert(rss.Feed("section", "blog"), qt.DeepEquals, RSSFeed{Limit: 5, FullContent: true, IncludeSubpages: true, ExcludeSections: []string{"legal"}})
+	// Section settings only apply to section feeds.
+	c.Assert(rss.Feed("page", "blog"), qt.DeepEquals, RSSFeed{Limit: 5, ExcludeSections: []string{"legal"}})
+}
@@ -102,23 +102,28 @@ func NewContent(
 
 	jww.FEEDBACK.Println(contentPath, "created")
 
+	return openInEditor(s, targetPath, contentPath)
+}
+
+// openInEditor opens contentPath in the newContentEditor, if set.
+func openInEditor(s *hugolib.Site, targetPath, contentPath string) error {
 	editor := s.Cfg.GetString("newContentEditor")
-	if editor != "" {
-		jww.FEEDBACK.Printf("Editing %s with %q ...\n", targetPath, editor)
+	if editor == "" {
+		return nil
+	}
 
-		editorCmd := append(strings.Fields(editor), contentPath)
-		cmd, err := hexec.SafeCommand(editorCmd[0], editorCmd[1:]...)
-		if err != nil {
-			return err
-		}
-		cmd.Stdin = os.Stdin
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
+	jww.FEEDBACK.Printf("Editing %s with %q ...\n", targetPath, editor)
 
-		return cmd.Run()
+	editorCmd := append(strings.Fields(editor), contentPath)
+	cmd, err := hexec.SafeCommand(editorCmd[0], editorCmd[1:]...)
+	if err != nil {
+		return err
 	}
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
 
-	return nil
+	return cmd.Run()
 }
 
 func targetSite(sites *hugolib.HugoSites, fi hugofs.FileMetaInfo) *hugolib.Site {
@@ -308,22 +313,76 @@ func resolveContentPath(sites *hugolib.HugoSites, fs afero.Fs, targetPath string
 		}
 	}
 
-	if siteContentDir == "" {
-	}
-
 	if siteContentDir != "" {
 		pp := filepath.Join(siteContentDir, strings.TrimPrefix(targetPath, siteContentDir))
 		return s.PathSpec.AbsPathify(pp), s
-	} else {
-		var contentDir string
-		for _, dir := range sites.BaseFs.Content.Dirs {
-			contentDir = dir.Meta().Filename()
-			if dir.Meta().Lang() == s.Lang() {
-				break
+	}
+
+	if filename := resolveMountedContentPath(sites, s, targetPath); filename != "" {
+		return s.PathSpec.AbsPathify(filename), s
+	}
+
+	var contentDir string
+	for _, dir := range sites.BaseFs.Content.Dirs {
+		contentDir = dir.Meta().Filename()
+		if dir.Meta().Lang() == s.Lang() {
+			break
+		}
+	}
+	return s.PathSpec.AbsPathify(filepath.Join(contentDir, targetPath)), s
+}
+
+// resolveMountedContentPath resolves targetPath, relative to the content root,
+// to a filename in the source directory of the content mount it belongs to,
+// e.g. "docs/intro.md" to "/mydocs/intro.md" given a mount of "/mydocs" to
+// "content/docs". The mount with the longest matching path wins, and mounts
+// for the site's language are preferred.
+// Modules in the module cache are skipped, but local, e.g. replaced, modules
+// are not. It returns an empty string if no mount matches.
+func resolveMountedContentPath(sites *hugolib.HugoSites, s *hugolib.Site, targetPath string) string {
+	readOnly := make(map[string]bool)
+	for _, m := range sites.PathSpec.AllModules {
+		if m.Vendor() || (m.IsGoMod() && m.Replace() == nil) {
+			readOnly[m.Path()] = true
+		}
+	}
+
+	var (
+		filename    string
+		longest     = -1
+		langMatches bool
+	)
+
+	for _, dir := range sites.BaseFs.Content.Dirs {
+		meta := dir.Meta()
+		if !dir.IsDir() || readOnly[meta.Module()] {
+			continue
+		}
+
+		mountRoot := meta.MountRoot()
+		rel := targetPath
+		if mountRoot != "" {
+			if !strings.HasPrefix(targetPath, mountRoot+helpers.FilePathSeparator) {
+				continue
 			}
+			rel = strings.TrimPrefix(targetPath, mountRoot+helpers.FilePathSeparator)
+		}
+
+		if !meta.InclusionFilter().Match(rel, false) {
+			continue
 		}
-		return s.PathSpec.AbsPathify(filepath.Join(contentDir, targetPath)), s
+
+		langMatch := meta.Lang() == s.Lang()
+		if len(mountRoot) < longest || (len(mountRoot) == longest && (langMatches || !langMatch)) {
+			continue
+		}
+
+		filename = filepath.Join(meta.Filename(), rel)
+		longest = len(mountRoot)
+		langMatches = langMatch
 	}
+
+	return filename
 }
 
 // FindArchetype takes a given kind/archetype of content and returns the path
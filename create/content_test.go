@@ -142,6 +142,50 @@ i18n: {{ T "hugo" }}
 	cContains(c, readFileFromFs(t, fs.Source, filepath.Join("content", "post/my-theme-post/resources/hugo1.json")), `hugo1: {{ printf "no template handling in here" }}`)
 }
 
+func TestNewContentInMount(t *testing.T) {
+	mm := afero.NewMemMapFs()
+	c := qt.New(t)
+
+	c.Assert(initFs(mm), qt.IsNil)
+	c.Assert(mm.MkdirAll("mydocs", 0777), qt.IsNil)
+	c.Assert(mm.MkdirAll(filepath.Join("themes", "mytheme", "themeblog"), 0777), qt.IsNil)
+	c.Assert(afero.WriteFile(mm, filepath.Join("themes", "mytheme", "config.toml"), []byte(`
+[module]
+[[module.mounts]]
+source = "themeblog"
+target = "content/blog"
+`), 0755), qt.IsNil)
+
+	cfg := `
+theme = "mytheme"
+
+[module]
+[[module.mounts]]
+source = "content"
+target = "content"
+[[module.mounts]]
+source = "mydocs"
+target = "content/docs"
+`
+	c.Assert(afero.WriteFile(mm, "config.toml", []byte(cfg), 0755), qt.IsNil)
+
+	v, _, err := hugolib.LoadConfig(hugolib.ConfigSourceDescriptor{Fs: mm, Filename: "config.toml"})
+	c.Assert(err, qt.IsNil)
+	fs := hugofs.NewFrom(mm, v)
+
+	h, err := hugolib.NewHugoSites(deps.DepsCfg{Cfg: v, Fs: fs})
+	c.Assert(err, qt.IsNil)
+
+	c.Assert(create.NewContent(h, "", filepath.FromSlash("docs/intro.md")), qt.IsNil)
+	cContains(c, readFileFromFs(t, fs.Source, filepath.Join("mydocs", "intro.md")), `title: "Intro"`)
+
+	c.Assert(create.NewContent(h, "", filepath.FromSlash("blog/first.md")), qt.IsNil)
+	cContains(c, readFileFromFs(t, fs.Source, filepath.Join("themes", "mytheme", "themeblog", "first.md")), `title: "First"`)
+
+	c.Assert(create.NewContent(h, "", filepath.FromSlash("post/other.md")), qt.IsNil)
+	cContains(c, readFileFromFs(t, fs.Source, filepath.Join("content", "post", "other.md")), `title: "Other"`)
+}
+
 func initFs(fs afero.Fs) error {
 	perm := os.FileMode(0755)
 	var err error
@@ -0,0 +1,448 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package create
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gohugoio/hugo/common/paths"
+	"github.com/gohugoio/hugo/config/security"
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/gohugoio/hugo/hugolib"
+	"github.com/gohugoio/hugo/media"
+	"github.com/gohugoio/hugo/parser"
+	"github.com/gohugoio/hugo/parser/metadecoders"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"github.com/spf13/cast"
+	jww "github.com/spf13/jwalterweatherman"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+// How long to wait for the page and for each of its images.
+var fetchTimeout = 30 * time.Second
+
+// The image types downloaded into the bundle.
+var imageTypes = media.Types{
+	media.BMPType,
+	media.GIFType,
+	media.JPEGType,
+	media.PNGType,
+	media.SVGType,
+	media.TIFFType,
+	media.WEBPType,
+}
+
+// NewContentFromURL creates a new page bundle at targetPath from the web
+// page at rawURL. The main content of the page is converted to Markdown,
+// the images in it are downloaded into the bundle, and the title, date,
+// description and canonical URL of the page are set in front matter. The
+// bundle is named after the page title if targetPath is empty.
+func NewContentFromURL(sites *hugolib.HugoSites, targetPath, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.Errorf("invalid URL %q, must be an absolute http or https URL", rawURL)
+	}
+
+	s := sites.Sites[0]
+	sc, ok := s.Cfg.Get("securityConfig").(security.Config)
+	if !ok {
+		sc = security.DefaultConfig
+	}
+	f := newURLFetcher(sc)
+
+	jww.INFO.Printf("fetching %s", u)
+
+	doc, pageURL, err := f.fetchHTML(u)
+	if err != nil {
+		return err
+	}
+
+	a := newArticle(doc, pageURL)
+
+	if targetPath == "" {
+		if a.title == "" {
+			return errors.Errorf("%s has no title, the path needs to be provided", u)
+		}
+		targetPath = s.PathSpec.URLize(a.title)
+	}
+
+	// The content goes into a leaf bundle, e.g. posts/my-post/index.md
+	// given posts/my-post.md.
+	targetPath = filepath.Clean(targetPath)
+	targetPath = strings.TrimSuffix(targetPath, paths.Ext(targetPath))
+	if filepath.Base(targetPath) == "index" {
+		targetPath = filepath.Dir(targetPath)
+	}
+	targetPath = filepath.Join(targetPath, "index.md")
+
+	contentPath, s := resolveContentPath(sites, s.Fs.Source, targetPath)
+	if exists, _ := helpers.Exists(contentPath, s.Fs.Source); exists {
+		return errors.Errorf("%s already exists", contentPath)
+	}
+
+	images := f.downloadImages(a.images, filepath.Dir(contentPath), s.Fs.Source)
+
+	c := &mdConverter{base: a.base, images: images}
+	body := c.convert(a.main)
+
+	fm := map[string]interface{}{
+		"title":        a.title,
+		"date":         a.date,
+		"draft":        true,
+		"canonicalURL": a.canonicalURL,
+	}
+	if a.description != "" {
+		fm["description"] = a.description
+	}
+
+	var buf bytes.Buffer
+	if err := parser.InterfaceToFrontMatter(fm, metadecoders.TOML, &buf); err != nil {
+		return err
+	}
+	buf.WriteString("\n")
+	buf.WriteString(body)
+
+	if err := helpers.SafeWriteToDisk(contentPath, &buf, s.Fs.Source); err != nil {
+		return err
+	}
+
+	jww.FEEDBACK.Println(contentPath, "created")
+
+	return openInEditor(s, targetPath, contentPath)
+}
+
+// article is the content and metadata extracted from a web page.
+type article struct {
+	title        string
+	description  string
+	date         time.Time
+	canonicalURL string
+
+	// The element holding the main content.
+	main *html.Node
+
+	// The URL to resolve relative URLs against.
+	base *url.URL
+
+	// The absolute URLs of the images in the main content.
+	images []*url.URL
+}
+
+func newArticle(doc *html.Node, pageURL *url.URL) *article {
+	a := &article{base: pageURL}
+
+	var (
+		meta      = make(map[string]string)
+		ld        []interface{}
+		title     string
+		canonical string
+	)
+
+	walk(doc, func(n *html.Node) bool {
+		switch n.Data {
+		case "meta":
+			key := strings.ToLower(first(attr(n, "property"), attr(n, "name"), attr(n, "itemprop")))
+			if _, found := meta[key]; key != "" && !found {
+				meta[key] = strings.TrimSpace(attr(n, "content"))
+			}
+		case "title":
+			if title == "" {
+				title = strings.TrimSpace(collapseSpace(textContent(n)))
+			}
+		case "base":
+			if b, err := pageURL.Parse(attr(n, "href")); err == nil && attr(n, "href") != "" {
+				a.base = b
+			}
+		case "link":
+			for _, rel := range strings.Fields(strings.ToLower(attr(n, "rel"))) {
+				if rel == "canonical" && canonical == "" {
+					canonical = attr(n, "href")
+				}
+			}
+		case "script":
+			if strings.EqualFold(attr(n, "type"), "application/ld+json") {
+				var v interface{}
+				if err := json.Unmarshal([]byte(textContent(n)), &v); err == nil {
+					ld = append(ld, v)
+				}
+			}
+		case "svg":
+			// Skip any title in inline SVG.
+			return false
+		}
+		return true
+	})
+
+	removeAll(doc, func(n *html.Node) bool { return skipElements[n.Data] || isHidden(n) })
+
+	a.main = findMainContent(doc)
+
+	// The heading and the date are usually found in the header of an
+	// article element, which isn't part of the converted content.
+	var h1, datetime string
+	walk(a.main, func(n *html.Node) bool {
+		switch n.Data {
+		case "h1":
+			if h1 == "" {
+				h1 = strings.TrimSpace(collapseSpace(textContent(n)))
+			}
+		case "time":
+			if datetime == "" {
+				datetime = attr(n, "datetime")
+			}
+		}
+		return true
+	})
+
+	a.title = first(meta["og:title"], meta["twitter:title"], jsonLDString(ld, "headline"), h1, title)
+	a.description = first(meta["description"], meta["og:description"])
+
+	for _, d := range []string{
+		meta["article:published_time"],
+		jsonLDString(ld, "datePublished"),
+		meta["datepublished"],
+		meta["date"],
+		meta["dc.date"],
+		datetime,
+	} {
+		if t, err := cast.ToTimeE(strings.TrimSpace(d)); err == nil && d != "" && !t.IsZero() {
+			a.date = t
+			break
+		}
+	}
+	if a.date.IsZero() {
+		a.date = time.Now()
+	}
+
+	a.canonicalURL = pageURL.String()
+	if ref := first(canonical, meta["og:url"]); ref != "" {
+		if cu, err := a.base.Parse(ref); err == nil {
+			a.canonicalURL = cu.String()
+		}
+	}
+
+	removeAll(a.main, func(n *html.Node) bool { return chromeElements[n.Data] && n != a.main })
+
+	// Avoid repeating the title in the content.
+	removedTitle := false
+	removeAll(a.main, func(n *html.Node) bool {
+		if !removedTitle && n.Data == "h1" && strings.TrimSpace(collapseSpace(textContent(n))) == a.title {
+			removedTitle = true
+			return true
+		}
+		return false
+	})
+
+	seen := make(map[string]bool)
+	walk(a.main, func(n *html.Node) bool {
+		if n.Data != "img" {
+			return true
+		}
+		src := imageSource(n)
+		if src == "" || strings.HasPrefix(src, "data:") {
+			return true
+		}
+		iu, err := a.base.Parse(src)
+		if err != nil || (iu.Scheme != "http" && iu.Scheme != "https") || seen[iu.String()] {
+			return true
+		}
+		seen[iu.String()] = true
+		a.images = append(a.images, iu)
+		return true
+	})
+
+	return a
+}
+
+// jsonLDString returns the first string value of key found in the JSON-LD
+// documents in ld.
+func jsonLDString(ld []interface{}, key string) string {
+	var find func(v interface{}) string
+	find = func(v interface{}) string {
+		switch vv := v.(type) {
+		case map[string]interface{}:
+			if s, ok := vv[key].(string); ok && s != "" {
+				return s
+			}
+			for _, v := range vv {
+				if s := find(v); s != "" {
+					return s
+				}
+			}
+		case []interface{}:
+			for _, v := range vv {
+				if s := find(v); s != "" {
+					return s
+				}
+			}
+		}
+		return ""
+	}
+	return find(ld)
+}
+
+func first(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+type urlFetcher struct {
+	client   *http.Client
+	security security.Config
+}
+
+func newURLFetcher(sc security.Config) *urlFetcher {
+	return &urlFetcher{
+		client:   sc.NewHTTPClient(fetchTimeout),
+		security: sc,
+	}
+}
+
+func (f *urlFetcher) get(u *url.URL, accept string) (*http.Response, error) {
+	if err := f.security.CheckAllowedHTTP(http.MethodGet, u); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "Hugo Static Site Generator")
+	req.Header.Set("Accept", accept)
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to fetch %s", u)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, errors.Errorf("failed to fetch %s: %s", u, resp.Status)
+	}
+
+	return resp, nil
+}
+
+// fetchHTML fetches and parses the HTML page at u. It also returns the URL
+// of the page after any redirects.
+func (f *urlFetcher) fetchHTML(u *url.URL) (*html.Node, *url.URL, error) {
+	resp, err := f.get(u, "text/html,application/xhtml+xml")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	contentType := resp.Header.Get("Content-Type")
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil && mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, nil, errors.Errorf("%s is not an HTML page but %s", u, mediaType)
+	}
+
+	r, err := charset.NewReader(resp.Body, contentType)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to read %s", u)
+	}
+
+	doc, err := html.Parse(r)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to parse %s", u)
+	}
+
+	return doc, resp.Request.URL, nil
+}
+
+// downloadImages downloads the images to dir. It returns a map of the
+// absolute URL to the filename of the images downloaded. Images failing to
+// download are logged and keep their remote URL.
+func (f *urlFetcher) downloadImages(urls []*url.URL, dir string, fs afero.Fs) map[string]string {
+	images := make(map[string]string)
+	used := make(map[string]bool)
+	taken := func(filename string) bool {
+		if used[filename] {
+			return true
+		}
+		exists, _ := helpers.Exists(filepath.Join(dir, filename), fs)
+		return exists
+	}
+
+	for _, u := range urls {
+		filename, err := f.downloadImage(u, dir, fs, taken)
+		if err != nil {
+			jww.WARN.Printf("failed to download image: %s", err)
+			continue
+		}
+		images[u.String()] = filename
+		used[filename] = true
+	}
+
+	return images
+}
+
+func (f *urlFetcher) downloadImage(u *url.URL, dir string, fs afero.Fs, taken func(string) bool) (string, error) {
+	resp, err := f.get(u, "image/*")
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	filename := imageFilename(u, mediaType, taken)
+
+	if err := helpers.SafeWriteToDisk(filepath.Join(dir, filename), resp.Body, fs); err != nil {
+		return "", errors.Wrapf(err, "failed to save %s", u)
+	}
+
+	return filename, nil
+}
+
+var unsafeFilenameRe = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// imageFilename returns a filename for the image at u, not taken, with the
+// extension of the media type if the URL doesn't have an image extension.
+func imageFilename(u *url.URL, mediaType string, taken func(string) bool) string {
+	name := path.Base(u.Path)
+	ext := strings.ToLower(path.Ext(name))
+	base := strings.Trim(unsafeFilenameRe.ReplaceAllString(strings.TrimSuffix(name, path.Ext(name)), "-"), "-")
+	if base == "" {
+		base = "image"
+	}
+
+	if _, _, found := imageTypes.GetFirstBySuffix(strings.TrimPrefix(ext, ".")); !found {
+		ext = ""
+		if t, found := imageTypes.GetByType(mediaType); found {
+			ext = "." + t.FirstSuffix.Suffix
+		}
+	}
+
+	filename := base + ext
+	for i := 1; taken(filename); i++ {
+		filename = fmt.Sprintf("%s-%d%s", base, i, ext)
+	}
+
+	return filename
+}
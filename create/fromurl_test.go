@@ -0,0 +1,130 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package create_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/create"
+	"github.com/gohugoio/hugo/deps"
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/gohugoio/hugo/hugolib"
+	"github.com/spf13/afero"
+)
+
+func TestNewContentFromURL(t *testing.T) {
+	c := qt.New(t)
+
+	page := `<!DOCTYPE html>
+<html>
+<head>
+<title>My Imported Post | The Blog</title>
+<meta property="og:title" content="My Imported Post">
+<meta name="description" content="A post about things.">
+<link rel="canonical" href="/blog/my-imported-post/">
+<script type="application/ld+json">{"@context":"https://schema.org","@type":"BlogPosting","datePublished":"2021-06-01T10:00:00Z"}</script>
+<style>body { color: red; }</style>
+</head>
+<body>
+<nav><a href="/">Home</a> <a href="/blog/">Blog</a></nav>
+<article>
+<header><h1>My Imported Post</h1><time datetime="2020-01-01">January 1, 2020</time></header>
+<p>Some <em>emphasis</em> and <a href="/other/">a link</a>.</p>
+<p><img src="/images/photo.jpg" alt="A photo"></p>
+<p><img src="/image?id=2" alt="Generated"></p>
+<p><img src="/images/missing.png" alt="Missing"></p>
+<script>alert("hi")</script>
+<footer>Share this post</footer>
+</article>
+<footer>Copyright</footer>
+</body>
+</html>`
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/blog/my-imported-post/":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			fmt.Fprint(w, page)
+		case "/images/photo.jpg":
+			w.Header().Set("Content-Type", "image/jpeg")
+			fmt.Fprint(w, "jpg")
+		case "/image":
+			w.Header().Set("Content-Type", "image/png")
+			fmt.Fprint(w, "png")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	newSites := func(c *qt.C) (*hugolib.HugoSites, afero.Fs) {
+		mm := afero.NewMemMapFs()
+		c.Assert(initFs(mm), qt.IsNil)
+		cfg, fs := newTestCfg(c, mm)
+		h, err := hugolib.NewHugoSites(deps.DepsCfg{Cfg: cfg, Fs: fs})
+		c.Assert(err, qt.IsNil)
+		return h, fs.Source
+	}
+
+	c.Run("Bundle", func(c *qt.C) {
+		h, fs := newSites(c)
+
+		c.Assert(create.NewContentFromURL(h, "post/my-post.md", ts.URL+"/blog/my-imported-post/"), qt.IsNil)
+
+		content := readFileFromFs(t, fs, filepath.Join("content", "post", "my-post", "index.md"))
+		cContains(c, content,
+			`title = "My Imported Post"`,
+			`date = 2021-06-01T10:00:00Z`,
+			`draft = true`,
+			`description = "A post about things."`,
+			fmt.Sprintf(`canonicalURL = "%s/blog/my-imported-post/"`, ts.URL),
+			fmt.Sprintf("Some *emphasis* and [a link](%s/other/).", ts.URL),
+			"![A photo](photo.jpg)",
+			"![Generated](image.png)",
+			fmt.Sprintf("![Missing](%s/images/missing.png)", ts.URL),
+		)
+		c.Assert(content, qt.Not(qt.Contains), "# My Imported Post")
+		c.Assert(content, qt.Not(qt.Contains), "Home")
+		c.Assert(content, qt.Not(qt.Contains), "Share this post")
+		c.Assert(content, qt.Not(qt.Contains), "Copyright")
+		c.Assert(content, qt.Not(qt.Contains), "alert")
+
+		c.Assert(readFileFromFs(t, fs, filepath.Join("content", "post", "my-post", "photo.jpg")), qt.Equals, "jpg")
+		c.Assert(readFileFromFs(t, fs, filepath.Join("content", "post", "my-post", "image.png")), qt.Equals, "png")
+
+		err := create.NewContentFromURL(h, "post/my-post", ts.URL+"/blog/my-imported-post/")
+		c.Assert(err, qt.ErrorMatches, ".*already exists")
+	})
+
+	c.Run("Path from title", func(c *qt.C) {
+		h, fs := newSites(c)
+
+		c.Assert(create.NewContentFromURL(h, "", ts.URL+"/blog/my-imported-post/"), qt.IsNil)
+		exists, _ := helpers.Exists(filepath.Join("content", "my-imported-post", "index.md"), fs)
+		c.Assert(exists, qt.IsTrue)
+	})
+
+	c.Run("Errors", func(c *qt.C) {
+		h, _ := newSites(c)
+
+		c.Assert(create.NewContentFromURL(h, "post/foo", "example.org/foo"), qt.ErrorMatches, `invalid URL.*`)
+		c.Assert(create.NewContentFromURL(h, "post/foo", ts.URL+"/nope/"), qt.ErrorMatches, `failed to fetch.*404 Not Found`)
+		c.Assert(create.NewContentFromURL(h, "post/foo", ts.URL+"/images/photo.jpg"), qt.ErrorMatches, `.*is not an HTML page but image/jpeg`)
+	})
+}
@@ -0,0 +1,636 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package create
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Elements that are never part of the converted content.
+var skipElements = map[string]bool{
+	"button":   true,
+	"canvas":   true,
+	"embed":    true,
+	"form":     true,
+	"iframe":   true,
+	"input":    true,
+	"link":     true,
+	"meta":     true,
+	"noscript": true,
+	"object":   true,
+	"script":   true,
+	"select":   true,
+	"style":    true,
+	"svg":      true,
+	"template": true,
+	"textarea": true,
+}
+
+// Elements inside the main content that are most likely page chrome.
+var chromeElements = map[string]bool{
+	"aside":  true,
+	"footer": true,
+	"header": true,
+	"nav":    true,
+}
+
+var blockElements = map[string]bool{
+	"address":    true,
+	"article":    true,
+	"aside":      true,
+	"blockquote": true,
+	"dd":         true,
+	"details":    true,
+	"div":        true,
+	"dl":         true,
+	"dt":         true,
+	"fieldset":   true,
+	"figcaption": true,
+	"figure":     true,
+	"footer":     true,
+	"h1":         true,
+	"h2":         true,
+	"h3":         true,
+	"h4":         true,
+	"h5":         true,
+	"h6":         true,
+	"header":     true,
+	"hgroup":     true,
+	"hr":         true,
+	"li":         true,
+	"main":       true,
+	"nav":        true,
+	"ol":         true,
+	"p":          true,
+	"pre":        true,
+	"section":    true,
+	"summary":    true,
+	"table":      true,
+	"ul":         true,
+}
+
+// findMainContent returns the element in doc holding the main content of
+// the page: the element marked as the article body, the largest article
+// or the main element if found, else the element with the most paragraph
+// text, falling back to the body.
+func findMainContent(doc *html.Node) *html.Node {
+	if n := findLargest(doc, func(n *html.Node) bool { return attr(n, "itemprop") == "articleBody" }); n != nil {
+		return n
+	}
+	if n := findLargest(doc, func(n *html.Node) bool { return n.Data == "article" }); n != nil {
+		return n
+	}
+	if n := findLargest(doc, func(n *html.Node) bool { return n.Data == "main" || attr(n, "role") == "main" }); n != nil {
+		return n
+	}
+
+	var (
+		best      *html.Node
+		bestScore int
+	)
+	walk(doc, func(n *html.Node) bool {
+		score := 0
+		for ch := n.FirstChild; ch != nil; ch = ch.NextSibling {
+			if ch.Type == html.ElementNode && ch.Data == "p" {
+				score += len(textContent(ch))
+			}
+		}
+		if score > bestScore {
+			best, bestScore = n, score
+		}
+		return true
+	})
+	if best != nil {
+		return best
+	}
+
+	if body := findLargest(doc, func(n *html.Node) bool { return n.Data == "body" }); body != nil {
+		return body
+	}
+	return doc
+}
+
+// findLargest returns the element matching match with the most text, nil if
+// none.
+func findLargest(doc *html.Node, match func(n *html.Node) bool) *html.Node {
+	var (
+		largest *html.Node
+		size    = -1
+	)
+	walk(doc, func(n *html.Node) bool {
+		if match(n) {
+			if l := len(textContent(n)); l > size {
+				largest, size = n, l
+			}
+			return false
+		}
+		return true
+	})
+	return largest
+}
+
+// walk calls fn for all elements below n, depth first. The children of an
+// element are skipped if fn returns false.
+func walk(n *html.Node, fn func(n *html.Node) bool) {
+	for ch := n.FirstChild; ch != nil; ch = ch.NextSibling {
+		if ch.Type == html.ElementNode && !fn(ch) {
+			continue
+		}
+		walk(ch, fn)
+	}
+}
+
+// removeAll removes all elements below n for which remove returns true.
+func removeAll(n *html.Node, remove func(n *html.Node) bool) {
+	for ch := n.FirstChild; ch != nil; {
+		next := ch.NextSibling
+		if ch.Type == html.CommentNode || (ch.Type == html.ElementNode && remove(ch)) {
+			n.RemoveChild(ch)
+		} else {
+			removeAll(ch, remove)
+		}
+		ch = next
+	}
+}
+
+func isHidden(n *html.Node) bool {
+	if attr(n, "aria-hidden") == "true" {
+		return true
+	}
+	for _, a := range n.Attr {
+		if a.Key == "hidden" {
+			return true
+		}
+	}
+	return false
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// textContent returns the text below n, with br elements as newlines.
+func textContent(n *html.Node) string {
+	var sb strings.Builder
+	var collect func(n *html.Node)
+	collect = func(n *html.Node) {
+		switch {
+		case n.Type == html.TextNode:
+			sb.WriteString(n.Data)
+		case n.Type == html.ElementNode && n.Data == "br":
+			sb.WriteString("\n")
+		}
+		for ch := n.FirstChild; ch != nil; ch = ch.NextSibling {
+			collect(ch)
+		}
+	}
+	collect(n)
+	return sb.String()
+}
+
+// mdConverter converts HTML to Markdown.
+type mdConverter struct {
+	// Relative links and images are resolved against this URL.
+	base *url.URL
+
+	// Maps the absolute URL of an image to the filename to use for it.
+	images map[string]string
+}
+
+// convert returns n's content as Markdown.
+func (c *mdConverter) convert(n *html.Node) string {
+	return strings.Join(c.blocks(n), "\n\n") + "\n"
+}
+
+// blocks returns the Markdown blocks for the children of n. Inline content
+// between block elements ends up in its own paragraph.
+func (c *mdConverter) blocks(n *html.Node) []string {
+	var (
+		out    []string
+		inline strings.Builder
+	)
+
+	flush := func() {
+		if s := paragraph(inline.String()); s != "" {
+			out = append(out, s)
+		}
+		inline.Reset()
+	}
+
+	for ch := n.FirstChild; ch != nil; ch = ch.NextSibling {
+		if ch.Type == html.ElementNode && blockElements[ch.Data] {
+			flush()
+			out = append(out, c.block(ch)...)
+			continue
+		}
+		inline.WriteString(c.inline(ch))
+	}
+	flush()
+
+	return out
+}
+
+func (c *mdConverter) block(n *html.Node) []string {
+	switch n.Data {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		s := paragraph(c.inlines(n))
+		if s == "" {
+			return nil
+		}
+		level := int(n.Data[1] - '0')
+		return []string{strings.Repeat("#", level) + " " + strings.Replace(s, "\\\n", " ", -1)}
+	case "p", "summary":
+		if s := paragraph(c.inlines(n)); s != "" {
+			return []string{s}
+		}
+		return nil
+	case "dt":
+		if s := paragraph(c.inlines(n)); s != "" {
+			return []string{"**" + s + "**"}
+		}
+		return nil
+	case "figcaption":
+		if s := paragraph(c.inlines(n)); s != "" {
+			return []string{"*" + s + "*"}
+		}
+		return nil
+	case "hr":
+		return []string{"---"}
+	case "pre":
+		return []string{c.codeBlock(n)}
+	case "blockquote":
+		inner := strings.Join(c.blocks(n), "\n\n")
+		if inner == "" {
+			return nil
+		}
+		lines := strings.Split(inner, "\n")
+		for i, line := range lines {
+			if line == "" {
+				lines[i] = ">"
+			} else {
+				lines[i] = "> " + line
+			}
+		}
+		return []string{strings.Join(lines, "\n")}
+	case "ul", "ol":
+		if s := c.list(n); s != "" {
+			return []string{s}
+		}
+		return nil
+	case "table":
+		if s := c.table(n); s != "" {
+			return []string{s}
+		}
+		return nil
+	default:
+		return c.blocks(n)
+	}
+}
+
+var listMarkerRe = regexp.MustCompile(`^(-|\d+\.) `)
+
+func (c *mdConverter) list(n *html.Node) string {
+	ordered := n.Data == "ol"
+	num := 1
+	if start, err := strconv.Atoi(attr(n, "start")); ordered && err == nil {
+		num = start
+	}
+
+	var items []string
+	for li := n.FirstChild; li != nil; li = li.NextSibling {
+		if li.Type != html.ElementNode || li.Data != "li" {
+			continue
+		}
+
+		marker := "- "
+		if ordered {
+			marker = strconv.Itoa(num) + ". "
+			num++
+		}
+
+		var sb strings.Builder
+		for i, b := range c.blocks(li) {
+			if i > 0 {
+				// Keep nested lists tight.
+				if listMarkerRe.MatchString(b) {
+					sb.WriteString("\n")
+				} else {
+					sb.WriteString("\n\n")
+				}
+			}
+			sb.WriteString(b)
+		}
+
+		indent := strings.Repeat(" ", len(marker))
+		lines := strings.Split(sb.String(), "\n")
+		for i := 1; i < len(lines); i++ {
+			if lines[i] != "" {
+				lines[i] = indent + lines[i]
+			}
+		}
+		items = append(items, strings.TrimRight(marker+strings.Join(lines, "\n"), " "))
+	}
+
+	return strings.Join(items, "\n")
+}
+
+func (c *mdConverter) codeBlock(n *html.Node) string {
+	lang := codeLanguage(n)
+	for ch := n.FirstChild; ch != nil; ch = ch.NextSibling {
+		if ch.Type == html.ElementNode && ch.Data == "code" {
+			if l := codeLanguage(ch); l != "" {
+				lang = l
+			}
+			break
+		}
+	}
+
+	code := strings.TrimRight(strings.TrimPrefix(textContent(n), "\n"), "\n ")
+	fence := "```"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+
+	return fence + lang + "\n" + code + "\n" + fence
+}
+
+// codeLanguage returns the language of the code in n as set in the class,
+// e.g. language-go, or the data-lang attribute.
+func codeLanguage(n *html.Node) string {
+	for _, class := range strings.Fields(attr(n, "class")) {
+		for _, prefix := range []string{"language-", "lang-"} {
+			if strings.HasPrefix(class, prefix) {
+				return strings.TrimPrefix(class, prefix)
+			}
+		}
+	}
+	return attr(n, "data-lang")
+}
+
+func (c *mdConverter) table(n *html.Node) string {
+	var rows [][]string
+	var collect func(n *html.Node)
+	collect = func(n *html.Node) {
+		for ch := n.FirstChild; ch != nil; ch = ch.NextSibling {
+			if ch.Type != html.ElementNode {
+				continue
+			}
+			switch ch.Data {
+			case "thead", "tbody", "tfoot":
+				collect(ch)
+			case "tr":
+				var row []string
+				for cell := ch.FirstChild; cell != nil; cell = cell.NextSibling {
+					if cell.Type == html.ElementNode && (cell.Data == "td" || cell.Data == "th") {
+						s := strings.Replace(paragraph(c.inlines(cell)), "\\\n", " ", -1)
+						row = append(row, strings.Replace(s, "|", "\\|", -1))
+					}
+				}
+				if len(row) > 0 {
+					rows = append(rows, row)
+				}
+			}
+		}
+	}
+	collect(n)
+
+	if len(rows) == 0 {
+		return ""
+	}
+
+	cols := 0
+	for _, row := range rows {
+		if len(row) > cols {
+			cols = len(row)
+		}
+	}
+
+	var sb strings.Builder
+	writeRow := func(row []string) {
+		sb.WriteString("|")
+		for i := 0; i < cols; i++ {
+			cell := ""
+			if i < len(row) {
+				cell = row[i]
+			}
+			sb.WriteString(" " + cell + " |")
+		}
+		sb.WriteString("\n")
+	}
+
+	// The first row is the header.
+	writeRow(rows[0])
+	sb.WriteString("|" + strings.Repeat(" --- |", cols) + "\n")
+	for _, row := range rows[1:] {
+		writeRow(row)
+	}
+
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// inlines returns the inline Markdown for the children of n.
+func (c *mdConverter) inlines(n *html.Node) string {
+	var sb strings.Builder
+	for ch := n.FirstChild; ch != nil; ch = ch.NextSibling {
+		sb.WriteString(c.inline(ch))
+	}
+	return sb.String()
+}
+
+func (c *mdConverter) inline(n *html.Node) string {
+	switch n.Type {
+	case html.TextNode:
+		return escapeMarkdown(collapseSpace(n.Data))
+	case html.ElementNode:
+	default:
+		return ""
+	}
+
+	switch n.Data {
+	case "br":
+		return "\\\n"
+	case "em", "i":
+		return wrapInline(c.inlines(n), "*")
+	case "strong", "b":
+		return wrapInline(c.inlines(n), "**")
+	case "del", "s", "strike":
+		return wrapInline(c.inlines(n), "~~")
+	case "code", "kbd", "samp", "tt":
+		code := strings.TrimSpace(collapseSpace(textContent(n)))
+		if code == "" {
+			return ""
+		}
+		delim := "`"
+		for strings.Contains(code, delim) {
+			delim += "`"
+		}
+		if strings.HasPrefix(code, "`") || strings.HasSuffix(code, "`") {
+			code = " " + code + " "
+		}
+		return delim + code + delim
+	case "a":
+		return c.link(n)
+	case "img":
+		return c.image(n)
+	default:
+		return c.inlines(n)
+	}
+}
+
+func (c *mdConverter) link(n *html.Node) string {
+	text := c.inlines(n)
+	href := strings.TrimSpace(attr(n, "href"))
+	if href == "" || strings.HasPrefix(href, "javascript:") {
+		return text
+	}
+	if strings.TrimSpace(text) == "" {
+		return text
+	}
+
+	// Keep links to anchors on the same page.
+	if !strings.HasPrefix(href, "#") {
+		href = c.resolve(href)
+	}
+
+	return wrapInline(text, "[", "]("+destination(href, attr(n, "title"))+")")
+}
+
+func (c *mdConverter) image(n *html.Node) string {
+	src := imageSource(n)
+	if src == "" || strings.HasPrefix(src, "data:") {
+		return ""
+	}
+	src = c.resolve(src)
+	if filename, found := c.images[src]; found {
+		src = filename
+	}
+
+	alt := escapeMarkdown(strings.TrimSpace(collapseSpace(attr(n, "alt"))))
+
+	return "![" + alt + "](" + destination(src, attr(n, "title")) + ")"
+}
+
+// imageSource returns the source of the image element n, taking the
+// attributes commonly used for lazy loading into account.
+func imageSource(n *html.Node) string {
+	for _, key := range []string{"data-src", "data-lazy-src", "data-original"} {
+		if src := strings.TrimSpace(attr(n, key)); src != "" {
+			return src
+		}
+	}
+	src := strings.TrimSpace(attr(n, "src"))
+	if src == "" || strings.HasPrefix(src, "data:") {
+		// Use the first candidate in srcset instead of a placeholder.
+		if fields := strings.Fields(attr(n, "srcset")); len(fields) > 0 {
+			return strings.TrimSuffix(fields[0], ",")
+		}
+	}
+	return src
+}
+
+// resolve returns ref resolved against the base URL.
+func (c *mdConverter) resolve(ref string) string {
+	if c.base == nil {
+		return ref
+	}
+	u, err := c.base.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return u.String()
+}
+
+// destination returns the link destination with an optional title.
+func destination(dest, title string) string {
+	if strings.ContainsAny(dest, " ()<>") {
+		dest = "<" + strings.NewReplacer("<", "%3C", ">", "%3E").Replace(dest) + ">"
+	}
+	if title = strings.TrimSpace(collapseSpace(title)); title != "" {
+		dest += ` "` + strings.Replace(title, `"`, `\"`, -1) + `"`
+	}
+	return dest
+}
+
+// wrapInline wraps the inline Markdown s in the given delimiters, keeping
+// any surrounding whitespace outside, as Markdown requires.
+func wrapInline(s, open string, close ...string) string {
+	trimmed := strings.TrimSpace(s)
+	if trimmed == "" {
+		return s
+	}
+	end := open
+	if len(close) > 0 {
+		end = close[0]
+	}
+	i := strings.Index(s, trimmed)
+	return s[:i] + open + trimmed + end + s[i+len(trimmed):]
+}
+
+var spaceRe = regexp.MustCompile(`\s+`)
+
+func collapseSpace(s string) string {
+	return spaceRe.ReplaceAllString(s, " ")
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"`", "\\`",
+	"*", `\*`,
+	"_", `\_`,
+	"[", `\[`,
+	"]", `\]`,
+	"<", `\<`,
+)
+
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+var blockStartRe = regexp.MustCompile(`^(#|>|[-+] |\d+[.)] )`)
+
+// paragraph cleans up the inline Markdown s: whitespace is trimmed from the
+// lines, empty lines and trailing hard line breaks are removed, and a
+// Markdown block marker at the start is escaped.
+func paragraph(s string) string {
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && line != "\\" {
+			lines = append(lines, line)
+		}
+	}
+	s = strings.Join(lines, "\n")
+	for strings.HasSuffix(s, "\\") && !strings.HasSuffix(s, "\\\\") {
+		s = strings.TrimSpace(strings.TrimSuffix(s, "\\"))
+	}
+	if m := blockStartRe.FindString(s); m != "" {
+		if m[0] >= '0' && m[0] <= '9' {
+			i := strings.IndexAny(m, ".)")
+			s = s[:i] + "\\" + s[i:]
+		} else {
+			s = "\\" + s
+		}
+	}
+	return s
+}
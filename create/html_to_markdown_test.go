@@ -0,0 +1,105 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package create
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"golang.org/x/net/html"
+)
+
+func TestHTMLToMarkdown(t *testing.T) {
+	c := qt.New(t)
+
+	base, _ := url.Parse("https://example.org/blog/post/")
+
+	for _, test := range []struct {
+		name   string
+		html   string
+		expect string
+	}{
+		{"Paragraphs", "<p>First  paragraph\nwith text.</p>\n<p>Second<br>line</p>", "First paragraph with text.\n\nSecond\\\nline"},
+		{"Headings", "<h2>Sub <em>heading</em></h2><h3>Third</h3>", "## Sub *heading*\n\n### Third"},
+		{"Inline", "<p><strong>Bold</strong>, <i>italic </i>text, <del>gone</del> and <code>x := 1</code>.</p>", "**Bold**, *italic* text, ~~gone~~ and `x := 1`."},
+		{"Escape", "<p>2 * 3 = 6 and some_name [1] &lt;tag&gt;</p>", `2 \* 3 = 6 and some\_name \[1\] \<tag>`},
+		{"Escape block start", "<p># not a heading</p><p>1. not a list</p>", "\\# not a heading\n\n1\\. not a list"},
+		{"Links", `<p><a href="../other/" title="Other">Relative</a>, <a href="#section">anchor</a> and <a href="https://gohugo.io">absolute</a>.</p>`, `[Relative](https://example.org/blog/other/ "Other"), [anchor](#section) and [absolute](https://gohugo.io).`},
+		{"Images", `<img src="a.jpg" alt="A"><img data-src="b.jpg" src="data:image/gif;base64,R0lG"><img src="data:image/gif;base64,R0lG">`, "![A](local.jpg)![](https://example.org/blog/post/b.jpg)"},
+		{"Lists", "<ul><li>One</li><li>Two<ul><li>Nested</li></ul></li></ul><ol start=\"3\"><li><p>Three</p><p>More</p></li><li>Four</li></ol>", "- One\n- Two\n  - Nested\n\n3. Three\n\n   More\n4. Four"},
+		{"Blockquote", "<blockquote><p>Quote</p><p>More</p></blockquote>", "> Quote\n>\n> More"},
+		{"Code", `<pre><code class="language-go">func main() {
+	fmt.Println("hi")
+}
+</code></pre>`, "```go\nfunc main() {\n\tfmt.Println(\"hi\")\n}\n```"},
+		{"Table", "<table><thead><tr><th>Name</th><th>Value</th></tr></thead><tbody><tr><td>a|b</td><td><b>1</b></td></tr><tr><td>c</td></tr></tbody></table>", "| Name | Value |\n| --- | --- |\n| a\\|b | **1** |\n| c |  |"},
+		{"Mixed", "<div>Loose text<p>Paragraph</p>more text<hr></div>", "Loose text\n\nParagraph\n\nmore text\n\n---"},
+		{"Figure", `<figure><img src="a.jpg" alt="A"><figcaption>The caption</figcaption></figure>`, "![A](local.jpg)\n\n*The caption*"},
+	} {
+		c.Run(test.name, func(c *qt.C) {
+			doc, err := html.Parse(strings.NewReader("<html><body>" + test.html + "</body></html>"))
+			c.Assert(err, qt.IsNil)
+			conv := &mdConverter{base: base, images: map[string]string{"https://example.org/blog/post/a.jpg": "local.jpg"}}
+			body := findLargest(doc, func(n *html.Node) bool { return n.Data == "body" })
+			c.Assert(conv.convert(body), qt.Equals, test.expect+"\n")
+		})
+	}
+}
+
+func TestFindMainContent(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range []struct {
+		name   string
+		html   string
+		expect string
+	}{
+		{"Article body", `<article><p>Teaser</p><div itemprop="articleBody"><p>Body</p></div></article>`, "Body"},
+		{"Largest article", `<article><p>Short</p></article><article><p>The longer one</p></article>`, "The longer one"},
+		{"Main", `<div>Menu</div><main><p>Main content</p></main>`, "Main content"},
+		{"Paragraphs", `<div><a href="/">Menu</a></div><div id="content"><p>Lots of paragraph text.</p><p>More.</p></div>`, "Lots of paragraph text.More."},
+		{"Body", `<div>Just text</div>`, "Just text"},
+	} {
+		c.Run(test.name, func(c *qt.C) {
+			doc, err := html.Parse(strings.NewReader("<html><body>" + test.html + "</body></html>"))
+			c.Assert(err, qt.IsNil)
+			c.Assert(textContent(findMainContent(doc)), qt.Equals, test.expect)
+		})
+	}
+}
+
+func TestImageFilename(t *testing.T) {
+	c := qt.New(t)
+
+	taken := map[string]bool{"photo.jpg": true, "photo-1.jpg": true}
+	isTaken := func(s string) bool { return taken[s] }
+
+	for _, test := range []struct {
+		url       string
+		mediaType string
+		expect    string
+	}{
+		{"https://example.org/img/sunset.JPG?w=200", "image/jpeg", "sunset.jpg"},
+		{"https://example.org/img/photo.jpg", "image/jpeg", "photo-2.jpg"},
+		{"https://example.org/render.php?id=3", "image/png", "render.png"},
+		{"https://example.org/", "image/webp", "image.webp"},
+		{"https://example.org/img/My%20Photo%21.gif", "", "My-Photo.gif"},
+		{"https://example.org/img/unknown", "application/octet-stream", "unknown"},
+	} {
+		u, _ := url.Parse(test.url)
+		c.Assert(imageFilename(u, test.mediaType, isTaken), qt.Equals, test.expect, qt.Commentf(test.url))
+	}
+}
@@ -66,6 +66,8 @@ type Deployer struct {
 
 	// For tests...
 	summary deploySummary // summary of latest Deploy results
+
+	changedFiles []string // files uploaded or deleted in the latest Deploy
 }
 
 type deploySummary struct {
@@ -125,6 +127,20 @@ func (d *Deployer) openBucket(ctx context.Context) (*blob.Bucket, error) {
 	return blob.OpenBucket(ctx, d.target.URL)
 }
 
+// Target returns the name of the deployment target.
+func (d *Deployer) Target() string {
+	if d.target == nil {
+		return ""
+	}
+	return d.target.Name
+}
+
+// ChangedFiles returns the paths of the files to upload or delete in the
+// latest Deploy, sorted. It is empty for dry runs.
+func (d *Deployer) ChangedFiles() []string {
+	return d.changedFiles
+}
+
 // Deploy deploys the site to a target.
 func (d *Deployer) Deploy(ctx context.Context) error {
 	bucket, err := d.openBucket(ctx)
@@ -156,6 +172,7 @@ func (d *Deployer) Deploy(ctx context.Context) error {
 	uploads, deletes := findDiffs(local, remote, d.force)
 	d.summary.NumUploads = len(uploads)
 	d.summary.NumDeletes = len(deletes)
+	d.changedFiles = nil
 	if len(uploads)+len(deletes) == 0 {
 		if !d.quiet {
 			jww.FEEDBACK.Println("No changes required.")
@@ -224,6 +241,7 @@ func (d *Deployer) Deploy(ctx context.Context) error {
 	if d.maxDeletes != -1 && len(deletes) > d.maxDeletes {
 		jww.WARN.Printf("Skipping %d deletes because it is more than --maxDeletes (%d). If this is expected, set --maxDeletes to a larger number, or -1 to disable this check.\n", len(deletes), d.maxDeletes)
 		d.summary.NumDeletes = 0
+		deletes = nil
 	} else {
 		// Apply deletes in parallel.
 		sort.Slice(deletes, func(i, j int) bool { return deletes[i] < deletes[j] })
@@ -255,6 +273,14 @@ func (d *Deployer) Deploy(ctx context.Context) error {
 			sem <- struct{}{}
 		}
 	}
+	if !d.dryRun {
+		for _, upload := range uploads {
+			d.changedFiles = append(d.changedFiles, upload.Local.SlashPath)
+		}
+		d.changedFiles = append(d.changedFiles, deletes...)
+		sort.Strings(d.changedFiles)
+	}
+
 	if len(errs) > 0 {
 		if !d.quiet {
 			jww.FEEDBACK.Printf("Encountered %d errors.\n", len(errs))
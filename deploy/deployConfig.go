@@ -21,6 +21,7 @@ import (
 
 	"github.com/gobwas/glob"
 	"github.com/gohugoio/hugo/config"
+	"github.com/gohugoio/hugo/config/configschema"
 	hglob "github.com/gohugoio/hugo/hugofs/glob"
 	"github.com/gohugoio/hugo/media"
 	"github.com/mitchellh/mapstructure"
@@ -28,6 +29,10 @@ import (
 
 const deploymentConfigKey = "deployment"
 
+func init() {
+	configschema.AddSection(deploymentConfigKey, deployConfig{})
+}
+
 // deployConfig is the complete configuration for deployment.
 type deployConfig struct {
 	Targets  []*target
@@ -1,6 +1,7 @@
 package deps
 
 import (
+	"os"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -8,8 +9,10 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/gohugoio/hugo/cache/filecache"
+	"github.com/gohugoio/hugo/common/htime"
 	"github.com/gohugoio/hugo/common/loggers"
 	"github.com/gohugoio/hugo/config"
+	"github.com/gohugoio/hugo/config/security"
 	"github.com/gohugoio/hugo/helpers"
 	"github.com/gohugoio/hugo/hugofs"
 	"github.com/gohugoio/hugo/langs"
@@ -18,6 +21,7 @@ import (
 
 	"github.com/gohugoio/hugo/metrics"
 	"github.com/gohugoio/hugo/output"
+	"github.com/gohugoio/hugo/plugins"
 	"github.com/gohugoio/hugo/resources"
 	"github.com/gohugoio/hugo/source"
 	"github.com/gohugoio/hugo/tpl"
@@ -85,9 +89,28 @@ type Deps struct {
 
 	Metrics metrics.Provider
 
+	// PageMetrics is set when per-page render timings are enabled.
+	PageMetrics *metrics.PageMetrics
+
+	// Tracer is set when traceEndpoint is configured.
+	Tracer *metrics.Tracer
+
+	// Progress is set when progress reporting is enabled.
+	Progress *metrics.Progress
+
+	// The WASM plugins configured, nil if none.
+	Plugins *plugins.Plugins
+
 	// Timeout is configurable in site config.
 	Timeout time.Duration
 
+	// Clock tells the time of the build, which is fixed in reproducible
+	// builds.
+	Clock htime.Clock
+
+	// Whether this is a reproducible build.
+	Reproducible bool
+
 	// BuildStartListeners will be notified before a build starts.
 	BuildStartListeners *Listeners
 
@@ -239,6 +262,7 @@ func New(cfg DepsCfg) (*Deps, error) {
 	if err != nil {
 		return nil, errors.WithMessage(err, "failed to create file caches from configuration")
 	}
+	fileCaches.SetLogger(logger)
 
 	errorHandler := &globalErrHandler{}
 	buildState := &BuildState{}
@@ -265,6 +289,11 @@ func New(cfg DepsCfg) (*Deps, error) {
 
 	logDistinct := helpers.NewDistinctLogger(logger)
 
+	clock, ok := cfg.Cfg.Get("buildClock").(htime.Clock)
+	if !ok {
+		clock = htime.SystemClock
+	}
+
 	d := &Deps{
 		Fs:                      fs,
 		Log:                     ignorableLogger,
@@ -286,6 +315,8 @@ func New(cfg DepsCfg) (*Deps, error) {
 		BuildState:              buildState,
 		Running:                 cfg.Running,
 		Timeout:                 time.Duration(timeoutms) * time.Millisecond,
+		Clock:                   clock,
+		Reproducible:            cfg.Cfg.GetBool("reproducible"),
 		globalErrHandler:        errorHandler,
 	}
 
@@ -293,6 +324,36 @@ func New(cfg DepsCfg) (*Deps, error) {
 		d.Metrics = metrics.NewProvider(cfg.Cfg.GetBool("templateMetricsHints"))
 	}
 
+	if cfg.Cfg.GetBool("pageMetrics") {
+		d.PageMetrics = metrics.NewPageMetrics()
+		d.ResourceSpec.PageMetrics = d.PageMetrics
+	}
+
+	if endpoint := cfg.Cfg.GetString("traceEndpoint"); endpoint != "" {
+		sc, ok := cfg.Cfg.Get("securityConfig").(security.Config)
+		if !ok {
+			sc = security.DefaultConfig
+		}
+		d.Tracer = metrics.NewTracer(endpoint, metrics.ParseOTLPHeaders(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS")), sc.NewHTTPClient(0))
+		d.ResourceSpec.Tracer = d.Tracer
+	}
+
+	if format := cfg.Cfg.GetString("progress"); format != "" {
+		d.Progress, err = metrics.NewProgress(os.Stderr, format)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	d.Plugins, err = plugins.New(fs.Source, cfg.Cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+	if d.Plugins != nil {
+		d.ResourceSpec.Plugins = d.Plugins
+		d.BuildClosers.Add(d.Plugins)
+	}
+
 	return d, nil
 }
 
@@ -322,12 +383,17 @@ func (d Deps) ForLanguage(cfg DepsCfg, onCreated func(d *Deps) error) (*Deps, er
 	// TODO(bep) clean up these inits.
 	resourceCache := d.ResourceSpec.ResourceCache
 	postBuildAssets := d.ResourceSpec.PostBuildAssets
+	workers := d.ResourceSpec.Workers
 	d.ResourceSpec, err = resources.NewSpec(d.PathSpec, d.ResourceSpec.FileCaches, d.BuildState, d.Log, d.globalErrHandler, cfg.OutputFormats, cfg.MediaTypes)
 	if err != nil {
 		return nil, err
 	}
 	d.ResourceSpec.ResourceCache = resourceCache
 	d.ResourceSpec.PostBuildAssets = postBuildAssets
+	d.ResourceSpec.Workers = workers
+	d.ResourceSpec.PageMetrics = d.PageMetrics
+	d.ResourceSpec.Tracer = d.Tracer
+	d.ResourceSpec.Plugins = d.Plugins
 
 	d.Cfg = l
 	d.Language = l
@@ -0,0 +1,109 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphql
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gohugoio/hugo/config"
+	"github.com/gohugoio/hugo/config/configschema"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+)
+
+const graphQLConfigKey = "graphql"
+
+// DefaultConfig holds the default GraphQL configuration.
+var DefaultConfig = Config{
+	Timeout: 30 * time.Second,
+}
+
+// Config holds the GraphQL configuration.
+type Config struct {
+	// The named endpoints that can be queried.
+	Endpoints []EndpointConfig
+
+	// The timeout for each request.
+	Timeout time.Duration
+}
+
+// EndpointConfig configures a GraphQL endpoint.
+type EndpointConfig struct {
+	// The name used to refer to the endpoint, e.g. in data.GraphQL.
+	Name string
+
+	// The URL of the endpoint, e.g. "https://cms.example.org/graphql".
+	URL string
+
+	// HTTP headers to send with every request, e.g. an Authorization header.
+	// Environment variables in the values are expanded, so you don't need
+	// to store secrets in your config files.
+	Headers map[string]string
+}
+
+// DecodeConfig decodes the graphql section in the site configuration.
+func DecodeConfig(cfg config.Provider) (Config, error) {
+	c := DefaultConfig
+
+	if !cfg.IsSet(graphQLConfigKey) {
+		return c, nil
+	}
+
+	dec, err := mapstructure.NewDecoder(
+		&mapstructure.DecoderConfig{
+			WeaklyTypedInput: true,
+			Result:           &c,
+			DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
+		},
+	)
+	if err != nil {
+		return c, err
+	}
+
+	if err := dec.Decode(cfg.GetStringMap(graphQLConfigKey)); err != nil {
+		return c, errors.Wrap(err, "failed to decode graphql config")
+	}
+
+	seen := make(map[string]bool)
+
+	for i, e := range c.Endpoints {
+		if e.Name == "" {
+			return c, errors.New("graphql: all endpoints must have a name")
+		}
+		e.Name = strings.ToLower(e.Name)
+		if seen[e.Name] {
+			return c, errors.Errorf("graphql: duplicate endpoint name %q", e.Name)
+		}
+		seen[e.Name] = true
+
+		if !isHTTPURL(e.URL) {
+			return c, errors.Errorf("graphql: invalid URL %q for endpoint %q", e.URL, e.Name)
+		}
+
+		c.Endpoints[i] = e
+	}
+
+	return c, nil
+}
+
+func isHTTPURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func init() {
+	configschema.AddSection(graphQLConfigKey, DefaultConfig)
+}
@@ -0,0 +1,256 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package graphql queries GraphQL APIs at build time, e.g. a headless CMS.
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gohugoio/hugo/cache/filecache"
+	"github.com/gohugoio/hugo/config/security"
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/pkg/errors"
+)
+
+// Options configures a single query.
+type Options struct {
+	// Additional HTTP headers to send, e.g. an Authorization header.
+	// These override the headers set for the endpoint in the config.
+	Headers map[string]string
+
+	// The operation to run if the query document contains more than one.
+	OperationName string
+}
+
+// Error is an error returned by the GraphQL server.
+type Error struct {
+	Message   string     `json:"message"`
+	Locations []Location `json:"locations"`
+
+	// The path to the field in the response, e.g. ["posts", 2, "author"].
+	Path []interface{} `json:"path"`
+}
+
+// Location is a position in the query document.
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+func (e Error) Error() string {
+	var sb strings.Builder
+	sb.WriteString(e.Message)
+	if len(e.Locations) > 0 {
+		fmt.Fprintf(&sb, " (line %d, column %d)", e.Locations[0].Line, e.Locations[0].Column)
+	}
+	if len(e.Path) > 0 {
+		path := make([]string, len(e.Path))
+		for i, p := range e.Path {
+			path[i] = fmt.Sprint(p)
+		}
+		fmt.Fprintf(&sb, " at %s", strings.Join(path, "."))
+	}
+	return sb.String()
+}
+
+// Errors is the list of errors returned by the GraphQL server.
+type Errors []Error
+
+func (e Errors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more errors)", e[0].Error(), len(e)-1)
+}
+
+type request struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+	OperationName string                 `json:"operationName,omitempty"`
+}
+
+type response struct {
+	Data   map[string]interface{} `json:"data"`
+	Errors Errors                 `json:"errors"`
+}
+
+// Client runs GraphQL queries.
+type Client struct {
+	endpoints map[string]EndpointConfig
+	cache     *filecache.Cache
+
+	httpClient *http.Client
+	timeout    time.Duration
+	security   security.Config
+}
+
+// New creates a new Client. The responses are cached in cache, if set.
+func New(cfg Config, cache *filecache.Cache, sc security.Config) *Client {
+	endpoints := make(map[string]EndpointConfig)
+	for _, e := range cfg.Endpoints {
+		endpoints[e.Name] = e
+	}
+
+	return &Client{
+		endpoints:  endpoints,
+		cache:      cache,
+		httpClient: sc.NewHTTPClient(0),
+		timeout:    cfg.Timeout,
+		security:   sc,
+	}
+}
+
+// Query runs query with the given variables against endpoint, which is
+// either the name of an endpoint in the config or a URL. It returns the data
+// in the response. Responses with errors are not cached.
+func (c *Client) Query(endpoint, query string, vars map[string]interface{}, opts Options) (map[string]interface{}, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, errors.New("graphql: no query given")
+	}
+
+	var e EndpointConfig
+	if ec, found := c.endpoints[strings.ToLower(endpoint)]; found {
+		e = ec
+	} else if isHTTPURL(endpoint) {
+		e = EndpointConfig{Name: endpoint, URL: endpoint}
+	} else {
+		return nil, errors.Errorf("graphql: endpoint %q not found in the graphql config", endpoint)
+	}
+
+	body, err := json.Marshal(request{Query: query, Variables: vars, OperationName: opts.OperationName})
+	if err != nil {
+		return nil, errors.Wrap(err, "graphql: failed to encode variables")
+	}
+
+	headers := make(map[string]string)
+	for k, v := range e.Headers {
+		headers[http.CanonicalHeaderKey(k)] = os.ExpandEnv(v)
+	}
+	for k, v := range opts.Headers {
+		headers[http.CanonicalHeaderKey(k)] = v
+	}
+
+	fetch := func() ([]byte, error) {
+		b, err := c.do(e.URL, body, headers)
+		if err != nil {
+			if security.IsAccessDenied(err) {
+				return nil, err
+			}
+			return nil, errors.Wrapf(err, "graphql: query to %q failed", e.Name)
+		}
+		return b, nil
+	}
+
+	var b []byte
+
+	if c.cache != nil {
+		_, b, err = c.cache.GetOrCreateBytes(cacheID(e.URL, body, headers), fetch)
+	} else {
+		b, err = fetch()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var resp response
+	if err := json.Unmarshal(b, &resp); err != nil {
+		return nil, err
+	}
+
+	return resp.Data, nil
+}
+
+// do posts body to u and returns the response body, if it has data and no errors.
+func (c *Client) do(u string, body []byte, headers map[string]string) ([]byte, error) {
+	req, err := http.NewRequest("POST", u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.security.CheckAllowedHTTP(req.Method, req.URL); err != nil {
+		return nil, err
+	}
+
+	if c.timeout > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+		defer cancel()
+		req = req.WithContext(ctx)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "Hugo Static Site Generator")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	b, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	// Many servers report errors in the query with a 4xx status.
+	var resp response
+	jsonErr := json.Unmarshal(b, &resp)
+	if jsonErr == nil && len(resp.Errors) > 0 {
+		return nil, resp.Errors
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return nil, errors.Errorf("%s %s: %s", req.Method, req.URL, res.Status)
+	}
+
+	if jsonErr != nil {
+		return nil, errors.Wrap(jsonErr, "invalid response")
+	}
+
+	if resp.Data == nil {
+		return nil, errors.New("no data in response")
+	}
+
+	return b, nil
+}
+
+func cacheID(u string, body []byte, headers map[string]string) string {
+	keys := make([]string, 0, len(headers))
+	for k := range headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	sb.WriteString(u)
+	sb.Write(body)
+	for _, k := range keys {
+		sb.WriteString(k)
+		sb.WriteString(headers[k])
+	}
+
+	return helpers.MD5String(sb.String())
+}
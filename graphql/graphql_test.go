@@ -0,0 +1,154 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphql
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/cache/filecache"
+	"github.com/gohugoio/hugo/config"
+	"github.com/gohugoio/hugo/config/security"
+	"github.com/spf13/afero"
+)
+
+func TestDecodeConfig(t *testing.T) {
+	c := qt.New(t)
+
+	gc, err := DecodeConfig(config.New())
+	c.Assert(err, qt.IsNil)
+	c.Assert(gc.Timeout, qt.Equals, 30*time.Second)
+
+	cfg, err := config.FromConfigString(`
+[graphql]
+timeout = "5s"
+[[graphql.endpoints]]
+name = "CMS"
+url = "https://cms.example.org/graphql"
+[graphql.endpoints.headers]
+Authorization = "Bearer ${CMS_TOKEN}"
+`, "toml")
+	c.Assert(err, qt.IsNil)
+
+	gc, err = DecodeConfig(cfg)
+	c.Assert(err, qt.IsNil)
+	c.Assert(gc.Timeout, qt.Equals, 5*time.Second)
+	c.Assert(gc.Endpoints, qt.HasLen, 1)
+	c.Assert(gc.Endpoints[0].Name, qt.Equals, "cms")
+	c.Assert(gc.Endpoints[0].Headers, qt.DeepEquals, map[string]string{"Authorization": "Bearer ${CMS_TOKEN}"})
+
+	for _, invalid := range []string{
+		`[[graphql.endpoints]]
+url = "https://cms.example.org/graphql"`,
+		`[[graphql.endpoints]]
+name = "cms"
+url = "cms.example.org/graphql"`,
+		`[[graphql.endpoints]]
+name = "cms"
+url = "https://a.example.org"
+[[graphql.endpoints]]
+name = "cms"
+url = "https://b.example.org"`,
+	} {
+		cfg, err := config.FromConfigString(invalid, "toml")
+		c.Assert(err, qt.IsNil)
+		_, err = DecodeConfig(cfg)
+		c.Assert(err, qt.Not(qt.IsNil))
+	}
+}
+
+func TestQuery(t *testing.T) {
+	c := qt.New(t)
+
+	os.Setenv("HUGO_TEST_GRAPHQL_TOKEN", "secret")
+	defer os.Unsetenv("HUGO_TEST_GRAPHQL_TOKEN")
+
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		var req request
+		json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case r.Header.Get("Authorization") != "Bearer secret":
+			w.WriteHeader(http.StatusUnauthorized)
+		case req.Query == "{ invalid }":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"errors": [{"message": "Cannot query field \"invalid\"", "locations": [{"line": 1, "column": 3}]}]}`))
+		case req.Query == "{ partial }":
+			w.Write([]byte(`{"data": {"partial": null}, "errors": [{"message": "Not allowed", "path": ["partial", 0]}, {"message": "Other"}]}`))
+		default:
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{"post": map[string]interface{}{"id": req.Variables["id"], "op": req.OperationName}},
+			})
+		}
+	}))
+	defer srv.Close()
+
+	cfg := DefaultConfig
+	cfg.Endpoints = []EndpointConfig{{Name: "cms", URL: srv.URL, Headers: map[string]string{"authorization": "Bearer ${HUGO_TEST_GRAPHQL_TOKEN}"}}}
+
+	cache := filecache.NewCache(afero.NewMemMapFs(), -1, "")
+	client := New(cfg, cache, security.DefaultConfig)
+
+	const query = `query Post($id: ID!) { post(id: $id) { id } }`
+
+	data, err := client.Query("CMS", query, map[string]interface{}{"id": "42"}, Options{OperationName: "Post"})
+	c.Assert(err, qt.IsNil)
+	c.Assert(data, qt.DeepEquals, map[string]interface{}{"post": map[string]interface{}{"id": "42", "op": "Post"}})
+
+	// Cached.
+	_, err = client.Query("cms", query, map[string]interface{}{"id": "42"}, Options{OperationName: "Post"})
+	c.Assert(err, qt.IsNil)
+	c.Assert(atomic.LoadInt32(&calls), qt.Equals, int32(1))
+
+	data, err = client.Query("cms", query, map[string]interface{}{"id": "43"}, Options{})
+	c.Assert(err, qt.IsNil)
+	c.Assert(data["post"], qt.DeepEquals, map[string]interface{}{"id": "43", "op": ""})
+	c.Assert(atomic.LoadInt32(&calls), qt.Equals, int32(2))
+
+	// A URL with headers set in the options.
+	_, err = client.Query(srv.URL, query, nil, Options{Headers: map[string]string{"Authorization": "Bearer secret"}})
+	c.Assert(err, qt.IsNil)
+
+	_, err = client.Query(srv.URL, query, nil, Options{})
+	c.Assert(err, qt.ErrorMatches, `graphql: query to ".*" failed: POST .*: 401 Unauthorized`)
+
+	_, err = client.Query("cms", "{ invalid }", nil, Options{})
+	c.Assert(err, qt.ErrorMatches, `graphql: query to "cms" failed: Cannot query field "invalid" \(line 1, column 3\)`)
+
+	_, err = client.Query("cms", "{ partial }", nil, Options{})
+	c.Assert(err, qt.ErrorMatches, `graphql: query to "cms" failed: Not allowed at partial.0 \(and 1 more errors\)`)
+
+	// Errors are not cached.
+	calls = 0
+	_, err = client.Query("cms", "{ partial }", nil, Options{})
+	c.Assert(err, qt.Not(qt.IsNil))
+	c.Assert(atomic.LoadInt32(&calls), qt.Equals, int32(1))
+
+	_, err = client.Query("foo", query, nil, Options{})
+	c.Assert(err, qt.ErrorMatches, `graphql: endpoint "foo" not found in the graphql config`)
+
+	sc := security.DefaultConfig
+	sc.HTTP.Methods = security.NewWhitelist("GET")
+	_, err = New(cfg, nil, sc).Query("cms", query, nil, Options{})
+	c.Assert(security.IsAccessDenied(err), qt.IsTrue)
+}
@@ -137,6 +137,34 @@ func BenchmarkTestTruncateWordsToWholeSentenceOld(b *testing.B) {
 	}
 }
 
+func TestTruncateHTMLToWholeSentence(t *testing.T) {
+	c := qt.New(t)
+
+	for i, test := range []struct {
+		input, expected string
+		max             int
+		truncated       bool
+	}{
+		{"<p>a b c</p>", "a b c", 12, false},
+		{"<p>To be. Or not to be. That's the question.</p>", "To be.", 1, true},
+		{"<p>To <em>be. Or</em> not to be.</p>", "To <em>be.</em>", 2, true},
+		{"<p>A <a href=\"/b\">link that is long. More</a> text.</p>", `A <a href="/b">link that is long.</a>`, 2, true},
+		{"<p>First paragraph</p>\n<p>Second paragraph.</p>", "First paragraph", 2, true},
+		{"<p>First</p>\n<p>Second paragraph.</p>", "First Second paragraph.", 2, false},
+		{"<h2>Heading</h2>\n<p>Text<sup id=\"fnref:1\"><a href=\"#fn:1\">1</a></sup>.</p>\n<section class=\"footnotes\"><p>Note.</p></section>", "Text.", 1, false},
+		{"<p>Run:</p><pre><code>go run .\n</code></pre><p>Done.</p>", "Run: Done.", 5, false},
+		{"<p>He said \"Stop.\" Then left.</p>", `He said &#34;Stop.&#34;`, 3, true},
+		{"<p>1 &lt; 2. Yes.</p>", "1 &lt; 2.", 3, true},
+		{"<p>日本語の文章です。次の文。</p>", "日本語の文章です。", 3, true},
+		{"<p>Unclosed <strong>tag and more words.", "Unclosed <strong>tag and more words.</strong>", 2, false},
+		{"", "", 10, false},
+	} {
+		output, truncated := TruncateHTMLToWholeSentence(test.input, test.max)
+		c.Assert(output, qt.Equals, test.expected, qt.Commentf("test %d", i))
+		c.Assert(truncated, qt.Equals, test.truncated, qt.Commentf("test %d", i))
+	}
+}
+
 func TestTruncateWordsToWholeSentence(t *testing.T) {
 	c := newTestContentSpec()
 	type test struct {
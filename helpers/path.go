@@ -311,7 +311,7 @@ func FindCWD() (string, error) {
 
 // SymbolicWalk is like filepath.Walk, but it follows symbolic links.
 func SymbolicWalk(fs afero.Fs, root string, walker hugofs.WalkFunc) error {
-	if _, isOs := fs.(*afero.OsFs); isOs {
+	if hugofs.IsOsFs(fs) {
 		// Mainly to track symlinks.
 		fs = hugofs.NewBaseFileDecorator(fs)
 	}
@@ -364,9 +364,16 @@ func OpenFilesForWriting(fs afero.Fs, filenames ...string) (io.WriteCloser, erro
 }
 
 // OpenFileForWriting opens or creates the given file. If the target directory
-// does not exist, it gets created.
+// does not exist, it gets created. An existing hard linked file is replaced.
 func OpenFileForWriting(fs afero.Fs, filename string) (afero.File, error) {
 	filename = filepath.Clean(filename)
+	// The file may be a hard link to e.g. a static file (see
+	// staticPublishMode), which we must not overwrite.
+	if fi, err := fs.Stat(filename); err == nil && hugofs.IsHardLinked(fi) {
+		if err := fs.Remove(filename); err != nil {
+			return nil, err
+		}
+	}
 	// Create will truncate if file already exists.
 	// os.Create will create any new files with mode 0666 (before umask).
 	f, err := fs.Create(filename)
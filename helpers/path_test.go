@@ -620,6 +620,36 @@ func TestWriteToDisk(t *testing.T) {
 	}
 }
 
+func TestOpenFileForWritingHardlink(t *testing.T) {
+	c := qt.New(t)
+
+	tmpDir, err := ioutil.TempDir("", "hugo-openfile")
+	c.Assert(err, qt.IsNil)
+	defer os.RemoveAll(tmpDir)
+
+	src := filepath.Join(tmpDir, "static", "a.txt")
+	dst := filepath.Join(tmpDir, "public", "a.txt")
+	c.Assert(os.MkdirAll(filepath.Dir(src), 0777), qt.IsNil)
+	c.Assert(os.MkdirAll(filepath.Dir(dst), 0777), qt.IsNil)
+	c.Assert(ioutil.WriteFile(src, []byte("static"), 0666), qt.IsNil)
+	if err := os.Link(src, dst); err != nil {
+		t.Skipf("hard links not supported: %s", err)
+	}
+
+	f, err := OpenFileForWriting(hugofs.Os, dst)
+	c.Assert(err, qt.IsNil)
+	_, err = f.Write([]byte("generated"))
+	c.Assert(err, qt.IsNil)
+	c.Assert(f.Close(), qt.IsNil)
+
+	b, err := ioutil.ReadFile(dst)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, "generated")
+	b, err = ioutil.ReadFile(src)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, "static")
+}
+
 func TestGetTempDir(t *testing.T) {
 	dir := os.TempDir()
 	if FilePathSeparator != dir[len(dir)-1:] {
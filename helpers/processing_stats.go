@@ -16,8 +16,10 @@ package helpers
 import (
 	"io"
 	"strconv"
+	"strings"
 	"sync/atomic"
 
+	"github.com/gohugoio/hugo/common/loggers"
 	"github.com/olekukonko/tablewriter"
 )
 
@@ -33,6 +35,11 @@ type ProcessingStats struct {
 	Aliases         uint64
 	Sitemaps        uint64
 	Cleaned         uint64
+
+	// Entries evicted to stay within the memory budgets.
+	EvictedPages     uint64
+	EvictedImages    uint64
+	EvictedResources uint64
 }
 
 type processingStatsTitleVal struct {
@@ -40,8 +47,8 @@ type processingStatsTitleVal struct {
 	val  uint64
 }
 
-func (s *ProcessingStats) toVals() []processingStatsTitleVal {
-	return []processingStatsTitleVal{
+func (s *ProcessingStats) toVals(withEvictions bool) []processingStatsTitleVal {
+	vals := []processingStatsTitleVal{
 		{"Pages", s.Pages},
 		{"Paginator pages", s.PaginatorPages},
 		{"Non-page files", s.Files},
@@ -51,6 +58,20 @@ func (s *ProcessingStats) toVals() []processingStatsTitleVal {
 		{"Sitemaps", s.Sitemaps},
 		{"Cleaned", s.Cleaned},
 	}
+
+	if withEvictions {
+		vals = append(vals,
+			processingStatsTitleVal{"Evicted pages", s.EvictedPages},
+			processingStatsTitleVal{"Evicted images", s.EvictedImages},
+			processingStatsTitleVal{"Evicted resources", s.EvictedResources},
+		)
+	}
+
+	return vals
+}
+
+func (s *ProcessingStats) hasEvictions() bool {
+	return s.EvictedPages > 0 || s.EvictedImages > 0 || s.EvictedResources > 0
 }
 
 // NewProcessingStats returns a new ProcessingStats instance.
@@ -71,7 +92,7 @@ func (s *ProcessingStats) Add(counter *uint64, amount int) {
 // Table writes a table-formatted representation of the stats in a
 // ProcessingStats instance to w.
 func (s *ProcessingStats) Table(w io.Writer) {
-	titleVals := s.toVals()
+	titleVals := s.toVals(s.hasEvictions())
 	data := make([][]string, len(titleVals))
 	for i, tv := range titleVals {
 		data[i] = []string{tv.name, strconv.Itoa(int(tv.val))}
@@ -85,17 +106,58 @@ func (s *ProcessingStats) Table(w io.Writer) {
 	table.Render()
 }
 
+// ProcessingStatsJSON writes the stats to w as JSON log lines, one per site.
+func ProcessingStatsJSON(w io.Writer, stats ...*ProcessingStats) {
+	var withEvictions bool
+	for _, stat := range stats {
+		if stat.hasEvictions() {
+			withEvictions = true
+		}
+	}
+
+	for _, stat := range stats {
+		fields := make(map[string]interface{})
+		for _, tv := range stat.toVals(withEvictions) {
+			fields[statsKey(tv.name)] = tv.val
+		}
+		e := loggers.NewEntry("info", "build stats")
+		e.Component = "stats"
+		e.Lang = stat.Name
+		e.Fields = fields
+		e.Write(w)
+	}
+}
+
+// statsKey turns a title, e.g. "Non-page files", into a key, e.g. "nonPageFiles".
+func statsKey(title string) string {
+	parts := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return r == ' ' || r == '-'
+	})
+	for i := 1; i < len(parts); i++ {
+		parts[i] = strings.Title(parts[i])
+	}
+	return strings.Join(parts, "")
+}
+
 // ProcessingStatsTable writes a table-formatted representation of stats to w.
 func ProcessingStatsTable(w io.Writer, stats ...*ProcessingStats) {
 	names := make([]string, len(stats)+1)
 
 	var data [][]string
 
+	// Only list the evictions if there are any.
+	var withEvictions bool
+	for _, stat := range stats {
+		if stat.hasEvictions() {
+			withEvictions = true
+		}
+	}
+
 	for i := 0; i < len(stats); i++ {
 		stat := stats[i]
 		names[i+1] = stat.Name
 
-		titleVals := stat.toVals()
+		titleVals := stat.toVals(withEvictions)
 
 		if i == 0 {
 			data = make([][]string, len(titleVals))
@@ -0,0 +1,258 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"io"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+)
+
+var (
+	// Elements to leave out of the summary with all of their content.
+	summarySkipElements = map[string]bool{
+		"audio": true, "button": true, "figure": true, "form": true,
+		"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+		"iframe": true, "nav": true, "noscript": true, "object": true, "pre": true,
+		"script": true, "select": true, "style": true, "svg": true, "table": true,
+		"template": true, "textarea": true, "video": true,
+	}
+
+	// Elements to keep in the summary.
+	summaryInlineElements = map[string]bool{
+		"a": true, "abbr": true, "b": true, "bdi": true, "bdo": true, "cite": true,
+		"code": true, "del": true, "dfn": true, "em": true, "i": true, "ins": true,
+		"kbd": true, "mark": true, "q": true, "s": true, "samp": true, "small": true,
+		"span": true, "strong": true, "sub": true, "sup": true, "time": true,
+		"u": true, "var": true,
+	}
+
+	htmlVoidElements = map[string]bool{
+		"area": true, "base": true, "br": true, "col": true, "embed": true,
+		"hr": true, "img": true, "input": true, "link": true, "meta": true,
+		"source": true, "track": true, "wbr": true,
+	}
+)
+
+// IsCJK reports whether r is a Chinese, Japanese or Korean character.
+func IsCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hangul, unicode.Hiragana, unicode.Katakana)
+}
+
+// TruncateHTMLToWholeSentence truncates the HTML in s to the first whole
+// sentence after length words, where every CJK character counts as a word.
+// Only the text and its inline markup, e.g. links and emphasis, is kept;
+// paragraphs are joined with a space and headings, code blocks, tables,
+// figures and footnotes are left out. Any open inline element is closed.
+// It also returns whether it is truncated.
+func TruncateHTMLToWholeSentence(s string, length int) (string, bool) {
+	t := &summaryTruncater{length: length}
+	z := html.NewTokenizer(strings.NewReader(s))
+
+	for !t.done {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			if z.Err() != io.EOF {
+				return "", false
+			}
+			break
+		}
+		t.handle(z.Token())
+	}
+
+	t.closeAll()
+
+	if t.remaining {
+		return strings.TrimSpace(t.out.String()), true
+	}
+
+	// Look for more text.
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		tok := z.Token()
+		if t.skip(tok) {
+			continue
+		}
+		if tok.Type == html.TextToken && strings.TrimSpace(tok.Data) != "" {
+			return strings.TrimSpace(t.out.String()), true
+		}
+	}
+
+	return strings.TrimSpace(t.out.String()), false
+}
+
+type summaryTruncater struct {
+	length int
+	out    strings.Builder
+
+	// Inline elements written and not yet closed.
+	open []string
+
+	// The element we are skipping and how deep we are in it.
+	skipName  string
+	skipDepth int
+
+	words         int
+	inWord        bool
+	pendingSpace  bool
+	sentenceEnded bool
+
+	done      bool
+	remaining bool
+}
+
+// skip reports whether tok is part of an element left out of the summary.
+func (t *summaryTruncater) skip(tok html.Token) bool {
+	if t.skipDepth > 0 {
+		switch {
+		case tok.Type == html.StartTagToken && tok.Data == t.skipName && !htmlVoidElements[tok.Data]:
+			t.skipDepth++
+		case tok.Type == html.EndTagToken && tok.Data == t.skipName:
+			t.skipDepth--
+		}
+		return true
+	}
+
+	if tok.Type == html.StartTagToken && !htmlVoidElements[tok.Data] && (summarySkipElements[tok.Data] || isFootnote(tok)) {
+		t.skipName = tok.Data
+		t.skipDepth = 1
+		return true
+	}
+
+	return tok.Type == html.CommentToken || tok.Type == html.DoctypeToken
+}
+
+func (t *summaryTruncater) handle(tok html.Token) {
+	if t.skip(tok) {
+		return
+	}
+
+	switch tok.Type {
+	case html.TextToken:
+		t.text(tok.Data)
+	case html.StartTagToken, html.SelfClosingTagToken, html.EndTagToken:
+		if !summaryInlineElements[tok.Data] {
+			// A block boundary.
+			t.inWord = false
+			t.pendingSpace = true
+			if t.words >= t.length && t.words > 0 {
+				t.done = true
+			}
+			return
+		}
+		switch tok.Type {
+		case html.StartTagToken:
+			t.flushSpace()
+			t.out.WriteString(tok.String())
+			t.open = append(t.open, tok.Data)
+		case html.EndTagToken:
+			for i := len(t.open) - 1; i >= 0; i-- {
+				if t.open[i] == tok.Data {
+					for j := len(t.open) - 1; j >= i; j-- {
+						t.out.WriteString("</" + t.open[j] + ">")
+					}
+					t.open = t.open[:i]
+					break
+				}
+			}
+		}
+	}
+}
+
+func (t *summaryTruncater) text(s string) {
+	for i, r := range s {
+		if t.done {
+			if strings.TrimSpace(s[i:]) != "" {
+				t.remaining = true
+			}
+			return
+		}
+
+		if unicode.IsSpace(r) {
+			t.inWord = false
+			t.pendingSpace = true
+			if t.sentenceEnded {
+				t.done = true
+			}
+			continue
+		}
+
+		if t.sentenceEnded && !isClosingPunct(r) {
+			t.sentenceEnded = false
+		}
+
+		t.flushSpace()
+		t.out.WriteString(html.EscapeString(string(r)))
+
+		if IsCJK(r) {
+			t.words++
+			t.inWord = false
+		} else if !t.inWord {
+			t.words++
+			t.inWord = true
+		}
+
+		if t.words >= t.length {
+			switch r {
+			case '.', '?', '!':
+				t.sentenceEnded = true
+			case '。', '！', '？':
+				t.done = true
+			}
+		}
+	}
+}
+
+func (t *summaryTruncater) flushSpace() {
+	if t.pendingSpace && t.out.Len() > 0 {
+		t.out.WriteByte(' ')
+	}
+	t.pendingSpace = false
+}
+
+func (t *summaryTruncater) closeAll() {
+	for i := len(t.open) - 1; i >= 0; i-- {
+		t.out.WriteString("</" + t.open[i] + ">")
+	}
+	t.open = nil
+}
+
+func isClosingPunct(r rune) bool {
+	return r == '"' || r == '\'' || r == ')' || r == '”' || r == '’' || r == '»'
+}
+
+// isFootnote reports whether tok starts a footnote reference or the
+// footnotes section as rendered by Goldmark and Blackfriday.
+func isFootnote(tok html.Token) bool {
+	for _, a := range tok.Attr {
+		switch a.Key {
+		case "id":
+			if strings.HasPrefix(a.Val, "fnref") {
+				return true
+			}
+		case "class":
+			for _, c := range strings.Fields(a.Val) {
+				if c == "footnotes" || c == "footnote-ref" {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugobuild
+
+import (
+	"github.com/gohugoio/hugo/common/herrors"
+)
+
+// ConfigError is returned from Build when the project configuration could
+// not be loaded.
+type ConfigError struct {
+	Err error
+}
+
+func (e *ConfigError) Error() string {
+	return "failed to load config: " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// BuildError is returned from Build when the sites could not be built,
+// e.g. because of a template error.
+type BuildError struct {
+	Err error
+
+	// The stable code identifying the class of the error,
+	// e.g. herrors.CodeTemplateExecute.
+	Code string
+
+	// The file and position of the error, if known.
+	Filename string
+	Line     int
+	Column   int
+
+	// The path of the page the error relates to, if known.
+	Page string
+}
+
+func (e *BuildError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *BuildError) Unwrap() error {
+	return e.Err
+}
+
+func newBuildError(err error) *BuildError {
+	info := herrors.NewErrorInfo(err)
+	return &BuildError{
+		Err:      err,
+		Code:     info.Code,
+		Filename: info.File,
+		Line:     info.Line,
+		Column:   info.Column,
+		Page:     info.Page,
+	}
+}
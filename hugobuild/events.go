@@ -0,0 +1,112 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugobuild
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EventType is the type of a progress event.
+type EventType string
+
+const (
+	// The configuration is being loaded.
+	EventLoadConfig EventType = "loadConfig"
+
+	// The sites are being built.
+	EventBuild EventType = "build"
+
+	// The pages are assembled and are being rendered.
+	EventRender EventType = "render"
+
+	// A page was rendered in one of its output formats.
+	EventPageRendered EventType = "pageRendered"
+
+	// A message was logged during the build.
+	EventLog EventType = "log"
+
+	// The build completed successfully.
+	EventDone EventType = "done"
+)
+
+// The log levels used in log events.
+const (
+	LevelInfo  = "info"
+	LevelWarn  = "warn"
+	LevelError = "error"
+)
+
+// Event is a progress event sent to Options.Progress.
+type Event struct {
+	Type EventType
+	Time time.Time
+
+	// The log level and message for log events.
+	Level   string
+	Message string
+
+	// For render events, the number of pages assembled, in all languages.
+	// For page rendered events, the number of pages rendered so far, where
+	// a page rendered in two output formats counts twice.
+	Count int
+
+	// The output format, e.g. "HTML", and the path relative to the publish
+	// dir for page rendered events.
+	OutputFormat string
+	TargetPath   string
+}
+
+var logLineRe = regexp.MustCompile(`^(TRACE|DEBUG|INFO|WARN|ERROR|CRITICAL|FATAL)\s+(?:\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2} )?`)
+
+// logWriter turns the lines written to the logger into log events.
+type logWriter struct {
+	mu   sync.Mutex
+	buf  bytes.Buffer
+	emit func(e Event)
+}
+
+func (w *logWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf.Write(p)
+	for {
+		i := bytes.IndexByte(w.buf.Bytes(), '\n')
+		if i < 0 {
+			break
+		}
+		line := string(w.buf.Next(i + 1))
+		w.emitLine(strings.TrimRight(line, "\r\n"))
+	}
+
+	return len(p), nil
+}
+
+func (w *logWriter) emitLine(line string) {
+	level := LevelInfo
+	if m := logLineRe.FindStringSubmatch(line); m != nil {
+		switch m[1] {
+		case "WARN":
+			level = LevelWarn
+		case "ERROR", "CRITICAL", "FATAL":
+			level = LevelError
+		}
+		line = line[len(m[0]):]
+	}
+	w.emit(Event{Type: EventLog, Level: level, Message: line})
+}
@@ -0,0 +1,176 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugobuild
+
+import (
+	"io/fs"
+	"time"
+
+	"github.com/gohugoio/hugo/config"
+	"github.com/gohugoio/hugo/hugolib"
+	"github.com/gohugoio/hugo/output"
+	"github.com/gohugoio/hugo/resources/page"
+	"github.com/spf13/afero"
+)
+
+// Hooks holds callbacks invoked at the stages of a build, e.g. to validate
+// the site or to export it somewhere. All of them are optional, and an
+// error returned fails the build with a *BuildError.
+type Hooks struct {
+	// Called with the site configuration before the build starts.
+	OnConfigLoaded func(cfg Config) error
+
+	// Called with the pages of all sites when they are assembled, before
+	// they are rendered.
+	OnPagesAssembled func(pages []Page) error
+
+	// Called for every page rendered, before it is published.
+	// This is called concurrently.
+	OnPageRendered func(p RenderedPage) error
+
+	// Called when the build is done and all files are published.
+	OnSitePublished func(site PublishedSite) error
+}
+
+// Config is a read-only view of the site configuration.
+type Config struct {
+	cfg config.Provider
+}
+
+// Get returns the value for the given key, e.g. "baseURL" or "params.foo",
+// nil if not set.
+func (c Config) Get(key string) interface{} {
+	return c.cfg.Get(key)
+}
+
+// GetString returns the value for the given key as a string.
+func (c Config) GetString(key string) string {
+	return c.cfg.GetString(key)
+}
+
+// IsSet reports whether the given key is set.
+func (c Config) IsSet(key string) bool {
+	return c.cfg.IsSet(key)
+}
+
+// Page is a read-only view of a page.
+type Page struct {
+	// The page kind, e.g. "page", "section" or "home".
+	Kind string
+	Lang string
+
+	// The path to the content file relative to the content dir, if any.
+	Path string
+
+	Section      string
+	Title        string
+	Permalink    string
+	RelPermalink string
+	Draft        bool
+	Date         time.Time
+
+	// A copy of the page params.
+	Params map[string]interface{}
+}
+
+// RenderedPage is a page rendered in one of its output formats.
+type RenderedPage struct {
+	Page
+
+	// The output format, e.g. "HTML".
+	OutputFormat string
+
+	// The path the page is published to, relative to the publish dir.
+	TargetPath string
+
+	// The rendered page, before any post processing, e.g. minification.
+	// It must not be retained.
+	Content []byte
+}
+
+// PublishedSite describes the published site.
+type PublishedSite struct {
+	// The absolute publish directory.
+	PublishDir string
+
+	// The published files.
+	Output fs.FS
+}
+
+func newPage(p page.Page) Page {
+	params := make(map[string]interface{})
+	for k, v := range p.Params() {
+		params[k] = v
+	}
+
+	return Page{
+		Kind:         p.Kind(),
+		Lang:         p.Lang(),
+		Path:         p.Path(),
+		Section:      p.Section(),
+		Title:        p.Title(),
+		Permalink:    p.Permalink(),
+		RelPermalink: p.RelPermalink(),
+		Draft:        p.Draft(),
+		Date:         p.Date(),
+		Params:       params,
+	}
+}
+
+// toBuildHooks adapts hooks to the hooks used by h.
+func (hooks Hooks) toBuildHooks(h *hugolib.HugoSites) hugolib.BuildHooks {
+	var bh hugolib.BuildHooks
+
+	if hooks.OnConfigLoaded != nil {
+		bh.OnConfigLoaded = func(cfg config.Provider) error {
+			return hooks.OnConfigLoaded(Config{cfg: cfg})
+		}
+	}
+
+	if hooks.OnPagesAssembled != nil {
+		bh.OnPagesAssembled = func(pages page.Pages) error {
+			ps := make([]Page, len(pages))
+			for i, p := range pages {
+				ps[i] = newPage(p)
+			}
+			return hooks.OnPagesAssembled(ps)
+		}
+	}
+
+	if hooks.OnPageRendered != nil {
+		bh.OnPageRendered = func(p page.Page, f output.Format, targetPath string, content []byte) error {
+			return hooks.OnPageRendered(RenderedPage{
+				Page:         newPage(p),
+				OutputFormat: f.Name,
+				TargetPath:   targetPath,
+				Content:      content,
+			})
+		}
+	}
+
+	if hooks.OnSitePublished != nil {
+		bh.OnSitePublished = func(publishDir string) error {
+			return hooks.OnSitePublished(PublishedSite{
+				PublishDir: publishDir,
+				Output:     newOutputFS(h),
+			})
+		}
+	}
+
+	return bh
+}
+
+func newOutputFS(h *hugolib.HugoSites) fs.FS {
+	return afero.NewIOFS(afero.NewBasePathFs(h.Fs.Destination, h.PathSpec.PublishDir))
+}
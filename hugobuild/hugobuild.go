@@ -0,0 +1,224 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package hugobuild provides a stable API for embedding Hugo, e.g. in preview
+// servers and CMS backends.
+//
+// The types and functions in the other packages in this module are internal
+// to Hugo and change between releases; the API in this package does not.
+package hugobuild
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/gohugoio/hugo/common/loggers"
+	"github.com/gohugoio/hugo/config"
+	"github.com/gohugoio/hugo/deps"
+	"github.com/gohugoio/hugo/hugofs"
+	"github.com/gohugoio/hugo/hugolib"
+	"github.com/gohugoio/hugo/output"
+	"github.com/gohugoio/hugo/resources/page"
+	jww "github.com/spf13/jwalterweatherman"
+)
+
+// Options configures a build.
+type Options struct {
+	// The project directory. Defaults to the current directory.
+	// If FS is set, this is only used in file paths and errors.
+	Dir string
+
+	// If set, the project is read from FS instead of Dir, e.g. an embed.FS or
+	// a fstest.MapFS. This implies InMemory.
+	FS fs.FS
+
+	// Keep the published site in memory instead of writing it to publishDir.
+	// Nothing is written to disk.
+	InMemory bool
+
+	// The environment, e.g. production or development. Defaults to production.
+	Environment string
+
+	// Configuration applied on top of the project configuration, e.g. baseURL.
+	Config map[string]interface{}
+
+	// Also report INFO log messages as events.
+	Verbose bool
+
+	// If set, this is called with the progress events of the build, in order.
+	// It's never called concurrently.
+	Progress func(Event)
+
+	// Callbacks invoked at the stages of the build.
+	Hooks Hooks
+}
+
+// Result holds the result of a successful build.
+type Result struct {
+	// The number of pages built, in all languages.
+	Pages int
+
+	// The number of warnings logged.
+	Warnings int
+
+	// How long the build took.
+	Duration time.Duration
+
+	// The published site.
+	Output fs.FS
+}
+
+// Build builds the project described by opts.
+//
+// The error returned is either a *ConfigError, a *BuildError or, if ctx is
+// done before the build completes, ctx.Err(). The context is checked between
+// the stages of the build and before every page is rendered; the pages
+// being rendered when ctx is done are completed.
+func Build(ctx context.Context, opts Options) (Result, error) {
+	start := time.Now()
+	b := &builder{opts: opts}
+
+	logThreshold := jww.LevelWarn
+	if opts.Verbose {
+		logThreshold = jww.LevelInfo
+	}
+	logger := loggers.NewBasicLoggerForWriter(logThreshold, &logWriter{emit: b.emit})
+
+	b.emit(Event{Type: EventLoadConfig})
+	fs, cfg, err := b.loadConfig(logger)
+	if err != nil {
+		return Result{}, &ConfigError{Err: err}
+	}
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+
+	h, err := hugolib.NewHugoSites(deps.DepsCfg{Fs: fs, Cfg: cfg, Logger: logger})
+	if err != nil {
+		return Result{}, newBuildError(err)
+	}
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+
+	h.AddBuildHooks(opts.Hooks.toBuildHooks(h))
+	if opts.Progress != nil {
+		h.AddBuildHooks(b.progressHooks())
+	}
+
+	b.emit(Event{Type: EventBuild})
+	if err := h.Build(hugolib.BuildCfg{Context: ctx}); err != nil {
+		if ctx.Err() != nil {
+			return Result{}, ctx.Err()
+		}
+		return Result{}, newBuildError(err)
+	}
+
+	res := Result{
+		Pages:    len(h.Pages()),
+		Warnings: int(logger.LogCounters().WarnCounter.Count()),
+		Duration: time.Since(start),
+		Output:   newOutputFS(h),
+	}
+
+	b.emit(Event{Type: EventDone})
+
+	return res, nil
+}
+
+type builder struct {
+	opts Options
+
+	mu       sync.Mutex
+	rendered int
+}
+
+func (b *builder) emit(e Event) {
+	if b.opts.Progress == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	if e.Type == EventPageRendered {
+		b.rendered++
+		e.Count = b.rendered
+	}
+	b.opts.Progress(e)
+}
+
+// progressHooks returns the hooks emitting the render progress events.
+func (b *builder) progressHooks() hugolib.BuildHooks {
+	return hugolib.BuildHooks{
+		OnPagesAssembled: func(pages page.Pages) error {
+			b.emit(Event{Type: EventRender, Count: len(pages)})
+			return nil
+		},
+		OnPageRendered: func(p page.Page, f output.Format, targetPath string, content []byte) error {
+			b.emit(Event{Type: EventPageRendered, OutputFormat: f.Name, TargetPath: targetPath})
+			return nil
+		},
+	}
+}
+
+func (b *builder) loadConfig(logger loggers.Logger) (*hugofs.Fs, config.Provider, error) {
+	dir := b.opts.Dir
+	if dir == "" {
+		dir = "."
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fsys := b.opts.FS
+	if fsys == nil && b.opts.InMemory {
+		fsys = os.DirFS(dir)
+	}
+
+	if fsys != nil {
+		return hugolib.LoadConfigFromFS(hugolib.FSConfig{
+			FS:          fsys,
+			WorkingDir:  dir,
+			Environment: b.opts.Environment,
+			Config:      b.opts.Config,
+			Logger:      logger,
+		})
+	}
+
+	cfg, _, err := hugolib.LoadConfig(hugolib.ConfigSourceDescriptor{
+		Fs:           hugofs.Os,
+		Logger:       logger,
+		Path:         dir,
+		WorkingDir:   dir,
+		AbsConfigDir: filepath.Join(dir, "config"),
+		Environment:  b.opts.Environment,
+	}, func(cfg config.Provider) error {
+		for k, v := range b.opts.Config {
+			cfg.Set(k, v)
+		}
+		cfg.Set("workingDir", dir)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return hugofs.NewDefault(cfg), cfg, nil
+}
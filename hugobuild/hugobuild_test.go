@@ -0,0 +1,214 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugobuild
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/common/herrors"
+)
+
+func TestBuild(t *testing.T) {
+	c := qt.New(t)
+
+	files := fstest.MapFS{
+		"config.toml": {Data: []byte(`
+baseURL = "https://example.org/"
+disableKinds = ["taxonomy", "term", "RSS", "sitemap"]
+`)},
+		"content/p1.md":                {Data: []byte("---\ntitle: P1\n---\nContent.")},
+		"layouts/_default/single.html": {Data: []byte(`{{ warnf "Warning in %s" .Title }}Single: {{ .Title }}`)},
+		"layouts/index.html":           {Data: []byte(`Home: {{ .Site.Params.foo }}`)},
+	}
+
+	var events []Event
+	res, err := Build(context.Background(), Options{
+		FS:       files,
+		Config:   map[string]interface{}{"params": map[string]interface{}{"foo": "bar"}},
+		Progress: func(e Event) { events = append(events, e) },
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(res.Pages, qt.Equals, 2)
+	c.Assert(res.Warnings, qt.Equals, 1)
+
+	b, err := fs.ReadFile(res.Output, "p1/index.html")
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, "Single: P1")
+	b, err = fs.ReadFile(res.Output, "index.html")
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, "Home: bar")
+
+	var (
+		types    []EventType
+		rendered []string
+	)
+	for _, e := range events {
+		c.Assert(e.Time.IsZero(), qt.IsFalse)
+		types = append(types, e.Type)
+		switch e.Type {
+		case EventLog:
+			c.Assert(e.Level, qt.Equals, LevelWarn)
+			c.Assert(e.Message, qt.Equals, "Warning in P1")
+		case EventRender:
+			c.Assert(e.Count, qt.Equals, 2)
+		case EventPageRendered:
+			c.Assert(e.OutputFormat, qt.Equals, "HTML")
+			c.Assert(e.Count, qt.Equals, len(rendered)+1)
+			rendered = append(rendered, e.TargetPath)
+		}
+	}
+	c.Assert(types[:3], qt.DeepEquals, []EventType{EventLoadConfig, EventBuild, EventRender})
+	c.Assert(types[len(types)-1], qt.Equals, EventDone)
+	c.Assert(types, qt.HasLen, 7)
+	sort.Strings(rendered)
+	c.Assert(rendered, qt.DeepEquals, []string{"/index.html", "/p1/index.html"})
+
+	c.Run("Dir", func(c *qt.C) {
+		dir := c.TempDir()
+		for name, f := range files {
+			filename := filepath.Join(dir, filepath.FromSlash(name))
+			c.Assert(os.MkdirAll(filepath.Dir(filename), 0777), qt.IsNil)
+			c.Assert(os.WriteFile(filename, f.Data, 0666), qt.IsNil)
+		}
+
+		res, err := Build(context.Background(), Options{Dir: dir})
+		c.Assert(err, qt.IsNil)
+		b, err := os.ReadFile(filepath.Join(dir, "public", "p1", "index.html"))
+		c.Assert(err, qt.IsNil)
+		c.Assert(string(b), qt.Equals, "Single: P1")
+		b, err = fs.ReadFile(res.Output, "p1/index.html")
+		c.Assert(err, qt.IsNil)
+		c.Assert(string(b), qt.Equals, "Single: P1")
+
+		c.Assert(os.RemoveAll(filepath.Join(dir, "public")), qt.IsNil)
+		res, err = Build(context.Background(), Options{Dir: dir, InMemory: true})
+		c.Assert(err, qt.IsNil)
+		_, err = fs.ReadFile(res.Output, "p1/index.html")
+		c.Assert(err, qt.IsNil)
+		_, err = os.Stat(filepath.Join(dir, "public"))
+		c.Assert(os.IsNotExist(err), qt.IsTrue)
+	})
+
+	c.Run("Hooks", func(c *qt.C) {
+		var (
+			mu    sync.Mutex
+			calls []string
+		)
+		add := func(s string) {
+			mu.Lock()
+			defer mu.Unlock()
+			calls = append(calls, s)
+		}
+
+		_, err := Build(context.Background(), Options{FS: files, Hooks: Hooks{
+			OnConfigLoaded: func(cfg Config) error {
+				add("config:" + cfg.GetString("baseURL"))
+				return nil
+			},
+			OnPagesAssembled: func(pages []Page) error {
+				for _, p := range pages {
+					add("assembled:" + p.Kind + ":" + p.RelPermalink)
+				}
+				return nil
+			},
+			OnPageRendered: func(p RenderedPage) error {
+				add("rendered:" + p.TargetPath + ":" + string(p.Content))
+				return nil
+			},
+			OnSitePublished: func(site PublishedSite) error {
+				b, err := fs.ReadFile(site.Output, "p1/index.html")
+				add("published:" + string(b))
+				return err
+			},
+		}})
+		c.Assert(err, qt.IsNil)
+		c.Assert(calls[:3], qt.DeepEquals, []string{"config:https://example.org/", "assembled:home:/", "assembled:page:/p1/"})
+		rendered := calls[3:5]
+		sort.Strings(rendered)
+		c.Assert(rendered, qt.DeepEquals, []string{"rendered:/index.html:Home: ", "rendered:/p1/index.html:Single: P1"})
+		c.Assert(calls[5:], qt.DeepEquals, []string{"published:Single: P1"})
+
+		_, err = Build(context.Background(), Options{FS: files, Hooks: Hooks{
+			OnPagesAssembled: func(pages []Page) error {
+				return errors.New("invalid")
+			},
+		}})
+		var berr *BuildError
+		c.Assert(errors.As(err, &berr), qt.IsTrue)
+		c.Assert(err, qt.ErrorMatches, ".*OnPagesAssembled: invalid")
+	})
+
+	c.Run("Canceled", func(c *qt.C) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := Build(ctx, Options{FS: files})
+		c.Assert(err, qt.Equals, context.Canceled)
+
+		// Canceled while rendering.
+		many := fstest.MapFS{}
+		for name, f := range files {
+			many[name] = f
+		}
+		for i := 0; i < 100; i++ {
+			many[fmt.Sprintf("content/p%d.md", i)] = &fstest.MapFile{Data: []byte("---\ntitle: P\n---\n")}
+		}
+		ctx, cancel = context.WithCancel(context.Background())
+		defer cancel()
+		var (
+			mu       sync.Mutex
+			rendered int
+		)
+		_, err = Build(ctx, Options{FS: many, Hooks: Hooks{
+			OnPageRendered: func(p RenderedPage) error {
+				mu.Lock()
+				defer mu.Unlock()
+				rendered++
+				cancel()
+				return nil
+			},
+		}})
+		c.Assert(err, qt.Equals, context.Canceled)
+		c.Assert(rendered < 50, qt.IsTrue, qt.Commentf("rendered %d", rendered))
+	})
+}
+
+func TestBuildErrors(t *testing.T) {
+	c := qt.New(t)
+
+	_, err := Build(context.Background(), Options{FS: fstest.MapFS{
+		"config.toml": {Data: []byte(`baseURL = `)},
+	}})
+	var cerr *ConfigError
+	c.Assert(errors.As(err, &cerr), qt.IsTrue)
+
+	_, err = Build(context.Background(), Options{FS: fstest.MapFS{
+		"config.toml":        {Data: []byte(`baseURL = "https://example.org/"`)},
+		"layouts/index.html": {Data: []byte("Home.\n{{ .Foo }}")},
+	}})
+	var berr *BuildError
+	c.Assert(errors.As(err, &berr), qt.IsTrue)
+	c.Assert(berr.Filename, qt.Contains, "index.html")
+	c.Assert(berr.Line, qt.Equals, 2)
+	c.Assert(berr.Code, qt.Equals, herrors.CodeTemplateExecute)
+}
@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/gohugoio/hugo/hugofs/files"
+	"github.com/gohugoio/hugo/hugofs/glob"
 	"golang.org/x/text/unicode/norm"
 
 	"github.com/pkg/errors"
@@ -59,6 +60,8 @@ const (
 	metaKeyTranslationBaseNameWithExt = "translationBaseNameWithExt"
 	metaKeyTranslations               = "translations"
 	metaKeyDecoraterPath              = "decoratorPath"
+	metaKeyInclusionFilter            = "inclusionFilter"
+	metaKeySymlinkPolicy              = "symlinkPolicy"
 )
 
 type FileMeta map[string]interface{}
@@ -142,6 +145,21 @@ func (f FileMeta) Module() string {
 	return f.stringV(metaKeyModule)
 }
 
+// InclusionFilter returns any filter configured for the mount this file
+// belongs to. A nil filter includes everything.
+func (f FileMeta) InclusionFilter() *glob.FilenameFilter {
+	if v, found := f[metaKeyInclusionFilter]; found {
+		return v.(*glob.FilenameFilter)
+	}
+	return nil
+}
+
+// SymlinkPolicy returns the symlink policy configured for the mount this
+// file belongs to, empty if not set.
+func (f FileMeta) SymlinkPolicy() string {
+	return f.stringV(metaKeySymlinkPolicy)
+}
+
 func (f FileMeta) Weight() int {
 	return f.GetInt(metaKeyWeight)
 }
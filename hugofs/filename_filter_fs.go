@@ -0,0 +1,97 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/gohugoio/hugo/hugofs/glob"
+	"github.com/spf13/afero"
+)
+
+var (
+	_ afero.Fs      = (*filenameFilterFs)(nil)
+	_ afero.Lstater = (*filenameFilterFs)(nil)
+)
+
+// filenameFilterFs hides the files in the wrapped filesystem not matched by
+// filter. It is used for mounts with includeFiles/excludeFiles set.
+type filenameFilterFs struct {
+	afero.Fs
+	filter *glob.FilenameFilter
+}
+
+func newFilenameFilterFs(fs afero.Fs, filter *glob.FilenameFilter) afero.Fs {
+	return &filenameFilterFs{Fs: fs, filter: filter}
+}
+
+func (fs *filenameFilterFs) LstatIfPossible(name string) (os.FileInfo, bool, error) {
+	fi, b, err := lstatIfPossible(fs.Fs, name)
+	if err != nil {
+		return nil, b, err
+	}
+	if !fs.filter.Match(name, fi.IsDir()) {
+		return nil, b, &os.PathError{Op: "LStat", Path: name, Err: os.ErrNotExist}
+	}
+	return fi, b, nil
+}
+
+func (fs *filenameFilterFs) Stat(name string) (os.FileInfo, error) {
+	fi, _, err := fs.LstatIfPossible(name)
+	return fi, err
+}
+
+func (fs *filenameFilterFs) Open(name string) (afero.File, error) {
+	if _, err := fs.Stat(name); err != nil {
+		return nil, err
+	}
+	f, err := fs.Fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &filenameFilterDir{File: f, fs: fs, name: name}, nil
+}
+
+func (fs *filenameFilterFs) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	return fs.Open(name)
+}
+
+type filenameFilterDir struct {
+	afero.File
+	fs   *filenameFilterFs
+	name string
+}
+
+func (f *filenameFilterDir) Readdir(count int) ([]os.FileInfo, error) {
+	fis, err := f.File.Readdir(count)
+	if err != nil {
+		return nil, err
+	}
+	result := fis[:0]
+	for _, fi := range fis {
+		if f.fs.filter.Match(filepath.Join(f.name, fi.Name()), fi.IsDir()) {
+			result = append(result, fi)
+		}
+	}
+	return result, nil
+}
+
+func (f *filenameFilterDir) Readdirnames(count int) ([]string, error) {
+	fis, err := f.Readdir(count)
+	if err != nil {
+		return nil, err
+	}
+	return fileInfosToNames(fis), nil
+}
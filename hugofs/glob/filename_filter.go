@@ -0,0 +1,151 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package glob
+
+import (
+	"strings"
+
+	"github.com/gobwas/glob"
+)
+
+// FilenameFilter filters filenames given a set of include and exclude Glob
+// patterns, e.g. "docs/**.md". Patterns are matched case insensitively
+// against slash separated paths relative to some root, e.g. a mount's source
+// directory.
+type FilenameFilter struct {
+	inclusions []glob.Glob
+	exclusions []glob.Glob
+
+	// The static part of the include patterns, e.g. "docs" for "docs/**.md".
+	// Used to determine which directories may contain included files.
+	dirInclusions []string
+}
+
+// NewFilenameFilter creates a new FilenameFilter. It returns nil, nil if both
+// inclusions and exclusions are empty.
+func NewFilenameFilter(inclusions, exclusions []string) (*FilenameFilter, error) {
+	if len(inclusions) == 0 && len(exclusions) == 0 {
+		return nil, nil
+	}
+
+	f := &FilenameFilter{}
+
+	for _, pattern := range inclusions {
+		pattern = normalizeFilterPattern(pattern)
+		if pattern == "" {
+			continue
+		}
+		if !HasGlobChar(pattern) {
+			// A plain directory or file, e.g. "docs".
+			f.dirInclusions = append(f.dirInclusions, pattern)
+			if err := f.addGlobs(&f.inclusions, pattern, pattern+"/**"); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		dir := ResolveRootDir(pattern)
+		if dir == "." {
+			dir = ""
+		}
+		f.dirInclusions = append(f.dirInclusions, dir)
+		if err := f.addGlobs(&f.inclusions, expandDoubleStar(pattern)...); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, pattern := range exclusions {
+		pattern = normalizeFilterPattern(pattern)
+		if pattern == "" {
+			continue
+		}
+		patterns := expandDoubleStar(pattern)
+		if !HasGlobChar(pattern) {
+			patterns = append(patterns, pattern+"/**")
+		}
+		if err := f.addGlobs(&f.exclusions, patterns...); err != nil {
+			return nil, err
+		}
+	}
+
+	return f, nil
+}
+
+func (f *FilenameFilter) addGlobs(globs *[]glob.Glob, patterns ...string) error {
+	for _, pattern := range patterns {
+		g, err := GetGlob(pattern)
+		if err != nil {
+			return err
+		}
+		*globs = append(*globs, g)
+	}
+	return nil
+}
+
+// Match reports whether filename is included by this filter. Directories
+// are included if they may contain included files.
+// A nil filter includes everything.
+func (f *FilenameFilter) Match(filename string, isDir bool) bool {
+	if f == nil {
+		return true
+	}
+
+	filename = NormalizePath(filename)
+	if filename == "" {
+		// The root.
+		return true
+	}
+
+	for _, g := range f.exclusions {
+		if g.Match(filename) {
+			return false
+		}
+	}
+
+	if len(f.inclusions) == 0 {
+		return true
+	}
+
+	if isDir {
+		for _, dir := range f.dirInclusions {
+			if dir == "" || strings.HasPrefix(dir+"/", filename+"/") || strings.HasPrefix(filename+"/", dir+"/") {
+				return true
+			}
+		}
+	}
+
+	for _, g := range f.inclusions {
+		if g.Match(filename) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func normalizeFilterPattern(pattern string) string {
+	return strings.Trim(strings.TrimSpace(strings.ReplaceAll(pattern, "\\", "/")), "/")
+}
+
+// expandDoubleStar makes "/**/" also match zero directories, which is what
+// most users expect, e.g. "docs/**/*.md" should match "docs/index.md".
+func expandDoubleStar(pattern string) []string {
+	patterns := []string{pattern}
+	if strings.Contains(pattern, "/**/") {
+		patterns = append(patterns, strings.ReplaceAll(pattern, "/**/", "/"))
+	}
+	if strings.HasPrefix(pattern, "**/") {
+		patterns = append(patterns, strings.TrimPrefix(pattern, "**/"))
+	}
+	return patterns
+}
@@ -0,0 +1,62 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package glob
+
+import (
+	"path/filepath"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestFilenameFilter(t *testing.T) {
+	c := qt.New(t)
+
+	nilFilter, err := NewFilenameFilter(nil, nil)
+	c.Assert(err, qt.IsNil)
+	c.Assert(nilFilter, qt.IsNil)
+	c.Assert(nilFilter.Match("a/b.md", false), qt.IsTrue)
+
+	f, err := NewFilenameFilter([]string{"docs/**/*.md", "/static"}, []string{"**/drafts/**", "*.tmp"})
+	c.Assert(err, qt.IsNil)
+
+	for _, test := range []struct {
+		name   string
+		isDir  bool
+		expect bool
+	}{
+		{"", true, true},
+		{"docs", true, true},
+		{"docs/a", true, true},
+		{"docs/index.md", false, true},
+		{"docs/a/b.md", false, true},
+		{filepath.FromSlash("docs/a/B.MD"), false, true},
+		{"docs/a/b.json", false, false},
+		{"docs/drafts/b.md", false, false},
+		{"src", true, false},
+		{"README.md", false, false},
+		{"static/logo.png", false, true},
+		{"static", true, true},
+		{"static/a.tmp", false, true},
+		{"a.tmp", false, false},
+	} {
+		c.Assert(f.Match(test.name, test.isDir), qt.Equals, test.expect, qt.Commentf(test.name))
+	}
+
+	excludeOnly, err := NewFilenameFilter(nil, []string{"node_modules"})
+	c.Assert(err, qt.IsNil)
+	c.Assert(excludeOnly.Match("node_modules", true), qt.IsFalse)
+	c.Assert(excludeOnly.Match("node_modules/a/b.js", false), qt.IsFalse)
+	c.Assert(excludeOnly.Match("a/b.js", false), qt.IsTrue)
+}
@@ -0,0 +1,27 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"os"
+	"syscall"
+)
+
+// IsHardLinked reports whether fi describes a file on the OS filesystem that
+// may have more than one hard link. The link count is not available on
+// Windows, so this is true for all files on the OS filesystem.
+func IsHardLinked(fi os.FileInfo) bool {
+	_, ok := fi.Sys().(*syscall.Win32FileAttributeData)
+	return ok
+}
@@ -0,0 +1,109 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gohugoio/hugo/config"
+	"github.com/spf13/afero"
+)
+
+// NewFromIOFS creates a read-only filesystem with the files in fsys available
+// below the absolute directory root, e.g. /my/project/content/post.md for the
+// file content/post.md in fsys.
+func NewFromIOFS(fsys fs.FS, root string) afero.Fs {
+	return afero.NewReadOnlyFs(&ioFs{Fs: afero.FromIOFS{FS: fsys}, root: filepath.Clean(root)})
+}
+
+// NewFromFS creates a new Fs with the files in fsys as the source, available
+// below the working directory in cfg. The destination is in memory, as are
+// any files written to the source, e.g. to the file caches.
+func NewFromFS(fsys fs.FS, cfg config.Provider) *Fs {
+	source := afero.NewCopyOnWriteFs(NewFromIOFS(fsys, cfg.GetString("workingDir")), afero.NewMemMapFs())
+	fs := newFs(source, cfg)
+	fs.Destination = afero.NewMemMapFs()
+	return fs
+}
+
+// ioFs maps the absolute filenames used in Hugo to the names in a fs.FS.
+type ioFs struct {
+	afero.Fs
+	root string
+}
+
+// rel returns the name in the fs.FS for filename.
+func (fs *ioFs) rel(filename string) (string, bool) {
+	filename = filepath.Clean(filename)
+	if filename == fs.root {
+		return ".", true
+	}
+	prefix := strings.TrimSuffix(fs.root, string(filepath.Separator)) + string(filepath.Separator)
+	if !strings.HasPrefix(filename, prefix) {
+		return "", false
+	}
+	return filepath.ToSlash(strings.TrimPrefix(filename, prefix)), true
+}
+
+func (fs *ioFs) Open(name string) (afero.File, error) {
+	rel, ok := fs.rel(name)
+	if !ok {
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+	f, err := fs.Fs.Open(rel)
+	if err != nil {
+		return nil, renamePathError(err, name)
+	}
+	return &ioFile{File: f, name: name}, nil
+}
+
+func (fs *ioFs) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	return fs.Open(name)
+}
+
+func (fs *ioFs) Stat(name string) (os.FileInfo, error) {
+	rel, ok := fs.rel(name)
+	if !ok {
+		return nil, &os.PathError{Op: "stat", Path: name, Err: os.ErrNotExist}
+	}
+	fi, err := fs.Fs.Stat(rel)
+	if err != nil {
+		return nil, renamePathError(err, name)
+	}
+	return fi, nil
+}
+
+func (fs *ioFs) Name() string {
+	return "ioFs"
+}
+
+// renamePathError sets the path in err to name, if it is a *os.PathError.
+func renamePathError(err error, name string) error {
+	if perr, ok := err.(*os.PathError); ok {
+		return &os.PathError{Op: perr.Op, Path: name, Err: perr.Err}
+	}
+	return err
+}
+
+type ioFile struct {
+	afero.File
+	name string
+}
+
+func (f *ioFile) Name() string {
+	return f.name
+}
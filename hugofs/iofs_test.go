@@ -0,0 +1,70 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/config"
+	"github.com/spf13/afero"
+)
+
+func TestNewFromFS(t *testing.T) {
+	c := qt.New(t)
+
+	cfg := config.New()
+	cfg.Set("workingDir", filepath.FromSlash("/my/project"))
+
+	fs := NewFromFS(fstest.MapFS{
+		"content/a.md": {Data: []byte("a")},
+		"content/b.md": {Data: []byte("b")},
+	}, cfg)
+
+	filename := filepath.FromSlash("/my/project/content/a.md")
+
+	b, err := afero.ReadFile(fs.Source, filename)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, "a")
+
+	f, err := fs.Source.Open(filename)
+	c.Assert(err, qt.IsNil)
+	c.Assert(f.Name(), qt.Equals, filename)
+	f.Close()
+
+	names, err := afero.ReadDir(fs.Source, filepath.FromSlash("/my/project/content"))
+	c.Assert(err, qt.IsNil)
+	c.Assert(names, qt.HasLen, 2)
+
+	fi, err := fs.Source.Stat(filepath.FromSlash("/my/project"))
+	c.Assert(err, qt.IsNil)
+	c.Assert(fi.IsDir(), qt.IsTrue)
+
+	_, err = fs.Source.Stat(filepath.FromSlash("/my/project/content/c.md"))
+	c.Assert(os.IsNotExist(err), qt.IsTrue)
+	_, err = fs.Source.Stat(filepath.FromSlash("/other/content/a.md"))
+	c.Assert(os.IsNotExist(err), qt.IsTrue)
+
+	// Writes go to memory.
+	c.Assert(afero.WriteFile(fs.Source, filename, []byte("changed"), 0666), qt.IsNil)
+	b, err = afero.ReadFile(fs.Source, filename)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, "changed")
+	c.Assert(afero.WriteFile(fs.Destination, "/index.html", []byte("index"), 0666), qt.IsNil)
+	exists, _ := afero.Exists(fs.Source, "/index.html")
+	c.Assert(exists, qt.IsFalse)
+}
@@ -0,0 +1,65 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// The modes for publishing a file to the destination.
+const (
+	// LinkModeCopy copies the file.
+	LinkModeCopy = "copy"
+
+	// LinkModeHardlink creates a hard link to the file.
+	LinkModeHardlink = "hardlink"
+
+	// LinkModeReflink creates a copy-on-write clone of the file.
+	// This is currently supported on Linux (e.g. Btrfs and XFS) and macOS (APFS).
+	LinkModeReflink = "reflink"
+)
+
+// ErrLinkNotSupported is returned when the OS or the filesystem does not
+// support the link mode requested.
+var ErrLinkNotSupported = errors.New("link mode not supported")
+
+// ValidateLinkMode returns an error if mode is not a valid link mode.
+func ValidateLinkMode(mode string) error {
+	switch mode {
+	case LinkModeCopy, LinkModeHardlink, LinkModeReflink:
+		return nil
+	default:
+		return fmt.Errorf("invalid link mode %q, must be one of %q, %q or %q", mode, LinkModeCopy, LinkModeHardlink, LinkModeReflink)
+	}
+}
+
+// LinkFile creates the file dst as a hard link to, or a clone of, the file
+// src, both OS filenames. Any existing dst is replaced. Note that dst must
+// not exist for reflinks on macOS.
+func LinkFile(mode, dst, src string) error {
+	if err := os.Remove(dst); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	switch mode {
+	case LinkModeHardlink:
+		return os.Link(src, dst)
+	case LinkModeReflink:
+		return reflink(dst, src)
+	default:
+		return ErrLinkNotSupported
+	}
+}
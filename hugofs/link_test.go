@@ -0,0 +1,74 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestValidateLinkMode(t *testing.T) {
+	c := qt.New(t)
+
+	for _, mode := range []string{LinkModeCopy, LinkModeHardlink, LinkModeReflink} {
+		c.Assert(ValidateLinkMode(mode), qt.IsNil)
+	}
+	c.Assert(ValidateLinkMode("symlink"), qt.Not(qt.IsNil))
+}
+
+func TestLinkFile(t *testing.T) {
+	c := qt.New(t)
+
+	dir, err := ioutil.TempDir("", "hugo-link")
+	c.Assert(err, qt.IsNil)
+	defer os.RemoveAll(dir)
+
+	src := filepath.Join(dir, "src.txt")
+	c.Assert(ioutil.WriteFile(src, []byte("source"), 0644), qt.IsNil)
+
+	c.Run("Hardlink", func(c *qt.C) {
+		dst := filepath.Join(dir, "hardlink.txt")
+		c.Assert(ioutil.WriteFile(dst, []byte("existing"), 0644), qt.IsNil)
+		c.Assert(LinkFile(LinkModeHardlink, dst, src), qt.IsNil)
+
+		sfi, err := os.Stat(src)
+		c.Assert(err, qt.IsNil)
+		dfi, err := os.Stat(dst)
+		c.Assert(err, qt.IsNil)
+		c.Assert(os.SameFile(sfi, dfi), qt.IsTrue)
+	})
+
+	c.Run("Reflink", func(c *qt.C) {
+		dst := filepath.Join(dir, "reflink.txt")
+		err := LinkFile(LinkModeReflink, dst, src)
+		if err == ErrLinkNotSupported {
+			c.Skip("reflinks not supported on this filesystem")
+		}
+		c.Assert(err, qt.IsNil)
+
+		b, err := ioutil.ReadFile(dst)
+		c.Assert(err, qt.IsNil)
+		c.Assert(string(b), qt.Equals, "source")
+
+		sfi, err := os.Stat(src)
+		c.Assert(err, qt.IsNil)
+		dfi, err := os.Stat(dst)
+		c.Assert(err, qt.IsNil)
+		c.Assert(os.SameFile(sfi, dfi), qt.IsFalse)
+	})
+}
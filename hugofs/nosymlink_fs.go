@@ -14,11 +14,11 @@
 package hugofs
 
 import (
-	"errors"
 	"os"
 	"path/filepath"
 
 	"github.com/gohugoio/hugo/common/loggers"
+	"github.com/pkg/errors"
 
 	"github.com/spf13/afero"
 )
@@ -33,6 +33,7 @@ func NewNoSymlinkFs(fs afero.Fs, logger loggers.Logger, allowFiles bool) afero.F
 // noSymlinkFs is a filesystem that prevents symlinking.
 type noSymlinkFs struct {
 	allowFiles bool // block dirs only
+	strict     bool // fail on symlinks in dir listings instead of skipping them
 	logger     loggers.Logger
 	afero.Fs
 }
@@ -49,6 +50,9 @@ func (f *noSymlinkFile) Readdir(count int) ([]os.FileInfo, error) {
 	for _, x := range fis {
 		filename := filepath.Join(f.Name(), x.Name())
 		if _, err := f.fs.checkSymlinkStatus(filename, x); err != nil {
+			if f.fs.strict {
+				return nil, err
+			}
 			// Log a warning and drop the file from the list
 			logUnsupportedSymlink(filename, f.fs.logger)
 		} else {
@@ -110,7 +114,7 @@ func (fs *noSymlinkFs) checkSymlinkStatus(name string, fi os.FileInfo) (os.FileI
 		if fs.allowFiles && !fi.IsDir() {
 			return fi, nil
 		}
-		return nil, ErrPermissionSymlink
+		return nil, fs.symlinkErr(name)
 	}
 
 	// Also support non-decorated filesystems, e.g. the Os fs.
@@ -124,12 +128,19 @@ func (fs *noSymlinkFs) checkSymlinkStatus(name string, fi os.FileInfo) (os.FileI
 			// Return the original FileInfo to get the expected Name.
 			return fi, nil
 		}
-		return nil, ErrPermissionSymlink
+		return nil, fs.symlinkErr(name)
 	}
 
 	return fi, nil
 }
 
+func (fs *noSymlinkFs) symlinkErr(name string) error {
+	if fs.strict {
+		return errors.Wrapf(ErrPermissionSymlink, "symlink %q not allowed by symlink policy %q", name, SymlinkPolicyError)
+	}
+	return ErrPermissionSymlink
+}
+
 func (fs *noSymlinkFs) Open(name string) (afero.File, error) {
 	if _, _, err := fs.stat(name); err != nil {
 		return nil, err
@@ -16,11 +16,13 @@ package hugofs
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/gohugoio/hugo/common/loggers"
 
 	"github.com/gohugoio/hugo/htesting"
+	"github.com/pkg/errors"
 
 	"github.com/spf13/afero"
 
@@ -144,3 +146,59 @@ func TestNoSymlinkFs(t *testing.T) {
 		}
 	}
 }
+
+func TestSymlinkPolicyFs(t *testing.T) {
+	if skipSymlink() {
+		t.Skip("Skip; os.Symlink needs administrator rights on Windows")
+	}
+	c := qt.New(t)
+	workDir, clean := prepareSymlinks(t)
+	defer clean()
+
+	blogDir := filepath.Join(workDir, "blog")
+	logger := loggers.NewWarningLogger()
+
+	readdirnames := func(fs afero.Fs, dirname string) ([]string, error) {
+		f, err := fs.Open(dirname)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return f.Readdirnames(-1)
+	}
+
+	bfs := NewBaseFileDecorator(Os)
+
+	names, err := readdirnames(NewSymlinkPolicyFs(bfs, SymlinkPolicyFollow, logger), blogDir)
+	c.Assert(err, qt.IsNil)
+	c.Assert(names, qt.HasLen, 4)
+
+	names, err = readdirnames(NewSymlinkPolicyFs(bfs, SymlinkPolicyFollowFiles, logger), blogDir)
+	c.Assert(err, qt.IsNil)
+	c.Assert(names, qt.HasLen, 3)
+
+	names, err = readdirnames(NewSymlinkPolicyFs(bfs, SymlinkPolicyIgnore, logger), blogDir)
+	c.Assert(err, qt.IsNil)
+	c.Assert(names, qt.HasLen, 2)
+
+	_, err = readdirnames(NewSymlinkPolicyFs(bfs, SymlinkPolicyError, logger), blogDir)
+	c.Assert(err, qt.Not(qt.IsNil))
+	c.Assert(errors.Cause(err), qt.Equals, ErrPermissionSymlink)
+	c.Assert(err.Error(), qt.Contains, "symsub")
+	_, err = NewSymlinkPolicyFs(bfs, SymlinkPolicyError, logger).Stat(filepath.Join(blogDir, "symlinkdedfile.txt"))
+	c.Assert(err, qt.Not(qt.IsNil))
+	c.Assert(err, qt.Not(qt.Equals), ErrPermissionSymlink)
+}
+
+func TestParseSymlinkPolicy(t *testing.T) {
+	c := qt.New(t)
+
+	for _, s := range []string{"follow", "followFiles", "FOLLOWFILES", "ignore", "error", ""} {
+		policy, err := ParseSymlinkPolicy(s)
+		c.Assert(err, qt.IsNil)
+		c.Assert(strings.EqualFold(policy, s), qt.IsTrue)
+	}
+
+	_, err := ParseSymlinkPolicy("allow")
+	c.Assert(err, qt.Not(qt.IsNil))
+}
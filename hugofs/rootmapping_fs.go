@@ -45,7 +45,11 @@ func NewRootMappingFs(fs afero.Fs, rms ...RootMapping) (*RootMappingFs, error) {
 			panic(fmt.Sprintf("invalid root mapping; from/to: %s/%s", rm.From, rm.To))
 		}
 
-		fi, err := fs.Stat(rm.To)
+		if rm.Fs == nil {
+			rm.Fs = fs
+		}
+
+		fi, err := rm.Fs.Stat(rm.To)
 		if err != nil {
 			if os.IsNotExist(err) {
 				continue
@@ -120,6 +124,10 @@ type RootMapping struct {
 	Module    string   // The module path/ID.
 	Meta      FileMeta // File metadata (lang etc.)
 
+	// The filesystem to resolve To in. If not set, the filesystem passed to
+	// NewRootMappingFs is used.
+	Fs afero.Fs
+
 	fi   FileMetaInfo
 	path string // The virtual mount point, e.g. "blog".
 
@@ -160,7 +168,10 @@ func (fs *RootMappingFs) Dirs(base string) ([]FileMetaInfo, error) {
 
 	fss := make([]FileMetaInfo, len(roots))
 	for i, r := range roots {
-		bfs := afero.NewBasePathFs(fs.Fs, r.To)
+		var bfs afero.Fs = afero.NewBasePathFs(r.Fs, r.To)
+		if filter := r.Meta.InclusionFilter(); filter != nil {
+			bfs = newFilenameFilterFs(bfs, filter)
+		}
 		bfs = decoratePath(bfs, func(name string) string {
 			p := strings.TrimPrefix(name, r.To)
 			if r.path != "" {
@@ -354,7 +365,8 @@ func (fs *RootMappingFs) collectDirEntries(prefix string) ([]os.FileInfo, error)
 	seen := make(map[string]bool) // Prevent duplicate directories
 	level := strings.Count(prefix, filepathSeparator)
 
-	collectDir := func(rm RootMapping, fi FileMetaInfo) error {
+	// dir is the directory relative to the mount root.
+	collectDir := func(rm RootMapping, dir string, fi FileMetaInfo) error {
 		f, err := fi.Meta().Open()
 		if err != nil {
 			return err
@@ -365,8 +377,13 @@ func (fs *RootMappingFs) collectDirEntries(prefix string) ([]os.FileInfo, error)
 			return err
 		}
 
+		filter := rm.Meta.InclusionFilter()
+
 		for _, fi := range direntries {
 			meta := fi.(FileMetaInfo).Meta()
+			if !filter.Match(filepath.Join(dir, fi.Name()), fi.IsDir()) {
+				continue
+			}
 			mergeFileMeta(rm.Meta, meta)
 			if fi.IsDir() {
 				name := fi.Name()
@@ -391,7 +408,7 @@ func (fs *RootMappingFs) collectDirEntries(prefix string) ([]os.FileInfo, error)
 	// First add any real files/directories.
 	rms := fs.getRoot(prefix)
 	for _, rm := range rms {
-		if err := collectDir(rm, rm.fi); err != nil {
+		if err := collectDir(rm, "", rm.fi); err != nil {
 			return nil, err
 		}
 	}
@@ -455,7 +472,7 @@ func (fs *RootMappingFs) collectDirEntries(prefix string) ([]os.FileInfo, error)
 			if rm.fi.IsDir() {
 				fi, err := rm.fi.Meta().JoinStat(subdir)
 				if err == nil {
-					if err := collectDir(rm, fi); err != nil {
+					if err := collectDir(rm, subdir, fi); err != nil {
 						return nil, err
 					}
 				}
@@ -533,32 +550,36 @@ func (fs *RootMappingFs) doLstat(name string) ([]FileMetaInfo, error) {
 func (fs *RootMappingFs) statRoot(root RootMapping, name string) (FileMetaInfo, bool, error) {
 	filename := root.filename(name)
 
-	fi, b, err := lstatIfPossible(fs.Fs, filename)
+	fi, b, err := lstatIfPossible(root.Fs, filename)
 	if err != nil {
 		return nil, b, err
 	}
 
+	if !root.Meta.InclusionFilter().Match(strings.TrimPrefix(filename, root.To), fi.IsDir()) {
+		return nil, b, &os.PathError{Op: "LStat", Path: name, Err: os.ErrNotExist}
+	}
+
 	var opener func() (afero.File, error)
 	if fi.IsDir() {
 		// Make sure metadata gets applied in Readdir.
-		opener = fs.realDirOpener(filename, root.Meta)
+		opener = fs.realDirOpener(root.Fs, filename, root.Meta)
 	} else {
 		// Opens the real file directly.
 		opener = func() (afero.File, error) {
-			return fs.Fs.Open(filename)
+			return root.Fs.Open(filename)
 		}
 	}
 
-	return decorateFileInfo(fi, fs.Fs, opener, "", "", root.Meta), b, nil
+	return decorateFileInfo(fi, root.Fs, opener, "", "", root.Meta), b, nil
 }
 
 func (fs *RootMappingFs) virtualDirOpener(name string) func() (afero.File, error) {
 	return func() (afero.File, error) { return &rootMappingFile{name: name, fs: fs}, nil }
 }
 
-func (fs *RootMappingFs) realDirOpener(name string, meta FileMeta) func() (afero.File, error) {
+func (fs *RootMappingFs) realDirOpener(base afero.Fs, name string, meta FileMeta) func() (afero.File, error) {
 	return func() (afero.File, error) {
-		f, err := fs.Fs.Open(name)
+		f, err := base.Open(name)
 		if err != nil {
 			return nil, err
 		}
@@ -591,10 +612,21 @@ func (f *rootMappingFile) Readdir(count int) ([]os.FileInfo, error) {
 			return nil, err
 		}
 
-		for i, fi := range fis {
-			fis[i] = decorateFileInfo(fi, f.fs, nil, "", "", f.meta)
+		filter := f.meta.InclusionFilter()
+		sourceRoot := f.meta.SourceRoot()
+		dirname := f.name
+		if filename := f.meta.Filename(); filename != "" {
+			dirname = filename
 		}
-		return fis, nil
+
+		result := fis[:0]
+		for _, fi := range fis {
+			if filter != nil && !filter.Match(strings.TrimPrefix(filepath.Join(dirname, fi.Name()), sourceRoot), fi.IsDir()) {
+				continue
+			}
+			result = append(result, decorateFileInfo(fi, f.fs, nil, "", "", f.meta))
+		}
+		return result, nil
 	}
 	return f.fs.collectDirEntries(f.name)
 }
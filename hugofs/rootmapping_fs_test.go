@@ -16,6 +16,7 @@ package hugofs
 import (
 	"fmt"
 	"io/ioutil"
+	"os"
 	"path/filepath"
 	"sort"
 	"testing"
@@ -24,6 +25,7 @@ import (
 
 	qt "github.com/frankban/quicktest"
 	"github.com/gohugoio/hugo/htesting"
+	"github.com/gohugoio/hugo/hugofs/glob"
 	"github.com/spf13/afero"
 )
 
@@ -339,6 +341,46 @@ func TestRootMappingFsMountOverlap(t *testing.T) {
 	c.Assert(fi.Name(), qt.Equals, "b.txt")
 }
 
+func TestRootMappingFsMountFilter(t *testing.T) {
+	c := qt.New(t)
+	fs := NewBaseFileDecorator(afero.NewMemMapFs())
+
+	for _, filename := range []string{"a.scss", "b.css", "sub/c.scss", "sub/d.txt", "vendor/e.scss"} {
+		c.Assert(afero.WriteFile(fs, filepath.Join("mod/src", filename), []byte("content"), 0755), qt.IsNil)
+	}
+
+	filter, err := glob.NewFilenameFilter([]string{"**.scss"}, []string{"vendor"})
+	c.Assert(err, qt.IsNil)
+
+	bfs := afero.NewBasePathFs(fs, "mod").(*afero.BasePathFs)
+	rm := []RootMapping{
+		{
+			From: "assets/scss",
+			To:   "src",
+			Meta: FileMeta{"inclusionFilter": filter},
+		},
+	}
+
+	rfs, err := NewRootMappingFs(bfs, rm...)
+	c.Assert(err, qt.IsNil)
+
+	collect := func(dirname string) []string {
+		fis, err := afero.ReadDir(rfs, filepath.FromSlash(dirname))
+		c.Assert(err, qt.IsNil)
+		return fileInfosToNames(fis)
+	}
+
+	c.Assert(collect("assets/scss"), qt.DeepEquals, []string{"a.scss", "sub"})
+	c.Assert(collect("assets/scss/sub"), qt.DeepEquals, []string{"c.scss"})
+
+	_, err = rfs.Stat(filepath.FromSlash("assets/scss/b.css"))
+	c.Assert(os.IsNotExist(err), qt.IsTrue)
+	_, err = rfs.Stat(filepath.FromSlash("assets/scss/vendor/e.scss"))
+	c.Assert(os.IsNotExist(err), qt.IsTrue)
+	_, err = rfs.Stat(filepath.FromSlash("assets/scss/sub/c.scss"))
+	c.Assert(err, qt.IsNil)
+}
+
 func TestRootMappingFsOs(t *testing.T) {
 	c := qt.New(t)
 	fs := NewBaseFileDecorator(afero.NewOsFs())
@@ -0,0 +1,454 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/gohugoio/hugo/config"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+const statCacheConfigKey = "statCache"
+
+// StatCacheConfig configures the cache of file metadata in the source
+// filesystem, useful when the project lives on a network mount.
+type StatCacheConfig struct {
+	// Enable the cache.
+	Enable bool
+
+	// How long to keep the metadata for a file or directory. In server mode,
+	// the metadata is also invalidated when a change is detected.
+	// 0 means no expiry.
+	TTL time.Duration
+
+	// If set, only cache the metadata for the files below these directories,
+	// relative to the working directory.
+	Dirs []string
+}
+
+// DecodeStatCacheConfig decodes the statCache section in the site
+// configuration.
+func DecodeStatCacheConfig(cfg config.Provider) (StatCacheConfig, error) {
+	var c StatCacheConfig
+	if !cfg.IsSet(statCacheConfigKey) {
+		return c, nil
+	}
+
+	dec, err := mapstructure.NewDecoder(
+		&mapstructure.DecoderConfig{
+			WeaklyTypedInput: true,
+			Result:           &c,
+			DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
+		},
+	)
+	if err != nil {
+		return c, err
+	}
+
+	if err := dec.Decode(cfg.Get(statCacheConfigKey)); err != nil {
+		return c, errors.Wrap(err, "failed to decode statCache config")
+	}
+
+	if c.TTL < 0 {
+		return c, errors.New("statCache: ttl must be >= 0")
+	}
+
+	workingDir := cfg.GetString("workingDir")
+	for i, dir := range c.Dirs {
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(workingDir, dir)
+		}
+		c.Dirs[i] = filepath.Clean(dir)
+	}
+
+	return c, nil
+}
+
+// Invalidater is implemented by the filesystems caching file metadata.
+type Invalidater interface {
+	// Invalidate removes the cached metadata for the given filenames,
+	// anything below them, and their parent directories.
+	Invalidate(filenames ...string)
+}
+
+var (
+	_ afero.Lstater = (*statCacheFs)(nil)
+	_ Invalidater   = (*statCacheFs)(nil)
+	_ Reseter       = (*statCacheFs)(nil)
+)
+
+// IsOsFs reports whether fs is the OS filesystem, possibly with its file
+// metadata cached.
+func IsOsFs(fs afero.Fs) bool {
+	if sfs, ok := fs.(*statCacheFs); ok {
+		fs = sfs.Fs
+	}
+	_, ok := fs.(*afero.OsFs)
+	return ok
+}
+
+// NewStatCacheFs creates a new filesystem caching the results of Stat,
+// LstatIfPossible and directory listings in fs.
+func NewStatCacheFs(fs afero.Fs, cfg StatCacheConfig) afero.Fs {
+	c := &statCacheFs{Fs: fs, cfg: cfg}
+	c.Reset()
+	return c
+}
+
+type statCacheFs struct {
+	afero.Fs
+	cfg StatCacheConfig
+
+	mu     sync.RWMutex
+	stats  map[string]statCacheEntry
+	lstats map[string]statCacheEntry
+	dirs   map[string]dirCacheEntry
+}
+
+type statCacheEntry struct {
+	fi      os.FileInfo
+	lstat   bool
+	err     error
+	expires time.Time
+}
+
+type dirCacheEntry struct {
+	fis     []os.FileInfo
+	expires time.Time
+}
+
+func (fs *statCacheFs) Reset() {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	fs.stats = make(map[string]statCacheEntry)
+	fs.lstats = make(map[string]statCacheEntry)
+	fs.dirs = make(map[string]dirCacheEntry)
+}
+
+func (fs *statCacheFs) Invalidate(filenames ...string) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	for _, filename := range filenames {
+		filename = filepath.Clean(filename)
+		prefix := strings.TrimSuffix(filename, string(filepath.Separator)) + string(filepath.Separator)
+		below := func(k string) bool {
+			return k == filename || strings.HasPrefix(k, prefix)
+		}
+
+		for k := range fs.stats {
+			if below(k) {
+				delete(fs.stats, k)
+			}
+		}
+		for k := range fs.lstats {
+			if below(k) {
+				delete(fs.lstats, k)
+			}
+		}
+		for k := range fs.dirs {
+			if below(k) {
+				delete(fs.dirs, k)
+			}
+		}
+
+		parent := filepath.Dir(filename)
+		delete(fs.stats, parent)
+		delete(fs.lstats, parent)
+		delete(fs.dirs, parent)
+	}
+}
+
+func (fs *statCacheFs) Stat(name string) (os.FileInfo, error) {
+	name = filepath.Clean(name)
+	if e, found := fs.getStat(fs.stats, name); found {
+		return e.fi, e.err
+	}
+	fi, err := fs.Fs.Stat(name)
+	fs.setStat(fs.stats, name, statCacheEntry{fi: fi, err: err})
+	return fi, err
+}
+
+func (fs *statCacheFs) LstatIfPossible(name string) (os.FileInfo, bool, error) {
+	name = filepath.Clean(name)
+	if e, found := fs.getStat(fs.lstats, name); found {
+		return e.fi, e.lstat, e.err
+	}
+
+	var (
+		fi    os.FileInfo
+		lstat bool
+		err   error
+	)
+	if lfs, ok := fs.Fs.(afero.Lstater); ok {
+		fi, lstat, err = lfs.LstatIfPossible(name)
+	} else {
+		fi, err = fs.Fs.Stat(name)
+	}
+	fs.setStat(fs.lstats, name, statCacheEntry{fi: fi, lstat: lstat, err: err})
+	return fi, lstat, err
+}
+
+func (fs *statCacheFs) Open(name string) (afero.File, error) {
+	name = filepath.Clean(name)
+
+	if fis, found := fs.getDir(name); found {
+		if fi, err := fs.Stat(name); err == nil && fi.IsDir() {
+			// No need to open the directory.
+			return &statCacheDir{name: name, fi: fi, fis: fis}, nil
+		}
+	}
+
+	f, err := fs.Fs.Open(name)
+	if err != nil {
+		if os.IsNotExist(err) {
+			fs.setStat(fs.stats, name, statCacheEntry{err: err})
+		}
+		return nil, err
+	}
+
+	return &statCacheFile{File: f, fs: fs, name: name}, nil
+}
+
+func (fs *statCacheFs) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	if !isWrite(flag) && flag&os.O_CREATE == 0 {
+		return fs.Open(name)
+	}
+	fs.Invalidate(name)
+	return fs.Fs.OpenFile(name, flag, perm)
+}
+
+func (fs *statCacheFs) Create(name string) (afero.File, error) {
+	fs.Invalidate(name)
+	return fs.Fs.Create(name)
+}
+
+func (fs *statCacheFs) Mkdir(name string, perm os.FileMode) error {
+	defer fs.Invalidate(name)
+	return fs.Fs.Mkdir(name, perm)
+}
+
+func (fs *statCacheFs) MkdirAll(path string, perm os.FileMode) error {
+	defer fs.Invalidate(path)
+	return fs.Fs.MkdirAll(path, perm)
+}
+
+func (fs *statCacheFs) Remove(name string) error {
+	defer fs.Invalidate(name)
+	return fs.Fs.Remove(name)
+}
+
+func (fs *statCacheFs) RemoveAll(path string) error {
+	defer fs.Invalidate(path)
+	return fs.Fs.RemoveAll(path)
+}
+
+func (fs *statCacheFs) Rename(oldname, newname string) error {
+	defer fs.Invalidate(oldname, newname)
+	return fs.Fs.Rename(oldname, newname)
+}
+
+func (fs *statCacheFs) Chmod(name string, mode os.FileMode) error {
+	defer fs.Invalidate(name)
+	return fs.Fs.Chmod(name, mode)
+}
+
+func (fs *statCacheFs) Chtimes(name string, atime time.Time, mtime time.Time) error {
+	defer fs.Invalidate(name)
+	return fs.Fs.Chtimes(name, atime, mtime)
+}
+
+func (fs *statCacheFs) Name() string {
+	return "statCacheFs"
+}
+
+func (fs *statCacheFs) cacheable(name string) bool {
+	if len(fs.cfg.Dirs) == 0 {
+		return true
+	}
+	for _, dir := range fs.cfg.Dirs {
+		if name == dir || strings.HasPrefix(name, dir+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (fs *statCacheFs) expires() time.Time {
+	if fs.cfg.TTL == 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(fs.cfg.TTL)
+}
+
+func expired(t time.Time) bool {
+	return !t.IsZero() && time.Now().After(t)
+}
+
+func (fs *statCacheFs) getStat(m map[string]statCacheEntry, name string) (statCacheEntry, bool) {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+	e, found := m[name]
+	if !found || expired(e.expires) {
+		return e, false
+	}
+	return e, true
+}
+
+func (fs *statCacheFs) setStat(m map[string]statCacheEntry, name string, e statCacheEntry) {
+	if e.err != nil && !os.IsNotExist(e.err) {
+		// Only cache the files that do not exist.
+		return
+	}
+	if !fs.cacheable(name) {
+		return
+	}
+	e.expires = fs.expires()
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	m[name] = e
+}
+
+func (fs *statCacheFs) getDir(name string) ([]os.FileInfo, bool) {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+	e, found := fs.dirs[name]
+	if !found || expired(e.expires) {
+		return nil, false
+	}
+	return copyFileInfos(e.fis), true
+}
+
+func (fs *statCacheFs) setDir(name string, fis []os.FileInfo) {
+	if !fs.cacheable(name) {
+		return
+	}
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	fs.dirs[name] = dirCacheEntry{fis: copyFileInfos(fis), expires: fs.expires()}
+}
+
+// copyFileInfos copies fis, as some of the other filesystems filter the
+// listings in place.
+func copyFileInfos(fis []os.FileInfo) []os.FileInfo {
+	return append([]os.FileInfo(nil), fis...)
+}
+
+// statCacheFile is a file opened in a statCacheFs, with the directory
+// listings cached.
+type statCacheFile struct {
+	afero.File
+	fs   *statCacheFs
+	name string
+	read bool
+}
+
+func (f *statCacheFile) Readdir(count int) ([]os.FileInfo, error) {
+	if count > 0 {
+		return f.File.Readdir(count)
+	}
+	if f.read {
+		return nil, nil
+	}
+	f.read = true
+
+	if fis, found := f.fs.getDir(f.name); found {
+		return fis, nil
+	}
+	fis, err := f.File.Readdir(count)
+	if err == nil {
+		f.fs.setDir(f.name, fis)
+	}
+	return fis, err
+}
+
+func (f *statCacheFile) Readdirnames(count int) ([]string, error) {
+	if count > 0 {
+		return f.File.Readdirnames(count)
+	}
+	fis, err := f.Readdir(count)
+	if err != nil {
+		return nil, err
+	}
+	return fileInfosToNames(fis), nil
+}
+
+func (f *statCacheFile) Stat() (os.FileInfo, error) {
+	return f.fs.Stat(f.name)
+}
+
+// statCacheDir is a directory served from the cache.
+type statCacheDir struct {
+	name string
+	fi   os.FileInfo
+	fis  []os.FileInfo
+	read bool
+}
+
+func (d *statCacheDir) Readdir(count int) ([]os.FileInfo, error) {
+	if count > 0 {
+		if len(d.fis) == 0 {
+			return nil, io.EOF
+		}
+		if count > len(d.fis) {
+			count = len(d.fis)
+		}
+		fis := d.fis[:count]
+		d.fis = d.fis[count:]
+		return fis, nil
+	}
+	if d.read {
+		return nil, nil
+	}
+	d.read = true
+	fis := d.fis
+	d.fis = nil
+	return fis, nil
+}
+
+func (d *statCacheDir) Readdirnames(count int) ([]string, error) {
+	fis, err := d.Readdir(count)
+	if err != nil {
+		return nil, err
+	}
+	return fileInfosToNames(fis), nil
+}
+
+func (d *statCacheDir) Stat() (os.FileInfo, error) { return d.fi, nil }
+func (d *statCacheDir) Name() string               { return d.name }
+func (d *statCacheDir) Close() error               { return nil }
+func (d *statCacheDir) Sync() error                { return nil }
+
+func (d *statCacheDir) Read(p []byte) (int, error)              { return 0, d.isDir() }
+func (d *statCacheDir) ReadAt(p []byte, off int64) (int, error) { return 0, d.isDir() }
+func (d *statCacheDir) Seek(offset int64, whence int) (int64, error) {
+	return 0, d.isDir()
+}
+func (d *statCacheDir) Write(p []byte) (int, error)              { return 0, d.isDir() }
+func (d *statCacheDir) WriteAt(p []byte, off int64) (int, error) { return 0, d.isDir() }
+func (d *statCacheDir) WriteString(s string) (int, error)        { return 0, d.isDir() }
+func (d *statCacheDir) Truncate(size int64) error                { return d.isDir() }
+
+func (d *statCacheDir) isDir() error {
+	return &os.PathError{Op: "read", Path: d.name, Err: syscall.EISDIR}
+}
@@ -0,0 +1,135 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/config"
+	"github.com/spf13/afero"
+)
+
+type syscallCountingFs struct {
+	afero.Fs
+	stats int32
+	opens int32
+}
+
+func (fs *syscallCountingFs) Stat(name string) (os.FileInfo, error) {
+	atomic.AddInt32(&fs.stats, 1)
+	return fs.Fs.Stat(name)
+}
+
+func (fs *syscallCountingFs) Open(name string) (afero.File, error) {
+	atomic.AddInt32(&fs.opens, 1)
+	return fs.Fs.Open(name)
+}
+
+func TestDecodeStatCacheConfig(t *testing.T) {
+	c := qt.New(t)
+
+	cfg, err := config.FromConfigString(`
+workingDir = "/my/project"
+[statCache]
+enable = true
+ttl = "10s"
+dirs = ["content", "/mnt/nfs"]
+`, "toml")
+	c.Assert(err, qt.IsNil)
+
+	sc, err := DecodeStatCacheConfig(cfg)
+	c.Assert(err, qt.IsNil)
+	c.Assert(sc.Enable, qt.IsTrue)
+	c.Assert(sc.TTL, qt.Equals, 10*time.Second)
+	c.Assert(sc.Dirs, qt.DeepEquals, []string{filepath.FromSlash("/my/project/content"), filepath.FromSlash("/mnt/nfs")})
+
+	sc, err = DecodeStatCacheConfig(config.New())
+	c.Assert(err, qt.IsNil)
+	c.Assert(sc.Enable, qt.IsFalse)
+}
+
+func TestStatCacheFs(t *testing.T) {
+	c := qt.New(t)
+
+	base := &syscallCountingFs{Fs: afero.NewMemMapFs()}
+	c.Assert(afero.WriteFile(base, filepath.FromSlash("/c/a.md"), []byte("a"), 0777), qt.IsNil)
+	c.Assert(afero.WriteFile(base, filepath.FromSlash("/c/b.md"), []byte("b"), 0777), qt.IsNil)
+
+	fs := NewStatCacheFs(base, StatCacheConfig{Enable: true})
+
+	readDir := func() []string {
+		f, err := fs.Open(filepath.FromSlash("/c"))
+		c.Assert(err, qt.IsNil)
+		defer f.Close()
+		names, err := f.Readdirnames(-1)
+		c.Assert(err, qt.IsNil)
+		return names
+	}
+
+	for i := 0; i < 3; i++ {
+		_, err := fs.Stat(filepath.FromSlash("/c/a.md"))
+		c.Assert(err, qt.IsNil)
+		_, err = fs.Stat(filepath.FromSlash("/c/nope.md"))
+		c.Assert(os.IsNotExist(err), qt.IsTrue)
+		c.Assert(readDir(), qt.DeepEquals, []string{"a.md", "b.md"})
+	}
+
+	c.Assert(atomic.LoadInt32(&base.stats), qt.Equals, int32(3)) // a.md, nope.md and /c
+	c.Assert(atomic.LoadInt32(&base.opens), qt.Equals, int32(1))
+
+	// Reading the files goes to the underlying filesystem.
+	b, err := afero.ReadFile(fs, filepath.FromSlash("/c/a.md"))
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, "a")
+
+	c.Run("Write", func(c *qt.C) {
+		c.Assert(afero.WriteFile(fs, filepath.FromSlash("/c/nope.md"), []byte("c"), 0777), qt.IsNil)
+		_, err := fs.Stat(filepath.FromSlash("/c/nope.md"))
+		c.Assert(err, qt.IsNil)
+		c.Assert(readDir(), qt.DeepEquals, []string{"a.md", "b.md", "nope.md"})
+	})
+
+	c.Run("Invalidate", func(c *qt.C) {
+		c.Assert(base.Remove(filepath.FromSlash("/c/b.md")), qt.IsNil)
+		c.Assert(readDir(), qt.HasLen, 3)
+		fs.(Invalidater).Invalidate(filepath.FromSlash("/c/b.md"))
+		c.Assert(readDir(), qt.DeepEquals, []string{"a.md", "nope.md"})
+		_, err := fs.Stat(filepath.FromSlash("/c/b.md"))
+		c.Assert(os.IsNotExist(err), qt.IsTrue)
+	})
+
+	c.Run("TTL", func(c *qt.C) {
+		fs := NewStatCacheFs(base, StatCacheConfig{Enable: true, TTL: time.Millisecond})
+		_, err := fs.Stat(filepath.FromSlash("/c/a.md"))
+		c.Assert(err, qt.IsNil)
+		count := atomic.LoadInt32(&base.stats)
+		time.Sleep(5 * time.Millisecond)
+		_, err = fs.Stat(filepath.FromSlash("/c/a.md"))
+		c.Assert(err, qt.IsNil)
+		c.Assert(atomic.LoadInt32(&base.stats), qt.Equals, count+1)
+	})
+
+	c.Run("Dirs", func(c *qt.C) {
+		fs := NewStatCacheFs(base, StatCacheConfig{Enable: true, Dirs: []string{filepath.FromSlash("/d")}})
+		count := atomic.LoadInt32(&base.stats)
+		fs.Stat(filepath.FromSlash("/c/a.md"))
+		fs.Stat(filepath.FromSlash("/c/a.md"))
+		c.Assert(atomic.LoadInt32(&base.stats), qt.Equals, count+2)
+	})
+}
@@ -0,0 +1,86 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"strings"
+
+	"github.com/gohugoio/hugo/common/loggers"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+// The symlink policies that can be configured for a mount.
+const (
+	// Follow all symlinks.
+	SymlinkPolicyFollow = "follow"
+
+	// Follow symlinked files, skip symlinked directories with a warning.
+	SymlinkPolicyFollowFiles = "followFiles"
+
+	// Skip all symlinks with a warning.
+	SymlinkPolicyIgnore = "ignore"
+
+	// Fail the build if a symlink is found.
+	SymlinkPolicyError = "error"
+)
+
+var symlinkPolicies = []string{
+	SymlinkPolicyFollow,
+	SymlinkPolicyFollowFiles,
+	SymlinkPolicyIgnore,
+	SymlinkPolicyError,
+}
+
+// ParseSymlinkPolicy returns the symlink policy matching s (case insensitive).
+// An empty s is returned as is, which means the default policy.
+func ParseSymlinkPolicy(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	for _, policy := range symlinkPolicies {
+		if strings.EqualFold(s, policy) {
+			return policy, nil
+		}
+	}
+	return "", errors.Errorf("invalid symlink policy %q; must be one of %v", s, symlinkPolicies)
+}
+
+// DefaultSymlinkPolicy returns the symlink policy used for mounts that do not
+// set one. Symlinks are followed in the project, but symlinked directories
+// are not supported in static. Symlinks are not followed in modules.
+func DefaultSymlinkPolicy(isProject, isStatic bool) string {
+	if !isProject {
+		return SymlinkPolicyIgnore
+	}
+	if isStatic {
+		return SymlinkPolicyFollowFiles
+	}
+	return SymlinkPolicyFollow
+}
+
+// NewSymlinkPolicyFs creates a new filesystem that handles symlinks
+// according to the given policy.
+func NewSymlinkPolicyFs(fs afero.Fs, policy string, logger loggers.Logger) afero.Fs {
+	switch policy {
+	case SymlinkPolicyFollow:
+		return fs
+	case SymlinkPolicyFollowFiles:
+		return NewNoSymlinkFs(fs, logger, true)
+	case SymlinkPolicyError:
+		return &noSymlinkFs{Fs: fs, logger: logger, strict: true}
+	default:
+		return NewNoSymlinkFs(fs, logger, false)
+	}
+}
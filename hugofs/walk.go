@@ -274,6 +274,9 @@ func (w *Walkway) walk(path string, info FileMetaInfo, dirEntries []FileMetaInfo
 			// Prevent infinite recursion
 			// Possible cyclic reference
 			meta[metaKeySkipDir] = true
+			if meta.IsSymlink() && strings.HasPrefix(filename+filepathSeparator, meta.Filename()+filepathSeparator) {
+				w.logger.Warnf("Symlink cycle detected in %q: it points to its ancestor %q, skipping.", meta.OriginalFilename(), meta.Filename())
+			}
 		}
 	}
 
@@ -23,6 +23,7 @@ import (
 
 	"github.com/pkg/errors"
 
+	"github.com/gohugoio/hugo/common/loggers"
 	"github.com/gohugoio/hugo/htesting"
 
 	"github.com/spf13/afero"
@@ -143,6 +144,19 @@ func TestWalkSymbolicLink(t *testing.T) {
 		// Note: the docsreal folder is considered cyclic when walking from the root, but this works.
 		c.Assert(names, qt.DeepEquals, []string{"b.txt", "docsreal/sub/a.txt"})
 	})
+
+	t.Run("Cycle warning", func(t *testing.T) {
+		c := qt.New(t)
+
+		logger := loggers.NewWarningLogger()
+		w := NewWalkway(WalkwayConfig{Fs: fs, Root: blogDir, Logger: logger, WalkFn: func(path string, info FileMetaInfo, err error) error {
+			return err
+		}})
+		c.Assert(w.Walk(), qt.IsNil)
+
+		// blog/real/cyclic points to its parent; blog/symlinked is just a duplicate.
+		c.Assert(logger.LogCounters().WarnCounter.Count(), qt.Equals, uint64(1))
+	})
 }
 
 func collectFilenames(fs afero.Fs, base, root string) ([]string, error) {
@@ -0,0 +1,72 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"bytes"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/common/loggers"
+	jww "github.com/spf13/jwalterweatherman"
+)
+
+func TestA11y(t *testing.T) {
+	t.Parallel()
+
+	for _, failOn := range []string{"", "error"} {
+		failOn := failOn
+		t.Run(failOn, func(t *testing.T) {
+			b := newTestSitesBuilder(t).WithConfigFile("toml", `
+baseURL = "https://example.org"
+disableKinds = ["taxonomy", "term"]
+[a11y]
+enable = true
+failOn = "`+failOn+`"
+`)
+
+			b.WithContent("p1.md", `---
+title: P1
+aliases: ["/old/"]
+---
+`, "p2.md", `---
+title: P2
+---
+## Heading
+`)
+
+			b.WithTemplates(
+				"_default/single.html", `<html lang="en"><body><h1>{{ .Title }}</h1>{{ .Content }}{{ if eq .Title "P1" }}<img src="cat.jpg">{{ end }}</body></html>`,
+				"_default/list.html", `<html><body><h2>{{ .Title }}</h2></body></html>`,
+			)
+
+			var buf bytes.Buffer
+			b.WithLogger(loggers.NewBasicLoggerForWriter(jww.LevelWarn, &buf))
+
+			err := b.BuildE(BuildCfg{})
+			if failOn == "" {
+				b.Assert(err, qt.IsNil)
+			} else {
+				b.Assert(err, qt.ErrorMatches, `.*a11y: found 2 issue\(s\) with severity error or higher`)
+			}
+
+			b.Assert(buf.String(), qt.Contains, `a11y: p1/index.html:1: error: image "cat.jpg" has no alt attribute (image-alt)`)
+			b.Assert(buf.String(), qt.Contains, `a11y: index.html:1: error: html element has no lang attribute (html-lang)`)
+			b.Assert(buf.String(), qt.Not(qt.Contains), "p2/index.html")
+			b.Assert(buf.String(), qt.Not(qt.Contains), "old/index.html")
+
+			b.AssertFileContent("hugo_a11y.json", `"path": "index.html"`, `"rule": "image-alt"`)
+		})
+	}
+}
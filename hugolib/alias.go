@@ -15,15 +15,21 @@ package hugolib
 
 import (
 	"bytes"
-	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"path"
 	"path/filepath"
 	"runtime"
 	"strings"
 
 	"github.com/gohugoio/hugo/common/loggers"
+	"github.com/gohugoio/hugo/common/maps"
+	"github.com/gohugoio/hugo/common/paths"
+	"github.com/gohugoio/hugo/config"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+	"github.com/spf13/cast"
 
 	"github.com/gohugoio/hugo/output"
 	"github.com/gohugoio/hugo/publisher"
@@ -31,6 +37,143 @@ import (
 	"github.com/gohugoio/hugo/tpl"
 )
 
+// The status codes supported for aliases.
+var aliasStatusCodes = map[int]bool{
+	http.StatusMovedPermanently: true,
+	http.StatusFound:            true,
+	http.StatusGone:             true,
+}
+
+// aliasConfig configures the alias stubs and redirects.
+type aliasConfig struct {
+	// The status code for aliases without one set in front matter,
+	// 301, 302 or 410.
+	Status int
+
+	// Whether to add a canonical link to the target to the stubs of
+	// permanent redirects.
+	Canonical bool
+
+	// The redirects file to write the aliases to with their status codes,
+	// "netlify" for _redirects or "apache" for .htaccess.
+	Redirects string
+}
+
+var defaultAliasConfig = aliasConfig{
+	Status:    http.StatusMovedPermanently,
+	Canonical: true,
+}
+
+func decodeAliasConfig(cfg config.Provider) (aliasConfig, error) {
+	c := defaultAliasConfig
+
+	if !cfg.IsSet("aliases") {
+		return c, nil
+	}
+
+	if err := mapstructure.WeakDecode(cfg.GetStringMap("aliases"), &c); err != nil {
+		return c, errors.Wrap(err, "failed to decode aliases config")
+	}
+
+	if !aliasStatusCodes[c.Status] {
+		return c, errors.Errorf("aliases: unsupported status code %d, must be 301, 302 or 410", c.Status)
+	}
+
+	c.Redirects = strings.ToLower(c.Redirects)
+	if _, found := aliasRedirectsFiles[c.Redirects]; !found && c.Redirects != "" {
+		return c, errors.Errorf("aliases: unsupported redirects %q, must be netlify or apache", c.Redirects)
+	}
+
+	return c, nil
+}
+
+// decodeAliases decodes the aliases in front matter, a list of paths or of
+// maps with a path and a status code.
+func decodeAliases(v interface{}) ([]string, map[string]int, error) {
+	var (
+		aliases []string
+		status  map[string]int
+	)
+
+	items := cast.ToSlice(v)
+	if items == nil {
+		for _, alias := range cast.ToStringSlice(v) {
+			items = append(items, alias)
+		}
+	}
+
+	for _, vv := range items {
+		var (
+			alias string
+			code  int
+		)
+
+		switch vvv := vv.(type) {
+		case string:
+			alias = vvv
+		default:
+			m, ok := maps.ToParamsAndPrepare(vvv)
+			if !ok {
+				return nil, nil, fmt.Errorf("invalid alias %v", vvv)
+			}
+			alias = cast.ToString(m["path"])
+			code = cast.ToInt(m["status"])
+		}
+
+		if strings.HasPrefix(alias, "http://") || strings.HasPrefix(alias, "https://") {
+			return nil, nil, fmt.Errorf("http* aliases not supported: %q", alias)
+		}
+		alias = filepath.ToSlash(alias)
+
+		if code != 0 {
+			if !aliasStatusCodes[code] {
+				return nil, nil, fmt.Errorf("unsupported status code %d for alias %q, must be 301, 302 or 410", code, alias)
+			}
+			if status == nil {
+				status = make(map[string]int)
+			}
+			status[alias] = code
+		}
+
+		aliases = append(aliases, alias)
+	}
+
+	return aliases, status, nil
+}
+
+// The redirects files supported, keyed by host.
+var aliasRedirectsFiles = map[string]string{
+	"netlify": "_redirects",
+	"apache":  ".htaccess",
+}
+
+// aliasRedirect is an alias entry in a redirects file.
+type aliasRedirect struct {
+	From   string
+	To     string
+	Status int
+}
+
+func (r aliasRedirect) format(host string) string {
+	switch host {
+	case "apache":
+		if r.Status == http.StatusGone {
+			return fmt.Sprintf("Redirect gone %s", r.From)
+		}
+		return fmt.Sprintf("Redirect %d %s %s", r.Status, r.From, r.To)
+	default:
+		if r.Status == http.StatusGone {
+			// Serve the stub with the status, it exists so it needs to be forced.
+			to := r.From
+			if strings.HasSuffix(to, "/") {
+				to += "index.html"
+			}
+			return fmt.Sprintf("%s %s %d!", r.From, to, r.Status)
+		}
+		return fmt.Sprintf("%s %s %d", r.From, r.To, r.Status)
+	}
+}
+
 type aliasHandler struct {
 	t         tpl.TemplateHandler
 	log       loggers.Logger
@@ -44,9 +187,19 @@ func newAliasHandler(t tpl.TemplateHandler, l loggers.Logger, allowRoot bool) al
 type aliasPage struct {
 	Permalink string
 	page.Page
+
+	// The status code, 301, 302 or 410.
+	Status int
+
+	// Whether to add a canonical link to the target.
+	Canonical bool
+
+	// Whether this is the site root redirecting to the main language and
+	// language negotiation is enabled, see languageRedirect.
+	LanguageRedirect bool
 }
 
-func (a aliasHandler) renderAlias(permalink string, p page.Page) (io.Reader, error) {
+func (a aliasHandler) renderAlias(data aliasPage) (io.Reader, error) {
 	var templ tpl.Template
 	var found bool
 
@@ -59,11 +212,6 @@ func (a aliasHandler) renderAlias(permalink string, p page.Page) (io.Reader, err
 		}
 	}
 
-	data := aliasPage{
-		permalink,
-		p,
-	}
-
 	buffer := new(bytes.Buffer)
 	err := a.t.Execute(templ, buffer, data)
 	if err != nil {
@@ -73,10 +221,10 @@ func (a aliasHandler) renderAlias(permalink string, p page.Page) (io.Reader, err
 }
 
 func (s *Site) writeDestAlias(path, permalink string, outputFormat output.Format, p page.Page) (err error) {
-	return s.publishDestAlias(false, path, permalink, outputFormat, p)
+	return s.publishDestAlias(false, path, permalink, http.StatusMovedPermanently, outputFormat, p)
 }
 
-func (s *Site) publishDestAlias(allowRoot bool, path, permalink string, outputFormat output.Format, p page.Page) (err error) {
+func (s *Site) publishDestAlias(allowRoot bool, path, permalink string, status int, outputFormat output.Format, p page.Page) (err error) {
 	handler := newAliasHandler(s.Tmpl(), s.Log, allowRoot)
 
 	s.Log.Debugln("creating alias:", path, "redirecting to", permalink)
@@ -86,11 +234,30 @@ func (s *Site) publishDestAlias(allowRoot bool, path, permalink string, outputFo
 		return err
 	}
 
-	aliasContent, err := handler.renderAlias(permalink, p)
+	aliasContent, err := handler.renderAlias(aliasPage{
+		Permalink:        permalink,
+		Page:             p,
+		Status:           status,
+		Canonical:        s.siteCfg.aliases.Canonical && status == http.StatusMovedPermanently,
+		LanguageRedirect: allowRoot && path == "/" && s.siteCfg.languageRedirect.Enable,
+	})
 	if err != nil {
 		return err
 	}
 
+	if p != nil && s.siteCfg.aliases.Redirects != "" {
+		from := "/" + strings.TrimSuffix(filepath.ToSlash(targetPath), "index.html")
+		if s.h.multihost {
+			// Each language is served from its own host.
+			from = strings.TrimPrefix(from, "/"+s.language.Lang)
+		}
+		s.aliasRedirects = append(s.aliasRedirects, aliasRedirect{
+			From:   paths.AddContextRoot(s.PathSpec.BaseURL.String(), from),
+			To:     s.PathSpec.RelURL(permalink, false),
+			Status: status,
+		})
+	}
+
 	pd := publisher.Descriptor{
 		Src:          aliasContent,
 		TargetPath:   targetPath,
@@ -171,3 +338,67 @@ func (a aliasHandler) targetPathAlias(src string) (string, error) {
 
 	return filepath.FromSlash(alias), nil
 }
+
+// publishAliasRedirects writes the aliases in sites to the redirects file
+// configured in aliases.redirects, in the root of the publish dir. Any
+// language negotiation rules, see languageRedirect, come first.
+func (h *HugoSites) publishAliasRedirects(sites ...*Site) error {
+	s := sites[0]
+	files := make(map[string]*bytes.Buffer)
+
+	if lr := s.siteCfg.languageRedirect; lr.Enable && lr.Redirects != "" {
+		for _, rule := range h.languageRedirectRules(lr.Redirects) {
+			if files[lr.Redirects] == nil {
+				files[lr.Redirects] = new(bytes.Buffer)
+			}
+			files[lr.Redirects].WriteString(rule + "\n")
+		}
+	}
+
+	if host := s.siteCfg.aliases.Redirects; host != "" {
+		seen := make(map[string]bool)
+		for _, s := range sites {
+			for _, r := range s.aliasRedirects {
+				if seen[r.From] {
+					continue
+				}
+				seen[r.From] = true
+				if files[host] == nil {
+					files[host] = new(bytes.Buffer)
+				}
+				files[host].WriteString(r.format(host) + "\n")
+			}
+		}
+	}
+
+	for host, b := range files {
+		filename := aliasRedirectsFiles[host]
+
+		if _, err := s.BaseFs.StaticFs(s.language.Lang).Stat(filename); err == nil {
+			s.Log.Warnf("aliases: %s exists in static, skip writing the alias redirects", filename)
+			continue
+		}
+
+		if h.multihost {
+			filename = filepath.Join(s.language.Lang, filename)
+		}
+
+		if err := s.publish(&s.PathSpec.ProcessingStats.Files, filename, b); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (h *HugoSites) renderCrossSitesAliasRedirects() error {
+	if h.multihost {
+		for _, s := range h.Sites {
+			if err := h.publishAliasRedirects(s); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return h.publishAliasRedirects(h.Sites...)
+}
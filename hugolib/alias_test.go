@@ -19,6 +19,7 @@ import (
 	"testing"
 
 	"github.com/gohugoio/hugo/common/loggers"
+	"github.com/gohugoio/hugo/config"
 
 	qt "github.com/frankban/quicktest"
 )
@@ -116,6 +117,58 @@ func TestAliasTemplate(t *testing.T) {
 	b.AssertFileContent("public/foo/bar/index.html", "ALIASTEMPLATE")
 }
 
+func TestAliasStatus(t *testing.T) {
+	t.Parallel()
+
+	for _, host := range []string{"netlify", "apache"} {
+		host := host
+		t.Run(host, func(t *testing.T) {
+			b := newTestSitesBuilder(t).WithConfigFile("toml", `
+baseURL = "https://example.org/"
+
+[aliases]
+redirects = "`+host+`"
+`)
+
+			b.WithContent("page.md", `---
+title: "Page"
+aliases:
+  - /old/
+  - path: /temp/
+    status: 302
+  - path: /gone/
+    status: 410
+---
+`)
+
+			b.Build(BuildCfg{})
+
+			b.AssertFileContent("public/old/index.html", `<link rel="canonical" href="https://example.org/page/"/>`, `url=https://example.org/page/`)
+			b.AssertFileContent("public/temp/index.html", `url=https://example.org/page/`)
+			b.Assert(b.FileContent("public/temp/index.html"), qt.Not(qt.Contains), "canonical")
+			b.AssertFileContent("public/gone/index.html", "<title>410 Gone</title>")
+			b.Assert(b.FileContent("public/gone/index.html"), qt.Not(qt.Contains), "refresh")
+
+			switch host {
+			case "netlify":
+				b.AssertFileContent("public/_redirects", "/old/ /page/ 301", "/temp/ /page/ 302", "/gone/ /gone/index.html 410!")
+			case "apache":
+				b.AssertFileContent("public/.htaccess", "Redirect 301 /old/ /page/", "Redirect 302 /temp/ /page/", "Redirect gone /gone/")
+			}
+		})
+	}
+
+	c := qt.New(t)
+
+	_, _, err := decodeAliases([]interface{}{map[string]interface{}{"path": "/a/", "status": 307}})
+	c.Assert(err, qt.ErrorMatches, `unsupported status code 307 for alias "/a/".*`)
+
+	cfg := config.New()
+	cfg.Set("aliases", map[string]interface{}{"status": 307})
+	_, err = decodeAliasConfig(cfg)
+	c.Assert(err, qt.ErrorMatches, `aliases: unsupported status code 307.*`)
+}
+
 func TestTargetPathHTMLRedirectAlias(t *testing.T) {
 	h := newAliasHandler(nil, loggers.NewErrorLogger(), false)
 
@@ -0,0 +1,274 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gohugoio/hugo/config"
+	"github.com/gohugoio/hugo/parser/pageparser"
+	"github.com/gohugoio/hugo/resources/page"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"github.com/spf13/cast"
+)
+
+// The template actions in archetypes are replaced with this when looking for
+// the front matter fields defined.
+const archetypeTemplatePlaceholder = "__hugo_archetype_template__"
+
+var archetypeTemplateRe = regexp.MustCompile(`{{.*?}}`)
+
+// archetypeContract holds the front matter fields defined in an archetype.
+type archetypeContract struct {
+	filename string
+
+	// The lower case field names, sorted.
+	fields []string
+
+	// The fields with static values, i.e. not set with template actions.
+	defaults map[string]interface{}
+}
+
+// archetypeContracts loads the archetype contracts for the pages in a site.
+// The archetype for a page is looked up the same way as in hugo new, using
+// the page's type as the kind.
+type archetypeContracts struct {
+	s *Site
+
+	// Whether to backfill the static defaults into the front matter.
+	backfill bool
+
+	mu    sync.Mutex
+	cache map[string]*archetypeContract
+}
+
+func newArchetypeContracts(s *Site, cfg config.Provider) *archetypeContracts {
+	backfill := cfg.GetBool("archetypeDefaults")
+	if !backfill && !cfg.GetBool("lintArchetypes") {
+		return nil
+	}
+	return &archetypeContracts{s: s, backfill: backfill, cache: make(map[string]*archetypeContract)}
+}
+
+// get returns the contract for the given content type and file extension,
+// nil if there is no archetype.
+func (a *archetypeContracts) get(typ, ext string) (*archetypeContract, error) {
+	key := typ + ext
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if c, found := a.cache[key]; found {
+		return c, nil
+	}
+
+	c, err := a.load(typ, ext)
+	if err != nil {
+		return nil, err
+	}
+	a.cache[key] = c
+
+	return c, nil
+}
+
+func (a *archetypeContracts) load(typ, ext string) (*archetypeContract, error) {
+	fs := a.s.BaseFs.Archetypes.Fs
+
+	var pathsToCheck []string
+	if typ != "" {
+		pathsToCheck = append(pathsToCheck, typ+ext, path.Join(typ, "index"+ext))
+	}
+	pathsToCheck = append(pathsToCheck, "default"+ext, path.Join("default", "index"+ext))
+
+	for _, filename := range pathsToCheck {
+		b, err := afero.ReadFile(fs, filename)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		c, err := parseArchetypeContract(b)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse archetype %q", filename)
+		}
+		c.filename = filename
+		return c, nil
+	}
+
+	return nil, nil
+}
+
+// apply records the fields defined in the page's archetype missing in its
+// front matter and, if enabled, sets the missing fields with static values.
+func (a *archetypeContracts) apply(pm *pageMeta, p *pageState, frontmatter map[string]interface{}) error {
+	if pm.kind != page.KindPage || p.File().IsZero() {
+		return nil
+	}
+
+	typ := cast.ToString(frontmatter["type"])
+	if typ == "" {
+		typ = pm.Section()
+	}
+
+	c, err := a.get(typ, "."+p.File().Ext())
+	if err != nil || c == nil {
+		return err
+	}
+
+	pm.archetype = c.filename
+	pm.archetypeMissing = nil
+	for _, field := range c.fields {
+		if _, found := frontmatter[field]; found {
+			continue
+		}
+		pm.archetypeMissing = append(pm.archetypeMissing, field)
+		if v, found := c.defaults[field]; found && a.backfill {
+			frontmatter[field] = v
+		}
+	}
+
+	return nil
+}
+
+func parseArchetypeContract(b []byte) (*archetypeContract, error) {
+	b = replaceArchetypeTemplates(b)
+
+	cf, err := pageparser.ParseFrontMatterAndContent(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+
+	c := &archetypeContract{defaults: make(map[string]interface{})}
+	for k, v := range cf.FrontMatter {
+		k = strings.ToLower(k)
+		if k == "draft" {
+			// Archetypes usually mark new content as draft, and the
+			// field is removed or set to false when published.
+			continue
+		}
+		c.fields = append(c.fields, k)
+		if !hasArchetypeTemplate(v) {
+			c.defaults[k] = v
+		}
+	}
+	sort.Strings(c.fields)
+
+	return c, nil
+}
+
+// replaceArchetypeTemplates replaces the template actions in b with a
+// placeholder, quoted if the action is the value itself, so the front
+// matter can be decoded.
+func replaceArchetypeTemplates(b []byte) []byte {
+	var (
+		buf  bytes.Buffer
+		prev int
+	)
+
+	for _, loc := range archetypeTemplateRe.FindAllIndex(b, -1) {
+		buf.Write(b[prev:loc[0]])
+		line := b[:loc[0]]
+		if i := bytes.LastIndexByte(line, '\n'); i != -1 {
+			line = line[i+1:]
+		}
+		line = bytes.TrimRight(line, " \t")
+		if len(line) > 0 && bytes.ContainsAny(line[len(line)-1:], "=:[,-") {
+			buf.WriteString(`"` + archetypeTemplatePlaceholder + `"`)
+		} else {
+			buf.WriteString(archetypeTemplatePlaceholder)
+		}
+		prev = loc[1]
+	}
+	buf.Write(b[prev:])
+
+	return buf.Bytes()
+}
+
+func hasArchetypeTemplate(v interface{}) bool {
+	switch vv := v.(type) {
+	case string:
+		return strings.Contains(vv, archetypeTemplatePlaceholder)
+	case []interface{}:
+		for _, e := range vv {
+			if hasArchetypeTemplate(e) {
+				return true
+			}
+		}
+	case map[string]interface{}:
+		for _, e := range vv {
+			if hasArchetypeTemplate(e) {
+				return true
+			}
+		}
+	case map[interface{}]interface{}:
+		for _, e := range vv {
+			if hasArchetypeTemplate(e) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ArchetypeLintResult describes a page missing front matter fields defined
+// in its archetype.
+type ArchetypeLintResult struct {
+	// The content filename.
+	Filename string
+
+	// The archetype filename, relative to the archetypes folder.
+	Archetype string
+
+	// The lower case names of the missing fields.
+	Missing []string
+}
+
+// LintArchetypes returns the pages missing front matter fields defined in
+// their archetypes, sorted by filename. Set lintArchetypes or
+// archetypeDefaults in the configuration to enable this.
+func (h *HugoSites) LintArchetypes() []ArchetypeLintResult {
+	var results []ArchetypeLintResult
+	seen := make(map[string]bool)
+
+	for _, pp := range h.Pages() {
+		p, ok := mustUnwrapPage(pp).(*pageState)
+		if !ok || p.File().IsZero() || len(p.m.archetypeMissing) == 0 {
+			continue
+		}
+		filename := p.File().Filename()
+		if seen[filename] {
+			continue
+		}
+		seen[filename] = true
+		results = append(results, ArchetypeLintResult{
+			Filename:  filename,
+			Archetype: p.m.archetype,
+			Missing:   p.m.archetypeMissing,
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Filename < results[j].Filename
+	})
+
+	return results
+}
@@ -0,0 +1,104 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestParseArchetypeContract(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range []struct {
+		name     string
+		source   string
+		defaults map[string]interface{}
+	}{
+		{"YAML", `---
+title: "{{ replace .Name "-" " " | title }}"
+date: {{ .Date }}
+draft: true
+author: Jo
+tags: [news]
+---
+{{ .Name }}
+`, map[string]interface{}{"author": "Jo", "tags": []interface{}{"news"}}},
+		{"TOML", `+++
+title = "{{ replace .Name "-" " " | title }}"
+date = {{ .Date }}
+draft = true
+author = "Jo"
+tags = ["news"]
++++
+`, map[string]interface{}{"author": "Jo", "tags": []interface{}{"news"}}},
+	} {
+		c.Run(test.name, func(c *qt.C) {
+			ac, err := parseArchetypeContract([]byte(test.source))
+			c.Assert(err, qt.IsNil)
+			c.Assert(ac.fields, qt.DeepEquals, []string{"author", "date", "tags", "title"})
+			c.Assert(ac.defaults, qt.DeepEquals, test.defaults)
+		})
+	}
+}
+
+func TestArchetypeContracts(t *testing.T) {
+	c := qt.New(t)
+
+	build := func(backfill bool) *sitesBuilder {
+		b := newTestSitesBuilder(t).WithConfigFile("toml", fmt.Sprintf(`
+baseURL = "https://example.org"
+disableKinds = ["RSS", "sitemap", "taxonomy", "term"]
+archetypeDefaults = %t
+`, backfill))
+
+		b.WithSourceFile(
+			"archetypes/default.md", "---\ntitle: \"{{ .Name }}\"\ndraft: true\n---\n",
+			"archetypes/posts.md", "---\ntitle: \"{{ .Name }}\"\ndate: {{ .Date }}\nauthor: Staff\nsummary: \"\"\n---\n",
+		)
+		b.WithContent(
+			"posts/p1.md", "---\ntitle: P1\ndate: 2021-01-01\nauthor: Jo\nsummary: S\n---\n",
+			"posts/p2.md", "---\ntitle: P2\n---\n",
+			"about.md", "---\ntitle: About\n---\n",
+			"notes/n1.md", "N1",
+		)
+		b.WithTemplates("_default/single.html", `{{ .Title }}|Author: {{ .Params.author }}|Draft: {{ .Draft }}`)
+		b.Build(BuildCfg{})
+		return b
+	}
+
+	b := build(true)
+
+	// The static defaults are backfilled, draft is never.
+	b.AssertFileContent("public/posts/p1/index.html", "P1|Author: Jo|Draft: false")
+	b.AssertFileContent("public/posts/p2/index.html", "P2|Author: Staff|Draft: false")
+	b.AssertFileContent("public/notes/n1/index.html", "|Author: |Draft: false")
+
+	results := b.H.LintArchetypes()
+	c.Assert(results, qt.HasLen, 2)
+	c.Assert(results[0].Filename, qt.Equals, filepath.FromSlash("content/notes/n1.md"))
+	c.Assert(results[0].Archetype, qt.Equals, "default.md")
+	c.Assert(results[0].Missing, qt.DeepEquals, []string{"title"})
+	c.Assert(results[1].Filename, qt.Equals, filepath.FromSlash("content/posts/p2.md"))
+	c.Assert(results[1].Archetype, qt.Equals, "posts.md")
+	c.Assert(results[1].Missing, qt.DeepEquals, []string{"author", "date", "summary"})
+
+	// Disabled by default.
+	b = build(false)
+	b.AssertFileContent("public/posts/p2/index.html", "P2|Author: |Draft: false")
+	c.Assert(b.H.LintArchetypes(), qt.HasLen, 0)
+}
@@ -0,0 +1,61 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"testing"
+)
+
+func TestBreadcrumbs(t *testing.T) {
+	t.Parallel()
+
+	b := newTestSitesBuilder(t).WithConfigFile("toml", `
+baseURL = "https://example.org/"
+title = "My Site"
+`)
+
+	b.WithContent("_index.md", `---
+title: "Home"
+breadcrumbTitle: "Start"
+---
+`, "docs/_index.md", `---
+title: "Documentation"
+linkTitle: "Docs"
+---
+`, "docs/install/_index.md", `---
+title: "Installation"
+---
+`, "docs/install/linux.md", `---
+title: "Install on Linux"
+tags: ["Linux"]
+---
+`)
+
+	crumbs := `{{ $crumbs := breadcrumbs . }}{{ range $crumbs }}{{ .Position }}:{{ .Title }}:{{ .URL }}{{ if .IsLast }}:current{{ end }}|{{ end }}
+{{ $crumbs.JSONLD }}`
+
+	b.WithTemplates(
+		"_default/single.html", crumbs,
+		"_default/list.html", crumbs,
+	)
+
+	b.Build(BuildCfg{})
+
+	b.AssertFileContent("public/docs/install/linux/index.html",
+		"1:Start:/|2:Docs:/docs/|3:Installation:/docs/install/|4:Install on Linux:/docs/install/linux/:current|",
+		`<script type="application/ld+json">{"@context":"https://schema.org","@type":"BreadcrumbList","itemListElement":[{"@type":"ListItem","position":1,"name":"Start","item":"https://example.org/"},{"@type":"ListItem","position":2,"name":"Docs","item":"https://example.org/docs/"}`,
+	)
+	b.AssertFileContent("public/index.html", "1:Start:/:current|")
+	b.AssertFileContent("public/tags/linux/index.html", "1:Start:/|2:Tags:/tags/|3:Linux:/tags/linux/:current|")
+}
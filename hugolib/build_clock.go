@@ -0,0 +1,70 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/gohugoio/hugo/common/hexec"
+	"github.com/gohugoio/hugo/common/htime"
+	"github.com/gohugoio/hugo/config"
+	"github.com/gohugoio/hugo/config/security"
+	"github.com/pkg/errors"
+)
+
+// sourceDateEpochEnv is the environment variable for the build time in
+// reproducible builds, see
+// https://reproducible-builds.org/specs/source-date-epoch/.
+const sourceDateEpochEnv = "SOURCE_DATE_EPOCH"
+
+// decodeBuildClock returns the clock used in the build. In reproducible
+// builds it's fixed at sourceDateEpoch if set, else at the time of the last
+// Git commit in workingDir. Other builds use the system clock.
+func decodeBuildClock(cfg config.Provider, sc security.Config, workingDir string) (htime.Clock, error) {
+	if !cfg.GetBool("reproducible") {
+		return htime.SystemClock, nil
+	}
+
+	if s := cfg.GetString("sourceDateEpoch"); s != "" {
+		t, err := htime.ParseSourceDateEpoch(s)
+		if err != nil {
+			return nil, err
+		}
+		return htime.FixedClock(t), nil
+	}
+
+	args := []string{"log", "-1", "--format=%ct"}
+	if err := sc.CheckAllowedExec("git", args...); err != nil {
+		return nil, err
+	}
+	cmd, err := hexec.SafeCommand("git", args...)
+	if err == nil {
+		var out bytes.Buffer
+		cmd.Dir = workingDir
+		cmd.Stdout = &out
+		if err = cmd.Run(); err == nil {
+			t := strings.TrimSpace(out.String())
+			if t != "" {
+				tt, err := htime.ParseSourceDateEpoch(t)
+				if err != nil {
+					return nil, err
+				}
+				return htime.FixedClock(tt), nil
+			}
+		}
+	}
+
+	return nil, errors.Errorf("reproducible builds need the time of the build: failed to get the time of the last Git commit, set %s to the number of seconds since the Unix epoch", sourceDateEpochEnv)
+}
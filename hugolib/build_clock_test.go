@@ -0,0 +1,81 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/common/htime"
+	"github.com/gohugoio/hugo/config"
+	"github.com/gohugoio/hugo/config/security"
+)
+
+func TestReproducibleBuild(t *testing.T) {
+	t.Parallel()
+
+	b := newTestSitesBuilder(t).WithConfigFile("toml", `
+baseURL = "https://example.org"
+reproducible = true
+[frontmatter]
+lastmod = [":fileModTime"]
+`).WithEnviron("SOURCE_DATE_EPOCH", "1625097600")
+
+	b.WithContent(
+		"b/past.md", "---\ntitle: B\ndate: 2021-06-02\n---\n",
+		"past.md", "---\ntitle: Past\ndate: 2021-06-01\n---\n",
+		"future.md", "---\ntitle: Future\ndate: 2021-08-01\n---\n",
+		"expired.md", "---\ntitle: Expired\ndate: 2021-05-01\nexpiryDate: 2021-06-15\n---\n",
+	)
+	b.WithTemplates("index.html", `Now: {{ now.Format "2006-01-02" }}|{{ range .Site.RegularPages }}{{ .Title }}: {{ .Lastmod.Format "2006-01-02" }}|{{ end }}`)
+	b.Build(BuildCfg{})
+
+	b.AssertFileContent("public/index.html", "Now: 2021-07-01|B: 2021-07-01|Past: 2021-07-01|")
+	// Sitemap URLs are sorted by permalink.
+	b.AssertFileContentRe("public/sitemap.xml", `(?s)<loc>https://example.org/</loc>.*<loc>https://example.org/b/</loc>.*<loc>https://example.org/b/past/</loc>.*<loc>https://example.org/past/</loc>`)
+}
+
+func TestDecodeBuildClock(t *testing.T) {
+	c := qt.New(t)
+
+	cfg := config.New()
+	clock, err := decodeBuildClock(cfg, security.DefaultConfig, "")
+	c.Assert(err, qt.IsNil)
+	c.Assert(clock, qt.Equals, htime.SystemClock)
+
+	// SOURCE_DATE_EPOCH is only used in reproducible builds.
+	cfg.Set("sourceDateEpoch", "0")
+	clock, err = decodeBuildClock(cfg, security.DefaultConfig, "")
+	c.Assert(err, qt.IsNil)
+	c.Assert(clock, qt.Equals, htime.SystemClock)
+
+	cfg.Set("reproducible", true)
+	clock, err = decodeBuildClock(cfg, security.DefaultConfig, "")
+	c.Assert(err, qt.IsNil)
+	c.Assert(clock.Now().Unix(), qt.Equals, int64(0))
+
+	cfg.Set("sourceDateEpoch", "yesterday")
+	_, err = decodeBuildClock(cfg, security.DefaultConfig, "")
+	c.Assert(err, qt.ErrorMatches, "invalid SOURCE_DATE_EPOCH.*")
+
+	cfg = config.New()
+	cfg.Set("reproducible", true)
+	_, err = decodeBuildClock(cfg, security.DefaultConfig, t.TempDir())
+	c.Assert(err, qt.ErrorMatches, "reproducible builds need the time of the build.*")
+
+	sc := security.DefaultConfig
+	sc.Exec.Allow = security.NewWhitelist("^go$")
+	_, err = decodeBuildClock(cfg, sc, t.TempDir())
+	c.Assert(security.IsAccessDenied(err), qt.IsTrue)
+}
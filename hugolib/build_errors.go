@@ -0,0 +1,112 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/gohugoio/hugo/common/herrors"
+	"github.com/gohugoio/hugo/common/loggers"
+	"github.com/gohugoio/hugo/hugolib/paths"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+// E.g. "[en] REF_NOT_FOUND: Ref ...".
+var loggedErrorCodeRe = regexp.MustCompile(`^(?:\[[\w-]+\] )?([A-Z][A-Z0-9_]+): `)
+
+// BuildErrors returns the errors from the last build, including the logged
+// errors, as machine-readable descriptions.
+// The logged errors are only included if the logger saves them.
+func (h *HugoSites) BuildErrors() []herrors.ErrorInfo {
+	infos := []herrors.ErrorInfo{}
+	seen := make(map[string]bool)
+
+	add := func(info herrors.ErrorInfo) {
+		if seen[info.Message] {
+			return
+		}
+		seen[info.Message] = true
+		infos = append(infos, info)
+	}
+
+	for _, err := range h.buildErrors {
+		if err != nil {
+			add(herrors.NewErrorInfo(err))
+		}
+	}
+
+	if err := h.fatalErrorHandler.getErr(); err != nil {
+		add(herrors.NewErrorInfo(err))
+	}
+
+	msgs := loggers.ErrorMessages(h.Log.Errors())
+	for _, msg := range msgs {
+		add(newLoggedErrorInfo(msg))
+	}
+
+	if len(msgs) == 0 {
+		if n := h.NumLogErrors(); n > 0 {
+			add(herrors.ErrorInfo{Code: herrors.CodeLogged, Message: fmt.Sprintf("logged %d error(s)", n)})
+		}
+	}
+
+	return infos
+}
+
+func newLoggedErrorInfo(msg string) herrors.ErrorInfo {
+	e := loggers.NewEntry("error", msg)
+	info := herrors.ErrorInfo{
+		Code:    herrors.CodeLogged,
+		Message: msg,
+		File:    e.File,
+		Line:    e.Line,
+		Column:  e.Column,
+		Page:    e.Page,
+	}
+	if m := loggedErrorCodeRe.FindStringSubmatch(msg); m != nil {
+		info.Code = m[1]
+	}
+	return info
+}
+
+// writeErrorsFile writes the build errors to the file set in errorsFile, if any.
+func (h *HugoSites) writeErrorsFile() error {
+	filename := h.Cfg.GetString("errorsFile")
+	if filename == "" {
+		return nil
+	}
+	filename = paths.AbsPathify(h.WorkingDir, filename)
+
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(struct {
+		Errors []herrors.ErrorInfo `json:"errors"`
+	}{
+		Errors: h.BuildErrors(),
+	}); err != nil {
+		return err
+	}
+
+	if err := afero.WriteFile(h.Fs.Source, filename, b.Bytes(), 0666); err != nil {
+		return errors.Wrap(err, "failed to write errors file")
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/common/herrors"
+	"github.com/gohugoio/hugo/common/loggers"
+	jww "github.com/spf13/jwalterweatherman"
+)
+
+func TestBuildErrors(t *testing.T) {
+	c := qt.New(t)
+
+	newBuilder := func(c *qt.C) *sitesBuilder {
+		b := newTestSitesBuilder(c).WithConfigFile("toml", `
+baseURL = "https://example.org"
+disableKinds = ["taxonomy", "term", "RSS", "sitemap"]
+errorsFile = "errors.json"
+`)
+		b.WithLogger(loggers.NewLogger(jww.LevelError, jww.LevelError, ioutil.Discard, ioutil.Discard, true))
+		return b
+	}
+
+	readErrors := func(b *sitesBuilder) []herrors.ErrorInfo {
+		var report struct {
+			Errors []herrors.ErrorInfo
+		}
+		b.Assert(json.Unmarshal([]byte(b.FileContent("errors.json")), &report), qt.IsNil)
+		return report.Errors
+	}
+
+	c.Run("Front matter", func(c *qt.C) {
+		b := newBuilder(c).WithContent("p1.md", `---
+title: "P1"
+date: [invalid
+---
+`)
+		b.Assert(b.BuildE(BuildCfg{}), qt.Not(qt.IsNil))
+
+		errs := readErrors(b)
+		b.Assert(errs, qt.HasLen, 1)
+		b.Assert(errs[0].Code, qt.Equals, herrors.CodeFrontMatter)
+		b.Assert(errs[0].File, qt.Contains, "p1.md")
+		b.Assert(errs[0].Line, qt.Equals, 3)
+	})
+
+	c.Run("Template", func(c *qt.C) {
+		b := newBuilder(c).WithContent("p1.md", `---
+title: "P1"
+---
+`).WithTemplates("_default/single.html", "Single.\n{{ .Foo }}")
+		b.Assert(b.BuildE(BuildCfg{}), qt.Not(qt.IsNil))
+
+		errs := readErrors(b)
+		b.Assert(errs, qt.HasLen, 1)
+		b.Assert(errs[0].Code, qt.Equals, herrors.CodeTemplateExecute)
+		b.Assert(errs[0].File, qt.Contains, "single.html")
+		b.Assert(errs[0].Line, qt.Equals, 2)
+	})
+
+	c.Run("Logged", func(c *qt.C) {
+		b := newBuilder(c).WithContent("p1.md", `---
+title: "P1"
+---
+{{< ref "missing.md" >}}
+`).WithTemplates("_default/single.html", "{{ .Content }}")
+		b.Assert(b.BuildE(BuildCfg{}), qt.Not(qt.IsNil))
+
+		errs := readErrors(b)
+		b.Assert(errs, qt.HasLen, 1)
+		b.Assert(errs[0].Code, qt.Equals, herrors.CodeRefNotFound)
+		b.Assert(errs[0].File, qt.Contains, "p1.md")
+		b.Assert(errs[0].Line, qt.Equals, 4)
+	})
+
+	c.Run("No errors", func(c *qt.C) {
+		b := newBuilder(c).WithContent("p1.md", `---
+title: "P1"
+---
+`)
+		b.Build(BuildCfg{})
+
+		b.Assert(readErrors(b), qt.HasLen, 0)
+		b.AssertFileContent("errors.json", `"errors": []`)
+	})
+}
@@ -0,0 +1,56 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"bytes"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/metrics"
+)
+
+func TestBuildProgress(t *testing.T) {
+	b := newTestSitesBuilder(t).WithConfigFile("toml", `
+baseURL = "https://example.org"
+disableKinds = ["taxonomy", "term", "sitemap", "robotsTXT"]
+progress = "text"
+[languages]
+[languages.en]
+weight = 1
+[languages.nn]
+weight = 2
+`)
+	b.WithContent("p1.md", "---\ntitle: P1\n---", "p2.md", "---\ntitle: P2\n---", "p1.nn.md", "---\ntitle: P1\n---")
+	b.WithTemplates("_default/single.html", `Single: {{ .Title }}`)
+
+	b.CreateSites()
+	b.Assert(b.H.Progress, qt.Not(qt.IsNil))
+
+	var buf bytes.Buffer
+	p, err := metrics.NewProgress(&buf, metrics.ProgressFormatText)
+	b.Assert(err, qt.IsNil)
+	for _, s := range b.H.Sites {
+		s.Deps.Progress = p
+	}
+
+	b.Build(BuildCfg{})
+
+	out := buf.String()
+	b.Assert(out, qt.Contains, "Progress: content: 3 in ")
+	b.Assert(out, qt.Contains, "Progress: assemble: 3/3 (100%) in ")
+	// Home, 404, section and the pages.
+	b.Assert(out, qt.Contains, "Progress: render HTML (en): 5/5 (100%) in ")
+	b.Assert(out, qt.Contains, "Progress: render RSS (nn): 4/4 (100%) in ")
+}
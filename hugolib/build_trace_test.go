@@ -0,0 +1,76 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestBuildTrace(t *testing.T) {
+	c := qt.New(t)
+
+	var (
+		mu    sync.Mutex
+		names = make(map[string]bool)
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ResourceSpans []struct {
+				ScopeSpans []struct {
+					Spans []struct {
+						Name string
+					}
+				}
+			}
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		mu.Lock()
+		defer mu.Unlock()
+		for _, rs := range req.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				for _, s := range ss.Spans {
+					names[s.Name] = true
+				}
+			}
+		}
+	}))
+	defer srv.Close()
+
+	b := newTestSitesBuilder(t).WithConfigFile("toml", `
+baseURL = "https://example.org"
+traceEndpoint = "`+srv.URL+`"
+`)
+	b.WithContent("p1.md", "---\ntitle: P1\n---\nContent.")
+	b.WithSourceFile("assets/css/main.css", "body {  color: blue;  }")
+	b.WithTemplates(
+		"_default/single.html", `Single: {{ .Title }}`,
+		"index.html", `Home: {{ (resources.Get "css/main.css" | minify).RelPermalink }}`,
+	)
+
+	b.Build(BuildCfg{})
+
+	b.AssertFileContent("public/index.html", "Home: /css/main.min.css")
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, name := range []string{"build", "process", "assemble", "render", "postProcess", "template: index.html", "template: _default/single.html", "transform: minify"} {
+		c.Assert(names[name], qt.IsTrue, qt.Commentf(name))
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestComments(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/comments/p1.json":
+			w.Write([]byte(`[{"id": "1", "content": "<p>Nice post!</p>", "author": {"name": "Jane"}, "date": "2021-06-01T10:00:00Z",
+"replies": [{"id": "2", "content": "<p>Thanks!</p>", "author": {"name": "Joe"}, "date": "2021-06-02T10:00:00Z"}]}]`))
+		case "/api/v1/statuses/100/context":
+			w.Write([]byte(`{"descendants": [{"id": "101", "in_reply_to_id": "100", "content": "<p>Toot</p>", "created_at": "2021-05-01T10:00:00Z", "account": {"acct": "bep"}}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	b := newTestSitesBuilder(t).WithConfigFile("toml", fmt.Sprintf(`
+baseURL = "https://example.org"
+disableKinds = ["taxonomy", "term"]
+[[comments.sources]]
+name = "api"
+type = "json"
+url = "%s/comments/:id.json"
+[[comments.sources]]
+name = "mastodon"
+type = "mastodon"
+`, srv.URL))
+
+	b.WithContent("p1.md", fmt.Sprintf(`---
+title: P1
+comments:
+  api: p1
+  mastodon: %s/@bep/100
+---
+`, srv.URL), "p2.md", `---
+title: P2
+comments: false
+---
+`)
+
+	b.WithTemplates("_default/single.html", `
+{{ define "comment" }}{{ .Author.Name }}: {{ .Content | safeHTML }}{{ with .Replies }}[{{ range . }}{{ template "comment" . }}{{ end }}]{{ end }}{{ end }}
+Comments: {{ range comments.Get . }}{{ template "comment" . }}|{{ end }}
+Source: {{ range comments.Get "api" "p1" }}{{ .Author.Name }}{{ end }}
+`)
+
+	b.Build(BuildCfg{})
+
+	b.AssertFileContent("public/p1/index.html",
+		"Comments: bep: <p>Toot</p>|Jane: <p>Nice post!</p>[Joe: <p>Thanks!</p>]|",
+		"Source: Jane",
+	)
+	b.AssertFileContent("public/p2/index.html", "Comments: \n")
+}
@@ -39,10 +39,15 @@ import (
 	"github.com/gohugoio/hugo/modules"
 	"github.com/pkg/errors"
 
+	"github.com/gohugoio/hugo/a11y"
+	"github.com/gohugoio/hugo/comments"
 	"github.com/gohugoio/hugo/config"
 	"github.com/gohugoio/hugo/config/privacy"
+	"github.com/gohugoio/hugo/config/security"
 	"github.com/gohugoio/hugo/config/services"
+	"github.com/gohugoio/hugo/graphql"
 	"github.com/gohugoio/hugo/helpers"
+	"github.com/gohugoio/hugo/transform/htmlpostprocess"
 	"github.com/spf13/afero"
 )
 
@@ -89,7 +94,7 @@ func LoadConfig(d ConfigSourceDescriptor, doWithConfig ...func(cfg config.Provid
 			if len(dirnames) > 0 {
 				return nil, nil, l.wrapFileError(err, dirnames[0])
 			}
-			return nil, nil, err
+			return nil, nil, herrors.WithCode(herrors.CodeConfig, err)
 		}
 	}
 
@@ -115,6 +120,12 @@ func LoadConfig(d ConfigSourceDescriptor, doWithConfig ...func(cfg config.Provid
 		return l.cfg, configFiles, err
 	}
 
+	for _, v := range d.Environ {
+		if key, val := config.SplitEnvVar(v); key == sourceDateEpochEnv && val != "" {
+			l.cfg.Set("sourceDateEpoch", val)
+		}
+	}
+
 	modulesConfig, err := l.loadModulesConfig()
 	if err != nil {
 		return l.cfg, configFiles, err
@@ -237,7 +248,13 @@ func (l configLoader) applyConfigAliases() error {
 }
 
 func (l configLoader) applyConfigDefaults() error {
-	defaultSettings := maps.Params{
+	l.cfg.SetDefaults(defaultSettings())
+
+	return nil
+}
+
+func defaultSettings() maps.Params {
+	return maps.Params{
 		"cleanDestinationDir":                  false,
 		"watch":                                false,
 		"resourceDir":                          "resources",
@@ -256,7 +273,7 @@ func (l configLoader) applyConfigDefaults() error {
 		"titleCaseStyle":                       "AP",
 		"taxonomies":                           map[string]string{"tag": "tags", "category": "categories"},
 		"permalinks":                           make(map[string]string),
-		"sitemap":                              config.Sitemap{Priority: -1, Filename: "sitemap.xml"},
+		"sitemap":                              config.Sitemap{Priority: -1, Filename: "sitemap.xml", LastmodSource: config.SitemapLastmodSourceLastmod},
 		"disableLiveReload":                    false,
 		"pluralizeListTitles":                  true,
 		"forceSyncStatic":                      false,
@@ -282,11 +299,12 @@ func (l configLoader) applyConfigDefaults() error {
 		"disableFastRender":                    false,
 		"timeout":                              "30s",
 		"enableInlineShortcodes":               false,
+		"pageMetricsCount":                     20,
+		"staticPublishMode":                    "copy",
+		"strictFrontMatter":                    false,
+		"archetypeDefaults":                    false,
+		"reproducible":                         false,
 	}
-
-	l.cfg.SetDefaults(defaultSettings)
-
-	return nil
 }
 
 func (l configLoader) applyOsEnvOverrides(environ []string) error {
@@ -369,6 +387,48 @@ func (l configLoader) collectModules(modConfig modules.Config, v1 config.Provide
 
 	v1.Set("filecacheConfigs", filecacheConfigs)
 
+	securityConfig, err := security.DecodeConfig(v1)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	v1.Set("securityConfig", securityConfig)
+
+	buildClock, err := decodeBuildClock(v1, securityConfig, workingDir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	v1.Set("buildClock", buildClock)
+
+	commentsConfig, err := comments.DecodeConfig(v1)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	v1.Set("commentsConfig", commentsConfig)
+
+	graphQLConfig, err := graphql.DecodeConfig(v1)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	v1.Set("graphQLConfig", graphQLConfig)
+
+	a11yConfig, err := a11y.DecodeConfig(v1)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	v1.Set("a11yConfig", a11yConfig)
+
+	htmlPostProcessConfig, err := htmlpostprocess.DecodeConfig(v1)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	v1.Set("htmlPostProcessConfig", htmlPostProcessConfig)
+
 	var configFilenames []string
 
 	hook := func(m *modules.ModulesConfig) error {
@@ -402,6 +462,8 @@ func (l configLoader) collectModules(modConfig modules.Config, v1 config.Provide
 		CacheDir:           filecacheConfigs.CacheDirModules(),
 		ModuleConfig:       modConfig,
 		IgnoreVendor:       ignoreVendor,
+		HooksDir:           filepath.Join(paths.AbsPathify(workingDir, v1.GetString("resourceDir")), "_gen", "hooks"),
+		Security:           securityConfig,
 	})
 
 	v1.Set("modulesClient", modulesClient)
@@ -494,7 +556,7 @@ func (configLoader) loadSiteConfig(cfg config.Provider) (scfg SiteConfig, err er
 
 func (l configLoader) wrapFileError(err error, filename string) error {
 	err, _ = herrors.WithFileContextForFile(
-		err,
+		herrors.WithCode(herrors.CodeConfig, err),
 		filename,
 		filename,
 		l.Fs,
@@ -0,0 +1,106 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"encoding/json"
+	"path/filepath"
+
+	"github.com/gohugoio/hugo/config/configschema"
+	"github.com/gohugoio/hugo/modules"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+// The root settings without a default in defaultSettings.
+var rootSettingsSchema = map[string]interface{}{
+	"baseURL":                    "",
+	"title":                      "",
+	"copyright":                  "",
+	"languageCode":               "",
+	"theme":                      []string{},
+	"contentDir":                 "content",
+	"dataDir":                    "data",
+	"layoutDir":                  "layouts",
+	"staticDir":                  []string{"static"},
+	"archetypeDir":               "archetypes",
+	"assetDir":                   "assets",
+	"i18nDir":                    "i18n",
+	"cacheDir":                   "",
+	"disableKinds":               []string{},
+	"disableLanguages":           []string{},
+	"ignoreErrors":               []string{},
+	"enableRobotsTXT":            false,
+	"disableHugoGeneratorInject": false,
+	"googleAnalytics":            "",
+	"disqusShortname":            "",
+	"outputs":                    map[string][]string{},
+	"traceEndpoint":              "",
+	"errorsFile":                 "",
+	"progress":                   "",
+	"editorURL":                  "",
+}
+
+// ConfigSchema creates a JSON Schema for the site configuration. The params
+// schemas set in the modules, see modules.Config.ParamsSchema, are read
+// from fs and added to the params section, also for every language.
+func ConfigSchema(fs afero.Fs, mods modules.Modules) (*configschema.Schema, error) {
+	s := configschema.New()
+
+	for k, v := range defaultSettings() {
+		if _, found := s.Properties[k]; !found {
+			s.Set(k, v)
+		}
+	}
+	for k, v := range rootSettingsSchema {
+		s.Set(k, v)
+	}
+
+	params := &configschema.Schema{Type: configschema.TypeObject}
+	for _, m := range mods {
+		filename := m.Config().ParamsSchema
+		if filename == "" {
+			continue
+		}
+		if !filepath.IsAbs(filename) {
+			filename = filepath.Join(m.Dir(), filename)
+		}
+		b, err := afero.ReadFile(fs, filename)
+		if err != nil {
+			return nil, errors.Wrapf(err, "module %q: failed to read params schema", m.Path())
+		}
+		var ps map[string]interface{}
+		if err := json.Unmarshal(b, &ps); err != nil {
+			return nil, errors.Wrapf(err, "module %q: failed to parse params schema %q", m.Path(), filename)
+		}
+		delete(ps, "$schema")
+		params.AllOf = append(params.AllOf, ps)
+	}
+	s.Set("params", params)
+
+	language := configschema.Object(map[string]interface{}{
+		"languageName":      "",
+		"languageCode":      "",
+		"languageDirection": &configschema.Schema{Type: configschema.TypeString, Enum: []interface{}{"ltr", "rtl"}},
+		"title":             "",
+		"weight":            0,
+		"contentDir":        "",
+		"disabled":          false,
+		"params":            params,
+		"menus":             s.Properties["menus"],
+	})
+	s.Set("languages", &configschema.Schema{Type: configschema.TypeObject, AdditionalProperties: language})
+
+	return s, nil
+}
@@ -357,6 +357,11 @@ func (m *contentMap) AddFilesBundle(header hugofs.FileMetaInfo, resources ...hug
 		section string
 	)
 
+	if rank := m.cfg.langRank(meta.Lang()); rank > 0 && isBranch {
+		// Only regular pages are rendered from a fallback language.
+		return nil
+	}
+
 	if isBranch {
 		// Either a section or a taxonomy node.
 		section = bundlePath
@@ -377,7 +382,21 @@ func (m *contentMap) AddFilesBundle(header hugofs.FileMetaInfo, resources ...hug
 	} else {
 		// A regular page. Attach it to its section.
 		section, _ = m.getOrCreateSection(n, bundlePath)
-		b = b.WithSection(section).ForPage(bundlePath).Insert(n)
+		b = b.WithSection(section).ForPage(bundlePath)
+
+		if v, found := b.tree.Get(b.Key()); found {
+			existing := v.(*contentNode).fi.Meta().Lang()
+			if m.cfg.langRank(meta.Lang()) > m.cfg.langRank(existing) {
+				// A translation in a preferred language is already added.
+				return nil
+			}
+			if existing != meta.Lang() {
+				// Replacing a page from a fallback language; remove its resources.
+				b.ForResource("").DeleteAll()
+			}
+		}
+
+		b.Insert(n)
 	}
 
 	if m.cfg.isRebuild {
@@ -740,6 +759,24 @@ type contentMapConfig struct {
 	taxonomyTermDisabled bool
 	pageDisabled         bool
 	isRebuild            bool
+
+	// The languages to render missing pages from, in order of preference.
+	fallbackLanguages []string
+}
+
+// langRank returns how preferred content in lang is in this content map,
+// 0 being the map's own language. Languages not in the fallback chain
+// rank last.
+func (cfg contentMapConfig) langRank(lang string) int {
+	if lang == cfg.lang {
+		return 0
+	}
+	for i, l := range cfg.fallbackLanguages {
+		if l == lang {
+			return i + 1
+		}
+	}
+	return len(cfg.fallbackLanguages) + 1
 }
 
 func (cfg contentMapConfig) getTaxonomyConfig(s string) (v viewName) {
@@ -14,8 +14,11 @@
 package hugolib
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"net/http"
+	"net/url"
 	"path"
 	"path/filepath"
 	"strings"
@@ -24,7 +27,10 @@ import (
 	"github.com/gohugoio/hugo/common/maps"
 
 	"github.com/gohugoio/hugo/common/types"
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/gohugoio/hugo/metrics"
 	"github.com/gohugoio/hugo/resources"
+	"github.com/gohugoio/hugo/resources/resource_factories/create"
 
 	"github.com/gohugoio/hugo/common/hugio"
 	"github.com/gohugoio/hugo/hugofs"
@@ -135,6 +141,10 @@ func (m *pageMap) newPageFromContentNode(n *contentNode, parentBucket *pagesMapB
 	}
 	ps.gitInfo = gi
 
+	ps.openContent = content
+
+	// Only the front matter is needed to assemble the site; the content is
+	// loaded when needed, which for many pages is never.
 	r, err := content()
 	if err != nil {
 		return nil, err
@@ -143,24 +153,13 @@ func (m *pageMap) newPageFromContentNode(n *contentNode, parentBucket *pagesMapB
 
 	parseResult, err := pageparser.Parse(
 		r,
-		pageparser.Config{EnableEmoji: s.siteCfg.enableEmoji},
+		pageparser.Config{EnableEmoji: s.siteCfg.enableEmoji, FrontMatterOnly: true},
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	ps.pageContent = pageContent{
-		source: rawPageContent{
-			parsed:         parseResult,
-			posMainContent: -1,
-			posSummaryEnd:  -1,
-			posBodyStart:   -1,
-		},
-	}
-
-	ps.shortcodeState = newShortcodeHandler(ps, ps.s, nil)
-
-	if err := ps.mapContent(parentBucket, metaProvider); err != nil {
+	if err := ps.mapFrontMatter(parentBucket, metaProvider, parseResult); err != nil {
 		return nil, ps.wrapError(err)
 	}
 
@@ -262,6 +261,55 @@ func (m *pageMap) newResource(fim hugofs.FileMetaInfo, owner *pageState) (resour
 		})
 }
 
+// newRemoteResource creates a bundled resource for the remote resource at
+// uri, published below the owner with the base name in the URL's path.
+func (m *pageMap) newRemoteResource(uri string, owner *pageState) (resource.Resource, string, error) {
+	b, err := create.New(owner.s.ResourceSpec).FetchRemote(uri)
+	if err != nil {
+		return nil, "", err
+	}
+
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, "", err
+	}
+
+	target := owner.s.PathSpec.MakePathSanitized(path.Base(u.Path))
+	if target == "" || target == "." || target == "/" {
+		target = helpers.MD5String(uri)
+	}
+	if path.Ext(target) == "" {
+		// Use the suffix of the content's media type.
+		contentType := strings.Split(http.DetectContentType(b), ";")[0]
+		if mt, found := owner.s.ResourceSpec.MediaTypes.GetByType(contentType); found && mt.FirstSuffix.Suffix != "" {
+			target += mt.FirstSuffix.FullSuffix
+		}
+	}
+
+	seen := make(map[string]bool)
+	var targetBasePaths []string
+	for _, f := range owner.m.outputFormats() {
+		if seen[f.Path] {
+			continue
+		}
+		seen[f.Path] = true
+		targetBasePaths = append(targetBasePaths, f.Path)
+	}
+
+	r, err := owner.s.ResourceSpec.New(
+		resources.ResourceSourceDescriptor{
+			TargetPaths: owner.getTargetPaths,
+			OpenReadSeekCloser: func() (hugio.ReadSeekCloser, error) {
+				return hugio.NewReadSeekerNoOpCloser(bytes.NewReader(b)), nil
+			},
+			RelTargetFilename: target,
+			TargetBasePaths:   targetBasePaths,
+			LazyPublish:       !owner.m.buildConfig.PublishResources,
+		})
+
+	return r, target, err
+}
+
 func (m *pageMap) createSiteTaxonomies() error {
 	m.s.taxonomies = make(TaxonomyList)
 	var walkErr error
@@ -331,8 +379,11 @@ func (m *pageMap) assemblePages() error {
 		return err
 	}
 
+	progress := m.s.Progress.Phase(metrics.ProgressPhaseAssemble)
+
 	m.pages.Walk(func(s string, v interface{}) bool {
 		n := v.(*contentNode)
+		progress.Incr()
 
 		var shouldBuild bool
 
@@ -391,6 +442,9 @@ func (m *pageMap) assemblePages() error {
 func (m *pageMap) assembleResources(s string, p *pageState, parentBucket *pagesMapBucket) error {
 	var err error
 
+	// The resource files are collected again when the resources are.
+	p.resourceFiles = nil
+
 	m.resources.WalkPrefix(s, func(s string, v interface{}) bool {
 		n := v.(*contentNode)
 		meta := n.fi.Meta()
@@ -405,12 +459,14 @@ func (m *pageMap) assembleResources(s string, p *pageState, parentBucket *pagesM
 			}
 			rp.m.resourcePath = filepath.ToSlash(strings.TrimPrefix(rp.Path(), p.File().Dir()))
 			r = rp
+			p.resourceFiles = append(p.resourceFiles, n.fi)
 
 		case files.ContentClassFile:
 			r, err = m.newResource(n.fi, p)
 			if err != nil {
 				return true
 			}
+			p.resourceFiles = append(p.resourceFiles, n.fi)
 		default:
 			panic(fmt.Sprintf("invalid classifier: %q", classifier))
 		}
@@ -419,7 +475,39 @@ func (m *pageMap) assembleResources(s string, p *pageState, parentBucket *pagesM
 		return false
 	})
 
-	return err
+	if err != nil {
+		return err
+	}
+
+	return m.assembleRemoteResources(p)
+}
+
+// assembleRemoteResources adds the remote resources declared in the page's
+// resources front matter, e.g. src = "https://example.org/hero.jpg".
+func (m *pageMap) assembleRemoteResources(p *pageState) error {
+	for i, meta := range p.m.resourcesMetadata {
+		src := cast.ToString(meta["src"])
+		if !create.IsRemote(src) {
+			continue
+		}
+
+		r, target, err := m.newRemoteResource(src, p)
+		if err != nil {
+			return p.wrapError(err)
+		}
+
+		// Match the metadata, e.g. name and title, against the target.
+		mm := make(map[string]interface{})
+		for k, v := range meta {
+			mm[k] = v
+		}
+		mm["src"] = target
+		p.m.resourcesMetadata[i] = mm
+
+		p.resources = append(p.resources, r)
+	}
+
+	return nil
 }
 
 func (m *pageMap) assembleSections() error {
@@ -0,0 +1,126 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"io"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/gohugoio/hugo/markup/converter/hooks"
+	"github.com/gohugoio/hugo/resources/page"
+	"github.com/gohugoio/hugo/resources/resource"
+	"github.com/gohugoio/hugo/resources/resource_factories/create"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+// imageHookRenderer renders Markdown images with a render-image template,
+// passing it an imageLinkContext.
+type imageHookRenderer struct {
+	hookRenderer
+	s *Site
+}
+
+func (hr imageHookRenderer) RenderLink(w io.Writer, ctx hooks.LinkContext) error {
+	return hr.hookRenderer.RenderLink(w, &imageLinkContext{LinkContext: ctx, s: hr.s})
+}
+
+// imageLinkContext is the context passed to the render-image templates.
+type imageLinkContext struct {
+	hooks.LinkContext
+	s *Site
+
+	resourceInit sync.Once
+	resource     resource.Resource
+	resourceErr  error
+}
+
+// Resolved forwards to the wrapped context, as its Resolved method is not in
+// the hooks.LinkContext interface.
+func (ctx *imageLinkContext) Resolved() bool {
+	if r, ok := ctx.LinkContext.(interface{ Resolved() bool }); ok {
+		return r.Resolved()
+	}
+	return false
+}
+
+// Resource returns the page resource, or if not found, the global resource
+// in /assets the image destination points to. It returns nil if not
+// found, e.g. for remote images.
+func (ctx *imageLinkContext) Resource() (resource.Resource, error) {
+	ctx.resourceInit.Do(func() {
+		ctx.resource, ctx.resourceErr = ctx.resolveResource()
+	})
+	return ctx.resource, ctx.resourceErr
+}
+
+func (ctx *imageLinkContext) resolveResource() (resource.Resource, error) {
+	u, err := url.Parse(ctx.Destination())
+	if err != nil || u.IsAbs() || u.Host != "" || u.Path == "" {
+		return nil, nil
+	}
+
+	if p, ok := ctx.Page().(page.Page); ok && !strings.HasPrefix(u.Path, "/") {
+		if r := p.Resources().GetMatch(u.Path); r != nil {
+			return r, nil
+		}
+	}
+
+	filename := strings.TrimPrefix(u.Path, "/")
+	if exists, _ := afero.Exists(ctx.s.BaseFs.Assets.Fs, filename); !exists {
+		return nil, nil
+	}
+
+	return create.New(ctx.s.ResourceSpec).Get(filename)
+}
+
+// Renditions creates the images in the named rendition set in the imaging
+// config from the image Resource. It returns nil if the Resource is not
+// a processable image.
+func (ctx *imageLinkContext) Renditions(name string) ([]resource.Image, error) {
+	renditions, found := ctx.s.ResourceSpec.ImageRenditions(name)
+	if !found {
+		return nil, errors.Errorf("image rendition set %q not found in imaging config", name)
+	}
+
+	r, err := ctx.Resource()
+	if err != nil {
+		return nil, err
+	}
+	img, ok := r.(resource.Image)
+	if !ok {
+		return nil, nil
+	}
+
+	images := make([]resource.Image, len(renditions))
+	for i, rendition := range renditions {
+		var processed resource.Image
+		switch rendition.Action {
+		case "resize":
+			processed, err = img.Resize(rendition.Options)
+		case "fit":
+			processed, err = img.Fit(rendition.Options)
+		case "fill":
+			processed, err = img.Fill(rendition.Options)
+		}
+		if err != nil {
+			return nil, err
+		}
+		images[i] = processed
+	}
+
+	return images, nil
+}
@@ -56,7 +56,6 @@ title: P1
 	b.AssertFileContent("public/p1/index.html", `Link First Link|PARTIAL1_EDITED PARTIAL2_EDITEDEND`)
 }
 
-
 func TestRenderHooks(t *testing.T) {
 	config := `
 baseURL="https://example.org"
@@ -477,3 +476,45 @@ func TestRenderStringOnListPage(t *testing.T) {
 		b.AssertFileContent("public/"+filename, `<strong>Hello</strong>`)
 	}
 }
+
+func TestRenderHookImageResource(t *testing.T) {
+	config := `
+baseURL="https://example.org"
+
+[imaging.renditions]
+responsive = ["resize 100x", "fill 50x50"]
+`
+	b := newTestSitesBuilder(t).WithConfigFile("toml", config)
+	b.WithTemplates("_default/single.html", `{{ .Content }}`)
+	b.WithTemplates("_default/_markup/render-image.html", `{{ with .Resource }}{{ .RelPermalink }}|{{ range $.Renditions "Responsive" }}{{ .RelPermalink }} {{ .Width }}w|{{ end }}{{ else }}{{ .Destination }}|no resource{{ end }}|Resolved: {{ .Resolved }}
+`)
+	b.WithContent("mybundle/index.md", `---
+title: Bundle
+---
+
+![Sunset](sunset.jpg)
+
+![Global](/images/sunset.jpg)
+
+![Remote](https://example.org/sunset.jpg)
+
+![Missing](missing.jpg)
+`)
+	b.WithSunset("content/mybundle/sunset.jpg")
+	b.WithSunset("assets/images/sunset.jpg")
+
+	b.Build(BuildCfg{})
+
+	b.AssertFileContent("public/mybundle/index.html",
+		`/mybundle/sunset.jpg|/mybundle/sunset_hu59e56ffff1bc1d8d122b1403d34e039f_90587_100x0_resize_q75_box.jpg 100w|/mybundle/sunset_hu59e56ffff1bc1d8d122b1403d34e039f_90587_50x50_fill_q75_box_smart1.jpg 50w|`,
+		`/images/sunset.jpg|/images/sunset_hu59e56ffff1bc1d8d122b1403d34e039f_90587_100x0_resize_q75_box.jpg 100w|`,
+		`https://example.org/sunset.jpg|no resource`,
+		`missing.jpg|no resource|Resolved: false`,
+	)
+
+	b = newTestSitesBuilder(t).WithConfigFile("toml", config)
+	b.WithTemplates("_default/single.html", `{{ .Content }}`)
+	b.WithTemplates("_default/_markup/render-image.html", `{{ .Renditions "foo" }}`)
+	b.WithContent("p1.md", "![Sunset](sunset.jpg)")
+	b.Assert(b.BuildE(BuildCfg{}), qt.ErrorMatches, `.*image rendition set "foo" not found in imaging config`)
+}
@@ -0,0 +1,392 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gohugoio/hugo/common/herrors"
+	"github.com/gohugoio/hugo/common/jsonschema"
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/gohugoio/hugo/parser/metadecoders"
+	"github.com/gohugoio/hugo/source"
+	toml "github.com/pelletier/go-toml"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"github.com/spf13/cast"
+)
+
+const dataSchemasConfigKey = "dataSchemas"
+
+// dataSchema is a JSON Schema used to validate the data files in a
+// directory below /data.
+type dataSchema struct {
+	// The schema file, relative to the project directory.
+	filename string
+	schema   *jsonschema.Schema
+}
+
+// dataSchemas maps a path below /data, e.g. "products" or "/" for all
+// files, to the schema used to validate the data files in it.
+type dataSchemas map[string]dataSchema
+
+// loadDataSchemas loads the schemas set in the dataSchemas config.
+func (h *HugoSites) loadDataSchemas() (dataSchemas, error) {
+	if !h.Cfg.IsSet(dataSchemasConfigKey) {
+		return nil, nil
+	}
+
+	schemas := make(dataSchemas)
+	for dir, v := range h.Cfg.GetStringMap(dataSchemasConfigKey) {
+		filename, err := cast.ToStringE(v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: invalid schema filename for %q", dataSchemasConfigKey, dir)
+		}
+		if filename == "" {
+			continue
+		}
+
+		b, err := afero.ReadFile(h.Fs.Source, filepath.Join(h.WorkingDir, filename))
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: failed to read schema for %q", dataSchemasConfigKey, dir)
+		}
+		format := metadecoders.FormatFromString(filepath.Ext(filename))
+		if format == "" {
+			return nil, errors.Errorf("%s: unsupported schema format %q, must be JSON, YAML or TOML", dataSchemasConfigKey, filename)
+		}
+		sm, err := metadecoders.Default.UnmarshalToMap(b, format)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: failed to decode schema %q", dataSchemasConfigKey, filename)
+		}
+		schema, err := jsonschema.Compile(sm)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: invalid schema %q", dataSchemasConfigKey, filename)
+		}
+
+		schemas[normalizeDataSchemaPath(dir)] = dataSchema{filename: filename, schema: schema}
+	}
+
+	return schemas, nil
+}
+
+func normalizeDataSchemaPath(s string) string {
+	return strings.ToLower(strings.Trim(filepath.ToSlash(s), "/"))
+}
+
+// get returns the schema for the data file with the given path below /data,
+// without its extension, e.g. "products/shirts". The closest match wins.
+func (s dataSchemas) get(filename string) (dataSchema, bool) {
+	if len(s) == 0 {
+		return dataSchema{}, false
+	}
+	p := normalizeDataSchemaPath(filename)
+	for {
+		if schema, found := s[p]; found {
+			return schema, true
+		}
+		if p == "" {
+			return dataSchema{}, false
+		}
+		p = path.Dir(p)
+		if p == "." {
+			p = ""
+		}
+	}
+}
+
+// validate validates the data in the file r against its schema, if any.
+// The returned error points to the line of the first invalid value.
+func (s dataSchemas) validate(h *HugoSites, mountRoot string, r source.File, data interface{}) error {
+	basePath := path.Join(filepath.ToSlash(filepath.Join(mountRoot, r.Dir())), r.BaseFileName())
+	schema, found := s.get(basePath)
+	if !found {
+		return nil
+	}
+
+	errs := schema.schema.Validate(data)
+	if len(errs) == 0 {
+		return nil
+	}
+
+	msg := fmt.Sprintf("data: %q does not match the schema %q: %s", r.Path(), schema.filename, errs[0])
+	if len(errs) > 1 {
+		msg += fmt.Sprintf(" (and %d more)", len(errs)-1)
+	}
+
+	format := metadecoders.FormatFromString(r.Extension())
+	line := 1
+	if f, err := r.FileInfo().Meta().Open(); err == nil {
+		line = dataLineNumber(helpers.ReaderToBytes(f), format, errs[0].Path)
+		f.Close()
+	}
+
+	return herrors.WithCode(herrors.CodeData, h.errWithFileContext(herrors.NewFileError(string(format), -1, line, 1, errors.New(msg)), r))
+}
+
+// dataLineNumber returns the line number of the value at path in content,
+// or the closest parent that could be found. It returns 1 if none is found.
+func dataLineNumber(content []byte, format metadecoders.Format, path []string) int {
+	var line int
+	switch format {
+	case metadecoders.JSON:
+		line = jsonLineNumber(content, path)
+	case metadecoders.TOML:
+		line = tomlLineNumber(content, path)
+	case metadecoders.YAML:
+		line = yamlLineNumber(content, path)
+	}
+	if line < 1 {
+		return 1
+	}
+	return line
+}
+
+func jsonLineNumber(content []byte, keys []string) int {
+	dec := json.NewDecoder(bytes.NewReader(content))
+
+	var (
+		offset int64
+		match  int
+	)
+
+	// skipValue reads the rest of the value started with tok.
+	skipValue := func(tok json.Token) error {
+		if d, ok := tok.(json.Delim); !ok || (d != '{' && d != '[') {
+			return nil
+		}
+		for depth := 1; depth > 0; {
+			t, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			if d, ok := t.(json.Delim); ok {
+				switch d {
+				case '{', '[':
+					depth++
+				default:
+					depth--
+				}
+			}
+		}
+		return nil
+	}
+
+	tok, err := dec.Token()
+
+walk:
+	for err == nil && match < len(keys) {
+		d, ok := tok.(json.Delim)
+		if !ok {
+			break
+		}
+		key := keys[match]
+		switch d {
+		case '{':
+			for dec.More() {
+				var kt json.Token
+				if kt, err = dec.Token(); err != nil {
+					break walk
+				}
+				keyOffset := dec.InputOffset()
+				if tok, err = dec.Token(); err != nil {
+					break walk
+				}
+				if kt == key {
+					offset = keyOffset
+					match++
+					continue walk
+				}
+				if err = skipValue(tok); err != nil {
+					break walk
+				}
+			}
+			break walk
+		case '[':
+			i, aerr := strconv.Atoi(key)
+			if aerr != nil {
+				break walk
+			}
+			for j := 0; dec.More(); j++ {
+				if tok, err = dec.Token(); err != nil {
+					break walk
+				}
+				if j == i {
+					offset = dec.InputOffset()
+					match++
+					continue walk
+				}
+				if err = skipValue(tok); err != nil {
+					break walk
+				}
+			}
+			break walk
+		default:
+			break walk
+		}
+	}
+
+	if offset == 0 {
+		return 1
+	}
+	return bytes.Count(content[:offset], []byte("\n")) + 1
+}
+
+func tomlLineNumber(content []byte, keys []string) int {
+	tree, err := toml.LoadBytes(content)
+	if err != nil {
+		return 1
+	}
+
+	line := 1
+	var current interface{} = tree
+	for _, key := range keys {
+		switch v := current.(type) {
+		case *toml.Tree:
+			if !v.Has(key) {
+				return line
+			}
+			if pos := v.GetPosition(key); pos.Line > 0 {
+				line = pos.Line
+			}
+			current = v.GetPath([]string{key})
+		case []*toml.Tree:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(v) {
+				return line
+			}
+			if pos := v[i].Position(); pos.Line > 0 {
+				line = pos.Line
+			}
+			current = v[i]
+		default:
+			return line
+		}
+	}
+
+	return line
+}
+
+var yamlKeyRe = regexp.MustCompile(`^(\s*)(-\s+)?(?:"([^"]*)"|'([^']*)'|([^\s#'":][^:#]*?))\s*:(\s|$)`)
+
+// yamlLineNumber finds the line of the value at keys in YAML content. YAML
+// has no position information in the decoded data, so this is based on
+// the indentation of block mappings and sequences.
+func yamlLineNumber(content []byte, keys []string) int {
+	type yamlLine struct {
+		number int
+		indent int
+		text   string
+	}
+
+	var lines []yamlLine
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for i := 1; scanner.Scan(); i++ {
+		text := scanner.Text()
+		trimmed := strings.TrimSpace(text)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || trimmed == "---" {
+			continue
+		}
+		lines = append(lines, yamlLine{number: i, indent: len(text) - len(strings.TrimLeft(text, " ")), text: text})
+	}
+
+	var (
+		line = 1
+		// The lines of the current value.
+		start, end = 0, len(lines)
+	)
+
+	for _, key := range keys {
+		if i, err := strconv.Atoi(key); err == nil {
+			// Find the i-th item in the sequence, indented as the first.
+			itemIndent, count, found := -1, 0, false
+			for j := start; j < end && !found; j++ {
+				l := lines[j]
+				if !isYAMLSequenceItem(l.text) {
+					continue
+				}
+				if itemIndent == -1 {
+					itemIndent = l.indent
+				}
+				if l.indent != itemIndent {
+					continue
+				}
+				if count < i {
+					count++
+					continue
+				}
+				found = true
+				line = l.number
+				k := j + 1
+				for k < end && lines[k].indent > l.indent {
+					k++
+				}
+				// The item starts on the dash line, e.g. "- name: Shirt".
+				lines[j].text = strings.Replace(l.text, "-", " ", 1)
+				start, end = j, k
+			}
+			if !found {
+				return line
+			}
+			continue
+		}
+
+		found := false
+		keyIndent := -1
+		for j := start; j < end; j++ {
+			l := lines[j]
+			m := yamlKeyRe.FindStringSubmatch(l.text)
+			if m == nil {
+				continue
+			}
+			ind := len(m[1]) + len(m[2])
+			if keyIndent == -1 {
+				keyIndent = ind
+			}
+			if ind != keyIndent {
+				continue
+			}
+			name := m[3] + m[4] + m[5]
+			if name != key {
+				continue
+			}
+			line = l.number
+			// A block sequence may have the same indentation as its key.
+			k := j + 1
+			for k < end && (lines[k].indent > ind || lines[k].indent == ind && isYAMLSequenceItem(lines[k].text)) {
+				k++
+			}
+			start, end = j+1, k
+			found = true
+			break
+		}
+		if !found {
+			return line
+		}
+	}
+
+	return line
+}
+
+func isYAMLSequenceItem(s string) bool {
+	s = strings.TrimSpace(s)
+	return s == "-" || strings.HasPrefix(s, "- ")
+}
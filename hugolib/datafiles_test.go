@@ -20,7 +20,9 @@ import (
 	"runtime"
 	"testing"
 
+	"github.com/gohugoio/hugo/common/herrors"
 	"github.com/gohugoio/hugo/common/loggers"
+	"github.com/gohugoio/hugo/parser/metadecoders"
 
 	"github.com/gohugoio/hugo/deps"
 
@@ -410,3 +412,107 @@ Slogan from shortcode: {{< d >}}
 	c.Assert(content, qt.Contains, "Slogan from template: Hugo Rocks!")
 	c.Assert(content, qt.Contains, "Slogan from shortcode: Hugo Rocks!")
 }
+
+func TestDataSchemas(t *testing.T) {
+	t.Parallel()
+
+	const schema = `{
+  "type": "array",
+  "items": {
+    "type": "object",
+    "required": ["name", "price"],
+    "properties": {
+      "name": {"type": "string"},
+      "price": {"type": "number", "minimum": 0}
+    }
+  }
+}`
+
+	newBuilder := func(t testing.TB, filename, content string) *sitesBuilder {
+		return newTestSitesBuilder(t).WithConfigFile("toml", `
+baseURL = "https://example.org"
+[dataSchemas]
+products = "schemas/products.json"
+`).WithSourceFile(
+			"schemas/products.json", schema,
+			"data/products/"+filename, content,
+		).WithTemplatesAdded("index.html", `{{ range .Site.Data.products }}{{ range . }}{{ .name }}: {{ .price }}|{{ end }}{{ end }}`)
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		b := newBuilder(t, "shirts.json", `[{"name": "Blue", "price": 32.5}, {"name": "Red", "price": 30}]`)
+		b.Build(BuildCfg{})
+		b.AssertFileContent("public/index.html", "Blue: 32.5|Red: 30|")
+	})
+
+	for _, test := range []struct {
+		filename string
+		content  string
+		line     int
+		message  string
+	}{
+		{"shirts.json", `[
+  {"name": "Blue", "price": 32.5},
+  {
+    "name": "Red",
+    "price": "30"
+  }
+]`, 5, `/1/price: must be of type number, got string`},
+		{"shirts.yaml", `- name: Blue
+  price: 32.5
+- name: Red
+  price: -1
+  size: L
+`, 4, `/1/price: must be >= 0, got -1`},
+		{"shirts.toml", `[[products]]
+name = "Blue"
+price = 32.5
+`, 1, `/: must be of type array, got object`},
+		{"hats.yaml", `- name: Blue
+  price: 32.5
+- price: 1
+- price: 2
+  name: 3
+`, 3, `/1/name: is required (and 1 more)`},
+	} {
+		test := test
+		t.Run(test.filename, func(t *testing.T) {
+			c := qt.New(t)
+			b := newBuilder(t, test.filename, test.content)
+			err := b.BuildE(BuildCfg{})
+			c.Assert(err, qt.Not(qt.IsNil))
+			c.Assert(err.Error(), qt.Contains, fmt.Sprintf(`does not match the schema "schemas/products.json": %s`, test.message))
+			fe := herrors.UnwrapErrorWithFileContext(err)
+			c.Assert(fe, qt.Not(qt.IsNil))
+			c.Assert(fe.Position().LineNumber, qt.Equals, test.line)
+			c.Assert(herrors.ErrorCode(err), qt.Equals, herrors.CodeData)
+		})
+	}
+
+	t.Run("Invalid schema", func(t *testing.T) {
+		c := qt.New(t)
+		b := newBuilder(t, "shirts.json", `[]`).WithSourceFile("schemas/products.json", `{"type": "foo"}`)
+		c.Assert(b.BuildE(BuildCfg{}), qt.ErrorMatches, `.*dataSchemas: invalid schema "schemas/products.json".*invalid type "foo"`)
+	})
+}
+
+func TestDataLineNumber(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range []struct {
+		format  metadecoders.Format
+		content string
+		path    []string
+		line    int
+	}{
+		{metadecoders.JSON, "{\n\"a\": {\n  \"b\": [1,\n 2, 3]\n}}", []string{"a", "b", "1"}, 4},
+		{metadecoders.JSON, "{\n\"a\": {\n  \"b\": [1,\n 2, 3]\n}}", []string{"a", "c"}, 2},
+		{metadecoders.TOML, "title = \"foo\"\n[a]\nb = 1\n[[c]]\nd = 1\n[[c]]\nd = 2\n", []string{"c", "1", "d"}, 7},
+		{metadecoders.TOML, "title = \"foo\"\n[a]\nb = 1\n", []string{"a", "b"}, 3},
+		{metadecoders.YAML, "a:\n  b: 1\n  # comment\n  c:\n  - d: 1\n  - e: 2\n    d: 3\nf: 4\n", []string{"a", "c", "1", "d"}, 7},
+		{metadecoders.YAML, "a:\n  b: 1\nf: 4\n", []string{"f"}, 3},
+		{metadecoders.YAML, "\"a b\":\n  - 1\n  - 2\n", []string{"a b", "1"}, 3},
+	} {
+		c.Assert(dataLineNumber([]byte(test.content), test.format, test.path), qt.Equals, test.line, qt.Commentf("%s %v", test.format, test.path))
+	}
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/gohugoio/hugo/common/loggers"
 
 	"github.com/gohugoio/hugo/hugofs/files"
+	"github.com/gohugoio/hugo/hugofs/glob"
 
 	"github.com/pkg/errors"
 
@@ -88,6 +89,19 @@ func (fs *BaseFs) AllDirs() []hugofs.FileMetaInfo {
 	return dirs
 }
 
+// FollowSymlinkedDirs reports whether symlinked directories below filename
+// should be followed according to the symlink policy of its mount.
+func (b *BaseFs) FollowSymlinkedDirs(filename string) bool {
+	for _, dir := range b.AllDirs() {
+		meta := dir.Meta()
+		dirname := meta.Filename()
+		if filename == dirname || strings.HasPrefix(filename, strings.TrimSuffix(dirname, filePathSeparator)+filePathSeparator) {
+			return meta.SymlinkPolicy() == hugofs.SymlinkPolicyFollow
+		}
+	}
+	return false
+}
+
 // RelContentDir tries to create a path relative to the content root from
 // the given filename. The return value is the path and language code.
 func (b *BaseFs) RelContentDir(filename string) string {
@@ -583,9 +597,26 @@ func (b *sourceFilesystemsBuilder) createModFs(
 
 		rm.Meta["lang"] = lang
 
+		inclusionFilter, err := glob.NewFilenameFilter(mount.IncludeFiles, mount.ExcludeFiles)
+		if err != nil {
+			return err
+		}
+		if inclusionFilter != nil {
+			rm.Meta["inclusionFilter"] = inclusionFilter
+		}
+
+		isStaticMount := b.isStaticMount(mount)
+
+		symlinkPolicy := mount.Symlinks
+		if symlinkPolicy == "" {
+			symlinkPolicy = hugofs.DefaultSymlinkPolicy(md.isMainProject, isStaticMount)
+		}
+		rm.Meta["symlinkPolicy"] = symlinkPolicy
+		rm.Fs = hugofs.NewSymlinkPolicyFs(collector.sourceProject, symlinkPolicy, b.logger)
+
 		if isContentMount {
 			fromToContent = append(fromToContent, rm)
-		} else if b.isStaticMount(mount) {
+		} else if isStaticMount {
 			fromToStatic = append(fromToStatic, rm)
 		} else {
 			fromTo = append(fromTo, rm)
@@ -596,7 +627,6 @@ func (b *sourceFilesystemsBuilder) createModFs(
 	if !md.isMainProject {
 		modBase = collector.sourceModules
 	}
-	sourceStatic := hugofs.NewNoSymlinkFs(modBase, b.logger, true)
 
 	rmfs, err := hugofs.NewRootMappingFs(modBase, fromTo...)
 	if err != nil {
@@ -606,7 +636,7 @@ func (b *sourceFilesystemsBuilder) createModFs(
 	if err != nil {
 		return err
 	}
-	rmfsStatic, err := hugofs.NewRootMappingFs(sourceStatic, fromToStatic...)
+	rmfsStatic, err := hugofs.NewRootMappingFs(modBase, fromToStatic...)
 	if err != nil {
 		return err
 	}
@@ -217,6 +217,33 @@ func TestNewBaseFsEmpty(t *testing.T) {
 	c.Assert(bfs.Static, qt.Not(qt.IsNil))
 }
 
+func TestFollowSymlinkedDirs(t *testing.T) {
+	c := qt.New(t)
+	v := createConfig()
+	v.Set("workingDir", "/work")
+	v.Set("module", map[string]interface{}{
+		"mounts": []interface{}{
+			map[string]interface{}{"source": "mycontent", "target": "content"},
+			map[string]interface{}{"source": "mycontent2", "target": "assets", "symlinks": "ignore"},
+		},
+	})
+	fs := hugofs.NewMem(v)
+	c.Assert(fs.Source.MkdirAll(filepath.FromSlash("/work/mycontent"), 0755), qt.IsNil)
+	c.Assert(fs.Source.MkdirAll(filepath.FromSlash("/work/mycontent2"), 0755), qt.IsNil)
+	c.Assert(initConfig(fs.Source, v), qt.IsNil)
+
+	p, err := paths.New(fs, v)
+	c.Assert(err, qt.IsNil)
+	bfs, err := NewBase(p, nil)
+	c.Assert(err, qt.IsNil)
+
+	c.Assert(bfs.FollowSymlinkedDirs(filepath.FromSlash("/work/mycontent")), qt.IsTrue)
+	c.Assert(bfs.FollowSymlinkedDirs(filepath.FromSlash("/work/mycontent/blog")), qt.IsTrue)
+	// Not in mycontent, even if the path has that prefix.
+	c.Assert(bfs.FollowSymlinkedDirs(filepath.FromSlash("/work/mycontent2/blog")), qt.IsFalse)
+	c.Assert(bfs.FollowSymlinkedDirs(filepath.FromSlash("/work/other")), qt.IsFalse)
+}
+
 func TestRealDirs(t *testing.T) {
 	c := qt.New(t)
 	v := createConfig()
@@ -17,18 +17,22 @@ import (
 	"path/filepath"
 	"strings"
 
-	"github.com/bep/gitmap"
 	"github.com/gohugoio/hugo/config"
 	"github.com/gohugoio/hugo/resources/page"
+	"github.com/gohugoio/hugo/source"
 )
 
 type gitInfo struct {
 	contentDir string
-	repo       *gitmap.GitRepo
+	repo       *source.GitRepo
 }
 
-func (g *gitInfo) forPage(p page.Page) *gitmap.GitInfo {
-	name := strings.TrimPrefix(filepath.ToSlash(p.File().Filename()), g.contentDir)
+func (g *gitInfo) forPage(p page.Page) *source.GitInfo {
+	return g.forFilename(p.File().Filename())
+}
+
+func (g *gitInfo) forFilename(filename string) *source.GitInfo {
+	name := strings.TrimPrefix(filepath.ToSlash(filename), g.contentDir)
 	name = strings.TrimPrefix(name, "/")
 
 	return g.repo.Files[name]
@@ -37,7 +41,7 @@ func (g *gitInfo) forPage(p page.Page) *gitmap.GitInfo {
 func newGitInfo(cfg config.Provider) (*gitInfo, error) {
 	workingDir := cfg.GetString("workingDir")
 
-	gitRepo, err := gitmap.Map(workingDir, "")
+	gitRepo, err := source.MapGitRepo(workingDir)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,49 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"testing"
+)
+
+func TestHTMLPostProcess(t *testing.T) {
+	t.Parallel()
+
+	b := newTestSitesBuilder(t).WithConfigFile("toml", `
+baseURL = "https://example.org"
+disableKinds = ["RSS", "sitemap", "taxonomy", "term", "section"]
+[minify]
+minifyOutput = true
+[[htmlPostProcess]]
+selector = "a[href^='https://']"
+attributes = { rel = "noopener", target = "_blank" }
+[[htmlPostProcess]]
+selector = "img"
+paths = ["posts/**"]
+attributes = { loading = "lazy" }
+`)
+
+	b.WithContent("posts/p1.md", "---\ntitle: p1\n---\n[Hugo](https://gohugo.io/) ![Logo](logo.png)")
+	b.WithTemplates(
+		"index.html", `<body>    <a href="/posts/p1/">P1</a>    <a href="https://example.com/">External</a> <img src="x.png"></body>`,
+		"_default/single.html", `<body>{{ .Content }}</body>`,
+	)
+	b.Build(BuildCfg{})
+
+	// The HTML is minified after the post-processing.
+	b.AssertFileContent("public/index.html", `<body><a href=/posts/p1/>P1</a>
+<a href=https://example.com/ rel=noopener target=_blank>External</a>
+<img src=x.png></body>`)
+	b.AssertFileContent("public/posts/p1/index.html", `<a href=https://gohugo.io/ rel=noopener target=_blank>Hugo</a>`, `<img src=logo.png alt=Logo loading=lazy>`)
+}
@@ -16,6 +16,8 @@ package hugolib
 import (
 	"fmt"
 	"math/rand"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
@@ -787,6 +789,73 @@ weight = 2
 	}
 }
 
+func TestMountsSymlinkPolicy(t *testing.T) {
+	skipSymlink(t)
+
+	wd, _ := os.Getwd()
+	defer func() {
+		os.Chdir(wd)
+	}()
+
+	c := qt.New(t)
+
+	for _, test := range []struct {
+		policy     string
+		expect     string
+		expectFail bool
+	}{
+		{"follow", "symmod", false},
+		{"ignore", "", false},
+		{"error", "", true},
+	} {
+		cfg := config.New()
+		fs := hugofs.NewFrom(hugofs.Os, cfg)
+
+		workDir, clean, err := htesting.CreateTempDir(hugofs.Os, "hugo-mod-sym-policy")
+		c.Assert(err, qt.IsNil)
+
+		themeDir := filepath.Join(workDir, "themes", "mymod")
+		realDir := filepath.Join(themeDir, "shared")
+		c.Assert(os.MkdirAll(realDir, 0777), qt.IsNil)
+		c.Assert(os.MkdirAll(filepath.Join(themeDir, "data"), 0777), qt.IsNil)
+		c.Assert(afero.WriteFile(fs.Source, filepath.Join(realDir, "data.toml"), []byte("[hello]\nother = \"hello\""), 0777), qt.IsNil)
+		c.Assert(os.MkdirAll(filepath.Join(workDir, "layouts"), 0777), qt.IsNil)
+		c.Assert(afero.WriteFile(fs.Source, filepath.Join(workDir, "layouts", "index.html"), []byte("Data: {{ .Site.Data }}"), 0777), qt.IsNil)
+		c.Assert(afero.WriteFile(fs.Source, filepath.Join(themeDir, "config.toml"), []byte(fmt.Sprintf(`
+[module]
+[[module.mounts]]
+source="data"
+target="data"
+symlinks=%q
+`, test.policy)), 0777), qt.IsNil)
+
+		c.Assert(os.Chdir(filepath.Join(themeDir, "data")), qt.IsNil)
+		c.Assert(os.Symlink(filepath.Join("..", "shared"), "realsymmod"), qt.IsNil)
+
+		b := newTestSitesBuilder(t).WithNothingAdded().WithWorkingDir(workDir)
+		b.WithLogger(loggers.NewErrorLogger())
+		b.Fs = fs
+		b.WithConfigFile("toml", `
+baseURL = "https://example.com"
+theme="mymod"
+`)
+		c.Assert(os.Chdir(workDir), qt.IsNil)
+
+		err = b.BuildE(BuildCfg{})
+		if test.expectFail {
+			c.Assert(err, qt.Not(qt.IsNil), qt.Commentf(test.policy))
+			c.Assert(err.Error(), qt.Contains, "symlink policy")
+		} else {
+			c.Assert(err, qt.IsNil, qt.Commentf(test.policy))
+			b.AssertFileContentFn(filepath.Join("public", "index.html"), func(s string) bool {
+				return strings.Contains(s, "realsymmod") == (test.expect != "")
+			})
+		}
+
+		clean()
+	}
+}
+
 func TestMountsProject(t *testing.T) {
 	t.Parallel()
 
@@ -816,6 +885,62 @@ title: "My Page"
 	b.AssertFileContent("public/mypage/index.html", "Permalink: https://example.org/mypage/")
 }
 
+func TestMountsURL(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("name,price\nbook,32\n"))
+	}))
+	defer srv.Close()
+
+	config := fmt.Sprintf(`
+baseURL="https://example.org"
+[caches.modules]
+dir = "/cache/modules"
+[module]
+[[module.mounts]]
+url=%q
+target="data/shop/prices.csv"
+refresh="1h"
+`, srv.URL)
+
+	b := newTestSitesBuilder(t).
+		WithConfigFile("toml", config).
+		WithTemplatesAdded("index.html", `{{ range site.Data.shop.prices }}{{ index . 0 }}: {{ index . 1 }}|{{ end }}`)
+
+	b.Build(BuildCfg{})
+
+	b.AssertFileContent("public/index.html", "name: price|book: 32|")
+}
+
+func TestMountsIncludeExcludeFiles(t *testing.T) {
+	t.Parallel()
+
+	config := `
+
+baseURL="https://example.org"
+
+[module]
+[[module.mounts]]
+source="mycontent"
+target="content"
+includeFiles="blog/**"
+excludeFiles=["**/draft.md"]
+
+`
+	b := newTestSitesBuilder(t).
+		WithConfigFile("toml", config).
+		WithSourceFile(filepath.Join("mycontent", "blog", "mypage.md"), "---\ntitle: \"My Page\"\n---\n").
+		WithSourceFile(filepath.Join("mycontent", "blog", "draft.md"), "---\ntitle: \"Draft\"\n---\n").
+		WithSourceFile(filepath.Join("mycontent", "docs", "mydoc.md"), "---\ntitle: \"My Doc\"\n---\n")
+
+	b.Build(BuildCfg{})
+
+	b.AssertFileContent("public/blog/mypage/index.html", "Permalink: https://example.org/blog/mypage/")
+	b.Assert(b.CheckExists("public/blog/draft/index.html"), qt.Equals, false)
+	b.Assert(b.CheckExists("public/docs/mydoc/index.html"), qt.Equals, false)
+}
+
 // https://github.com/gohugoio/hugo/issues/6684
 func TestMountsContentFile(t *testing.T) {
 	t.Parallel()
@@ -32,12 +32,13 @@ import (
 	"github.com/gohugoio/hugo/parser/metadecoders"
 
 	"github.com/gohugoio/hugo/common/para"
+	"github.com/gohugoio/hugo/common/types"
 	"github.com/gohugoio/hugo/hugofs"
+	"github.com/gohugoio/hugo/hugofs/files"
 	"github.com/pkg/errors"
 
 	"github.com/gohugoio/hugo/source"
 
-	"github.com/bep/gitmap"
 	"github.com/gohugoio/hugo/config"
 
 	"github.com/gohugoio/hugo/publisher"
@@ -96,6 +97,13 @@ type HugoSites struct {
 	workers    *para.Workers
 	numWorkers int
 
+	buildHooks            []BuildHooks
+	hasPageRenderedHooks  bool
+	configLoadedHooksDone bool
+
+	// The errors collected in the last build, see BuildErrors.
+	buildErrors []error
+
 	*fatalErrorHandler
 	*testCounters
 }
@@ -189,7 +197,7 @@ func (h *HugoSites) Data() map[string]interface{} {
 	return h.data
 }
 
-func (h *HugoSites) gitInfoForPage(p page.Page) (*gitmap.GitInfo, error) {
+func (h *HugoSites) gitInfoForPage(p page.Page) (*source.GitInfo, error) {
 	if _, err := h.init.gitInfo.Do(); err != nil {
 		return nil, err
 	}
@@ -201,6 +209,18 @@ func (h *HugoSites) gitInfoForPage(p page.Page) (*gitmap.GitInfo, error) {
 	return h.gitInfo.forPage(p), nil
 }
 
+func (h *HugoSites) gitInfoForFilename(filename string) (*source.GitInfo, error) {
+	if _, err := h.init.gitInfo.Do(); err != nil {
+		return nil, err
+	}
+
+	if h.gitInfo == nil {
+		return nil, nil
+	}
+
+	return h.gitInfo.forFilename(filename), nil
+}
+
 func (h *HugoSites) siteInfos() page.Sites {
 	infos := make(page.Sites, len(h.Sites))
 	for i, site := range h.Sites {
@@ -243,6 +263,14 @@ func (h *HugoSites) pickOneAndLogTheRest(errors []error) error {
 	return errors[i]
 }
 
+// LockBuild waits for any running rebuild to finish and blocks new
+// rebuilds until unlock is called. This is used by the server to read the
+// pages outside of a build.
+func (h *HugoSites) LockBuild() (unlock func()) {
+	h.runningMu.Lock()
+	return h.runningMu.Unlock
+}
+
 func (h *HugoSites) IsMultihost() bool {
 	return h != nil && h.multihost
 }
@@ -268,6 +296,10 @@ func (h *HugoSites) PrintProcessingStats(w io.Writer) {
 	for i := 0; i < len(h.Sites); i++ {
 		stats[i] = h.Sites[i].PathSpec.ProcessingStats
 	}
+	if loggers.IsJSON() {
+		helpers.ProcessingStatsJSON(w, stats...)
+		return
+	}
 	helpers.ProcessingStatsTable(w, stats...)
 }
 
@@ -292,6 +324,65 @@ func (h *HugoSites) GetContentPage(filename string) page.Page {
 	return p
 }
 
+// GetContentPages finds the pages for the given absolute filename, which can
+// be a content file or a resource in a page bundle, in which case the pages
+// of the bundle are returned. There is one page per language the file is
+// rendered in, with the pages in the file's own language first.
+// Returns nil if none found.
+func (h *HugoSites) GetContentPages(filename string) page.Pages {
+	var (
+		pages    page.Pages
+		owners   page.Pages
+		ownerDir string
+		dir      = filepath.Dir(filename)
+	)
+
+	h.getContentMaps().walkBundles(func(b *contentNode) bool {
+		if b.p == nil || b.fi == nil {
+			return false
+		}
+
+		bfilename := b.fi.Meta().Filename()
+		if bfilename == filename {
+			pages = append(pages, b.p)
+			return false
+		}
+
+		bdir := filepath.Dir(bfilename)
+		var owns bool
+		switch b.p.BundleType() {
+		case files.ContentClassLeaf:
+			owns = dir == bdir || strings.HasPrefix(dir, bdir+helpers.FilePathSeparator)
+		case files.ContentClassBranch:
+			// Content files in a branch bundle are pages of their own.
+			owns = dir == bdir && !files.IsContentFile(filename)
+		}
+
+		if owns {
+			// The innermost bundle owns the resource.
+			if len(bdir) > len(ownerDir) {
+				owners = nil
+				ownerDir = bdir
+			}
+			if bdir == ownerDir {
+				owners = append(owners, b.p)
+			}
+		}
+
+		return false
+	})
+
+	if pages == nil {
+		pages = owners
+	}
+
+	sort.SliceStable(pages, func(i, j int) bool {
+		return !pages[i].IsFallback() && pages[j].IsFallback()
+	})
+
+	return pages
+}
+
 // NewHugoSites creates a new collection of sites given the input sites, building
 // a language configuration based on those.
 func newHugoSites(cfg deps.DepsCfg, sites ...*Site) (*HugoSites, error) {
@@ -381,6 +472,10 @@ func newHugoSites(cfg deps.DepsCfg, sites ...*Site) (*HugoSites, error) {
 
 	h.Deps = sites[0].Deps
 
+	if h.Plugins != nil {
+		h.AddBuildHooks(newPluginBuildHooks(h.Plugins))
+	}
+
 	// Only needed in server mode.
 	// TODO(bep) clean up the running vs watching terms
 	if cfg.Running {
@@ -460,6 +555,7 @@ func (l configLoader) applyDeps(cfg deps.DepsCfg, sites ...*Site) error {
 					taxonomyDisabled:     !s.isEnabled(page.KindTerm),
 					taxonomyTermDisabled: !s.isEnabled(page.KindTaxonomy),
 					pageDisabled:         !s.isEnabled(page.KindPage),
+					fallbackLanguages:    s.fallbackLanguages(),
 				}),
 				s: s,
 			}
@@ -674,9 +770,30 @@ type BuildCfg struct {
 	// Recently visited URLs. This is used for partial re-rendering.
 	RecentlyVisited map[string]bool
 
+	// If set, the build is canceled when Context is done: no more pages
+	// are rendered, and Build returns the context's error.
+	Context context.Context
+
 	testCounters *testCounters
 }
 
+// canceled returns the error of the build's context, if done.
+func (cfg *BuildCfg) canceled() error {
+	if cfg.Context == nil {
+		return nil
+	}
+	return cfg.Context.Err()
+}
+
+// done returns a channel that's closed when the build's context is done.
+// It's never closed if there is no context.
+func (cfg *BuildCfg) done() <-chan struct{} {
+	if cfg.Context == nil {
+		return nil
+	}
+	return cfg.Context.Done()
+}
+
 // shouldRender is used in the Fast Render Mode to determine if we need to re-render
 // a Page: If it is recently visited (the home pages will always be in this set) or changed.
 // Note that a page does not have to have a content page / file.
@@ -687,6 +804,18 @@ func (cfg *BuildCfg) shouldRender(p *pageState) bool {
 		return true
 	}
 
+	if cfg.whatChanged != nil && cfg.whatChanged.terms != nil && (p.Kind() == page.KindTerm || p.Kind() == page.KindTaxonomy) {
+		if !p.File().IsZero() && cfg.whatChanged.files[p.File().Filename()] {
+			return true
+		}
+		if p.treeRef != nil && p.treeRef.n.viewInfo != nil {
+			vi := p.treeRef.n.viewInfo
+			if !cfg.whatChanged.terms[termsKey(p.Lang(), vi.name.plural, vi.termKey)] {
+				return false
+			}
+		}
+	}
+
 	if len(cfg.RecentlyVisited) == 0 {
 		return true
 	}
@@ -702,6 +831,58 @@ func (cfg *BuildCfg) shouldRender(p *pageState) bool {
 	return false
 }
 
+// shouldRenderFormat reports whether pages in the output format f need to
+// be rendered. This will always return true for regular builds.
+func (cfg *BuildCfg) shouldRenderFormat(f output.Format) bool {
+	if cfg.whatChanged == nil || cfg.whatChanged.formats == nil {
+		return true
+	}
+	return cfg.whatChanged.formats[f.Name]
+}
+
+// termsKey returns the key used in whatChanged.terms for the given
+// taxonomy and term. Use an empty termKey for the taxonomy itself.
+func termsKey(lang, plural, termKey string) string {
+	return lang + "/" + plural + "/" + termKey
+}
+
+// addTemplateOutputFormats adds the names of the output formats in all
+// sites that can use the template with the given name to formats. It
+// returns false if the template may be used by any output format.
+func (h *HugoSites) addTemplateOutputFormats(name string, formats map[string]bool) bool {
+	for _, s := range h.Sites {
+		f, ok := templateOutputFormats(name, s.outputFormatsConfig)
+		if !ok {
+			return false
+		}
+		for k := range f {
+			formats[k] = true
+		}
+	}
+	return true
+}
+
+// addTermsForFiles adds the taxonomies and terms (see termsKey) of the
+// pages in the given content files to terms.
+func (h *HugoSites) addTermsForFiles(filenames map[string]bool, terms map[string]bool) {
+	h.getContentMaps().walkBundles(func(n *contentNode) bool {
+		p := n.p
+		if p == nil || p.File().IsZero() || !filenames[p.File().Filename()] {
+			return false
+		}
+
+		for _, viewName := range p.s.pageMap.cfg.taxonomyConfig {
+			vals := types.ToStringSlicePreserveString(getParam(p, viewName.plural, false))
+			for _, v := range vals {
+				terms[termsKey(p.Lang(), viewName.plural, "")] = true
+				terms[termsKey(p.Lang(), viewName.plural, p.s.getTaxonomyKey(v))] = true
+			}
+		}
+
+		return false
+	})
+}
+
 func (h *HugoSites) renderCrossSitesSitemap() error {
 	if !h.multilingual.enabled() || h.IsMultihost() {
 		return nil
@@ -818,6 +999,11 @@ func (h *HugoSites) Pages() page.Pages {
 func (h *HugoSites) loadData(fis []hugofs.FileMetaInfo) (err error) {
 	spec := source.NewSourceSpec(h.PathSpec, nil)
 
+	schemas, err := h.loadDataSchemas()
+	if err != nil {
+		return err
+	}
+
 	h.data = make(map[string]interface{})
 	for _, fi := range fis {
 		fileSystem := spec.NewFilesystemFromFileMetaInfo(fi)
@@ -825,8 +1011,10 @@ func (h *HugoSites) loadData(fis []hugofs.FileMetaInfo) (err error) {
 		if err != nil {
 			return err
 		}
+		// The mount target may be below /data, e.g. /data/shop.
+		mountRoot := fi.Meta().MountRoot()
 		for _, r := range files {
-			if err := h.handleDataFile(r); err != nil {
+			if err := h.handleDataFile(schemas, mountRoot, r); err != nil {
 				return err
 			}
 		}
@@ -835,7 +1023,7 @@ func (h *HugoSites) loadData(fis []hugofs.FileMetaInfo) (err error) {
 	return
 }
 
-func (h *HugoSites) handleDataFile(r source.File) error {
+func (h *HugoSites) handleDataFile(schemas dataSchemas, mountRoot string, r source.File) error {
 	var current map[string]interface{}
 
 	f, err := r.FileInfo().Meta().Open()
@@ -846,7 +1034,7 @@ func (h *HugoSites) handleDataFile(r source.File) error {
 
 	// Crawl in data tree to insert data
 	current = h.data
-	keyParts := strings.Split(r.Dir(), helpers.FilePathSeparator)
+	keyParts := strings.Split(filepath.Join(mountRoot, r.Dir()), helpers.FilePathSeparator)
 
 	for _, key := range keyParts {
 		if key != "" {
@@ -859,13 +1047,17 @@ func (h *HugoSites) handleDataFile(r source.File) error {
 
 	data, err := h.readData(r)
 	if err != nil {
-		return h.errWithFileContext(err, r)
+		return h.errWithFileContext(herrors.WithCode(herrors.CodeData, err), r)
 	}
 
 	if data == nil {
 		return nil
 	}
 
+	if err := schemas.validate(h, mountRoot, r, data); err != nil {
+		return err
+	}
+
 	// filepath.Walk walks the files in lexical order, '/' comes before '.'
 	higherPrecedentData := current[r.BaseFileName()]
 
@@ -897,7 +1089,7 @@ func (h *HugoSites) handleDataFile(r source.File) error {
 				"higher precedence %T data already in the data tree", data, r.Path(), higherPrecedentData)
 		}
 
-	case []interface{}:
+	case []interface{}, [][]string:
 		if higherPrecedentData == nil {
 			current[r.BaseFileName()] = data
 		} else {
@@ -23,10 +23,14 @@ import (
 	"runtime/trace"
 	"strings"
 
+	"github.com/gohugoio/hugo/metrics"
 	"github.com/gohugoio/hugo/publisher"
 
 	"github.com/gohugoio/hugo/hugofs"
+	"github.com/gohugoio/hugo/hugofs/files"
+	"github.com/gohugoio/hugo/modules"
 
+	"github.com/gohugoio/hugo/a11y"
 	"github.com/gohugoio/hugo/common/para"
 	"github.com/gohugoio/hugo/config"
 	"github.com/gohugoio/hugo/resources/postpub"
@@ -43,7 +47,7 @@ import (
 
 // Build builds all sites. If filesystem events are provided,
 // this is considered to be a potential partial rebuild.
-func (h *HugoSites) Build(config BuildCfg, events ...fsnotify.Event) error {
+func (h *HugoSites) Build(config BuildCfg, events ...fsnotify.Event) (err error) {
 	if h.running {
 		// Make sure we don't trigger rebuilds in parallel.
 		h.runningMu.Lock()
@@ -57,6 +61,16 @@ func (h *HugoSites) Build(config BuildCfg, events ...fsnotify.Event) error {
 	ctx, task := trace.NewTask(context.Background(), "Build")
 	defer task.End()
 
+	buildSpan := h.Tracer.StartBuild("build", "hugo.rebuild", len(events) > 0, "hugo.sites", len(h.Sites))
+	defer func() {
+		buildSpan.SetError(err)
+		buildSpan.End()
+		h.exportTrace()
+		if err := h.writeErrorsFile(); err != nil {
+			h.Log.Warnln(err)
+		}
+	}()
+
 	errCollector := h.StartErrorCollector()
 	errs := make(chan error)
 
@@ -70,6 +84,7 @@ func (h *HugoSites) Build(config BuildCfg, events ...fsnotify.Event) error {
 			}
 			errors = append(errors, e)
 		}
+		h.buildErrors = errors
 		to <- h.pickOneAndLogTheRest(errors)
 
 		close(to)
@@ -79,6 +94,10 @@ func (h *HugoSites) Build(config BuildCfg, events ...fsnotify.Event) error {
 		h.Metrics.Reset()
 	}
 
+	if h.PageMetrics != nil {
+		h.PageMetrics.Reset()
+	}
+
 	h.testCounters = config.testCounters
 
 	// Need a pointer as this may be modified.
@@ -93,6 +112,14 @@ func (h *HugoSites) Build(config BuildCfg, events ...fsnotify.Event) error {
 
 	if !config.PartialReRender {
 		prepare := func() error {
+			if err := h.runConfigLoadedHooks(); err != nil {
+				return err
+			}
+
+			if err := h.runModuleHooks(modules.HookStagePre); err != nil {
+				return err
+			}
+
 			init := func(conf *BuildCfg) error {
 				for _, s := range h.Sites {
 					s.Deps.BuildStartListeners.Notify()
@@ -115,15 +142,23 @@ func (h *HugoSites) Build(config BuildCfg, events ...fsnotify.Event) error {
 			var err error
 
 			f := func() {
-				err = h.process(conf, init, events...)
+				err = h.tracePhase("process", func() error {
+					return h.process(conf, init, events...)
+				})
 			}
 			trace.WithRegion(ctx, "process", f)
 			if err != nil {
 				return errors.Wrap(err, "process")
 			}
 
+			if err := conf.canceled(); err != nil {
+				return err
+			}
+
 			f = func() {
-				err = h.assemble(conf)
+				err = h.tracePhase("assemble", func() error {
+					return h.assemble(conf)
+				})
 			}
 			trace.WithRegion(ctx, "assemble", f)
 			if err != nil {
@@ -137,24 +172,49 @@ func (h *HugoSites) Build(config BuildCfg, events ...fsnotify.Event) error {
 			prepareErr = prepare()
 		}
 		trace.WithRegion(ctx, "prepare", f)
-		if prepareErr != nil {
+		if prepareErr != nil && conf.canceled() == nil {
 			h.SendError(prepareErr)
 		}
 
 	}
 
 	if prepareErr == nil {
-		var err error
+		var (
+			err    error
+			failed bool
+		)
 		f := func() {
-			err = h.render(conf)
+			err = h.tracePhase("render", func() error {
+				return h.render(conf)
+			})
 		}
 		trace.WithRegion(ctx, "render", f)
-		if err != nil {
+		if conf.canceled() != nil {
+			// Nothing more to do.
+			failed = true
+		} else if err != nil {
 			h.SendError(err)
+			failed = true
 		}
 
-		if err = h.postProcess(); err != nil {
-			h.SendError(err)
+		if conf.canceled() == nil {
+			if err = h.tracePhase("postProcess", h.postProcess); err != nil {
+				h.SendError(err)
+				failed = true
+			}
+		}
+
+		if !config.PartialReRender && conf.canceled() == nil {
+			if err = h.runModuleHooks(modules.HookStagePost); err != nil {
+				h.SendError(err)
+				failed = true
+			}
+		}
+
+		if !failed {
+			if err = h.runSitePublishedHooks(); err != nil {
+				h.SendError(err)
+			}
 		}
 	}
 
@@ -166,6 +226,22 @@ func (h *HugoSites) Build(config BuildCfg, events ...fsnotify.Event) error {
 		h.Log.Println(b.String())
 	}
 
+	if h.PageMetrics != nil {
+		var b bytes.Buffer
+		h.PageMetrics.WriteMetrics(&b, h.Cfg.GetInt("pageMetricsCount"))
+
+		h.Log.Printf("\nPage Metrics:\n\n")
+		h.Log.Println(b.String())
+	}
+
+	if h.Cfg.GetBool("debugMemory") {
+		var b bytes.Buffer
+		h.writeMemoryReport(&b)
+
+		h.Log.Printf("\nMemory Report:\n\n")
+		h.Log.Println(b.String())
+	}
+
 	select {
 	// Make sure the channel always gets something.
 	case errCollector <- nil:
@@ -173,7 +249,12 @@ func (h *HugoSites) Build(config BuildCfg, events ...fsnotify.Event) error {
 	}
 	close(errCollector)
 
-	err := <-errs
+	err = <-errs
+
+	if err := conf.canceled(); err != nil {
+		return err
+	}
+
 	if err != nil {
 		return err
 	}
@@ -190,9 +271,48 @@ func (h *HugoSites) Build(config BuildCfg, events ...fsnotify.Event) error {
 	return nil
 }
 
+// runModuleHooks runs the hooks declared by the active modules for the
+// given stage, see modules.Hook.
+func (h *HugoSites) runModuleHooks(stage string) error {
+	client := h.PathSpec.ModulesClient
+	if client == nil {
+		return nil
+	}
+
+	return client.RunHooks(
+		context.Background(),
+		stage,
+		h.PathSpec.AllModules,
+		"HUGO_ENVIRONMENT", h.Cfg.GetString("environment"),
+		"HUGO_PUBLISH_DIR", h.PathSpec.AbsPublishDir,
+	)
+}
+
 // Build lifecycle methods below.
 // The order listed matches the order of execution.
 
+// tracePhase runs f in a span for the build phase name.
+func (h *HugoSites) tracePhase(name string, f func() error) error {
+	span := h.Tracer.StartPhase(name)
+	err := f()
+	span.SetError(err)
+	span.End()
+	return err
+}
+
+// exportTrace sends the build trace, if enabled, to the configured endpoint.
+// A failed export does not fail the build.
+func (h *HugoSites) exportTrace() {
+	dropped, err := h.Tracer.Export(context.Background())
+	if err != nil {
+		h.Log.Warnln(err)
+		return
+	}
+	if dropped > 0 {
+		h.Log.Warnf("Build trace: dropped %d spans, the maximum number of spans in a build was reached.", dropped)
+	}
+}
+
 func (h *HugoSites) initSites(config *BuildCfg) error {
 	h.reset(config)
 
@@ -259,6 +379,15 @@ func (h *HugoSites) assemble(bcfg *BuildCfg) error {
 		return nil
 	}
 
+	if h.Progress != nil {
+		var total int
+		for _, pm := range h.getContentMaps().pmaps {
+			total += pm.pages.Len()
+		}
+		progress := h.Progress.Start(metrics.ProgressPhaseAssemble, total)
+		defer progress.End()
+	}
+
 	if err := h.getContentMaps().AssemblePages(); err != nil {
 		return err
 	}
@@ -267,6 +396,11 @@ func (h *HugoSites) assemble(bcfg *BuildCfg) error {
 		return err
 	}
 
+	if bcfg.whatChanged.terms != nil {
+		// Add the terms of the changed pages after the change.
+		h.addTermsForFiles(bcfg.whatChanged.files, bcfg.whatChanged.terms)
+	}
+
 	return nil
 }
 
@@ -277,10 +411,18 @@ func (h *HugoSites) render(config *BuildCfg) error {
 
 	siteRenderContext := &siteRenderContext{cfg: config, multihost: h.multihost}
 
+	if h.PageMetrics != nil {
+		h.addPageMetricsBundles()
+	}
+
+	memoryBudget := h.ResourceSpec.Memory
+
 	if !config.PartialReRender {
 		h.renderFormats = output.Formats{}
 		h.withSite(func(s *Site) error {
 			s.initRenderFormats()
+			s.renderCache = newRenderCache(s)
+			s.relatedTerms = newRelatedTermsCache(s)
 			return nil
 		})
 
@@ -299,6 +441,8 @@ func (h *HugoSites) render(config *BuildCfg) error {
 			select {
 			case <-h.Done():
 				return nil
+			case <-config.done():
+				return config.Context.Err()
 			default:
 				for _, s2 := range h.Sites {
 					// We render site by site, but since the content is lazily rendered
@@ -311,6 +455,14 @@ func (h *HugoSites) render(config *BuildCfg) error {
 					}
 				}
 
+				if i == 1 && !config.PartialReRender {
+					// Run the hooks when the pages are ready for the first
+					// output format, so the URLs are set.
+					if err := h.runPagesAssembledHooks(); err != nil {
+						return err
+					}
+				}
+
 				if !config.SkipRender {
 					if config.PartialReRender {
 						if err := s.renderPages(siteRenderContext); err != nil {
@@ -321,12 +473,19 @@ func (h *HugoSites) render(config *BuildCfg) error {
 							return err
 						}
 					}
+					h.evictRenderedContent(memoryBudget.Pages)
 				}
 			}
 
 		}
 	}
 
+	if err := h.withSite(func(s *Site) error {
+		return errors.Wrap(s.relatedTerms.save(), "failed to save related terms")
+	}); err != nil {
+		return err
+	}
+
 	if !config.SkipRender {
 		if err := h.renderCrossSitesSitemap(); err != nil {
 			return err
@@ -334,6 +493,9 @@ func (h *HugoSites) render(config *BuildCfg) error {
 		if err := h.renderCrossSitesRobotsTXT(); err != nil {
 			return err
 		}
+		if err := h.renderCrossSitesAliasRedirects(); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -346,6 +508,10 @@ func (h *HugoSites) postProcess() error {
 		return err
 	}
 
+	if err := h.writeA11yReport(); err != nil {
+		return err
+	}
+
 	// This will only be set when js.Build have been triggered with
 	// imports that resolves to the project or a module.
 	// Write a jsconfig.json file to the project's /asset directory
@@ -506,3 +672,60 @@ func (h *HugoSites) writeBuildStats() error {
 
 	return nil
 }
+
+// writeA11yReport logs the accessibility issues found in the published pages
+// and writes them to hugo_a11y.json. It fails if there are issues at or above
+// the configured failOn severity.
+func (h *HugoSites) writeA11yReport() error {
+	cfg, ok := h.Cfg.Get("a11yConfig").(a11y.Config)
+	if !ok || !cfg.Enable {
+		return nil
+	}
+
+	var report a11y.Report
+	for _, s := range h.Sites {
+		report.Pages = append(report.Pages, s.publisher.A11yReport().Pages...)
+	}
+
+	for _, p := range report.Pages {
+		for _, issue := range p.Issues {
+			h.Log.Warnf("a11y: %s:%s", p.Path, issue)
+		}
+	}
+
+	js, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	filename := filepath.Join(h.WorkingDir, "hugo_a11y.json")
+
+	if err := afero.WriteFile(h.Fs.Source, filename, js, 0666); err != nil {
+		return err
+	}
+
+	if cfg.FailOn != "" {
+		if n := report.Count(cfg.FailOn); n > 0 {
+			return errors.Errorf("a11y: found %d issue(s) with severity %s or higher", n, cfg.FailOn)
+		}
+	}
+
+	return nil
+}
+
+// addPageMetricsBundles registers the page bundle directories so image
+// processing can be attributed to the owning page in the page metrics.
+func (h *HugoSites) addPageMetricsBundles() {
+	for _, p := range h.Pages() {
+		ps, ok := p.(*pageState)
+		if !ok || ps.File().IsZero() {
+			continue
+		}
+		bundleType := ps.BundleType()
+		if bundleType != files.ContentClassLeaf && bundleType != files.ContentClassBranch {
+			continue
+		}
+		dir := filepath.Dir(ps.File().FileInfo().Meta().Filename())
+		h.PageMetrics.AddBundleDir(dir, ps.metricsKey(), bundleType == files.ContentClassLeaf)
+	}
+}
@@ -794,6 +794,63 @@ categories: ["mycat"]
 	}
 }
 
+func TestMultiSitesFallbackLanguages(t *testing.T) {
+	b := newTestSitesBuilder(t)
+	b.WithConfigFile("toml", `
+baseURL = "http://example.com/"
+defaultContentLanguage = "en"
+defaultContentLanguageInSubdir = true
+
+[languages]
+[languages.en]
+weight = 10
+[languages.fr]
+weight = 20
+[languages.ca]
+weight = 30
+fallbackLanguages = ["fr", "en"]
+`)
+
+	b.WithContent(
+		"docs/_index.en.md", "---\ntitle: Docs EN\n---",
+		"docs/p1.en.md", "---\ntitle: P1 EN\n---",
+		"docs/p1.fr.md", "---\ntitle: P1 FR\n---",
+		"docs/p2.en.md", "---\ntitle: P2 EN\n---",
+		"docs/p3.ca.md", "---\ntitle: P3 CA\n---",
+		"docs/p3.en.md", "---\ntitle: P3 EN\n---",
+		"docs/p4/index.en.md", "---\ntitle: P4 EN\n---",
+		"docs/p4/data.txt", "data",
+	)
+
+	b.WithTemplates(
+		"_default/single.html", `{{ .Title }}|{{ .Lang }}|Fallback: {{ .IsFallback }}|{{ .File.Lang }}|{{ range .Resources }}{{ .RelPermalink }}{{ end }}|{{ range .Translations }}{{ .Lang }};{{ end }}`,
+		"_default/list.html", `{{ .Title }}|{{ range .Pages }}{{ .RelPermalink }}:{{ .IsFallback }};{{ end }}`,
+	)
+
+	b.Build(BuildCfg{})
+
+	b.AssertFileContent("public/ca/docs/p1/index.html", "P1 FR|ca|Fallback: true|fr|")
+	b.AssertFileContent("public/ca/docs/p2/index.html", "P2 EN|ca|Fallback: true|en|")
+	b.AssertFileContent("public/ca/docs/p3/index.html", "P3 CA|ca|Fallback: false|ca|")
+	b.AssertFileContent("public/ca/docs/p4/index.html", "P4 EN|ca|Fallback: true|en|/ca/docs/p4/data.txt|")
+	b.AssertFileContent("public/en/docs/p2/index.html", "P2 EN|en|Fallback: false|en||ca;")
+	b.AssertFileContent("public/ca/docs/index.html", "/ca/docs/p1/:true;", "/ca/docs/p3/:false;")
+
+	// Sections are not rendered from a fallback language.
+	b.AssertFileContent("public/ca/docs/index.html", "Docs|")
+
+	// Only the languages with a fallback chain get the missing pages.
+	b.Assert(b.CheckExists("public/fr/docs/p2/index.html"), qt.Equals, false)
+
+	b = newTestSitesBuilder(t)
+	b.WithConfigFile("toml", `
+[languages]
+[languages.en]
+fallbackLanguages = ["de"]
+`)
+	b.Assert(b.CreateSitesE(), qt.ErrorMatches, `.*invalid fallback language "de" for language "en"`)
+}
+
 // https://github.com/gohugoio/hugo/issues/5777
 func TestTableOfContentsInShortcodes(t *testing.T) {
 	t.Parallel()
@@ -1466,3 +1523,46 @@ func TestRebuildOnAssetChange(t *testing.T) {
 	b.Build(BuildCfg{})
 	b.AssertFileContent("public/index.html", `changed data`)
 }
+
+func TestGetContentPages(t *testing.T) {
+	b := newTestSitesBuilder(t)
+	b.WithConfigFile("toml", `
+baseURL = "http://example.com/"
+defaultContentLanguage = "en"
+
+[languages]
+[languages.en]
+weight = 10
+[languages.fr]
+weight = 20
+[languages.ca]
+weight = 30
+fallbackLanguages = ["en"]
+`)
+
+	b.WithContent(
+		"docs/_index.md", "---\ntitle: Docs\n---",
+		"docs/branch.txt", "branch",
+		"docs/p1.md", "---\ntitle: P1\n---",
+		"docs/p1.fr.md", "---\ntitle: P1 FR\n---",
+		"docs/p2/index.md", "---\ntitle: P2\n---",
+		"docs/p2/index.fr.md", "---\ntitle: P2 FR\n---",
+		"docs/p2/images/a.txt", "data",
+	)
+
+	b.Build(BuildCfg{})
+
+	relPermalinks := func(filename string) []string {
+		var s []string
+		for _, p := range b.H.GetContentPages(filepath.FromSlash(filename)) {
+			s = append(s, p.RelPermalink())
+		}
+		return s
+	}
+
+	b.Assert(relPermalinks("content/docs/p1.fr.md"), qt.DeepEquals, []string{"/fr/docs/p1/"})
+	b.Assert(relPermalinks("content/docs/p1.md"), qt.DeepEquals, []string{"/docs/p1/", "/ca/docs/p1/"})
+	b.Assert(relPermalinks("content/docs/p2/images/a.txt"), qt.DeepEquals, []string{"/docs/p2/", "/fr/docs/p2/", "/ca/docs/p2/"})
+	b.Assert(relPermalinks("content/docs/branch.txt"), qt.DeepEquals, []string{"/docs/"})
+	b.Assert(relPermalinks("content/docs/nope.md"), qt.IsNil)
+}
@@ -0,0 +1,104 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"io/fs"
+	"path/filepath"
+
+	"github.com/gohugoio/hugo/common/loggers"
+	"github.com/gohugoio/hugo/config"
+	"github.com/gohugoio/hugo/deps"
+	"github.com/gohugoio/hugo/hugofs"
+	"github.com/pkg/errors"
+)
+
+// FSConfig configures the sites created with NewHugoSitesFromFS.
+type FSConfig struct {
+	// The project to build, with the configuration file(s) or the config
+	// directory in its root.
+	FS fs.FS
+
+	// The directory the project is available below, used in file paths and
+	// errors. It does not need to exist. Defaults to /project.
+	WorkingDir string
+
+	// The environment, e.g. production or development. Defaults to production.
+	Environment string
+
+	// Configuration applied on top of the configuration in FS, e.g. baseURL.
+	Config map[string]interface{}
+
+	// Defaults to logging errors only.
+	Logger loggers.Logger
+}
+
+// NewHugoSitesFromFS creates the sites for the project in the file system
+// c.FS, which, as an example, can be an embed.FS or a fstest.MapFS. Nothing
+// is written to disk: the published site and any other files written, e.g.
+// to the file caches, are kept in memory, with the published site in the
+// root of Fs.Destination in the returned HugoSites.
+func NewHugoSitesFromFS(c FSConfig) (*HugoSites, error) {
+	if c.Logger == nil {
+		c.Logger = loggers.NewErrorLogger()
+	}
+
+	fs, cfg, err := LoadConfigFromFS(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewHugoSites(deps.DepsCfg{Fs: fs, Cfg: cfg, Logger: c.Logger})
+}
+
+// LoadConfigFromFS loads the configuration for the project in c.FS and
+// creates the file systems used by NewHugoSitesFromFS.
+func LoadConfigFromFS(c FSConfig) (*hugofs.Fs, config.Provider, error) {
+	if c.FS == nil {
+		return nil, nil, errors.New("no FS provided")
+	}
+
+	workingDir := c.WorkingDir
+	if workingDir == "" {
+		workingDir = filepath.FromSlash("/project")
+	}
+	if c.Logger == nil {
+		c.Logger = loggers.NewErrorLogger()
+	}
+
+	fsCfg := config.New()
+	fsCfg.Set("workingDir", workingDir)
+	fs := hugofs.NewFromFS(c.FS, fsCfg)
+
+	cfg, _, err := LoadConfig(ConfigSourceDescriptor{
+		Fs:           fs.Source,
+		Logger:       c.Logger,
+		WorkingDir:   workingDir,
+		AbsConfigDir: filepath.Join(workingDir, "config"),
+		Environment:  c.Environment,
+	}, func(cfg config.Provider) error {
+		for k, v := range c.Config {
+			cfg.Set(k, v)
+		}
+		cfg.Set("workingDir", workingDir)
+		// Publish to the root of the destination, as in hugo server.
+		cfg.Set("publishDir", "/")
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return fs, cfg, nil
+}
@@ -0,0 +1,59 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"testing"
+	"testing/fstest"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/spf13/afero"
+)
+
+func TestNewHugoSitesFromFS(t *testing.T) {
+	c := qt.New(t)
+
+	fsys := fstest.MapFS{
+		"config.toml": {Data: []byte(`
+baseURL = "https://example.org/"
+title = "From FS"
+theme = "mytheme"
+`)},
+		"config/production/params.toml":          {Data: []byte(`color = "blue"`)},
+		"content/posts/p1.md":                    {Data: []byte("---\ntitle: P1\n---\nHello **world**.")},
+		"assets/css/main.css":                    {Data: []byte("body {  color:   red; }")},
+		"layouts/_default/single.html":           {Data: []byte(`{{ .Title }}|{{ .Content }}|{{ site.Params.color }}|{{ site.BaseURL }}|{{ partial "p.html" . }}`)},
+		"layouts/_default/list.html":             {Data: []byte(`{{ $css := resources.Get "css/main.css" | minify | fingerprint }}{{ $css.RelPermalink }}|{{ range .Pages }}{{ .Title }}{{ end }}`)},
+		"themes/mytheme/layouts/partials/p.html": {Data: []byte(`From theme`)},
+	}
+
+	h, err := NewHugoSitesFromFS(FSConfig{
+		FS:     fsys,
+		Config: map[string]interface{}{"baseURL": "https://preview.example.org/"},
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(h.Build(BuildCfg{}), qt.IsNil)
+
+	read := func(filename string) string {
+		b, err := afero.ReadFile(h.Fs.Destination, filename)
+		c.Assert(err, qt.IsNil)
+		return string(b)
+	}
+
+	c.Assert(read("/posts/p1/index.html"), qt.Equals, "P1|<p>Hello <strong>world</strong>.</p>\n|blue|https://preview.example.org/|From theme")
+	c.Assert(read("/index.html"), qt.Matches, `/css/main.min.[0-9a-f]{64}.css\|Posts`)
+
+	_, err = NewHugoSitesFromFS(FSConfig{FS: fstest.MapFS{"config.toml": {Data: []byte("title = ")}}})
+	c.Assert(err, qt.Not(qt.IsNil))
+}
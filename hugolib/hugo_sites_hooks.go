@@ -0,0 +1,173 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"github.com/gohugoio/hugo/config"
+	"github.com/gohugoio/hugo/output"
+	"github.com/gohugoio/hugo/plugins"
+	"github.com/gohugoio/hugo/resources/page"
+	"github.com/pkg/errors"
+)
+
+// BuildHooks holds callbacks invoked at the stages of a build, e.g. to
+// validate the site or to export it somewhere. All of them are optional.
+// The values passed are read-only, and an error returned fails the build.
+type BuildHooks struct {
+	// Called before the first build with the site configuration.
+	OnConfigLoaded func(cfg config.Provider) error
+
+	// Called when the pages of all sites are assembled, before they are
+	// rendered. The URLs are those of the first output format. Not called for
+	// partial re-renders.
+	OnPagesAssembled func(pages page.Pages) error
+
+	// Called for every page rendered, before it is published to targetPath.
+	// This is called concurrently, and content must not be retained.
+	OnPageRendered func(p page.Page, f output.Format, targetPath string, content []byte) error
+
+	// Called when the build is done and all files are published to
+	// publishDir in the destination file system.
+	OnSitePublished func(publishDir string) error
+}
+
+// AddBuildHooks registers hooks to run in the builds of h.
+func (h *HugoSites) AddBuildHooks(hooks BuildHooks) {
+	h.buildHooks = append(h.buildHooks, hooks)
+	if hooks.OnPageRendered != nil {
+		h.hasPageRenderedHooks = true
+	}
+}
+
+func (h *HugoSites) runConfigLoadedHooks() error {
+	if h.configLoadedHooksDone {
+		return nil
+	}
+	h.configLoadedHooksDone = true
+	for _, hooks := range h.buildHooks {
+		if hooks.OnConfigLoaded == nil {
+			continue
+		}
+		if err := hooks.OnConfigLoaded(h.Cfg); err != nil {
+			return errors.Wrap(err, "OnConfigLoaded")
+		}
+	}
+	return nil
+}
+
+func (h *HugoSites) runPagesAssembledHooks() error {
+	for _, hooks := range h.buildHooks {
+		if hooks.OnPagesAssembled == nil {
+			continue
+		}
+		if err := hooks.OnPagesAssembled(h.Pages()); err != nil {
+			return errors.Wrap(err, "OnPagesAssembled")
+		}
+	}
+	return nil
+}
+
+func (h *HugoSites) runPageRenderedHooks(p page.Page, f output.Format, targetPath string, content []byte) error {
+	if !h.hasPageRenderedHooks {
+		return nil
+	}
+	for _, hooks := range h.buildHooks {
+		if hooks.OnPageRendered == nil {
+			continue
+		}
+		if err := hooks.OnPageRendered(p, f, targetPath, content); err != nil {
+			return errors.Wrap(err, "OnPageRendered")
+		}
+	}
+	return nil
+}
+
+func (h *HugoSites) runSitePublishedHooks() error {
+	for _, hooks := range h.buildHooks {
+		if hooks.OnSitePublished == nil {
+			continue
+		}
+		if err := hooks.OnSitePublished(h.PathSpec.AbsPublishDir); err != nil {
+			return errors.Wrap(err, "OnSitePublished")
+		}
+	}
+	return nil
+}
+
+// pluginConfigKeys are the configuration keys passed to the config_loaded
+// plugin hook.
+var pluginConfigKeys = []string{"baseURL", "title", "languageCode", "defaultContentLanguage", "environment", "params"}
+
+// newPluginBuildHooks creates the hooks running the build hooks implemented
+// by the WASM plugins in p.
+func newPluginBuildHooks(p *plugins.Plugins) BuildHooks {
+	var hooks BuildHooks
+
+	if p.HasHook(plugins.HookConfigLoaded) {
+		hooks.OnConfigLoaded = func(cfg config.Provider) error {
+			m := make(map[string]interface{})
+			for _, key := range pluginConfigKeys {
+				if cfg.IsSet(key) {
+					m[key] = cfg.Get(key)
+				}
+			}
+			return p.RunHook(plugins.HookContext{Hook: plugins.HookConfigLoaded, Config: m}, nil)
+		}
+	}
+
+	if p.HasHook(plugins.HookPagesAssembled) {
+		hooks.OnPagesAssembled = func(pages page.Pages) error {
+			hps := make([]plugins.HookPage, len(pages))
+			for i, pp := range pages {
+				hps[i] = newPluginHookPage(pp)
+			}
+			return p.RunHook(plugins.HookContext{Hook: plugins.HookPagesAssembled, Pages: hps}, nil)
+		}
+	}
+
+	if p.HasHook(plugins.HookPageRendered) {
+		hooks.OnPageRendered = func(pp page.Page, f output.Format, targetPath string, content []byte) error {
+			hp := newPluginHookPage(pp)
+			return p.RunHook(plugins.HookContext{
+				Hook:         plugins.HookPageRendered,
+				Page:         &hp,
+				OutputFormat: f.Name,
+				TargetPath:   targetPath,
+			}, content)
+		}
+	}
+
+	if p.HasHook(plugins.HookSitePublished) {
+		hooks.OnSitePublished = func(publishDir string) error {
+			return p.RunHook(plugins.HookContext{Hook: plugins.HookSitePublished, PublishDir: publishDir}, nil)
+		}
+	}
+
+	return hooks
+}
+
+func newPluginHookPage(p page.Page) plugins.HookPage {
+	return plugins.HookPage{
+		Kind:         p.Kind(),
+		Lang:         p.Lang(),
+		Path:         p.Path(),
+		Section:      p.Section(),
+		Title:        p.Title(),
+		Permalink:    p.Permalink(),
+		RelPermalink: p.RelPermalink(),
+		Draft:        p.Draft(),
+		Date:         p.Date(),
+		Params:       p.Params(),
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"sync/atomic"
+
+	"github.com/gohugoio/hugo/helpers"
+)
+
+// Number of entries listed in the memory report.
+const memoryReportTopN = 10
+
+// evictRenderedContent releases the rendered content of the least recently
+// used pages exceeding the given budget (see config.Memory). It must be
+// called between render passes, when no content is being rendered.
+func (h *HugoSites) evictRenderedContent(budget int) {
+	if budget <= 0 || h.running {
+		// In server mode the content is needed for partial rebuilds.
+		return
+	}
+
+	type usedPage struct {
+		p        *pageState
+		lastUsed uint64
+	}
+
+	var pages []usedPage
+	for _, s := range h.Sites {
+		s.pageMap.withEveryBundlePage(func(p *pageState) bool {
+			var (
+				hasContent bool
+				lastUsed   uint64
+			)
+			for _, po := range p.pageOutputs {
+				if po.cp != nil && po.cp.retainedSize() > 0 {
+					hasContent = true
+					if u := atomic.LoadUint64(&po.cp.lastUsed); u > lastUsed {
+						lastUsed = u
+					}
+				}
+			}
+			if hasContent {
+				pages = append(pages, usedPage{p: p, lastUsed: lastUsed})
+			}
+			return false
+		})
+	}
+
+	if len(pages) <= budget {
+		return
+	}
+
+	// Most recently used first.
+	sort.Slice(pages, func(i, j int) bool {
+		return pages[i].lastUsed > pages[j].lastUsed
+	})
+
+	for _, up := range pages[budget:] {
+		var evicted bool
+		for _, po := range up.p.pageOutputs {
+			if po.cp != nil && po.cp.evict() {
+				evicted = true
+			}
+		}
+		if evicted {
+			stats := up.p.s.PathSpec.ProcessingStats
+			stats.Incr(&stats.EvictedPages)
+		}
+	}
+}
+
+type retainedPage struct {
+	path string
+	lang string
+	size int
+}
+
+// writeMemoryReport writes a report of the memory usage and the biggest
+// objects retained in memory by the in-memory caches to w.
+func (h *HugoSites) writeMemoryReport(w io.Writer) {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+
+	fmt.Fprintf(w, "HeapAlloc = %s\nHeapInuse = %s\nSys = %s\nNumGC = %d\n\n",
+		helpers.FormatByteCount(m.HeapAlloc), helpers.FormatByteCount(m.HeapInuse), helpers.FormatByteCount(m.Sys), m.NumGC)
+
+	var (
+		pages     []retainedPage
+		totalSize int
+	)
+
+	for _, s := range h.Sites {
+		s.pageMap.withEveryBundlePage(func(p *pageState) bool {
+			var size int
+			seen := make(map[*pageContentOutput]bool)
+			for _, po := range p.pageOutputs {
+				if po.cp == nil || seen[po.cp] {
+					continue
+				}
+				seen[po.cp] = true
+				size += po.cp.retainedSize()
+			}
+			if size > 0 {
+				pages = append(pages, retainedPage{path: p.pathOrTitle(), lang: p.Lang(), size: size})
+				totalSize += size
+			}
+			return false
+		})
+	}
+
+	sort.SliceStable(pages, func(i, j int) bool {
+		return pages[i].size > pages[j].size
+	})
+
+	fmt.Fprintf(w, "Pages with rendered content in memory: %d (%s)\n", len(pages), helpers.FormatByteCount(uint64(totalSize)))
+	for i, p := range pages {
+		if i == memoryReportTopN {
+			break
+		}
+		fmt.Fprintf(w, "  %10s  %s (%s)\n", helpers.FormatByteCount(uint64(p.size)), p.path, p.lang)
+	}
+
+	fmt.Fprintln(w)
+	for _, s := range h.Sites {
+		fmt.Fprintf(w, "Processed images in memory (%s): %d\n", s.Lang(), s.ResourceSpec.ImageCacheEntries())
+	}
+
+	entries := h.Sites[0].ResourceSpec.ResourceCache.Entries()
+	var partitions []string
+	var total int
+	for k, v := range entries {
+		partitions = append(partitions, k)
+		total += v
+	}
+	sort.Slice(partitions, func(i, j int) bool {
+		if entries[partitions[i]] == entries[partitions[j]] {
+			return partitions[i] < partitions[j]
+		}
+		return entries[partitions[i]] > entries[partitions[j]]
+	})
+
+	fmt.Fprintf(w, "Resource cache entries in memory: %d\n", total)
+	for i, k := range partitions {
+		if i == memoryReportTopN {
+			break
+		}
+		fmt.Fprintf(w, "  %10d  %s\n", entries[k], k)
+	}
+}
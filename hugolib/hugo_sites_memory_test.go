@@ -0,0 +1,96 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestMemoryBudgets(t *testing.T) {
+	c := qt.New(t)
+
+	b := newTestSitesBuilder(t).WithConfigFile("toml", `
+baseURL = "https://example.org"
+[outputs]
+home = ["HTML", "JSON"]
+page = ["HTML", "JSON"]
+[memory]
+pages = 2
+resources = 1
+`)
+
+	for i := 1; i <= 5; i++ {
+		b.WithContent(fmt.Sprintf("p%d.md", i), fmt.Sprintf("---\ntitle: P%d\n---\nContent %d.", i, i))
+	}
+
+	b.WithTemplates(
+		"_default/single.html", `Single: {{ .Title }}|{{ .Content }}`,
+		"_default/single.json", `JSON: {{ .Title }}|{{ .Summary }}`,
+		"index.html", `Home: {{ range .RegularPages }}{{ .Title }}:{{ .Content }}|{{ end }}{{ $a := "a" | resources.FromString "a.txt" }}{{ $b := "b" | resources.FromString "b.txt" }}{{ $a.RelPermalink }}|{{ $b.RelPermalink }}`,
+		"index.json", `JSON Home: {{ range .RegularPages }}{{ .Title }}:{{ .Summary }}|{{ end }}`,
+	)
+
+	b.Build(BuildCfg{})
+
+	// All the content is still rendered correctly.
+	b.AssertFileContent("public/p3/index.html", "Single: P3|<p>Content 3.</p>")
+	b.AssertFileContent("public/p3/index.json", "JSON: P3|Content 3.")
+	b.AssertFileContent("public/index.html", "P1:<p>Content 1.</p>", "P5:<p>Content 5.</p>", "/a.txt|/b.txt")
+	b.AssertFileContent("public/index.json", "P5:Content 5.")
+
+	stats := b.H.Sites[0].PathSpec.ProcessingStats
+	c.Assert(stats.EvictedPages > 0, qt.IsTrue)
+	c.Assert(stats.EvictedResources > 0, qt.IsTrue)
+
+	var buf bytes.Buffer
+	b.H.PrintProcessingStats(&buf)
+	c.Assert(buf.String(), qt.Contains, "Evicted pages")
+
+	buf.Reset()
+	b.H.writeMemoryReport(&buf)
+	c.Assert(buf.String(), qt.Contains, "Pages with rendered content in memory: 2")
+	c.Assert(buf.String(), qt.Contains, "Resource cache entries in memory: 1")
+}
+
+func TestEvictRenderedContentLeastRecentlyUsed(t *testing.T) {
+	c := qt.New(t)
+
+	b := newTestSitesBuilder(t).WithSimpleConfigFile()
+	for i := 1; i <= 5; i++ {
+		b.WithContent(fmt.Sprintf("p%d.md", i), fmt.Sprintf("---\ntitle: P%d\n---\nContent %d.", i, i))
+	}
+	b.WithTemplates("_default/single.html", `{{ .Content }}`)
+	b.Build(BuildCfg{})
+
+	s := b.H.Sites[0]
+	for _, path := range []string{"/p4.md", "/p2.md"} {
+		p, err := s.getPageNew(nil, path)
+		c.Assert(err, qt.IsNil)
+		_, err = p.Content()
+		c.Assert(err, qt.IsNil)
+	}
+
+	b.H.evictRenderedContent(2)
+
+	for i := 1; i <= 5; i++ {
+		p, err := s.getPageNew(nil, fmt.Sprintf("/p%d.md", i))
+		c.Assert(err, qt.IsNil)
+		retained := p.(*pageState).pageOutput.cp.retainedSize() > 0
+		c.Assert(retained, qt.Equals, i == 2 || i == 4, qt.Commentf("p%d", i))
+	}
+}
@@ -314,3 +314,107 @@ P5 changed content
 P6 changed content
 `)
 }
+
+func TestRebuildTerms(t *testing.T) {
+	c := qt.New(t)
+
+	b := newTestSitesBuilder(t).WithConfigFile("toml", `
+baseURL = "https://example.com"
+disableKinds = ["RSS", "sitemap"]
+`).Running()
+
+	b.WithContent(
+		"p1.md", "---\ntitle: P1\ntags: [a, b]\n---\n",
+		"p2.md", "---\ntitle: P2\ntags: [c]\n---\n",
+	)
+
+	b.WithTemplates(
+		"index.html", `Home`,
+		"_default/single.html", `Single: {{ .Title }}`,
+		"_default/list.html", `List: {{ .Title }}|{{ range .Pages }}{{ .Title }}|{{ end }}`,
+		"_default/terms.html", `Terms: {{ range .Data.Terms.Alphabetical }}{{ .Page.Title }}:{{ .Count }}|{{ end }}`,
+	)
+
+	b.Build(BuildCfg{})
+
+	b.AssertFileContent("public/tags/a/index.html", "List: a|P1|")
+	b.AssertFileContent("public/tags/c/index.html", "List: c|P2|")
+
+	// Only the term pages listing P1, before and after the edit, and the
+	// tags page are rendered.
+	c.Assert(b.Fs.Destination.Remove("public/tags/c/index.html"), qt.IsNil)
+	b.EditFiles("content/p1.md", "---\ntitle: P1 Edited\ntags: [a, d]\n---\n")
+	b.Build(BuildCfg{})
+
+	b.AssertFileContent("public/tags/a/index.html", "List: a|P1 Edited|")
+	c.Assert(b.FileContent("public/tags/b/index.html"), qt.Equals, "List: b|")
+	b.AssertFileContent("public/tags/d/index.html", "List: d|P1 Edited|")
+	b.AssertFileContent("public/tags/index.html", "Terms: a:1|c:1|d:1|")
+	c.Assert(b.CheckExists("public/tags/c/index.html"), qt.IsFalse)
+
+	// In fast render mode, visited term pages not affected are skipped.
+	b.EditFiles("content/p1.md", "---\ntitle: P1 Edited Again\ntags: [a, d]\n---\n")
+	b.Build(BuildCfg{RecentlyVisited: map[string]bool{"/": true, "/tags/a/": true, "/tags/c/": true}})
+
+	b.AssertFileContent("public/tags/a/index.html", "List: a|P1 Edited Again|")
+	c.Assert(b.CheckExists("public/tags/c/index.html"), qt.IsFalse)
+
+	// Template changes re-render all of them.
+	b.EditFiles("layouts/_default/list.html", `List Edited: {{ .Title }}|{{ range .Pages }}{{ .Title }}|{{ end }}`)
+	b.Build(BuildCfg{})
+
+	b.AssertFileContent("public/tags/c/index.html", "List Edited: c|P2|")
+}
+
+func TestRebuildOutputFormats(t *testing.T) {
+	c := qt.New(t)
+
+	b := newTestSitesBuilder(t).WithConfigFile("toml", `
+baseURL = "https://example.com"
+disableKinds = ["sitemap", "taxonomy", "term"]
+[outputs]
+home = ["HTML", "RSS", "JSON"]
+`).Running()
+
+	b.WithContent("p1.md", "---\ntitle: P1\n---\n")
+
+	b.WithTemplates(
+		"index.html", `Home: {{ partial "title.html" . }}`,
+		"index.json", `{"title": "{{ .Title }}"}`,
+		"_default/rss.xml", `RSS: {{ .Title }}`,
+		"_default/single.html", `Single: {{ .Title }}`,
+		"partials/title.html", `{{ .Title }}`,
+	)
+
+	b.Build(BuildCfg{})
+
+	remove := func(filenames ...string) {
+		for _, filename := range filenames {
+			c.Assert(b.Fs.Destination.Remove(filename), qt.IsNil)
+		}
+	}
+
+	// Only the RSS output format is rendered.
+	remove("public/index.html", "public/index.json", "public/p1/index.html")
+	b.EditFiles("layouts/_default/rss.xml", `RSS Edited: {{ .Title }}`)
+	b.Build(BuildCfg{})
+
+	b.AssertFileContent("public/index.xml", "RSS Edited:")
+	c.Assert(b.CheckExists("public/index.html"), qt.IsFalse)
+	c.Assert(b.CheckExists("public/index.json"), qt.IsFalse)
+	c.Assert(b.CheckExists("public/p1/index.html"), qt.IsFalse)
+
+	// Only the HTML output format is rendered.
+	b.EditFiles("layouts/_default/single.html", `Single Edited: {{ .Title }}`)
+	b.Build(BuildCfg{})
+
+	b.AssertFileContent("public/p1/index.html", "Single Edited: P1")
+	b.AssertFileContent("public/index.html", "Home:")
+	c.Assert(b.CheckExists("public/index.json"), qt.IsFalse)
+
+	// Partials may be used by any output format.
+	b.EditFiles("layouts/partials/title.html", `Title: {{ .Title }}`)
+	b.Build(BuildCfg{})
+
+	b.AssertFileContent("public/index.json", `{"title":`)
+}
@@ -0,0 +1,110 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gohugoio/hugo/config"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+)
+
+// languageRedirectConfig configures the language negotiation for the site
+// root on multilingual sites.
+type languageRedirectConfig struct {
+	// Enables the language negotiation.
+	Enable bool
+
+	// The redirects file to add rules redirecting the site root from the
+	// Accept-Language header to, "netlify" or "apache".
+	// Defaults to aliases.redirects.
+	Redirects string
+
+	// The status code for the redirects, 301 or 302.
+	Status int
+}
+
+var defaultLanguageRedirectConfig = languageRedirectConfig{
+	Status: http.StatusFound,
+}
+
+func decodeLanguageRedirectConfig(cfg config.Provider, aliases aliasConfig) (languageRedirectConfig, error) {
+	c := defaultLanguageRedirectConfig
+	c.Redirects = aliases.Redirects
+
+	if !cfg.IsSet("languageRedirect") {
+		return c, nil
+	}
+
+	if err := mapstructure.WeakDecode(cfg.GetStringMap("languageRedirect"), &c); err != nil {
+		return c, errors.Wrap(err, "failed to decode languageRedirect config")
+	}
+
+	if c.Status != http.StatusMovedPermanently && c.Status != http.StatusFound {
+		return c, errors.Errorf("languageRedirect: unsupported status code %d, must be 301 or 302", c.Status)
+	}
+
+	c.Redirects = strings.ToLower(c.Redirects)
+	if _, found := aliasRedirectsFiles[c.Redirects]; !found && c.Redirects != "" {
+		return c, errors.Errorf("languageRedirect: unsupported redirects %q, must be netlify or apache", c.Redirects)
+	}
+
+	return c, nil
+}
+
+// languageRedirectRules returns the rules redirecting the site root to the
+// language homes from the Accept-Language header, in the format of the
+// redirects file for host.
+func (h *HugoSites) languageRedirectRules(host string) []string {
+	if !h.multilingual.enabled() || h.multihost {
+		return nil
+	}
+
+	s := h.Sites[0]
+	c := s.siteCfg.languageRedirect
+	root := s.PathSpec.RelURL("/", false)
+
+	var rules []string
+	for _, ss := range h.Sites {
+		if ss.home == nil {
+			continue
+		}
+		home := ss.home.RelPermalink()
+		if home == root {
+			// The main language is served from the root.
+			continue
+		}
+		lang := ss.Lang()
+
+		switch host {
+		case "apache":
+			if len(rules) == 0 {
+				rules = append(rules, "RewriteEngine On")
+			}
+			rules = append(rules,
+				fmt.Sprintf(`RewriteCond %%{HTTP:Accept-Language} ^%s\b [NC]`, regexp.QuoteMeta(lang)),
+				fmt.Sprintf("RewriteRule ^$ %s [R=%d,L]", home, c.Status),
+			)
+		default:
+			// Forced, the root always exists.
+			rules = append(rules, fmt.Sprintf("%s %s %d! Language=%s", root, home, c.Status, lang))
+		}
+	}
+
+	return rules
+}
@@ -0,0 +1,82 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"fmt"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestLanguageRedirect(t *testing.T) {
+	t.Parallel()
+
+	config := `
+baseURL = "https://example.org/"
+defaultContentLanguage = "en"
+defaultContentLanguageInSubdir = %t
+[languages]
+[languages.en]
+weight = 1
+[languages.fr]
+weight = 2
+[languageRedirect]
+enable = true
+redirects = %q
+`
+
+	newBuilder := func(t testing.TB, inSubdir bool, host string) *sitesBuilder {
+		b := newTestSitesBuilder(t)
+		b.WithConfigFile("toml", fmt.Sprintf(config, inSubdir, host))
+		b.WithTemplatesAdded("index.html", `{{ template "_internal/language_redirect.html" . }}|Home: {{ .Lang }}`)
+		return b
+	}
+
+	t.Run("In subdir", func(t *testing.T) {
+		b := newBuilder(t, true, "netlify")
+		b.Build(BuildCfg{})
+
+		b.AssertFileContent("public/index.html",
+			`var root = "/", langs = [{"lang":"en","url":"/en/"},{"lang":"fr","url":"/fr/"}], fallback = "/en/"`,
+			`<noscript><meta http-equiv="refresh" content="0; url=https://example.org/en/" /></noscript>`,
+		)
+		b.AssertFileContent("public/_redirects", "/ /en/ 302! Language=en\n/ /fr/ 302! Language=fr\n")
+	})
+
+	t.Run("In root", func(t *testing.T) {
+		b := newBuilder(t, false, "apache")
+		b.Build(BuildCfg{})
+
+		b.AssertFileContent("public/index.html",
+			`var root = "/", langs = [{"lang":"en","url":"/"},{"lang":"fr","url":"/fr/"}], fallback = ""`,
+			"|Home: en",
+		)
+		b.AssertFileContent("public/.htaccess", `RewriteEngine On
+RewriteCond %{HTTP:Accept-Language} ^fr\b [NC]
+RewriteRule ^$ /fr/ [R=302,L]
+`)
+		b.Assert(b.CheckExists("public/_redirects"), qt.Equals, false)
+	})
+
+	t.Run("Invalid status", func(t *testing.T) {
+		b := newTestSitesBuilder(t)
+		b.WithConfigFile("toml", `
+[languageRedirect]
+enable = true
+status = 410
+`)
+		b.Assert(b.CreateSitesE(), qt.ErrorMatches, ".*unsupported status code 410.*")
+	})
+}
@@ -53,6 +53,35 @@ weight = 1
 
 		b.AssertFileContent("public/index.html", "Hello: Hello")
 	})
+
+	c.Run("ICU and go-i18n precedence and fallback", func(c *qt.C) {
+		b := newTestSitesBuilder(c)
+		b.WithConfigFile(`toml`, `
+baseURL = "https://example.com"
+defaultContentLanguage = "en"
+theme = "mytheme"
+[languages]
+[languages.en]
+weight = 1
+[languages.fr]
+weight = 2
+`)
+
+		b.WithSourceFile("themes/mytheme/i18n/en.icu.toml", `
+hello = "Theme ICU hello, {name}"
+bye = "Theme ICU bye"
+icuonly = "ICU only, {name}"
+`)
+		b.WithSourceFile("themes/mytheme/i18n/en.toml", `thanks = "Theme thanks"`)
+		b.WithI18n("i18n/en.toml", `hello = "Project hello"`)
+		b.WithI18n("i18n/en.icu.toml", `thanks = "Project ICU thanks"`)
+		b.WithTemplates("index.html", `{{ i18n "hello" (dict "name" "Jo") }}|{{ i18n "bye" }}|{{ i18n "thanks" }}|{{ i18n "icuonly" (dict "name" "Jo") }}|`)
+		b.WithContent("p1.md", "")
+		b.Build(BuildCfg{})
+
+		b.AssertFileContent("public/index.html", "Project hello|Theme ICU bye|Project ICU thanks|ICU only, Jo|")
+		b.AssertFileContent("public/fr/index.html", "Project hello|Theme ICU bye|Project ICU thanks|ICU only, Jo|")
+	})
 }
 
 func TestLanguageBugs(t *testing.T) {
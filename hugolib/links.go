@@ -0,0 +1,175 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/gohugoio/hugo/resources/page"
+	"github.com/spf13/cast"
+)
+
+// PageLink is an internal link from one page to another.
+type PageLink struct {
+	From page.Page
+	To   page.Page
+}
+
+// LinkGraph is the graph of the internal links between the pages in a site,
+// found in their rendered content.
+type LinkGraph struct {
+	pages    page.Pages
+	outbound map[page.Page]page.Pages
+	inbound  map[page.Page]page.Pages
+}
+
+// Links returns all the links between the pages in the site.
+func (g *LinkGraph) Links() []PageLink {
+	var links []PageLink
+	for _, p := range g.pages {
+		for _, to := range g.outbound[p] {
+			links = append(links, PageLink{From: p, To: to})
+		}
+	}
+	return links
+}
+
+// Backlinks returns the pages that link to p.
+func (g *LinkGraph) Backlinks(p page.Page) page.Pages {
+	return g.inbound[p]
+}
+
+// OutboundLinks returns the pages p links to, in the order they are linked.
+func (g *LinkGraph) OutboundLinks(p page.Page) page.Pages {
+	return g.outbound[p]
+}
+
+// Orphans returns the regular pages no other page links to.
+func (g *LinkGraph) Orphans() page.Pages {
+	var orphans page.Pages
+	for _, p := range g.pages {
+		if p.IsPage() && len(g.inbound[p]) == 0 {
+			orphans = append(orphans, p)
+		}
+	}
+	return orphans
+}
+
+// LinkGraph returns the graph of the internal links between the pages in
+// the site. Note that this renders the content of all pages, so it cannot
+// be used from the content itself, e.g. in a shortcode.
+func (s *SiteInfo) LinkGraph() *LinkGraph {
+	return s.s.linkGraph()
+}
+
+// Backlinks returns the pages that link to this page.
+func (p *pageState) Backlinks() page.Pages {
+	return p.s.linkGraph().Backlinks(p)
+}
+
+// OutboundLinks returns the pages this page links to.
+func (p *pageState) OutboundLinks() page.Pages {
+	return p.s.linkGraph().OutboundLinks(p)
+}
+
+func (s *Site) linkGraph() *LinkGraph {
+	v, err := s.init.linkGraph.Do()
+	if err != nil {
+		s.h.FatalError(err)
+		return &LinkGraph{}
+	}
+	return v.(*LinkGraph)
+}
+
+var anchorHrefRe = regexp.MustCompile(`(?i)<a\s[^>]*?\bhref\s*=\s*["']([^"']+)["']`)
+
+func (s *Site) assembleLinkGraph() (*LinkGraph, error) {
+	pages := s.Pages()
+
+	g := &LinkGraph{
+		pages:    pages,
+		outbound: make(map[page.Page]page.Pages),
+		inbound:  make(map[page.Page]page.Pages),
+	}
+
+	byPath := make(map[string]page.Page)
+	for _, p := range pages {
+		if rel := p.RelPermalink(); rel != "" {
+			byPath[rel] = p
+		}
+	}
+
+	base := s.PathSpec.BaseURL.URL()
+
+	for _, p := range pages {
+		content, err := p.Content()
+		if err != nil {
+			return nil, err
+		}
+
+		from, err := url.Parse(p.RelPermalink())
+		if err != nil {
+			continue
+		}
+
+		seen := make(map[page.Page]bool)
+		for _, m := range anchorHrefRe.FindAllStringSubmatch(cast.ToString(content), -1) {
+			to := resolveLinkTarget(byPath, base, from, m[1])
+			if to == nil || to == p || seen[to] {
+				continue
+			}
+			seen[to] = true
+			g.outbound[p] = append(g.outbound[p], to)
+			g.inbound[to] = append(g.inbound[to], p)
+		}
+	}
+
+	for _, pages := range g.inbound {
+		page.SortByDefault(pages)
+	}
+
+	return g, nil
+}
+
+// resolveLinkTarget returns the page in byPath that href, found in the page
+// at from, links to, nil if none.
+func resolveLinkTarget(byPath map[string]page.Page, base, from *url.URL, href string) page.Page {
+	u, err := url.Parse(strings.TrimSpace(href))
+	if err != nil {
+		return nil
+	}
+
+	if u.Scheme != "" || u.Host != "" {
+		if u.Host != base.Host {
+			return nil
+		}
+	} else {
+		u = from.ResolveReference(u)
+	}
+
+	pth := u.Path
+	if pth == "" {
+		return nil
+	}
+
+	for _, candidate := range []string{pth, pth + "/", strings.TrimSuffix(pth, "index.html")} {
+		if p, found := byPath[candidate]; found {
+			return p
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"testing"
+)
+
+func TestLinkGraph(t *testing.T) {
+	b := newTestSitesBuilder(t).WithConfigFile("toml", `
+baseURL = "https://example.org/docs/"
+`)
+
+	b.WithContent(
+		"_index.md", "---\ntitle: Home\n---\n[Intro](/docs/intro/)",
+		"intro.md", "---\ntitle: Intro\n---\n[Setup](../setup/) [Setup again](https://example.org/docs/setup/#install) [Self](#top) [External](https://gohugo.io/)",
+		"setup.md", "---\ntitle: Setup\n---\n[Intro]({{< relref \"intro.md\" >}}) {{< link >}}",
+		"usage.md", "---\ntitle: Usage\n---\n[Setup]({{< ref \"setup.md\" >}})",
+		"orphan.md", "---\ntitle: Orphan\n---\nNo links.",
+	)
+	b.WithTemplatesAdded(
+		"shortcodes/link.html", `<a class="btn" href="/docs/usage/">Usage</a>`,
+		"_default/single.html", `{{ .Title }}|Backlinks: {{ range .Backlinks }}{{ .Title }}, {{ end }}|Outbound: {{ range .OutboundLinks }}{{ .Title }}, {{ end }}`,
+		"index.html", `Orphans: {{ range site.LinkGraph.Orphans }}{{ .Title }}, {{ end }}|Links: {{ range site.LinkGraph.Links }}{{ .From.Title }} => {{ .To.Title }}, {{ end }}`,
+	)
+
+	b.Build(BuildCfg{})
+
+	b.AssertFileContent("public/intro/index.html", "Intro|Backlinks: Home, Setup, |Outbound: Setup, ")
+	b.AssertFileContent("public/setup/index.html", "Setup|Backlinks: Intro, Usage, |Outbound: Intro, Usage, ")
+	b.AssertFileContent("public/usage/index.html", "Usage|Backlinks: Setup, |Outbound: Setup, ")
+	b.AssertFileContent("public/orphan/index.html", "Orphan|Backlinks: |Outbound: ")
+	b.AssertFileContent("public/index.html", "Orphans: Orphan, |", "Home => Intro, ", "Setup => Usage, ")
+}
@@ -397,3 +397,79 @@ menu:
 		"Main|P2: /blog/page2/|map[]",
 	)
 }
+
+func TestMenusFromData(t *testing.T) {
+	b := newTestSitesBuilder(t).WithConfigFile("toml", `
+baseURL = "https://example.org"
+defaultContentLanguage = "en"
+
+[languages]
+[languages.en]
+weight = 1
+[languages.fr]
+weight = 2
+
+[[languages.en.menus.main]]
+identifier = "about"
+name = "About from config"
+url = "/about/"
+weight = 1
+`)
+
+	b.WithSourceFile("data/menus/main.yaml", `
+- identifier: about
+  name: About from data
+  weight: 1
+- identifier: docs
+  name: Docs
+  url: /docs/
+  weight: 2
+  icon: book
+  children:
+    - name: Install
+      url: /docs/install/
+      weight: 1
+    - name: GitHub
+      url: https://github.com/gohugoio/hugo
+      external: true
+      weight: 2
+      params:
+        rel: noopener
+`, "data/menus/main.fr.yaml", `
+- identifier: docs
+  name: Documentation
+  url: /docs/
+  weight: 2
+- identifier: blog
+  name: Blog
+  url: /blog/
+  weight: 3
+`, "data/menus/footer.fr.yaml", `
+- name: Contact
+  url: /contact/
+`)
+
+	b.WithContent("_index.md", "---\ntitle: Home\n---")
+
+	b.WithTemplates("index.html", `
+{{ range .Site.Menus.main }}{{ .Name }}: {{ .URL }}|{{ with .Params.icon }}Icon: {{ . }}|{{ end }}{{ range .Children }}Child: {{ .Name }}|{{ .URL }}|{{ .Params.external }}|{{ .Params.rel }}|{{ end }}
+{{ end }}
+Footer: {{ with .Site.Menus.footer }}{{ len . }}{{ else }}0{{ end }}
+`)
+
+	b.Build(BuildCfg{})
+
+	b.AssertFileContent("public/index.html",
+		"About from config: /about/|",
+		"Docs: /docs/|Icon: book|Child: Install|/docs/install/|||Child: GitHub|https://github.com/gohugoio/hugo|true|noopener|",
+		"Footer: 0",
+	)
+
+	b.AssertFileContent("public/fr/index.html",
+		"About from data: |",
+		// The children are inherited from the default menu.
+		"Documentation: /docs/|Child: Install|",
+		"Blog: /blog/|",
+		"Footer: 1",
+	)
+}
@@ -33,14 +33,13 @@ import (
 
 	"github.com/gohugoio/hugo/hugofs/files"
 
-	"github.com/bep/gitmap"
-
 	"github.com/gohugoio/hugo/helpers"
 
 	"github.com/gohugoio/hugo/common/herrors"
 	"github.com/gohugoio/hugo/parser/metadecoders"
 
 	"github.com/gohugoio/hugo/parser/pageparser"
+	"github.com/gohugoio/hugo/related"
 	"github.com/pkg/errors"
 
 	"github.com/gohugoio/hugo/output"
@@ -144,7 +143,7 @@ func (p *pageState) GetIdentity() identity.Identity {
 	return identity.NewPathIdentity(files.ComponentFolderContent, filepath.FromSlash(p.Path()))
 }
 
-func (p *pageState) GitInfo() *gitmap.GitInfo {
+func (p *pageState) GitInfo() *source.GitInfo {
 	return p.gitInfo
 }
 
@@ -260,6 +259,22 @@ func (p *pageState) Pages() page.Pages {
 			pages = p.bucket.getTaxonomyEntries()
 		case page.KindTaxonomy:
 			pages = p.bucket.getTaxonomies()
+			if o, found := p.s.siteCfg.taxonomyOptions[p.Section()]; found {
+				pages = o.sortTerms(pages)
+			}
+		case kindSitemap:
+			for _, pp := range p.s.Pages() {
+				if !pp.Sitemap().Disable {
+					pages = append(pages, pp)
+				}
+			}
+			if p.s.Deps.Reproducible {
+				// The order of the URLs in a sitemap has no meaning, so
+				// make it independent of dates and titles.
+				sort.SliceStable(pages, func(i, j int) bool {
+					return pages[i].Permalink() < pages[j].Permalink()
+				})
+			}
 		default:
 			pages = p.s.Pages()
 		}
@@ -273,6 +288,7 @@ func (p *pageState) Pages() page.Pages {
 // RawContent returns the un-rendered source content without
 // any leading front matter.
 func (p *pageState) RawContent() string {
+	p.loadContentOrReport()
 	if p.source.parsed == nil {
 		return ""
 	}
@@ -319,13 +335,23 @@ func (p *pageState) Resources() resource.Resources {
 }
 
 func (p *pageState) HasShortcode(name string) bool {
-	if p.shortcodeState == nil {
+	if !p.loadContentOrReport() || p.shortcodeState == nil {
 		return false
 	}
 
 	return p.shortcodeState.nameSet[name]
 }
 
+// RelatedKeywords implements the related.Document interface needed for fast page searches.
+// A fulltext index named "content" indexes the words in the page content.
+func (p *pageState) RelatedKeywords(cfg related.IndexConfig) ([]related.Keyword, error) {
+	if cfg.Type != related.IndexTypeFulltext || cfg.Name != relatedContentIndexName {
+		return p.m.RelatedKeywords(cfg)
+	}
+
+	return p.s.relatedTerms.keywords(p)
+}
+
 func (p *pageState) Site() page.Site {
 	return p.s.Info
 }
@@ -344,6 +370,13 @@ func (p *pageState) IsTranslated() bool {
 	return len(p.translations) > 0
 }
 
+// IsFallback returns whether this page is rendered from the content
+// in a fallback language.
+func (p *pageState) IsFallback() bool {
+	f := p.File()
+	return f != nil && !f.IsZero() && f.Lang() != p.s.Lang()
+}
+
 // TranslationKey returns the key used to map language translations of this page.
 // It will use the translationKey set in front matter if set, or the content path and
 // filename (excluding any language code and extension), e.g. "about/index".
@@ -417,10 +450,13 @@ func (p *pageState) createRenderHooks(f output.Format) (hooks.Renderers, error)
 		return renderers, err
 	}
 	if templFound {
-		renderers.ImageRenderer = hookRenderer{
-			templateHandler: p.s.Tmpl(),
-			SearchProvider:  templ.(identity.SearchProvider),
-			templ:           templ,
+		renderers.ImageRenderer = imageHookRenderer{
+			hookRenderer: hookRenderer{
+				templateHandler: p.s.Tmpl(),
+				SearchProvider:  templ.(identity.SearchProvider),
+				templ:           templ,
+			},
+			s: p.s,
 		}
 	}
 
@@ -689,7 +725,7 @@ func (p *pageState) wrapError(err error) error {
 	}
 
 	err, _ = herrors.WithFileContextForFile(
-		err,
+		herrors.WithPage(p.Path(), err),
 		filename,
 		filename,
 		p.s.SourceSpec.Fs.Source,
@@ -715,7 +751,115 @@ func (p *pageState) getContentConverter() converter.Converter {
 	return p.m.contentConverter
 }
 
-func (p *pageState) mapContent(bucket *pagesMapBucket, meta *pageMeta) error {
+func (p *pageState) getGemtextConverter() converter.Converter {
+	var err error
+	p.m.gemtextConverterInit.Do(func() {
+		p.m.gemtextConverter, err = p.m.newContentConverter(p, "gemtext", p.m.renderingConfigOverrides)
+	})
+
+	if err != nil {
+		p.s.Log.Errorln("Failed to create content converter:", err)
+	}
+	return p.m.gemtextConverter
+}
+
+// mapFrontMatter applies the front matter in parsed, the result of a front
+// matter only parse, to the page.
+func (p *pageState) mapFrontMatter(bucket *pagesMapBucket, meta *pageMeta, parsed pageparser.Result) error {
+	iter := parsed.Iterator()
+
+	for {
+		it := iter.Next()
+
+		switch {
+		case it.Type == pageparser.TypeIgnore:
+		case it.IsFrontMatter():
+			f := pageparser.FormatFromFrontMatterType(it.Type)
+			m, err := metadecoders.Default.UnmarshalToMap(it.Val, f)
+			if err != nil {
+				if fe, ok := err.(herrors.FileError); ok {
+					return herrors.WithCode(herrors.CodeFrontMatter, herrors.ToFileErrorWithOffset(fe, iter.LineNumber()-1))
+				} else {
+					return herrors.WithCode(herrors.CodeFrontMatter, err)
+				}
+			}
+
+			return meta.setMetadata(bucket, p, m)
+		case it.IsError():
+			return p.parseError(herrors.WithCode(herrors.CodeContentParse, errors.WithStack(errors.New(it.ValStr()))), iter.Input(), it.Pos)
+		default:
+			// Page content without front matter. Assign default front matter from
+			// cascades etc.
+			return meta.setMetadata(bucket, p, nil)
+		}
+	}
+}
+
+// loadContent reads and parses the page content. This is deferred until
+// the content is needed, as in bigger sites most pages are only listed.
+func (p *pageState) loadContent() error {
+	p.contentLoad.Do(func() {
+		p.contentLoadErr = p.doLoadContent()
+	})
+	return p.contentLoadErr
+}
+
+// loadContentOrReport is loadContent for callers that cannot return the
+// error. It's otherwise reported when the page is rendered, so we report it
+// here for pages that are never rendered.
+func (p *pageState) loadContentOrReport() bool {
+	err := p.loadContent()
+	if err == nil {
+		return true
+	}
+	if p.m.noRender() {
+		p.contentLoadErrReport.Do(func() {
+			p.s.SendError(p.wrapError(err))
+		})
+	}
+	return false
+}
+
+func (p *pageState) doLoadContent() error {
+	if p.openContent == nil || !p.s.shouldBuild(p) {
+		return nil
+	}
+
+	r, err := p.openContent()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	parseResult, err := pageparser.Parse(
+		r,
+		pageparser.Config{EnableEmoji: p.s.siteCfg.enableEmoji},
+	)
+	if err != nil {
+		return err
+	}
+
+	p.source = rawPageContent{
+		parsed:         parseResult,
+		posMainContent: -1,
+		posSummaryEnd:  -1,
+		posBodyStart:   -1,
+	}
+
+	if p.m.detectCJKLanguage {
+		p.m.isCJKLanguage = cjkRe.Match(parseResult.Input())
+	}
+
+	p.shortcodeState = newShortcodeHandler(p, p.s, nil)
+
+	if err := p.mapContent(p.m); err != nil {
+		return p.wrapError(err)
+	}
+
+	return nil
+}
+
+func (p *pageState) mapContent(meta *pageMeta) error {
 	s := p.shortcodeState
 
 	rn := &pageContentMap{
@@ -732,7 +876,6 @@ func (p *pageState) mapContent(bucket *pagesMapBucket, meta *pageMeta) error {
 	// … it's safe to keep some "global" state
 	var currShortcode shortcode
 	var ordinal int
-	var frontMatterSet bool
 
 Loop:
 	for {
@@ -741,32 +884,12 @@ Loop:
 		switch {
 		case it.Type == pageparser.TypeIgnore:
 		case it.IsFrontMatter():
-			f := pageparser.FormatFromFrontMatterType(it.Type)
-			m, err := metadecoders.Default.UnmarshalToMap(it.Val, f)
-			if err != nil {
-				if fe, ok := err.(herrors.FileError); ok {
-					return herrors.ToFileErrorWithOffset(fe, iter.LineNumber()-1)
-				} else {
-					return err
-				}
-			}
-
-			if err := meta.setMetadata(bucket, p, m); err != nil {
-				return err
-			}
-
-			frontMatterSet = true
-
+			// Already applied, see mapFrontMatter.
 			next := iter.Peek()
 			if !next.IsDone() {
 				p.source.posMainContent = next.Pos
 			}
 
-			if !p.s.shouldBuild(p) {
-				// Nothing more to do.
-				return nil
-			}
-
 		case it.Type == pageparser.TypeLeadSummaryDivider:
 			posBody := -1
 			f := func(item pageparser.Item) bool {
@@ -801,7 +924,7 @@ Loop:
 
 			currShortcode, err := s.extractShortcode(ordinal, 0, iter)
 			if err != nil {
-				return fail(errors.Wrap(err, "failed to extract shortcode"), it)
+				return fail(herrors.WithCode(herrors.CodeShortcode, errors.Wrap(err, "failed to extract shortcode")), it)
 			}
 
 			currShortcode.pos = it.Pos
@@ -834,7 +957,7 @@ Loop:
 		case it.IsEOF():
 			break Loop
 		case it.IsError():
-			err := fail(errors.WithStack(errors.New(it.ValStr())), it)
+			err := fail(herrors.WithCode(herrors.CodeContentParse, errors.WithStack(errors.New(it.ValStr()))), it)
 			currShortcode.err = err
 			return err
 
@@ -843,14 +966,6 @@ Loop:
 		}
 	}
 
-	if !frontMatterSet {
-		// Page content without front matter. Assign default front matter from
-		// cascades etc.
-		if err := meta.setMetadata(bucket, p, nil); err != nil {
-			return err
-		}
-	}
-
 	p.cmap = rn
 
 	return nil
@@ -867,7 +982,7 @@ func (p *pageState) errorf(err error, format string, a ...interface{}) error {
 		errors.Errorf(format, args...)
 		return fmt.Errorf(format, args...)
 	}
-	return errors.Wrapf(err, format, args...)
+	return errors.Wrapf(herrors.WithPage(p.Path(), err), format, args...)
 }
 
 func (p *pageState) outputFormat() (f output.Format) {
@@ -883,7 +998,7 @@ func (p *pageState) parseError(err error, input []byte, offset int) error {
 		return err
 	}
 	pos := p.posFromInput(input, offset)
-	return herrors.NewFileError("md", -1, pos.LineNumber, pos.ColumnNumber, err)
+	return herrors.NewFileError("md", -1, pos.LineNumber, pos.ColumnNumber, herrors.WithPage(p.Path(), err))
 }
 
 func (p *pageState) pathOrTitle() string {
@@ -898,6 +1013,18 @@ func (p *pageState) pathOrTitle() string {
 	return p.Title()
 }
 
+// metricsKey identifies the page in the --pageMetrics report.
+func (p *pageState) metricsKey() string {
+	key := p.RelPermalink()
+	if !p.File().IsZero() {
+		key = p.File().Path()
+	}
+	if p.s.multilingualEnabled() {
+		key += " (" + p.Lang() + ")"
+	}
+	return key
+}
+
 func (p *pageState) posFromPage(offset int) text.Position {
 	return p.posFromInput(p.source.parsed.Input(), offset)
 }
@@ -952,7 +1079,7 @@ func (p *pageState) shiftToOutputFormat(isRenderingSite bool, idx int) error {
 				}
 				po := p.pageOutputs[i]
 
-				if po.cp != nil && po.cp.reuse {
+				if po.cp != nil && po.cp.reuse && po.cp.renderMode == contentRenderModeFor(p, p.pageOutput.f) {
 					cp = po.cp
 					break
 				}
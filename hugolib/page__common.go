@@ -15,15 +15,18 @@ package hugolib
 
 import (
 	"sync"
+	"time"
 
-	"github.com/bep/gitmap"
+	"github.com/gohugoio/hugo/common/hugio"
 	"github.com/gohugoio/hugo/common/maps"
 	"github.com/gohugoio/hugo/compare"
+	"github.com/gohugoio/hugo/hugofs"
 	"github.com/gohugoio/hugo/lazy"
 	"github.com/gohugoio/hugo/navigation"
 	"github.com/gohugoio/hugo/output"
 	"github.com/gohugoio/hugo/resources/page"
 	"github.com/gohugoio/hugo/resources/resource"
+	"github.com/gohugoio/hugo/source"
 )
 
 type treeRefProvider interface {
@@ -101,8 +104,15 @@ type pageCommon struct {
 	// The parsed page content.
 	pageContent
 
+	// Opens the page source. The content is loaded on first use,
+	// see loadContent.
+	openContent          func() (hugio.ReadSeekCloser, error)
+	contentLoad          sync.Once
+	contentLoadErr       error
+	contentLoadErrReport sync.Once
+
 	// Set if feature enabled and this is in a Git repo.
-	gitInfo *gitmap.GitInfo
+	gitInfo *source.GitInfo
 
 	// Positional navigation
 	posNextPrev        *nextPrev
@@ -122,6 +132,13 @@ type pageCommon struct {
 	resourcesInit        sync.Once
 	resourcesPublishInit sync.Once
 
+	// The source files of the bundled resources, used to resolve the
+	// lastmod date in the sitemap.
+	resourceFiles []hugofs.FileMetaInfo
+
+	sitemapLastmod     time.Time
+	sitemapLastmodInit sync.Once
+
 	translations    page.Pages
 	allTranslations page.Pages
 
@@ -14,9 +14,10 @@
 package hugolib
 
 import (
+	"bytes"
 	"fmt"
+	"io/ioutil"
 	"path"
-	"path/filepath"
 	"regexp"
 	"strings"
 	"sync"
@@ -28,6 +29,7 @@ import (
 	"github.com/gohugoio/hugo/hugofs/files"
 
 	"github.com/gohugoio/hugo/common/hugo"
+	"github.com/gohugoio/hugo/common/text"
 
 	"github.com/gohugoio/hugo/related"
 
@@ -65,6 +67,11 @@ type pageMeta struct {
 	draft       bool // Only published when running with -D flag
 	buildConfig pagemeta.BuildConfig
 
+	// The archetype matching this page and the fields defined in it
+	// missing in the page's front matter.
+	archetype        string
+	archetypeMissing []string
+
 	bundleType files.ContentClass
 
 	// Params contains configuration defined in the params section of page frontmatter.
@@ -85,10 +92,16 @@ type pageMeta struct {
 	// whether the content is in a CJK language.
 	isCJKLanguage bool
 
+	// Set if isCJKLanguage is detected when the content is loaded.
+	detectCJKLanguage bool
+
 	layout string
 
 	aliases []string
 
+	// The status codes set for aliases in front matter, keyed by alias.
+	aliasStatus map[string]int
+
 	description string
 	keywords    []string
 
@@ -103,6 +116,11 @@ type pageMeta struct {
 	// from the page front matter.
 	translationKey string
 
+	// If set, the rendered HTML of this page is encrypted with this password.
+	// This is fetched from the page front matter (or a cascade), but is
+	// intentionally not stored in Params.
+	password string
+
 	// From front matter.
 	configuredOutputFormats output.Formats
 
@@ -122,6 +140,9 @@ type pageMeta struct {
 	renderingConfigOverrides map[string]interface{}
 	contentConverterInit     sync.Once
 	contentConverter         converter.Converter
+
+	gemtextConverterInit sync.Once
+	gemtextConverter     converter.Converter
 }
 
 func (p *pageMeta) Aliases() []string {
@@ -330,8 +351,16 @@ func (pm *pageMeta) mergeBucketCascades(b1, b2 *pagesMapBucket) {
 func (pm *pageMeta) setMetadata(parentBucket *pagesMapBucket, p *pageState, frontmatter map[string]interface{}) error {
 	pm.params = make(maps.Params)
 
-	if frontmatter == nil && (parentBucket == nil || parentBucket.cascade == nil) {
-		return nil
+	var termMeta maps.Params
+	if pm.kind == page.KindTerm {
+		var err error
+		if termMeta, err = p.s.termMetadata(pm.sections); err != nil {
+			return err
+		}
+	}
+
+	if frontmatter == nil && termMeta == nil && (parentBucket == nil || parentBucket.cascade == nil) && p.s.archetypes == nil {
+		return pm.validateFrontMatter(p, nil)
 	}
 
 	if frontmatter != nil {
@@ -375,6 +404,14 @@ func (pm *pageMeta) setMetadata(parentBucket *pagesMapBucket, p *pageState, fron
 		frontmatter = make(map[string]interface{})
 	}
 
+	// Front matter in the term's content file has precedence over the
+	// term metadata, the default title set for terms without one has not.
+	for k, v := range termMeta {
+		if _, found := frontmatter[k]; !found || p.File().IsZero() {
+			frontmatter[k] = v
+		}
+	}
+
 	var cascade map[page.PageMatcher]maps.Params
 
 	if p.bucket != nil {
@@ -398,6 +435,12 @@ func (pm *pageMeta) setMetadata(parentBucket *pagesMapBucket, p *pageState, fron
 		}
 	}
 
+	if p.s.archetypes != nil {
+		if err := p.s.archetypes.apply(pm, p, frontmatter); err != nil {
+			return err
+		}
+	}
+
 	var mtime time.Time
 	var contentBaseName string
 	if !p.File().IsZero() {
@@ -406,6 +449,10 @@ func (pm *pageMeta) setMetadata(parentBucket *pagesMapBucket, p *pageState, fron
 			mtime = p.File().FileInfo().ModTime()
 		}
 	}
+	if p.s.Deps.Reproducible {
+		// File modification times differ between checkouts.
+		mtime = p.s.Deps.Clock.Now()
+	}
 
 	var gitAuthorDate time.Time
 	if p.gitInfo != nil {
@@ -435,7 +482,7 @@ func (pm *pageMeta) setMetadata(parentBucket *pagesMapBucket, p *pageState, fron
 		return err
 	}
 
-	var sitemapSet bool
+	var sitemap map[string]interface{}
 
 	var draft, published, isCJKLanguage *bool
 	for k, v := range frontmatter {
@@ -530,24 +577,23 @@ func (pm *pageMeta) setMetadata(parentBucket *pagesMapBucket, p *pageState, fron
 			pm.weight = cast.ToInt(v)
 			pm.params[loki] = pm.weight
 		case "aliases":
-			pm.aliases = cast.ToStringSlice(v)
-			for i, alias := range pm.aliases {
-				if strings.HasPrefix(alias, "http://") || strings.HasPrefix(alias, "https://") {
-					return fmt.Errorf("http* aliases not supported: %q", alias)
-				}
-				pm.aliases[i] = filepath.ToSlash(alias)
+			var err error
+			pm.aliases, pm.aliasStatus, err = decodeAliases(v)
+			if err != nil {
+				return err
 			}
 			pm.params[loki] = pm.aliases
 		case "sitemap":
-			p.m.sitemap = config.DecodeSitemap(p.s.siteCfg.sitemap, maps.ToStringMap(v))
-			pm.params[loki] = p.m.sitemap
-			sitemapSet = true
+			// Decoded below, on top of any sitemap rule matching the page.
+			sitemap = maps.ToStringMap(v)
 		case "iscjklanguage":
 			isCJKLanguage = new(bool)
 			*isCJKLanguage = cast.ToBool(v)
 		case "translationkey":
 			pm.translationKey = cast.ToString(v)
 			pm.params[loki] = pm.translationKey
+		case "password":
+			pm.password = cast.ToString(v)
 		case "resources":
 			var resources []map[string]interface{}
 			handled := true
@@ -621,8 +667,13 @@ func (pm *pageMeta) setMetadata(parentBucket *pagesMapBucket, p *pageState, fron
 		}
 	}
 
-	if !sitemapSet {
-		pm.sitemap = p.s.siteCfg.sitemap
+	pm.sitemap = p.s.siteCfg.sitemapRules.apply(p, p.s.siteCfg.sitemap)
+	if sitemap != nil {
+		pm.sitemap = config.DecodeSitemap(pm.sitemap, sitemap)
+		if err := validateSitemapLastmodSource(pm.sitemap); err != nil {
+			return p.wrapError(err)
+		}
+		pm.params["sitemap"] = pm.sitemap
 	}
 
 	pm.markup = p.s.ContentSpec.ResolveMarkup(pm.markup)
@@ -639,19 +690,79 @@ func (pm *pageMeta) setMetadata(parentBucket *pagesMapBucket, p *pageState, fron
 
 	if isCJKLanguage != nil {
 		pm.isCJKLanguage = *isCJKLanguage
-	} else if p.s.siteCfg.hasCJKLanguage && p.source.parsed != nil {
-		if cjkRe.Match(p.source.parsed.Input()) {
-			pm.isCJKLanguage = true
-		} else {
-			pm.isCJKLanguage = false
+	} else if p.s.siteCfg.hasCJKLanguage && p.openContent != nil {
+		// The content is not loaded yet, see loadContent.
+		pm.detectCJKLanguage = true
+	}
+
+	// A detected value is not known until the content is loaded.
+	pm.params["iscjklanguage"] = pm.isCJKLanguage
+
+	return pm.validateFrontMatter(p, frontmatter)
+}
+
+// validateFrontMatter validates the front matter of regular pages against
+// the schema configured for the page's content type in contentTypes. Any
+// errors are logged as warnings, or as errors in strictFrontMatter mode.
+func (pm *pageMeta) validateFrontMatter(p *pageState, frontmatter map[string]interface{}) error {
+	if len(p.s.siteCfg.contentTypes) == 0 || pm.kind != page.KindPage || p.File().IsZero() {
+		return nil
+	}
+
+	ct, found := p.s.siteCfg.contentTypes[strings.ToLower(pm.Type())]
+	if !found {
+		return nil
+	}
+
+	errs := ct.Validate(frontmatter)
+	if len(errs) == 0 {
+		return nil
+	}
+
+	var src []byte
+	if p.openContent != nil {
+		r, err := p.openContent()
+		if err != nil {
+			return err
+		}
+		src, err = ioutil.ReadAll(r)
+		r.Close()
+		if err != nil {
+			return err
 		}
 	}
 
-	pm.params["iscjklanguage"] = p.m.isCJKLanguage
+	for _, err := range errs {
+		pos := frontMatterFieldPosition(src, err.Field)
+		pos.Filename = p.File().Filename()
+		if p.s.siteCfg.strictFrontMatter {
+			p.s.Log.Errorf("%s: %s", pos, err)
+		} else {
+			p.s.Log.Warnf("%s: %s", pos, err)
+		}
+	}
 
 	return nil
 }
 
+// frontMatterFieldPosition returns the position of the given field in the
+// front matter in src, or the start of the file if not found.
+func frontMatterFieldPosition(src []byte, field string) text.Position {
+	pos := text.Position{Offset: -1, LineNumber: 1, ColumnNumber: 1}
+	re, err := regexp.Compile(`(?mi)^([ \t]*)"?` + regexp.QuoteMeta(field) + `"?[ \t]*[:=]`)
+	if err != nil {
+		return pos
+	}
+	loc := re.FindSubmatchIndex(src)
+	if loc == nil {
+		return pos
+	}
+	pos.Offset = loc[0]
+	pos.LineNumber = bytes.Count(src[:loc[0]], []byte("\n")) + 1
+	pos.ColumnNumber = loc[3] - loc[2] + 1
+	return pos
+}
+
 func (p *pageMeta) noListAlways() bool {
 	return p.buildConfig.List != pagemeta.Always
 }
@@ -783,6 +894,7 @@ func (p *pageMeta) newContentConverter(ps *pageState, markup string, renderingCo
 			DocumentName:    p.Path(),
 			Filename:        filename,
 			ConfigOverrides: renderingConfigOverrides,
+			LinkResolver:    wikilinkResolver{p: ps},
 		},
 	)
 	if err != nil {
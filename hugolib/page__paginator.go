@@ -41,10 +41,22 @@ func (p *pagePaginator) reset() {
 	p.pagePaginatorInit = &pagePaginatorInit{}
 }
 
+// pagerOptions returns options, or the pager size configured in
+// taxonomyOptions if options is empty and this is a term page.
+func (p *pagePaginator) pagerOptions(options []interface{}) []interface{} {
+	if len(options) > 0 || p.source.Kind() != page.KindTerm {
+		return options
+	}
+	if o, found := p.source.s.siteCfg.taxonomyOptions[p.source.Section()]; found && o.Paginate > 0 {
+		return []interface{}{o.Paginate}
+	}
+	return options
+}
+
 func (p *pagePaginator) Paginate(seq interface{}, options ...interface{}) (*page.Pager, error) {
 	var initErr error
 	p.init.Do(func() {
-		pagerSize, err := page.ResolvePagerSize(p.source.s.Cfg, options...)
+		pagerSize, err := page.ResolvePagerSize(p.source.s.Cfg, p.pagerOptions(options)...)
 		if err != nil {
 			initErr = err
 			return
@@ -52,6 +64,7 @@ func (p *pagePaginator) Paginate(seq interface{}, options ...interface{}) (*page
 
 		pd := p.source.targetPathDescriptor
 		pd.Type = p.source.outputFormat()
+		p.source.s.Info.urlRules.apply(p.source, &pd)
 		paginator, err := page.Paginate(pd, seq, pagerSize)
 		if err != nil {
 			initErr = err
@@ -71,7 +84,7 @@ func (p *pagePaginator) Paginate(seq interface{}, options ...interface{}) (*page
 func (p *pagePaginator) Paginator(options ...interface{}) (*page.Pager, error) {
 	var initErr error
 	p.init.Do(func() {
-		pagerSize, err := page.ResolvePagerSize(p.source.s.Cfg, options...)
+		pagerSize, err := page.ResolvePagerSize(p.source.s.Cfg, p.pagerOptions(options)...)
 		if err != nil {
 			initErr = err
 			return
@@ -79,6 +92,7 @@ func (p *pagePaginator) Paginator(options ...interface{}) (*page.Pager, error) {
 
 		pd := p.source.targetPathDescriptor
 		pd.Type = p.source.outputFormat()
+		p.source.s.Info.urlRules.apply(p.source, &pd)
 
 		var pages page.Pages
 
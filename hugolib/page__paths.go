@@ -46,6 +46,7 @@ func newPagePaths(
 	for i, f := range outputFormats {
 		desc := targetPathDescriptor
 		desc.Type = f
+		s.Info.urlRules.apply(p, &desc)
 		paths := page.CreateTargetPaths(desc)
 
 		var relPermalink, permalink string
@@ -21,6 +21,8 @@ import (
 	"runtime/debug"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 	"unicode/utf8"
 
 	"github.com/gohugoio/hugo/identity"
@@ -28,6 +30,7 @@ import (
 	"github.com/gohugoio/hugo/markup/converter/hooks"
 
 	"github.com/gohugoio/hugo/markup/converter"
+	"github.com/gohugoio/hugo/media"
 
 	"github.com/gohugoio/hugo/lazy"
 
@@ -35,7 +38,9 @@ import (
 	"github.com/gohugoio/hugo/tpl"
 
 	"github.com/gohugoio/hugo/helpers"
+	"github.com/gohugoio/hugo/metrics"
 	"github.com/gohugoio/hugo/output"
+	"github.com/gohugoio/hugo/plugins"
 	"github.com/gohugoio/hugo/resources/page"
 	"github.com/gohugoio/hugo/resources/resource"
 )
@@ -76,12 +81,17 @@ func newPageContentOutput(p *pageState, po *pageOutput) (*pageContentOutput, err
 		dependencyTracker: dependencyTracker,
 		p:                 p,
 		f:                 po.f,
+		renderMode:        contentRenderModeFor(p, po.f),
 		renderHooks:       &renderHooks{},
 	}
 
 	initContent := func() (err error) {
 		p.s.h.IncrContentRender()
 
+		if err := p.loadContent(); err != nil {
+			return err
+		}
+
 		if p.cmap == nil {
 			// Nothing to do.
 			return nil
@@ -100,11 +110,21 @@ func newPageContentOutput(p *pageState, po *pageOutput) (*pageContentOutput, err
 
 		var hasShortcodeVariants bool
 
+		pageMetrics := p.s.PageMetrics
+		var start time.Time
+
 		f := po.f
+		if pageMetrics != nil {
+			start = time.Now()
+		}
 		cp.contentPlaceholders, hasShortcodeVariants, err = p.shortcodeState.renderShortcodesForPage(p, f)
 		if err != nil {
 			return err
 		}
+		if pageMetrics != nil {
+			pageMetrics.MeasureSince(p.metricsKey(), metrics.PhaseShortcodes, start)
+			start = time.Now()
+		}
 
 		enableReuse := !(hasShortcodeVariants || cp.renderHooksHaveVariants)
 
@@ -120,6 +140,17 @@ func newPageContentOutput(p *pageState, po *pageOutput) (*pageContentOutput, err
 
 		cp.workContent = p.contentToRender(cp.contentPlaceholders)
 
+		if p.s.Plugins.Has(plugins.KindContent) && !p.File().IsZero() {
+			cp.workContent, err = p.s.Plugins.Transform(plugins.Context{
+				Kind:   plugins.KindContent,
+				Path:   p.File().Path(),
+				Markup: p.m.markup,
+			}, cp.workContent)
+			if err != nil {
+				return err
+			}
+		}
+
 		isHTML := cp.p.m.markup == "html"
 
 		if !isHTML {
@@ -152,6 +183,12 @@ func newPageContentOutput(p *pageState, po *pageOutput) (*pageContentOutput, err
 			cp.contentPlaceholders[tocShortcodePlaceholder] = string(cp.tableOfContents)
 		}
 
+		// The html summary mode needs the content without shortcode output.
+		var summaryContent []byte
+		if p.s.siteCfg.summary.Mode == summaryModeHTML && !cp.p.source.hasSummaryDivider && cp.p.m.summary == "" {
+			summaryContent = append([]byte(nil), cp.workContent...)
+		}
+
 		if p.cmap.hasNonMarkdownShortcode || cp.placeholdersEnabled {
 			// There are one or more replacement tokens to be replaced.
 			cp.workContent, err = replaceShortcodeTokens(cp.workContent, cp.contentPlaceholders)
@@ -161,7 +198,14 @@ func newPageContentOutput(p *pageState, po *pageOutput) (*pageContentOutput, err
 		}
 
 		if cp.p.source.hasSummaryDivider {
-			if isHTML {
+			if cp.renderMode == contentRenderMarkdown {
+				if i := bytes.Index(cp.workContent, internalSummaryDividerBaseBytes); i != -1 {
+					summary := bytes.TrimSpace(cp.workContent[:i])
+					rest := bytes.TrimSpace(cp.workContent[i+len(internalSummaryDividerBase):])
+					cp.summary = helpers.BytesToHTML(summary)
+					cp.workContent = append(append(append([]byte(nil), summary...), "\n\n"...), rest...)
+				}
+			} else if isHTML {
 				src := p.source.parsed.Input()
 
 				// Use the summary sections as they are provided by the user.
@@ -189,10 +233,21 @@ func newPageContentOutput(p *pageState, po *pageOutput) (*pageContentOutput, err
 			}
 			html := cp.p.s.ContentSpec.TrimShortHTML(b.Bytes())
 			cp.summary = helpers.BytesToHTML(html)
+		} else if summaryContent != nil {
+			summary, truncated, err := p.htmlSummary(summaryContent, cp.contentPlaceholders)
+			if err != nil {
+				return err
+			}
+			cp.summary = template.HTML(summary)
+			cp.truncated = truncated
 		}
 
 		cp.content = helpers.BytesToHTML(cp.workContent)
 
+		if pageMetrics != nil {
+			pageMetrics.MeasureSince(p.metricsKey(), metrics.PhaseContent, start)
+		}
+
 		return nil
 	}
 
@@ -230,14 +285,26 @@ type renderHooks struct {
 	init  sync.Once
 }
 
+// contentUseCounter is incremented every time a page's content is used, to
+// tell which content was least recently used when applying the pages memory
+// budget.
+var contentUseCounter uint64
+
 // pageContentOutput represents the Page content for a given output format.
 type pageContentOutput struct {
+	// When the content was last used, see contentUseCounter.
+	// Must be first for the alignment of atomic access on 32-bit platforms.
+	lastUsed uint64
+
 	f output.Format
 
 	// If we can reuse this for other output formats.
 	reuse     bool
 	reuseInit sync.Once
 
+	// How the content is rendered in this output format.
+	renderMode contentRenderMode
+
 	p *pageState
 
 	// Lazy load dependencies
@@ -273,7 +340,7 @@ type pageContentOutput struct {
 	plain          string
 	fuzzyWordCount int
 	wordCount      int
-	readingTime    int
+	readingTime    time.Duration
 }
 
 func (p *pageContentOutput) trackDependency(id identity.Provider) {
@@ -291,48 +358,89 @@ func (p *pageContentOutput) Reset() {
 	p.renderHooks = &renderHooks{}
 }
 
+// retainedSize returns the approximate size in bytes of the rendered content
+// kept in memory.
+func (p *pageContentOutput) retainedSize() int {
+	size := len(p.workContent) + len(p.content) + len(p.summary) + len(p.tableOfContents) + len(p.plain)
+	for _, w := range p.plainWords {
+		size += len(w)
+	}
+	for _, v := range p.contentPlaceholders {
+		size += len(v)
+	}
+	return size
+}
+
+// evict releases the rendered content. It will be rendered again if needed.
+// This must not be called while rendering.
+func (p *pageContentOutput) evict() bool {
+	if p.retainedSize() == 0 {
+		return false
+	}
+	p.Reset()
+	p.workContent = nil
+	p.contentPlaceholders = nil
+	p.content = ""
+	p.summary = ""
+	p.tableOfContents = ""
+	p.plain = ""
+	p.plainWords = nil
+	return true
+}
+
+// use initializes the content with init and marks it as used.
+func (p *pageContentOutput) use(init *lazy.Init) bool {
+	atomic.StoreUint64(&p.lastUsed, atomic.AddUint64(&contentUseCounter, 1))
+	return p.p.s.initInit(init, p.p)
+}
+
 func (p *pageContentOutput) Content() (interface{}, error) {
-	if p.p.s.initInit(p.initMain, p.p) {
+	if p.use(p.initMain) {
 		return p.content, nil
 	}
 	return nil, nil
 }
 
 func (p *pageContentOutput) FuzzyWordCount() int {
-	p.p.s.initInit(p.initPlain, p.p)
+	p.use(p.initPlain)
 	return p.fuzzyWordCount
 }
 
 func (p *pageContentOutput) Len() int {
-	p.p.s.initInit(p.initMain, p.p)
+	p.use(p.initMain)
 	return len(p.content)
 }
 
 func (p *pageContentOutput) Plain() string {
-	p.p.s.initInit(p.initPlain, p.p)
+	p.use(p.initPlain)
 	return p.plain
 }
 
 func (p *pageContentOutput) PlainWords() []string {
-	p.p.s.initInit(p.initPlain, p.p)
+	p.use(p.initPlain)
 	return p.plainWords
 }
 
 func (p *pageContentOutput) ReadingTime() int {
-	p.p.s.initInit(p.initPlain, p.p)
-	return p.readingTime
+	p.use(p.initPlain)
+	return readingMinutes(p.readingTime)
+}
+
+func (p *pageContentOutput) ReadingTimeDuration() time.Duration {
+	p.use(p.initPlain)
+	return p.readingTime.Round(time.Second)
 }
 
 func (p *pageContentOutput) Summary() template.HTML {
-	p.p.s.initInit(p.initMain, p.p)
+	p.use(p.initMain)
 	if !p.p.source.hasSummaryDivider {
-		p.p.s.initInit(p.initPlain, p.p)
+		p.use(p.initPlain)
 	}
 	return p.summary
 }
 
 func (p *pageContentOutput) TableOfContents() template.HTML {
-	p.p.s.initInit(p.initMain, p.p)
+	p.use(p.initMain)
 	return p.tableOfContents
 }
 
@@ -340,17 +448,17 @@ func (p *pageContentOutput) Truncated() bool {
 	if p.p.truncated {
 		return true
 	}
-	p.p.s.initInit(p.initPlain, p.p)
+	p.use(p.initPlain)
 	return p.truncated
 }
 
 func (p *pageContentOutput) WordCount() int {
-	p.p.s.initInit(p.initPlain, p.p)
+	p.use(p.initPlain)
 	return p.wordCount
 }
 
 func (p *pageContentOutput) setAutoSummary() error {
-	if p.p.source.hasSummaryDivider || p.p.m.summary != "" {
+	if p.p.source.hasSummaryDivider || p.p.m.summary != "" || p.p.s.siteCfg.summary.Mode == summaryModeHTML {
 		return nil
 	}
 
@@ -370,8 +478,45 @@ func (p *pageContentOutput) setAutoSummary() error {
 }
 
 func (cp *pageContentOutput) renderContent(content []byte, renderTOC bool) (converter.Result, error) {
-	c := cp.p.getContentConverter()
-	return cp.renderContentWithConverter(c, content, renderTOC)
+	switch cp.renderMode {
+	case contentRenderGemtext:
+		return cp.renderContentWithConverter(cp.p.getGemtextConverter(), content, renderTOC)
+	case contentRenderMarkdown:
+		return converter.Bytes(bytes.TrimSpace(content)), nil
+	}
+	return cp.renderContentWithConverter(cp.p.getContentConverter(), content, renderTOC)
+}
+
+// contentRenderMode tells how the content of a page is rendered in an
+// output format.
+type contentRenderMode int
+
+const (
+	// The content is rendered with the page's content converter, usually to HTML.
+	contentRenderDefault contentRenderMode = iota
+
+	// Markdown is rendered as gemtext.
+	contentRenderGemtext
+
+	// Markdown is left as is, with the shortcodes rendered.
+	contentRenderMarkdown
+)
+
+func contentRenderModeFor(p *pageState, f output.Format) contentRenderMode {
+	switch p.m.markup {
+	case "markdown", "goldmark", "blackfriday", "mmark":
+	default:
+		return contentRenderDefault
+	}
+
+	switch {
+	case f.MediaType.Type() == media.GemtextType.Type():
+		return contentRenderGemtext
+	case f.MediaType.Type() == media.MarkdownType.Type(), f.Name == output.LLMSFormat.Name, f.Name == output.LLMSFullFormat.Name:
+		return contentRenderMarkdown
+	}
+
+	return contentRenderDefault
 }
 
 func (cp *pageContentOutput) renderContentWithConverter(c converter.Converter, content []byte, renderTOC bool) (converter.Result, error) {
@@ -413,11 +558,12 @@ func (p *pageContentOutput) setWordCounts(isCJKLanguage bool) {
 		p.fuzzyWordCount = (p.wordCount + 100) / 100 * 100
 	}
 
-	if isCJKLanguage {
-		p.readingTime = (p.wordCount + 500) / 501
-	} else {
-		p.readingTime = (p.wordCount + 212) / 213
+	rt := p.p.s.siteCfg.readingTime
+	plain := p.plain
+	if rt.ExcludeCode {
+		plain = helpers.StripHTML(rt.readingText(string(p.content)))
 	}
+	p.readingTime = rt.duration(plain)
 }
 
 // A callback to signal that we have inserted a placeholder into the rendered
@@ -0,0 +1,69 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"testing"
+
+	"github.com/gohugoio/hugo/metrics"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestPageMetrics(t *testing.T) {
+	c := qt.New(t)
+
+	b := newTestSitesBuilder(t).WithConfigFile("toml", `
+baseURL = "https://example.org"
+pageMetrics = true
+`)
+
+	b.WithContent(
+		"p1.md", "---\ntitle: P1\n---\nSome **content** with a {{< sc >}}.",
+		"bundle/index.md", "---\ntitle: Bundle\n---\nA bundle.",
+	)
+	b.WithSunset("content/bundle/sunset.jpg")
+	b.WithSunset("assets/images/sunset.jpg")
+
+	b.WithTemplates(
+		"_default/single.html", `Single: {{ .Title }}|{{ .Content }}|{{ with .Resources.GetMatch "sunset*" }}{{ (.Resize "123x").RelPermalink }}{{ end }}`,
+		"index.html", `Home: {{ (resources.Get "images/sunset.jpg").Resize "45x" }}`,
+		"shortcodes/sc.html", `shortcode`,
+	)
+
+	b.Build(BuildCfg{})
+
+	b.AssertFileContent("public/p1/index.html", "Single: P1|<p>Some <strong>content</strong> with a shortcode.</p>")
+
+	c.Assert(b.H.PageMetrics, qt.Not(qt.IsNil))
+
+	timings := make(map[string]metrics.PageTiming)
+	for _, t := range b.H.PageMetrics.Slowest(0) {
+		timings[t.Page] = t
+	}
+
+	p1 := timings["p1.md"]
+	c.Assert(p1.Template > 0, qt.IsTrue)
+	c.Assert(p1.Content > 0, qt.IsTrue)
+	c.Assert(p1.Shortcodes > 0, qt.IsTrue)
+	c.Assert(p1.ImageCount, qt.Equals, 0)
+
+	bundle := timings["bundle/index.md"]
+	c.Assert(bundle.ImageCount, qt.Equals, 1)
+	c.Assert(bundle.Images > 0, qt.IsTrue)
+	c.Assert(bundle.Total(), qt.Equals, bundle.Template)
+
+	c.Assert(timings[metrics.UnattributedImages].ImageCount, qt.Equals, 1)
+	c.Assert(timings["/"].Template > 0, qt.IsTrue)
+}
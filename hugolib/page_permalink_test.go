@@ -147,3 +147,32 @@ Some content.
 	b.AssertFileContent("public/myblog/p2/index.html", "Single: A page|Hello|en|RelPermalink: /myblog/p2/|Permalink: https://example.com/myblog/p2/|")
 	b.AssertFileContent("public/myblog/p3/index.html", "Single: A page|Hello|en|RelPermalink: /myblog/p3/|Permalink: https://example.com/myblog/p3/|")
 }
+
+func TestPermalinkTokens(t *testing.T) {
+	config := `
+baseURL = "https://example.com"
+
+[permalinks]
+posts = "/:category/:author/:slug/"
+
+[permalinkTokens]
+author = "params.author"
+[permalinkTokens.category]
+from = "terms.categories"
+default = "uncategorized"
+
+[params]
+author = "The Team"
+`
+
+	b := newTestSitesBuilder(t).WithConfigFile("toml", config)
+	b.WithContent(
+		"posts/p1.md", "---\ntitle: P1\nauthor: Jane Doe\ncategories: [\"Release Notes\", \"News\"]\n---\n",
+		"posts/p2.md", "---\ntitle: P2\n---\n",
+	)
+
+	b.Build(BuildCfg{})
+
+	b.AssertFileContent("public/release-notes/jane-doe/p1/index.html", "Single: P1|")
+	b.AssertFileContent("public/uncategorized/the-team/p2/index.html", "Single: P2|")
+}
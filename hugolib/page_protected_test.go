@@ -0,0 +1,110 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestProtectedPages(t *testing.T) {
+	t.Parallel()
+
+	b := newTestSitesBuilder(t).WithConfigFile("toml", `
+baseURL = "https://example.org"
+disableKinds = ["taxonomy", "term"]
+[outputs]
+page = ["HTML", "JSON"]
+`)
+
+	b.WithContent("public.md", `---
+title: Public
+---
+Public content.
+`, "private/_index.md", `---
+title: Private
+cascade:
+  password: s3cr3t
+---
+`, "private/p1.md", `---
+title: P1
+---
+Private content.
+`, "secret.md", `---
+title: Secret
+password: foo
+---
+Secret content.
+`)
+
+	b.WithTemplates(
+		"_default/single.html", `Single: {{ .Title }}|{{ .Content }}|Params: {{ .Params }}`,
+		"_default/single.json", `{"title": {{ .Title | jsonify }}}`,
+		"_default/list.html", `List: {{ .Title }}`,
+	)
+
+	b.Build(BuildCfg{})
+
+	b.AssertFileContent("public/public/index.html", "Single: Public|<p>Public content.</p>")
+	b.AssertFileContent("public/public/index.json", `"Public"`)
+
+	for _, filename := range []string{"public/private/p1/index.html", "public/private/index.html", "public/secret/index.html"} {
+		b.AssertFileContent(filename, `<form id="hugo-protected">`, `"iterations":100000`)
+		content := b.FileContent(filename)
+		for _, s := range []string{"Single:", "List:", "content.", "s3cr3t", "foo"} {
+			b.Assert(content, qt.Not(qt.Contains), s)
+		}
+	}
+
+	// Only HTML is encrypted.
+	b.AssertFileContent("public/secret/index.json", `"Secret"`)
+}
+
+func TestProtectedPagesReproducible(t *testing.T) {
+	t.Parallel()
+
+	b := newTestSitesBuilder(t).WithConfigFile("toml", `
+baseURL = "https://example.org"
+disableKinds = ["taxonomy", "term"]
+reproducible = true
+[build]
+writeStats = true
+`).WithEnviron("SOURCE_DATE_EPOCH", "1625097600")
+
+	b.WithContent("secret.md", `---
+title: Secret
+password: foo
+---
+Secret content.
+`)
+
+	b.WithTemplates(
+		"_default/single.html", `<div class="secret-class" id="secret-id">{{ .Content }}</div>`,
+		"_default/list.html", `List: {{ .Title }}`,
+	)
+
+	b.Build(BuildCfg{})
+	content := b.FileContent("public/secret/index.html")
+	b.Assert(content, qt.Contains, `<form id="hugo-protected">`)
+	b.Assert(content, qt.Not(qt.Contains), "secret-class")
+
+	// The protected content is included in the stats.
+	b.AssertFileContent("hugo_stats.json", "secret-class", "secret-id", "hugo-protected")
+
+	// The same output in every build.
+	b.H = nil
+	b.Build(BuildCfg{})
+	b.Assert(b.FileContent("public/secret/index.html"), qt.Equals, content)
+}
@@ -14,6 +14,7 @@
 package hugolib
 
 import (
+	"bytes"
 	"fmt"
 	"html/template"
 	"os"
@@ -37,6 +38,7 @@ import (
 	"github.com/gohugoio/hugo/resources/page"
 	"github.com/gohugoio/hugo/resources/resource"
 	"github.com/spf13/afero"
+	jww "github.com/spf13/jwalterweatherman"
 
 	qt "github.com/frankban/quicktest"
 	"github.com/gohugoio/hugo/deps"
@@ -985,6 +987,114 @@ func TestWordCount(t *testing.T) {
 	testAllMarkdownEnginesForPages(t, assertFunc, nil, simplePageWithLongContent)
 }
 
+func TestSummaryModeHTML(t *testing.T) {
+	c := qt.New(t)
+
+	b := newTestSitesBuilder(t).WithConfigFile("toml", `
+baseURL = "https://example.org"
+summaryLength = 3
+[summary]
+mode = "html"
+[summary.sections]
+news = 6
+`)
+
+	b.WithContent(
+		"blog/p1.md", `---
+title: p1
+---
+
+## Intro
+
+{{< note >}}A note.{{< /note >}}
+
+Some *emphasized words here*. Second sentence[^1].
+
+[^1]: The footnote.
+`,
+		"news/p2.md", `---
+title: p2
+---
+
+One. Two words. Three more words. Four.
+`,
+		"news/p3.md", `---
+title: p3
+summary: From front matter.
+---
+
+Content.
+`,
+	)
+	b.WithTemplatesAdded(
+		"shortcodes/note.html", `<div class="note">{{ .Inner }}</div>`,
+		"_default/single.html", `Summary: {{ .Summary }}|Truncated: {{ .Truncated }}|`,
+	)
+
+	b.Build(BuildCfg{})
+
+	b.AssertFileContent("public/blog/p1/index.html", "Summary: Some <em>emphasized words here</em>.|Truncated: true|")
+	b.AssertFileContent("public/news/p2/index.html", "Summary: One. Two words. Three more words.|Truncated: true|")
+	b.AssertFileContent("public/news/p3/index.html", "Summary: From front matter.|")
+
+	b.WithConfigFile("toml", `
+[summary]
+mode = "foo"
+`)
+	c.Assert(b.CreateSitesE(), qt.ErrorMatches, `.*summary: invalid mode "foo", must be text or html`)
+}
+
+func TestReadingTime(t *testing.T) {
+	c := qt.New(t)
+
+	b := newTestSitesBuilder(t).WithConfigFile("toml", `
+baseURL = "https://example.org"
+defaultContentLanguage = "en"
+[readingTime]
+excludeCode = true
+[languages]
+[languages.en]
+weight = 1
+[languages.ja]
+weight = 2
+[languages.ja.readingTime]
+cjkCharsPerMinute = 334
+excludeCode = true
+`)
+
+	words := strings.Repeat("word ", 426)
+	code := "```\n" + strings.Repeat("code ", 1000) + "\n```\n"
+	kana := strings.Repeat("あ", 1002)
+
+	b.WithContent(
+		"p1.en.md", "---\ntitle: p1\n---\n"+words+"\n\n"+code,
+		"p1.ja.md", "---\ntitle: p1\n---\n"+kana+"\n\n"+words+"\n\n"+code,
+	)
+	b.WithTemplatesAdded("_default/single.html", `ReadingTime: {{ .ReadingTime }}|Duration: {{ .ReadingTimeDuration }}|Words: {{ .WordCount }}`)
+
+	b.Build(BuildCfg{})
+
+	b.AssertFileContent("public/p1/index.html", "ReadingTime: 2|Duration: 2m0s|Words: 1426")
+	b.AssertFileContent("public/ja/p1/index.html", "ReadingTime: 5|Duration: 5m0s")
+
+	b.WithConfigFile("toml", `
+[readingTime]
+wordsPerMinute = 0
+`)
+	c.Assert(b.CreateSitesE(), qt.ErrorMatches, ".*wordsPerMinute and cjkCharsPerMinute must be > 0")
+}
+
+func TestReadingTimeDefault(t *testing.T) {
+	c := qt.New(t)
+
+	// The defaults must give the same result as earlier Hugo versions.
+	for n := 0; n < 1000; n++ {
+		text := strings.Repeat("word ", n/2) + strings.Repeat("— ", n-n/2)
+		d := defaultReadingTimeConfig.duration(text)
+		c.Assert(readingMinutes(d), qt.Equals, (n+212)/213, qt.Commentf("%d words", n))
+	}
+}
+
 func TestPagePaths(t *testing.T) {
 	t.Parallel()
 	c := qt.New(t)
@@ -1268,6 +1378,71 @@ Content:{{ .Content }}
 	)
 }
 
+func TestPageContentLoadedOnDemand(t *testing.T) {
+	b := newTestSitesBuilder(t)
+	b.WithConfigFile("toml", `
+baseURL = "https://example.org"
+disableKinds = ["RSS", "sitemap", "taxonomy", "term"]
+`)
+
+	b.WithContent("posts/p1.md", `---
+title: "P1"
+---
+P1 content.
+`, "notes/n1.md", `---
+title: "N1"
+layout: "nocontent"
+---
+N1 content {{< unknown >}}.
+`)
+
+	b.WithTemplatesAdded(
+		"_default/single.html", "Single: {{ .Title }}|{{ .Content }}",
+		"_default/nocontent.html", "No content: {{ .Title }}",
+		"index.html", "{{ range .Site.RegularPages }}{{ .Title }}|{{ end }}",
+	)
+
+	b.Build(BuildCfg{})
+
+	b.AssertFileContent("public/index.html", "N1|P1|")
+	b.AssertFileContent("public/posts/p1/index.html", "Single: P1|<p>P1 content.</p>")
+	b.AssertFileContent("public/notes/n1/index.html", "No content: N1")
+
+	p1 := b.H.Sites[0].getPage(page.KindPage, "posts/p1.md").(*pageState)
+	n1 := b.H.Sites[0].getPage(page.KindPage, "notes/n1.md").(*pageState)
+	b.Assert(p1.source.parsed, qt.Not(qt.IsNil))
+	b.Assert(n1.source.parsed, qt.IsNil)
+
+	b.Assert(n1.RawContent(), qt.Equals, "N1 content {{< unknown >}}.\n")
+	b.Assert(n1.loadContent(), qt.Not(qt.IsNil))
+}
+
+func TestPageContentErrorNeverRendered(t *testing.T) {
+	b := newTestSitesBuilder(t)
+	b.WithConfigFile("toml", `
+baseURL = "https://example.org"
+disableKinds = ["RSS", "sitemap", "taxonomy", "term"]
+`)
+
+	b.WithContent("notes/n1.md", `---
+title: "N1"
+_build:
+  render: never
+---
+N1 content {{< unknown >}}.
+`)
+
+	b.WithTemplatesAdded(
+		"index.html", `{{ with .Site.GetPage "notes/n1" }}{{ .HasShortcode "foo" }}|{{ .RawContent }}{{ end }}`,
+	)
+
+	b.BuildFail(BuildCfg{})
+
+	n1 := b.H.Sites[0].getPage(page.KindPage, "notes/n1.md").(*pageState)
+	b.Assert(n1.loadContent(), qt.Not(qt.IsNil))
+	b.Assert(n1.loadContent().Error(), qt.Contains, "n1.md")
+}
+
 // https://github.com/gohugoio/hugo/issues/5781
 func TestPageWithZeroFile(t *testing.T) {
 	newTestSitesBuilder(t).WithLogger(loggers.NewWarningLogger()).WithSimpleConfigFile().
@@ -1330,7 +1505,7 @@ func TestShouldBuild(t *testing.T) {
 
 	for _, ps := range publishSettings {
 		s := shouldBuild(ps.buildFuture, ps.buildExpired, ps.buildDrafts, ps.draft,
-			ps.publishDate, ps.expiryDate)
+			ps.publishDate, ps.expiryDate, time.Now())
 		if s != ps.out {
 			t.Errorf("AssertShouldBuild unexpected output with params: %+v", ps)
 		}
@@ -1760,3 +1935,44 @@ Page1: {{ $p1.Path }}
 
 	b.AssertFileContent("public/index.html", "Lang: no", filepath.FromSlash("Page1: a/B/C/Page1.md"))
 }
+
+func TestPageFrontMatterSchema(t *testing.T) {
+	c := qt.New(t)
+
+	config := `
+baseURL = "https://example.org"
+disableKinds = ["RSS", "sitemap", "taxonomy", "term"]
+strictFrontMatter = %t
+[contentTypes.posts.fields]
+title = { required = true, type = "string" }
+category = { allowed = ["news", "blog"] }
+weight = { type = "int" }
+`
+
+	build := func(strict bool) (*sitesBuilder, string, error) {
+		var buf bytes.Buffer
+		b := newTestSitesBuilder(t).WithConfigFile("toml", fmt.Sprintf(config, strict))
+		b.WithLogger(loggers.NewBasicLoggerForWriter(jww.LevelWarn, &buf))
+		b.WithContent(
+			"posts/p1.md", "---\ntitle: P1\ncategory: news\n---\n",
+			"posts/p2.md", "---\nweight: 10\ncategory: misc\n---\n",
+			"posts/_index.md", "---\n---\n",
+			"other/p3.md", "---\ncategory: misc\n---\n",
+		)
+		b.WithTemplates("_default/single.html", `{{ .Title }}`)
+		err := b.BuildE(BuildCfg{})
+		return b, buf.String(), err
+	}
+
+	b, log, err := build(false)
+	c.Assert(err, qt.IsNil)
+	b.AssertFileContent("public/posts/p1/index.html", "P1")
+	c.Assert(log, qt.Contains, `p2.md:3:1": front matter field "category" has value "misc", must be one of "news", "blog"`)
+	c.Assert(log, qt.Contains, `p2.md:1:1": front matter field "title" is required`)
+	c.Assert(strings.Count(log, "front matter field"), qt.Equals, 2)
+	c.Assert(b.H.Log.LogCounters().ErrorCounter.Count(), qt.Equals, uint64(0))
+
+	_, log, err = build(true)
+	c.Assert(err, qt.ErrorMatches, "logged 2 error.*")
+	c.Assert(log, qt.Contains, `ERROR`)
+}
@@ -16,6 +16,9 @@ package hugolib
 import (
 	"fmt"
 	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path"
 	"path/filepath"
@@ -1343,3 +1346,65 @@ func TestPageBundlerHome(t *testing.T) {
 Title: Home|First Resource: data.json|Content: <p>Hook Len Page Resources 1</p>
 `)
 }
+
+func TestPageBundlerRemoteResources(t *testing.T) {
+	t.Parallel()
+
+	sunset, err := ioutil.ReadFile(filepath.FromSlash("testdata/sunset.jpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/images/sunset.jpg", "/image":
+			w.Write(sunset)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	b := newTestSitesBuilder(t).WithConfigFile("toml", `
+baseURL = "https://example.org"
+disableKinds = ["taxonomy", "term"]
+`)
+
+	b.WithContent("mybundle/index.md", fmt.Sprintf(`---
+title: My Bundle
+resources:
+- src: %s/images/sunset.jpg
+  name: hero.jpg
+  title: Hero
+- src: %s/image
+  params:
+    credit: Bep
+---
+`, srv.URL, srv.URL), "mybundle/local.txt", "Local")
+
+	b.WithTemplates("_default/single.html", `
+Len: {{ len .Resources }}|
+{{ with .Resources.GetMatch "hero.jpg" }}Hero: {{ .Title }}|{{ .RelPermalink }}|{{ .MediaType }}|{{ with .Resize "100x" }}{{ .Width }}{{ end }}{{ end }}|
+{{ with .Resources.GetMatch "image.jpg" }}Image: {{ .Params.credit }}|{{ .RelPermalink }}{{ end }}|
+Images: {{ len (.Resources.ByType "image") }}|
+`)
+
+	b.Build(BuildCfg{})
+
+	b.AssertFileContent("public/mybundle/index.html",
+		"Len: 3|",
+		"Hero: Hero|/mybundle/sunset.jpg|image/jpeg|100|",
+		"Image: Bep|/mybundle/image.jpg|",
+		"Images: 2|",
+	)
+	b.Assert(b.CheckExists("public/mybundle/sunset.jpg"), qt.Equals, true)
+
+	b = newTestSitesBuilder(t)
+	b.WithContent("p1/index.md", fmt.Sprintf(`---
+title: P1
+resources:
+- src: %s/missing.jpg
+---
+`, srv.URL))
+	b.Assert(b.BuildE(BuildCfg{}), qt.ErrorMatches, `.*failed to fetch remote resource.*404 Not Found`)
+}
@@ -27,19 +27,30 @@ import (
 
 	"github.com/gohugoio/hugo/common/herrors"
 	"github.com/gohugoio/hugo/hugofs"
+	"github.com/gohugoio/hugo/metrics"
 )
 
-func newPagesProcessor(h *HugoSites, sp *source.SourceSpec) *pagesProcessor {
+func newPagesProcessor(h *HugoSites, sp *source.SourceSpec, progress *metrics.ProgressPhase) *pagesProcessor {
 	procs := make(map[string]pagesCollectorProcessorProvider)
 	for _, s := range h.Sites {
 		procs[s.Lang()] = &sitePagesProcessor{
 			m:           s.pageMap,
 			errorSender: s.h,
+			progress:    progress,
 			itemChan:    make(chan interface{}, config.GetNumWorkerMultiplier()*2),
 		}
 	}
+
+	fallbacks := make(map[string][]pagesCollectorProcessorProvider)
+	for _, s := range h.Sites {
+		for _, lang := range s.fallbackLanguages() {
+			fallbacks[lang] = append(fallbacks[lang], procs[s.Lang()])
+		}
+	}
+
 	return &pagesProcessor{
-		procs: procs,
+		procs:     procs,
+		fallbacks: fallbacks,
 	}
 }
 
@@ -52,6 +63,9 @@ type pagesCollectorProcessorProvider interface {
 type pagesProcessor struct {
 	// Per language/Site
 	procs map[string]pagesCollectorProcessorProvider
+
+	// The sites rendering missing pages from a language, mapped to that language.
+	fallbacks map[string][]pagesCollectorProcessorProvider
 }
 
 func (proc *pagesProcessor) Process(item interface{}) error {
@@ -60,9 +74,19 @@ func (proc *pagesProcessor) Process(item interface{}) error {
 	case pageBundles:
 		for _, vv := range v {
 			proc.getProcFromFi(vv.header).Process(vv)
+			if vv.header.Meta().Classifier() != files.ContentClassBranch {
+				for _, p := range proc.fallbacks[vv.header.Meta().Lang()] {
+					p.Process(vv)
+				}
+			}
 		}
 	case hugofs.FileMetaInfo:
 		proc.getProcFromFi(v).Process(v)
+		if v.Meta().Classifier() == files.ContentClassContent {
+			for _, p := range proc.fallbacks[v.Meta().Lang()] {
+				p.Process(v)
+			}
+		}
 	default:
 		panic(fmt.Sprintf("unrecognized item type in Process: %T", item))
 
@@ -115,6 +139,9 @@ type sitePagesProcessor struct {
 	m           *pageMap
 	errorSender herrors.ErrorSender
 
+	// Counts the files processed.
+	progress *metrics.ProgressPhase
+
 	itemChan  chan interface{}
 	itemGroup *errgroup.Group
 }
@@ -165,10 +192,12 @@ func (p *sitePagesProcessor) doProcess(item interface{}) error {
 		if err := m.AddFilesBundle(v.header, v.resources...); err != nil {
 			return err
 		}
+		p.progress.Add(1 + len(v.resources))
 	case hugofs.FileMetaInfo:
 		if p.shouldSkip(v) {
 			return nil
 		}
+		p.progress.Incr()
 		meta := v.Meta()
 
 		classifier := meta.Classifier()
@@ -0,0 +1,114 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"bytes"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/common/loggers"
+	jww "github.com/spf13/jwalterweatherman"
+
+	"github.com/gohugoio/hugo/plugins"
+	"github.com/gohugoio/hugo/plugins/pluginstest"
+)
+
+func TestPlugins(t *testing.T) {
+	b := newTestSitesBuilder(t).WithConfigFile("toml", `
+baseURL = "https://example.org"
+disableKinds = ["RSS", "sitemap", "taxonomy", "term", "home", "section"]
+
+[[plugins]]
+name = "content"
+path = "plugins/content.wasm"
+[[plugins]]
+name = "resource"
+path = "plugins/resource.wasm"
+[[plugins]]
+name = "output"
+path = "plugins/output.wasm"
+`)
+
+	b.WithSourceFile(
+		"plugins/content.wasm", string(pluginstest.Upper(plugins.KindContent)),
+		"plugins/resource.wasm", string(pluginstest.Upper(plugins.KindResource)),
+		"plugins/output.wasm", string(pluginstest.Upper(plugins.KindOutput)),
+		"assets/css/main.css", "body { color: red; }",
+	)
+	b.WithContent("p1.md", "---\ntitle: p1\nlayout: single\n---\n*Hello* world.")
+	b.WithTemplates(
+		"_default/single.html", `{{ .Title }}|{{ .Content }}|{{ (resources.Get "css/main.css" | resources.Plugin "resource").Content }}`,
+		"_default/single.json", `{{ .Title }}`,
+	)
+	b.Build(BuildCfg{})
+
+	// The content plugin is applied before the output plugin.
+	b.AssertFileContent("public/p1/index.html", "P1|<P><EM>HELLO</EM> WORLD.</P>\n|BODY { COLOR: RED; }")
+
+	b = newTestSitesBuilder(t).WithConfigFile("toml", `
+baseURL = "https://example.org"
+[[plugins]]
+name = "output"
+path = "plugins/output.wasm"
+`)
+	b.WithSourceFile("plugins/output.wasm", string(pluginstest.Upper(plugins.KindOutput)))
+	b.WithTemplates("index.html", `{{ resources.FromString "a.css" "a" | resources.Plugin "output" }}`)
+	b.Assert(b.BuildE(BuildCfg{}), qt.ErrorMatches, `.*plugin "output" does not implement resource transformations.*`)
+}
+
+func TestPluginBuildHooks(t *testing.T) {
+	// Logs the hook context as a warning.
+	logContext := pluginstest.BuildModule([]string{"log"}, pluginstest.Func{
+		Export: "hugo_on_pages_assembled",
+		Params: 4,
+		Body:   []byte{0x41, 0x01, 0x20, 0x00, 0x20, 0x01, 0x10, 0x00, 0x41, 0x00}, // log(1, ctxPtr, ctxLen); return 0
+	})
+
+	// Fails with the rendered page as the error message.
+	fail := pluginstest.BuildModule([]string{"error"}, pluginstest.Func{
+		Export: "hugo_on_page_rendered",
+		Params: 4,
+		Body:   []byte{0x20, 0x02, 0x20, 0x03, 0x10, 0x00, 0x41, 0x00}, // error(ptr, len); return 0
+	})
+
+	config := `
+baseURL = "https://example.org"
+disableKinds = ["RSS", "sitemap", "taxonomy", "term", "home", "section"]
+
+[[plugins]]
+name = "log"
+path = "plugins/log.wasm"
+capabilities = ["log"]
+`
+
+	var buf bytes.Buffer
+	b := newTestSitesBuilder(t).WithConfigFile("toml", config).WithLogger(loggers.NewBasicLoggerForWriter(jww.LevelWarn, &buf))
+	b.WithSourceFile("plugins/log.wasm", string(logContext), "plugins/fail.wasm", string(fail))
+	b.WithContent("p1.md", "---\ntitle: P1\n---\nContent.")
+	b.WithTemplates("_default/single.html", `Single: {{ .Title }}`)
+	b.Build(BuildCfg{})
+
+	b.Assert(buf.String(), qt.Contains, `plugin "log": {"hook":"pages_assembled","pages":[{"kind":"page","lang":"en","path":"p1.md","title":"P1","permalink":"https://example.org/p1/","relPermalink":"/p1/"`)
+
+	b = newTestSitesBuilder(t).WithConfigFile("toml", config+`
+[[plugins]]
+name = "fail"
+path = "plugins/fail.wasm"
+`)
+	b.WithSourceFile("plugins/log.wasm", string(logContext), "plugins/fail.wasm", string(fail))
+	b.WithContent("p1.md", "---\ntitle: P1\n---\nContent.")
+	b.WithTemplates("_default/single.html", `Single: {{ .Title }}`)
+	b.Assert(b.BuildE(BuildCfg{}), qt.ErrorMatches, `.*plugin "fail": page_rendered: Single: P1`)
+}
@@ -0,0 +1,106 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gohugoio/hugo/config"
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+)
+
+const readingTimeConfigKey = "readingTime"
+
+var defaultReadingTimeConfig = readingTimeConfig{
+	WordsPerMinute:    213,
+	CJKCharsPerMinute: 501,
+}
+
+// readingTimeConfig configures how .ReadingTime is estimated.
+type readingTimeConfig struct {
+	// The reading speed for words separated by spaces.
+	WordsPerMinute int
+
+	// The reading speed for Chinese, Japanese and Korean text, which is
+	// counted by characters.
+	CJKCharsPerMinute int
+
+	// Whether to ignore the text in code blocks.
+	ExcludeCode bool
+}
+
+func decodeReadingTimeConfig(cfg config.Provider) (readingTimeConfig, error) {
+	c := defaultReadingTimeConfig
+
+	if !cfg.IsSet(readingTimeConfigKey) {
+		return c, nil
+	}
+
+	if err := mapstructure.WeakDecode(cfg.GetStringMap(readingTimeConfigKey), &c); err != nil {
+		return c, errors.Wrapf(err, "failed to decode %s config", readingTimeConfigKey)
+	}
+
+	if c.WordsPerMinute <= 0 || c.CJKCharsPerMinute <= 0 {
+		return c, errors.Errorf("%s: wordsPerMinute and cjkCharsPerMinute must be > 0", readingTimeConfigKey)
+	}
+
+	return c, nil
+}
+
+var codeBlockRe = regexp.MustCompile(`(?is)<pre[\s>].*?</pre>`)
+
+// readingText returns the HTML to estimate the reading time from.
+func (c readingTimeConfig) readingText(content string) string {
+	if c.ExcludeCode {
+		return codeBlockRe.ReplaceAllString(content, "")
+	}
+	return content
+}
+
+// duration estimates the time it takes to read the plain text s. CJK
+// characters are counted one by one, everything else by words. As in
+// earlier Hugo versions, any other whitespace separated field, e.g. a dash,
+// counts as a word, so the default settings give the same result for text
+// without CJK characters.
+func (c readingTimeConfig) duration(s string) time.Duration {
+	var words, cjkChars int
+
+	for _, field := range strings.Fields(s) {
+		var cjk, other bool
+		for _, r := range field {
+			if helpers.IsCJK(r) {
+				cjk = true
+				cjkChars++
+			} else if unicode.IsLetter(r) || unicode.IsNumber(r) {
+				other = true
+			}
+		}
+		if other || !cjk {
+			words++
+		}
+	}
+
+	return time.Duration(words)*time.Minute/time.Duration(c.WordsPerMinute) +
+		time.Duration(cjkChars)*time.Minute/time.Duration(c.CJKCharsPerMinute)
+}
+
+// readingMinutes rounds d up to whole minutes.
+func readingMinutes(d time.Duration) int {
+	return int((d + time.Minute - 1) / time.Minute)
+}
@@ -919,6 +919,43 @@ Hello2: Bonjour
 `)
 }
 
+func TestFromStringAndExecuteAsTemplateWithMediaType(t *testing.T) {
+	b := newTestSitesBuilder(t)
+	b.WithContent("p1.md", "---\ntitle: P1\n---", "p2.md", "---\ntitle: P2\n---")
+	b.WithTemplates("index.html", `
+{{ $ld := "{}" | resources.FromString "ld.json" "application/ld+json" }}
+{{ $default := "{}" | resources.FromString "default.json" }}
+{{ $tpl := "{{ .Kind }}" | resources.FromString "tpl.txt" }}
+{{ $json := $tpl | resources.ExecuteAsTemplate "home.json" "application/json" . }}
+{{ $lazy := "{{ fail }}" | resources.FromString "fail.txt" | resources.ExecuteAsTemplate "lazy.txt" . }}
+{{ $unused := "unused" | resources.FromString "unused.txt" }}
+LD: {{ $ld.MediaType.Type | safeHTML }}|{{ $ld.RelPermalink }}|
+Default: {{ $default.MediaType.Type }}|
+JSON: {{ $json.MediaType.Type }}|{{ $json.Content }}|
+Pages: {{ range site.RegularPages }}{{ $r := .Title | resources.FromString "pages/title.txt" }}{{ $r.Content }}|{{ end }}
+`)
+	b.WithTemplates("_default/single.html", "{{ .Title }}", "_default/list.html", "")
+
+	b.Build(BuildCfg{})
+
+	b.AssertFileContent("public/index.html",
+		"LD: application/ld+json|/ld.json|",
+		"Default: application/json|",
+		"JSON: application/json|home|",
+		// FromString resources with the same target path, but different content.
+		"Pages: P1|P2|",
+	)
+	b.Assert(b.CheckExists("public/ld.json"), qt.Equals, true)
+
+	// These are never accessed, so never executed or published.
+	b.Assert(b.CheckExists("public/lazy.txt"), qt.Equals, false)
+	b.Assert(b.CheckExists("public/unused.txt"), qt.Equals, false)
+
+	b = newTestSitesBuilder(t)
+	b.WithTemplates("index.html", `{{ "{}" | resources.FromString "ld.json" "foo" }}`)
+	b.Assert(b.BuildE(BuildCfg{}), qt.ErrorMatches, `.*invalid media type.*`)
+}
+
 func TestResourceChainPostCSS(t *testing.T) {
 	if !htesting.IsCI() {
 		t.Skip("skip (relative) long running modules test when running locally")
@@ -14,10 +14,12 @@
 package hugolib
 
 import (
+	"fmt"
 	"path/filepath"
 	"strings"
 	"testing"
 
+	qt "github.com/frankban/quicktest"
 	"github.com/gohugoio/hugo/deps"
 )
 
@@ -98,3 +100,40 @@ Figure:
 
 	b.AssertFileContent("public/index.xml", "img src=&#34;http://example.com/images/sunset.jpg")
 }
+
+func TestRSSFeedConfig(t *testing.T) {
+	t.Parallel()
+
+	b := newTestSitesBuilder(t).WithConfigFile("toml", `
+baseURL = "https://example.org"
+disableKinds = ["taxonomy", "term"]
+[services.rss]
+limit = 3
+excludeSections = ["legal"]
+[services.rss.kinds.section]
+fullContent = true
+[services.rss.sections.blog]
+includeSubpages = true
+limit = 10
+`)
+
+	page := "---\ntitle: %s\ndate: 2021-06-%02d\n---\nContent of %s.\n\n<!--more-->\n\nMore."
+	for i, p := range []string{"blog/b1", "blog/b2", "blog/sub/b3", "docs/d1", "docs/sub/d2", "legal/l1"} {
+		b.WithContent(p+".md", fmt.Sprintf(page, p, i+1, p))
+	}
+	b.WithContent("blog/sub/_index.md", "---\ntitle: Sub\n---", "docs/sub/_index.md", "---\ntitle: Sub\n---")
+
+	b.Build(BuildCfg{})
+
+	// Home: limit 3, legal excluded, summaries.
+	b.AssertFileContent("public/index.xml", "<title>docs/sub/d2</title>", "<title>docs/d1</title>", "<title>blog/sub/b3</title>", "<description>&lt;p&gt;Content of docs/d1.&lt;/p&gt;</description>")
+	b.Assert(b.FileContent("public/index.xml"), qt.Not(qt.Contains), "legal/l1")
+	b.Assert(b.FileContent("public/index.xml"), qt.Not(qt.Contains), "blog/b2")
+
+	// Blog: includes the sub section, full content.
+	b.AssertFileContent("public/blog/index.xml", "<title>blog/sub/b3</title>", "<title>blog/b2</title>", "<title>blog/b1</title>", "More.")
+
+	// Docs: direct pages only, full content.
+	b.AssertFileContent("public/docs/index.xml", "<title>docs/d1</title>", "More.")
+	b.Assert(b.FileContent("public/docs/index.xml"), qt.Not(qt.Contains), "docs/sub/d2")
+}
@@ -0,0 +1,209 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"sort"
+
+	"github.com/gohugoio/hugo/common/maps"
+	"github.com/gohugoio/hugo/config"
+	"github.com/gohugoio/hugo/resources/page"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+	"github.com/spf13/cast"
+)
+
+// The key of the series in front matter, the site config, the data folder,
+// e.g. data/series/go-basics.yaml, and of the series taxonomy.
+const seriesKey = "series"
+
+type seriesConfig struct {
+	// Whether to add the series taxonomy, which renders the series landing
+	// pages, e.g. /series/go-basics/.
+	LandingPages bool
+}
+
+func decodeSeriesConfig(cfg config.Provider) (seriesConfig, error) {
+	var c seriesConfig
+	if !cfg.IsSet(seriesKey) {
+		return c, nil
+	}
+	if err := mapstructure.WeakDecode(cfg.GetStringMap(seriesKey), &c); err != nil {
+		return c, errors.Wrap(err, "failed to decode series config")
+	}
+	return c, nil
+}
+
+// seriesDefinition is a series defined in the data folder.
+type seriesDefinition struct {
+	Title string
+
+	// Refs to the pages in the series, in order. Pages with the series set
+	// in front matter, but not listed, are added after these.
+	Pages []string
+}
+
+// siteSeries holds the series in a site.
+type siteSeries struct {
+	byKey  map[string]*page.Series
+	byPage map[page.Page]*page.PageSeries
+}
+
+// Series returns the series the page belongs to, nil if none. For the series
+// landing page this is the series at position 0.
+func (p *pageState) Series() *page.PageSeries {
+	if !p.IsPage() && p.Kind() != page.KindTerm {
+		return nil
+	}
+
+	v, err := p.s.init.series.Do()
+	if err != nil {
+		p.s.h.FatalError(p.wrapError(err))
+		return nil
+	}
+	series := v.(*siteSeries)
+
+	if p.IsPage() {
+		return series.byPage[p]
+	}
+
+	sections := p.SectionsEntries()
+	if len(sections) != 2 || sections[0] != seriesKey {
+		return nil
+	}
+	if s, found := series.byKey[p.s.getTaxonomyKey(sections[1])]; found {
+		return &page.PageSeries{Series: s}
+	}
+
+	return nil
+}
+
+func (s *Site) assembleSeries() (*siteSeries, error) {
+	series := &siteSeries{
+		byKey:  make(map[string]*page.Series),
+		byPage: make(map[page.Page]*page.PageSeries),
+	}
+
+	// The explicit position in the series definition, 1-based.
+	listed := make(map[page.Page]int)
+
+	get := func(name string) *page.Series {
+		key := s.getTaxonomyKey(name)
+		ser, found := series.byKey[key]
+		if !found {
+			ser = &page.Series{Key: key, Title: name}
+			series.byKey[key] = ser
+		}
+		return ser
+	}
+
+	add := func(ser *page.Series, p page.Page) {
+		if _, found := series.byPage[p]; found {
+			// A page belongs to one series only.
+			return
+		}
+		ser.Pages = append(ser.Pages, p)
+		series.byPage[p] = &page.PageSeries{Series: ser}
+	}
+
+	if v := s.h.Data()[seriesKey]; v != nil {
+		m, err := maps.ToStringMapE(v)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode series in data")
+		}
+		for name, vv := range m {
+			var def seriesDefinition
+			if err := mapstructure.WeakDecode(vv, &def); err != nil {
+				return nil, errors.Wrapf(err, "failed to decode series %q in data", name)
+			}
+			ser := get(name)
+			if def.Title != "" {
+				ser.Title = def.Title
+			}
+			for i, ref := range def.Pages {
+				p, err := s.getPageNew(nil, ref)
+				if err != nil {
+					return nil, errors.Wrapf(err, "series %q", name)
+				}
+				if p == nil {
+					return nil, errors.Errorf("series %q: page %q not found", name, ref)
+				}
+				if _, found := listed[p]; !found {
+					listed[p] = i + 1
+				}
+				add(ser, p)
+			}
+		}
+	}
+
+	for _, p := range s.RegularPages() {
+		v, found := p.Params()[seriesKey]
+		if !found {
+			continue
+		}
+		name, ok := v.(string)
+		if !ok {
+			// The series is a list if it's also a taxonomy.
+			names, err := cast.ToStringSliceE(v)
+			if err != nil || len(names) == 0 {
+				continue
+			}
+			name = names[0]
+		}
+		if name == "" {
+			continue
+		}
+		add(get(name), p)
+	}
+
+	for key, ser := range series.byKey {
+		sortSeriesPages(ser.Pages, listed)
+		for i, p := range ser.Pages {
+			series.byPage[p].Position = i + 1
+		}
+		ser.Page, _ = s.getPageNew(nil, "/"+seriesKey+"/"+key)
+	}
+
+	return series, nil
+}
+
+// sortSeriesPages sorts the pages in a series by their position in the series
+// definition, then by series_weight, date and title.
+func sortSeriesPages(pages page.Pages, listed map[page.Page]int) {
+	weight := func(p page.Page) int {
+		return cast.ToInt(p.Params()[seriesKey+"_weight"])
+	}
+
+	sort.SliceStable(pages, func(i, j int) bool {
+		p1, p2 := pages[i], pages[j]
+		l1, l2 := listed[p1], listed[p2]
+		if l1 != l2 {
+			if l1 == 0 || l2 == 0 {
+				return l2 == 0
+			}
+			return l1 < l2
+		}
+		w1, w2 := weight(p1), weight(p2)
+		if w1 != w2 {
+			if w1 == 0 || w2 == 0 {
+				return w2 == 0
+			}
+			return w1 < w2
+		}
+		if !p1.Date().Equal(p2.Date()) {
+			return p1.Date().Before(p2.Date())
+		}
+		return p1.Title() < p2.Title()
+	})
+}
@@ -0,0 +1,82 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestSeries(t *testing.T) {
+	files := []string{
+		"content/go/intro.md", "---\ntitle: Intro\nseries: Go Basics\ndate: 2021-01-03\n---\n",
+		"content/go/types.md", "---\ntitle: Types\nseries: Go Basics\nseries_weight: 2\ndate: 2021-01-01\n---\n",
+		"content/go/setup.md", "---\ntitle: Setup\nseries: Go Basics\nseries_weight: 1\ndate: 2021-01-02\n---\n",
+		"content/go/funcs.md", "---\ntitle: Funcs\nseries: Go Basics\ndate: 2021-01-04\n---\n",
+		"content/hugo/one.md", "---\ntitle: Hugo One\ndate: 2021-01-01\n---\n",
+		"content/hugo/two.md", "---\ntitle: Hugo Two\nseries: hugo\n---\n",
+		"content/other.md", "---\ntitle: Other\n---\n",
+	}
+
+	templates := []string{
+		"_default/single.html", `{{ .Title }}|{{ with .Series }}{{ .Title }}|{{ .Position }}/{{ .Len }}|Prev: {{ with .Prev }}{{ .Title }}{{ end }}|Next: {{ with .Next }}{{ .Title }}{{ end }}|First: {{ .IsFirst }}|Last: {{ .IsLast }}|Landing: {{ with .Page }}{{ .RelPermalink }}{{ end }}{{ else }}No series{{ end }}`,
+		"_default/term.html", `Term: {{ .Title }}|{{ with .Series }}{{ .Title }}|{{ .Position }}|{{ range .Pages }}{{ .Title }}, {{ end }}{{ end }}`,
+		"_default/list.html", `List: {{ .Title }}`,
+	}
+
+	c := qt.New(t)
+
+	c.Run("Front matter and data", func(c *qt.C) {
+		b := newTestSitesBuilder(c).WithConfigFile("toml", `
+baseURL = "https://example.org"
+[series]
+landingPages = true
+`)
+		b.WithSourceFile(files...)
+		b.WithSourceFile("data/series/hugo.yaml", "title: Hugo Tutorial\npages: [\"/hugo/one\"]\n")
+		b.WithTemplatesAdded(templates...)
+		b.Build(BuildCfg{})
+
+		b.AssertFileContent("public/go/setup/index.html", "Setup|Go Basics|1/4|Prev: |Next: Types|First: true|Last: false|Landing: /series/go-basics/")
+		b.AssertFileContent("public/go/types/index.html", "Types|Go Basics|2/4|Prev: Setup|Next: Intro|")
+		b.AssertFileContent("public/go/intro/index.html", "Intro|Go Basics|3/4|Prev: Types|Next: Funcs|")
+		b.AssertFileContent("public/go/funcs/index.html", "Funcs|Go Basics|4/4|Prev: Intro|Next: |First: false|Last: true")
+		b.AssertFileContent("public/hugo/one/index.html", "Hugo One|Hugo Tutorial|1/2|Prev: |Next: Hugo Two|")
+		b.AssertFileContent("public/hugo/two/index.html", "Hugo Two|Hugo Tutorial|2/2|Prev: Hugo One|")
+		b.AssertFileContent("public/other/index.html", "Other|No series")
+		b.AssertFileContent("public/series/go-basics/index.html", "Term: Go Basics|Go Basics|0|Setup, Types, Intro, Funcs, ")
+		b.AssertFileContent("public/series/hugo/index.html", "Term: hugo|Hugo Tutorial|0|Hugo One, Hugo Two, ")
+	})
+
+	c.Run("No landing pages", func(c *qt.C) {
+		b := newTestSitesBuilder(c).WithSimpleConfigFile()
+		b.WithSourceFile(files...)
+		b.WithTemplatesAdded(templates...)
+		b.Build(BuildCfg{})
+
+		b.AssertFileContent("public/go/setup/index.html", "Setup|Go Basics|1/4|Prev: |Next: Types|First: true|Last: false|Landing: \n")
+		b.Assert(b.CheckExists("public/series/go-basics/index.html"), qt.IsFalse)
+	})
+
+	c.Run("Page not found", func(c *qt.C) {
+		b := newTestSitesBuilder(c).WithSimpleConfigFile()
+		b.WithSourceFile(files...)
+		b.WithSourceFile("data/series/hugo.yaml", "pages: [\"/hugo/three\"]\n")
+		b.WithTemplatesAdded(templates...)
+		err := b.BuildE(BuildCfg{})
+		b.Assert(err, qt.Not(qt.IsNil))
+		b.Assert(err.Error(), qt.Contains, `series "hugo": page "/hugo/three" not found`)
+	})
+}
@@ -67,6 +67,9 @@ type ShortcodeWithPage struct {
 	pos       text.Position
 
 	scratch *maps.Scratch
+
+	registeredMu sync.Mutex
+	registered   map[string][]interface{}
 }
 
 // Position returns this shortcode's detailed position. Note that this information
@@ -106,6 +109,64 @@ func (scp *ShortcodeWithPage) Scratch() *maps.Scratch {
 	return scp.scratch
 }
 
+// Root returns the outermost shortcode in this shortcode's tree, which may be
+// this shortcode. Use .Root.Scratch for state shared by all the shortcodes
+// in a tree.
+func (scp *ShortcodeWithPage) Root() *ShortcodeWithPage {
+	root := scp
+	for root.Parent != nil {
+		root = root.Parent
+	}
+	return root
+}
+
+// Ancestor returns the closest enclosing shortcode with the given name, or
+// nil if not found.
+func (scp *ShortcodeWithPage) Ancestor(name string) *ShortcodeWithPage {
+	for p := scp.Parent; p != nil; p = p.Parent {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// GetInherited looks up the named parameter key in this shortcode, and if
+// not set, in the enclosing shortcodes, closest first.
+func (scp *ShortcodeWithPage) GetInherited(key string) interface{} {
+	for p := scp; p != nil; p = p.Parent {
+		if params, ok := p.Params.(map[string]interface{}); ok {
+			if v, found := params[key]; found {
+				return v
+			}
+		}
+	}
+	return nil
+}
+
+// Register adds v to the values registered with this shortcode under key,
+// typically called by the nested shortcodes on .Parent or an .Ancestor.
+// The nested shortcodes are rendered before the shortcode enclosing them,
+// so it can use .Registered to e.g. render the tab headers from the tabs
+// inside it.
+func (scp *ShortcodeWithPage) Register(key string, v interface{}) string {
+	scp.registeredMu.Lock()
+	defer scp.registeredMu.Unlock()
+	if scp.registered == nil {
+		scp.registered = make(map[string][]interface{})
+	}
+	scp.registered[key] = append(scp.registered[key], v)
+	return ""
+}
+
+// Registered returns the values registered with this shortcode under key,
+// in the order they were registered.
+func (scp *ShortcodeWithPage) Registered(key string) []interface{} {
+	scp.registeredMu.Lock()
+	defer scp.registeredMu.Unlock()
+	return scp.registered[key]
+}
+
 // Get is a convenience method to look up shortcode parameters by its key.
 func (scp *ShortcodeWithPage) Get(key interface{}) interface{} {
 	if scp.Params == nil {
@@ -321,9 +382,18 @@ func renderShortcode(
 		hasVariants = hasVariants || more
 	}
 
-	data := &ShortcodeWithPage{Ordinal: sc.ordinal, posOffset: sc.pos, Params: sc.params, Page: newPageForShortcode(p), Parent: parent, Name: sc.name}
-	if sc.params != nil {
-		data.IsNamedParams = reflect.TypeOf(sc.params).Kind() == reflect.Map
+	params := sc.params
+	if info, ok := tmpl.(tpl.Info); ok && len(info.ParseInfo().Config.Params) > 0 {
+		var err error
+		params, err = checkShortcodeParams(info.ParseInfo().Config.Params, sc.params)
+		if err != nil {
+			return "", false, p.parseError(herrors.WithCode(herrors.CodeShortcode, errors.Wrapf(err, "shortcode %q", sc.name)), p.source.parsed.Input(), sc.pos)
+		}
+	}
+
+	data := &ShortcodeWithPage{Ordinal: sc.ordinal, posOffset: sc.pos, Params: params, Page: newPageForShortcode(p), Parent: parent, Name: sc.name}
+	if params != nil {
+		data.IsNamedParams = reflect.TypeOf(params).Kind() == reflect.Map
 	}
 
 	if len(sc.inner) > 0 {
@@ -398,6 +468,31 @@ func renderShortcode(
 	return result, hasVariants, err
 }
 
+// checkShortcodeParams validates the named parameters in params against
+// the declarations in the shortcode template and applies the defaults.
+// Positional parameters are not checked.
+func checkShortcodeParams(decls map[string]tpl.Param, params interface{}) (interface{}, error) {
+	var named map[string]interface{}
+	switch v := params.(type) {
+	case nil:
+	case map[string]interface{}:
+		named = v
+	default:
+		return params, nil
+	}
+
+	checked, err := tpl.CheckParams(decls, named, "parameter")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(checked) == 0 {
+		return params, nil
+	}
+
+	return checked, nil
+}
+
 func (s *shortcodeHandler) hasShortcodes() bool {
 	return s != nil && len(s.shortcodes) > 0
 }
@@ -415,7 +510,7 @@ func (s *shortcodeHandler) renderShortcodesForPage(p *pageState, f output.Format
 	for _, v := range s.shortcodes {
 		s, more, err := renderShortcode(0, s.s, tplVariants, v, nil, p)
 		if err != nil {
-			err = p.parseError(errors.Wrapf(err, "failed to render shortcode %q", v.name), p.source.parsed.Input(), v.pos)
+			err = p.parseError(herrors.WithCode(herrors.CodeShortcode, errors.Wrapf(err, "failed to render shortcode %q", v.name)), p.source.parsed.Input(), v.pos)
 			return nil, false, err
 		}
 		hasVariants = hasVariants || more
@@ -20,6 +20,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/gohugoio/hugo/common/herrors"
 	"github.com/gohugoio/hugo/config"
 	"github.com/gohugoio/hugo/markup/asciidocext"
 	"github.com/gohugoio/hugo/markup/rst"
@@ -286,6 +287,29 @@ func TestParentShortcode(t *testing.T) {
 		"1: p1 1: 2: p1p2 2: 3: p1p2p3 ", wt)
 }
 
+func TestShortcodeTree(t *testing.T) {
+	t.Parallel()
+
+	b := newTestSitesBuilder(t).WithSimpleConfigFile()
+	b.WithTemplatesAdded(
+		"layouts/shortcodes/tabs.html", `{{ .Root.Scratch.Get "count" }}<ul>{{ range .Registered "tab" }}<li>{{ .title }}</li>{{ end }}</ul>{{ .Inner }}`,
+		"layouts/shortcodes/tab.html", `{{ (.Ancestor "tabs").Register "tab" (dict "title" (.Get "title")) }}{{ .Root.Scratch.Add "count" 1 }}<div class="{{ .GetInherited "style" }}">{{ .Inner }}</div>`,
+		"layouts/shortcodes/group.html", `{{ .Inner }}`,
+		"layouts/shortcodes/note.html", `Root: {{ .Root.Name }}|{{ with .Ancestor "tabs" }}{{ .Name }}{{ end }}|{{ with .Ancestor "foo" }}{{ .Name }}{{ end }}|{{ .GetInherited "missing" }}`,
+	)
+	b.WithContent("page.md", `---
+title: "Page"
+---
+
+{{< tabs style="plain" >}}{{< tab title="One" >}}1{{< /tab >}}{{< group >}}{{< tab title="Two" style="fancy" >}}{{< note >}}{{< /tab >}}{{< /group >}}{{< /tabs >}}
+`)
+	b.Build(BuildCfg{})
+
+	b.AssertFileContent("public/page/index.html",
+		`2<ul><li>One</li><li>Two</li></ul><div class="plain">1</div><div class="fancy">Root: tabs|tabs||</div>`,
+	)
+}
+
 func TestFigureOnlySrc(t *testing.T) {
 	t.Parallel()
 	CheckShortCodeMatch(t, `{{< figure src="/found/here" >}}`, "<figure><img src=\"/found/here\"/>\n</figure>", nil)
@@ -1272,6 +1296,72 @@ Get: {{ printf "%v (%T)" $b1 $b1 | safeHTML }}
 	)
 }
 
+func TestShortcodeParamDeclarations(t *testing.T) {
+	t.Parallel()
+
+	shortcode := "{{ $_hugo_config := `" + `{ "params": {
+	"title": { "type": "string", "required": true },
+	"width": { "type": "int", "default": "300" },
+	"zoom": { "type": "bool" }
+}}` + "` }}" + `
+{{ range $k, $v := .Params }}{{ printf "%s: %v (%T)" $k $v $v | safeHTML }}|{{ end }}`
+
+	newBuilder := func(t testing.TB, content string) *sitesBuilder {
+		b := newTestSitesBuilder(t).WithSimpleConfigFile()
+		b.WithTemplatesAdded("layouts/shortcodes/figure2.html", shortcode)
+		b.WithContent("page.md", `---
+title: "Page"
+---
+
+`+content)
+		return b
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		b := newBuilder(t, `
+{{< figure2 title="Sunset" width="640" zoom=true >}}
+
+{{< figure2 title="Sunset" >}}
+`)
+		b.Build(BuildCfg{})
+
+		b.AssertFileContent("public/page/index.html",
+			"title: Sunset (string)|width: 640 (int)|zoom: true (bool)|",
+			"title: Sunset (string)|width: 300 (int)|\n",
+		)
+	})
+
+	for _, test := range []struct {
+		name    string
+		content string
+		expect  string
+	}{
+		{"Missing", `{{< figure2 width=10 >}}`, `missing required parameter "title"`},
+		{"Unknown", `{{< figure2 title="a" heigth=10 >}}`, `unknown parameter "heigth"`},
+		{"Type", `{{< figure2 title="a" width="wide" >}}`, `parameter "width": "wide" is not a valid int`},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			b := newBuilder(t, "Text\n\n"+test.content)
+			err := b.BuildE(BuildCfg{})
+			b.Assert(err, qt.Not(qt.IsNil))
+			b.Assert(err.Error(), qt.Contains, test.expect)
+			fe := herrors.UnwrapErrorWithFileContext(err)
+			b.Assert(fe, qt.Not(qt.IsNil))
+			b.Assert(fe.Position().LineNumber, qt.Equals, 7)
+			b.Assert(fe.Position().Filename, qt.Contains, "page.md")
+			b.Assert(herrors.ErrorCode(err), qt.Equals, herrors.CodeShortcode)
+		})
+	}
+
+	t.Run("Invalid declaration", func(t *testing.T) {
+		b := newTestSitesBuilder(t).WithSimpleConfigFile()
+		b.WithTemplatesAdded("layouts/shortcodes/figure2.html", "{{ $_hugo_config := `"+`{ "params": { "width": { "type": "number" } } }`+"` }}")
+		err := b.CreateSitesE()
+		b.Assert(err, qt.ErrorMatches, `.*shortcodes/figure2.html:1:20: failed to decode \$_hugo_config in template: shortcode parameter "width": invalid type "number".*`)
+	})
+}
+
 func TestShortcodeRef(t *testing.T) {
 	for _, plainIDAnchors := range []bool{false, true} {
 		plainIDAnchors := plainIDAnchors
@@ -35,6 +35,7 @@ import (
 
 	"github.com/gohugoio/hugo/common/loggers"
 
+	"github.com/gohugoio/hugo/metrics"
 	"github.com/gohugoio/hugo/resources"
 
 	"github.com/gohugoio/hugo/identity"
@@ -59,6 +60,7 @@ import (
 
 	"github.com/gohugoio/hugo/langs"
 
+	"github.com/gohugoio/hugo/common/herrors"
 	"github.com/gohugoio/hugo/resources/page"
 
 	"github.com/gohugoio/hugo/config"
@@ -135,6 +137,14 @@ type Site struct {
 	// How to handle page front matter.
 	frontmatterHandler pagemeta.FrontMatterHandler
 
+	// The archetypes used to lint and backfill page front matter, nil
+	// if not enabled.
+	archetypes *archetypeContracts
+
+	// The aliases rendered, written to the redirects file configured in
+	// aliases.redirects.
+	aliasRedirects []aliasRedirect
+
 	// We render each site for all the relevant output formats in serial with
 	// this rendering context pointing to the current one.
 	rc *siteRenderingContext
@@ -145,6 +155,12 @@ type Site struct {
 	// This slice will be sorted.
 	renderFormats output.Formats
 
+	// Cache of rendered pages shared between builds, nil if disabled.
+	renderCache *renderCache
+
+	// The words in the page content indexed for related content.
+	relatedTerms *relatedTermsCache
+
 	// Logger etc.
 	*deps.Deps `json:"-"`
 
@@ -190,10 +206,21 @@ func (t taxonomiesConfig) Values() []viewName {
 
 type siteConfigHolder struct {
 	sitemap          config.Sitemap
+	sitemapRules     sitemapRules
+	aliases          aliasConfig
+	languageRedirect languageRedirectConfig
 	taxonomiesConfig taxonomiesConfig
+	taxonomyOptions  map[string]taxonomyOptions
+	readingTime      readingTimeConfig
+	summary          summaryConfig
 	timeout          time.Duration
 	hasCJKLanguage   bool
 	enableEmoji      bool
+
+	// The front matter schemas and whether to fail the build on
+	// front matter not matching them.
+	contentTypes      pagemeta.ContentTypes
+	strictFrontMatter bool
 }
 
 // Lazily loaded site dependencies.
@@ -202,6 +229,10 @@ type siteInit struct {
 	prevNextInSection *lazy.Init
 	menus             *lazy.Init
 	taxonomies        *lazy.Init
+	termMetadata      *lazy.Init
+	series            *lazy.Init
+	linkGraph         *lazy.Init
+	wikilinkTitles    *lazy.Init
 }
 
 func (init *siteInit) Reset() {
@@ -209,6 +240,10 @@ func (init *siteInit) Reset() {
 	init.prevNextInSection.Reset()
 	init.menus.Reset()
 	init.taxonomies.Reset()
+	init.termMetadata.Reset()
+	init.series.Reset()
+	init.linkGraph.Reset()
+	init.wikilinkTitles.Reset()
 }
 
 func (s *Site) initInit(init *lazy.Init, pctx pageContext) bool {
@@ -317,6 +352,22 @@ func (s *Site) prepareInits() {
 		err := s.pageMap.assembleTaxonomies()
 		return nil, err
 	})
+
+	s.init.termMetadata = init.Branch(func() (interface{}, error) {
+		return s.loadTermMetadata()
+	})
+
+	s.init.series = init.Branch(func() (interface{}, error) {
+		return s.assembleSeries()
+	})
+
+	s.init.linkGraph = init.Branch(func() (interface{}, error) {
+		return s.assembleLinkGraph()
+	})
+
+	s.init.wikilinkTitles = init.Branch(func() (interface{}, error) {
+		return s.assembleWikilinkTitles()
+	})
 }
 
 type siteRenderingContext struct {
@@ -372,9 +423,19 @@ func (s *Site) isEnabled(kind string) bool {
 	return !s.disabledKinds[kind]
 }
 
+// fallbackLanguages returns the languages to render pages missing a
+// translation in this site from, in order of preference.
+func (s *Site) fallbackLanguages() []string {
+	var langs []string
+	for _, l := range s.language.GetStringSlice("fallbackLanguages") {
+		langs = append(langs, strings.ToLower(l))
+	}
+	return langs
+}
+
 // reset returns a new Site prepared for rebuild.
 func (s *Site) reset() *Site {
-	return &Site{
+	ns := &Site{
 		Deps:                   s.Deps,
 		disabledKinds:          s.disabledKinds,
 		titleFunc:              s.titleFunc,
@@ -394,6 +455,9 @@ func (s *Site) reset() *Site {
 		PageCollections:        s.PageCollections,
 		siteCfg:                s.siteCfg,
 	}
+	// Reload the archetypes, they may have changed.
+	ns.archetypes = newArchetypeContracts(ns, s.language)
+	return ns
 }
 
 // newSite creates a new site with the given configuration.
@@ -499,6 +563,19 @@ But this also means that your site configuration may not do what you expect. If
 
 	taxonomies := cfg.Language.GetStringMapString("taxonomies")
 
+	seriesConfig, err := decodeSeriesConfig(cfg.Language)
+	if err != nil {
+		return nil, err
+	}
+	if seriesConfig.LandingPages {
+		if taxonomies == nil {
+			taxonomies = make(map[string]string)
+		}
+		if _, found := taxonomies[seriesKey]; !found {
+			taxonomies[seriesKey] = seriesKey
+		}
+	}
+
 	var relatedContentConfig related.Config
 
 	if cfg.Language.IsSet("related") {
@@ -533,12 +610,60 @@ But this also means that your site configuration may not do what you expect. If
 		}
 	}
 
+	contentTypes, err := pagemeta.DecodeContentTypes(cfg.Language.GetStringMap("contentTypes"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode contentTypes config")
+	}
+
+	taxonomyOptions, err := decodeTaxonomyOptions(cfg.Language)
+	if err != nil {
+		return nil, err
+	}
+
+	readingTimeConfig, err := decodeReadingTimeConfig(cfg.Language)
+	if err != nil {
+		return nil, err
+	}
+
+	summaryConfig, err := decodeSummaryConfig(cfg.Language)
+	if err != nil {
+		return nil, err
+	}
+
+	sitemap, err := decodeSitemapConfig(cfg.Language)
+	if err != nil {
+		return nil, err
+	}
+
+	sitemapRules, err := decodeSitemapRules(cfg.Language)
+	if err != nil {
+		return nil, err
+	}
+
+	aliasConfig, err := decodeAliasConfig(cfg.Language)
+	if err != nil {
+		return nil, err
+	}
+
+	languageRedirectConfig, err := decodeLanguageRedirectConfig(cfg.Language, aliasConfig)
+	if err != nil {
+		return nil, err
+	}
+
 	siteConfig := siteConfigHolder{
-		sitemap:          config.DecodeSitemap(config.Sitemap{Priority: -1, Filename: "sitemap.xml"}, cfg.Language.GetStringMap("sitemap")),
-		taxonomiesConfig: taxonomies,
-		timeout:          timeout,
-		hasCJKLanguage:   cfg.Language.GetBool("hasCJKLanguage"),
-		enableEmoji:      cfg.Language.Cfg.GetBool("enableEmoji"),
+		sitemap:           sitemap,
+		sitemapRules:      sitemapRules,
+		aliases:           aliasConfig,
+		languageRedirect:  languageRedirectConfig,
+		taxonomiesConfig:  taxonomies,
+		taxonomyOptions:   taxonomyOptions,
+		readingTime:       readingTimeConfig,
+		summary:           summaryConfig,
+		timeout:           timeout,
+		hasCJKLanguage:    cfg.Language.GetBool("hasCJKLanguage"),
+		enableEmoji:       cfg.Language.Cfg.GetBool("enableEmoji"),
+		contentTypes:      contentTypes,
+		strictFrontMatter: cfg.Language.GetBool("strictFrontMatter"),
 	}
 
 	s := &Site{
@@ -561,6 +686,8 @@ But this also means that your site configuration may not do what you expect. If
 		relatedDocsHandler: page.NewRelatedDocsHandler(relatedContentConfig),
 	}
 
+	s.archetypes = newArchetypeContracts(s, cfg.Language)
+
 	s.prepareInits()
 
 	return s, nil
@@ -655,6 +782,7 @@ type SiteInfo struct {
 	canonifyURLs bool
 	relativeURLs bool
 	uglyURLs     func(p page.Page) bool
+	urlRules     urlRules
 
 	owner                          *HugoSites
 	s                              *Site
@@ -909,6 +1037,15 @@ func (s *Site) multilingual() *Multilingual {
 type whatChanged struct {
 	source bool
 	files  map[string]bool
+
+	// The taxonomies and terms (see termsKey) of the changed content pages,
+	// both before and after the change. If set, only the taxonomy and term
+	// pages affected by the change need to be re-rendered.
+	terms map[string]bool
+
+	// The names of the output formats affected by the changed templates.
+	// If set, only these output formats need to be re-rendered.
+	formats map[string]bool
 }
 
 // RegisterMediaTypes will register the Site's media types in the mime
@@ -1027,6 +1164,11 @@ func (s *Site) processPartial(config *BuildCfg, init func(config *BuildCfg) erro
 
 		sourceFilesChanged = make(map[string]bool)
 
+		// The output formats affected by the changed templates, nil if all.
+		tmplFormats    = make(map[string]bool)
+		tmplAllFormats bool
+		tmplEvents     int
+
 		// prevent spamming the log on changes
 		logger = helpers.NewDistinctErrorLogger()
 	)
@@ -1062,6 +1204,10 @@ func (s *Site) processPartial(config *BuildCfg, init func(config *BuildCfg) erro
 				sourceChanged = append(sourceChanged, ev)
 			case files.ComponentFolderLayouts:
 				tmplChanged = true
+				tmplEvents++
+				if !tmplAllFormats {
+					tmplAllFormats = !h.addTemplateOutputFormats(id.Path, tmplFormats)
+				}
 				if !s.Tmpl().HasTemplate(id.Path) {
 					tmplAdded = true
 				}
@@ -1087,6 +1233,23 @@ func (s *Site) processPartial(config *BuildCfg, init func(config *BuildCfg) erro
 		files:  sourceFilesChanged,
 	}
 
+	if len(sourceChanged) > 0 && len(sourceChanged) == len(events) && !config.ErrRecovery {
+		// Only content changed, so we only need to render the taxonomy and
+		// term pages that list the changed pages, before and after the change.
+		changedFiles := make(map[string]bool)
+		for _, ev := range sourceChanged {
+			changedFiles[ev.Name] = true
+		}
+		changed.terms = make(map[string]bool)
+		h.addTermsForFiles(changedFiles, changed.terms)
+	}
+
+	if tmplEvents > 0 && tmplEvents == len(events) && !tmplAllFormats && !config.ErrRecovery {
+		// Only templates changed, so we only need to render the output
+		// formats that can use them.
+		changed.formats = tmplFormats
+	}
+
 	config.whatChanged = changed
 
 	if err := init(config); err != nil {
@@ -1323,6 +1486,11 @@ func (s *Site) initializeSiteInfo() error {
 		}
 	}
 
+	urlRules, err := decodeURLRules(s.Cfg)
+	if err != nil {
+		return err
+	}
+
 	s.Info = &SiteInfo{
 		title:                          lang.GetString("title"),
 		Author:                         lang.GetStringMap("author"),
@@ -1338,6 +1506,7 @@ func (s *Site) initializeSiteInfo() error {
 		canonifyURLs:                   s.Cfg.GetBool("canonifyURLs"),
 		relativeURLs:                   s.Cfg.GetBool("relativeURLs"),
 		uglyURLs:                       uglyURLs,
+		urlRules:                       urlRules,
 		permalinks:                     permalinks,
 		owner:                          s.h,
 		s:                              s,
@@ -1365,7 +1534,10 @@ func (s *Site) eventToIdentity(e fsnotify.Event) (identity.PathIdentity, bool) {
 func (s *Site) readAndProcessContent(filenames ...string) error {
 	sourceSpec := source.NewSourceSpec(s.PathSpec, s.BaseFs.Content.Fs)
 
-	proc := newPagesProcessor(s.h, sourceSpec)
+	progress := s.Progress.Start(metrics.ProgressPhaseContent, 0)
+	defer progress.End()
+
+	proc := newPagesProcessor(s.h, sourceSpec, progress)
 
 	c := newPagesCollector(sourceSpec, s.h.getContentMaps(), s.Log, s.h.ContentChanges, proc, filenames...)
 
@@ -1442,6 +1614,16 @@ func (s *Site) assembleMenus() {
 		}
 	}
 
+	// add menu entries from data not defined in config
+	for name, menu := range s.getMenusFromData() {
+		for _, me := range menu {
+			if _, ok := flat[twoD{name, me.KeyName()}]; ok {
+				continue
+			}
+			flat[twoD{name, me.KeyName()}] = me
+		}
+	}
+
 	sectionPagesMenu := s.Info.sectionPagesMenu
 
 	if sectionPagesMenu != "" {
@@ -1560,16 +1742,22 @@ func (s *Site) resetBuildState(sourceChanged bool) {
 		s.PageCollections = newPageCollections(s.pageMap)
 		s.pageMap.withEveryBundlePage(func(p *pageState) bool {
 			p.pagePages = &pagePages{}
+			p.resetSitemapLastmod()
 			if p.bucket != nil {
 				p.bucket.pagesMapBucketPages = &pagesMapBucketPages{}
 			}
 			p.parent = nil
 			p.Scratcher = maps.NewScratcher()
+			if p.IsNode() {
+				// .Data holds the taxonomies, which may have changed.
+				p.ResourceDataProvider = &pageData{pageState: p}
+			}
 			return false
 		})
 	} else {
 		s.pageMap.withEveryBundlePage(func(p *pageState) bool {
 			p.Scratcher = maps.NewScratcher()
+			p.resetSitemapLastmod()
 			return false
 		})
 	}
@@ -1676,7 +1864,19 @@ func (s *Site) renderAndWritePage(statCounter *uint64, name string, targetPath s
 
 	of := p.outputFormat()
 
-	if err := s.renderForTemplate(p.Kind(), of.Name, p, renderBuffer, templ); err != nil {
+	cacheKey, err := s.renderCache.key(p, targetPath)
+	if err != nil {
+		return err
+	}
+
+	if err := s.renderCache.render(cacheKey, renderBuffer, func() error {
+		if s.PageMetrics != nil {
+			return s.PageMetrics.MeasureTemplate(p.metricsKey(), func() error {
+				return s.renderForTemplate(p.Kind(), of.Name, p, renderBuffer, templ)
+			})
+		}
+		return s.renderForTemplate(p.Kind(), of.Name, p, renderBuffer, templ)
+	}); err != nil {
 		return err
 	}
 
@@ -1684,6 +1884,10 @@ func (s *Site) renderAndWritePage(statCounter *uint64, name string, targetPath s
 		return nil
 	}
 
+	if err := s.h.runPageRenderedHooks(p, of, targetPath, renderBuffer.Bytes()); err != nil {
+		return err
+	}
+
 	isHTML := of.IsHTML
 	isRSS := of.Name == "RSS"
 
@@ -1697,6 +1901,13 @@ func (s *Site) renderAndWritePage(statCounter *uint64, name string, targetPath s
 	if isRSS {
 		// Always canonify URLs in RSS
 		pd.AbsURLPath = s.absURLPath(targetPath)
+	} else if of.Name == output.EmailFormat.Name {
+		// Links in emails must be absolute, even with relativeURLs set.
+		pd.AbsURLPath = s.PathSpec.BaseURL.String()
+		if !strings.HasSuffix(pd.AbsURLPath, "/") {
+			pd.AbsURLPath += "/"
+		}
+		pd.Email = true
 	} else if isHTML {
 		if s.Info.relativeURLs || s.Info.canonifyURLs {
 			pd.AbsURLPath = s.absURLPath(targetPath)
@@ -1714,6 +1925,11 @@ func (s *Site) renderAndWritePage(statCounter *uint64, name string, targetPath s
 			pd.AddHugoGeneratorTag = !s.Cfg.GetBool("disableHugoGeneratorInject")
 		}
 
+		pd.CheckA11y = true
+		pd.Password = p.m.password
+		if pd.Password != "" && s.Deps.Reproducible {
+			pd.EncryptionSeed = fmt.Sprintf("%d:%s", s.Deps.Clock.Now().Unix(), targetPath)
+		}
 	}
 
 	return s.publisher.Publish(pd)
@@ -1747,6 +1963,9 @@ func (s *Site) renderForTemplate(name, outputFormat string, d interface{}, w io.
 	}
 
 	if err = s.Tmpl().Execute(templ, w, d); err != nil {
+		if p, ok := d.(page.Page); ok {
+			err = herrors.WithPage(p.Path(), err)
+		}
 		return _errors.Wrapf(err, "render of %q failed", name)
 	}
 	return
@@ -1832,18 +2051,18 @@ func (s *Site) newPage(
 
 func (s *Site) shouldBuild(p page.Page) bool {
 	return shouldBuild(s.BuildFuture, s.BuildExpired,
-		s.BuildDrafts, p.Draft(), p.PublishDate(), p.ExpiryDate())
+		s.BuildDrafts, p.Draft(), p.PublishDate(), p.ExpiryDate(), s.Deps.Clock.Now())
 }
 
 func shouldBuild(buildFuture bool, buildExpired bool, buildDrafts bool, Draft bool,
-	publishDate time.Time, expiryDate time.Time) bool {
+	publishDate time.Time, expiryDate time.Time, now time.Time) bool {
 	if !(buildDrafts || !Draft) {
 		return false
 	}
-	if !buildFuture && !publishDate.IsZero() && publishDate.After(time.Now()) {
+	if !buildFuture && !publishDate.IsZero() && publishDate.After(now) {
 		return false
 	}
-	if !buildExpired && !expiryDate.IsZero() && expiryDate.Before(time.Now()) {
+	if !buildExpired && !expiryDate.IsZero() && expiryDate.Before(now) {
 		return false
 	}
 	return true
@@ -0,0 +1,153 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"strings"
+
+	"github.com/gohugoio/hugo/common/maps"
+	"github.com/gohugoio/hugo/navigation"
+	"github.com/pkg/errors"
+	"github.com/spf13/cast"
+)
+
+// The key of the menus in the data folder, e.g. data/menus/main.yaml.
+const menusDataKey = "menus"
+
+// getMenusFromData returns the menus defined in the menus data files, e.g.
+// data/menus/main.yaml. Entries in a language variant, e.g.
+// data/menus/main.fr.yaml, replace the entries with the same identifier in
+// the default file for that language.
+func (s *Site) getMenusFromData() navigation.Menus {
+	ret := navigation.Menus{}
+
+	menus, err := maps.ToStringMapE(s.h.Data()[menusDataKey])
+	if err != nil || len(menus) == 0 {
+		return ret
+	}
+
+	suffix := "." + s.language.Lang
+
+	for key, v := range menus {
+		name := key
+		if strings.HasSuffix(key, suffix) {
+			name = strings.TrimSuffix(key, suffix)
+		} else if s.isMenuLanguageVariant(key) {
+			// Another language's menu.
+			continue
+		}
+
+		entries, err := s.decodeDataMenu(name, "", v)
+		if err != nil {
+			s.Log.Errorln(errors.Wrapf(err, "unable to process menu %q in data", key))
+			continue
+		}
+
+		ret[name] = mergeDataMenu(ret[name], entries, key != name)
+	}
+
+	return ret
+}
+
+// isMenuLanguageVariant reports whether the menu data key is the variant of
+// a menu for one of the site languages, e.g. main.fr.
+func (s *Site) isMenuLanguageVariant(key string) bool {
+	for _, l := range s.h.Sites {
+		if strings.HasSuffix(key, "."+l.language.Lang) {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeDataMenu merges the language specific menu entries in b, if
+// override is set, with the default entries in a.
+func mergeDataMenu(a, b navigation.Menu, override bool) navigation.Menu {
+	if !override {
+		a, b = b, a
+	}
+	var merged navigation.Menu
+	seen := make(map[string]bool)
+	for _, e := range b {
+		seen[e.KeyName()] = true
+		merged = append(merged, e)
+	}
+	for _, e := range a {
+		if !seen[e.KeyName()] {
+			merged = append(merged, e)
+		}
+	}
+	return merged
+}
+
+// decodeDataMenu decodes the list of menu entries in v, flattening any
+// children into entries with the parent set.
+func (s *Site) decodeDataMenu(name, parent string, v interface{}) (navigation.Menu, error) {
+	m, err := cast.ToSliceE(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var menu navigation.Menu
+
+	for _, entry := range m {
+		ime, err := maps.ToStringMapE(entry)
+		if err != nil {
+			return nil, err
+		}
+
+		me := &navigation.MenuEntry{Menu: name, Parent: parent}
+		me.MarshallMap(ime)
+		me.ConfiguredURL = s.Info.createNodeMenuEntryURL(me.ConfiguredURL)
+
+		// Copy the params, they may be shared with the data.
+		params := make(maps.Params)
+		for k, v := range me.Params {
+			params[k] = v
+		}
+
+		var children interface{}
+		for k, v := range ime {
+			switch lk := strings.ToLower(k); lk {
+			case "icon", "external":
+				// Shorthands for the params commonly used in menu templates.
+				if _, found := params[lk]; !found {
+					params[lk] = v
+				}
+			case "children":
+				children = v
+			}
+		}
+
+		if len(params) > 0 {
+			me.Params = params
+		}
+
+		if me.KeyName() == "" {
+			return nil, errors.New("menu entry must have a name or an identifier")
+		}
+
+		menu = append(menu, me)
+
+		if children != nil {
+			cm, err := s.decodeDataMenu(name, me.KeyName(), children)
+			if err != nil {
+				return nil, err
+			}
+			menu = append(menu, cm...)
+		}
+	}
+
+	return menu, nil
+}
@@ -15,6 +15,7 @@ package hugolib
 
 import (
 	"fmt"
+	"path"
 	"strings"
 
 	"github.com/gohugoio/hugo/output"
@@ -106,3 +107,53 @@ func createSiteOutputFormats(allFormats output.Formats, outputs map[string]inter
 
 	return outFormats, nil
 }
+
+// templateOutputFormats returns the names of the output formats that can
+// use the layout template with the given name, relative to the layouts
+// folder, e.g. "_default/list.rss.xml". It returns false if the template
+// may be used by any output format, e.g. partials and shortcodes, or if we
+// cannot tell.
+func templateOutputFormats(name string, allFormats output.Formats) (map[string]bool, bool) {
+	name = strings.TrimPrefix(name, "/")
+	for _, prefix := range []string{"partials/", "shortcodes/", "_markup/", "_internal/"} {
+		if strings.HasPrefix(name, prefix) || strings.Contains(name, "/"+prefix) {
+			return nil, false
+		}
+	}
+
+	parts := strings.Split(path.Base(name), ".")
+	if len(parts) < 2 {
+		return nil, false
+	}
+	suffix := strings.ToLower(parts[len(parts)-1])
+
+	// The layout lookup matches the first suffix of the output format's
+	// media type, optionally with the output format's name.
+	var candidates output.Formats
+	for _, f := range allFormats {
+		if f.MediaType.FirstSuffix.Suffix == suffix {
+			candidates = append(candidates, f)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, false
+	}
+
+	formats := make(map[string]bool)
+	for _, part := range parts[:len(parts)-1] {
+		for _, f := range candidates {
+			if strings.EqualFold(part, f.Name) {
+				formats[f.Name] = true
+			}
+		}
+	}
+	if len(formats) > 0 {
+		return formats, true
+	}
+
+	for _, f := range candidates {
+		formats[f.Name] = true
+	}
+
+	return formats, true
+}
@@ -15,6 +15,7 @@ package hugolib
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"testing"
 
@@ -616,3 +617,203 @@ WordCount: {{ .WordCount }}
 	b.AssertFileContent("public/outputs-empty/index.html", "HTML:", "Word1. Word2.")
 	b.AssertFileContent("public/outputs-string/index.html", "O1:", "Word1. Word2.")
 }
+
+func TestTemplateOutputFormats(t *testing.T) {
+	c := qt.New(t)
+
+	formats := output.Formats{output.HTMLFormat, output.AMPFormat, output.RSSFormat, output.JSONFormat, output.SitemapFormat}
+
+	for _, test := range []struct {
+		name   string
+		expect []string
+	}{
+		{"_default/single.html", []string{"AMP", "HTML"}},
+		{"_default/single.amp.html", []string{"AMP"}},
+		{"_default/list.en.amp.html", []string{"AMP"}},
+		{"_default/rss.xml", []string{"RSS"}},
+		{"index.xml", []string{"RSS", "Sitemap"}},
+		{"posts/list.json", []string{"JSON"}},
+		{"partials/head.html", nil},
+		{"_default/_markup/render-link.html", nil},
+		{"shortcodes/foo.html", nil},
+		{"_default/single.foo", nil},
+	} {
+		got, ok := templateOutputFormats(test.name, formats)
+		c.Assert(ok, qt.Equals, test.expect != nil, qt.Commentf(test.name))
+		var names []string
+		for k := range got {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		c.Assert(names, qt.DeepEquals, test.expect, qt.Commentf(test.name))
+	}
+}
+
+func TestGeminiOutputFormat(t *testing.T) {
+	b := newTestSitesBuilder(t).WithConfigFile("toml", `
+baseURL = "https://example.org/"
+disableKinds = ["taxonomy", "term"]
+[outputs]
+home = ["HTML", "Gemini"]
+section = ["HTML", "Gemini"]
+page = ["HTML", "Gemini"]
+`)
+
+	b.WithTemplates(
+		"_default/single.html", `HTML: {{ .Content }}|{{ with .OutputFormats.Get "gemini" }}{{ .Permalink }}{{ end }}`,
+		"_default/list.html", `List HTML`,
+	)
+
+	b.WithContent("posts/first.md", `---
+title: First & Last
+date: 2021-06-10
+---
+
+## Hello
+
+Some *text* with a [link](https://example.com/).
+`)
+
+	b.Build(BuildCfg{})
+
+	b.AssertFileContent("public/posts/first/index.html",
+		"HTML: <h2 id=\"hello\">Hello</h2>",
+		"|gemini://example.org/posts/first/index.gmi",
+	)
+	b.AssertFileContent("public/posts/first/index.gmi",
+		"# First & Last\n\n2021-06-10\n\n## Hello\n\nSome text with a link.\n=> https://example.com/ link",
+	)
+	b.AssertFileContent("public/posts/index.gmi",
+		"# Posts\n\n=> /posts/first/index.gmi 2021-06-10 First & Last",
+	)
+}
+
+func TestMarkdownAndLLMSOutputFormats(t *testing.T) {
+	b := newTestSitesBuilder(t).WithConfigFile("toml", `
+baseURL = "https://example.org/"
+title = "My Site"
+disableKinds = ["taxonomy", "term"]
+[params]
+description = "A site about things."
+[outputs]
+home = ["HTML", "LLMS", "LLMSFull"]
+section = ["HTML", "Markdown"]
+page = ["HTML", "Markdown"]
+`)
+
+	b.WithTemplates(
+		"_default/single.html", `HTML: {{ .Content }}`,
+		"_default/list.html", `List HTML`,
+		"shortcodes/greeting.html", `<b>Hello, {{ .Get 0 }}!</b>`,
+	)
+
+	b.WithContent("posts/_index.md", "---\ntitle: Posts\n---",
+		"posts/first.md", `---
+title: "First: A \"Post\""
+description: The first post.
+date: 2021-06-10
+---
+
+Summary with {{< greeting "world" >}}.
+
+<!--more-->
+
+## Heading
+
+Some *text*.
+`,
+		"about.md", "---\ntitle: About\n---\n\nAbout *us*.",
+	)
+
+	b.Build(BuildCfg{})
+
+	b.AssertFileContent("public/posts/first/index.html", "HTML: <p>Summary with <b>Hello, world!</b>.</p>")
+	b.AssertFileContent("public/posts/first/index.md", `---
+title: "First: A \"Post\""
+description: "The first post."
+date: 2021-06-10T00:00:00Z
+url: https://example.org/posts/first/
+---
+
+Summary with <b>Hello, world!</b>.
+
+## Heading
+
+Some *text*.
+`)
+	b.AssertFileContent("public/posts/index.md", `title: "Posts"`, "- [First: A \"Post\"](https://example.org/posts/first/index.md): The first post.")
+
+	b.AssertFileContent("public/llms.txt", `# My Site
+
+> A site about things.
+
+## My Site
+
+- [About](https://example.org/about/index.md)
+
+## Posts
+
+- [First: A "Post"](https://example.org/posts/first/index.md): The first post.
+`)
+	b.Assert(b.FileContent("public/llms.txt"), qt.Not(qt.Contains), "\n\n\n")
+	b.AssertFileContent("public/llms-full.txt",
+		"---\n\n# About\n\nURL: https://example.org/about/\n\nAbout *us*.\n",
+		"# First: A \"Post\"\n\nURL: https://example.org/posts/first/\n\nSummary with <b>Hello, world!</b>.\n\n## Heading",
+	)
+}
+
+func TestEmailOutputFormat(t *testing.T) {
+	b := newTestSitesBuilder(t).WithConfigFile("toml", `
+baseURL = "https://example.org/"
+title = "My Site"
+relativeURLs = true
+disableKinds = ["taxonomy", "term"]
+[outputs]
+home = ["HTML"]
+section = ["Email"]
+page = ["HTML", "Email"]
+`)
+
+	b.WithTemplates(
+		"index.html", `Home`,
+		"posts/single.html", `HTML: {{ .Content }}`,
+		"posts/single.email.html", `<mjml>
+<mj-head><mj-style inline="inline">.lead { font-weight: bold }</mj-style></mj-head>
+<mj-body>
+<mj-section><mj-column>
+<mj-image src="/logo.png" alt="{{ site.Title }}" />
+<mj-text><p class="lead">{{ .Title }}</p>{{ .Content }}</mj-text>
+</mj-column></mj-section>
+</mj-body>
+</mjml>`,
+	)
+
+	b.WithContent("posts/_index.md", "---\ntitle: Posts\n---",
+		"posts/first.md", `---
+title: First & Last
+---
+
+Some *text* with a [link](/about/).
+`,
+		"about.md", "---\ntitle: About\n---\n\nAbout &amp; us.",
+	)
+
+	b.Build(BuildCfg{})
+
+	b.AssertFileContent("public/posts/first/email.html",
+		`<img alt="My Site" src="https://example.org/logo.png"`,
+		`<p class="lead" style="font-weight:bold;">First &amp; Last</p><p>Some <em>text</em> with a <a href="https://example.org/about/">link</a>.</p>`,
+	)
+	b.Assert(b.FileContent("public/posts/first/email.html"), qt.Not(qt.Contains), "<mj-")
+
+	// The built-in template.
+	b.AssertFileContent("public/posts/email.html",
+		"<title>Posts</title>",
+		`<a href="https://example.org/posts/first/" style="color:#1a73e8;">First &amp; Last</a>`,
+	)
+	b.AssertFileContent("public/about/email.html",
+		"<title>About</title>",
+		`overflow:hidden;">About &amp; us.</div>`,
+		`<a href="https://example.org/about/" target="_blank" style="display:inline-block;background:#333333;color:#ffffff;`,
+	)
+}
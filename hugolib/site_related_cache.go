@@ -0,0 +1,224 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/gohugoio/hugo/cache/filecache"
+	"github.com/gohugoio/hugo/common/hugo"
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/gohugoio/hugo/parser/pageparser"
+	"github.com/gohugoio/hugo/related"
+	"github.com/pkg/errors"
+)
+
+// relatedContentIndexName is the name of a fulltext related index that
+// indexes the page content.
+const relatedContentIndexName = "content"
+
+// relatedTermsCache holds the words in the content of the pages indexed
+// in a fulltext related index. These are stored in the "related" file
+// cache, so the next build can create the index without loading the
+// content of the pages that have not changed.
+type relatedTermsCache struct {
+	s     *Site
+	cache *filecache.Cache
+
+	init    sync.Once
+	initErr error
+
+	mu      sync.Mutex
+	prev    map[string]relatedTermsEntry
+	current map[string]relatedTermsEntry
+	changed bool
+}
+
+type relatedTermsFile struct {
+	Version string
+	Entries map[string]relatedTermsEntry
+}
+
+type relatedTermsEntry struct {
+	// The MD5 hash of the source file.
+	Hash string
+
+	// The word frequencies.
+	Terms map[string]int
+}
+
+func newRelatedTermsCache(s *Site) *relatedTermsCache {
+	var cache *filecache.Cache
+	if s.FileCaches != nil {
+		cache = s.FileCaches.RelatedCache()
+	}
+	if cache != nil && (!cache.Enabled() || s.running()) {
+		cache = nil
+	}
+	return &relatedTermsCache{
+		s:       s,
+		cache:   cache,
+		current: make(map[string]relatedTermsEntry),
+	}
+}
+
+func (c *relatedTermsCache) key() string {
+	return "related_terms_" + c.s.Lang() + ".json"
+}
+
+func (c *relatedTermsCache) load() error {
+	if c.cache == nil {
+		return nil
+	}
+
+	_, b, err := c.cache.GetBytes(c.key())
+	if err != nil || b == nil {
+		return err
+	}
+
+	var f relatedTermsFile
+	if err := json.Unmarshal(b, &f); err != nil || f.Version != hugo.CurrentVersion.String() {
+		// Start over.
+		return nil
+	}
+	c.prev = f.Entries
+
+	return nil
+}
+
+// keywords returns the keywords for the content of p. c may be nil,
+// in which case nothing is cached.
+func (c *relatedTermsCache) keywords(p *pageState) ([]related.Keyword, error) {
+	if p.File().IsZero() {
+		return nil, nil
+	}
+
+	var (
+		entry relatedTermsEntry
+		found bool
+		key   = p.File().Filename()
+		hash  string
+	)
+
+	if c != nil && c.cache != nil {
+		c.init.Do(func() {
+			c.initErr = c.load()
+		})
+		if c.initErr != nil {
+			return nil, errors.Wrap(c.initErr, "failed to load related terms")
+		}
+
+		var err error
+		hash, err = p.sourceHash()
+		if err != nil {
+			return nil, err
+		}
+
+		c.mu.Lock()
+		entry, found = c.prev[key]
+		c.mu.Unlock()
+		found = found && entry.Hash == hash
+	}
+
+	if !found {
+		if err := p.loadContent(); err != nil {
+			return nil, err
+		}
+		entry = relatedTermsEntry{Hash: hash, Terms: make(map[string]int)}
+		for _, w := range related.Tokenize(p.relatedText()) {
+			entry.Terms[w]++
+		}
+	}
+
+	if c != nil && c.cache != nil {
+		c.mu.Lock()
+		c.current[key] = entry
+		c.changed = c.changed || !found
+		c.mu.Unlock()
+	}
+
+	var keywords []related.Keyword
+	for w, n := range entry.Terms {
+		for i := 0; i < n; i++ {
+			keywords = append(keywords, related.StringKeyword(w))
+		}
+	}
+
+	return keywords, nil
+}
+
+// save writes the entries used in this build to the file cache.
+func (c *relatedTermsCache) save() error {
+	if c == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cache == nil || !(c.changed || len(c.current) != len(c.prev)) {
+		return nil
+	}
+
+	b, err := json.Marshal(relatedTermsFile{Version: hugo.CurrentVersion.String(), Entries: c.current})
+	if err != nil {
+		return err
+	}
+
+	_, w, err := c.cache.WriteCloser(c.key())
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	_, err = w.Write(b)
+	return err
+}
+
+// sourceHash returns the MD5 hash of the page's source file. Hashing the
+// file is cheap compared to parsing it.
+func (p *pageState) sourceHash() (string, error) {
+	if p.source.parsed != nil {
+		return helpers.MD5String(string(p.source.parsed.Input())), nil
+	}
+	if p.openContent == nil {
+		return "", nil
+	}
+	r, err := p.openContent()
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+	return helpers.MD5FromReader(r)
+}
+
+// relatedText returns the text in the page's content source, without
+// front matter, shortcodes and HTML. The content must be loaded.
+func (p *pageState) relatedText() string {
+	if p.cmap == nil {
+		return ""
+	}
+
+	source := p.source.parsed.Input()
+	var b []byte
+	for _, it := range p.cmap.items {
+		if v, ok := it.(pageparser.Item); ok {
+			b = append(b, source[v.Pos:v.Pos+len(v.Val)]...)
+			b = append(b, ' ')
+		}
+	}
+
+	return helpers.StripHTML(string(b))
+}
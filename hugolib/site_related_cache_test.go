@@ -0,0 +1,84 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestRelatedFulltextContent(t *testing.T) {
+	c := qt.New(t)
+
+	b := newTestSitesBuilder(t).WithConfigFile("toml", `
+baseURL = "https://example.org"
+disableKinds = ["RSS", "sitemap", "taxonomy", "term"]
+[caches.related]
+dir = ":resourceDir/_related"
+maxAge = -1
+[related]
+threshold = 20
+scoring = "bm25"
+[[related.indices]]
+name = "content"
+type = "fulltext"
+weight = 100
+`)
+
+	b.WithTemplates(
+		"_default/single.html", `Related: {{ range .Site.RegularPages.Related . }}{{ .Title }}|{{ end }}`,
+		"index.html", `Home`,
+	)
+	b.WithContent(
+		"gopher.md", "---\ntitle: Gopher\ndate: 2021-01-01\n---\nThe gopher is the mascot of Go.",
+		"go.md", "---\ntitle: Go\ndate: 2021-01-02\n---\nGo is a language with a gopher mascot. {{< foo >}}",
+		"pancakes.md", "---\ntitle: Pancakes\ndate: 2021-01-03\n---\nA recipe for pancakes.",
+	)
+	b.WithTemplatesAdded("shortcodes/foo.html", "gopher gopher gopher")
+
+	// Every build is a new CLI build.
+	build := func() {
+		b.H = nil
+		b.Build(BuildCfg{})
+		b.AssertFileContent("public/go/index.html", "Related: Gopher|")
+		b.AssertFileContent("public/gopher/index.html", "Related: \n")
+		b.AssertFileContent("public/pancakes/index.html", "Related: \n")
+	}
+
+	build()
+	for _, p := range b.H.Sites[0].RegularPages() {
+		c.Assert(p.(*pageState).source.parsed, qt.Not(qt.IsNil))
+	}
+
+	// The words are now fetched from the cache, so the content does not
+	// need to be loaded.
+	build()
+	for _, p := range b.H.Sites[0].RegularPages() {
+		c.Assert(p.(*pageState).source.parsed, qt.IsNil)
+	}
+
+	b.WithContent("pancakes.md", "---\ntitle: Pancakes\ndate: 2021-01-03\n---\nA gopher recipe for pancakes, made in Go.")
+	b.H = nil
+	b.Build(BuildCfg{})
+	b.AssertFileContent("public/pancakes/index.html", "Related: Go|Gopher|")
+}
+
+func TestRelatedTermsCacheNil(t *testing.T) {
+	c := qt.New(t)
+
+	// Sites without fulltext indices have no cache.
+	var cache *relatedTermsCache
+	c.Assert(cache.save(), qt.IsNil)
+}
@@ -15,10 +15,12 @@ package hugolib
 
 import (
 	"fmt"
+	"net/http"
 	"path"
 	"strings"
 	"sync"
 
+	"github.com/gohugoio/hugo/metrics"
 	"github.com/gohugoio/hugo/tpl"
 
 	"github.com/gohugoio/hugo/config"
@@ -58,6 +60,10 @@ func (s siteRenderContext) renderSingletonPages() bool {
 // renderPages renders pages each corresponding to a markdown file.
 // TODO(bep np doc
 func (s *Site) renderPages(ctx *siteRenderContext) error {
+	if !ctx.cfg.shouldRenderFormat(s.rc.Format) {
+		return nil
+	}
+
 	numWorkers := config.GetNumWorkerMultiplier()
 
 	results := make(chan error)
@@ -66,20 +72,34 @@ func (s *Site) renderPages(ctx *siteRenderContext) error {
 
 	go s.errorCollator(results, errs)
 
+	cfg := ctx.cfg
+
+	if s.renderCache != nil {
+		// Publish the page resources up front, so they are published even
+		// if the page is fetched from the render cache, and so publishing
+		// them doesn't prevent other pages from being cached.
+		if err := s.renderPageResources(cfg); err != nil {
+			return err
+		}
+	}
+
+	progress := s.startRenderProgress(cfg)
+	defer progress.End()
+
 	wg := &sync.WaitGroup{}
 
 	for i := 0; i < numWorkers; i++ {
 		wg.Add(1)
-		go pageRenderer(ctx, s, pages, results, wg)
+		go pageRenderer(ctx, s, pages, results, wg, progress)
 	}
 
-	cfg := ctx.cfg
-
 	s.pageMap.pageTrees.Walk(func(ss string, n *contentNode) bool {
 		if cfg.shouldRender(n.p) {
 			select {
 			case <-s.h.Done():
 				return true
+			case <-cfg.done():
+				return true
 			default:
 				pages <- n.p
 			}
@@ -97,7 +117,47 @@ func (s *Site) renderPages(ctx *siteRenderContext) error {
 	if err != nil {
 		return errors.Wrap(err, "failed to render pages")
 	}
-	return nil
+	return cfg.canceled()
+}
+
+// renderPageResources publishes the resources of the pages to render in the
+// current output format.
+func (s *Site) renderPageResources(cfg *BuildCfg) error {
+	var err error
+	s.pageMap.pageTrees.Walk(func(ss string, n *contentNode) bool {
+		if !cfg.shouldRender(n.p) || !n.p.m.buildConfig.PublishResources {
+			return false
+		}
+		if err = n.p.renderResources(); err != nil {
+			err = n.p.errorf(err, "failed to render page resources")
+			return true
+		}
+		return false
+	})
+	return err
+}
+
+// startRenderProgress starts the progress phase for rendering the pages in
+// the current output format, if progress reporting is enabled.
+func (s *Site) startRenderProgress(cfg *BuildCfg) *metrics.ProgressPhase {
+	if s.Progress == nil {
+		return nil
+	}
+
+	var total int
+	s.pageMap.pageTrees.Walk(func(ss string, n *contentNode) bool {
+		if cfg.shouldRender(n.p) {
+			total++
+		}
+		return false
+	})
+
+	name := metrics.ProgressPhaseRender + " " + s.rc.Format.Name
+	if len(s.h.Sites) > 1 {
+		name += " (" + s.Lang() + ")"
+	}
+
+	return s.Progress.Start(name, total)
 }
 
 func pageRenderer(
@@ -105,10 +165,18 @@ func pageRenderer(
 	s *Site,
 	pages <-chan *pageState,
 	results chan<- error,
-	wg *sync.WaitGroup) {
+	wg *sync.WaitGroup,
+	progress *metrics.ProgressPhase) {
 	defer wg.Done()
 
 	for p := range pages {
+		if ctx.cfg.canceled() != nil {
+			// Drain the channel.
+			continue
+		}
+
+		progress.Incr()
+
 		if p.m.buildConfig.PublishResources {
 			if err := p.renderResources(); err != nil {
 				s.SendError(p.errorf(err, "failed to render page resources"))
@@ -187,6 +255,7 @@ func (s *Site) renderPaginator(p *pageState, templ tpl.Template) error {
 	d := p.targetPathDescriptor
 	f := p.s.rc.Format
 	d.Type = f
+	s.Info.urlRules.apply(p, &d)
 
 	if p.paginator.current == nil || p.paginator.current != p.paginator.current.First() {
 		panic(fmt.Sprintf("invalid paginator state for %q", p.pathOrTitle()))
@@ -317,6 +386,7 @@ func (s *Site) renderRobotsTXT() error {
 // renderAliases renders shell pages that simply have a redirect in the header.
 func (s *Site) renderAliases() error {
 	var err error
+	s.aliasRedirects = nil
 	s.pageMap.pageTrees.WalkLinkable(func(ss string, n *contentNode) bool {
 		p := n.p
 		if len(p.Aliases()) == 0 {
@@ -340,6 +410,11 @@ func (s *Site) renderAliases() error {
 			plink := of.Permalink()
 
 			for _, a := range p.Aliases() {
+				status, found := p.m.aliasStatus[a]
+				if !found {
+					status = s.siteCfg.aliases.Status
+				}
+
 				isRelative := !strings.HasPrefix(a, "/")
 
 				if isRelative {
@@ -364,7 +439,7 @@ func (s *Site) renderAliases() error {
 					a = path.Join(lang, a)
 				}
 
-				err = s.writeDestAlias(a, plink, f, p)
+				err = s.publishDestAlias(false, a, plink, status, f, p)
 				if err != nil {
 					return true
 				}
@@ -390,13 +465,13 @@ func (s *Site) renderMainLanguageRedirect() error {
 		if s.Info.defaultContentLanguageInSubdir {
 			mainLangURL := s.PathSpec.AbsURL(mainLang.Lang+"/", false)
 			s.Log.Debugf("Write redirect to main language %s: %s", mainLang, mainLangURL)
-			if err := s.publishDestAlias(true, "/", mainLangURL, html, nil); err != nil {
+			if err := s.publishDestAlias(true, "/", mainLangURL, http.StatusMovedPermanently, html, nil); err != nil {
 				return err
 			}
 		} else {
 			mainLangURL := s.PathSpec.AbsURL("", false)
 			s.Log.Debugf("Write redirect to main language %s: %s", mainLang, mainLangURL)
-			if err := s.publishDestAlias(true, mainLang.Lang, mainLangURL, html, nil); err != nil {
+			if err := s.publishDestAlias(true, mainLang.Lang, mainLangURL, http.StatusMovedPermanently, html, nil); err != nil {
 				return err
 			}
 		}
@@ -0,0 +1,311 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gohugoio/hugo/cache/filecache"
+	"github.com/gohugoio/hugo/common/hugo"
+	"github.com/gohugoio/hugo/common/maps"
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/gohugoio/hugo/hugofs"
+	"github.com/gohugoio/hugo/hugolib/filesystems"
+	"github.com/gohugoio/hugo/resources/page"
+	"github.com/gohugoio/hugo/resources/resource"
+	"github.com/mitchellh/hashstructure"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+// The configuration settings that cannot affect the rendered output of a
+// page, or that hold runtime objects derived from other settings and files.
+// Changing any other setting invalidates the render cache.
+var renderCacheIgnoreConfigKeys = map[string]bool{
+	"allmodules":                  true,
+	"buildclock":                  true,
+	"cachedir":                    true,
+	"cleandestinationdir":         true,
+	"debug":                       true,
+	"debugmemory":                 true,
+	"errorsfile":                  true,
+	"filecacheconfigs":            true,
+	"forcesyncstatic":             true,
+	"gc":                          true,
+	"ignorecache":                 true,
+	"languagessorted":             true,
+	"languagessorteddefaultfirst": true,
+	"logfile":                     true,
+	"logformat":                   true,
+	"logi18nwarnings":             true,
+	"logpathwarnings":             true,
+	"modulesclient":               true,
+	"pagemetrics":                 true,
+	"pagemetricscount":            true,
+	"progress":                    true,
+	"quiet":                       true,
+	"templatemetrics":             true,
+	"templatemetricshints":        true,
+	"traceendpoint":               true,
+	"verbose":                     true,
+	"watch":                       true,
+}
+
+// renderCache caches the rendered output of regular pages in the "renders"
+// file cache so it can be reused in the next build.
+//
+// A page is looked up by a hash of all of its inputs: the page source and
+// its resources, the templates, data, i18n and assets files, the site
+// configuration and the content of all pages. A page may show e.g. the
+// content, backlinks or related pages of any other page, so any change to
+// the content invalidates the entire cache.
+//
+// Rendering a page may have side effects, e.g. publishing a resource or
+// processing an image, that we would miss when the page is fetched from the
+// cache, so we only store pages that were rendered without publishing
+// anything. The page resources are published before any page is rendered.
+type renderCache struct {
+	s     *Site
+	cache *filecache.Cache
+
+	init    sync.Once
+	siteKey string
+	initErr error
+}
+
+func newRenderCache(s *Site) *renderCache {
+	var cache *filecache.Cache
+	if s.FileCaches != nil {
+		cache = s.FileCaches.RendersCache()
+	}
+	if cache == nil || !cache.Enabled() || s.running() {
+		return nil
+	}
+	return &renderCache{s: s, cache: cache}
+}
+
+// key returns the cache key for the given page rendered to targetPath, or ""
+// if the page cannot be cached.
+func (c *renderCache) key(p *pageState, targetPath string) (string, error) {
+	if c == nil || p.Kind() != page.KindPage || p.File().IsZero() {
+		return "", nil
+	}
+
+	if err := p.loadContent(); err != nil {
+		return "", err
+	}
+	if p.source.parsed == nil {
+		return "", nil
+	}
+
+	c.init.Do(func() {
+		c.siteKey, c.initErr = c.createSiteKey()
+	})
+	if c.initErr != nil {
+		return "", errors.Wrap(c.initErr, "failed to create render cache key")
+	}
+
+	h := md5.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00", c.siteKey, p.outputFormat().Name, targetPath)
+	h.Write(p.source.parsed.Input())
+
+	pageKey, err := hashstructure.Hash([]interface{}{p.Date(), p.Lastmod(), p.GitInfo()}, nil)
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprintf(h, "\x00%d", pageKey)
+
+	for _, r := range p.Resources() {
+		rkey, err := hashstructure.Hash([]interface{}{r.ResourceType(), r.Name(), r.Title(), r.Params()}, nil)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "\x00%d", rkey)
+
+		if rr, ok := r.(resource.ReadSeekCloserResource); ok {
+			f, err := rr.ReadSeekCloser()
+			if err != nil {
+				return "", err
+			}
+			hash, err := helpers.MD5FromFileFast(f)
+			f.Close()
+			if err != nil {
+				return "", err
+			}
+			fmt.Fprintf(h, "\x00%s", hash)
+		}
+	}
+
+	return p.s.Lang() + "/" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// get returns the cached output for key, nil if not found.
+func (c *renderCache) get(key string) ([]byte, error) {
+	if key == "" {
+		return nil, nil
+	}
+	_, b, err := c.cache.GetBytes(key)
+	return b, err
+}
+
+// set stores the rendered output b for key.
+func (c *renderCache) set(key string, b []byte) error {
+	_, w, err := c.cache.WriteCloser(key)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+	_, err = w.Write(b)
+	return err
+}
+
+// publishRequests returns the number of resource permalinks requested in
+// all sites so far.
+func (c *renderCache) publishRequests() uint64 {
+	var n uint64
+	for _, s := range c.s.h.Sites {
+		n += s.ResourceSpec.PublishRequests()
+	}
+	return n
+}
+
+func (c *renderCache) createSiteKey() (string, error) {
+	s := c.s
+
+	config := make(maps.Params)
+	if root, ok := s.h.Cfg.Get("").(maps.Params); ok {
+		for k, v := range root {
+			if !renderCacheIgnoreConfigKeys[k] {
+				config[k] = v
+			}
+		}
+	}
+
+	configKey, err := hashstructure.Hash([]interface{}{config, s.Lang(), s.language.LocalCfg.Get(""), s.Language().Params()}, nil)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to hash config")
+	}
+
+	h := md5.New()
+	fmt.Fprintf(h, "%s\x00%d", hugo.CurrentVersion.String(), configKey)
+
+	for _, sfs := range []*filesystems.SourceFilesystem{
+		s.BaseFs.Content,
+		s.BaseFs.Layouts,
+		s.BaseFs.Data,
+		s.BaseFs.I18n,
+		s.BaseFs.Assets,
+	} {
+		if sfs == nil {
+			continue
+		}
+		filesKey, err := hashFiles(sfs.Fs)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to hash %s files", sfs.Name)
+		}
+		fmt.Fprintf(h, "\x00%s:%s", sfs.Name, filesKey)
+	}
+
+	// The dates of all pages in all sites, which may come from Git or the
+	// file system and not from the content files hashed above.
+	var pages []string
+	for _, p := range s.h.Pages() {
+		ps, ok := p.(*pageState)
+		if !ok {
+			continue
+		}
+		pkey, err := hashstructure.Hash([]interface{}{ps.Lang(), ps.Path(), ps.Kind(), ps.Date(), ps.Lastmod(), ps.PublishDate(), ps.ExpiryDate()}, nil)
+		if err != nil {
+			return "", err
+		}
+		pages = append(pages, fmt.Sprint(pkey))
+	}
+	sort.Strings(pages)
+	fmt.Fprintf(h, "\x00%s", strings.Join(pages, ","))
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// hashFiles returns a hash of the names and content of all files in fs.
+func hashFiles(fs afero.Fs) (string, error) {
+	var entries []string
+
+	walker := func(path string, fi hugofs.FileMetaInfo, err error) error {
+		if err != nil || fi.IsDir() {
+			return err
+		}
+
+		f, err := fi.Meta().Open()
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		h := md5.New()
+		if _, err := io.Copy(h, f); err != nil {
+			return err
+		}
+
+		entries = append(entries, path+":"+hex.EncodeToString(h.Sum(nil)))
+
+		return nil
+	}
+
+	if err := helpers.SymbolicWalk(fs, "", walker); err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+
+	sort.Strings(entries)
+
+	return helpers.MD5String(strings.Join(entries, "\n")), nil
+}
+
+// render renders the page into w using render, or fetches it from the
+// cache if possible.
+func (c *renderCache) render(key string, w *bytes.Buffer, render func() error) error {
+	if key == "" {
+		return render()
+	}
+
+	b, err := c.get(key)
+	if err != nil {
+		return err
+	}
+	if b != nil {
+		_, err := w.Write(b)
+		return err
+	}
+
+	before := c.publishRequests()
+
+	if err := render(); err != nil {
+		return err
+	}
+
+	if c.publishRequests() != before {
+		// The page, or one rendered in parallel, may have published
+		// a resource.
+		return nil
+	}
+
+	return c.set(key, w.Bytes())
+}
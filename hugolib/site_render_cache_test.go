@@ -0,0 +1,127 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestRenderCache(t *testing.T) {
+	c := qt.New(t)
+
+	b := newTestSitesBuilder(t).WithConfigFile("toml", `
+baseURL = "https://example.org"
+[caches.renders]
+dir = ":resourceDir/_renders"
+maxAge = -1
+`)
+
+	b.WithTemplates(
+		"_default/single.html", `Single: {{ .Title }}|{{ .Content }}|{{ now.UnixNano }}`,
+		"_default/list.html", `List: {{ now.UnixNano }}`,
+		"res/single.html", `Res: {{ (resources.Get "a.txt").RelPermalink }}|{{ now.UnixNano }}`,
+	)
+	b.WithContent(
+		"p1.md", "---\ntitle: P1\n---\nP1 content.",
+		"p2.md", "---\ntitle: P2\n---\nP2 content.",
+		"res/p3.md", "---\ntitle: P3\n---\n",
+	)
+	b.WithSourceFile("assets/a.txt", "A")
+
+	// Every build is a new CLI build. The files added last win.
+	build := func() (p1, p2, p3, home string) {
+		b.H = nil
+		b.Build(BuildCfg{})
+		return b.FileContent("public/p1/index.html"), b.FileContent("public/p2/index.html"), b.FileContent("public/res/p3/index.html"), b.FileContent("public/index.html")
+	}
+
+	p1, p2, p3, home := build()
+	c.Assert(p1, qt.Contains, "Single: P1|<p>P1 content.</p>")
+
+	p1b, p2b, p3b, homeb := build()
+	c.Assert(p1b, qt.Equals, p1)
+	c.Assert(p2b, qt.Equals, p2)
+	// Rendering this page publishes a resource.
+	c.Assert(p3b, qt.Not(qt.Equals), p3)
+	b.AssertFileContent("public/a.txt", "A")
+	// List pages are always rendered.
+	c.Assert(homeb, qt.Not(qt.Equals), home)
+
+	// Changing the body of p2 invalidates all pages, as any page may
+	// show e.g. the content of p2.
+	b.WithContent("p2.md", "---\ntitle: P2\n---\nP2 edited.")
+	p1c, p2c, _, _ := build()
+	c.Assert(p1c, qt.Not(qt.Equals), p1)
+	c.Assert(p2c, qt.Contains, "Single: P2|<p>P2 edited.</p>")
+
+	// So does changing a template.
+	b.WithTemplates("_default/single.html", `Single Edited: {{ .Title }}|{{ now.UnixNano }}`)
+	p1d, _, _, _ := build()
+	c.Assert(p1d, qt.Contains, "Single Edited: P1")
+
+	// And any config setting.
+	p1e, _, _, _ := build()
+	c.Assert(p1e, qt.Equals, p1d)
+	b.WithConfigFile("toml", `
+baseURL = "https://example.org"
+sectionPagesMenu = "main"
+[caches.renders]
+dir = ":resourceDir/_renders"
+maxAge = -1
+`)
+	p1f, _, _, _ := build()
+	c.Assert(p1f, qt.Not(qt.Equals), p1d)
+}
+
+func TestRenderCachePublishes(t *testing.T) {
+	c := qt.New(t)
+
+	b := newTestSitesBuilder(t).WithConfigFile("toml", `
+baseURL = "https://example.org"
+[caches.renders]
+dir = ":resourceDir/_renders"
+maxAge = -1
+`)
+
+	b.WithTemplates(
+		"_default/single.html", `Single: {{ .Title }}|{{ now.UnixNano }}`,
+		"_default/list.html", `List: {{ now.UnixNano }}`,
+		"img/single.html", `Image: {{ with .Resources.GetMatch "sunset*" }}{{ (.Resize "10x").Width }}{{ end }}|{{ now.UnixNano }}`,
+	)
+	b.WithContent(
+		"p1/index.md", "---\ntitle: P1\n---\n",
+		"p1/data.txt", "Data",
+		"img/p2/index.md", "---\ntitle: P2\n---\n",
+	)
+	b.WithSunset("content/img/p2/sunset.jpg")
+
+	build := func() (p1, p2 string) {
+		b.H = nil
+		c.Assert(b.Fs.Destination.RemoveAll("public"), qt.IsNil)
+		b.Build(BuildCfg{})
+		return b.FileContent("public/p1/index.html"), b.FileContent("public/img/p2/index.html")
+	}
+
+	p1, p2 := build()
+	c.Assert(p2, qt.Contains, "Image: 10|")
+
+	p1b, p2b := build()
+	c.Assert(p1b, qt.Equals, p1)
+	// Processing an image writes it to /public.
+	c.Assert(p2b, qt.Not(qt.Equals), p2)
+	b.AssertFileContent("public/p1/data.txt", "Data")
+	c.Assert(b.CheckExists("public/img/p2/sunset_hu59e56ffff1bc1d8d122b1403d34e039f_90587_10x0_resize_q75_box.jpg"), qt.IsTrue)
+}
@@ -132,6 +132,65 @@ Do not go gentle into that good night.
 	c.Assert(ugly.RelPermalink(), qt.Equals, "/sect2/p2.html")
 }
 
+func TestURLRules(t *testing.T) {
+	config := `
+baseURL = "https://example.org/"
+paginate = 1
+
+[outputs]
+page = ["HTML", "JSON"]
+
+[[urlRules]]
+section = "kb"
+uglyURLs = true
+
+[[urlRules]]
+section = "kb"
+outputFormat = "json"
+uglyURLs = false
+
+[[urlRules]]
+section = "docs"
+trailingSlash = false
+
+[[urlRules]]
+path = "/docs/legacy/**"
+kind = "page"
+uglyURLs = true
+`
+
+	b := newTestSitesBuilder(t).WithConfigFile("toml", config)
+	b.WithContent(
+		"kb/a.md", "---\ntitle: A\n---\n",
+		"docs/b.md", "---\ntitle: B\n---\n",
+		"docs/c.md", "---\ntitle: C\n---\n",
+		"docs/legacy/d.md", "---\ntitle: D\n---\n",
+		"blog/e.md", "---\ntitle: E\n---\n",
+	)
+	b.WithTemplatesAdded(
+		"_default/single.json", `{"title": {{ .Title | jsonify }}}`,
+		"_default/list.html", `{{ .Title }}|{{ .RelPermalink }}|{{ with .Paginator.Next }}Next: {{ .URL }}{{ end }}`,
+		"_default/single.html", `{{ .Title }}|{{ range .OutputFormats }}{{ .Name }}: {{ .RelPermalink }}|{{ end }}`,
+	)
+
+	b.Build(BuildCfg{})
+
+	b.AssertFileContent("public/kb/a.html", "A|HTML: /kb/a.html|JSON: /kb/a/index.json|")
+	b.AssertFileContent("public/docs/b/index.html", "B|HTML: /docs/b|JSON: /docs/b/index.json|")
+	b.AssertFileContent("public/docs/legacy/d.html", "D|HTML: /docs/legacy/d.html|")
+	b.AssertFileContent("public/blog/e/index.html", "E|HTML: /blog/e/|")
+	b.AssertFileContent("public/docs/index.html", "|/docs|Next: /docs/page/2")
+	b.AssertFileContent("public/docs/page/2/index.html", "|/docs|Next: /docs/page/3")
+	b.AssertFileContent("public/blog/index.html", "|/blog/|")
+
+	b = newTestSitesBuilder(t).WithConfigFile("toml", `
+[[urlRules]]
+kind = "pages"
+uglyURLs = true
+`)
+	b.Assert(b.CreateSitesE(), qt.ErrorMatches, `.*"pages" is not a valid Page Kind`)
+}
+
 func TestSectionWithURLInFrontMatter(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,108 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"github.com/gohugoio/hugo/config"
+	"github.com/gohugoio/hugo/hugofs/glob"
+	"github.com/gohugoio/hugo/resources/page"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+	"github.com/spf13/cast"
+)
+
+const urlRulesConfigKey = "urlRules"
+
+// urlRule configures the URLs of the pages it matches, overriding the
+// global uglyURLs setting and the trailing slash in pretty URLs.
+type urlRule struct {
+	// Matches the pages by their content path, Kind and language, as in
+	// the cascade _target.
+	page.PageMatcher `mapstructure:",squash"`
+
+	// A Glob pattern matching the Page's top level section, e.g. "{kb,faq}".
+	Section string
+
+	// A Glob pattern matching the output format name, e.g. "html".
+	OutputFormat string
+
+	// If set, whether to use ugly URLs, e.g. /kb/my-page.html.
+	UglyURLs *bool
+
+	// If set to false, pretty URLs are linked to without the trailing slash,
+	// e.g. /posts/my-post. The page is still published as index.html.
+	TrailingSlash *bool
+}
+
+func (r urlRule) matches(p page.Page, d page.TargetPathDescriptor) bool {
+	if !r.PageMatcher.Matches(p) {
+		return false
+	}
+	if r.Section != "" {
+		g, err := glob.GetGlob(r.Section)
+		if err == nil && !g.Match(p.Section()) {
+			return false
+		}
+	}
+	if r.OutputFormat != "" {
+		g, err := glob.GetGlob(r.OutputFormat)
+		if err == nil && !g.Match(d.Type.Name) {
+			return false
+		}
+	}
+	return true
+}
+
+type urlRules []urlRule
+
+// apply applies the rules matching p and the output format in d to d.
+// For each setting, the last matching rule that sets it wins.
+func (rules urlRules) apply(p page.Page, d *page.TargetPathDescriptor) {
+	for _, r := range rules {
+		if !r.matches(p, *d) {
+			continue
+		}
+		if r.UglyURLs != nil {
+			d.UglyURLs = *r.UglyURLs
+		}
+		if r.TrailingSlash != nil {
+			d.NoTrailingSlash = !*r.TrailingSlash
+		}
+	}
+}
+
+func decodeURLRules(cfg config.Provider) (urlRules, error) {
+	if !cfg.IsSet(urlRulesConfigKey) {
+		return nil, nil
+	}
+
+	var rules urlRules
+	for _, v := range cast.ToSlice(cfg.Get(urlRulesConfigKey)) {
+		var r urlRule
+		if err := mapstructure.WeakDecode(v, &r); err != nil {
+			return nil, errors.Wrapf(err, "failed to decode %s config", urlRulesConfigKey)
+		}
+		if err := page.DecodePageMatcher(v, &r.PageMatcher); err != nil {
+			return nil, errors.Wrapf(err, "failed to decode %s config", urlRulesConfigKey)
+		}
+		for _, pattern := range []string{r.Path, r.Kind, r.Lang, r.Section, r.OutputFormat} {
+			if _, err := glob.GetGlob(pattern); err != nil {
+				return nil, errors.Wrapf(err, "%s: invalid pattern %q", urlRulesConfigKey, pattern)
+			}
+		}
+		rules = append(rules, r)
+	}
+
+	return rules, nil
+}
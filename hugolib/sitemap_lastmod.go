@@ -0,0 +1,89 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gohugoio/hugo/config"
+	"github.com/pkg/errors"
+)
+
+// decodeSitemapConfig decodes the sitemap config, e.g.:
+//
+//  [sitemap]
+//  changefreq = "monthly"
+//  lastmodSource = "git"
+//  lastmodResources = true
+func decodeSitemapConfig(cfg config.Provider) (config.Sitemap, error) {
+	sm := config.DecodeSitemap(config.Sitemap{Priority: -1, Filename: "sitemap.xml", LastmodSource: config.SitemapLastmodSourceLastmod}, cfg.GetStringMap("sitemap"))
+	return sm, validateSitemapLastmodSource(sm)
+}
+
+func validateSitemapLastmodSource(sm config.Sitemap) error {
+	switch sm.LastmodSource {
+	case config.SitemapLastmodSourceLastmod, config.SitemapLastmodSourceGit:
+		return nil
+	default:
+		return errors.Errorf("invalid sitemap lastmodSource %q, must be %q or %q", sm.LastmodSource, config.SitemapLastmodSourceLastmod, config.SitemapLastmodSourceGit)
+	}
+}
+
+// resetSitemapLastmod makes the lastmod date in the sitemap be resolved
+// again, e.g. after the Git info is reloaded on a rebuild.
+func (p *pageState) resetSitemapLastmod() {
+	p.sitemapLastmodInit = sync.Once{}
+	p.sitemapLastmod = time.Time{}
+}
+
+// Sitemap returns the sitemap settings for the page, with the lastmod date
+// resolved from the configured sources.
+func (p *pageState) Sitemap() config.Sitemap {
+	sm := p.m.Sitemap()
+	p.sitemapLastmodInit.Do(func() {
+		p.sitemapLastmod = p.resolveSitemapLastmod(sm)
+	})
+	sm.Lastmod = p.sitemapLastmod
+	return sm
+}
+
+func (p *pageState) resolveSitemapLastmod(sm config.Sitemap) time.Time {
+	lastmod := p.Lastmod()
+	if sm.LastmodSource == config.SitemapLastmodSourceGit && p.gitInfo != nil {
+		lastmod = p.gitInfo.AuthorDate
+	}
+
+	if !sm.LastmodResources {
+		return lastmod
+	}
+
+	for _, fi := range p.resourceFiles {
+		// File modification times are not preserved in e.g. a Git checkout,
+		// so prefer the Git author date.
+		changed := fi.ModTime()
+		if p.s.Deps.Reproducible {
+			changed = p.s.Deps.Clock.Now()
+		}
+		gi, err := p.s.h.gitInfoForFilename(fi.Meta().Filename())
+		if err == nil && gi != nil {
+			changed = gi.AuthorDate
+		}
+		if changed.After(lastmod) {
+			lastmod = changed
+		}
+	}
+
+	return lastmod
+}
@@ -0,0 +1,149 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"strings"
+
+	"github.com/gohugoio/hugo/common/maps"
+	"github.com/gohugoio/hugo/config"
+	"github.com/gohugoio/hugo/hugofs/glob"
+	"github.com/gohugoio/hugo/resources/page"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+	"github.com/spf13/cast"
+)
+
+// sitemapRule sets the sitemap settings for the pages matching it.
+type sitemapRule struct {
+	// Matches the page's path, kind and language, as in cascade.
+	Target page.PageMatcher `mapstructure:"-"`
+
+	// A Glob pattern matching the page's section.
+	Section string
+
+	// The params the page must have. A list param, e.g. tags, matches if it
+	// contains the value.
+	Params map[string]interface{}
+
+	Disable    *bool
+	ChangeFreq *string
+	Priority   *float64
+}
+
+func (r sitemapRule) matches(p *pageState) bool {
+	if r.Section != "" {
+		g, err := glob.GetGlob(r.Section)
+		if err != nil || !g.Match(p.Section()) {
+			return false
+		}
+	}
+
+	for k, v := range r.Params {
+		if !sitemapParamMatches(p.m.params[k], v) {
+			return false
+		}
+	}
+
+	return r.Target.Matches(p)
+}
+
+func (r sitemapRule) apply(sm config.Sitemap) config.Sitemap {
+	if r.Disable != nil {
+		sm.Disable = *r.Disable
+	}
+	if r.ChangeFreq != nil {
+		sm.ChangeFreq = *r.ChangeFreq
+	}
+	if r.Priority != nil {
+		sm.Priority = *r.Priority
+	}
+	return sm
+}
+
+func sitemapParamMatches(pv, v interface{}) bool {
+	if pv == nil {
+		return false
+	}
+
+	s := cast.ToString(v)
+
+	switch vv := pv.(type) {
+	case []string:
+		for _, e := range vv {
+			if e == s {
+				return true
+			}
+		}
+		return false
+	case []interface{}:
+		for _, e := range vv {
+			if cast.ToString(e) == s {
+				return true
+			}
+		}
+		return false
+	}
+
+	return cast.ToString(pv) == s
+}
+
+type sitemapRules []sitemapRule
+
+// apply applies the first rule matching p to sm.
+func (rules sitemapRules) apply(p *pageState, sm config.Sitemap) config.Sitemap {
+	for _, r := range rules {
+		if r.matches(p) {
+			return r.apply(sm)
+		}
+	}
+	return sm
+}
+
+// decodeSitemapRules decodes the rules in the sitemap config, e.g.:
+//
+//  [[sitemap.rules]]
+//  section = "legal"
+//  disable = true
+func decodeSitemapRules(cfg config.Provider) (sitemapRules, error) {
+	v := cfg.GetStringMap("sitemap")["rules"]
+	if v == nil {
+		return nil, nil
+	}
+
+	var rules sitemapRules
+	for _, vv := range cast.ToSlice(v) {
+		m, err := maps.ToStringMapE(vv)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode sitemap rule")
+		}
+		var r sitemapRule
+		if err := mapstructure.WeakDecode(m, &r); err != nil {
+			return nil, errors.Wrap(err, "failed to decode sitemap rule")
+		}
+		if err := page.DecodePageMatcher(m, &r.Target); err != nil {
+			return nil, errors.Wrap(err, "failed to decode sitemap rule")
+		}
+		if r.Params != nil {
+			params := make(map[string]interface{})
+			for k, v := range r.Params {
+				params[strings.ToLower(k)] = v
+			}
+			r.Params = params
+		}
+		rules = append(rules, r)
+	}
+
+	return rules, nil
+}
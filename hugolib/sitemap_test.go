@@ -16,10 +16,12 @@ package hugolib
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	qt "github.com/frankban/quicktest"
 	"github.com/gohugoio/hugo/config"
 	"github.com/gohugoio/hugo/deps"
+	"github.com/gohugoio/hugo/resources/page"
 	"github.com/gohugoio/hugo/tpl"
 )
 
@@ -87,12 +89,14 @@ func doTestSitemapOutput(t *testing.T, internal bool) {
 
 func TestParseSitemap(t *testing.T) {
 	t.Parallel()
-	expected := config.Sitemap{Priority: 3.0, Filename: "doo.xml", ChangeFreq: "3"}
+	expected := config.Sitemap{Priority: 3.0, Filename: "doo.xml", ChangeFreq: "3", LastmodSource: "git", LastmodResources: true}
 	input := map[string]interface{}{
-		"changefreq": "3",
-		"priority":   3.0,
-		"filename":   "doo.xml",
-		"unknown":    "ignore",
+		"changefreq":       "3",
+		"priority":         3.0,
+		"filename":         "doo.xml",
+		"lastmodsource":    "Git",
+		"lastmodresources": true,
+		"unknown":          "ignore",
 	}
 	result := config.DecodeSitemap(config.Sitemap{}, input)
 
@@ -118,3 +122,127 @@ outputs: [ "html", "amp" ]
 	// Should link to the HTML version.
 	b.AssertFileContent("public/sitemap.xml", " <loc>http://example.com/blog/html-amp/</loc>")
 }
+
+func TestSitemapRules(t *testing.T) {
+	b := newTestSitesBuilder(t).WithConfigFile("toml", `
+baseURL = "http://example.com/"
+
+[sitemap]
+changefreq = "monthly"
+priority = 0.5
+
+[[sitemap.rules]]
+section = "legal"
+disable = true
+
+[[sitemap.rules]]
+kind = "term"
+priority = 0.1
+changefreq = "yearly"
+
+[[sitemap.rules]]
+disable = true
+[sitemap.rules.params]
+noindex = true
+
+[[sitemap.rules]]
+section = "blog"
+priority = 0.9
+changefreq = "daily"
+[sitemap.rules.params]
+tags = "featured"
+`)
+
+	b.WithContent("legal/privacy.md", "---\ntitle: Privacy\n---",
+		"blog/featured.md", "---\ntitle: Featured\ntags: [featured, go]\n---",
+		"blog/plain.md", "---\ntitle: Plain\n---",
+		"blog/hidden.md", "---\ntitle: Hidden\nnoindex: true\n---",
+		"blog/override.md", "---\ntitle: Override\ntags: [featured]\nsitemap:\n  priority: 0.2\n---",
+	)
+
+	b.Build(BuildCfg{})
+
+	b.AssertFileContent("public/sitemap.xml",
+		"<loc>http://example.com/blog/featured/</loc>\n    <changefreq>daily</changefreq>\n    <priority>0.9</priority>",
+		"<loc>http://example.com/blog/plain/</loc>\n    <changefreq>monthly</changefreq>\n    <priority>0.5</priority>",
+		"<loc>http://example.com/blog/override/</loc>\n    <changefreq>daily</changefreq>\n    <priority>0.2</priority>",
+		"<loc>http://example.com/tags/go/</loc>\n    <changefreq>yearly</changefreq>\n    <priority>0.1</priority>",
+	)
+
+	content := b.FileContent("public/sitemap.xml")
+	b.Assert(content, qt.Not(qt.Contains), "legal/privacy")
+	b.Assert(content, qt.Not(qt.Contains), "blog/hidden")
+}
+
+func TestSitemapLastmod(t *testing.T) {
+	c := qt.New(t)
+
+	b := newTestSitesBuilder(t).WithConfigFile("toml", `
+baseURL = "http://example.com/"
+
+[sitemap]
+lastmodResources = true
+`)
+
+	b.WithSourceFile(
+		"content/blog/changed/index.md", "---\ntitle: Changed\nlastmod: 2020-01-01\n---",
+		"content/blog/changed/data.json", "{}",
+		"content/blog/unchanged/index.md", "---\ntitle: Unchanged\nlastmod: 2020-01-01\n---",
+		"content/blog/unchanged/data.json", "{}",
+		"content/blog/disabled/index.md", "---\ntitle: Disabled\nlastmod: 2020-01-01\nsitemap:\n  lastmodResources: false\n---",
+		"content/blog/disabled/data.json", "{}",
+	)
+
+	for name, date := range map[string]string{"changed": "2021-02-03", "unchanged": "2019-05-06", "disabled": "2021-02-03"} {
+		d, err := time.Parse("2006-01-02", date)
+		c.Assert(err, qt.IsNil)
+		c.Assert(b.Fs.Source.Chtimes(b.absFilename("content/blog/"+name+"/data.json"), d, d), qt.IsNil)
+	}
+
+	b.Build(BuildCfg{})
+
+	b.AssertFileContent("public/sitemap.xml",
+		"<loc>http://example.com/blog/changed/</loc>\n    <lastmod>2021-02-03T00:00:00+00:00</lastmod>",
+		"<loc>http://example.com/blog/unchanged/</loc>\n    <lastmod>2020-01-01T00:00:00+00:00</lastmod>",
+		"<loc>http://example.com/blog/disabled/</loc>\n    <lastmod>2020-01-01T00:00:00+00:00</lastmod>",
+	)
+
+	// The page's .Lastmod is not changed.
+	b.Assert(b.H.Sites[0].getPage(page.KindPage, "blog/changed/index.md").Lastmod().Year(), qt.Equals, 2020)
+
+	cfg := config.New()
+	cfg.Set("sitemap", map[string]interface{}{"lastmodSource": "mtime"})
+	_, err := decodeSitemapConfig(cfg)
+	c.Assert(err, qt.ErrorMatches, `invalid sitemap lastmodSource "mtime".*`)
+
+	b = newTestSitesBuilder(t).WithSimpleConfigFile()
+	b.WithContent("p1.md", "---\ntitle: P1\nsitemap:\n  lastmodSource: mtime\n---")
+	b.Assert(b.BuildE(BuildCfg{}), qt.ErrorMatches, `.*p1.md.*invalid sitemap lastmodSource "mtime".*`)
+}
+
+func TestSitemapLastmodRebuild(t *testing.T) {
+	c := qt.New(t)
+
+	b := newTestSitesBuilder(t).Running().WithConfigFile("toml", `
+baseURL = "http://example.com/"
+
+[sitemap]
+lastmodResources = true
+`)
+
+	b.WithSourceFile(
+		"content/blog/bundle/index.md", "---\ntitle: Bundle\nlastmod: 2020-01-01\n---",
+		"content/blog/bundle/data.json", "{}",
+		"content/blog/other.md", "---\ntitle: Other\n---",
+	)
+
+	b.Build(BuildCfg{})
+	b.AssertFileContent("public/sitemap.xml", "<loc>http://example.com/blog/bundle/</loc>\n    <lastmod>2020-01-01T00:00:00+00:00</lastmod>")
+
+	// The bundle is not rebuilt, but its lastmod date is resolved again.
+	d := time.Date(2021, 6, 7, 0, 0, 0, 0, time.UTC)
+	c.Assert(b.Fs.Source.Chtimes(b.absFilename("content/blog/bundle/data.json"), d, d), qt.IsNil)
+	b.EditFiles("content/blog/other.md", "---\ntitle: Other Edited\n---")
+	b.Build(BuildCfg{})
+	b.AssertFileContent("public/sitemap.xml", "<loc>http://example.com/blog/bundle/</loc>\n    <lastmod>2021-06-07T00:00:00+00:00</lastmod>")
+}
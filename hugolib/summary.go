@@ -0,0 +1,103 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"strings"
+
+	"github.com/gohugoio/hugo/config"
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+)
+
+const (
+	summaryConfigKey = "summary"
+
+	// The summary modes.
+	summaryModeText = "text"
+	summaryModeHTML = "html"
+)
+
+// summaryConfig configures the automatic summaries.
+type summaryConfig struct {
+	// How to create the summary, text (default) or html.
+	// The text mode truncates the plain text of the content. The html
+	// mode keeps inline markup, leaves out shortcode output, footnotes,
+	// headings and code blocks, and never splits an HTML element.
+	Mode string
+
+	// The summary length in words per section, overriding summaryLength.
+	// Only used in html mode.
+	Sections map[string]int
+}
+
+func decodeSummaryConfig(cfg config.Provider) (summaryConfig, error) {
+	c := summaryConfig{Mode: summaryModeText}
+
+	if !cfg.IsSet(summaryConfigKey) {
+		return c, nil
+	}
+
+	if err := mapstructure.WeakDecode(cfg.GetStringMap(summaryConfigKey), &c); err != nil {
+		return c, errors.Wrapf(err, "failed to decode %s config", summaryConfigKey)
+	}
+
+	c.Mode = strings.ToLower(c.Mode)
+	switch c.Mode {
+	case "":
+		c.Mode = summaryModeText
+	case summaryModeText, summaryModeHTML:
+	default:
+		return c, errors.Errorf("%s: invalid mode %q, must be text or html", summaryConfigKey, c.Mode)
+	}
+
+	sections := make(map[string]int)
+	for k, v := range c.Sections {
+		if v <= 0 {
+			return c, errors.Errorf("%s: the length for section %q must be > 0", summaryConfigKey, k)
+		}
+		sections[strings.ToLower(k)] = v
+	}
+	c.Sections = sections
+
+	return c, nil
+}
+
+// htmlSummary creates the summary in html mode from content, the rendered
+// content with shortcode placeholders.
+func (p *pageState) htmlSummary(content []byte, placeholders map[string]string) (string, bool, error) {
+	cfg := p.s.siteCfg.summary
+
+	length := p.s.language.GetInt("summaryLength")
+	if l, found := cfg.Sections[strings.ToLower(p.Section())]; found {
+		length = l
+	}
+
+	if len(placeholders) > 0 {
+		empty := make(map[string]string, len(placeholders))
+		for k := range placeholders {
+			empty[k] = ""
+		}
+		var err error
+		content, err = replaceShortcodeTokens(append([]byte(nil), content...), empty)
+		if err != nil {
+			return "", false, err
+		}
+	}
+
+	summary, truncated := helpers.TruncateHTMLToWholeSentence(string(content), length)
+
+	return summary, truncated, nil
+}
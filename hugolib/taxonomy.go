@@ -16,12 +16,75 @@ package hugolib
 import (
 	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/gohugoio/hugo/compare"
+	"github.com/gohugoio/hugo/config"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
 
 	"github.com/gohugoio/hugo/resources/page"
 )
 
+const taxonomyOptionsConfigKey = "taxonomyOptions"
+
+// taxonomyOptions configures a taxonomy and its term pages.
+type taxonomyOptions struct {
+	// The order of the term pages in .Pages and the paginator of the
+	// taxonomy page. One of "default", "title", "weight", "count" (the
+	// number of pages in the term, descending) or "params.<key>".
+	TermsOrderBy string
+
+	// The default number of pages per pager on the term pages.
+	Paginate int
+}
+
+// sortTerms sorts the term pages in a new slice according to TermsOrderBy.
+func (o taxonomyOptions) sortTerms(terms page.Pages) page.Pages {
+	switch orderBy := strings.ToLower(o.TermsOrderBy); {
+	case orderBy == "title":
+		return terms.ByTitle()
+	case orderBy == "weight":
+		return terms.ByWeight()
+	case orderBy == "count":
+		terms = append(page.Pages(nil), terms...)
+		sort.SliceStable(terms, func(i, j int) bool {
+			return len(terms[i].Pages()) > len(terms[j].Pages())
+		})
+		return terms
+	case strings.HasPrefix(orderBy, "params."):
+		return terms.ByParam(strings.TrimPrefix(orderBy, "params."))
+	default:
+		return terms
+	}
+}
+
+// decodeTaxonomyOptions decodes the taxonomyOptions config, keyed by the
+// plural name of the taxonomy.
+func decodeTaxonomyOptions(cfg config.Provider) (map[string]taxonomyOptions, error) {
+	m := cfg.GetStringMap(taxonomyOptionsConfigKey)
+	if len(m) == 0 {
+		return nil, nil
+	}
+
+	options := make(map[string]taxonomyOptions)
+	for plural, v := range m {
+		var o taxonomyOptions
+		if err := mapstructure.WeakDecode(v, &o); err != nil {
+			return nil, errors.Wrapf(err, "failed to decode %s config", taxonomyOptionsConfigKey)
+		}
+		switch orderBy := strings.ToLower(o.TermsOrderBy); {
+		case orderBy == "", orderBy == "default", orderBy == "title", orderBy == "weight", orderBy == "count":
+		case strings.HasPrefix(orderBy, "params.") && len(orderBy) > len("params."):
+		default:
+			return nil, errors.Errorf("%s: invalid termsOrderBy %q for %q", taxonomyOptionsConfigKey, o.TermsOrderBy, plural)
+		}
+		options[strings.ToLower(plural)] = o
+	}
+
+	return options, nil
+}
+
 // The TaxonomyList is a list of all taxonomies and their values
 // e.g. List['tags'] => TagTaxonomy (from above)
 type TaxonomyList map[string]Taxonomy
@@ -99,6 +162,47 @@ func (i Taxonomy) ByCount() OrderedTaxonomy {
 	return ia
 }
 
+// ByWeight returns an ordered taxonomy sorted by the weight of the term
+// pages, e.g. set in content/tags/go/_index.md, as in .Pages.ByWeight.
+func (i Taxonomy) ByWeight() OrderedTaxonomy {
+	return i.byTermPages(page.Pages.ByWeight)
+}
+
+// ByParam returns an ordered taxonomy sorted by the given param in the
+// term pages, as in .Pages.ByParam. Terms without the param come last.
+func (i Taxonomy) ByParam(paramsKey interface{}) OrderedTaxonomy {
+	return i.byTermPages(func(p page.Pages) page.Pages {
+		return p.ByParam(paramsKey)
+	})
+}
+
+// byTermPages orders the taxonomy by sorting its term pages with by.
+// Terms with equal sort values keep their alphabetical order.
+func (i Taxonomy) byTermPages(by func(page.Pages) page.Pages) OrderedTaxonomy {
+	var (
+		ia     = i.Alphabetical()
+		terms  page.Pages
+		byTerm = make(map[page.Page]OrderedTaxonomyEntry)
+		rest   OrderedTaxonomy
+	)
+
+	for _, e := range ia {
+		if p := e.WeightedPages.Page(); p != nil {
+			terms = append(terms, p)
+			byTerm[p] = e
+		} else {
+			rest = append(rest, e)
+		}
+	}
+
+	ordered := make(OrderedTaxonomy, 0, len(ia))
+	for _, p := range by(terms) {
+		ordered = append(ordered, byTerm[p])
+	}
+
+	return append(ordered, rest...)
+}
+
 // Pages returns the Pages for this taxonomy.
 func (ie OrderedTaxonomyEntry) Pages() page.Pages {
 	return ie.WeightedPages.Pages()
@@ -0,0 +1,137 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"github.com/gohugoio/hugo/common/maps"
+	"github.com/pkg/errors"
+	"github.com/spf13/cast"
+)
+
+// The key of the term metadata in the site config and the data folder,
+// e.g. data/terms/tags.yaml.
+const termMetadataKey = "terms"
+
+// termMetadata maps a taxonomy's plural name and the term key to the
+// metadata defined for the term.
+type termMetadata map[string]map[string]maps.Params
+
+// termMetadata returns the front matter defined for the term page with the
+// given sections, i.e. the taxonomy's plural name and the term key, in the
+// terms data files or site config. It returns nil if there is none.
+func (s *Site) termMetadata(sections []string) (maps.Params, error) {
+	if len(sections) != 2 {
+		return nil, nil
+	}
+	v, err := s.init.termMetadata.Do()
+	if err != nil {
+		return nil, err
+	}
+	return v.(termMetadata)[sections[0]][s.getTaxonomyKey(sections[1])], nil
+}
+
+func (s *Site) loadTermMetadata() (termMetadata, error) {
+	tm := make(termMetadata)
+
+	// Data files have precedence over the site config.
+	if err := tm.add(s, s.h.Data()[termMetadataKey]); err != nil {
+		return nil, errors.Wrap(err, "failed to decode terms in data")
+	}
+	if err := tm.add(s, s.language.Get(termMetadataKey)); err != nil {
+		return nil, errors.Wrap(err, "failed to decode terms in config")
+	}
+
+	return tm, nil
+}
+
+// add adds the terms in v, a map of taxonomy to term to metadata, not
+// already set.
+func (tm termMetadata) add(s *Site, v interface{}) error {
+	if v == nil {
+		return nil
+	}
+
+	taxonomies, err := maps.ToStringMapE(v)
+	if err != nil {
+		return err
+	}
+
+	for plural, terms := range taxonomies {
+		termsm, err := maps.ToStringMapE(terms)
+		if err != nil {
+			return errors.Wrapf(err, "taxonomy %q", plural)
+		}
+		plural = s.getTaxonomyKey(plural)
+		if tm[plural] == nil {
+			tm[plural] = make(map[string]maps.Params)
+		}
+		for term, meta := range termsm {
+			metam, err := maps.ToStringMapE(meta)
+			if err != nil {
+				return errors.Wrapf(err, "term %q in taxonomy %q", term, plural)
+			}
+			// Copy the map, PrepareParams modifies it and any nested maps.
+			params := copyTermParams(metam).(maps.Params)
+			maps.PrepareParams(params)
+
+			// Allow custom params to be set below params, as in config.
+			if p, found := params["params"]; found {
+				delete(params, "params")
+				if pm, ok := p.(maps.Params); ok {
+					for k, v := range pm {
+						if _, found := params[k]; !found {
+							params[k] = v
+						}
+					}
+				}
+			}
+
+			key := s.getTaxonomyKey(term)
+			existing, found := tm[plural][key]
+			if !found {
+				tm[plural][key] = params
+				continue
+			}
+			for k, v := range params {
+				if _, found := existing[k]; !found {
+					existing[k] = v
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func copyTermParams(v interface{}) interface{} {
+	switch vv := v.(type) {
+	case map[string]interface{}:
+		m := make(maps.Params, len(vv))
+		for k, v := range vv {
+			m[k] = copyTermParams(v)
+		}
+		return m
+	case maps.Params:
+		return copyTermParams(map[string]interface{}(vv))
+	case map[interface{}]interface{}:
+		return copyTermParams(cast.ToStringMap(vv))
+	case []interface{}:
+		s := make([]interface{}, len(vv))
+		for i, v := range vv {
+			s[i] = copyTermParams(v)
+		}
+		return s
+	}
+	return v
+}
@@ -0,0 +1,71 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"testing"
+)
+
+func TestTermMetadata(t *testing.T) {
+	t.Parallel()
+
+	b := newTestSitesBuilder(t).WithConfigFile("toml", `
+baseURL = "https://example.org"
+
+[taxonomies]
+tag = "tags"
+category = "categories"
+
+[terms.tags.hugo]
+title = "Hugo from config"
+description = "Not used"
+weight = 3
+[terms.tags."Static Sites"]
+title = "Static Sites"
+[terms.categories.news]
+title = "News from config"
+`)
+
+	b.WithSourceFile("data/terms/tags.yaml", `
+hugo:
+  title: "The Hugo Tag"
+  description: "All about Hugo."
+  images: ["hugo.png"]
+  params:
+    color: Blue
+go:
+  title: "Go"
+`)
+
+	b.WithContent("p1.md", `---
+title: "P1"
+tags: ["Hugo", "Go", "Static Sites"]
+categories: ["News"]
+---
+`, "tags/go/_index.md", `---
+title: "The Go Tag"
+---
+`)
+
+	b.WithTemplates("_default/list.html", `
+Title: {{ .Title }}|Description: {{ .Description }}|Images: {{ .Params.images }}|Color: {{ .Params.color }}|Weight: {{ .Weight }}|
+`)
+
+	b.Build(BuildCfg{})
+
+	b.AssertFileContent("public/tags/hugo/index.html", "Title: The Hugo Tag|Description: All about Hugo.|Images: [hugo.png]|Color: Blue|Weight: 3|")
+	b.AssertFileContent("public/tags/go/index.html", "Title: The Go Tag|")
+	b.AssertFileContent("public/tags/static-sites/index.html", "Title: Static Sites|Description: |")
+	b.AssertFileContent("public/categories/news/index.html", "Title: News from config|")
+}
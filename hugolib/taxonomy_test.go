@@ -694,3 +694,116 @@ abcdefgs: {{ template "print-page" $abcdefgs }}|IsAncestor: {{ $abcdefgs.IsAnces
     abcdefgs: /abcdefgs/|Abcdefgs|taxonomy|Parent: /|CurrentSection: /|FirstSection: /|IsAncestor: true|IsDescendant: false
 `)
 }
+
+func TestTaxonomiesPerLanguage(t *testing.T) {
+	t.Parallel()
+
+	b := newTestSitesBuilder(t)
+	b.WithConfigFile("toml", `
+baseURL = "https://example.org"
+defaultContentLanguage = "en"
+
+[taxonomies]
+tag = "tags"
+category = "categories"
+
+[permalinks]
+posts = "/posts/:slug/"
+docs = "/documentation/:slug/"
+tags = "/topics/:slug/"
+
+[languages]
+[languages.en]
+weight = 1
+[languages.es]
+weight = 2
+[languages.es.taxonomies]
+tag = "etiquetas"
+[languages.es.permalinks]
+posts = "/articulos/:slug/"
+etiquetas = "/temas/:slug/"
+tags = ""
+`)
+
+	b.WithContent(
+		"posts/p1.en.md", "---\ntitle: P1\ntags: [a]\ncategories: [c]\n---",
+		"posts/p1.es.md", "---\ntitle: P1\netiquetas: [a]\ncategories: [c]\n---",
+		"docs/d1.en.md", "---\ntitle: D1\n---",
+		"docs/d1.es.md", "---\ntitle: D1\n---",
+	)
+
+	b.WithTemplatesAdded("index.html", `{{ range site.Pages }}{{ .Kind }}:{{ .RelPermalink }}|{{ end }}`)
+
+	b.Build(BuildCfg{})
+
+	b.AssertFileContent("public/index.html",
+		"page:/posts/p1/|",
+		"page:/documentation/d1/|",
+		"term:/topics/a/|",
+		"term:/categories/c/|",
+	)
+
+	b.AssertFileContent("public/es/index.html",
+		"page:/es/articulos/p1/|",
+		"page:/es/documentation/d1/|",
+		"term:/es/temas/a/|",
+		"taxonomy:/es/etiquetas/|",
+	)
+
+	c := qt.New(t)
+	c.Assert(b.CheckExists("public/es/categories/c/index.html"), qt.Equals, false)
+	c.Assert(b.CheckExists("public/es/tags/index.html"), qt.Equals, false)
+}
+
+func TestTaxonomyOrderingAndOptions(t *testing.T) {
+	config := `
+baseURL = "https://example.org/"
+paginate = 10
+
+[taxonomies]
+tag = "tags"
+category = "categories"
+
+[taxonomyOptions.tags]
+termsOrderBy = "count"
+paginate = 1
+
+[taxonomyOptions.categories]
+termsOrderBy = "params.rank"
+`
+
+	b := newTestSitesBuilder(t).WithConfigFile("toml", config)
+	b.WithContent(
+		"p1.md", "---\ntitle: P1\ntags: [a, b]\ncategories: [go, js]\n---\n",
+		"p2.md", "---\ntitle: P2\ntags: [b, c]\ncategories: [js, rust]\n---\n",
+		"p3.md", "---\ntitle: P3\ntags: [b, c]\n---\n",
+		"tags/a/_index.md", "---\ntitle: A\nweight: 1\n---\n",
+		"tags/c/_index.md", "---\ntitle: C\nweight: 2\n---\n",
+		"categories/go/_index.md", "---\ntitle: Go\nrank: 3\n---\n",
+		"categories/js/_index.md", "---\ntitle: JS\nrank: 1\n---\n",
+		"categories/rust/_index.md", "---\ntitle: Rust\nrank: 2\n---\n",
+	)
+	b.WithTemplatesAdded(
+		"index.html", `
+ByWeight: {{ range site.Taxonomies.tags.ByWeight }}{{ .Name }} {{ end }}|
+ByParam: {{ range site.Taxonomies.categories.ByParam "rank" }}{{ .Name }} {{ end }}|
+ByCount: {{ range site.Taxonomies.tags.ByCount }}{{ .Name }} {{ end }}|
+`,
+		"_default/terms.html", `Terms: {{ range .Pages }}{{ .Title }} {{ end }}|`,
+		"_default/taxonomy.html", `{{ .Title }}|Pager: {{ range .Paginator.Pages }}{{ .Title }} {{ end }}|{{ .Paginator.TotalPages }}|`,
+	)
+
+	b.Build(BuildCfg{})
+
+	b.AssertFileContent("public/index.html", "ByWeight: a c b |", "ByParam: js rust go |", "ByCount: b c a |")
+	b.AssertFileContent("public/tags/index.html", "Terms: b C A |")
+	b.AssertFileContent("public/categories/index.html", "Terms: JS Rust Go |")
+	b.AssertFileContent("public/tags/b/index.html", "b|Pager: P1 |3|")
+	b.AssertFileContent("public/categories/js/index.html", "JS|Pager: P1 P2 |1|")
+
+	b = newTestSitesBuilder(t).WithConfigFile("toml", `
+[taxonomyOptions.tags]
+termsOrderBy = "date"
+`)
+	b.Assert(b.CreateSitesE(), qt.ErrorMatches, `.*invalid termsOrderBy "date" for "tags"`)
+}
@@ -15,7 +15,10 @@ package hugolib
 
 import (
 	"fmt"
+	"io"
 	"path/filepath"
+	"reflect"
+	"regexp"
 	"strings"
 	"testing"
 
@@ -472,6 +475,67 @@ add42: fail: {{ partial "add42.tpl" 0 }}
 		e := b.CreateSites().BuildE(BuildCfg{})
 		b.Assert(e, qt.Not(qt.IsNil))
 	})
+
+	c.Run("Named return values", func(c *qt.C) {
+		b := newBuilder(c)
+
+		b.WithTemplatesAdded(
+			"partials/stats.tpl", `
+{{ $_hugo_config := `+"`"+`{ "returns": { "title": { "type": "string", "required": true }, "count": { "type": "int" }, "draft": { "type": "bool", "default": false } } }`+"`"+` }}
+{{ return "title" (printf "Hello %s" .) "count" "32" }}
+`,
+			"partials/multi.tpl", `
+{{ return "a" 1 "b" . }}
+`,
+			"index.html", `
+{{ $stats := partial "stats.tpl" "World" }}
+Title: {{ $stats.title }}|Count: {{ add $stats.count 10 }}|Draft: {{ $stats.draft }}
+{{ $cached := partialCached "stats.tpl" "Cached" }}
+Cached: {{ $cached.title }}
+{{ with partial "multi.tpl" "B" }}Multi: {{ .a }}|{{ .b }}{{ end }}
+`,
+		)
+
+		b.Build(BuildCfg{})
+
+		b.AssertFileContent("public/index.html",
+			"Title: Hello World|Count: 42|Draft: false",
+			"Cached: Hello Cached",
+			"Multi: 1|B",
+		)
+	})
+
+	c.Run("Invalid named return values", func(c *qt.C) {
+		for _, test := range []struct {
+			partial string
+			expect  string
+		}{
+			{`{{ return "title" "a" "count" "many" }}`, `partial "stats.tpl": return value "count": "many" is not a valid int`},
+			{`{{ return "count" 3 }}`, `partial "stats.tpl": missing required return value "title"`},
+			{`{{ return "title" "a" "cnt" 3 }}`, `partial "stats.tpl": unknown return value "cnt"`},
+			{`{{ return "Hello" }}`, `partial "stats.tpl": expected named return values, got string`},
+		} {
+			b := newBuilder(c)
+			b.WithTemplatesAdded(
+				"partials/stats.tpl", "{{ $_hugo_config := `"+`{ "returns": { "title": { "type": "string", "required": true }, "count": { "type": "int" } } }`+"` }}\n"+test.partial,
+				"index.html", `{{ $stats := partial "stats.tpl" "World" }}{{ $stats.title }}`,
+			)
+			err := b.BuildE(BuildCfg{})
+			b.Assert(err, qt.Not(qt.IsNil))
+			b.Assert(err.Error(), qt.Contains, test.expect)
+		}
+	})
+
+	c.Run("Declared return values without return", func(c *qt.C) {
+		b := newBuilder(c)
+		b.WithTemplatesAdded(
+			"partials/stats.tpl", "{{ $_hugo_config := `"+`{ "returns": { "title": { "type": "string" } } }`+"` }}\nNo return",
+			"index.html", `{{ partial "stats.tpl" "World" }}`,
+		)
+		err := b.CreateSitesE()
+		b.Assert(err, qt.Not(qt.IsNil))
+		b.Assert(err.Error(), qt.Contains, "partial declares return values, but has no return statement")
+	})
 }
 
 func TestPartialCached(t *testing.T) {
@@ -745,3 +809,127 @@ This is single main
 `,
 	)
 }
+
+// placeholderEngine is a minimal template engine used in the tests. It
+// replaces ${Name} with the method or field Name of the data and
+// ${func arg} with the result of calling the template func with arg.
+type placeholderEngine struct{}
+
+type placeholderTemplate struct {
+	src string
+}
+
+var placeholderRe = regexp.MustCompile(`\$\{(\w+)(?: ([^}]+))?\}`)
+
+func (placeholderEngine) Parse(name, src string) (tpl.EngineTemplate, error) {
+	if strings.Count(src, "${") != len(placeholderRe.FindAllString(src, -1)) {
+		return nil, fmt.Errorf("unclosed placeholder")
+	}
+	return placeholderTemplate{src: src}, nil
+}
+
+func (t placeholderTemplate) Execute(wr io.Writer, data interface{}, funcs map[string]interface{}) error {
+	var err error
+	s := placeholderRe.ReplaceAllStringFunc(t.src, func(m string) string {
+		sm := placeholderRe.FindStringSubmatch(m)
+		var v []reflect.Value
+		if sm[2] != "" {
+			fn, found := funcs[sm[1]]
+			if !found {
+				err = fmt.Errorf("function %q not found", sm[1])
+				return ""
+			}
+			v = reflect.ValueOf(fn).Call([]reflect.Value{reflect.ValueOf(sm[2]), reflect.ValueOf(data)})
+		} else if m := reflect.ValueOf(data).MethodByName(sm[1]); m.IsValid() {
+			v = m.Call(nil)
+		} else {
+			v = []reflect.Value{reflect.Indirect(reflect.ValueOf(data)).FieldByName(sm[1])}
+		}
+		if len(v) > 1 && !v[1].IsNil() {
+			err = v[1].Interface().(error)
+		}
+		return fmt.Sprint(v[0].Interface())
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(wr, s)
+	return err
+}
+
+func init() {
+	tpl.RegisterTemplateEngine("placeholder", placeholderEngine{})
+}
+
+func TestTemplateEngine(t *testing.T) {
+	b := newTestSitesBuilder(t).WithSimpleConfigFile()
+
+	b.WithTemplates(
+		"_default/single.html.placeholder", `Single: ${Title}|${partial greeting}|${Content}`,
+		"_default/list.html", `List: {{ .Title }}|{{ partial "greeting" . }}`,
+		"partials/greeting.placeholder", `Hello from ${Title}`,
+		"shortcodes/hello.html.placeholder", `Shortcode: ${Name}`,
+	)
+	b.WithContent("p1.md", `---
+title: P1
+---
+{{< hello >}}
+`)
+
+	b.Build(BuildCfg{})
+
+	b.AssertFileContent("public/p1/index.html", "Single: P1|Hello from P1|Shortcode: hello")
+	b.AssertFileContent("public/index.html", "List: |Hello from ")
+
+	b = newTestSitesBuilder(t).WithSimpleConfigFile()
+	b.WithTemplates("index.html.placeholder", `${Title`)
+	err := b.CreateSitesE()
+	b.Assert(err, qt.Not(qt.IsNil))
+	b.Assert(err.Error(), qt.Contains, "unclosed placeholder")
+}
+
+func TestComponents(t *testing.T) {
+	b := newTestSitesBuilder(t).WithSimpleConfigFile()
+
+	b.WithTemplatesAdded(
+		"partials/components/card.html", "{{ $_hugo_config := `"+`{ "params": { "title": { "type": "string", "required": true }, "level": { "type": "int", "default": 2 } }, "slots": ["footer"] }`+"` }}"+`
+<div class="{{ .Scope }}"><h{{ .Props.level }}>{{ .Props.title }}</h{{ .Props.level }}>{{ .Children }}{{ with .Slot "footer" }}<footer>{{ . }}</footer>{{ end }}</div>`,
+		"partials/components/nav/item.html", `<li class="{{ .Scope }}">{{ .Props.text }}</li>`,
+		"index.html", `
+{{ component "card" (dict "title" "Hello") (safeHTML "<p>Body</p>") }}
+{{ component "card" (dict "title" "Slots" "level" "3") (dict "default" "Default" "footer" (component "nav/item" (dict "text" "Item"))) }}
+{{ with components.CSS }}CSS: {{ .RelPermalink }}|{{ .Content }}{{ end }}
+`,
+	)
+	b.WithSourceFile(
+		"assets/components/card.css", `:scope h2 { color: red; } footer { margin: 0; }`,
+		"assets/components/nav/item.css", `:scope { display: inline; }`,
+	)
+
+	b.Build(BuildCfg{})
+
+	b.AssertFileContent("public/index.html",
+		`<div class="c-card"><h2>Hello</h2><p>Body</p></div>`,
+		`<div class="c-card"><h3>Slots</h3>Default<footer><li class="c-nav__item">Item</li></footer></div>`,
+		"CSS: /components.css|/* card */\n.c-card h2 { color: red; } .c-card footer { margin: 0; }\n/* nav/item */\n.c-nav__item { display: inline; }",
+	)
+
+	for _, test := range []struct {
+		call   string
+		expect string
+	}{
+		{`{{ component "card" (dict "level" 3) }}`, `component "card": missing required prop "title"`},
+		{`{{ component "card" (dict "title" "a" "size" 3) }}`, `component "card": unknown prop "size"`},
+		{`{{ component "card" (dict "title" "a") (dict "header" "a") }}`, `component "card": unknown slot "header"`},
+		{`{{ component "button" nil }}`, `component "button" not found`},
+	} {
+		b := newTestSitesBuilder(t).WithSimpleConfigFile()
+		b.WithTemplatesAdded(
+			"partials/components/card.html", "{{ $_hugo_config := `"+`{ "params": { "title": { "required": true }, "level": {} }, "slots": ["footer"] }`+"` }}{{ .Props.title }}",
+			"index.html", test.call,
+		)
+		err := b.BuildE(BuildCfg{})
+		b.Assert(err, qt.Not(qt.IsNil))
+		b.Assert(err.Error(), qt.Contains, test.expect)
+	}
+}
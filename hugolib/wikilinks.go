@@ -0,0 +1,111 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gohugoio/hugo/common/text"
+	"github.com/gohugoio/hugo/markup/converter"
+	"github.com/gohugoio/hugo/resources/page"
+)
+
+var _ converter.LinkResolver = wikilinkResolver{}
+
+// wikilinkResolver resolves the wikilinks in the content of a page.
+type wikilinkResolver struct {
+	p *pageState
+}
+
+// ResolveWikilink resolves target against the page titles in the site,
+// falling back to a page reference as in ref, e.g. a path or a filename.
+// Targets not found or matching more than one page are logged according to
+// refLinksErrorLevel, and link to refLinksNotFoundURL.
+func (r wikilinkResolver) ResolveWikilink(target string) (string, string) {
+	p := r.p
+	s := p.s
+
+	ref, fragment := target, ""
+	if i := strings.Index(target, "#"); i >= 0 {
+		ref, fragment = target[:i], target[i+1:]
+	}
+
+	if ref == "" {
+		return "#" + fragment, fragment
+	}
+
+	var (
+		linked page.Page
+		err    error
+	)
+
+	candidates := s.wikilinkTitles()[strings.ToLower(ref)]
+	switch len(candidates) {
+	case 0:
+		linked, err = s.getPageRef(p, ref)
+	case 1:
+		linked = candidates[0]
+	default:
+		paths := make([]string, len(candidates))
+		for i, c := range candidates {
+			paths[i] = fmt.Sprintf("%q", c.Path())
+		}
+		err = fmt.Errorf("wikilink is ambiguous, matches %s; use a path to disambiguate", strings.Join(paths, ", "))
+	}
+
+	if err == nil && linked == nil {
+		err = fmt.Errorf("page not found")
+	}
+
+	if err != nil {
+		s.siteRefLinker.logNotFound(target, err.Error(), p, text.Position{})
+		return s.siteRefLinker.notFoundURL, ""
+	}
+
+	link := linked.RelPermalink()
+	if fragment != "" {
+		link = link + "#" + fragment
+		if pctx, ok := linked.(pageContext); ok {
+			if di, ok := pctx.getContentConverter().(converter.DocumentInfo); ok {
+				link = link + di.AnchorSuffix()
+			}
+		}
+	}
+
+	return link, linked.LinkTitle()
+}
+
+// wikilinkTitles returns the regular pages and sections in the site keyed
+// by their lower case title.
+func (s *Site) wikilinkTitles() map[string]page.Pages {
+	v, err := s.init.wikilinkTitles.Do()
+	if err != nil {
+		s.h.FatalError(err)
+		return nil
+	}
+	return v.(map[string]page.Pages)
+}
+
+func (s *Site) assembleWikilinkTitles() (map[string]page.Pages, error) {
+	titles := make(map[string]page.Pages)
+	for _, p := range s.Pages() {
+		if !(p.IsPage() || p.IsSection()) || p.Title() == "" {
+			continue
+		}
+		key := strings.ToLower(p.Title())
+		titles[key] = append(titles[key], p)
+	}
+	return titles, nil
+}
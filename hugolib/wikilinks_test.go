@@ -0,0 +1,88 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"bytes"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/common/loggers"
+	jww "github.com/spf13/jwalterweatherman"
+)
+
+func TestWikilinks(t *testing.T) {
+	config := `
+baseURL = "https://example.org/"
+refLinksErrorLevel = "warning"
+refLinksNotFoundURL = "/404.html"
+[markup.goldmark.extensions]
+wikilinks = true
+`
+
+	content := []string{
+		"docs/_index.md", "---\ntitle: Documentation\n---\n",
+		"docs/getting-started.md", "---\ntitle: Getting Started\nlinkTitle: Start\n---\n## Install\n",
+		"blog/hugo.md", "---\ntitle: Hugo\n---\n",
+		"docs/hugo.md", "---\ntitle: Hugo\n---\n",
+		"post.md", `---
+title: Post
+---
+By title: [[getting started]].
+With label: [[Getting Started|Read this first]].
+With fragment: [[Getting Started#install]].
+By path: [[/blog/hugo.md|the blog post]].
+By filename: [[getting-started]].
+Section: [[Documentation]].
+Same page: [[#top]].
+Ambiguous: [[Hugo]].
+Missing: [[Nowhere]].
+`,
+	}
+
+	t.Run("Default", func(t *testing.T) {
+		var buf bytes.Buffer
+		b := newTestSitesBuilder(t).WithConfigFile("toml", config).WithLogger(loggers.NewBasicLoggerForWriter(jww.LevelWarn, &buf))
+		b.WithContent(content...)
+		b.Build(BuildCfg{})
+
+		b.AssertFileContent("public/post/index.html",
+			`By title: <a href="/docs/getting-started/">Start</a>.`,
+			`With label: <a href="/docs/getting-started/">Read this first</a>.`,
+			`With fragment: <a href="/docs/getting-started/#install">Start</a>.`,
+			`By path: <a href="/blog/hugo/">the blog post</a>.`,
+			`By filename: <a href="/docs/getting-started/">Start</a>.`,
+			`Section: <a href="/docs/">Documentation</a>.`,
+			`Same page: <a href="#top">top</a>.`,
+			`Ambiguous: <a href="/404.html">Hugo</a>.`,
+			`Missing: <a href="/404.html">Nowhere</a>.`,
+		)
+
+		c := qt.New(t)
+		c.Assert(buf.String(), qt.Contains, `REF_NOT_FOUND: Ref "Hugo" from page "post.md": wikilink is ambiguous, matches "blog/hugo.md", "docs/hugo.md"; use a path to disambiguate`)
+		c.Assert(buf.String(), qt.Contains, `REF_NOT_FOUND: Ref "Nowhere" from page "post.md"`)
+	})
+
+	t.Run("Render hook", func(t *testing.T) {
+		b := newTestSitesBuilder(t).WithConfigFile("toml", config)
+		b.WithContent(content...)
+		b.WithTemplatesAdded("_default/_markup/render-link.html", `<a class="hook" href="{{ .Destination }}">{{ .Text | safeHTML }}|{{ .PlainText }}|{{ .Page.Title }}</a>`)
+		b.Build(BuildCfg{})
+
+		b.AssertFileContent("public/post/index.html",
+			`By title: <a class="hook" href="/docs/getting-started/">Start|Start|Post</a>.`,
+			`With label: <a class="hook" href="/docs/getting-started/">Read this first|Read this first|Post</a>.`,
+		)
+	})
+}
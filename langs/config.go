@@ -117,6 +117,14 @@ func LoadLanguageSettings(cfg config.Provider, oldLangs Languages) (c LanguagesC
 		return c, fmt.Errorf("site config value %q for defaultContentLanguage does not match any language definition", defaultLang)
 	}
 
+	for _, l := range languages2 {
+		for _, fallback := range l.GetStringSlice("fallbackLanguages") {
+			if strings.EqualFold(fallback, l.Lang) || !languageExists(languages2, fallback) {
+				return c, fmt.Errorf("invalid fallback language %q for language %q", fallback, l.Lang)
+			}
+		}
+	}
+
 	c.Languages = languages2
 	c.Multihost = languages2.IsMultihost()
 	c.DefaultContentLanguageInSubdir = c.Multihost
@@ -197,6 +205,9 @@ func toSortedLanguages(cfg config.Provider, l map[string]interface{}) (Languages
 				for k, vv := range m {
 					language.SetParam(k, vv)
 				}
+			case "permalinks":
+				// Resolved on top of the global settings.
+				v = mergeStringMap(cfg.GetStringMapString(loki), v)
 			}
 
 			// Put all into the Params map
@@ -214,3 +225,30 @@ func toSortedLanguages(cfg config.Provider, l map[string]interface{}) (Languages
 
 	return languages, nil
 }
+
+func languageExists(languages Languages, lang string) bool {
+	for _, l := range languages {
+		if strings.EqualFold(l.Lang, lang) {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeStringMap returns a copy of base with the entries in v applied. An
+// entry with an empty or false value removes the key from the result.
+func mergeStringMap(base map[string]string, v interface{}) map[string]string {
+	m := make(map[string]string)
+	for k, vv := range base {
+		m[k] = vv
+	}
+	for k, vv := range maps.ToStringMap(v) {
+		k = strings.ToLower(k)
+		if vv == nil || vv == false || cast.ToString(vv) == "" {
+			delete(m, k)
+			continue
+		}
+		m[k] = cast.ToString(vv)
+	}
+	return m
+}
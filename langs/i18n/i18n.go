@@ -26,6 +26,7 @@ import (
 	"github.com/gohugoio/hugo/helpers"
 
 	"github.com/gohugoio/go-i18n/v2/i18n"
+	"golang.org/x/text/language"
 )
 
 type translateFunc func(translationID string, templateData interface{}) string
@@ -41,8 +42,12 @@ type Translator struct {
 
 // NewTranslator creates a new Translator for the given language bundle and configuration.
 func NewTranslator(b *i18n.Bundle, cfg config.Provider, logger loggers.Logger) Translator {
+	return newTranslator(b, nil, cfg, logger)
+}
+
+func newTranslator(b *i18n.Bundle, icu icuBundle, cfg config.Provider, logger loggers.Logger) Translator {
 	t := Translator{cfg: cfg, logger: logger, translateFuncs: make(map[string]translateFunc)}
-	t.initFuncs(b)
+	t.initFuncs(b, icu)
 	return t
 }
 
@@ -63,15 +68,43 @@ func (t Translator) Func(lang string) translateFunc {
 	}
 }
 
-func (t Translator) initFuncs(bndl *i18n.Bundle) {
+func (t Translator) initFuncs(bndl *i18n.Bundle, icu icuBundle) {
 	enableMissingTranslationPlaceholders := t.cfg.GetBool("enableMissingTranslationPlaceholders")
-	for _, lang := range bndl.LanguageTags() {
+
+	langs := bndl.LanguageTags()
+	for lang := range icu {
+		// Languages with ICU messages only.
+		tag := language.Make(lang)
+		if tag == language.Und {
+			tag = language.Make(artificialLangTagPrefix + lang)
+		}
+		found := false
+		for _, l := range langs {
+			if strings.EqualFold(strings.TrimPrefix(l.String(), artificialLangTagPrefix), lang) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			langs = append(langs, tag)
+		}
+	}
+
+	icuFallback := icu[strings.ToLower(defaultBundleLanguage.String())]
+
+	for _, lang := range langs {
 		currentLang := lang
 		currentLangStr := currentLang.String()
 		// This may be pt-BR; make it case insensitive.
 		currentLangKey := strings.ToLower(strings.TrimPrefix(currentLangStr, artificialLangTagPrefix))
 		localizer := i18n.NewLocalizer(bndl, currentLangStr)
+		icuMessages := icu[currentLangKey]
 		t.translateFuncs[currentLangKey] = func(translationID string, templateData interface{}) string {
+			if msg, found := icuMessages[translationID]; found {
+				return msg.Format(currentLang, templateData)
+			}
+			icuTemplateData := templateData
+
 			pluralCount := getPluralCount(templateData)
 
 			if templateData != nil {
@@ -118,6 +151,13 @@ func (t Translator) initFuncs(bndl *i18n.Bundle) {
 				return "[i18n] " + translationID
 			}
 
+			// Fall back to the same language as the go-i18n messages.
+			// ICU messages are checked first, as any go-i18n message with
+			// the same ID and higher precedence has removed it.
+			if msg, found := icuFallback[translationID]; found && !sameLang {
+				return msg.Format(defaultBundleLanguage, icuTemplateData)
+			}
+
 			return translated
 		}
 	}
@@ -328,6 +328,54 @@ one =  "abc"`),
 		expected:     "abc",
 		expectedFlag: "abc",
 	},
+	{
+		name: "icu-plural",
+		data: map[string][]byte{
+			"en.toml": []byte(`[hello]
+other = "Hello"`),
+			"pl.icu.json": []byte(`{"cart": {"items": "{count, plural, one {# produkt} few {# produkty} many {# produktów} other {# produktu}}"}}`),
+		},
+		args:         map[string]interface{}{"Count": 3},
+		lang:         "pl",
+		id:           "cart.items",
+		expected:     "3 produkty",
+		expectedFlag: "3 produkty",
+	},
+	{
+		name: "icu-and-go-i18n",
+		data: map[string][]byte{
+			"en.toml":     []byte(`hello = "Hello"`),
+			"en.icu.yaml": []byte(`files: "{count, plural, one {# file} other {# files}}"`),
+		},
+		args:         1,
+		lang:         "en",
+		id:           "files",
+		expected:     "1 file",
+		expectedFlag: "1 file",
+	},
+	{
+		name: "icu-present-in-default",
+		data: map[string][]byte{
+			"en.icu.toml": []byte(`hello = "Hello, {name}!"`),
+			"es.toml":     []byte("[goodbye]\nother = \"¡Adiós, Mundo!\""),
+		},
+		args:         map[string]interface{}{"name": "Mundo"},
+		lang:         "es",
+		id:           "hello",
+		expected:     "Hello, Mundo!",
+		expectedFlag: "[i18n] hello",
+	},
+	{
+		name: "icu-missing",
+		data: map[string][]byte{
+			"en.icu.toml": []byte(`hello = "Hello, {name}!"`),
+		},
+		args:         nil,
+		lang:         "en",
+		id:           "bye",
+		expected:     "",
+		expectedFlag: "[i18n] bye",
+	},
 }
 
 func TestPlural(t *testing.T) {
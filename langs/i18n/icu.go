@@ -0,0 +1,493 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package i18n
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gohugoio/hugo/common/maps"
+	"github.com/pkg/errors"
+	"github.com/spf13/cast"
+	"golang.org/x/text/feature/plural"
+	"golang.org/x/text/language"
+)
+
+// icuFileSuffix marks a translation file as holding ICU MessageFormat
+// strings, e.g. i18n/en.icu.json.
+const icuFileSuffix = ".icu"
+
+// icuBundle holds the ICU messages per lower case language code and
+// message ID.
+type icuBundle map[string]map[string]icuMessage
+
+func (b icuBundle) add(lang string, m map[string]interface{}) error {
+	if b[lang] == nil {
+		b[lang] = make(map[string]icuMessage)
+	}
+	return b.addPrefixed(lang, "", m)
+}
+
+func (b icuBundle) addPrefixed(lang, prefix string, m map[string]interface{}) error {
+	for k, v := range m {
+		id := prefix + k
+		if _, ok := v.(string); !ok {
+			mm, err := maps.ToStringMapE(v)
+			if err != nil {
+				return errors.Errorf("invalid ICU message %q: must be a string", id)
+			}
+			// Nested messages, e.g. {"cart": {"items": "..."}} gives cart.items.
+			if err := b.addPrefixed(lang, id+".", mm); err != nil {
+				return err
+			}
+			continue
+		}
+		msg, err := parseICUMessage(cast.ToString(v))
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse ICU message %q", id)
+		}
+		b[lang][id] = msg
+	}
+	return nil
+}
+
+// remove removes the messages in lang with the given IDs, e.g. when they
+// are overridden by go-i18n messages in a file with higher precedence.
+func (b icuBundle) remove(lang string, ids ...string) {
+	for _, id := range ids {
+		delete(b[lang], id)
+	}
+}
+
+// icuMessage is a parsed ICU MessageFormat string.
+type icuMessage []icuNode
+
+type icuNode interface {
+	format(b *strings.Builder, c *icuContext)
+}
+
+type icuContext struct {
+	tag  language.Tag
+	args func(name string) (interface{}, bool)
+
+	// The number to print for # in the current plural option.
+	pound string
+}
+
+func (m icuMessage) format(b *strings.Builder, c *icuContext) {
+	for _, n := range m {
+		n.format(b, c)
+	}
+}
+
+// Format formats the message with the given arguments, see icuArgs.
+func (m icuMessage) Format(tag language.Tag, args interface{}) string {
+	var b strings.Builder
+	m.format(&b, &icuContext{tag: tag, args: icuArgs(args)})
+	return b.String()
+}
+
+type icuText string
+
+func (t icuText) format(b *strings.Builder, c *icuContext) {
+	b.WriteString(string(t))
+}
+
+type icuPound struct{}
+
+func (icuPound) format(b *strings.Builder, c *icuContext) {
+	b.WriteString(c.pound)
+}
+
+// icuArg is a simple argument, e.g. {name} or {count, number}.
+type icuArg struct {
+	name string
+}
+
+func (a icuArg) format(b *strings.Builder, c *icuContext) {
+	if v, found := c.args(a.name); found {
+		b.WriteString(cast.ToString(v))
+	}
+}
+
+// icuSelect is a select argument, e.g. {gender, select, female {...} other {...}}.
+type icuSelect struct {
+	name    string
+	options map[string]icuMessage
+}
+
+func (s icuSelect) format(b *strings.Builder, c *icuContext) {
+	v, _ := c.args(s.name)
+	if m, found := s.options[cast.ToString(v)]; found {
+		m.format(b, c)
+		return
+	}
+	s.options["other"].format(b, c)
+}
+
+// icuPlural is a plural or selectordinal argument, e.g.
+// {count, plural, =0 {none} one {# item} other {# items}}.
+type icuPlural struct {
+	name    string
+	ordinal bool
+	offset  float64
+	options map[string]icuMessage
+}
+
+func (p icuPlural) format(b *strings.Builder, c *icuContext) {
+	v, found := c.args(p.name)
+	n, err := cast.ToFloat64E(v)
+	if !found || err != nil {
+		p.options["other"].format(b, c)
+		return
+	}
+
+	pound := c.pound
+	defer func() { c.pound = pound }()
+
+	if m, found := p.options["="+formatICUNumber(n)]; found {
+		c.pound = formatICUNumber(n - p.offset)
+		m.format(b, c)
+		return
+	}
+
+	// Keep any visible fraction digits in a string value, e.g. "1.0".
+	num := cast.ToString(v)
+	if _, ok := v.(string); !ok || p.offset != 0 {
+		num = formatICUNumber(n - p.offset)
+	}
+	c.pound = num
+
+	rules := plural.Cardinal
+	if p.ordinal {
+		rules = plural.Ordinal
+	}
+	i, vv, w, f, t := pluralOperands(num)
+	form := rules.MatchPlural(c.tag, i, vv, w, f, t)
+
+	if m, found := p.options[pluralFormNames[form]]; found {
+		m.format(b, c)
+		return
+	}
+	p.options["other"].format(b, c)
+}
+
+var pluralFormNames = map[plural.Form]string{
+	plural.Other: "other",
+	plural.Zero:  "zero",
+	plural.One:   "one",
+	plural.Two:   "two",
+	plural.Few:   "few",
+	plural.Many:  "many",
+}
+
+func formatICUNumber(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
+
+// pluralOperands returns the CLDR plural operands for the number in s.
+func pluralOperands(s string) (i, v, w, f, t int) {
+	s = strings.TrimPrefix(s, "-")
+	intPart, frac := s, ""
+	if idx := strings.IndexByte(s, '.'); idx != -1 {
+		intPart, frac = s[:idx], s[idx+1:]
+	}
+	// The operands may be passed modulo 10,000,000.
+	const mod = 10000000
+	trim := func(s string) string {
+		if len(s) > 7 {
+			return s[len(s)-7:]
+		}
+		return s
+	}
+	i, _ = strconv.Atoi(trim(intPart))
+	i = i % mod
+	v = len(frac)
+	f, _ = strconv.Atoi(trim(frac))
+	tt := strings.TrimRight(frac, "0")
+	w = len(tt)
+	t, _ = strconv.Atoi(trim(tt))
+	return
+}
+
+// icuArgs returns a func to look up the named arguments in v, a map or a
+// struct. Names are case insensitive. Any other value, e.g. a number, is
+// available as the count argument.
+func icuArgs(v interface{}) func(name string) (interface{}, bool) {
+	if v == nil {
+		return func(name string) (interface{}, bool) { return nil, false }
+	}
+
+	if m, ok := v.(map[string]interface{}); ok {
+		return func(name string) (interface{}, bool) {
+			for k, vv := range m {
+				if strings.EqualFold(k, name) {
+					return vv, true
+				}
+			}
+			return nil, false
+		}
+	}
+
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() == reflect.Interface && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() == reflect.Struct {
+		return func(name string) (interface{}, bool) {
+			match := func(s string) bool { return strings.EqualFold(s, name) }
+			if f := rv.FieldByNameFunc(match); f.IsValid() && f.CanInterface() {
+				return f.Interface(), true
+			}
+			for _, vv := range []reflect.Value{reflect.ValueOf(v), rv} {
+				tp := vv.Type()
+				for i := 0; i < tp.NumMethod(); i++ {
+					m := tp.Method(i)
+					if match(m.Name) && m.Type.NumIn() == 1 && m.Type.NumOut() == 1 {
+						return vv.Method(i).Call(nil)[0].Interface(), true
+					}
+				}
+			}
+			return nil, false
+		}
+	}
+
+	return func(name string) (interface{}, bool) {
+		if strings.EqualFold(name, countFieldName) {
+			return v, true
+		}
+		return nil, false
+	}
+}
+
+// parseICUMessage parses the ICU MessageFormat string s.
+func parseICUMessage(s string) (icuMessage, error) {
+	p := &icuParser{s: s}
+	m, err := p.parseMessage(false)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.s) {
+		return nil, p.errorf("unexpected %q", p.s[p.pos])
+	}
+	return m, nil
+}
+
+type icuParser struct {
+	s   string
+	pos int
+}
+
+func (p *icuParser) errorf(format string, args ...interface{}) error {
+	return errors.Errorf("%s at position %d", fmt.Sprintf(format, args...), p.pos)
+}
+
+// parseMessage parses until the end of input or an unmatched '}'.
+// In a plural option, # is the number.
+func (p *icuParser) parseMessage(inPlural bool) (icuMessage, error) {
+	var (
+		m    icuMessage
+		text strings.Builder
+	)
+
+	flush := func() {
+		if text.Len() > 0 {
+			m = append(m, icuText(text.String()))
+			text.Reset()
+		}
+	}
+
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		switch {
+		case c == '\'':
+			p.pos++
+			if p.pos < len(p.s) && p.s[p.pos] == '\'' {
+				// '' is a literal apostrophe.
+				text.WriteByte('\'')
+				p.pos++
+			} else if p.pos < len(p.s) && (p.s[p.pos] == '{' || p.s[p.pos] == '}' || (inPlural && p.s[p.pos] == '#')) {
+				// Quoted literal text until the next single apostrophe.
+				for p.pos < len(p.s) {
+					if p.s[p.pos] == '\'' {
+						if p.pos+1 < len(p.s) && p.s[p.pos+1] == '\'' {
+							text.WriteByte('\'')
+							p.pos += 2
+							continue
+						}
+						p.pos++
+						break
+					}
+					text.WriteByte(p.s[p.pos])
+					p.pos++
+				}
+			} else {
+				text.WriteByte('\'')
+			}
+		case c == '{':
+			flush()
+			p.pos++
+			n, err := p.parseArgument()
+			if err != nil {
+				return nil, err
+			}
+			m = append(m, n)
+		case c == '}':
+			flush()
+			return m, nil
+		case c == '#' && inPlural:
+			flush()
+			m = append(m, icuPound{})
+			p.pos++
+		default:
+			text.WriteByte(c)
+			p.pos++
+		}
+	}
+
+	flush()
+
+	return m, nil
+}
+
+// parseArgument parses an argument after the opening '{'.
+func (p *icuParser) parseArgument() (icuNode, error) {
+	name := p.parseWord()
+	if name == "" {
+		return nil, p.errorf("missing argument name")
+	}
+
+	p.skipSpace()
+	if p.consume('}') {
+		return icuArg{name: name}, nil
+	}
+	if !p.consume(',') {
+		return nil, p.errorf("expected ',' or '}' after argument %q", name)
+	}
+
+	typ := p.parseWord()
+	p.skipSpace()
+
+	switch typ {
+	case "plural", "selectordinal", "select":
+	default:
+		// A formatted argument, e.g. {n, number}; the style is ignored.
+		depth := 0
+		for p.pos < len(p.s) {
+			switch p.s[p.pos] {
+			case '{':
+				depth++
+			case '}':
+				if depth == 0 {
+					p.pos++
+					return icuArg{name: name}, nil
+				}
+				depth--
+			}
+			p.pos++
+		}
+		return nil, p.errorf("unclosed argument %q", name)
+	}
+
+	if !p.consume(',') {
+		return nil, p.errorf("expected ',' after %s", typ)
+	}
+
+	var offset float64
+	options := make(map[string]icuMessage)
+	isPlural := typ != "select"
+
+	for {
+		p.skipSpace()
+		if p.consume('}') {
+			break
+		}
+
+		key := p.parseWord()
+		if key == "" {
+			return nil, p.errorf("expected option in %s argument %q", typ, name)
+		}
+
+		if isPlural && strings.HasPrefix(key, "offset:") {
+			var err error
+			offset, err = strconv.ParseFloat(strings.TrimPrefix(key, "offset:"), 64)
+			if err != nil {
+				return nil, p.errorf("invalid offset in argument %q", name)
+			}
+			continue
+		}
+
+		if isPlural && strings.HasPrefix(key, "=") {
+			n, err := strconv.ParseFloat(key[1:], 64)
+			if err != nil {
+				return nil, p.errorf("invalid option %q in argument %q", key, name)
+			}
+			key = "=" + formatICUNumber(n)
+		}
+
+		p.skipSpace()
+		if !p.consume('{') {
+			return nil, p.errorf("expected '{' after option %q", key)
+		}
+		m, err := p.parseMessage(isPlural)
+		if err != nil {
+			return nil, err
+		}
+		if !p.consume('}') {
+			return nil, p.errorf("unclosed option %q", key)
+		}
+		options[key] = m
+	}
+
+	if _, found := options["other"]; !found {
+		return nil, p.errorf("missing 'other' option in argument %q", name)
+	}
+
+	if isPlural {
+		return icuPlural{name: name, ordinal: typ == "selectordinal", offset: offset, options: options}, nil
+	}
+
+	return icuSelect{name: name, options: options}, nil
+}
+
+func (p *icuParser) parseWord() string {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.s) {
+		r := rune(p.s[p.pos])
+		if unicode.IsSpace(r) || r == ',' || r == '{' || r == '}' {
+			break
+		}
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+func (p *icuParser) skipSpace() {
+	for p.pos < len(p.s) && unicode.IsSpace(rune(p.s[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *icuParser) consume(c byte) bool {
+	if p.pos < len(p.s) && p.s[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package i18n
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"golang.org/x/text/language"
+)
+
+func TestICUMessageFormat(t *testing.T) {
+	c := qt.New(t)
+
+	items := "{count, plural, =0 {No items} one {# item} other {# items}}"
+
+	for _, test := range []struct {
+		lang     string
+		msg      string
+		args     interface{}
+		expected string
+	}{
+		{"en", "Hello, {name}!", map[string]interface{}{"name": "Hugo"}, "Hello, Hugo!"},
+		{"en", "Hello, {Name}!", struct{ Name string }{"Hugo"}, "Hello, Hugo!"},
+		{"en", "Hello, {name}!", nil, "Hello, !"},
+		{"en", items, 0, "No items"},
+		{"en", items, 1, "1 item"},
+		{"en", items, 5, "5 items"},
+		{"en", items, "1.0", "1.0 items"},
+		{"en", items, 1.5, "1.5 items"},
+		{"en", items, map[string]interface{}{"Count": 1}, "1 item"},
+		{"en", items, nil, " items"},
+		// Polish has the few and many categories.
+		{"pl", "{n, plural, one {# plik} few {# pliki} many {# plików} other {# pliku}}", map[string]interface{}{"n": 3}, "3 pliki"},
+		{"pl", "{n, plural, one {# plik} few {# pliki} many {# plików} other {# pliku}}", map[string]interface{}{"n": 5}, "5 plików"},
+		{"pl", "{n, plural, one {# plik} few {# pliki} many {# plików} other {# pliku}}", map[string]interface{}{"n": 22}, "22 pliki"},
+		{"en", "{n, selectordinal, one {#st} two {#nd} few {#rd} other {#th}}", map[string]interface{}{"n": 22}, "22nd"},
+		{"en", "{n, plural, offset:1 =0 {Nobody} =1 {{name}} one {{name} and # other} other {{name} and # others}}", map[string]interface{}{"n": 3, "name": "Ann"}, "Ann and 2 others"},
+		{"en", "{n, plural, offset:1 =0 {Nobody} =1 {{name}} one {{name} and # other} other {{name} and # others}}", map[string]interface{}{"n": 1, "name": "Ann"}, "Ann"},
+		{"en", "{gender, select, female {She} male {He} other {They}} liked {count, plural, one {# post} other {# posts}}.", map[string]interface{}{"gender": "female", "count": 2}, "She liked 2 posts."},
+		{"en", "{gender, select, female {{count, plural, one {She has # post} other {She has # posts}}} other {They}}", map[string]interface{}{"gender": "female", "count": 1}, "She has 1 post"},
+		{"en", "{gender, select, female {She} other {They}}", map[string]interface{}{"gender": "x"}, "They"},
+		{"en", "{n, number} and {d, date, short}", map[string]interface{}{"n": 3, "d": "today"}, "3 and today"},
+		{"en", "It''s '{literal}' and '#', {n, plural, other {'#' is #}}", map[string]interface{}{"n": 2}, "It's {literal} and '#', # is 2"},
+	} {
+		m, err := parseICUMessage(test.msg)
+		c.Assert(err, qt.IsNil, qt.Commentf(test.msg))
+		c.Assert(m.Format(language.Make(test.lang), test.args), qt.Equals, test.expected, qt.Commentf(test.msg))
+	}
+
+	for _, invalid := range []string{
+		"{",
+		"{}",
+		"{name",
+		"a } b",
+		"{n, plural, one {# item}}",
+		"{n, select, a {b} other {c}",
+		"{n, plural, =x {a} other {b}}",
+	} {
+		_, err := parseICUMessage(invalid)
+		c.Assert(err, qt.Not(qt.IsNil), qt.Commentf(invalid))
+	}
+}
@@ -0,0 +1,363 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package i18n
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gohugoio/hugo/common/maps"
+	"github.com/gohugoio/hugo/common/paths"
+	"github.com/gohugoio/hugo/deps"
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/gohugoio/hugo/hugofs"
+	"github.com/gohugoio/hugo/hugofs/files"
+	"github.com/gohugoio/hugo/parser/metadecoders"
+	"github.com/gohugoio/hugo/source"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"github.com/spf13/cast"
+)
+
+// templateKeyRe matches the i18n calls with a constant key in templates,
+// e.g. {{ i18n "hello" }} or {{ T `hello` . }}.
+var templateKeyRe = regexp.MustCompile("(?:\\bi18n|\\bT|\\blang\\.Translate)\\s+(?:\"([^\"]+)\"|`([^`]+)`)")
+
+// SyncResult holds the i18n keys that are missing from or unused in the
+// translation files of a language.
+type SyncResult struct {
+	Lang string
+
+	// The keys used in the templates, but not defined for the language.
+	Missing []string
+
+	// The keys defined for the language, but not used in any template.
+	Unused []string
+
+	// The file the stubs for the missing keys were written to, if any.
+	Written string
+}
+
+// Sync compares the i18n keys used in the templates with the keys defined in
+// the translation files for each of the given languages.
+//
+// If write is set, stubs for the missing keys are added to the project's
+// translation file for the language, using the text in the default content
+// language if available.
+func Sync(d *deps.Deps, langs []string, write bool) ([]SyncResult, error) {
+	used, err := findTemplateKeys(d)
+	if err != nil {
+		return nil, err
+	}
+
+	defined, err := readMessages(d)
+	if err != nil {
+		return nil, err
+	}
+
+	defaultLang := strings.ToLower(d.Cfg.GetString("defaultContentLanguage"))
+
+	var results []SyncResult
+	for _, lang := range langs {
+		lang = strings.ToLower(lang)
+		r := SyncResult{Lang: lang}
+		keys := defined[lang]
+
+		for key := range used {
+			if _, found := keys[key]; !found {
+				r.Missing = append(r.Missing, key)
+			}
+		}
+		for key := range keys {
+			if !used[key] {
+				r.Unused = append(r.Unused, key)
+			}
+		}
+		sort.Strings(r.Missing)
+		sort.Strings(r.Unused)
+
+		if write && len(r.Missing) > 0 {
+			stubs := make(map[string]string)
+			for _, key := range r.Missing {
+				if s := defined[defaultLang][key].Forms["other"]; s != "" {
+					stubs[key] = s
+				} else {
+					stubs[key] = key
+				}
+			}
+			r.Written, err = writeStubs(d, lang, r.Missing, stubs)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		results = append(results, r)
+	}
+
+	return results, nil
+}
+
+func sourceFiles(d *deps.Deps, dirs []hugofs.FileMetaInfo) ([]source.File, error) {
+	spec := source.NewSourceSpec(d.PathSpec, nil)
+
+	var all []source.File
+	for _, dir := range dirs {
+		files, err := spec.NewFilesystemFromFileMetaInfo(dir).Files()
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, files...)
+	}
+	return all, nil
+}
+
+// findTemplateKeys returns the constant i18n keys used in the templates in
+// the project, its themes and modules.
+func findTemplateKeys(d *deps.Deps) (map[string]bool, error) {
+	files, err := sourceFiles(d, d.BaseFs.Layouts.Dirs)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]bool)
+	for _, file := range files {
+		f, err := file.FileInfo().Meta().Open()
+		if err != nil {
+			return nil, err
+		}
+		b := helpers.ReaderToBytes(f)
+		f.Close()
+
+		for _, m := range templateKeyRe.FindAllSubmatch(b, -1) {
+			key := m[1]
+			if key == nil {
+				key = m[2]
+			}
+			keys[string(key)] = true
+		}
+	}
+
+	return keys, nil
+}
+
+// message is a translation in a translation file.
+type message struct {
+	// The text per plural form, "other" only for messages without plural forms.
+	Forms map[string]string
+
+	// Whether this is an ICU MessageFormat string.
+	ICU bool
+}
+
+func (m message) isPlural() bool {
+	for form := range m.Forms {
+		if form != "other" {
+			return true
+		}
+	}
+	return false
+}
+
+// The plural forms in the go-i18n message format.
+var pluralForms = []string{"zero", "one", "two", "few", "many", "other"}
+
+// readMessages returns the messages defined in the translation files in
+// the project, its themes and modules, per language and key.
+func readMessages(d *deps.Deps) (map[string]map[string]message, error) {
+	files, err := sourceFiles(d, d.BaseFs.I18n.Dirs)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make(map[string]map[string]message)
+	for _, file := range files {
+		f, err := file.FileInfo().Meta().Open()
+		if err != nil {
+			return nil, err
+		}
+		b := helpers.ReaderToBytes(f)
+		f.Close()
+
+		format := metadecoders.FormatFromString(file.Ext())
+		if format == "" {
+			continue
+		}
+		v, err := metadecoders.Default.Unmarshal(b, format)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decode translations file %q", file.Filename())
+		}
+
+		lang := strings.ToLower(paths.Filename(file.LogicalName()))
+		isICU := strings.HasSuffix(lang, icuFileSuffix)
+		lang = strings.TrimSuffix(lang, icuFileSuffix)
+		if messages[lang] == nil {
+			messages[lang] = make(map[string]message)
+		}
+
+		addMessage := func(key string, v interface{}) {
+			if _, found := messages[lang][key]; found {
+				// The most important file comes first.
+				return
+			}
+			msg := message{Forms: make(map[string]string), ICU: isICU}
+			if _, ok := v.(string); ok {
+				msg.Forms["other"] = cast.ToString(v)
+			} else if m, err := maps.ToStringMapE(v); err == nil {
+				for _, form := range pluralForms {
+					if s, found := m[form]; found {
+						msg.Forms[form] = cast.ToString(s)
+					}
+				}
+			} else {
+				msg.Forms["other"] = cast.ToString(v)
+			}
+			messages[lang][key] = msg
+		}
+
+		if m, ok := v.(map[string]interface{}); ok && isICU {
+			// ICU messages may be nested, e.g. cart.items.
+			var addNested func(prefix string, m map[string]interface{})
+			addNested = func(prefix string, m map[string]interface{}) {
+				for key, v := range m {
+					if _, ok := v.(string); !ok {
+						if mm, err := maps.ToStringMapE(v); err == nil {
+							addNested(prefix+key+".", mm)
+							continue
+						}
+					}
+					addMessage(prefix+key, v)
+				}
+			}
+			addNested("", m)
+			continue
+		}
+
+		switch vv := v.(type) {
+		case map[string]interface{}:
+			for key, v := range vv {
+				addMessage(key, v)
+			}
+		case []interface{}:
+			// The old go-i18n format, a list of id/translation entries.
+			for _, e := range vv {
+				m := cast.ToStringMap(e)
+				addMessage(cast.ToString(m["id"]), m["translation"])
+			}
+		}
+	}
+
+	return messages, nil
+}
+
+// writeStubs adds the keys with the given texts to the project's
+// translation file for lang, creating a TOML file if none exists. It returns
+// the filename written to.
+func writeStubs(d *deps.Deps, lang string, keys []string, texts map[string]string) (string, error) {
+	fs := d.Fs.Source
+	dir := filepath.Join(d.Cfg.GetString("workingDir"), files.ComponentFolderI18n)
+
+	filename := filepath.Join(dir, lang+".toml")
+	for _, ext := range []string{".toml", ".yaml", ".yml", ".json"} {
+		if _, err := fs.Stat(filepath.Join(dir, lang+ext)); err == nil {
+			filename = filepath.Join(dir, lang+ext)
+			break
+		}
+	}
+
+	existing, err := afero.ReadFile(fs, filename)
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+
+	var isList bool
+	format := metadecoders.FormatFromString(filename)
+	if len(bytes.TrimSpace(existing)) > 0 {
+		v, err := metadecoders.Default.Unmarshal(existing, format)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to decode translations file %q", filename)
+		}
+		_, isList = v.([]interface{})
+	}
+
+	var buf bytes.Buffer
+	buf.Write(existing)
+	if len(existing) > 0 && !bytes.HasSuffix(existing, []byte("\n")) {
+		buf.WriteString("\n")
+	}
+
+	switch format {
+	case metadecoders.TOML:
+		for _, key := range keys {
+			fmt.Fprintf(&buf, "\n[%q]\nother = %q\n", key, texts[key])
+		}
+	case metadecoders.YAML:
+		for _, key := range keys {
+			if isList {
+				fmt.Fprintf(&buf, "- id: %q\n  translation: %q\n", key, texts[key])
+			} else {
+				fmt.Fprintf(&buf, "%q:\n  other: %q\n", key, texts[key])
+			}
+		}
+	case metadecoders.JSON:
+		b, err := addJSONStubs(existing, isList, keys, texts)
+		if err != nil {
+			return "", err
+		}
+		buf.Reset()
+		buf.Write(b)
+	}
+
+	if err := fs.MkdirAll(dir, 0777); err != nil {
+		return "", err
+	}
+
+	return filename, afero.WriteFile(fs, filename, buf.Bytes(), 0666)
+}
+
+func addJSONStubs(existing []byte, isList bool, keys []string, texts map[string]string) ([]byte, error) {
+	var v interface{}
+	if isList {
+		var list []interface{}
+		if err := json.Unmarshal(existing, &list); err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			list = append(list, map[string]interface{}{"id": key, "translation": texts[key]})
+		}
+		v = list
+	} else {
+		m := make(map[string]interface{})
+		if len(bytes.TrimSpace(existing)) > 0 {
+			if err := json.Unmarshal(existing, &m); err != nil {
+				return nil, err
+			}
+		}
+		for _, key := range keys {
+			m[key] = map[string]interface{}{"other": texts[key]}
+		}
+		v = m
+	}
+
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
@@ -18,6 +18,7 @@ import (
 	"strings"
 
 	"github.com/gohugoio/hugo/common/paths"
+	"github.com/gohugoio/hugo/parser/metadecoders"
 
 	"github.com/gohugoio/hugo/common/herrors"
 	"golang.org/x/text/language"
@@ -48,12 +49,14 @@ func NewTranslationProvider() *TranslationProvider {
 func (tp *TranslationProvider) Update(d *deps.Deps) error {
 	spec := source.NewSourceSpec(d.PathSpec, nil)
 
-	bundle := i18n.NewBundle(language.English)
+	bundle := i18n.NewBundle(defaultBundleLanguage)
 	bundle.RegisterUnmarshalFunc("toml", toml.Unmarshal)
 	bundle.RegisterUnmarshalFunc("yaml", yaml.Unmarshal)
 	bundle.RegisterUnmarshalFunc("yml", yaml.Unmarshal)
 	bundle.RegisterUnmarshalFunc("json", json.Unmarshal)
 
+	icu := make(icuBundle)
+
 	// The source dirs are ordered so the most important comes first. Since this is a
 	// last key win situation, we have to reverse the iteration order.
 	dirs := d.BaseFs.I18n.Dirs
@@ -65,13 +68,13 @@ func (tp *TranslationProvider) Update(d *deps.Deps) error {
 			return err
 		}
 		for _, file := range files {
-			if err := addTranslationFile(bundle, file); err != nil {
+			if err := addTranslationFile(bundle, icu, file); err != nil {
 				return err
 			}
 		}
 	}
 
-	tp.t = NewTranslator(bundle, d.Cfg, d.Log)
+	tp.t = newTranslator(bundle, icu, d.Cfg, d.Log)
 
 	d.Translate = tp.t.Func(d.Language.Lang)
 
@@ -80,7 +83,10 @@ func (tp *TranslationProvider) Update(d *deps.Deps) error {
 
 const artificialLangTagPrefix = "art-x-"
 
-func addTranslationFile(bundle *i18n.Bundle, r source.File) error {
+// defaultBundleLanguage is the language used for missing translations.
+var defaultBundleLanguage = language.English
+
+func addTranslationFile(bundle *i18n.Bundle, icu icuBundle, r source.File) error {
 	f, err := r.FileInfo().Meta().Open()
 	if err != nil {
 		return _errors.Wrapf(err, "failed to open translations file %q:", r.LogicalName())
@@ -91,22 +97,38 @@ func addTranslationFile(bundle *i18n.Bundle, r source.File) error {
 
 	name := r.LogicalName()
 	lang := paths.Filename(name)
+
+	if strings.HasSuffix(lang, icuFileSuffix) {
+		m, err := metadecoders.Default.UnmarshalToMap(b, metadecoders.FormatFromString(r.Ext()))
+		if err == nil {
+			err = icu.add(strings.ToLower(strings.TrimSuffix(lang, icuFileSuffix)), m)
+		}
+		if err != nil {
+			return errWithFileContext(herrors.WithCode(herrors.CodeI18n, _errors.Wrapf(err, "failed to load translations")), r)
+		}
+		return nil
+	}
 	tag := language.Make(lang)
 	if tag == language.Und {
 		name = artificialLangTagPrefix + name
 	}
 
-	_, err = bundle.ParseMessageFileBytes(b, name)
+	mf, err := bundle.ParseMessageFileBytes(b, name)
 	if err != nil {
 		if strings.Contains(err.Error(), "no plural rule") {
 			// https://github.com/gohugoio/hugo/issues/7798
 			name = artificialLangTagPrefix + name
-			_, err = bundle.ParseMessageFileBytes(b, name)
-			if err == nil {
-				return nil
-			}
+			mf, err = bundle.ParseMessageFileBytes(b, name)
+		}
+		if err != nil {
+			return errWithFileContext(herrors.WithCode(herrors.CodeI18n, _errors.Wrapf(err, "failed to load translations")), r)
 		}
-		return errWithFileContext(_errors.Wrapf(err, "failed to load translations"), r)
+	}
+
+	// The files are loaded in order of precedence, the most important last,
+	// so these override any ICU messages with the same IDs loaded earlier.
+	for _, m := range mf.Messages {
+		icu.remove(strings.ToLower(lang), m.ID)
 	}
 
 	return nil
@@ -0,0 +1,351 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package i18n
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gohugoio/hugo/deps"
+	"github.com/gohugoio/hugo/hugofs/files"
+	"github.com/gohugoio/hugo/parser"
+	"github.com/gohugoio/hugo/parser/metadecoders"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"golang.org/x/text/feature/plural"
+	"golang.org/x/text/language"
+)
+
+// XLIFFFileI18n is the ID of the XLIFF file element holding the i18n messages.
+const XLIFFFileI18n = "i18n"
+
+const xliffVersion = "2.0"
+
+// XLIFFDocument is an XLIFF 2.0 document.
+type XLIFFDocument struct {
+	XMLName xml.Name    `xml:"urn:oasis:names:tc:xliff:document:2.0 xliff"`
+	Version string      `xml:"version,attr"`
+	SrcLang string      `xml:"srcLang,attr"`
+	TrgLang string      `xml:"trgLang,attr,omitempty"`
+	Files   []XLIFFFile `xml:"file"`
+}
+
+// XLIFFFile is a file element in an XLIFF document.
+type XLIFFFile struct {
+	ID    string      `xml:"id,attr"`
+	Units []XLIFFUnit `xml:"unit"`
+
+	ids map[string]bool
+}
+
+// XLIFFUnit is a unit to translate.
+type XLIFFUnit struct {
+	ID string `xml:"id,attr"`
+
+	// The key of the unit, set if it is not a valid ID.
+	Name string `xml:"name,attr,omitempty"`
+
+	Segments []XLIFFSegment `xml:"segment"`
+}
+
+// Key returns the i18n key, or other identifier, of the unit.
+func (u XLIFFUnit) Key() string {
+	if u.Name != "" {
+		return u.Name
+	}
+	return u.ID
+}
+
+// XLIFFSegment is a segment of a unit. Plural forms are segments with the
+// form as ID.
+type XLIFFSegment struct {
+	ID     string `xml:"id,attr,omitempty"`
+	Source string `xml:"source"`
+	Target string `xml:"target,omitempty"`
+}
+
+// NewXLIFFDocument creates a new XLIFF document for translations from
+// srcLang to trgLang.
+func NewXLIFFDocument(srcLang, trgLang string) *XLIFFDocument {
+	return &XLIFFDocument{Version: xliffVersion, SrcLang: srcLang, TrgLang: trgLang}
+}
+
+// ReadXLIFF reads an XLIFF 2.0 document from r.
+func ReadXLIFF(r io.Reader) (*XLIFFDocument, error) {
+	var doc XLIFFDocument
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, errors.Wrap(err, "failed to decode XLIFF")
+	}
+	if doc.Version != xliffVersion {
+		return nil, errors.Errorf("unsupported XLIFF version %q, must be %s", doc.Version, xliffVersion)
+	}
+	if doc.TrgLang == "" {
+		return nil, errors.New("XLIFF document has no trgLang")
+	}
+	return &doc, nil
+}
+
+// Write writes the document to w.
+func (d *XLIFFDocument) Write(w io.Writer) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(d); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// File returns the file element with the given ID, or nil if not found.
+func (d *XLIFFDocument) File(id string) *XLIFFFile {
+	for i := range d.Files {
+		if d.Files[i].ID == id {
+			return &d.Files[i]
+		}
+	}
+	return nil
+}
+
+// AddUnit adds a unit for key to the file element with the given ID,
+// creating it if needed.
+func (d *XLIFFDocument) AddUnit(fileID, key string, segments ...XLIFFSegment) {
+	f := d.File(fileID)
+	if f == nil {
+		d.Files = append(d.Files, XLIFFFile{ID: fileID})
+		f = &d.Files[len(d.Files)-1]
+	}
+	if f.ids == nil {
+		f.ids = make(map[string]bool)
+	}
+
+	u := XLIFFUnit{ID: xliffID(key), Segments: segments}
+	if u.ID != key {
+		u.Name = key
+	}
+	for i := 2; f.ids[u.ID]; i++ {
+		u.ID = fmt.Sprintf("%s_%d", xliffID(key), i)
+		u.Name = key
+	}
+	f.ids[u.ID] = true
+
+	f.Units = append(f.Units, u)
+}
+
+// xliffID returns key with the characters not valid in an XML NMTOKEN
+// replaced.
+func xliffID(key string) string {
+	id := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_', r == ':':
+			return r
+		default:
+			return '_'
+		}
+	}, key)
+	if id == "" {
+		return "_"
+	}
+	return id
+}
+
+// ExportXLIFF adds the i18n messages in the document's source language to
+// doc, with any translations to its target language.
+func ExportXLIFF(d *deps.Deps, doc *XLIFFDocument) error {
+	defined, err := readMessages(d)
+	if err != nil {
+		return err
+	}
+
+	src, trg := defined[strings.ToLower(doc.SrcLang)], defined[strings.ToLower(doc.TrgLang)]
+
+	keys := make([]string, 0, len(src))
+	for key := range src {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	trgForms := pluralFormsFor(doc.TrgLang)
+
+	for _, key := range keys {
+		msg := src[key]
+		if !msg.isPlural() {
+			doc.AddUnit(XLIFFFileI18n, key, XLIFFSegment{Source: msg.Forms["other"], Target: trg[key].Forms["other"]})
+			continue
+		}
+
+		var segments []XLIFFSegment
+		for _, form := range trgForms {
+			source, found := msg.Forms[form]
+			if !found {
+				source = msg.Forms["other"]
+			}
+			segments = append(segments, XLIFFSegment{ID: form, Source: source, Target: trg[key].Forms[form]})
+		}
+		doc.AddUnit(XLIFFFileI18n, key, segments...)
+	}
+
+	return nil
+}
+
+// ImportXLIFF writes the translated i18n messages in doc to the project's
+// translation files for the document's target language. It returns the
+// files written.
+func ImportXLIFF(d *deps.Deps, doc *XLIFFDocument) ([]string, error) {
+	f := doc.File(XLIFFFileI18n)
+	if f == nil {
+		return nil, nil
+	}
+
+	defined, err := readMessages(d)
+	if err != nil {
+		return nil, err
+	}
+	src := defined[strings.ToLower(doc.SrcLang)]
+
+	// The ICU messages go into their own file.
+	entries := map[bool]map[string]interface{}{}
+
+	for _, u := range f.Units {
+		var (
+			key   = u.Key()
+			forms = make(map[string]interface{})
+		)
+		for _, s := range u.Segments {
+			if s.Target == "" {
+				continue
+			}
+			form := s.ID
+			if form == "" {
+				form = "other"
+			}
+			forms[form] = s.Target
+		}
+		if len(forms) == 0 {
+			continue
+		}
+
+		isICU := src[key].ICU
+		if entries[isICU] == nil {
+			entries[isICU] = make(map[string]interface{})
+		}
+		if s, found := forms["other"]; found && len(forms) == 1 && len(u.Segments) == 1 {
+			entries[isICU][key] = s
+		} else {
+			entries[isICU][key] = forms
+		}
+	}
+
+	var written []string
+	for _, isICU := range []bool{false, true} {
+		if len(entries[isICU]) == 0 {
+			continue
+		}
+		filename, err := writeMessages(d, strings.ToLower(doc.TrgLang), isICU, entries[isICU])
+		if err != nil {
+			return nil, err
+		}
+		written = append(written, filename)
+	}
+
+	return written, nil
+}
+
+// writeMessages merges entries into the project's translation file for
+// lang, creating a TOML file if none exists.
+func writeMessages(d *deps.Deps, lang string, isICU bool, entries map[string]interface{}) (string, error) {
+	fs := d.Fs.Source
+	dir := filepath.Join(d.Cfg.GetString("workingDir"), files.ComponentFolderI18n)
+
+	base := lang
+	if isICU {
+		base += icuFileSuffix
+	}
+
+	filename := filepath.Join(dir, base+".toml")
+	for _, ext := range []string{".toml", ".yaml", ".yml", ".json"} {
+		if _, err := fs.Stat(filepath.Join(dir, base+ext)); err == nil {
+			filename = filepath.Join(dir, base+ext)
+			break
+		}
+	}
+
+	format := metadecoders.FormatFromString(filename)
+	m := make(map[string]interface{})
+
+	b, err := afero.ReadFile(fs, filename)
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	if len(bytes.TrimSpace(b)) > 0 {
+		v, err := metadecoders.Default.Unmarshal(b, format)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to decode translations file %q", filename)
+		}
+		vm, ok := v.(map[string]interface{})
+		if !ok {
+			return "", errors.Errorf("translations file %q: only the key/value format is supported", filename)
+		}
+		m = vm
+	}
+
+	for k, v := range entries {
+		m[k] = v
+	}
+
+	var buf bytes.Buffer
+	if err := parser.InterfaceToConfig(m, format, &buf); err != nil {
+		return "", err
+	}
+
+	if err := fs.MkdirAll(dir, 0777); err != nil {
+		return "", err
+	}
+
+	return filename, afero.WriteFile(fs, filename, buf.Bytes(), 0666)
+}
+
+// pluralFormsFor returns the cardinal plural forms used in lang, in the
+// CLDR order.
+func pluralFormsFor(lang string) []string {
+	tag := language.Make(lang)
+	used := make(map[plural.Form]bool)
+
+	for i := 0; i <= 1000; i++ {
+		used[plural.Cardinal.MatchPlural(tag, i, 0, 0, 0, 0)] = true
+	}
+	for i := 0; i <= 10; i++ {
+		for f := 1; f <= 9; f++ {
+			used[plural.Cardinal.MatchPlural(tag, i, 1, 1, f, f)] = true
+		}
+	}
+	// Some languages use "many" for large numbers only, e.g. 1000000 in French.
+	used[plural.Cardinal.MatchPlural(tag, 1000000, 0, 0, 0, 0)] = true
+
+	var forms []string
+	for _, form := range []plural.Form{plural.Zero, plural.One, plural.Two, plural.Few, plural.Many, plural.Other} {
+		if used[form] {
+			forms = append(forms, pluralFormNames[form])
+		}
+	}
+	return forms
+}
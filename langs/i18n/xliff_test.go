@@ -0,0 +1,59 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package i18n
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestXLIFFDocument(t *testing.T) {
+	c := qt.New(t)
+
+	doc := NewXLIFFDocument("en", "fr")
+	doc.AddUnit(XLIFFFileI18n, "hello", XLIFFSegment{Source: "Hello", Target: "Bonjour"})
+	doc.AddUnit(XLIFFFileI18n, "a b", XLIFFSegment{Source: "A"})
+	doc.AddUnit(XLIFFFileI18n, "a_b", XLIFFSegment{Source: "B"})
+
+	var buf bytes.Buffer
+	c.Assert(doc.Write(&buf), qt.IsNil)
+
+	doc2, err := ReadXLIFF(&buf)
+	c.Assert(err, qt.IsNil)
+	c.Assert(doc2.SrcLang, qt.Equals, "en")
+	c.Assert(doc2.TrgLang, qt.Equals, "fr")
+	f := doc2.File(XLIFFFileI18n)
+	c.Assert(f, qt.Not(qt.IsNil))
+	c.Assert(f.Units, qt.HasLen, 3)
+	c.Assert(f.Units[0].Key(), qt.Equals, "hello")
+	c.Assert(f.Units[0].Segments[0].Target, qt.Equals, "Bonjour")
+	c.Assert(f.Units[1].ID, qt.Equals, "a_b")
+	c.Assert(f.Units[1].Key(), qt.Equals, "a b")
+	c.Assert(f.Units[2].ID, qt.Equals, "a_b_2")
+	c.Assert(f.Units[2].Key(), qt.Equals, "a_b")
+
+	_, err = ReadXLIFF(strings.NewReader(`<xliff xmlns="urn:oasis:names:tc:xliff:document:2.0" version="1.2" srcLang="en"></xliff>`))
+	c.Assert(err, qt.ErrorMatches, `unsupported XLIFF version "1.2".*`)
+}
+
+func TestPluralFormsFor(t *testing.T) {
+	c := qt.New(t)
+
+	c.Assert(pluralFormsFor("en"), qt.DeepEquals, []string{"one", "other"})
+	c.Assert(pluralFormsFor("ja"), qt.DeepEquals, []string{"other"})
+	c.Assert(pluralFormsFor("pl"), qt.DeepEquals, []string{"one", "few", "many", "other"})
+}
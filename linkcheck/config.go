@@ -0,0 +1,114 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linkcheck
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/gohugoio/hugo/config"
+	"github.com/gohugoio/hugo/config/configschema"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+)
+
+const linkcheckConfigKey = "linkcheck"
+
+// DefaultConfig holds the default link checker configuration.
+var DefaultConfig = Config{
+	Concurrency: 8,
+	PerHost:     2,
+	Timeout:     10 * time.Second,
+}
+
+// Config holds the link checker configuration.
+type Config struct {
+	// The maximum number of external links checked at the same time.
+	Concurrency int
+
+	// The maximum number of links to the same host checked at the same time.
+	PerHost int
+
+	// The timeout for each request.
+	Timeout time.Duration
+
+	// Regular expressions matching links that should not be checked.
+	Ignore []string
+
+	// If set, only external links matching one of these regular
+	// expressions are checked.
+	Allow []string
+}
+
+// DecodeConfig decodes the linkcheck section in the site configuration.
+func DecodeConfig(cfg config.Provider) (Config, error) {
+	c := DefaultConfig
+
+	if !cfg.IsSet(linkcheckConfigKey) {
+		return c, nil
+	}
+
+	dec, err := mapstructure.NewDecoder(
+		&mapstructure.DecoderConfig{
+			WeaklyTypedInput: true,
+			Result:           &c,
+			DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
+		},
+	)
+	if err != nil {
+		return c, err
+	}
+
+	if err := dec.Decode(cfg.GetStringMap(linkcheckConfigKey)); err != nil {
+		return c, errors.Wrap(err, "failed to decode linkcheck config")
+	}
+
+	if c.Concurrency <= 0 || c.PerHost <= 0 {
+		return c, errors.New("linkcheck: concurrency and perHost must be > 0")
+	}
+
+	if _, err := compilePatterns(c.Ignore); err != nil {
+		return c, err
+	}
+	if _, err := compilePatterns(c.Allow); err != nil {
+		return c, err
+	}
+
+	return c, nil
+}
+
+func compilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	var res []*regexp.Regexp
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, errors.Wrapf(err, "linkcheck: invalid pattern %q", p)
+		}
+		res = append(res, re)
+	}
+	return res, nil
+}
+
+func matchesAny(res []*regexp.Regexp, s string) bool {
+	for _, re := range res {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	configschema.AddSection(linkcheckConfigKey, DefaultConfig)
+}
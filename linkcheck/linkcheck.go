@@ -0,0 +1,365 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package linkcheck finds broken links in the rendered site.
+package linkcheck
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gohugoio/hugo/cache/filecache"
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"golang.org/x/net/html"
+)
+
+// The link attributes to check, per element.
+var linkAttrs = map[string]string{
+	"a":      "href",
+	"area":   "href",
+	"link":   "href",
+	"img":    "src",
+	"script": "src",
+	"source": "src",
+	"iframe": "src",
+	"video":  "src",
+	"audio":  "src",
+}
+
+// Checker checks the links in the HTML files in a published site.
+type Checker struct {
+	cfg     Config
+	fs      afero.Fs
+	baseURL *url.URL
+	cache   *filecache.Cache
+
+	ignore []*regexp.Regexp
+	allow  []*regexp.Regexp
+
+	client *http.Client
+}
+
+// New creates a new Checker for the site published to fs with the given base URL.
+// The results of successful external checks are stored in cache, if set.
+func New(cfg Config, fs afero.Fs, baseURL string, cache *filecache.Cache) (*Checker, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "linkcheck: invalid baseURL")
+	}
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+
+	ignore, err := compilePatterns(cfg.Ignore)
+	if err != nil {
+		return nil, err
+	}
+	allow, err := compilePatterns(cfg.Allow)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Checker{
+		cfg:     cfg,
+		fs:      fs,
+		baseURL: u,
+		cache:   cache,
+		ignore:  ignore,
+		allow:   allow,
+		client:  &http.Client{Timeout: cfg.Timeout},
+	}, nil
+}
+
+// Check checks all links in the site, including the external ones if
+// external is set.
+func (c *Checker) Check(ctx context.Context, external bool) (Report, error) {
+	links, err := c.collectLinks()
+	if err != nil {
+		return Report{}, err
+	}
+
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		results   []Result
+		sem       = make(chan struct{}, c.cfg.Concurrency)
+		hostSems  = make(map[string]chan struct{})
+		addResult = func(r Result) {
+			mu.Lock()
+			results = append(results, r)
+			mu.Unlock()
+		}
+	)
+
+	for _, l := range links {
+		if matchesAny(c.ignore, l.u.String()) {
+			continue
+		}
+
+		if !l.external {
+			addResult(c.checkInternal(l))
+			continue
+		}
+
+		if !external || (len(c.allow) > 0 && !matchesAny(c.allow, l.u.String())) {
+			continue
+		}
+
+		hostSem, found := hostSems[l.u.Host]
+		if !found {
+			hostSem = make(chan struct{}, c.cfg.PerHost)
+			hostSems[l.u.Host] = hostSem
+		}
+
+		wg.Add(1)
+		go func(l link) {
+			defer wg.Done()
+			hostSem <- struct{}{}
+			sem <- struct{}{}
+			defer func() {
+				<-sem
+				<-hostSem
+			}()
+			addResult(c.checkExternal(ctx, l))
+		}(l)
+	}
+
+	wg.Wait()
+
+	return newReport(results), nil
+}
+
+type link struct {
+	u        *url.URL
+	external bool
+	pages    []string
+}
+
+// collectLinks collects the unique links, without fragments, in all the
+// HTML files.
+func (c *Checker) collectLinks() ([]link, error) {
+	links := make(map[string]*link)
+
+	err := afero.Walk(c.fs, "", func(filename string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(filename, ".html") {
+			return nil
+		}
+
+		f, err := c.fs.Open(filename)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		page := strings.TrimPrefix(filepath.ToSlash(filename), "/")
+		pageURL := c.baseURL.ResolveReference(&url.URL{Path: page})
+
+		return extractLinks(f, func(s string) {
+			s = strings.TrimSpace(s)
+			if strings.HasPrefix(s, "#") {
+				// A link to the same page.
+				return
+			}
+			u, err := pageURL.Parse(s)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+				return
+			}
+			u.Fragment = ""
+
+			key := u.String()
+			l, found := links[key]
+			if !found {
+				l = &link{u: u, external: u.Host != c.baseURL.Host || !strings.HasPrefix(u.Path, c.baseURL.Path)}
+				links[key] = l
+			}
+			if len(l.pages) == 0 || l.pages[len(l.pages)-1] != page {
+				l.pages = append(l.pages, page)
+			}
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var res []link
+	for _, l := range links {
+		res = append(res, *l)
+	}
+
+	return res, nil
+}
+
+func extractLinks(r io.Reader, handle func(s string)) error {
+	z := html.NewTokenizer(r)
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			if z.Err() == io.EOF {
+				return nil
+			}
+			return z.Err()
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			attr, found := linkAttrs[string(name)]
+			if !found {
+				continue
+			}
+			for hasAttr {
+				var k, v []byte
+				k, v, hasAttr = z.TagAttr()
+				if string(k) == attr && len(v) > 0 {
+					handle(string(v))
+				}
+			}
+		}
+	}
+}
+
+// checkInternal checks that the link points to a file in the published site.
+func (c *Checker) checkInternal(l link) Result {
+	r := Result{URL: l.u.String(), Pages: l.pages}
+
+	p := strings.TrimPrefix(l.u.Path, c.baseURL.Path)
+	var candidates []string
+	if p == "" || strings.HasSuffix(p, "/") {
+		candidates = []string{path.Join(p, "index.html")}
+	} else {
+		candidates = []string{p, path.Join(p, "index.html")}
+	}
+
+	for _, candidate := range candidates {
+		if fi, err := c.fs.Stat(filepath.FromSlash(candidate)); err == nil && !fi.IsDir() {
+			return r
+		}
+	}
+
+	r.Error = "not found"
+
+	return r
+}
+
+// checkExternal checks the link with a HEAD request, falling back to GET if
+// that fails, as some servers do not support HEAD.
+func (c *Checker) checkExternal(ctx context.Context, l link) Result {
+	r := Result{URL: l.u.String(), External: true, Pages: l.pages}
+
+	check := func() ([]byte, error) {
+		status, err := c.request(ctx, "HEAD", l.u)
+		if err != nil || status >= 400 {
+			status, err = c.request(ctx, "GET", l.u)
+		}
+		r.Status = status
+		if err != nil {
+			return nil, err
+		}
+		if status >= 400 {
+			return nil, errors.Errorf("%d %s", status, http.StatusText(status))
+		}
+		return json.Marshal(status)
+	}
+
+	var (
+		b   []byte
+		err error
+	)
+
+	if c.cache != nil {
+		_, b, err = c.cache.GetOrCreateBytes(helpers.MD5String(r.URL), check)
+	} else {
+		b, err = check()
+	}
+
+	if err != nil {
+		r.Error = err.Error()
+		return r
+	}
+
+	if r.Status == 0 {
+		r.Cached = true
+		json.Unmarshal(b, &r.Status)
+	}
+
+	return r
+}
+
+func (c *Checker) request(ctx context.Context, method string, u *url.URL) (int, error) {
+	req, err := http.NewRequest(method, u.String(), nil)
+	if err != nil {
+		return 0, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("User-Agent", "Hugo Static Site Generator")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 1<<20))
+
+	return resp.StatusCode, nil
+}
+
+// Result is the result of checking a link.
+type Result struct {
+	URL      string `json:"url"`
+	External bool   `json:"external"`
+
+	// The HTTP status code, for external links.
+	Status int `json:"status,omitempty"`
+
+	// Set if the link is broken.
+	Error string `json:"error,omitempty"`
+
+	// Whether the result was fetched from the cache of a previous run.
+	Cached bool `json:"cached,omitempty"`
+
+	// The published files containing the link.
+	Pages []string `json:"pages"`
+}
+
+// OK returns whether the link is valid.
+func (r Result) OK() bool {
+	return r.Error == ""
+}
+
+func newReport(results []Result) Report {
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].URL < results[j].URL
+	})
+
+	report := Report{Checked: len(results), Results: results}
+	for _, r := range results {
+		if !r.OK() {
+			report.Broken++
+		}
+	}
+
+	return report
+}
@@ -0,0 +1,197 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linkcheck
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/cache/filecache"
+	"github.com/gohugoio/hugo/config"
+	"github.com/spf13/afero"
+)
+
+func TestDecodeConfig(t *testing.T) {
+	c := qt.New(t)
+
+	lc, err := DecodeConfig(config.New())
+	c.Assert(err, qt.IsNil)
+	c.Assert(lc, qt.DeepEquals, DefaultConfig)
+
+	cfg, err := config.FromConfigString(`
+[linkcheck]
+concurrency = 4
+timeout = "3s"
+ignore = ["linkedin\\.com"]
+`, "toml")
+	c.Assert(err, qt.IsNil)
+	lc, err = DecodeConfig(cfg)
+	c.Assert(err, qt.IsNil)
+	c.Assert(lc.Concurrency, qt.Equals, 4)
+	c.Assert(lc.PerHost, qt.Equals, 2)
+	c.Assert(lc.Timeout, qt.Equals, 3*time.Second)
+	c.Assert(lc.Ignore, qt.DeepEquals, []string{`linkedin\.com`})
+
+	for _, invalid := range []string{
+		`[linkcheck]
+concurrency = 0`,
+		`[linkcheck]
+ignore = ["("]`,
+	} {
+		cfg, err := config.FromConfigString(invalid, "toml")
+		c.Assert(err, qt.IsNil)
+		_, err = DecodeConfig(cfg)
+		c.Assert(err, qt.Not(qt.IsNil))
+	}
+}
+
+func TestCheck(t *testing.T) {
+	c := qt.New(t)
+
+	var heads, gets int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "HEAD" {
+			atomic.AddInt32(&heads, 1)
+		} else {
+			atomic.AddInt32(&gets, 1)
+		}
+		switch r.URL.Path {
+		case "/ok":
+		case "/nohead":
+			if r.Method == "HEAD" {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	fs := afero.NewMemMapFs()
+	for filename, content := range map[string]string{
+		"index.html": `<html><body>
+<a href="/docs/">Docs</a>
+<a href="docs/page/#foo">Page</a>
+<a href="https://example.org/docs/missing/">Missing</a>
+<a href="/style.css">Style</a>
+<a href="mailto:jane@example.org">Mail</a>
+<a href="#top">Top</a>
+<img src="` + srv.URL + `/ok">
+<a href="` + srv.URL + `/nohead">No HEAD</a>
+<a href="` + srv.URL + `/broken">Broken</a>
+<a href="` + srv.URL + `/ignored">Ignored</a>
+</body></html>`,
+		"docs/index.html":      `<a href="../">Home</a><a href="page">Page</a>`,
+		"docs/page/index.html": `<a href="` + srv.URL + `/ok">OK</a>`,
+		"style.css":            `body { background: url("/missing.png") }`,
+	} {
+		c.Assert(afero.WriteFile(fs, filename, []byte(content), 0666), qt.IsNil)
+	}
+
+	cfg := DefaultConfig
+	cfg.Ignore = []string{"/ignored$"}
+	cache := filecache.NewCache(afero.NewMemMapFs(), time.Hour, "")
+
+	checker, err := New(cfg, fs, "https://example.org", cache)
+	c.Assert(err, qt.IsNil)
+
+	c.Run("Internal", func(c *qt.C) {
+		report, err := checker.Check(context.Background(), false)
+		c.Assert(err, qt.IsNil)
+		c.Assert(report.Checked, qt.Equals, 6)
+		c.Assert(report.Broken, qt.Equals, 1)
+		c.Assert(report.Results[1].URL, qt.Equals, "https://example.org/docs/")
+		c.Assert(report.Results[1].Pages, qt.DeepEquals, []string{"index.html"})
+		c.Assert(report.Results[2].URL, qt.Equals, "https://example.org/docs/missing/")
+		c.Assert(report.Results[2].Error, qt.Equals, "not found")
+		c.Assert(report.Results[3].URL, qt.Equals, "https://example.org/docs/page")
+		c.Assert(report.Results[3].OK(), qt.IsTrue)
+		c.Assert(atomic.LoadInt32(&heads), qt.Equals, int32(0))
+	})
+
+	c.Run("External", func(c *qt.C) {
+		report, err := checker.Check(context.Background(), true)
+		c.Assert(err, qt.IsNil)
+		c.Assert(report.Checked, qt.Equals, 9)
+		c.Assert(report.Broken, qt.Equals, 2)
+
+		byURL := make(map[string]Result)
+		for _, r := range report.Results {
+			byURL[r.URL] = r
+		}
+		c.Assert(byURL[srv.URL+"/ok"].OK(), qt.IsTrue)
+		c.Assert(byURL[srv.URL+"/ok"].Pages, qt.DeepEquals, []string{"docs/page/index.html", "index.html"})
+		c.Assert(byURL[srv.URL+"/nohead"].OK(), qt.IsTrue)
+		c.Assert(byURL[srv.URL+"/broken"].Error, qt.Equals, "404 Not Found")
+		c.Assert(byURL[srv.URL+"/broken"].Status, qt.Equals, 404)
+
+		// Only the valid links are cached.
+		heads, gets = 0, 0
+		report, err = checker.Check(context.Background(), true)
+		c.Assert(err, qt.IsNil)
+		for _, r := range report.Results {
+			byURL[r.URL] = r
+		}
+		c.Assert(byURL[srv.URL+"/ok"].Cached, qt.IsTrue)
+		c.Assert(byURL[srv.URL+"/ok"].Status, qt.Equals, 200)
+		c.Assert(byURL[srv.URL+"/broken"].Cached, qt.IsFalse)
+		c.Assert(atomic.LoadInt32(&heads), qt.Equals, int32(1))
+	})
+
+	c.Run("Allow", func(c *qt.C) {
+		cfg := DefaultConfig
+		cfg.Allow = []string{"/ok$"}
+		checker, err := New(cfg, fs, "https://example.org/", nil)
+		c.Assert(err, qt.IsNil)
+		report, err := checker.Check(context.Background(), true)
+		c.Assert(err, qt.IsNil)
+		c.Assert(report.Checked, qt.Equals, 7)
+	})
+}
+
+func TestReport(t *testing.T) {
+	c := qt.New(t)
+
+	report := newReport([]Result{
+		{URL: "https://example.org/b", External: true, Status: 404, Error: "404 Not Found", Pages: []string{"index.html", "about/index.html"}},
+		{URL: "https://example.org/a", Pages: []string{"index.html"}},
+	})
+
+	var buf bytes.Buffer
+	c.Assert(report.Write(&buf, "text"), qt.IsNil)
+	c.Assert(buf.String(), qt.Equals, "https://example.org/b: 404 Not Found\n\tlinked from index.html, about/index.html\nChecked 2 links, 1 broken\n")
+
+	buf.Reset()
+	c.Assert(report.Write(&buf, "json"), qt.IsNil)
+	var decoded Report
+	c.Assert(json.Unmarshal(buf.Bytes(), &decoded), qt.IsNil)
+	c.Assert(decoded.Broken, qt.Equals, 1)
+	c.Assert(decoded.Results[0].URL, qt.Equals, "https://example.org/a")
+
+	buf.Reset()
+	c.Assert(report.Write(&buf, "junit"), qt.IsNil)
+	c.Assert(buf.String(), qt.Contains, `<testsuite name="links" tests="2" failures="1">`)
+	c.Assert(buf.String(), qt.Contains, `<testcase classname="external" name="https://example.org/b">
+      <failure message="404 Not Found">Linked from index.html, about/index.html</failure>`)
+	c.Assert(buf.String(), qt.Contains, `<testcase classname="internal" name="https://example.org/a"></testcase>`)
+
+	c.Assert(report.Write(&buf, "xml"), qt.ErrorMatches, `.*invalid report format "xml".*`)
+}
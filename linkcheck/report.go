@@ -0,0 +1,119 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linkcheck
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// The report formats.
+const (
+	FormatText  = "text"
+	FormatJSON  = "json"
+	FormatJUnit = "junit"
+)
+
+// Report holds the results of a link check.
+type Report struct {
+	Checked int      `json:"checked"`
+	Broken  int      `json:"broken"`
+	Results []Result `json:"results"`
+}
+
+// Write writes the report to w in the given format, one of text, json or junit.
+// The text format only lists the broken links.
+func (r Report) Write(w io.Writer, format string) error {
+	switch strings.ToLower(format) {
+	case FormatText, "":
+		return r.writeText(w)
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	case FormatJUnit:
+		return r.writeJUnit(w)
+	default:
+		return errors.Errorf("linkcheck: invalid report format %q, must be one of text, json or junit", format)
+	}
+}
+
+func (r Report) writeText(w io.Writer) error {
+	for _, res := range r.Results {
+		if res.OK() {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "%s: %s\n\tlinked from %s\n", res.URL, res.Error, strings.Join(res.Pages, ", ")); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "Checked %d links, %d broken\n", r.Checked, r.Broken)
+	return err
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message  string `xml:"message,attr"`
+	Contents string `xml:",chardata"`
+}
+
+func (r Report) writeJUnit(w io.Writer) error {
+	suite := junitTestSuite{Name: "links", Tests: r.Checked, Failures: r.Broken}
+	for _, res := range r.Results {
+		tc := junitTestCase{ClassName: "internal", Name: res.URL}
+		if res.External {
+			tc.ClassName = "external"
+		}
+		if !res.OK() {
+			tc.Failure = &junitFailure{
+				Message:  res.Error,
+				Contents: "Linked from " + strings.Join(res.Pages, ", "),
+			}
+		}
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(junitTestSuites{Suites: []junitTestSuite{suite}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
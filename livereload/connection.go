@@ -15,6 +15,8 @@ package livereload
 
 import (
 	"bytes"
+	"encoding/json"
+	"net/url"
 	"sync"
 
 	"github.com/gorilla/websocket"
@@ -30,6 +32,47 @@ type connection struct {
 	// There is a potential data race, especially visible with large files.
 	// This is protected by synchronisation of the send channel's close.
 	closer sync.Once
+
+	// The URL of the page the browser is viewing, as reported by the
+	// livereload client. Nil if not known.
+	mu  sync.Mutex
+	url *url.URL
+}
+
+// viewing reports whether the browser is viewing one of the pages with
+// the given paths. It returns true if the page is not known.
+func (c *connection) viewing(paths []string) bool {
+	c.mu.Lock()
+	u := c.url
+	c.mu.Unlock()
+
+	if u == nil {
+		return true
+	}
+
+	current := normalizePagePath(u.Path)
+	for _, path := range paths {
+		if normalizePagePath(path) == current {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *connection) setURL(message []byte) {
+	var info struct {
+		URL string `json:"url"`
+	}
+	if err := json.Unmarshal(message, &info); err != nil || info.URL == "" {
+		return
+	}
+	u, err := url.Parse(info.URL)
+	if err != nil {
+		return
+	}
+	c.mu.Lock()
+	c.url = u
+	c.mu.Unlock()
 }
 
 func (c *connection) close() {
@@ -50,6 +93,8 @@ func (c *connection) reader() {
 				"protocols": [ "http://livereload.com/protocols/official-7" ],
 				"serverName": "Hugo"
 			}`)
+		} else if bytes.Contains(message, []byte(`"command":"info"`)) {
+			c.setURL(message)
 		}
 	}
 	c.ws.Close()
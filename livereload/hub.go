@@ -13,12 +13,19 @@
 
 package livereload
 
+// message is a message to the connections, sent to all of them or, if
+// paths is set, to the ones viewing one of those pages.
+type message struct {
+	b     []byte
+	paths []string
+}
+
 type hub struct {
 	// Registered connections.
 	connections map[*connection]bool
 
 	// Inbound messages from the connections.
-	broadcast chan []byte
+	broadcast chan message
 
 	// Register requests from the connections.
 	register chan *connection
@@ -28,7 +35,7 @@ type hub struct {
 }
 
 var wsHub = hub{
-	broadcast:   make(chan []byte),
+	broadcast:   make(chan message),
 	register:    make(chan *connection),
 	unregister:  make(chan *connection),
 	connections: make(map[*connection]bool),
@@ -44,8 +51,11 @@ func (h *hub) run() {
 			c.close()
 		case m := <-h.broadcast:
 			for c := range h.connections {
+				if m.paths != nil && !c.viewing(m.paths) {
+					continue
+				}
 				select {
-				case c.send <- m:
+				case c.send <- m.b:
 				default:
 					delete(h.connections, c)
 					c.close()
@@ -37,11 +37,13 @@
 package livereload
 
 import (
+	"encoding/json"
 	"fmt"
 	"net"
 	"net/http"
 	"net/url"
 	"path/filepath"
+	"strings"
 
 	"github.com/gorilla/websocket"
 )
@@ -49,6 +51,14 @@ import (
 // Prefix to signal to LiveReload that we need to navigate to another path.
 const hugoNavigatePrefix = "__hugo_navigate"
 
+// Prefix to signal to LiveReload that we need to navigate to one of several
+// paths, see Navigation.
+const hugoNavigateSelectPrefix = hugoNavigatePrefix + "_select"
+
+// Prefix to signal to LiveReload that we need to swap stylesheets and
+// scripts in place, see SwapAssets.
+const hugoSwapPrefix = "__hugo_swap"
+
 var upgrader = &websocket.Upgrader{
 	// Hugo may potentially spin up multiple HTTP servers, so we need to exclude the
 	// port when checking the origin.
@@ -116,6 +126,39 @@ func NavigateToPathForPort(path string, port int) {
 	refreshPathForPort(hugoNavigatePrefix+path, port)
 }
 
+// NavigationTarget is a page to navigate to.
+type NavigationTarget struct {
+	Path string `json:"path"`
+
+	// The server port of the page, if any.
+	Port int `json:"port,omitempty"`
+}
+
+// Navigation describes how to navigate after a change to one or more pages.
+type Navigation struct {
+	// The pages to navigate to, in order of preference. If the current page is
+	// one of them, it is reloaded.
+	Targets []NavigationTarget `json:"targets"`
+
+	// The paths of all the pages affected by the change.
+	Affected []string `json:"affected,omitempty"`
+
+	// If set, the browser only navigates away from the current page if its path
+	// is in Affected, else it is reloaded.
+	OnlyIfAffected bool `json:"onlyIfAffected,omitempty"`
+}
+
+// Navigate tells livereload to navigate to one of the targets in n, or to
+// reload the current page, as described in Navigation.
+func Navigate(n Navigation) {
+	b, err := json.Marshal(n)
+	if err != nil || len(n.Targets) == 0 {
+		ForceRefresh()
+		return
+	}
+	RefreshPath(hugoNavigateSelectPrefix + string(b))
+}
+
 // RefreshPath tells livereload to refresh only the given path.
 // If that path points to a CSS stylesheet or an image, only the changes
 // will be updated in the browser, not the entire page.
@@ -123,15 +166,53 @@ func RefreshPath(s string) {
 	refreshPathForPort(s, -1)
 }
 
+// RefreshPages tells livereload to do a hard refresh in the browsers viewing
+// one of the pages with the given paths only.
+func RefreshPages(paths []string) {
+	if paths == nil {
+		paths = []string{}
+	}
+	wsHub.broadcast <- message{b: reloadMessage("/x.js", -1), paths: paths}
+}
+
+// Asset is a stylesheet or script changed in a rebuild.
+type Asset struct {
+	// The path of the asset.
+	Path string `json:"path"`
+
+	// The path of the asset it replaces, if different from Path, e.g. because
+	// the asset is fingerprinted.
+	OldPath string `json:"oldPath,omitempty"`
+}
+
+// SwapAssets tells livereload to replace the given stylesheets and scripts in
+// the pages using them without reloading the pages.
+func SwapAssets(assets []Asset) {
+	b, err := json.Marshal(assets)
+	if err != nil {
+		ForceRefresh()
+		return
+	}
+	RefreshPath(hugoSwapPrefix + string(b))
+}
+
 func refreshPathForPort(s string, port int) {
+	wsHub.broadcast <- message{b: reloadMessage(s, port)}
+}
+
+func reloadMessage(s string, port int) []byte {
 	// Tell livereload a file has changed - will force a hard refresh if not CSS or an image
 	urlPath := filepath.ToSlash(s)
 	portStr := ""
 	if port > 0 {
 		portStr = fmt.Sprintf(`, "overrideURL": %d`, port)
 	}
-	msg := fmt.Sprintf(`{"command":"reload","path":%q,"originalPath":"","liveCSS":true,"liveImg":true%s}`, urlPath, portStr)
-	wsHub.broadcast <- []byte(msg)
+	return []byte(fmt.Sprintf(`{"command":"reload","path":%q,"originalPath":"","liveCSS":true,"liveImg":true%s}`, urlPath, portStr))
+}
+
+// normalizePagePath returns path with any trailing index.html removed.
+func normalizePagePath(path string) string {
+	return strings.TrimSuffix(path, "index.html")
 }
 
 // ServeJS serves the liverreload.js who's reference is injected into the page.
@@ -140,6 +221,20 @@ func ServeJS(w http.ResponseWriter, r *http.Request) {
 	w.Write(liveReloadJS())
 }
 
+// ServeJSForPagePort is like ServeJS, but makes the client connect to the
+// port the page was loaded from rather than the port in the script URL. This
+// is used when the pages are served both directly and through a tunnel.
+func ServeJSForPagePort(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/javascript")
+	w.Write([]byte(pagePortJS))
+	w.Write(liveReloadJS())
+}
+
+// pagePortJS replaces the port in the URL of the livereload script, which
+// LiveReload reads its options from, with the port of the page.
+const pagePortJS = `(function(){var p=location.port||(location.protocol==="https:"?"443":"80");Array.prototype.forEach.call(document.getElementsByTagName("script"),function(s){if(/\/livereload\.js\?/.test(s.src)){s.src=s.src.replace(/([?&]port=)\d*/,"$1"+p);}});})();
+`
+
 func liveReloadJS() []byte {
 	return []byte(livereloadJS + hugoLiveReloadPlugin)
 }
@@ -150,7 +245,7 @@ var (
 	hugoLiveReloadPlugin = fmt.Sprintf(`
 /*
 Hugo adds a specific prefix, "__hugo_navigate", to the path in certain situations to signal
-navigation to another content page.
+navigation to another content page, or "__hugo_swap" to swap stylesheets and scripts in place.
 */
 
 function HugoReload() {}
@@ -160,20 +255,51 @@ HugoReload.version = '0.9';
 
 HugoReload.prototype.reload = function(path, options) {
 	var prefix = %q;
+	var selectPrefix = %q;
+	var swapPrefix = %q;
+
+	if (path.lastIndexOf(swapPrefix, 0) === 0) {
+		this.swap(JSON.parse(path.substring(swapPrefix.length)));
+		return true;
+	}
 
 	if (path.lastIndexOf(prefix, 0) !== 0) {
 		return false
 	}
-	
-	path = path.substring(prefix.length);
 
-	var portChanged = options.overrideURL && options.overrideURL != window.location.port
+	var target;
+
+	if (path.lastIndexOf(selectPrefix, 0) === 0) {
+		var nav = JSON.parse(path.substring(selectPrefix.length));
+		var isCurrent = function(t) {
+			return t.path === window.location.pathname && (!t.port || t.port == window.location.port);
+		};
+
+		if (nav.targets.some(isCurrent)) {
+			// Already on one of the changed pages, e.g. in the right language.
+			window.location.reload();
+			return true;
+		}
+
+		if (nav.onlyIfAffected && (nav.affected || []).indexOf(window.location.pathname) === -1) {
+			window.location.reload();
+			return true;
+		}
+
+		target = nav.targets[0];
+	} else {
+		target = { path: path.substring(prefix.length), port: options.overrideURL };
+	}
+
+	path = target.path;
+
+	var portChanged = target.port && target.port != window.location.port
 	
 	if (!portChanged && window.location.pathname === path) {
 		window.location.reload();
 	} else {
 		if (portChanged) {
-			window.location = location.protocol + "//" + location.hostname + ":" + options.overrideURL + path;
+			window.location = location.protocol + "//" + location.hostname + ":" + target.port + path;
 		} else {
 			window.location.pathname = path;
 		}
@@ -182,6 +308,49 @@ HugoReload.prototype.reload = function(path, options) {
 	return true;
 };
 
+// swap replaces the stylesheets and scripts matching the given assets,
+// keeping the scroll position and form state of the page.
+HugoReload.prototype.swap = function(assets) {
+	var pathOf = function(href) {
+		return new URL(href, window.location.href).pathname;
+	};
+	var bust = function(path) {
+		return path + (path.indexOf('?') === -1 ? '?' : '&') + 'livereload=' + Date.now();
+	};
+
+	assets.forEach(function(asset) {
+		var from = asset.oldPath || asset.path;
+
+		document.querySelectorAll('link[rel~="stylesheet"][href]').forEach(function(el) {
+			if (pathOf(el.href) !== from) {
+				return;
+			}
+			var next = el.cloneNode();
+			next.removeAttribute('integrity');
+			next.href = bust(asset.path);
+			next.onload = next.onerror = function() {
+				el.remove();
+			};
+			el.parentNode.insertBefore(next, el.nextSibling);
+		});
+
+		document.querySelectorAll('script[src]').forEach(function(el) {
+			if (pathOf(el.src) !== from) {
+				return;
+			}
+			var next = document.createElement('script');
+			for (var i = 0; i < el.attributes.length; i++) {
+				var attr = el.attributes[i];
+				if (attr.name !== 'src' && attr.name !== 'integrity') {
+					next.setAttribute(attr.name, attr.value);
+				}
+			}
+			next.src = bust(asset.path);
+			el.parentNode.replaceChild(next, el);
+		});
+	});
+};
+
 LiveReload.addPlugin(HugoReload)
-`, hugoNavigatePrefix)
+`, hugoNavigatePrefix, hugoNavigateSelectPrefix, hugoSwapPrefix)
 )
@@ -121,6 +121,15 @@ type DocumentContext struct {
 	DocumentName    string
 	Filename        string
 	ConfigOverrides map[string]interface{}
+	LinkResolver    LinkResolver // May be nil.
+}
+
+// LinkResolver resolves the targets of wiki-style links in a document,
+// e.g. [[Page Title]] or [[path/to/page]].
+type LinkResolver interface {
+	// ResolveWikilink returns the destination of target, a page title or
+	// path with an optional fragment, and the title of the page it links to.
+	ResolveWikilink(target string) (destination, title string)
 }
 
 // RenderContext holds contextual information about the content to render.
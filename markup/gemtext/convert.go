@@ -0,0 +1,398 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gemtext converts Markdown to Gemini's text format, gemtext.
+package gemtext
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gohugoio/hugo/identity"
+	"github.com/gohugoio/hugo/markup/converter"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// Provider is the package entry point.
+var Provider converter.ProviderProvider = provide{}
+
+type provide struct {
+}
+
+func (p provide) New(cfg converter.ProviderConfig) (converter.Provider, error) {
+	md := newMarkdown(cfg)
+
+	return converter.NewProvider("gemtext", func(ctx converter.DocumentContext) (converter.Converter, error) {
+		return &gemtextConverter{
+			ctx:                 ctx,
+			md:                  md,
+			skipBlockAttributes: cfg.MarkupConfig.Goldmark.Parser.Attribute.Block,
+		}, nil
+	}), nil
+}
+
+type gemtextConverter struct {
+	md  goldmark.Markdown
+	ctx converter.DocumentContext
+
+	// Block attributes, e.g. {.class}, are not supported by the parser used
+	// here, so leave them out when they are enabled for Goldmark.
+	skipBlockAttributes bool
+}
+
+func newMarkdown(pcfg converter.ProviderConfig) goldmark.Markdown {
+	cfg := pcfg.MarkupConfig.Goldmark
+
+	var (
+		extensions    []goldmark.Extender
+		parserOptions []parser.Option
+	)
+
+	if cfg.Extensions.Table {
+		extensions = append(extensions, extension.Table)
+	}
+
+	if cfg.Extensions.Strikethrough {
+		extensions = append(extensions, extension.Strikethrough)
+	}
+
+	if cfg.Extensions.Linkify {
+		extensions = append(extensions, extension.Linkify)
+	}
+
+	if cfg.Extensions.TaskList {
+		extensions = append(extensions, extension.TaskList)
+	}
+
+	if cfg.Extensions.Typographer {
+		extensions = append(extensions, extension.Typographer)
+	}
+
+	if cfg.Extensions.DefinitionList {
+		extensions = append(extensions, extension.DefinitionList)
+	}
+
+	if cfg.Extensions.Footnote {
+		extensions = append(extensions, extension.Footnote)
+	}
+
+	if cfg.Parser.Attribute.Title {
+		parserOptions = append(parserOptions, parser.WithAttribute())
+	}
+
+	return goldmark.New(
+		goldmark.WithExtensions(
+			extensions...,
+		),
+		goldmark.WithParserOptions(
+			parserOptions...,
+		),
+	)
+}
+
+func (c *gemtextConverter) Convert(ctx converter.RenderContext) (converter.Result, error) {
+	doc := c.md.Parser().Parse(text.NewReader(ctx.Src))
+	w := &writer{src: ctx.Src, skipBlockAttributes: c.skipBlockAttributes}
+	w.blocks(doc)
+	return converter.Bytes(w.bytes()), nil
+}
+
+func (c *gemtextConverter) Supports(feature identity.Identity) bool {
+	return false
+}
+
+var blockAttributesRe = regexp.MustCompile(`^\{[^}]*\}$`)
+
+type link struct {
+	url   string
+	label string
+}
+
+// writer writes the gemtext for a Markdown AST. Gemtext has no inline
+// markup, so links are written as link lines after the block they are in.
+type writer struct {
+	src []byte
+
+	buf   bytes.Buffer
+	out   []string
+	links []link
+
+	skipBlockAttributes bool
+}
+
+func (w *writer) bytes() []byte {
+	return []byte(strings.Join(w.out, "\n\n") + "\n")
+}
+
+func (w *writer) add(s string) {
+	if s == "" {
+		return
+	}
+	w.out = append(w.out, s)
+}
+
+// addWithLinks adds s followed by the link lines collected while writing it.
+func (w *writer) addWithLinks(s string) {
+	var sb strings.Builder
+	sb.WriteString(s)
+	for _, l := range w.links {
+		if sb.Len() > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(linkLine(l))
+	}
+	w.links = nil
+	w.add(sb.String())
+}
+
+func linkLine(l link) string {
+	if l.label == "" || l.label == l.url {
+		return "=> " + l.url
+	}
+	return "=> " + l.url + " " + l.label
+}
+
+func (w *writer) blocks(n ast.Node) {
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		w.block(c)
+	}
+}
+
+func (w *writer) block(n ast.Node) {
+	switch n := n.(type) {
+	case *ast.Heading:
+		level := n.Level
+		if level > 3 {
+			level = 3
+		}
+		w.addWithLinks(strings.Repeat("#", level) + " " + w.inlineText(n))
+	case *ast.Paragraph, *ast.TextBlock:
+		if l, ok := w.onlyLink(n); ok {
+			w.add(linkLine(l))
+			return
+		}
+		s := w.inlineText(n)
+		if w.skipBlockAttributes && blockAttributesRe.MatchString(s) {
+			return
+		}
+		w.addWithLinks(s)
+	case *ast.List:
+		var lines []string
+		w.listItems(n, &lines)
+		w.addWithLinks(strings.Join(lines, "\n"))
+	case *ast.Blockquote:
+		var lines []string
+		for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+			for _, line := range strings.Split(w.inlineText(c), "\n") {
+				lines = append(lines, "> "+line)
+			}
+		}
+		w.addWithLinks(strings.Join(lines, "\n"))
+	case *ast.FencedCodeBlock:
+		w.add(preformatted(string(n.Language(w.src)), w.lines(n)))
+	case *ast.CodeBlock:
+		w.add(preformatted("", w.lines(n)))
+	case *east.Table:
+		w.addWithLinks(w.table(n))
+	case *east.DefinitionList:
+		var lines []string
+		for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+			switch c.(type) {
+			case *east.DefinitionTerm:
+				lines = append(lines, w.inlineText(c))
+			case *east.DefinitionDescription:
+				lines = append(lines, "* "+w.inlineText(c))
+			}
+		}
+		w.addWithLinks(strings.Join(lines, "\n"))
+	case *east.FootnoteList:
+		var lines []string
+		for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+			if fn, ok := c.(*east.Footnote); ok {
+				lines = append(lines, "["+strconv.Itoa(fn.Index)+"] "+w.inlineText(fn))
+			}
+		}
+		w.addWithLinks(strings.Join(lines, "\n"))
+	case *ast.HTMLBlock, *ast.ThematicBreak:
+		// Not supported in gemtext.
+	default:
+		w.blocks(n)
+	}
+}
+
+// listItems writes the items in list to lines, nested lists flattened, as
+// gemtext has no nested lists.
+func (w *writer) listItems(list *ast.List, lines *[]string) {
+	for item := list.FirstChild(); item != nil; item = item.NextSibling() {
+		var (
+			texts  []string
+			nested []*ast.List
+		)
+		for c := item.FirstChild(); c != nil; c = c.NextSibling() {
+			if l, ok := c.(*ast.List); ok {
+				nested = append(nested, l)
+				continue
+			}
+			if s := w.inlineText(c); s != "" {
+				texts = append(texts, s)
+			}
+		}
+		*lines = append(*lines, "* "+strings.Join(texts, " "))
+		for _, l := range nested {
+			w.listItems(l, lines)
+		}
+	}
+}
+
+func (w *writer) table(t *east.Table) string {
+	var (
+		rows   [][]string
+		widths []int
+	)
+	for r := t.FirstChild(); r != nil; r = r.NextSibling() {
+		var row []string
+		for i, c := 0, r.FirstChild(); c != nil; i, c = i+1, c.NextSibling() {
+			s := w.inlineText(c)
+			row = append(row, s)
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			if n := utf8.RuneCountInString(s); n > widths[i] {
+				widths[i] = n
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	var lines []string
+	for i, row := range rows {
+		var sb strings.Builder
+		for j, s := range row {
+			if j > 0 {
+				sb.WriteString(" | ")
+			}
+			sb.WriteString(s)
+			if j < len(row)-1 {
+				sb.WriteString(strings.Repeat(" ", widths[j]-utf8.RuneCountInString(s)))
+			}
+		}
+		lines = append(lines, strings.TrimRight(sb.String(), " "))
+		if i == 0 {
+			var sep []string
+			for _, width := range widths {
+				sep = append(sep, strings.Repeat("-", width))
+			}
+			lines = append(lines, strings.Join(sep, "-|-"))
+		}
+	}
+
+	return preformatted("", strings.Join(lines, "\n"))
+}
+
+func preformatted(alt, s string) string {
+	return "```" + alt + "\n" + strings.TrimSuffix(s, "\n") + "\n```"
+}
+
+func (w *writer) lines(n ast.Node) string {
+	var sb strings.Builder
+	l := n.Lines()
+	for i := 0; i < l.Len(); i++ {
+		line := l.At(i)
+		sb.Write(line.Value(w.src))
+	}
+	return sb.String()
+}
+
+// onlyLink reports whether n contains only a link or an image, e.g. on a
+// line by itself, which is then written as a link line only.
+func (w *writer) onlyLink(n ast.Node) (link, bool) {
+	c := n.FirstChild()
+	if c == nil || c.NextSibling() != nil {
+		return link{}, false
+	}
+	switch c := c.(type) {
+	case *ast.Link:
+		return link{url: string(c.Destination), label: w.inlineText(c)}, true
+	case *ast.Image:
+		return link{url: string(c.Destination), label: w.inlineText(c)}, true
+	case *ast.AutoLink:
+		return link{url: string(c.URL(w.src))}, true
+	}
+	return link{}, false
+}
+
+// inlineText returns the text in n with all of the inline markup removed.
+// Any links are collected to be written after the block.
+func (w *writer) inlineText(n ast.Node) string {
+	w.buf.Reset()
+	w.inline(n)
+	return strings.TrimSpace(w.buf.String())
+}
+
+func (w *writer) inline(n ast.Node) {
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		switch c := c.(type) {
+		case *ast.Text:
+			w.buf.Write(c.Segment.Value(w.src))
+			if c.HardLineBreak() {
+				w.buf.WriteString("\n")
+			} else if c.SoftLineBreak() {
+				w.buf.WriteString(" ")
+			}
+		case *ast.String:
+			w.buf.Write(c.Value)
+		case *ast.AutoLink:
+			url := string(c.URL(w.src))
+			w.buf.Write(c.Label(w.src))
+			w.links = append(w.links, link{url: url})
+		case *ast.Link:
+			start := w.buf.Len()
+			w.inline(c)
+			label := strings.TrimSpace(w.buf.String()[start:])
+			w.links = append(w.links, link{url: string(c.Destination), label: label})
+		case *ast.Image:
+			// Images are written as link lines only.
+			start := w.buf.Len()
+			w.inline(c)
+			label := strings.TrimSpace(w.buf.String()[start:])
+			w.buf.Truncate(start)
+			w.links = append(w.links, link{url: string(c.Destination), label: label})
+		case *ast.RawHTML:
+		case *east.TaskCheckBox:
+			if c.IsChecked {
+				w.buf.WriteString("[x] ")
+			} else {
+				w.buf.WriteString("[ ] ")
+			}
+		case *east.FootnoteLink:
+			w.buf.WriteString("[" + strconv.Itoa(c.Index) + "]")
+		case *east.FootnoteBacklink:
+		case *ast.Paragraph, *ast.TextBlock:
+			// E.g. in footnotes and definition descriptions.
+			if w.buf.Len() > 0 {
+				w.buf.WriteString(" ")
+			}
+			w.inline(c)
+		default:
+			w.inline(c)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gemtext
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/common/loggers"
+	"github.com/gohugoio/hugo/markup/converter"
+	"github.com/gohugoio/hugo/markup/markup_config"
+)
+
+func TestConvert(t *testing.T) {
+	c := qt.New(t)
+
+	p, err := Provider.New(converter.ProviderConfig{
+		MarkupConfig: markup_config.Default,
+		Logger:       loggers.NewErrorLogger(),
+	})
+	c.Assert(err, qt.IsNil)
+	conv, err := p.New(converter.DocumentContext{})
+	c.Assert(err, qt.IsNil)
+
+	convert := func(s string) string {
+		b, err := conv.Convert(converter.RenderContext{Src: []byte(s)})
+		c.Assert(err, qt.IsNil)
+		return string(b.Bytes())
+	}
+
+	c.Assert(convert(`# Title
+
+Some *emphasized* text with a [link](https://example.org/)
+and <b>raw HTML</b>.
+
+#### Deep heading
+
+[Only a link](/posts/first/)
+
+![A cat](cat.jpg)
+
+* One
+* Two with `+"`code`"+`
+    1. Nested
+
+> Quoted
+> text
+
+`+"```go"+`
+fmt.Println("Hello")
+`+"```"+`
+
+| Name | Value |
+|------|-------|
+| a    | 1     |
+
+- [x] Done
+- [ ] Todo
+
+---
+
+<div>Dropped</div>
+
+Footnote[^1].
+
+[^1]: The note.
+`), qt.Equals, "# Title\n\n"+
+		"Some emphasized text with a link and raw HTML.\n=> https://example.org/ link\n\n"+
+		"### Deep heading\n\n"+
+		"=> /posts/first/ Only a link\n\n"+
+		"=> cat.jpg A cat\n\n"+
+		"* One\n* Two with code\n* Nested\n\n"+
+		"> Quoted text\n\n"+
+		"```go\nfmt.Println(\"Hello\")\n```\n\n"+
+		"```\nName | Value\n-----|------\na    | 1\n```\n\n"+
+		"* [x] Done\n* [ ] Todo\n\n"+
+		"Footnote[1].\n\n"+
+		"[1] The note.\n")
+
+	c.Assert(convert("Visit https://gohugo.io today."), qt.Equals, "Visit https://gohugo.io today.\n=> https://gohugo.io\n")
+}
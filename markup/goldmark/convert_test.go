@@ -446,3 +446,47 @@ LINE5
 		c.Assert(result, qt.Contains, "<span class=\"ln\">2</span>LINE2\n<")
 	})
 }
+
+type testLinkResolver map[string]string
+
+func (r testLinkResolver) ResolveWikilink(target string) (string, string) {
+	if dest, found := r[target]; found {
+		return dest, strings.Title(target)
+	}
+	return "/404", ""
+}
+
+func TestConvertWikilinks(t *testing.T) {
+	c := qt.New(t)
+
+	mconf := markup_config.Default
+	mconf.Goldmark.Extensions.Wikilinks = true
+
+	p, err := Provider.New(
+		converter.ProviderConfig{
+			MarkupConfig: mconf,
+			Logger:       loggers.NewErrorLogger(),
+		},
+	)
+	c.Assert(err, qt.IsNil)
+	conv, err := p.New(converter.DocumentContext{
+		LinkResolver: testLinkResolver{"my page": "/my-page/", "docs/intro": "/docs/intro/"},
+	})
+	c.Assert(err, qt.IsNil)
+
+	b, err := conv.Convert(converter.RenderContext{Src: []byte(`See [[my page]], [[docs/intro | the <intro>]], [[missing]] and [not a wikilink](/foo/).
+
+[[ ]] [[a [b] c]] [[unclosed`)})
+	c.Assert(err, qt.IsNil)
+
+	got := string(b.Bytes())
+	c.Assert(got, qt.Contains, `<a href="/my-page/">My Page</a>`)
+	c.Assert(got, qt.Contains, `<a href="/docs/intro/">the &lt;intro&gt;</a>`)
+	c.Assert(got, qt.Contains, `<a href="/404">missing</a>`)
+	c.Assert(got, qt.Contains, `<a href="/foo/">not a wikilink</a>`)
+	c.Assert(got, qt.Contains, `[[ ]] [[a [b] c]] [[unclosed`)
+
+	// Disabled by default.
+	b = convert(c, markup_config.Default, "[[my page]]")
+	c.Assert(string(b.Bytes()), qt.Contains, "<p>[[my page]]</p>")
+}
@@ -61,6 +61,9 @@ type Extensions struct {
 	Strikethrough bool
 	Linkify       bool
 	TaskList      bool
+
+	// Wiki-style links, e.g. [[Page Title]] and [[path/to/page|Label]].
+	Wikilinks bool
 }
 
 type Renderer struct {
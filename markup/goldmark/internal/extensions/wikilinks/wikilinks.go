@@ -0,0 +1,119 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package wikilinks provides a Goldmark extension that parses wiki-style
+// links, e.g. [[Page Title]] and [[path/to/page|Label]].
+package wikilinks
+
+import (
+	"bytes"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// KindWikilink is the node kind of a Wikilink.
+var KindWikilink = ast.NewNodeKind("Wikilink")
+
+var (
+	defaultParser                           = new(wikilinkParser)
+	wikilinks             goldmark.Extender = new(wikilinkExtension)
+	labelSeparator                          = []byte("|")
+	openDelim, closeDelim                   = []byte("[["), []byte("]]")
+)
+
+// New returns the wikilinks extension. Rendering of the parsed nodes is left
+// to the caller.
+func New() goldmark.Extender {
+	return wikilinks
+}
+
+// Wikilink represents a wiki-style link.
+type Wikilink struct {
+	ast.BaseInline
+
+	// The link target, e.g. a page title or path with an optional fragment.
+	Target []byte
+
+	// The label after the '|', if set.
+	Label []byte
+}
+
+// Kind implements ast.Node.Kind.
+func (n *Wikilink) Kind() ast.NodeKind {
+	return KindWikilink
+}
+
+// Dump implements ast.Node.Dump.
+func (n *Wikilink) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{
+		"Target": string(n.Target),
+		"Label":  string(n.Label),
+	}, nil)
+}
+
+// Text returns the label if set, else the target.
+func (n *Wikilink) Text(source []byte) []byte {
+	if len(n.Label) > 0 {
+		return n.Label
+	}
+	return n.Target
+}
+
+type wikilinkExtension struct{}
+
+func (e *wikilinkExtension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		parser.WithInlineParsers(
+			// Before the link parser (200), which also triggers on '['.
+			util.Prioritized(defaultParser, 199),
+		),
+	)
+}
+
+type wikilinkParser struct{}
+
+func (p *wikilinkParser) Trigger() []byte {
+	return []byte{'['}
+}
+
+func (p *wikilinkParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, _ := block.PeekLine()
+	if !bytes.HasPrefix(line, openDelim) {
+		return nil
+	}
+	end := bytes.Index(line, closeDelim)
+	if end < 0 {
+		return nil
+	}
+	inner := line[len(openDelim):end]
+	if bytes.ContainsAny(inner, "[]\n") {
+		return nil
+	}
+
+	target, label := inner, []byte(nil)
+	if i := bytes.Index(inner, labelSeparator); i >= 0 {
+		target, label = inner[:i], bytes.TrimSpace(inner[i+1:])
+	}
+	target = bytes.TrimSpace(target)
+	if len(target) == 0 {
+		return nil
+	}
+
+	block.Advance(end + len(closeDelim))
+
+	return &Wikilink{Target: target, Label: label}
+}
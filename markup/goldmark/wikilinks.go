@@ -0,0 +1,106 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goldmark
+
+import (
+	"github.com/gohugoio/hugo/markup/goldmark/internal/extensions/wikilinks"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/util"
+)
+
+var _ renderer.SetOptioner = (*wikilinkRenderer)(nil)
+
+func newWikilinks() goldmark.Extender {
+	return &wikilinksExtension{}
+}
+
+type wikilinksExtension struct {
+}
+
+// Extend implements goldmark.Extender.
+func (e *wikilinksExtension) Extend(m goldmark.Markdown) {
+	wikilinks.New().Extend(m)
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(&wikilinkRenderer{Config: html.Config{Writer: html.DefaultWriter}}, 100),
+	))
+}
+
+// wikilinkRenderer renders wikilinks resolved by the document's
+// LinkResolver, using the link render hook if set.
+type wikilinkRenderer struct {
+	html.Config
+}
+
+func (r *wikilinkRenderer) SetOption(name renderer.OptionName, value interface{}) {
+	r.Config.SetOption(name, value)
+}
+
+// RegisterFuncs implements NodeRenderer.RegisterFuncs.
+func (r *wikilinkRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(wikilinks.KindWikilink, r.renderWikilink)
+}
+
+func (r *wikilinkRenderer) renderWikilink(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*wikilinks.Wikilink)
+
+	target := string(n.Target)
+	destination, title := target, ""
+
+	ctx, ok := w.(*renderContext)
+	if ok {
+		if resolver := ctx.DocumentContext().LinkResolver; resolver != nil {
+			destination, title = resolver.ResolveWikilink(target)
+		}
+	}
+
+	// Use the label if set, else the title of the page linked to.
+	text := string(n.Label)
+	if text == "" {
+		text = title
+	}
+	if text == "" {
+		text = target
+	}
+
+	if ok && ctx.RenderContext().RenderHooks.LinkRenderer != nil {
+		h := ctx.RenderContext().RenderHooks
+		err := h.LinkRenderer.RenderLink(
+			w,
+			linkContext{
+				page:        ctx.DocumentContext().Document,
+				destination: destination,
+				text:        string(util.EscapeHTML([]byte(text))),
+				plainText:   text,
+			},
+		)
+		ctx.AddIdentity(h.LinkRenderer)
+		return ast.WalkSkipChildren, err
+	}
+
+	_, _ = w.WriteString("<a href=\"")
+	if r.Unsafe || !html.IsDangerousURL([]byte(destination)) {
+		_, _ = w.Write(util.EscapeHTML(util.URLEscape([]byte(destination), true)))
+	}
+	_, _ = w.WriteString("\">")
+	_, _ = w.Write(util.EscapeHTML([]byte(text)))
+	_, _ = w.WriteString("</a>")
+
+	return ast.WalkSkipChildren, nil
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/gohugoio/hugo/markup/asciidocext"
 	"github.com/gohugoio/hugo/markup/blackfriday"
 	"github.com/gohugoio/hugo/markup/converter"
+	"github.com/gohugoio/hugo/markup/gemtext"
 	"github.com/gohugoio/hugo/markup/mmark"
 	"github.com/gohugoio/hugo/markup/pandoc"
 	"github.com/gohugoio/hugo/markup/rst"
@@ -88,6 +89,9 @@ func NewConverterProvider(cfg converter.ProviderConfig) (ConverterProvider, erro
 	if err := add(org.Provider); err != nil {
 		return nil, err
 	}
+	if err := add(gemtext.Provider); err != nil {
+		return nil, err
+	}
 
 	return &converterRegistry{
 		config:     cfg,
@@ -16,6 +16,7 @@ package markup_config
 import (
 	"github.com/gohugoio/hugo/common/maps"
 	"github.com/gohugoio/hugo/config"
+	"github.com/gohugoio/hugo/config/configschema"
 	"github.com/gohugoio/hugo/docshelper"
 	"github.com/gohugoio/hugo/markup/asciidocext/asciidocext_config"
 	"github.com/gohugoio/hugo/markup/blackfriday/blackfriday_config"
@@ -120,4 +121,5 @@ func init() {
 		return docshelper.DocProvider{"config": map[string]interface{}{"markup": parser.LowerCaseCamelJSONMarshaller{Value: Default}}}
 	}
 	docshelper.AddDocProviderFunc(docsProvider)
+	configschema.AddSection("markup", Default)
 }
@@ -1,6 +1,7 @@
 package media
 
 import (
+	"github.com/gohugoio/hugo/config/configschema"
 	"github.com/gohugoio/hugo/docshelper"
 )
 
@@ -10,4 +11,9 @@ func init() {
 		return docshelper.DocProvider{"media": map[string]interface{}{"types": DefaultTypes}}
 	}
 	docshelper.AddDocProviderFunc(docsProvider)
+
+	configschema.AddSection("mediaTypes", map[string]struct {
+		Suffixes  []string
+		Delimiter string
+	}{})
 }
@@ -160,7 +160,10 @@ var (
 	SCSSType       = newMediaType("text", "x-scss", []string{"scss"})
 	SASSType       = newMediaType("text", "x-sass", []string{"sass"})
 	CSVType        = newMediaType("text", "csv", []string{"csv"})
+	TSVType        = newMediaType("text", "tab-separated-values", []string{"tsv"})
 	HTMLType       = newMediaType("text", "html", []string{"html"})
+	GemtextType    = newMediaType("text", "gemini", []string{"gmi"})
+	MarkdownType   = newMediaType("text", "markdown", []string{"md", "markdown"})
 	JavascriptType = newMediaType("application", "javascript", []string{"js"})
 	TypeScriptType = newMediaType("application", "typescript", []string{"ts"})
 	TSXType        = newMediaType("text", "tsx", []string{"tsx"})
@@ -199,9 +202,12 @@ var DefaultTypes = Types{
 	CalendarType,
 	CSSType,
 	CSVType,
+	TSVType,
 	SCSSType,
 	SASSType,
 	HTMLType,
+	GemtextType,
+	MarkdownType,
 	JavascriptType,
 	TypeScriptType,
 	TSXType,
@@ -35,6 +35,9 @@ func TestDefaultTypes(t *testing.T) {
 		{CSSType, "text", "css", "css", "text/css", "text/css"},
 		{SCSSType, "text", "x-scss", "scss", "text/x-scss", "text/x-scss"},
 		{CSVType, "text", "csv", "csv", "text/csv", "text/csv"},
+		{TSVType, "text", "tab-separated-values", "tsv", "text/tab-separated-values", "text/tab-separated-values"},
+		{GemtextType, "text", "gemini", "gmi", "text/gemini", "text/gemini"},
+		{MarkdownType, "text", "markdown", "md", "text/markdown", "text/markdown"},
 		{HTMLType, "text", "html", "html", "text/html", "text/html"},
 		{JavascriptType, "application", "javascript", "js", "application/javascript", "application/javascript"},
 		{TypeScriptType, "application", "typescript", "ts", "application/typescript", "application/typescript"},
@@ -56,7 +59,7 @@ func TestDefaultTypes(t *testing.T) {
 
 	}
 
-	c.Assert(len(DefaultTypes), qt.Equals, 28)
+	c.Assert(len(DefaultTypes), qt.Equals, 31)
 }
 
 func TestGetByType(t *testing.T) {
@@ -0,0 +1,221 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// The page render phases measured by PageMetrics.
+const (
+	PhaseContent    = "content"
+	PhaseShortcodes = "shortcodes"
+	PhaseImages     = "images"
+)
+
+// UnattributedImages is the key used for image processing that cannot be
+// attributed to a page, e.g. images in /assets.
+const UnattributedImages = "(images not in a page bundle)"
+
+// PageMetrics tracks the time spent rendering each page, broken down into
+// template execution, content rendering, shortcodes and image processing.
+type PageMetrics struct {
+	mu         sync.Mutex
+	pages      map[string]*PageTiming
+	active     map[string]int
+	bundleDirs map[string]bundleDir
+}
+
+type bundleDir struct {
+	page string
+	leaf bool
+}
+
+// PageTiming holds the accumulated render timings for a page.
+type PageTiming struct {
+	// The page, e.g. "post/my-post.md (en)".
+	Page string
+
+	// Time spent executing the page's templates. This includes anything
+	// triggered from them, e.g. rendering .Content.
+	Template time.Duration
+
+	Content    time.Duration
+	Shortcodes time.Duration
+	Images     time.Duration
+
+	// The number of image processing operations.
+	ImageCount int
+
+	// Time spent on this page's content, shortcodes and images while
+	// not executing its templates, e.g. when rendered from a list page.
+	external time.Duration
+}
+
+// Total returns the total time attributed to the page.
+func (t PageTiming) Total() time.Duration {
+	return t.Template + t.external
+}
+
+// NewPageMetrics returns a new, empty PageMetrics.
+func NewPageMetrics() *PageMetrics {
+	m := &PageMetrics{}
+	m.Reset()
+	return m
+}
+
+// Reset clears all timings and registered bundles.
+func (m *PageMetrics) Reset() {
+	m.mu.Lock()
+	m.pages = make(map[string]*PageTiming)
+	m.active = make(map[string]int)
+	m.bundleDirs = make(map[string]bundleDir)
+	m.mu.Unlock()
+}
+
+// AddBundleDir registers dir as the directory of page's bundle. Image
+// processing of files in dir, and below it for leaf bundles, is attributed
+// to page.
+func (m *PageMetrics) AddBundleDir(dir, page string, leaf bool) {
+	m.mu.Lock()
+	if _, found := m.bundleDirs[dir]; !found {
+		m.bundleDirs[dir] = bundleDir{page: page, leaf: leaf}
+	}
+	m.mu.Unlock()
+}
+
+// MeasureTemplate runs f, the template execution for page, and adds the
+// time spent to page.
+func (m *PageMetrics) MeasureTemplate(page string, f func() error) error {
+	m.mu.Lock()
+	m.active[page]++
+	m.mu.Unlock()
+
+	start := time.Now()
+	err := f()
+	d := time.Since(start)
+
+	m.mu.Lock()
+	m.active[page]--
+	m.get(page).Template += d
+	m.mu.Unlock()
+
+	return err
+}
+
+// MeasureSince adds the time since start spent in phase to page.
+func (m *PageMetrics) MeasureSince(page, phase string, start time.Time) {
+	d := time.Since(start)
+	m.mu.Lock()
+	m.add(page, phase, d)
+	m.mu.Unlock()
+}
+
+// MeasureImageSince adds the time since start spent processing the image
+// with the given source filename to the bundle it lives in.
+func (m *PageMetrics) MeasureImageSince(filename string, start time.Time) {
+	d := time.Since(start)
+	m.mu.Lock()
+	page := m.bundleOwner(filename)
+	m.add(page, PhaseImages, d)
+	m.get(page).ImageCount++
+	m.mu.Unlock()
+}
+
+func (m *PageMetrics) bundleOwner(filename string) string {
+	if filename == "" {
+		return UnattributedImages
+	}
+	dir := filepath.Dir(filename)
+	if b, found := m.bundleDirs[dir]; found {
+		return b.page
+	}
+	for {
+		if b, found := m.bundleDirs[dir]; found && b.leaf {
+			return b.page
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return UnattributedImages
+		}
+		dir = parent
+	}
+}
+
+func (m *PageMetrics) add(page, phase string, d time.Duration) {
+	t := m.get(page)
+	switch phase {
+	case PhaseContent:
+		t.Content += d
+	case PhaseShortcodes:
+		t.Shortcodes += d
+	case PhaseImages:
+		t.Images += d
+	default:
+		panic(fmt.Sprintf("unknown phase %q", phase))
+	}
+	if m.active[page] == 0 {
+		t.external += d
+	}
+}
+
+func (m *PageMetrics) get(page string) *PageTiming {
+	t, found := m.pages[page]
+	if !found {
+		t = &PageTiming{Page: page}
+		m.pages[page] = t
+	}
+	return t
+}
+
+// Slowest returns the timings for the n slowest pages, slowest first.
+// If n <= 0, all pages are returned.
+func (m *PageMetrics) Slowest(n int) []PageTiming {
+	m.mu.Lock()
+	timings := make([]PageTiming, 0, len(m.pages))
+	for _, t := range m.pages {
+		timings = append(timings, *t)
+	}
+	m.mu.Unlock()
+
+	sort.Slice(timings, func(i, j int) bool {
+		ti, tj := timings[i].Total(), timings[j].Total()
+		if ti == tj {
+			return timings[i].Page < timings[j].Page
+		}
+		return ti > tj
+	})
+
+	if n > 0 && len(timings) > n {
+		timings = timings[:n]
+	}
+
+	return timings
+}
+
+// WriteMetrics writes a summary of the n slowest pages to w.
+func (m *PageMetrics) WriteMetrics(w io.Writer, n int) {
+	fmt.Fprintf(w, "  %12s  %12s  %12s  %12s  %12s  %6s  %s\n", "total", "template", "content", "shortcodes", "images", "", "")
+	fmt.Fprintf(w, "  %12s  %12s  %12s  %12s  %12s  %6s  %s\n", "duration", "duration", "duration", "duration", "duration", "images", "page")
+	fmt.Fprintf(w, "  %12s  %12s  %12s  %12s  %12s  %6s  %s\n", "--------", "--------", "--------", "----------", "--------", "------", "----")
+
+	for _, t := range m.Slowest(n) {
+		fmt.Fprintf(w, "  %12s  %12s  %12s  %12s  %12s  %6d  %s\n", t.Total(), t.Template, t.Content, t.Shortcodes, t.Images, t.ImageCount, t.Page)
+	}
+}
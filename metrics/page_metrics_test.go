@@ -0,0 +1,83 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestPageMetrics(t *testing.T) {
+	c := qt.New(t)
+
+	m := NewPageMetrics()
+	hour := time.Now().Add(-time.Hour)
+	minute := time.Now().Add(-time.Minute)
+
+	m.AddBundleDir(filepath.FromSlash("/content/leaf"), "leaf/index.md", true)
+	m.AddBundleDir(filepath.FromSlash("/content/branch"), "branch/_index.md", false)
+
+	// Content rendered inside the page's own template is part of it.
+	c.Assert(m.MeasureTemplate("leaf/index.md", func() error {
+		m.MeasureSince("leaf/index.md", PhaseContent, minute)
+		m.MeasureImageSince(filepath.FromSlash("/content/leaf/images/a.jpg"), minute)
+		return nil
+	}), qt.IsNil)
+
+	// Content rendered from another page's template is added to the total.
+	c.Assert(m.MeasureTemplate("branch/_index.md", func() error {
+		m.MeasureSince("branch/p1.md", PhaseShortcodes, hour)
+		return nil
+	}), qt.IsNil)
+
+	m.MeasureImageSince(filepath.FromSlash("/content/branch/b.jpg"), minute)
+	m.MeasureImageSince(filepath.FromSlash("/content/branch/sub/c.jpg"), minute)
+	m.MeasureImageSince(filepath.FromSlash("/assets/d.jpg"), minute)
+
+	slowest := m.Slowest(0)
+	c.Assert(slowest, qt.HasLen, 4)
+
+	c.Assert(slowest[0].Page, qt.Equals, "branch/p1.md")
+	c.Assert(slowest[0].Total() >= time.Hour, qt.IsTrue)
+	c.Assert(slowest[0].Template, qt.Equals, time.Duration(0))
+	c.Assert(slowest[0].Shortcodes >= time.Hour, qt.IsTrue)
+
+	c.Assert(slowest[1].Page, qt.Equals, UnattributedImages)
+	c.Assert(slowest[1].ImageCount, qt.Equals, 2)
+
+	c.Assert(slowest[2].Page, qt.Equals, "branch/_index.md")
+	c.Assert(slowest[2].Images >= time.Minute, qt.IsTrue)
+	c.Assert(slowest[2].ImageCount, qt.Equals, 1)
+
+	leaf := slowest[3]
+	c.Assert(leaf.Page, qt.Equals, "leaf/index.md")
+	c.Assert(leaf.Content >= time.Minute, qt.IsTrue)
+	c.Assert(leaf.Images >= time.Minute, qt.IsTrue)
+	c.Assert(leaf.ImageCount, qt.Equals, 1)
+	c.Assert(leaf.Total(), qt.Equals, leaf.Template)
+
+	c.Assert(m.Slowest(2), qt.HasLen, 2)
+
+	var b bytes.Buffer
+	m.WriteMetrics(&b, 1)
+	c.Assert(b.String(), qt.Contains, "branch/p1.md")
+	c.Assert(b.String(), qt.Not(qt.Contains), "leaf/index.md")
+
+	m.Reset()
+	c.Assert(m.Slowest(0), qt.HasLen, 0)
+}
@@ -0,0 +1,259 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// The progress formats.
+const (
+	ProgressFormatText = "text"
+	ProgressFormatJSON = "json"
+)
+
+// The build phases reported.
+const (
+	ProgressPhaseContent  = "content"
+	ProgressPhaseAssemble = "assemble"
+	ProgressPhaseRender   = "render"
+	ProgressPhaseStatic   = "static"
+)
+
+// Progress reports the progress of the build phases, e.g. the number of
+// pages rendered and an ETA when the total is known, at regular intervals
+// while the phases are running.
+//
+// All methods are safe to call on a nil Progress, which reports nothing.
+type Progress struct {
+	w        io.Writer
+	json     bool
+	interval time.Duration
+
+	mu      sync.Mutex
+	phases  []*ProgressPhase
+	ticking bool
+}
+
+// ProgressPhase tracks the progress of a running build phase.
+type ProgressPhase struct {
+	p *Progress
+
+	name  string
+	start time.Time
+
+	// Updated atomically.
+	total int64
+	done  int64
+}
+
+// NewProgress creates a new Progress writing in the given format, text or
+// json, to w.
+func NewProgress(w io.Writer, format string) (*Progress, error) {
+	switch format {
+	case ProgressFormatText, ProgressFormatJSON:
+	default:
+		return nil, errors.Errorf("invalid progress format %q, must be %s or %s", format, ProgressFormatText, ProgressFormatJSON)
+	}
+	return &Progress{w: w, json: format == ProgressFormatJSON, interval: time.Second}, nil
+}
+
+// Start starts a new phase with the given name, e.g. "render HTML", and the
+// total number of items to process, 0 if not known.
+// Any running phase with the same name is replaced.
+func (p *Progress) Start(name string, total int) *ProgressPhase {
+	if p == nil {
+		return nil
+	}
+
+	ph := &ProgressPhase{p: p, name: name, start: time.Now(), total: int64(total)}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.remove(name)
+	p.phases = append(p.phases, ph)
+
+	if !p.ticking {
+		p.ticking = true
+		go p.tick()
+	}
+
+	return ph
+}
+
+// Phase returns the running phase with the given name, nil if not found.
+func (p *Progress) Phase(name string) *ProgressPhase {
+	if p == nil {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, ph := range p.phases {
+		if ph.name == name {
+			return ph
+		}
+	}
+	return nil
+}
+
+func (p *Progress) remove(name string) {
+	for i, ph := range p.phases {
+		if ph.name == name {
+			p.phases = append(p.phases[:i], p.phases[i+1:]...)
+			return
+		}
+	}
+}
+
+func (p *Progress) tick() {
+	t := time.NewTicker(p.interval)
+	defer t.Stop()
+
+	for range t.C {
+		p.mu.Lock()
+		if len(p.phases) == 0 {
+			p.ticking = false
+			p.mu.Unlock()
+			return
+		}
+		for _, ph := range p.phases {
+			p.report(ph, false)
+		}
+		p.mu.Unlock()
+	}
+}
+
+// progressReport is a progress line in the JSON format.
+type progressReport struct {
+	Time      string `json:"time"`
+	Phase     string `json:"phase"`
+	State     string `json:"state"`
+	Done      int64  `json:"done"`
+	Total     int64  `json:"total,omitempty"`
+	Percent   int    `json:"percent,omitempty"`
+	ElapsedMs int64  `json:"elapsedMs"`
+	EtaMs     int64  `json:"etaMs,omitempty"`
+}
+
+func (p *Progress) report(ph *ProgressPhase, ended bool) {
+	now := time.Now()
+	done, total := atomic.LoadInt64(&ph.done), atomic.LoadInt64(&ph.total)
+	elapsed := now.Sub(ph.start)
+
+	var (
+		percent int
+		eta     time.Duration
+	)
+	if total > 0 {
+		if done > total {
+			total = done
+		}
+		percent = int(done * 100 / total)
+		if done > 0 && !ended {
+			eta = time.Duration(int64(elapsed) / done * (total - done))
+		}
+	}
+
+	if p.json {
+		r := progressReport{
+			Time:      now.Format(time.RFC3339),
+			Phase:     ph.name,
+			State:     "running",
+			Done:      done,
+			Total:     total,
+			Percent:   percent,
+			ElapsedMs: int64(elapsed / time.Millisecond),
+			EtaMs:     int64(eta / time.Millisecond),
+		}
+		if ended {
+			r.State = "done"
+		}
+		b, _ := json.Marshal(r)
+		p.w.Write(append(b, '\n'))
+		return
+	}
+
+	counts := fmt.Sprint(done)
+	if total > 0 {
+		counts = fmt.Sprintf("%d/%d (%d%%)", done, total, percent)
+	}
+
+	if ended {
+		fmt.Fprintf(p.w, "Progress: %s: %s in %s\n", ph.name, counts, formatProgressDuration(elapsed))
+		return
+	}
+
+	var etaStr string
+	if eta > 0 {
+		etaStr = ", ETA " + formatProgressDuration(eta)
+	}
+	fmt.Fprintf(p.w, "Progress: %s: %s, %s elapsed%s\n", ph.name, counts, formatProgressDuration(elapsed), etaStr)
+}
+
+func formatProgressDuration(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(time.Second).String()
+}
+
+// AddTotal adds n to the total number of items in the phase.
+func (ph *ProgressPhase) AddTotal(n int) {
+	if ph == nil {
+		return
+	}
+	atomic.AddInt64(&ph.total, int64(n))
+}
+
+// Incr marks one more item in the phase as done.
+func (ph *ProgressPhase) Incr() {
+	ph.Add(1)
+}
+
+// Add marks n more items in the phase as done.
+func (ph *ProgressPhase) Add(n int) {
+	if ph == nil {
+		return
+	}
+	atomic.AddInt64(&ph.done, int64(n))
+}
+
+// End ends the phase and reports its final count and duration.
+func (ph *ProgressPhase) End() {
+	if ph == nil {
+		return
+	}
+
+	p := ph.p
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for i, ph2 := range p.phases {
+		if ph2 == ph {
+			p.phases = append(p.phases[:i], p.phases[i+1:]...)
+			p.report(ph, true)
+			return
+		}
+	}
+}
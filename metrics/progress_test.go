@@ -0,0 +1,101 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+type syncBuffer struct {
+	mu sync.Mutex
+	b  bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.b.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.b.String()
+}
+
+func TestProgress(t *testing.T) {
+	c := qt.New(t)
+
+	_, err := NewProgress(nil, "xml")
+	c.Assert(err, qt.Not(qt.IsNil))
+
+	c.Run("Text", func(c *qt.C) {
+		var b syncBuffer
+		p, err := NewProgress(&b, ProgressFormatText)
+		c.Assert(err, qt.IsNil)
+		p.interval = 10 * time.Millisecond
+
+		ph := p.Start("render HTML", 4)
+		c.Assert(p.Phase("render HTML"), qt.Equals, ph)
+		ph.Add(2)
+		time.Sleep(50 * time.Millisecond)
+		ph.Add(2)
+		ph.End()
+		c.Assert(p.Phase("render HTML"), qt.IsNil)
+
+		p.Start("content", 0).End()
+
+		out := b.String()
+		c.Assert(out, qt.Contains, "Progress: render HTML: 2/4 (50%), ")
+		c.Assert(out, qt.Contains, "elapsed, ETA ")
+		c.Assert(out, qt.Contains, "Progress: render HTML: 4/4 (100%) in ")
+		c.Assert(out, qt.Contains, "Progress: content: 0 in ")
+	})
+
+	c.Run("JSON", func(c *qt.C) {
+		var b syncBuffer
+		p, err := NewProgress(&b, ProgressFormatJSON)
+		c.Assert(err, qt.IsNil)
+
+		ph := p.Start("assemble", 10)
+		ph.AddTotal(10)
+		ph.Add(5)
+		ph.End()
+
+		lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+		c.Assert(lines, qt.HasLen, 1)
+		var r progressReport
+		c.Assert(json.Unmarshal([]byte(lines[0]), &r), qt.IsNil)
+		c.Assert(r.Phase, qt.Equals, "assemble")
+		c.Assert(r.State, qt.Equals, "done")
+		c.Assert(r.Done, qt.Equals, int64(5))
+		c.Assert(r.Total, qt.Equals, int64(20))
+		c.Assert(r.Percent, qt.Equals, 25)
+	})
+
+	c.Run("Nil", func(c *qt.C) {
+		var p *Progress
+		ph := p.Start("content", 0)
+		ph.Incr()
+		ph.End()
+		c.Assert(p.Phase("content"), qt.IsNil)
+	})
+}
@@ -0,0 +1,193 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// maxTraceSpans is the maximum number of spans recorded in a build. Any
+// spans beyond that are dropped.
+const maxTraceSpans = 200000
+
+// Tracer records the build phases, template executions and resource
+// transformations as spans, exported as OpenTelemetry traces.
+//
+// All methods are safe to call on a nil Tracer, which records nothing.
+type Tracer struct {
+	endpoint string
+	headers  map[string]string
+	client   *http.Client
+
+	mu      sync.Mutex
+	traceID string
+	root    *Span
+	phase   *Span
+	spans   []*Span
+	dropped int
+}
+
+// Span is a timed operation in a build.
+type Span struct {
+	t *Tracer
+
+	name     string
+	id       string
+	parentID string
+	start    time.Time
+	end      time.Time
+	attrs    []spanAttribute
+	err      error
+}
+
+type spanAttribute struct {
+	key   string
+	value interface{}
+}
+
+// NewTracer creates a new Tracer exporting to the OTLP/HTTP endpoint, e.g.
+// http://localhost:4318, sending the given HTTP headers with client. If
+// client is nil, http.DefaultClient is used.
+func NewTracer(endpoint string, headers map[string]string, client *http.Client) *Tracer {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Tracer{endpoint: endpoint, headers: headers, client: client}
+}
+
+// StartBuild starts a new trace with a root span for a build.
+// attrs are key/value pairs, see Span.SetAttributes.
+func (t *Tracer) StartBuild(name string, attrs ...interface{}) *Span {
+	if t == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.traceID = newTraceID(16)
+	t.spans = nil
+	t.dropped = 0
+	t.phase = nil
+	t.root = t.newSpan(name, "", attrs)
+
+	return t.root
+}
+
+// StartPhase starts a span for a build phase, e.g. "render". The spans
+// started until it ends are added to this phase.
+func (t *Tracer) StartPhase(name string) *Span {
+	if t == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.phase = t.newSpan(name, t.root.getID(), nil)
+
+	return t.phase
+}
+
+// Start starts a span in the current phase.
+// attrs are key/value pairs, see Span.SetAttributes.
+func (t *Tracer) Start(name string, attrs ...interface{}) *Span {
+	if t == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	parent := t.phase
+	if parent == nil {
+		parent = t.root
+	}
+
+	return t.newSpan(name, parent.getID(), attrs)
+}
+
+func (t *Tracer) newSpan(name, parentID string, attrs []interface{}) *Span {
+	if len(t.spans) >= maxTraceSpans {
+		t.dropped++
+		return nil
+	}
+
+	s := &Span{
+		t:        t,
+		name:     name,
+		id:       newTraceID(8),
+		parentID: parentID,
+		start:    time.Now(),
+	}
+	s.SetAttributes(attrs...)
+	t.spans = append(t.spans, s)
+
+	return s
+}
+
+func (s *Span) getID() string {
+	if s == nil {
+		return ""
+	}
+	return s.id
+}
+
+// SetAttributes adds the attributes given as key/value pairs. The keys
+// must be strings and the values strings, ints or bools.
+func (s *Span) SetAttributes(attrs ...interface{}) {
+	if s == nil {
+		return
+	}
+	for i := 0; i+1 < len(attrs); i += 2 {
+		key, ok := attrs[i].(string)
+		if !ok {
+			continue
+		}
+		s.attrs = append(s.attrs, spanAttribute{key: key, value: attrs[i+1]})
+	}
+}
+
+// SetError marks the span as failed if err is not nil.
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.t.mu.Lock()
+	s.err = err
+	s.t.mu.Unlock()
+}
+
+// End ends the span.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.t.mu.Lock()
+	s.end = time.Now()
+	if s.t.phase == s {
+		s.t.phase = nil
+	}
+	s.t.mu.Unlock()
+}
+
+func newTraceID(size int) string {
+	b := make([]byte, size)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
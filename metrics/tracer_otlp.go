@@ -0,0 +1,217 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gohugoio/hugo/common/hugo"
+	"github.com/pkg/errors"
+)
+
+const (
+	otlpTracesPath = "/v1/traces"
+
+	// The number of spans sent in each request.
+	otlpBatchSize = 5000
+
+	otlpTimeout = 30 * time.Second
+
+	otlpSpanKindInternal = 1
+	otlpStatusCodeError  = 2
+)
+
+// The OTLP/HTTP JSON encoding, see
+// https://github.com/open-telemetry/opentelemetry-proto/blob/main/docs/specification.md
+type (
+	otlpTracesRequest struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+
+	otlpResource struct {
+		Attributes []otlpKeyValue `json:"attributes"`
+	}
+
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+
+	otlpScope struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	}
+
+	otlpSpan struct {
+		TraceID           string         `json:"traceId"`
+		SpanID            string         `json:"spanId"`
+		ParentSpanID      string         `json:"parentSpanId,omitempty"`
+		Name              string         `json:"name"`
+		Kind              int            `json:"kind"`
+		StartTimeUnixNano string         `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+		Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+		Status            *otlpStatus    `json:"status,omitempty"`
+	}
+
+	otlpStatus struct {
+		Code    int    `json:"code"`
+		Message string `json:"message,omitempty"`
+	}
+
+	otlpKeyValue struct {
+		Key   string                 `json:"key"`
+		Value map[string]interface{} `json:"value"`
+	}
+)
+
+func toOTLPKeyValue(key string, v interface{}) otlpKeyValue {
+	var value map[string]interface{}
+	switch vv := v.(type) {
+	case bool:
+		value = map[string]interface{}{"boolValue": vv}
+	case int:
+		value = map[string]interface{}{"intValue": strconv.Itoa(vv)}
+	case int64:
+		value = map[string]interface{}{"intValue": strconv.FormatInt(vv, 10)}
+	default:
+		value = map[string]interface{}{"stringValue": fmt.Sprint(vv)}
+	}
+	return otlpKeyValue{Key: key, Value: value}
+}
+
+// ParseOTLPHeaders parses headers on the form used in the
+// OTEL_EXPORTER_OTLP_HEADERS environment variable, e.g. "key1=value1,key2=value2".
+func ParseOTLPHeaders(s string) map[string]string {
+	headers := make(map[string]string)
+	for _, kv := range strings.Split(s, ",") {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		headers[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	return headers
+}
+
+// Export sends the spans recorded in the current build to the endpoint.
+// It returns the number of spans dropped because the trace was too big.
+func (t *Tracer) Export(ctx context.Context) (int, error) {
+	if t == nil {
+		return 0, nil
+	}
+
+	t.mu.Lock()
+	spans := make([]otlpSpan, 0, len(t.spans))
+	for _, s := range t.spans {
+		end := s.end
+		if end.IsZero() {
+			// Not ended, e.g. in a failed build.
+			continue
+		}
+		span := otlpSpan{
+			TraceID:           t.traceID,
+			SpanID:            s.id,
+			ParentSpanID:      s.parentID,
+			Name:              s.name,
+			Kind:              otlpSpanKindInternal,
+			StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(end.UnixNano(), 10),
+		}
+		for _, a := range s.attrs {
+			span.Attributes = append(span.Attributes, toOTLPKeyValue(a.key, a.value))
+		}
+		if s.err != nil {
+			span.Status = &otlpStatus{Code: otlpStatusCodeError, Message: s.err.Error()}
+		}
+		spans = append(spans, span)
+	}
+	dropped := t.dropped
+	t.spans = nil
+	t.mu.Unlock()
+
+	resource := otlpResource{
+		Attributes: []otlpKeyValue{
+			toOTLPKeyValue("service.name", "hugo"),
+			toOTLPKeyValue("service.version", hugo.CurrentVersion.String()),
+		},
+	}
+	scope := otlpScope{Name: "github.com/gohugoio/hugo", Version: hugo.CurrentVersion.String()}
+
+	for len(spans) > 0 {
+		n := otlpBatchSize
+		if n > len(spans) {
+			n = len(spans)
+		}
+		req := otlpTracesRequest{
+			ResourceSpans: []otlpResourceSpans{
+				{Resource: resource, ScopeSpans: []otlpScopeSpans{{Scope: scope, Spans: spans[:n]}}},
+			},
+		}
+		if err := t.post(ctx, req); err != nil {
+			return dropped, err
+		}
+		spans = spans[n:]
+	}
+
+	return dropped, nil
+}
+
+func (t *Tracer) post(ctx context.Context, req otlpTracesRequest) error {
+	b, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, otlpTimeout)
+	defer cancel()
+
+	endpoint := strings.TrimSuffix(t.endpoint, "/")
+	if !strings.HasSuffix(endpoint, otlpTracesPath) {
+		endpoint += otlpTracesPath
+	}
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(b))
+	if err != nil {
+		return errors.Wrap(err, "failed to export traces")
+	}
+	r.Header.Set("Content-Type", "application/json")
+	for k, v := range t.headers {
+		r.Header.Set(k, v)
+	}
+
+	res, err := t.client.Do(r)
+	if err != nil {
+		return errors.Wrap(err, "failed to export traces")
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return errors.Errorf("failed to export traces to %q: %s", endpoint, res.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestTracer(t *testing.T) {
+	c := qt.New(t)
+
+	var (
+		received otlpTracesRequest
+		auth     string
+		path     string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		auth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer srv.Close()
+
+	tracer := NewTracer(srv.URL, ParseOTLPHeaders("Authorization=Bearer foo, x-other=bar"), nil)
+
+	build := tracer.StartBuild("build", "hugo.rebuild", false, "hugo.sites", 2)
+	tracer.Start("outside").End()
+	render := tracer.StartPhase("render")
+	tracer.Start("template: index.html", "hugo.template", "index.html").End()
+	failed := tracer.Start("transform: postcss")
+	failed.SetError(errors.New("failed"))
+	failed.End()
+	render.End()
+	tracer.Start("after").End()
+	build.End()
+
+	dropped, err := tracer.Export(context.Background())
+	c.Assert(err, qt.IsNil)
+	c.Assert(dropped, qt.Equals, 0)
+	c.Assert(path, qt.Equals, "/v1/traces")
+	c.Assert(auth, qt.Equals, "Bearer foo")
+
+	c.Assert(received.ResourceSpans, qt.HasLen, 1)
+	rs := received.ResourceSpans[0]
+	c.Assert(rs.Resource.Attributes[0].Key, qt.Equals, "service.name")
+	spans := rs.ScopeSpans[0].Spans
+	c.Assert(spans, qt.HasLen, 6)
+
+	byName := make(map[string]otlpSpan)
+	for _, s := range spans {
+		c.Assert(s.TraceID, qt.HasLen, 32)
+		c.Assert(s.TraceID, qt.Equals, spans[0].TraceID)
+		c.Assert(s.SpanID, qt.HasLen, 16)
+		byName[s.Name] = s
+	}
+
+	root := byName["build"]
+	c.Assert(root.ParentSpanID, qt.Equals, "")
+	c.Assert(root.Attributes, qt.DeepEquals, []otlpKeyValue{
+		{Key: "hugo.rebuild", Value: map[string]interface{}{"boolValue": false}},
+		{Key: "hugo.sites", Value: map[string]interface{}{"intValue": "2"}},
+	})
+	c.Assert(byName["outside"].ParentSpanID, qt.Equals, root.SpanID)
+	c.Assert(byName["render"].ParentSpanID, qt.Equals, root.SpanID)
+	c.Assert(byName["template: index.html"].ParentSpanID, qt.Equals, byName["render"].SpanID)
+	c.Assert(byName["template: index.html"].Attributes[0].Value["stringValue"], qt.Equals, "index.html")
+	c.Assert(byName["transform: postcss"].Status, qt.DeepEquals, &otlpStatus{Code: otlpStatusCodeError, Message: "failed"})
+	c.Assert(byName["after"].ParentSpanID, qt.Equals, root.SpanID)
+
+	// The spans are sent once.
+	received = otlpTracesRequest{}
+	_, err = tracer.Export(context.Background())
+	c.Assert(err, qt.IsNil)
+	c.Assert(received.ResourceSpans, qt.HasLen, 0)
+
+	c.Run("Failed", func(c *qt.C) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer srv.Close()
+
+		tracer := NewTracer(srv.URL+"/v1/traces", nil, nil)
+		tracer.StartBuild("build").End()
+		_, err := tracer.Export(context.Background())
+		c.Assert(err, qt.ErrorMatches, `failed to export traces to ".*/v1/traces": 401 Unauthorized`)
+	})
+
+	c.Run("Nil", func(c *qt.C) {
+		var tracer *Tracer
+		span := tracer.StartBuild("build")
+		tracer.Start("template").End()
+		span.SetError(errors.New("failed"))
+		span.End()
+		_, err := tracer.Export(context.Background())
+		c.Assert(err, qt.IsNil)
+	})
+}
@@ -16,6 +16,7 @@ package minifiers
 import (
 	"github.com/gohugoio/hugo/common/maps"
 	"github.com/gohugoio/hugo/config"
+	"github.com/gohugoio/hugo/config/configschema"
 	"github.com/gohugoio/hugo/docshelper"
 	"github.com/gohugoio/hugo/parser"
 	"github.com/spf13/cast"
@@ -72,6 +73,9 @@ type minifyConfig struct {
 	DisableXML  bool
 
 	Tdewolff tdewolffConfig
+
+	// Parts of the HTML to keep as is.
+	Preserve preserveConfig
 }
 
 var defaultConfig = minifyConfig{
@@ -127,4 +131,5 @@ func init() {
 		return docshelper.DocProvider{"config": map[string]interface{}{"minify": parser.LowerCaseCamelJSONMarshaller{Value: defaultConfig}}}
 	}
 	docshelper.AddDocProviderFunc(docsProvider)
+	configschema.AddSection("minify", defaultConfig)
 }
@@ -89,10 +89,17 @@ func New(mediaTypes media.Types, outputFormats output.Formats, cfg config.Provid
 
 	// HTML
 	if !conf.DisableHTML {
-		addMinifier(m, mediaTypes, "html", &conf.Tdewolff.HTML)
+		var htmlMin minify.Minifier = &conf.Tdewolff.HTML
+		if !conf.Preserve.isZero() {
+			htmlMin, err = newHTMLPreserveMinifier(&conf.Tdewolff.HTML, conf.Preserve)
+			if err != nil {
+				return Client{}, err
+			}
+		}
+		addMinifier(m, mediaTypes, "html", htmlMin)
 		for _, of := range outputFormats {
 			if of.IsHTML {
-				m.Add(of.MediaType.Type(), &conf.Tdewolff.HTML)
+				m.Add(of.MediaType.Type(), htmlMin)
 			}
 		}
 	}
@@ -107,6 +107,50 @@ func TestConfigureMinify(t *testing.T) {
 	}
 }
 
+func TestMinifyPreserve(t *testing.T) {
+	c := qt.New(t)
+	v := config.New()
+	v.Set("minify", map[string]interface{}{
+		"preserve": map[string]interface{}{
+			"comments":         []interface{}{"^\\s*(google_ad|LICENSE)"},
+			"quotedAttributes": []interface{}{"class", "data-x"},
+			"elements":         []interface{}{"pre", ".no-minify", "div[data-raw]", "#banner"},
+		},
+	})
+	m, err := New(media.DefaultTypes, output.DefaultFormats, v)
+	c.Assert(err, qt.IsNil)
+
+	for _, test := range []struct {
+		rawString         string
+		expectedMinString string
+	}{
+		{"<p>  a  </p>\n<!-- remove me -->\n<!-- LICENSE: MIT -->\n<p>b</p>", "<p>a</p><!-- LICENSE: MIT --><p>b</p>"},
+		{"<!-- google_ad_section_start -->  <p>Ad</p>  <!-- google_ad_section_end -->", "<!-- google_ad_section_start --><p>Ad</p><!-- google_ad_section_end -->"},
+		{`<p class="a" id="b" data-x="c">  x  </p>`, `<p class="a" id=b data-x="c">x</p>`},
+		{`<p class="a b">x</p>`, `<p class="a b">x</p>`},
+		{"<pre>\n  a  \n</pre>", "<pre>\n  a  \n</pre>"},
+		{"<div>  <section class=\"x no-minify\">\n  <div>  a  </div>\n  </section>  </div>", "<div><section class=\"x no-minify\">\n  <div>  a  </div>\n  </section></div>"},
+		{"<div data-raw>  <div>  <div> a </div> </div>  </div><p>  b  </p>", "<div data-raw>  <div>  <div> a </div> </div>  </div><p>b</p>"},
+		{"<div data-rew>  a  </div>", "<div data-rew>a</div>"},
+		{"<img id=\"banner\"  src=\"a.png\" ><p>  b  </p>", "<img id=\"banner\"  src=\"a.png\" ><p>b</p>"},
+	} {
+		var b bytes.Buffer
+		c.Assert(m.Minify(media.HTMLType, &b, strings.NewReader(test.rawString)), qt.IsNil)
+		c.Assert(b.String(), qt.Equals, test.expectedMinString, qt.Commentf(test.rawString))
+	}
+
+	for _, invalid := range []map[string]interface{}{
+		{"comments": []interface{}{"("}},
+		{"elements": []interface{}{"div > p"}},
+		{"elements": []interface{}{"[data"}},
+	} {
+		v := config.New()
+		v.Set("minify", map[string]interface{}{"preserve": invalid})
+		_, err := New(media.DefaultTypes, output.DefaultFormats, v)
+		c.Assert(err, qt.Not(qt.IsNil))
+	}
+}
+
 func TestJSONRoundTrip(t *testing.T) {
 	c := qt.New(t)
 	v := config.New()
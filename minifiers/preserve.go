@@ -0,0 +1,394 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package minifiers
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tdewolff/minify/v2"
+	"github.com/tdewolff/minify/v2/html"
+	"github.com/tdewolff/parse/v2"
+	htmlparse "github.com/tdewolff/parse/v2/html"
+)
+
+// preserveConfig configures the parts of the HTML that should survive
+// minification as is.
+type preserveConfig struct {
+	// Comments matching any of these regular expressions are kept,
+	// e.g. "^\\s*google_ad" or "(?i)license".
+	Comments []string
+
+	// Attributes that are always written with quoted values, e.g. "class".
+	QuotedAttributes []string
+
+	// Elements matching any of these selectors are not minified, e.g. "pre",
+	// "#banner", ".no-minify" or "div[data-nominify]".
+	Elements []string
+}
+
+func (c preserveConfig) isZero() bool {
+	return len(c.Comments) == 0 && len(c.QuotedAttributes) == 0 && len(c.Elements) == 0
+}
+
+// htmlPreserveMinifier wraps the HTML minifier, keeping the comments,
+// attribute quotes and elements configured in preserveConfig.
+type htmlPreserveMinifier struct {
+	html *html.Minifier
+
+	comments         []*regexp.Regexp
+	quotedAttributes map[string]bool
+	elements         []elementSelector
+}
+
+func newHTMLPreserveMinifier(m *html.Minifier, conf preserveConfig) (*htmlPreserveMinifier, error) {
+	hm := &htmlPreserveMinifier{
+		html:             m,
+		quotedAttributes: make(map[string]bool),
+	}
+
+	for _, s := range conf.Comments {
+		re, err := regexp.Compile(s)
+		if err != nil {
+			return nil, errors.Wrapf(err, "minify: failed to compile preserve comment pattern %q", s)
+		}
+		hm.comments = append(hm.comments, re)
+	}
+
+	for _, s := range conf.QuotedAttributes {
+		hm.quotedAttributes[strings.ToLower(s)] = true
+	}
+
+	for _, s := range conf.Elements {
+		sel, err := parseElementSelector(s)
+		if err != nil {
+			return nil, err
+		}
+		hm.elements = append(hm.elements, sel)
+	}
+
+	return hm, nil
+}
+
+// The placeholder for the preserved parts while the rest is minified.
+const preservePlaceholderFormat = "_hugopreserve%d_"
+
+// Minify minifies the HTML in r into w. The preserved comments and elements
+// are replaced with placeholders before minification and restored after.
+func (m *htmlPreserveMinifier) Minify(mm *minify.M, w io.Writer, r io.Reader, params map[string]string) error {
+	src, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	var (
+		skeleton  bytes.Buffer
+		preserved [][]byte
+		pos       int
+	)
+
+	for _, seg := range m.findPreserved(src) {
+		skeleton.Write(src[pos:seg[0]])
+		fmt.Fprintf(&skeleton, preservePlaceholderFormat, len(preserved))
+		preserved = append(preserved, src[seg[0]:seg[1]])
+		pos = seg[1]
+	}
+	skeleton.Write(src[pos:])
+
+	var minified bytes.Buffer
+	if err := m.html.Minify(mm, &minified, &skeleton, params); err != nil {
+		return err
+	}
+
+	b := minified.Bytes()
+	if len(m.quotedAttributes) > 0 {
+		b = m.quoteAttributes(b)
+	}
+
+	for i := len(preserved) - 1; i >= 0; i-- {
+		b = bytes.Replace(b, []byte(fmt.Sprintf(preservePlaceholderFormat, i)), preserved[i], 1)
+	}
+
+	_, err = w.Write(b)
+	return err
+}
+
+type htmlToken struct {
+	tt         htmlparse.TokenType
+	text       []byte
+	attrVal    []byte
+	start, end int
+}
+
+func lexHTML(src []byte) []htmlToken {
+	z := parse.NewInputBytes(src)
+	defer z.Restore()
+	l := htmlparse.NewLexer(z)
+
+	var (
+		tokens []htmlToken
+		start  int
+	)
+	for {
+		tt, _ := l.Next()
+		if tt == htmlparse.ErrorToken {
+			return tokens
+		}
+		end := z.Offset()
+		tokens = append(tokens, htmlToken{tt: tt, text: l.Text(), attrVal: l.AttrVal(), start: start, end: end})
+		start = end
+	}
+}
+
+// findPreserved returns the start and end offsets of the comments and
+// elements in src to preserve, in order.
+func (m *htmlPreserveMinifier) findPreserved(src []byte) [][2]int {
+	if len(m.comments) == 0 && len(m.elements) == 0 {
+		return nil
+	}
+
+	var segments [][2]int
+	tokens := lexHTML(src)
+
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		switch t.tt {
+		case htmlparse.CommentToken:
+			for _, re := range m.comments {
+				if re.Match(t.text) {
+					segments = append(segments, [2]int{t.start, t.end})
+					break
+				}
+			}
+		case htmlparse.StartTagToken:
+			if len(m.elements) == 0 {
+				continue
+			}
+			name := strings.ToLower(string(t.text))
+			attrs := make(map[string]string)
+			j := i + 1
+			for ; j < len(tokens) && tokens[j].tt == htmlparse.AttributeToken; j++ {
+				attrs[strings.ToLower(string(tokens[j].text))] = unquoteAttrVal(tokens[j].attrVal)
+			}
+			if j == len(tokens) || !m.matchesElement(name, attrs) {
+				continue
+			}
+
+			end := tokens[j].end
+			if tokens[j].tt == htmlparse.StartTagCloseToken && !isVoidElement(name) {
+				// Find the matching end tag.
+				depth := 1
+				for j++; j < len(tokens); j++ {
+					tj := tokens[j]
+					if tj.tt == htmlparse.StartTagToken && strings.EqualFold(string(tj.text), name) {
+						depth++
+					} else if tj.tt == htmlparse.EndTagToken && strings.EqualFold(string(tj.text), name) {
+						depth--
+						if depth == 0 {
+							break
+						}
+					}
+				}
+				if j == len(tokens) {
+					j--
+				}
+				end = tokens[j].end
+			}
+
+			segments = append(segments, [2]int{t.start, end})
+			i = j
+		}
+	}
+
+	return segments
+}
+
+func (m *htmlPreserveMinifier) matchesElement(name string, attrs map[string]string) bool {
+	for _, sel := range m.elements {
+		if sel.matches(name, attrs) {
+			return true
+		}
+	}
+	return false
+}
+
+// quoteAttributes adds quotes to the unquoted values of the attributes in
+// quotedAttributes.
+func (m *htmlPreserveMinifier) quoteAttributes(b []byte) []byte {
+	var (
+		buf bytes.Buffer
+		pos int
+	)
+
+	for _, t := range lexHTML(b) {
+		if t.tt != htmlparse.AttributeToken || len(t.attrVal) == 0 || t.attrVal[0] == '"' || t.attrVal[0] == '\'' {
+			continue
+		}
+		if !m.quotedAttributes[strings.ToLower(string(t.text))] {
+			continue
+		}
+		valStart := t.end - len(t.attrVal)
+		buf.Write(b[pos:valStart])
+		buf.WriteByte('"')
+		buf.Write(t.attrVal)
+		buf.WriteByte('"')
+		pos = t.end
+	}
+
+	if pos == 0 {
+		return b
+	}
+	buf.Write(b[pos:])
+
+	return buf.Bytes()
+}
+
+func unquoteAttrVal(b []byte) string {
+	if len(b) > 1 && (b[0] == '"' || b[0] == '\'') && b[len(b)-1] == b[0] {
+		b = b[1 : len(b)-1]
+	}
+	return string(b)
+}
+
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "param": true, "source": true, "track": true, "wbr": true,
+}
+
+func isVoidElement(name string) bool {
+	return voidElements[name]
+}
+
+// elementSelector is a simple CSS selector, e.g. "div", "#id", ".class",
+// "[attr]" or "[attr=value]", or a combination of these, e.g. "div.a[b]".
+type elementSelector struct {
+	tag     string
+	id      string
+	classes []string
+	attrs   []attrSelector
+}
+
+type attrSelector struct {
+	name     string
+	value    string
+	hasValue bool
+}
+
+func (s elementSelector) matches(name string, attrs map[string]string) bool {
+	if s.tag != "" && s.tag != name {
+		return false
+	}
+	if s.id != "" && attrs["id"] != s.id {
+		return false
+	}
+	if len(s.classes) > 0 {
+		classes := strings.Fields(attrs["class"])
+		for _, c := range s.classes {
+			var found bool
+			for _, cc := range classes {
+				if c == cc {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+	}
+	for _, a := range s.attrs {
+		v, found := attrs[a.name]
+		if !found || (a.hasValue && v != a.value) {
+			return false
+		}
+	}
+	return true
+}
+
+func isSelectorIdentChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == ':'
+}
+
+func parseElementSelector(s string) (elementSelector, error) {
+	var sel elementSelector
+
+	invalid := func() (elementSelector, error) {
+		return elementSelector{}, errors.Errorf("minify: unsupported preserve element selector %q", s)
+	}
+
+	ident := func(i int) (string, int) {
+		start := i
+		for i < len(s) && isSelectorIdentChar(s[i]) {
+			i++
+		}
+		return s[start:i], i
+	}
+
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return invalid()
+	}
+
+	for i := 0; i < len(s); {
+		var v string
+		switch s[i] {
+		case '#':
+			v, i = ident(i + 1)
+			if v == "" {
+				return invalid()
+			}
+			sel.id = v
+		case '.':
+			v, i = ident(i + 1)
+			if v == "" {
+				return invalid()
+			}
+			sel.classes = append(sel.classes, v)
+		case '[':
+			end := strings.IndexByte(s[i:], ']')
+			if end == -1 {
+				return invalid()
+			}
+			inner := s[i+1 : i+end]
+			i += end + 1
+			var a attrSelector
+			if eq := strings.IndexByte(inner, '='); eq != -1 {
+				a.name, a.value, a.hasValue = inner[:eq], strings.Trim(inner[eq+1:], `"'`), true
+			} else {
+				a.name = inner
+			}
+			a.name = strings.ToLower(strings.TrimSpace(a.name))
+			if a.name == "" {
+				return invalid()
+			}
+			sel.attrs = append(sel.attrs, a)
+		default:
+			if i != 0 {
+				return invalid()
+			}
+			v, i = ident(i)
+			if v == "" {
+				return invalid()
+			}
+			sel.tag = strings.ToLower(v)
+		}
+	}
+
+	return sel, nil
+}
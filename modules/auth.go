@@ -0,0 +1,224 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modules
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/gohugoio/hugo/common/loggers"
+	"github.com/gohugoio/hugo/config"
+	"github.com/pkg/errors"
+)
+
+// The default username used for token authentication. Both GitHub and
+// GitLab accept this in combination with a personal access token.
+const defaultAuthUsername = "oauth2"
+
+// Auth holds the credentials for a host serving private modules, either
+// a Git host (e.g. "github.com") or a module proxy (e.g. "proxy.example.org").
+//
+// The credentials are passed on to Git as an HTTP Authorization header.
+// For module proxies they are written to a temporary netrc file that only
+// exists while the Go command runs, so they never show up in GOPROXY, in
+// Go's error messages or in the logs.
+type Auth struct {
+	// The host name, e.g. "github.com".
+	Host string
+
+	// The username to use. Defaults to "oauth2".
+	Username string
+
+	// The token or password to use.
+	// Prefer TokenEnv, you should not store secrets in your config files.
+	Token string
+
+	// The name of an environment variable holding the token, e.g. "GITHUB_TOKEN".
+	TokenEnv string
+}
+
+func (a Auth) credentials() (string, string, error) {
+	username := a.Username
+	if username == "" {
+		username = defaultAuthUsername
+	}
+
+	token := a.Token
+	if a.TokenEnv != "" {
+		token = os.Getenv(a.TokenEnv)
+		if token == "" {
+			return "", "", errors.Errorf("module.auth: environment variable %q for host %q is not set", a.TokenEnv, a.Host)
+		}
+	}
+
+	if token == "" {
+		return "", "", errors.Errorf("module.auth: no token configured for host %q", a.Host)
+	}
+
+	return username, token, nil
+}
+
+// matchesHost reports whether this Auth applies to the given host, with
+// or without port.
+func (a Auth) matchesHost(host string) bool {
+	if strings.EqualFold(a.Host, host) {
+		return true
+	}
+	if i := strings.LastIndex(host, ":"); i != -1 {
+		return strings.EqualFold(a.Host, host[:i])
+	}
+	return false
+}
+
+type credentials struct {
+	username, token string
+}
+
+func validateAuth(auths []Auth) error {
+	for _, a := range auths {
+		if a.Host == "" {
+			return errors.New("module.auth: host must be set")
+		}
+		if strings.Contains(a.Host, "/") {
+			return errors.Errorf("module.auth: invalid host %q; must be a host name, e.g. \"github.com\"", a.Host)
+		}
+	}
+	return nil
+}
+
+// applyAuth adds the credentials in auths to the environment used when
+// running the Go command and returns the netrc entries for the hosts in
+// proxy, see writeNetrc.
+// Hosts with missing credentials are skipped with a warning.
+func applyAuth(env *[]string, proxy string, auths []Auth, logger loggers.Logger) string {
+	if len(auths) == 0 {
+		return ""
+	}
+
+	resolved := make(map[int]credentials)
+	for i, a := range auths {
+		username, token, err := a.credentials()
+		if err != nil {
+			logger.Warnln(err)
+			continue
+		}
+		resolved[i] = credentials{username: username, token: token}
+	}
+
+	// Git >= 2.31 supports configuration via environment variables.
+	// This allows us to set the Authorization header without touching
+	// the user's Git config. Preserve any config set by the user.
+	var count int
+	for _, v := range *env {
+		if k, v := config.SplitEnvVar(v); k == "GIT_CONFIG_COUNT" {
+			count, _ = strconv.Atoi(v)
+		}
+	}
+
+	var keyValues []string
+	for i, a := range auths {
+		cred, ok := resolved[i]
+		if !ok {
+			continue
+		}
+		basic := base64.StdEncoding.EncodeToString([]byte(cred.username + ":" + cred.token))
+		keyValues = append(keyValues,
+			"GIT_CONFIG_KEY_"+strconv.Itoa(count), "http.https://"+a.Host+"/.extraHeader",
+			"GIT_CONFIG_VALUE_"+strconv.Itoa(count), "Authorization: Basic "+basic,
+		)
+		count++
+	}
+
+	if len(keyValues) > 0 {
+		config.SetEnvVars(env, append(keyValues, "GIT_CONFIG_COUNT", strconv.Itoa(count))...)
+	}
+
+	// The Go command looks up proxy credentials by host, including any port.
+	// GOPROXY is a list separated by either "," or "|".
+	var b strings.Builder
+	seen := make(map[string]bool)
+	for _, p := range strings.FieldsFunc(proxy, func(r rune) bool { return r == ',' || r == '|' }) {
+		u, err := url.Parse(strings.TrimSpace(p))
+		if err != nil || u.Host == "" || u.User != nil || seen[u.Host] {
+			continue
+		}
+		for i, a := range auths {
+			cred, ok := resolved[i]
+			if !ok || !a.matchesHost(u.Host) {
+				continue
+			}
+			seen[u.Host] = true
+			fmt.Fprintf(&b, "machine %s login %s password %s\n", u.Host, cred.username, cred.token)
+			break
+		}
+	}
+
+	return b.String()
+}
+
+// writeNetrc writes the netrc entries to a new temporary file followed by
+// the user's own netrc file, if any, so that file is still used for
+// other hosts. It returns the environment to use with NETRC pointing to
+// the new file and a func to remove it.
+func writeNetrc(env []string, entries string) ([]string, func(), error) {
+	f, err := ioutil.TempFile("", "hugo-netrc")
+	if err != nil {
+		return nil, nil, err
+	}
+	remove := func() {
+		os.Remove(f.Name())
+	}
+
+	var user string
+	for _, v := range env {
+		if k, v := config.SplitEnvVar(v); k == "NETRC" {
+			user = v
+		}
+	}
+	if user == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			name := ".netrc"
+			if runtime.GOOS == "windows" {
+				name = "_netrc"
+			}
+			user = filepath.Join(home, name)
+		}
+	}
+
+	_, err = f.WriteString(entries)
+	if err == nil && user != "" {
+		if b, rerr := ioutil.ReadFile(user); rerr == nil {
+			_, err = f.Write(b)
+		}
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		remove()
+		return nil, nil, errors.Wrap(err, "failed to write netrc file")
+	}
+
+	netrcEnv := append([]string(nil), env...)
+	config.SetEnvVars(&netrcEnv, "NETRC", f.Name())
+
+	return netrcEnv, remove, nil
+}
@@ -29,6 +29,7 @@ import (
 	"time"
 
 	"github.com/gohugoio/hugo/common/hexec"
+	"github.com/gohugoio/hugo/config/security"
 
 	hglob "github.com/gohugoio/hugo/hugofs/glob"
 
@@ -79,9 +80,16 @@ func NewClient(cfg ClientConfig) *Client {
 		goModFilename = n
 	}
 
+	logger := cfg.Logger
+	if logger == nil {
+		logger = loggers.NewWarningLogger()
+	}
+
 	env := os.Environ()
 	mcfg := cfg.ModuleConfig
 
+	netrc := applyAuth(&env, mcfg.Proxy, mcfg.Auth, logger)
+
 	config.SetEnvVars(&env,
 		"PWD", cfg.WorkingDir,
 		"GO111MODULE", "on",
@@ -94,11 +102,6 @@ func NewClient(cfg ClientConfig) *Client {
 		config.SetEnvVars(&env, "GOPATH", cfg.CacheDir)
 	}
 
-	logger := cfg.Logger
-	if logger == nil {
-		logger = loggers.NewWarningLogger()
-	}
-
 	var noVendor glob.Glob
 	if cfg.ModuleConfig.NoVendor != "" {
 		noVendor, _ = hglob.GetGlob(hglob.NormalizePath(cfg.ModuleConfig.NoVendor))
@@ -111,6 +114,7 @@ func NewClient(cfg ClientConfig) *Client {
 		noVendor:          noVendor,
 		moduleConfig:      mcfg,
 		environ:           env,
+		netrc:             netrc,
 		GoModulesFilename: goModFilename,
 	}
 }
@@ -130,6 +134,9 @@ type Client struct {
 	// Environment variables used in "go get" etc.
 	environ []string
 
+	// The netrc entries with the credentials for the module proxies.
+	netrc string
+
 	// Set when Go modules are initialized in the current repo, that is:
 	// a go.mod file exists.
 	GoModulesFilename string
@@ -139,6 +146,9 @@ type Client struct {
 	// so we can give an instructional error at the end if module/theme
 	// resolution fails.
 	goBinaryStatus goBinaryStatus
+
+	// The URL mounts collected, see RefreshURLMounts.
+	urlMounts []Mount
 }
 
 // Graph writes a module dependenchy graph to the given writer.
@@ -178,6 +188,10 @@ func (c *Client) Tidy() error {
 		return coll.err
 	}
 
+	if err := coll.checkVersionConstraints(); err != nil {
+		return err
+	}
+
 	if coll.skipTidy {
 		return nil
 	}
@@ -248,6 +262,10 @@ func (c *Client) Vendor() error {
 		dir := t.Dir()
 
 		for _, mount := range t.Mounts() {
+			if filepath.IsAbs(mount.Source) {
+				// Output from module hooks, they will be re-generated.
+				continue
+			}
 			sourceFilename := filepath.Join(dir, mount.Source)
 			targetFilename := filepath.Join(vendorDir, t.Path(), mount.Source)
 			fi, err := c.fs.Stat(sourceFilename)
@@ -551,6 +569,14 @@ func (c *Client) runGo(
 	}
 
 	cmd.Env = c.environ
+	if c.netrc != "" {
+		env, remove, err := writeNetrc(c.environ, c.netrc)
+		if err != nil {
+			return err
+		}
+		defer remove()
+		cmd.Env = env
+	}
 	cmd.Dir = c.ccfg.WorkingDir
 	cmd.Stdout = stdout
 	cmd.Stderr = io.MultiWriter(stderr, os.Stderr)
@@ -658,6 +684,13 @@ type ClientConfig struct {
 
 	CacheDir     string // Module cache
 	ModuleConfig Config
+
+	// The directory below which module hooks store their output.
+	// If not set, module hooks are disabled.
+	HooksDir string
+
+	// The security policy used when running module hooks.
+	Security security.Config
 }
 
 func (c ClientConfig) shouldIgnoreVendor(path string) bool {
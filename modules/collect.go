@@ -32,6 +32,7 @@ import (
 	"github.com/gohugoio/hugo/common/hugo"
 	"github.com/gohugoio/hugo/parser/metadecoders"
 
+	"github.com/gohugoio/hugo/hugofs"
 	"github.com/gohugoio/hugo/hugofs/files"
 
 	"github.com/rogpeppe/go-internal/module"
@@ -71,6 +72,10 @@ func (h *Client) Collect() (ModulesConfig, error) {
 		return mc, coll.err
 	}
 
+	if err := coll.checkVersionConstraints(); err != nil {
+		return mc, err
+	}
+
 	if err := (&mc).setActiveMods(h.logger); err != nil {
 		return mc, err
 	}
@@ -127,7 +132,7 @@ func (m *ModulesConfig) setActiveMods(logger loggers.Logger) error {
 	var activeMods Modules
 	for _, mod := range m.AllModules {
 		if !mod.Config().HugoVersion.IsValid() {
-			logger.Warnf(`Module %q is not compatible with this Hugo version; run "hugo mod graph" for more information.`, mod.Path())
+			logger.Warnf(`Module %q is not compatible with this Hugo version: it requires Hugo %s, this is Hugo %s; run "hugo mod graph" for more information.`, mod.Path(), mod.Config().HugoVersion, hugo.CurrentVersion.Version())
 		}
 		if !mod.Disabled() {
 			activeMods = append(activeMods, mod)
@@ -149,13 +154,14 @@ func (m *ModulesConfig) finalize(logger loggers.Logger) error {
 
 func filterUnwantedMounts(mounts []Mount) []Mount {
 	// Remove duplicates
-	seen := make(map[Mount]bool)
+	seen := make(map[string]bool)
 	tmp := mounts[:0]
 	for _, m := range mounts {
-		if !seen[m] {
+		key := m.key()
+		if !seen[key] {
 			tmp = append(tmp, m)
 		}
-		seen[m] = true
+		seen[key] = true
 	}
 	return tmp
 }
@@ -174,6 +180,9 @@ type collected struct {
 	// Ordered list of collected modules, including Go Modules and theme
 	// components stored below /themes.
 	modules Modules
+
+	// Maps module path to the version constraints set on its imports.
+	versionConstraints map[string][]versionRequiredBy
 }
 
 // Collects and creates a module tree.
@@ -337,6 +346,10 @@ func (c *collector) addAndRecurse(owner *moduleAdapter, disabled bool) error {
 	for _, moduleImport := range moduleConfig.Imports {
 		disabled := disabled || moduleImport.Disable
 
+		if err := c.addVersionConstraint(owner, moduleImport); err != nil {
+			return err
+		}
+
 		if !c.isSeen(moduleImport.Path) {
 			tc, err := c.add(owner, moduleImport, disabled)
 			if err != nil {
@@ -378,17 +391,28 @@ func (c *collector) applyMounts(moduleImport Import, mod *moduleAdapter) error {
 		}
 	}
 
-	var err error
+	mounts, remoteMounts, err := c.resolveRemoteMounts(mod, mounts)
+	if err != nil {
+		return err
+	}
+
 	mounts, err = c.normalizeMounts(mod, mounts)
 	if err != nil {
 		return err
 	}
+	mounts = append(mounts, remoteMounts...)
 
 	mounts, err = c.mountCommonJSConfig(mod, mounts)
 	if err != nil {
 		return err
 	}
 
+	hookMounts, err := c.hookMounts(mod)
+	if err != nil {
+		return err
+	}
+	mounts = append(mounts, hookMounts...)
+
 	mod.mounts = mounts
 	return nil
 }
@@ -653,6 +677,10 @@ func (c *collector) normalizeMounts(owner *moduleAdapter, mounts []Mount) ([]Mou
 			return nil, errors.Errorf("%s: mount target must be one of: %v", errMsg, files.ComponentFolders)
 		}
 
+		if mnt.Symlinks, err = hugofs.ParseSymlinkPolicy(mnt.Symlinks); err != nil {
+			return nil, errors.Wrap(err, errMsg)
+		}
+
 		out = append(out, mnt)
 	}
 
@@ -17,10 +17,12 @@ import (
 	"fmt"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 
 	"github.com/gohugoio/hugo/common/hugo"
+	"github.com/gohugoio/hugo/config/configschema"
 
 	"github.com/gohugoio/hugo/config"
 	"github.com/gohugoio/hugo/hugofs/files"
@@ -208,7 +210,17 @@ func decodeConfig(cfg config.Provider, pathReplacements map[string]string) (Conf
 
 	if moduleSet {
 		m := cfg.GetStringMap("module")
-		if err := mapstructure.WeakDecode(m, &c); err != nil {
+		dec, err := mapstructure.NewDecoder(
+			&mapstructure.DecoderConfig{
+				WeaklyTypedInput: true,
+				Result:           &c,
+				DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
+			},
+		)
+		if err != nil {
+			return c, err
+		}
+		if err := dec.Decode(m); err != nil {
 			return c, err
 		}
 
@@ -243,12 +255,32 @@ func decodeConfig(cfg config.Provider, pathReplacements map[string]string) (Conf
 			}
 		}
 
+		for _, imp := range c.Imports {
+			if imp.Version == "" {
+				continue
+			}
+			if _, err := ParseVersionConstraint(imp.Version); err != nil {
+				return c, errors.Wrapf(err, "module.imports: %q", imp.Path)
+			}
+		}
+
 		for i, mnt := range c.Mounts {
 			mnt.Source = filepath.Clean(mnt.Source)
 			mnt.Target = filepath.Clean(mnt.Target)
 			c.Mounts[i] = mnt
 		}
 
+		if err := validateAuth(c.Auth); err != nil {
+			return c, err
+		}
+
+		if c.Hooks != nil {
+			var err error
+			if c.Hooks, err = validateHooks(c.Hooks); err != nil {
+				return c, err
+			}
+		}
+
 	}
 
 	if themeSet {
@@ -272,6 +304,11 @@ type Config struct {
 	// Meta info about this module (license information etc.).
 	Params map[string]interface{}
 
+	// The path, relative to the module directory, to a JSON Schema file
+	// describing the site params this module reads. It is added to the
+	// schema generated by "hugo gen configschema".
+	ParamsSchema string
+
 	// Will be validated against the running Hugo version.
 	HugoVersion HugoVersion
 
@@ -295,6 +332,18 @@ type Config struct {
 	NoProxy string
 	// Configures GOPRIVATE.
 	Private string
+
+	// Commands to run before or after the build, e.g. to generate assets.
+	Hooks []Hook
+
+	// Glob patterns matching the paths of the modules whose hooks can run,
+	// e.g. "github.com/bep/*". The hooks of the main project always run.
+	// This is only used in the main project.
+	AllowHooks []string
+
+	// Credentials for hosts serving private modules.
+	// This is only used in the main project.
+	Auth []Auth
 }
 
 // hasModuleImport reports whether the project config have one or more
@@ -370,6 +419,10 @@ type Import struct {
 	NoVendor            bool   // Never vendor this import (only allowed in main project).
 	Disable             bool   // Turn off this module.
 	Mounts              []Mount
+
+	// Version constraint for the imported module, e.g. "^v1.2.0" or
+	// ">= v1.2.0, < v2.0.0". Checked on build and "hugo mod tidy".
+	Version string
 }
 
 type Mount struct {
@@ -378,6 +431,54 @@ type Mount struct {
 
 	Lang string // any language code associated with this mount.
 
+	// Include only files matching the given Glob patterns (string or slice).
+	// The patterns are matched against the path relative to the mount source,
+	// e.g. "**.scss".
+	IncludeFiles []string
+
+	// Exclude all files matching the given Glob patterns (string or slice).
+	ExcludeFiles []string
+
+	// Fetch Source from a remote, either a Git repository, e.g.
+	// "https://github.com/bep/docs.git", or an object storage prefix, e.g.
+	// "s3://mybucket?region=us-west-1". The remote is cached in the module cache.
+	Remote string
+
+	// The Git ref (branch, tag or commit) to check out. Defaults to HEAD.
+	Ref string
+
+	// Fetch a single data file from an HTTP(S) URL, e.g. a JSON, YAML or
+	// CSV API endpoint, into Target, e.g. "data/prices.json". The format is
+	// given by the Target's extension. The file is cached in the module cache.
+	URL string
+
+	// How often to fetch URL again, e.g. "1h". The default is to fetch it
+	// once; run "hugo mod clean --all" to refresh it.
+	Refresh time.Duration
+
+	// HTTP headers to send with the URL request. Environment variables in
+	// the values are expanded, e.g. "Bearer ${API_TOKEN}". Only allowed in
+	// the project's mounts.
+	Headers map[string]string
+
+	// How to handle symlinks in this mount, one of "follow", "followFiles",
+	// "ignore" or "error". The default is to follow symlinks in the project
+	// (files only in static) and to ignore them in modules.
+	Symlinks string
+}
+
+// key returns a string uniquely identifying this mount.
+func (m Mount) key() string {
+	return strings.Join([]string{
+		m.Lang,
+		m.Remote,
+		m.Ref,
+		m.Source,
+		m.Target,
+		m.Symlinks,
+		strings.Join(m.IncludeFiles, ","),
+		strings.Join(m.ExcludeFiles, ","),
+	}, "/")
 }
 
 func (m Mount) Component() string {
@@ -407,3 +508,7 @@ func getStringOrStringSlice(cfg config.Provider, key string, id int) []string {
 
 	return config.GetStringSlicePreserveString(cfg, key)
 }
+
+func init() {
+	configschema.AddSection("module", DefaultModuleConfig)
+}
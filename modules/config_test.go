@@ -15,6 +15,7 @@ package modules
 
 import (
 	"testing"
+	"time"
 
 	"github.com/gohugoio/hugo/common/hugo"
 
@@ -92,6 +93,25 @@ lang="en"
 		c.Assert(imp.Mounts[1].Lang, qt.Equals, "en")
 	})
 
+	c.Run("URL mounts", func(c *qt.C) {
+		cfg, err := config.FromConfigString(`
+[[module.mounts]]
+url = "https://api.example.org/prices"
+target = "data/prices.json"
+refresh = "15m"
+[module.mounts.headers]
+Authorization = "Bearer ${API_TOKEN}"
+`, "toml")
+		c.Assert(err, qt.IsNil)
+
+		mcfg, err := DecodeConfig(cfg)
+		c.Assert(err, qt.IsNil)
+		c.Assert(mcfg.Mounts, qt.HasLen, 1)
+		c.Assert(mcfg.Mounts[0].URL, qt.Equals, "https://api.example.org/prices")
+		c.Assert(mcfg.Mounts[0].Refresh, qt.Equals, 15*time.Minute)
+		c.Assert(mcfg.Mounts[0].Headers, qt.DeepEquals, map[string]string{"Authorization": "Bearer ${API_TOKEN}"})
+	})
+
 	c.Run("Replacements", func(c *qt.C) {
 		for _, tomlConfig := range []string{`
 [module]
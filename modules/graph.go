@@ -0,0 +1,176 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modules
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// The formats supported by GraphFormat.
+const (
+	GraphFormatText = "text"
+	GraphFormatDot  = "dot"
+	GraphFormatJSON = "json"
+)
+
+// GraphNode describes a module in the dependency graph.
+type GraphNode struct {
+	Path     string       `json:"path"`
+	Version  string       `json:"version,omitempty"`
+	Owner    string       `json:"owner,omitempty"`
+	Dir      string       `json:"dir,omitempty"`
+	Vendor   bool         `json:"vendor,omitempty"`
+	Disabled bool         `json:"disabled,omitempty"`
+	Replace  *GraphNode   `json:"replace,omitempty"`
+	Mounts   []GraphMount `json:"mounts,omitempty"`
+}
+
+// GraphMount describes a mount in the dependency graph.
+type GraphMount struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Lang   string `json:"lang,omitempty"`
+}
+
+// GraphFormat writes a module dependency graph to the given writer in the
+// given format, one of "text", "dot" or "json".
+func (c *Client) GraphFormat(w io.Writer, format string) error {
+	switch strings.ToLower(format) {
+	case "", GraphFormatText:
+		return c.Graph(w)
+	case GraphFormatDot:
+		nodes, err := c.graphNodes()
+		if err != nil {
+			return err
+		}
+		return writeGraphDot(w, nodes)
+	case GraphFormatJSON:
+		nodes, err := c.graphNodes()
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			Modules []GraphNode `json:"modules"`
+		}{
+			Modules: nodes,
+		})
+	default:
+		return errors.Errorf("unsupported graph format %q; must be one of %q, %q or %q", format, GraphFormatText, GraphFormatDot, GraphFormatJSON)
+	}
+}
+
+func (c *Client) graphNodes() ([]GraphNode, error) {
+	mc, coll := c.collect(true)
+	if coll.err != nil {
+		return nil, coll.err
+	}
+
+	nodes := make([]GraphNode, 0, len(mc.AllModules))
+	for _, module := range mc.AllModules {
+		node := newGraphNode(module)
+		if owner := module.Owner(); owner != nil {
+			node.Owner = pathVersion(owner)
+		}
+		if replace := module.Replace(); replace != nil {
+			r := newGraphNode(replace)
+			node.Replace = &r
+		}
+		for _, m := range module.Mounts() {
+			node.Mounts = append(node.Mounts, GraphMount{Source: m.Source, Target: m.Target, Lang: m.Lang})
+		}
+		nodes = append(nodes, node)
+	}
+
+	return nodes, nil
+}
+
+func newGraphNode(m Module) GraphNode {
+	return GraphNode{
+		Path:     m.Path(),
+		Version:  m.Version(),
+		Dir:      m.Dir(),
+		Vendor:   m.Vendor(),
+		Disabled: m.Disabled(),
+	}
+}
+
+func writeGraphDot(w io.Writer, nodes []GraphNode) error {
+	// This must match pathVersion.
+	id := func(n GraphNode) string {
+		versionStr := n.Version
+		if n.Vendor {
+			versionStr += "+vendor"
+		}
+		if versionStr == "" {
+			return strconv.Quote(n.Path)
+		}
+		return strconv.Quote(n.Path + "@" + versionStr)
+	}
+
+	fmt.Fprintln(w, "digraph modules {")
+	fmt.Fprintln(w, "\tnode [shape=box];")
+
+	for _, n := range nodes {
+		var attrs []string
+		label := n.Path
+		if n.Version != "" {
+			label += "\\n" + n.Version
+		}
+		if n.Replace != nil {
+			if n.Replace.Version != "" {
+				label += "\\n=> " + n.Replace.Path + "@" + n.Replace.Version
+			} else {
+				label += "\\n=> " + n.Replace.Dir
+			}
+		}
+		mounts := make([]string, len(n.Mounts))
+		for i, m := range n.Mounts {
+			mounts[i] = m.Source + " -> " + m.Target
+		}
+		if len(mounts) > 0 {
+			attrs = append(attrs, "tooltip="+strconv.Quote(strings.Join(mounts, "\n")))
+		}
+		attrs = append(attrs, "label=\""+strings.ReplaceAll(label, `"`, `\"`)+"\"")
+		if n.Owner == "" {
+			attrs = append(attrs, "style=bold")
+		}
+		if n.Disabled {
+			attrs = append(attrs, "style=dashed", "color=gray")
+		}
+		fmt.Fprintf(w, "\t%s [%s];\n", id(n), strings.Join(attrs, ", "))
+	}
+
+	for _, n := range nodes {
+		if n.Owner == "" {
+			continue
+		}
+		var attrs string
+		if n.Disabled {
+			attrs = " [style=dashed]"
+		}
+		fmt.Fprintf(w, "\t%s -> %s%s;\n", strconv.Quote(n.Owner), id(n), attrs)
+	}
+
+	fmt.Fprintln(w, "}")
+
+	return nil
+}
@@ -0,0 +1,52 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modules
+
+import (
+	"bytes"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/spf13/afero"
+)
+
+func TestWriteGraphDot(t *testing.T) {
+	c := qt.New(t)
+
+	nodes := []GraphNode{
+		{Path: "project"},
+		{Path: "github.com/bep/a", Version: "v1.0.0", Owner: "project", Mounts: []GraphMount{{Source: "layouts", Target: "layouts"}}},
+		{Path: "github.com/bep/b", Version: "v1.1.0", Owner: "github.com/bep/a@v1.0.0", Vendor: true, Disabled: true},
+		{Path: "github.com/bep/c", Version: "v1.2.0", Owner: "project", Replace: &GraphNode{Path: "github.com/bep/c", Dir: "/work/c"}},
+	}
+
+	var b bytes.Buffer
+	c.Assert(writeGraphDot(&b, nodes), qt.IsNil)
+
+	got := b.String()
+
+	c.Assert(got, qt.Contains, `digraph modules {`)
+	c.Assert(got, qt.Contains, `"project" [label="project", style=bold];`)
+	c.Assert(got, qt.Contains, `"github.com/bep/a@v1.0.0" [tooltip="layouts -> layouts", label="github.com/bep/a\nv1.0.0"];`)
+	c.Assert(got, qt.Contains, `"project" -> "github.com/bep/a@v1.0.0";`)
+	c.Assert(got, qt.Contains, `"github.com/bep/a@v1.0.0" -> "github.com/bep/b@v1.1.0+vendor" [style=dashed];`)
+	c.Assert(got, qt.Contains, `label="github.com/bep/c\nv1.2.0\n=> /work/c"`)
+}
+
+func TestGraphFormatInvalid(t *testing.T) {
+	c := qt.New(t)
+	client := NewClient(ClientConfig{Fs: afero.NewMemMapFs(), WorkingDir: "/nonexisting"})
+	err := client.GraphFormat(&bytes.Buffer{}, "xml")
+	c.Assert(err, qt.ErrorMatches, `unsupported graph format "xml".*`)
+}
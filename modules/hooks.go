@@ -0,0 +1,400 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modules
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gohugoio/hugo/common/hexec"
+	hglob "github.com/gohugoio/hugo/hugofs/glob"
+
+	"github.com/gohugoio/hugo/hugofs/files"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+// The build stages a module hook can run in.
+const (
+	HookStagePre  = "pre"
+	HookStagePost = "post"
+)
+
+// The name of the environment variables made available to hooks.
+const (
+	HookEnvModuleDir = "HUGO_MODULE_DIR"
+	HookEnvOutputDir = "HUGO_HOOK_OUTPUT_DIR"
+)
+
+// Hook is a command declared by a module to be run before or after a build,
+// e.g. to generate assets.
+//
+// The command and its expanded arguments must be allowed by the security
+// policy (security.exec.allow and security.exec.commands) and it is run with
+// its own output directory as the working directory and an OS environment
+// filtered by security.exec.osEnv, or the command's own osEnv if set.
+// Hooks in other modules than the main project only run if enabled in its
+// module.allowHooks config.
+type Hook struct {
+	// A name unique within the module, e.g. "icons".
+	Name string
+
+	// When to run the hook, "pre" (before the build) or "post" (after the build).
+	// Defaults to "pre".
+	Stage string
+
+	// The command and its arguments, e.g. "svgo" and ["-f", "$HUGO_MODULE_DIR/icons"].
+	// Environment variables in Args are expanded.
+	Command string
+	Args    []string
+
+	// Glob patterns relative to the module's directory, e.g. "icons/**.svg".
+	// If set, the hook is only re-run when any of the matched files change.
+	// If not set, the hook runs on every build.
+	Sources []string
+
+	// The mount target for the files generated by a "pre" hook, e.g. "assets/icons".
+	Target string
+}
+
+func (h Hook) isPre() bool {
+	return h.Stage == HookStagePre
+}
+
+func validateHooks(hooks []Hook) ([]Hook, error) {
+	seen := make(map[string]bool)
+	for i, h := range hooks {
+		if h.Name == "" {
+			return nil, errors.New("module.hooks: name must be set")
+		}
+		if strings.ContainsAny(h.Name, `/\`) {
+			return nil, errors.Errorf("module.hooks: invalid name %q", h.Name)
+		}
+		if seen[h.Name] {
+			return nil, errors.Errorf("module.hooks: duplicate hook name %q", h.Name)
+		}
+		seen[h.Name] = true
+
+		if h.Command == "" {
+			return nil, errors.Errorf("module.hooks: command must be set for hook %q", h.Name)
+		}
+
+		h.Stage = strings.ToLower(h.Stage)
+		if h.Stage == "" {
+			h.Stage = HookStagePre
+		}
+		if h.Stage != HookStagePre && h.Stage != HookStagePost {
+			return nil, errors.Errorf("module.hooks: invalid stage %q for hook %q; must be %q or %q", h.Stage, h.Name, HookStagePre, HookStagePost)
+		}
+
+		if h.Target != "" {
+			if !h.isPre() {
+				return nil, errors.Errorf("module.hooks: target is only supported for %q hooks (hook %q)", HookStagePre, h.Name)
+			}
+			h.Target = filepath.Clean(h.Target)
+			if !files.IsComponentFolder(strings.Split(h.Target, fileSeparator)[0]) {
+				return nil, errors.Errorf("module.hooks: target for hook %q must be one of: %v", h.Name, files.ComponentFolders)
+			}
+		}
+
+		hooks[i] = h
+	}
+
+	return hooks, nil
+}
+
+// hooksAllowed reports whether the hooks declared by m can run. Hooks in
+// other modules than the main project must be enabled in its
+// module.allowHooks config.
+func (c *Client) hooksAllowed(m Module) bool {
+	if m.Owner() == nil {
+		return true
+	}
+	for _, pattern := range c.ccfg.ModuleConfig.AllowHooks {
+		g, err := hglob.GetGlob(pattern)
+		if err == nil && g.Match(strings.ToLower(m.Path())) {
+			return true
+		}
+	}
+	return false
+}
+
+// hookOutputDir returns the working and output directory for the given hook.
+func (c *Client) hookOutputDir(m Module, h Hook) string {
+	return filepath.Join(c.ccfg.HooksDir, filepath.FromSlash(strings.ToLower(m.Path())), h.Name)
+}
+
+// hookMounts creates the mounts for the output of m's pre hooks.
+func (c *Client) hookMounts(m Module) ([]Mount, error) {
+	if c.ccfg.HooksDir == "" {
+		return nil, nil
+	}
+
+	if !c.hooksAllowed(m) {
+		return nil, nil
+	}
+
+	var mounts []Mount
+	for _, h := range m.Config().Hooks {
+		if h.Target == "" {
+			continue
+		}
+		dir := c.hookOutputDir(m, h)
+		// Make sure it exists so it can be mounted before the first build.
+		if err := c.fs.MkdirAll(dir, 0777); err != nil {
+			return nil, err
+		}
+		mounts = append(mounts, Mount{Source: dir, Target: h.Target})
+	}
+
+	return mounts, nil
+}
+
+// RunHooks runs the hooks for the given stage in the active modules,
+// dependencies first. The key/value pairs in env are added to the
+// environment of every hook.
+func (c *Client) RunHooks(ctx context.Context, stage string, mods Modules, env ...string) error {
+	if c.ccfg.HooksDir == "" {
+		return nil
+	}
+
+	if stage == HookStagePre {
+		if err := c.removeStaleHookOutput(mods); err != nil {
+			return err
+		}
+	}
+
+	for i := len(mods) - 1; i >= 0; i-- {
+		m := mods[i]
+		if len(m.Config().Hooks) > 0 && !c.hooksAllowed(m) {
+			c.logger.Infof("hugo: skip hooks in %q, enable them with module.allowHooks in the project config", m.Path())
+			continue
+		}
+		for _, h := range m.Config().Hooks {
+			if h.Stage != stage {
+				continue
+			}
+			if err := c.runHook(ctx, m, h, env); err != nil {
+				return errors.Wrapf(err, "module %q: hook %q", m.Path(), h.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *Client) runHook(ctx context.Context, m Module, h Hook, env []string) error {
+	if err := c.ccfg.Security.CheckAllowedExec(h.Command); err != nil {
+		return err
+	}
+
+	dir := c.hookOutputDir(m, h)
+	hashFilename := dir + ".hash"
+
+	var hash string
+	if len(h.Sources) > 0 {
+		var err error
+		hash, err = c.hookHash(m, h)
+		if err != nil {
+			return err
+		}
+		if b, err := afero.ReadFile(c.fs, hashFilename); err == nil && string(b) == hash {
+			c.logger.Infof("hugo: skip hook %q in %q, sources not changed", h.Name, m.Path())
+			return nil
+		}
+	}
+
+	// Start with an empty directory, so no output from earlier runs is left.
+	if err := c.fs.Remove(hashFilename); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := c.fs.RemoveAll(dir); err != nil {
+		return err
+	}
+	if err := c.fs.MkdirAll(dir, 0777); err != nil {
+		return err
+	}
+
+	hookEnv := append(c.ccfg.Security.FilterOsEnvFor(h.Command, os.Environ()),
+		HookEnvModuleDir+"="+m.Dir(),
+		HookEnvOutputDir+"="+dir,
+	)
+	for i := 0; i+1 < len(env); i += 2 {
+		hookEnv = append(hookEnv, env[i]+"="+env[i+1])
+	}
+
+	args := make([]string, len(h.Args))
+	for i, arg := range h.Args {
+		args[i] = os.Expand(arg, func(k string) string {
+			for j := len(hookEnv) - 1; j >= 0; j-- {
+				if v := hookEnv[j]; strings.HasPrefix(v, k+"=") {
+					return v[len(k)+1:]
+				}
+			}
+			return ""
+		})
+	}
+
+	if err := c.ccfg.Security.CheckAllowedExec(h.Command, args...); err != nil {
+		return err
+	}
+
+	cmd, err := hexec.SafeCommandContext(ctx, h.Command, args...)
+	if err != nil {
+		return err
+	}
+
+	stderr := new(bytes.Buffer)
+	cmd.Dir = dir
+	cmd.Env = hookEnv
+	cmd.Stdout = c.logger.Out()
+	cmd.Stderr = io.MultiWriter(stderr, c.logger.Out())
+
+	defer c.logger.PrintTimerIfDelayed(time.Now(), "hugo: ran hook "+h.Name)
+
+	if err := cmd.Run(); err != nil {
+		return errors.Errorf("failed to run %q: %s: %s", h.Command, err, strings.TrimSpace(stderr.String()))
+	}
+
+	if hash != "" {
+		return afero.WriteFile(c.fs, hashFilename, []byte(hash), 0666)
+	}
+
+	return nil
+}
+
+// removeStaleHookOutput removes the output of hooks that are no longer
+// declared by, or allowed to run in, the active modules.
+func (c *Client) removeStaleHookOutput(mods Modules) error {
+	keep := make(map[string]bool)
+	for _, m := range mods {
+		if !c.hooksAllowed(m) {
+			continue
+		}
+		for _, h := range m.Config().Hooks {
+			dir := c.hookOutputDir(m, h)
+			keep[dir] = true
+			keep[dir+".hash"] = true
+			// Keep the parent directories.
+			for d := filepath.Dir(dir); len(d) > len(c.ccfg.HooksDir); d = filepath.Dir(d) {
+				keep[d] = true
+			}
+		}
+	}
+
+	var stale []string
+	err := afero.Walk(c.fs, c.ccfg.HooksDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if path == c.ccfg.HooksDir {
+			return nil
+		}
+		if !keep[path] {
+			stale = append(stale, path)
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() && keep[path+".hash"] {
+			// A hook's output directory.
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, path := range stale {
+		if err := c.fs.RemoveAll(path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// hookHash creates a hash of the hook definition and the files matching its sources.
+func (c *Client) hookHash(m Module, h Hook) (string, error) {
+	hasher := sha256.New()
+	io.WriteString(hasher, h.Command+"\x00"+strings.Join(h.Args, "\x00")+"\x00")
+
+	dir := m.Dir()
+
+	filenames := make(map[string]string)
+	for _, pattern := range h.Sources {
+		g, err := hglob.GetGlob(hglob.NormalizePath(pattern))
+		if err != nil {
+			return "", err
+		}
+		err = afero.Walk(c.fs, dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				if info.Name() == "node_modules" || info.Name() == ".git" {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			rel = hglob.NormalizePath(rel)
+			if g.Match(rel) {
+				filenames[rel] = path
+			}
+			return nil
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+
+	rels := make([]string, 0, len(filenames))
+	for rel := range filenames {
+		rels = append(rels, rel)
+	}
+	sort.Strings(rels)
+
+	for _, rel := range rels {
+		f, err := c.fs.Open(filenames[rel])
+		if err != nil {
+			return "", err
+		}
+		io.WriteString(hasher, rel+"\x00")
+		_, err = io.Copy(hasher, f)
+		f.Close()
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
@@ -0,0 +1,161 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modules
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/gohugoio/hugo/config/security"
+	"github.com/gohugoio/hugo/htesting"
+	"github.com/gohugoio/hugo/hugofs"
+	"github.com/spf13/afero"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestValidateHooks(t *testing.T) {
+	c := qt.New(t)
+
+	hooks, err := validateHooks([]Hook{
+		{Name: "a", Command: "npx", Target: "assets/a"},
+		{Name: "b", Command: "npx", Stage: "POST"},
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(hooks[0].Stage, qt.Equals, HookStagePre)
+	c.Assert(hooks[1].Stage, qt.Equals, HookStagePost)
+
+	for _, invalid := range [][]Hook{
+		{{Command: "npx"}},
+		{{Name: "a"}},
+		{{Name: "a", Command: "npx"}, {Name: "a", Command: "npx"}},
+		{{Name: "a", Command: "npx", Stage: "during"}},
+		{{Name: "a", Command: "npx", Stage: "post", Target: "assets"}},
+		{{Name: "a", Command: "npx", Target: "foo/bar"}},
+	} {
+		_, err := validateHooks(invalid)
+		c.Assert(err, qt.Not(qt.IsNil), qt.Commentf("%v", invalid))
+	}
+}
+
+func TestRunHooks(t *testing.T) {
+	c := qt.New(t)
+
+	workDir, clean, err := htesting.CreateTempDir(hugofs.Os, "hugo-modules-hooks")
+	c.Assert(err, qt.IsNil)
+	defer clean()
+
+	fs := hugofs.Os
+	modDir := filepath.Join(workDir, "mymod")
+	c.Assert(fs.MkdirAll(filepath.Join(modDir, "src"), 0777), qt.IsNil)
+	c.Assert(afero.WriteFile(fs, filepath.Join(modDir, "src", "a.txt"), []byte("a"), 0666), qt.IsNil)
+
+	hooksDir := filepath.Join(workDir, "hooks")
+
+	client := NewClient(ClientConfig{
+		Fs:         fs,
+		WorkingDir: workDir,
+		HooksDir:   hooksDir,
+		Security:   security.DefaultConfig,
+	})
+
+	hook := Hook{Name: "gen", Stage: HookStagePre, Command: "go", Args: []string{"env", "GOROOT"}, Sources: []string{"src/**"}, Target: "assets/gen"}
+	mod := &moduleAdapter{path: "github.com/bep/mymod", dir: modDir, config: Config{Hooks: []Hook{hook}}}
+
+	mounts, err := client.hookMounts(mod)
+	c.Assert(err, qt.IsNil)
+	outDir := filepath.Join(hooksDir, "github.com", "bep", "mymod", "gen")
+	c.Assert(mounts, qt.DeepEquals, []Mount{{Source: outDir, Target: filepath.FromSlash("assets/gen")}})
+
+	c.Assert(client.RunHooks(context.Background(), HookStagePre, Modules{mod}), qt.IsNil)
+	hash1, err := afero.ReadFile(fs, outDir+".hash")
+	c.Assert(err, qt.IsNil)
+	c.Assert(len(hash1), qt.Equals, 64)
+
+	c.Assert(afero.WriteFile(fs, filepath.Join(modDir, "src", "a.txt"), []byte("b"), 0666), qt.IsNil)
+	c.Assert(client.RunHooks(context.Background(), HookStagePre, Modules{mod}), qt.IsNil)
+	hash2, err := afero.ReadFile(fs, outDir+".hash")
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(hash2), qt.Not(qt.Equals), string(hash1))
+
+	// Not allowed by the security policy.
+	mod.config.Hooks = []Hook{{Name: "rm", Stage: HookStagePre, Command: "rm", Args: []string{"-rf", "/"}}}
+	err = client.RunHooks(context.Background(), HookStagePre, Modules{mod})
+	c.Assert(err, qt.Not(qt.IsNil))
+	c.Assert(security.IsAccessDenied(err), qt.IsTrue)
+
+	// Argument not allowed by the security policy.
+	client.ccfg.Security.Exec.Commands = map[string]security.ExecCommand{
+		"go": {Args: security.NewWhitelist("^env$")},
+	}
+	mod.config.Hooks = []Hook{{Name: "gen", Stage: HookStagePre, Command: "go", Args: []string{"env", "GOROOT"}}}
+	err = client.RunHooks(context.Background(), HookStagePre, Modules{mod})
+	c.Assert(security.IsAccessDenied(err), qt.IsTrue)
+}
+
+func TestRunHooksAllowAndCleanup(t *testing.T) {
+	c := qt.New(t)
+
+	workDir, clean, err := htesting.CreateTempDir(hugofs.Os, "hugo-modules-hooks")
+	c.Assert(err, qt.IsNil)
+	defer clean()
+
+	fs := hugofs.Os
+	hooksDir := filepath.Join(workDir, "hooks")
+
+	client := NewClient(ClientConfig{
+		Fs:         fs,
+		WorkingDir: workDir,
+		HooksDir:   hooksDir,
+		Security:   security.DefaultConfig,
+	})
+
+	project := &moduleAdapter{path: "project", dir: workDir, projectMod: true}
+	hook := Hook{Name: "gen", Stage: HookStagePre, Command: "go", Args: []string{"env", "GOROOT"}, Target: "assets/gen"}
+	theme := &moduleAdapter{path: "github.com/bep/mytheme", dir: workDir, owner: project, config: Config{Hooks: []Hook{hook}}}
+	outDir := filepath.Join(hooksDir, "github.com", "bep", "mytheme", "gen")
+
+	// Hooks in other modules must be enabled in the project.
+	mounts, err := client.hookMounts(theme)
+	c.Assert(err, qt.IsNil)
+	c.Assert(mounts, qt.HasLen, 0)
+	c.Assert(client.RunHooks(context.Background(), HookStagePre, Modules{project, theme}), qt.IsNil)
+	_, err = fs.Stat(outDir)
+	c.Assert(err, qt.Not(qt.IsNil))
+
+	client.ccfg.ModuleConfig.AllowHooks = []string{"github.com/bep/*"}
+	mounts, err = client.hookMounts(theme)
+	c.Assert(err, qt.IsNil)
+	c.Assert(mounts, qt.HasLen, 1)
+
+	// Output from earlier runs and removed hooks is cleaned up.
+	stale := filepath.Join(hooksDir, "github.com", "bep", "old", "gen")
+	c.Assert(fs.MkdirAll(stale, 0777), qt.IsNil)
+	c.Assert(afero.WriteFile(fs, stale+".hash", []byte("hash"), 0666), qt.IsNil)
+	c.Assert(afero.WriteFile(fs, filepath.Join(outDir, "old.txt"), []byte("old"), 0666), qt.IsNil)
+
+	c.Assert(client.RunHooks(context.Background(), HookStagePre, Modules{project, theme}), qt.IsNil)
+	_, err = fs.Stat(outDir)
+	c.Assert(err, qt.IsNil)
+	_, err = fs.Stat(filepath.Join(outDir, "old.txt"))
+	c.Assert(err, qt.Not(qt.IsNil))
+	_, err = fs.Stat(filepath.Join(hooksDir, "github.com", "bep", "old"))
+	c.Assert(err, qt.Not(qt.IsNil))
+
+	client.ccfg.ModuleConfig.AllowHooks = nil
+	c.Assert(client.RunHooks(context.Background(), HookStagePre, Modules{project, theme}), qt.IsNil)
+	_, err = fs.Stat(outDir)
+	c.Assert(err, qt.Not(qt.IsNil))
+}
@@ -0,0 +1,263 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modules
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"unicode"
+
+	"github.com/gohugoio/hugo/parser/metadecoders"
+	"github.com/spf13/afero"
+	"github.com/spf13/cast"
+)
+
+// Common license filenames, lower case and without extension.
+var licenseFilenames = map[string]bool{
+	"license":     true,
+	"licence":     true,
+	"copying":     true,
+	"unlicense":   true,
+	"license-mit": true,
+	"mit-license": true,
+}
+
+// licenseMatchers is an ordered list of SPDX identifiers and the phrases
+// that must all be present in the (normalized) license text for a match.
+// The GNU licenses and the MPL refer to each other, so these are matched on
+// their title lines, which include the version and date, before the notices
+// that are often used instead of the full text.
+var licenseMatchers = []struct {
+	spdx    string
+	phrases []string
+}{
+	{"AGPL-3.0", []string{"gnu affero general public license version 3, 19 november 2007"}},
+	{"LGPL-3.0", []string{"gnu lesser general public license version 3, 29 june 2007"}},
+	{"LGPL-2.1", []string{"gnu lesser general public license version 2.1, february 1999"}},
+	{"GPL-3.0", []string{"gnu general public license version 3, 29 june 2007"}},
+	{"GPL-2.0", []string{"gnu general public license version 2, june 1991"}},
+	{"MPL-2.0", []string{"mozilla public license version 2.0"}},
+	{"AGPL-3.0", []string{"under the terms of the gnu affero general public license as published by the free software foundation, either version 3"}},
+	{"LGPL-3.0", []string{"under the terms of the gnu lesser general public license as published by the free software foundation, either version 3"}},
+	{"GPL-3.0", []string{"under the terms of the gnu general public license as published by the free software foundation, either version 3"}},
+	{"GPL-2.0", []string{"under the terms of the gnu general public license as published by the free software foundation; either version 2"}},
+	{"Apache-2.0", []string{"apache license", "version 2.0"}},
+	{"CC0-1.0", []string{"cc0 1.0 universal"}},
+	{"CC-BY-SA-4.0", []string{"attribution-sharealike 4.0 international"}},
+	{"CC-BY-4.0", []string{"attribution 4.0 international"}},
+	{"Unlicense", []string{"free and unencumbered software released into the public domain"}},
+	{"WTFPL", []string{"do what the fuck you want to public license"}},
+	{"ISC", []string{"permission to use, copy, modify, and/or distribute this software for any purpose with or without fee is hereby granted"}},
+	{"BSD-3-Clause", []string{"redistribution and use in source and binary forms", "neither the name"}},
+	{"BSD-2-Clause", []string{"redistribution and use in source and binary forms"}},
+	{"MIT", []string{"permission is hereby granted, free of charge"}},
+}
+
+// License holds license information about a module.
+type License struct {
+	Module Module
+
+	// The license file relative to the module's directory.
+	// This is empty if no license file could be found.
+	Filename string
+
+	// Our best guess of the SPDX identifier for the license file.
+	// This is empty if we could not detect the license.
+	SPDX string
+
+	// Any license declared in the module's configuration (e.g. "license" in theme.toml).
+	Declared string
+}
+
+// Unknown reports whether we could not determine the license of the module.
+func (l License) Unknown() bool {
+	return l.SPDX == ""
+}
+
+// Licenses writes a license report for all the modules in the dependency
+// graph to the given writer.
+func (c *Client) Licenses(w io.Writer) error {
+	licenses, err := c.CollectLicenses()
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "MODULE\tSPDX\tDECLARED\tFILE")
+	for _, l := range licenses {
+		spdx := l.SPDX
+		if l.Unknown() {
+			spdx = "UNKNOWN"
+		}
+		if l.Module.Disabled() {
+			spdx += " (DISABLED)"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", pathVersion(l.Module), spdx, valueOrDash(l.Declared), valueOrDash(l.Filename))
+	}
+
+	return tw.Flush()
+}
+
+// CollectLicenses detects the license of every module in the dependency
+// graph, the main project excluded.
+func (c *Client) CollectLicenses() ([]License, error) {
+	mc, coll := c.collect(true)
+	if coll.err != nil {
+		return nil, coll.err
+	}
+
+	var licenses []License
+
+	for _, module := range mc.AllModules {
+		if module.Owner() == nil {
+			continue
+		}
+
+		l, err := detectLicense(c.fs, module.Dir())
+		if err != nil {
+			return nil, err
+		}
+
+		l.Module = module
+		l.Declared = declaredLicense(c.fs, module)
+		if l.SPDX == "" && l.Declared != "" {
+			l.SPDX = guessSPDX(l.Declared)
+		}
+
+		licenses = append(licenses, l)
+	}
+
+	return licenses, nil
+}
+
+func detectLicense(fs afero.Fs, dir string) (License, error) {
+	var l License
+
+	fis, err := afero.ReadDir(fs, dir)
+	if err != nil {
+		return l, err
+	}
+
+	var candidates []string
+	for _, fi := range fis {
+		if fi.IsDir() {
+			continue
+		}
+		name := fi.Name()
+		base := strings.ToLower(strings.TrimSuffix(name, filepath.Ext(name)))
+		if licenseFilenames[base] {
+			candidates = append(candidates, name)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return l, nil
+	}
+
+	sort.Strings(candidates)
+
+	for _, name := range candidates {
+		b, err := afero.ReadFile(fs, filepath.Join(dir, name))
+		if err != nil {
+			return l, err
+		}
+
+		if spdx := guessSPDX(string(b)); spdx != "" {
+			l.Filename = name
+			l.SPDX = spdx
+			return l, nil
+		}
+	}
+
+	// We found a license file, but don't know what's in it.
+	l.Filename = candidates[0]
+
+	return l, nil
+}
+
+// declaredLicense returns the license declared in the module's theme.toml,
+// falling back to a "license" param in the module's configuration.
+func declaredLicense(fs afero.Fs, m Module) string {
+	if b, err := afero.ReadFile(fs, filepath.Join(m.Dir(), "theme.toml")); err == nil {
+		// Any errors in theme.toml are reported when the modules are collected.
+		if tc, err := metadecoders.Default.UnmarshalToMap(b, metadecoders.TOML); err == nil {
+			if s := strings.TrimSpace(cast.ToString(tc["license"])); s != "" {
+				return s
+			}
+		}
+	}
+
+	params := m.Config().Params
+	if params == nil {
+		return ""
+	}
+	return strings.TrimSpace(cast.ToString(params["license"]))
+}
+
+// guessSPDX tries to guess the SPDX identifier from the given license text
+// or short license name.
+func guessSPDX(s string) string {
+	s = normalizeLicenseText(s)
+	if s == "" {
+		return ""
+	}
+
+	// A declared license may already be an SPDX identifier, e.g. "MIT".
+	for _, m := range licenseMatchers {
+		if s == strings.ToLower(m.spdx) {
+			return m.spdx
+		}
+	}
+
+	for _, m := range licenseMatchers {
+		found := true
+		for _, phrase := range m.phrases {
+			if !strings.Contains(s, normalizeLicenseText(phrase)) {
+				found = false
+				break
+			}
+		}
+		if found {
+			return m.spdx
+		}
+	}
+
+	// Some license text, e.g. the MIT license, is often just prefixed with its name.
+	if strings.HasPrefix(s, "mit license") || strings.HasPrefix(s, "the mit license") {
+		return "MIT"
+	}
+
+	return ""
+}
+
+// normalizeLicenseText lower cases s and replaces any punctuation, except
+// the dots in version numbers, and whitespace with a single space, as the
+// copies of the same license vary in both.
+func normalizeLicenseText(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return r != '.' && r != '-' && !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+func valueOrDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
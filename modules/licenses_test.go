@@ -0,0 +1,117 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modules
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/spf13/afero"
+)
+
+func TestGuessSPDX(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range []struct {
+		in     string
+		expect string
+	}{
+		{"MIT", "MIT"},
+		{"apache-2.0", "Apache-2.0"},
+		{"The MIT License (MIT)\n\nCopyright (c) 2021 Foo", "MIT"},
+		{"Permission is hereby granted, free of charge, to any person obtaining a copy", "MIT"},
+		{"Apache License\n   Version 2.0, January 2004", "Apache-2.0"},
+		{"GNU LESSER GENERAL PUBLIC LICENSE\n Version 3, 29 June 2007", "LGPL-3.0"},
+		{"GNU GENERAL PUBLIC LICENSE\n Version 3, 29 June 2007", "GPL-3.0"},
+		{"GNU GENERAL PUBLIC LICENSE\n Version 2, June 1991", "GPL-2.0"},
+		{"Redistribution and use in source and binary forms, with or without modification. Neither the name of", "BSD-3-Clause"},
+		{"Redistribution and use in source and binary forms, with or without modification.", "BSD-2-Clause"},
+		{"GNU AFFERO GENERAL PUBLIC LICENSE\n Version 3, 19 November 2007", "AGPL-3.0"},
+		{"Mozilla Public License, version 2.0", "MPL-2.0"},
+		{"This program is free software: you can redistribute it and/or modify\nit under the terms of the GNU Affero General Public License as published by\nthe Free Software Foundation, either version 3 of the License", "AGPL-3.0"},
+		{"This program is free software: you can redistribute it and/or modify\nit under the terms of the GNU General Public License as published by\nthe Free Software Foundation, either version 3 of the License", "GPL-3.0"},
+		{"All rights reserved.", ""},
+		{"", ""},
+	} {
+		c.Assert(guessSPDX(test.in), qt.Equals, test.expect, qt.Commentf(test.in))
+	}
+}
+
+func TestGuessSPDXFullText(t *testing.T) {
+	c := qt.New(t)
+
+	filenames, err := filepath.Glob(filepath.Join("testdata", "licenses", "*.txt"))
+	c.Assert(err, qt.IsNil)
+	c.Assert(len(filenames) > 0, qt.IsTrue)
+
+	for _, filename := range filenames {
+		b, err := ioutil.ReadFile(filename)
+		c.Assert(err, qt.IsNil)
+		expect := strings.TrimSuffix(filepath.Base(filename), ".txt")
+		c.Assert(guessSPDX(string(b)), qt.Equals, expect, qt.Commentf(filename))
+	}
+}
+
+func TestDetectLicense(t *testing.T) {
+	c := qt.New(t)
+	fs := afero.NewMemMapFs()
+
+	write := func(filename, content string) {
+		c.Assert(afero.WriteFile(fs, filename, []byte(content), 0666), qt.IsNil)
+	}
+
+	write(filepath.Join("a", "LICENSE.md"), "The MIT License")
+	write(filepath.Join("a", "README.md"), "GNU GENERAL PUBLIC LICENSE Version 3")
+	write(filepath.Join("b", "COPYING"), "All rights reserved.")
+	write(filepath.Join("c", "README.md"), "No license.")
+
+	l, err := detectLicense(fs, "a")
+	c.Assert(err, qt.IsNil)
+	c.Assert(l.Filename, qt.Equals, "LICENSE.md")
+	c.Assert(l.SPDX, qt.Equals, "MIT")
+	c.Assert(l.Unknown(), qt.IsFalse)
+
+	l, err = detectLicense(fs, "b")
+	c.Assert(err, qt.IsNil)
+	c.Assert(l.Filename, qt.Equals, "COPYING")
+	c.Assert(l.Unknown(), qt.IsTrue)
+
+	l, err = detectLicense(fs, "c")
+	c.Assert(err, qt.IsNil)
+	c.Assert(l.Filename, qt.Equals, "")
+	c.Assert(l.Unknown(), qt.IsTrue)
+}
+
+func TestDeclaredLicense(t *testing.T) {
+	c := qt.New(t)
+	fs := afero.NewMemMapFs()
+
+	c.Assert(afero.WriteFile(fs, filepath.Join("mytheme", "theme.toml"), []byte(`
+name = "My Theme"
+license = "MIT"
+licenselink = "https://github.com/example/mytheme/blob/master/LICENSE"
+description = "A theme."
+min_version = "0.80.0"
+
+[author]
+  name = "Hugo Author"
+`), 0666), qt.IsNil)
+
+	c.Assert(declaredLicense(fs, &moduleAdapter{dir: "mytheme"}), qt.Equals, "MIT")
+	c.Assert(declaredLicense(fs, &moduleAdapter{dir: "nothemetoml"}), qt.Equals, "")
+	c.Assert(declaredLicense(fs, &moduleAdapter{dir: "nothemetoml", config: Config{Params: map[string]interface{}{"license": " Apache-2.0 "}}}), qt.Equals, "Apache-2.0")
+}
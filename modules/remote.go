@@ -0,0 +1,250 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modules
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gohugoio/hugo/common/hexec"
+	"github.com/gohugoio/hugo/hugofs"
+	"github.com/gohugoio/hugo/hugofs/files"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+// The directory below the module cache where remote mounts are stored.
+const remoteMountsDir = "_remote"
+
+// The URL schemes for remote mounts fetched from object storage.
+// Any other remote is assumed to be a Git repository.
+var blobRemoteSchemes = map[string]bool{
+	"s3": true,
+	"gs": true,
+}
+
+func isBlobRemote(remote string) bool {
+	u, err := url.Parse(remote)
+	return err == nil && blobRemoteSchemes[u.Scheme]
+}
+
+// remoteMountDir returns the directory in the module cache that holds the
+// fetched content for the given remote mount.
+func (c *Client) remoteMountDir(m Mount) string {
+	hasher := sha256.New()
+	fmt.Fprintf(hasher, "%s\x00%s\x00%s", m.Remote, m.Ref, filepath.ToSlash(m.Source))
+	return filepath.Join(c.ccfg.CacheDir, remoteMountsDir, hex.EncodeToString(hasher.Sum(nil))[:16])
+}
+
+// resolveRemoteMounts fetches the remote mounts in mounts (if not already
+// cached) and returns the local and the resolved remote mounts. The Source
+// of a resolved remote mount is an absolute path in the module cache.
+func (c *collector) resolveRemoteMounts(owner *moduleAdapter, mounts []Mount) ([]Mount, []Mount, error) {
+	var local, remote []Mount
+
+	for _, mnt := range mounts {
+		if mnt.URL != "" {
+			resolved, err := c.resolveURLMount(owner, mnt)
+			if err != nil {
+				return nil, nil, err
+			}
+			remote = append(remote, resolved)
+			continue
+		}
+
+		if mnt.Remote == "" {
+			local = append(local, mnt)
+			continue
+		}
+
+		errMsg := fmt.Sprintf("invalid module config for %q", owner.Path())
+
+		if mnt.Target == "" {
+			return nil, nil, errors.New(errMsg + ": target must be set")
+		}
+		mnt.Target = filepath.Clean(mnt.Target)
+		if !files.IsComponentFolder(strings.Split(mnt.Target, fileSeparator)[0]) {
+			return nil, nil, errors.Errorf("%s: mount target must be one of: %v", errMsg, files.ComponentFolders)
+		}
+
+		if mnt.Source == "" {
+			mnt.Source = "."
+		}
+		var err error
+		if mnt.Symlinks, err = hugofs.ParseSymlinkPolicy(mnt.Symlinks); err != nil {
+			return nil, nil, errors.Wrap(err, errMsg)
+		}
+
+		mnt.Source = filepath.Clean(mnt.Source)
+		if filepath.IsAbs(mnt.Source) || strings.HasPrefix(mnt.Source, "..") {
+			return nil, nil, errors.Errorf("%s: the source of remote mount %q must be a relative path", errMsg, mnt.Remote)
+		}
+
+		dir, err := c.fetchRemoteMount(context.Background(), mnt)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "%s: failed to fetch remote mount %q", errMsg, mnt.Remote)
+		}
+
+		mnt.Source = dir
+		remote = append(remote, mnt)
+	}
+
+	return local, remote, nil
+}
+
+// fetchRemoteMount fetches the remote mount m into the module cache and
+// returns the absolute path to its source directory.
+// Remotes are fetched once; run "hugo mod clean --all" to refresh them.
+func (c *Client) fetchRemoteMount(ctx context.Context, m Mount) (string, error) {
+	if c.ccfg.CacheDir == "" {
+		return "", errors.New("remote mounts require a module cache")
+	}
+
+	dir := c.remoteMountDir(m)
+	sourceDir := filepath.Join(dir, m.Source)
+
+	if exists, _ := afero.DirExists(c.fs, dir); exists {
+		return sourceDir, nil
+	}
+
+	if err := c.checkAllowedRemote(m.Remote); err != nil {
+		return "", err
+	}
+
+	// Fetch into a temporary directory to avoid leaving a partial
+	// checkout in the cache on failure.
+	tmpDir := dir + ".tmp"
+	if err := c.fs.RemoveAll(tmpDir); err != nil {
+		return "", err
+	}
+	if err := c.fs.MkdirAll(tmpDir, 0777); err != nil {
+		return "", err
+	}
+
+	defer c.logger.PrintTimerIfDelayed(time.Now(), "hugo: fetched remote mount "+m.Remote)
+
+	var err error
+	if isBlobRemote(m.Remote) {
+		err = fetchBlobRemote(ctx, c.fs, m.Remote, m.Source, filepath.Join(tmpDir, m.Source))
+	} else {
+		err = c.fetchGitRemote(ctx, m, tmpDir)
+	}
+	if err != nil {
+		c.fs.RemoveAll(tmpDir)
+		return "", err
+	}
+
+	if err := c.fs.Rename(tmpDir, dir); err != nil {
+		return "", err
+	}
+
+	return sourceDir, nil
+}
+
+// checkAllowedRemote checks remotes fetched over HTTP(S) against the HTTP
+// security policy. Other remotes, e.g. Git over SSH, are not checked.
+func (c *Client) checkAllowedRemote(remote string) error {
+	u, err := url.Parse(remote)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil
+	}
+	return c.ccfg.Security.CheckAllowedHTTP("GET", u)
+}
+
+// fetchGitRemote does a shallow, sparse checkout of m.Source in the Git
+// repository m.Remote at m.Ref (default HEAD) into dir.
+func (c *Client) fetchGitRemote(ctx context.Context, m Mount, dir string) error {
+	if err := c.ccfg.Security.CheckAllowedExec("git"); err != nil {
+		return err
+	}
+
+	ref := m.Ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+
+	// Both end up as arguments to git, so make sure neither can be
+	// interpreted as an option, e.g. --upload-pack.
+	if strings.HasPrefix(m.Remote, "-") {
+		return errors.Errorf("invalid remote %q", m.Remote)
+	}
+	if strings.HasPrefix(ref, "-") {
+		return errors.Errorf("invalid ref %q for remote %s", ref, m.Remote)
+	}
+
+	git := func(args ...string) error {
+		if err := c.ccfg.Security.CheckAllowedExec("git", args...); err != nil {
+			return err
+		}
+		cmd, err := hexec.SafeCommandContext(ctx, "git", args...)
+		if err != nil {
+			return err
+		}
+		stderr := new(bytes.Buffer)
+		cmd.Dir = dir
+		cmd.Env = c.environ
+		cmd.Stderr = stderr
+		if err := cmd.Run(); err != nil {
+			return errors.Errorf("git %s: %s: %s", args[0], err, strings.TrimSpace(stderr.String()))
+		}
+		return nil
+	}
+
+	if err := git("init", "-q"); err != nil {
+		return err
+	}
+	if err := git("remote", "add", "--end-of-options", "origin", m.Remote); err != nil {
+		return err
+	}
+
+	if m.Source != "." {
+		if err := git("config", "core.sparseCheckout", "true"); err != nil {
+			return err
+		}
+		sparse := "/" + filepath.ToSlash(m.Source) + "/\n"
+		if err := afero.WriteFile(c.fs, filepath.Join(dir, ".git", "info", "sparse-checkout"), []byte(sparse), 0666); err != nil {
+			return err
+		}
+	}
+
+	if err := git("fetch", "-q", "--depth", "1", "--filter=blob:none", "--end-of-options", "origin", ref); err != nil {
+		return err
+	}
+	if err := git("checkout", "-q", "FETCH_HEAD"); err != nil {
+		return err
+	}
+
+	// We only need the files.
+	if err := c.fs.RemoveAll(filepath.Join(dir, ".git")); err != nil {
+		return err
+	}
+
+	if _, err := c.fs.Stat(filepath.Join(dir, m.Source)); err != nil {
+		if os.IsNotExist(err) {
+			return errors.Errorf("source %q not found in %s@%s", m.Source, m.Remote, ref)
+		}
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !nodeploy
+
+package modules
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+	"gocloud.dev/blob"
+	_ "gocloud.dev/blob/gcsblob" // import
+	_ "gocloud.dev/blob/s3blob"  // import
+)
+
+// fetchBlobRemote downloads all objects below source in the bucket given by
+// remote, e.g. "s3://mybucket?region=us-west-1", into dir.
+func fetchBlobRemote(ctx context.Context, fs afero.Fs, remote, source, dir string) error {
+	u, err := url.Parse(remote)
+	if err != nil {
+		return err
+	}
+
+	// Allow the prefix to be set in the URL path, e.g. "gs://mybucket/docs".
+	prefix := strings.Trim(u.Path, "/")
+	u.Path = ""
+
+	if source != "." {
+		prefix = strings.Trim(prefix+"/"+filepath.ToSlash(source), "/")
+	}
+
+	bucket, err := blob.OpenBucket(ctx, u.String())
+	if err != nil {
+		return err
+	}
+	defer bucket.Close()
+
+	return copyBucketPrefix(ctx, fs, bucket, prefix, dir)
+}
+
+func copyBucketPrefix(ctx context.Context, fs afero.Fs, bucket *blob.Bucket, prefix, dir string) error {
+	if prefix != "" {
+		prefix += "/"
+	}
+
+	if err := fs.MkdirAll(dir, 0777); err != nil {
+		return err
+	}
+
+	iter := bucket.List(&blob.ListOptions{Prefix: prefix})
+	for {
+		obj, err := iter.Next(ctx)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if obj.IsDir {
+			continue
+		}
+
+		rel := strings.TrimPrefix(obj.Key, prefix)
+		if rel == "" || strings.Contains("/"+rel+"/", "/../") {
+			continue
+		}
+
+		if err := copyBucketObject(ctx, fs, bucket, obj.Key, filepath.Join(dir, filepath.FromSlash(rel))); err != nil {
+			return err
+		}
+	}
+}
+
+func copyBucketObject(ctx context.Context, fs afero.Fs, bucket *blob.Bucket, key, filename string) error {
+	r, err := bucket.NewReader(ctx, key, nil)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	if err := fs.MkdirAll(filepath.Dir(filename), 0777); err != nil {
+		return err
+	}
+
+	f, err := fs.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(f, r)
+	return err
+}
@@ -0,0 +1,61 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !nodeploy
+
+package modules
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/spf13/afero"
+	"gocloud.dev/blob/memblob"
+)
+
+func TestCopyBucketPrefix(t *testing.T) {
+	c := qt.New(t)
+	ctx := context.Background()
+
+	bucket := memblob.OpenBucket(nil)
+	defer bucket.Close()
+
+	for key, content := range map[string]string{
+		"docs/a.md":     "a",
+		"docs/sub/b.md": "b",
+		"docsother/c.m": "c",
+		"other/d.md":    "d",
+	} {
+		c.Assert(bucket.WriteAll(ctx, key, []byte(content), nil), qt.IsNil)
+	}
+
+	fs := afero.NewMemMapFs()
+	c.Assert(copyBucketPrefix(ctx, fs, bucket, "docs", "out"), qt.IsNil)
+
+	var filenames []string
+	c.Assert(afero.Walk(fs, "out", func(path string, info os.FileInfo, err error) error {
+		if !info.IsDir() {
+			filenames = append(filenames, filepath.ToSlash(path))
+		}
+		return err
+	}), qt.IsNil)
+
+	c.Assert(filenames, qt.DeepEquals, []string{"out/a.md", "out/sub/b.md"})
+
+	b, err := afero.ReadFile(fs, filepath.Join("out", "sub", "b.md"))
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, "b")
+}
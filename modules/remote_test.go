@@ -0,0 +1,132 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modules
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/gohugoio/hugo/config/security"
+	"github.com/gohugoio/hugo/htesting"
+	"github.com/gohugoio/hugo/hugofs"
+	"github.com/spf13/afero"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestIsBlobRemote(t *testing.T) {
+	c := qt.New(t)
+
+	c.Assert(isBlobRemote("s3://mybucket?region=us-west-1"), qt.IsTrue)
+	c.Assert(isBlobRemote("gs://mybucket/docs"), qt.IsTrue)
+	c.Assert(isBlobRemote("https://github.com/bep/docs.git"), qt.IsFalse)
+	c.Assert(isBlobRemote("git@github.com:bep/docs.git"), qt.IsFalse)
+}
+
+func TestFetchGitRemoteMount(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	c := qt.New(t)
+
+	workDir, clean, err := htesting.CreateTempDir(hugofs.Os, "hugo-modules-remote")
+	c.Assert(err, qt.IsNil)
+	defer clean()
+
+	fs := hugofs.Os
+
+	// Create a Git repository to fetch from.
+	repoDir := filepath.Join(workDir, "repo")
+	c.Assert(fs.MkdirAll(filepath.Join(repoDir, "docs"), 0777), qt.IsNil)
+	c.Assert(fs.MkdirAll(filepath.Join(repoDir, "other"), 0777), qt.IsNil)
+	c.Assert(afero.WriteFile(fs, filepath.Join(repoDir, "docs", "a.md"), []byte("a"), 0666), qt.IsNil)
+	c.Assert(afero.WriteFile(fs, filepath.Join(repoDir, "other", "b.md"), []byte("b"), 0666), qt.IsNil)
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "."},
+		{"-c", "user.name=hugo", "-c", "user.email=hugo@example.org", "commit", "-q", "-m", "init"},
+		{"tag", "v1.0.0"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoDir
+		out, err := cmd.CombinedOutput()
+		c.Assert(err, qt.IsNil, qt.Commentf("%s", out))
+	}
+
+	client := NewClient(ClientConfig{
+		Fs:         fs,
+		WorkingDir: workDir,
+		CacheDir:   filepath.Join(workDir, "cache"),
+		Security:   security.DefaultConfig,
+	})
+
+	m := Mount{Remote: "file://" + filepath.ToSlash(repoDir), Ref: "v1.0.0", Source: "docs", Target: "content"}
+	dir, err := client.fetchRemoteMount(context.Background(), m)
+	c.Assert(err, qt.IsNil)
+	c.Assert(dir, qt.Equals, filepath.Join(client.remoteMountDir(m), "docs"))
+
+	b, err := afero.ReadFile(fs, filepath.Join(dir, "a.md"))
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, "a")
+
+	// Sparse checkout.
+	exists, _ := afero.Exists(fs, filepath.Join(client.remoteMountDir(m), "other"))
+	c.Assert(exists, qt.IsFalse)
+
+	// Cached.
+	c.Assert(fs.RemoveAll(repoDir), qt.IsNil)
+	dir2, err := client.fetchRemoteMount(context.Background(), m)
+	c.Assert(err, qt.IsNil)
+	c.Assert(dir2, qt.Equals, dir)
+
+	m.Ref = "v2.0.0"
+	_, err = client.fetchRemoteMount(context.Background(), m)
+	c.Assert(err, qt.Not(qt.IsNil))
+}
+
+func TestFetchGitRemoteRejectsOptions(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	c := qt.New(t)
+
+	workDir, clean, err := htesting.CreateTempDir(hugofs.Os, "hugo-modules-remote")
+	c.Assert(err, qt.IsNil)
+	defer clean()
+
+	fs := hugofs.Os
+	marker := filepath.Join(workDir, "pwned")
+
+	client := NewClient(ClientConfig{
+		Fs:         fs,
+		WorkingDir: workDir,
+		CacheDir:   filepath.Join(workDir, "cache"),
+		Security:   security.DefaultConfig,
+	})
+
+	for _, m := range []Mount{
+		{Remote: "https://github.com/bep/docs.git", Ref: "--upload-pack=touch " + marker, Source: ".", Target: "content"},
+		{Remote: "--upload-pack=touch " + marker, Source: ".", Target: "content"},
+	} {
+		_, err := client.fetchRemoteMount(context.Background(), m)
+		c.Assert(err, qt.Not(qt.IsNil))
+		c.Assert(err.Error(), qt.Contains, "invalid")
+		exists, _ := afero.Exists(fs, marker)
+		c.Assert(exists, qt.IsFalse)
+	}
+}
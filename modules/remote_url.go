@@ -0,0 +1,191 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modules
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gohugoio/hugo/hugofs/files"
+	"github.com/gohugoio/hugo/parser/metadecoders"
+
+	"github.com/pkg/errors"
+)
+
+// The timeout for fetching a URL mount.
+const urlMountTimeout = 30 * time.Second
+
+// urlMountDir returns the directory in the module cache that holds the
+// fetched file for the given URL mount.
+func (c *Client) urlMountDir(m Mount) string {
+	hasher := sha256.New()
+	fmt.Fprintf(hasher, "%s\x00%s", m.URL, filepath.ToSlash(m.Target))
+	return filepath.Join(c.ccfg.CacheDir, remoteMountsDir, hex.EncodeToString(hasher.Sum(nil))[:16])
+}
+
+// resolveURLMount validates the URL mount mnt and fetches it, if not
+// already cached and fresh. The Source of the returned mount is the
+// directory in the module cache holding the file, the Target its
+// directory below /data.
+func (c *collector) resolveURLMount(owner *moduleAdapter, mnt Mount) (Mount, error) {
+	errMsg := fmt.Sprintf("invalid module config for %q", owner.Path())
+
+	if mnt.Remote != "" {
+		return mnt, errors.Errorf("%s: remote and url cannot both be set for mount %q", errMsg, mnt.URL)
+	}
+	u, err := url.Parse(mnt.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return mnt, errors.Errorf("%s: url %q must be an HTTP(S) URL", errMsg, mnt.URL)
+	}
+
+	mnt.Target = filepath.Clean(mnt.Target)
+	parts := strings.Split(mnt.Target, fileSeparator)
+	if len(parts) < 2 || parts[0] != files.ComponentFolderData {
+		return mnt, errors.Errorf("%s: the target of url mount %q must be a data file, e.g. \"data/prices.json\"", errMsg, mnt.URL)
+	}
+	if f := metadecoders.FormatFromString(filepath.Ext(mnt.Target)); f == "" || f == metadecoders.ORG {
+		return mnt, errors.Errorf("%s: the target %q of url mount %q has an unsupported data format", errMsg, mnt.Target, mnt.URL)
+	}
+	if len(mnt.Headers) > 0 && !owner.projectMod {
+		// The headers may hold secrets from the environment, so don't let
+		// other modules send them anywhere.
+		return mnt, errors.Errorf("%s: headers are only allowed in the url mounts of the project, not for %q", errMsg, mnt.URL)
+	}
+	if mnt.Refresh < 0 {
+		return mnt, errors.Errorf("%s: refresh for url mount %q must be >= 0", errMsg, mnt.URL)
+	}
+
+	if err := c.fetchURLMount(context.Background(), mnt, false); err != nil {
+		return mnt, errors.Wrapf(err, "%s: failed to fetch url mount %q", errMsg, mnt.URL)
+	}
+
+	c.urlMounts = append(c.urlMounts, mnt)
+
+	resolved := mnt
+	resolved.Source = c.urlMountDir(mnt)
+	resolved.Target = filepath.Dir(mnt.Target)
+
+	return resolved, nil
+}
+
+// fetchURLMount fetches the file for the URL mount m into the module cache
+// if it is not there or, if m.Refresh is set, older than m.Refresh.
+// If the fetch fails and a cached file exists, it is used with a warning,
+// unless refresh is set, where the cached file is kept silently and the
+// error returned.
+func (c *Client) fetchURLMount(ctx context.Context, m Mount, refresh bool) error {
+	if c.ccfg.CacheDir == "" {
+		return errors.New("url mounts require a module cache")
+	}
+
+	filename := filepath.Join(c.urlMountDir(m), filepath.Base(m.Target))
+
+	fi, err := c.fs.Stat(filename)
+	cached := err == nil
+	if cached && (m.Refresh == 0 || time.Since(fi.ModTime()) < m.Refresh) {
+		return nil
+	}
+
+	if err := c.downloadURLMount(ctx, m, filename); err != nil {
+		if cached && !refresh {
+			c.logger.Warnf("Failed to refresh url mount %q, using the cached file: %s", m.URL, err)
+			return nil
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (c *Client) downloadURLMount(ctx context.Context, m Mount, filename string) error {
+	u, err := url.Parse(m.URL)
+	if err != nil {
+		return err
+	}
+	if err := c.ccfg.Security.CheckAllowedHTTP("GET", u); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", m.URL, nil)
+	if err != nil {
+		return err
+	}
+	for k, v := range m.Headers {
+		req.Header.Set(k, os.ExpandEnv(v))
+	}
+
+	defer c.logger.PrintTimerIfDelayed(time.Now(), "hugo: fetched url mount "+m.URL)
+
+	res, err := c.ccfg.Security.NewHTTPClient(urlMountTimeout).Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return errors.Errorf("GET %s: %s", m.URL, res.Status)
+	}
+
+	if err := c.fs.MkdirAll(filepath.Dir(filename), 0777); err != nil {
+		return err
+	}
+
+	// Write to a temporary file and rename, so a file watcher
+	// never sees a partial file.
+	tmpFilename := filename + ".tmp"
+	f, err := c.fs.Create(tmpFilename)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, res.Body)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		c.fs.Remove(tmpFilename)
+		return err
+	}
+
+	return c.fs.Rename(tmpFilename, filename)
+}
+
+// RefreshURLMounts fetches the files for the URL mounts with a refresh
+// interval that are due, e.g. while running the server. The new files
+// are picked up by the file watcher.
+func (c *Client) RefreshURLMounts(ctx context.Context) error {
+	var errs []string
+	for _, m := range c.urlMounts {
+		if m.Refresh == 0 {
+			continue
+		}
+		if err := c.fetchURLMount(ctx, m, true); err != nil {
+			errs = append(errs, fmt.Sprintf("%q: %s", m.URL, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.Errorf("failed to refresh url mounts: %s", strings.Join(errs, "; "))
+	}
+
+	return nil
+}
@@ -0,0 +1,159 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modules
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gohugoio/hugo/common/loggers"
+	"github.com/gohugoio/hugo/config/security"
+	"github.com/spf13/afero"
+	jww "github.com/spf13/jwalterweatherman"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestFetchURLMount(t *testing.T) {
+	c := qt.New(t)
+
+	var (
+		calls   int32
+		failing int32
+		auth    atomic.Value
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if atomic.LoadInt32(&failing) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		auth.Store(r.Header.Get("Authorization"))
+		w.Write([]byte(`{"price": 32}`))
+	}))
+	defer srv.Close()
+
+	os.Setenv("HUGO_TEST_API_TOKEN", "secret")
+	defer os.Unsetenv("HUGO_TEST_API_TOKEN")
+
+	fs := afero.NewMemMapFs()
+	var logBuf bytes.Buffer
+	client := NewClient(ClientConfig{
+		Fs:         fs,
+		WorkingDir: "/my/project",
+		CacheDir:   "/my/cache",
+		Security:   security.DefaultConfig,
+		Logger:     loggers.NewBasicLoggerForWriter(jww.LevelWarn, &logBuf),
+	})
+	coll := &collector{Client: client}
+	owner := &moduleAdapter{path: "project", projectMod: true}
+
+	m := Mount{
+		URL:     srv.URL + "/prices",
+		Target:  "data/shop/prices.json",
+		Refresh: time.Hour,
+		Headers: map[string]string{"Authorization": "Bearer ${HUGO_TEST_API_TOKEN}"},
+	}
+
+	resolved, err := coll.resolveURLMount(owner, m)
+	c.Assert(err, qt.IsNil)
+	c.Assert(resolved.Source, qt.Equals, client.urlMountDir(m))
+	c.Assert(resolved.Target, qt.Equals, filepath.FromSlash("data/shop"))
+	c.Assert(atomic.LoadInt32(&calls), qt.Equals, int32(1))
+	c.Assert(auth.Load(), qt.Equals, "Bearer secret")
+
+	filename := filepath.Join(resolved.Source, "prices.json")
+	b, err := afero.ReadFile(fs, filename)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, `{"price": 32}`)
+
+	// Cached and fresh.
+	c.Assert(client.RefreshURLMounts(context.Background()), qt.IsNil)
+	c.Assert(atomic.LoadInt32(&calls), qt.Equals, int32(1))
+
+	// Due for a refresh.
+	old := time.Now().Add(-2 * time.Hour)
+	c.Assert(fs.Chtimes(filename, old, old), qt.IsNil)
+	c.Assert(client.RefreshURLMounts(context.Background()), qt.IsNil)
+	c.Assert(atomic.LoadInt32(&calls), qt.Equals, int32(2))
+
+	// The cached file is used if the refresh fails.
+	atomic.StoreInt32(&failing, 1)
+	c.Assert(fs.Chtimes(filename, old, old), qt.IsNil)
+	c.Assert(client.RefreshURLMounts(context.Background()), qt.ErrorMatches, `failed to refresh url mounts: .*500 Internal Server Error`)
+	_, err = coll.resolveURLMount(owner, m)
+	c.Assert(err, qt.IsNil)
+	c.Assert(logBuf.String(), qt.Contains, "using the cached file")
+	b, err = afero.ReadFile(fs, filename)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, `{"price": 32}`)
+
+	// Nothing cached.
+	_, err = coll.resolveURLMount(owner, Mount{URL: srv.URL + "/other", Target: "data/other.json"})
+	c.Assert(err, qt.ErrorMatches, `.*failed to fetch url mount.*500 Internal Server Error`)
+
+	for _, invalid := range []Mount{
+		{URL: "ftp://example.org/a.json", Target: "data/a.json"},
+		{URL: srv.URL, Target: "assets/a.json"},
+		{URL: srv.URL, Target: "data"},
+		{URL: srv.URL, Target: "data/a.exe"},
+		{URL: srv.URL, Remote: "https://github.com/bep/docs.git", Target: "data/a.json"},
+	} {
+		_, err := coll.resolveURLMount(owner, invalid)
+		c.Assert(err, qt.Not(qt.IsNil), qt.Commentf("%v", invalid))
+	}
+
+	// Other modules can't send headers, which may hold secrets.
+	theme := &moduleAdapter{path: "github.com/bep/mytheme"}
+	_, err = coll.resolveURLMount(theme, m)
+	c.Assert(err, qt.ErrorMatches, `.*headers are only allowed in the url mounts of the project.*`)
+	calls = 0
+	_, err = coll.resolveURLMount(theme, Mount{URL: srv.URL + "/theme", Target: "data/theme.json"})
+	c.Assert(err, qt.ErrorMatches, `.*500 Internal Server Error`)
+	c.Assert(atomic.LoadInt32(&calls), qt.Equals, int32(1))
+}
+
+func TestFetchURLMountRedirect(t *testing.T) {
+	c := qt.New(t)
+
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer target.Close()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, strings.Replace(target.URL, "127.0.0.1", "localhost", 1), http.StatusFound)
+	}))
+	defer srv.Close()
+
+	sc := security.DefaultConfig
+	sc.HTTP.Domains = security.NewWhitelist(`^127\.0\.0\.1$`)
+	client := NewClient(ClientConfig{
+		Fs:         afero.NewMemMapFs(),
+		WorkingDir: "/my/project",
+		CacheDir:   "/my/cache",
+		Security:   sc,
+	})
+	coll := &collector{Client: client}
+
+	_, err := coll.resolveURLMount(&moduleAdapter{path: "project", projectMod: true}, Mount{URL: srv.URL, Target: "data/a.json"})
+	c.Assert(err, qt.ErrorMatches, `.*"localhost" is not whitelisted in policy "security.http.domains".*`)
+}
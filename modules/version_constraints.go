@@ -0,0 +1,195 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modules
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/rogpeppe/go-internal/semver"
+)
+
+// VersionConstraint is a set of version requirements for a module, e.g.
+// ">= v1.2.0, < v2.0.0". All requirements must be satisfied.
+//
+// The supported operators are =, !=, >, >=, <, <=, ^ (same major version,
+// e.g. "^v1.2.0") and ~ (same minor version, e.g. "~v1.2.0").
+// A version without an operator must match exactly.
+type VersionConstraint struct {
+	constraint   string
+	requirements []versionRequirement
+}
+
+type versionRequirement struct {
+	op      string
+	version string
+}
+
+var versionConstraintOperators = []string{">=", "<=", "!=", ">", "<", "=", "^", "~"}
+
+// ParseVersionConstraint parses the given constraint, e.g. "^v1.2.0".
+func ParseVersionConstraint(constraint string) (VersionConstraint, error) {
+	vc := VersionConstraint{constraint: strings.TrimSpace(constraint)}
+
+	for _, part := range strings.Split(vc.constraint, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		op := "="
+		for _, candidate := range versionConstraintOperators {
+			if strings.HasPrefix(part, candidate) {
+				op = candidate
+				part = strings.TrimSpace(strings.TrimPrefix(part, candidate))
+				break
+			}
+		}
+
+		version := part
+		if !strings.HasPrefix(version, "v") {
+			version = "v" + version
+		}
+		if !semver.IsValid(version) {
+			return vc, errors.Errorf("invalid version constraint %q: %q is not a valid semantic version", constraint, part)
+		}
+
+		vc.requirements = append(vc.requirements, versionRequirement{op: op, version: version})
+	}
+
+	if len(vc.requirements) == 0 {
+		return vc, errors.Errorf("invalid version constraint %q", constraint)
+	}
+
+	return vc, nil
+}
+
+// Check reports whether the given version satisfies this constraint.
+func (vc VersionConstraint) Check(version string) bool {
+	if !semver.IsValid(version) {
+		return false
+	}
+
+	for _, r := range vc.requirements {
+		cmp := semver.Compare(version, r.version)
+		var ok bool
+		switch r.op {
+		case "=":
+			ok = cmp == 0
+		case "!=":
+			ok = cmp != 0
+		case ">":
+			ok = cmp > 0
+		case ">=":
+			ok = cmp >= 0
+		case "<":
+			ok = cmp < 0
+		case "<=":
+			ok = cmp <= 0
+		case "^":
+			ok = cmp >= 0 && semver.Major(version) == semver.Major(r.version)
+		case "~":
+			ok = cmp >= 0 && semver.MajorMinor(version) == semver.MajorMinor(r.version)
+		}
+		if !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (vc VersionConstraint) String() string {
+	return vc.constraint
+}
+
+// versionRequiredBy is a version constraint set on an import.
+type versionRequiredBy struct {
+	owner      string
+	constraint VersionConstraint
+}
+
+// addVersionConstraint registers the version constraint, if any, set on the
+// given import in owner.
+func (c *collector) addVersionConstraint(owner Module, moduleImport Import) error {
+	if moduleImport.Version == "" {
+		return nil
+	}
+
+	vc, err := ParseVersionConstraint(moduleImport.Version)
+	if err != nil {
+		return errors.Wrapf(err, "module %q: import %q", owner.Path(), moduleImport.Path)
+	}
+
+	if c.versionConstraints == nil {
+		c.versionConstraints = make(map[string][]versionRequiredBy)
+	}
+
+	key := pathKey(moduleImport.Path)
+	c.versionConstraints[key] = append(c.versionConstraints[key], versionRequiredBy{owner: owner.Path(), constraint: vc})
+
+	return nil
+}
+
+// checkVersionConstraints checks the resolved module versions against the
+// version constraints set on the imports, and returns an error listing all
+// the conflicts found.
+// Modules without a version, e.g. those in /themes or replaced with a local
+// directory, are not checked.
+func (c *collector) checkVersionConstraints() error {
+	var conflicts []string
+
+	for _, m := range c.modules {
+		if m.Owner() == nil || m.Disabled() || m.Replace() != nil {
+			continue
+		}
+
+		version := m.Version()
+		if version == "" {
+			continue
+		}
+
+		requiredBy := c.versionConstraints[pathKey(m.Path())]
+
+		var failed bool
+		var lines []string
+		for _, r := range requiredBy {
+			ok := r.constraint.Check(version)
+			if !ok {
+				failed = true
+			}
+			status := "ok"
+			if !ok {
+				status = "not satisfied"
+			}
+			lines = append(lines, fmt.Sprintf("  %q requires %q (%s)", r.owner, r.constraint, status))
+		}
+
+		if !failed {
+			continue
+		}
+
+		sort.Strings(lines)
+
+		conflicts = append(conflicts, fmt.Sprintf("module %q resolved to %s, which conflicts with the version constraints of its importers:\n%s", m.Path(), version, strings.Join(lines, "\n")))
+	}
+
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	return errors.Errorf("%s\n\nUpdate the modules above to versions that agree on the shared module, or use \"hugo mod get <module>@<version>\" to select a version that satisfies all constraints. Run \"hugo mod graph\" to see the full dependency graph.", strings.Join(conflicts, "\n\n"))
+}
@@ -0,0 +1,79 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modules
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestVersionConstraint(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range []struct {
+		constraint string
+		version    string
+		expect     bool
+	}{
+		{"v1.2.0", "v1.2.0", true},
+		{"1.2.0", "v1.2.0", true},
+		{"=v1.2.0", "v1.2.1", false},
+		{"!=v1.2.0", "v1.2.1", true},
+		{">= v1.2.0, < v2.0.0", "v1.9.3", true},
+		{">= v1.2.0, < v2.0.0", "v2.0.0", false},
+		{">v1.2.0", "v1.2.0", false},
+		{"<=v1.2.0", "v1.2.0", true},
+		{"^v1.2.0", "v1.5.0", true},
+		{"^v1.2.0", "v1.1.0", false},
+		{"^v1.2.0", "v2.0.0", false},
+		{"~v1.2.0", "v1.2.9", true},
+		{"~v1.2.0", "v1.3.0", false},
+		{"^v1.2.0", "v1.2.1-0.20210601120000-abcdefabcdef", true},
+		{"^v1.2.0", "", false},
+	} {
+		vc, err := ParseVersionConstraint(test.constraint)
+		c.Assert(err, qt.IsNil)
+		c.Assert(vc.Check(test.version), qt.Equals, test.expect, qt.Commentf("%s %s", test.constraint, test.version))
+	}
+
+	for _, invalid := range []string{"", ",", ">=", "^foo", "v1.2.0, latest"} {
+		_, err := ParseVersionConstraint(invalid)
+		c.Assert(err, qt.Not(qt.IsNil), qt.Commentf(invalid))
+	}
+}
+
+func TestCheckVersionConstraints(t *testing.T) {
+	c := qt.New(t)
+
+	project := &moduleAdapter{projectMod: true, path: "github.com/bep/project"}
+	themeA := &moduleAdapter{path: "github.com/bep/themea", owner: project}
+	themeB := &moduleAdapter{path: "github.com/bep/themeb", owner: project}
+	shared := &moduleAdapter{path: "github.com/bep/shared", version: "v2.1.0", owner: themeA}
+
+	coll := &collector{collected: &collected{}}
+	coll.modules = Modules{project, themeA, themeB, shared}
+
+	c.Assert(coll.addVersionConstraint(themeB, Import{Path: "github.com/bep/shared", Version: ">= v2.0.0"}), qt.IsNil)
+	c.Assert(coll.checkVersionConstraints(), qt.IsNil)
+
+	c.Assert(coll.addVersionConstraint(themeA, Import{Path: "github.com/bep/shared", Version: "^v1.2.0"}), qt.IsNil)
+	err := coll.checkVersionConstraints()
+	c.Assert(err, qt.Not(qt.IsNil))
+	c.Assert(err.Error(), qt.Contains, `module "github.com/bep/shared" resolved to v2.1.0`)
+	c.Assert(err.Error(), qt.Contains, `"github.com/bep/themea" requires "^v1.2.0" (not satisfied)`)
+	c.Assert(err.Error(), qt.Contains, `"github.com/bep/themeb" requires ">= v2.0.0" (ok)`)
+
+	c.Assert(coll.addVersionConstraint(themeA, Import{Path: "github.com/bep/other", Version: "latest"}), qt.Not(qt.IsNil))
+}
@@ -0,0 +1,102 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package navigation
+
+import (
+	"encoding/json"
+	"html/template"
+	"strings"
+
+	"github.com/spf13/cast"
+)
+
+// The front matter param used to override the title of a page in
+// breadcrumbs.
+const breadcrumbTitleParam = "breadcrumbtitle"
+
+// BreadcrumbPage is the narrow version of page.Page needed for breadcrumbs.
+type BreadcrumbPage interface {
+	Page
+	Permalink() string
+}
+
+// Breadcrumb is an entry in a BreadcrumbList.
+type Breadcrumb struct {
+	// The page, the home page for the first entry.
+	Page BreadcrumbPage
+
+	// The title, the param breadcrumbTitle if set, else the link title.
+	Title string
+
+	// The 1-based position in the list.
+	Position int
+
+	// Whether this is the last entry, i.e. the current page.
+	IsLast bool
+}
+
+// URL returns the relative permalink of the entry's page.
+func (b Breadcrumb) URL() string {
+	return b.Page.RelPermalink()
+}
+
+// BreadcrumbList is the list of pages from the home page down to the
+// current page.
+type BreadcrumbList []Breadcrumb
+
+// NewBreadcrumbList creates a new BreadcrumbList from the given pages,
+// ordered from the home page down to the current page.
+func NewBreadcrumbList(pages ...BreadcrumbPage) BreadcrumbList {
+	list := make(BreadcrumbList, len(pages))
+	for i, p := range pages {
+		title := strings.TrimSpace(cast.ToString(p.Params()[breadcrumbTitleParam]))
+		if title == "" {
+			title = p.LinkTitle()
+		}
+		list[i] = Breadcrumb{
+			Page:     p,
+			Title:    title,
+			Position: i + 1,
+			IsLast:   i == len(pages)-1,
+		}
+	}
+	return list
+}
+
+// JSONLD returns the list as schema.org BreadcrumbList structured data,
+// in a script element.
+func (b BreadcrumbList) JSONLD() (template.HTML, error) {
+	type listItem struct {
+		Type     string `json:"@type"`
+		Position int    `json:"position"`
+		Name     string `json:"name"`
+		Item     string `json:"item"`
+	}
+
+	items := make([]listItem, len(b))
+	for i, e := range b {
+		items[i] = listItem{Type: "ListItem", Position: e.Position, Name: e.Title, Item: e.Page.Permalink()}
+	}
+
+	data, err := json.Marshal(struct {
+		Context         string     `json:"@context"`
+		Type            string     `json:"@type"`
+		ItemListElement []listItem `json:"itemListElement"`
+	}{"https://schema.org", "BreadcrumbList", items})
+	if err != nil {
+		return "", err
+	}
+
+	return template.HTML(`<script type="application/ld+json">` + string(data) + `</script>`), nil
+}
@@ -21,12 +21,29 @@ import (
 	"github.com/gohugoio/hugo/common/maps"
 	"github.com/gohugoio/hugo/common/types"
 	"github.com/gohugoio/hugo/compare"
+	"github.com/gohugoio/hugo/config/configschema"
 
 	"github.com/spf13/cast"
 )
 
 var smc = newMenuCache()
 
+func init() {
+	// The menu entries as configured in the site config, see MarshallMap.
+	type menuEntryConfig struct {
+		Identifier string
+		Name       string
+		URL        string
+		Title      string
+		Pre        string
+		Post       string
+		Weight     int
+		Parent     string
+		Params     map[string]interface{}
+	}
+	configschema.AddSection("menus", map[string][]menuEntryConfig{})
+}
+
 // MenuEntry represents a menu item defined in either Page front matter
 // or in the site config.
 type MenuEntry struct {
@@ -0,0 +1,259 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package notify sends build and deploy summaries to webhooks.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gohugoio/hugo/common/loggers"
+	"github.com/gohugoio/hugo/config"
+	"github.com/gohugoio/hugo/config/configschema"
+	"github.com/gohugoio/hugo/config/security"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+)
+
+const notificationsConfigKey = "notifications"
+
+// The events a webhook can be notified about.
+const (
+	EventBuild  = "build"
+	EventDeploy = "deploy"
+)
+
+// The time to wait before the first retry, doubled for every retry.
+// Set in tests.
+var retryWait = time.Second
+
+// DefaultConfig holds the default notifications configuration.
+var DefaultConfig = Config{
+	Retries: 3,
+	Timeout: 10 * time.Second,
+}
+
+// Config holds the notifications configuration.
+type Config struct {
+	// The webhooks to POST the summaries to.
+	Webhooks []Webhook
+
+	// The number of times to retry a failed request.
+	Retries int
+
+	// The timeout for each request.
+	Timeout time.Duration
+}
+
+// Webhook configures a webhook.
+type Webhook struct {
+	// The URL to POST the summary to.
+	URL string
+
+	// The events to notify about, "build" and/or "deploy".
+	// Defaults to both.
+	Events []string
+
+	// HTTP headers to set, e.g. Authorization.
+	Headers map[string]string
+}
+
+func (w Webhook) accepts(event string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if strings.EqualFold(e, event) {
+			return true
+		}
+	}
+	return false
+}
+
+// Summary is the JSON payload sent to the webhooks.
+type Summary struct {
+	// The event, "build" or "deploy".
+	Event string `json:"event"`
+
+	Success bool `json:"success"`
+
+	// The duration in milliseconds.
+	Duration int64 `json:"duration"`
+
+	// The number of pages built.
+	Pages int `json:"pages"`
+
+	Errors []string `json:"errors"`
+
+	// The files uploaded or deleted by a deploy, sorted.
+	ChangedFiles []string `json:"changedFiles"`
+
+	// The name of the deployment target.
+	Target string `json:"target,omitempty"`
+
+	HugoVersion string    `json:"hugoVersion"`
+	Date        time.Time `json:"date"`
+}
+
+// DecodeConfig creates a notifications Config from a given Hugo configuration.
+func DecodeConfig(cfg config.Provider) (Config, error) {
+	c := DefaultConfig
+	if !cfg.IsSet(notificationsConfigKey) {
+		return c, nil
+	}
+
+	dec, err := mapstructure.NewDecoder(
+		&mapstructure.DecoderConfig{
+			WeaklyTypedInput: true,
+			Result:           &c,
+			DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
+		},
+	)
+	if err != nil {
+		return c, err
+	}
+
+	if err := dec.Decode(cfg.GetStringMap(notificationsConfigKey)); err != nil {
+		return c, errors.Wrap(err, "failed to decode notifications config")
+	}
+
+	for _, w := range c.Webhooks {
+		u, err := url.Parse(w.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return c, errors.Errorf("notifications: invalid webhook URL %q", w.URL)
+		}
+		for _, e := range w.Events {
+			if e = strings.ToLower(e); e != EventBuild && e != EventDeploy {
+				return c, errors.Errorf("notifications: invalid event %q for webhook %q, must be %q or %q", e, w.URL, EventBuild, EventDeploy)
+			}
+		}
+	}
+
+	return c, nil
+}
+
+// Notifier sends summaries to the configured webhooks.
+type Notifier struct {
+	cfg      Config
+	security security.Config
+	client   *http.Client
+	logger   loggers.Logger
+}
+
+// New creates a new Notifier. The webhook URLs must be allowed by the HTTP
+// security policy.
+func New(cfg Config, sc security.Config, logger loggers.Logger) *Notifier {
+	if logger == nil {
+		logger = loggers.NewWarningLogger()
+	}
+	return &Notifier{
+		cfg:      cfg,
+		security: sc,
+		client:   sc.NewHTTPClient(cfg.Timeout),
+		logger:   logger,
+	}
+}
+
+// Notify POSTs s to the webhooks accepting s.Event. A failing webhook does
+// not stop the others from being notified; the first error is returned.
+func (n *Notifier) Notify(ctx context.Context, s Summary) error {
+	var firstErr error
+
+	for _, w := range n.cfg.Webhooks {
+		if !w.accepts(s.Event) {
+			continue
+		}
+		if err := n.post(ctx, w, s); err != nil {
+			err = errors.Wrapf(err, "notifications: failed to notify %q", w.URL)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	return firstErr
+}
+
+func (n *Notifier) post(ctx context.Context, w Webhook, s Summary) error {
+	u, err := url.Parse(w.URL)
+	if err != nil {
+		return err
+	}
+	if err := n.security.CheckAllowedHTTP("POST", u); err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	wait := retryWait
+	for i := 0; ; i++ {
+		var retry bool
+		retry, err = n.send(ctx, w, body)
+		if err == nil || !retry || i >= n.cfg.Retries {
+			return err
+		}
+
+		n.logger.Infof("notifications: retry #%d for %s in %s: %s", i+1, w.URL, wait, err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+}
+
+// send does one POST request and reports whether a failed request should be
+// retried.
+func (n *Notifier) send(ctx context.Context, w Webhook, body []byte) (bool, error) {
+	req, err := http.NewRequest("POST", w.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Hugo Static Site Generator")
+	for k, v := range w.Headers {
+		req.Header.Set(k, v)
+	}
+
+	res, err := n.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer res.Body.Close()
+	io.Copy(ioutil.Discard, res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		retry := res.StatusCode >= 500 || res.StatusCode == http.StatusTooManyRequests
+		return retry, errors.Errorf("unexpected status: %s", res.Status)
+	}
+
+	return false, nil
+}
+
+func init() {
+	configschema.AddSection(notificationsConfigKey, DefaultConfig)
+}
@@ -0,0 +1,131 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/config"
+	"github.com/gohugoio/hugo/config/security"
+)
+
+func TestDecodeConfig(t *testing.T) {
+	c := qt.New(t)
+
+	nc, err := DecodeConfig(config.New())
+	c.Assert(err, qt.IsNil)
+	c.Assert(nc.Retries, qt.Equals, 3)
+	c.Assert(nc.Webhooks, qt.HasLen, 0)
+
+	cfg, err := config.FromConfigString(`
+[notifications]
+retries = 1
+timeout = "3s"
+[[notifications.webhooks]]
+url = "https://example.org/hook"
+events = ["deploy"]
+[notifications.webhooks.headers]
+Authorization = "Bearer foo"
+`, "toml")
+	c.Assert(err, qt.IsNil)
+
+	nc, err = DecodeConfig(cfg)
+	c.Assert(err, qt.IsNil)
+	c.Assert(nc.Retries, qt.Equals, 1)
+	c.Assert(nc.Timeout, qt.Equals, 3*time.Second)
+	c.Assert(nc.Webhooks, qt.HasLen, 1)
+	c.Assert(nc.Webhooks[0].URL, qt.Equals, "https://example.org/hook")
+	c.Assert(nc.Webhooks[0].Headers, qt.HasLen, 1)
+	c.Assert(nc.Webhooks[0].accepts(EventDeploy), qt.IsTrue)
+	c.Assert(nc.Webhooks[0].accepts(EventBuild), qt.IsFalse)
+
+	for _, invalid := range []string{
+		`[[notifications.webhooks]]
+url = "ftp://example.org"`,
+		`[[notifications.webhooks]]
+url = "https://example.org"
+events = ["server"]`,
+	} {
+		cfg, err := config.FromConfigString(invalid, "toml")
+		c.Assert(err, qt.IsNil)
+		_, err = DecodeConfig(cfg)
+		c.Assert(err, qt.Not(qt.IsNil))
+	}
+}
+
+func TestNotify(t *testing.T) {
+	c := qt.New(t)
+
+	retryWait = time.Millisecond
+	defer func() { retryWait = time.Second }()
+
+	var (
+		calls    int32
+		received Summary
+		auth     string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		auth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer srv.Close()
+
+	cfg := DefaultConfig
+	cfg.Webhooks = []Webhook{
+		{URL: srv.URL, Headers: map[string]string{"authorization": "Bearer foo"}},
+		{URL: srv.URL + "/deploy", Events: []string{EventDeploy}},
+	}
+
+	n := New(cfg, security.DefaultConfig, nil)
+	err := n.Notify(context.Background(), Summary{Event: EventBuild, Success: true, Pages: 32, Duration: 123})
+	c.Assert(err, qt.IsNil)
+	c.Assert(atomic.LoadInt32(&calls), qt.Equals, int32(2))
+	c.Assert(auth, qt.Equals, "Bearer foo")
+	c.Assert(received.Event, qt.Equals, EventBuild)
+	c.Assert(received.Pages, qt.Equals, 32)
+	c.Assert(received.Duration, qt.Equals, int64(123))
+
+	c.Run("Client error", func(c *qt.C) {
+		var calls int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer srv.Close()
+
+		cfg := DefaultConfig
+		cfg.Webhooks = []Webhook{{URL: srv.URL}}
+		err := New(cfg, security.DefaultConfig, nil).Notify(context.Background(), Summary{Event: EventBuild})
+		c.Assert(err, qt.ErrorMatches, ".*404 Not Found")
+		c.Assert(atomic.LoadInt32(&calls), qt.Equals, int32(1))
+	})
+
+	c.Run("Access denied", func(c *qt.C) {
+		sc := security.DefaultConfig
+		sc.HTTP.Domains = security.NewWhitelist("none")
+		err := New(cfg, sc, nil).Notify(context.Background(), Summary{Event: EventBuild})
+		c.Assert(security.IsAccessDenied(err), qt.IsTrue)
+	})
+}
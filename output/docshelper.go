@@ -5,6 +5,7 @@ import (
 
 	//	"fmt"
 
+	"github.com/gohugoio/hugo/config/configschema"
 	"github.com/gohugoio/hugo/docshelper"
 )
 
@@ -20,6 +21,13 @@ func init() {
 	}
 
 	docshelper.AddDocProviderFunc(docsProvider)
+
+	formats := configschema.FromValue(map[string]Format{})
+	// The name is the key.
+	delete(formats.AdditionalProperties.Properties, "name")
+	// The media type is set as a string, e.g. "text/html".
+	formats.AdditionalProperties.Properties["mediaType"] = &configschema.Schema{Type: configschema.TypeString}
+	configschema.AddSection("outputFormats", formats)
 }
 
 func createLayoutExamples() interface{} {
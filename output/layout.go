@@ -204,9 +204,27 @@ func resolvePageTemplate(d LayoutDescriptor, f Format) []string {
 		layouts = append(layouts, "_internal/_default/rss.xml")
 	}
 
+	if internal, found := internalLayouts[f.Name]; found && !d.RenderingHook && !d.Baseof && d.Kind != "404" {
+		if d.isList() {
+			layouts = append(layouts, internal[0])
+		} else {
+			layouts = append(layouts, internal[1])
+		}
+	}
+
 	return layouts
 }
 
+// The built-in list and single templates for some of the output formats,
+// used if there are none in the project.
+var internalLayouts = map[string][2]string{
+	EmailFormat.Name:    {"_internal/_default/email.html", "_internal/_default/email.html"},
+	GeminiFormat.Name:   {"_internal/_default/list.gmi", "_internal/_default/single.gmi"},
+	MarkdownFormat.Name: {"_internal/_default/list.md", "_internal/_default/single.md"},
+	LLMSFormat.Name:     {"_internal/_default/llms.txt", "_internal/_default/llms.txt"},
+	LLMSFullFormat.Name: {"_internal/_default/llms-full.txt", "_internal/_default/llms-full.txt"},
+}
+
 func (l *layoutBuilder) resolveVariations() []string {
 	var layouts []string
 
@@ -702,6 +702,48 @@ func TestLayout(t *testing.T) {
 				"_default/single.html",
 			},
 		},
+		// Gemini
+		{
+			"Gemini Home",
+			LayoutDescriptor{Kind: "home"},
+			"", GeminiFormat,
+			[]string{
+				"index.gemini.gmi",
+				"home.gemini.gmi",
+				"list.gemini.gmi",
+				"index.gmi",
+				"home.gmi",
+				"list.gmi",
+				"_default/index.gemini.gmi",
+				"_default/home.gemini.gmi",
+				"_default/list.gemini.gmi",
+				"_default/index.gmi",
+				"_default/home.gmi",
+				"_default/list.gmi",
+				"_internal/_default/list.gmi",
+			},
+		},
+		{
+			"Gemini Page",
+			LayoutDescriptor{Kind: "page"},
+			"", GeminiFormat,
+			[]string{
+				"_default/single.gemini.gmi",
+				"_default/single.gmi",
+				"_internal/_default/single.gmi",
+			},
+		},
+		// Email
+		{
+			"Email Page",
+			LayoutDescriptor{Kind: "page"},
+			"", EmailFormat,
+			[]string{
+				"_default/single.email.html",
+				"_default/single.html",
+				"_internal/_default/email.html",
+			},
+		},
 		// RSS
 		{
 			"RSS Home",
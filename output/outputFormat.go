@@ -20,7 +20,9 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/gohugoio/hugo/common/maps"
 	"github.com/pkg/errors"
+	"github.com/spf13/cast"
 
 	"github.com/mitchellh/mapstructure"
 
@@ -122,6 +124,30 @@ var (
 		Rel:         "alternate",
 	}
 
+	// EmailFormat publishes a HTML email version of a page, e.g. for
+	// newsletters. The templates can be written in MJML, which is compiled to
+	// table based HTML with the CSS inlined.
+	EmailFormat = Format{
+		Name:           "Email",
+		MediaType:      media.HTMLType,
+		BaseName:       "email",
+		IsHTML:         true,
+		NotAlternative: true,
+		Rel:            "alternate",
+	}
+
+	// GeminiFormat publishes to Gemini capsules, see
+	// https://gemini.circumlunar.space/. Markdown content is rendered as
+	// gemtext.
+	GeminiFormat = Format{
+		Name:        "Gemini",
+		MediaType:   media.GemtextType,
+		BaseName:    "index",
+		IsPlainText: true,
+		Protocol:    "gemini://",
+		Rel:         "alternate",
+	}
+
 	HTMLFormat = Format{
 		Name:          "HTML",
 		MediaType:     media.HTMLType,
@@ -143,6 +169,36 @@ var (
 		Rel:         "alternate",
 	}
 
+	// LLMSFormat and LLMSFullFormat publish the site as llms.txt and
+	// llms-full.txt, see https://llmstxt.org/.
+	LLMSFormat = Format{
+		Name:           "LLMS",
+		MediaType:      media.TextType,
+		BaseName:       "llms",
+		IsPlainText:    true,
+		NotAlternative: true,
+		Rel:            "alternate",
+	}
+
+	LLMSFullFormat = Format{
+		Name:           "LLMSFull",
+		MediaType:      media.TextType,
+		BaseName:       "llms-full",
+		IsPlainText:    true,
+		NotAlternative: true,
+		Rel:            "alternate",
+	}
+
+	// MarkdownFormat publishes the Markdown content of the pages with the
+	// shortcodes rendered.
+	MarkdownFormat = Format{
+		Name:        "Markdown",
+		MediaType:   media.MarkdownType,
+		BaseName:    "index",
+		IsPlainText: true,
+		Rel:         "alternate",
+	}
+
 	WebAppManifestFormat = Format{
 		Name:           "WebAppManifest",
 		MediaType:      media.WebAppManifestType,
@@ -183,8 +239,13 @@ var DefaultFormats = Formats{
 	CalendarFormat,
 	CSSFormat,
 	CSVFormat,
+	EmailFormat,
+	GeminiFormat,
 	HTMLFormat,
 	JSONFormat,
+	LLMSFormat,
+	LLMSFullFormat,
+	MarkdownFormat,
 	WebAppManifestFormat,
 	RobotsTxtFormat,
 	RSSFormat,
@@ -297,41 +358,121 @@ func DecodeFormats(mediaTypes media.Types, maps ...map[string]interface{}) (Form
 	copy(f, DefaultFormats)
 
 	for _, m := range maps {
-		for k, v := range m {
-			found := false
-			for i, vv := range f {
-				if strings.EqualFold(k, vv.Name) {
-					// Merge it with the existing
-					if err := decode(mediaTypes, v, &f[i]); err != nil {
-						return f, err
-					}
-					found = true
-				}
+		d := &formatsDecoder{
+			mediaTypes: mediaTypes,
+			formats:    f,
+			m:          m,
+			state:      make(map[string]int),
+		}
+		for k := range m {
+			if err := d.decode(k); err != nil {
+				return f, err
 			}
-			if !found {
-				var newOutFormat Format
-				newOutFormat.Name = k
-				if err := decode(mediaTypes, v, &newOutFormat); err != nil {
-					return f, err
-				}
+		}
+		f = d.formats
+	}
 
-				// We need values for these
-				if newOutFormat.BaseName == "" {
-					newOutFormat.BaseName = "index"
-				}
-				if newOutFormat.Rel == "" {
-					newOutFormat.Rel = "alternate"
-				}
+	sort.Sort(f)
 
-				f = append(f, newOutFormat)
+	return f, nil
+}
 
+// formatsDecoder decodes the output formats in one config map, making sure
+// that formats are decoded after the formats they inherit from.
+type formatsDecoder struct {
+	mediaTypes media.Types
+	formats    Formats
+	m          map[string]interface{}
+
+	// Maps the lower case format names to decodeInProgress or decodeDone.
+	state map[string]int
+}
+
+const (
+	decodeInProgress = iota + 1
+	decodeDone
+)
+
+func (d *formatsDecoder) decode(k string) error {
+	key := strings.ToLower(k)
+	switch d.state[key] {
+	case decodeDone:
+		return nil
+	case decodeInProgress:
+		return errors.Errorf("output format %q: circular inheritance", k)
+	}
+	d.state[key] = decodeInProgress
+
+	v := d.m[k]
+
+	var base string
+	if m, err := maps.ToStringMapE(v); err == nil {
+		for kk, vv := range m {
+			if strings.EqualFold(kk, "inherits") {
+				base = cast.ToString(vv)
 			}
 		}
 	}
 
-	sort.Sort(f)
+	var baseFormat Format
+	if base != "" {
+		if strings.EqualFold(base, k) {
+			return errors.Errorf("output format %q can not inherit from itself", k)
+		}
+		// The base may be defined or redefined in the same config.
+		for kk := range d.m {
+			if strings.EqualFold(kk, base) {
+				if err := d.decode(kk); err != nil {
+					return err
+				}
+			}
+		}
+		var found bool
+		baseFormat, found = d.formats.GetByName(base)
+		if !found {
+			return errors.Errorf("output format %q: base format %q not found", k, base)
+		}
+	}
 
-	return f, nil
+	found := false
+	for i, vv := range d.formats {
+		if strings.EqualFold(k, vv.Name) {
+			if base != "" {
+				// Start over from the base format.
+				baseFormat.Name = vv.Name
+				d.formats[i] = baseFormat
+			}
+			// Merge it with the existing
+			if err := decode(d.mediaTypes, v, &d.formats[i]); err != nil {
+				return err
+			}
+			found = true
+		}
+	}
+	if !found {
+		var newOutFormat Format
+		if base != "" {
+			newOutFormat = baseFormat
+		}
+		newOutFormat.Name = k
+		if err := decode(d.mediaTypes, v, &newOutFormat); err != nil {
+			return err
+		}
+
+		// We need values for these
+		if newOutFormat.BaseName == "" {
+			newOutFormat.BaseName = "index"
+		}
+		if newOutFormat.Rel == "" {
+			newOutFormat.Rel = "alternate"
+		}
+
+		d.formats = append(d.formats, newOutFormat)
+	}
+
+	d.state[key] = decodeDone
+
+	return nil
 }
 
 func decode(mediaTypes media.Types, input interface{}, output *Format) error {
@@ -68,7 +68,28 @@ func TestDefaultTypes(t *testing.T) {
 	c.Assert(RSSFormat.NoUgly, qt.Equals, true)
 	c.Assert(CalendarFormat.IsHTML, qt.Equals, false)
 
-	c.Assert(len(DefaultFormats), qt.Equals, 10)
+	c.Assert(GeminiFormat.Name, qt.Equals, "Gemini")
+	c.Assert(GeminiFormat.MediaType, qt.Equals, media.GemtextType)
+	c.Assert(GeminiFormat.Protocol, qt.Equals, "gemini://")
+	c.Assert(GeminiFormat.IsPlainText, qt.Equals, true)
+	c.Assert(GeminiFormat.IsHTML, qt.Equals, false)
+
+	c.Assert(MarkdownFormat.Name, qt.Equals, "Markdown")
+	c.Assert(MarkdownFormat.MediaType, qt.Equals, media.MarkdownType)
+	c.Assert(MarkdownFormat.IsPlainText, qt.Equals, true)
+	c.Assert(MarkdownFormat.Permalinkable, qt.Equals, false)
+
+	c.Assert(LLMSFormat.BaseName, qt.Equals, "llms")
+	c.Assert(LLMSFullFormat.BaseName, qt.Equals, "llms-full")
+	c.Assert(LLMSFormat.MediaType, qt.Equals, media.TextType)
+
+	c.Assert(EmailFormat.Name, qt.Equals, "Email")
+	c.Assert(EmailFormat.MediaType, qt.Equals, media.HTMLType)
+	c.Assert(EmailFormat.BaseName, qt.Equals, "email")
+	c.Assert(EmailFormat.IsHTML, qt.Equals, true)
+	c.Assert(EmailFormat.NotAlternative, qt.Equals, true)
+
+	c.Assert(len(DefaultFormats), qt.Equals, 15)
 
 }
 
@@ -230,6 +251,88 @@ func TestDecodeFormats(t *testing.T) {
 				c.Assert(xml.MediaType, qt.Equals, media.XMLType)
 			},
 		},
+		{
+			"Inherit",
+			[]map[string]interface{}{
+				{
+					"API": map[string]interface{}{
+						"inherits":       "json",
+						"path":           "api",
+						"notAlternative": true,
+						"weight":         2,
+					},
+					"APIV2": map[string]interface{}{
+						"Inherits": "api",
+						"path":     "api/v2",
+					},
+					"APIXML": map[string]interface{}{
+						"inherits":  "API",
+						"mediaType": "application/xml",
+						"weight":    0,
+					},
+				},
+				{
+					"APIV2": map[string]interface{}{
+						"baseName": "data",
+					},
+				},
+			},
+			false,
+			func(t *testing.T, name string, f Formats) {
+				c.Assert(len(f), qt.Equals, len(DefaultFormats)+3)
+				api, _ := f.GetByName("API")
+				c.Assert(api.Name, qt.Equals, "API")
+				c.Assert(api.MediaType, qt.Equals, media.JSONType)
+				c.Assert(api.IsPlainText, qt.Equals, true)
+				c.Assert(api.Path, qt.Equals, "api")
+				c.Assert(api.NotAlternative, qt.Equals, true)
+				c.Assert(api.Weight, qt.Equals, 2)
+
+				v2, _ := f.GetByName("APIV2")
+				c.Assert(v2.Name, qt.Equals, "APIV2")
+				c.Assert(v2.MediaType, qt.Equals, media.JSONType)
+				c.Assert(v2.Path, qt.Equals, "api/v2")
+				c.Assert(v2.BaseName, qt.Equals, "data")
+				c.Assert(v2.NotAlternative, qt.Equals, true)
+
+				xml, _ := f.GetByName("APIXML")
+				c.Assert(xml.MediaType, qt.Equals, media.XMLType)
+				c.Assert(xml.Path, qt.Equals, "api")
+				c.Assert(xml.Weight, qt.Equals, 0)
+
+				json, _ := f.GetByName("JSON")
+				c.Assert(json.Path, qt.Equals, "")
+			},
+		},
+		{
+			"Inherit unknown format",
+			[]map[string]interface{}{
+				{
+					"API": map[string]interface{}{
+						"inherits": "foo",
+					},
+				},
+			},
+			true,
+			func(t *testing.T, name string, f Formats) {
+			},
+		},
+		{
+			"Circular inheritance",
+			[]map[string]interface{}{
+				{
+					"A": map[string]interface{}{
+						"inherits": "b",
+					},
+					"B": map[string]interface{}{
+						"inherits": "a",
+					},
+				},
+			},
+			true,
+			func(t *testing.T, name string, f Formats) {
+			},
+		},
 	}
 
 	for _, test := range tests {
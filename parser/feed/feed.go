@@ -0,0 +1,597 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package feed parses RSS, Atom and JSON feeds into a common structure.
+package feed
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/html/charset"
+)
+
+// The supported feed formats.
+const (
+	FormatRSS  = "rss"
+	FormatAtom = "atom"
+	FormatJSON = "json"
+)
+
+// Feed is a parsed feed.
+type Feed struct {
+	// One of rss, atom or json.
+	Format string
+
+	Title       string
+	Description string
+
+	// The URL of the site the feed belongs to.
+	Link string
+
+	// The URL of the feed itself, if set in the feed.
+	FeedLink string
+
+	Language string
+	Image    string
+	Updated  time.Time
+	Authors  []Person
+	Items    []Item
+}
+
+// Item is an item (RSS and JSON) or an entry (Atom) in a feed.
+type Item struct {
+	ID    string
+	Title string
+	Link  string
+
+	// The summary and the full content, as HTML if provided as HTML
+	// by the feed. These are not sanitized.
+	Summary string
+	Content string
+
+	Published time.Time
+	Updated   time.Time
+
+	Authors    []Person
+	Categories []string
+	Image      string
+	Enclosures []Enclosure
+}
+
+// Person is the author of a feed or an item.
+type Person struct {
+	Name  string
+	Email string
+	URL   string
+}
+
+// Enclosure is a media file attached to an item, e.g. a podcast episode.
+type Enclosure struct {
+	URL    string
+	Type   string
+	Length int64
+}
+
+// Parse parses the RSS (0.9x, 1.0 and 2.0), Atom 1.0 or JSON Feed in b.
+func Parse(b []byte) (*Feed, error) {
+	b = bytes.TrimSpace(b)
+	if len(b) == 0 {
+		return nil, errors.New("feed: empty input")
+	}
+
+	if b[0] == '{' {
+		return parseJSON(b)
+	}
+
+	dec := newXMLDecoder(b)
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, errors.Wrap(err, "feed: failed to parse XML")
+		}
+		se, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(se.Name.Local) {
+		case "rss", "rdf":
+			return parseRSS(b)
+		case "feed":
+			return parseAtom(b)
+		default:
+			return nil, errors.Errorf("feed: unsupported root element %q, must be rss, rdf:RDF or feed", se.Name.Local)
+		}
+	}
+}
+
+func newXMLDecoder(b []byte) *xml.Decoder {
+	dec := xml.NewDecoder(bytes.NewReader(b))
+	dec.CharsetReader = charset.NewReaderLabel
+	// Feeds in the wild are not always well-formed, and often use HTML
+	// entities such as &nbsp;.
+	dec.Strict = false
+	dec.Entity = xml.HTMLEntity
+	return dec
+}
+
+const nsAtom = "http://www.w3.org/2005/Atom"
+
+type rssLink struct {
+	XMLName xml.Name
+	Href    string `xml:"href,attr"`
+	Rel     string `xml:"rel,attr"`
+	Value   string `xml:",chardata"`
+}
+
+type rssImage struct {
+	URL  string `xml:"url"`
+	Href string `xml:"href,attr"`
+}
+
+type rssMedia struct {
+	URL    string `xml:"url,attr"`
+	Medium string `xml:"medium,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+type rssItem struct {
+	GUID        string     `xml:"guid"`
+	Title       string     `xml:"title"`
+	Links       []rssLink  `xml:"link"`
+	Description string     `xml:"description"`
+	Content     string     `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	PubDate     string     `xml:"pubDate"`
+	DCDate      string     `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Author      string     `xml:"author"`
+	Creators    []string   `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Categories  []string   `xml:"category"`
+	Subjects    []string   `xml:"http://purl.org/dc/elements/1.1/ subject"`
+	Thumbnail   rssMedia   `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+	Media       []rssMedia `xml:"http://search.yahoo.com/mrss/ content"`
+	ITunesImage rssImage   `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd image"`
+	Enclosures  []struct {
+		URL    string `xml:"url,attr"`
+		Type   string `xml:"type,attr"`
+		Length int64  `xml:"length,attr"`
+	} `xml:"enclosure"`
+}
+
+type rssChannel struct {
+	Title          string    `xml:"title"`
+	Links          []rssLink `xml:"link"`
+	Description    string    `xml:"description"`
+	Language       string    `xml:"language"`
+	DCLanguage     string    `xml:"http://purl.org/dc/elements/1.1/ language"`
+	LastBuildDate  string    `xml:"lastBuildDate"`
+	PubDate        string    `xml:"pubDate"`
+	DCDate         string    `xml:"http://purl.org/dc/elements/1.1/ date"`
+	ManagingEditor string    `xml:"managingEditor"`
+	Creators       []string  `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Image          rssImage  `xml:"image"`
+	ITunesImage    rssImage  `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd image"`
+	Items          []rssItem `xml:"item"`
+}
+
+type rssDoc struct {
+	Channel rssChannel `xml:"channel"`
+
+	// In RSS 1.0 the items and the image are siblings of the channel.
+	Items []rssItem `xml:"item"`
+	Image rssImage  `xml:"image"`
+}
+
+func parseRSS(b []byte) (*Feed, error) {
+	var doc rssDoc
+	if err := newXMLDecoder(b).Decode(&doc); err != nil {
+		return nil, errors.Wrap(err, "feed: failed to parse RSS")
+	}
+
+	ch := doc.Channel
+	link, feedLink := rssLinks(ch.Links)
+
+	f := &Feed{
+		Format:      FormatRSS,
+		Title:       text(ch.Title),
+		Description: text(ch.Description),
+		Link:        link,
+		FeedLink:    feedLink,
+		Language:    first(ch.Language, ch.DCLanguage),
+		Image:       first(ch.Image.URL, doc.Image.URL, ch.ITunesImage.Href),
+		Updated:     parseDate(first(ch.LastBuildDate, ch.PubDate, ch.DCDate)),
+		Authors:     rssAuthors(ch.ManagingEditor, ch.Creators),
+	}
+
+	items := ch.Items
+	if len(items) == 0 {
+		items = doc.Items
+	}
+
+	for _, it := range items {
+		link, _ := rssLinks(it.Links)
+		item := Item{
+			ID:         first(text(it.GUID), link),
+			Title:      text(it.Title),
+			Link:       link,
+			Summary:    strings.TrimSpace(it.Description),
+			Content:    strings.TrimSpace(it.Content),
+			Published:  parseDate(first(it.PubDate, it.DCDate)),
+			Authors:    rssAuthors(it.Author, it.Creators),
+			Categories: texts(append(it.Categories, it.Subjects...)),
+			Image:      first(it.Thumbnail.URL, it.ITunesImage.Href),
+		}
+		if item.Content == "" {
+			item.Content = item.Summary
+		}
+		item.Updated = item.Published
+		for _, m := range it.Media {
+			if item.Image == "" && (m.Medium == "image" || strings.HasPrefix(m.Type, "image/")) {
+				item.Image = m.URL
+			}
+		}
+		for _, e := range it.Enclosures {
+			item.Enclosures = append(item.Enclosures, Enclosure{URL: e.URL, Type: e.Type, Length: e.Length})
+			if item.Image == "" && strings.HasPrefix(e.Type, "image/") {
+				item.Image = e.URL
+			}
+		}
+		f.Items = append(f.Items, item)
+	}
+
+	return f, nil
+}
+
+// rssLinks returns the site link and the feed link, set in an atom:link
+// with rel="self".
+func rssLinks(links []rssLink) (link, feedLink string) {
+	for _, l := range links {
+		if l.XMLName.Space == nsAtom {
+			if l.Rel == "self" {
+				feedLink = l.Href
+			}
+			continue
+		}
+		if link == "" {
+			link = strings.TrimSpace(l.Value)
+		}
+	}
+	return
+}
+
+// rssAuthors returns the authors, given either as an email address with the
+// name in parentheses, e.g. "jane@example.org (Jane Doe)", or as dc:creator.
+func rssAuthors(author string, creators []string) []Person {
+	var authors []Person
+	if author = strings.TrimSpace(author); author != "" {
+		p := Person{Name: author}
+		if i := strings.Index(author, "("); i != -1 && strings.HasSuffix(author, ")") {
+			p = Person{Email: strings.TrimSpace(author[:i]), Name: strings.TrimSpace(author[i+1 : len(author)-1])}
+		} else if strings.Contains(author, "@") && !strings.Contains(author, " ") {
+			p = Person{Email: author}
+		}
+		authors = append(authors, p)
+	}
+	for _, c := range texts(creators) {
+		authors = append(authors, Person{Name: c})
+	}
+	return authors
+}
+
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+	Inner string `xml:",innerxml"`
+}
+
+// html returns t as HTML.
+func (t atomText) html() string {
+	switch t.Type {
+	case "html":
+		return strings.TrimSpace(t.Value)
+	case "xhtml":
+		return strings.TrimSpace(t.Inner)
+	default:
+		return html.EscapeString(strings.TrimSpace(t.Value))
+	}
+}
+
+type atomPerson struct {
+	Name  string `xml:"name"`
+	Email string `xml:"email"`
+	URI   string `xml:"uri"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+
+	Length int64 `xml:"length,attr"`
+}
+
+type atomEntry struct {
+	ID         string       `xml:"id"`
+	Title      atomText     `xml:"title"`
+	Links      []atomLink   `xml:"link"`
+	Summary    atomText     `xml:"summary"`
+	Content    atomText     `xml:"content"`
+	Published  string       `xml:"published"`
+	Updated    string       `xml:"updated"`
+	Authors    []atomPerson `xml:"author"`
+	Categories []struct {
+		Term string `xml:"term,attr"`
+	} `xml:"category"`
+	Thumbnail rssMedia `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+}
+
+type atomFeed struct {
+	Title    atomText     `xml:"title"`
+	Subtitle atomText     `xml:"subtitle"`
+	Links    []atomLink   `xml:"link"`
+	Lang     string       `xml:"http://www.w3.org/XML/1998/namespace lang,attr"`
+	Updated  string       `xml:"updated"`
+	Authors  []atomPerson `xml:"author"`
+	Logo     string       `xml:"logo"`
+	Icon     string       `xml:"icon"`
+	Entries  []atomEntry  `xml:"entry"`
+}
+
+func parseAtom(b []byte) (*Feed, error) {
+	var doc atomFeed
+	if err := newXMLDecoder(b).Decode(&doc); err != nil {
+		return nil, errors.Wrap(err, "feed: failed to parse Atom")
+	}
+
+	f := &Feed{
+		Format:      FormatAtom,
+		Title:       text(doc.Title.Value),
+		Description: text(doc.Subtitle.Value),
+		Link:        atomLinkHref(doc.Links, "alternate"),
+		FeedLink:    atomLinkHref(doc.Links, "self"),
+		Language:    doc.Lang,
+		Image:       first(doc.Logo, doc.Icon),
+		Updated:     parseDate(doc.Updated),
+		Authors:     atomAuthors(doc.Authors),
+	}
+
+	for _, e := range doc.Entries {
+		item := Item{
+			ID:        strings.TrimSpace(e.ID),
+			Title:     text(e.Title.Value),
+			Link:      atomLinkHref(e.Links, "alternate"),
+			Summary:   e.Summary.html(),
+			Content:   e.Content.html(),
+			Published: parseDate(e.Published),
+			Updated:   parseDate(e.Updated),
+			Authors:   atomAuthors(e.Authors),
+			Image:     e.Thumbnail.URL,
+		}
+		if item.Published.IsZero() {
+			item.Published = item.Updated
+		}
+		if item.Content == "" {
+			item.Content = item.Summary
+		}
+		if len(item.Authors) == 0 {
+			item.Authors = f.Authors
+		}
+		for _, c := range e.Categories {
+			if c.Term != "" {
+				item.Categories = append(item.Categories, c.Term)
+			}
+		}
+		for _, l := range e.Links {
+			if l.Rel == "enclosure" {
+				item.Enclosures = append(item.Enclosures, Enclosure{URL: l.Href, Type: l.Type, Length: l.Length})
+			}
+		}
+		f.Items = append(f.Items, item)
+	}
+
+	return f, nil
+}
+
+// atomLinkHref returns the href of the first link with the given rel,
+// where a missing rel means alternate.
+func atomLinkHref(links []atomLink, rel string) string {
+	for _, l := range links {
+		r := l.Rel
+		if r == "" {
+			r = "alternate"
+		}
+		if r == rel {
+			return l.Href
+		}
+	}
+	return ""
+}
+
+func atomAuthors(persons []atomPerson) []Person {
+	var authors []Person
+	for _, p := range persons {
+		authors = append(authors, Person{Name: text(p.Name), Email: text(p.Email), URL: text(p.URI)})
+	}
+	return authors
+}
+
+type jsonAuthor struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Avatar string `json:"avatar"`
+}
+
+type jsonFeed struct {
+	Version     string       `json:"version"`
+	Title       string       `json:"title"`
+	HomePageURL string       `json:"home_page_url"`
+	FeedURL     string       `json:"feed_url"`
+	Description string       `json:"description"`
+	Icon        string       `json:"icon"`
+	Language    string       `json:"language"`
+	Author      *jsonAuthor  `json:"author"`
+	Authors     []jsonAuthor `json:"authors"`
+	Items       []struct {
+		ID            json.RawMessage `json:"id"`
+		URL           string          `json:"url"`
+		Title         string          `json:"title"`
+		ContentHTML   string          `json:"content_html"`
+		ContentText   string          `json:"content_text"`
+		Summary       string          `json:"summary"`
+		Image         string          `json:"image"`
+		DatePublished string          `json:"date_published"`
+		DateModified  string          `json:"date_modified"`
+		Author        *jsonAuthor     `json:"author"`
+		Authors       []jsonAuthor    `json:"authors"`
+		Tags          []string        `json:"tags"`
+		Attachments   []struct {
+			URL      string `json:"url"`
+			MimeType string `json:"mime_type"`
+			Size     int64  `json:"size_in_bytes"`
+		} `json:"attachments"`
+	} `json:"items"`
+}
+
+func parseJSON(b []byte) (*Feed, error) {
+	var doc jsonFeed
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, errors.Wrap(err, "feed: failed to parse JSON Feed")
+	}
+	if !strings.Contains(doc.Version, "jsonfeed.org") {
+		return nil, errors.Errorf("feed: unsupported JSON Feed version %q", doc.Version)
+	}
+
+	f := &Feed{
+		Format:      FormatJSON,
+		Title:       doc.Title,
+		Description: doc.Description,
+		Link:        doc.HomePageURL,
+		FeedLink:    doc.FeedURL,
+		Language:    doc.Language,
+		Image:       doc.Icon,
+		Authors:     jsonAuthors(doc.Author, doc.Authors),
+	}
+
+	for _, it := range doc.Items {
+		// The id is a string, but some feeds use numbers.
+		var id interface{}
+		json.Unmarshal(it.ID, &id)
+
+		item := Item{
+			ID:         strings.TrimSpace(toString(id)),
+			Title:      it.Title,
+			Link:       it.URL,
+			Summary:    it.Summary,
+			Content:    first(it.ContentHTML, html.EscapeString(it.ContentText)),
+			Published:  parseDate(it.DatePublished),
+			Updated:    parseDate(first(it.DateModified, it.DatePublished)),
+			Authors:    jsonAuthors(it.Author, it.Authors),
+			Categories: it.Tags,
+			Image:      it.Image,
+		}
+		if len(item.Authors) == 0 {
+			item.Authors = f.Authors
+		}
+		for _, a := range it.Attachments {
+			item.Enclosures = append(item.Enclosures, Enclosure{URL: a.URL, Type: a.MimeType, Length: a.Size})
+		}
+		f.Items = append(f.Items, item)
+	}
+
+	return f, nil
+}
+
+func jsonAuthors(author *jsonAuthor, authors []jsonAuthor) []Person {
+	if author != nil {
+		authors = append([]jsonAuthor{*author}, authors...)
+	}
+	var persons []Person
+	for _, a := range authors {
+		persons = append(persons, Person{Name: a.Name, URL: a.URL})
+	}
+	return persons
+}
+
+var dateLayouts = []string{
+	time.RFC3339,
+	time.RFC3339Nano,
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"Mon, 2 Jan 2006 15:04 -0700",
+	"Mon, 2 Jan 2006 15:04 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04:05 MST",
+	time.RFC822Z,
+	time.RFC822,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// parseDate parses the date formats used in feeds. It returns the zero
+// time if s could not be parsed.
+func parseDate(s string) time.Time {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}
+	}
+	// Some feeds use "GMT+00:00" and similar.
+	s = strings.Replace(s, "GMT+", "+", 1)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func text(s string) string {
+	return strings.TrimSpace(s)
+}
+
+func texts(s []string) []string {
+	var result []string
+	for _, v := range s {
+		if v = text(v); v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func first(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func toString(v interface{}) string {
+	switch vv := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return vv
+	default:
+		b, _ := json.Marshal(vv)
+		return string(b)
+	}
+}
@@ -0,0 +1,241 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package feed
+
+import (
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+const rss2 = `<?xml version="1.0" encoding="utf-8"?>
+<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:media="http://search.yahoo.com/mrss/">
+  <channel>
+    <title>Jane's Blog</title>
+    <link>https://jane.example.org/</link>
+    <atom:link href="https://jane.example.org/index.xml" rel="self" type="application/rss+xml" />
+    <description>Notes &amp; thoughts</description>
+    <language>en-us</language>
+    <lastBuildDate>Tue, 01 Jun 2021 10:00:00 +0000</lastBuildDate>
+    <item>
+      <title>Second Post</title>
+      <link>https://jane.example.org/posts/second/</link>
+      <guid isPermaLink="true">https://jane.example.org/posts/second/</guid>
+      <pubDate>Tue, 1 Jun 2021 10:00:00 GMT</pubDate>
+      <author>jane@example.org (Jane Doe)</author>
+      <category>hugo</category>
+      <category>go</category>
+      <description>A summary.</description>
+      <content:encoded><![CDATA[<p>The full <em>content</em>.</p>]]></content:encoded>
+      <media:thumbnail url="https://jane.example.org/second.jpg" />
+    </item>
+    <item>
+      <title>Episode 1</title>
+      <link>https://jane.example.org/posts/first/</link>
+      <pubDate>Mon, 31 May 2021 08:30:00 -0400</pubDate>
+      <dc:creator>John</dc:creator>
+      <description>&lt;p&gt;First&lt;/p&gt;</description>
+      <enclosure url="https://jane.example.org/ep1.mp3" type="audio/mpeg" length="1234" />
+    </item>
+  </channel>
+</rss>`
+
+const rss1 = `<?xml version="1.0"?>
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/" xmlns:dc="http://purl.org/dc/elements/1.1/">
+  <channel rdf:about="https://example.org/">
+    <title>RDF Site</title>
+    <link>https://example.org/</link>
+    <description>An RSS 1.0 feed</description>
+  </channel>
+  <item rdf:about="https://example.org/a">
+    <title>A</title>
+    <link>https://example.org/a</link>
+    <dc:date>2021-05-01T12:00:00Z</dc:date>
+    <dc:subject>rdf</dc:subject>
+  </item>
+</rdf:RDF>`
+
+const atom = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom" xml:lang="nb">
+  <title type="text">Atom Site</title>
+  <subtitle>Everything Atom</subtitle>
+  <link href="https://atom.example.org/" />
+  <link href="https://atom.example.org/atom.xml" rel="self" />
+  <updated>2021-06-02T08:00:00Z</updated>
+  <author><name>Ada</name><uri>https://ada.example.org</uri></author>
+  <logo>https://atom.example.org/logo.png</logo>
+  <entry>
+    <id>urn:uuid:1</id>
+    <title>Hello &amp; welcome</title>
+    <link rel="alternate" href="https://atom.example.org/hello/" />
+    <link rel="enclosure" href="https://atom.example.org/hello.mp4" type="video/mp4" length="42" />
+    <updated>2021-06-02T08:00:00Z</updated>
+    <summary>Plain &lt;text&gt;</summary>
+    <content type="xhtml"><div xmlns="http://www.w3.org/1999/xhtml"><p>Hello</p></div></content>
+    <category term="intro" />
+  </entry>
+  <entry>
+    <id>urn:uuid:2</id>
+    <title>HTML</title>
+    <published>2021-06-01T08:00:00+02:00</published>
+    <updated>2021-06-03T08:00:00+02:00</updated>
+    <content type="html">&lt;p&gt;Escaped&lt;/p&gt;</content>
+    <author><name>Bob</name><email>bob@example.org</email></author>
+  </entry>
+</feed>`
+
+const jsonFeedV1 = `{
+  "version": "https://jsonfeed.org/version/1.1",
+  "title": "JSON Site",
+  "home_page_url": "https://json.example.org/",
+  "feed_url": "https://json.example.org/feed.json",
+  "authors": [{"name": "Jay", "url": "https://jay.example.org"}],
+  "items": [
+    {
+      "id": 1,
+      "url": "https://json.example.org/one/",
+      "title": "One",
+      "content_text": "Plain <text>",
+      "date_published": "2021-06-01T10:00:00Z",
+      "tags": ["json"],
+      "attachments": [{"url": "https://json.example.org/one.mp3", "mime_type": "audio/mpeg", "size_in_bytes": 99}]
+    },
+    {
+      "id": "two",
+      "title": "Two",
+      "content_html": "<p>Two</p>",
+      "date_published": "2021-06-02T10:00:00Z",
+      "date_modified": "2021-06-03T10:00:00Z",
+      "author": {"name": "Kay"}
+    }
+  ]
+}`
+
+func TestParse(t *testing.T) {
+	c := qt.New(t)
+
+	c.Run("RSS 2.0", func(c *qt.C) {
+		f, err := Parse([]byte(rss2))
+		c.Assert(err, qt.IsNil)
+		c.Assert(f.Format, qt.Equals, FormatRSS)
+		c.Assert(f.Title, qt.Equals, "Jane's Blog")
+		c.Assert(f.Description, qt.Equals, "Notes & thoughts")
+		c.Assert(f.Link, qt.Equals, "https://jane.example.org/")
+		c.Assert(f.FeedLink, qt.Equals, "https://jane.example.org/index.xml")
+		c.Assert(f.Language, qt.Equals, "en-us")
+		c.Assert(f.Updated.UTC(), qt.Equals, time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC))
+		c.Assert(f.Items, qt.HasLen, 2)
+
+		it := f.Items[0]
+		c.Assert(it.ID, qt.Equals, "https://jane.example.org/posts/second/")
+		c.Assert(it.Title, qt.Equals, "Second Post")
+		c.Assert(it.Summary, qt.Equals, "A summary.")
+		c.Assert(it.Content, qt.Equals, "<p>The full <em>content</em>.</p>")
+		c.Assert(it.Published.Unix(), qt.Equals, time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC).Unix())
+		c.Assert(it.Authors, qt.DeepEquals, []Person{{Name: "Jane Doe", Email: "jane@example.org"}})
+		c.Assert(it.Categories, qt.DeepEquals, []string{"hugo", "go"})
+		c.Assert(it.Image, qt.Equals, "https://jane.example.org/second.jpg")
+
+		it = f.Items[1]
+		c.Assert(it.ID, qt.Equals, "https://jane.example.org/posts/first/")
+		c.Assert(it.Content, qt.Equals, "<p>First</p>")
+		c.Assert(it.Published.UTC(), qt.Equals, time.Date(2021, 5, 31, 12, 30, 0, 0, time.UTC))
+		c.Assert(it.Authors, qt.DeepEquals, []Person{{Name: "John"}})
+		c.Assert(it.Enclosures, qt.DeepEquals, []Enclosure{{URL: "https://jane.example.org/ep1.mp3", Type: "audio/mpeg", Length: 1234}})
+	})
+
+	c.Run("RSS 1.0", func(c *qt.C) {
+		f, err := Parse([]byte(rss1))
+		c.Assert(err, qt.IsNil)
+		c.Assert(f.Format, qt.Equals, FormatRSS)
+		c.Assert(f.Title, qt.Equals, "RDF Site")
+		c.Assert(f.Items, qt.HasLen, 1)
+		c.Assert(f.Items[0].Link, qt.Equals, "https://example.org/a")
+		c.Assert(f.Items[0].Published, qt.Equals, time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC))
+		c.Assert(f.Items[0].Categories, qt.DeepEquals, []string{"rdf"})
+	})
+
+	c.Run("Atom", func(c *qt.C) {
+		f, err := Parse([]byte(atom))
+		c.Assert(err, qt.IsNil)
+		c.Assert(f.Format, qt.Equals, FormatAtom)
+		c.Assert(f.Title, qt.Equals, "Atom Site")
+		c.Assert(f.Description, qt.Equals, "Everything Atom")
+		c.Assert(f.Link, qt.Equals, "https://atom.example.org/")
+		c.Assert(f.FeedLink, qt.Equals, "https://atom.example.org/atom.xml")
+		c.Assert(f.Language, qt.Equals, "nb")
+		c.Assert(f.Image, qt.Equals, "https://atom.example.org/logo.png")
+		c.Assert(f.Authors, qt.DeepEquals, []Person{{Name: "Ada", URL: "https://ada.example.org"}})
+		c.Assert(f.Items, qt.HasLen, 2)
+
+		it := f.Items[0]
+		c.Assert(it.ID, qt.Equals, "urn:uuid:1")
+		c.Assert(it.Title, qt.Equals, "Hello & welcome")
+		c.Assert(it.Link, qt.Equals, "https://atom.example.org/hello/")
+		c.Assert(it.Summary, qt.Equals, "Plain &lt;text&gt;")
+		c.Assert(it.Content, qt.Contains, "<p>Hello</p>")
+		c.Assert(it.Published, qt.Equals, time.Date(2021, 6, 2, 8, 0, 0, 0, time.UTC))
+		c.Assert(it.Authors, qt.DeepEquals, f.Authors)
+		c.Assert(it.Categories, qt.DeepEquals, []string{"intro"})
+		c.Assert(it.Enclosures, qt.DeepEquals, []Enclosure{{URL: "https://atom.example.org/hello.mp4", Type: "video/mp4", Length: 42}})
+
+		it = f.Items[1]
+		c.Assert(it.Content, qt.Equals, "<p>Escaped</p>")
+		c.Assert(it.Published.UTC(), qt.Equals, time.Date(2021, 6, 1, 6, 0, 0, 0, time.UTC))
+		c.Assert(it.Updated.UTC(), qt.Equals, time.Date(2021, 6, 3, 6, 0, 0, 0, time.UTC))
+		c.Assert(it.Authors, qt.DeepEquals, []Person{{Name: "Bob", Email: "bob@example.org"}})
+	})
+
+	c.Run("JSON Feed", func(c *qt.C) {
+		f, err := Parse([]byte(jsonFeedV1))
+		c.Assert(err, qt.IsNil)
+		c.Assert(f.Format, qt.Equals, FormatJSON)
+		c.Assert(f.Title, qt.Equals, "JSON Site")
+		c.Assert(f.Link, qt.Equals, "https://json.example.org/")
+		c.Assert(f.FeedLink, qt.Equals, "https://json.example.org/feed.json")
+		c.Assert(f.Items, qt.HasLen, 2)
+
+		it := f.Items[0]
+		c.Assert(it.ID, qt.Equals, "1")
+		c.Assert(it.Content, qt.Equals, "Plain &lt;text&gt;")
+		c.Assert(it.Authors, qt.DeepEquals, []Person{{Name: "Jay", URL: "https://jay.example.org"}})
+		c.Assert(it.Categories, qt.DeepEquals, []string{"json"})
+		c.Assert(it.Enclosures, qt.DeepEquals, []Enclosure{{URL: "https://json.example.org/one.mp3", Type: "audio/mpeg", Length: 99}})
+
+		it = f.Items[1]
+		c.Assert(it.ID, qt.Equals, "two")
+		c.Assert(it.Content, qt.Equals, "<p>Two</p>")
+		c.Assert(it.Updated, qt.Equals, time.Date(2021, 6, 3, 10, 0, 0, 0, time.UTC))
+		c.Assert(it.Authors, qt.DeepEquals, []Person{{Name: "Kay"}})
+	})
+
+	c.Run("Charset", func(c *qt.C) {
+		f, err := Parse([]byte("<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?><rss><channel><title>Bl\xe5b\xe6r</title></channel></rss>"))
+		c.Assert(err, qt.IsNil)
+		c.Assert(f.Title, qt.Equals, "Blåbær")
+	})
+
+	c.Run("Errors", func(c *qt.C) {
+		for _, invalid := range []string{
+			"",
+			"<html><body>Not a feed</body></html>",
+			`{"title": "Not a feed"}`,
+			"not xml",
+		} {
+			_, err := Parse([]byte(invalid))
+			c.Assert(err, qt.Not(qt.IsNil), qt.Commentf(invalid))
+		}
+	})
+}
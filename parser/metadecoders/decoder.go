@@ -19,6 +19,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/gohugoio/hugo/common/herrors"
@@ -40,16 +42,50 @@ type Decoder struct {
 	// Comment, if not 0, is the comment character ued in the CSV decoder. Lines beginning with the
 	// Comment character without preceding whitespace are ignored.
 	Comment rune
+
+	// TargetType is the target data type in the CSV decoder, either "slice"
+	// or "map". With "map", the first row is used as the header, and each
+	// of the following rows is decoded into a map keyed by the header.
+	// It defaults to "slice".
+	TargetType string
+
+	// ColumnTypes converts the values in the given CSV columns to one of
+	// "string", "int", "float", "bool" or "time". The columns are identified by
+	// their header name with TargetType "map", else by their index, starting at 0.
+	// Empty values are decoded as nil.
+	ColumnTypes map[string]string
 }
 
+// The CSV target types.
+const (
+	csvTargetTypeSlice = "slice"
+	csvTargetTypeMap   = "map"
+)
+
 // OptionsKey is used in cache keys.
 func (d Decoder) OptionsKey() string {
 	var sb strings.Builder
 	sb.WriteRune(d.Delimiter)
 	sb.WriteRune(d.Comment)
+	sb.WriteString(d.TargetType)
+	columns := make([]string, 0, len(d.ColumnTypes))
+	for k := range d.ColumnTypes {
+		columns = append(columns, k)
+	}
+	sort.Strings(columns)
+	for _, k := range columns {
+		sb.WriteString(k)
+		sb.WriteRune('=')
+		sb.WriteString(d.ColumnTypes[k])
+	}
 	return sb.String()
 }
 
+// IsDefault reports whether d is configured as Default.
+func (d Decoder) IsDefault() bool {
+	return d.OptionsKey() == Default.OptionsKey()
+}
+
 // Default is a Decoder in its default configuration.
 var Default = Decoder{
 	Delimiter: ',',
@@ -114,7 +150,7 @@ func (d Decoder) UnmarshalStringTo(data string, typ interface{}) (interface{}, e
 func (d Decoder) Unmarshal(data []byte, f Format) (interface{}, error) {
 	if data == nil {
 		switch f {
-		case CSV:
+		case CSV, TSV:
 			return make([][]string, 0), nil
 		default:
 			return make(map[string]interface{}), nil
@@ -169,6 +205,9 @@ func (d Decoder) UnmarshalTo(data []byte, f Format, v interface{}) error {
 		}
 	case CSV:
 		return d.unmarshalCSV(data, v)
+	case TSV:
+		d.Delimiter = '\t'
+		return d.unmarshalCSV(data, v)
 
 	default:
 		return errors.Errorf("unmarshal of format %q is not supported", f)
@@ -191,17 +230,89 @@ func (d Decoder) unmarshalCSV(data []byte, v interface{}) error {
 		return err
 	}
 
-	switch v.(type) {
-	case *interface{}:
-		*v.(*interface{}) = records
-	default:
+	vv, ok := v.(*interface{})
+	if !ok {
 		return errors.Errorf("CSV cannot be unmarshaled into %T", v)
+	}
 
+	switch d.TargetType {
+	case "", csvTargetTypeSlice:
+		if len(d.ColumnTypes) == 0 {
+			*vv = records
+			return nil
+		}
+		rows := make([]interface{}, len(records))
+		for i, record := range records {
+			row := make([]interface{}, len(record))
+			for j, s := range record {
+				if row[j], err = d.convertCSVValue(strconv.Itoa(j), s); err != nil {
+					return errors.Wrapf(err, "row %d", i+1)
+				}
+			}
+			rows[i] = row
+		}
+		*vv = rows
+	case csvTargetTypeMap:
+		rows := make([]interface{}, 0)
+		if len(records) == 0 {
+			*vv = rows
+			return nil
+		}
+		header := records[0]
+		for i, record := range records[1:] {
+			row := make(map[string]interface{}, len(header))
+			for j, name := range header {
+				if row[name], err = d.convertCSVValue(name, record[j]); err != nil {
+					return errors.Wrapf(err, "row %d", i+2)
+				}
+			}
+			rows = append(rows, row)
+		}
+		*vv = rows
+	default:
+		return errors.Errorf("invalid CSV target type %q, must be %q or %q", d.TargetType, csvTargetTypeSlice, csvTargetTypeMap)
 	}
 
 	return nil
 }
 
+// convertCSVValue converts s in the given CSV column to the type set in ColumnTypes.
+func (d Decoder) convertCSVValue(column, s string) (interface{}, error) {
+	typ, found := d.ColumnTypes[column]
+	if !found || typ == "string" {
+		return s, nil
+	}
+
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+
+	var (
+		v   interface{}
+		err error
+	)
+
+	switch typ {
+	case "int":
+		v, err = strconv.Atoi(s)
+	case "float":
+		v, err = strconv.ParseFloat(s, 64)
+	case "bool":
+		v, err = strconv.ParseBool(s)
+	case "time":
+		v, err = cast.ToTimeE(s)
+	default:
+		return nil, errors.Errorf("invalid type %q for column %q, must be one of string, int, float, bool or time", typ, column)
+	}
+
+	if err != nil {
+		return nil, errors.Errorf("column %q: failed to convert %q to %s", column, s, typ)
+	}
+
+	return v, nil
+}
+
 func parseORGDate(s string) string {
 	r := regexp.MustCompile(`[<\[](\d{4}-\d{2}-\d{2}) .*[>\]]`)
 	if m := r.FindStringSubmatch(s); m != nil {
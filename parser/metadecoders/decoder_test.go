@@ -16,6 +16,7 @@ package metadecoders
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	qt "github.com/frankban/quicktest"
 )
@@ -73,6 +74,7 @@ func TestUnmarshalToInterface(t *testing.T) {
 		{`a = "b"`, TOML, expect},
 		{`a: "b"`, YAML, expect},
 		{`a,b,c`, CSV, [][]string{{"a", "b", "c"}}},
+		{"a\tb\tc", TSV, [][]string{{"a", "b", "c"}}},
 		{"a: Easy!\nb:\n  c: 2\n  d: [3, 4]", YAML, map[string]interface{}{"a": "Easy!", "b": map[string]interface{}{"c": 2, "d": []interface{}{3, 4}}}},
 		// errors
 		{`a = "`, TOML, false},
@@ -89,6 +91,54 @@ func TestUnmarshalToInterface(t *testing.T) {
 	}
 }
 
+func TestUnmarshalCSV(t *testing.T) {
+	c := qt.New(t)
+
+	const data = `name,price,inStock,released
+Shirt,32.5,true,2021-06-01
+Hat,,false,2021-01-15
+`
+
+	d := Default
+	d.TargetType = "map"
+	d.ColumnTypes = map[string]string{"price": "float", "inStock": "bool", "released": "time"}
+
+	m, err := d.Unmarshal([]byte(data), CSV)
+	c.Assert(err, qt.IsNil)
+	c.Assert(m, qt.DeepEquals, []interface{}{
+		map[string]interface{}{"name": "Shirt", "price": 32.5, "inStock": true, "released": time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)},
+		map[string]interface{}{"name": "Hat", "price": nil, "inStock": false, "released": time.Date(2021, 1, 15, 0, 0, 0, 0, time.UTC)},
+	})
+
+	d = Default
+	d.ColumnTypes = map[string]string{"1": "int"}
+	m, err = d.Unmarshal([]byte("a,1\nb,2"), CSV)
+	c.Assert(err, qt.IsNil)
+	c.Assert(m, qt.DeepEquals, []interface{}{[]interface{}{"a", 1}, []interface{}{"b", 2}})
+
+	d = Default
+	d.TargetType = "map"
+	m, err = d.Unmarshal([]byte("a\tb\n1\t2"), TSV)
+	c.Assert(err, qt.IsNil)
+	c.Assert(m, qt.DeepEquals, []interface{}{map[string]interface{}{"a": "1", "b": "2"}})
+
+	c.Assert(d.OptionsKey(), qt.Not(qt.Equals), Default.OptionsKey())
+	c.Assert(Default.IsDefault(), qt.IsTrue)
+
+	// Errors.
+	d = Default
+	d.TargetType = "list"
+	_, err = d.Unmarshal([]byte(data), CSV)
+	c.Assert(err, qt.ErrorMatches, `invalid CSV target type "list".*`)
+	d.TargetType = "map"
+	d.ColumnTypes = map[string]string{"price": "int"}
+	_, err = d.Unmarshal([]byte(data), CSV)
+	c.Assert(err, qt.ErrorMatches, `row 2: column "price": failed to convert "32.5" to int`)
+	d.ColumnTypes = map[string]string{"price": "decimal"}
+	_, err = d.Unmarshal([]byte(data), CSV)
+	c.Assert(err, qt.ErrorMatches, `row 2: invalid type "decimal" for column "price".*`)
+}
+
 func TestUnmarshalStringTo(t *testing.T) {
 	c := qt.New(t)
 
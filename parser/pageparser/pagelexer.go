@@ -63,6 +63,9 @@ func (l *pageLexer) Input() []byte {
 
 type Config struct {
 	EnableEmoji bool
+
+	// If set, stop after any front matter; the main content is not lexed.
+	FrontMatterOnly bool
 }
 
 // note: the input position here is normally 0 (start), but
@@ -429,6 +432,11 @@ func lexMainSection(l *pageLexer) stateFunc {
 		return lexEndFrontMatterHTMLComment
 	}
 
+	if l.cfg.FrontMatterOnly {
+		l.ignore()
+		return lexDone
+	}
+
 	// Fast forward as far as possible.
 	skip := l.sectionHandlers.skip()
 
@@ -94,7 +94,8 @@ func FormatFromFrontMatterType(typ ItemType) metadecoders.Format {
 	}
 }
 
-// ParseMain parses starting with the main section. Used in tests.
+// ParseMain parses starting with the main section, i.e. content without
+// front matter.
 func ParseMain(r io.Reader, cfg Config) (Result, error) {
 	return parseSection(r, cfg, lexMainSection)
 }
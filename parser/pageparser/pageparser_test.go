@@ -88,3 +88,39 @@ func TestFormatFromFrontMatterType(t *testing.T) {
 		c.Assert(FormatFromFrontMatterType(test.typ), qt.Equals, test.expect)
 	}
 }
+
+func TestParseFrontMatterOnly(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range []struct {
+		name  string
+		input string
+		front string
+	}{
+		{"YAML", "---\nfoo: bar\n---\nSome text {{< sc1 >}}\n<!--more-->\nMore.", "foo: bar\n"},
+		{"YAML commented out", "<!--\n---\nfoo: bar\n---\n-->\nSome text {{< sc1 >}}", "foo: bar\n"},
+		{"No front matter", "Some text {{< sc1 >}}", ""},
+	} {
+		c.Run(test.name, func(c *qt.C) {
+			res, err := Parse(strings.NewReader(test.input), Config{FrontMatterOnly: true})
+			c.Assert(err, qt.IsNil)
+
+			var front string
+			iter := res.Iterator()
+			for {
+				it := iter.Next()
+				c.Assert(it.IsError(), qt.IsFalse)
+				c.Assert(it.IsText(), qt.IsFalse)
+				c.Assert(it.IsLeftShortcodeDelim(), qt.IsFalse)
+				c.Assert(it.Type == TypeLeadSummaryDivider, qt.IsFalse)
+				if it.IsFrontMatter() {
+					front = it.ValStr()
+				}
+				if it.IsEOF() {
+					break
+				}
+			}
+			c.Assert(front, qt.Equals, test.front)
+		})
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gohugoio/hugo/config"
+	"github.com/gohugoio/hugo/config/configschema"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+	"github.com/spf13/cast"
+)
+
+const pluginsConfigKey = "plugins"
+
+// The capabilities that can be granted to a plugin.
+const (
+	// Allows the plugin to log messages with hugo.log.
+	CapabilityLog = "log"
+
+	// Allows the plugin to import the WASI (wasi_snapshot_preview1) functions,
+	// with stdout and stderr written to the log. There is no file system,
+	// environment or network access.
+	CapabilityWASI = "wasi"
+
+	// Gives the plugin the real time and clock in WASI.
+	// Without this it gets a fake, deterministic clock.
+	CapabilityClock = "clock"
+
+	// Gives the plugin cryptographically secure random numbers in WASI.
+	// Without this it gets a deterministic source.
+	CapabilityRandom = "random"
+)
+
+var capabilities = []string{CapabilityLog, CapabilityWASI, CapabilityClock, CapabilityRandom}
+
+var defaultPluginConfig = PluginConfig{
+	MemoryLimit: 64,
+	Timeout:     30 * time.Second,
+}
+
+// PluginConfig configures a plugin.
+type PluginConfig struct {
+	// The name used to refer to the plugin, e.g. in resources.Plugin.
+	Name string
+
+	// The path to the WASM file, relative to the project directory.
+	Path string
+
+	// The capabilities granted to the plugin.
+	Capabilities []string
+
+	// The maximum memory the plugin can use, in MiB.
+	MemoryLimit int
+
+	// The maximum time a single call to the plugin can take.
+	Timeout time.Duration
+
+	// Passed on to the plugin in the call context.
+	Params map[string]interface{}
+}
+
+func (c PluginConfig) granted(capability string) bool {
+	for _, cc := range c.Capabilities {
+		if cc == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// DecodeConfig decodes the plugins section in the site configuration.
+func DecodeConfig(cfg config.Provider) ([]PluginConfig, error) {
+	if !cfg.IsSet(pluginsConfigKey) {
+		return nil, nil
+	}
+
+	var (
+		configs []PluginConfig
+		seen    = make(map[string]bool)
+	)
+
+	for _, v := range cast.ToSlice(cfg.Get(pluginsConfigKey)) {
+		c := defaultPluginConfig
+
+		dec, err := mapstructure.NewDecoder(
+			&mapstructure.DecoderConfig{
+				WeaklyTypedInput: true,
+				Result:           &c,
+				DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := dec.Decode(v); err != nil {
+			return nil, errors.Wrap(err, "failed to decode plugins config")
+		}
+
+		if c.Name == "" || c.Path == "" {
+			return nil, errors.New("plugins: name and path must be set")
+		}
+		if seen[c.Name] {
+			return nil, errors.Errorf("plugins: duplicate plugin name %q", c.Name)
+		}
+		seen[c.Name] = true
+
+		for i, capability := range c.Capabilities {
+			capability = strings.ToLower(capability)
+			if !isCapability(capability) {
+				return nil, errors.Errorf("plugins: invalid capability %q for plugin %q, must be one of %s", capability, c.Name, strings.Join(capabilities, ", "))
+			}
+			c.Capabilities[i] = capability
+		}
+
+		if c.MemoryLimit <= 0 {
+			return nil, errors.Errorf("plugins: memoryLimit for plugin %q must be > 0", c.Name)
+		}
+
+		configs = append(configs, c)
+	}
+
+	return configs, nil
+}
+
+func isCapability(s string) bool {
+	for _, c := range capabilities {
+		if s == c {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	configschema.AddSection(pluginsConfigKey, []PluginConfig{defaultPluginConfig})
+}
@@ -0,0 +1,139 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// The build hooks a plugin can implement.
+const (
+	// Called before the first build with the site configuration.
+	HookConfigLoaded = "config_loaded"
+
+	// Called when the pages of all sites are assembled, before they are
+	// rendered.
+	HookPagesAssembled = "pages_assembled"
+
+	// Called for every page rendered, with the rendered page as the input.
+	HookPageRendered = "page_rendered"
+
+	// Called when the build is done and all files are published.
+	HookSitePublished = "site_published"
+)
+
+var hooks = []string{HookConfigLoaded, HookPagesAssembled, HookPageRendered, HookSitePublished}
+
+func hookFuncName(hook string) string {
+	return "hugo_on_" + hook
+}
+
+// HookContext is passed to the build hooks as JSON.
+type HookContext struct {
+	// The hook, e.g. "page_rendered".
+	Hook string `json:"hook"`
+
+	// A subset of the site configuration. Only set for config_loaded.
+	Config map[string]interface{} `json:"config,omitempty"`
+
+	// The pages in all sites. Only set for pages_assembled.
+	Pages []HookPage `json:"pages,omitempty"`
+
+	// The page rendered, its output format and the path it is published
+	// to. Only set for page_rendered.
+	Page         *HookPage `json:"page,omitempty"`
+	OutputFormat string    `json:"outputFormat,omitempty"`
+	TargetPath   string    `json:"targetPath,omitempty"`
+
+	// The absolute publish directory. Only set for site_published.
+	PublishDir string `json:"publishDir,omitempty"`
+
+	// The params from the plugin config.
+	Params map[string]interface{} `json:"params"`
+}
+
+// HookPage is a page as passed to the build hooks.
+type HookPage struct {
+	Kind         string                 `json:"kind"`
+	Lang         string                 `json:"lang"`
+	Path         string                 `json:"path,omitempty"`
+	Section      string                 `json:"section,omitempty"`
+	Title        string                 `json:"title,omitempty"`
+	Permalink    string                 `json:"permalink"`
+	RelPermalink string                 `json:"relPermalink"`
+	Draft        bool                   `json:"draft,omitempty"`
+	Date         time.Time              `json:"date"`
+	Params       map[string]interface{} `json:"params,omitempty"`
+}
+
+// HasHook reports whether any plugin implements the given build hook.
+func (p *Plugins) HasHook(hook string) bool {
+	if p == nil {
+		return false
+	}
+	for _, plugin := range p.plugins {
+		if plugin.HasHook(hook) {
+			return true
+		}
+	}
+	return false
+}
+
+// RunHook calls the given build hook in all the plugins implementing it,
+// in the configured order, stopping at the first error.
+func (p *Plugins) RunHook(c HookContext, in []byte) error {
+	if p == nil {
+		return nil
+	}
+	for _, plugin := range p.plugins {
+		if !plugin.HasHook(c.Hook) {
+			continue
+		}
+		if err := plugin.RunHook(c, in); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HasHook reports whether the plugin implements the given build hook.
+func (p *Plugin) HasHook(hook string) bool {
+	return p != nil && p.hooks[hook]
+}
+
+// RunHook calls the plugin's function for the build hook c.Hook with in
+// as the input.
+func (p *Plugin) RunHook(c HookContext, in []byte) error {
+	if !p.HasHook(c.Hook) {
+		return errors.Errorf("plugin %q does not implement the %s hook", p.cfg.Name, c.Hook)
+	}
+
+	c.Params = p.cfg.Params
+	if c.Params == nil {
+		c.Params = make(map[string]interface{})
+	}
+	cb, err := json.Marshal(c)
+	if err != nil {
+		return errors.Wrapf(err, "plugin %q", p.cfg.Name)
+	}
+
+	if err := p.call(hookFuncName(c.Hook), cb, in, nil); err != nil {
+		return errors.Wrapf(err, "plugin %q: %s", p.cfg.Name, c.Hook)
+	}
+
+	return nil
+}
@@ -0,0 +1,484 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package plugins runs WASM plugins transforming content, resources and
+// published files in a sandbox.
+//
+// A plugin must export its memory, a function to allocate memory for the
+// input and one or more transform functions:
+//
+//	hugo_alloc(size i32) i32
+//	hugo_transform_content(ctxPtr, ctxLen, ptr, len i32) i64
+//	hugo_transform_resource(ctxPtr, ctxLen, ptr, len i32) i64
+//	hugo_transform_output(ctxPtr, ctxLen, ptr, len i32) i64
+//
+// The transform functions get the Context as JSON and the input, and return
+// the address of the output in the upper and its length in the lower
+// 32 bits. Every call runs in a new instance of the plugin.
+//
+// Instead of, or in addition to, the transform functions, a plugin can
+// export functions to run at the stages of a build, see HookContext:
+//
+//	hugo_on_config_loaded(ctxPtr, ctxLen, ptr, len i32)
+//	hugo_on_pages_assembled(ctxPtr, ctxLen, ptr, len i32)
+//	hugo_on_page_rendered(ctxPtr, ctxLen, ptr, len i32)
+//	hugo_on_site_published(ctxPtr, ctxLen, ptr, len i32)
+//
+// Any value returned from these is ignored. A hook fails the build by
+// calling error.
+//
+// A plugin can import these functions from the "hugo" module:
+//
+//	log(level, ptr, len i32)  // level 0 is info, 1 is warning; requires the log capability
+//	error(ptr, len i32)       // fails the call with the given message
+package plugins
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+
+	"github.com/gohugoio/hugo/common/loggers"
+	"github.com/gohugoio/hugo/config"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/sys"
+)
+
+// The kinds of transformations a plugin can implement.
+const (
+	// Transforms the content source of a page, before it is rendered.
+	KindContent = "content"
+
+	// Transforms a resource, see resources.Plugin.
+	KindResource = "resource"
+
+	// Transforms a file before it is published.
+	KindOutput = "output"
+
+	// Transforms the HTML of a published file in an htmlPostProcess step.
+	KindHTML = "html"
+)
+
+const (
+	hostModuleName = "hugo"
+	allocFuncName  = "hugo_alloc"
+)
+
+var kinds = []string{KindContent, KindResource, KindOutput, KindHTML}
+
+func transformFuncName(kind string) string {
+	return "hugo_transform_" + kind
+}
+
+// Context is passed to the plugin as JSON.
+type Context struct {
+	// The kind of transformation, e.g. "content".
+	Kind string `json:"kind"`
+
+	// The path of the content file, resource or published file.
+	Path string `json:"path"`
+
+	// The markup of the content, e.g. "markdown". Only set for content.
+	Markup string `json:"markup,omitempty"`
+
+	// The media type of the resource or published file.
+	MediaType string `json:"mediaType,omitempty"`
+
+	// The params from the plugin config, merged with any options passed
+	// to resources.Plugin.
+	Params map[string]interface{} `json:"params"`
+}
+
+// Plugins holds the configured plugins.
+type Plugins struct {
+	plugins []*Plugin
+}
+
+// New compiles the plugins in the site configuration, nil if none is
+// configured.
+func New(fs afero.Fs, cfg config.Provider, logger loggers.Logger) (*Plugins, error) {
+	configs, err := DecodeConfig(cfg)
+	if err != nil || len(configs) == 0 {
+		return nil, err
+	}
+
+	if logger == nil {
+		logger = loggers.NewErrorLogger()
+	}
+
+	workingDir := cfg.GetString("workingDir")
+
+	p := &Plugins{}
+	for _, pc := range configs {
+		filename := pc.Path
+		if !filepath.IsAbs(filename) {
+			filename = filepath.Join(workingDir, filename)
+		}
+		b, err := afero.ReadFile(fs, filename)
+		if err != nil {
+			return nil, errors.Wrapf(err, "plugins: failed to read plugin %q", pc.Name)
+		}
+		plugin, err := newPlugin(pc, b, logger)
+		if err != nil {
+			return nil, errors.Wrapf(err, "plugins: failed to load plugin %q", pc.Name)
+		}
+		p.plugins = append(p.plugins, plugin)
+	}
+
+	return p, nil
+}
+
+// Get returns the plugin with the given name, nil if not found.
+func (p *Plugins) Get(name string) *Plugin {
+	if p == nil {
+		return nil
+	}
+	for _, plugin := range p.plugins {
+		if plugin.cfg.Name == name {
+			return plugin
+		}
+	}
+	return nil
+}
+
+// Has reports whether any plugin implements the given kind.
+func (p *Plugins) Has(kind string) bool {
+	if p == nil {
+		return false
+	}
+	for _, plugin := range p.plugins {
+		if plugin.Has(kind) {
+			return true
+		}
+	}
+	return false
+}
+
+// Transform passes in through all the plugins implementing c.Kind, in the
+// configured order.
+func (p *Plugins) Transform(c Context, in []byte) ([]byte, error) {
+	if p == nil {
+		return in, nil
+	}
+	for _, plugin := range p.plugins {
+		if !plugin.Has(c.Kind) {
+			continue
+		}
+		var err error
+		in, err = plugin.Transform(c, in)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return in, nil
+}
+
+// Close closes the runtimes of all the plugins.
+func (p *Plugins) Close() error {
+	if p == nil {
+		return nil
+	}
+	for _, plugin := range p.plugins {
+		plugin.runtime.Close(context.Background())
+	}
+	return nil
+}
+
+// Plugin is a compiled WASM plugin.
+type Plugin struct {
+	cfg    PluginConfig
+	logger loggers.Logger
+
+	runtime  wazero.Runtime
+	compiled wazero.CompiledModule
+
+	kinds map[string]bool
+	hooks map[string]bool
+}
+
+func newPlugin(cfg PluginConfig, b []byte, logger loggers.Logger) (*Plugin, error) {
+	ctx := context.Background()
+
+	r := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().
+		WithMemoryLimitPages(uint32(cfg.MemoryLimit)*16). // 64 KiB pages
+		WithCloseOnContextDone(true))
+
+	p := &Plugin{cfg: cfg, logger: logger, runtime: r, kinds: make(map[string]bool), hooks: make(map[string]bool)}
+
+	if err := p.init(ctx, b); err != nil {
+		r.Close(ctx)
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func (p *Plugin) init(ctx context.Context, b []byte) error {
+	compiled, err := p.runtime.CompileModule(ctx, b)
+	if err != nil {
+		return err
+	}
+	p.compiled = compiled
+
+	var needsWASI bool
+	for _, f := range compiled.ImportedFunctions() {
+		moduleName, name, _ := f.Import()
+		switch {
+		case moduleName == hostModuleName && name == "error":
+		case moduleName == hostModuleName && name == "log":
+			if !p.cfg.granted(CapabilityLog) {
+				return errors.Errorf("imports %s.%s, which requires the %q capability", moduleName, name, CapabilityLog)
+			}
+		case moduleName == wasi_snapshot_preview1.ModuleName:
+			if !p.cfg.granted(CapabilityWASI) {
+				return errors.Errorf("imports %s.%s, which requires the %q capability", moduleName, name, CapabilityWASI)
+			}
+			needsWASI = true
+		default:
+			return errors.Errorf("imports %s.%s, which is not provided", moduleName, name)
+		}
+	}
+
+	if _, found := compiled.ExportedMemories()["memory"]; !found {
+		return errors.New("must export its memory as \"memory\"")
+	}
+
+	exports := compiled.ExportedFunctions()
+	if _, found := exports[allocFuncName]; !found {
+		return errors.Errorf("must export %s", allocFuncName)
+	}
+	for _, kind := range kinds {
+		if _, found := exports[transformFuncName(kind)]; found {
+			p.kinds[kind] = true
+		}
+	}
+	for _, hook := range hooks {
+		if _, found := exports[hookFuncName(hook)]; found {
+			p.hooks[hook] = true
+		}
+	}
+	if len(p.kinds) == 0 && len(p.hooks) == 0 {
+		return errors.New("must export one or more of hugo_transform_content, hugo_transform_resource, hugo_transform_output, hugo_transform_html or a hugo_on_* hook")
+	}
+
+	if needsWASI {
+		if _, err := wasi_snapshot_preview1.Instantiate(ctx, p.runtime); err != nil {
+			return err
+		}
+	}
+
+	_, err = p.runtime.NewHostModuleBuilder(hostModuleName).
+		NewFunctionBuilder().WithFunc(hostLog).Export("log").
+		NewFunctionBuilder().WithFunc(hostError).Export("error").
+		Instantiate(ctx)
+
+	return err
+}
+
+// Name returns the plugin name.
+func (p *Plugin) Name() string {
+	return p.cfg.Name
+}
+
+// Has reports whether the plugin implements the given kind.
+func (p *Plugin) Has(kind string) bool {
+	return p != nil && p.kinds[kind]
+}
+
+// Transform calls the plugin's transform function for c.Kind with in as the
+// input and returns the output.
+func (p *Plugin) Transform(c Context, in []byte) ([]byte, error) {
+	if !p.Has(c.Kind) {
+		return nil, errors.Errorf("plugin %q does not implement %s transformations", p.cfg.Name, c.Kind)
+	}
+
+	out, err := p.transform(c, in)
+	if err != nil {
+		return nil, errors.Wrapf(err, "plugin %q", p.cfg.Name)
+	}
+
+	return out, nil
+}
+
+func (p *Plugin) transform(c Context, in []byte) ([]byte, error) {
+	if len(c.Params) == 0 {
+		c.Params = p.cfg.Params
+	}
+	if c.Params == nil {
+		c.Params = make(map[string]interface{})
+	}
+	cb, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []byte
+	err = p.call(transformFuncName(c.Kind), cb, in, func(mod api.Module, res []uint64) error {
+		if len(res) != 1 {
+			return errors.Errorf("%s must return an i64", transformFuncName(c.Kind))
+		}
+
+		b, ok := mod.Memory().Read(uint32(res[0]>>32), uint32(res[0]))
+		if !ok {
+			return errors.Errorf("%s returned an output outside of memory", transformFuncName(c.Kind))
+		}
+
+		// The memory is gone when the module is closed.
+		out = append([]byte(nil), b...)
+		return nil
+	})
+
+	return out, err
+}
+
+// call calls funcName in a new instance of the plugin with the context cb
+// and the input in. If set, handle gets the results of the call before
+// the instance is closed.
+func (p *Plugin) call(funcName string, cb, in []byte, handle func(mod api.Module, res []uint64) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), p.cfg.Timeout)
+	defer cancel()
+
+	state := &callState{plugin: p}
+	ctx = context.WithValue(ctx, callStateKey{}, state)
+
+	mod, err := p.runtime.InstantiateModule(ctx, p.compiled, p.moduleConfig())
+	if err != nil {
+		return p.callError(err, state)
+	}
+	defer mod.Close(ctx)
+
+	cptr, err := write(ctx, mod, cb)
+	if err != nil {
+		return p.callError(err, state)
+	}
+	ptr, err := write(ctx, mod, in)
+	if err != nil {
+		return p.callError(err, state)
+	}
+
+	res, err := mod.ExportedFunction(funcName).Call(ctx, uint64(cptr), uint64(len(cb)), uint64(ptr), uint64(len(in)))
+	if err != nil || state.err != "" {
+		return p.callError(err, state)
+	}
+
+	if handle == nil {
+		return nil
+	}
+
+	return handle(mod, res)
+}
+
+func (p *Plugin) moduleConfig() wazero.ModuleConfig {
+	mc := wazero.NewModuleConfig().
+		WithName("").
+		WithStartFunctions("_initialize")
+
+	if p.cfg.granted(CapabilityWASI) {
+		mc = mc.WithStdout(logWriter{p: p}).WithStderr(logWriter{p: p, warn: true})
+	}
+	if p.cfg.granted(CapabilityClock) {
+		mc = mc.WithSysWalltime().WithSysNanotime()
+	}
+	if p.cfg.granted(CapabilityRandom) {
+		mc = mc.WithRandSource(rand.Reader)
+	}
+
+	return mc
+}
+
+func (p *Plugin) callError(err error, state *callState) error {
+	if state.err != "" {
+		return errors.New(state.err)
+	}
+	if ee, ok := err.(*sys.ExitError); ok && ee.ExitCode() == sys.ExitCodeDeadlineExceeded {
+		return errors.Errorf("timed out after %s", p.cfg.Timeout)
+	}
+	return err
+}
+
+// write allocates memory for b in the module and writes b to it.
+func write(ctx context.Context, mod api.Module, b []byte) (uint32, error) {
+	res, err := mod.ExportedFunction(allocFuncName).Call(ctx, uint64(len(b)))
+	if err != nil {
+		return 0, err
+	}
+	if len(res) != 1 {
+		return 0, errors.Errorf("%s must return an i32", allocFuncName)
+	}
+	ptr := uint32(res[0])
+	if !mod.Memory().Write(ptr, b) {
+		return 0, errors.Errorf("%s returned an address outside of memory", allocFuncName)
+	}
+	return ptr, nil
+}
+
+type callStateKey struct{}
+
+// callState holds the state of a call to a plugin, available to the host
+// functions.
+type callState struct {
+	plugin *Plugin
+	err    string
+}
+
+func getCallState(ctx context.Context) *callState {
+	return ctx.Value(callStateKey{}).(*callState)
+}
+
+func readString(m api.Module, ptr, size uint32) string {
+	b, _ := m.Memory().Read(ptr, size)
+	return string(b)
+}
+
+func hostLog(ctx context.Context, m api.Module, level, ptr, size uint32) {
+	state := getCallState(ctx)
+	msg := readString(m, ptr, size)
+	if level > 0 {
+		state.plugin.logger.Warnf("plugin %q: %s", state.plugin.cfg.Name, msg)
+	} else {
+		state.plugin.logger.Infof("plugin %q: %s", state.plugin.cfg.Name, msg)
+	}
+}
+
+func hostError(ctx context.Context, m api.Module, ptr, size uint32) {
+	state := getCallState(ctx)
+	state.err = readString(m, ptr, size)
+	if state.err == "" {
+		state.err = "failed"
+	}
+}
+
+// logWriter writes the WASI stdout and stderr of a plugin to the log.
+type logWriter struct {
+	p    *Plugin
+	warn bool
+}
+
+func (w logWriter) Write(b []byte) (int, error) {
+	msg := strings.TrimRight(string(b), "\n")
+	if msg == "" {
+		return len(b), nil
+	}
+	if w.warn {
+		w.p.logger.Warnf("plugin %q: %s", w.p.cfg.Name, msg)
+	} else {
+		w.p.logger.Infof("plugin %q: %s", w.p.cfg.Name, msg)
+	}
+	return len(b), nil
+}
@@ -0,0 +1,204 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/common/loggers"
+	"github.com/gohugoio/hugo/config"
+	"github.com/gohugoio/hugo/plugins/pluginstest"
+	"github.com/spf13/afero"
+	jww "github.com/spf13/jwalterweatherman"
+)
+
+func TestDecodeConfig(t *testing.T) {
+	c := qt.New(t)
+
+	cfg, err := config.FromConfigString(`
+[[plugins]]
+name = "upper"
+path = "plugins/upper.wasm"
+capabilities = ["LOG", "wasi"]
+timeout = "2s"
+[plugins.params]
+foo = "bar"
+`, "toml")
+	c.Assert(err, qt.IsNil)
+
+	configs, err := DecodeConfig(cfg)
+	c.Assert(err, qt.IsNil)
+	c.Assert(configs, qt.HasLen, 1)
+	pc := configs[0]
+	c.Assert(pc.Name, qt.Equals, "upper")
+	c.Assert(pc.Capabilities, qt.DeepEquals, []string{CapabilityLog, CapabilityWASI})
+	c.Assert(pc.Timeout, qt.Equals, 2*time.Second)
+	c.Assert(pc.MemoryLimit, qt.Equals, 64)
+	c.Assert(pc.Params["foo"], qt.Equals, "bar")
+
+	for _, invalid := range []string{
+		`[[plugins]]
+path = "a.wasm"`,
+		`[[plugins]]
+name = "a"
+path = "a.wasm"
+capabilities = ["network"]`,
+		`[[plugins]]
+name = "a"
+path = "a.wasm"
+[[plugins]]
+name = "a"
+path = "b.wasm"`,
+	} {
+		cfg, err := config.FromConfigString(invalid, "toml")
+		c.Assert(err, qt.IsNil)
+		_, err = DecodeConfig(cfg)
+		c.Assert(err, qt.Not(qt.IsNil))
+	}
+}
+
+func TestPlugins(t *testing.T) {
+	c := qt.New(t)
+
+	upper := pluginstest.Upper(KindOutput)
+
+	logAndEcho := pluginstest.BuildModule([]string{"log"}, pluginstest.Func{
+		Export: transformFuncName(KindContent),
+		Params: 4, I64: true,
+		Body: append(
+			[]byte{0x41, 0x00, 0x20, 0x02, 0x20, 0x03, 0x10, 0x00}, // log(0, ptr, len)
+			pluginstest.ReturnInput...,
+		),
+	})
+
+	fail := pluginstest.BuildModule([]string{"error"}, pluginstest.Func{
+		Export: transformFuncName(KindResource),
+		Params: 4, I64: true,
+		Body: []byte{0x20, 0x02, 0x20, 0x03, 0x10, 0x00, 0x42, 0x00}, // error(ptr, len); return 0
+	})
+
+	loop := pluginstest.BuildModule(nil, pluginstest.Func{
+		Export: transformFuncName(KindResource),
+		Params: 4, I64: true,
+		Body: []byte{0x03, 0x40, 0x0c, 0x00, 0x0b, 0x42, 0x00}, // loop br 0 end; return 0
+	})
+
+	fs := afero.NewMemMapFs()
+	for name, b := range map[string][]byte{"upper.wasm": upper, "log.wasm": logAndEcho, "fail.wasm": fail, "loop.wasm": loop} {
+		c.Assert(afero.WriteFile(fs, "/my/project/plugins/"+name, b, 0666), qt.IsNil)
+	}
+
+	newPlugins := func(c *qt.C, cfgStr string) (*Plugins, *bytes.Buffer, error) {
+		cfg, err := config.FromConfigString(cfgStr, "toml")
+		c.Assert(err, qt.IsNil)
+		cfg.Set("workingDir", "/my/project")
+		var buf bytes.Buffer
+		p, err := New(fs, cfg, loggers.NewBasicLoggerForWriter(jww.LevelInfo, &buf))
+		return p, &buf, err
+	}
+
+	c.Run("Transform", func(c *qt.C) {
+		p, logBuf, err := newPlugins(c, `
+[[plugins]]
+name = "upper"
+path = "plugins/upper.wasm"
+[[plugins]]
+name = "log"
+path = "plugins/log.wasm"
+capabilities = ["log"]
+`)
+		c.Assert(err, qt.IsNil)
+		defer p.Close()
+
+		c.Assert(p.Has(KindOutput), qt.IsTrue)
+		c.Assert(p.Has(KindContent), qt.IsTrue)
+		c.Assert(p.Has(KindResource), qt.IsFalse)
+		c.Assert(p.Get("upper").Name(), qt.Equals, "upper")
+		c.Assert(p.Get("foo"), qt.IsNil)
+
+		out, err := p.Transform(Context{Kind: KindOutput, Path: "index.html"}, []byte("<p>Hello, world!</p>"))
+		c.Assert(err, qt.IsNil)
+		c.Assert(string(out), qt.Equals, "<P>HELLO, WORLD!</P>")
+
+		out, err = p.Transform(Context{Kind: KindContent, Path: "post.md"}, []byte("Log me"))
+		c.Assert(err, qt.IsNil)
+		c.Assert(string(out), qt.Equals, "Log me")
+		c.Assert(logBuf.String(), qt.Contains, `plugin "log": Log me`)
+
+		// No plugin implements this kind.
+		out, err = p.Transform(Context{Kind: KindResource}, []byte("a"))
+		c.Assert(err, qt.IsNil)
+		c.Assert(string(out), qt.Equals, "a")
+
+		_, err = p.Get("upper").Transform(Context{Kind: KindResource}, []byte("a"))
+		c.Assert(err, qt.ErrorMatches, `plugin "upper" does not implement resource transformations`)
+	})
+
+	c.Run("Capability not granted", func(c *qt.C) {
+		_, _, err := newPlugins(c, `
+[[plugins]]
+name = "log"
+path = "plugins/log.wasm"
+`)
+		c.Assert(err, qt.ErrorMatches, `.*imports hugo.log, which requires the "log" capability`)
+	})
+
+	c.Run("Error", func(c *qt.C) {
+		p, _, err := newPlugins(c, `
+[[plugins]]
+name = "fail"
+path = "plugins/fail.wasm"
+`)
+		c.Assert(err, qt.IsNil)
+		defer p.Close()
+
+		_, err = p.Get("fail").Transform(Context{Kind: KindResource}, []byte("invalid input"))
+		c.Assert(err, qt.ErrorMatches, `plugin "fail": invalid input`)
+	})
+
+	c.Run("Timeout", func(c *qt.C) {
+		p, _, err := newPlugins(c, `
+[[plugins]]
+name = "loop"
+path = "plugins/loop.wasm"
+timeout = "50ms"
+`)
+		c.Assert(err, qt.IsNil)
+		defer p.Close()
+
+		_, err = p.Get("loop").Transform(Context{Kind: KindResource}, []byte("a"))
+		c.Assert(err, qt.ErrorMatches, `plugin "loop": timed out after 50ms`)
+	})
+
+	c.Run("Invalid", func(c *qt.C) {
+		c.Assert(afero.WriteFile(fs, "/my/project/plugins/invalid.wasm", []byte("not wasm"), 0666), qt.IsNil)
+		_, _, err := newPlugins(c, `
+[[plugins]]
+name = "invalid"
+path = "plugins/invalid.wasm"
+`)
+		c.Assert(err, qt.ErrorMatches, `plugins: failed to load plugin "invalid".*`)
+	})
+
+	c.Run("Nil", func(c *qt.C) {
+		var p *Plugins
+		c.Assert(p.Has(KindContent), qt.IsFalse)
+		out, err := p.Transform(Context{Kind: KindContent}, []byte("a"))
+		c.Assert(err, qt.IsNil)
+		c.Assert(string(out), qt.Equals, "a")
+	})
+}
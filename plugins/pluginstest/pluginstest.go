@@ -0,0 +1,163 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pluginstest builds small WASM modules for testing plugins.
+package pluginstest
+
+import "bytes"
+
+// ReturnInput is the end of a transform function body returning the input,
+// i.e. (ptr << 32) | len.
+var ReturnInput = []byte{
+	0x20, 0x02, 0xad, 0x42, 0x20, 0x86, // i64(ptr) << 32
+	0x20, 0x03, 0xad, 0x84, // | i64(len)
+}
+
+// upperBody upper cases the ASCII letters in the input in place, using the locals
+// i (4) and b (5).
+var upperBody = concat([]byte{
+	0x02, 0x40, // block
+	0x03, 0x40, // loop
+	0x20, 0x04, 0x20, 0x03, 0x4f, 0x0d, 0x01, // br_if 1 (i >= len)
+	0x20, 0x02, 0x20, 0x04, 0x6a, 0x2d, 0x00, 0x00, 0x21, 0x05, // b = load8_u(ptr + i)
+	0x20, 0x02, 0x20, 0x04, 0x6a, // ptr + i
+	0x20, 0x05, 0x41, 0x20, 0x6b, // b - 32
+	0x20, 0x05, // b
+	0x20, 0x05, 0x41, 0xe1, 0x00, 0x6b, 0x41, 0x1a, 0x49, // b - 'a' < 26
+	0x1b,             // select
+	0x3a, 0x00, 0x00, // store8
+	0x20, 0x04, 0x41, 0x01, 0x6a, 0x21, 0x04, // i++
+	0x0c, 0x00, // br 0
+	0x0b, // end loop
+	0x0b, // end block
+}, ReturnInput)
+
+// Func is a function in a test module.
+type Func struct {
+	Export string
+	Params int  // The number of i32 params.
+	I64    bool // Whether the result is an i64, else i32.
+	Locals int  // The number of i32 locals.
+	Body   []byte
+}
+
+// Upper builds a module upper casing the ASCII letters in the input for
+// the given kind, e.g. "output".
+func Upper(kind string) []byte {
+	return BuildModule(nil, Func{
+		Export: "hugo_transform_" + kind,
+		Params: 4, I64: true, Locals: 2,
+		Body: upperBody,
+	})
+}
+
+// BuildModule builds a WASM module exporting its memory, hugo_alloc and f.
+// The imports are functions from the hugo host module, "log" or "error".
+// The function indices of the imports start at 0.
+func BuildModule(imports []string, f Func) []byte {
+	const (
+		i32 = 0x7f
+		i64 = 0x7e
+	)
+
+	sig := func(params int, results ...byte) []byte {
+		b := []byte{0x60, byte(params)}
+		for i := 0; i < params; i++ {
+			b = append(b, i32)
+		}
+		return append(append(b, byte(len(results))), results...)
+	}
+
+	result := byte(i32)
+	if f.I64 {
+		result = i64
+	}
+
+	// Types: 0 is hugo_alloc, 1 is f, 2 is log and 3 is error.
+	types := vec(sig(1, i32), sig(f.Params, result), sig(3), sig(2))
+
+	var importEntries [][]byte
+	for _, name := range imports {
+		typ := byte(2)
+		if name == "error" {
+			typ = 3
+		}
+		importEntries = append(importEntries, concat(str("hugo"), str(name), []byte{0x00, typ}))
+	}
+
+	n := byte(len(imports))
+
+	alloc := []byte{
+		0x23, 0x00, // global.get 0
+		0x23, 0x00, 0x20, 0x00, 0x6a, 0x24, 0x00, // global 0 += size
+		0x0b,
+	}
+
+	locals := []byte{0x00}
+	if f.Locals > 0 {
+		locals = []byte{0x01, byte(f.Locals), i32}
+	}
+
+	return concat(
+		[]byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00},
+		section(1, types),
+		section(2, vec(importEntries...)),
+		section(3, vec([]byte{0x00}, []byte{0x01})),
+		section(5, vec([]byte{0x00, 0x01})),
+		section(6, vec([]byte{i32, 0x01, 0x41, 0x80, 0x08, 0x0b})), // mutable i32 = 1024
+		section(7, vec(
+			concat(str("memory"), []byte{0x02, 0x00}),
+			concat(str("hugo_alloc"), []byte{0x00, n}),
+			concat(str(f.Export), []byte{0x00, n + 1}),
+		)),
+		section(10, vec(
+			sized(concat([]byte{0x00}, alloc)),
+			sized(concat(locals, f.Body, []byte{0x0b})),
+		)),
+	)
+}
+
+func concat(b ...[]byte) []byte {
+	return bytes.Join(b, nil)
+}
+
+func uleb(n int) []byte {
+	var b []byte
+	for {
+		c := byte(n & 0x7f)
+		n >>= 7
+		if n != 0 {
+			c |= 0x80
+		}
+		b = append(b, c)
+		if n == 0 {
+			return b
+		}
+	}
+}
+
+func sized(b []byte) []byte {
+	return concat(uleb(len(b)), b)
+}
+
+func vec(entries ...[]byte) []byte {
+	return concat(uleb(len(entries)), concat(entries...))
+}
+
+func str(s string) []byte {
+	return sized([]byte(s))
+}
+
+func section(id byte, b []byte) []byte {
+	return concat([]byte{id}, sized(b))
+}
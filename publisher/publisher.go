@@ -14,9 +14,12 @@
 package publisher
 
 import (
+	"bytes"
 	"errors"
 	"io"
 	"net/url"
+	"path/filepath"
+	"strings"
 	"sync/atomic"
 
 	"github.com/gohugoio/hugo/resources"
@@ -30,8 +33,13 @@ import (
 
 	"github.com/spf13/afero"
 
+	"github.com/gohugoio/hugo/a11y"
 	"github.com/gohugoio/hugo/output"
+	"github.com/gohugoio/hugo/plugins"
 	"github.com/gohugoio/hugo/transform"
+	"github.com/gohugoio/hugo/transform/email"
+	"github.com/gohugoio/hugo/transform/encrypt"
+	"github.com/gohugoio/hugo/transform/htmlpostprocess"
 	"github.com/gohugoio/hugo/transform/livereloadinject"
 	"github.com/gohugoio/hugo/transform/metainject"
 	"github.com/gohugoio/hugo/transform/urlreplacers"
@@ -65,6 +73,21 @@ type Descriptor struct {
 	// Enable to minify the output using the OutputFormat defined above to
 	// pick the correct minifier configuration.
 	Minify bool
+
+	// Enable to run the accessibility checks on HTML, if enabled in the
+	// a11y config.
+	CheckA11y bool
+
+	// If set, the HTML is encrypted with this password and wrapped in a page
+	// that decrypts it in the browser.
+	Password string
+
+	// If set, the salt and IV used to encrypt the HTML are derived from
+	// this and the HTML, so the output is the same in every build.
+	EncryptionSeed string
+
+	// Enable to render the HTML as an email, see the Email output format.
+	Email bool
 }
 
 // DestinationPublisher is the default and currently only publisher in Hugo. This
@@ -73,6 +96,9 @@ type DestinationPublisher struct {
 	fs                    afero.Fs
 	min                   minifiers.Client
 	htmlElementsCollector *htmlElementsCollector
+	plugins               *plugins.Plugins
+	postProcessor         *htmlpostprocess.Processor
+	a11y                  *a11y.Checker
 }
 
 // NewDestinationPublisher creates a new DestinationPublisher.
@@ -83,7 +109,16 @@ func NewDestinationPublisher(rs *resources.Spec, outputFormats output.Formats, m
 	if rs.BuildConfig.WriteStats {
 		classCollector = newHTMLElementsCollector()
 	}
-	pub = DestinationPublisher{fs: fs, htmlElementsCollector: classCollector}
+	a11yConfig, ok := cfg.Get("a11yConfig").(a11y.Config)
+	if !ok {
+		a11yConfig = a11y.DefaultConfig
+	}
+	pub = DestinationPublisher{fs: fs, htmlElementsCollector: classCollector, plugins: rs.Plugins, a11y: a11y.New(a11yConfig)}
+	postProcessSteps, _ := cfg.Get("htmlPostProcessConfig").([]htmlpostprocess.Step)
+	pub.postProcessor, err = htmlpostprocess.New(postProcessSteps, rs.Plugins)
+	if err != nil {
+		return
+	}
 	pub.min, err = minifiers.New(mediaTypes, outputFormats, cfg)
 	return
 }
@@ -131,6 +166,14 @@ func (p DestinationPublisher) Publish(d Descriptor) error {
 	return err
 }
 
+// A11yReport returns the accessibility issues found in the published pages.
+func (p DestinationPublisher) A11yReport() a11y.Report {
+	if p.a11y == nil {
+		return a11y.Report{}
+	}
+	return p.a11y.Report()
+}
+
 func (p DestinationPublisher) PublishStats() PublishStats {
 	if p.htmlElementsCollector == nil {
 		return PublishStats{}
@@ -149,6 +192,7 @@ type PublishStats struct {
 type Publisher interface {
 	Publish(d Descriptor) error
 	PublishStats() PublishStats
+	A11yReport() a11y.Report
 }
 
 // XML transformer := transform.New(urlreplacers.NewAbsURLInXMLTransformer(path))
@@ -157,6 +201,12 @@ func (p DestinationPublisher) createTransformerChain(f Descriptor) transform.Cha
 
 	isHTML := f.OutputFormat.IsHTML
 
+	// This must be first, as MJML must be compiled to HTML before
+	// the other transformers can do their work.
+	if isHTML && f.Email {
+		transformers = append(transformers, email.New())
+	}
+
 	if f.AbsURLPath != "" {
 		if isHTML {
 			transformers = append(transformers, urlreplacers.NewAbsURLTransformer(f.AbsURLPath))
@@ -166,6 +216,12 @@ func (p DestinationPublisher) createTransformerChain(f Descriptor) transform.Cha
 		}
 	}
 
+	// Run the configured post-processing on the HTML from the templates,
+	// before Hugo's own injections below.
+	if isHTML && p.postProcessor != nil {
+		transformers = append(transformers, p.newPostProcessTransformer(f))
+	}
+
 	if isHTML {
 		if f.LiveReloadBaseURL != nil {
 			transformers = append(transformers, livereloadinject.New(*f.LiveReloadBaseURL))
@@ -178,6 +234,15 @@ func (p DestinationPublisher) createTransformerChain(f Descriptor) transform.Cha
 
 	}
 
+	if p.plugins.Has(plugins.KindOutput) {
+		transformers = append(transformers, p.newPluginsTransformer(f))
+	}
+
+	// Check the markup before it's minified.
+	if isHTML && f.CheckA11y && p.a11y != nil {
+		transformers = append(transformers, p.newA11yTransformer(f))
+	}
+
 	if p.min.MinifyOutput {
 		minifyTransformer := p.min.Transformer(f.OutputFormat.MediaType)
 		if minifyTransformer != nil {
@@ -185,5 +250,74 @@ func (p DestinationPublisher) createTransformerChain(f Descriptor) transform.Cha
 		}
 	}
 
+	// This must be last, as the above transformers work on the original document.
+	if isHTML && f.Password != "" {
+		if p.htmlElementsCollector != nil {
+			// The wrapper page is collected in Publish.
+			transformers = append(transformers, p.newHTMLElementsCollectorTransformer())
+		}
+		if f.EncryptionSeed != "" {
+			transformers = append(transformers, encrypt.NewReproducible(f.Password, f.EncryptionSeed))
+		} else {
+			transformers = append(transformers, encrypt.New(f.Password))
+		}
+	}
+
 	return transformers
 }
+
+// newHTMLElementsCollectorTransformer creates a transformer collecting the
+// HTML elements in the content, passing it through unchanged.
+func (p DestinationPublisher) newHTMLElementsCollectorTransformer() transform.Transformer {
+	return func(ft transform.FromTo) error {
+		b := ft.From().Bytes()
+		if _, err := newHTMLElementsCollectorWriter(p.htmlElementsCollector).Write(b); err != nil {
+			return err
+		}
+		_, err := ft.To().Write(b)
+		return err
+	}
+}
+
+// newA11yTransformer creates a transformer running the accessibility checks
+// on the content, passing it through unchanged.
+func (p DestinationPublisher) newA11yTransformer(f Descriptor) transform.Transformer {
+	return func(ft transform.FromTo) error {
+		b := ft.From().Bytes()
+		if err := p.a11y.Check(strings.TrimPrefix(filepath.ToSlash(f.TargetPath), "/"), bytes.NewReader(b)); err != nil {
+			return err
+		}
+		_, err := ft.To().Write(b)
+		return err
+	}
+}
+
+// newPostProcessTransformer creates a transformer running the configured
+// post-processing steps on the HTML.
+func (p DestinationPublisher) newPostProcessTransformer(f Descriptor) transform.Transformer {
+	return func(ft transform.FromTo) error {
+		b, err := p.postProcessor.Process(strings.TrimPrefix(filepath.ToSlash(f.TargetPath), "/"), ft.From().Bytes())
+		if err != nil {
+			return err
+		}
+		_, err = ft.To().Write(b)
+		return err
+	}
+}
+
+// newPluginsTransformer creates a transformer passing the content through
+// the plugins implementing output transformations.
+func (p DestinationPublisher) newPluginsTransformer(f Descriptor) transform.Transformer {
+	return func(ft transform.FromTo) error {
+		b, err := p.plugins.Transform(plugins.Context{
+			Kind:      plugins.KindOutput,
+			Path:      filepath.ToSlash(f.TargetPath),
+			MediaType: f.OutputFormat.MediaType.Type(),
+		}, ft.From().Bytes())
+		if err != nil {
+			return err
+		}
+		_, err = ft.To().Write(b)
+		return err
+	}
+}
@@ -0,0 +1,222 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package related
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// The max number of words from a document's fulltext used when searching
+// for related documents. We use the words that best identify the document.
+const maxFulltextQueryKeywords = 25
+
+type frequencies struct {
+	// The unique keywords in the order first seen.
+	keywords []Keyword
+	counts   map[Keyword]int
+}
+
+func keywordFrequencies(keywords []Keyword) frequencies {
+	f := frequencies{counts: make(map[Keyword]int)}
+	for _, kw := range keywords {
+		if f.counts[kw] == 0 {
+			f.keywords = append(f.keywords, kw)
+		}
+		f.counts[kw]++
+	}
+	return f
+}
+
+// idf returns the inverse document frequency of a keyword found in n
+// documents.
+func (idx *InvertedIndex) idf(n int) float64 {
+	return math.Log(1 + (float64(idx.numDocs-n)+0.5)/(float64(n)+0.5))
+}
+
+// fulltextQueryKeywords picks the words to search for from a document's
+// fulltext: the most frequent in the document that are rare in the index.
+func (idx *InvertedIndex) fulltextQueryKeywords(cfg IndexConfig, keywords []Keyword) []Keyword {
+	f := keywordFrequencies(keywords)
+	if len(f.keywords) <= maxFulltextQueryKeywords {
+		return f.keywords
+	}
+
+	setm := idx.index[cfg.Name]
+	score := func(kw Keyword) float64 {
+		return float64(f.counts[kw]) * idx.idf(len(setm[kw]))
+	}
+
+	sort.SliceStable(f.keywords, func(i, j int) bool {
+		return score(f.keywords[i]) > score(f.keywords[j])
+	})
+
+	return f.keywords[:maxFulltextQueryKeywords]
+}
+
+type scoredDoc struct {
+	doc   Document
+	score float64
+}
+
+// searchBM25 searches the index using BM25 scoring. If self is set, it will
+// not be part of the result.
+func (idx *InvertedIndex) searchBM25(self Document, upperDate time.Time, query ...queryElement) ([]Document, error) {
+	scores := make(map[Document]float64, 200)
+	applyDateFilter := !idx.cfg.IncludeNewer && !upperDate.IsZero()
+
+	k1, b := idx.cfg.BM25.K1, idx.cfg.BM25.B
+	if k1 <= 0 {
+		k1 = DefaultConfig.BM25.K1
+	}
+
+	for _, el := range query {
+		setm, found := idx.index[el.Index]
+		if !found {
+			return []Document{}, fmt.Errorf("index for %q not found", el.Index)
+		}
+
+		config, found := idx.getIndexCfg(el.Index)
+		if !found {
+			return []Document{}, fmt.Errorf("index config for %q not found", el.Index)
+		}
+
+		weight := float64(config.Weight)
+		if idx.maxWeight > 0 {
+			weight /= float64(idx.maxWeight)
+		}
+
+		freqs := idx.freqs[el.Index]
+		docLens := idx.docLens[el.Index]
+		avgLen := 1.0
+		if len(docLens) > 0 {
+			avgLen = float64(idx.totalLens[el.Index]) / float64(len(docLens))
+		}
+
+		addScore := func(doc Document, kw Keyword, i int, factor float64) {
+			if applyDateFilter {
+				// Exclude newer than the limit given
+				if doc.PublishDate().After(upperDate) {
+					return
+				}
+			}
+			idf := idx.idf(len(setm[kw]))
+			tf := float64(freqs[kw][i])
+			norm := 1 - b + b*float64(docLens[doc])/avgLen
+			scores[doc] += factor * weight * idf * tf * (k1 + 1) / (tf + k1*norm)
+		}
+
+		for _, kw := range keywordFrequencies(el.Keywords).keywords {
+			if config.isHierarchical() {
+				for doc, m := range idx.hierarchyMatches(config, kw) {
+					if doc != self {
+						addScore(doc, m.keyword, m.pos, m.factor(config))
+					}
+				}
+				continue
+			}
+			docs, found := setm[kw]
+			if !found {
+				continue
+			}
+			for i, doc := range docs {
+				if doc == self {
+					continue
+				}
+				addScore(doc, kw, i, 1)
+			}
+		}
+	}
+
+	if len(scores) == 0 {
+		return []Document{}, nil
+	}
+
+	matches := make([]scoredDoc, 0, len(scores))
+	var maxScore float64
+	for doc, score := range scores {
+		if !upperDate.IsZero() {
+			score *= idx.dateDecay(doc.PublishDate(), upperDate)
+		}
+		if score > maxScore {
+			maxScore = score
+		}
+		matches = append(matches, scoredDoc{doc: doc, score: score})
+	}
+
+	if maxScore <= 0 {
+		return []Document{}, nil
+	}
+
+	threshold := float64(idx.cfg.Threshold)
+	n := 0
+	for _, m := range matches {
+		if math.Floor(m.score/maxScore*100+0.5) >= threshold {
+			matches[n] = m
+			n++
+		}
+	}
+	matches = matches[:n]
+
+	sort.Slice(matches, func(i, j int) bool {
+		mi, mj := matches[i], matches[j]
+		if mi.score == mj.score {
+			if mi.doc.PublishDate() == mj.doc.PublishDate() {
+				return mi.doc.Name() < mj.doc.Name()
+			}
+			return mi.doc.PublishDate().After(mj.doc.PublishDate())
+		}
+		return mi.score > mj.score
+	})
+
+	result := make([]Document, len(matches))
+	for i, m := range matches {
+		result[i] = m.doc
+	}
+
+	return result, nil
+}
+
+// dateDecay returns the factor, between 0 and 1, to multiply the score of
+// a document published at date with, when searching relative to the given
+// reference date.
+func (idx *InvertedIndex) dateDecay(date, reference time.Time) float64 {
+	cfg := idx.cfg.DateDecay
+	if cfg.Curve == "" || cfg.Scale <= 0 {
+		return 1
+	}
+
+	d := date.Sub(reference)
+	if d < 0 {
+		d = -d
+	}
+	d -= cfg.Offset
+	if d < 0 {
+		d = 0
+	}
+	x := float64(d) / float64(cfg.Scale)
+
+	switch cfg.Curve {
+	case DateDecayLinear:
+		return math.Max(0, 1-0.5*x)
+	case DateDecayExponential:
+		return math.Pow(0.5, x)
+	case DateDecayGauss:
+		return math.Pow(0.5, x*x)
+	default:
+		return 1
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package related
+
+import (
+	"math"
+	"strings"
+)
+
+// ancestors returns kw and its ancestors in the hierarchy, starting with kw,
+// e.g. "a/b/c", "a/b" and "a".
+func ancestors(cfg IndexConfig, kw Keyword) []Keyword {
+	sep := cfg.HierarchySeparator
+	s := strings.Trim(kw.String(), sep)
+	if s == "" {
+		return nil
+	}
+
+	keywords := []Keyword{StringKeyword(s)}
+	for {
+		i := strings.LastIndex(s, sep)
+		if i <= 0 {
+			break
+		}
+		s = s[:i]
+		keywords = append(keywords, StringKeyword(s))
+	}
+
+	return keywords
+}
+
+// expandHierarchy returns the unique keywords and their ancestors, with the
+// least number of steps up from a keyword to each.
+func expandHierarchy(cfg IndexConfig, keywords []Keyword) ([]Keyword, map[Keyword]int) {
+	var (
+		expanded []Keyword
+		ups      = make(map[Keyword]int)
+	)
+
+	for _, kw := range keywords {
+		for up, a := range ancestors(cfg, kw) {
+			if prev, found := ups[a]; found {
+				if up < prev {
+					ups[a] = up
+				}
+				continue
+			}
+			ups[a] = up
+			expanded = append(expanded, a)
+		}
+	}
+
+	return expanded, ups
+}
+
+// hierarchyMatch is the closest match of a document in a hierarchical index.
+type hierarchyMatch struct {
+	// The common ancestor keyword and the document's position in its list.
+	keyword Keyword
+	pos     int
+
+	// The number of steps in the hierarchy between the values.
+	distance int
+}
+
+func (m hierarchyMatch) factor(cfg IndexConfig) float64 {
+	return math.Pow(cfg.DistanceDecay, float64(m.distance))
+}
+
+// hierarchyMatches returns the documents with a value in the same branch of
+// the hierarchy as kw, with their closest match.
+func (idx *InvertedIndex) hierarchyMatches(cfg IndexConfig, kw Keyword) map[Document]hierarchyMatch {
+	var (
+		setm    = idx.index[cfg.Name]
+		ups     = idx.ups[cfg.Name]
+		matches = make(map[Document]hierarchyMatch)
+	)
+
+	for up, a := range ancestors(cfg, kw) {
+		for i, doc := range setm[a] {
+			distance := up + ups[a][i]
+			if m, found := matches[doc]; !found || distance < m.distance {
+				matches[doc] = hierarchyMatch{keyword: a, pos: i, distance: distance}
+			}
+		}
+	}
+
+	return matches
+}
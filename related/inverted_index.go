@@ -21,8 +21,10 @@ import (
 	"sort"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/gohugoio/hugo/common/maps"
+	"github.com/gohugoio/hugo/config/configschema"
 
 	"github.com/gohugoio/hugo/common/types"
 	"github.com/mitchellh/mapstructure"
@@ -35,6 +37,8 @@ var (
 	// DefaultConfig is the default related config.
 	DefaultConfig = Config{
 		Threshold: 80,
+		Scoring:   ScoringWeights,
+		BM25:      BM25Config{K1: 1.2, B: 0.75},
 		Indices: IndexConfigs{
 			IndexConfig{Name: "keywords", Weight: 100},
 			IndexConfig{Name: "date", Weight: 10},
@@ -42,6 +46,32 @@ var (
 	}
 )
 
+const (
+	// ScoringWeights ranks the matches by the weights of the indices they
+	// matched in. This is the default.
+	ScoringWeights = "weights"
+
+	// ScoringBM25 ranks the matches using Okapi BM25, taking the frequency
+	// and rarity of the keywords matched into account.
+	ScoringBM25 = "bm25"
+)
+
+const (
+	// IndexTypeBasic indexes the values of a field as keywords.
+	// This is the default.
+	IndexTypeBasic = "basic"
+
+	// IndexTypeFulltext indexes the words in a text field.
+	IndexTypeFulltext = "fulltext"
+)
+
+// The date decay curves, see DateDecayConfig.
+const (
+	DateDecayLinear      = "linear"
+	DateDecayExponential = "exponential"
+	DateDecayGauss       = "gauss"
+)
+
 /*
 Config is the top level configuration element used to configure how to retrieve
 related content in Hugo.
@@ -60,11 +90,52 @@ An example site config.toml:
 	name  = "date"
 	weight = 1
 	pattern = "2006"
+
+Or, to favor recent pages in the same or a nearby category:
+
+	[related]
+	threshold = 50
+	[related.dateDecay]
+	curve = "exponential"
+	offset = "168h"
+	scale = "720h"
+	[[related.indices]]
+	name = "categories"
+	weight = 100
+	hierarchySeparator = "/"
+	distanceDecay = 0.5
+
+Or, to find pages with similar content:
+
+	[related]
+	threshold = 20
+	scoring = "bm25"
+	[related.dateDecay]
+	curve = "gauss"
+	scale = "8760h"
+	[[related.indices]]
+	name = "content"
+	type = "fulltext"
+	weight = 80
+	[[related.indices]]
+	name  = "tags"
+	weight = 100
 */
 type Config struct {
 	// Only include matches >= threshold, a normalized rank between 0 and 100.
+	// With BM25 scoring this is relative to the best match.
 	Threshold int
 
+	// How to rank the matches, "weights" (default) or "bm25".
+	Scoring string
+
+	// Tuning parameters for BM25 scoring.
+	BM25 BM25Config
+
+	// Lowers the score of matches published long before or after the
+	// document searched for.
+	DateDecay DateDecayConfig
+
 	// To get stable "See also" sections we, by default, exclude newer related pages.
 	IncludeNewer bool
 
@@ -104,6 +175,54 @@ type IndexConfig struct {
 	// Will lower case all string values in and queries tothis index.
 	// May get better accurate results, but at a slight performance cost.
 	ToLower bool
+
+	// The index type, "basic" (default) or "fulltext". A fulltext index
+	// indexes the words in the field's text, which is always lower cased.
+	Type string
+
+	// If set, the values are paths in a hierarchy separated by this, e.g.
+	// "news/politics/europe" with "/". Values in the same branch of the
+	// hierarchy then also match, see DistanceDecay.
+	HierarchySeparator string
+
+	// The factor, between 0 and 1, the weight of a match in a hierarchical
+	// index is multiplied with for each step between the values, e.g. one step
+	// from "news/politics" to "news/politics/europe" and two to
+	// "news/politics/us". Default is 0.5.
+	DistanceDecay float64
+}
+
+func (cfg IndexConfig) isFulltext() bool {
+	return cfg.Type == IndexTypeFulltext
+}
+
+func (cfg IndexConfig) isHierarchical() bool {
+	return cfg.HierarchySeparator != ""
+}
+
+// BM25Config holds the tuning parameters for BM25 scoring.
+type BM25Config struct {
+	// Controls how quickly the score saturates as a keyword is repeated
+	// in a document. Default is 1.2.
+	K1 float64
+
+	// Controls how much longer documents are penalized, from 0 (not at all)
+	// to 1. Default is 0.75.
+	B float64
+}
+
+// DateDecayConfig configures how the score of a match decays with the
+// time between its date and the date of the document searched for.
+type DateDecayConfig struct {
+	// The decay curve, one of "linear", "exponential" or "gauss". The
+	// default is no decay.
+	Curve string
+
+	// The time distance, after Offset, at which the score is halved.
+	Scale time.Duration
+
+	// The time distance within which there is no decay.
+	Offset time.Duration
 }
 
 // Document is the interface an indexable document in Hugo must fulfill.
@@ -119,12 +238,29 @@ type Document interface {
 	Name() string
 }
 
+// pathProvider is implemented by documents with a path, used as the last
+// tiebreaker so documents with the same name are always in the same order.
+type pathProvider interface {
+	Path() string
+}
+
 // InvertedIndex holds an inverted index, also sometimes named posting list, which
 // lists, for every possible search term, the documents that contain that term.
 type InvertedIndex struct {
 	cfg   Config
 	index map[string]map[Keyword][]Document
 
+	// The statistics needed for BM25 scoring. freqs holds the keyword
+	// frequencies for the documents in index, in the same order.
+	freqs     map[string]map[Keyword][]int
+	docLens   map[string]map[Document]int
+	totalLens map[string]int
+	numDocs   int
+
+	// For the hierarchical indices, the number of steps up in the hierarchy
+	// from the documents' values to the keyword, in the same order as index.
+	ups map[string]map[Keyword][]int
+
 	minWeight int
 	maxWeight int
 }
@@ -142,9 +278,21 @@ func (idx *InvertedIndex) getIndexCfg(name string) (IndexConfig, bool) {
 // NewInvertedIndex creates a new InvertedIndex.
 // Documents to index must be added in Add.
 func NewInvertedIndex(cfg Config) *InvertedIndex {
-	idx := &InvertedIndex{index: make(map[string]map[Keyword][]Document), cfg: cfg}
+	idx := &InvertedIndex{index: make(map[string]map[Keyword][]Document), ups: make(map[string]map[Keyword][]int), cfg: cfg}
+	if cfg.Scoring == ScoringBM25 {
+		idx.freqs = make(map[string]map[Keyword][]int)
+		idx.docLens = make(map[string]map[Document]int)
+		idx.totalLens = make(map[string]int)
+	}
 	for _, conf := range cfg.Indices {
 		idx.index[conf.Name] = make(map[Keyword][]Document)
+		if conf.isHierarchical() {
+			idx.ups[conf.Name] = make(map[Keyword][]int)
+		}
+		if idx.freqs != nil {
+			idx.freqs[conf.Name] = make(map[Keyword][]int)
+			idx.docLens[conf.Name] = make(map[Document]int)
+		}
 		if conf.Weight < idx.minWeight {
 			// By default, the weight scale starts at 0, but we allow
 			// negative weights.
@@ -161,6 +309,7 @@ func NewInvertedIndex(cfg Config) *InvertedIndex {
 // The value must support == and !=.
 func (idx *InvertedIndex) Add(docs ...Document) error {
 	var err error
+	idx.numDocs += len(docs)
 	for _, config := range idx.cfg.Indices {
 		if config.Weight == 0 {
 			// Disabled
@@ -175,8 +324,35 @@ func (idx *InvertedIndex) Add(docs ...Document) error {
 				continue
 			}
 
-			for _, keyword := range words {
+			var ups map[Keyword]int
+			if config.isHierarchical() {
+				words, ups = expandHierarchy(config, words)
+			}
+
+			if idx.freqs == nil && !config.isFulltext() {
+				for _, keyword := range words {
+					setm[keyword] = append(setm[keyword], doc)
+					if ups != nil {
+						idx.ups[config.Name][keyword] = append(idx.ups[config.Name][keyword], ups[keyword])
+					}
+				}
+				continue
+			}
+
+			freqs := keywordFrequencies(words)
+			for _, keyword := range freqs.keywords {
 				setm[keyword] = append(setm[keyword], doc)
+				if ups != nil {
+					idx.ups[config.Name][keyword] = append(idx.ups[config.Name][keyword], ups[keyword])
+				}
+				if idx.freqs != nil {
+					idx.freqs[config.Name][keyword] = append(idx.freqs[config.Name][keyword], freqs.counts[keyword])
+				}
+			}
+
+			if idx.freqs != nil {
+				idx.docLens[config.Name][doc] = len(words)
+				idx.totalLens[config.Name] += len(words)
 			}
 		}
 	}
@@ -199,16 +375,16 @@ type ranks []*rank
 
 type rank struct {
 	Doc     Document
-	Weight  int
+	Weight  float64
 	Matches int
 }
 
-func (r *rank) addWeight(w int) {
+func (r *rank) addWeight(w float64) {
 	r.Weight += w
 	r.Matches++
 }
 
-func newRank(doc Document, weight int) *rank {
+func newRank(doc Document, weight float64) *rank {
 	return &rank{Doc: doc, Weight: weight, Matches: 1}
 }
 
@@ -217,6 +393,13 @@ func (r ranks) Swap(i, j int) { r[i], r[j] = r[j], r[i] }
 func (r ranks) Less(i, j int) bool {
 	if r[i].Weight == r[j].Weight {
 		if r[i].Doc.PublishDate() == r[j].Doc.PublishDate() {
+			if r[i].Doc.Name() == r[j].Doc.Name() {
+				p1, ok1 := r[i].Doc.(pathProvider)
+				p2, ok2 := r[j].Doc.(pathProvider)
+				if ok1 && ok2 {
+					return p1.Path() < p2.Path()
+				}
+			}
 			return r[i].Doc.Name() < r[j].Doc.Name()
 		}
 		return r[i].Doc.PublishDate().After(r[j].Doc.PublishDate())
@@ -254,10 +437,18 @@ func (idx *InvertedIndex) SearchDoc(doc Document, indices ...string) ([]Document
 			return nil, err
 		}
 
+		if cfg.isFulltext() {
+			keywords = idx.fulltextQueryKeywords(cfg, keywords)
+		}
+
 		q = append(q, newQueryElement(cfg.Name, keywords...))
 
 	}
 
+	if idx.cfg.Scoring == ScoringBM25 {
+		return idx.searchBM25(doc, doc.PublishDate(), q...)
+	}
+
 	return idx.searchDate(doc.PublishDate(), q...)
 }
 
@@ -269,11 +460,20 @@ func (cfg IndexConfig) ToKeywords(v interface{}) ([]Keyword, error) {
 	)
 	switch vv := v.(type) {
 	case string:
+		if cfg.isFulltext() {
+			return StringsToKeywords(Tokenize(vv)...), nil
+		}
 		if toLower {
 			vv = strings.ToLower(vv)
 		}
 		keywords = append(keywords, StringKeyword(vv))
 	case []string:
+		if cfg.isFulltext() {
+			for _, v := range vv {
+				keywords = append(keywords, StringsToKeywords(Tokenize(v)...)...)
+			}
+			return keywords, nil
+		}
 		if toLower {
 			vc := make([]string, len(vv))
 			copy(vc, vv)
@@ -324,10 +524,18 @@ func (idx *InvertedIndex) SearchKeyValues(args ...types.KeyValues) ([]Document,
 			keywords = append(keywords, k...)
 		}
 
+		if conf.isFulltext() {
+			keywords = keywordFrequencies(keywords).keywords
+		}
+
 		q[i] = newQueryElement(conf.Name, keywords...)
 
 	}
 
+	if idx.cfg.Scoring == ScoringBM25 {
+		return idx.searchBM25(nil, zeroDate, q...)
+	}
+
 	return idx.search(q...)
 }
 
@@ -350,21 +558,31 @@ func (idx *InvertedIndex) searchDate(upperDate time.Time, query ...queryElement)
 			return []Document{}, fmt.Errorf("index config for %q not found", el.Index)
 		}
 
+		addWeight := func(doc Document, weight float64) {
+			if applyDateFilter {
+				// Exclude newer than the limit given
+				if doc.PublishDate().After(upperDate) {
+					return
+				}
+			}
+			r, found := matchm[doc]
+			if !found {
+				matchm[doc] = newRank(doc, weight)
+			} else {
+				r.addWeight(weight)
+			}
+		}
+
 		for _, kw := range el.Keywords {
+			if config.isHierarchical() {
+				for doc, m := range idx.hierarchyMatches(config, kw) {
+					addWeight(doc, float64(config.Weight)*m.factor(config))
+				}
+				continue
+			}
 			if docs, found := setm[kw]; found {
 				for _, doc := range docs {
-					if applyDateFilter {
-						// Exclude newer than the limit given
-						if doc.PublishDate().After(upperDate) {
-							continue
-						}
-					}
-					r, found := matchm[doc]
-					if !found {
-						matchm[doc] = newRank(doc, config.Weight)
-					} else {
-						r.addWeight(config.Weight)
-					}
+					addWeight(doc, float64(config.Weight))
 				}
 			}
 		}
@@ -377,7 +595,10 @@ func (idx *InvertedIndex) searchDate(upperDate time.Time, query ...queryElement)
 	matches := make(ranks, 0, 100)
 
 	for _, v := range matchm {
-		avgWeight := v.Weight / v.Matches
+		if !upperDate.IsZero() {
+			v.Weight *= idx.dateDecay(v.Doc.PublishDate(), upperDate)
+		}
+		avgWeight := math.Trunc(v.Weight / float64(v.Matches))
 		weight := norm(avgWeight, idx.minWeight, idx.maxWeight)
 		threshold := idx.cfg.Threshold / v.Matches
 
@@ -398,11 +619,11 @@ func (idx *InvertedIndex) searchDate(upperDate time.Time, query ...queryElement)
 }
 
 // normalizes num to a number between 0 and 100.
-func norm(num, min, max int) int {
+func norm(num float64, min, max int) int {
 	if min > max {
 		panic("min > max")
 	}
-	return int(math.Floor((float64(num-min) / float64(max-min) * 100) + 0.5))
+	return int(math.Floor(((num - float64(min)) / float64(max-min) * 100) + 0.5))
 }
 
 // DecodeConfig decodes a slice of map into Config.
@@ -415,9 +636,21 @@ func DecodeConfig(m maps.Params) (Config, error) {
 		return Config{}, errors.New("empty related config provided")
 	}
 
-	var c Config
+	c := Config{
+		Scoring: DefaultConfig.Scoring,
+		BM25:    DefaultConfig.BM25,
+	}
 
-	if err := mapstructure.WeakDecode(m, &c); err != nil {
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		Result:           &c,
+		WeaklyTypedInput: true,
+		DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
+	})
+	if err != nil {
+		return c, err
+	}
+
+	if err := decoder.Decode(m); err != nil {
 		return c, err
 	}
 
@@ -425,6 +658,43 @@ func DecodeConfig(m maps.Params) (Config, error) {
 		return Config{}, errors.New("related threshold must be between 0 and 100")
 	}
 
+	c.Scoring = strings.ToLower(c.Scoring)
+	if c.Scoring != ScoringWeights && c.Scoring != ScoringBM25 {
+		return Config{}, fmt.Errorf("related scoring must be one of %q or %q", ScoringWeights, ScoringBM25)
+	}
+
+	switch strings.ToLower(c.DateDecay.Curve) {
+	case "", DateDecayLinear, DateDecayExponential, DateDecayGauss:
+		c.DateDecay.Curve = strings.ToLower(c.DateDecay.Curve)
+	default:
+		return Config{}, fmt.Errorf("related date decay curve must be one of %q, %q or %q", DateDecayLinear, DateDecayExponential, DateDecayGauss)
+	}
+	if c.DateDecay.Curve != "" && c.DateDecay.Scale <= 0 {
+		return Config{}, errors.New("related date decay needs a positive scale")
+	}
+
+	for i, index := range c.Indices {
+		index.Type = strings.ToLower(index.Type)
+		if index.Type == "" {
+			index.Type = IndexTypeBasic
+		}
+		if index.Type != IndexTypeBasic && index.Type != IndexTypeFulltext {
+			return Config{}, fmt.Errorf("related index %q: type must be one of %q or %q", index.Name, IndexTypeBasic, IndexTypeFulltext)
+		}
+		if index.isHierarchical() {
+			if index.isFulltext() {
+				return Config{}, fmt.Errorf("related index %q: a fulltext index cannot be hierarchical", index.Name)
+			}
+			if index.DistanceDecay == 0 {
+				index.DistanceDecay = 0.5
+			}
+			if index.DistanceDecay < 0 || index.DistanceDecay > 1 {
+				return Config{}, fmt.Errorf("related index %q: distanceDecay must be between 0 and 1", index.Name)
+			}
+		}
+		c.Indices[i] = index
+	}
+
 	if c.ToLower {
 		for i := range c.Indices {
 			c.Indices[i].ToLower = true
@@ -434,6 +704,24 @@ func DecodeConfig(m maps.Params) (Config, error) {
 	return c, nil
 }
 
+// Tokenize splits s into lower cased words for a fulltext index.
+// Single letters and digits are skipped.
+func Tokenize(s string) []string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	n := 0
+	for _, w := range words {
+		if len(w) > 1 {
+			words[n] = w
+			n++
+		}
+	}
+
+	return words[:n]
+}
+
 // StringKeyword is a string search keyword.
 type StringKeyword string
 
@@ -456,3 +744,7 @@ func StringsToKeywords(s ...string) []Keyword {
 
 	return kw
 }
+
+func init() {
+	configschema.AddSection("related", DefaultConfig)
+}
@@ -16,6 +16,7 @@ package related
 import (
 	"fmt"
 	"math/rand"
+	"sort"
 	"testing"
 	"time"
 
@@ -198,6 +199,267 @@ func TestSearch(t *testing.T) {
 	})
 }
 
+func newTestIndex(cfg Config, docs ...*testDoc) *InvertedIndex {
+	idx := NewInvertedIndex(cfg)
+	for _, d := range docs {
+		idx.Add(d)
+	}
+	return idx
+}
+
+func docNames(docs []Document) []string {
+	var names []string
+	for _, d := range docs {
+		names = append(names, d.Name())
+	}
+	return names
+}
+
+func TestSearchBM25(t *testing.T) {
+	c := qt.New(t)
+
+	config := Config{
+		Threshold: 10,
+		Scoring:   ScoringBM25,
+		BM25:      DefaultConfig.BM25,
+		Indices: IndexConfigs{
+			IndexConfig{Name: "content", Type: IndexTypeFulltext, Weight: 100},
+			IndexConfig{Name: "tags", Weight: 50},
+		},
+	}
+
+	date := time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)
+	newDoc := func(name, text string, tags ...string) *testDoc {
+		d := newTestDocWithDate("content", date, Tokenize(text)...).addKeywords("tags", tags...)
+		d.name = name
+		date = date.Add(-time.Hour)
+		return d
+	}
+
+	docs := []*testDoc{
+		newDoc("gophers", "The gopher is the Go mascot. Go gophers build fast static sites.", "go"),
+		newDoc("go", "Building static sites with Go templates. The Go gopher approves.", "go"),
+		newDoc("rust", "The crab is the Rust mascot. It is a fast language.", "rust"),
+		newDoc("cooking", "A recipe for pancakes, with eggs and milk."),
+	}
+	index := newTestIndex(config, docs...)
+
+	m, err := index.SearchDoc(docs[0])
+	c.Assert(err, qt.IsNil)
+	c.Assert(docNames(m), qt.DeepEquals, []string{"go", "rust"})
+
+	m, err = index.SearchDoc(docs[0], "content")
+	c.Assert(err, qt.IsNil)
+	c.Assert(docNames(m)[0], qt.Equals, "go")
+
+	m, err = index.SearchDoc(docs[3])
+	c.Assert(err, qt.IsNil)
+	c.Assert(m, qt.HasLen, 0)
+
+	m, err = index.search(newQueryElement("content", StringsToKeywords("crab")...))
+	c.Assert(err, qt.IsNil)
+	c.Assert(docNames(m), qt.DeepEquals, []string{"rust"})
+
+	// The newest documents come first, so "gophers" is not returned as
+	// related to "rust" unless newer ones are included.
+	config.IncludeNewer = true
+	index = newTestIndex(config, docs...)
+	m, err = index.SearchDoc(docs[2], "content")
+	c.Assert(err, qt.IsNil)
+	c.Assert(docNames(m)[0], qt.Equals, "gophers")
+}
+
+type testPathDoc struct {
+	*testDoc
+	path string
+}
+
+func (d testPathDoc) Path() string {
+	return d.path
+}
+
+func TestRanksSameNameAndDate(t *testing.T) {
+	c := qt.New(t)
+
+	date := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	var docs []Document
+	for _, path := range []string{"c/index.md", "a/index.md", "b/index.md"} {
+		docs = append(docs, testPathDoc{testDoc: newTestDocWithDate("tags", date, "a"), path: path})
+	}
+
+	for i := 0; i < 10; i++ {
+		r := ranks{newRank(docs[i%3], 50), newRank(docs[(i+1)%3], 50), newRank(docs[(i+2)%3], 50)}
+		sort.Stable(r)
+		var paths []string
+		for _, rr := range r {
+			paths = append(paths, rr.Doc.(testPathDoc).path)
+		}
+		c.Assert(paths, qt.DeepEquals, []string{"a/index.md", "b/index.md", "c/index.md"})
+	}
+}
+
+func TestDateDecay(t *testing.T) {
+	c := qt.New(t)
+
+	ref := time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	for _, test := range []struct {
+		curve  string
+		d      time.Duration
+		expect float64
+	}{
+		{"", 100 * day, 1},
+		{DateDecayLinear, 0, 1},
+		{DateDecayLinear, 10 * day, 0.5},
+		{DateDecayLinear, 30 * day, 0},
+		{DateDecayExponential, 10 * day, 0.5},
+		{DateDecayExponential, -20 * day, 0.25},
+		{DateDecayGauss, 10 * day, 0.5},
+		{DateDecayGauss, 20 * day, 0.0625},
+	} {
+		idx := NewInvertedIndex(Config{DateDecay: DateDecayConfig{Curve: test.curve, Scale: 10 * day}})
+		c.Assert(idx.dateDecay(ref.Add(test.d), ref), qt.Equals, test.expect, qt.Commentf("%s %s", test.curve, test.d))
+	}
+}
+
+func TestDateDecayWithOffset(t *testing.T) {
+	c := qt.New(t)
+
+	ref := time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	idx := NewInvertedIndex(Config{DateDecay: DateDecayConfig{Curve: DateDecayExponential, Scale: 10 * day, Offset: 5 * day}})
+	c.Assert(idx.dateDecay(ref.Add(-3*day), ref), qt.Equals, 1.0)
+	c.Assert(idx.dateDecay(ref.Add(-15*day), ref), qt.Equals, 0.5)
+}
+
+func TestSearchWeightsWithDateDecay(t *testing.T) {
+	c := qt.New(t)
+
+	day := 24 * time.Hour
+	ref := time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	newDoc := func(name string, age time.Duration, tags ...string) *testDoc {
+		d := newTestDocWithDate("tags", ref.Add(-age), tags...)
+		d.name = name
+		return d
+	}
+
+	docs := []*testDoc{
+		newDoc("old-two-tags", 200*day, "a", "b"),
+		newDoc("recent-one-tag", 2*day, "a"),
+		newDoc("ancient", 2000*day, "a", "b"),
+	}
+
+	config := Config{
+		Threshold: 10,
+		Indices:   IndexConfigs{IndexConfig{Name: "tags", Weight: 100}},
+	}
+
+	doc := newDoc("self", 0, "a", "b")
+
+	m, err := newTestIndex(config, docs...).SearchDoc(doc)
+	c.Assert(err, qt.IsNil)
+	c.Assert(docNames(m), qt.DeepEquals, []string{"old-two-tags", "ancient", "recent-one-tag"})
+
+	config.DateDecay = DateDecayConfig{Curve: DateDecayExponential, Scale: 30 * day}
+	m, err = newTestIndex(config, docs...).SearchDoc(doc)
+	c.Assert(err, qt.IsNil)
+	c.Assert(docNames(m), qt.DeepEquals, []string{"recent-one-tag"})
+}
+
+func TestSearchHierarchy(t *testing.T) {
+	c := qt.New(t)
+
+	date := time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)
+	newDoc := func(name string, categories ...string) *testDoc {
+		d := newTestDocWithDate("categories", date, categories...)
+		d.name = name
+		date = date.Add(-time.Hour)
+		return d
+	}
+
+	docs := []*testDoc{
+		newDoc("europe", "news/politics/europe"),
+		newDoc("us", "news/politics/us"),
+		newDoc("politics", "news/politics"),
+		newDoc("football", "news/sports/football"),
+		newDoc("recipes", "food/recipes"),
+	}
+
+	for _, scoring := range []string{ScoringWeights, ScoringBM25} {
+		c.Run(scoring, func(c *qt.C) {
+			config := Config{
+				Threshold: 20,
+				Scoring:   scoring,
+				Indices: IndexConfigs{
+					IndexConfig{Name: "categories", Weight: 100, HierarchySeparator: "/", DistanceDecay: 0.5},
+				},
+			}
+			idx := newTestIndex(config, docs...)
+
+			m, err := idx.search(newQueryElement("categories", StringsToKeywords("news/politics/europe")...))
+			c.Assert(err, qt.IsNil)
+			c.Assert(docNames(m), qt.DeepEquals, []string{"europe", "politics", "us"})
+
+			m, err = idx.search(newQueryElement("categories", StringsToKeywords("/news/")...))
+			c.Assert(err, qt.IsNil)
+			c.Assert(docNames(m), qt.DeepEquals, []string{"politics", "europe", "us", "football"})
+		})
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	c := qt.New(t)
+
+	c.Assert(Tokenize("Hugo is a Fast static-site generator, 1 of 2021's best!"), qt.DeepEquals,
+		[]string{"hugo", "is", "fast", "static", "site", "generator", "of", "2021", "best"})
+	c.Assert(Tokenize(""), qt.HasLen, 0)
+
+	keywords, err := IndexConfig{Type: IndexTypeFulltext}.ToKeywords("Go, go GO")
+	c.Assert(err, qt.IsNil)
+	c.Assert(keywords, qt.DeepEquals, StringsToKeywords("go", "go", "go"))
+}
+
+func TestDecodeConfig(t *testing.T) {
+	c := qt.New(t)
+
+	cfg, err := DecodeConfig(map[string]interface{}{
+		"threshold": 20,
+		"scoring":   "BM25",
+		"bm25":      map[string]interface{}{"b": 0.5},
+		"dateDecay": map[string]interface{}{"curve": "gauss", "scale": "240h", "offset": "24h"},
+		"indices": []map[string]interface{}{
+			{"name": "content", "type": "fulltext", "weight": 80},
+			{"name": "tags", "weight": 100},
+			{"name": "categories", "weight": 50, "hierarchySeparator": "/"},
+		},
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(cfg.Scoring, qt.Equals, ScoringBM25)
+	c.Assert(cfg.BM25, qt.Equals, BM25Config{K1: 1.2, B: 0.5})
+	c.Assert(cfg.DateDecay, qt.Equals, DateDecayConfig{Curve: DateDecayGauss, Scale: 240 * time.Hour, Offset: 24 * time.Hour})
+	c.Assert(cfg.Indices[0].Type, qt.Equals, IndexTypeFulltext)
+	c.Assert(cfg.Indices[1].Type, qt.Equals, IndexTypeBasic)
+	c.Assert(cfg.Indices[2].DistanceDecay, qt.Equals, 0.5)
+
+	cfg, err = DecodeConfig(map[string]interface{}{"threshold": 20})
+	c.Assert(err, qt.IsNil)
+	c.Assert(cfg.Scoring, qt.Equals, ScoringWeights)
+
+	_, err = DecodeConfig(map[string]interface{}{"scoring": "foo"})
+	c.Assert(err, qt.Not(qt.IsNil))
+	_, err = DecodeConfig(map[string]interface{}{"dateDecay": map[string]interface{}{"curve": "gauss"}})
+	c.Assert(err, qt.Not(qt.IsNil))
+	_, err = DecodeConfig(map[string]interface{}{"indices": []map[string]interface{}{{"name": "content", "type": "foo"}}})
+	c.Assert(err, qt.Not(qt.IsNil))
+	_, err = DecodeConfig(map[string]interface{}{"indices": []map[string]interface{}{{"name": "content", "type": "fulltext", "hierarchySeparator": "/"}}})
+	c.Assert(err, qt.Not(qt.IsNil))
+	_, err = DecodeConfig(map[string]interface{}{"indices": []map[string]interface{}{{"name": "categories", "hierarchySeparator": "/", "distanceDecay": 2}}})
+	c.Assert(err, qt.Not(qt.IsNil))
+}
+
 func TestToKeywordsToLower(t *testing.T) {
 	c := qt.New(t)
 	slice := []string{"A", "B", "C"}
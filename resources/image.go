@@ -28,6 +28,7 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/gohugoio/hugo/common/paths"
 
@@ -137,6 +138,31 @@ func (i *imageResource) getExif() *exif.Exif {
 	return i.meta.Exif
 }
 
+// Placeholder returns a tiny placeholder representation of the image of the
+// given kind, "blurhash" or "thumb-base64". It is cached in the images file cache.
+func (i *imageResource) Placeholder(kind string) (string, error) {
+	kind = strings.ToLower(kind)
+	if !images.IsPlaceholderKind(kind) {
+		return "", errors.Errorf("unsupported placeholder kind %q, must be one of %q or %q", kind, images.PlaceholderBlurhash, images.PlaceholderThumbBase64)
+	}
+
+	key := i.getImageCacheTargetPath(kind + ".txt")
+
+	_, b, err := i.getSpec().imageCache.fileCache.GetOrCreateBytes(key, func() ([]byte, error) {
+		img, err := i.DecodeImage()
+		if err != nil {
+			return nil, err
+		}
+		s, err := i.Proc.Placeholder(img, kind)
+		return []byte(s), err
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to create %s placeholder for image %q", kind, i.getResourcePaths().relTargetDirFile.file)
+	}
+
+	return string(b), nil
+}
+
 func (i *imageResource) Clone() resource.Resource {
 	gr := i.baseResource.Clone().(baseResource)
 	return &imageResource{
@@ -242,21 +268,26 @@ func (i *imageResource) Filter(filters ...interface{}) (resource.Image, error) {
 	})
 }
 
-// Serialize image processing. The imaging library spins up its own set of Go routines,
-// so there is not much to gain from adding more load to the mix. That
-// can even have negative effect in low resource scenarios.
-// Note that this only effects the non-cached scenario. Once the processed
-// image is written to disk, everything is fast, fast fast.
-const imageProcWorkers = 1
-
-var imageProcSem = make(chan bool, imageProcWorkers)
-
 func (i *imageResource) doWithImageConfig(conf images.ImageConfig, f func(src image.Image) (image.Image, error)) (resource.Image, error) {
 	img, err := i.getSpec().imageCache.getOrCreate(i, conf, func() (*imageResource, image.Image, error) {
-		imageProcSem <- true
-		defer func() {
-			<-imageProcSem
-		}()
+		// The image processing is limited by the images worker pool
+		// (1 by default). The imaging library spins up its own set of
+		// Go routines, so there is not much to gain from adding more load
+		// to the mix. That can even have negative effect in low resource
+		// scenarios.
+		// Note that this only effects the non-cached scenario. Once the processed
+		// image is written to disk, everything is fast, fast fast.
+		workers := i.getSpec().Workers.Images
+		workers.Acquire()
+		defer workers.Release()
+
+		if pm := i.getSpec().PageMetrics; pm != nil {
+			var filename string
+			if i.root != nil && i.root.getFileInfo() != nil {
+				filename = i.root.getFileInfo().Meta().Filename()
+			}
+			defer pm.MeasureImageSince(filename, time.Now())
+		}
 
 		errOp := conf.Action
 		errPath := i.getSourceFilename()
@@ -360,6 +391,10 @@ func (i *imageResource) setBasePath(conf images.ImageConfig) {
 }
 
 func (i *imageResource) getImageMetaCacheTargetPath() string {
+	return i.getImageCacheTargetPath("json")
+}
+
+func (i *imageResource) getImageCacheTargetPath(suffix string) string {
 	const imageMetaVersionNumber = 1 // Increment to invalidate the meta cache
 
 	cfgHash := i.getSpec().imaging.Cfg.CfgHash
@@ -370,7 +405,7 @@ func (i *imageResource) getImageMetaCacheTargetPath() string {
 	p1, _ := paths.FileAndExt(df.file)
 	h, _ := i.hash()
 	idStr := helpers.HashString(h, i.size(), imageMetaVersionNumber, cfgHash)
-	p := path.Join(df.dir, fmt.Sprintf("%s_%s.json", p1, idStr))
+	p := path.Join(df.dir, fmt.Sprintf("%s_%s.%s", p1, idStr, suffix))
 	return p
 }
 
@@ -33,6 +33,9 @@ type imageCache struct {
 
 	mu    sync.RWMutex
 	store map[string]*resourceAdapter
+
+	// Keeps the store within the images memory budget.
+	evictions *evictionQueue
 }
 
 func (c *imageCache) deleteIfContains(s string) {
@@ -42,6 +45,7 @@ func (c *imageCache) deleteIfContains(s string) {
 	for k := range c.store {
 		if strings.Contains(k, s) {
 			delete(c.store, k)
+			c.evictions.remove(k)
 		}
 	}
 }
@@ -60,11 +64,16 @@ func (c *imageCache) clear() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	c.store = make(map[string]*resourceAdapter)
+	c.evictions.clear()
 }
 
 func (c *imageCache) getOrCreate(
 	parent *imageResource, conf images.ImageConfig,
 	createImage func() (*imageResource, image.Image, error)) (*resourceAdapter, error) {
+	// The processed image is written to the publish dir the first time
+	// it's created or read from the file cache.
+	parent.getSpec().incrPublishRequests()
+
 	relTarget := parent.relTargetPathFromConfig(conf)
 	memKey := parent.relTargetPathForRel(relTarget.path(), false, false, false)
 	memKey = c.normalizeKey(memKey)
@@ -82,6 +91,11 @@ func (c *imageCache) getOrCreate(
 	c.mu.RUnlock()
 
 	if found {
+		if c.evictions.enabled() {
+			c.mu.Lock()
+			c.evictions.touch(memKey)
+			c.mu.Unlock()
+		}
 		return cachedImage, nil
 	}
 
@@ -157,11 +171,20 @@ func (c *imageCache) getOrCreate(
 
 	imgAdapter := newResourceAdapter(parent.getSpec(), true, img)
 	c.store[memKey] = imgAdapter
+	for _, k := range c.evictions.add(memKey) {
+		delete(c.store, k)
+		c.pathSpec.ProcessingStats.Incr(&c.pathSpec.ProcessingStats.EvictedImages)
+	}
 	c.mu.Unlock()
 
 	return imgAdapter, nil
 }
 
-func newImageCache(fileCache *filecache.Cache, ps *helpers.PathSpec) *imageCache {
-	return &imageCache{fileCache: fileCache, pathSpec: ps, store: make(map[string]*resourceAdapter)}
+func newImageCache(fileCache *filecache.Cache, ps *helpers.PathSpec, budget int) *imageCache {
+	return &imageCache{
+		fileCache: fileCache,
+		pathSpec:  ps,
+		store:     make(map[string]*resourceAdapter),
+		evictions: newEvictionQueue(budget),
+	}
 }
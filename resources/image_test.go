@@ -451,6 +451,36 @@ func TestImageExif(t *testing.T) {
 	getAndCheckExif(c, image)
 }
 
+func TestImagePlaceholder(t *testing.T) {
+	c := qt.New(t)
+	fs := afero.NewMemMapFs()
+	spec := newTestResourceSpec(specDescriptor{fs: fs, c: c})
+	image := fetchResourceForSpec(spec, c, "sunset.jpg").(resource.Image)
+
+	blurhash, err := image.Placeholder("blurhash")
+	c.Assert(err, qt.IsNil)
+	c.Assert(blurhash, qt.HasLen, 28)
+
+	thumb, err := image.Placeholder("THUMB-base64")
+	c.Assert(err, qt.IsNil)
+	c.Assert(thumb, qt.Matches, `data:image/png;base64,.+`)
+
+	resized, err := image.Resize("200x")
+	c.Assert(err, qt.IsNil)
+	resizedBlurhash, err := resized.Placeholder("blurhash")
+	c.Assert(err, qt.IsNil)
+	c.Assert(resizedBlurhash, qt.HasLen, 28)
+
+	// This will read from file cache.
+	image = fetchResourceForSpec(spec, c, "sunset.jpg").(resource.Image)
+	cached, err := image.Placeholder("blurhash")
+	c.Assert(err, qt.IsNil)
+	c.Assert(cached, qt.Equals, blurhash)
+
+	_, err = image.Placeholder("foo")
+	c.Assert(err, qt.ErrorMatches, `unsupported placeholder kind "foo".*`)
+}
+
 func BenchmarkImageExif(b *testing.B) {
 	getImages := func(c *qt.C, b *testing.B, fs afero.Fs) []resource.Image {
 		spec := newTestResourceSpec(specDescriptor{fs: fs, c: c})
@@ -19,6 +19,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/gohugoio/hugo/config/configschema"
 	"github.com/gohugoio/hugo/helpers"
 
 	"github.com/pkg/errors"
@@ -157,6 +158,11 @@ func DecodeConfig(m map[string]interface{}) (ImagingConfig, error) {
 		i.Cfg.Exif.ExcludeFields = "GPS|Exif|Exposure[M|P|B]|Contrast|Resolution|Sharp|JPEG|Metering|Sensing|Saturation|ColorSpace|Flash|WhiteBalance"
 	}
 
+	i.Renditions, err = decodeRenditions(i.Cfg.Renditions, i)
+	if err != nil {
+		return i, err
+	}
+
 	return i, nil
 }
 
@@ -371,6 +377,47 @@ type ImagingConfig struct {
 
 	// Hash of the config map provided by the user.
 	CfgHash string
+
+	// The named rendition sets in Cfg.Renditions.
+	Renditions map[string][]Rendition
+}
+
+// Rendition is an image processing step in a rendition set, see
+// Imaging.Renditions.
+type Rendition struct {
+	// The processing method, one of resize, fit or fill.
+	Action string
+
+	// The options, e.g. "800x webp".
+	Options string
+}
+
+func decodeRenditions(renditions map[string][]string, defaults ImagingConfig) (map[string][]Rendition, error) {
+	if len(renditions) == 0 {
+		return nil, nil
+	}
+
+	m := make(map[string][]Rendition)
+	for name, specs := range renditions {
+		for _, spec := range specs {
+			parts := strings.Fields(spec)
+			if len(parts) < 2 {
+				return nil, errors.Errorf("invalid spec %q in image rendition set %q, must be the method followed by the options, e.g. \"resize 800x\"", spec, name)
+			}
+			r := Rendition{Action: strings.ToLower(parts[0]), Options: strings.Join(parts[1:], " ")}
+			switch r.Action {
+			case "resize", "fit", "fill":
+			default:
+				return nil, errors.Errorf("invalid method %q in image rendition set %q, must be one of resize, fit or fill", parts[0], name)
+			}
+			if _, err := DecodeImageConfig(r.Action, r.Options, defaults, JPEG); err != nil {
+				return nil, errors.Wrapf(err, "invalid spec %q in image rendition set %q", spec, name)
+			}
+			m[strings.ToLower(name)] = append(m[strings.ToLower(name)], r)
+		}
+	}
+
+	return m, nil
 }
 
 // Imaging contains default image processing configuration. This will be fetched
@@ -395,6 +442,12 @@ type Imaging struct {
 	BgColor string
 
 	Exif ExifConfig
+
+	// Named sets of images to create from an image, used in the
+	// render-image templates. Each entry is the processing method, resize,
+	// fit or fill, followed by its options, e.g.
+	// responsive = ["resize 480x webp", "resize 960x webp"].
+	Renditions map[string][]string
 }
 
 func (cfg *Imaging) init() error {
@@ -432,3 +485,7 @@ type ExifConfig struct {
 	// .Long and .Lat. Set this to true to turn it off.
 	DisableLatLong bool
 }
+
+func init() {
+	configschema.AddSection("imaging", defaultImaging)
+}
@@ -76,6 +76,25 @@ func TestDecodeConfig(t *testing.T) {
 	imaging = imagingConfig.Cfg
 	c.Assert(imaging.Exif.DisableLatLong, qt.Equals, true)
 	c.Assert(imaging.Exif.ExcludeFields, qt.Equals, "GPS|Exif|Exposure[M|P|B]|Contrast|Resolution|Sharp|JPEG|Metering|Sensing|Saturation|ColorSpace|Flash|WhiteBalance")
+
+	imagingConfig, err = DecodeConfig(map[string]interface{}{
+		"renditions": map[string]interface{}{
+			"Responsive": []interface{}{"resize 480x webp", "Fill  200x200 q50"},
+		},
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(imagingConfig.Renditions, qt.DeepEquals, map[string][]Rendition{
+		"responsive": {{Action: "resize", Options: "480x webp"}, {Action: "fill", Options: "200x200 q50"}},
+	})
+
+	for _, invalid := range []string{"resize", "crop 200x", "resize q50"} {
+		_, err = DecodeConfig(map[string]interface{}{
+			"renditions": map[string]interface{}{
+				"foo": []interface{}{invalid},
+			},
+		})
+		c.Assert(err, qt.Not(qt.IsNil), qt.Commentf(invalid))
+	}
 }
 
 func TestDecodeImageConfig(t *testing.T) {
@@ -189,6 +189,13 @@ func NewImageProcessor(cfg ImagingConfig) (*ImageProcessor, error) {
 type ImageProcessor struct {
 	Cfg         ImagingConfig
 	exifDecoder *exif.Decoder
+
+	sequential bool
+}
+
+// DisableParallelization makes the image filters run on a single Go routine.
+func (p *ImageProcessor) DisableParallelization() {
+	p.sequential = true
 }
 
 func (p *ImageProcessor) DecodeExif(r io.Reader) (*exif.Exif, error) {
@@ -236,6 +243,9 @@ func (p *ImageProcessor) ApplyFiltersFromConfig(src image.Image, conf ImageConfi
 
 func (p *ImageProcessor) Filter(src image.Image, filters ...gift.Filter) (image.Image, error) {
 	g := gift.New(filters...)
+	if p.sequential {
+		g.SetParallelization(false)
+	}
 	dst := image.NewRGBA(g.Bounds(src.Bounds()))
 	g.Draw(dst, src)
 	return dst, nil
@@ -0,0 +1,176 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package images
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/png"
+	"math"
+	"strings"
+
+	"github.com/disintegration/gift"
+	"github.com/pkg/errors"
+)
+
+// The placeholder kinds supported by Placeholder.
+const (
+	// A BlurHash (https://blurha.sh), with 4x3 components.
+	PlaceholderBlurhash = "blurhash"
+
+	// A tiny PNG thumbnail as a base64 encoded data URL.
+	PlaceholderThumbBase64 = "thumb-base64"
+)
+
+const (
+	blurhashXComponents = 4
+	blurhashYComponents = 3
+
+	// The size the image is scaled down to before the placeholder is
+	// computed. BlurHash does not benefit from larger images.
+	placeholderBlurhashSize = 32
+	placeholderThumbSize    = 16
+)
+
+// IsPlaceholderKind reports whether kind is a valid placeholder kind.
+func IsPlaceholderKind(kind string) bool {
+	return kind == PlaceholderBlurhash || kind == PlaceholderThumbBase64
+}
+
+// Placeholder returns a tiny placeholder representation of src of the given
+// kind, e.g. to show while the full image loads.
+func (p *ImageProcessor) Placeholder(src image.Image, kind string) (string, error) {
+	switch strings.ToLower(kind) {
+	case PlaceholderBlurhash:
+		img, err := p.Filter(src, gift.ResizeToFit(placeholderBlurhashSize, placeholderBlurhashSize, gift.LinearResampling))
+		if err != nil {
+			return "", err
+		}
+		return EncodeBlurhash(img, blurhashXComponents, blurhashYComponents)
+	case PlaceholderThumbBase64:
+		img, err := p.Filter(src, gift.ResizeToFit(placeholderThumbSize, placeholderThumbSize, gift.LinearResampling))
+		if err != nil {
+			return "", err
+		}
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			return "", err
+		}
+		return "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+	default:
+		return "", errors.Errorf("unsupported placeholder kind %q, must be one of %q or %q", kind, PlaceholderBlurhash, PlaceholderThumbBase64)
+	}
+}
+
+// EncodeBlurhash encodes img as a BlurHash with the given number of
+// components, each between 1 and 9, along the x and y axis.
+func EncodeBlurhash(img image.Image, xComponents, yComponents int) (string, error) {
+	if xComponents < 1 || xComponents > 9 || yComponents < 1 || yComponents > 9 {
+		return "", errors.New("blurhash components must be between 1 and 9")
+	}
+
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width == 0 || height == 0 {
+		return "", errors.New("blurhash: empty image")
+	}
+
+	factors := make([][3]float64, 0, xComponents*yComponents)
+	for j := 0; j < yComponents; j++ {
+		for i := 0; i < xComponents; i++ {
+			normalisation := 2.0
+			if i == 0 && j == 0 {
+				normalisation = 1.0
+			}
+
+			var r, g, b float64
+			for y := 0; y < height; y++ {
+				for x := 0; x < width; x++ {
+					basis := math.Cos(math.Pi*float64(i)*float64(x)/float64(width)) *
+						math.Cos(math.Pi*float64(j)*float64(y)/float64(height))
+					cr, cg, cb, _ := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+					r += basis * srgbToLinear(cr>>8)
+					g += basis * srgbToLinear(cg>>8)
+					b += basis * srgbToLinear(cb>>8)
+				}
+			}
+
+			scale := normalisation / float64(width*height)
+			factors = append(factors, [3]float64{r * scale, g * scale, b * scale})
+		}
+	}
+
+	var sb strings.Builder
+
+	sizeFlag := (xComponents - 1) + (yComponents-1)*9
+	writeBase83(&sb, sizeFlag, 1)
+
+	dc, ac := factors[0], factors[1:]
+
+	maximumValue := 1.0
+	if len(ac) > 0 {
+		var actualMaximumValue float64
+		for _, f := range ac {
+			for _, v := range f {
+				actualMaximumValue = math.Max(actualMaximumValue, math.Abs(v))
+			}
+		}
+		quantisedMaximumValue := int(math.Max(0, math.Min(82, math.Floor(actualMaximumValue*166-0.5))))
+		maximumValue = float64(quantisedMaximumValue+1) / 166
+		writeBase83(&sb, quantisedMaximumValue, 1)
+	} else {
+		writeBase83(&sb, 0, 1)
+	}
+
+	writeBase83(&sb, (linearToSrgb(dc[0])<<16)+(linearToSrgb(dc[1])<<8)+linearToSrgb(dc[2]), 4)
+
+	for _, f := range ac {
+		quant := func(v float64) int {
+			return int(math.Max(0, math.Min(18, math.Floor(signPow(v/maximumValue, 0.5)*9+9.5))))
+		}
+		writeBase83(&sb, quant(f[0])*19*19+quant(f[1])*19+quant(f[2]), 2)
+	}
+
+	return sb.String(), nil
+}
+
+const base83Chars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz#$%*+,-.:;=?@[]^_{|}~"
+
+func writeBase83(sb *strings.Builder, value, length int) {
+	for i := 1; i <= length; i++ {
+		digit := (value / int(math.Pow(83, float64(length-i)))) % 83
+		sb.WriteByte(base83Chars[digit])
+	}
+}
+
+func srgbToLinear(v uint32) float64 {
+	f := float64(v) / 255
+	if f <= 0.04045 {
+		return f / 12.92
+	}
+	return math.Pow((f+0.055)/1.055, 2.4)
+}
+
+func linearToSrgb(v float64) int {
+	v = math.Max(0, math.Min(1, v))
+	if v <= 0.0031308 {
+		return int(v*12.92*255 + 0.5)
+	}
+	return int((1.055*math.Pow(v, 1/2.4)-0.055)*255 + 0.5)
+}
+
+func signPow(v, exp float64) float64 {
+	return math.Copysign(math.Pow(math.Abs(v), exp), v)
+}
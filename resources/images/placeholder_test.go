@@ -0,0 +1,58 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package images
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestEncodeBlurhash(t *testing.T) {
+	c := qt.New(t)
+
+	red := image.NewRGBA(image.Rect(0, 0, 8, 6))
+	draw.Draw(red, red.Bounds(), &image.Uniform{color.RGBA{255, 0, 0, 255}}, image.Point{}, draw.Src)
+
+	h, err := EncodeBlurhash(red, 4, 3)
+	c.Assert(err, qt.IsNil)
+	c.Assert(h, qt.Equals, "LsTI:j]9fQ]9|csUfQsUfQfQfQfQ")
+
+	h, err = EncodeBlurhash(red, 1, 1)
+	c.Assert(err, qt.IsNil)
+	c.Assert(h, qt.Equals, "00TI:j")
+
+	gradient := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	for x := 0; x < 10; x++ {
+		for y := 0; y < 10; y++ {
+			gradient.Set(x, y, color.RGBA{uint8(x * 25), uint8(y * 25), 128, 255})
+		}
+	}
+	h, err = EncodeBlurhash(gradient, 4, 3)
+	c.Assert(err, qt.IsNil)
+	c.Assert(h, qt.HasLen, 28)
+	c.Assert(h[:1], qt.Equals, "L")
+	c.Assert(h[6:], qt.Not(qt.Equals), strings.Repeat("fQ", 11))
+
+	_, err = EncodeBlurhash(red, 0, 3)
+	c.Assert(err, qt.Not(qt.IsNil))
+	_, err = EncodeBlurhash(red, 4, 10)
+	c.Assert(err, qt.Not(qt.IsNil))
+	_, err = EncodeBlurhash(image.NewRGBA(image.Rect(0, 0, 0, 0)), 4, 3)
+	c.Assert(err, qt.Not(qt.IsNil))
+}
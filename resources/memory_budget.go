@@ -0,0 +1,89 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resources
+
+import (
+	"container/list"
+)
+
+// evictionQueue keeps track of the order the keys in a memory cache with a
+// budget were last used in, see config.Memory.
+// It is not thread safe; it's protected by the owning cache's lock.
+type evictionQueue struct {
+	budget int
+
+	order *list.List
+	elems map[string]*list.Element
+}
+
+func newEvictionQueue(budget int) *evictionQueue {
+	return &evictionQueue{
+		budget: budget,
+		order:  list.New(),
+		elems:  make(map[string]*list.Element),
+	}
+}
+
+// enabled reports whether the queue has a budget.
+func (q *evictionQueue) enabled() bool {
+	return q.budget > 0
+}
+
+// add adds key to the queue, or marks it as used if already there, and
+// returns the keys to evict to stay within the budget, least recently used
+// first.
+func (q *evictionQueue) add(key string) []string {
+	if q.budget <= 0 {
+		return nil
+	}
+
+	if e, found := q.elems[key]; found {
+		q.order.MoveToBack(e)
+		return nil
+	}
+
+	q.elems[key] = q.order.PushBack(key)
+
+	var evicted []string
+	for q.order.Len() > q.budget {
+		e := q.order.Front()
+		k := e.Value.(string)
+		q.order.Remove(e)
+		delete(q.elems, k)
+		evicted = append(evicted, k)
+	}
+
+	return evicted
+}
+
+// touch marks key as used, if present.
+func (q *evictionQueue) touch(key string) {
+	if e, found := q.elems[key]; found {
+		q.order.MoveToBack(e)
+	}
+}
+
+// remove removes key from the queue, if present.
+func (q *evictionQueue) remove(key string) {
+	if e, found := q.elems[key]; found {
+		q.order.Remove(e)
+		delete(q.elems, key)
+	}
+}
+
+// clear removes all keys from the queue.
+func (q *evictionQueue) clear() {
+	q.order.Init()
+	q.elems = make(map[string]*list.Element)
+}
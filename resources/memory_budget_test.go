@@ -0,0 +1,51 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resources
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestEvictionQueue(t *testing.T) {
+	c := qt.New(t)
+
+	q := newEvictionQueue(2)
+	c.Assert(q.add("a"), qt.IsNil)
+	c.Assert(q.add("b"), qt.IsNil)
+	c.Assert(q.add("b"), qt.IsNil)
+	c.Assert(q.add("c"), qt.DeepEquals, []string{"a"})
+
+	q.remove("b")
+	c.Assert(q.add("d"), qt.IsNil)
+	c.Assert(q.add("e"), qt.DeepEquals, []string{"c"})
+
+	// The least recently used key is evicted.
+	q.touch("d")
+	c.Assert(q.add("f"), qt.DeepEquals, []string{"e"})
+	c.Assert(q.add("d"), qt.IsNil)
+	c.Assert(q.add("g"), qt.DeepEquals, []string{"f"})
+	q.touch("missing")
+
+	q.clear()
+	c.Assert(q.add("h"), qt.IsNil)
+	c.Assert(q.add("i"), qt.IsNil)
+
+	// No limit.
+	q = newEvictionQueue(0)
+	for _, k := range []string{"a", "b", "c"} {
+		c.Assert(q.add(k), qt.IsNil)
+	}
+}
@@ -17,10 +17,10 @@ package page
 
 import (
 	"html/template"
+	"time"
 
 	"github.com/gohugoio/hugo/identity"
 
-	"github.com/bep/gitmap"
 	"github.com/gohugoio/hugo/config"
 	"github.com/gohugoio/hugo/tpl"
 
@@ -84,6 +84,7 @@ type ContentProvider interface {
 	FuzzyWordCount() int
 	WordCount() int
 	ReadingTime() int
+	ReadingTimeDuration() time.Duration
 	Len() int
 }
 
@@ -107,7 +108,7 @@ type GetPageProvider interface {
 
 // GitInfoProvider provides Git info.
 type GitInfoProvider interface {
-	GitInfo() *gitmap.GitInfo
+	GitInfo() *source.GitInfo
 }
 
 // InSectionPositioner provides section navigation.
@@ -242,6 +243,7 @@ type PageWithoutContent interface {
 	PageRenderProvider
 	PaginatorProvider
 	Positioner
+	SeriesProvider
 	navigation.PageMenusProvider
 
 	// TODO(bep)
@@ -250,6 +252,7 @@ type PageWithoutContent interface {
 	// Page lookups/refs
 	GetPageProvider
 	RefProvider
+	LinksProvider
 
 	resource.TranslationKeyProvider
 	TranslationsProvider
@@ -334,6 +337,11 @@ type TranslationsProvider interface {
 
 	// Translations returns the translations excluding the current Page.
 	Translations() Pages
+
+	// IsFallback returns whether this Page is rendered from the content
+	// in a fallback language, because it is not translated to the
+	// current language.
+	IsFallback() bool
 }
 
 // TreeProvider provides section tree navigation.
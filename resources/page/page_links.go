@@ -0,0 +1,23 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package page
+
+// LinksProvider provides the internal links to and from a page.
+type LinksProvider interface {
+	// Backlinks returns the pages that link to this page.
+	Backlinks() Pages
+
+	// OutboundLinks returns the pages this page links to.
+	OutboundLinks() Pages
+}
@@ -17,7 +17,6 @@ package page
 
 import (
 	"encoding/json"
-	"github.com/bep/gitmap"
 	"github.com/gohugoio/hugo/common/maps"
 	"github.com/gohugoio/hugo/config"
 	"github.com/gohugoio/hugo/hugofs/files"
@@ -42,6 +41,7 @@ func MarshalPageToJSON(p Page) ([]byte, error) {
 	fuzzyWordCount := p.FuzzyWordCount()
 	wordCount := p.WordCount()
 	readingTime := p.ReadingTime()
+	readingTimeDuration := p.ReadingTimeDuration()
 	length := p.Len()
 	tableOfContents := p.TableOfContents()
 	rawContent := p.RawContent()
@@ -88,6 +88,7 @@ func MarshalPageToJSON(p Page) ([]byte, error) {
 	isTranslated := p.IsTranslated()
 	allTranslations := p.AllTranslations()
 	translations := p.Translations()
+	isFallback := p.IsFallback()
 	getIdentity := p.GetIdentity()
 
 	s := struct {
@@ -99,6 +100,7 @@ func MarshalPageToJSON(p Page) ([]byte, error) {
 		FuzzyWordCount           int
 		WordCount                int
 		ReadingTime              int
+		ReadingTimeDuration      time.Duration
 		Len                      int
 		TableOfContents          template.HTML
 		RawContent               string
@@ -137,7 +139,7 @@ func MarshalPageToJSON(p Page) ([]byte, error) {
 		Weight                   int
 		Language                 *langs.Language
 		File                     source.File
-		GitInfo                  *gitmap.GitInfo
+		GitInfo                  *source.GitInfo
 		OutputFormats            OutputFormats
 		AlternativeOutputFormats OutputFormats
 		Menus                    navigation.PageMenus
@@ -145,6 +147,7 @@ func MarshalPageToJSON(p Page) ([]byte, error) {
 		IsTranslated             bool
 		AllTranslations          Pages
 		Translations             Pages
+		IsFallback               bool
 		GetIdentity              identity.Identity
 	}{
 		Content:                  content,
@@ -155,6 +158,7 @@ func MarshalPageToJSON(p Page) ([]byte, error) {
 		FuzzyWordCount:           fuzzyWordCount,
 		WordCount:                wordCount,
 		ReadingTime:              readingTime,
+		ReadingTimeDuration:      readingTimeDuration,
 		Len:                      length,
 		TableOfContents:          tableOfContents,
 		RawContent:               rawContent,
@@ -201,6 +205,7 @@ func MarshalPageToJSON(p Page) ([]byte, error) {
 		IsTranslated:             isTranslated,
 		AllTranslations:          allTranslations,
 		Translations:             translations,
+		IsFallback:               isFallback,
 		GetIdentity:              getIdentity,
 	}
 
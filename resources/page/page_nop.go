@@ -26,7 +26,6 @@ import (
 
 	"github.com/gohugoio/hugo/hugofs"
 
-	"github.com/bep/gitmap"
 	"github.com/gohugoio/hugo/navigation"
 
 	"github.com/gohugoio/hugo/common/hugo"
@@ -88,6 +87,10 @@ func (p *nopPage) BaseFileName() string {
 	return ""
 }
 
+func (p *nopPage) Backlinks() Pages {
+	return nil
+}
+
 func (p *nopPage) BundleType() files.ContentClass {
 	return ""
 }
@@ -186,7 +189,7 @@ func (p *nopPage) GetTerms(taxonomy string) Pages {
 	return nil
 }
 
-func (p *nopPage) GitInfo() *gitmap.GitInfo {
+func (p *nopPage) GitInfo() *source.GitInfo {
 	return nil
 }
 
@@ -218,6 +221,10 @@ func (p *nopPage) IsDraft() bool {
 	return false
 }
 
+func (p *nopPage) IsFallback() bool {
+	return false
+}
+
 func (p *nopPage) IsHome() bool {
 	return false
 }
@@ -290,6 +297,10 @@ func (p *nopPage) Next() Page {
 	return nil
 }
 
+func (p *nopPage) OutboundLinks() Pages {
+	return nil
+}
+
 func (p *nopPage) OutputFormats() OutputFormats {
 	return nil
 }
@@ -378,6 +389,10 @@ func (p *nopPage) ReadingTime() int {
 	return 0
 }
 
+func (p *nopPage) ReadingTimeDuration() time.Duration {
+	return 0
+}
+
 func (p *nopPage) Ref(argsm map[string]interface{}) (string, error) {
 	return "", nil
 }
@@ -430,6 +445,10 @@ func (p *nopPage) SectionsPath() string {
 	return ""
 }
 
+func (p *nopPage) Series() *PageSeries {
+	return nil
+}
+
 func (p *nopPage) Site() Site {
 	return nil
 }
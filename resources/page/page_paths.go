@@ -69,6 +69,10 @@ type TargetPathDescriptor struct {
 
 	// Some types cannot have uglyURLs, even if globally enabled, RSS being one example.
 	UglyURLs bool
+
+	// Whether to drop the trailing slash from pretty links, e.g. /posts/my-post
+	// instead of /posts/my-post/. This does not change the target filename.
+	NoTrailingSlash bool
 }
 
 // TODO(bep) move this type.
@@ -320,6 +324,9 @@ func CreateTargetPaths(d TargetPathDescriptor) (tp TargetPaths) {
 	tp.SubResourceBaseTarget = filepath.FromSlash(pagePathDir)
 	tp.SubResourceBaseLink = linkDir
 	tp.Link = d.PathSpec.URLizeFilename(link)
+	if d.NoTrailingSlash && tp.Link != slash {
+		tp.Link = strings.TrimSuffix(tp.Link, slash)
+	}
 	if tp.Link == "" {
 		tp.Link = slash
 	}
@@ -0,0 +1,90 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package page
+
+// SeriesProvider provides the series a page belongs to.
+type SeriesProvider interface {
+	// Series returns the series the page belongs to, nil if none.
+	Series() *PageSeries
+}
+
+// Series is an ordered collection of pages, e.g. the parts of a tutorial.
+type Series struct {
+	// The series key, e.g. "go-basics".
+	Key string
+
+	// The series title, e.g. "Go Basics".
+	Title string
+
+	// The pages in the series, in order.
+	Pages Pages
+
+	// The series landing page, nil if none.
+	Page Page
+}
+
+// Len returns the number of pages in the series.
+func (s *Series) Len() int {
+	return len(s.Pages)
+}
+
+// First returns the first page in the series.
+func (s *Series) First() Page {
+	if len(s.Pages) == 0 {
+		return nil
+	}
+	return s.Pages[0]
+}
+
+// Last returns the last page in the series.
+func (s *Series) Last() Page {
+	if len(s.Pages) == 0 {
+		return nil
+	}
+	return s.Pages[len(s.Pages)-1]
+}
+
+// PageSeries is a Series seen from one of its pages.
+type PageSeries struct {
+	*Series
+
+	// The 1-based position of the page in the series, 0 for the landing page.
+	Position int
+}
+
+// Next returns the next page in the series, nil if this is the last.
+func (s *PageSeries) Next() Page {
+	if s.Position >= len(s.Pages) {
+		return nil
+	}
+	return s.Pages[s.Position]
+}
+
+// Prev returns the previous page in the series, nil if this is the first.
+func (s *PageSeries) Prev() Page {
+	if s.Position < 2 {
+		return nil
+	}
+	return s.Pages[s.Position-2]
+}
+
+// IsFirst returns whether this is the first page in the series.
+func (s *PageSeries) IsFirst() bool {
+	return s.Position == 1
+}
+
+// IsLast returns whether this is the last page in the series.
+func (s *PageSeries) IsLast() bool {
+	return s.Position > 0 && s.Position == len(s.Pages)
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/gohugoio/hugo/resources/resource"
 
 	"github.com/gohugoio/hugo/config"
+	"github.com/gohugoio/hugo/config/configschema"
 	"github.com/spf13/cast"
 )
 
@@ -192,6 +193,17 @@ func newDefaultFrontmatterConfig() frontmatterConfig {
 	}
 }
 
+func init() {
+	c := newDefaultFrontmatterConfig()
+	configschema.AddSection("frontmatter", map[string][]string{
+		"date":        c.date,
+		"lastmod":     c.lastmod,
+		"publishDate": c.publishDate,
+		"expiryDate":  c.expiryDate,
+	})
+	configschema.AddSection("contentTypes", ContentTypes{})
+}
+
 func newFrontmatterConfig(cfg config.Provider) (frontmatterConfig, error) {
 	c := newDefaultFrontmatterConfig()
 	defaultConfig := c
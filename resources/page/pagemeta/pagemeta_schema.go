@@ -0,0 +1,212 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pagemeta
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+	"github.com/spf13/cast"
+)
+
+// The field types supported in a front matter schema.
+const (
+	FieldTypeString = "string"
+	FieldTypeInt    = "int"
+	FieldTypeFloat  = "float"
+	FieldTypeBool   = "bool"
+	FieldTypeDate   = "date"
+	FieldTypeArray  = "array"
+	FieldTypeMap    = "map"
+)
+
+var fieldTypes = []string{FieldTypeString, FieldTypeInt, FieldTypeFloat, FieldTypeBool, FieldTypeDate, FieldTypeArray, FieldTypeMap}
+
+// ContentTypes holds the front matter schemas keyed by the lower case
+// content type, i.e. the section or the type set in front matter.
+type ContentTypes map[string]ContentType
+
+// ContentType holds the front matter schema for a content type.
+type ContentType struct {
+	// The front matter fields, keyed by the lower case field name.
+	Fields map[string]FieldSchema
+}
+
+// FieldSchema describes a front matter field.
+type FieldSchema struct {
+	// Whether the field must be set.
+	Required bool
+
+	// The field type, one of string, int, float, bool, date, array or map.
+	// Any type is accepted if not set.
+	Type string
+
+	// If set, the value must be one of these. For arrays, this applies to
+	// every element.
+	Allowed []interface{}
+}
+
+// FieldError describes a front matter field not matching its schema.
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("front matter field %q %s", e.Field, e.Message)
+}
+
+// DecodeContentTypes decodes the contentTypes site configuration.
+func DecodeContentTypes(m map[string]interface{}) (ContentTypes, error) {
+	if len(m) == 0 {
+		return nil, nil
+	}
+
+	types := make(ContentTypes)
+
+	for name, v := range m {
+		var ct ContentType
+		if err := mapstructure.WeakDecode(v, &ct); err != nil {
+			return nil, errors.Wrapf(err, "failed to decode content type %q", name)
+		}
+
+		fields := make(map[string]FieldSchema)
+		for field, schema := range ct.Fields {
+			schema.Type = strings.ToLower(schema.Type)
+			if schema.Type != "" && !isFieldType(schema.Type) {
+				return nil, errors.Errorf("content type %q: invalid type %q for field %q, must be one of %s", name, schema.Type, field, strings.Join(fieldTypes, ", "))
+			}
+			fields[strings.ToLower(field)] = schema
+		}
+		ct.Fields = fields
+		types[strings.ToLower(name)] = ct
+	}
+
+	return types, nil
+}
+
+func isFieldType(s string) bool {
+	for _, t := range fieldTypes {
+		if s == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate validates the front matter, with lower case keys, against the
+// schema. The errors are sorted by field name.
+func (c ContentType) Validate(frontmatter map[string]interface{}) []*FieldError {
+	var errs []*FieldError
+
+	for field, schema := range c.Fields {
+		v, found := frontmatter[field]
+		if !found || v == nil {
+			if schema.Required {
+				errs = append(errs, &FieldError{Field: field, Message: "is required"})
+			}
+			continue
+		}
+
+		if schema.Type != "" && !isOfFieldType(v, schema.Type) {
+			errs = append(errs, &FieldError{Field: field, Message: fmt.Sprintf("must be of type %s, got %T", schema.Type, v)})
+			continue
+		}
+
+		if len(schema.Allowed) > 0 {
+			values := []interface{}{v}
+			if rv := reflect.ValueOf(v); rv.Kind() == reflect.Slice {
+				values = values[:0]
+				for i := 0; i < rv.Len(); i++ {
+					values = append(values, rv.Index(i).Interface())
+				}
+			}
+			for _, vv := range values {
+				if !isAllowed(vv, schema.Allowed) {
+					errs = append(errs, &FieldError{Field: field, Message: fmt.Sprintf("has value %q, must be one of %s", cast.ToString(vv), allowedString(schema.Allowed))})
+					break
+				}
+			}
+		}
+	}
+
+	sort.Slice(errs, func(i, j int) bool {
+		return errs[i].Field < errs[j].Field
+	})
+
+	return errs
+}
+
+func isOfFieldType(v interface{}, typ string) bool {
+	switch typ {
+	case FieldTypeString:
+		_, ok := v.(string)
+		return ok
+	case FieldTypeInt:
+		switch vv := v.(type) {
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+			return true
+		case float64:
+			// JSON numbers.
+			return vv == float64(int64(vv))
+		}
+		return false
+	case FieldTypeFloat:
+		switch v.(type) {
+		case float32, float64, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+			return true
+		}
+		return false
+	case FieldTypeBool:
+		_, ok := v.(bool)
+		return ok
+	case FieldTypeDate:
+		switch vv := v.(type) {
+		case time.Time:
+			return true
+		case string:
+			_, err := cast.ToTimeE(vv)
+			return err == nil
+		}
+		return false
+	case FieldTypeArray:
+		return reflect.ValueOf(v).Kind() == reflect.Slice
+	case FieldTypeMap:
+		return reflect.ValueOf(v).Kind() == reflect.Map
+	}
+	return true
+}
+
+func isAllowed(v interface{}, allowed []interface{}) bool {
+	s := cast.ToString(v)
+	for _, a := range allowed {
+		if cast.ToString(a) == s {
+			return true
+		}
+	}
+	return false
+}
+
+func allowedString(allowed []interface{}) string {
+	s := make([]string, len(allowed))
+	for i, a := range allowed {
+		s[i] = fmt.Sprintf("%q", cast.ToString(a))
+	}
+	return strings.Join(s, ", ")
+}
@@ -0,0 +1,103 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pagemeta
+
+import (
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestDecodeContentTypes(t *testing.T) {
+	c := qt.New(t)
+
+	types, err := DecodeContentTypes(map[string]interface{}{
+		"Posts": map[string]interface{}{
+			"fields": map[string]interface{}{
+				"Title":    map[string]interface{}{"required": true, "type": "String"},
+				"category": map[string]interface{}{"allowed": []interface{}{"news", "blog"}},
+			},
+		},
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(types["posts"].Fields["title"], qt.DeepEquals, FieldSchema{Required: true, Type: FieldTypeString})
+	c.Assert(types["posts"].Fields["category"].Allowed, qt.DeepEquals, []interface{}{"news", "blog"})
+
+	_, err = DecodeContentTypes(map[string]interface{}{
+		"posts": map[string]interface{}{
+			"fields": map[string]interface{}{
+				"title": map[string]interface{}{"type": "text"},
+			},
+		},
+	})
+	c.Assert(err, qt.ErrorMatches, `.*invalid type "text" for field "title".*`)
+
+	types, err = DecodeContentTypes(nil)
+	c.Assert(err, qt.IsNil)
+	c.Assert(types, qt.IsNil)
+}
+
+func TestContentTypeValidate(t *testing.T) {
+	c := qt.New(t)
+
+	ct := ContentType{Fields: map[string]FieldSchema{
+		"title":    {Required: true, Type: FieldTypeString},
+		"weight":   {Type: FieldTypeInt},
+		"rating":   {Type: FieldTypeFloat},
+		"featured": {Type: FieldTypeBool},
+		"expires":  {Type: FieldTypeDate},
+		"tags":     {Type: FieldTypeArray, Allowed: []interface{}{"go", "hugo"}},
+		"author":   {Type: FieldTypeMap},
+		"category": {Allowed: []interface{}{"news", "blog"}},
+	}}
+
+	valid := map[string]interface{}{
+		"title":    "My Post",
+		"weight":   int64(10),
+		"rating":   4,
+		"featured": true,
+		"expires":  "2021-06-01",
+		"tags":     []interface{}{"go", "hugo"},
+		"author":   map[string]interface{}{"name": "Jo"},
+		"category": "news",
+	}
+	c.Assert(ct.Validate(valid), qt.HasLen, 0)
+	c.Assert(ct.Validate(map[string]interface{}{"title": "T", "expires": time.Now(), "weight": float64(3)}), qt.HasLen, 0)
+
+	errs := ct.Validate(map[string]interface{}{
+		"weight":   "ten",
+		"rating":   "high",
+		"featured": "yes",
+		"expires":  "soon",
+		"tags":     []interface{}{"go", "rust"},
+		"author":   "Jo",
+		"category": "misc",
+	})
+
+	var got []string
+	for _, err := range errs {
+		got = append(got, err.Error())
+	}
+	c.Assert(got, qt.DeepEquals, []string{
+		`front matter field "author" must be of type map, got string`,
+		`front matter field "category" has value "misc", must be one of "news", "blog"`,
+		`front matter field "expires" must be of type date, got string`,
+		`front matter field "featured" must be of type bool, got string`,
+		`front matter field "rating" must be of type float, got string`,
+		`front matter field "tags" has value "rust", must be one of "go", "hugo"`,
+		`front matter field "title" is required`,
+		`front matter field "weight" must be of type int, got string`,
+	})
+}
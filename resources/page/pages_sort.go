@@ -74,10 +74,13 @@ var (
 			if p1.Date().Unix() == p2.Date().Unix() {
 				c := compare.Strings(p1.LinkTitle(), p2.LinkTitle())
 				if c == 0 {
-					if p1.File().IsZero() || p2.File().IsZero() {
+					if p1.File().IsZero() != p2.File().IsZero() {
 						return p1.File().IsZero()
 					}
-					return compare.LessStrings(p1.File().Filename(), p2.File().Filename())
+					if !p1.File().IsZero() && p1.File().Filename() != p2.File().Filename() {
+						return compare.LessStrings(p1.File().Filename(), p2.File().Filename())
+					}
+					return lessPageIdentity(p1, p2)
 				}
 				return c < 0
 			}
@@ -95,6 +98,19 @@ var (
 		return p1.Weight() < p2.Weight()
 	}
 
+	// lessPageIdentity orders pages with nothing else to tell them apart,
+	// e.g. sections without a content file, so the order doesn't depend on
+	// the order the pages were created in.
+	lessPageIdentity = func(p1, p2 Page) bool {
+		if p1.Lang() != p2.Lang() {
+			return p1.Lang() < p2.Lang()
+		}
+		if p1.Kind() != p2.Kind() {
+			return p1.Kind() < p2.Kind()
+		}
+		return p1.Path() < p2.Path()
+	}
+
 	lessPageLanguage = func(p1, p2 Page) bool {
 		if p1.Language().Weight == p2.Language().Weight {
 			if p1.Date().Unix() == p2.Date().Unix() {
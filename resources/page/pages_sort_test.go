@@ -66,6 +66,31 @@ func TestDefaultSort(t *testing.T) {
 	c.Assert(p[2].LinkTitle(), qt.Equals, "cl")
 }
 
+func TestDefaultSortTotalOrder(t *testing.T) {
+	t.Parallel()
+	c := qt.New(t)
+	d := time.Now()
+
+	// Nothing but the path tells these apart.
+	p := createSortTestPages(4)
+	setSortVals([4]time.Time{d, d, d, d}, [4]string{"a", "a", "a", "a"}, [4]int{1, 1, 1, 1}, p)
+
+	for i := 0; i < 3; i++ {
+		p.shuffle()
+		SortByDefault(p)
+		for j, pp := range p {
+			c.Assert(pp.Path(), qt.Equals, fmt.Sprintf("/x/y/p%d.md", j))
+		}
+	}
+
+	for _, pp := range p {
+		pp.(*testPage).kind = KindPage
+	}
+	p[3].(*testPage).kind = KindHome
+	SortByDefault(p)
+	c.Assert(p[0].Kind(), qt.Equals, KindHome)
+}
+
 // https://github.com/gohugoio/hugo/issues/4953
 func TestSortByLinkTitle(t *testing.T) {
 	t.Parallel()
@@ -23,11 +23,27 @@ import (
 	"strings"
 	"time"
 
+	"github.com/mitchellh/mapstructure"
 	"github.com/pkg/errors"
+	"github.com/spf13/cast"
 
+	"github.com/gohugoio/hugo/common/maps"
 	"github.com/gohugoio/hugo/helpers"
 )
 
+const permalinkTokensConfigKey = "permalinkTokens"
+
+// PermalinkToken configures a custom permalink token.
+type PermalinkToken struct {
+	// Where to get the value from, either params.<key> for a page param
+	// (falling back to the site params), or terms.<taxonomy> for the first
+	// term in the given taxonomy, e.g. terms.categories.
+	From string
+
+	// The value to use when the page has no value for From.
+	Default string
+}
+
 // PermalinkExpander holds permalin mappings per section.
 type PermalinkExpander struct {
 	// knownPermalinkAttributes maps :tags in a permalink specification to a
@@ -86,6 +102,17 @@ func NewPermalinkExpander(ps *helpers.PathSpec) (PermalinkExpander, error) {
 		"filename":    p.pageToPermalinkFilename,
 	}
 
+	tokens, err := decodePermalinkTokens(ps.Cfg.Get(permalinkTokensConfigKey))
+	if err != nil {
+		return p, err
+	}
+	for name, token := range tokens {
+		if _, found := p.knownPermalinkAttributes[name]; found {
+			return p, errors.Errorf("%s: %q is a built-in permalink token", permalinkTokensConfigKey, name)
+		}
+		p.knownPermalinkAttributes[name] = p.pageToPermalinkToken(token)
+	}
+
 	patterns := ps.Cfg.GetStringMapString("permalinks")
 	if patterns == nil {
 		return p, nil
@@ -241,6 +268,96 @@ func (l PermalinkExpander) pageToPermalinkDate(p Page, dateField string) (string
 	return p.Date().Format(dateField), nil
 }
 
+// pageToPermalinkToken returns a callback that gives the URL-safe form of the
+// value of the given custom token.
+func (l PermalinkExpander) pageToPermalinkToken(token PermalinkToken) pageToPermaAttribute {
+	source, key := splitPermalinkTokenFrom(token.From)
+	return func(p Page, attr string) (string, error) {
+		var v string
+		switch source {
+		case "params":
+			pv, err := p.Param(key)
+			if err != nil {
+				return "", err
+			}
+			v = firstString(pv)
+		case "terms":
+			v = firstString(p.Params()[key])
+		}
+
+		if v == "" {
+			v = token.Default
+		}
+		if v == "" {
+			return "", errors.Errorf("no value for :%s in %s", attr, token.From)
+		}
+
+		return l.ps.URLize(v), nil
+	}
+}
+
+// firstString returns v as a string, or the first element if v is a slice.
+func firstString(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	if ss, err := cast.ToStringSliceE(v); err == nil {
+		if len(ss) > 0 {
+			return ss[0]
+		}
+		return ""
+	}
+	return cast.ToString(v)
+}
+
+func splitPermalinkTokenFrom(from string) (source, key string) {
+	parts := strings.SplitN(from, ".", 2)
+	if len(parts) != 2 {
+		return "", ""
+	}
+	return strings.ToLower(parts[0]), strings.ToLower(parts[1])
+}
+
+var permalinkTokenNameRe = regexp.MustCompile(`^\w+$`)
+
+// decodePermalinkTokens decodes the permalinkTokens config, a map from token
+// name to either the From string or a PermalinkToken.
+func decodePermalinkTokens(in interface{}) (map[string]PermalinkToken, error) {
+	if in == nil {
+		return nil, nil
+	}
+
+	m, err := maps.ToStringMapE(in)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decode %s config", permalinkTokensConfigKey)
+	}
+
+	tokens := make(map[string]PermalinkToken)
+	for name, v := range m {
+		var token PermalinkToken
+		switch vv := v.(type) {
+		case string:
+			token.From = vv
+		default:
+			if err := mapstructure.WeakDecode(v, &token); err != nil {
+				return nil, errors.Wrapf(err, "failed to decode %s config", permalinkTokensConfigKey)
+			}
+		}
+
+		if !permalinkTokenNameRe.MatchString(name) {
+			return nil, errors.Errorf("%s: invalid token name %q", permalinkTokensConfigKey, name)
+		}
+		source, key := splitPermalinkTokenFrom(token.From)
+		if key == "" || (source != "params" && source != "terms") {
+			return nil, errors.Errorf("%s: invalid from %q for token %q, must be on the form params.<key> or terms.<taxonomy>", permalinkTokensConfigKey, token.From, name)
+		}
+
+		tokens[name] = token
+	}
+
+	return tokens, nil
+}
+
 // pageToPermalinkTitle returns the URL-safe form of the title
 func (l PermalinkExpander) pageToPermalinkTitle(p Page, _ string) (string, error) {
 	return l.ps.URLize(p.Title()), nil
@@ -122,6 +122,57 @@ func TestPermalinkExpansionMultiSection(t *testing.T) {
 	c.Assert(expanded, qt.Equals, "/blue/2012")
 }
 
+func TestPermalinkExpansionCustomTokens(t *testing.T) {
+	t.Parallel()
+
+	c := qt.New(t)
+
+	page := newTestPage()
+	page.title = "Page Title"
+	page.params = map[string]interface{}{
+		"author":     "Jane Doe",
+		"categories": []string{"Go Tips", "Hugo"},
+	}
+
+	ps := newTestPathSpec()
+	ps.Cfg.Set("permalinks", map[string]string{
+		"posts": "/:category/:author/:title/",
+		"blog":  "/:editor/:title/",
+	})
+	ps.Cfg.Set("permalinkTokens", map[string]interface{}{
+		"author":   "params.author",
+		"category": map[string]interface{}{"from": "terms.categories", "default": "misc"},
+		"editor":   "params.editor",
+	})
+
+	expander, err := NewPermalinkExpander(ps)
+	c.Assert(err, qt.IsNil)
+
+	expanded, err := expander.Expand("posts", page)
+	c.Assert(err, qt.IsNil)
+	c.Assert(expanded, qt.Equals, "/go-tips/jane-doe/page-title/")
+
+	page.params = map[string]interface{}{"author": "Jane Doe"}
+	expanded, err = expander.Expand("posts", page)
+	c.Assert(err, qt.IsNil)
+	c.Assert(expanded, qt.Equals, "/misc/jane-doe/page-title/")
+
+	_, err = expander.Expand("blog", page)
+	c.Assert(err, qt.ErrorMatches, `.*no value for :editor in params.editor`)
+
+	for _, invalid := range []map[string]interface{}{
+		{"title": "params.title"},
+		{"author": "author"},
+		{"author": "site.author"},
+		{"my-author": "params.author"},
+	} {
+		ps := newTestPathSpec()
+		ps.Cfg.Set("permalinkTokens", invalid)
+		_, err := NewPermalinkExpander(ps)
+		c.Assert(err, qt.Not(qt.IsNil))
+	}
+}
+
 func TestPermalinkExpansionConcurrent(t *testing.T) {
 	t.Parallel()
 
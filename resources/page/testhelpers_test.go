@@ -26,10 +26,8 @@ import (
 
 	"github.com/gohugoio/hugo/modules"
 
-	"github.com/bep/gitmap"
 	"github.com/gohugoio/hugo/helpers"
 	"github.com/gohugoio/hugo/resources/resource"
-	
 
 	"github.com/gohugoio/hugo/navigation"
 
@@ -145,6 +143,10 @@ func (p *testPage) BaseFileName() string {
 	panic("not implemented")
 }
 
+func (p *testPage) Backlinks() Pages {
+	return nil
+}
+
 func (p *testPage) BundleType() files.ContentClass {
 	panic("not implemented")
 }
@@ -245,7 +247,7 @@ func (p *testPage) GetRelatedDocsHandler() *RelatedDocsHandler {
 	return relatedDocsHandler
 }
 
-func (p *testPage) GitInfo() *gitmap.GitInfo {
+func (p *testPage) GitInfo() *source.GitInfo {
 	return nil
 }
 
@@ -277,6 +279,10 @@ func (p *testPage) IsDraft() bool {
 	return false
 }
 
+func (p *testPage) IsFallback() bool {
+	panic("not implemented")
+}
+
 func (p *testPage) IsHome() bool {
 	panic("not implemented")
 }
@@ -367,6 +373,10 @@ func (p *testPage) NextPage() Page {
 	return nil
 }
 
+func (p *testPage) OutboundLinks() Pages {
+	return nil
+}
+
 func (p *testPage) OutputFormats() OutputFormats {
 	panic("not implemented")
 }
@@ -451,6 +461,10 @@ func (p *testPage) ReadingTime() int {
 	panic("not implemented")
 }
 
+func (p *testPage) ReadingTimeDuration() time.Duration {
+	panic("not implemented")
+}
+
 func (p *testPage) Ref(argsm map[string]interface{}) (string, error) {
 	panic("not implemented")
 }
@@ -516,6 +530,10 @@ func (p *testPage) SectionsPath() string {
 	return path.Join(p.sectionEntries...)
 }
 
+func (p *testPage) Series() *PageSeries {
+	return nil
+}
+
 func (p *testPage) Site() Site {
 	panic("not implemented")
 }
@@ -81,6 +81,10 @@ type ResourceSourceDescriptor struct {
 
 	// Delay publishing until either Permalink or RelPermalink is called. Maybe never.
 	LazyPublish bool
+
+	// The media type of the resource. If not set, it is resolved from the
+	// extension of RelTargetFilename.
+	MediaType media.Type
 }
 
 func (r ResourceSourceDescriptor) Filename() string {
@@ -62,6 +62,10 @@ type ImageOps interface {
 	Filter(filters ...interface{}) (Image, error)
 	Exif() *exif.Exif
 
+	// Placeholder returns a tiny placeholder representation of the image,
+	// "blurhash" or "thumb-base64".
+	Placeholder(kind string) (string, error)
+
 	// Internal
 	DecodeImage() (image.Image, error)
 }
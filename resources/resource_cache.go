@@ -50,6 +50,9 @@ type ResourceCache struct {
 
 	// Provides named resource locks.
 	nlocker *locker.Locker
+
+	// Keeps the cache within the resources memory budget.
+	evictions *evictionQueue
 }
 
 // ResourceCacheKey converts the filename into the format used in the resource
@@ -130,6 +133,7 @@ func newResourceCache(rs *Spec) *ResourceCache {
 		fileCache: rs.FileCaches.AssetsCache(),
 		cache:     make(map[string]interface{}),
 		nlocker:   locker.NewLocker(),
+		evictions: newEvictionQueue(rs.Memory.Resources),
 	}
 }
 
@@ -139,6 +143,7 @@ func (c *ResourceCache) clear() {
 
 	c.cache = make(map[string]interface{})
 	c.nlocker = locker.NewLocker()
+	c.evictions.clear()
 }
 
 func (c *ResourceCache) Contains(key string) bool {
@@ -153,8 +158,13 @@ func (c *ResourceCache) cleanKey(key string) string {
 
 func (c *ResourceCache) get(key string) (interface{}, bool) {
 	c.RLock()
-	defer c.RUnlock()
 	r, found := c.cache[key]
+	c.RUnlock()
+	if found && c.evictions.enabled() {
+		c.Lock()
+		c.evictions.touch(key)
+		c.Unlock()
+	}
 	return r, found
 }
 
@@ -203,6 +213,19 @@ func (c *ResourceCache) getOrCreate(key string, f func() (interface{}, error)) (
 	return r, nil
 }
 
+// Entries returns the number of entries in memory per cache partition
+// (typically the file extension).
+func (c *ResourceCache) Entries() map[string]int {
+	c.RLock()
+	defer c.RUnlock()
+
+	m := make(map[string]int)
+	for k := range c.cache {
+		m[strings.Split(k, "/")[0]]++
+	}
+	return m
+}
+
 func (c *ResourceCache) getFilenames(key string) (string, string) {
 	filenameMeta := key + ".json"
 	filenameContent := key + ".content"
@@ -258,6 +281,10 @@ func (c *ResourceCache) set(key string, r interface{}) {
 	c.Lock()
 	defer c.Unlock()
 	c.cache[key] = r
+	for _, k := range c.evictions.add(key) {
+		delete(c.cache, k)
+		c.rs.ProcessingStats.Incr(&c.rs.ProcessingStats.EvictedResources)
+	}
 }
 
 func (c *ResourceCache) DeletePartitions(partitions ...string) {
@@ -289,6 +316,7 @@ func (c *ResourceCache) DeletePartitions(partitions ...string) {
 
 		if clear {
 			delete(c.cache, k)
+			c.evictions.remove(k)
 		}
 	}
 }
@@ -300,6 +328,7 @@ func (c *ResourceCache) DeleteMatches(re *regexp.Regexp) {
 	for k := range c.cache {
 		if re.MatchString(k) {
 			delete(c.cache, k)
+			c.evictions.remove(k)
 		}
 	}
 }
@@ -25,10 +25,17 @@ import (
 	"github.com/gohugoio/hugo/hugofs"
 
 	"github.com/gohugoio/hugo/common/hugio"
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/gohugoio/hugo/media"
 	"github.com/gohugoio/hugo/resources"
 	"github.com/gohugoio/hugo/resources/resource"
 )
 
+// The resource cache key prefix for resources created with FromString. These
+// are keyed by content and, unlike the "other" partition, not cleared on
+// every rebuild.
+const fromStringCacheKeyPrefix = "fromstring"
+
 // Client contains methods to create Resource objects.
 // tasks to Resource objects.
 type Client struct {
@@ -112,9 +119,16 @@ func (c *Client) match(pattern string, firstOnly bool) (resource.Resources, erro
 	})
 }
 
-// FromString creates a new Resource from a string with the given relative target path.
-func (c *Client) FromString(targetPath, content string) (resource.Resource, error) {
-	return c.rs.ResourceCache.GetOrCreate(path.Join(resources.CACHE_OTHER, targetPath), func() (resource.Resource, error) {
+// FromString creates a new Resource from a string with the given relative
+// target path. If mediaType is zero, it is resolved from the target path.
+//
+// The resource is cached by its content, so an unchanged resource is reused
+// across rebuilds. It is only published when its Permalink or RelPermalink is
+// accessed.
+func (c *Client) FromString(targetPath, content string, mediaType media.Type) (resource.Resource, error) {
+	targetPath = filepath.Clean(targetPath)
+	key := path.Join(fromStringCacheKeyPrefix, helpers.MD5String(mediaType.Type()+content), filepath.ToSlash(targetPath))
+	return c.rs.ResourceCache.GetOrCreate(key, func() (resource.Resource, error) {
 		return c.rs.New(
 			resources.ResourceSourceDescriptor{
 				Fs:          c.rs.FileCaches.AssetsCache().Fs,
@@ -122,7 +136,8 @@ func (c *Client) FromString(targetPath, content string) (resource.Resource, erro
 				OpenReadSeekCloser: func() (hugio.ReadSeekCloser, error) {
 					return hugio.NewReadSeekerNoOpCloserFromString(content), nil
 				},
-				RelTargetFilename: filepath.Clean(targetPath),
+				RelTargetFilename: targetPath,
+				MediaType:         mediaType,
 			})
 	})
 }
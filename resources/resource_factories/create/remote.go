@@ -0,0 +1,77 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package create
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gohugoio/hugo/config/security"
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/pkg/errors"
+)
+
+// The timeout for fetching a remote resource.
+var remoteTimeout = 30 * time.Second
+
+// IsRemote reports whether s is the URL of a remote resource.
+func IsRemote(s string) bool {
+	return strings.HasPrefix(s, "https://") || strings.HasPrefix(s, "http://")
+}
+
+// FetchRemote returns the content of the remote resource at uri. The
+// content is cached in the getresource file cache, keyed by uri.
+func (c *Client) FetchRemote(uri string) ([]byte, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse URL %q", uri)
+	}
+
+	sc, ok := c.rs.Cfg.Get("securityConfig").(security.Config)
+	if !ok {
+		sc = security.DefaultConfig
+	}
+	if err := sc.CheckAllowedHTTP(http.MethodGet, u); err != nil {
+		return nil, err
+	}
+
+	_, b, err := c.rs.FileCaches.GetResourceCache().GetOrCreateBytes(helpers.MD5String(uri), func() ([]byte, error) {
+		client := sc.NewHTTPClient(remoteTimeout)
+		req, err := http.NewRequest(http.MethodGet, uri, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("User-Agent", "Hugo Static Site Generator")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			io.Copy(ioutil.Discard, resp.Body)
+			return nil, fmt.Errorf("failed to fetch remote resource %q: %s", uri, resp.Status)
+		}
+
+		return ioutil.ReadAll(resp.Body)
+	})
+
+	return b, err
+}
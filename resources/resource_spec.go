@@ -22,6 +22,7 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/gohugoio/hugo/resources/jsconfig"
 
@@ -37,7 +38,9 @@ import (
 	"github.com/gohugoio/hugo/cache/filecache"
 	"github.com/gohugoio/hugo/common/loggers"
 	"github.com/gohugoio/hugo/media"
+	"github.com/gohugoio/hugo/metrics"
 	"github.com/gohugoio/hugo/output"
+	"github.com/gohugoio/hugo/plugins"
 	"github.com/gohugoio/hugo/resources/images"
 	"github.com/gohugoio/hugo/resources/page"
 	"github.com/gohugoio/hugo/resources/resource"
@@ -76,6 +79,14 @@ func NewSpec(
 		return nil, err
 	}
 
+	buildConfig := config.DecodeBuild(s.Cfg)
+	memoryConfig := config.DecodeMemory(s.Cfg)
+
+	workers := newWorkers(buildConfig.Workers, s.Cfg.GetBool("running"))
+	if workers.LowPriority {
+		imaging.DisableParallelization()
+	}
+
 	rs := &Spec{
 		PathSpec:      s,
 		Logger:        logger,
@@ -85,16 +96,18 @@ func NewSpec(
 		MediaTypes:    mimeTypes,
 		OutputFormats: outputFormats,
 		Permalinks:    permalinks,
-		BuildConfig:   config.DecodeBuild(s.Cfg),
+		BuildConfig:   buildConfig,
+		Memory:        memoryConfig,
 		FileCaches:    fileCaches,
+		Workers:       workers,
 		PostBuildAssets: &PostBuildAssets{
 			PostProcessResources: make(map[string]postpub.PostPublishedResource),
 			JSConfigBuilder:      jsconfig.NewBuilder(),
 		},
 		imageCache: newImageCache(
 			fileCaches.ImageCache(),
-
 			s,
+			memoryConfig.Images,
 		),
 	}
 
@@ -116,6 +129,7 @@ type Spec struct {
 
 	Permalinks  page.PermalinkExpander
 	BuildConfig config.Build
+	Memory      config.Memory
 
 	// Holds default filter settings etc.
 	imaging *images.ImageProcessor
@@ -125,6 +139,24 @@ type Spec struct {
 	ResourceCache *ResourceCache
 	FileCaches    filecache.Caches
 
+	// Limits the number of concurrent heavy transformations.
+	// This is shared between all sites.
+	Workers *Workers
+
+	// Set when per-page render timings are enabled.
+	PageMetrics *metrics.PageMetrics
+
+	// Set when build tracing is enabled.
+	Tracer *metrics.Tracer
+
+	// The WASM plugins configured, nil if none.
+	Plugins *plugins.Plugins
+
+	// The number of times a resource has been asked to publish itself,
+	// directly or through its permalink, or an image has been processed
+	// (which writes it to the publish dir).
+	publishRequests uint64
+
 	// Assets used after the build is done.
 	// This is shared between all sites.
 	*PostBuildAssets
@@ -140,6 +172,16 @@ func (r *Spec) New(fd ResourceSourceDescriptor) (resource.Resource, error) {
 	return r.newResourceFor(fd)
 }
 
+// PublishRequests returns the number of times a resource may have been
+// published since this Spec was created.
+func (r *Spec) PublishRequests() uint64 {
+	return atomic.LoadUint64(&r.publishRequests)
+}
+
+func (r *Spec) incrPublishRequests() {
+	atomic.AddUint64(&r.publishRequests, 1)
+}
+
 func (r *Spec) CacheStats() string {
 	r.imageCache.mu.RLock()
 	defer r.imageCache.mu.RUnlock()
@@ -158,6 +200,20 @@ func (r *Spec) CacheStats() string {
 	return s
 }
 
+// ImageCacheEntries returns the number of processed images in memory.
+func (r *Spec) ImageCacheEntries() int {
+	r.imageCache.mu.RLock()
+	defer r.imageCache.mu.RUnlock()
+	return len(r.imageCache.store)
+}
+
+// ImageRenditions returns the named image rendition set in the imaging
+// config.
+func (r *Spec) ImageRenditions(name string) ([]images.Rendition, bool) {
+	renditions, found := r.imaging.Cfg.Renditions[strings.ToLower(name)]
+	return renditions, found
+}
+
 func (r *Spec) ClearCaches() {
 	r.imageCache.clear()
 	r.ResourceCache.clear()
@@ -268,11 +324,15 @@ func (r *Spec) newResource(sourceFs afero.Fs, fd ResourceSourceDescriptor) (reso
 	}
 
 	ext := strings.ToLower(filepath.Ext(fd.RelTargetFilename))
-	mimeType, suffixInfo, found := r.MediaTypes.GetFirstBySuffix(strings.TrimPrefix(ext, "."))
-	// TODO(bep) we need to handle these ambiguous types better, but in this context
-	// we most likely want the application/xml type.
-	if suffixInfo.Suffix == "xml" && mimeType.SubType == "rss" {
-		mimeType, found = r.MediaTypes.GetByType("application/xml")
+	mimeType, found := fd.MediaType, !fd.MediaType.IsZero()
+	if !found {
+		var suffixInfo media.SuffixInfo
+		mimeType, suffixInfo, found = r.MediaTypes.GetFirstBySuffix(strings.TrimPrefix(ext, "."))
+		// TODO(bep) we need to handle these ambiguous types better, but in this context
+		// we most likely want the application/xml type.
+		if suffixInfo.Suffix == "xml" && mimeType.SubType == "rss" {
+			mimeType, found = r.MediaTypes.GetByType("application/xml")
+		}
 	}
 
 	if !found {
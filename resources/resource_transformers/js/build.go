@@ -63,6 +63,9 @@ func (t *buildTransformation) Key() internal.ResourceTransformationKey {
 }
 
 func (t *buildTransformation) Transform(ctx *resources.ResourceTransformationCtx) error {
+	t.c.rs.Workers.JSBuild.Acquire()
+	defer t.c.rs.Workers.JSBuild.Release()
+
 	ctx.OutMediaType = media.JavascriptType
 
 	opts, err := decodeOptions(t.optsm)
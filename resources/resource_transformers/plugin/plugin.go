@@ -0,0 +1,78 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"io/ioutil"
+
+	"github.com/gohugoio/hugo/plugins"
+	"github.com/gohugoio/hugo/resources"
+	"github.com/gohugoio/hugo/resources/internal"
+	"github.com/gohugoio/hugo/resources/resource"
+	"github.com/pkg/errors"
+)
+
+// Client transforms Resource objects with WASM plugins.
+type Client struct {
+	rs *resources.Spec
+}
+
+// New creates a new Client with the given specification.
+func New(rs *resources.Spec) *Client {
+	return &Client{rs: rs}
+}
+
+type pluginTransformation struct {
+	plugin  *plugins.Plugin
+	options map[string]interface{}
+}
+
+func (t *pluginTransformation) Key() internal.ResourceTransformationKey {
+	return internal.NewResourceTransformationKey("plugin", t.plugin.Name(), t.options)
+}
+
+func (t *pluginTransformation) Transform(ctx *resources.ResourceTransformationCtx) error {
+	b, err := ioutil.ReadAll(ctx.From)
+	if err != nil {
+		return err
+	}
+
+	b, err = t.plugin.Transform(plugins.Context{
+		Kind:      plugins.KindResource,
+		Path:      ctx.InPath,
+		MediaType: ctx.InMediaType.Type(),
+		Params:    t.options,
+	}, b)
+	if err != nil {
+		return err
+	}
+
+	_, err = ctx.To.Write(b)
+	return err
+}
+
+// Transform transforms the given Resource with the named plugin. The options,
+// if set, are passed to the plugin as params instead of the params in its
+// config.
+func (c *Client) Transform(name string, res resources.ResourceTransformer, options map[string]interface{}) (resource.Resource, error) {
+	p := c.rs.Plugins.Get(name)
+	if p == nil {
+		return nil, errors.Errorf("plugin %q not found", name)
+	}
+	if !p.Has(plugins.KindResource) {
+		return nil, errors.Errorf("plugin %q does not implement resource transformations", name)
+	}
+
+	return res.Transform(&pluginTransformation{plugin: p, options: options})
+}
@@ -16,6 +16,7 @@ package templates
 
 import (
 	"github.com/gohugoio/hugo/helpers"
+	"github.com/gohugoio/hugo/media"
 	"github.com/gohugoio/hugo/resources"
 	"github.com/gohugoio/hugo/resources/internal"
 	"github.com/gohugoio/hugo/resources/resource"
@@ -44,10 +45,14 @@ type executeAsTemplateTransform struct {
 	rs         *resources.Spec
 	t          tpl.TemplatesProvider
 	targetPath string
+	mediaType  media.Type
 	data       interface{}
 }
 
 func (t *executeAsTemplateTransform) Key() internal.ResourceTransformationKey {
+	if !t.mediaType.IsZero() {
+		return internal.NewResourceTransformationKey("execute-as-template", t.targetPath, t.mediaType.Type())
+	}
 	return internal.NewResourceTransformationKey("execute-as-template", t.targetPath)
 }
 
@@ -59,14 +64,21 @@ func (t *executeAsTemplateTransform) Transform(ctx *resources.ResourceTransforma
 	}
 
 	ctx.OutPath = t.targetPath
+	if !t.mediaType.IsZero() {
+		ctx.OutMediaType = t.mediaType
+	}
 
 	return t.t.Tmpl().Execute(templ, ctx.To, t.data)
 }
 
-func (c *Client) ExecuteAsTemplate(res resources.ResourceTransformer, targetPath string, data interface{}) (resource.Resource, error) {
+// ExecuteAsTemplate creates a Resource from res executed as a Go template with
+// the given data. If mediaType is zero, the media type of res is kept.
+// The template is executed when the resource is first accessed.
+func (c *Client) ExecuteAsTemplate(res resources.ResourceTransformer, targetPath string, data interface{}, mediaType media.Type) (resource.Resource, error) {
 	return res.Transform(&executeAsTemplateTransform{
 		rs:         c.rs,
 		targetPath: helpers.ToSlashTrimLeading(targetPath),
+		mediaType:  mediaType,
 		t:          c.t,
 		data:       data,
 	})
@@ -60,6 +60,9 @@ func (t *transform) Key() internal.ResourceTransformationKey {
 }
 
 func (t *transform) Transform(ctx *resources.ResourceTransformationCtx) error {
+	t.c.rs.Workers.Sass.Acquire()
+	defer t.c.rs.Workers.Sass.Release()
+
 	ctx.OutMediaType = media.CSSType
 
 	opts, err := decodeOptions(t.optsm)
@@ -36,6 +36,9 @@ func Supports() bool {
 }
 
 func (t *toCSSTransformation) Transform(ctx *resources.ResourceTransformationCtx) error {
+	t.c.rs.Workers.Sass.Acquire()
+	defer t.c.rs.Workers.Sass.Release()
+
 	ctx.OutMediaType = media.CSSType
 
 	var outName string
@@ -192,6 +192,10 @@ func (r *resourceAdapter) Exif() *exif.Exif {
 	return r.getImageOps().Exif()
 }
 
+func (r *resourceAdapter) Placeholder(kind string) (string, error) {
+	return r.getImageOps().Placeholder(kind)
+}
+
 func (r *resourceAdapter) Key() string {
 	r.init(false, false)
 	return r.target.(resource.Identifier).Key()
@@ -218,6 +222,7 @@ func (r *resourceAdapter) Permalink() string {
 }
 
 func (r *resourceAdapter) Publish() error {
+	r.spec.incrPublishRequests()
 	r.init(false, false)
 
 	return r.target.Publish()
@@ -422,10 +427,10 @@ func (r *resourceAdapter) transform(publish, setContent bool) error {
 					errMsg = ". You need to install Babel, see https://gohugo.io/hugo-pipes/babel/"
 				}
 
-				return errors.New(msg + errMsg)
+				return herrors.WithCode(herrors.CodeResourceTransform, errors.New(msg+errMsg))
 			}
 
-			return errors.Wrap(err, msg)
+			return herrors.WithCode(herrors.CodeResourceTransform, errors.Wrap(err, msg))
 		}
 
 		var tryFileCache bool
@@ -433,7 +438,12 @@ func (r *resourceAdapter) transform(publish, setContent bool) error {
 		if mayBeCachedOnDisk && r.spec.BuildConfig.UseResourceCache(nil) {
 			tryFileCache = true
 		} else {
+			span := r.spec.Tracer.Start("transform: "+tr.Key().Name, "hugo.resource", tctx.InPath)
 			err = tr.Transform(tctx)
+			if err != herrors.ErrFeatureNotAvailable {
+				span.SetError(err)
+			}
+			span.End()
 			if err != nil && err != herrors.ErrFeatureNotAvailable {
 				return newErr(err)
 			}
@@ -537,6 +547,9 @@ func (r *resourceAdapter) transform(publish, setContent bool) error {
 }
 
 func (r *resourceAdapter) init(publish, setContent bool) {
+	if publish {
+		r.spec.incrPublishRequests()
+	}
 	r.initTransform(publish, setContent)
 }
 
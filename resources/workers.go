@@ -0,0 +1,84 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resources
+
+import (
+	"runtime"
+
+	"github.com/gohugoio/hugo/config"
+)
+
+// Workers holds the worker pools for the heavy resource transformations.
+// These are shared by all sites.
+type Workers struct {
+	Images  *WorkerPool
+	Sass    *WorkerPool
+	JSBuild *WorkerPool
+
+	// Whether we're running in low priority mode, see config.Workers.
+	LowPriority bool
+}
+
+func newWorkers(cfg config.Workers, running bool) *Workers {
+	lowPriority := cfg.LowPriority && running
+
+	newPool := func(size int) *WorkerPool {
+		if lowPriority {
+			maxSize := runtime.NumCPU() / 2
+			if maxSize < 1 {
+				maxSize = 1
+			}
+			if size <= 0 || size > maxSize {
+				size = maxSize
+			}
+		}
+		return NewWorkerPool(size)
+	}
+
+	return &Workers{
+		Images:      newPool(cfg.Images),
+		Sass:        newPool(cfg.Sass),
+		JSBuild:     newPool(cfg.JSBuild),
+		LowPriority: lowPriority,
+	}
+}
+
+// WorkerPool limits the number of concurrent operations.
+type WorkerPool struct {
+	sem chan bool
+}
+
+// NewWorkerPool creates a new pool with the given number of workers.
+// A size of 0 or less means no limit.
+func NewWorkerPool(size int) *WorkerPool {
+	if size <= 0 {
+		return &WorkerPool{}
+	}
+	return &WorkerPool{sem: make(chan bool, size)}
+}
+
+// Acquire blocks until a worker is available. It must be paired with a call
+// to Release.
+func (p *WorkerPool) Acquire() {
+	if p.sem != nil {
+		p.sem <- true
+	}
+}
+
+// Release releases a worker acquired with Acquire.
+func (p *WorkerPool) Release() {
+	if p.sem != nil {
+		<-p.sem
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resources
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gohugoio/hugo/config"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestWorkers(t *testing.T) {
+	c := qt.New(t)
+
+	cfg := config.Workers{Images: 1, Sass: 0, JSBuild: 1000}
+
+	w := newWorkers(cfg, false)
+	c.Assert(w.LowPriority, qt.Equals, false)
+	c.Assert(cap(w.Images.sem), qt.Equals, 1)
+	c.Assert(w.Sass.sem, qt.IsNil)
+	c.Assert(cap(w.JSBuild.sem), qt.Equals, 1000)
+
+	// Low priority only applies when running the server.
+	cfg.LowPriority = true
+	c.Assert(newWorkers(cfg, false).LowPriority, qt.Equals, false)
+
+	maxSize := runtime.NumCPU() / 2
+	if maxSize < 1 {
+		maxSize = 1
+	}
+	w = newWorkers(cfg, true)
+	c.Assert(w.LowPriority, qt.Equals, true)
+	c.Assert(cap(w.Images.sem), qt.Equals, 1)
+	c.Assert(cap(w.Sass.sem), qt.Equals, maxSize)
+	c.Assert(cap(w.JSBuild.sem), qt.Equals, maxSize)
+}
+
+func TestWorkerPool(t *testing.T) {
+	c := qt.New(t)
+
+	pool := NewWorkerPool(2)
+
+	var active, maxActive int32
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			pool.Acquire()
+			defer pool.Release()
+			n := atomic.AddInt32(&active, 1)
+			for {
+				m := atomic.LoadInt32(&maxActive)
+				if n <= m || atomic.CompareAndSwapInt32(&maxActive, m, n) {
+					break
+				}
+			}
+			runtime.Gosched()
+			atomic.AddInt32(&active, -1)
+		}()
+	}
+	wg.Wait()
+
+	c.Assert(maxActive <= 2, qt.IsTrue)
+
+	// No limit.
+	pool = NewWorkerPool(0)
+	for i := 0; i < 10; i++ {
+		pool.Acquire()
+	}
+}
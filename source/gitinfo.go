@@ -0,0 +1,215 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"bytes"
+	"net/mail"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gohugoio/hugo/common/hexec"
+	"github.com/pkg/errors"
+)
+
+// ErrGitNotFound is returned when the Git executable cannot be found.
+var ErrGitNotFound = errors.New("Git executable not found in $PATH")
+
+// GitInfo holds the Git commits for a file. The last commit is embedded,
+// so .GitInfo.Hash etc. refers to the last commit.
+type GitInfo struct {
+	GitCommit
+
+	// All the commits touching the file, newest first.
+	History []*GitCommit `json:"history"`
+}
+
+// FirstCommit returns the commit adding the file.
+func (g *GitInfo) FirstCommit() *GitCommit {
+	if g == nil || len(g.History) == 0 {
+		return nil
+	}
+	return g.History[len(g.History)-1]
+}
+
+// GitCommit holds information about a Git commit.
+type GitCommit struct {
+	Hash            string    `json:"hash"`            // Commit hash
+	AbbreviatedHash string    `json:"abbreviatedHash"` // Abbreviated commit hash
+	Subject         string    `json:"subject"`         // The commit message's subject/title line
+	AuthorName      string    `json:"authorName"`      // The author name, respecting .mailmap
+	AuthorEmail     string    `json:"authorEmail"`     // The author email address, respecting .mailmap
+	AuthorDate      time.Time `json:"authorDate"`      // The author date
+	CommitDate      time.Time `json:"commitDate"`      // The commit date
+
+	// The authors listed in the commit message's Co-authored-by trailers.
+	CoAuthors []GitAuthor `json:"coAuthors"`
+}
+
+// GitAuthor is a commit author.
+type GitAuthor struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// GitRepo holds the Git information for the files in a Git repository.
+type GitRepo struct {
+	// The absolute path of the top-level directory, using forward slashes.
+	TopLevelAbsPath string
+
+	// The files in this repository, relative to TopLevelAbsPath.
+	Files map[string]*GitInfo
+}
+
+const (
+	gitRecordSep  = "\x1e"
+	gitFieldSep   = "\x1f"
+	gitMessageEnd = "\x1d"
+)
+
+// MapGitRepo reads the history of all the files in the Git repository
+// containing dir with one git log command.
+func MapGitRepo(dir string) (*GitRepo, error) {
+	absPath, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := git("-C", dir, "rev-parse", "--show-cdup")
+	if err != nil {
+		return nil, err
+	}
+	topLevelPath := filepath.ToSlash(filepath.Join(absPath, strings.TrimSpace(string(out))))
+
+	out, err = git(
+		"-c", "diff.renames=0", "-c", "log.showSignature=0", "-C", dir,
+		"log", "--name-only", "--no-merges",
+		"--format=format:%x1e%H%x1f%h%x1f%s%x1f%aN%x1f%aE%x1f%ai%x1f%ci%x1f%b%x1d",
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := parseGitLog(string(out))
+	if err != nil {
+		return nil, err
+	}
+
+	return &GitRepo{TopLevelAbsPath: topLevelPath, Files: files}, nil
+}
+
+// parseGitLog parses the output of the git log command in MapGitRepo.
+// The commits are listed newest first.
+func parseGitLog(log string) (map[string]*GitInfo, error) {
+	files := make(map[string]*GitInfo)
+
+	for _, record := range strings.Split(log, gitRecordSep) {
+		if strings.TrimSpace(record) == "" {
+			continue
+		}
+
+		i := strings.Index(record, gitMessageEnd)
+		if i == -1 {
+			return nil, errors.Errorf("invalid git log entry %q", record)
+		}
+
+		commit, err := parseGitCommit(record[:i])
+		if err != nil {
+			return nil, err
+		}
+
+		for _, filename := range strings.Split(record[i+len(gitMessageEnd):], "\n") {
+			filename = strings.TrimSpace(filename)
+			if filename == "" {
+				continue
+			}
+			gi, found := files[filename]
+			if !found {
+				gi = &GitInfo{GitCommit: *commit}
+				files[filename] = gi
+			}
+			gi.History = append(gi.History, commit)
+		}
+	}
+
+	return files, nil
+}
+
+func parseGitCommit(s string) (*GitCommit, error) {
+	fields := strings.Split(s, gitFieldSep)
+	if len(fields) != 8 {
+		return nil, errors.Errorf("invalid git log entry %q", s)
+	}
+
+	authorDate, err := time.Parse("2006-01-02 15:04:05 -0700", fields[5])
+	if err != nil {
+		return nil, err
+	}
+	commitDate, err := time.Parse("2006-01-02 15:04:05 -0700", fields[6])
+	if err != nil {
+		return nil, err
+	}
+
+	return &GitCommit{
+		Hash:            fields[0],
+		AbbreviatedHash: fields[1],
+		Subject:         fields[2],
+		AuthorName:      fields[3],
+		AuthorEmail:     fields[4],
+		AuthorDate:      authorDate,
+		CommitDate:      commitDate,
+		CoAuthors:       parseCoAuthors(fields[7]),
+	}, nil
+}
+
+// parseCoAuthors returns the authors in the Co-authored-by trailers in
+// the commit message body.
+func parseCoAuthors(body string) []GitAuthor {
+	const key = "co-authored-by:"
+
+	var authors []GitAuthor
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) <= len(key) || !strings.EqualFold(line[:len(key)], key) {
+			continue
+		}
+		value := strings.TrimSpace(line[len(key):])
+		if addr, err := mail.ParseAddress(value); err == nil {
+			authors = append(authors, GitAuthor{Name: addr.Name, Email: addr.Address})
+		} else {
+			authors = append(authors, GitAuthor{Name: value})
+		}
+	}
+
+	return authors
+}
+
+func git(args ...string) ([]byte, error) {
+	cmd, err := hexec.SafeCommand("git", args...)
+	if err != nil {
+		if ee, ok := err.(*exec.Error); ok && ee.Err == exec.ErrNotFound {
+			return nil, ErrGitNotFound
+		}
+		return nil, err
+	}
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, errors.New(string(bytes.TrimSpace(out)))
+	}
+
+	return out, nil
+}
@@ -0,0 +1,65 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestParseGitLog(t *testing.T) {
+	c := qt.New(t)
+
+	entry := func(hash, subject, author, date, body string, files ...string) string {
+		fields := []string{hash, hash[:3], subject, author, strings.ToLower(author) + "@example.com", date, date, body}
+		return gitRecordSep + strings.Join(fields, gitFieldSep) + gitMessageEnd + "\n" + strings.Join(files, "\n") + "\n"
+	}
+
+	log := entry("ccc123", "Third", "Carol", "2021-03-01 10:00:00 +0100", "Fix typo\n\nCo-authored-by: Dave <dave@example.com>\nco-authored-by: Eve\n", "content/a.md") +
+		entry("bbb123", "Second", "Bob", "2021-02-01 10:00:00 +0100", "", "content/a.md", "content/b.md") +
+		entry("aaa123", "First", "Alice", "2021-01-01 10:00:00 +0100", "", "content/a.md")
+
+	files, err := parseGitLog(log)
+	c.Assert(err, qt.IsNil)
+	c.Assert(files, qt.HasLen, 2)
+
+	a := files["content/a.md"]
+	c.Assert(a.Hash, qt.Equals, "ccc123")
+	c.Assert(a.AbbreviatedHash, qt.Equals, "ccc")
+	c.Assert(a.Subject, qt.Equals, "Third")
+	c.Assert(a.AuthorName, qt.Equals, "Carol")
+	c.Assert(a.AuthorEmail, qt.Equals, "carol@example.com")
+	c.Assert(a.AuthorDate.Year(), qt.Equals, 2021)
+	c.Assert(a.CoAuthors, qt.DeepEquals, []GitAuthor{{Name: "Dave", Email: "dave@example.com"}, {Name: "Eve"}})
+	c.Assert(a.History, qt.HasLen, 3)
+	c.Assert(a.History[1].Hash, qt.Equals, "bbb123")
+	c.Assert(a.FirstCommit().Hash, qt.Equals, "aaa123")
+
+	b := files["content/b.md"]
+	c.Assert(b.Hash, qt.Equals, "bbb123")
+	c.Assert(b.CoAuthors, qt.IsNil)
+	c.Assert(b.History, qt.HasLen, 1)
+	c.Assert(b.FirstCommit(), qt.Equals, b.History[0])
+
+	// The commits are shared between the files.
+	c.Assert(a.History[1], qt.Equals, b.History[0])
+
+	_, err = parseGitLog(gitRecordSep + "invalid")
+	c.Assert(err, qt.Not(qt.IsNil))
+
+	var nilInfo *GitInfo
+	c.Assert(nilInfo.FirstCommit(), qt.IsNil)
+}
@@ -19,6 +19,7 @@ import (
 	"regexp"
 	"runtime"
 
+	"github.com/gohugoio/hugo/hugofs"
 	"github.com/gohugoio/hugo/langs"
 	"github.com/spf13/afero"
 
@@ -79,7 +80,7 @@ func NewSourceSpec(ps *helpers.PathSpec, fs afero.Fs) *SourceSpec {
 // IgnoreFile returns whether a given file should be ignored.
 func (s *SourceSpec) IgnoreFile(filename string) bool {
 	if filename == "" {
-		if _, ok := s.SourceFs.(*afero.OsFs); ok {
+		if hugofs.IsOsFs(s.SourceFs) {
 			return true
 		}
 		return false
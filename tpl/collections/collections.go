@@ -560,7 +560,8 @@ func (ns *Namespace) Seq(args ...interface{}) ([]int, error) {
 	return seq, nil
 }
 
-// Shuffle returns the given rangeable list in a randomised order.
+// Shuffle returns the given rangeable list in a randomised order. The order is
+// the same in every reproducible build.
 func (ns *Namespace) Shuffle(seq interface{}) (interface{}, error) {
 	if seq == nil {
 		return nil, errors.New("both count and seq must be provided")
@@ -581,7 +582,13 @@ func (ns *Namespace) Shuffle(seq interface{}) (interface{}, error) {
 
 	shuffled := reflect.MakeSlice(reflect.TypeOf(seq), seqv.Len(), seqv.Len())
 
-	randomIndices := rand.Perm(seqv.Len())
+	var randomIndices []int
+	if ns.deps != nil && ns.deps.Reproducible {
+		// Shuffle the same way in every build.
+		randomIndices = rand.New(rand.NewSource(ns.deps.Clock.Now().Unix())).Perm(seqv.Len())
+	} else {
+		randomIndices = rand.Perm(seqv.Len())
+	}
 
 	for index, value := range randomIndices {
 		shuffled.Index(value).Set(seqv.Index(index))
@@ -22,6 +22,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/gohugoio/hugo/common/htime"
 	"github.com/gohugoio/hugo/common/maps"
 
 	qt "github.com/frankban/quicktest"
@@ -729,6 +730,23 @@ func TestShuffleRandomising(t *testing.T) {
 	}
 }
 
+func TestShuffleReproducible(t *testing.T) {
+	t.Parallel()
+	c := qt.New(t)
+	d := &deps.Deps{
+		Clock:        htime.FixedClock(time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)),
+		Reproducible: true,
+	}
+
+	seq := rand.Perm(100)
+	result1, err := New(d).Shuffle(seq)
+	c.Assert(err, qt.IsNil)
+	result2, err := New(d).Shuffle(seq)
+	c.Assert(err, qt.IsNil)
+	c.Assert(result1, qt.DeepEquals, result2)
+	c.Assert(result1, qt.Not(qt.DeepEquals), seq)
+}
+
 // Also see tests in commons/collection.
 func TestSlice(t *testing.T) {
 	t.Parallel()
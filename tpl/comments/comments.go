@@ -0,0 +1,124 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package comments provides template functions for working with comments
+// fetched from external sources at build time.
+package comments
+
+import (
+	"sort"
+
+	"github.com/gohugoio/hugo/comments"
+	"github.com/gohugoio/hugo/common/constants"
+	"github.com/gohugoio/hugo/common/loggers"
+	"github.com/gohugoio/hugo/common/maps"
+	"github.com/gohugoio/hugo/config/security"
+	"github.com/gohugoio/hugo/deps"
+	"github.com/pkg/errors"
+	"github.com/spf13/cast"
+)
+
+// The front matter key holding a map of source names to ids.
+const frontMatterKey = "comments"
+
+// New returns a new instance of the comments-namespaced template functions.
+func New(d *deps.Deps) *Namespace {
+	sc, ok := d.Cfg.Get("securityConfig").(security.Config)
+	if !ok {
+		sc = security.DefaultConfig
+	}
+	cc, ok := d.Cfg.Get("commentsConfig").(comments.Config)
+	if !ok {
+		cc = comments.DefaultConfig
+	}
+
+	return &Namespace{
+		deps:   d,
+		client: comments.New(cc, d.FileCaches.CommentsCache(), sc),
+	}
+}
+
+// Namespace provides template functions for the "comments" namespace.
+type Namespace struct {
+	deps   *deps.Deps
+	client *comments.Client
+}
+
+type paramsProvider interface {
+	Params() maps.Params
+}
+
+// Get returns the comments for a page, given either the page, which must have
+// a map of source names to ids in its "comments" front matter, or a source
+// name and an id. Comments from multiple sources are sorted by date.
+func (ns *Namespace) Get(args ...interface{}) ([]comments.Comment, error) {
+	switch len(args) {
+	case 1:
+		p, ok := args[0].(paramsProvider)
+		if !ok {
+			return nil, errors.Errorf("comments.Get: expected a page, got %T", args[0])
+		}
+		ids, err := maps.ToStringMapE(p.Params()[frontMatterKey])
+		if err != nil {
+			// Not set, or used for something else, e.g. comments = false.
+			return nil, nil
+		}
+
+		var all []comments.Comment
+		for name, id := range ids {
+			if !ns.client.HasSource(name) {
+				continue
+			}
+			cs, err := ns.get(name, cast.ToString(id))
+			if err != nil {
+				return nil, err
+			}
+			all = append(all, cs...)
+		}
+
+		sort.SliceStable(all, func(i, j int) bool {
+			return all[i].Date.Before(all[j].Date)
+		})
+
+		return all, nil
+	case 2:
+		name, err := cast.ToStringE(args[0])
+		if err != nil {
+			return nil, err
+		}
+		id, err := cast.ToStringE(args[1])
+		if err != nil {
+			return nil, err
+		}
+		return ns.get(name, id)
+	default:
+		return nil, errors.New("comments.Get: expected a page or a source name and an id")
+	}
+}
+
+func (ns *Namespace) get(name, id string) ([]comments.Comment, error) {
+	if !ns.client.HasSource(name) {
+		return nil, errors.Errorf("comments.Get: source %q not found in the comments config", name)
+	}
+
+	cs, err := ns.client.Get(name, id)
+	if security.IsAccessDenied(err) {
+		return nil, err
+	}
+	if err != nil {
+		ns.deps.Log.(loggers.IgnorableLogger).Errorsf(constants.ErrRemoteGetComments, "%s", err)
+		return nil, nil
+	}
+
+	return cs, nil
+}
@@ -0,0 +1,252 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package components provides template functions for working with
+// components, partials with declared props and slots.
+package components
+
+import (
+	"fmt"
+	"html/template"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gohugoio/hugo/bufferpool"
+	"github.com/gohugoio/hugo/common/maps"
+	"github.com/gohugoio/hugo/deps"
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/gohugoio/hugo/hugofs"
+	"github.com/gohugoio/hugo/media"
+	"github.com/gohugoio/hugo/resources/resource"
+	"github.com/gohugoio/hugo/resources/resource_factories/create"
+	"github.com/gohugoio/hugo/tpl"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"github.com/spf13/cast"
+)
+
+const (
+	// The components live in layouts/partials/components.
+	componentsPathPrefix = "partials/components/"
+
+	// The component CSS lives in assets/components.
+	componentsAssetsDir = "components"
+
+	// The default slot.
+	defaultSlot = "default"
+)
+
+// New returns a new instance of the components-namespaced template functions.
+func New(deps *deps.Deps) *Namespace {
+	ns := &Namespace{
+		deps: deps,
+	}
+	if deps.ResourceSpec != nil {
+		ns.createClient = create.New(deps.ResourceSpec)
+	}
+	return ns
+}
+
+// Namespace provides template functions for the "components" namespace.
+type Namespace struct {
+	deps         *deps.Deps
+	createClient *create.Client
+}
+
+// Context is the context passed to a component template.
+type Context struct {
+	// The component name, e.g. "card".
+	Name string
+
+	// The props passed to the component, with the defaults applied.
+	Props map[string]interface{}
+
+	// The content for the default slot.
+	Children template.HTML
+
+	// The content for the named slots.
+	Slots map[string]template.HTML
+
+	// The CSS class that scopes the component's CSS, e.g. "c-card".
+	Scope string
+}
+
+// Slot returns the content of the named slot, empty if not set.
+func (c Context) Slot(name string) template.HTML {
+	if name == defaultSlot {
+		return c.Children
+	}
+	return c.Slots[name]
+}
+
+// Render renders the component with the given name and props. The optional
+// children are either the content of the default slot or a map with the
+// content of the named slots, where "default" is the default slot.
+func (ns *Namespace) Render(name string, props interface{}, children ...interface{}) (template.HTML, error) {
+	templ, found := ns.lookup(name)
+	if !found {
+		return "", errors.Errorf("component %q not found", name)
+	}
+
+	ctx, err := ns.newContext(name, templ, props, children...)
+	if err != nil {
+		return "", errors.Wrapf(err, "component %q", name)
+	}
+
+	b := bufferpool.GetBuffer()
+	defer bufferpool.PutBuffer(b)
+
+	if err := ns.deps.Tmpl().Execute(templ, b, ctx); err != nil {
+		return "", err
+	}
+
+	return template.HTML(b.String()), nil
+}
+
+func (ns *Namespace) lookup(name string) (tpl.Template, bool) {
+	n := componentsPathPrefix + name
+	if templ, found := ns.deps.Tmpl().Lookup(n); found {
+		return templ, true
+	}
+	return ns.deps.Tmpl().Lookup(n + ".html")
+}
+
+func (ns *Namespace) newContext(name string, templ tpl.Template, props interface{}, children ...interface{}) (Context, error) {
+	ctx := Context{
+		Name:  name,
+		Scope: scopeClass(name),
+	}
+
+	var config tpl.ParseConfig
+	if info, ok := templ.(tpl.Info); ok {
+		config = info.ParseInfo().Config
+	}
+
+	if props != nil {
+		m, err := maps.ToStringMapE(props)
+		if err != nil {
+			return ctx, errors.Errorf("props must be a map, got %T", props)
+		}
+		ctx.Props = m
+	}
+
+	if len(config.Params) > 0 {
+		var err error
+		if ctx.Props, err = tpl.CheckParams(config.Params, ctx.Props, "prop"); err != nil {
+			return ctx, err
+		}
+	}
+
+	if ctx.Props == nil {
+		ctx.Props = make(map[string]interface{})
+	}
+
+	if len(children) > 1 {
+		return ctx, errors.New("too many arguments")
+	}
+
+	if len(children) == 0 || children[0] == nil {
+		return ctx, nil
+	}
+
+	if slots, err := maps.ToStringMapE(children[0]); err == nil {
+		ctx.Slots = make(map[string]template.HTML)
+		for k, v := range slots {
+			if len(config.Slots) > 0 && k != defaultSlot && !isDeclaredSlot(config.Slots, k) {
+				return ctx, errors.Errorf("unknown slot %q", k)
+			}
+			s, err := cast.ToStringE(v)
+			if err != nil {
+				return ctx, errors.Wrapf(err, "slot %q", k)
+			}
+			if k == defaultSlot {
+				ctx.Children = template.HTML(s)
+			} else {
+				ctx.Slots[k] = template.HTML(s)
+			}
+		}
+		return ctx, nil
+	}
+
+	s, err := cast.ToStringE(children[0])
+	if err != nil {
+		return ctx, errors.Wrap(err, "children")
+	}
+	ctx.Children = template.HTML(s)
+
+	return ctx, nil
+}
+
+func isDeclaredSlot(slots []string, name string) bool {
+	for _, s := range slots {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Scope returns the CSS class that scopes the CSS of the named component.
+func (ns *Namespace) Scope(name string) string {
+	return scopeClass(name)
+}
+
+// CSS returns the CSS of all components in assets/components bundled into
+// one resource, nil if there is none. The rules in the CSS for a component
+// are scoped to the elements with the component's scope class.
+func (ns *Namespace) CSS() (resource.Resource, error) {
+	if ns.createClient == nil {
+		return nil, nil
+	}
+
+	fs := ns.deps.BaseFs.Assets.Fs
+
+	var files []string
+	walker := func(path string, fi hugofs.FileMetaInfo, err error) error {
+		if err != nil || fi.IsDir() {
+			return err
+		}
+		if filepath.Ext(path) == ".css" {
+			files = append(files, path)
+		}
+		return nil
+	}
+
+	if err := helpers.SymbolicWalk(fs, componentsAssetsDir, walker); err != nil {
+		return nil, err
+	}
+
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	sort.Strings(files)
+
+	var sb strings.Builder
+	for _, filename := range files {
+		b, err := afero.ReadFile(fs, filename)
+		if err != nil {
+			return nil, err
+		}
+		name := strings.TrimPrefix(filepath.ToSlash(filename), "/")
+		name = strings.TrimPrefix(name, componentsAssetsDir+"/")
+		name = strings.TrimSuffix(name, path.Ext(name))
+		fmt.Fprintf(&sb, "/* %s */\n", name)
+		sb.WriteString(scopeCSS(string(b), scopeClass(name)))
+		sb.WriteString("\n")
+	}
+
+	return ns.createClient.FromString("components.css", sb.String(), media.CSSType)
+}
@@ -0,0 +1,249 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package components
+
+import (
+	"fmt"
+	"strings"
+)
+
+// scopeClass returns the scope class for name, e.g. "c-card" for "card" and
+// "c-nav__item" for "nav/item". Different names always get different
+// classes: "/" is written as "__", "_" as "_-" and any other character not
+// allowed in a class name as "_" followed by its hex code.
+func scopeClass(name string) string {
+	name = strings.Trim(strings.TrimSuffix(name, ".html"), "/")
+
+	var sb strings.Builder
+	sb.WriteString("c-")
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case c == '/':
+			sb.WriteString("__")
+		case c == '_':
+			sb.WriteString("_-")
+		case c == '-' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9':
+			sb.WriteByte(c)
+		default:
+			fmt.Fprintf(&sb, "_%02x", c)
+		}
+	}
+	return sb.String()
+}
+
+// scopeCSS scopes all the style rules in css to the elements with class:
+// ":scope" in a selector is replaced with the class, all other selectors
+// are prefixed with it, e.g. "h2, :scope > p" becomes
+// ".c-card h2, .c-card > p". The rules in conditional group rules, e.g.
+// @media and @supports, are scoped, other at-rules, e.g. @keyframes and
+// @font-face, are left alone.
+func scopeCSS(css, class string) string {
+	var sb strings.Builder
+	scopeRules(&sb, css, "."+class)
+	return sb.String()
+}
+
+// groupRules are the at-rules with style rules in their block.
+var groupRules = map[string]bool{
+	"@media":     true,
+	"@supports":  true,
+	"@container": true,
+	"@layer":     true,
+	"@document":  true,
+}
+
+func scopeRules(sb *strings.Builder, css, selector string) {
+	for len(css) > 0 {
+		// Copy the whitespace and comments between the rules.
+		i := skipSpaceAndComments(css)
+		sb.WriteString(css[:i])
+		css = css[i:]
+		if css == "" {
+			return
+		}
+
+		// The prelude ends at the start of the block, or at a ";" for
+		// at-rules without one, e.g. @import.
+		end := indexOutsideNesting(css, "{;")
+		if end == -1 {
+			sb.WriteString(css)
+			return
+		}
+		prelude := css[:end]
+		if css[end] == ';' {
+			sb.WriteString(css[:end+1])
+			css = css[end+1:]
+			continue
+		}
+
+		blockEnd := matchingBrace(css, end)
+		block := css[end+1 : blockEnd]
+
+		if strings.HasPrefix(prelude, "@") {
+			name := strings.ToLower(prelude)
+			if i := strings.IndexAny(name, " \t\n\r\f("); i != -1 {
+				name = name[:i]
+			}
+			sb.WriteString(prelude)
+			sb.WriteByte('{')
+			if groupRules[name] {
+				scopeRules(sb, block, selector)
+			} else {
+				sb.WriteString(block)
+			}
+		} else {
+			sb.WriteString(scopeSelectors(prelude, selector))
+			sb.WriteString(" {")
+			sb.WriteString(block)
+		}
+
+		if blockEnd < len(css) {
+			sb.WriteByte('}')
+			css = css[blockEnd+1:]
+		} else {
+			css = ""
+		}
+	}
+}
+
+// scopeSelectors scopes the comma separated selectors in prelude.
+func scopeSelectors(prelude, selector string) string {
+	prelude = stripComments(prelude)
+	var selectors []string
+	for {
+		i := indexOutsideNesting(prelude, ",")
+		if i == -1 {
+			selectors = append(selectors, scopeSelector(prelude, selector))
+			break
+		}
+		selectors = append(selectors, scopeSelector(prelude[:i], selector))
+		prelude = prelude[i+1:]
+	}
+	return strings.Join(selectors, ", ")
+}
+
+func scopeSelector(s, selector string) string {
+	s = strings.TrimSpace(s)
+	if strings.Contains(s, ":scope") {
+		return strings.ReplaceAll(s, ":scope", selector)
+	}
+	return selector + " " + s
+}
+
+// skipSpaceAndComments returns the index of the first character in s that
+// isn't whitespace or in a comment.
+func skipSpaceAndComments(s string) int {
+	i := 0
+	for i < len(s) {
+		switch {
+		case s[i] == ' ' || s[i] == '\t' || s[i] == '\n' || s[i] == '\r' || s[i] == '\f':
+			i++
+		case strings.HasPrefix(s[i:], "/*"):
+			end := strings.Index(s[i+2:], "*/")
+			if end == -1 {
+				return len(s)
+			}
+			i += end + 4
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// indexOutsideNesting returns the index of the first of chars in s that is
+// not in a string, a comment, or in parentheses or brackets, -1 if not found.
+func indexOutsideNesting(s, chars string) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '"' || c == '\'':
+			i = skipString(s, i)
+		case c == '/' && strings.HasPrefix(s[i:], "/*"):
+			end := strings.Index(s[i+2:], "*/")
+			if end == -1 {
+				return -1
+			}
+			i += end + 3
+		case c == '\\':
+			i++
+		case c == '(' || c == '[':
+			depth++
+		case c == ')' || c == ']':
+			depth--
+		case depth <= 0 && strings.IndexByte(chars, c) != -1:
+			return i
+		}
+	}
+	return -1
+}
+
+// matchingBrace returns the index of the "}" closing the "{" at start in s,
+// len(s) if it's not closed.
+func matchingBrace(s string, start int) int {
+	depth := 0
+	for i := start; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' || c == '\'':
+			i = skipString(s, i)
+		case c == '/' && strings.HasPrefix(s[i:], "/*"):
+			end := strings.Index(s[i+2:], "*/")
+			if end == -1 {
+				return len(s)
+			}
+			i += end + 3
+		case c == '\\':
+			i++
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(s)
+}
+
+// skipString returns the index of the quote ending the string starting at
+// start in s.
+func skipString(s string, start int) int {
+	quote := s[start]
+	for i := start + 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case quote:
+			return i
+		}
+	}
+	return len(s)
+}
+
+func stripComments(s string) string {
+	for {
+		start := strings.Index(s, "/*")
+		if start == -1 {
+			return s
+		}
+		end := strings.Index(s[start+2:], "*/")
+		if end == -1 {
+			return s[:start]
+		}
+		s = s[:start] + " " + s[start+2+end+2:]
+	}
+}
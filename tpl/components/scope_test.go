@@ -0,0 +1,52 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package components
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestScopeClass(t *testing.T) {
+	c := qt.New(t)
+
+	c.Assert(scopeClass("card"), qt.Equals, "c-card")
+	c.Assert(scopeClass("card.html"), qt.Equals, "c-card")
+	c.Assert(scopeClass("nav/item"), qt.Equals, "c-nav__item")
+	c.Assert(scopeClass("nav-item"), qt.Equals, "c-nav-item")
+	c.Assert(scopeClass("nav__item"), qt.Equals, "c-nav_-_-item")
+	c.Assert(scopeClass("card.v2"), qt.Equals, "c-card_2ev2")
+}
+
+func TestScopeCSS(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range []struct {
+		css    string
+		expect string
+	}{
+		{`:scope { display: block; }`, `.c-card { display: block; }`},
+		{`h2 { color: red; }`, `.c-card h2 { color: red; }`},
+		{`h2, :scope > p, a:is(.x, .y) { color: red; }`, `.c-card h2, .c-card > p, .c-card a:is(.x, .y) { color: red; }`},
+		{"/* Title */\nh2 { content: \"}\"; }\np{}", "/* Title */\n.c-card h2 { content: \"}\"; }\n.c-card p {}"},
+		{`@import "base.css"; p { margin: 0; }`, `@import "base.css"; .c-card p { margin: 0; }`},
+		{`@media (min-width: 30em) { h2 { font-size: 2rem; } :scope { padding: 0; } }`, `@media (min-width: 30em) { .c-card h2 { font-size: 2rem; } .c-card { padding: 0; } }`},
+		{`@keyframes fade { from { opacity: 0; } to { opacity: 1; } }`, `@keyframes fade { from { opacity: 0; } to { opacity: 1; } }`},
+		{`@font-face { font-family: "A"; src: url(a.woff2); }`, `@font-face { font-family: "A"; src: url(a.woff2); }`},
+		{`h2 { color: red;`, `.c-card h2 { color: red;`},
+	} {
+		c.Assert(scopeCSS(test.css, "c-card"), qt.Equals, test.expect, qt.Commentf(test.css))
+	}
+}
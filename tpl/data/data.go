@@ -33,17 +33,34 @@ import (
 	"github.com/spf13/cast"
 
 	"github.com/gohugoio/hugo/cache/filecache"
+	"github.com/gohugoio/hugo/config/security"
 	"github.com/gohugoio/hugo/deps"
+	"github.com/gohugoio/hugo/graphql"
+	"github.com/gohugoio/hugo/parser/feed"
+	"github.com/mitchellh/mapstructure"
 	_errors "github.com/pkg/errors"
 )
 
 // New returns a new instance of the data-namespaced template functions.
 func New(deps *deps.Deps) *Namespace {
+	sc, ok := deps.Cfg.Get("securityConfig").(security.Config)
+	if !ok {
+		sc = security.DefaultConfig
+	}
+
+	gc, ok := deps.Cfg.Get("graphQLConfig").(graphql.Config)
+	if !ok {
+		gc = graphql.DefaultConfig
+	}
+
 	return &Namespace{
 		deps:         deps,
 		cacheGetCSV:  deps.FileCaches.GetCSVCache(),
 		cacheGetJSON: deps.FileCaches.GetJSONCache(),
-		client:       http.DefaultClient,
+		cacheGetFeed: deps.FileCaches.GetFeedCache(),
+		client:       sc.NewHTTPClient(0),
+		graphql:      graphql.New(gc, deps.FileCaches.GetGraphQLCache(), sc),
+		security:     sc,
 	}
 }
 
@@ -53,8 +70,12 @@ type Namespace struct {
 
 	cacheGetJSON *filecache.Cache
 	cacheGetCSV  *filecache.Cache
+	cacheGetFeed *filecache.Cache
 
-	client *http.Client
+	client  *http.Client
+	graphql *graphql.Client
+
+	security security.Config
 }
 
 // GetCSV expects a data separator and one or n-parts of a URL to a resource which
@@ -87,6 +108,9 @@ func (ns *Namespace) GetCSV(sep string, args ...interface{}) (d [][]string, err
 	addDefaultHeaders(req, "text/csv", "text/plain")
 
 	err = ns.getResource(cache, unmarshal, req)
+	if security.IsAccessDenied(err) {
+		return nil, err
+	}
 	if err != nil {
 		ns.deps.Log.(loggers.IgnorableLogger).Errorsf(constants.ErrRemoteGetCSV, "Failed to get CSV resource %q: %s", url, err)
 		return nil, nil
@@ -120,6 +144,9 @@ func (ns *Namespace) GetJSON(args ...interface{}) (interface{}, error) {
 	addDefaultHeaders(req, "application/json")
 
 	err = ns.getResource(cache, unmarshal, req)
+	if security.IsAccessDenied(err) {
+		return nil, err
+	}
 	if err != nil {
 		ns.deps.Log.(loggers.IgnorableLogger).Errorsf(constants.ErrRemoteGetJSON, "Failed to get JSON resource %q: %s", url, err)
 		return nil, nil
@@ -128,6 +155,91 @@ func (ns *Namespace) GetJSON(args ...interface{}) (interface{}, error) {
 	return v, nil
 }
 
+// GetFeed expects one or n-parts of a URL to an RSS, Atom or JSON feed, which can
+// either be a local or a remote one, and returns the parsed feed.
+// If you provide multiple parts they will be joined together to the final URL.
+// GetFeed returns nil if the feed could not be fetched or parsed.
+func (ns *Namespace) GetFeed(args ...interface{}) (*feed.Feed, error) {
+	var f *feed.Feed
+	url, headers := toURLAndHeaders(args)
+	cache := ns.cacheGetFeed
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, _errors.Wrapf(err, "Failed to create request for getFeed resource %s", url)
+	}
+
+	unmarshal := func(b []byte) (bool, error) {
+		var err error
+		f, err = feed.Parse(b)
+		if err != nil {
+			return true, err
+		}
+		return false, nil
+	}
+
+	addUserProvidedHeaders(headers, req)
+	addDefaultHeaders(req, "application/rss+xml", "application/atom+xml", "application/feed+json", "application/xml", "application/json")
+
+	err = ns.getResource(cache, unmarshal, req)
+	if security.IsAccessDenied(err) {
+		return nil, err
+	}
+	if err != nil {
+		ns.deps.Log.(loggers.IgnorableLogger).Errorsf(constants.ErrRemoteGetFeed, "Failed to get feed %q: %s", url, err)
+		return nil, nil
+	}
+
+	return f, nil
+}
+
+// GraphQL runs the GraphQL query against endpoint, which is either the
+// name of an endpoint in the graphql config or a URL, and returns the data
+// in the response. The optional arguments are a map of variables and a map of
+// options, headers and operationName.
+// GraphQL returns nil if the query failed.
+func (ns *Namespace) GraphQL(endpoint, query interface{}, args ...interface{}) (map[string]interface{}, error) {
+	if len(args) > 2 {
+		return nil, errors.New("GraphQL takes an endpoint, a query and optional variables and options")
+	}
+
+	endpointStr, err := cast.ToStringE(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	queryStr, err := cast.ToStringE(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		vars map[string]interface{}
+		opts graphql.Options
+	)
+
+	if len(args) > 0 && args[0] != nil {
+		if vars, err = maps.ToStringMapE(args[0]); err != nil {
+			return nil, _errors.Wrap(err, "GraphQL: variables must be a map")
+		}
+	}
+	if len(args) > 1 {
+		if err := mapstructure.WeakDecode(args[1], &opts); err != nil {
+			return nil, _errors.Wrap(err, "GraphQL: failed to decode options")
+		}
+	}
+
+	data, err := ns.graphql.Query(endpointStr, queryStr, vars, opts)
+	if security.IsAccessDenied(err) {
+		return nil, err
+	}
+	if err != nil {
+		ns.deps.Log.(loggers.IgnorableLogger).Errorsf(constants.ErrRemoteGraphQL, "%s", err)
+		return nil, nil
+	}
+
+	return data, nil
+}
+
 func addDefaultHeaders(req *http.Request, accepts ...string) {
 	for _, accept := range accepts {
 		if !hasHeaderValue(req.Header, "Accept", accept) {
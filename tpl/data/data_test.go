@@ -212,6 +212,67 @@ func TestGetJSON(t *testing.T) {
 	}
 }
 
+func TestGraphQL(t *testing.T) {
+	t.Parallel()
+	c := qt.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.Header.Get("X-Api-Key") != "foo" {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+		w.Header().Add("Content-type", "application/json")
+		w.Write([]byte(`{"data": {"posts": [{"title": "First"}]}}`))
+	}))
+	defer srv.Close()
+
+	ns := newTestNs()
+
+	got, err := ns.GraphQL(srv.URL, "{ posts { title } }", map[string]interface{}{"first": 1}, map[string]interface{}{"headers": map[string]interface{}{"X-Api-Key": "foo"}})
+	c.Assert(err, qt.IsNil)
+	c.Assert(got, qt.DeepEquals, map[string]interface{}{"posts": []interface{}{map[string]interface{}{"title": "First"}}})
+	c.Assert(int(ns.deps.Log.LogCounters().ErrorCounter.Count()), qt.Equals, 0)
+
+	got, err = ns.GraphQL(srv.URL, "{ posts { title } }")
+	c.Assert(err, qt.IsNil)
+	c.Assert(got, qt.IsNil)
+	c.Assert(int(ns.deps.Log.LogCounters().ErrorCounter.Count()), qt.Equals, 1)
+
+	_, err = ns.GraphQL(srv.URL, "{ posts { title } }", "notamap")
+	c.Assert(err, qt.Not(qt.IsNil))
+}
+
+func TestGetFeed(t *testing.T) {
+	t.Parallel()
+	c := qt.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/invalid.xml" {
+			w.Write([]byte("<html><body>Not a feed</body></html>"))
+			return
+		}
+		w.Header().Add("Content-type", "application/rss+xml")
+		w.Write([]byte(`<?xml version="1.0"?>
+<rss version="2.0"><channel><title>My Blog</title><item><title>First</title><link>https://example.org/first/</link></item></channel></rss>`))
+	}))
+	defer srv.Close()
+
+	ns := newTestNs()
+
+	f, err := ns.GetFeed(srv.URL, "/feed.xml")
+	c.Assert(err, qt.IsNil)
+	c.Assert(f, qt.Not(qt.IsNil))
+	c.Assert(f.Title, qt.Equals, "My Blog")
+	c.Assert(f.Items, qt.HasLen, 1)
+	c.Assert(f.Items[0].Link, qt.Equals, "https://example.org/first/")
+	c.Assert(int(ns.deps.Log.LogCounters().ErrorCounter.Count()), qt.Equals, 0)
+
+	f, err = ns.GetFeed(srv.URL, "/invalid.xml")
+	c.Assert(err, qt.IsNil)
+	c.Assert(f, qt.IsNil)
+	c.Assert(int(ns.deps.Log.LogCounters().ErrorCounter.Count()), qt.Equals, 1)
+}
+
 func TestHeaders(t *testing.T) {
 	t.Parallel()
 	c := qt.New(t)
@@ -38,6 +38,16 @@ func init() {
 			[]string{"getJSON"},
 			[][2]string{},
 		)
+
+		ns.AddMethodMapping(ctx.GetFeed,
+			[]string{"getFeed"},
+			[][2]string{},
+		)
+
+		ns.AddMethodMapping(ctx.GraphQL,
+			nil,
+			[][2]string{},
+		)
 		return ns
 	}
 
@@ -35,8 +35,13 @@ var (
 	resRetries = 1               // number of retries to load the JSON from URL
 )
 
-// getRemote loads the content of a remote file. This method is thread safe.
+// getRemote loads the content of a remote file if allowed by the HTTP
+// security policy. This method is thread safe.
 func (ns *Namespace) getRemote(cache *filecache.Cache, unmarshal func([]byte) (bool, error), req *http.Request) error {
+	if err := ns.security.CheckAllowedHTTP(req.Method, req.URL); err != nil {
+		return err
+	}
+
 	url := req.URL.String()
 	var headers bytes.Buffer
 	req.Header.Write(&headers)
@@ -30,6 +30,7 @@ import (
 	"github.com/gohugoio/hugo/cache/filecache"
 	"github.com/gohugoio/hugo/common/loggers"
 	"github.com/gohugoio/hugo/config"
+	"github.com/gohugoio/hugo/config/security"
 	"github.com/gohugoio/hugo/deps"
 	"github.com/gohugoio/hugo/hugofs"
 	"github.com/gohugoio/hugo/langs"
@@ -128,6 +129,30 @@ func TestScpGetRemote(t *testing.T) {
 	}
 }
 
+func TestScpGetRemoteSecurity(t *testing.T) {
+	t.Parallel()
+	c := qt.New(t)
+	fs := new(afero.MemMapFs)
+	cache := filecache.NewCache(fs, 100, "")
+
+	ns := newTestNs()
+	ns.security.HTTP.Domains = security.NewWhitelist("^gohugo\\.io$")
+
+	f := func(b []byte) (bool, error) {
+		return false, nil
+	}
+
+	req, err := http.NewRequest("GET", "http://example.org/data.json", nil)
+	c.Assert(err, qt.IsNil)
+	err = ns.getRemote(cache, f, req)
+	c.Assert(security.IsAccessDenied(err), qt.IsTrue)
+
+	req, err = http.NewRequest("DELETE", "http://gohugo.io/data.json", nil)
+	c.Assert(err, qt.IsNil)
+	err = ns.getRemote(cache, f, req)
+	c.Assert(security.IsAccessDenied(err), qt.IsTrue)
+}
+
 func TestScpGetRemoteParallel(t *testing.T) {
 	t.Parallel()
 	c := qt.New(t)
@@ -0,0 +1,57 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package navigation provides template functions for site navigation.
+package navigation
+
+import (
+	"github.com/gohugoio/hugo/common/types"
+	"github.com/gohugoio/hugo/deps"
+	"github.com/gohugoio/hugo/navigation"
+	"github.com/gohugoio/hugo/resources/page"
+	"github.com/pkg/errors"
+)
+
+// New returns a new instance of the navigation-namespaced template functions.
+func New(deps *deps.Deps) *Namespace {
+	return &Namespace{deps: deps}
+}
+
+// Namespace provides template functions for the "navigation" namespace.
+type Namespace struct {
+	deps *deps.Deps
+}
+
+// Breadcrumbs returns the breadcrumbs for the given page, from the home page
+// down to the page itself, following the page's ancestors, e.g. the section
+// for a regular page or the taxonomy for a term page.
+func (ns *Namespace) Breadcrumbs(v interface{}) (navigation.BreadcrumbList, error) {
+	p, ok := v.(page.Page)
+	if !ok || types.IsNil(p) {
+		return nil, errors.Errorf("breadcrumbs: expected a page, got %T", v)
+	}
+
+	ancestors := []navigation.BreadcrumbPage{p}
+	seen := map[page.Page]bool{p: true}
+	for parent := p.Parent(); !types.IsNil(parent) && !seen[parent]; parent = parent.Parent() {
+		seen[parent] = true
+		ancestors = append(ancestors, parent)
+	}
+
+	// Reverse, the home page first.
+	for i, j := 0, len(ancestors)-1; i < j; i, j = i+1, j-1 {
+		ancestors[i], ancestors[j] = ancestors[j], ancestors[i]
+	}
+
+	return navigation.NewBreadcrumbList(ancestors...), nil
+}
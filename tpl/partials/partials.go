@@ -26,6 +26,7 @@ import (
 	"sync"
 
 	"github.com/gohugoio/hugo/common/hreflect"
+	"github.com/gohugoio/hugo/common/maps"
 	texttemplate "github.com/gohugoio/hugo/tpl/internal/go_templates/texttemplate"
 
 	"github.com/gohugoio/hugo/helpers"
@@ -144,10 +145,18 @@ func (ns *Namespace) Include(name string, contextList ...interface{}) (interface
 		return "", err
 	}
 
-	var result interface{}
+	var (
+		result interface{}
+		err    error
+	)
 
 	if ctx, ok := context.(*contextWrapper); ok {
 		result = ctx.Result
+		if len(info.Config.Returns) > 0 {
+			if result, err = checkReturnValues(info.Config.Returns, result); err != nil {
+				return nil, fmt.Errorf("partial %q: %w", name, err)
+			}
+		}
 	} else if _, ok := templ.(*texttemplate.Template); ok {
 		result = w.(fmt.Stringer).String()
 	} else {
@@ -161,6 +170,16 @@ func (ns *Namespace) Include(name string, contextList ...interface{}) (interface
 	return result, nil
 }
 
+// checkReturnValues validates the named values returned from a partial
+// against the declarations in its $_hugo_config.
+func checkReturnValues(decls map[string]tpl.Param, result interface{}) (interface{}, error) {
+	values, err := maps.ToStringMapE(result)
+	if err != nil {
+		return nil, fmt.Errorf("expected named return values, got %T", result)
+	}
+	return tpl.CheckParams(decls, values, "return value")
+}
+
 // IncludeCached executes and caches partial templates.  The cache is created with name+variants as the key.
 func (ns *Namespace) IncludeCached(name string, context interface{}, variants ...interface{}) (interface{}, error) {
 	key, err := createKey(name, variants...)
@@ -25,6 +25,7 @@ import (
 	"github.com/gohugoio/hugo/tpl/internal/resourcehelpers"
 
 	"github.com/gohugoio/hugo/helpers"
+	"github.com/gohugoio/hugo/media"
 	"github.com/gohugoio/hugo/resources/postpub"
 
 	"github.com/gohugoio/hugo/deps"
@@ -36,6 +37,7 @@ import (
 	"github.com/gohugoio/hugo/resources/resource_transformers/babel"
 	"github.com/gohugoio/hugo/resources/resource_transformers/integrity"
 	"github.com/gohugoio/hugo/resources/resource_transformers/minifier"
+	"github.com/gohugoio/hugo/resources/resource_transformers/plugin"
 	"github.com/gohugoio/hugo/resources/resource_transformers/postcss"
 	"github.com/gohugoio/hugo/resources/resource_transformers/templates"
 	"github.com/gohugoio/hugo/resources/resource_transformers/tocss/dartsass"
@@ -70,6 +72,7 @@ func New(deps *deps.Deps) (*Namespace, error) {
 		postcssClient:     postcss.New(deps.ResourceSpec),
 		templatesClient:   templates.New(deps.ResourceSpec, deps),
 		babelClient:       babel.New(deps.ResourceSpec),
+		pluginClient:      plugin.New(deps.ResourceSpec),
 	}, nil
 }
 
@@ -84,6 +87,7 @@ type Namespace struct {
 	minifyClient      *minifier.Client
 	postcssClient     *postcss.Client
 	babelClient       *babel.Client
+	pluginClient      *plugin.Client
 	templatesClient   *templates.Client
 
 	// The Dart Client requires a os/exec process, so  only
@@ -186,37 +190,76 @@ func (ns *Namespace) Concat(targetPathIn interface{}, r interface{}) (resource.R
 }
 
 // FromString creates a Resource from a string published to the relative target path.
-func (ns *Namespace) FromString(targetPathIn, contentIn interface{}) (resource.Resource, error) {
-	targetPath, err := cast.ToStringE(targetPathIn)
+// An optional media type, e.g. "application/ld+json", can be passed after the
+// target path; it defaults to the one matching the target path's extension.
+func (ns *Namespace) FromString(args ...interface{}) (resource.Resource, error) {
+	if len(args) != 2 && len(args) != 3 {
+		return nil, errors.New("must provide targetPath, (optional) media type and content")
+	}
+	targetPath, err := cast.ToStringE(args[0])
 	if err != nil {
 		return nil, err
 	}
-	content, err := cast.ToStringE(contentIn)
+	mediaType, err := ns.resolveMediaType(targetPath, args[1:len(args)-1])
+	if err != nil {
+		return nil, err
+	}
+	content, err := cast.ToStringE(args[len(args)-1])
 	if err != nil {
 		return nil, err
 	}
 
-	return ns.createClient.FromString(targetPath, content)
+	return ns.createClient.FromString(targetPath, content, mediaType)
 }
 
 // ExecuteAsTemplate creates a Resource from a Go template, parsed and executed with
 // the given data, and published to the relative target path.
+// An optional media type can be passed after the target path; it defaults to
+// the media type of the template Resource.
 func (ns *Namespace) ExecuteAsTemplate(args ...interface{}) (resource.Resource, error) {
-	if len(args) != 3 {
-		return nil, fmt.Errorf("must provide targetPath, the template data context and a Resource object")
+	if len(args) != 3 && len(args) != 4 {
+		return nil, fmt.Errorf("must provide targetPath, (optional) media type, the template data context and a Resource object")
 	}
 	targetPath, err := cast.ToStringE(args[0])
 	if err != nil {
 		return nil, err
 	}
-	data := args[1]
+	mediaType, err := ns.resolveMediaType(targetPath, args[1:len(args)-2])
+	if err != nil {
+		return nil, err
+	}
+	data := args[len(args)-2]
 
-	r, ok := args[2].(resources.ResourceTransformer)
+	r, ok := args[len(args)-1].(resources.ResourceTransformer)
 	if !ok {
-		return nil, fmt.Errorf("type %T not supported in Resource transformations", args[2])
+		return nil, fmt.Errorf("type %T not supported in Resource transformations", args[len(args)-1])
 	}
 
-	return ns.templatesClient.ExecuteAsTemplate(r, targetPath, data)
+	return ns.templatesClient.ExecuteAsTemplate(r, targetPath, data, mediaType)
+}
+
+// resolveMediaType resolves the optional media type argument in args, e.g.
+// "application/json". Media types not in the site's configuration are
+// created with the target path's extension as suffix.
+func (ns *Namespace) resolveMediaType(targetPath string, args []interface{}) (media.Type, error) {
+	if len(args) == 0 {
+		return media.Type{}, nil
+	}
+	s, err := cast.ToStringE(args[0])
+	if err != nil {
+		return media.Type{}, err
+	}
+	if s == "" {
+		return media.Type{}, nil
+	}
+	if mt, found := ns.deps.ResourceSpec.MediaTypes.GetByType(s); found {
+		return mt, nil
+	}
+	mt, err := media.FromStringAndExt(s, filepath.Ext(targetPath))
+	if err != nil {
+		return media.Type{}, errors.Wrap(err, "invalid media type")
+	}
+	return mt, nil
 }
 
 // Fingerprint transforms the given Resource with a MD5 hash of the content in
@@ -361,3 +404,15 @@ func (ns *Namespace) Babel(args ...interface{}) (resource.Resource, error) {
 
 	return ns.babelClient.Process(r, options)
 }
+
+// Plugin transforms the given Resource with the named WASM plugin. You can
+// optionally provide an Options object, passed on to the plugin, as the
+// second argument.
+func (ns *Namespace) Plugin(name string, args ...interface{}) (resource.Resource, error) {
+	r, m, err := resourcehelpers.ResolveArgs(args)
+	if err != nil {
+		return nil, err
+	}
+
+	return ns.pluginClient.Transform(name, r, m)
+}
@@ -0,0 +1,64 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tpl
+
+import (
+	"io"
+	"path"
+	"strings"
+)
+
+// TemplateEngine parses templates written in an alternative template
+// language, e.g. Liquid, see RegisterTemplateEngine.
+type TemplateEngine interface {
+	// Parse parses the template source in src.
+	Parse(name, src string) (EngineTemplate, error)
+}
+
+// EngineTemplate is a template parsed by a TemplateEngine.
+type EngineTemplate interface {
+	// Execute executes the template with the given data and writes the
+	// output to wr. funcs are Hugo's template functions, e.g. partial.
+	Execute(wr io.Writer, data interface{}, funcs map[string]interface{}) error
+}
+
+// templateEngines maps a file extension without the dot to its engine.
+var templateEngines = make(map[string]TemplateEngine)
+
+// RegisterTemplateEngine registers engine as the parser for the layout files
+// with the given extension, e.g. "liquid". A layout file on the form
+// single.html.liquid is then parsed by the engine and looked up as
+// single.html. This should be called from an init func.
+func RegisterTemplateEngine(ext string, engine TemplateEngine) {
+	templateEngines[strings.TrimPrefix(ext, ".")] = engine
+}
+
+// TemplateEngineFor returns the engine registered for the extension of the
+// template name and the name without that extension. If the name has no
+// other extension, ".html" is added.
+func TemplateEngineFor(name string) (TemplateEngine, string, bool) {
+	ext := path.Ext(name)
+	if ext == "" {
+		return nil, name, false
+	}
+	engine, found := templateEngines[ext[1:]]
+	if !found {
+		return nil, name, false
+	}
+	name = strings.TrimSuffix(name, ext)
+	if path.Ext(name) == "" {
+		name += ".html"
+	}
+	return engine, name, true
+}
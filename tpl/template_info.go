@@ -14,7 +14,14 @@
 package tpl
 
 import (
+	"sort"
+	"strings"
+
+	"github.com/gohugoio/hugo/common/maps"
 	"github.com/gohugoio/hugo/identity"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+	"github.com/spf13/cast"
 )
 
 // Increments on breaking changes.
@@ -71,6 +78,149 @@ type Info1 struct {
 
 type ParseConfig struct {
 	Version int
+
+	// The named parameters declared in a shortcode template.
+	Params map[string]Param
+
+	// The named values returned from a partial template.
+	Returns map[string]Param
+
+	// The named slots of a component.
+	Slots []string
+}
+
+// The parameter types.
+const (
+	ParamString = "string"
+	ParamInt    = "int"
+	ParamFloat  = "float"
+	ParamBool   = "bool"
+)
+
+// Param declares a named shortcode parameter or partial return value.
+type Param struct {
+	// The type of the value, one of string, int, float or bool.
+	// Default is any type.
+	Type string
+
+	// The value used if not set.
+	Default interface{}
+
+	// Whether the value must be set.
+	Required bool
+
+	// Describes the value, used in the generated theme docs.
+	Description string
+}
+
+// Convert converts v to the parameter type.
+func (p Param) Convert(v interface{}) (interface{}, error) {
+	switch p.Type {
+	case ParamString:
+		return cast.ToStringE(v)
+	case ParamInt:
+		return cast.ToIntE(v)
+	case ParamFloat:
+		return cast.ToFloat64E(v)
+	case ParamBool:
+		return cast.ToBoolE(v)
+	}
+	return v, nil
+}
+
+func (p *Param) init() error {
+	p.Type = strings.ToLower(p.Type)
+	switch p.Type {
+	case "", ParamString, ParamInt, ParamFloat, ParamBool:
+	default:
+		return errors.Errorf("invalid type %q, must be one of string, int, float or bool", p.Type)
+	}
+
+	if p.Default != nil {
+		if p.Required {
+			return errors.New("a required value cannot have a default")
+		}
+		var err error
+		if p.Default, err = p.Convert(p.Default); err != nil {
+			return errors.Wrap(err, "invalid default")
+		}
+	}
+
+	return nil
+}
+
+// CheckParams validates the named values against the declarations in
+// decls, converts them to the declared types and applies the defaults.
+// what names the values in the error messages, e.g. "parameter".
+func CheckParams(decls map[string]Param, values map[string]interface{}, what string) (map[string]interface{}, error) {
+	checked := make(map[string]interface{})
+
+	keys := make([]string, 0, len(values))
+	for name := range values {
+		keys = append(keys, name)
+	}
+	sort.Strings(keys)
+
+	for _, name := range keys {
+		decl, found := decls[name]
+		if !found {
+			return nil, errors.Errorf("unknown %s %q", what, name)
+		}
+		v, err := decl.Convert(values[name])
+		if err != nil {
+			return nil, errors.Errorf("%s %q: %q is not a valid %s", what, name, values[name], decl.Type)
+		}
+		checked[name] = v
+	}
+
+	names := make([]string, 0, len(decls))
+	for name := range decls {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if _, found := checked[name]; found {
+			continue
+		}
+		decl := decls[name]
+		if decl.Required {
+			return nil, errors.Errorf("missing required %s %q", what, name)
+		}
+		if decl.Default != nil {
+			checked[name] = decl.Default
+		}
+	}
+
+	return checked, nil
+}
+
+// Decode decodes the JSON config in a $_hugo_config template variable into c.
+func (c *ParseConfig) Decode(s string) error {
+	m, err := maps.ToStringMapE(s)
+	if err != nil {
+		return err
+	}
+	if err := mapstructure.WeakDecode(m, c); err != nil {
+		return err
+	}
+	return c.init()
+}
+
+func (c ParseConfig) init() error {
+	for name, p := range c.Params {
+		if err := p.init(); err != nil {
+			return errors.Wrapf(err, "shortcode parameter %q", name)
+		}
+		c.Params[name] = p
+	}
+	for name, p := range c.Returns {
+		if err := p.init(); err != nil {
+			return errors.Wrapf(err, "partial return value %q", name)
+		}
+		c.Returns[name] = p
+	}
+	return nil
 }
 
 var DefaultParseConfig = ParseConfig{
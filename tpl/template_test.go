@@ -28,3 +28,33 @@ func TestExtractBaseof(t *testing.T) {
 	c.Assert(extractBaseOf("not baseof for you"), qt.Equals, "")
 	c.Assert(extractBaseOf("template: blog/baseof.html:23:11:"), qt.Equals, "blog/baseof.html")
 }
+
+type testTemplateEngine struct{}
+
+func (testTemplateEngine) Parse(name, src string) (EngineTemplate, error) {
+	return nil, nil
+}
+
+func TestTemplateEngineFor(t *testing.T) {
+	c := qt.New(t)
+
+	RegisterTemplateEngine(".testengine", testTemplateEngine{})
+	defer delete(templateEngines, "testengine")
+
+	for _, test := range []struct {
+		name     string
+		expect   string
+		expectOK bool
+	}{
+		{"_default/single.html.testengine", "_default/single.html", true},
+		{"_default/single.json.testengine", "_default/single.json", true},
+		{"partials/foo.testengine", "partials/foo.html", true},
+		{"_default/single.html", "_default/single.html", false},
+		{"_default/single", "_default/single", false},
+	} {
+		engine, name, found := TemplateEngineFor(test.name)
+		c.Assert(found, qt.Equals, test.expectOK, qt.Commentf(test.name))
+		c.Assert(name, qt.Equals, test.expect)
+		c.Assert(engine != nil, qt.Equals, test.expectOK)
+	}
+}
@@ -22,7 +22,7 @@ const name = "time"
 
 func init() {
 	f := func(d *deps.Deps) *internal.TemplateFuncsNamespace {
-		ctx := New()
+		ctx := NewWithClock(d.Clock)
 
 		ns := &internal.TemplateFuncsNamespace{
 			Name: name,
@@ -18,6 +18,7 @@ import (
 	"fmt"
 	_time "time"
 
+	"github.com/gohugoio/hugo/common/htime"
 	"github.com/spf13/cast"
 )
 
@@ -50,11 +51,23 @@ var timeFormats = []string{
 
 // New returns a new instance of the time-namespaced template functions.
 func New() *Namespace {
-	return &Namespace{}
+	return NewWithClock(htime.SystemClock)
+}
+
+// NewWithClock returns a new instance of the time-namespaced template
+// functions where Now reads the given clock. If clock is nil, the system
+// clock is used.
+func NewWithClock(clock htime.Clock) *Namespace {
+	if clock == nil {
+		clock = htime.SystemClock
+	}
+	return &Namespace{clock: clock}
 }
 
 // Namespace provides template functions for the "time" namespace.
-type Namespace struct{}
+type Namespace struct {
+	clock htime.Clock
+}
 
 // AsTime converts the textual representation of the datetime string into
 // a time.Time interface.
@@ -108,9 +121,10 @@ func (ns *Namespace) Format(layout string, v interface{}) (string, error) {
 	return t.Format(layout), nil
 }
 
-// Now returns the current local time.
+// Now returns the current local time, or the time of the build in
+// reproducible builds.
 func (ns *Namespace) Now() _time.Time {
-	return _time.Now()
+	return ns.clock.Now()
 }
 
 // ParseDuration parses a duration string.
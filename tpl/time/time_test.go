@@ -16,6 +16,8 @@ package time
 import (
 	"testing"
 	"time"
+
+	"github.com/gohugoio/hugo/common/htime"
 )
 
 func TestTimeLocation(t *testing.T) {
@@ -136,3 +138,13 @@ func TestDuration(t *testing.T) {
 		}
 	}
 }
+
+func TestNow(t *testing.T) {
+	t.Parallel()
+
+	d := time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)
+	ns := NewWithClock(htime.FixedClock(d))
+	if now := ns.Now(); !now.Equal(d) {
+		t.Errorf("Now got %v but expected %v", now, d)
+	}
+}
@@ -18,16 +18,111 @@ package embedded
 
 // EmbeddedTemplates represents all embedded templates.
 var EmbeddedTemplates = [][2]string{
+	{`_default/email.html`, `<mjml lang="{{ site.LanguageCode | default "en" }}">
+  <mj-head>
+    <mj-title>{{ .Title }}</mj-title>
+    {{- with .Summary | plainify | htmlUnescape | truncate 140 }}
+    <mj-preview>{{ . }}</mj-preview>
+    {{- end }}
+    <mj-attributes>
+      <mj-all font-family="Helvetica, Arial, sans-serif" />
+      <mj-text font-size="16px" line-height="1.5" color="#333333" />
+      <mj-button background-color="#333333" font-size="16px" />
+    </mj-attributes>
+    <mj-style inline="inline">
+      a { color: #1a73e8; }
+      img { max-width: 100%; height: auto; }
+      pre { white-space: pre-wrap; }
+    </mj-style>
+  </mj-head>
+  <mj-body background-color="#f4f4f4">
+    <mj-section padding-bottom="0">
+      <mj-column>
+        <mj-text align="center" font-size="14px"><a href="{{ site.Home.Permalink }}">{{ site.Title }}</a></mj-text>
+      </mj-column>
+    </mj-section>
+    <mj-section background-color="#ffffff">
+      <mj-column>
+        <mj-text><h1>{{ .Title }}</h1>{{ .Content }}</mj-text>
+        {{- if .IsPage }}
+        <mj-button href="{{ .Permalink }}">Read online</mj-button>
+        {{- else }}
+        {{- range first 10 .RegularPagesRecursive }}
+        <mj-divider border-width="1px" border-color="#eeeeee" />
+        <mj-text><h2><a href="{{ .Permalink }}">{{ .Title }}</a></h2>{{ .Summary }}</mj-text>
+        {{- end }}
+        {{- end }}
+      </mj-column>
+    </mj-section>
+  </mj-body>
+</mjml>
+`},
+	{`_default/list.gmi`, `# {{ .Title }}
+{{ with .Content }}
+{{ . | chomp }}
+{{ end }}{{ range .Pages }}{{ $page := . }}{{ with .OutputFormats.Get "gemini" }}
+=> {{ .RelPermalink }} {{ if not $page.Date.IsZero }}{{ $page.Date.Format "2006-01-02" }} {{ end }}{{ $page.Title }}{{ end }}{{ end }}
+`},
+	{`_default/list.md`, `---
+title: {{ .Title | jsonify }}
+{{- with .Description }}
+description: {{ . | jsonify }}{{ end }}
+url: {{ .Permalink }}
+---
+{{ with .Content }}
+{{ . | chomp }}
+{{ end }}
+{{ range .Pages -}}
+- [{{ .Title }}]({{ with .OutputFormats.Get "markdown" }}{{ .Permalink }}{{ else }}{{ .Permalink }}{{ end }}){{ with .Description }}: {{ . }}{{ end }}
+{{ end -}}
+`},
+	{`_default/llms-full.txt`, `# {{ site.Title }}
+{{ with site.Params.description }}
+> {{ . }}
+{{ end }}
+{{- range site.RegularPages }}
+---
+
+# {{ .Title }}
+
+URL: {{ .Permalink }}
+{{ with .Content }}
+{{ . | chomp }}
+{{ end }}
+{{- end }}`},
+	{`_default/llms.txt`, `{{- define "_internal/llms-link" -}}
+- [{{ .Title }}]({{ with .OutputFormats.Get "markdown" }}{{ .Permalink }}{{ else }}{{ .Permalink }}{{ end }}){{ with .Description }}: {{ . }}{{ end }}
+{{ end -}}
+# {{ site.Title }}
+{{ with site.Params.description }}
+> {{ . }}
+{{ end }}
+{{- with site.Home.RegularPages }}
+## {{ site.Home.Title }}
+
+{{ range . }}{{ template "_internal/llms-link" . }}{{ end }}
+{{- end }}
+{{- range site.Sections }}
+## {{ .Title }}
+
+{{ range .RegularPagesRecursive }}{{ template "_internal/llms-link" . }}{{ end }}
+{{- end }}`},
 	{`_default/robots.txt`, `User-agent: *`},
 	{`_default/rss.xml`, `{{- $pctx := . -}}
 {{- if .IsHome -}}{{ $pctx = .Site }}{{- end -}}
+{{- $feed := .Site.Config.Services.RSS.Feed .Kind .Section -}}
 {{- $pages := slice -}}
-{{- if or $.IsHome $.IsSection -}}
+{{- if and $.IsSection $feed.IncludeSubpages -}}
+{{- $pages = $pctx.RegularPagesRecursive -}}
+{{- else if or $.IsHome $.IsSection -}}
 {{- $pages = $pctx.RegularPages -}}
 {{- else -}}
 {{- $pages = $pctx.Pages -}}
 {{- end -}}
-{{- $limit := .Site.Config.Services.RSS.Limit -}}
+{{- with $feed.ExcludeSections -}}
+{{- $pages = where $pages "Section" "not in" . -}}
+{{- end -}}
+{{- $limit := $feed.Limit -}}
 {{- if ge $limit 1 -}}
 {{- $pages = $pages | first $limit -}}
 {{- end -}}
@@ -53,11 +148,30 @@ var EmbeddedTemplates = [][2]string{
       <pubDate>{{ .Date.Format "Mon, 02 Jan 2006 15:04:05 -0700" | safeHTML }}</pubDate>
       {{ with .Site.Author.email }}<author>{{.}}{{ with $.Site.Author.name }} ({{.}}){{end}}</author>{{end}}
       <guid>{{ .Permalink }}</guid>
-      <description>{{ .Summary | html }}</description>
+      <description>{{ if $feed.FullContent }}{{ .Content | html }}{{ else }}{{ .Summary | html }}{{ end }}</description>
     </item>
     {{ end }}
   </channel>
 </rss>
+`},
+	{`_default/single.gmi`, `# {{ .Title }}
+{{ if not .Date.IsZero }}
+{{ .Date.Format "2006-01-02" }}
+{{ end }}
+{{ .Content }}
+`},
+	{`_default/single.md`, `---
+title: {{ .Title | jsonify }}
+{{- with .Description }}
+description: {{ . | jsonify }}{{ end }}
+{{- if not .Date.IsZero }}
+date: {{ .Date.Format "2006-01-02T15:04:05Z07:00" }}{{ end }}
+{{- if and (not .Lastmod.IsZero) (ne .Lastmod .Date) }}
+lastmod: {{ .Lastmod.Format "2006-01-02T15:04:05Z07:00" }}{{ end }}
+url: {{ .Permalink }}
+---
+
+{{ .Content | chomp }}
 `},
 	{`_default/sitemap.xml`, `{{ printf "<?xml version=\"1.0\" encoding=\"utf-8\" standalone=\"yes\"?>" | safeHTML }}
 <urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"
@@ -65,8 +179,8 @@ var EmbeddedTemplates = [][2]string{
   {{ range .Data.Pages }}
     {{- if .Permalink -}}
   <url>
-    <loc>{{ .Permalink }}</loc>{{ if not .Lastmod.IsZero }}
-    <lastmod>{{ safeHTML ( .Lastmod.Format "2006-01-02T15:04:05-07:00" ) }}</lastmod>{{ end }}{{ with .Sitemap.ChangeFreq }}
+    <loc>{{ .Permalink }}</loc>{{ if not .Sitemap.Lastmod.IsZero }}
+    <lastmod>{{ safeHTML ( .Sitemap.Lastmod.Format "2006-01-02T15:04:05-07:00" ) }}</lastmod>{{ end }}{{ with .Sitemap.ChangeFreq }}
     <changefreq>{{ . }}</changefreq>{{ end }}{{ if ge .Sitemap.Priority 0.0 }}
     <priority>{{ .Sitemap.Priority }}</priority>{{ end }}{{ if .IsTranslated }}{{ range .Translations }}
     <xhtml:link
@@ -96,7 +210,13 @@ var EmbeddedTemplates = [][2]string{
   {{ end }}
 </sitemapindex>
 `},
-	{`alias.html`, `<!DOCTYPE html><html><head><title>{{ .Permalink }}</title><link rel="canonical" href="{{ .Permalink }}"/><meta name="robots" content="noindex"><meta charset="utf-8" /><meta http-equiv="refresh" content="0; url={{ .Permalink }}" /></head></html>`},
+	{`alias.html`, `{{- if eq .Status 410 -}}
+<!DOCTYPE html><html><head><title>410 Gone</title><meta name="robots" content="noindex"><meta charset="utf-8" /></head><body><h1>Gone</h1></body></html>
+{{- else if .LanguageRedirect -}}
+<!DOCTYPE html><html><head><title>{{ .Permalink }}</title><meta name="robots" content="noindex"><meta charset="utf-8" />{{ template "_internal/language_redirect.html" . }}<noscript><meta http-equiv="refresh" content="0; url={{ .Permalink }}" /></noscript></head></html>
+{{- else -}}
+<!DOCTYPE html><html><head><title>{{ .Permalink }}</title>{{ if .Canonical }}<link rel="canonical" href="{{ .Permalink }}"/>{{ end }}<meta name="robots" content="noindex"><meta charset="utf-8" /><meta http-equiv="refresh" content="0; url={{ .Permalink }}" /></head></html>
+{{- end -}}`},
 	{`disqus.html`, `{{- $pc := .Site.Config.Privacy.Disqus -}}
 {{- if not $pc.Disable -}}
 {{ if .Site.DisqusShortname }}<div id="disqus_thread"></div>
@@ -203,6 +323,47 @@ if (!doNotTrack) {
 	{`google_news.html`, `{{ if .IsPage }}{{ with .Params.news_keywords }}
   <meta name="news_keywords" content="{{ range $i, $kw := first 10 . }}{{ if $i }},{{ end }}{{ $kw }}{{ end }}" />
 {{ end }}{{ end }}`},
+	{`language_redirect.html`, `{{- $root := "/" | relURL -}}
+{{- $langs := slice -}}
+{{- range site.Sites -}}
+  {{- with .Home -}}
+    {{- $langs = $langs | append (dict "lang" .Language.Lang "url" .RelPermalink) -}}
+  {{- end -}}
+{{- end -}}
+{{- $fallback := "" -}}
+{{- with site.Sites.First.Home -}}
+  {{- if ne .RelPermalink $root -}}
+    {{- $fallback = .RelPermalink -}}
+  {{- end -}}
+{{- end -}}
+<script>
+(function() {
+  var root = {{ $root }}, langs = {{ $langs }}, fallback = {{ $fallback }}, key = "hugo-language-redirect";
+  if (location.pathname !== root) return;
+  if (!fallback) {
+    // The root has content; only redirect on the first visit in a session.
+    try {
+      if (sessionStorage.getItem(key)) return;
+      sessionStorage.setItem(key, "1");
+    } catch (e) {}
+  }
+  var target = fallback, prefs = navigator.languages || [navigator.language || ""];
+  search: for (var i = 0; i < prefs.length; i++) {
+    var pref = prefs[i].toLowerCase();
+    for (var exact = 1; exact >= 0; exact--) {
+      for (var j = 0; j < langs.length; j++) {
+        var lang = langs[j].lang.toLowerCase();
+        if (exact ? lang === pref : lang.split("-")[0] === pref.split("-")[0]) {
+          target = langs[j].url;
+          break search;
+        }
+      }
+    }
+  }
+  if (target && target !== root) location.replace(target);
+})();
+</script>
+`},
 	{`opengraph.html`, `<meta property="og:title" content="{{ .Title }}" />
 <meta property="og:description" content="{{ with .Description }}{{ . }}{{ else }}{{if .IsPage}}{{ .Summary }}{{ else }}{{ with .Site.Params.description }}{{ . }}{{ end }}{{ end }}{{ end }}" />
 <meta property="og:type" content="{{ if .IsPage }}article{{ else }}website{{ end }}" />
@@ -16,6 +16,7 @@ package tplimpl
 import (
 	"io"
 	"os"
+	"path"
 	"path/filepath"
 	"reflect"
 	"regexp"
@@ -124,10 +125,7 @@ func newStandaloneTextTemplate(funcs map[string]interface{}) tpl.TemplateParseFi
 
 func newTemplateExec(d *deps.Deps) (*templateExec, error) {
 	exec, funcs := newTemplateExecuter(d)
-	funcMap := make(map[string]interface{})
-	for k, v := range funcs {
-		funcMap[k] = v.Interface()
-	}
+	funcMap := toFuncMap(funcs)
 
 	h := &templateHandler{
 		nameBaseTemplateName: make(map[string]string),
@@ -147,6 +145,8 @@ func newTemplateExec(d *deps.Deps) (*templateExec, error) {
 		layoutTemplateCache: make(map[layoutCacheKey]tpl.Template),
 	}
 
+	templateParseCache.rotate()
+
 	if err := h.loadEmbedded(); err != nil {
 		return nil, err
 	}
@@ -155,10 +155,13 @@ func newTemplateExec(d *deps.Deps) (*templateExec, error) {
 		return nil, err
 	}
 
+	d.Log.Infof("templates: parsed %d, reused %d from the parse cache", h.main.parseStats.parsed, h.main.parseStats.cached)
+
 	e := &templateExec{
 		d:               d,
 		executor:        exec,
 		funcs:           funcs,
+		funcMap:         funcMap,
 		templateHandler: h,
 	}
 
@@ -174,10 +177,21 @@ func newTemplateExec(d *deps.Deps) (*templateExec, error) {
 	return e, nil
 }
 
+func toFuncMap(funcs map[string]reflect.Value) map[string]interface{} {
+	funcMap := make(map[string]interface{})
+	for k, v := range funcs {
+		funcMap[k] = v.Interface()
+	}
+	return funcMap
+}
+
 func newTemplateNamespace(funcs map[string]interface{}) *templateNamespace {
 	return &templateNamespace{
 		prototypeHTML: htmltemplate.New("").Funcs(funcs),
 		prototypeText: texttemplate.New("").Funcs(funcs),
+		funcs:         funcs,
+		funcsKey:      funcsKey(funcs),
+		parseStats:    &parseCacheStats{},
 		templateStateMap: &templateStateMap{
 			templates: make(map[string]*templateState),
 		},
@@ -204,6 +218,9 @@ type templateExec struct {
 	executor texttemplate.Executer
 	funcs    map[string]reflect.Value
 
+	// The funcs passed on to the alternative template engines.
+	funcMap map[string]interface{}
+
 	*templateHandler
 }
 
@@ -211,6 +228,7 @@ func (t templateExec) Clone(d *deps.Deps) *templateExec {
 	exec, funcs := newTemplateExecuter(d)
 	t.executor = exec
 	t.funcs = funcs
+	t.funcMap = toFuncMap(funcs)
 	t.d = d
 	return &t
 }
@@ -224,9 +242,18 @@ func (t *templateExec) Execute(templ tpl.Template, wr io.Writer, data interface{
 		defer t.Metrics.MeasureSince(templ.Name(), time.Now())
 	}
 
-	execErr := t.executor.Execute(templ, wr, data)
+	span := t.Tracer.Start("template: "+templ.Name(), "hugo.template", templ.Name())
+	defer span.End()
+
+	var execErr error
+	if et, ok := unwrap(templ).(*engineTemplate); ok {
+		execErr = et.execute(wr, data, t.funcMap)
+	} else {
+		execErr = t.executor.Execute(templ, wr, data)
+	}
 	if execErr != nil {
 		execErr = t.addFileContext(templ, execErr)
+		span.SetError(execErr)
 	}
 	return execErr
 }
@@ -488,7 +515,7 @@ func (t *templateHandler) addFileContext(templ tpl.Template, inerr error) error
 		return inErr, false
 	}
 
-	inerr = errors.Wrap(inerr, "execute of template failed")
+	inerr = herrors.WithCode(herrors.CodeTemplateExecute, errors.Wrap(inerr, "execute of template failed"))
 
 	if err, ok := checkFilename(ts.info, inerr); ok {
 		return err
@@ -525,40 +552,41 @@ func (t *templateHandler) addShortcodeVariant(ts *templateState) {
 	}
 }
 
-func (t *templateHandler) addTemplateFile(name, path string) error {
-	getTemplate := func(filename string) (templateInfo, error) {
-		fs := t.Layouts.Fs
-		b, err := afero.ReadFile(fs, filename)
-		if err != nil {
-			return templateInfo{filename: filename, fs: fs}, err
-		}
+func (t *templateHandler) readTemplateFile(name, filename string) (templateInfo, error) {
+	fs := t.Layouts.Fs
+	b, err := afero.ReadFile(fs, filename)
+	if err != nil {
+		return templateInfo{filename: filename, fs: fs}, err
+	}
 
-		s := removeLeadingBOM(string(b))
+	s := removeLeadingBOM(string(b))
 
-		realFilename := filename
-		if fi, err := fs.Stat(filename); err == nil {
-			if fim, ok := fi.(hugofs.FileMetaInfo); ok {
-				realFilename = fim.Meta().Filename()
-			}
+	realFilename := filename
+	if fi, err := fs.Stat(filename); err == nil {
+		if fim, ok := fi.(hugofs.FileMetaInfo); ok {
+			realFilename = fim.Meta().Filename()
 		}
+	}
 
-		var isText bool
-		name, isText = t.nameIsText(name)
+	var isText bool
+	name, isText = t.nameIsText(name)
 
-		return templateInfo{
-			name:         name,
-			isText:       isText,
-			template:     s,
-			filename:     filename,
-			realFilename: realFilename,
-			fs:           fs,
-		}, nil
-	}
+	return templateInfo{
+		name:         name,
+		isText:       isText,
+		template:     s,
+		filename:     filename,
+		realFilename: realFilename,
+		fs:           fs,
+	}, nil
+}
 
-	tinfo, err := getTemplate(path)
+func (t *templateHandler) addTemplateFile(name, path string) error {
+	tinfo, err := t.readTemplateFile(name, path)
 	if err != nil {
 		return err
 	}
+	name = tinfo.name
 
 	if isBaseTemplatePath(name) {
 		// Store it for later.
@@ -576,7 +604,9 @@ func (t *templateHandler) addTemplateFile(name, path string) error {
 	if err != nil {
 		return tinfo.errWithFileContext("parse failed", err)
 	}
-	t.applyTemplateTransformers(t.main, templ)
+	if _, err := t.applyTemplateTransformers(t.main, templ); err != nil {
+		return tinfo.errWithFileContext("parse failed", err)
+	}
 
 	return nil
 }
@@ -593,13 +623,13 @@ func (t *templateHandler) applyBaseTemplate(overlay, base templateInfo) (tpl.Tem
 		)
 
 		if !base.IsZero() {
-			templ, err = templ.Parse(base.template)
+			templ, err = t.main.parseText(templ, base.template)
 			if err != nil {
 				return nil, base.errWithFileContext("parse failed", err)
 			}
 		}
 
-		templ, err = texttemplate.Must(templ.Clone()).Parse(overlay.template)
+		templ, err = t.main.parseText(texttemplate.Must(templ.Clone()), overlay.template)
 		if err != nil {
 			return nil, overlay.errWithFileContext("parse failed", err)
 		}
@@ -618,13 +648,13 @@ func (t *templateHandler) applyBaseTemplate(overlay, base templateInfo) (tpl.Tem
 	)
 
 	if !base.IsZero() {
-		templ, err = templ.Parse(base.template)
+		templ, err = t.main.parseHTML(templ, base.template)
 		if err != nil {
 			return nil, base.errWithFileContext("parse failed", err)
 		}
 	}
 
-	templ, err = htmltemplate.Must(templ.Clone()).Parse(overlay.template)
+	templ, err = t.main.parseHTML(htmltemplate.Must(templ.Clone()), overlay.template)
 	if err != nil {
 		return nil, overlay.errWithFileContext("parse failed", err)
 	}
@@ -667,7 +697,11 @@ func (t *templateHandler) extractIdentifiers(line string) []string {
 func (t *templateHandler) loadEmbedded() error {
 	for _, kv := range embedded.EmbeddedTemplates {
 		name, templ := kv[0], kv[1]
-		if err := t.AddTemplate(internalPathPrefix+name, templ); err != nil {
+		fullName := internalPathPrefix + name
+		if isEmbeddedTextTemplate(name) {
+			fullName = textTmplNamePrefix + fullName
+		}
+		if err := t.AddTemplate(fullName, templ); err != nil {
 			return err
 		}
 		if aliases, found := embeddedTemplatesAliases[name]; found {
@@ -683,6 +717,16 @@ func (t *templateHandler) loadEmbedded() error {
 	return nil
 }
 
+// isEmbeddedTextTemplate reports whether the embedded template name is for
+// one of the plain text output formats with a built-in template.
+func isEmbeddedTextTemplate(name string) bool {
+	switch path.Ext(name) {
+	case output.GeminiFormat.MediaType.FirstSuffix.FullSuffix, output.MarkdownFormat.MediaType.FirstSuffix.FullSuffix:
+		return true
+	}
+	return strings.HasPrefix(path.Base(name), output.LLMSFormat.BaseName)
+}
+
 func (t *templateHandler) loadTemplates() error {
 	walker := func(path string, fi hugofs.FileMetaInfo, err error) error {
 		if err != nil || fi.IsDir() {
@@ -694,6 +738,11 @@ func (t *templateHandler) loadTemplates() error {
 		}
 
 		name := strings.TrimPrefix(filepath.ToSlash(path), "/")
+
+		if engine, engineName, found := tpl.TemplateEngineFor(name); found {
+			return t.addEngineTemplateFile(engine, engineName, path)
+		}
+
 		filename := filepath.Base(path)
 		outputFormat, found := t.OutputFormatsConfig.FromFilename(filename)
 
@@ -773,6 +822,10 @@ func (t *templateHandler) postTransform() error {
 			t.addShortcodeVariant(v)
 		}
 
+		if isEngineTemplate(v) {
+			continue
+		}
+
 		if defineCheckedHTML && defineCheckedText {
 			continue
 		}
@@ -842,6 +895,10 @@ type templateNamespace struct {
 	prototypeTextClone *texttemplate.Template
 	prototypeHTMLClone *htmltemplate.Template
 
+	funcs      map[string]interface{}
+	funcsKey   string
+	parseStats *parseCacheStats
+
 	*templateStateMap
 }
 
@@ -900,7 +957,7 @@ func (t *templateNamespace) parse(info templateInfo) (*templateState, error) {
 	if info.isText {
 		prototype := t.prototypeText
 
-		templ, err := prototype.New(info.name).Parse(info.template)
+		templ, err := t.parseText(prototype.New(info.name), info.template)
 		if err != nil {
 			return nil, err
 		}
@@ -914,7 +971,7 @@ func (t *templateNamespace) parse(info templateInfo) (*templateState, error) {
 
 	prototype := t.prototypeHTML
 
-	templ, err := prototype.New(info.name).Parse(info.template)
+	templ, err := t.parseHTML(prototype.New(info.name), info.template)
 	if err != nil {
 		return nil, err
 	}
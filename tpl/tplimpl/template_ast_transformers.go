@@ -14,6 +14,7 @@
 package tplimpl
 
 import (
+	"fmt"
 	"regexp"
 	"strings"
 
@@ -22,9 +23,7 @@ import (
 
 	"github.com/gohugoio/hugo/tpl/internal/go_templates/texttemplate/parse"
 
-	"github.com/gohugoio/hugo/common/maps"
 	"github.com/gohugoio/hugo/tpl"
-	"github.com/mitchellh/mapstructure"
 	"github.com/pkg/errors"
 )
 
@@ -100,6 +99,10 @@ func applyTemplateTransformers(
 		tree.Root = c.wrapInPartialReturnWrapper(tree.Root)
 	}
 
+	if err == nil && !c.t.parseInfo.HasReturn && len(c.t.parseInfo.Config.Returns) > 0 {
+		err = errors.Errorf("template: %s: partial declares return values, but has no return statement", t.Name())
+	}
+
 	return c, err
 }
 
@@ -206,8 +209,10 @@ func (c *templateContext) hasIdent(idents []string, ident string) bool {
 // This will be the first PipeNode in the template, and will be a variable declaration
 // on the form:
 //    {{ $_hugo_config:= `{ "version": 1 }` }}
+// The config may also declare the named parameters of the shortcode or the
+// named return values of the partial, see tpl.Param.
 func (c *templateContext) collectConfig(n *parse.PipeNode) {
-	if c.t.typ != templateShortcode {
+	if c.t.typ != templateShortcode && c.t.typ != templatePartial {
 		return
 	}
 	if c.configChecked {
@@ -233,13 +238,9 @@ func (c *templateContext) collectConfig(n *parse.PipeNode) {
 	}
 
 	if s, ok := cmd.Args[0].(*parse.StringNode); ok {
-		errMsg := "failed to decode $_hugo_config in template"
-		m, err := maps.ToStringMapE(s.Text)
-		if err != nil {
-			c.err = errors.Wrap(err, errMsg)
-			return
-		}
-		if err := mapstructure.WeakDecode(m, &c.t.parseInfo.Config); err != nil {
+		location, _ := getParseTree(c.t.Template).ErrorContext(s)
+		errMsg := fmt.Sprintf("template: %s: failed to decode $_hugo_config in template", location)
+		if err := c.t.parseInfo.Config.Decode(s.Text); err != nil {
 			c.err = errors.Wrap(err, errMsg)
 		}
 	}
@@ -322,9 +323,31 @@ func (c *templateContext) collectReturnNode(n *parse.CommandNode) bool {
 	// Remove the "return" identifiers
 	c.returnNode.Args = c.returnNode.Args[1:]
 
+	if isNamedReturnValues(c.returnNode.Args) {
+		// Multiple named return values, e.g.
+		//    {{ return "title" $title "count" 3 }}
+		// Return them as a dict.
+		dict := parse.NewIdentifier("dict").SetTree(getParseTree(c.t.Template)).SetPos(ident.Position())
+		c.returnNode.Args = append([]parse.Node{dict}, c.returnNode.Args...)
+	}
+
 	return false
 }
 
+// isNamedReturnValues reports whether args is a list of name/value pairs with
+// string literal names.
+func isNamedReturnValues(args []parse.Node) bool {
+	if len(args) < 2 || len(args)%2 != 0 {
+		return false
+	}
+	for i := 0; i < len(args); i += 2 {
+		if _, ok := args[i].(*parse.StringNode); !ok {
+			return false
+		}
+	}
+	return true
+}
+
 func findTemplateIn(name string, in tpl.Template) (tpl.Template, bool) {
 	in = unwrap(in)
 	if text, ok := in.(*texttemplate.Template); ok {
@@ -16,6 +16,7 @@ import (
 	"testing"
 
 	template "github.com/gohugoio/hugo/tpl/internal/go_templates/htmltemplate"
+	"github.com/gohugoio/hugo/tpl/internal/go_templates/texttemplate/parse"
 
 	qt "github.com/frankban/quicktest"
 	"github.com/gohugoio/hugo/tpl"
@@ -86,6 +87,7 @@ func TestCollectInfo(t *testing.T) {
 	}{
 		{"Basic Inner", `{{ .Inner }}`, tpl.ParseInfo{IsInner: true, Config: tpl.DefaultParseConfig}},
 		{"Basic config map", "{{ $_hugo_config := `" + configStr + "`  }}", tpl.ParseInfo{Config: tpl.ParseConfig{Version: 42}}},
+		{"Params", "{{ $_hugo_config := `" + `{ "params": { "width": { "type": "Int", "default": "300" } } }` + "`  }}", tpl.ParseInfo{Config: tpl.ParseConfig{Version: tpl.TemplateVersion, Params: map[string]tpl.Param{"width": {Type: "int", Default: 300}}}}},
 	}
 
 	echo := func(in interface{}) interface{} {
@@ -126,6 +128,9 @@ func TestPartialReturn(t *testing.T) {
 `, true},
 		{"Expression", `
 {{ return add 32 }}
+`, true},
+		{"Named values", `
+{{ return "a" 32 "b" "Hugo" }}
 `, true},
 	}
 
@@ -158,3 +163,19 @@ func TestPartialReturn(t *testing.T) {
 		})
 	}
 }
+
+func TestIsNamedReturnValues(t *testing.T) {
+	c := qt.New(t)
+
+	args := func(tplString string) []parse.Node {
+		templ, err := template.New("foo").Funcs(template.FuncMap{"add": func(in interface{}) interface{} { return in }}).Parse(tplString)
+		c.Assert(err, qt.IsNil)
+		return templ.Tree.Root.Nodes[0].(*parse.ActionNode).Pipe.Cmds[0].Args
+	}
+
+	c.Assert(isNamedReturnValues(args(`{{ "a" 32 "b" $ }}`)), qt.IsTrue)
+	c.Assert(isNamedReturnValues(args(`{{ "a" }}`)), qt.IsFalse)
+	c.Assert(isNamedReturnValues(args(`{{ "a" 32 "b" }}`)), qt.IsFalse)
+	c.Assert(isNamedReturnValues(args(`{{ add 32 }}`)), qt.IsFalse)
+	c.Assert(isNamedReturnValues(args(`{{ "a" 32 $ 32 }}`)), qt.IsFalse)
+}
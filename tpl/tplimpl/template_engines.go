@@ -0,0 +1,69 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tplimpl
+
+import (
+	"io"
+
+	"github.com/gohugoio/hugo/tpl"
+	texttemplate "github.com/gohugoio/hugo/tpl/internal/go_templates/texttemplate"
+	"github.com/pkg/errors"
+)
+
+// engineTemplate is a template parsed by one of the registered
+// tpl.TemplateEngine's.
+type engineTemplate struct {
+	name string
+	tpl.EngineTemplate
+}
+
+func (t *engineTemplate) Name() string {
+	return t.name
+}
+
+func (t *engineTemplate) Prepare() (*texttemplate.Template, error) {
+	return nil, errors.Errorf("template %q is not a Go template", t.name)
+}
+
+func (t *engineTemplate) execute(wr io.Writer, data interface{}, funcs map[string]interface{}) error {
+	return t.EngineTemplate.Execute(wr, data, funcs)
+}
+
+func isEngineTemplate(templ tpl.Template) bool {
+	_, ok := unwrap(templ).(*engineTemplate)
+	return ok
+}
+
+// addEngineTemplateFile parses the template file in path with engine and adds
+// it as name. These templates are not transformed and cannot be used with
+// base templates.
+func (t *templateHandler) addEngineTemplateFile(engine tpl.TemplateEngine, name, path string) error {
+	info, err := t.readTemplateFile(name, path)
+	if err != nil {
+		return err
+	}
+
+	et, err := engine.Parse(name, info.template)
+	if err != nil {
+		return info.errWithFileContext("parse failed", err)
+	}
+
+	ts := newTemplateState(&engineTemplate{name: name, EngineTemplate: et}, info)
+
+	t.main.mu.Lock()
+	t.main.templates[name] = ts
+	t.main.mu.Unlock()
+
+	return nil
+}
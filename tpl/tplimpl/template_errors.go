@@ -43,7 +43,7 @@ func (t templateInfo) resolveType() templateType {
 }
 
 func (info templateInfo) errWithFileContext(what string, err error) error {
-	err = errors.Wrapf(err, what)
+	err = herrors.WithCode(herrors.CodeTemplateParse, errors.Wrapf(err, what))
 
 	err, _ = herrors.WithFileContextForFile(
 		err,
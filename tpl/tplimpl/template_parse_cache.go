@@ -0,0 +1,162 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tplimpl
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gohugoio/hugo/helpers"
+
+	htmltemplate "github.com/gohugoio/hugo/tpl/internal/go_templates/htmltemplate"
+	texttemplate "github.com/gohugoio/hugo/tpl/internal/go_templates/texttemplate"
+	"github.com/gohugoio/hugo/tpl/internal/go_templates/texttemplate/parse"
+)
+
+// templateParseCache holds the parse trees of the templates loaded in this
+// process, keyed by a hash of the template name and source, so we can skip
+// parsing unchanged templates when the templates are reloaded on rebuilds in
+// the server and in watch mode.
+var templateParseCache = newParseCache()
+
+// parseCache is a cache of parse trees. The trees stored are never
+// modified; we hand out copies, as the AST transformers and the HTML
+// escaper modify the trees.
+//
+// To keep the memory usage in check we only keep the trees used in the
+// current and the previous template load.
+type parseCache struct {
+	mu      sync.Mutex
+	prev    map[string][]*parse.Tree
+	current map[string][]*parse.Tree
+}
+
+func newParseCache() *parseCache {
+	return &parseCache{
+		prev:    make(map[string][]*parse.Tree),
+		current: make(map[string][]*parse.Tree),
+	}
+}
+
+// rotate is called when the templates are about to be (re)loaded. Trees not
+// used since the previous call are dropped.
+func (c *parseCache) rotate() {
+	c.mu.Lock()
+	c.prev = c.current
+	c.current = make(map[string][]*parse.Tree)
+	c.mu.Unlock()
+}
+
+func (c *parseCache) get(key string) ([]*parse.Tree, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if trees, found := c.current[key]; found {
+		return trees, true
+	}
+	if trees, found := c.prev[key]; found {
+		c.current[key] = trees
+		return trees, true
+	}
+	return nil, false
+}
+
+func (c *parseCache) set(key string, trees []*parse.Tree) {
+	c.mu.Lock()
+	c.current[key] = trees
+	c.mu.Unlock()
+}
+
+// parseCacheStats holds the number of templates parsed and the number
+// fetched from the parse cache in a template load.
+type parseCacheStats struct {
+	parsed uint64
+	cached uint64
+}
+
+// funcsKey returns a key for the set of template function names. Parsing
+// validates the function names used, so trees cannot be shared between
+// different sets of functions.
+func funcsKey(funcs map[string]interface{}) string {
+	names := make([]string, 0, len(funcs))
+	for k := range funcs {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return helpers.MD5String(strings.Join(names, ","))
+}
+
+// parseTrees returns copies of the trees for the template with the given
+// name and source, one for the template itself and one for each template
+// defined in it.
+func (t *templateNamespace) parseTrees(name, text string) ([]*parse.Tree, error) {
+	key := helpers.MD5String(t.funcsKey + "\x00" + name + "\x00" + text)
+
+	trees, found := templateParseCache.get(key)
+	if found {
+		atomic.AddUint64(&t.parseStats.cached, 1)
+	} else {
+		// Parse it in a standalone template so we get the trees defined
+		// in this source only.
+		templ, err := texttemplate.New(name).Funcs(t.funcs).Parse(text)
+		if err != nil {
+			return nil, err
+		}
+		for _, tt := range templ.Templates() {
+			if tt.Tree != nil {
+				trees = append(trees, tt.Tree)
+			}
+		}
+		templateParseCache.set(key, trees)
+		atomic.AddUint64(&t.parseStats.parsed, 1)
+	}
+
+	copies := make([]*parse.Tree, len(trees))
+	for i, tree := range trees {
+		copies[i] = tree.Copy()
+	}
+
+	return copies, nil
+}
+
+// parseText is the cached equivalent of templ.Parse(text).
+func (t *templateNamespace) parseText(templ *texttemplate.Template, text string) (*texttemplate.Template, error) {
+	trees, err := t.parseTrees(templ.Name(), text)
+	if err != nil {
+		return nil, err
+	}
+	for _, tree := range trees {
+		if _, err := templ.AddParseTree(tree.Name, tree); err != nil {
+			return nil, err
+		}
+	}
+	return templ, nil
+}
+
+// parseHTML is the cached equivalent of templ.Parse(text).
+func (t *templateNamespace) parseHTML(templ *htmltemplate.Template, text string) (*htmltemplate.Template, error) {
+	trees, err := t.parseTrees(templ.Name(), text)
+	if err != nil {
+		return nil, err
+	}
+	for _, tree := range trees {
+		if _, err := templ.AddParseTree(tree.Name, tree); err != nil {
+			return nil, err
+		}
+	}
+	// AddParseTree replaces the template in the set, so
+	// look it up again.
+	return templ.Lookup(templ.Name()), nil
+}
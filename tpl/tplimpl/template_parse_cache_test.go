@@ -0,0 +1,66 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tplimpl
+
+import (
+	"bytes"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestTemplateParseCache(t *testing.T) {
+	c := qt.New(t)
+
+	const templ = `{{ define "inner" }}<b>{{ . }}</b>{{ end }}Hello {{ template "inner" . }}`
+
+	execute := func() (string, string, *parseCacheStats) {
+		d := newD(c)
+		defer d.Close()
+		h := d.Tmpl().(*templateExec)
+
+		c.Assert(h.AddTemplate("mytemplate.html", templ), qt.IsNil)
+		c.Assert(h.AddTemplate("_text/mytemplate.txt", templ), qt.IsNil)
+		c.Assert(h.postTransform(), qt.IsNil)
+
+		render := func(name string) string {
+			templ, found := d.Tmpl().Lookup(name)
+			c.Assert(found, qt.Equals, true)
+			var b bytes.Buffer
+			c.Assert(d.Tmpl().Execute(templ, &b, "<i>"), qt.IsNil)
+			return b.String()
+		}
+
+		return render("mytemplate.html"), render("mytemplate.txt"), h.main.parseStats
+	}
+
+	html1, text1, stats1 := execute()
+	c.Assert(html1, qt.Equals, "Hello <b>&lt;i&gt;</b>")
+	c.Assert(text1, qt.Equals, "Hello <b><i></b>")
+	c.Assert(stats1.parsed > 0, qt.IsTrue)
+
+	// The second load gets the trees from the cache. The HTML escaping of
+	// the first load must not leak into the second.
+	html2, text2, stats2 := execute()
+	c.Assert(html2, qt.Equals, html1)
+	c.Assert(text2, qt.Equals, text1)
+	c.Assert(stats2.parsed, qt.Equals, uint64(0))
+	c.Assert(stats2.cached, qt.Equals, stats1.parsed+stats1.cached)
+
+	// Parse errors are reported as before.
+	d := newD(c)
+	defer d.Close()
+	err := d.Tmpl().(*templateExec).AddTemplate("invalid.html", `{{ if }}`)
+	c.Assert(err, qt.ErrorMatches, `.*missing value for if.*`)
+}
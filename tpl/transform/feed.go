@@ -0,0 +1,71 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transform
+
+import (
+	"io/ioutil"
+
+	"github.com/gohugoio/hugo/common/types"
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/gohugoio/hugo/parser/feed"
+	"github.com/gohugoio/hugo/resources/resource"
+	"github.com/pkg/errors"
+)
+
+// ParseFeed parses the RSS, Atom or JSON feed in data, which can be either
+// a string or a Resource, e.g. from resources.Get, into a structure
+// common to all the feed formats.
+func (ns *Namespace) ParseFeed(data interface{}) (*feed.Feed, error) {
+	var (
+		key  string
+		read func() ([]byte, error)
+	)
+
+	if r, ok := data.(resource.UnmarshableResource); ok {
+		key = r.Key()
+		if key == "" {
+			return nil, errors.New("no Key set in Resource")
+		}
+		read = func() ([]byte, error) {
+			reader, err := r.ReadSeekCloser()
+			if err != nil {
+				return nil, err
+			}
+			defer reader.Close()
+			return ioutil.ReadAll(reader)
+		}
+	} else {
+		s, err := types.ToStringE(data)
+		if err != nil {
+			return nil, errors.Errorf("type %T not supported", data)
+		}
+		key = helpers.MD5String(s)
+		read = func() ([]byte, error) {
+			return []byte(s), nil
+		}
+	}
+
+	v, err := ns.cache.GetOrCreate("feed_"+key, func() (interface{}, error) {
+		b, err := read()
+		if err != nil {
+			return nil, err
+		}
+		return feed.Parse(b)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return v.(*feed.Feed), nil
+}
@@ -0,0 +1,52 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transform
+
+import (
+	"testing"
+
+	"github.com/gohugoio/hugo/config"
+	"github.com/gohugoio/hugo/media"
+	"github.com/gohugoio/hugo/parser/feed"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestParseFeed(t *testing.T) {
+	c := qt.New(t)
+	ns := New(newDeps(config.New()))
+
+	const rss = `<rss version="2.0"><channel><title>My Blog</title><item><title>First</title><link>https://example.org/first/</link></item></channel></rss>`
+
+	f, err := ns.ParseFeed(rss)
+	c.Assert(err, qt.IsNil)
+	c.Assert(f.Format, qt.Equals, feed.FormatRSS)
+	c.Assert(f.Title, qt.Equals, "My Blog")
+	c.Assert(f.Items, qt.HasLen, 1)
+	c.Assert(f.Items[0].Link, qt.Equals, "https://example.org/first/")
+
+	f, err = ns.ParseFeed(testContentResource{key: "r1", content: `<feed xmlns="http://www.w3.org/2005/Atom"><title>Atom</title></feed>`, mime: media.XMLType})
+	c.Assert(err, qt.IsNil)
+	c.Assert(f.Format, qt.Equals, feed.FormatAtom)
+	c.Assert(f.Title, qt.Equals, "Atom")
+
+	_, err = ns.ParseFeed(testContentResource{content: rss, mime: media.RSSType})
+	c.Assert(err, qt.ErrorMatches, "no Key set in Resource")
+
+	_, err = ns.ParseFeed("<html></html>")
+	c.Assert(err, qt.Not(qt.IsNil))
+
+	_, err = ns.ParseFeed(tstNoStringer{})
+	c.Assert(err, qt.Not(qt.IsNil))
+}
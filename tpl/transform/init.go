@@ -88,6 +88,13 @@ func init() {
 			},
 		)
 
+		ns.AddMethodMapping(ctx.ParseFeed,
+			nil,
+			[][2]string{
+				{`{{ (transform.ParseFeed "<rss><channel><title>My Blog</title></channel></rss>").Title }}`, "My Blog"},
+			},
+		)
+
 		ns.AddMethodMapping(ctx.Plainify,
 			[]string{"plainify"},
 			[][2]string{
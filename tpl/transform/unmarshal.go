@@ -31,7 +31,7 @@ import (
 )
 
 // Unmarshal unmarshals the data given, which can be either a string, json.RawMessage
-// or a Resource. Supported formats are JSON, TOML, YAML, and CSV.
+// or a Resource. Supported formats are JSON, TOML, YAML, CSV and TSV.
 // You can optionally provide an options map as the first argument.
 func (ns *Namespace) Unmarshal(args ...interface{}) (interface{}, error) {
 	if len(args) < 1 || len(args) > 2 {
@@ -65,7 +65,7 @@ func (ns *Namespace) Unmarshal(args ...interface{}) (interface{}, error) {
 			return nil, errors.New("no Key set in Resource")
 		}
 
-		if decoder != metadecoders.Default {
+		if !decoder.IsDefault() {
 			key += decoder.OptionsKey()
 		}
 
@@ -96,6 +96,9 @@ func (ns *Namespace) Unmarshal(args ...interface{}) (interface{}, error) {
 	}
 
 	key := helpers.MD5String(dataStr)
+	if !decoder.IsDefault() {
+		key += decoder.OptionsKey()
+	}
 
 	return ns.cache.GetOrCreate(key, func() (interface{}, error) {
 		f := decoder.FormatFromContentString(dataStr)
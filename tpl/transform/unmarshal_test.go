@@ -132,6 +132,10 @@ func TestUnmarshal(t *testing.T) {
 a;b;c`, mime: media.CSVType}, map[string]interface{}{"DElimiter": ";", "Comment": "%"}, func(r [][]string) {
 			c.Assert([][]string{{"a", "b", "c"}}, qt.DeepEquals, r)
 		}},
+		{testContentResource{key: "r1", content: "name\tprice\nShirt\t32.5\nHat\t", mime: media.TSVType}, map[string]interface{}{"targetType": "map", "columnTypes": map[string]interface{}{"price": "float"}}, []interface{}{
+			map[string]interface{}{"name": "Shirt", "price": 32.5},
+			map[string]interface{}{"name": "Hat", "price": nil},
+		}},
 		// errors
 		{"thisisnotavaliddataformat", nil, false},
 		{testContentResource{key: "r1", content: `invalid&toml"`, mime: media.TOMLType}, nil, false},
@@ -165,6 +169,9 @@ a;b;c`, mime: media.CSVType}, map[string]interface{}{"DElimiter": ";", "Comment"
 			r, ok := result.([][]string)
 			c.Assert(ok, qt.Equals, true)
 			fn(r)
+		} else if expect, ok := test.expect.([]interface{}); ok {
+			c.Assert(err, qt.IsNil)
+			c.Assert(result, qt.DeepEquals, expect)
 		} else {
 			c.Assert(err, qt.IsNil)
 			c.Assert(result, qt.Equals, test.expect)
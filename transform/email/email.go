@@ -0,0 +1,57 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package email provides a transformer that turns a HTML document into a HTML
+// email: documents written in MJML (https://mjml.io/) are compiled to table
+// based, responsive HTML, and the CSS is inlined into style attributes.
+package email
+
+import (
+	"regexp"
+
+	"github.com/gohugoio/hugo/transform"
+	"github.com/pkg/errors"
+)
+
+var mjmlRe = regexp.MustCompile(`(?i)<mjml[\s>]`)
+
+// New creates a transformer that renders the document as a HTML email.
+//
+// If the document has a mjml root element it's compiled to HTML first, and
+// only the styles in mj-style elements marked with inline="inline" are
+// inlined. Otherwise the rules in the document's style elements are inlined,
+// keeping the ones that can't be expressed as a style attribute (e.g. media
+// queries and pseudo classes) in the head.
+func New() transform.Transformer {
+	return func(ft transform.FromTo) error {
+		b, err := render(ft.From().Bytes())
+		if err != nil {
+			return errors.Wrap(err, "failed to render email")
+		}
+		_, err = ft.To().Write(b)
+		return err
+	}
+}
+
+func render(b []byte) ([]byte, error) {
+	if !mjmlRe.Match(b) {
+		return inline(b, nil, true)
+	}
+
+	doc, css, err := compile(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return inline(doc, css, false)
+}
@@ -0,0 +1,171 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package email
+
+import (
+	"bytes"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/transform"
+)
+
+func apply(c *qt.C, doc string) string {
+	tr := transform.New(New())
+	var out bytes.Buffer
+	c.Assert(tr.Apply(&out, bytes.NewBufferString(doc)), qt.IsNil)
+	return out.String()
+}
+
+func TestMJML(t *testing.T) {
+	c := qt.New(t)
+
+	s := apply(c, `<mjml lang="en">
+<mj-head>
+  <mj-title>My Newsletter</mj-title>
+  <mj-preview>This week</mj-preview>
+  <mj-attributes>
+    <mj-all font-family="Arial" />
+    <mj-class name="big" font-size="20px" />
+    <mj-button background-color="#ff0000" />
+  </mj-attributes>
+  <mj-style inline="inline">.highlight { color: red } p a { color: blue }</mj-style>
+  <mj-style>a:hover { color: green }</mj-style>
+</mj-head>
+<mj-body background-color="#eeeeee">
+  <mj-section background-color="#ffffff" padding="0">
+    <mj-column>
+      <mj-image src="/logo.png" alt="Logo" width="200px" href="/" />
+      <mj-text mj-class="big"><p class="highlight">Hello <a href="/posts/">there</a></p></mj-text>
+    </mj-column>
+    <mj-column width="30%">
+      <mj-button href="/posts/first/">Read more</mj-button>
+      <mj-divider border-width="1px" />
+      <mj-spacer height="10px" />
+    </mj-column>
+  </mj-section>
+</mj-body>
+</mjml>`)
+
+	c.Assert(s, qt.Contains, `<html lang="en">`)
+	c.Assert(s, qt.Contains, `<title>My Newsletter</title>`)
+	c.Assert(s, qt.Contains, `overflow:hidden;">This week</div>`)
+	c.Assert(s, qt.Contains, `<body style="word-spacing:normal;background-color:#eeeeee;">`)
+	c.Assert(s, qt.Contains, `.mj-column-per-70 { width:70% !important; max-width:70%; }`)
+	c.Assert(s, qt.Contains, `.mj-column-per-30 { width:30% !important; max-width:30%; }`)
+	c.Assert(s, qt.Contains, `a:hover { color: green }`)
+	c.Assert(s, qt.Contains, `<div class="mj-column-per-70" style="font-size:0px;text-align:left;direction:ltr;display:inline-block;vertical-align:top;width:100%;">`)
+	c.Assert(s, qt.Contains, `<td style="width:200px;"><a href="/" target="_blank"><img alt="Logo" src="/logo.png" style="border:0;display:block;outline:none;text-decoration:none;height:auto;width:100%;font-size:13px;" width="200" height="auto"/></a></td>`)
+	c.Assert(s, qt.Contains, `<div style="font-family:Arial;font-size:20px;line-height:1;text-align:left;color:#000000;"><p class="highlight" style="color:red;">Hello <a href="/posts/" style="color:blue;">there</a></p></div>`)
+	c.Assert(s, qt.Contains, `bgcolor="#ff0000"`)
+	c.Assert(s, qt.Contains, `<a href="/posts/first/" target="_blank" style="display:inline-block;background:#ff0000;color:#ffffff;font-family:Arial;`)
+	c.Assert(s, qt.Contains, `<p style="border-top:solid 1px #000000;font-size:1px;margin:0px auto;width:100%;"></p>`)
+	c.Assert(s, qt.Contains, `<div style="height:10px;line-height:10px;">`)
+	c.Assert(s, qt.Not(qt.Contains), "<mj-")
+	c.Assert(s, qt.Not(qt.Contains), ".highlight")
+
+	c.Run("Column widths", func(c *qt.C) {
+		s := apply(c, `<mjml><mj-body width="500px"><mj-section padding="0 50px">
+<mj-column width="100px"><mj-image src="a.png" /></mj-column>
+<mj-column><mj-image src="b.png" /></mj-column>
+<mj-column><mj-image src="c.png" width="1000px" /></mj-column>
+</mj-section></mj-body></mjml>`)
+
+		c.Assert(s, qt.Contains, `.mj-column-per-25 { width:25% !important; max-width:25%; }`)
+		c.Assert(s, qt.Contains, `.mj-column-per-37-5 { width:37.5% !important; max-width:37.5%; }`)
+		c.Assert(s, qt.Contains, `<td style="width:50px;"><img alt="" src="a.png"`)
+		c.Assert(s, qt.Contains, `<td style="width:100px;"><img alt="" src="c.png"`)
+	})
+
+	c.Run("Errors", func(c *qt.C) {
+		for _, test := range []struct {
+			doc    string
+			expect string
+		}{
+			{`<mjml><mj-head></mj-head></mjml>`, ".*no mj-body element found"},
+			{`<mjml><mj-body><mj-section><mj-text>Hi</mj-text></mj-section></mj-body></mjml>`, `.*unexpected element <mj-text> in mj-section`},
+			{`<mjml><mj-body><mj-section><mj-column><mj-carousel></mj-carousel></mj-column></mj-section></mj-body></mjml>`, `.*unexpected element <mj-carousel> in mj-column`},
+			{`<mjml><mj-body><div>Hi</div></mj-body></mjml>`, `.*unexpected element <div>.*`},
+			{`<mjml><mj-body><mj-section>`, `.*unclosed element <mj-section>`},
+		} {
+			var out bytes.Buffer
+			tr := transform.New(New())
+			err := tr.Apply(&out, bytes.NewBufferString(test.doc))
+			c.Assert(err, qt.ErrorMatches, "failed to render email: "+test.expect)
+		}
+	})
+}
+
+func TestInlineCSS(t *testing.T) {
+	c := qt.New(t)
+
+	s := apply(c, `<!doctype html>
+<html>
+<head>
+<style>
+/* A comment. */
+p { color: black; font-size: 14px }
+.intro { color: green }
+#first.intro { color: red }
+div > p.note { margin: 0 !important }
+.note { margin: 10px; }
+a:hover, a { text-decoration: none }
+@media (max-width: 480px) { p { font-size: 16px } }
+</style>
+<style>td { padding: 0 }</style>
+</head>
+<body>
+<p id="first" class="intro">Red</p>
+<p class="intro" style="font-size: 12px">Green</p>
+<div><p class="note" style="color: blue !important">Note</p></div>
+<a href="/">Link</a>
+</body>
+</html>`)
+
+	c.Assert(s, qt.Contains, `<p id="first" class="intro" style="color:red;font-size:14px;">Red</p>`)
+	c.Assert(s, qt.Contains, `<p class="intro" style="color:green;font-size:12px;">Green</p>`)
+	c.Assert(s, qt.Contains, `<p class="note" style="font-size:14px;margin:0;color:blue !important;">Note</p>`)
+	c.Assert(s, qt.Contains, `<a href="/" style="text-decoration:none;">Link</a>`)
+	c.Assert(s, qt.Contains, "a:hover {text-decoration: none}")
+	c.Assert(s, qt.Contains, "@media (max-width: 480px) { p { font-size: 16px } }")
+	c.Assert(s, qt.Not(qt.Contains), "A comment")
+	c.Assert(s, qt.Not(qt.Contains), "td {")
+}
+
+func TestParseSelector(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range []struct {
+		selector    string
+		ok          bool
+		specificity int
+	}{
+		{"p", true, 1},
+		{"*", true, 0},
+		{".a.b", true, 200},
+		{"div#main > p.intro a", true, 10103},
+		{"a:hover", false, 0},
+		{"input[type=text]", false, 0},
+		{"h1 + p", false, 0},
+		{"> p", false, 0},
+		{"p >", false, 0},
+		{"p##a", false, 0},
+	} {
+		sel, ok := parseSelector(test.selector)
+		c.Assert(ok, qt.Equals, test.ok, qt.Commentf(test.selector))
+		if ok {
+			c.Assert(sel.specificity(), qt.Equals, test.specificity, qt.Commentf(test.selector))
+		}
+	}
+}
@@ -0,0 +1,492 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package email
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+type declaration struct {
+	property  string
+	value     string
+	important bool
+}
+
+type rule struct {
+	selector    selector
+	specificity int
+	order       int
+	decls       []declaration
+}
+
+// compound is a simple selector sequence, e.g. p.intro#first.
+type compound struct {
+	tag     string
+	id      string
+	classes []string
+}
+
+func (c compound) match(n *html.Node) bool {
+	if c.tag != "" && c.tag != "*" && c.tag != n.Data {
+		return false
+	}
+	if c.id != "" && attr(n, "id") != c.id {
+		return false
+	}
+	if len(c.classes) > 0 {
+		classes := strings.Fields(attr(n, "class"))
+		for _, cl := range c.classes {
+			if !containsString(classes, cl) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// selector is a list of compound selectors joined by the descendant (' ') or
+// child ('>') combinators.
+type selector struct {
+	parts       []compound
+	combinators []byte
+}
+
+func (s selector) match(n *html.Node) bool {
+	return s.matchAt(len(s.parts)-1, n)
+}
+
+func (s selector) matchAt(i int, n *html.Node) bool {
+	if !s.parts[i].match(n) {
+		return false
+	}
+	if i == 0 {
+		return true
+	}
+	if s.combinators[i-1] == '>' {
+		p := parentElement(n)
+		return p != nil && s.matchAt(i-1, p)
+	}
+	for p := parentElement(n); p != nil; p = parentElement(p) {
+		if s.matchAt(i-1, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s selector) specificity() int {
+	var ids, classes, tags int
+	for _, p := range s.parts {
+		if p.id != "" {
+			ids++
+		}
+		classes += len(p.classes)
+		if p.tag != "" && p.tag != "*" {
+			tags++
+		}
+	}
+	return ids*10000 + classes*100 + tags
+}
+
+// parseSelector parses s into a selector. It returns false for
+// selectors that can't be inlined, e.g. those with pseudo classes.
+func parseSelector(s string) (selector, bool) {
+	var (
+		sel        selector
+		combinator byte = ' '
+	)
+
+	for _, f := range strings.Fields(strings.Replace(s, ">", " > ", -1)) {
+		if f == ">" {
+			if len(sel.parts) == 0 || combinator == '>' {
+				return sel, false
+			}
+			combinator = '>'
+			continue
+		}
+		c, ok := parseCompound(f)
+		if !ok {
+			return sel, false
+		}
+		if len(sel.parts) > 0 {
+			sel.combinators = append(sel.combinators, combinator)
+		}
+		sel.parts = append(sel.parts, c)
+		combinator = ' '
+	}
+
+	return sel, len(sel.parts) > 0 && combinator == ' '
+}
+
+func parseCompound(s string) (compound, bool) {
+	var c compound
+
+	i := strings.IndexAny(s, ".#")
+	if i == -1 {
+		i = len(s)
+	}
+	c.tag = strings.ToLower(s[:i])
+	if c.tag != "*" && !isIdent(c.tag) {
+		return c, false
+	}
+
+	for s = s[i:]; s != ""; {
+		kind := s[0]
+		s = s[1:]
+		i := strings.IndexAny(s, ".#")
+		if i == -1 {
+			i = len(s)
+		}
+		name := s[:i]
+		s = s[i:]
+		if name == "" || !isIdent(name) {
+			return c, false
+		}
+		if kind == '#' {
+			if c.id != "" {
+				return c, false
+			}
+			c.id = name
+		} else {
+			c.classes = append(c.classes, name)
+		}
+	}
+
+	return c, c.tag != "" || c.id != "" || len(c.classes) > 0
+}
+
+func isIdent(s string) bool {
+	for _, r := range s {
+		if !(r == '-' || r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// parseStylesheet parses the rules in css that can be inlined, starting
+// from the given order. The remaining CSS, e.g. media queries, is returned as
+// a string.
+func parseStylesheet(css string, order int) ([]rule, string) {
+	var (
+		rules []rule
+		rest  strings.Builder
+	)
+
+	s := stripComments(css)
+
+	for {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			break
+		}
+
+		if s[0] == '@' {
+			i := strings.IndexAny(s, ";{")
+			if i == -1 {
+				rest.WriteString(s)
+				break
+			}
+			end := i
+			if s[i] == '{' {
+				end = closingBrace(s, i)
+			}
+			rest.WriteString(s[:end+1])
+			rest.WriteString("\n")
+			s = s[end+1:]
+			continue
+		}
+
+		i := strings.IndexByte(s, '{')
+		if i == -1 {
+			break
+		}
+		j := strings.IndexByte(s[i:], '}')
+		if j == -1 {
+			j = len(s) - i
+		}
+		prelude, body := s[:i], s[i+1:i+j]
+		if i+j < len(s) {
+			s = s[i+j+1:]
+		} else {
+			s = ""
+		}
+
+		decls := parseDeclarations(body)
+		var keep []string
+		for _, ss := range strings.Split(prelude, ",") {
+			ss = strings.TrimSpace(ss)
+			sel, ok := parseSelector(ss)
+			if !ok {
+				if ss != "" {
+					keep = append(keep, ss)
+				}
+				continue
+			}
+			rules = append(rules, rule{selector: sel, specificity: sel.specificity(), order: order, decls: decls})
+			order++
+		}
+		if len(keep) > 0 {
+			rest.WriteString(strings.Join(keep, ", "))
+			rest.WriteString(" {")
+			rest.WriteString(strings.TrimSpace(body))
+			rest.WriteString("}\n")
+		}
+	}
+
+	return rules, strings.TrimSpace(rest.String())
+}
+
+func parseDeclarations(s string) []declaration {
+	var decls []declaration
+	for _, d := range strings.Split(s, ";") {
+		i := strings.IndexByte(d, ':')
+		if i == -1 {
+			continue
+		}
+		property := strings.ToLower(strings.TrimSpace(d[:i]))
+		value := strings.TrimSpace(d[i+1:])
+		if property == "" || value == "" {
+			continue
+		}
+		var important bool
+		if lower := strings.ToLower(value); strings.HasSuffix(lower, "!important") {
+			important = true
+			value = strings.TrimSpace(value[:len(value)-len("!important")])
+		}
+		decls = append(decls, declaration{property: property, value: value, important: important})
+	}
+	return decls
+}
+
+func stripComments(s string) string {
+	for {
+		i := strings.Index(s, "/*")
+		if i == -1 {
+			return s
+		}
+		j := strings.Index(s[i+2:], "*/")
+		if j == -1 {
+			return s[:i]
+		}
+		s = s[:i] + s[i+2+j+2:]
+	}
+}
+
+// closingBrace returns the index of the brace closing the one at start.
+func closingBrace(s string, start int) int {
+	depth := 0
+	for i := start; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(s) - 1
+}
+
+// inline applies the rules in css to the elements in the HTML document b.
+// If fromDocument is set, the rules in the document's style elements are
+// inlined as well.
+func inline(b []byte, css []string, fromDocument bool) ([]byte, error) {
+	doc, err := html.Parse(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []rule
+
+	for _, s := range css {
+		r, _ := parseStylesheet(s, len(rules))
+		rules = append(rules, r...)
+	}
+
+	if fromDocument {
+		var styles []*html.Node
+		walk(doc, func(n *html.Node) {
+			if n.DataAtom == atom.Style {
+				styles = append(styles, n)
+			}
+		})
+		for _, n := range styles {
+			var text strings.Builder
+			for c := n.FirstChild; c != nil; c = c.NextSibling {
+				text.WriteString(c.Data)
+			}
+			r, rest := parseStylesheet(text.String(), len(rules))
+			rules = append(rules, r...)
+			if rest == "" {
+				n.Parent.RemoveChild(n)
+				continue
+			}
+			for n.FirstChild != nil {
+				n.RemoveChild(n.FirstChild)
+			}
+			n.AppendChild(&html.Node{Type: html.TextNode, Data: "\n" + rest + "\n"})
+		}
+	}
+
+	if len(rules) > 0 {
+		walk(doc, func(n *html.Node) {
+			if n.DataAtom != atom.Body && !hasAncestor(n, atom.Body) {
+				return
+			}
+			applyRules(n, rules)
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := html.Render(&buf, doc); err != nil {
+		return nil, err
+	}
+	buf.WriteString("\n")
+
+	return buf.Bytes(), nil
+}
+
+func applyRules(n *html.Node, rules []rule) {
+	var matched []rule
+	for _, r := range rules {
+		if r.selector.match(n) {
+			matched = append(matched, r)
+		}
+	}
+	if len(matched) == 0 {
+		return
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		if matched[i].specificity != matched[j].specificity {
+			return matched[i].specificity < matched[j].specificity
+		}
+		return matched[i].order < matched[j].order
+	})
+
+	type inlined struct {
+		declaration
+		fromStyleAttr bool
+	}
+
+	var decls []inlined
+	set := func(d declaration, fromStyleAttr bool) {
+		for i, dd := range decls {
+			if dd.property == d.property {
+				if dd.important && !d.important {
+					return
+				}
+				if fromStyleAttr {
+					// Keep the order of the original style attribute.
+					decls = append(decls[:i], decls[i+1:]...)
+					break
+				}
+				decls[i] = inlined{d, fromStyleAttr}
+				return
+			}
+		}
+		decls = append(decls, inlined{d, fromStyleAttr})
+	}
+
+	for _, r := range matched {
+		for _, d := range r.decls {
+			set(d, false)
+		}
+	}
+
+	for _, d := range parseDeclarations(attr(n, "style")) {
+		set(d, true)
+	}
+
+	var style strings.Builder
+	for _, d := range decls {
+		style.WriteString(d.property)
+		style.WriteString(":")
+		style.WriteString(d.value)
+		// The cascade of the inlined rules is resolved here, so only keep
+		// the flag from the original style attribute.
+		if d.important && d.fromStyleAttr {
+			style.WriteString(" !important")
+		}
+		style.WriteString(";")
+	}
+
+	setAttr(n, "style", style.String())
+}
+
+func walk(n *html.Node, fn func(n *html.Node)) {
+	if n.Type == html.ElementNode {
+		fn(n)
+	}
+	for c := n.FirstChild; c != nil; {
+		// fn may remove c.
+		next := c.NextSibling
+		walk(c, fn)
+		c = next
+	}
+}
+
+func parentElement(n *html.Node) *html.Node {
+	for p := n.Parent; p != nil; p = p.Parent {
+		if p.Type == html.ElementNode {
+			return p
+		}
+	}
+	return nil
+}
+
+func hasAncestor(n *html.Node, a atom.Atom) bool {
+	for p := parentElement(n); p != nil; p = parentElement(p) {
+		if p.DataAtom == a {
+			return true
+		}
+	}
+	return false
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func setAttr(n *html.Node, key, val string) {
+	for i, a := range n.Attr {
+		if a.Key == key {
+			n.Attr[i].Val = val
+			return
+		}
+	}
+	n.Attr = append(n.Attr, html.Attribute{Key: key, Val: val})
+}
+
+func containsString(s []string, v string) bool {
+	for _, vv := range s {
+		if vv == v {
+			return true
+		}
+	}
+	return false
+}
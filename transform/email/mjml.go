@@ -0,0 +1,691 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package email
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	xhtml "golang.org/x/net/html"
+)
+
+const defaultFontFamily = "Ubuntu, Helvetica, Arial, sans-serif"
+
+// The default attribute values, as documented at
+// https://documentation.mjml.io/.
+var defaultAttributes = map[string]map[string]string{
+	"mj-body": {
+		"width": "600px",
+	},
+	"mj-section": {
+		"direction":  "ltr",
+		"padding":    "20px 0",
+		"text-align": "center",
+	},
+	"mj-column": {
+		"direction":      "ltr",
+		"vertical-align": "top",
+	},
+	"mj-text": {
+		"align":       "left",
+		"color":       "#000000",
+		"font-family": defaultFontFamily,
+		"font-size":   "13px",
+		"line-height": "1",
+		"padding":     "10px 25px",
+	},
+	"mj-image": {
+		"align":     "center",
+		"font-size": "13px",
+		"height":    "auto",
+		"padding":   "10px 25px",
+		"target":    "_blank",
+	},
+	"mj-button": {
+		"align":            "center",
+		"background-color": "#414141",
+		"border-radius":    "3px",
+		"color":            "#ffffff",
+		"font-family":      defaultFontFamily,
+		"font-size":        "13px",
+		"font-weight":      "normal",
+		"inner-padding":    "10px 25px",
+		"line-height":      "120%",
+		"padding":          "10px 25px",
+		"target":           "_blank",
+		"text-decoration":  "none",
+		"text-transform":   "none",
+		"vertical-align":   "middle",
+	},
+	"mj-divider": {
+		"align":        "center",
+		"border-color": "#000000",
+		"border-style": "solid",
+		"border-width": "4px",
+		"padding":      "10px 25px",
+		"width":        "100%",
+	},
+	"mj-spacer": {
+		"height": "20px",
+	},
+}
+
+// Elements with HTML or text content.
+var endingTags = map[string]bool{
+	"mj-button":  true,
+	"mj-preview": true,
+	"mj-raw":     true,
+	"mj-style":   true,
+	"mj-text":    true,
+	"mj-title":   true,
+}
+
+// Elements that can be used in mj-column.
+var contentElements = map[string]bool{
+	"mj-button":  true,
+	"mj-divider": true,
+	"mj-image":   true,
+	"mj-raw":     true,
+	"mj-spacer":  true,
+	"mj-text":    true,
+}
+
+const baseStyles = `#outlook a { padding:0; }
+body { margin:0;padding:0;-webkit-text-size-adjust:100%;-ms-text-size-adjust:100%; }
+table, td { border-collapse:collapse;mso-table-lspace:0pt;mso-table-rspace:0pt; }
+img { border:0;height:auto;line-height:100%;outline:none;text-decoration:none;-ms-interpolation-mode:bicubic; }
+p { display:block;margin:13px 0; }`
+
+// The breakpoint above which the columns are shown side by side.
+const breakpoint = "480px"
+
+type node struct {
+	name     string
+	attrs    map[string]string
+	children []*node
+
+	// The raw content of ending tags.
+	content string
+}
+
+func parseMJML(b []byte) (*node, error) {
+	z := xhtml.NewTokenizer(bytes.NewReader(b))
+	root := &node{}
+	stack := []*node{root}
+
+	for {
+		tt := z.Next()
+		switch tt {
+		case xhtml.ErrorToken:
+			if z.Err() != io.EOF {
+				return nil, z.Err()
+			}
+			if len(stack) > 1 {
+				return nil, errors.Errorf("unclosed element <%s>", stack[len(stack)-1].name)
+			}
+			for _, n := range root.children {
+				if n.name == "mjml" {
+					return n, nil
+				}
+			}
+			return nil, errors.New("no mjml element found")
+		case xhtml.StartTagToken, xhtml.SelfClosingTagToken:
+			tok := z.Token()
+			if !strings.HasPrefix(tok.Data, "mj") {
+				return nil, errors.Errorf("unexpected element <%s>, use it inside mj-text or mj-raw", tok.Data)
+			}
+			n := &node{name: tok.Data, attrs: make(map[string]string)}
+			for _, a := range tok.Attr {
+				n.attrs[a.Key] = a.Val
+			}
+			parent := stack[len(stack)-1]
+			parent.children = append(parent.children, n)
+			if tt == xhtml.SelfClosingTagToken {
+				continue
+			}
+			if endingTags[n.name] {
+				content, err := readContent(z, n.name)
+				if err != nil {
+					return nil, err
+				}
+				n.content = content
+				continue
+			}
+			stack = append(stack, n)
+		case xhtml.EndTagToken:
+			name, _ := z.TagName()
+			i := len(stack) - 1
+			for i > 0 && stack[i].name != string(name) {
+				i--
+			}
+			if i == 0 {
+				return nil, errors.Errorf("unexpected closing tag </%s>", name)
+			}
+			stack = stack[:i]
+		}
+	}
+}
+
+// readContent reads the raw content up to the closing tag of the element
+// with the given name.
+func readContent(z *xhtml.Tokenizer, name string) (string, error) {
+	var (
+		buf   bytes.Buffer
+		depth int
+	)
+
+	for {
+		tt := z.Next()
+		switch tt {
+		case xhtml.ErrorToken:
+			if z.Err() == io.EOF {
+				return "", errors.Errorf("unclosed element <%s>", name)
+			}
+			return "", z.Err()
+		case xhtml.StartTagToken:
+			if n, _ := z.TagName(); string(n) == name {
+				depth++
+			}
+		case xhtml.EndTagToken:
+			if n, _ := z.TagName(); string(n) == name {
+				if depth == 0 {
+					return strings.TrimSpace(buf.String()), nil
+				}
+				depth--
+			}
+		}
+		buf.Write(z.Raw())
+	}
+}
+
+// compile compiles the MJML document in b to HTML. It returns the HTML and
+// the CSS to inline.
+func compile(b []byte) ([]byte, []string, error) {
+	root, err := parseMJML(b)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	c := &compiler{
+		tagAttributes:   make(map[string]map[string]string),
+		classAttributes: make(map[string]map[string]string),
+		columnClasses:   make(map[string]string),
+	}
+
+	var body *node
+	for _, n := range root.children {
+		switch n.name {
+		case "mj-head":
+			c.head(n)
+		case "mj-body":
+			body = n
+		default:
+			return nil, nil, errors.Errorf("unexpected element <%s> in mjml", n.name)
+		}
+	}
+
+	if body == nil {
+		return nil, nil, errors.New("no mj-body element found")
+	}
+
+	if err := c.body(body, root.attrs["lang"]); err != nil {
+		return nil, nil, err
+	}
+
+	return c.buf.Bytes(), c.inlineStyles, nil
+}
+
+type compiler struct {
+	buf bytes.Buffer
+
+	title   string
+	preview string
+
+	tagAttributes   map[string]map[string]string
+	allAttributes   map[string]string
+	classAttributes map[string]map[string]string
+
+	styles       []string
+	inlineStyles []string
+
+	// Maps column class names to their width.
+	columnClasses map[string]string
+}
+
+func (c *compiler) head(head *node) {
+	for _, n := range head.children {
+		switch n.name {
+		case "mj-title":
+			c.title = n.content
+		case "mj-preview":
+			c.preview = n.content
+		case "mj-style":
+			if n.attrs["inline"] == "inline" {
+				c.inlineStyles = append(c.inlineStyles, n.content)
+			} else {
+				c.styles = append(c.styles, n.content)
+			}
+		case "mj-attributes":
+			for _, a := range n.children {
+				attrs := make(map[string]string)
+				for k, v := range a.attrs {
+					attrs[k] = v
+				}
+				switch a.name {
+				case "mj-all":
+					c.allAttributes = attrs
+				case "mj-class":
+					name := attrs["name"]
+					delete(attrs, "name")
+					c.classAttributes[name] = attrs
+				default:
+					c.tagAttributes[a.name] = attrs
+				}
+			}
+		}
+	}
+}
+
+// attr resolves the value of the attribute key on n, looking at the element
+// itself, its mj-class, the mj-attributes in the head and the defaults, in
+// that order.
+func (c *compiler) attr(n *node, key string) string {
+	if v, ok := n.attrs[key]; ok {
+		return v
+	}
+	for _, class := range strings.Fields(n.attrs["mj-class"]) {
+		if v, ok := c.classAttributes[class][key]; ok {
+			return v
+		}
+	}
+	if v, ok := c.tagAttributes[n.name][key]; ok {
+		return v
+	}
+	if v, ok := c.allAttributes[key]; ok {
+		return v
+	}
+	return defaultAttributes[n.name][key]
+}
+
+// horizontalPadding returns the left and right padding of n in pixels.
+func (c *compiler) horizontalPadding(n *node, key string) float64 {
+	_, right, _, left := parseBox(c.attr(n, key))
+	if v := c.attr(n, key+"-left"); v != "" {
+		left = parsePixels(v)
+	}
+	if v := c.attr(n, key+"-right"); v != "" {
+		right = parsePixels(v)
+	}
+	return left + right
+}
+
+// padding returns the CSS padding declarations for n.
+func (c *compiler) padding(n *node) []string {
+	var decls []string
+	decls = append(decls, "padding", c.attr(n, "padding"))
+	for _, side := range []string{"top", "right", "bottom", "left"} {
+		decls = append(decls, "padding-"+side, c.attr(n, "padding-"+side))
+	}
+	return decls
+}
+
+func (c *compiler) body(body *node, lang string) error {
+	width := parsePixels(c.attr(body, "width"))
+	bg := c.attr(body, "background-color")
+
+	var sections bytes.Buffer
+	for _, n := range body.children {
+		var err error
+		switch n.name {
+		case "mj-section":
+			err = c.section(&sections, n, width)
+		case "mj-raw":
+			sections.WriteString(n.content)
+		default:
+			err = errors.Errorf("unexpected element <%s> in mj-body", n.name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	w := &c.buf
+	w.WriteString("<!doctype html>\n")
+	if lang != "" {
+		fmt.Fprintf(w, "<html lang=\"%s\">\n", html.EscapeString(lang))
+	} else {
+		w.WriteString("<html>\n")
+	}
+	w.WriteString("<head>\n")
+	fmt.Fprintf(w, "<title>%s</title>\n", c.title)
+	w.WriteString(`<meta http-equiv="X-UA-Compatible" content="IE=edge">
+<meta http-equiv="Content-Type" content="text/html; charset=UTF-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+`)
+	fmt.Fprintf(w, "<style type=\"text/css\">\n%s\n</style>\n", baseStyles)
+
+	if len(c.columnClasses) > 0 {
+		var classes []string
+		for class := range c.columnClasses {
+			classes = append(classes, class)
+		}
+		sort.Strings(classes)
+		fmt.Fprintf(w, "<style type=\"text/css\">\n@media only screen and (min-width:%s) {\n", breakpoint)
+		for _, class := range classes {
+			width := c.columnClasses[class]
+			fmt.Fprintf(w, ".%s { width:%s !important; max-width:%s; }\n", class, width, width)
+		}
+		w.WriteString("}\n</style>\n")
+	}
+
+	for _, s := range c.styles {
+		fmt.Fprintf(w, "<style type=\"text/css\">\n%s\n</style>\n", s)
+	}
+	w.WriteString("</head>\n")
+
+	fmt.Fprintf(w, "<body style=\"%s\">\n", style("word-spacing", "normal", "background-color", bg))
+	if c.preview != "" {
+		fmt.Fprintf(w, "<div style=\"display:none;font-size:1px;color:#ffffff;line-height:1px;max-height:0px;max-width:0px;opacity:0;overflow:hidden;\">%s</div>\n", c.preview)
+	}
+	fmt.Fprintf(w, "<div style=\"%s\">\n", style("background-color", bg))
+	w.Write(sections.Bytes())
+	w.WriteString("</div>\n</body>\n</html>\n")
+
+	return nil
+}
+
+func (c *compiler) section(w io.Writer, n *node, width float64) error {
+	bg := c.attr(n, "background-color")
+	box := width - c.horizontalPadding(n, "padding")
+
+	var columns []*node
+	for _, cn := range n.children {
+		switch cn.name {
+		case "mj-column", "mj-raw":
+			columns = append(columns, cn)
+		default:
+			return errors.Errorf("unexpected element <%s> in mj-section", cn.name)
+		}
+	}
+
+	// Distribute the width not claimed by the columns with a width attribute
+	// evenly between the others.
+	var (
+		claimed float64
+		auto    int
+	)
+	for _, cn := range columns {
+		if cn.name != "mj-column" {
+			continue
+		}
+		if v := c.attr(cn, "width"); v != "" {
+			claimed += columnPercent(v, box)
+		} else {
+			auto++
+		}
+	}
+
+	fmt.Fprintf(w, "<div style=\"%s\">\n", style("background", bg, "background-color", bg, "margin", "0px auto", "max-width", px(width)))
+	fmt.Fprintf(w, "<table align=\"center\" border=\"0\" cellpadding=\"0\" cellspacing=\"0\" role=\"presentation\" style=\"%s\">\n", style("background", bg, "background-color", bg, "width", "100%"))
+	fmt.Fprintf(w, "<tbody><tr><td style=\"%s\">\n", style(append([]string{"direction", c.attr(n, "direction"), "font-size", "0px"}, append(c.padding(n), "text-align", c.attr(n, "text-align"))...)...))
+
+	for _, cn := range columns {
+		if cn.name == "mj-raw" {
+			io.WriteString(w, cn.content)
+			continue
+		}
+		percent := (100 - claimed) / float64(auto)
+		if v := c.attr(cn, "width"); v != "" {
+			percent = columnPercent(v, box)
+		}
+		if err := c.column(w, cn, percent, box*percent/100); err != nil {
+			return err
+		}
+	}
+
+	io.WriteString(w, "</td></tr></tbody>\n</table>\n</div>\n")
+
+	return nil
+}
+
+func (c *compiler) column(w io.Writer, n *node, percent, width float64) error {
+	percent = math.Round(percent*100) / 100
+	class := "mj-column-per-" + strings.Replace(strconv.FormatFloat(percent, 'f', -1, 64), ".", "-", 1)
+	c.columnClasses[class] = strconv.FormatFloat(percent, 'f', -1, 64) + "%"
+
+	fmt.Fprintf(w, "<div class=\"%s\" style=\"%s\">\n", class, style("font-size", "0px", "text-align", "left", "direction", c.attr(n, "direction"), "display", "inline-block", "vertical-align", c.attr(n, "vertical-align"), "width", "100%"))
+
+	tableStyle := style("background-color", c.attr(n, "background-color"), "vertical-align", c.attr(n, "vertical-align"))
+	hasPadding := c.attr(n, "padding") != ""
+	if hasPadding {
+		width -= c.horizontalPadding(n, "padding")
+		fmt.Fprintf(w, "<table border=\"0\" cellpadding=\"0\" cellspacing=\"0\" role=\"presentation\" width=\"100%%\"><tbody><tr><td style=\"%s\">\n", style(append(c.padding(n), "vertical-align", c.attr(n, "vertical-align"))...))
+	}
+	fmt.Fprintf(w, "<table border=\"0\" cellpadding=\"0\" cellspacing=\"0\" role=\"presentation\" style=\"%s\" width=\"100%%\"><tbody>\n", tableStyle)
+
+	for _, cn := range n.children {
+		if !contentElements[cn.name] {
+			return errors.Errorf("unexpected element <%s> in mj-column", cn.name)
+		}
+		if cn.name == "mj-raw" {
+			fmt.Fprintf(w, "<tr><td>%s</td></tr>\n", cn.content)
+			continue
+		}
+		padding := c.padding(cn)
+		if cn.name == "mj-spacer" {
+			padding = nil
+		}
+		io.WriteString(w, "<tr><td")
+		if align := c.attr(cn, "align"); align != "" {
+			fmt.Fprintf(w, " align=\"%s\"", html.EscapeString(align))
+		}
+		fmt.Fprintf(w, " style=\"%s\">", style(append(append([]string{"background", c.attr(cn, "container-background-color"), "font-size", "0px"}, padding...), "word-break", "break-word")...))
+		switch cn.name {
+		case "mj-text":
+			c.text(w, cn)
+		case "mj-image":
+			c.image(w, cn, width-c.horizontalPadding(cn, "padding"))
+		case "mj-button":
+			c.button(w, cn)
+		case "mj-divider":
+			c.divider(w, cn)
+		case "mj-spacer":
+			fmt.Fprintf(w, "<div style=\"%s\">&#8202;</div>", style("height", c.attr(cn, "height"), "line-height", c.attr(cn, "height")))
+		}
+		io.WriteString(w, "</td></tr>\n")
+	}
+
+	io.WriteString(w, "</tbody></table>\n")
+	if hasPadding {
+		io.WriteString(w, "</td></tr></tbody></table>\n")
+	}
+	io.WriteString(w, "</div>\n")
+
+	return nil
+}
+
+func (c *compiler) text(w io.Writer, n *node) {
+	fmt.Fprintf(w, "<div style=\"%s\">%s</div>", style(
+		"font-family", c.attr(n, "font-family"),
+		"font-size", c.attr(n, "font-size"),
+		"font-style", c.attr(n, "font-style"),
+		"font-weight", c.attr(n, "font-weight"),
+		"letter-spacing", c.attr(n, "letter-spacing"),
+		"line-height", c.attr(n, "line-height"),
+		"text-align", c.attr(n, "align"),
+		"text-decoration", c.attr(n, "text-decoration"),
+		"text-transform", c.attr(n, "text-transform"),
+		"color", c.attr(n, "color"),
+	), n.content)
+}
+
+func (c *compiler) image(w io.Writer, n *node, maxWidth float64) {
+	width := maxWidth
+	if v := c.attr(n, "width"); v != "" {
+		width = math.Min(parsePixels(v), maxWidth)
+	}
+	height := c.attr(n, "height")
+	heightAttr := height
+	if height != "auto" {
+		heightAttr = strconv.FormatFloat(parsePixels(height), 'f', -1, 64)
+	}
+
+	fmt.Fprintf(w, "<table border=\"0\" cellpadding=\"0\" cellspacing=\"0\" role=\"presentation\" style=\"border-collapse:collapse;border-spacing:0px;\"><tbody><tr><td style=\"%s\">", style("width", px(width)))
+
+	href := c.attr(n, "href")
+	if href != "" {
+		fmt.Fprintf(w, "<a href=\"%s\" target=\"%s\">", html.EscapeString(href), html.EscapeString(c.attr(n, "target")))
+	}
+	fmt.Fprintf(w, "<img alt=\"%s\" src=\"%s\"", html.EscapeString(c.attr(n, "alt")), html.EscapeString(c.attr(n, "src")))
+	if title := c.attr(n, "title"); title != "" {
+		fmt.Fprintf(w, " title=\"%s\"", html.EscapeString(title))
+	}
+	fmt.Fprintf(w, " style=\"%s\" width=\"%s\" height=\"%s\">", style(
+		"border", "0",
+		"border-radius", c.attr(n, "border-radius"),
+		"display", "block",
+		"outline", "none",
+		"text-decoration", "none",
+		"height", height,
+		"width", "100%",
+		"font-size", c.attr(n, "font-size"),
+	), strconv.FormatFloat(math.Round(width), 'f', -1, 64), html.EscapeString(heightAttr))
+	if href != "" {
+		io.WriteString(w, "</a>")
+	}
+
+	io.WriteString(w, "</td></tr></tbody></table>")
+}
+
+func (c *compiler) button(w io.Writer, n *node) {
+	bg := c.attr(n, "background-color")
+	innerPadding := c.attr(n, "inner-padding")
+	radius := c.attr(n, "border-radius")
+
+	fmt.Fprintf(w, "<table border=\"0\" cellpadding=\"0\" cellspacing=\"0\" role=\"presentation\" style=\"border-collapse:separate;line-height:100%%;\"><tbody><tr><td align=\"center\" bgcolor=\"%s\" role=\"presentation\" style=\"%s\" valign=\"%s\">",
+		html.EscapeString(bg),
+		style("border", c.attr(n, "border"), "border-radius", radius, "cursor", "auto", "mso-padding-alt", innerPadding, "background", bg),
+		html.EscapeString(c.attr(n, "vertical-align")),
+	)
+
+	tag := "p"
+	href := c.attr(n, "href")
+	if href != "" {
+		tag = "a"
+	}
+	fmt.Fprintf(w, "<%s", tag)
+	if href != "" {
+		fmt.Fprintf(w, " href=\"%s\" target=\"%s\"", html.EscapeString(href), html.EscapeString(c.attr(n, "target")))
+	}
+	fmt.Fprintf(w, " style=\"%s\">%s</%s>", style(
+		"display", "inline-block",
+		"background", bg,
+		"color", c.attr(n, "color"),
+		"font-family", c.attr(n, "font-family"),
+		"font-size", c.attr(n, "font-size"),
+		"font-style", c.attr(n, "font-style"),
+		"font-weight", c.attr(n, "font-weight"),
+		"line-height", c.attr(n, "line-height"),
+		"letter-spacing", c.attr(n, "letter-spacing"),
+		"margin", "0",
+		"text-decoration", c.attr(n, "text-decoration"),
+		"text-transform", c.attr(n, "text-transform"),
+		"padding", innerPadding,
+		"mso-padding-alt", "0px",
+		"border-radius", radius,
+	), n.content, tag)
+
+	io.WriteString(w, "</td></tr></tbody></table>")
+}
+
+func (c *compiler) divider(w io.Writer, n *node) {
+	border := c.attr(n, "border-style") + " " + c.attr(n, "border-width") + " " + c.attr(n, "border-color")
+	fmt.Fprintf(w, "<p style=\"%s\"></p>", style("border-top", border, "font-size", "1px", "margin", "0px auto", "width", c.attr(n, "width")))
+}
+
+// style creates a style attribute value from the given property and value
+// pairs, skipping empty values.
+func style(pairs ...string) string {
+	var sb strings.Builder
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if pairs[i+1] == "" {
+			continue
+		}
+		sb.WriteString(pairs[i])
+		sb.WriteString(":")
+		sb.WriteString(pairs[i+1])
+		sb.WriteString(";")
+	}
+	return html.EscapeString(sb.String())
+}
+
+func px(v float64) string {
+	return strconv.FormatFloat(math.Round(v), 'f', -1, 64) + "px"
+}
+
+// parsePixels parses a CSS length in pixels, e.g. "25px". Other units
+// are not supported and parsed as 0.
+func parsePixels(s string) float64 {
+	s = strings.TrimSpace(s)
+	if s == "0" {
+		return 0
+	}
+	if !strings.HasSuffix(s, "px") {
+		return 0
+	}
+	f, _ := strconv.ParseFloat(strings.TrimSuffix(s, "px"), 64)
+	return f
+}
+
+// parseBox parses a CSS shorthand for the four sides of a box.
+func parseBox(s string) (top, right, bottom, left float64) {
+	f := strings.Fields(s)
+	var v [4]float64
+	for i, ff := range f {
+		if i < 4 {
+			v[i] = parsePixels(ff)
+		}
+	}
+	switch len(f) {
+	case 0:
+		return
+	case 1:
+		return v[0], v[0], v[0], v[0]
+	case 2:
+		return v[0], v[1], v[0], v[1]
+	case 3:
+		return v[0], v[1], v[2], v[1]
+	default:
+		return v[0], v[1], v[2], v[3]
+	}
+}
+
+// columnPercent returns the width of a column in percent of box.
+func columnPercent(width string, box float64) float64 {
+	if strings.HasSuffix(width, "%") {
+		f, _ := strconv.ParseFloat(strings.TrimSuffix(width, "%"), 64)
+		return f
+	}
+	if box <= 0 {
+		return 0
+	}
+	return parsePixels(width) / box * 100
+}
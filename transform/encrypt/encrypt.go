@@ -0,0 +1,201 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package encrypt provides a transformer that replaces a HTML document with
+// an encrypted copy of itself, wrapped in a small page that decrypts it in
+// the browser.
+package encrypt
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"html/template"
+	"io"
+
+	"github.com/gohugoio/hugo/transform"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	// The number of PBKDF2 iterations used to derive the key from the password.
+	iterations = 100000
+
+	keyLen  = 32
+	saltLen = 16
+)
+
+// payload is what's needed by the browser to decrypt the document.
+type payload struct {
+	Salt       string `json:"salt"`
+	IV         string `json:"iv"`
+	Iterations int    `json:"iterations"`
+	Data       string `json:"data"`
+}
+
+// New creates a transformer that encrypts the document with AES-GCM, using a
+// key derived from password with PBKDF2 (SHA-256). The result is a HTML page
+// with a password form that decrypts and shows the original document using
+// the Web Crypto API.
+func New(password string) transform.Transformer {
+	return newTransformer(password, func([]byte) io.Reader { return rand.Reader })
+}
+
+// NewReproducible is like New, but the salt and IV are derived from the
+// password, seed and the document instead of being random, so the same
+// document is encrypted the same way in every build.
+func NewReproducible(password, seed string) transform.Transformer {
+	return newTransformer(password, func(b []byte) io.Reader {
+		return reproducibleRandom(password, seed, b)
+	})
+}
+
+func newTransformer(password string, random func(b []byte) io.Reader) transform.Transformer {
+	return func(ft transform.FromTo) error {
+		b := ft.From().Bytes()
+		p, err := encrypt(password, b, random(b))
+		if err != nil {
+			return errors.Wrap(err, "failed to encrypt page")
+		}
+		return wrapperTempl.Execute(ft.To(), p)
+	}
+}
+
+// reproducibleRandom returns the bytes to read the salt and IV from when
+// encrypting b reproducibly. They're keyed with the password, so they reveal
+// nothing about the document to anyone without it, and they change with the
+// document, so an IV is never reused for another document.
+func reproducibleRandom(password, seed string, b []byte) io.Reader {
+	mac := hmac.New(sha256.New, []byte(password))
+	mac.Write([]byte(seed))
+	mac.Write([]byte{0})
+	mac.Write(b)
+	return bytes.NewReader(mac.Sum(nil))
+}
+
+func encrypt(password string, b []byte, random io.Reader) (payload, error) {
+	salt := make([]byte, saltLen)
+	if _, err := io.ReadFull(random, salt); err != nil {
+		return payload{}, err
+	}
+
+	gcm, err := newGCM(password, salt, iterations)
+	if err != nil {
+		return payload{}, err
+	}
+
+	iv := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(random, iv); err != nil {
+		return payload{}, err
+	}
+
+	enc := base64.StdEncoding.EncodeToString
+
+	return payload{
+		Salt:       enc(salt),
+		IV:         enc(iv),
+		Iterations: iterations,
+		Data:       enc(gcm.Seal(nil, iv, b, nil)),
+	}, nil
+}
+
+func decrypt(password string, p payload) ([]byte, error) {
+	dec := base64.StdEncoding.DecodeString
+
+	salt, err := dec(p.Salt)
+	if err != nil {
+		return nil, err
+	}
+	iv, err := dec(p.IV)
+	if err != nil {
+		return nil, err
+	}
+	data, err := dec(p.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(password, salt, p.Iterations)
+	if err != nil {
+		return nil, err
+	}
+
+	return gcm.Open(nil, iv, data, nil)
+}
+
+func newGCM(password string, salt []byte, iter int) (cipher.AEAD, error) {
+	key := pbkdf2.Key([]byte(password), salt, iter, keyLen, sha256.New)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (p payload) JSON() (template.JS, error) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(p); err != nil {
+		return "", err
+	}
+	return template.JS(bytes.TrimSpace(buf.Bytes())), nil
+}
+
+// The decryption replaces the whole document, so scripts, styles etc. in the
+// original page work as before.
+var wrapperTempl = template.Must(template.New("").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>Protected page</title>
+</head>
+<body>
+<form id="hugo-protected">
+<p><label for="hugo-password">This page is password protected.</label></p>
+<p><input type="password" id="hugo-password" autocomplete="current-password" autofocus required> <button type="submit">Unlock</button></p>
+<p id="hugo-protected-error" hidden>Wrong password.</p>
+</form>
+<script>
+(function() {
+  var p = {{ .JSON }};
+  var decode = function(s) {
+    return Uint8Array.from(atob(s), function(c) { return c.charCodeAt(0); });
+  };
+  document.getElementById("hugo-protected").addEventListener("submit", function(e) {
+    e.preventDefault();
+    var subtle = window.crypto.subtle;
+    var password = new TextEncoder().encode(document.getElementById("hugo-password").value);
+    subtle.importKey("raw", password, "PBKDF2", false, ["deriveKey"]).then(function(k) {
+      return subtle.deriveKey({ name: "PBKDF2", salt: decode(p.salt), iterations: p.iterations, hash: "SHA-256" }, k, { name: "AES-GCM", length: 256 }, false, ["decrypt"]);
+    }).then(function(k) {
+      return subtle.decrypt({ name: "AES-GCM", iv: decode(p.iv) }, k, decode(p.data));
+    }).then(function(b) {
+      document.open();
+      document.write(new TextDecoder().decode(b));
+      document.close();
+    }).catch(function() {
+      document.getElementById("hugo-protected-error").hidden = false;
+    });
+  });
+})();
+</script>
+</body>
+</html>
+`))
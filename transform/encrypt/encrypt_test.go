@@ -0,0 +1,87 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encrypt
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"regexp"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/transform"
+)
+
+func TestEncrypt(t *testing.T) {
+	c := qt.New(t)
+
+	const doc = "<html><head><title>Secret</title></head><body><p>My secret.</p></body></html>"
+
+	tr := transform.New(New("s3cr3t"))
+	var out bytes.Buffer
+	c.Assert(tr.Apply(&out, bytes.NewBufferString(doc)), qt.IsNil)
+
+	s := out.String()
+	c.Assert(s, qt.Not(qt.Contains), "secret")
+	c.Assert(s, qt.Contains, `<form id="hugo-protected">`)
+
+	m := regexp.MustCompile(`var p = (\{.*\});`).FindStringSubmatch(s)
+	c.Assert(m, qt.HasLen, 2)
+	var p payload
+	c.Assert(json.Unmarshal([]byte(m[1]), &p), qt.IsNil)
+	c.Assert(p.Iterations, qt.Equals, iterations)
+
+	b, err := decrypt("s3cr3t", p)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, doc)
+
+	_, err = decrypt("wrong", p)
+	c.Assert(err, qt.Not(qt.IsNil))
+
+	// Same input, new salt and IV.
+	p2, err := encrypt("s3cr3t", []byte(doc), rand.Reader)
+	c.Assert(err, qt.IsNil)
+	c.Assert(p2.Salt, qt.Not(qt.Equals), p.Salt)
+	c.Assert(p2.Data, qt.Not(qt.Equals), p.Data)
+}
+
+func TestEncryptReproducible(t *testing.T) {
+	c := qt.New(t)
+
+	const doc = "<html><body><p>My secret.</p></body></html>"
+
+	apply := func(seed, doc string) string {
+		tr := transform.New(NewReproducible("s3cr3t", seed))
+		var out bytes.Buffer
+		c.Assert(tr.Apply(&out, bytes.NewBufferString(doc)), qt.IsNil)
+		return out.String()
+	}
+
+	out := apply("/secret/index.html", doc)
+	c.Assert(out, qt.Not(qt.Contains), "secret.")
+	c.Assert(apply("/secret/index.html", doc), qt.Equals, out)
+	c.Assert(apply("/other/index.html", doc), qt.Not(qt.Equals), out)
+
+	// Different documents must not share the IV.
+	p1, err := encrypt("s3cr3t", []byte(doc), reproducibleRandom("s3cr3t", "seed", []byte(doc)))
+	c.Assert(err, qt.IsNil)
+	p2, err := encrypt("s3cr3t", []byte(doc+" "), reproducibleRandom("s3cr3t", "seed", []byte(doc+" ")))
+	c.Assert(err, qt.IsNil)
+	c.Assert(p2.IV, qt.Not(qt.Equals), p1.IV)
+
+	b, err := decrypt("s3cr3t", p1)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, doc)
+}
@@ -0,0 +1,109 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package htmlpostprocess
+
+import (
+	"github.com/gohugoio/hugo/config"
+	"github.com/gohugoio/hugo/config/configschema"
+	"github.com/gohugoio/hugo/hugofs/glob"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+	"github.com/spf13/cast"
+)
+
+const htmlPostProcessConfigKey = "htmlPostProcess"
+
+// Step configures a step in the post-processing of the published HTML. One
+// of Selector, Transformer or Plugin must be set.
+type Step struct {
+	// A CSS selector for the elements to change, e.g. "a[href^='https://']".
+	Selector string
+
+	// The attributes to set on the elements matching Selector.
+	Attributes map[string]string
+
+	// Whether to overwrite attributes already set. By default only missing
+	// attributes are added.
+	Overwrite bool
+
+	// The attributes to remove from the elements matching Selector.
+	RemoveAttributes []string
+
+	// The name of a transformer registered with Register.
+	Transformer string
+
+	// The name of a plugin implementing HTML transformations.
+	Plugin string
+
+	// Passed on to the transformer or plugin.
+	Params map[string]interface{}
+
+	// Glob patterns matching the paths of the published files to process,
+	// e.g. "posts/**". All HTML files if not set.
+	Paths []string
+}
+
+// DecodeConfig decodes the htmlPostProcess section in the site
+// configuration.
+func DecodeConfig(cfg config.Provider) ([]Step, error) {
+	if !cfg.IsSet(htmlPostProcessConfigKey) {
+		return nil, nil
+	}
+
+	var steps []Step
+
+	for i, v := range cast.ToSlice(cfg.Get(htmlPostProcessConfigKey)) {
+		var s Step
+		if err := mapstructure.WeakDecode(v, &s); err != nil {
+			return nil, errors.Wrap(err, "failed to decode htmlPostProcess config")
+		}
+
+		var set int
+		for _, v := range []string{s.Selector, s.Transformer, s.Plugin} {
+			if v != "" {
+				set++
+			}
+		}
+		if set != 1 {
+			return nil, errors.Errorf("htmlPostProcess: step %d: one of selector, transformer or plugin must be set", i+1)
+		}
+
+		if s.Selector != "" {
+			if _, err := parseSelector(s.Selector); err != nil {
+				return nil, errors.Wrapf(err, "htmlPostProcess: step %d", i+1)
+			}
+			if len(s.Attributes) == 0 && len(s.RemoveAttributes) == 0 {
+				return nil, errors.Errorf("htmlPostProcess: step %d: attributes or removeAttributes must be set with a selector", i+1)
+			}
+		}
+
+		if s.Transformer != "" && getTransformer(s.Transformer) == nil {
+			return nil, errors.Errorf("htmlPostProcess: step %d: transformer %q not found", i+1, s.Transformer)
+		}
+
+		for _, pattern := range s.Paths {
+			if _, err := glob.GetGlob(pattern); err != nil {
+				return nil, errors.Wrapf(err, "htmlPostProcess: step %d: invalid path pattern %q", i+1, pattern)
+			}
+		}
+
+		steps = append(steps, s)
+	}
+
+	return steps, nil
+}
+
+func init() {
+	configschema.AddSection(htmlPostProcessConfigKey, []Step{{}})
+}
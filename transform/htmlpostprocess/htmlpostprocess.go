@@ -0,0 +1,282 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package htmlpostprocess runs the configured post-processing steps on the
+// published HTML, e.g. adding attributes to the elements matching a CSS
+// selector.
+package htmlpostprocess
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"sync"
+
+	bp "github.com/gohugoio/hugo/bufferpool"
+	hglob "github.com/gohugoio/hugo/hugofs/glob"
+	"github.com/gohugoio/hugo/plugins"
+
+	"github.com/gobwas/glob"
+	"github.com/pkg/errors"
+	"golang.org/x/net/html"
+)
+
+// Context is passed to the transformers.
+type Context struct {
+	// The path of the published file, e.g. "posts/my-post/index.html".
+	Path string
+
+	// The params from the step config.
+	Params map[string]interface{}
+}
+
+// Transformer transforms the HTML of a published file.
+type Transformer func(ctx Context, src []byte) ([]byte, error)
+
+var (
+	transformersMu sync.RWMutex
+	transformers   = make(map[string]Transformer)
+)
+
+// Register registers t as the transformer with the given name, to be used
+// with transformer = name in the htmlPostProcess config. This is meant to be
+// called from an init func in custom builds of Hugo.
+func Register(name string, t Transformer) {
+	transformersMu.Lock()
+	defer transformersMu.Unlock()
+	transformers[name] = t
+}
+
+func getTransformer(name string) Transformer {
+	transformersMu.RLock()
+	defer transformersMu.RUnlock()
+	return transformers[name]
+}
+
+// Processor runs the post-processing steps.
+type Processor struct {
+	steps []step
+}
+
+type step struct {
+	Step
+	paths       []glob.Glob
+	sel         selector
+	transformer Transformer
+	plugin      *plugins.Plugin
+}
+
+// New creates a Processor for the given steps, nil if there are none. Any
+// plugins referred to must be loaded in p.
+func New(steps []Step, p *plugins.Plugins) (*Processor, error) {
+	if len(steps) == 0 {
+		return nil, nil
+	}
+
+	proc := &Processor{}
+	for i, s := range steps {
+		st := step{Step: s}
+		for _, pattern := range s.Paths {
+			g, err := hglob.GetGlob(pattern)
+			if err != nil {
+				return nil, errors.Wrapf(err, "htmlPostProcess: step %d: invalid path pattern %q", i+1, pattern)
+			}
+			st.paths = append(st.paths, g)
+		}
+
+		switch {
+		case s.Selector != "":
+			sel, err := parseSelector(s.Selector)
+			if err != nil {
+				return nil, errors.Wrapf(err, "htmlPostProcess: step %d", i+1)
+			}
+			st.sel = sel
+		case s.Transformer != "":
+			if st.transformer = getTransformer(s.Transformer); st.transformer == nil {
+				return nil, errors.Errorf("htmlPostProcess: step %d: transformer %q not found", i+1, s.Transformer)
+			}
+		case s.Plugin != "":
+			if st.plugin = p.Get(s.Plugin); st.plugin == nil {
+				return nil, errors.Errorf("htmlPostProcess: step %d: plugin %q not found", i+1, s.Plugin)
+			}
+			if !st.plugin.Has(plugins.KindHTML) {
+				return nil, errors.Errorf("htmlPostProcess: step %d: plugin %q does not export hugo_transform_html", i+1, s.Plugin)
+			}
+		}
+
+		proc.steps = append(proc.steps, st)
+	}
+
+	return proc, nil
+}
+
+// Process runs the steps matching path, the path of the published file
+// relative to the publish directory, on src.
+func (p *Processor) Process(path string, src []byte) ([]byte, error) {
+	if p == nil {
+		return src, nil
+	}
+
+	var err error
+	for i, s := range p.steps {
+		if !s.matchPath(path) {
+			continue
+		}
+
+		switch {
+		case s.sel != nil:
+			src, err = s.setAttributes(src)
+		case s.transformer != nil:
+			src, err = s.transformer(Context{Path: path, Params: s.Params}, src)
+			err = errors.Wrapf(err, "transformer %q", s.Transformer)
+		case s.plugin != nil:
+			src, err = s.plugin.Transform(plugins.Context{
+				Kind:      plugins.KindHTML,
+				Path:      path,
+				MediaType: "text/html",
+				Params:    s.Params,
+			}, src)
+		}
+
+		if err != nil {
+			return nil, errors.Wrapf(err, "htmlPostProcess: step %d: %s", i+1, path)
+		}
+	}
+
+	return src, nil
+}
+
+func (s step) matchPath(path string) bool {
+	if len(s.paths) == 0 {
+		return true
+	}
+	for _, g := range s.paths {
+		if g.Match(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// setAttributes sets and removes the configured attributes on the elements
+// matching the selector. Everything else is passed through unchanged.
+func (s step) setAttributes(src []byte) ([]byte, error) {
+	b := bp.GetBuffer()
+	defer bp.PutBuffer(b)
+
+	z := html.NewTokenizer(bytes.NewReader(src))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			b.Write(z.Raw())
+			if z.Err() == io.EOF {
+				break
+			}
+			return nil, z.Err()
+		}
+
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			b.Write(z.Raw())
+			continue
+		}
+
+		// Token lower cases the tag name in the buffer shared with Raw.
+		raw := append([]byte(nil), z.Raw()...)
+		tok := z.Token()
+		if !s.sel.match(tok.Data, tok.Attr) {
+			b.Write(raw)
+			continue
+		}
+		b.Write(s.rewriteTag(tt, raw, tok))
+	}
+
+	return append([]byte(nil), b.Bytes()...), nil
+}
+
+// rewriteTag returns the tag raw with the attributes changed. The tag is
+// kept as-is where possible.
+func (s step) rewriteTag(tt html.TokenType, raw []byte, tok html.Token) []byte {
+	var (
+		changed bool
+		attrs   = make([]html.Attribute, 0, len(tok.Attr)+len(s.Attributes))
+		added   []html.Attribute
+	)
+
+	for _, a := range tok.Attr {
+		if a.Namespace == "" && containsString(s.RemoveAttributes, a.Key) {
+			changed = true
+			continue
+		}
+		if v, found := s.Attributes[a.Key]; found && a.Namespace == "" && s.Overwrite && v != a.Val {
+			a.Val = v
+			changed = true
+		}
+		attrs = append(attrs, a)
+	}
+
+	keys := make([]string, 0, len(s.Attributes))
+	for k := range s.Attributes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if _, found := getAttr(attrs, k); !found && !containsString(s.RemoveAttributes, k) {
+			added = append(added, html.Attribute{Key: k, Val: s.Attributes[k]})
+		}
+	}
+
+	if !changed && len(added) == 0 {
+		return raw
+	}
+
+	var b bytes.Buffer
+	if !changed {
+		// Only new attributes, insert them before the end of the tag.
+		end := len(raw) - 1
+		if tt == html.SelfClosingTagToken && end > 0 && raw[end-1] == '/' {
+			end--
+			for end > 0 && isSpace(raw[end-1]) {
+				end--
+			}
+		}
+		b.Write(raw[:end])
+		writeAttrs(&b, added)
+		b.Write(raw[end:])
+		return b.Bytes()
+	}
+
+	b.WriteByte('<')
+	b.WriteString(tok.Data)
+	writeAttrs(&b, append(attrs, added...))
+	if tt == html.SelfClosingTagToken {
+		b.WriteString(" />")
+	} else {
+		b.WriteByte('>')
+	}
+
+	return b.Bytes()
+}
+
+func writeAttrs(b *bytes.Buffer, attrs []html.Attribute) {
+	for _, a := range attrs {
+		b.WriteByte(' ')
+		if a.Namespace != "" {
+			b.WriteString(a.Namespace)
+			b.WriteByte(':')
+		}
+		b.WriteString(a.Key)
+		b.WriteString(`="`)
+		b.WriteString(html.EscapeString(a.Val))
+		b.WriteByte('"')
+	}
+}
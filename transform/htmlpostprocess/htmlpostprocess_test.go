@@ -0,0 +1,206 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package htmlpostprocess
+
+import (
+	"bytes"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/config"
+	"github.com/gohugoio/hugo/plugins"
+	"github.com/gohugoio/hugo/plugins/pluginstest"
+	"github.com/spf13/afero"
+)
+
+func init() {
+	Register("reverse-title", func(ctx Context, src []byte) ([]byte, error) {
+		suffix, _ := ctx.Params["suffix"].(string)
+		return bytes.Replace(src, []byte("<title>Home</title>"), []byte("<title>emoH"+suffix+"</title>"), 1), nil
+	})
+}
+
+func newTestProcessor(c *qt.C, cfgStr string, p *plugins.Plugins) (*Processor, error) {
+	cfg, err := config.FromConfigString(cfgStr, "toml")
+	c.Assert(err, qt.IsNil)
+	steps, err := DecodeConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return New(steps, p)
+}
+
+func TestDecodeConfig(t *testing.T) {
+	c := qt.New(t)
+
+	cfg, err := config.FromConfigString(`
+[[htmlPostProcess]]
+selector = "img"
+overwrite = true
+paths = ["posts/**"]
+[htmlPostProcess.attributes]
+loading = "lazy"
+[[htmlPostProcess]]
+transformer = "reverse-title"
+`, "toml")
+	c.Assert(err, qt.IsNil)
+	steps, err := DecodeConfig(cfg)
+	c.Assert(err, qt.IsNil)
+	c.Assert(steps, qt.HasLen, 2)
+	c.Assert(steps[0].Attributes, qt.DeepEquals, map[string]string{"loading": "lazy"})
+	c.Assert(steps[0].Overwrite, qt.IsTrue)
+	c.Assert(steps[0].Paths, qt.DeepEquals, []string{"posts/**"})
+	c.Assert(steps[1].Transformer, qt.Equals, "reverse-title")
+
+	steps, err = DecodeConfig(config.New())
+	c.Assert(err, qt.IsNil)
+	c.Assert(steps, qt.IsNil)
+
+	for _, test := range []struct {
+		cfg    string
+		expect string
+	}{
+		{`selector = "img"`, `.*attributes or removeAttributes must be set.*`},
+		{`selector = "ul li"
+removeAttributes = ["id"]`, `.*combinators are not supported.*`},
+		{`paths = ["**"]`, `.*one of selector, transformer or plugin must be set`},
+		{`selector = "img"
+plugin = "foo"`, `.*one of selector, transformer or plugin must be set`},
+		{`transformer = "foo"`, `.*transformer "foo" not found`},
+	} {
+		cfg, err := config.FromConfigString("[[htmlPostProcess]]\n"+test.cfg, "toml")
+		c.Assert(err, qt.IsNil)
+		_, err = DecodeConfig(cfg)
+		c.Assert(err, qt.ErrorMatches, "htmlPostProcess: step 1: "+test.expect)
+	}
+}
+
+func TestProcess(t *testing.T) {
+	c := qt.New(t)
+
+	src := `<!DOCTYPE html>
+<html><head><title>Home</title>
+<script>if (a<b) { document.write("<a href='https://example.org/'>") }</script></head>
+<body>
+<!-- <a href="https://example.org/"> -->
+<a HREF="https://example.org/" Class="x">External</a>
+<a href="/about/">About</a>
+<a href='https://example.com/' target=_self>Self</a>
+<img src="a.png" alt="A"/>
+<img src="b.png" loading="eager">
+<br>
+</body></html>`
+
+	c.Run("Attributes", func(c *qt.C) {
+		p, err := newTestProcessor(c, `
+[[htmlPostProcess]]
+selector = "a[href^='https://']"
+attributes = { rel = "noopener", target = "_blank" }
+[[htmlPostProcess]]
+selector = "img"
+attributes = { loading = "lazy", decoding = "async" }
+`, nil)
+		c.Assert(err, qt.IsNil)
+		out, err := p.Process("index.html", []byte(src))
+		c.Assert(err, qt.IsNil)
+		c.Assert(string(out), qt.Equals, `<!DOCTYPE html>
+<html><head><title>Home</title>
+<script>if (a<b) { document.write("<a href='https://example.org/'>") }</script></head>
+<body>
+<!-- <a href="https://example.org/"> -->
+<a HREF="https://example.org/" Class="x" rel="noopener" target="_blank">External</a>
+<a href="/about/">About</a>
+<a href='https://example.com/' target=_self rel="noopener">Self</a>
+<img src="a.png" alt="A" decoding="async" loading="lazy"/>
+<img src="b.png" loading="eager" decoding="async">
+<br>
+</body></html>`)
+	})
+
+	c.Run("Overwrite and remove", func(c *qt.C) {
+		p, err := newTestProcessor(c, `
+[[htmlPostProcess]]
+selector = "img, a.x"
+overwrite = true
+attributes = { loading = "lazy", class = "y" }
+removeAttributes = ["alt", "href"]
+`, nil)
+		c.Assert(err, qt.IsNil)
+		out, err := p.Process("index.html", []byte(src))
+		c.Assert(err, qt.IsNil)
+		c.Assert(string(out), qt.Contains, `<a class="y" loading="lazy">External</a>`)
+		c.Assert(string(out), qt.Contains, `<img src="a.png" class="y" loading="lazy" />`)
+		c.Assert(string(out), qt.Contains, `<img src="b.png" loading="lazy" class="y">`)
+		c.Assert(string(out), qt.Contains, `<a href='https://example.com/' target=_self>Self</a>`)
+	})
+
+	c.Run("Paths", func(c *qt.C) {
+		p, err := newTestProcessor(c, `
+[[htmlPostProcess]]
+selector = "br"
+paths = ["posts/**"]
+attributes = { class = "clear" }
+`, nil)
+		c.Assert(err, qt.IsNil)
+		out, err := p.Process("index.html", []byte(src))
+		c.Assert(err, qt.IsNil)
+		c.Assert(string(out), qt.Equals, src)
+		out, err = p.Process("posts/p1/index.html", []byte(src))
+		c.Assert(err, qt.IsNil)
+		c.Assert(string(out), qt.Contains, `<br class="clear">`)
+	})
+
+	c.Run("Transformer and plugin", func(c *qt.C) {
+		fs := afero.NewMemMapFs()
+		c.Assert(afero.WriteFile(fs, "/my/project/plugins/upper.wasm", pluginstest.Upper(plugins.KindHTML), 0666), qt.IsNil)
+		c.Assert(afero.WriteFile(fs, "/my/project/plugins/output.wasm", pluginstest.Upper(plugins.KindOutput), 0666), qt.IsNil)
+		cfg, err := config.FromConfigString(`
+[[plugins]]
+name = "upper"
+path = "plugins/upper.wasm"
+[[plugins]]
+name = "output"
+path = "plugins/output.wasm"
+`, "toml")
+		c.Assert(err, qt.IsNil)
+		cfg.Set("workingDir", "/my/project")
+		pl, err := plugins.New(fs, cfg, nil)
+		c.Assert(err, qt.IsNil)
+		defer pl.Close()
+
+		p, err := newTestProcessor(c, `
+[[htmlPostProcess]]
+transformer = "reverse-title"
+params = { suffix = "!" }
+[[htmlPostProcess]]
+plugin = "upper"
+`, pl)
+		c.Assert(err, qt.IsNil)
+		out, err := p.Process("index.html", []byte("<title>Home</title>"))
+		c.Assert(err, qt.IsNil)
+		c.Assert(string(out), qt.Equals, "<TITLE>EMOH!</TITLE>")
+
+		_, err = newTestProcessor(c, `
+[[htmlPostProcess]]
+plugin = "output"
+`, pl)
+		c.Assert(err, qt.ErrorMatches, `htmlPostProcess: step 1: plugin "output" does not export hugo_transform_html`)
+
+		_, err = newTestProcessor(c, `
+[[htmlPostProcess]]
+plugin = "foo"
+`, pl)
+		c.Assert(err, qt.ErrorMatches, `htmlPostProcess: step 1: plugin "foo" not found`)
+	})
+}
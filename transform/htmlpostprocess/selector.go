@@ -0,0 +1,269 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package htmlpostprocess
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/html"
+)
+
+// selector is a list of compound selectors, matching an element if any of
+// them matches. Combinators are not supported, as the elements are matched
+// one tag at a time.
+type selector []compoundSelector
+
+// compoundSelector is e.g. "a.external[href^='https://']".
+type compoundSelector struct {
+	tag     string
+	id      string
+	classes []string
+	attrs   []attrSelector
+}
+
+// attrSelector is e.g. "[href^='https://']". An empty op matches any
+// element with the attribute.
+type attrSelector struct {
+	name  string
+	op    string
+	value string
+}
+
+func (s selector) match(tag string, attrs []html.Attribute) bool {
+	for _, c := range s {
+		if c.match(tag, attrs) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c compoundSelector) match(tag string, attrs []html.Attribute) bool {
+	if c.tag != "" && c.tag != tag {
+		return false
+	}
+	if c.id != "" {
+		if v, found := getAttr(attrs, "id"); !found || v != c.id {
+			return false
+		}
+	}
+	if len(c.classes) > 0 {
+		v, _ := getAttr(attrs, "class")
+		classes := strings.Fields(v)
+		for _, class := range c.classes {
+			if !containsString(classes, class) {
+				return false
+			}
+		}
+	}
+	for _, a := range c.attrs {
+		v, found := getAttr(attrs, a.name)
+		if !found || !a.matchValue(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func (a attrSelector) matchValue(v string) bool {
+	switch a.op {
+	case "":
+		return true
+	case "=":
+		return v == a.value
+	case "~=":
+		return containsString(strings.Fields(v), a.value)
+	case "^=":
+		return a.value != "" && strings.HasPrefix(v, a.value)
+	case "$=":
+		return a.value != "" && strings.HasSuffix(v, a.value)
+	case "*=":
+		return a.value != "" && strings.Contains(v, a.value)
+	case "|=":
+		return v == a.value || strings.HasPrefix(v, a.value+"-")
+	}
+	return false
+}
+
+// parseSelector parses a comma separated list of compound selectors, e.g.
+// "img, iframe[src*='youtube']".
+func parseSelector(s string) (selector, error) {
+	p := &selectorParser{s: s}
+	var sel selector
+	for {
+		p.skipSpace()
+		c, err := p.parseCompound()
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid selector %q", s)
+		}
+		sel = append(sel, c)
+		p.skipSpace()
+		if p.eof() {
+			return sel, nil
+		}
+		if p.s[p.pos] != ',' {
+			return nil, errors.Errorf("invalid selector %q: combinators are not supported, found %q at position %d", s, p.s[p.pos], p.pos)
+		}
+		p.pos++
+	}
+}
+
+type selectorParser struct {
+	s   string
+	pos int
+}
+
+func (p *selectorParser) eof() bool {
+	return p.pos >= len(p.s)
+}
+
+func (p *selectorParser) skipSpace() {
+	for !p.eof() && isSpace(p.s[p.pos]) {
+		p.pos++
+	}
+}
+
+func (p *selectorParser) parseCompound() (compoundSelector, error) {
+	var c compoundSelector
+
+	start := p.pos
+	if !p.eof() && p.s[p.pos] == '*' {
+		p.pos++
+	} else {
+		c.tag = strings.ToLower(p.parseIdent())
+	}
+
+	for !p.eof() {
+		switch p.s[p.pos] {
+		case '#':
+			p.pos++
+			if c.id = p.parseIdent(); c.id == "" {
+				return c, errors.New("missing id after #")
+			}
+		case '.':
+			p.pos++
+			class := p.parseIdent()
+			if class == "" {
+				return c, errors.New("missing class after .")
+			}
+			c.classes = append(c.classes, class)
+		case '[':
+			p.pos++
+			a, err := p.parseAttr()
+			if err != nil {
+				return c, err
+			}
+			c.attrs = append(c.attrs, a)
+		default:
+			if p.pos == start {
+				return c, errors.Errorf("unexpected %q at position %d", p.s[p.pos], p.pos)
+			}
+			return c, nil
+		}
+	}
+
+	if p.pos == start {
+		return c, errors.New("empty selector")
+	}
+
+	return c, nil
+}
+
+func (p *selectorParser) parseAttr() (attrSelector, error) {
+	var a attrSelector
+
+	p.skipSpace()
+	start := p.pos
+	for !p.eof() && (isIdentChar(p.s[p.pos]) || p.s[p.pos] == ':') {
+		p.pos++
+	}
+	if a.name = strings.ToLower(p.s[start:p.pos]); a.name == "" {
+		return a, errors.New("missing attribute name")
+	}
+	p.skipSpace()
+
+	if p.eof() {
+		return a, errors.New("missing ]")
+	}
+
+	if p.s[p.pos] != ']' {
+		for _, op := range []string{"=", "~=", "^=", "$=", "*=", "|="} {
+			if strings.HasPrefix(p.s[p.pos:], op) {
+				a.op = op
+				p.pos += len(op)
+				break
+			}
+		}
+		if a.op == "" {
+			return a, errors.Errorf("unexpected %q at position %d", p.s[p.pos], p.pos)
+		}
+
+		p.skipSpace()
+		if !p.eof() && (p.s[p.pos] == '"' || p.s[p.pos] == '\'') {
+			quote := p.s[p.pos]
+			end := strings.IndexByte(p.s[p.pos+1:], quote)
+			if end == -1 {
+				return a, errors.New("unterminated string")
+			}
+			a.value = p.s[p.pos+1 : p.pos+1+end]
+			p.pos += end + 2
+		} else if a.value = p.parseIdent(); a.value == "" {
+			return a, errors.Errorf("missing value for attribute %q", a.name)
+		}
+		p.skipSpace()
+	}
+
+	if p.eof() || p.s[p.pos] != ']' {
+		return a, errors.New("missing ]")
+	}
+	p.pos++
+
+	return a, nil
+}
+
+func (p *selectorParser) parseIdent() string {
+	start := p.pos
+	for !p.eof() && isIdentChar(p.s[p.pos]) {
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+func isIdentChar(c byte) bool {
+	return c == '-' || c == '_' || c >= 0x80 ||
+		('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+func getAttr(attrs []html.Attribute, name string) (string, bool) {
+	for _, a := range attrs {
+		if a.Namespace == "" && a.Key == name {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
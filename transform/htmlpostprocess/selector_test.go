@@ -0,0 +1,83 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package htmlpostprocess
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"golang.org/x/net/html"
+)
+
+func TestSelector(t *testing.T) {
+	c := qt.New(t)
+
+	attrs := func(kv ...string) []html.Attribute {
+		var a []html.Attribute
+		for i := 0; i < len(kv); i += 2 {
+			a = append(a, html.Attribute{Key: kv[i], Val: kv[i+1]})
+		}
+		return a
+	}
+
+	for _, test := range []struct {
+		selector string
+		tag      string
+		attrs    []html.Attribute
+		expect   bool
+	}{
+		{"a", "a", nil, true},
+		{"A", "a", nil, true},
+		{"a", "img", nil, false},
+		{"*", "img", nil, true},
+		{"#main", "div", attrs("id", "main"), true},
+		{"div#main", "div", attrs("id", "other"), false},
+		{".b.c", "p", attrs("class", "a b c"), true},
+		{".b.d", "p", attrs("class", "a b c"), false},
+		{"[hidden]", "p", attrs("hidden", ""), true},
+		{"[href^='https://']", "a", attrs("href", "https://example.org"), true},
+		{`[href^="https://"]`, "a", attrs("href", "/about/"), false},
+		{"[href$='.pdf']", "a", attrs("href", "/doc.pdf"), true},
+		{"iframe[src*=youtube]", "iframe", attrs("src", "https://www.youtube.com/embed/x"), true},
+		{"[rel~=external]", "a", attrs("rel", "nofollow external"), true},
+		{"[lang|=en]", "p", attrs("lang", "en-US"), true},
+		{"[lang|=en]", "p", attrs("lang", "eng"), false},
+		{"[ data-x = 'a b' ]", "p", attrs("data-x", "a b"), true},
+		{"img, iframe", "iframe", nil, true},
+		{"use[xlink:href]", "use", attrs("xlink:href", "#icon"), true},
+	} {
+		sel, err := parseSelector(test.selector)
+		c.Assert(err, qt.IsNil, qt.Commentf(test.selector))
+		c.Assert(sel.match(test.tag, test.attrs), qt.Equals, test.expect, qt.Commentf(test.selector))
+	}
+
+	for _, test := range []struct {
+		selector string
+		expect   string
+	}{
+		{"", "empty selector"},
+		{"a b", "combinators are not supported.*"},
+		{"ul > li", "combinators are not supported.*"},
+		{"a,", "empty selector"},
+		{"a[href", "missing ]"},
+		{"a[href='x]", "unterminated string"},
+		{"a[href=]", "missing value.*"},
+		{"a[href!=x]", "unexpected '!'.*"},
+		{"a.", "missing class.*"},
+		{":hover", "unexpected ':'.*"},
+	} {
+		_, err := parseSelector(test.selector)
+		c.Assert(err, qt.ErrorMatches, `invalid selector.*: `+test.expect, qt.Commentf(test.selector))
+	}
+}
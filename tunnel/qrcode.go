@@ -0,0 +1,540 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tunnel
+
+import (
+	"bufio"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// This is a minimal QR code encoder, enough to print a URL in the terminal.
+// It supports byte mode, error correction level L and versions 1 to 10,
+// which holds up to 271 bytes. See ISO/IEC 18004 and
+// https://www.nayuki.io/page/qr-code-generator-library, which it follows
+// closely.
+
+const qrMaxVersion = 10
+
+// Indexed by version; the first entry is unused.
+var (
+	qrTotalCodewords = [...]int{0, 26, 44, 70, 100, 134, 172, 196, 242, 292, 346}
+	qrECCPerBlock    = [...]int{0, 7, 10, 15, 20, 26, 18, 20, 24, 30, 18}
+	qrNumBlocks      = [...]int{0, 1, 1, 1, 1, 1, 2, 2, 2, 2, 4}
+)
+
+// qrCode is a QR code symbol, with modules[y][x] set for dark modules.
+type qrCode struct {
+	version  int
+	size     int
+	modules  [][]bool
+	function [][]bool
+}
+
+// WriteQRCode writes text as a QR code to w, drawn with Unicode half block
+// characters, two rows of modules per line.
+func WriteQRCode(w io.Writer, text string) error {
+	qr, err := encodeQR([]byte(text))
+	if err != nil {
+		return err
+	}
+
+	const quiet = 2
+
+	// The terminal is usually light text on a dark background, so draw the
+	// light modules.
+	dark := func(x, y int) bool {
+		if x < 0 || y < 0 || x >= qr.size || y >= qr.size {
+			return false
+		}
+		return qr.modules[y][x]
+	}
+
+	bw := bufio.NewWriter(w)
+	for y := -quiet; y < qr.size+quiet; y += 2 {
+		for x := -quiet; x < qr.size+quiet; x++ {
+			top, bottom := !dark(x, y), !dark(x, y+1)
+			if y+1 >= qr.size+quiet {
+				bottom = false
+			}
+			switch {
+			case top && bottom:
+				bw.WriteString("█")
+			case top:
+				bw.WriteString("▀")
+			case bottom:
+				bw.WriteString("▄")
+			default:
+				bw.WriteString(" ")
+			}
+		}
+		bw.WriteString("\n")
+	}
+
+	return bw.Flush()
+}
+
+// encodeQR encodes data in byte mode in the smallest version that fits.
+func encodeQR(data []byte) (*qrCode, error) {
+	version := 0
+	for v := 1; v <= qrMaxVersion; v++ {
+		if qrDataBits(v, len(data)) <= qrDataCodewords(v)*8 {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, errors.Errorf("text too long for a QR code: %d bytes", len(data))
+	}
+
+	// Mode indicator, character count and data.
+	var bb qrBitBuffer
+	bb.append(0x4, 4)
+	bb.append(uint32(len(data)), qrCountBits(version))
+	for _, b := range data {
+		bb.append(uint32(b), 8)
+	}
+
+	// Terminator, padding to a byte and pad codewords.
+	capacity := qrDataCodewords(version) * 8
+	bb.append(0, minInt(4, capacity-len(bb)))
+	bb.append(0, (8-len(bb)%8)%8)
+	for pad := uint32(0xEC); len(bb) < capacity; pad ^= 0xEC ^ 0x11 {
+		bb.append(pad, 8)
+	}
+
+	codewords := make([]byte, len(bb)/8)
+	for i, bit := range bb {
+		if bit {
+			codewords[i>>3] |= 1 << (7 - uint(i&7))
+		}
+	}
+
+	qr := newQRCode(version)
+	qr.drawFunctionPatterns()
+	qr.drawCodewords(qrAddECCAndInterleave(version, codewords))
+
+	bestMask, minPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		qr.applyMask(mask)
+		qr.drawFormatBits(mask)
+		if p := qr.penalty(); minPenalty < 0 || p < minPenalty {
+			bestMask, minPenalty = mask, p
+		}
+		// Masking is its own inverse.
+		qr.applyMask(mask)
+	}
+	qr.applyMask(bestMask)
+	qr.drawFormatBits(bestMask)
+
+	return qr, nil
+}
+
+func qrCountBits(version int) int {
+	if version < 10 {
+		return 8
+	}
+	return 16
+}
+
+func qrDataBits(version, n int) int {
+	return 4 + qrCountBits(version) + n*8
+}
+
+func qrDataCodewords(version int) int {
+	return qrTotalCodewords[version] - qrECCPerBlock[version]*qrNumBlocks[version]
+}
+
+type qrBitBuffer []bool
+
+func (b *qrBitBuffer) append(v uint32, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*b = append(*b, (v>>uint(i))&1 != 0)
+	}
+}
+
+// qrAddECCAndInterleave splits data into blocks, adds the error correction
+// codewords to each block and interleaves the blocks.
+func qrAddECCAndInterleave(version int, data []byte) []byte {
+	numBlocks := qrNumBlocks[version]
+	eccLen := qrECCPerBlock[version]
+	total := qrTotalCodewords[version]
+	numShort := numBlocks - total%numBlocks
+	shortLen := total / numBlocks
+
+	divisor := qrReedSolomonDivisor(eccLen)
+
+	var blocks [][]byte
+	k := 0
+	for i := 0; i < numBlocks; i++ {
+		n := shortLen - eccLen
+		if i >= numShort {
+			n++
+		}
+		dat := data[k : k+n]
+		k += n
+		block := make([]byte, 0, shortLen+1)
+		block = append(block, dat...)
+		if i < numShort {
+			// Placeholder to align the columns, skipped below.
+			block = append(block, 0)
+		}
+		block = append(block, qrReedSolomonRemainder(dat, divisor)...)
+		blocks = append(blocks, block)
+	}
+
+	result := make([]byte, 0, total)
+	for i := 0; i <= shortLen; i++ {
+		for j, block := range blocks {
+			if i != shortLen-eccLen || j >= numShort {
+				result = append(result, block[i])
+			}
+		}
+	}
+
+	return result
+}
+
+func qrReedSolomonDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := range result {
+			result[j] = qrMultiply(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = qrMultiply(root, 0x02)
+	}
+	return result
+}
+
+func qrReedSolomonRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i, d := range divisor {
+			result[i] ^= qrMultiply(d, factor)
+		}
+	}
+	return result
+}
+
+// qrMultiply multiplies x and y in GF(2^8) modulo x^8 + x^4 + x^3 + x^2 + 1.
+func qrMultiply(x, y byte) byte {
+	var z int
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11D)
+		z ^= int((y>>uint(i))&1) * int(x)
+	}
+	return byte(z)
+}
+
+func newQRCode(version int) *qrCode {
+	size := version*4 + 17
+	qr := &qrCode{version: version, size: size}
+	qr.modules = make([][]bool, size)
+	qr.function = make([][]bool, size)
+	for i := range qr.modules {
+		qr.modules[i] = make([]bool, size)
+		qr.function[i] = make([]bool, size)
+	}
+	return qr
+}
+
+func (qr *qrCode) setFunction(x, y int, dark bool) {
+	qr.modules[y][x] = dark
+	qr.function[y][x] = true
+}
+
+func (qr *qrCode) drawFunctionPatterns() {
+	for i := 0; i < qr.size; i++ {
+		qr.setFunction(6, i, i%2 == 0)
+		qr.setFunction(i, 6, i%2 == 0)
+	}
+
+	qr.drawFinderPattern(3, 3)
+	qr.drawFinderPattern(qr.size-4, 3)
+	qr.drawFinderPattern(3, qr.size-4)
+
+	pos := qr.alignmentPatternPositions()
+	last := len(pos) - 1
+	for i := range pos {
+		for j := range pos {
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				// Overlaps the finder patterns.
+				continue
+			}
+			qr.drawAlignmentPattern(pos[i], pos[j])
+		}
+	}
+
+	// Reserve the format bits; they're set after masking.
+	qr.drawFormatBits(0)
+	qr.drawVersion()
+}
+
+func (qr *qrCode) drawFinderPattern(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || yy < 0 || xx >= qr.size || yy >= qr.size {
+				continue
+			}
+			dist := maxInt(absInt(dx), absInt(dy))
+			qr.setFunction(xx, yy, dist != 2 && dist != 4)
+		}
+	}
+}
+
+func (qr *qrCode) drawAlignmentPattern(x, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			qr.setFunction(x+dx, y+dy, maxInt(absInt(dx), absInt(dy)) != 1)
+		}
+	}
+}
+
+func (qr *qrCode) alignmentPatternPositions() []int {
+	if qr.version == 1 {
+		return nil
+	}
+	numAlign := qr.version/7 + 2
+	step := (qr.version*4 + numAlign*2 + 1) / (numAlign*2 - 2) * 2
+	result := make([]int, numAlign)
+	result[0] = 6
+	for i, pos := numAlign-1, qr.size-7; i >= 1; i, pos = i-1, pos-step {
+		result[i] = pos
+	}
+	return result
+}
+
+// qrFormatBits returns the 15 format bits for error correction level L and
+// the given mask.
+func qrFormatBits(mask int) int {
+	data := 1<<3 | mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	return (data<<10 | rem) ^ 0x5412
+}
+
+func (qr *qrCode) drawFormatBits(mask int) {
+	bits := qrFormatBits(mask)
+	bit := func(i int) bool { return (bits>>uint(i))&1 != 0 }
+
+	// Around the top left finder pattern.
+	for i := 0; i <= 5; i++ {
+		qr.setFunction(8, i, bit(i))
+	}
+	qr.setFunction(8, 7, bit(6))
+	qr.setFunction(8, 8, bit(7))
+	qr.setFunction(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		qr.setFunction(14-i, 8, bit(i))
+	}
+
+	// The copy next to the other finder patterns.
+	for i := 0; i < 8; i++ {
+		qr.setFunction(qr.size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		qr.setFunction(8, qr.size-15+i, bit(i))
+	}
+	qr.setFunction(8, qr.size-8, true)
+}
+
+func (qr *qrCode) drawVersion() {
+	if qr.version < 7 {
+		return
+	}
+	rem := qr.version
+	for i := 0; i < 12; i++ {
+		rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+	}
+	bits := qr.version<<12 | rem
+	for i := 0; i < 18; i++ {
+		dark := (bits>>uint(i))&1 != 0
+		a, b := qr.size-11+i%3, i/3
+		qr.setFunction(a, b, dark)
+		qr.setFunction(b, a, dark)
+	}
+}
+
+// drawCodewords draws data in the zigzag pattern, two columns at a time
+// from the bottom right corner, skipping the function modules.
+func (qr *qrCode) drawCodewords(data []byte) {
+	i := 0
+	for right := qr.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			// Skip the vertical timing pattern.
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < qr.size; vert++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				y := vert
+				if upward {
+					y = qr.size - 1 - vert
+				}
+				if !qr.function[y][x] && i < len(data)*8 {
+					qr.modules[y][x] = (data[i>>3]>>(7-uint(i&7)))&1 != 0
+					i++
+				}
+			}
+		}
+	}
+}
+
+func qrMaskBit(mask, x, y int) bool {
+	switch mask {
+	case 0:
+		return (x+y)%2 == 0
+	case 1:
+		return y%2 == 0
+	case 2:
+		return x%3 == 0
+	case 3:
+		return (x+y)%3 == 0
+	case 4:
+		return (x/3+y/2)%2 == 0
+	case 5:
+		return x*y%2+x*y%3 == 0
+	case 6:
+		return (x*y%2+x*y%3)%2 == 0
+	default:
+		return ((x+y)%2+x*y%3)%2 == 0
+	}
+}
+
+func (qr *qrCode) applyMask(mask int) {
+	for y := 0; y < qr.size; y++ {
+		for x := 0; x < qr.size; x++ {
+			if !qr.function[y][x] && qrMaskBit(mask, x, y) {
+				qr.modules[y][x] = !qr.modules[y][x]
+			}
+		}
+	}
+}
+
+// penalty scores the symbol by the rules in the specification, used to
+// select the mask. It's lower for symbols that are easier to scan.
+func (qr *qrCode) penalty() int {
+	var (
+		result int
+		dark   int
+	)
+
+	get := func(x, y int, vertical bool) bool {
+		if vertical {
+			return qr.modules[x][y]
+		}
+		return qr.modules[y][x]
+	}
+
+	finderLike := []bool{true, false, true, true, true, false, true}
+
+	for _, vertical := range []bool{false, true} {
+		for y := 0; y < qr.size; y++ {
+			run := 0
+			var color bool
+			for x := 0; x < qr.size; x++ {
+				c := get(x, y, vertical)
+				if x == 0 || c != color {
+					color, run = c, 1
+				} else {
+					run++
+					if run == 5 {
+						result += 3
+					} else if run > 5 {
+						result++
+					}
+				}
+
+				// A finder like pattern with four light modules before or
+				// after, the area outside the symbol being light.
+				if x+7 <= qr.size {
+					match := true
+					for i, f := range finderLike {
+						if get(x+i, y, vertical) != f {
+							match = false
+							break
+						}
+					}
+					if match && (qr.isLightRun(x-4, x, y, vertical) || qr.isLightRun(x+7, x+11, y, vertical)) {
+						result += 40
+					}
+				}
+			}
+		}
+	}
+
+	for y := 0; y < qr.size; y++ {
+		for x := 0; x < qr.size; x++ {
+			c := qr.modules[y][x]
+			if c {
+				dark++
+			}
+			if x+1 < qr.size && y+1 < qr.size && c == qr.modules[y][x+1] && c == qr.modules[y+1][x] && c == qr.modules[y+1][x+1] {
+				result += 3
+			}
+		}
+	}
+
+	total := qr.size * qr.size
+	k := (absInt(dark*20-total*10)+total-1)/total - 1
+	result += k * 10
+
+	return result
+}
+
+func (qr *qrCode) isLightRun(from, to, y int, vertical bool) bool {
+	for x := from; x < to; x++ {
+		if x < 0 || x >= qr.size {
+			continue
+		}
+		if vertical && qr.modules[x][y] || !vertical && qr.modules[y][x] {
+			return false
+		}
+	}
+	return true
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func absInt(a int) int {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
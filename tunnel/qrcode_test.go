@@ -0,0 +1,169 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tunnel
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestQRFormatBits(t *testing.T) {
+	c := qt.New(t)
+
+	// From the table in the specification.
+	c.Assert(qrFormatBits(0), qt.Equals, 0x77C4)
+	c.Assert(qrFormatBits(7), qt.Equals, 0x6976)
+}
+
+func TestEncodeQR(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range []struct {
+		text    string
+		version int
+	}{
+		{"a", 1},
+		{"https://example.org", 2},
+		{"https://some-random-words-here.trycloudflare.com", 3},
+		{strings.Repeat("x", 100), 5},
+		{strings.Repeat("y", 150), 7},
+		{strings.Repeat("z", 271), 10},
+	} {
+		qr, err := encodeQR([]byte(test.text))
+		c.Assert(err, qt.IsNil)
+		c.Assert(qr.version, qt.Equals, test.version)
+		c.Assert(qr.size, qt.Equals, test.version*4+17)
+		c.Assert(decodeQR(c, qr), qt.Equals, test.text)
+	}
+
+	_, err := encodeQR(bytes.Repeat([]byte("a"), 272))
+	c.Assert(err, qt.ErrorMatches, "text too long.*")
+}
+
+func TestWriteQRCode(t *testing.T) {
+	c := qt.New(t)
+
+	var buf bytes.Buffer
+	c.Assert(WriteQRCode(&buf, "https://example.org"), qt.IsNil)
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	// Version 2 is 25 modules, with a quiet zone of two modules.
+	c.Assert(lines, qt.HasLen, 15)
+	c.Assert([]rune(lines[0]), qt.HasLen, 29)
+	c.Assert(lines[0], qt.Equals, strings.Repeat("█", 29))
+}
+
+// decodeQR reads the data back from qr, checking the format bits and the
+// error correction codewords on the way.
+func decodeQR(c *qt.C, qr *qrCode) string {
+	// The format bits next to the top left finder pattern.
+	var format int
+	for i := 0; i <= 5; i++ {
+		format |= boolBit(qr.modules[i][8]) << uint(i)
+	}
+	format |= boolBit(qr.modules[7][8]) << 6
+	format |= boolBit(qr.modules[8][8]) << 7
+	format |= boolBit(qr.modules[8][7]) << 8
+	for i := 9; i < 15; i++ {
+		format |= boolBit(qr.modules[8][14-i]) << uint(i)
+	}
+	mask := -1
+	for m := 0; m < 8; m++ {
+		if qrFormatBits(m) == format {
+			mask = m
+		}
+	}
+	c.Assert(mask, qt.Not(qt.Equals), -1)
+
+	// The function modules are the same for all symbols of a version.
+	ref := newQRCode(qr.version)
+	ref.drawFunctionPatterns()
+
+	var bits []bool
+	for right := qr.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < qr.size; vert++ {
+			for j := 0; j < 2; j++ {
+				x, y := right-j, vert
+				if (right+1)&2 == 0 {
+					y = qr.size - 1 - vert
+				}
+				if !ref.function[y][x] {
+					bits = append(bits, qr.modules[y][x] != qrMaskBit(mask, x, y))
+				}
+			}
+		}
+	}
+
+	total := qrTotalCodewords[qr.version]
+	codewords := make([]byte, total)
+	for i := 0; i < total*8; i++ {
+		if bits[i] {
+			codewords[i>>3] |= 1 << (7 - uint(i&7))
+		}
+	}
+
+	// De-interleave.
+	numBlocks := qrNumBlocks[qr.version]
+	eccLen := qrECCPerBlock[qr.version]
+	numShort := numBlocks - total%numBlocks
+	shortLen := total / numBlocks
+	blocks := make([][]byte, numBlocks)
+	k := 0
+	for i := 0; i <= shortLen; i++ {
+		for j := range blocks {
+			if i != shortLen-eccLen || j >= numShort {
+				blocks[j] = append(blocks[j], codewords[k])
+				k++
+			}
+		}
+	}
+
+	var data []byte
+	divisor := qrReedSolomonDivisor(eccLen)
+	for _, block := range blocks {
+		dat, ecc := block[:len(block)-eccLen], block[len(block)-eccLen:]
+		c.Assert(qrReedSolomonRemainder(dat, divisor), qt.DeepEquals, ecc)
+		data = append(data, dat...)
+	}
+
+	// Byte mode and the count.
+	c.Assert(data[0]>>4, qt.Equals, byte(0x4))
+	var n int
+	var rest []byte
+	if qrCountBits(qr.version) == 8 {
+		n = int(data[0]&0xF)<<4 | int(data[1]>>4)
+		rest = data[1:]
+	} else {
+		n = int(data[0]&0xF)<<12 | int(data[1])<<4 | int(data[2]>>4)
+		rest = data[2:]
+	}
+	text := make([]byte, n)
+	for i := range text {
+		text[i] = rest[i]<<4 | rest[i+1]>>4
+	}
+
+	return string(text)
+}
+
+func boolBit(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
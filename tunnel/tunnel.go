@@ -0,0 +1,340 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tunnel sets up a tunnel from a public URL to the Hugo server using
+// one of the supported tunnel clients, e.g. cloudflared.
+package tunnel
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gohugoio/hugo/common/hexec"
+	"github.com/gohugoio/hugo/common/loggers"
+	"github.com/gohugoio/hugo/config"
+	"github.com/gohugoio/hugo/config/security"
+	"github.com/pkg/errors"
+)
+
+// DefaultProvider is the provider used if none is set.
+const DefaultProvider = "cloudflared"
+
+// ProviderCommand is the provider running the command set in the config.
+const ProviderCommand = "command"
+
+// How long to wait for the tunnel client to report the public URL.
+var startTimeout = 30 * time.Second
+
+// The number of output lines from the tunnel client to include in errors.
+const maxErrorLines = 10
+
+// provider describes how to run a tunnel client and find the public URL in
+// its output.
+type provider struct {
+	command string
+	args    func(local *url.URL) []string
+	urlRe   *regexp.Regexp
+
+	// The header the provider sets to the IP address of the client. If not
+	// set, the last entry in X-Forwarded-For is used.
+	clientIPHeader string
+}
+
+var httpsURLRe = regexp.MustCompile(`https://[^\s"'<>|]+`)
+
+var providers = map[string]provider{
+	"cloudflared": {
+		command: "cloudflared",
+		args: func(local *url.URL) []string {
+			args := []string{"tunnel", "--no-autoupdate", "--url", local.String()}
+			if local.Scheme == "https" {
+				// The server uses a self-signed certificate by default.
+				args = append(args, "--no-tls-verify")
+			}
+			return args
+		},
+		urlRe:          regexp.MustCompile(`https://[a-z0-9-]+\.trycloudflare\.com`),
+		clientIPHeader: "Cf-Connecting-Ip",
+	},
+	"ngrok": {
+		command: "ngrok",
+		args: func(local *url.URL) []string {
+			return []string{"http", local.String(), "--log", "stdout"}
+		},
+		urlRe: regexp.MustCompile(`url=(https://\S+)`),
+	},
+	"localtunnel": {
+		command: "lt",
+		args: func(local *url.URL) []string {
+			args := []string{"--port", local.Port()}
+			if local.Scheme == "https" {
+				args = append(args, "--local-https", "--allow-invalid-cert")
+			}
+			return args
+		},
+		urlRe: httpsURLRe,
+	},
+}
+
+// IsProvider reports whether name is a supported provider.
+func IsProvider(name string) bool {
+	if name == ProviderCommand {
+		return true
+	}
+	_, found := providers[strings.ToLower(name)]
+	return found
+}
+
+// Tunnel is a running tunnel client.
+type Tunnel struct {
+	// The name of the provider.
+	Provider string
+
+	// The public URL forwarded to the local server.
+	URL *url.URL
+
+	cmd  *exec.Cmd
+	done chan struct{}
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// Start starts the tunnel client configured in cfg, forwarding to the server
+// at local, and waits for it to report the public URL. The output of the
+// client is logged at the info level.
+func Start(cfg config.Tunnel, sc security.Config, local *url.URL, logger loggers.Logger) (*Tunnel, error) {
+	name := strings.ToLower(cfg.Provider)
+	if name == "" {
+		name = DefaultProvider
+	}
+
+	var (
+		command string
+		args    []string
+		urlRe   = httpsURLRe
+	)
+
+	if name == ProviderCommand {
+		if cfg.Command == "" {
+			return nil, errors.New("tunnel: command must be set with the command provider")
+		}
+		command = cfg.Command
+		r := strings.NewReplacer("{port}", local.Port(), "{url}", local.String())
+		for _, arg := range cfg.Args {
+			args = append(args, r.Replace(arg))
+		}
+		if cfg.URLPattern != "" {
+			re, err := regexp.Compile(cfg.URLPattern)
+			if err != nil {
+				return nil, errors.Wrap(err, "tunnel: invalid urlPattern")
+			}
+			urlRe = re
+		}
+		// The command is set in the project config, so it's subject to the
+		// same policy as other external programs.
+		if err := sc.CheckAllowedExec(command, args...); err != nil {
+			return nil, err
+		}
+	} else {
+		p, found := providers[name]
+		if !found {
+			return nil, errors.Errorf("tunnel: unknown provider %q, must be one of cloudflared, ngrok, localtunnel or command", cfg.Provider)
+		}
+		command = p.command
+		args = append(p.args(local), cfg.Args...)
+		urlRe = p.urlRe
+	}
+
+	cmd, err := hexec.SafeCommand(command, args...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "tunnel: failed to find %s, is it installed?", command)
+	}
+
+	// Use an OS pipe so Wait doesn't wait for any child processes of the
+	// client holding on to the output.
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	cmd.Stdout = pw
+	cmd.Stderr = pw
+	detach(cmd)
+
+	err = cmd.Start()
+	pw.Close()
+	if err != nil {
+		pr.Close()
+		return nil, errors.Wrapf(err, "tunnel: failed to start %s", command)
+	}
+
+	t := &Tunnel{
+		Provider: name,
+		cmd:      cmd,
+		done:     make(chan struct{}),
+	}
+
+	var (
+		found   = make(chan *url.URL, 1)
+		readAll = make(chan struct{})
+		outMu   sync.Mutex
+		lastOut []string
+		waitErr error
+	)
+
+	go func() {
+		scanner := bufio.NewScanner(pr)
+		reported := false
+		for scanner.Scan() {
+			line := scanner.Text()
+			if logger != nil {
+				logger.Infof("tunnel: %s", line)
+			}
+
+			outMu.Lock()
+			lastOut = append(lastOut, line)
+			if len(lastOut) > maxErrorLines {
+				lastOut = lastOut[1:]
+			}
+			outMu.Unlock()
+
+			if !reported {
+				if u := findURL(urlRe, line); u != nil {
+					reported = true
+					found <- u
+				}
+			}
+		}
+		// Keep the client from blocking on a full pipe.
+		io.Copy(ioutil.Discard, pr)
+		pr.Close()
+		close(readAll)
+	}()
+
+	go func() {
+		waitErr = cmd.Wait()
+		close(t.done)
+	}()
+
+	lastOutput := func() string {
+		outMu.Lock()
+		defer outMu.Unlock()
+		if len(lastOut) == 0 {
+			return ""
+		}
+		return "\n" + strings.Join(lastOut, "\n")
+	}
+
+	select {
+	case u := <-found:
+		t.URL = u
+	case <-t.done:
+		// Wait for the last output, unless a child process holds on to it.
+		select {
+		case <-readAll:
+		case <-time.After(time.Second):
+		}
+		return nil, errors.Errorf("tunnel: %s exited before reporting a public URL: %v%s", command, waitErr, lastOutput())
+	case <-time.After(startTimeout):
+		t.Close()
+		return nil, errors.Errorf("tunnel: timed out waiting for %s to report a public URL%s", command, lastOutput())
+	}
+
+	go func() {
+		<-t.done
+		t.mu.Lock()
+		closed := t.closed
+		t.mu.Unlock()
+		if !closed && logger != nil {
+			logger.Warnf("tunnel: %s exited: %v", command, waitErr)
+		}
+	}()
+
+	return t, nil
+}
+
+// findURL returns the first URL matched by re in line, or nil if none. If
+// re has a subexpression, the first one is used.
+func findURL(re *regexp.Regexp, line string) *url.URL {
+	m := re.FindStringSubmatch(line)
+	if m == nil {
+		return nil
+	}
+	s := m[0]
+	if len(m) > 1 {
+		s = m[1]
+	}
+	u, err := url.Parse(strings.TrimRight(s, ".,;)"))
+	if err != nil || u.Host == "" {
+		return nil
+	}
+	u.Path = ""
+	u.RawQuery = ""
+	return u
+}
+
+// Close stops the tunnel client.
+func (t *Tunnel) Close() error {
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return nil
+	}
+	t.closed = true
+	t.mu.Unlock()
+
+	select {
+	case <-t.done:
+		return nil
+	default:
+	}
+
+	if err := kill(t.cmd); err != nil {
+		return err
+	}
+	<-t.done
+
+	return nil
+}
+
+// Forwards reports whether r was forwarded through the tunnel.
+func (t *Tunnel) Forwards(r *http.Request) bool {
+	return t != nil && t.URL != nil && strings.EqualFold(r.Host, t.URL.Host)
+}
+
+// ClientIP returns the IP address of the client sending r through the
+// tunnel, as reported by the provider in the request headers, or nil if
+// not known. Only the header set by the provider is trusted: for
+// X-Forwarded-For, that is the last entry, as the client may send any
+// entries before it.
+func (t *Tunnel) ClientIP(r *http.Request) net.IP {
+	if h := providers[strings.ToLower(t.Provider)].clientIPHeader; h != "" {
+		return net.ParseIP(strings.TrimSpace(r.Header.Get(h)))
+	}
+	xff := r.Header.Values("X-Forwarded-For")
+	if len(xff) == 0 {
+		return nil
+	}
+	entries := strings.Split(xff[len(xff)-1], ",")
+	return net.ParseIP(strings.TrimSpace(entries[len(entries)-1]))
+}
@@ -0,0 +1,166 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tunnel
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"runtime"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/config"
+	"github.com/gohugoio/hugo/config/security"
+)
+
+func TestProviders(t *testing.T) {
+	c := qt.New(t)
+
+	local, _ := url.Parse("https://localhost:1313")
+
+	c.Assert(providers["cloudflared"].args(local), qt.DeepEquals, []string{"tunnel", "--no-autoupdate", "--url", "https://localhost:1313", "--no-tls-verify"})
+	c.Assert(providers["localtunnel"].args(local), qt.DeepEquals, []string{"--port", "1313", "--local-https", "--allow-invalid-cert"})
+
+	for _, test := range []struct {
+		provider string
+		line     string
+		expect   string
+	}{
+		{"cloudflared", `2021-07-01T10:00:00Z INF |  https://brave-lions-sing-loudly.trycloudflare.com                 |`, "https://brave-lions-sing-loudly.trycloudflare.com"},
+		{"cloudflared", `2021-07-01T10:00:00Z INF Requesting new quick Tunnel on trycloudflare.com...`, ""},
+		{"ngrok", `t=2021-07-01T10:00:00+0200 lvl=info msg="started tunnel" obj=tunnels name=command_line addr=http://localhost:1313 url=https://1a2b3c.ngrok.io`, "https://1a2b3c.ngrok.io"},
+		{"ngrok", `t=2021-07-01T10:00:00+0200 lvl=info msg="starting web service" obj=web addr=127.0.0.1:4040`, ""},
+		{"localtunnel", `your url is: https://quiet-fox-12.loca.lt`, "https://quiet-fox-12.loca.lt"},
+	} {
+		u := findURL(providers[test.provider].urlRe, test.line)
+		if test.expect == "" {
+			c.Assert(u, qt.IsNil)
+			continue
+		}
+		c.Assert(u, qt.Not(qt.IsNil))
+		c.Assert(u.String(), qt.Equals, test.expect)
+	}
+
+	c.Assert(IsProvider("ngrok"), qt.IsTrue)
+	c.Assert(IsProvider("command"), qt.IsTrue)
+	c.Assert(IsProvider("foo"), qt.IsFalse)
+}
+
+func TestClientIP(t *testing.T) {
+	c := qt.New(t)
+
+	clientIP := func(provider string, headers ...string) string {
+		r := httptest.NewRequest("GET", "/", nil)
+		for i := 0; i < len(headers); i += 2 {
+			r.Header.Add(headers[i], headers[i+1])
+		}
+		ip := (&Tunnel{Provider: provider}).ClientIP(r)
+		if ip == nil {
+			return ""
+		}
+		return ip.String()
+	}
+
+	c.Assert(clientIP("ngrok"), qt.Equals, "")
+	c.Assert(clientIP("ngrok", "X-Forwarded-For", "203.0.113.7"), qt.Equals, "203.0.113.7")
+	// The client controls all but the last entry.
+	c.Assert(clientIP("ngrok", "X-Forwarded-For", "127.0.0.1, 203.0.113.7"), qt.Equals, "203.0.113.7")
+	c.Assert(clientIP("command", "X-Forwarded-For", "127.0.0.1", "X-Forwarded-For", "203.0.113.7"), qt.Equals, "203.0.113.7")
+	c.Assert(clientIP("ngrok", "Cf-Connecting-Ip", "127.0.0.1"), qt.Equals, "")
+
+	c.Assert(clientIP("cloudflared", "Cf-Connecting-Ip", "203.0.113.8", "X-Forwarded-For", "127.0.0.1"), qt.Equals, "203.0.113.8")
+	c.Assert(clientIP("cloudflared", "X-Forwarded-For", "203.0.113.7"), qt.Equals, "")
+}
+
+func TestStart(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skip on Windows")
+	}
+
+	c := qt.New(t)
+
+	local, _ := url.Parse("http://localhost:1313")
+	sc := security.DefaultConfig
+	sc.Exec.Allow = security.NewWhitelist("^sh$")
+
+	c.Run("Command", func(c *qt.C) {
+		cfg := config.Tunnel{
+			Provider: "command",
+			Command:  "sh",
+			Args:     []string{"-c", "echo connecting to {url}; echo 'ready at https://{port}.example.org/'; sleep 30"},
+		}
+		tun, err := Start(cfg, sc, local, nil)
+		c.Assert(err, qt.IsNil)
+		c.Assert(tun.URL.String(), qt.Equals, "https://1313.example.org")
+		c.Assert(tun.Provider, qt.Equals, "command")
+
+		r := httptest.NewRequest("GET", "https://1313.example.org/", nil)
+		r.Header.Set("X-Forwarded-For", "203.0.113.7")
+		c.Assert(tun.Forwards(r), qt.IsTrue)
+		c.Assert(tun.ClientIP(r).String(), qt.Equals, "203.0.113.7")
+		c.Assert(tun.Forwards(httptest.NewRequest("GET", "http://localhost:1313/", nil)), qt.IsFalse)
+
+		c.Assert(tun.Close(), qt.IsNil)
+		c.Assert(tun.Close(), qt.IsNil)
+	})
+
+	c.Run("URL pattern", func(c *qt.C) {
+		cfg := config.Tunnel{
+			Provider:   "command",
+			Command:    "sh",
+			Args:       []string{"-c", "echo 'https://ignored.example.org'; echo 'forwarding: https://my.example.org'; sleep 30"},
+			URLPattern: `forwarding: (\S+)`,
+		}
+		tun, err := Start(cfg, sc, local, nil)
+		c.Assert(err, qt.IsNil)
+		defer tun.Close()
+		c.Assert(tun.URL.String(), qt.Equals, "https://my.example.org")
+	})
+
+	c.Run("Exited", func(c *qt.C) {
+		cfg := config.Tunnel{
+			Provider: "command",
+			Command:  "sh",
+			Args:     []string{"-c", "echo 'not logged in'; exit 3"},
+		}
+		_, err := Start(cfg, sc, local, nil)
+		c.Assert(err, qt.ErrorMatches, `(?s)tunnel: sh exited before reporting a public URL: exit status 3.*not logged in`)
+	})
+
+	c.Run("Timeout", func(c *qt.C) {
+		startTimeout = 100 * time.Millisecond
+		defer func() { startTimeout = 30 * time.Second }()
+		cfg := config.Tunnel{
+			Provider: "command",
+			Command:  "sh",
+			Args:     []string{"-c", "sleep 30"},
+		}
+		_, err := Start(cfg, sc, local, nil)
+		c.Assert(err, qt.ErrorMatches, `tunnel: timed out.*`)
+	})
+
+	c.Run("Access denied", func(c *qt.C) {
+		cfg := config.Tunnel{Provider: "command", Command: "sh"}
+		_, err := Start(cfg, security.DefaultConfig, local, nil)
+		c.Assert(security.IsAccessDenied(err), qt.IsTrue)
+	})
+
+	c.Run("Invalid", func(c *qt.C) {
+		_, err := Start(config.Tunnel{Provider: "foo"}, sc, local, nil)
+		c.Assert(err, qt.ErrorMatches, `tunnel: unknown provider "foo".*`)
+		_, err = Start(config.Tunnel{Provider: "command"}, sc, local, nil)
+		c.Assert(err, qt.ErrorMatches, `tunnel: command must be set.*`)
+	})
+}
@@ -16,40 +16,42 @@ package watcher
 import (
 	"time"
 
-	
 	"github.com/fsnotify/fsnotify"
-	
-	"foo"
-	
-	"bar/baz"
-	
-	asdf
 )
 
 // Batcher batches file watch events in a given interval.
 type Batcher struct {
-	*fsnotif.Watcher
+	FileWatcher
 	interval time.Duration
 	done     chan struct{}
-  
+
 	Events chan []fsnotify.Event // Events are returned on this channel
 }
 
-// New creates and starts a Batcher with the given time interval.
-func New(interval time.Duration) (*Batcher, error) {
-	watcher, err := fsnotify.NewWatcher()
+// New creates and starts a Batcher with the given time interval. If
+// pollInterval is set, the files are polled for changes, see
+// NewPollingWatcher, else the events from the operating system are used.
+func New(interval, pollInterval time.Duration) (*Batcher, error) {
+	var watcher FileWatcher
+	if pollInterval > 0 {
+		watcher = NewPollingWatcher(pollInterval)
+	} else {
+		var err error
+		watcher, err = NewEventWatcher()
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	batcher := &Batcher{}
-	batcher.Watcher = watcher
+	batcher.FileWatcher = watcher
 	batcher.interval = interval
 	batcher.done = make(chan struct{}, 1)
 	batcher.Events = make(chan []fsnotify.Event, 1)
 
-	if err == nil {
-		go batcher.run()
-	}
+	go batcher.run()
 
-	return batcher, err
+	return batcher, nil
 }
 
 func (b *Batcher) run() {
@@ -58,7 +60,7 @@ func (b *Batcher) run() {
 OuterLoop:
 	for {
 		select {
-		case ev := <-b.Watcher.Events:
+		case ev := <-b.FileWatcher.Events():
 			evs = append(evs, ev)
 		case <-tick:
 			if len(evs) == 0 {
@@ -76,5 +78,5 @@ OuterLoop:
 // Close stops the watching of the files.
 func (b *Batcher) Close() {
 	b.done <- struct{}{}
-	b.Watcher.Close()
+	b.FileWatcher.Close()
 }
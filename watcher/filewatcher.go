@@ -0,0 +1,69 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package watcher
+
+import (
+	"runtime"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// FileWatcher watches files and directories for changes. Directories are
+// watched non-recursively, i.e. for changes to their entries.
+type FileWatcher interface {
+	Events() <-chan fsnotify.Event
+	Errors() <-chan error
+	Add(name string) error
+	Remove(name string) error
+	Close() error
+
+	// Backend describes how the changes are detected, for diagnostics.
+	Backend() string
+}
+
+// NewEventWatcher returns a FileWatcher using the events from the operating
+// system.
+func NewEventWatcher() (FileWatcher, error) {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	return &fsnotifyWatcher{Watcher: w}, nil
+}
+
+type fsnotifyWatcher struct {
+	*fsnotify.Watcher
+}
+
+func (w *fsnotifyWatcher) Events() <-chan fsnotify.Event {
+	return w.Watcher.Events
+}
+
+func (w *fsnotifyWatcher) Errors() <-chan error {
+	return w.Watcher.Errors
+}
+
+func (w *fsnotifyWatcher) Backend() string {
+	switch runtime.GOOS {
+	case "linux", "android":
+		return "fsnotify (inotify)"
+	case "darwin", "freebsd", "openbsd", "netbsd", "dragonfly":
+		return "fsnotify (kqueue)"
+	case "windows":
+		return "fsnotify (ReadDirectoryChangesW)"
+	case "solaris", "illumos":
+		return "fsnotify (FEN)"
+	}
+	return "fsnotify"
+}
@@ -0,0 +1,280 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package watcher
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/pkg/errors"
+)
+
+// The poll interval backs off to this many times the minimum interval when
+// nothing changes.
+const maxPollIntervalFactor = 4
+
+// NewPollingWatcher returns a FileWatcher that polls the watched files and
+// directories for changes, for file systems with no or unreliable events,
+// e.g. network file systems or directories mounted into a Docker container.
+//
+// The poll interval adapts to the change frequency: it starts at interval,
+// doubles for every poll without changes, up to four times interval, and is
+// reset on any change. It's never less than twice the time the last poll
+// took, to keep the load down on slow file systems.
+func NewPollingWatcher(interval time.Duration) FileWatcher {
+	w := &pollingWatcher{
+		minInterval: interval,
+		maxInterval: interval * maxPollIntervalFactor,
+		interval:    int64(interval),
+		events:      make(chan fsnotify.Event),
+		errors:      make(chan error),
+		done:        make(chan struct{}),
+		watches:     make(map[string]*pollWatch),
+	}
+	go w.run()
+	return w
+}
+
+type pollingWatcher struct {
+	minInterval time.Duration
+	maxInterval time.Duration
+
+	// The current poll interval.
+	interval int64
+
+	events chan fsnotify.Event
+	errors chan error
+	done   chan struct{}
+
+	mu      sync.Mutex
+	watches map[string]*pollWatch
+	closed  bool
+}
+
+// pollWatch is the state of a watched file or directory at the last poll.
+type pollWatch struct {
+	fi os.FileInfo
+
+	// The directory entries by name.
+	entries map[string]os.FileInfo
+
+	// The last error polling this, to report it once.
+	lastErr string
+}
+
+func (w *pollingWatcher) Events() <-chan fsnotify.Event {
+	return w.events
+}
+
+func (w *pollingWatcher) Errors() <-chan error {
+	return w.errors
+}
+
+func (w *pollingWatcher) Backend() string {
+	return fmt.Sprintf("polling every %s, up to %s when idle", w.minInterval, w.maxInterval)
+}
+
+// Interval returns the current poll interval.
+func (w *pollingWatcher) Interval() time.Duration {
+	return time.Duration(atomic.LoadInt64(&w.interval))
+}
+
+func (w *pollingWatcher) Add(name string) error {
+	name = filepath.Clean(name)
+	wa, err := newPollWatch(name)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return errors.New("watcher is closed")
+	}
+	w.watches[name] = wa
+
+	return nil
+}
+
+func (w *pollingWatcher) Remove(name string) error {
+	name = filepath.Clean(name)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, found := w.watches[name]; !found {
+		return errors.Errorf("can't remove non-existent watch for %q", name)
+	}
+	delete(w.watches, name)
+
+	return nil
+}
+
+func (w *pollingWatcher) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	close(w.done)
+	w.watches = nil
+
+	return nil
+}
+
+func (w *pollingWatcher) run() {
+	interval := w.minInterval
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+		case <-w.done:
+			return
+		}
+
+		start := time.Now()
+		evs, errs := w.poll()
+		took := time.Since(start)
+
+		for _, err := range errs {
+			select {
+			case w.errors <- err:
+			case <-w.done:
+				return
+			}
+		}
+		for _, ev := range evs {
+			select {
+			case w.events <- ev:
+			case <-w.done:
+				return
+			}
+		}
+
+		interval = nextPollInterval(interval, w.minInterval, w.maxInterval, len(evs) > 0, took)
+		atomic.StoreInt64(&w.interval, int64(interval))
+		timer.Reset(interval)
+	}
+}
+
+func nextPollInterval(current, min, max time.Duration, changed bool, took time.Duration) time.Duration {
+	next := current * 2
+	if changed {
+		next = min
+	}
+	if next > max {
+		next = max
+	}
+	if next < 2*took {
+		next = 2 * took
+	}
+	return next
+}
+
+// poll checks all the watches for changes. The events are returned, not
+// sent, so the lock isn't held while the receiver may call Add.
+func (w *pollingWatcher) poll() ([]fsnotify.Event, []error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var (
+		evs  []fsnotify.Event
+		errs []error
+		seen = make(map[fsnotify.Event]bool)
+	)
+
+	addEvent := func(name string, op fsnotify.Op) {
+		ev := fsnotify.Event{Name: name, Op: op}
+		if !seen[ev] {
+			seen[ev] = true
+			evs = append(evs, ev)
+		}
+	}
+
+	for name, old := range w.watches {
+		wa, err := newPollWatch(name)
+		if err != nil {
+			if os.IsNotExist(err) {
+				addEvent(name, fsnotify.Remove)
+				delete(w.watches, name)
+			} else if old.lastErr != err.Error() {
+				old.lastErr = err.Error()
+				errs = append(errs, err)
+			}
+			continue
+		}
+
+		if !wa.fi.IsDir() && fileChanged(old.fi, wa.fi) {
+			addEvent(name, fsnotify.Write)
+		} else if old.fi.Mode() != wa.fi.Mode() {
+			addEvent(name, fsnotify.Chmod)
+		}
+
+		for entryName, fi := range wa.entries {
+			filename := filepath.Join(name, entryName)
+			oldFi, found := old.entries[entryName]
+			switch {
+			case !found:
+				addEvent(filename, fsnotify.Create)
+			case !fi.IsDir() && fileChanged(oldFi, fi):
+				addEvent(filename, fsnotify.Write)
+			case oldFi.Mode() != fi.Mode():
+				addEvent(filename, fsnotify.Chmod)
+			}
+		}
+		for entryName := range old.entries {
+			if _, found := wa.entries[entryName]; !found {
+				addEvent(filepath.Join(name, entryName), fsnotify.Remove)
+			}
+		}
+
+		w.watches[name] = wa
+	}
+
+	return evs, errs
+}
+
+func newPollWatch(name string) (*pollWatch, error) {
+	fi, err := os.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	wa := &pollWatch{fi: fi}
+	if !fi.IsDir() {
+		return wa, nil
+	}
+
+	fis, err := ioutil.ReadDir(name)
+	if err != nil {
+		return nil, err
+	}
+	wa.entries = make(map[string]os.FileInfo, len(fis))
+	for _, fi := range fis {
+		wa.entries[fi.Name()] = fi
+	}
+
+	return wa, nil
+}
+
+func fileChanged(old, fi os.FileInfo) bool {
+	return !old.ModTime().Equal(fi.ModTime()) || old.Size() != fi.Size()
+}
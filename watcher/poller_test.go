@@ -0,0 +1,79 @@
+// Copyright 2021 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package watcher
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/fsnotify/fsnotify"
+)
+
+func TestPollingWatcher(t *testing.T) {
+	c := qt.New(t)
+
+	dir, err := ioutil.TempDir("", "hugo-poll")
+	c.Assert(err, qt.IsNil)
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "p1.md")
+	c.Assert(ioutil.WriteFile(filename, []byte("a"), 0644), qt.IsNil)
+
+	w := NewPollingWatcher(10 * time.Millisecond)
+	defer w.Close()
+	c.Assert(w.Backend(), qt.Equals, "polling every 10ms, up to 40ms when idle")
+	c.Assert(w.Add(dir), qt.IsNil)
+	c.Assert(w.Add(filepath.Join(dir, "nope")), qt.Not(qt.IsNil))
+
+	next := func() fsnotify.Event {
+		select {
+		case ev := <-w.Events():
+			return ev
+		case <-time.After(5 * time.Second):
+			c.Fatal("timed out waiting for event")
+		}
+		return fsnotify.Event{}
+	}
+
+	c.Assert(ioutil.WriteFile(filename, []byte("ab"), 0644), qt.IsNil)
+	c.Assert(next(), qt.Equals, fsnotify.Event{Name: filename, Op: fsnotify.Write})
+
+	p2 := filepath.Join(dir, "p2.md")
+	c.Assert(ioutil.WriteFile(p2, []byte("b"), 0644), qt.IsNil)
+	c.Assert(next(), qt.Equals, fsnotify.Event{Name: p2, Op: fsnotify.Create})
+
+	c.Assert(os.Remove(filename), qt.IsNil)
+	c.Assert(next(), qt.Equals, fsnotify.Event{Name: filename, Op: fsnotify.Remove})
+
+	c.Assert(w.Remove(dir), qt.IsNil)
+	c.Assert(w.Remove(dir), qt.Not(qt.IsNil))
+	c.Assert(w.Close(), qt.IsNil)
+	c.Assert(w.Add(dir), qt.Not(qt.IsNil))
+}
+
+func TestNextPollInterval(t *testing.T) {
+	c := qt.New(t)
+
+	min, max := 100*time.Millisecond, 400*time.Millisecond
+
+	c.Assert(nextPollInterval(min, min, max, false, 0), qt.Equals, 200*time.Millisecond)
+	c.Assert(nextPollInterval(300*time.Millisecond, min, max, false, 0), qt.Equals, max)
+	c.Assert(nextPollInterval(max, min, max, true, 0), qt.Equals, min)
+	// Slow file systems.
+	c.Assert(nextPollInterval(max, min, max, true, time.Second), qt.Equals, 2*time.Second)
+}